@@ -12,20 +12,89 @@ from app.api.v1 import (
     webhooks,
 )
 from app.api.v1.endpoints import (
+    abac_conditions,
+    access_reviews,
+    admin_surface,
     applications,
     audit_logs,
+    auth_gaps,
+    auth_libraries,
+    auth_mechanisms,
+    authz_tests,
+    backstage,
+    bola,
+    bundle_targets,
+    casbin,
+    cedar_exports,
+    cluster,
     code_advisories,
+    compliance,
+    cors_csrf,
+    coverage,
     cross_application_conflicts,
+    custom_rules,
+    dashboard,
+    delegated_checks,
     duplicates,
+    endpoint_consistency,
+    entry_points,
+    environments,
+    evidence,
+    exposure,
+    false_positives,
+    findings,
+    framework_routes,
+    identity_models,
+    identity_propagation,
+    idp_connectors,
+    idp_groups,
+    image_scans,
+    incident_alerts,
     inconsistent_enforcement,
+    istio_exports,
+    itsm_connectors,
+    k8s_manifests,
+    lint,
+    live_discovery,
+    management,
+    mass_assignment,
+    opa_queries,
+    openapi_exports,
     organizations,
+    ownership,
+    pdp_migration,
+    permission_matrix,
     policies,
+    policy_annotations,
     policy_fixes,
+    policy_graph,
+    policy_scaffolds,
+    rate_limits,
+    readiness,
+    rego_exports,
     repositories,
+    response_exposure,
     risk,
+    role_impact,
+    role_parameters,
+    saved_views,
+    scan_comparison,
     secrets,
+    service_graph,
+    service_views,
+    siem_connectors,
     similarity,
+    simulation,
+    spicedb_exports,
+    stable_ids,
+    status_badges,
+    step_up,
+    tenant_isolation,
+    thresholds,
     translation_verification,
+    trends,
+    vulnerable_auth_patterns,
+    xacml_exports,
 )
 
 api_router = APIRouter()
@@ -53,3 +122,72 @@ api_router.include_router(policy_fixes.router, prefix="/policy-fixes", tags=["po
 api_router.include_router(inconsistent_enforcement.router, prefix="/inconsistent-enforcement", tags=["inconsistent-enforcement"])
 api_router.include_router(duplicates.router, prefix="/duplicates", tags=["duplicates"])
 api_router.include_router(cross_application_conflicts.router, prefix="/cross-application-conflicts", tags=["cross-application-conflicts"])
+api_router.include_router(authz_tests.router, prefix="/authz-tests", tags=["authz-tests"])
+api_router.include_router(simulation.router, prefix="/simulate", tags=["simulation"])
+api_router.include_router(role_impact.router, prefix="/role-impact", tags=["role-impact"])
+api_router.include_router(access_reviews.router, prefix="/access-reviews", tags=["access-reviews"])
+api_router.include_router(compliance.router, prefix="/compliance", tags=["compliance"])
+api_router.include_router(permission_matrix.router, prefix="/permission-matrix", tags=["permission-matrix"])
+api_router.include_router(policy_graph.router, prefix="/policy-graph", tags=["policy-graph"])
+api_router.include_router(coverage.router, prefix="/coverage", tags=["coverage"])
+api_router.include_router(trends.router, prefix="/trends", tags=["trends"])
+api_router.include_router(scan_comparison.router, prefix="/scan-comparison", tags=["scan-comparison"])
+api_router.include_router(evidence.router, prefix="/evidence", tags=["evidence"])
+api_router.include_router(saved_views.router, prefix="/saved-views", tags=["saved-views"])
+api_router.include_router(dashboard.router, prefix="/dashboard", tags=["dashboard"])
+api_router.include_router(image_scans.router, prefix="/image-scans", tags=["image-scans"])
+api_router.include_router(k8s_manifests.router, prefix="/k8s-manifests", tags=["k8s-manifests"])
+api_router.include_router(idp_groups.router, prefix="/idp-groups", tags=["idp-groups"])
+api_router.include_router(idp_connectors.router, prefix="/idp-connectors", tags=["idp-connectors"])
+api_router.include_router(bundle_targets.router, prefix="/bundle-targets", tags=["bundle-targets"])
+api_router.include_router(live_discovery.router, prefix="/live-discovery", tags=["live-discovery"])
+api_router.include_router(framework_routes.router, prefix="/framework-routes", tags=["framework-routes"])
+api_router.include_router(auth_mechanisms.router, prefix="/auth-mechanisms", tags=["auth-mechanisms"])
+api_router.include_router(step_up.router, prefix="/step-up", tags=["step-up"])
+api_router.include_router(cors_csrf.router, prefix="/cors-csrf", tags=["cors-csrf"])
+api_router.include_router(rate_limits.router, prefix="/rate-limits", tags=["rate-limits"])
+api_router.include_router(admin_surface.router, prefix="/admin-surface", tags=["admin-surface"])
+api_router.include_router(entry_points.router, prefix="/entry-points", tags=["entry-points"])
+api_router.include_router(exposure.router, prefix="/exposure", tags=["exposure"])
+api_router.include_router(stable_ids.router, prefix="/stable-ids", tags=["stable-ids"])
+api_router.include_router(false_positives.router, prefix="/false-positives", tags=["false-positives"])
+api_router.include_router(lint.router, prefix="/lint", tags=["lint"])
+api_router.include_router(environments.router, prefix="/environments", tags=["environments"])
+api_router.include_router(thresholds.router, prefix="/thresholds", tags=["thresholds"])
+api_router.include_router(ownership.router, prefix="/ownership", tags=["ownership"])
+api_router.include_router(readiness.router, prefix="/readiness", tags=["readiness"])
+api_router.include_router(custom_rules.router, prefix="/custom-rules", tags=["custom-rules"])
+api_router.include_router(role_parameters.router, prefix="/role-parameters", tags=["role-parameters"])
+api_router.include_router(policy_annotations.router, prefix="/policy-annotations", tags=["policy-annotations"])
+api_router.include_router(policy_scaffolds.router, prefix="/policy-scaffolds", tags=["policy-scaffolds"])
+api_router.include_router(pdp_migration.router, prefix="/pdp-migration", tags=["pdp-migration"])
+api_router.include_router(service_graph.router, prefix="/service-graph", tags=["service-graph"])
+api_router.include_router(identity_propagation.router, prefix="/identity-propagation", tags=["identity-propagation"])
+api_router.include_router(auth_libraries.router, prefix="/auth-libraries", tags=["auth-libraries"])
+api_router.include_router(vulnerable_auth_patterns.router, prefix="/vulnerable-auth-patterns", tags=["vulnerable-auth-patterns"])
+api_router.include_router(findings.router, prefix="/findings", tags=["findings"])
+api_router.include_router(casbin.router, prefix="/casbin", tags=["casbin"])
+api_router.include_router(bola.router, prefix="/bola", tags=["bola"])
+api_router.include_router(opa_queries.router, prefix="/opa-queries", tags=["opa-queries"])
+api_router.include_router(abac_conditions.router, prefix="/abac-conditions", tags=["abac-conditions"])
+api_router.include_router(mass_assignment.router, prefix="/mass-assignment", tags=["mass-assignment"])
+api_router.include_router(delegated_checks.router, prefix="/delegated-checks", tags=["delegated-checks"])
+api_router.include_router(response_exposure.router, prefix="/response-exposure", tags=["response-exposure"])
+api_router.include_router(service_views.router, prefix="/service-views", tags=["service-views"])
+api_router.include_router(identity_models.router, prefix="/identity-model", tags=["identity-model"])
+api_router.include_router(tenant_isolation.router, prefix="/tenant-isolation", tags=["tenant-isolation"])
+api_router.include_router(auth_gaps.router, prefix="/auth-gaps", tags=["auth-gaps"])
+api_router.include_router(endpoint_consistency.router, prefix="/endpoint-consistency", tags=["endpoint-consistency"])
+api_router.include_router(status_badges.router, prefix="/status", tags=["status"])
+api_router.include_router(backstage.router, prefix="/backstage", tags=["backstage"])
+api_router.include_router(rego_exports.router, prefix="/rego-exports", tags=["rego-exports"])
+api_router.include_router(itsm_connectors.router, prefix="/itsm-connectors", tags=["itsm-connectors"])
+api_router.include_router(cedar_exports.router, prefix="/cedar-exports", tags=["cedar-exports"])
+api_router.include_router(incident_alerts.router, prefix="/incident-alerts", tags=["incident-alerts"])
+api_router.include_router(siem_connectors.router, prefix="/siem-connectors", tags=["siem-connectors"])
+api_router.include_router(spicedb_exports.router, prefix="/spicedb-exports", tags=["spicedb-exports"])
+api_router.include_router(management.router, prefix="/management", tags=["management"])
+api_router.include_router(xacml_exports.router, prefix="/xacml-exports", tags=["xacml-exports"])
+api_router.include_router(cluster.router, prefix="/cluster", tags=["cluster"])
+api_router.include_router(istio_exports.router, prefix="/istio-exports", tags=["istio-exports"])
+api_router.include_router(openapi_exports.router, prefix="/openapi-exports", tags=["openapi-exports"])
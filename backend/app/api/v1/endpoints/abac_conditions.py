@@ -0,0 +1,37 @@
+"""API endpoints for attribute conditions lifted from handler bodies."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.abac_condition import AbacConditionResult
+from app.services.abac_condition_service import AbacConditionService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=AbacConditionResult)
+def lift_abac_conditions(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository whose handlers to read"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AbacConditionResult:
+    """Add the attribute checks guarding a repository's handlers to their policies' conditions.
+
+    Runs automatically after each repository scan; call it directly after
+    editing policies to lift the conditions again.
+    """
+    service = AbacConditionService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.lift_conditions(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return AbacConditionResult(**result)
@@ -0,0 +1,141 @@
+"""API endpoints for access review campaigns."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.models.access_review import AccessReviewCampaign
+from app.schemas.access_review import (
+    AccessReviewCampaignCreate,
+    AccessReviewCampaignResponse,
+    AccessReviewItemResponse,
+    AccessReviewPacketResponse,
+    AttestationRequest,
+)
+from app.services.access_review_service import AccessReviewService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+def _campaign_response(campaign: AccessReviewCampaign) -> AccessReviewCampaignResponse:
+    """Build a campaign response with progress counts."""
+    return AccessReviewCampaignResponse(
+        id=campaign.id,
+        name=campaign.name,
+        description=campaign.description,
+        status=campaign.status,
+        due_date=campaign.due_date,
+        created_by=campaign.created_by,
+        created_at=campaign.created_at,
+        completed_at=campaign.completed_at,
+        **AccessReviewService.summarize(campaign),
+    )
+
+
+@router.post("/campaigns", response_model=AccessReviewCampaignResponse)
+def create_campaign(
+    request: AccessReviewCampaignCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AccessReviewCampaignResponse:
+    """Generate an access review campaign with one packet per resource owner.
+
+    Packets are grouped by application owner; policies without an owning
+    application land in an "unassigned" packet.
+    """
+    service = AccessReviewService(db, tenant_id)
+    try:
+        campaign = service.create_campaign(**request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return _campaign_response(campaign)
+
+
+@router.get("/campaigns", response_model=list[AccessReviewCampaignResponse])
+def list_campaigns(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[AccessReviewCampaignResponse]:
+    """List access review campaigns, newest first."""
+    service = AccessReviewService(db, tenant_id)
+    return [_campaign_response(c) for c in service.list_campaigns()]
+
+
+@router.get("/campaigns/{campaign_id}", response_model=AccessReviewCampaignResponse)
+def get_campaign(
+    campaign_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AccessReviewCampaignResponse:
+    """Get a campaign with progress and outcome counts."""
+    service = AccessReviewService(db, tenant_id)
+    try:
+        campaign = service.get_campaign(campaign_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return _campaign_response(campaign)
+
+
+@router.post("/campaigns/{campaign_id}/cancel", response_model=AccessReviewCampaignResponse)
+def cancel_campaign(
+    campaign_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AccessReviewCampaignResponse:
+    """Cancel an open campaign."""
+    service = AccessReviewService(db, tenant_id)
+    try:
+        campaign = service.cancel_campaign(campaign_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return _campaign_response(campaign)
+
+
+@router.get("/campaigns/{campaign_id}/remediation", response_model=list[AccessReviewItemResponse])
+def get_remediation_items(
+    campaign_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[AccessReviewItemResponse]:
+    """List items reviewers asked to revoke or modify."""
+    service = AccessReviewService(db, tenant_id)
+    try:
+        campaign = service.get_campaign(campaign_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [AccessReviewItemResponse.model_validate(i) for i in service.remediation_items(campaign)]
+
+
+@router.get("/packets/{packet_id}", response_model=AccessReviewPacketResponse)
+def get_packet(
+    packet_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AccessReviewPacketResponse:
+    """Get a reviewer packet: who can do what, with evidence."""
+    service = AccessReviewService(db, tenant_id)
+    try:
+        packet = service.get_packet(packet_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return AccessReviewPacketResponse.model_validate(packet)
+
+
+@router.post("/items/{item_id}/attest", response_model=AccessReviewItemResponse)
+def attest_item(
+    item_id: int,
+    request: AttestationRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AccessReviewItemResponse:
+    """Record a reviewer's attestation for one item."""
+    service = AccessReviewService(db, tenant_id)
+    try:
+        item = service.attest(item_id, request.decision, request.reviewed_by, request.comment)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return AccessReviewItemResponse.model_validate(item)
@@ -0,0 +1,35 @@
+"""API endpoints for admin surface inventories."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.admin_surface import ServiceAdminSurface
+from app.services.admin_surface_service import AdminSurfaceService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/services", response_model=list[ServiceAdminSurface])
+def list_service_admin_surfaces(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    exposed_only: bool = Query(False, description="Only services with public, any-user, or unmapped admin endpoints"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ServiceAdminSurface]:
+    """Get the admin surface inventory per service.
+
+    Admin path prefixes, management endpoints, framework admin modules, and
+    management ports are read from the mined policies and each
+    repository's clone. Every admin endpoint is listed with its protection
+    status, exposed ones first.
+    """
+    try:
+        services = AdminSurfaceService(db, tenant_id).services(repository_id, exposed_only)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ServiceAdminSurface(**s) for s in services]
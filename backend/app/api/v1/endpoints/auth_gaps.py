@@ -0,0 +1,38 @@
+"""API endpoints for registered routes missing authentication or authorization."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.auth_gap import AuthGapReport
+from app.services.auth_gap_service import AuthGapService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=AuthGapReport)
+def list_auth_gaps(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    severity: str | None = Query(None, description="Only gaps of this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AuthGapReport:
+    """Find registered routes with no detectable authentication or authorization.
+
+    Every route registration in each repository clone is checked for a mined
+    policy requiring authentication, a guard on the registration or its
+    handler, a guard on the enclosing controller, and auth middleware mounted
+    earlier in the file. Routes with none are reported with the registration
+    line as evidence, ordered by severity and endpoint risk (OWASP API2 and
+    API5, CWE-306 and CWE-862). Routes explicitly marked public are counted,
+    not reported.
+    """
+    try:
+        result = AuthGapService(db, tenant_id).gaps(repository_id, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return AuthGapReport(**result)
@@ -0,0 +1,39 @@
+"""API endpoints for the auth library inventory."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.auth_library import AuthLibraryInventory
+from app.services.auth_library_service import AuthLibraryService, Ecosystem, LibraryCategory
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=AuthLibraryInventory)
+def get_auth_library_inventory(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    library: str | None = Query(None, description="Keep usages of this library, e.g. Spring Security or jsonwebtoken"),
+    category: LibraryCategory | None = Query(None, description="Keep usages of libraries in this category"),
+    ecosystem: Ecosystem | None = Query(None, description="Keep usages from this package ecosystem"),
+    deprecated_only: bool = Query(False, description="Only deprecated libraries and versions below a safe floor"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> AuthLibraryInventory:
+    """Inventory the auth libraries and versions each service uses.
+
+    Dependency manifests, lockfiles, and CycloneDX or SPDX SBOMs are read
+    from each repository's clone and matched against a catalog of
+    authentication, token, federation, authorization, and policy engine
+    libraries. Usages are rolled up per library, with the versions each
+    service runs, and per category, with the libraries competing in it.
+    """
+    try:
+        result = AuthLibraryService(db, tenant_id).inventory(repository_id, library, category, ecosystem, deprecated_only)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return AuthLibraryInventory(**result)
@@ -0,0 +1,42 @@
+"""API endpoints for authentication mechanism classification."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.auth_mechanism import EndpointAuthMechanism, ServiceAuthMechanisms
+from app.services.auth_mechanism_service import AuthMechanism, AuthMechanismService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=list[EndpointAuthMechanism])
+def list_endpoint_mechanisms(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    application_id: int | None = Query(None, description="Restrict to one application"),
+    mechanism: AuthMechanism | None = Query(None, description="Keep endpoints that use this mechanism"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[EndpointAuthMechanism]:
+    """Classify endpoints by authentication mechanism.
+
+    Reads cookie sessions, JWT bearer tokens, API keys, and client
+    certificates from the mined middleware and evidence behind each endpoint.
+    """
+    service = AuthMechanismService(db, tenant_id)
+    return [EndpointAuthMechanism(**e) for e in service.endpoints(repository_id, application_id, mechanism)]
+
+
+@router.get("/services", response_model=list[ServiceAuthMechanisms])
+def list_service_mechanisms(
+    db: Annotated[Session, Depends(get_db)],
+    mechanism: AuthMechanism | None = Query(None, description="Keep services with endpoints using this mechanism"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ServiceAuthMechanisms]:
+    """Summarize authentication mechanisms per service."""
+    service = AuthMechanismService(db, tenant_id)
+    return [ServiceAuthMechanisms(**s) for s in service.service_summary(mechanism)]
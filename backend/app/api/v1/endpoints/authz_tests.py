@@ -0,0 +1,181 @@
+"""API endpoints for generating authorization tests from mined policies."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import PlainTextResponse
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.authz_test import (
+    ContractVerificationReport,
+    ContractVerificationRequest,
+    GeneratedAuthzTests,
+    PolicyMutationReport,
+)
+from app.services.authz_test_generation_service import (
+    AuthzTestFramework,
+    AuthzTestGenerationService,
+)
+from app.services.contract_verification_service import ContractVerificationService
+from app.services.policy_mutation_service import MutationHarness, PolicyMutationService
+from app.services.redaction_service import redact_for_egress
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/repositories/{repository_id}", response_model=GeneratedAuthzTests)
+def generate_authz_tests(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    framework: AuthzTestFramework = Query(AuthzTestFramework.PYTEST, description="Target test framework"),
+    package_name: str | None = Query(None, description="Go package name (go_httptest only)"),
+) -> GeneratedAuthzTests:
+    """Generate per-endpoint authorization tests for a repository.
+
+    Each endpoint is tested against every mined role plus an anonymous
+    caller, asserting the expected status code so teams can lock in current
+    behavior in their own CI.
+
+    Args:
+        repository_id: Repository ID
+        framework: go_httptest, pytest, or jest_supertest
+        package_name: Optional Go package name
+
+    Returns:
+        GeneratedAuthzTests with the rendered suite
+
+    Raises:
+        HTTPException: 404 if repository not found
+    """
+    logger.info(
+        "generate_authz_tests_endpoint",
+        repository_id=repository_id,
+        framework=framework.value,
+        tenant_id=tenant_id,
+    )
+
+    service = AuthzTestGenerationService(db, tenant_id)
+    try:
+        result = service.generate_for_repository(repository_id, framework, package_name)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e))
+
+    return GeneratedAuthzTests(**result)
+
+
+@router.get("/repositories/{repository_id}/download", response_class=PlainTextResponse)
+def download_authz_tests(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    framework: AuthzTestFramework = Query(AuthzTestFramework.PYTEST, description="Target test framework"),
+    package_name: str | None = Query(None, description="Go package name (go_httptest only)"),
+) -> PlainTextResponse:
+    """Download the generated test suite as a source file.
+
+    Args:
+        repository_id: Repository ID
+        framework: go_httptest, pytest, or jest_supertest
+        package_name: Optional Go package name
+
+    Returns:
+        Test source as an attachment
+
+    Raises:
+        HTTPException: 404 if repository not found
+    """
+    service = AuthzTestGenerationService(db, tenant_id)
+    try:
+        result = service.generate_for_repository(repository_id, framework, package_name)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e))
+
+    return PlainTextResponse(
+        content=redact_for_egress(result["content"], "export"),
+        headers={"Content-Disposition": f'attachment; filename="{result["filename"]}"'},
+    )
+
+
+@router.post("/repositories/{repository_id}/verify", response_model=ContractVerificationReport)
+async def verify_against_deployment(
+    repository_id: int,
+    request: ContractVerificationRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ContractVerificationReport:
+    """Verify mined policies against a running application.
+
+    Sends the generated role/endpoint cases as real requests to the given
+    deployment and reports where live enforcement differs from the mined
+    expectation. Only safe methods are sent unless include_unsafe_methods is set.
+
+    Args:
+        repository_id: Repository ID
+        request: Base URL, per-role credentials, and execution options
+
+    Returns:
+        ContractVerificationReport with per-case outcomes
+
+    Raises:
+        HTTPException: 404 if repository not found
+    """
+    logger.info(
+        "verify_against_deployment_endpoint",
+        repository_id=repository_id,
+        base_url=request.base_url,
+        tenant_id=tenant_id,
+    )
+
+    service = ContractVerificationService(db, tenant_id)
+    try:
+        report = await service.verify_repository(
+            repository_id,
+            base_url=request.base_url,
+            credentials=request.credentials,
+            include_unsafe_methods=request.include_unsafe_methods,
+            timeout_seconds=request.timeout_seconds,
+            max_concurrency=request.max_concurrency,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e))
+
+    return ContractVerificationReport(**report)
+
+
+@router.get("/repositories/{repository_id}/mutation-score", response_model=PolicyMutationReport)
+def get_policy_mutation_score(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    harness: str = Query(
+        MutationHarness.GENERATED_TESTS,
+        pattern="^(generated_tests|probe)$",
+        description="Score generated test suites or the live probing harness",
+    ),
+) -> PolicyMutationReport:
+    """Get the policy test strength score for a repository.
+
+    Mutates each mined endpoint rule and reports which mutations the
+    generated cases would catch.
+
+    Args:
+        repository_id: Repository ID
+        harness: generated_tests or probe
+
+    Returns:
+        PolicyMutationReport with score and surviving mutants
+
+    Raises:
+        HTTPException: 404 if repository not found
+    """
+    service = PolicyMutationService(db, tenant_id)
+    try:
+        report = service.score_repository(repository_id, harness)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e))
+
+    return PolicyMutationReport(**report)
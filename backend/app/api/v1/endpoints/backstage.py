@@ -0,0 +1,50 @@
+"""API endpoints for the Backstage developer portal integration."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.backstage import BackstageEntitySummary, BackstagePosture
+from app.services.backstage_service import BackstageService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/entities", response_model=list[BackstageEntitySummary])
+def list_entities(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[BackstageEntitySummary]:
+    """List every repository's status with the annotation that links a catalog entity to it."""
+    return [BackstageEntitySummary(**e) for e in BackstageService(db, tenant_id).entities()]
+
+
+@router.get("/entities/by-name/{kind}/{namespace}/{name}", response_model=BackstagePosture)
+def get_entity_posture(
+    kind: str,
+    namespace: str,
+    name: str,
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Value of the policy-miner/repository-id annotation"),
+    project_slug: str | None = Query(None, description="Value of the github.com/project-slug annotation"),
+    source_location: str | None = Query(None, description="Value of the backstage.io/source-location annotation"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> BackstagePosture:
+    """Get the authorization posture of a catalog entity.
+
+    Mirrors the Backstage catalog's entity path so a plugin can pass the
+    entity it is rendering along with its annotations. The entity is matched
+    to a repository by annotation, then by name, and its page gets coverage
+    and open critical findings as of the last scan, a policy summary, and the
+    open findings.
+    """
+    service = BackstageService(db, tenant_id)
+    try:
+        repository = service.resolve(name, repository_id, project_slug, source_location)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=f"{kind}:{namespace}/{name}: {e}") from e
+    return BackstagePosture(**service.posture(repository))
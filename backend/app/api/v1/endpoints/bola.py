@@ -0,0 +1,35 @@
+"""API endpoints for broken object level authorization (BOLA) candidates."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.bola import BolaReport
+from app.services.bola_detection_service import BolaDetectionService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=BolaReport)
+def list_bola_candidates(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    severity: str | None = Query(None, description="Only this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> BolaReport:
+    """Find handlers that look up objects by a client-supplied ID without an ownership check.
+
+    Follows path, query, and body IDs through each handler to lookups such
+    as GetByID(id) or findById(id), and reports those no query scope, owner
+    comparison, or object permission check covers (OWASP API1, CWE-639).
+    Each candidate carries its dataflow trace, most severe first.
+    """
+    try:
+        result = BolaDetectionService(db, tenant_id).candidates(repository_id, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return BolaReport(**result)
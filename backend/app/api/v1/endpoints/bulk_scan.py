@@ -11,6 +11,7 @@ from sqlalchemy.orm import Session
 from app.core.database import get_db
 from app.core.dependencies import get_tenant_id
 from app.models.repository import Repository
+from app.schemas.role_parameter import ConfigSnapshot
 from app.tasks.scan_tasks import bulk_scan_repositories_task, scan_repository_task
 
 router = APIRouter()
@@ -157,6 +158,7 @@ async def get_task_status(task_id: str) -> TaskStatusResponse:
 async def async_scan_repository(
     repository_id: int,
     incremental: bool = False,
+    snapshot: ConfigSnapshot | None = None,
     db: Session = Depends(get_db),
     tenant_id: str | None = Depends(get_tenant_id),
 ) -> dict[str, Any]:
@@ -166,6 +168,8 @@ async def async_scan_repository(
     Args:
         repository_id: ID of the repository to scan
         incremental: If True, only scan changed files
+        snapshot: Optional config and environment snapshot; settings mined
+            conditions reference (cfg.MaxSelfApproveAmount) resolve to its values
         db: Database session
         tenant_id: Optional tenant ID for multi-tenancy
 
@@ -197,6 +201,7 @@ async def async_scan_repository(
             "repository_id": repository_id,
             "tenant_id": tenant_id,
             "incremental": incremental,
+            "config_snapshot": snapshot.model_dump() if snapshot else None,
         }
     )
 
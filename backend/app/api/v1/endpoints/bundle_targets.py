@@ -0,0 +1,116 @@
+"""API endpoints for publishing policy bundles to OPA bundle servers and OCI registries."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.bundle_publication import (
+    BundlePublicationResponse,
+    BundleTargetCreate,
+    BundleTargetResponse,
+)
+from app.services.bundle_publish_service import BundlePublishService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=BundleTargetResponse, status_code=201)
+def create_bundle_target(
+    request: BundleTargetCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> BundleTargetResponse:
+    """Register a bundle server or registry approved policies are published to."""
+    try:
+        target = BundlePublishService(db, tenant_id).create_target(request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return BundleTargetResponse.model_validate(target)
+
+
+@router.get("/", response_model=list[BundleTargetResponse])
+def list_bundle_targets(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[BundleTargetResponse]:
+    """List bundle targets."""
+    return [BundleTargetResponse.model_validate(t) for t in BundlePublishService(db, tenant_id).list_targets()]
+
+
+@router.delete("/{target_id}", status_code=204)
+def delete_bundle_target(
+    target_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Delete a bundle target and its publication history."""
+    try:
+        BundlePublishService(db, tenant_id).delete_target(target_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.post("/{target_id}/publish", response_model=BundlePublicationResponse)
+async def publish_bundle(
+    target_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> BundlePublicationResponse:
+    """Build the next bundle version from approved policies and push it.
+
+    A failed push is returned with status "failed" and the registry or
+    server error, and stays in the publication history.
+    """
+    service = BundlePublishService(db, tenant_id)
+    try:
+        service.get_target(target_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        publication = await service.publish(target_id, triggered_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return BundlePublicationResponse.model_validate(publication)
+
+
+@router.get("/{target_id}/publications", response_model=list[BundlePublicationResponse])
+def list_publications(
+    target_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[BundlePublicationResponse]:
+    """Publication history of a target, newest first."""
+    try:
+        publications = BundlePublishService(db, tenant_id).list_publications(target_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [BundlePublicationResponse.model_validate(p) for p in publications]
+
+
+@router.get("/{target_id}/bundle")
+async def download_bundle(
+    target_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> Response:
+    """Build the bundle a publish would push, without pushing it."""
+    service = BundlePublishService(db, tenant_id)
+    try:
+        target = service.get_target(target_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        archive, _ = await service.build_bundle(target, revision=f"{target.bundle_name}-preview")
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return Response(
+        content=archive,
+        media_type="application/gzip",
+        headers={"Content-Disposition": f'attachment; filename="{target.bundle_name}.tar.gz"'},
+    )
@@ -0,0 +1,37 @@
+"""API endpoints for Casbin model and policy imports."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.casbin import CasbinScanResult
+from app.services.casbin_policy_service import CasbinPolicyService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=CasbinScanResult)
+def import_casbin_policies(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository whose clone calls a Casbin enforcer"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> CasbinScanResult:
+    """Import the Casbin model and policy rows a repository's enforcers load.
+
+    Runs automatically after each repository scan; call it directly to
+    re-import after editing the policy files without a full rescan.
+    """
+    service = CasbinPolicyService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.scan_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return CasbinScanResult(**result)
@@ -0,0 +1,56 @@
+"""API endpoints for exporting mined policies as Cedar."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.cedar_export import CedarExportResponse
+from app.services.cedar_export_service import CedarExportService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/repositories/{repository_id}", response_model=CedarExportResponse)
+def export_repository_cedar(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> CedarExportResponse:
+    """Export a repository's mined policies as Cedar for Amazon Verified Permissions.
+
+    Returns one statement per policy, ready for CreatePolicy, and the entity
+    schema for PutSchema. Only approved policies are exported unless
+    include_pending is set; policies scored below min_confidence are left out.
+    """
+    try:
+        result = CedarExportService(db, tenant_id).export(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return CedarExportResponse(**result)
+
+
+@router.get("/repositories/{repository_id}/archive")
+def download_repository_cedar(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> Response:
+    """Download a repository's Cedar policy file and schema as a tarball."""
+    try:
+        archive = CedarExportService(db, tenant_id).archive(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return Response(
+        content=archive,
+        media_type="application/gzip",
+        headers={"Content-Disposition": f'attachment; filename="repository-{repository_id}-cedar.tar.gz"'},
+    )
@@ -0,0 +1,32 @@
+"""API endpoints for the state of a highly available deployment."""
+from typing import Annotated
+
+from fastapi import APIRouter, Depends
+
+from app.core.coordination import LeaderElection, get_redis
+from app.core.dependencies import require_auth
+from app.models.user import User
+from app.schemas.cluster import ClusterStatus, RegisteredWorker
+from app.services.worker_registry import WorkerRegistry
+
+router = APIRouter()
+
+ELECTIONS = ("scheduler", "operator")
+
+
+@router.get("/status", response_model=ClusterStatus)
+def get_cluster_status(user: Annotated[User, Depends(require_auth)]) -> ClusterStatus:
+    """Get the scheduler and operator leaders and the registered scan workers.
+
+    Orphaned scans belong to workers whose registration expired; the
+    scheduler leader queues them again within a minute.
+    """
+    client = get_redis()
+    registry = WorkerRegistry(client)
+    workers = registry.list_workers()
+    live = {w["worker_id"] for w in workers}
+    return ClusterStatus(
+        leaders={name: LeaderElection(client, name).holder() for name in ELECTIONS},
+        workers=[RegisteredWorker(**w) for w in workers],
+        orphaned_scans=sum(1 for s in registry.list_scans() if s["worker_id"] not in live),
+    )
@@ -0,0 +1,72 @@
+"""API endpoints for compliance framework mapping."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import PlainTextResponse
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.compliance import ComplianceFramework, CompliancePosture
+from app.services.compliance_mapping_service import ComplianceMappingService
+from app.services.redaction_service import redact_for_egress
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+def _service(db: Session, tenant_id: str | None) -> ComplianceMappingService:
+    """Create the service, surfacing a broken mapping file as a server error."""
+    try:
+        return ComplianceMappingService(db, tenant_id)
+    except ValueError as e:
+        logger.error("compliance_mapping_invalid", error=str(e))
+        raise HTTPException(status_code=500, detail=str(e)) from e
+
+
+@router.get("/frameworks", response_model=list[ComplianceFramework])
+def list_frameworks(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ComplianceFramework]:
+    """List configured compliance frameworks."""
+    return [ComplianceFramework(**f) for f in _service(db, tenant_id).list_frameworks()]
+
+
+@router.get("/posture", response_model=CompliancePosture)
+def get_posture(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    framework: str | None = Query(None, description="Restrict to one framework ID"),
+) -> CompliancePosture:
+    """Summarize compliance posture per control.
+
+    Each control lists the posture signals backing it, so auditors can see
+    why it is passing or failing.
+    """
+    try:
+        result = _service(db, tenant_id).posture(framework)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return CompliancePosture(**result)
+
+
+@router.get("/export", response_class=PlainTextResponse)
+def export_posture(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    framework: str | None = Query(None, description="Restrict to one framework ID"),
+) -> PlainTextResponse:
+    """Download per-control posture as CSV for auditors."""
+    try:
+        content = _service(db, tenant_id).export_csv(framework)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+    filename = f"compliance-{framework or 'all'}.csv"
+    return PlainTextResponse(
+        content=redact_for_egress(content, "export"),
+        media_type="text/csv",
+        headers={"Content-Disposition": f'attachment; filename="{filename}"'},
+    )
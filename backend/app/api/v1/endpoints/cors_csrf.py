@@ -0,0 +1,34 @@
+"""API endpoints for CORS and CSRF posture."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.cors_csrf import ServiceCorsCsrf
+from app.services.cors_csrf_service import CorsCsrfService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/services", response_model=list[ServiceCorsCsrf])
+def list_service_cors_csrf(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    flagged_only: bool = Query(False, description="Only services with credentialed wildcard-origin endpoints"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ServiceCorsCsrf]:
+    """Get CORS configuration and CSRF protection per service and route.
+
+    Settings are read from each repository's clone and resolved onto the
+    endpoints its policies map to. Authenticated endpoints that any origin
+    may call with credentials are flagged.
+    """
+    try:
+        services = CorsCsrfService(db, tenant_id).services(repository_id, flagged_only)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ServiceCorsCsrf(**s) for s in services]
@@ -0,0 +1,44 @@
+"""API endpoints for authorization coverage metrics."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.coverage import RepositoryCoverage, WorkspaceCoverage
+from app.services.coverage_metrics_service import CoverageMetricsService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=WorkspaceCoverage)
+def get_workspace_coverage(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> WorkspaceCoverage:
+    """Get coverage metrics for every service plus workspace totals."""
+    service = CoverageMetricsService(db, tenant_id)
+    return WorkspaceCoverage(**service.workspace_coverage())
+
+
+@router.get("/repositories/{repository_id}", response_model=RepositoryCoverage)
+def get_repository_coverage(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> RepositoryCoverage:
+    """Get coverage metrics for one service.
+
+    Reports the share of endpoints that are authenticated, authorized beyond
+    authentication, and guarded by ABAC conditions, plus the number of
+    authorization patterns the miner could not interpret.
+    """
+    service = CoverageMetricsService(db, tenant_id)
+    try:
+        result = service.repository_coverage(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return RepositoryCoverage(**result)
@@ -0,0 +1,204 @@
+"""API endpoints for custom detection rules."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.custom_rule import (
+    CustomRuleDefinition,
+    CustomRuleDryRunRequest,
+    CustomRuleDryRunResult,
+    CustomRuleHitResponse,
+    CustomRuleResponse,
+    CustomRuleStats,
+    CustomRuleSyncRequest,
+    CustomRuleSyncResult,
+    CustomRuleUpdate,
+    CustomRuleVersionResponse,
+)
+from app.services.custom_rule_service import CustomRuleService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=list[CustomRuleResponse])
+def list_custom_rules(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[CustomRuleResponse]:
+    """List the workspace's custom rules."""
+    return [CustomRuleResponse.model_validate(r) for r in CustomRuleService(db, tenant_id).list_rules()]
+
+
+@router.post("/", response_model=CustomRuleResponse, status_code=201)
+def create_custom_rule(
+    request: CustomRuleDefinition,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> CustomRuleResponse:
+    """Define a custom rule evaluated against every scan of the workspace."""
+    try:
+        rule = CustomRuleService(db, tenant_id).create_rule(request.model_dump(), created_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return CustomRuleResponse.model_validate(rule)
+
+
+@router.put("/sync", response_model=CustomRuleSyncResult)
+def sync_custom_rules(
+    request: CustomRuleSyncRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> CustomRuleSyncResult:
+    """Make the workspace's rules match a rules file, matching rules by name.
+
+    Nothing is applied unless every rule in the file is valid.
+    """
+    try:
+        result = CustomRuleService(db, tenant_id).sync(
+            [rule.model_dump() for rule in request.rules],
+            prune=request.prune,
+            dry_run=request.dry_run,
+            changed_by=user_email,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return CustomRuleSyncResult(**result)
+
+
+@router.post("/dry-run", response_model=CustomRuleDryRunResult)
+def dry_run_custom_rules(
+    request: CustomRuleDryRunRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> CustomRuleDryRunResult:
+    """Evaluate saved or unsaved rules against a repository's clone without recording hits."""
+    service = CustomRuleService(db, tenant_id)
+    try:
+        service.get_repository(request.repository_id)
+        for rule_id in request.rule_ids or []:
+            service.get_rule(rule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.dry_run(
+            request.repository_id,
+            definitions=[rule.model_dump() for rule in request.rules or []],
+            rule_ids=request.rule_ids,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return CustomRuleDryRunResult(**result)
+
+
+@router.get("/stats", response_model=list[CustomRuleStats])
+def get_custom_rule_stats(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[CustomRuleStats]:
+    """Get every rule's hits across the scans it was evaluated in."""
+    return [CustomRuleStats(**row) for row in CustomRuleService(db, tenant_id).stats()]
+
+
+@router.get("/{rule_id}", response_model=CustomRuleResponse)
+def get_custom_rule(
+    rule_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> CustomRuleResponse:
+    """Get a custom rule."""
+    try:
+        rule = CustomRuleService(db, tenant_id).get_rule(rule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return CustomRuleResponse.model_validate(rule)
+
+
+@router.put("/{rule_id}", response_model=CustomRuleResponse)
+def update_custom_rule(
+    rule_id: int,
+    request: CustomRuleUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> CustomRuleResponse:
+    """Change a custom rule; a changed definition is recorded as a new version."""
+    service = CustomRuleService(db, tenant_id)
+    try:
+        service.get_rule(rule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        rule = service.update_rule(rule_id, request.model_dump(), changed_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return CustomRuleResponse.model_validate(rule)
+
+
+@router.delete("/{rule_id}", status_code=204)
+def delete_custom_rule(
+    rule_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Delete a custom rule with its versions and hits."""
+    try:
+        CustomRuleService(db, tenant_id).delete_rule(rule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/{rule_id}/versions", response_model=list[CustomRuleVersionResponse])
+def list_custom_rule_versions(
+    rule_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[CustomRuleVersionResponse]:
+    """List a custom rule's versions, newest first."""
+    try:
+        versions = CustomRuleService(db, tenant_id).versions(rule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [CustomRuleVersionResponse.model_validate(v) for v in versions]
+
+
+@router.post("/{rule_id}/versions/{version}/restore", response_model=CustomRuleResponse)
+def restore_custom_rule_version(
+    rule_id: int,
+    version: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> CustomRuleResponse:
+    """Make an earlier version's definition current, recorded as a new version."""
+    service = CustomRuleService(db, tenant_id)
+    try:
+        service.get_version(rule_id, version)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        rule = service.restore(rule_id, version, changed_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return CustomRuleResponse.model_validate(rule)
+
+
+@router.get("/{rule_id}/hits", response_model=list[CustomRuleHitResponse])
+def list_custom_rule_hits(
+    rule_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    limit: int = Query(20, ge=1, le=100),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[CustomRuleHitResponse]:
+    """List a custom rule's hits recorded by scans, newest first."""
+    try:
+        hits = CustomRuleService(db, tenant_id).hits(rule_id, limit)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [CustomRuleHitResponse.model_validate(h) for h in hits]
@@ -0,0 +1,119 @@
+"""API endpoints for configurable dashboard widgets."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.dashboard import (
+    DashboardWidgetCreate,
+    DashboardWidgetResponse,
+    DashboardWidgetUpdate,
+    MetricDefinitionResponse,
+    WidgetData,
+)
+from app.services.dashboard_service import DashboardService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/metrics", response_model=list[MetricDefinitionResponse])
+def list_metrics() -> list[MetricDefinitionResponse]:
+    """List the metrics widgets can display, with their chart types and filters."""
+    return [MetricDefinitionResponse(**m) for m in DashboardService.metric_catalog()]
+
+
+@router.get("/widgets", response_model=list[DashboardWidgetResponse])
+def list_widgets(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> list[DashboardWidgetResponse]:
+    """List workspace-wide widgets and the current user's own widgets."""
+    service = DashboardService(db, tenant_id)
+    return [DashboardWidgetResponse.model_validate(w) for w in service.list_widgets(user_email)]
+
+
+@router.post("/widgets", response_model=DashboardWidgetResponse)
+def create_widget(
+    request: DashboardWidgetCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> DashboardWidgetResponse:
+    """Add a widget to the current user's dashboard, or to the workspace's if shared."""
+    service = DashboardService(db, tenant_id)
+    fields = request.model_dump(exclude={"shared"})
+    try:
+        widget = service.create_widget(**fields, owner_email=None if request.shared else user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return DashboardWidgetResponse.model_validate(widget)
+
+
+@router.put("/widgets/{widget_id}", response_model=DashboardWidgetResponse)
+def update_widget(
+    widget_id: int,
+    request: DashboardWidgetUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> DashboardWidgetResponse:
+    """Change a widget's metric, chart type, scope, or position."""
+    service = DashboardService(db, tenant_id)
+    try:
+        widget = service.get_widget(widget_id, user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        widget = service.update_widget(widget, **request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return DashboardWidgetResponse.model_validate(widget)
+
+
+@router.delete("/widgets/{widget_id}")
+def delete_widget(
+    widget_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> dict:
+    """Remove a widget."""
+    service = DashboardService(db, tenant_id)
+    try:
+        widget = service.get_widget(widget_id, user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    service.delete_widget(widget)
+    return {"status": "success", "message": "Widget deleted"}
+
+
+@router.get("/widgets/{widget_id}/data", response_model=WidgetData)
+def get_widget_data(
+    widget_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> WidgetData:
+    """Get one widget's pre-aggregated data."""
+    service = DashboardService(db, tenant_id)
+    try:
+        widget = service.get_widget(widget_id, user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return WidgetData(**service.widget_data(widget))
+
+
+@router.get("/data", response_model=list[WidgetData])
+def get_dashboard_data(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> list[WidgetData]:
+    """Get pre-aggregated data for every widget on the current user's dashboard."""
+    service = DashboardService(db, tenant_id)
+    return [WidgetData(**d) for d in service.dashboard_data(user_email)]
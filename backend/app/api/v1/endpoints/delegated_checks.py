@@ -0,0 +1,40 @@
+"""API endpoints for checks handlers delegate to helper functions."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.delegated_check import DelegatedCheckResult
+from app.services.delegated_check_service import MAX_DEPTH, DelegatedCheckService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=DelegatedCheckResult)
+def attribute_delegated_checks(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository whose handlers to follow"),
+    depth: int | None = Query(None, ge=1, le=MAX_DEPTH, description="Call levels to follow; defaults to INTERPROCEDURAL_DEPTH"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> DelegatedCheckResult:
+    """Attribute role checks and conditions enforced in helpers a handler calls to its policy.
+
+    Follows calls such as ensureManager(user) or if !canApprove(user) from
+    each policy's handlers into the helpers they resolve to. Runs
+    automatically after each repository scan at the configured depth; call
+    it directly to follow calls deeper.
+    """
+    service = DelegatedCheckService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.attribute_checks(repository_id, depth)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return DelegatedCheckResult(**result)
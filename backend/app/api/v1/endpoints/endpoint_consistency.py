@@ -0,0 +1,37 @@
+"""API endpoints for endpoints of one resource authorized inconsistently."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.endpoint_consistency import EndpointConsistencyReport
+from app.services.endpoint_consistency_service import EndpointConsistencyService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=EndpointConsistencyReport)
+def list_endpoint_inconsistencies(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    severity: str | None = Query(None, description="Only inconsistencies of this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> EndpointConsistencyReport:
+    """Find endpoints of one resource that are authorized inconsistently.
+
+    Endpoints are grouped by resource (/api/expenses and /api/expenses/{id})
+    and every pair of methods is compared: a write open to anyone while a
+    sibling requires a role, writes requiring different roles (DELETE requires
+    ADMIN while PUT requires only MANAGER), or a role that may write what it
+    may not read. Pairs whose rules document the difference, in an approval
+    comment or a valid policy annotation, are counted, not reported.
+    """
+    try:
+        result = EndpointConsistencyService(db, tenant_id).inconsistencies(repository_id, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return EndpointConsistencyReport(**result)
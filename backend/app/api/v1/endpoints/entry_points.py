@@ -0,0 +1,36 @@
+"""API endpoints for non-HTTP entry point inventories."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.entry_point import ServiceEntryPoints
+from app.services.entry_point_service import EntryPointService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/services", response_model=list[ServiceEntryPoints])
+def list_service_entry_points(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    unchecked_only: bool = Query(False, description="Only services with privileged entry points that check nothing"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ServiceEntryPoints]:
+    """Get the non-HTTP entry point inventory per service.
+
+    Message consumers, scheduled jobs, background tasks, and CLI commands
+    are read from each repository's clone with the role and identity checks
+    their handlers perform and the privileged operations they carry out.
+    CLI commands get their own section; privileged entry points that check
+    nothing are listed first.
+    """
+    try:
+        services = EntryPointService(db, tenant_id).services(repository_id, unchecked_only)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ServiceEntryPoints(**s) for s in services]
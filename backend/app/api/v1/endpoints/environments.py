@@ -0,0 +1,74 @@
+"""API endpoints for scan environments and cross-environment comparison."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.scan_environment import (
+    EnvironmentComparison,
+    ScanEnvironmentResponse,
+    ScanEnvironmentSummary,
+    ScanEnvironmentTag,
+)
+from app.services.environment_comparison_service import EnvironmentComparisonService, normalize_environment
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.put("/scans/{scan_id}", response_model=ScanEnvironmentResponse)
+def tag_scan_environment(
+    scan_id: int,
+    request: ScanEnvironmentTag,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> ScanEnvironmentResponse:
+    """Tag a repository's latest scan with an environment, replacing any detected tag."""
+    service = EnvironmentComparisonService(db, tenant_id)
+    try:
+        tag = service.tag_scan(scan_id, request.environment, ref=request.ref, tagged_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ScanEnvironmentResponse.model_validate(tag)
+
+
+@router.get("/repositories/{repository_id}", response_model=list[ScanEnvironmentResponse])
+def list_repository_environments(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ScanEnvironmentResponse]:
+    """List the latest tagged scan of each environment of a repository."""
+    try:
+        tags = EnvironmentComparisonService(db, tenant_id).environments(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ScanEnvironmentResponse.model_validate(t) for t in tags]
+
+
+@router.get("/repositories/{repository_id}/compare", response_model=EnvironmentComparison)
+def compare_environments(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    base: str = Query("staging", description="Environment the target should be at least as strict as"),
+    target: str = Query("prod", description="Environment checked for relaxations"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> EnvironmentComparison:
+    """Compare two environments and flag where the target relaxes authorization."""
+    if normalize_environment(base) == normalize_environment(target):
+        raise HTTPException(status_code=400, detail="Compare two different environments")
+    try:
+        comparison = EnvironmentComparisonService(db, tenant_id).compare(repository_id, base, target)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return EnvironmentComparison(
+        **{
+            **comparison,
+            "base": ScanEnvironmentSummary.model_validate(comparison["base"]),
+            "target": ScanEnvironmentSummary.model_validate(comparison["target"]),
+        }
+    )
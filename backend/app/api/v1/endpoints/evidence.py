@@ -0,0 +1,50 @@
+"""API endpoints for highlight-ready evidence regions."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.evidence_detail import EvidenceDetail, PolicyEvidenceDetail
+from app.services.evidence_detail_service import DEFAULT_CONTEXT_LINES, MAX_CONTEXT_LINES, EvidenceDetailService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/{evidence_id}", response_model=EvidenceDetail)
+def get_evidence_detail(
+    evidence_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    context_lines: int = Query(DEFAULT_CONTEXT_LINES, ge=0, le=MAX_CONTEXT_LINES, description="Lines of surrounding context"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> EvidenceDetail:
+    """Get an evidence item as numbered source lines with surrounding context."""
+    service = EvidenceDetailService(db, tenant_id)
+    try:
+        result = service.get_evidence(evidence_id, context_lines)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return EvidenceDetail(**result)
+
+
+@router.get("/policies/{policy_id}", response_model=PolicyEvidenceDetail)
+def get_policy_evidence_detail(
+    policy_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    context_lines: int = Query(DEFAULT_CONTEXT_LINES, ge=0, le=MAX_CONTEXT_LINES, description="Lines of surrounding context"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> PolicyEvidenceDetail:
+    """Get all evidence for a policy as per-file regions.
+
+    Overlapping context windows in the same file are merged into a single
+    region with several highlighted ranges.
+    """
+    service = EvidenceDetailService(db, tenant_id)
+    try:
+        result = service.get_policy_evidence(policy_id, context_lines)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return PolicyEvidenceDetail(**result)
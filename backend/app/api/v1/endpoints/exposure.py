@@ -0,0 +1,36 @@
+"""API endpoints for endpoint exposure."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.exposure import ServiceExposure
+from app.services.exposure_service import ExposureService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/services", response_model=list[ServiceExposure])
+def list_service_exposure(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    internet_only: bool = Query(False, description="Only services with internet-facing endpoints"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ServiceExposure]:
+    """Get the inferred exposure of each service's endpoints.
+
+    Ingress resources, LoadBalancer Services and their annotations, Istio
+    and Gateway API routes, API gateway definitions (Serverless, SAM, Kong),
+    and Terraform load balancers in each repository's clone decide whether
+    an endpoint is internet-facing or internal-only. Endpoints nothing
+    covers are reported as unknown; internet-facing ones are listed first.
+    """
+    try:
+        services = ExposureService(db, tenant_id).services(repository_id, internet_only)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ServiceExposure(**s) for s in services]
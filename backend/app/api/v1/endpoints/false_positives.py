@@ -0,0 +1,91 @@
+"""API endpoints for false-positive feedback and suppression."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.false_positive import (
+    AnalyzerFalsePositiveRate,
+    FalsePositiveCreate,
+    FalsePositiveMarkResponse,
+    SuppressionPatternResponse,
+    SuppressionRun,
+)
+from app.services.false_positive_service import FalsePositiveService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=FalsePositiveMarkResponse)
+def mark_false_positive(
+    request: FalsePositiveCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> FalsePositiveMarkResponse:
+    """Mark a mined rule or finding as a false positive.
+
+    Dismisses it and learns its structural pattern, so matching detections
+    by the same analyzer are suppressed in every repository of the workspace.
+    """
+    service = FalsePositiveService(db, tenant_id)
+    try:
+        mark = service.mark(request.target_type, request.target_id, request.reason, user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return FalsePositiveMarkResponse.model_validate(mark)
+
+
+@router.get("/patterns", response_model=list[SuppressionPatternResponse])
+def list_suppression_patterns(
+    db: Annotated[Session, Depends(get_db)],
+    analyzer: str | None = Query(None, description="Restrict to one analyzer"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[SuppressionPatternResponse]:
+    """List the workspace's learned false-positive patterns."""
+    service = FalsePositiveService(db, tenant_id)
+    return [SuppressionPatternResponse.model_validate(p) for p in service.patterns(analyzer)]
+
+
+@router.put("/patterns/{pattern_id}", response_model=SuppressionPatternResponse)
+def set_suppression_pattern_active(
+    pattern_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    active: bool = Query(..., description="Whether the pattern suppresses new detections"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SuppressionPatternResponse:
+    """Turn a suppression pattern on or off; already dismissed detections are not reopened."""
+    service = FalsePositiveService(db, tenant_id)
+    try:
+        pattern = service.set_active(pattern_id, active)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return SuppressionPatternResponse.model_validate(pattern)
+
+
+@router.post("/repositories/{repository_id}/suppress", response_model=SuppressionRun)
+def suppress_repository_false_positives(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SuppressionRun:
+    """Apply the suppression model to a repository's open rules and findings.
+
+    Runs after every scan; call it after analyses that produce findings
+    outside a scan, such as conflict or security gap detection.
+    """
+    suppressed = FalsePositiveService(db, tenant_id).suppress(repository_id)
+    return SuppressionRun(repository_id=repository_id, suppressed=suppressed)
+
+
+@router.get("/analytics", response_model=list[AnalyzerFalsePositiveRate])
+def get_false_positive_analytics(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[AnalyzerFalsePositiveRate]:
+    """Get false-positive rates per analyzer, highest first."""
+    return [AnalyzerFalsePositiveRate(**r) for r in FalsePositiveService(db, tenant_id).analytics()]
@@ -0,0 +1,106 @@
+"""API endpoints for findings tagged with CWE and OWASP API Top 10 categories."""
+import json
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import PlainTextResponse
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.finding import TaggedFindingReport, TaxonomyEntryResponse
+from app.services.finding_export_service import FindingExportService, to_csv, to_sarif
+from app.services.finding_taxonomy import TAXONOMY, FindingType, describe, normalize_cwe, normalize_owasp
+from app.services.redaction_service import redact_for_egress
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+def _findings(
+    db: Session,
+    tenant_id: str | None,
+    repository_id: int | None,
+    finding_type: FindingType | None,
+    cwe: str | None,
+    owasp: str | None,
+    severity: str | None,
+) -> list[dict]:
+    """Load tagged findings, surfacing bad tags as 400 and unknown repositories as 404."""
+    try:
+        cwe_id = normalize_cwe(cwe) if cwe else None
+        category = normalize_owasp(owasp) if owasp else None
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    try:
+        return FindingExportService(db, tenant_id).findings(repository_id, finding_type, cwe_id, category, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/", response_model=TaggedFindingReport)
+def list_findings(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Only findings in or touching one repository"),
+    finding_type: FindingType | None = Query(None, description="Only one finding type"),
+    cwe: str | None = Query(None, description="Only findings tagged with this CWE, e.g. CWE-863"),
+    owasp: str | None = Query(None, description="Only findings in this OWASP API Top 10 category, e.g. API1:2023 or BOLA"),
+    severity: str | None = Query(None, description="Only this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> TaggedFindingReport:
+    """List open findings from every analyzer with their CWE and OWASP API tags.
+
+    Covers conflicts, inconsistent enforcement, security gaps, hard-coded
+    secrets, credentialed wildcard CORS, exposed admin endpoints, confused
+    deputies, and known-vulnerable auth patterns, most severe first.
+    """
+    findings = _findings(db, tenant_id, repository_id, finding_type, cwe, owasp, severity)
+    return TaggedFindingReport(findings=findings, summary=FindingExportService.summary(findings))
+
+
+@router.get("/taxonomy", response_model=list[TaxonomyEntryResponse])
+def get_taxonomy(
+    finding_type: FindingType | None = Query(None, description="Only one finding type"),
+) -> list[TaxonomyEntryResponse]:
+    """List the CWE and OWASP API tags of every finding type and kind."""
+    return [TaxonomyEntryResponse(**describe(e)) for e in TAXONOMY if finding_type in (None, e.finding_type)]
+
+
+@router.get("/export/sarif", response_class=PlainTextResponse)
+def export_sarif(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Only findings in or touching one repository"),
+    finding_type: FindingType | None = Query(None, description="Only one finding type"),
+    cwe: str | None = Query(None, description="Only findings tagged with this CWE"),
+    owasp: str | None = Query(None, description="Only findings in this OWASP API Top 10 category"),
+    severity: str | None = Query(None, description="Only this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> PlainTextResponse:
+    """Download tagged findings as SARIF 2.1.0 for code scanning dashboards."""
+    findings = _findings(db, tenant_id, repository_id, finding_type, cwe, owasp, severity)
+    content = json.dumps(to_sarif(findings), indent=2)
+    return PlainTextResponse(
+        content=redact_for_egress(content, "export"),
+        media_type="application/sarif+json",
+        headers={"Content-Disposition": 'attachment; filename="findings.sarif"'},
+    )
+
+
+@router.get("/export/csv", response_class=PlainTextResponse)
+def export_csv(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Only findings in or touching one repository"),
+    finding_type: FindingType | None = Query(None, description="Only one finding type"),
+    cwe: str | None = Query(None, description="Only findings tagged with this CWE"),
+    owasp: str | None = Query(None, description="Only findings in this OWASP API Top 10 category"),
+    severity: str | None = Query(None, description="Only this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> PlainTextResponse:
+    """Download tagged findings as CSV, one row per finding."""
+    findings = _findings(db, tenant_id, repository_id, finding_type, cwe, owasp, severity)
+    return PlainTextResponse(
+        content=redact_for_egress(to_csv(findings), "export"),
+        media_type="text/csv",
+        headers={"Content-Disposition": 'attachment; filename="findings.csv"'},
+    )
@@ -0,0 +1,37 @@
+"""API endpoints for framework route configuration scans."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.framework_route import FrameworkRouteScanResult
+from app.services.framework_route_service import FrameworkRouteService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=FrameworkRouteScanResult)
+def scan_framework_routes(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository whose clone contains the application"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> FrameworkRouteScanResult:
+    """Mine route auth from Hapi, Koa, Micronaut, Quarkus, Play, and realtime endpoints.
+
+    Runs automatically after each repository scan; call it directly to
+    refresh route findings without a full rescan.
+    """
+    service = FrameworkRouteService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.scan_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return FrameworkRouteScanResult(**result)
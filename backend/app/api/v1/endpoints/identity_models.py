@@ -0,0 +1,59 @@
+"""API endpoints for the workspace identity model."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.identity_model import IdentityMappingReport, IdentityModelResponse, IdentityModelUpdate
+from app.services.identity_model_service import IdentityModelService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=IdentityModelResponse)
+def get_identity_model(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdentityModelResponse:
+    """Get the workspace's identity model, or the default one if none is declared."""
+    return IdentityModelResponse(**IdentityModelService(db, tenant_id).get_model())
+
+
+@router.put("/", response_model=IdentityModelResponse)
+def set_identity_model(
+    request: IdentityModelUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> IdentityModelResponse:
+    """Declare the workspace's subject types and the attributes each carries."""
+    service = IdentityModelService(db, tenant_id)
+    try:
+        model = service.set_model(request.model_dump()["subject_types"], updated_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return IdentityModelResponse(**model)
+
+
+@router.get("/mapping", response_model=IdentityMappingReport)
+def get_identity_mapping(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Only this repository"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdentityMappingReport:
+    """Map mined conditions onto declared attributes and list undeclared subject attributes."""
+    return IdentityMappingReport(**IdentityModelService(db, tenant_id).map_conditions(repository_id))
+
+
+@router.post("/mapping/apply", response_model=IdentityMappingReport)
+def apply_identity_mapping(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Only this repository"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdentityMappingReport:
+    """Rewrite mined conditions onto declared attribute names so exports use them."""
+    return IdentityMappingReport(**IdentityModelService(db, tenant_id).apply_mapping(repository_id))
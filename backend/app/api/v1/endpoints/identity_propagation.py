@@ -0,0 +1,36 @@
+"""API endpoints for end-user identity propagation across service calls."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.identity_propagation import IdentityPropagation
+from app.services.identity_propagation_service import IdentityPropagationService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=IdentityPropagation)
+def get_identity_propagation(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Only hops made by or to one repository"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdentityPropagation:
+    """Trace whether the end user's identity survives the tenant's internal calls.
+
+    Each hop is classified by whose identity the callee sees: the user's
+    forwarded token, the calling service's own credentials, a token relayed
+    after an upstream service already replaced the user's, or none. Hops
+    where a service identity reaches a role- or condition-checked endpoint
+    with request input the caller never authorizes are listed as confused
+    deputies, most severe first.
+    """
+    try:
+        result = IdentityPropagationService(db, tenant_id).analyze(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return IdentityPropagation(**result)
@@ -0,0 +1,106 @@
+"""API endpoints for IdP role sync and role drift detection."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import AirGapViolation
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.idp_connector import (
+    IdpConnectorCreate,
+    IdpConnectorResponse,
+    IdpRoleSyncResult,
+    RoleDriftResponse,
+    RoleDriftStatusUpdate,
+)
+from app.services.idp_role_sync_service import IdpRoleSyncService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=IdpConnectorResponse, status_code=201)
+def create_connector(
+    request: IdpConnectorCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdpConnectorResponse:
+    """Register a Keycloak or Auth0 connector for periodic role sync."""
+    try:
+        connector = IdpRoleSyncService(db, tenant_id).create_connector(request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return IdpConnectorResponse.model_validate(connector)
+
+
+@router.get("/", response_model=list[IdpConnectorResponse])
+def list_connectors(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[IdpConnectorResponse]:
+    """List connectors with their last sync status."""
+    return [IdpConnectorResponse.model_validate(c) for c in IdpRoleSyncService(db, tenant_id).list_connectors()]
+
+
+@router.delete("/{connector_id}", status_code=204)
+def delete_connector(
+    connector_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Delete a connector and its drift findings."""
+    try:
+        IdpRoleSyncService(db, tenant_id).delete_connector(connector_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.post("/{connector_id}/sync", response_model=IdpRoleSyncResult)
+def sync_connector(
+    connector_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdpRoleSyncResult:
+    """Pull the connector's roles now and reconcile drift findings."""
+    service = IdpRoleSyncService(db, tenant_id)
+    try:
+        service.get_connector(connector_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.sync_connector(connector_id)
+    except (ValueError, AirGapViolation) as e:
+        raise HTTPException(status_code=502, detail=str(e)) from e
+    return IdpRoleSyncResult(**result)
+
+
+@router.get("/drift", response_model=list[RoleDriftResponse])
+def list_role_drift(
+    db: Annotated[Session, Depends(get_db)],
+    connector_id: int | None = Query(None),
+    status: str | None = Query("open", description="open, resolved, or dismissed; empty for all"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[RoleDriftResponse]:
+    """List roles that exist in the IdP but not in code, or vice versa."""
+    try:
+        drifts = IdpRoleSyncService(db, tenant_id).list_drifts(connector_id, status or None)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return [RoleDriftResponse(**d) for d in drifts]
+
+
+@router.patch("/drift/{drift_id}", response_model=RoleDriftResponse)
+def update_role_drift(
+    drift_id: int,
+    request: RoleDriftStatusUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> RoleDriftResponse:
+    """Dismiss an accepted drift finding, or reopen a dismissed one."""
+    try:
+        drift = IdpRoleSyncService(db, tenant_id).set_drift_status(drift_id, request.status)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return RoleDriftResponse(**drift)
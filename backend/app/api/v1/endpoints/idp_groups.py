@@ -0,0 +1,100 @@
+"""API endpoints for IdP group ingestion and endpoint population analysis."""
+from typing import Annotated, Literal
+
+import structlog
+from fastapi import APIRouter, Depends, File, HTTPException, Query, UploadFile
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import AirGapViolation
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.idp_group import (
+    EndpointPopulationReport,
+    GroupRoleMappingRequest,
+    IdpGroupIngestResult,
+    IdpGroupResponse,
+    IdpGroupSyncRequest,
+)
+from app.services.idp_group_service import IdpGroupService, parse_export
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/import", response_model=IdpGroupIngestResult)
+def import_groups(
+    db: Annotated[Session, Depends(get_db)],
+    file: UploadFile = File(..., description="Okta or Entra JSON export, or an LDIF directory export"),
+    source: Literal["okta", "entra", "ldap"] = Query(..., description="Identity provider the export came from"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdpGroupIngestResult:
+    """Import group memberships from an IdP export.
+
+    Replaces the source's previously imported groups and re-expands
+    group-to-role mappings into role assignments.
+    """
+    try:
+        groups = parse_export(source, file.file.read().decode("utf-8", errors="replace"))
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return IdpGroupIngestResult(**IdpGroupService(db, tenant_id).ingest_groups(source, groups))
+
+
+@router.post("/sync", response_model=IdpGroupIngestResult)
+def sync_groups(
+    request: IdpGroupSyncRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdpGroupIngestResult:
+    """Pull groups and members live from the Okta or Microsoft Graph API."""
+    service = IdpGroupService(db, tenant_id)
+    try:
+        groups = service.fetch_groups(request.source, request.token, request.base_url)
+    except (ValueError, AirGapViolation) as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return IdpGroupIngestResult(**service.ingest_groups(request.source, groups))
+
+
+@router.put("/mappings", response_model=IdpGroupIngestResult)
+def set_group_role_mappings(
+    request: GroupRoleMappingRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IdpGroupIngestResult:
+    """Map IdP groups to code-level roles.
+
+    Groups without an explicit mapping are matched by name against mined
+    roles ("expenses-admins" grants ADMIN).
+    """
+    service = IdpGroupService(db, tenant_id)
+    result = service.set_mappings([m.model_dump() for m in request.mappings], replace=request.replace)
+    return IdpGroupIngestResult(**result)
+
+
+@router.get("/", response_model=list[IdpGroupResponse])
+def list_groups(
+    db: Annotated[Session, Depends(get_db)],
+    source: str | None = Query(None, description="Filter by identity provider"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[IdpGroupResponse]:
+    """List ingested groups with their member counts and the roles they grant."""
+    return [IdpGroupResponse(**g) for g in IdpGroupService(db, tenant_id).list_groups(source)]
+
+
+@router.get("/population", response_model=EndpointPopulationReport)
+def endpoint_population(
+    db: Annotated[Session, Depends(get_db)],
+    method: str = Query(..., description="HTTP method (e.g., DELETE)"),
+    path: str = Query(..., description="Route template or concrete path (e.g., /api/expenses/42)"),
+    repository_id: int | None = Query(None),
+    application_id: int | None = Query(None),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> EndpointPopulationReport:
+    """Answer "how many humans can call this endpoint?".
+
+    Joins the endpoint's mined roles with the users holding them through
+    IdP groups or uploaded assignments.
+    """
+    service = IdpGroupService(db, tenant_id)
+    result = service.endpoint_population(method, path, repository_id, application_id)
+    return EndpointPopulationReport(**result)
@@ -0,0 +1,57 @@
+"""API endpoints for scanning container images."""
+import tempfile
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, File, HTTPException, Query, UploadFile
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.image_scan import ImageScanResult
+from app.services.image_scan_service import ImageScanService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+# Uploads are copied in chunks of this size so oversized archives are cut off early
+UPLOAD_CHUNK_BYTES = 1024 * 1024
+
+
+@router.post("/", response_model=ImageScanResult)
+def scan_image(
+    db: Annotated[Session, Depends(get_db)],
+    file: UploadFile = File(..., description="Image archive from `docker save` or an OCI layout tarball"),
+    repository_id: int = Query(..., description="Source repository of the service the image runs"),
+    image_name: str | None = Query(None, description="Image reference (defaults to the archive's tag)"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ImageScanResult:
+    """Mine authorization config baked into a container image.
+
+    OPA bundles, Casbin policies, nginx auth directives, and security toggles
+    in the image environment are merged into the repository's policies.
+    Rescanning the same image replaces its earlier findings.
+    """
+    service = ImageScanService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+    max_bytes = settings.IMAGE_SCAN_MAX_SIZE_MB * 1024 * 1024
+    with tempfile.NamedTemporaryFile(prefix="policy_miner_image_", suffix=".tar") as archive:
+        copied = 0
+        while chunk := file.file.read(UPLOAD_CHUNK_BYTES):
+            copied += len(chunk)
+            if copied > max_bytes:
+                raise HTTPException(
+                    status_code=413, detail=f"Image archive exceeds {settings.IMAGE_SCAN_MAX_SIZE_MB} MB"
+                )
+            archive.write(chunk)
+        archive.flush()
+        try:
+            result = service.scan_image(repository_id, archive.name, image_name)
+        except ValueError as e:
+            raise HTTPException(status_code=400, detail=str(e)) from e
+    return ImageScanResult(**result)
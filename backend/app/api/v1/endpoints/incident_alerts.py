@@ -0,0 +1,81 @@
+"""API endpoints for paging on auth regressions through PagerDuty and Opsgenie."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.incident_alert import (
+    AuthRegressionResponse,
+    IncidentConnectorCreate,
+    IncidentConnectorResponse,
+    IncidentDeliveryResult,
+)
+from app.services.regression_alert_service import RegressionAlertService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/connectors", response_model=IncidentConnectorResponse, status_code=201)
+def create_connector(
+    request: IncidentConnectorCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IncidentConnectorResponse:
+    """Register a PagerDuty or Opsgenie connector paged when a scan finds an auth regression."""
+    connector = RegressionAlertService(db, tenant_id).create_connector(request.model_dump())
+    return IncidentConnectorResponse.model_validate(connector)
+
+
+@router.get("/connectors", response_model=list[IncidentConnectorResponse])
+def list_connectors(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[IncidentConnectorResponse]:
+    """List connectors with their last delivery status."""
+    return [IncidentConnectorResponse.model_validate(c) for c in RegressionAlertService(db, tenant_id).list_connectors()]
+
+
+@router.delete("/connectors/{connector_id}", status_code=204)
+def delete_connector(
+    connector_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Delete a connector and its incident links; open incidents stay open in the provider."""
+    try:
+        RegressionAlertService(db, tenant_id).delete_connector(connector_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/regressions", response_model=list[AuthRegressionResponse])
+def list_regressions(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None),
+    status: str | None = Query(None, description="open or resolved; empty for all"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[AuthRegressionResponse]:
+    """List internet-facing routes that lost their authentication, with their incidents."""
+    try:
+        regressions = RegressionAlertService(db, tenant_id).list_regressions(repository_id, status or None)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return [AuthRegressionResponse(**r) for r in regressions]
+
+
+@router.post("/regressions/{regression_id}/deliver", response_model=IncidentDeliveryResult)
+def deliver_regression(
+    regression_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> IncidentDeliveryResult:
+    """Retry a regression's incidents that failed to trigger or resolve."""
+    try:
+        result = RegressionAlertService(db, tenant_id).redeliver(regression_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return IncidentDeliveryResult(**result)
@@ -0,0 +1,86 @@
+"""API endpoints for exporting mined policies as Istio AuthorizationPolicies."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.istio_export import IstioExportResponse
+from app.services.istio_export_service import IstioExportService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+# Kubernetes namespace and label key syntax
+NAMESPACE_PATTERN = r"^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+LABEL_PATTERN = r"^([a-z0-9.-]+/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$"
+
+
+def export(
+    repository_id: int,
+    db: Session,
+    tenant_id: str | None,
+    namespace: str,
+    role_claim: str,
+    selector_label: str,
+    dry_run: bool,
+    min_confidence: float | None,
+    include_pending: bool,
+) -> dict:
+    """Run an export, raising 404 when the repository does not exist."""
+    try:
+        return IstioExportService(db, tenant_id).export(
+            repository_id, namespace, role_claim, selector_label, dry_run, min_confidence, include_pending
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/repositories/{repository_id}", response_model=IstioExportResponse)
+def export_repository_istio(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    namespace: Annotated[str, Query(max_length=63, pattern=NAMESPACE_PATTERN)] = "default",
+    role_claim: Annotated[str, Query(min_length=1, max_length=100)] = "roles",
+    selector_label: Annotated[str, Query(max_length=253, pattern=LABEL_PATTERN)] = "app",
+    dry_run: bool = False,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> IstioExportResponse:
+    """Export a repository's mined policies as Istio AuthorizationPolicies, one per service.
+
+    Each service's pods are selected by selector_label and callers' roles read
+    from the role_claim JWT claim. Only approved policies are exported unless
+    include_pending is set; policies scored below min_confidence are left out.
+    """
+    result = export(
+        repository_id, db, tenant_id, namespace, role_claim, selector_label, dry_run, min_confidence, include_pending
+    )
+    return IstioExportResponse(**result)
+
+
+@router.get("/repositories/{repository_id}/manifests")
+def download_repository_istio(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    namespace: Annotated[str, Query(max_length=63, pattern=NAMESPACE_PATTERN)] = "default",
+    role_claim: Annotated[str, Query(min_length=1, max_length=100)] = "roles",
+    selector_label: Annotated[str, Query(max_length=253, pattern=LABEL_PATTERN)] = "app",
+    dry_run: bool = False,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> Response:
+    """Download a repository's AuthorizationPolicies as YAML for kubectl apply."""
+    result = export(
+        repository_id, db, tenant_id, namespace, role_claim, selector_label, dry_run, min_confidence, include_pending
+    )
+    return Response(
+        content=result["manifests"],
+        media_type="application/yaml",
+        headers={"Content-Disposition": f'attachment; filename="repository-{repository_id}-istio.yaml"'},
+    )
@@ -0,0 +1,90 @@
+"""API endpoints for ITSM connectors and finding tickets."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import AirGapViolation
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.itsm_connector import (
+    FindingTicketResponse,
+    ItsmConnectorCreate,
+    ItsmConnectorResponse,
+    ItsmSyncResult,
+)
+from app.services.itsm_sync_service import ItsmSyncService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=ItsmConnectorResponse, status_code=201)
+def create_connector(
+    request: ItsmConnectorCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ItsmConnectorResponse:
+    """Register a ServiceNow or generic ITSM connector for periodic ticket sync."""
+    try:
+        connector = ItsmSyncService(db, tenant_id).create_connector(request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return ItsmConnectorResponse.model_validate(connector)
+
+
+@router.get("/", response_model=list[ItsmConnectorResponse])
+def list_connectors(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ItsmConnectorResponse]:
+    """List connectors with their last sync status."""
+    return [ItsmConnectorResponse.model_validate(c) for c in ItsmSyncService(db, tenant_id).list_connectors()]
+
+
+@router.delete("/{connector_id}", status_code=204)
+def delete_connector(
+    connector_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Delete a connector and its ticket links; the tickets stay in the ITSM."""
+    try:
+        ItsmSyncService(db, tenant_id).delete_connector(connector_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.post("/{connector_id}/sync", response_model=ItsmSyncResult)
+def sync_connector(
+    connector_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ItsmSyncResult:
+    """Sync the connector's tickets with the currently detected findings now."""
+    service = ItsmSyncService(db, tenant_id)
+    try:
+        service.get_connector(connector_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.sync_connector(connector_id)
+    except (ValueError, AirGapViolation) as e:
+        raise HTTPException(status_code=502, detail=str(e)) from e
+    return ItsmSyncResult(**result)
+
+
+@router.get("/tickets", response_model=list[FindingTicketResponse])
+def list_tickets(
+    db: Annotated[Session, Depends(get_db)],
+    connector_id: int | None = Query(None),
+    status: str | None = Query(None, description="open, risk_accepted, or resolved; empty for all"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[FindingTicketResponse]:
+    """List the tickets tracking findings, with each finding's state."""
+    try:
+        tickets = ItsmSyncService(db, tenant_id).list_tickets(connector_id, status or None)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return [FindingTicketResponse(**t) for t in tickets]
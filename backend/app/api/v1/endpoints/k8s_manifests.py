@@ -0,0 +1,37 @@
+"""API endpoints for Helm chart and Kubernetes manifest scans."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.k8s_manifest import K8sManifestScanResult
+from app.services.k8s_manifest_service import K8sManifestService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=K8sManifestScanResult)
+def scan_manifests(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository whose clone contains the manifests"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> K8sManifestScanResult:
+    """Mine ingress auth, OPA sidecars, and service-account RBAC from manifests.
+
+    Runs automatically after each repository scan; call it directly to
+    refresh manifest findings without a full rescan.
+    """
+    service = K8sManifestService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.scan_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return K8sManifestScanResult(**result)
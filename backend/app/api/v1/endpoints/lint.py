@@ -0,0 +1,117 @@
+"""API endpoints for organizational policy lint rules."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query, Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.lint import LintReport, LintRuleCreate, LintRuleResponse, LintRuleUpdate, LintRunResponse
+from app.services.policy_lint_service import PolicyLintService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/rules", response_model=list[LintRuleResponse])
+def list_lint_rules(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[LintRuleResponse]:
+    """List the workspace's lint rules; scans use the default rules while there are none."""
+    return [LintRuleResponse.model_validate(r) for r in PolicyLintService(db, tenant_id).list_rules()]
+
+
+@router.post("/rules", response_model=LintRuleResponse, status_code=201)
+def create_lint_rule(
+    request: LintRuleCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> LintRuleResponse:
+    """Define a lint rule evaluated against every scan of the workspace."""
+    service = PolicyLintService(db, tenant_id)
+    try:
+        rule = service.create_rule(**request.model_dump(), created_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return LintRuleResponse.model_validate(rule)
+
+
+@router.put("/rules/{rule_id}", response_model=LintRuleResponse)
+def update_lint_rule(
+    rule_id: int,
+    request: LintRuleUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> LintRuleResponse:
+    """Change a lint rule's scope, severity, or switches."""
+    service = PolicyLintService(db, tenant_id)
+    try:
+        service.get_rule(rule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        rule = service.update_rule(rule_id, **request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return LintRuleResponse.model_validate(rule)
+
+
+@router.delete("/rules/{rule_id}", status_code=204)
+def delete_lint_rule(
+    rule_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Delete a lint rule."""
+    try:
+        PolicyLintService(db, tenant_id).delete_rule(rule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/repositories/{repository_id}", response_model=LintReport)
+def lint_repository(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    fail_on: str = Query("high", description="Lowest severity of a blocking violation that fails the gate"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> LintReport:
+    """Lint a repository's current policies against the workspace's rules."""
+    try:
+        report = PolicyLintService(db, tenant_id).lint(repository_id, fail_on)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return LintReport(**report)
+
+
+@router.get("/repositories/{repository_id}/gate", response_model=LintReport)
+def lint_gate(
+    repository_id: int,
+    response: Response,
+    db: Annotated[Session, Depends(get_db)],
+    fail_on: str = Query("high", description="Lowest severity of a blocking violation that fails the gate"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> LintReport:
+    """CI gate: responds 422 with the lint report when a blocking violation reaches fail_on."""
+    try:
+        report = PolicyLintService(db, tenant_id).lint(repository_id, fail_on)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    if not report["passed"]:
+        response.status_code = 422
+        logger.info("lint_gate_failed", repository_id=repository_id, blocking=report["blocking_violations"])
+    return LintReport(**report)
+
+
+@router.get("/repositories/{repository_id}/runs", response_model=list[LintRunResponse])
+def list_lint_runs(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    limit: int = Query(20, ge=1, le=100),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[LintRunResponse]:
+    """List lint results stored by the repository's scans, newest first."""
+    return [LintRunResponse.model_validate(r) for r in PolicyLintService(db, tenant_id).runs(repository_id, limit)]
@@ -0,0 +1,32 @@
+"""API endpoints for discovering endpoints on running services."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.live_discovery import LiveDiscoveryReport, LiveDiscoveryRequest
+from app.services.live_discovery_service import LiveDiscoveryService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=LiveDiscoveryReport)
+def discover_live_endpoints(
+    request: LiveDiscoveryRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> LiveDiscoveryReport:
+    """Enumerate live gRPC methods and REST routes and reconcile them with mined routes.
+
+    Endpoints with status "never_seen" are deployed but were missed by the
+    static scan entirely.
+    """
+    try:
+        result = LiveDiscoveryService(db, tenant_id).discover(**request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return LiveDiscoveryReport(**result)
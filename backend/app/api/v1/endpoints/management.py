@@ -0,0 +1,284 @@
+"""Management API for configuring workspaces, repositories, webhooks, and scan schedules as code.
+
+Unlike the rest of the API, every endpoint here requires a signed-in user:
+repositories, webhooks, and schedules belong to the user's workspace, and
+workspaces can only be managed by superusers.
+"""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import require_auth
+from app.models.tenant import Tenant
+from app.models.user import User
+from app.schemas.management import (
+    ManagedRepositoryCreate,
+    ManagedRepositoryResponse,
+    ManagedRepositoryUpdate,
+    ScanScheduleCreate,
+    ScanScheduleResponse,
+    ScanScheduleUpdate,
+    WebhookConfigResponse,
+    WebhookConfigUpdate,
+    WorkspaceCreate,
+    WorkspaceResponse,
+    WorkspaceUpdate,
+)
+from app.services.management_service import ManagementService
+from app.services.scan_schedule_service import ScanScheduleService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+def require_superuser(user: Annotated[User, Depends(require_auth)]) -> User:
+    """Require a superuser - raises 403 for other users."""
+    if not user.is_superuser:
+        raise HTTPException(status_code=403, detail="Managing workspaces requires a superuser")
+    return user
+
+
+def workspace_response(tenant: Tenant) -> WorkspaceResponse:
+    """Workspace of a tenant."""
+    return WorkspaceResponse(
+        workspace_id=tenant.tenant_id, name=tenant.name, description=tenant.description, created_at=tenant.created_at
+    )
+
+
+@router.get("/workspaces", response_model=list[WorkspaceResponse])
+def list_workspaces(
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_superuser)],
+) -> list[WorkspaceResponse]:
+    """List active workspaces."""
+    return [workspace_response(t) for t in ManagementService(db).list_workspaces()]
+
+
+@router.post("/workspaces", response_model=WorkspaceResponse, status_code=201)
+def create_workspace(
+    request: WorkspaceCreate,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_superuser)],
+) -> WorkspaceResponse:
+    """Create a workspace, or reactivate a deleted one with the same ID."""
+    try:
+        tenant = ManagementService(db).create_workspace(request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=409, detail=str(e)) from e
+    return workspace_response(tenant)
+
+
+@router.get("/workspaces/{workspace_id}", response_model=WorkspaceResponse)
+def get_workspace(
+    workspace_id: str,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_superuser)],
+) -> WorkspaceResponse:
+    """Get a workspace."""
+    try:
+        return workspace_response(ManagementService(db).get_workspace(workspace_id))
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.put("/workspaces/{workspace_id}", response_model=WorkspaceResponse)
+def update_workspace(
+    workspace_id: str,
+    request: WorkspaceUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_superuser)],
+) -> WorkspaceResponse:
+    """Rename or redescribe a workspace."""
+    try:
+        return workspace_response(ManagementService(db).update_workspace(workspace_id, request.model_dump()))
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.delete("/workspaces/{workspace_id}", status_code=204)
+def delete_workspace(
+    workspace_id: str,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_superuser)],
+) -> None:
+    """Deactivate a workspace; its data is kept."""
+    try:
+        ManagementService(db).delete_workspace(workspace_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/repositories", response_model=list[ManagedRepositoryResponse])
+def list_repositories(
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> list[ManagedRepositoryResponse]:
+    """List the workspace's repositories."""
+    service = ManagementService(db, user.tenant_id)
+    return [ManagedRepositoryResponse.model_validate(r) for r in service.list_repositories()]
+
+
+@router.post("/repositories", response_model=ManagedRepositoryResponse, status_code=201)
+def create_repository(
+    request: ManagedRepositoryCreate,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> ManagedRepositoryResponse:
+    """Register a repository in the workspace."""
+    repository = ManagementService(db, user.tenant_id).create_repository(request.model_dump())
+    return ManagedRepositoryResponse.model_validate(repository)
+
+
+@router.get("/repositories/{repository_id}", response_model=ManagedRepositoryResponse)
+def get_repository(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> ManagedRepositoryResponse:
+    """Get a repository's registration."""
+    try:
+        repository = ManagementService(db, user.tenant_id).get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ManagedRepositoryResponse.model_validate(repository)
+
+
+@router.put("/repositories/{repository_id}", response_model=ManagedRepositoryResponse)
+def update_repository(
+    repository_id: int,
+    request: ManagedRepositoryUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> ManagedRepositoryResponse:
+    """Change a repository's registration."""
+    try:
+        repository = ManagementService(db, user.tenant_id).update_repository(repository_id, request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ManagedRepositoryResponse.model_validate(repository)
+
+
+@router.delete("/repositories/{repository_id}", status_code=204)
+def delete_repository(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> None:
+    """Deregister a repository."""
+    try:
+        ManagementService(db, user.tenant_id).delete_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/repositories/{repository_id}/webhook", response_model=WebhookConfigResponse)
+def get_webhook(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> WebhookConfigResponse:
+    """Get a repository's push webhook settings."""
+    try:
+        return WebhookConfigResponse(**ManagementService(db, user.tenant_id).get_webhook(repository_id))
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.put("/repositories/{repository_id}/webhook", response_model=WebhookConfigResponse)
+def configure_webhook(
+    repository_id: int,
+    request: WebhookConfigUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> WebhookConfigResponse:
+    """Turn a repository's push webhook on or off; the secret is returned only when set or generated."""
+    service = ManagementService(db, user.tenant_id)
+    try:
+        return WebhookConfigResponse(**service.configure_webhook(repository_id, request.enabled, request.secret))
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.delete("/repositories/{repository_id}/webhook", status_code=204)
+def delete_webhook(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> None:
+    """Turn a repository's push webhook off and forget its secret."""
+    try:
+        ManagementService(db, user.tenant_id).delete_webhook(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.get("/scan-schedules", response_model=list[ScanScheduleResponse])
+def list_schedules(
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> list[ScanScheduleResponse]:
+    """List the workspace's scan schedules."""
+    return [ScanScheduleResponse.model_validate(s) for s in ScanScheduleService(db, user.tenant_id).list_schedules()]
+
+
+@router.post("/scan-schedules", response_model=ScanScheduleResponse, status_code=201)
+def create_schedule(
+    request: ScanScheduleCreate,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> ScanScheduleResponse:
+    """Scan a repository on a cron schedule."""
+    try:
+        schedule = ScanScheduleService(db, user.tenant_id).create_schedule(request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return ScanScheduleResponse.model_validate(schedule)
+
+
+@router.get("/scan-schedules/{schedule_id}", response_model=ScanScheduleResponse)
+def get_schedule(
+    schedule_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> ScanScheduleResponse:
+    """Get a scan schedule with its last and next run."""
+    try:
+        return ScanScheduleResponse.model_validate(ScanScheduleService(db, user.tenant_id).get_schedule(schedule_id))
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.put("/scan-schedules/{schedule_id}", response_model=ScanScheduleResponse)
+def update_schedule(
+    schedule_id: int,
+    request: ScanScheduleUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> ScanScheduleResponse:
+    """Change a scan schedule."""
+    service = ScanScheduleService(db, user.tenant_id)
+    try:
+        service.get_schedule(schedule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        schedule = service.update_schedule(schedule_id, request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return ScanScheduleResponse.model_validate(schedule)
+
+
+@router.delete("/scan-schedules/{schedule_id}", status_code=204)
+def delete_schedule(
+    schedule_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> None:
+    """Delete a scan schedule."""
+    try:
+        ScanScheduleService(db, user.tenant_id).delete_schedule(schedule_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
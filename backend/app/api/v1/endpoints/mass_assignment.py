@@ -0,0 +1,36 @@
+"""API endpoints for mass assignment of privileged model fields."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.mass_assignment import MassAssignmentReport
+from app.services.mass_assignment_service import MassAssignmentService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=MassAssignmentReport)
+def list_mass_assignment_candidates(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    severity: str | None = Query(None, description="Only this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> MassAssignmentReport:
+    """Find handlers that bind request bodies to models with privileged fields.
+
+    Reports binds such as Decode(&expense), User.create(req.body), or
+    Model(**request.data) onto models whose role, admin, approval, or
+    ownership fields are not excluded from binding or overwritten after it,
+    so a crafted request could set them (OWASP API3, CWE-915). Most severe
+    first.
+    """
+    try:
+        result = MassAssignmentService(db, tenant_id).candidates(repository_id, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return MassAssignmentReport(**result)
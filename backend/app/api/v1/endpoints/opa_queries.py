@@ -0,0 +1,37 @@
+"""API endpoints for OPA query detection and Rego policy imports."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.opa_query import OpaQueryScanResult
+from app.services.opa_query_service import OpaQueryService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=OpaQueryScanResult)
+def import_opa_query_policies(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository whose clone queries OPA"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> OpaQueryScanResult:
+    """Import the Rego rules a repository's code asks OPA to evaluate.
+
+    Runs automatically after each repository scan; call it directly to
+    re-import after editing the Rego packages without a full rescan.
+    """
+    service = OpaQueryService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.scan_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return OpaQueryScanResult(**result)
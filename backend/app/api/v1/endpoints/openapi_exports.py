@@ -0,0 +1,113 @@
+"""API endpoints for annotating OpenAPI documents with mined authorization."""
+import json
+from typing import Annotated, Literal
+
+import structlog
+import yaml
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.openapi_export import OpenApiAnnotateRequest, OpenApiExportResponse
+from app.services.openapi_export_service import OpenApiExportService, parse_spec
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+def export(
+    repository_id: int,
+    db: Session,
+    tenant_id: str | None,
+    text: str | None,
+    min_confidence: float | None,
+    include_pending: bool,
+) -> dict:
+    """Run an export, raising 400 for a document that is not OpenAPI 3 and 404 when the repository does not exist."""
+    try:
+        spec = parse_spec(text) if text is not None else None
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    try:
+        return OpenApiExportService(db, tenant_id).export(repository_id, spec, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+def document(result: dict, repository_id: int, format: str) -> Response:
+    """The annotated document as a download."""
+    if format == "json":
+        content, media_type = json.dumps(result["spec"], indent=2), "application/json"
+    else:
+        content = yaml.safe_dump(result["spec"], sort_keys=False, default_flow_style=False)
+        media_type = "application/yaml"
+    return Response(
+        content=content,
+        media_type=media_type,
+        headers={"Content-Disposition": f'attachment; filename="repository-{repository_id}-openapi.{format}"'},
+    )
+
+
+@router.get("/repositories/{repository_id}", response_model=OpenApiExportResponse)
+def export_repository_openapi(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> OpenApiExportResponse:
+    """Generate an OpenAPI document of a repository's mined routes with their security requirements.
+
+    Only approved policies are exported unless include_pending is set;
+    policies scored below min_confidence are left out.
+    """
+    result = export(repository_id, db, tenant_id, None, min_confidence, include_pending)
+    return OpenApiExportResponse(**result)
+
+
+@router.post("/repositories/{repository_id}", response_model=OpenApiExportResponse)
+def annotate_repository_openapi(
+    repository_id: int,
+    request: OpenApiAnnotateRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> OpenApiExportResponse:
+    """Annotate a service's OpenAPI document with security and x-required-roles from its mined policies.
+
+    Operations no mined policy covers are left as they are and listed.
+    """
+    result = export(repository_id, db, tenant_id, request.spec, min_confidence, include_pending)
+    return OpenApiExportResponse(**result)
+
+
+@router.get("/repositories/{repository_id}/document")
+def download_repository_openapi(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    format: Literal["yaml", "json"] = "yaml",
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> Response:
+    """Download the generated OpenAPI document for an API gateway."""
+    result = export(repository_id, db, tenant_id, None, min_confidence, include_pending)
+    return document(result, repository_id, format)
+
+
+@router.post("/repositories/{repository_id}/document")
+def download_annotated_openapi(
+    repository_id: int,
+    request: OpenApiAnnotateRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    format: Literal["yaml", "json"] = "yaml",
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> Response:
+    """Download a service's annotated OpenAPI document for an API gateway."""
+    result = export(repository_id, db, tenant_id, request.spec, min_confidence, include_pending)
+    return document(result, repository_id, format)
@@ -0,0 +1,91 @@
+"""API endpoints for policy ownership and per-team views."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.ownership import OwnershipRun, PolicyOwnershipResponse, TeamPolicy, TeamSummary
+from app.schemas.policy_change import WorkItem
+from app.services.ownership_service import OwnershipService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/repositories/{repository_id}/attribute", response_model=OwnershipRun)
+def attribute_repository_ownership(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    force: bool = Query(False, description="Re-attribute policies that already have owners"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> OwnershipRun:
+    """Attribute a repository's policies from CODEOWNERS and blame, then route their work items.
+
+    Runs after every scan; force it after CODEOWNERS changes.
+    """
+    service = OwnershipService(db, tenant_id)
+    try:
+        attributed = service.attribute(repository_id, force=force)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    routed = service.route_work_items(repository_id)
+    return OwnershipRun(repository_id=repository_id, attributed=attributed, routed_work_items=routed)
+
+
+@router.get("/policies/{policy_id}", response_model=PolicyOwnershipResponse)
+def get_policy_owners(
+    policy_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> PolicyOwnershipResponse:
+    """Get the owners of a mined policy."""
+    try:
+        ownership = OwnershipService(db, tenant_id).policy_owners(policy_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return PolicyOwnershipResponse.model_validate(ownership)
+
+
+@router.get("/teams", response_model=list[TeamSummary])
+def list_teams(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[TeamSummary]:
+    """List owners with the policies and open work items they are responsible for."""
+    return [TeamSummary(**t) for t in OwnershipService(db, tenant_id).teams()]
+
+
+@router.get("/teams/policies", response_model=list[TeamPolicy])
+def list_team_policies(
+    db: Annotated[Session, Depends(get_db)],
+    team: str = Query(..., description='Owner, e.g. "@acme/payments"'),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[TeamPolicy]:
+    """List the policies a team owns, newest first."""
+    return [
+        TeamPolicy(
+            policy_id=policy.id,
+            repository_id=policy.repository_id,
+            subject=policy.subject,
+            resource=policy.resource,
+            action=policy.action,
+            status=policy.status,
+            source=ownership.source,
+            file_path=ownership.file_path,
+            authors=ownership.authors or [],
+        )
+        for policy, ownership in OwnershipService(db, tenant_id).team_policies(team)
+    ]
+
+
+@router.get("/teams/work-items", response_model=list[WorkItem])
+def list_team_work_items(
+    db: Annotated[Session, Depends(get_db)],
+    team: str = Query(..., description='Owner, e.g. "@acme/payments"'),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[WorkItem]:
+    """List the open work items routed to a team, newest first."""
+    return [WorkItem.model_validate(item) for item in OwnershipService(db, tenant_id).team_work_items(team)]
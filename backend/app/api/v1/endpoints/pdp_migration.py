@@ -0,0 +1,82 @@
+"""API endpoints for migrating inline authorization checks to PDP calls."""
+from datetime import datetime
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.models.pdp_migration import CallSiteStatus
+from app.schemas.pdp_migration import (
+    MigrationCallSiteResponse,
+    MigrationOverviewEntry,
+    MigrationProgressResponse,
+    MigrationTrendPoint,
+)
+from app.services.pdp_migration_service import PdpMigrationService
+from app.services.trend_metrics_service import TrendInterval
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=list[MigrationOverviewEntry])
+def get_migration_overview(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[MigrationOverviewEntry]:
+    """Latest migration progress of every measured repository, least migrated first."""
+    return [MigrationOverviewEntry(**entry) for entry in PdpMigrationService(db, tenant_id).overview()]
+
+
+@router.post("/repositories/{repository_id}/refresh", response_model=MigrationProgressResponse)
+def refresh_migration(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> MigrationProgressResponse:
+    """Re-check a repository's call sites against its clone and record the progress.
+
+    Scans refresh automatically; this measures again without one, for
+    example after a migration branch is merged and cloned.
+    """
+    service = PdpMigrationService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        snapshot = service.refresh(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return MigrationProgressResponse.model_validate(snapshot)
+
+
+@router.get("/repositories/{repository_id}/call-sites", response_model=list[MigrationCallSiteResponse])
+def list_call_sites(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    status: CallSiteStatus | None = Query(None, description="pending, migrated, or removed"),
+) -> list[MigrationCallSiteResponse]:
+    """Call sites of a repository with their PDP queries and replacement snippets."""
+    try:
+        call_sites = PdpMigrationService(db, tenant_id).call_sites(repository_id, status)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [MigrationCallSiteResponse.model_validate(c) for c in call_sites]
+
+
+@router.get("/progress", response_model=list[MigrationTrendPoint])
+def get_migration_progress(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    interval: str = Query(TrendInterval.WEEK, pattern="^(day|week)$", description="day or week"),
+    since: datetime | None = Query(None, description="Earliest capture time"),
+) -> list[MigrationTrendPoint]:
+    """Migration progress over time from per-refresh snapshots."""
+    points = PdpMigrationService(db, tenant_id).progress_series(repository_id=repository_id, interval=interval, since=since)
+    return [MigrationTrendPoint(**p) for p in points]
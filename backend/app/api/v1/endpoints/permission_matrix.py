@@ -0,0 +1,49 @@
+"""API endpoints for the role-permission matrix."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.permission_matrix import PermissionMatrix
+from app.services.permission_matrix_service import PermissionMatrixService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=PermissionMatrix)
+def get_permission_matrix(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    application_id: int | None = Query(None, description="Restrict to one application"),
+    include_pending: bool = Query(True, description="Include policies not yet approved"),
+    role: str | None = Query(None, description="Filter roles (substring)"),
+    resource: str | None = Query(None, description="Filter resources (substring)"),
+    action: str | None = Query(None, description="Filter actions (substring)"),
+    conditional_only: bool = Query(False, description="Only return conditional cells"),
+    skip: int = Query(0, ge=0, description="Number of roles to skip"),
+    limit: int = Query(50, ge=1, le=500, description="Maximum number of roles to return"),
+) -> PermissionMatrix:
+    """Get the roles x actions x resources matrix for a repository or the workspace.
+
+    Omit repository_id and application_id for a workspace-wide matrix.
+    Pages are taken over roles; columns always cover every resource/action
+    pair that matches the filters.
+    """
+    service = PermissionMatrixService(db, tenant_id)
+    result = service.build(
+        repository_id=repository_id,
+        application_id=application_id,
+        include_pending=include_pending,
+        role=role,
+        resource=resource,
+        action=action,
+        conditional_only=conditional_only,
+        skip=skip,
+        limit=limit,
+    )
+    return PermissionMatrix(**result)
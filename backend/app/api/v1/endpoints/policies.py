@@ -6,15 +6,19 @@ from fastapi import APIRouter, Depends, HTTPException
 from pydantic import BaseModel
 from sqlalchemy.orm import Session
 
+from app.core.config import settings
 from app.core.database import get_db
 from app.core.dependencies import get_current_user_email, get_tenant_id
-from app.models.policy import Evidence, Policy, SourceType
+from app.models.policy import Evidence, ExtractionMethod, Policy, SourceType
 from app.models.repository import Repository
 from app.schemas.policy import Policy as PolicySchema
 from app.schemas.policy import PolicyList, PolicyUpdate
 from app.services.audit_service import AuditService
+from app.services.auth_mechanism_service import AuthMechanism, policy_mechanisms
 from app.services.evidence_validation_service import EvidenceValidationService
+from app.services.redaction_service import redact_for_egress
 from app.services.translation_service import TranslationService
+from app.tasks.bundle_tasks import publish_bundles_on_approval_task
 
 logger = logging.getLogger(__name__)
 
@@ -35,6 +39,9 @@ class SourceFileResponse(BaseModel):
 async def list_policies(
     repository_id: int | None = None,
     source_type: SourceType | None = None,
+    auth_mechanism: AuthMechanism | None = None,
+    extraction_method: ExtractionMethod | None = None,
+    min_confidence: float | None = None,
     skip: int = 0,
     limit: int = 100,
     db: Session = Depends(get_db),
@@ -44,6 +51,9 @@ async def list_policies(
     Args:
         repository_id: Filter by repository ID
         source_type: Filter by source type (frontend/backend/database/unknown)
+        auth_mechanism: Filter by the authentication mechanism the evidence names
+        extraction_method: Filter by how the policies were extracted
+        min_confidence: Only policies with at least this confidence score (0-100)
         skip: Number of records to skip
         limit: Maximum number of records to return
         db: Database session
@@ -59,6 +69,17 @@ async def list_policies(
     if source_type:
         query = query.filter(Policy.source_type == source_type)
 
+    if extraction_method:
+        query = query.filter(Policy.extraction_method == extraction_method)
+
+    if min_confidence is not None:
+        query = query.filter(Policy.confidence_score >= min_confidence)
+
+    if auth_mechanism:
+        # Classified from policy text and evidence, so filtered after loading
+        matching = [p for p in query.all() if auth_mechanism in policy_mechanisms(p)]
+        return PolicyList(policies=matching[skip : skip + limit], total=len(matching))
+
     total = query.count()
     policies = query.offset(skip).limit(limit).all()
 
@@ -116,6 +137,18 @@ async def update_policy(
     return policy
 
 
+def _auto_publish_bundles(tenant_id: str | None, policy_ids: list[int]) -> None:
+    """Queue the republishing of auto-publish bundle targets after approval.
+
+    Bundles are built and pushed by a Celery task; publishing problems are
+    logged and recorded on the publication, and never fail the approval itself.
+    """
+    try:
+        publish_bundles_on_approval_task.delay(policy_ids, tenant_id=tenant_id)
+    except Exception as e:
+        logger.error(f"Queueing bundle auto-publish after approval failed: {e}")
+
+
 class ApprovalRequest(BaseModel):
     """Request body for policy approval/rejection."""
 
@@ -166,6 +199,8 @@ async def approve_policy(
             user_email=user_email or "anonymous",
         )
 
+    _auto_publish_bundles(tenant_id, [policy_id])
+
     return {"status": "success", "message": "Policy approved"}
 
 
@@ -283,6 +318,10 @@ async def bulk_approve_policies(
 
     db.commit()
 
+    approved_ids = [pid for pid in request.policy_ids if pid not in failed_policy_ids]
+    if approved_ids:
+        _auto_publish_bundles(tenant_id, approved_ids)
+
     return BulkApprovalResponse(
         total_requested=len(request.policy_ids),
         approved=approved,
@@ -393,7 +432,7 @@ async def get_source_file(evidence_id: int, db: Session = Depends(get_db)) -> So
         raise HTTPException(status_code=404, detail="Repository not found")
 
     # Build path to cloned repository
-    repo_path = Path("/tmp/policy_miner_repos") / str(repository.id)
+    repo_path = Path(settings.REPO_CLONE_DIR) / str(repository.id)
     file_path = repo_path / evidence.file_path
 
     # Check if file exists
@@ -452,7 +491,7 @@ async def export_policy_rego(policy_id: int, db: Session = Depends(get_db)) -> P
 
         logger.info(f"Policy {policy_id} exported to Rego", extra={"policy_id": policy_id})
 
-        return PolicyExportResponse(format="rego", policy=rego_policy)
+        return PolicyExportResponse(format="rego", policy=redact_for_egress(rego_policy, "export"))
 
     except Exception as e:
         logger.error(f"Failed to export policy to Rego: {e}", extra={"policy_id": policy_id})
@@ -481,7 +520,7 @@ async def export_policy_cedar(policy_id: int, db: Session = Depends(get_db)) ->
 
         logger.info(f"Policy {policy_id} exported to Cedar", extra={"policy_id": policy_id})
 
-        return PolicyExportResponse(format="cedar", policy=cedar_policy)
+        return PolicyExportResponse(format="cedar", policy=redact_for_egress(cedar_policy, "export"))
 
     except Exception as e:
         logger.error(f"Failed to export policy to Cedar: {e}", extra={"policy_id": policy_id})
@@ -510,7 +549,7 @@ async def export_policy_json(policy_id: int, db: Session = Depends(get_db)) -> P
 
         logger.info(f"Policy {policy_id} exported to JSON", extra={"policy_id": policy_id})
 
-        return PolicyExportResponse(format="json", policy=json_policy)
+        return PolicyExportResponse(format="json", policy=redact_for_egress(json_policy, "export"))
 
     except Exception as e:
         logger.error(f"Failed to export policy to JSON: {e}", extra={"policy_id": policy_id})
@@ -542,7 +581,7 @@ async def validate_evidence(evidence_id: int, db: Session = Depends(get_db)) ->
         raise HTTPException(status_code=404, detail="Repository not found")
 
     # Get repository path
-    repo_path = Path("/tmp/policy_miner_repos") / str(repository.id)
+    repo_path = Path(settings.REPO_CLONE_DIR) / str(repository.id)
 
     # Validate evidence
     validation_service = EvidenceValidationService(db)
@@ -573,7 +612,7 @@ async def validate_policy_evidence(policy_id: int, db: Session = Depends(get_db)
         raise HTTPException(status_code=404, detail="Repository not found")
 
     # Get repository path
-    repo_path = Path("/tmp/policy_miner_repos") / str(repository.id)
+    repo_path = Path(settings.REPO_CLONE_DIR) / str(repository.id)
 
     # Validate all evidence for this policy
     validation_service = EvidenceValidationService(db)
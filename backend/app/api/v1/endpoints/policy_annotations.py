@@ -0,0 +1,57 @@
+"""API endpoints for inline policy annotations."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.policy_annotation import PolicyAnnotationResponse, PolicyAnnotationScanResult
+from app.services.policy_annotation_service import PolicyAnnotationService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=PolicyAnnotationScanResult)
+def scan_policy_annotations(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository whose clone contains the annotations"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> PolicyAnnotationScanResult:
+    """Mine and validate "policy:" comments in a repository.
+
+    Runs automatically after each repository scan; call it directly to
+    re-check annotations without a full rescan.
+    """
+    service = PolicyAnnotationService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.scan_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return PolicyAnnotationScanResult(**result)
+
+
+@router.get("/", response_model=list[PolicyAnnotationResponse])
+def list_policy_annotations(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository the annotations are in"),
+    status: str | None = Query(None, description="valid, stale, or invalid"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[PolicyAnnotationResponse]:
+    """List a repository's annotations as of its last scan, e.g. the stale ones to fix."""
+    service = PolicyAnnotationService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        annotations = service.annotations(repository_id, status)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return [PolicyAnnotationResponse.model_validate(a) for a in annotations]
@@ -0,0 +1,47 @@
+"""API endpoints for the policy graph."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.policy_graph import PolicyGraph
+from app.services.policy_graph_service import DEFAULT_MAX_EDGES, GraphDetail, PolicyGraphService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=PolicyGraph)
+def get_policy_graph(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    application_id: int | None = Query(None, description="Restrict to one application"),
+    include_pending: bool = Query(True, description="Include policies not yet approved"),
+    detail: str = Query(GraphDetail.STANDARD, description="summary, standard, or full"),
+    role: str | None = Query(None, description="Only include this role"),
+    resource: str | None = Query(None, description="Only include matching resources"),
+    max_edges: int = Query(DEFAULT_MAX_EDGES, ge=1, le=100_000, description="Edge budget for the response"),
+) -> PolicyGraph:
+    """Get subjects, permissions, resources, and conditions as nodes and edges.
+
+    Node IDs are stable across requests. Use detail=summary for large
+    workspaces; the response is flagged truncated when max_edges is reached.
+    """
+    service = PolicyGraphService(db, tenant_id)
+    try:
+        result = service.build(
+            repository_id=repository_id,
+            application_id=application_id,
+            include_pending=include_pending,
+            detail=detail,
+            role=role,
+            resource=resource,
+            max_edges=max_edges,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return PolicyGraph(**result)
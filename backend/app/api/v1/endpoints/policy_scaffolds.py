@@ -0,0 +1,80 @@
+"""API endpoints for opening policy-as-code scaffolding pull requests."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.models.scaffold_pull_request import ScaffoldFormat
+from app.schemas.policy_scaffold import PolicyScaffoldPreview, PolicyScaffoldRequest, ScaffoldPullRequestResponse
+from app.services.policy_scaffold_service import PolicyScaffoldService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/preview", response_model=PolicyScaffoldPreview)
+async def preview_scaffold(
+    request: PolicyScaffoldRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> PolicyScaffoldPreview:
+    """Generate the files a scaffold pull request would add, without opening it."""
+    service = PolicyScaffoldService(db, tenant_id)
+    try:
+        service.get_repository(request.repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        scaffold = await service.build(
+            request.repository_id, ScaffoldFormat(request.policy_format), request.language, request.application_id
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return PolicyScaffoldPreview(**scaffold)
+
+
+@router.post("/", response_model=ScaffoldPullRequestResponse, status_code=201)
+async def open_scaffold_pull_request(
+    request: PolicyScaffoldRequest,
+    db: Annotated[Session, Depends(get_db)],
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ScaffoldPullRequestResponse:
+    """Open a pull request adding generated policies and a PDP integration stub to a repository.
+
+    A pull request the Git host rejects is returned with status "failed"
+    and the host's error, and stays in the repository's history.
+    """
+    service = PolicyScaffoldService(db, tenant_id)
+    try:
+        service.get_repository(request.repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        record = await service.open_pull_request(
+            request.repository_id,
+            ScaffoldFormat(request.policy_format),
+            request.language,
+            request.application_id,
+            requested_by=user_email,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return ScaffoldPullRequestResponse.model_validate(record)
+
+
+@router.get("/", response_model=list[ScaffoldPullRequestResponse])
+def list_scaffold_pull_requests(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ScaffoldPullRequestResponse]:
+    """Scaffold pull requests opened for a repository, newest first."""
+    try:
+        records = PolicyScaffoldService(db, tenant_id).list_pull_requests(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ScaffoldPullRequestResponse.model_validate(r) for r in records]
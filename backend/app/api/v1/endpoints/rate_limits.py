@@ -0,0 +1,34 @@
+"""API endpoints for rate limits and quotas."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.rate_limit import ServiceRateLimits
+from app.services.rate_limit_service import RateLimitService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/services", response_model=list[ServiceRateLimits])
+def list_service_rate_limits(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    unlimited_only: bool = Query(False, description="Only services with authenticated endpoints no limit covers"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ServiceRateLimits]:
+    """Get rate limits and quotas per service and endpoint.
+
+    Limits are read from each repository's clone (middleware, throttle
+    settings, and gateway configuration) and resolved onto the endpoints
+    its policies map to, per role or consumer where limits differ.
+    """
+    try:
+        services = RateLimitService(db, tenant_id).services(repository_id, unlimited_only)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ServiceRateLimits(**s) for s in services]
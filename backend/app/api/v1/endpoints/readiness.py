@@ -0,0 +1,75 @@
+"""API endpoints for repository readiness assessments."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.readiness import ReadinessAssessmentResponse, ReadinessAssessmentSummary, RepositoryReadiness
+from app.services.readiness_service import ReadinessService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=list[RepositoryReadiness])
+def get_workspace_readiness(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[RepositoryReadiness]:
+    """Get the latest readiness of every repository, least ready first."""
+    return [RepositoryReadiness(**row) for row in ReadinessService(db, tenant_id).workspace()]
+
+
+@router.post("/repositories/{repository_id}", response_model=ReadinessAssessmentResponse)
+def assess_repository(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> ReadinessAssessmentResponse:
+    """Assess how analyzable a repository's clone is.
+
+    Runs automatically before each scan mines policies; call it directly
+    to re-check after fixing what a previous assessment reported.
+    """
+    service = ReadinessService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        assessment = service.assess(repository_id, assessed_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return ReadinessAssessmentResponse.model_validate(assessment)
+
+
+@router.get("/repositories/{repository_id}", response_model=ReadinessAssessmentResponse)
+def get_repository_readiness(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ReadinessAssessmentResponse:
+    """Get a repository's latest readiness assessment."""
+    try:
+        assessment = ReadinessService(db, tenant_id).latest(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ReadinessAssessmentResponse.model_validate(assessment)
+
+
+@router.get("/repositories/{repository_id}/history", response_model=list[ReadinessAssessmentSummary])
+def get_readiness_history(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ReadinessAssessmentSummary]:
+    """Get a repository's readiness scores over time, newest first."""
+    try:
+        history = ReadinessService(db, tenant_id).history(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ReadinessAssessmentSummary.model_validate(a) for a in history]
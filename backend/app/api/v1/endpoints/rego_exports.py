@@ -0,0 +1,56 @@
+"""API endpoints for exporting mined policies as Rego packages."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.rego_export import RegoExportResponse
+from app.services.rego_export_service import RegoExportService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/repositories/{repository_id}", response_model=RegoExportResponse)
+def export_repository_rego(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> RegoExportResponse:
+    """Export a repository's mined policies as Rego, one package per service.
+
+    Each package has one rule per endpoint and an input schema for
+    ``opa check --schema``. Only approved policies are exported unless
+    include_pending is set; policies scored below min_confidence are left out.
+    """
+    try:
+        result = RegoExportService(db, tenant_id).export(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return RegoExportResponse(**result)
+
+
+@router.get("/repositories/{repository_id}/archive")
+def download_repository_rego(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> Response:
+    """Download a repository's Rego packages and input schemas as a tarball."""
+    try:
+        archive = RegoExportService(db, tenant_id).archive(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return Response(
+        content=archive,
+        media_type="application/gzip",
+        headers={"Content-Disposition": f'attachment; filename="repository-{repository_id}-rego.tar.gz"'},
+    )
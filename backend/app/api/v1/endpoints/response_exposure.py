@@ -0,0 +1,45 @@
+"""API endpoints for sensitive response fields returned to roles not allowed to see them."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.response_exposure import ResponseExposureReport, VisibilityPolicy
+from app.services.response_exposure_service import ResponseExposureService, load_visibility_policy
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=ResponseExposureReport)
+def list_response_exposures(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    severity: str | None = Query(None, description="Only this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ResponseExposureReport:
+    """Find endpoints returning sensitive fields to roles the data visibility policy excludes.
+
+    Reads what each mined endpoint's handlers return (object literals,
+    serialized models and DRF serializers, FastAPI response models) and
+    compares its salary, SSN-like, payment, health, and credential fields
+    with the roles allowed to call the endpoint (OWASP API3, CWE-213). Most
+    severe first.
+    """
+    try:
+        result = ResponseExposureService(db, tenant_id).exposures(repository_id, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ResponseExposureReport(**result)
+
+
+@router.get("/visibility-policy", response_model=VisibilityPolicy)
+def get_visibility_policy() -> VisibilityPolicy:
+    """Get the data visibility policy: sensitive field classes and the roles allowed to receive each."""
+    try:
+        return VisibilityPolicy(**load_visibility_policy())
+    except ValueError as e:
+        raise HTTPException(status_code=500, detail=str(e)) from e
@@ -2,13 +2,17 @@
 import logging
 from typing import Any
 
-from fastapi import APIRouter, Depends
+from fastapi import APIRouter, Depends, HTTPException, Query
 from pydantic import BaseModel
 from sqlalchemy import func
 from sqlalchemy.orm import Session
 
 from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
 from app.models.policy import Policy, RiskLevel
+from app.services.auth_mechanism_service import AuthMechanism
+from app.services.endpoint_risk_service import EndpointRiskService, load_risk_model
+from app.services.finding_taxonomy import normalize_cwe, normalize_owasp
 
 logger = logging.getLogger(__name__)
 
@@ -43,6 +47,38 @@ class RiskThresholds(BaseModel):
     # < 40 is low risk
 
 
+class EndpointRiskResponse(BaseModel):
+    """Composite risk score for one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    score: float
+    level: str
+    components: dict[str, float]
+    factors: list[str]
+    auth_mechanism: str
+    exposure: str = "unknown"
+
+
+class RankedFinding(BaseModel):
+    """An open finding ordered by the risk of the endpoints it touches."""
+
+    finding_type: str
+    finding_id: int
+    severity: str
+    description: str
+    policy_ids: list[int]
+    endpoint: str
+    endpoint_risk_score: float
+    endpoint_risk_level: str
+    exposure: str = "unknown"
+    weighted_severity: str
+    cwe: list[str] = []
+    owasp_api: list[str] = []
+
+
 @router.get("/metrics", response_model=RiskMetrics)
 async def get_risk_metrics(db: Session = Depends(get_db)) -> RiskMetrics:
     """Get overall risk metrics and distribution.
@@ -170,3 +206,95 @@ async def update_risk_thresholds(thresholds: RiskThresholds) -> RiskThresholds:
         },
     )
     return thresholds
+
+
+@router.get("/endpoints", response_model=list[EndpointRiskResponse])
+async def get_endpoint_risk(
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    application_id: int | None = Query(None, description="Restrict to one application"),
+    auth_mechanism: AuthMechanism | None = Query(None, description="Restrict to one authentication mechanism"),
+    limit: int = Query(100, ge=1, le=1000, description="Maximum endpoints to return"),
+    db: Session = Depends(get_db),
+    tenant_id: str | None = Depends(get_tenant_id),
+) -> list[EndpointRiskResponse]:
+    """Get endpoints ordered by composite risk score.
+
+    Combines data sensitivity, exposure, authorization strength, and finding
+    history using the configured risk model.
+
+    Args:
+        repository_id: Restrict to one repository
+        application_id: Restrict to one application
+        auth_mechanism: Restrict to endpoints whose primary mechanism is this one
+        limit: Maximum endpoints to return
+        db: Database session
+        tenant_id: Current tenant
+
+    Returns:
+        Endpoints sorted by descending risk
+    """
+    try:
+        service = EndpointRiskService(db, tenant_id)
+    except ValueError as e:
+        raise HTTPException(status_code=500, detail=str(e)) from e
+    endpoints = service.score_endpoints(repository_id, application_id)
+    if auth_mechanism:
+        endpoints = [e for e in endpoints if e["auth_mechanism"] == auth_mechanism.value]
+    return [EndpointRiskResponse(**e) for e in endpoints[:limit]]
+
+
+@router.get("/findings", response_model=list[RankedFinding])
+async def get_ranked_findings(
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    application_id: int | None = Query(None, description="Restrict to one application"),
+    cwe: str | None = Query(None, description="Only findings tagged with this CWE, e.g. CWE-863"),
+    owasp: str | None = Query(None, description="Only findings in this OWASP API Top 10 category, e.g. API1:2023 or BOLA"),
+    limit: int = Query(100, ge=1, le=1000, description="Maximum findings to return"),
+    db: Session = Depends(get_db),
+    tenant_id: str | None = Depends(get_tenant_id),
+) -> list[RankedFinding]:
+    """Get open findings ordered by the risk of the endpoints they touch.
+
+    Each finding's severity is weighted by the exposure of its riskiest
+    endpoint: raised when internet-facing, lowered when internal-only.
+
+    Args:
+        repository_id: Restrict to one repository
+        application_id: Restrict to one application
+        cwe: Only findings tagged with this CWE
+        owasp: Only findings in this OWASP API Top 10 category
+        limit: Maximum findings to return
+        db: Database session
+        tenant_id: Current tenant
+
+    Returns:
+        Findings sorted by endpoint risk, then weighted severity
+    """
+    try:
+        service = EndpointRiskService(db, tenant_id)
+    except ValueError as e:
+        raise HTTPException(status_code=500, detail=str(e)) from e
+    try:
+        cwe = normalize_cwe(cwe) if cwe else None
+        category = normalize_owasp(owasp).value if owasp else None
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    findings = [
+        f
+        for f in service.rank_findings(repository_id, application_id)
+        if (cwe is None or cwe in f["cwe"]) and (category is None or category in f["owasp_api"])
+    ]
+    return [RankedFinding(**f) for f in findings[:limit]]
+
+
+@router.get("/model", response_model=dict[str, Any])
+async def get_risk_model() -> dict[str, Any]:
+    """Get the endpoint risk model in effect (defaults merged with RISK_MODEL_PATH).
+
+    Returns:
+        Weights, keyword scores, and thresholds
+    """
+    try:
+        return load_risk_model()
+    except ValueError as e:
+        raise HTTPException(status_code=500, detail=str(e)) from e
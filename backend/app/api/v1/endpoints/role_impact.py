@@ -0,0 +1,67 @@
+"""API endpoints for what-if analysis of role changes."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.role_impact import (
+    RoleAssignmentIngestRequest,
+    RoleAssignmentIngestResponse,
+    RoleChangeImpactReport,
+    RoleChangeRequest,
+)
+from app.services.role_impact_service import RoleImpactService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/assignments", response_model=RoleAssignmentIngestResponse)
+def ingest_role_assignments(
+    request: RoleAssignmentIngestRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> RoleAssignmentIngestResponse:
+    """Upload user-to-role assignments (e.g., an IdP group export).
+
+    Assignments let impact analysis report which users are affected, not
+    just which endpoints.
+    """
+    service = RoleImpactService(db, tenant_id)
+    ingested = service.ingest_assignments(
+        [a.model_dump() for a in request.assignments],
+        source=request.source,
+        replace=request.replace,
+    )
+    return RoleAssignmentIngestResponse(ingested=ingested)
+
+
+@router.post("/analyze", response_model=RoleChangeImpactReport)
+def analyze_role_change(
+    request: RoleChangeRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> RoleChangeImpactReport:
+    """Analyze which endpoints and users a role removal or merge affects.
+
+    Args:
+        request: The hypothetical change and optional scope
+
+    Returns:
+        RoleChangeImpactReport with per-endpoint and per-user impact
+    """
+    service = RoleImpactService(db, tenant_id)
+    try:
+        result = service.analyze(
+            operation=request.operation,
+            role=request.role,
+            target_role=request.target_role,
+            repository_id=request.repository_id,
+            application_id=request.application_id,
+        )
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return RoleChangeImpactReport(**result)
@@ -0,0 +1,122 @@
+"""API endpoints for role parameters and their bindings."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.schemas.role_parameter import (
+    ConfigSnapshot,
+    ConfigSnapshotApplied,
+    PolicyParameterProvenance,
+    RoleParameterBindingResponse,
+    RoleParameterBindRequest,
+    RoleParameterSummary,
+)
+from app.services.role_parameter_service import RoleParameterService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=list[RoleParameterSummary])
+def list_role_parameters(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: Annotated[int | None, Query(description="Only parameters used in this repository")] = None,
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[RoleParameterSummary]:
+    """List the role parameters policies use, and whether each is bound."""
+    service = RoleParameterService(db, tenant_id)
+    if repository_id is not None:
+        try:
+            service.get_repository(repository_id)
+        except ValueError as e:
+            raise HTTPException(status_code=404, detail=str(e)) from e
+    return [RoleParameterSummary(**row) for row in service.parameters(repository_id)]
+
+
+@router.get("/bindings", response_model=list[RoleParameterBindingResponse])
+def list_role_parameter_bindings(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: Annotated[int | None, Query(description="Only bindings applying to this repository")] = None,
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[RoleParameterBindingResponse]:
+    """List bound role parameters."""
+    bindings = RoleParameterService(db, tenant_id).bindings(repository_id)
+    return [RoleParameterBindingResponse.model_validate(b) for b in bindings]
+
+
+@router.put("/bindings", response_model=list[RoleParameterBindingResponse])
+def bind_role_parameters(
+    request: RoleParameterBindRequest,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> list[RoleParameterBindingResponse]:
+    """Bind role parameters to values and render the policies using them.
+
+    Nothing is bound unless every binding is valid.
+    """
+    service = RoleParameterService(db, tenant_id)
+    if request.repository_id is not None:
+        try:
+            service.get_repository(request.repository_id)
+        except ValueError as e:
+            raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        bindings = service.bind_all(request.bindings, repository_id=request.repository_id, bound_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return [RoleParameterBindingResponse.model_validate(b) for b in bindings]
+
+
+@router.post("/{repository_id}/snapshot", response_model=ConfigSnapshotApplied)
+def apply_config_snapshot(
+    repository_id: int,
+    snapshot: ConfigSnapshot,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ConfigSnapshotApplied:
+    """Bind a repository's parameters from a config and environment snapshot, as a scan given one does.
+
+    Only parameters the repository's policies use are read and stored.
+    """
+    service = RoleParameterService(db, tenant_id)
+    try:
+        service.get_repository(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.apply_snapshot(repository_id, snapshot.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return ConfigSnapshotApplied(**result)
+
+
+@router.get("/policies/{policy_id}/provenance", response_model=PolicyParameterProvenance)
+def get_parameter_provenance(
+    policy_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> PolicyParameterProvenance:
+    """Show which bindings, manual or from a scan's snapshot, supplied the values rendered into a policy."""
+    try:
+        result = RoleParameterService(db, tenant_id).provenance(policy_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return PolicyParameterProvenance(**result)
+
+
+@router.delete("/bindings/{binding_id}", status_code=204)
+def unbind_role_parameter(
+    binding_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Remove a binding; policies using the parameter show it unbound again."""
+    try:
+        RoleParameterService(db, tenant_id).unbind(binding_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
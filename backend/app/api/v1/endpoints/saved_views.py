@@ -0,0 +1,138 @@
+"""API endpoints for saved views (named filter combinations)."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.models.saved_view import SavedView
+from app.schemas.saved_view import SavedViewCreate, SavedViewResponse, SavedViewUpdate
+from app.services.saved_view_service import SavedViewService, share_path
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+def _view_response(view: SavedView) -> SavedViewResponse:
+    """Build a view response with its share path."""
+    return SavedViewResponse(
+        id=view.id,
+        name=view.name,
+        description=view.description,
+        view_type=view.view_type,
+        filters=view.filters or {},
+        share_token=view.share_token,
+        share_path=share_path(view),
+        created_by=view.created_by,
+        created_at=view.created_at,
+        updated_at=view.updated_at,
+    )
+
+
+@router.post("/", response_model=SavedViewResponse)
+def create_saved_view(
+    request: SavedViewCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> SavedViewResponse:
+    """Save a named filter combination in the workspace."""
+    service = SavedViewService(db, tenant_id)
+    try:
+        view = service.create_view(**request.model_dump(), created_by=user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return _view_response(view)
+
+
+@router.get("/", response_model=list[SavedViewResponse])
+def list_saved_views(
+    db: Annotated[Session, Depends(get_db)],
+    view_type: str | None = Query(None, description="Restrict to one page"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[SavedViewResponse]:
+    """List saved views in the workspace."""
+    service = SavedViewService(db, tenant_id)
+    return [_view_response(v) for v in service.list_views(view_type)]
+
+
+@router.get("/shared/{share_token}", response_model=SavedViewResponse)
+def get_shared_view(
+    share_token: str,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SavedViewResponse:
+    """Resolve a share link to its saved view."""
+    service = SavedViewService(db, tenant_id)
+    try:
+        view = service.get_shared_view(share_token)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return _view_response(view)
+
+
+@router.get("/{view_id}", response_model=SavedViewResponse)
+def get_saved_view(
+    view_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SavedViewResponse:
+    """Get a saved view."""
+    service = SavedViewService(db, tenant_id)
+    try:
+        view = service.get_view(view_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return _view_response(view)
+
+
+@router.put("/{view_id}", response_model=SavedViewResponse)
+def update_saved_view(
+    view_id: int,
+    request: SavedViewUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SavedViewResponse:
+    """Rename a saved view or replace its filters."""
+    service = SavedViewService(db, tenant_id)
+    try:
+        service.get_view(view_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        view = service.update_view(view_id, **request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return _view_response(view)
+
+
+@router.post("/{view_id}/share-token", response_model=SavedViewResponse)
+def regenerate_share_token(
+    view_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SavedViewResponse:
+    """Issue a new share link for a view, invalidating the old one."""
+    service = SavedViewService(db, tenant_id)
+    try:
+        view = service.regenerate_share_token(view_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return _view_response(view)
+
+
+@router.delete("/{view_id}")
+def delete_saved_view(
+    view_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> dict:
+    """Delete a saved view."""
+    service = SavedViewService(db, tenant_id)
+    try:
+        service.delete_view(view_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return {"status": "success", "message": "Saved view deleted"}
@@ -0,0 +1,34 @@
+"""API endpoints for the scan comparison (diff) view."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.scan_comparison import ScanComparison
+from app.services.scan_comparison_service import ScanComparisonService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=ScanComparison)
+def compare_scans(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int = Query(..., description="Repository ID"),
+    scan_id: int | None = Query(None, description="Scan to compare with its predecessor (defaults to the latest)"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ScanComparison:
+    """Compare a scan's mined rules with the previous scan.
+
+    Returns added, removed, and modified rules with inline before/after
+    condition diffs and the evidence supporting each side.
+    """
+    service = ScanComparisonService(db, tenant_id)
+    try:
+        result = service.compare(repository_id, scan_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ScanComparison(**result)
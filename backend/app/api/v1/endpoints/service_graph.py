@@ -0,0 +1,37 @@
+"""API endpoints for the cross-service dependency graph."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.service_graph import ServiceGraph
+from app.services.service_graph_service import ServiceGraphService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=ServiceGraph)
+def get_service_graph(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Only calls made by or to one repository"),
+    dropped_only: bool = Query(False, description="Only hops that drop the user context"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ServiceGraph:
+    """Get the call graph between the tenant's services.
+
+    Internal HTTP and gRPC calls are read from each repository's clone and
+    resolved to the callee repository and endpoint rule. Each hop records
+    whether it forwards the user's token, authenticates as the calling
+    service, or sends nothing; hops that reach a role- or condition-checked
+    endpoint without the user's token are flagged as dropping the user
+    context and listed first.
+    """
+    try:
+        graph = ServiceGraphService(db, tenant_id).graph(repository_id, dropped_only)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ServiceGraph(**graph)
@@ -0,0 +1,35 @@
+"""API endpoints for layered views of multi-language monorepo services."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.service_view import ServiceViewReport
+from app.services.service_view_service import ServiceViewService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/{repository_id}", response_model=ServiceViewReport)
+def get_service_views(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    service: str | None = Query(None, description="Only this service"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> ServiceViewReport:
+    """Get each service of a repository as one entity across its languages.
+
+    Components are found from build manifests and SQL directories and
+    grouped into services by path, so a Go backend, its TypeScript BFF, and
+    its SQL policies are one service with edge, app, and data layers. Edge
+    calls into the service's app endpoints are linked.
+    """
+    try:
+        result = ServiceViewService(db, tenant_id).services(repository_id, service)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ServiceViewReport(**result)
@@ -0,0 +1,70 @@
+"""API endpoints for SIEM connectors."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import AirGapViolation
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.siem_connector import SiemConnectorCreate, SiemConnectorResponse, SiemStreamResult
+from app.services.siem_export_service import SiemExportService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=SiemConnectorResponse, status_code=201)
+def create_connector(
+    request: SiemConnectorCreate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SiemConnectorResponse:
+    """Register a Splunk, Elastic, or Sentinel connector for periodic event streaming."""
+    try:
+        connector = SiemExportService(db, tenant_id).create_connector(request.model_dump())
+    except ValueError as e:
+        raise HTTPException(status_code=400, detail=str(e)) from e
+    return SiemConnectorResponse.model_validate(connector)
+
+
+@router.get("/", response_model=list[SiemConnectorResponse])
+def list_connectors(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[SiemConnectorResponse]:
+    """List connectors with their last stream status."""
+    return [SiemConnectorResponse.model_validate(c) for c in SiemExportService(db, tenant_id).list_connectors()]
+
+
+@router.delete("/{connector_id}", status_code=204)
+def delete_connector(
+    connector_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> None:
+    """Delete a connector; events already streamed stay in the SIEM."""
+    try:
+        SiemExportService(db, tenant_id).delete_connector(connector_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+
+
+@router.post("/{connector_id}/stream", response_model=SiemStreamResult)
+def stream_connector(
+    connector_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SiemStreamResult:
+    """Stream the events since the connector's last stream now."""
+    service = SiemExportService(db, tenant_id)
+    try:
+        service.get_connector(connector_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    try:
+        result = service.stream_connector(connector_id)
+    except (ValueError, AirGapViolation) as e:
+        raise HTTPException(status_code=502, detail=str(e)) from e
+    return SiemStreamResult(**result)
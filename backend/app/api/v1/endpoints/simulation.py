@@ -0,0 +1,54 @@
+"""API endpoints for simulating authorization decisions."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.simulation import SimulationRequestSchema, SimulationResponse
+from app.services.decision_simulation_service import (
+    DecisionSimulationService,
+    SimulationRequest,
+)
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.post("/", response_model=SimulationResponse)
+def simulate_decision(
+    request: SimulationRequestSchema,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> SimulationResponse:
+    """Evaluate the mined policy model for a hypothetical request.
+
+    Returns allow/deny along with every applicable policy and why it did or
+    did not fire, so mined policies can be sanity-checked without exporting
+    them to a policy engine.
+
+    Args:
+        request: Subject, action, resource, and context to evaluate
+
+    Returns:
+        SimulationResponse with the decision and per-policy evaluations
+    """
+    service = DecisionSimulationService(db, tenant_id)
+    result = service.simulate(
+        SimulationRequest(
+            action=request.action,
+            resource=request.resource.name,
+            subject_id=request.subject.id,
+            roles=request.subject.roles,
+            subject_attributes=request.subject.attributes,
+            resource_attributes=request.resource.attributes,
+            context=request.context,
+            authenticated=request.subject.authenticated,
+        ),
+        repository_id=request.repository_id,
+        application_id=request.application_id,
+        include_pending=request.include_pending,
+    )
+    return SimulationResponse(**result)
@@ -0,0 +1,57 @@
+"""API endpoints for exporting mined policies as a SpiceDB schema."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.spicedb_export import SpiceDbExportResponse
+from app.services.spicedb_export_service import SpiceDbExportService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/repositories/{repository_id}", response_model=SpiceDbExportResponse)
+def export_repository_spicedb(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> SpiceDbExportResponse:
+    """Export a repository's mined policies as a SpiceDB schema and relationships.
+
+    Returns the schema for WriteSchema and the relationships granting mined
+    roles to the users ingested role assignments name. Only approved policies
+    are exported unless include_pending is set; policies scored below
+    min_confidence are left out.
+    """
+    try:
+        result = SpiceDbExportService(db, tenant_id).export(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return SpiceDbExportResponse(**result)
+
+
+@router.get("/repositories/{repository_id}/archive")
+def download_repository_spicedb(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> Response:
+    """Download a repository's SpiceDB schema, relationships, and zed import file as a tarball."""
+    try:
+        archive = SpiceDbExportService(db, tenant_id).archive(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return Response(
+        content=archive,
+        media_type="application/gzip",
+        headers={"Content-Disposition": f'attachment; filename="repository-{repository_id}-spicedb.tar.gz"'},
+    )
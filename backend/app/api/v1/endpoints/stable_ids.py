@@ -0,0 +1,58 @@
+"""API endpoints for stable rule and endpoint identifiers."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.stable_identity import RuleLineage, TriageCarryOver
+from app.services.stable_identity_service import StableIdentityService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/repositories/{repository_id}/rules", response_model=list[RuleLineage])
+def list_rule_lineages(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[RuleLineage]:
+    """Get every rule mined from a repository with its stable identifiers.
+
+    Rules are identified by what they enforce and the endpoint they guard,
+    not by file and line, so a rule keeps its ID, triage, and history when
+    its code is moved or renamed.
+
+    Raises:
+        HTTPException: 404 if repository not found
+    """
+    try:
+        lineages = StableIdentityService(db, tenant_id).lineages(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [RuleLineage(**lineage) for lineage in lineages]
+
+
+@router.post("/repositories/{repository_id}/carry-over", response_model=TriageCarryOver)
+def carry_over_triage(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> TriageCarryOver:
+    """Copy review decisions and owners onto the latest scan's policies.
+
+    Runs after every scan; call it to re-apply triage after reviewing old
+    policies. Policies already reviewed are left alone.
+
+    Raises:
+        HTTPException: 404 if repository not found
+    """
+    logger.info("carry_over_triage_endpoint", repository_id=repository_id, tenant_id=tenant_id)
+    try:
+        inherited = StableIdentityService(db, tenant_id).carry_over(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return TriageCarryOver(repository_id=repository_id, inherited=inherited)
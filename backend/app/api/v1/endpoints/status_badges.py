@@ -0,0 +1,89 @@
+"""API endpoints for per-repository policy status and badges."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import require_auth
+from app.models.user import User
+from app.schemas.status_badge import RepositoryStatusResponse
+from app.services.status_badge_service import StatusBadgeService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+# Badges are fetched through image proxies (e.g. GitHub's); keep them from serving a stale scan for long
+BADGE_CACHE_CONTROL = "max-age=300, must-revalidate"
+
+
+def _svg(svg: str) -> Response:
+    """Badge response, cacheable only briefly."""
+    return Response(content=svg, media_type="image/svg+xml", headers={"Cache-Control": BADGE_CACHE_CONTROL})
+
+
+@router.get("/repositories/{repository_id}", response_model=RepositoryStatusResponse)
+def get_repository_status(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> RepositoryStatusResponse:
+    """Get a repository's authentication coverage and open critical findings.
+
+    Read from the metrics captured when the repository's last scan completed,
+    for platform catalogs and dashboards polling many services. The response
+    carries the public badge URL to embed.
+    """
+    try:
+        result = StatusBadgeService(db, user.tenant_id).status(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return RepositoryStatusResponse(**result)
+
+
+@router.get("/repositories/{repository_id}/badge.svg", response_class=Response)
+def get_repository_badge(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> Response:
+    """Get a repository's status as an SVG badge, for signed-in users of its tenant.
+
+    Shows authentication coverage, colored by how much of the API it covers,
+    or in red with the count of open critical findings when there are any.
+    """
+    try:
+        svg = StatusBadgeService(db, user.tenant_id).badge(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return _svg(svg)
+
+
+@router.post("/repositories/{repository_id}/badge-token", response_model=RepositoryStatusResponse)
+def regenerate_badge_token(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    user: Annotated[User, Depends(require_auth)],
+) -> RepositoryStatusResponse:
+    """Issue a new public badge URL; badges embedded with the old one stop resolving."""
+    try:
+        result = StatusBadgeService(db, user.tenant_id).regenerate_badge_token(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return RepositoryStatusResponse(**result)
+
+
+@router.get("/badges/{badge_token}.svg", response_class=Response)
+def get_public_badge(badge_token: str, db: Annotated[Session, Depends(get_db)]) -> Response:
+    """Get the SVG badge to embed in a README, by the repository's badge token.
+
+    Needs no sign-in; the token in the URL is the only thing identifying the
+    repository, so IDs of other repositories cannot be enumerated.
+    """
+    try:
+        svg = StatusBadgeService(db).public_badge(badge_token)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return _svg(svg)
@@ -0,0 +1,48 @@
+"""API endpoints for step-up authentication and MFA requirements."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.step_up import EndpointStepUp, PolicyStepUp
+from app.services.step_up_service import StepUpService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/policies", response_model=list[PolicyStepUp])
+def list_policy_step_up(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    application_id: int | None = Query(None, description="Restrict to one application"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[PolicyStepUp]:
+    """Get the step-up strength attributes of each policy.
+
+    Detects amr/acr claim checks, MFA guards, and re-authentication prompts
+    in the mined evidence.
+    """
+    service = StepUpService(db, tenant_id)
+    return [PolicyStepUp(**p) for p in service.policies(repository_id, application_id)]
+
+
+@router.get("/endpoints", response_model=list[EndpointStepUp])
+def list_endpoint_step_up(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    application_id: int | None = Query(None, description="Restrict to one application"),
+    missing_only: bool = Query(False, description="Only high-risk endpoints that lack step-up"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[EndpointStepUp]:
+    """Get step-up requirements per endpoint, flagging high-risk endpoints without any."""
+    service = StepUpService(db, tenant_id)
+    try:
+        endpoints = service.endpoints(repository_id, application_id, missing_only)
+    except ValueError as e:
+        # Raised when the configured risk model file is unreadable
+        raise HTTPException(status_code=500, detail=str(e)) from e
+    return [EndpointStepUp(**e) for e in endpoints]
@@ -0,0 +1,50 @@
+"""API endpoints for tenant isolation constraints and the endpoints missing them."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.tenant_isolation import TenantConstraintsApplied, TenantIsolationReport
+from app.services.tenant_isolation_service import TenantIsolationService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=TenantIsolationReport)
+def get_tenant_isolation(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    severity: str | None = Query(None, description="Only gaps of this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> TenantIsolationReport:
+    """Find tenant isolation checks and the endpoints touching tenant-owned models without one.
+
+    Models with a tenant column (tenant_id, org_id, workspace_id) are
+    tenant-owned. Each mined endpoint's handlers are read for comparisons of
+    the object's tenant with the caller's, tenant-scoped query filters,
+    tenant stamping on create, and tenant middleware. Endpoints with none
+    are reported as gaps (OWASP API1, CWE-668), most severe first.
+    """
+    try:
+        result = TenantIsolationService(db, tenant_id).isolation(repository_id, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return TenantIsolationReport(**result)
+
+
+@router.post("/{repository_id}/apply", response_model=TenantConstraintsApplied)
+def apply_tenant_constraints(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> TenantConstraintsApplied:
+    """Add mined tenant isolation constraints to the conditions of their endpoints' policies."""
+    try:
+        result = TenantIsolationService(db, tenant_id).apply_constraints(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return TenantConstraintsApplied(**result)
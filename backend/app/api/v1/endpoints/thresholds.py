@@ -0,0 +1,58 @@
+"""API endpoints for condition threshold tracking."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_current_user_email, get_tenant_id
+from app.models.threshold import ThresholdChangeStatus
+from app.schemas.threshold import ThresholdChangeResponse, ThresholdChangeUpdate, ThresholdHistory
+from app.services.threshold_tracking_service import ThresholdTrackingService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/repositories/{repository_id}", response_model=list[ThresholdHistory])
+def get_threshold_history(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ThresholdHistory]:
+    """Get every numeric condition threshold of a repository and how it moved across scans."""
+    try:
+        history = ThresholdTrackingService(db, tenant_id).history(repository_id)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return [ThresholdHistory(**h) for h in history]
+
+
+@router.get("/changes", response_model=list[ThresholdChangeResponse])
+def list_threshold_changes(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    status: ThresholdChangeStatus | None = Query(None, description="Restrict to one review status"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> list[ThresholdChangeResponse]:
+    """List thresholds loosened between scans, newest first."""
+    changes = ThresholdTrackingService(db, tenant_id).list_changes(repository_id, status)
+    return [ThresholdChangeResponse.model_validate(c) for c in changes]
+
+
+@router.put("/changes/{change_id}", response_model=ThresholdChangeResponse)
+def review_threshold_change(
+    change_id: int,
+    request: ThresholdChangeUpdate,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    user_email: Annotated[str | None, Depends(get_current_user_email)] = None,
+) -> ThresholdChangeResponse:
+    """Acknowledge, resolve, or dismiss a loosened threshold."""
+    service = ThresholdTrackingService(db, tenant_id)
+    try:
+        change = service.update_status(change_id, request.status, request.resolution_notes, user_email)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return ThresholdChangeResponse.model_validate(change)
@@ -0,0 +1,40 @@
+"""API endpoints for historical trend metrics."""
+from datetime import datetime
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.trends import CoverageTrendPoint, FindingsWeek
+from app.services.trend_metrics_service import TrendInterval, TrendMetricsService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/coverage", response_model=list[CoverageTrendPoint])
+def get_coverage_trend(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    interval: str = Query(TrendInterval.WEEK, pattern="^(day|week)$", description="day or week"),
+    since: datetime | None = Query(None, description="Earliest capture time"),
+) -> list[CoverageTrendPoint]:
+    """Get coverage over time from per-scan snapshots."""
+    service = TrendMetricsService(db, tenant_id)
+    points = service.coverage_series(repository_id=repository_id, interval=interval, since=since)
+    return [CoverageTrendPoint(**p) for p in points]
+
+
+@router.get("/findings", response_model=list[FindingsWeek])
+def get_findings_trend(
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    weeks: int = Query(12, ge=1, le=104, description="Number of weeks, ending this week"),
+) -> list[FindingsWeek]:
+    """Get findings opened and closed per week."""
+    service = TrendMetricsService(db, tenant_id)
+    return [FindingsWeek(**w) for w in service.findings_per_week(weeks=weeks)]
@@ -0,0 +1,44 @@
+"""API endpoints for known-vulnerable authentication patterns."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.vulnerable_auth_pattern import VulnerableAuthPatternReport, WeaknessResponse
+from app.services.vulnerable_auth_pattern_service import PatternKind, VulnerableAuthPatternService, catalog
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/", response_model=VulnerableAuthPatternReport)
+def list_vulnerable_auth_patterns(
+    db: Annotated[Session, Depends(get_db)],
+    repository_id: int | None = Query(None, description="Restrict to one repository"),
+    kind: PatternKind | None = Query(None, description="Only this pattern kind"),
+    cwe: str | None = Query(None, description="Only kinds mapped to this CWE, e.g. CWE-347"),
+    severity: str | None = Query(None, description="Only this severity"),
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+) -> VulnerableAuthPatternReport:
+    """Find well-known broken authentication patterns in repository clones.
+
+    Reports JWT verification that accepts "none" or skips the signature,
+    tokens accepted without an audience check, secrets compared with
+    early-exit equality, disabled certificate verification, and routes
+    registered so their authorization check never runs. Each finding
+    carries the CWE it is an instance of, most severe first.
+    """
+    try:
+        result = VulnerableAuthPatternService(db, tenant_id).findings(repository_id, kind, cwe, severity)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return VulnerableAuthPatternReport(**result)
+
+
+@router.get("/catalog", response_model=list[WeaknessResponse])
+def get_vulnerable_auth_pattern_catalog() -> list[WeaknessResponse]:
+    """List the detected pattern kinds with their CWE mappings and remediation."""
+    return [WeaknessResponse(**w) for w in catalog()]
@@ -0,0 +1,56 @@
+"""API endpoints for exporting mined policies as XACML 3.0."""
+from typing import Annotated
+
+import structlog
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import Response
+from sqlalchemy.orm import Session
+
+from app.core.database import get_db
+from app.core.dependencies import get_tenant_id
+from app.schemas.xacml_export import XacmlExportResponse
+from app.services.xacml_export_service import XacmlExportService
+
+router = APIRouter()
+logger = structlog.get_logger(__name__)
+
+
+@router.get("/repositories/{repository_id}", response_model=XacmlExportResponse)
+def export_repository_xacml(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> XacmlExportResponse:
+    """Export a repository's mined policies as an XACML 3.0 PolicySet for PBAC products.
+
+    Returns the PolicySet document and the attributes its rules read. Only
+    approved policies are exported unless include_pending is set; policies
+    scored below min_confidence are left out.
+    """
+    try:
+        result = XacmlExportService(db, tenant_id).export(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return XacmlExportResponse(**result)
+
+
+@router.get("/repositories/{repository_id}/policy-set")
+def download_repository_xacml(
+    repository_id: int,
+    db: Annotated[Session, Depends(get_db)],
+    tenant_id: Annotated[str | None, Depends(get_tenant_id)] = None,
+    min_confidence: Annotated[float | None, Query(ge=0, le=100)] = None,
+    include_pending: bool = False,
+) -> Response:
+    """Download a repository's XACML PolicySet document."""
+    try:
+        result = XacmlExportService(db, tenant_id).export(repository_id, min_confidence, include_pending)
+    except ValueError as e:
+        raise HTTPException(status_code=404, detail=str(e)) from e
+    return Response(
+        content=result["xacml"],
+        media_type="application/xml",
+        headers={"Content-Disposition": f'attachment; filename="repository-{repository_id}-xacml.xml"'},
+    )
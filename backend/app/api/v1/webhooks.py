@@ -1,17 +1,19 @@
 """Webhook API endpoints for Git providers."""
 import hashlib
 import hmac
+import json
 import secrets
 from typing import Annotated
 
 import structlog
-from fastapi import APIRouter, Body, Depends, Header, HTTPException
+from fastapi import APIRouter, Depends, Header, HTTPException, Request
 from sqlalchemy import select
 from sqlalchemy.orm import Session
 
 from app.core.database import get_db
 from app.models.repository import Repository
 from app.services.repository_service import RepositoryService
+from app.tasks.scan_tasks import enqueue_scan
 
 logger = structlog.get_logger()
 
@@ -42,16 +44,26 @@ def verify_github_signature(payload_body: bytes, signature_header: str, secret:
 
 @router.post("/github")
 async def github_webhook(
-    payload: dict = Body(...),
+    request: Request,
     x_hub_signature_256: Annotated[str | None, Header()] = None,
     x_github_event: Annotated[str | None, Header()] = None,
     db: Session = Depends(get_db),
 ):
     """Handle GitHub webhook events.
 
-    Supports push events to trigger automatic scans.
-    Verifies webhook signature for security.
+    Supports push events to queue automatic scans.
+    When the repository has a webhook secret, the payload must carry a valid
+    X-Hub-Signature-256 computed over the raw request body.
     """
+    # The signature covers the exact bytes sent, so the body is parsed here rather than by FastAPI
+    body = await request.body()
+    try:
+        payload = json.loads(body)
+    except ValueError:
+        raise HTTPException(status_code=400, detail="Payload is not valid JSON")
+    if not isinstance(payload, dict):
+        raise HTTPException(status_code=400, detail="Payload must be a JSON object")
+
     logger.info(
         "webhook_received",
         github_event=x_github_event,
@@ -79,6 +91,13 @@ async def github_webhook(
             detail=f"Repository not found with URL: {repo_url}",
         )
 
+    # Verify the signature before anything about the repository is acted on
+    if repository.webhook_secret and not verify_github_signature(
+        body, x_hub_signature_256, repository.webhook_secret
+    ):
+        logger.warning("webhook_signature_invalid", repository_id=repository.id)
+        raise HTTPException(status_code=401, detail="Missing or invalid webhook signature")
+
     # Check if webhook is enabled
     if not repository.webhook_enabled:
         logger.info("webhook_disabled", repository_id=repository.id)
@@ -87,38 +106,20 @@ async def github_webhook(
             "reason": "Webhooks are disabled for this repository",
         }
 
-    # Verify signature if webhook_secret is set
-    if repository.webhook_secret and x_hub_signature_256:
-        # Get raw body for signature verification
-        # Note: In production, we'd need to capture raw body before JSON parsing
-        # For now, we'll log a warning
-        logger.warning(
-            "webhook_signature_verification_skipped",
-            repository_id=repository.id,
-            reason="Signature verification requires raw request body",
-        )
-
-    # Trigger scan
-    logger.info("triggering_scan", repository_id=repository.id)
-
-    from app.services.scanner_service import ScannerService
-
-    scanner = ScannerService(db)
+    # Queue the scan; the worker runs it outside this request
     try:
-        result = await scanner.scan_repository(
-            repository.id,
-            tenant_id=repository.tenant_id,
-        )
-        logger.info("webhook_scan_triggered", repository_id=repository.id, scan_id=result.get("scan_id"))
-        return {
-            "status": "success",
-            "message": "Scan triggered",
-            "repository_id": repository.id,
-            "scan_id": result.get("scan_id"),
-        }
+        task_id = enqueue_scan(repository.id, tenant_id=repository.tenant_id)
     except Exception as e:
         logger.error("webhook_scan_failed", repository_id=repository.id, error=str(e))
-        raise HTTPException(status_code=500, detail=f"Failed to trigger scan: {str(e)}")
+        raise HTTPException(status_code=500, detail=f"Failed to queue scan: {str(e)}")
+
+    logger.info("webhook_scan_queued", repository_id=repository.id, task_id=task_id)
+    return {
+        "status": "queued",
+        "message": "Scan queued",
+        "repository_id": repository.id,
+        "task_id": task_id,
+    }
 
 
 @router.post("/{repository_id}/generate-secret")
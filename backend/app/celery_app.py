@@ -4,6 +4,12 @@ import os
 
 from celery import Celery
 
+from app.core.air_gap import install_egress_guard, is_air_gapped
+
+# Scan tasks run in the worker, so it needs the same egress guard as the API
+if is_air_gapped():
+    install_egress_guard()
+
 # Get Redis URL from environment
 REDIS_URL = os.getenv("REDIS_URL", "redis://localhost:6379")
 
@@ -12,7 +18,15 @@ celery_app = Celery(
     "policy_miner",
     broker=REDIS_URL,
     backend=REDIS_URL,
-    include=["app.tasks.scan_tasks"],
+    include=[
+        "app.tasks.scan_tasks",
+        "app.tasks.bundle_tasks",
+        "app.tasks.idp_tasks",
+        "app.tasks.itsm_tasks",
+        "app.tasks.siem_tasks",
+        "app.tasks.schedule_tasks",
+        "app.tasks.worker_tasks",
+    ],
 )
 
 # Configure Celery
@@ -30,4 +44,12 @@ celery_app.conf.update(
     task_acks_late=True,  # Acknowledge tasks only after completion
     task_reject_on_worker_lost=True,  # Reject tasks if worker dies
     result_expires=3600,  # Results expire after 1 hour
+    beat_schedule={
+        # Each connector has its own interval; this just checks which are due
+        "sync-idp-connectors": {"task": "sync_idp_connectors", "schedule": 300.0},
+        "sync-itsm-connectors": {"task": "sync_itsm_connectors", "schedule": 300.0},
+        "stream-siem-events": {"task": "stream_siem_events", "schedule": 60.0},
+        "run-scan-schedules": {"task": "run_scan_schedules", "schedule": 60.0},
+        "recover-orphaned-scans": {"task": "recover_orphaned_scans", "schedule": 60.0},
+    },
 )
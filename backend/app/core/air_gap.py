@@ -0,0 +1,222 @@
+"""
+Air-gapped operation mode.
+
+With AIR_GAPPED enabled the application makes no outbound network calls: LLM
+providers are disabled, git operations are limited to local repositories and
+in-enclave mirrors, and every Python-level socket connection is checked
+against an allowlist. Anything that attempts egress raises AirGapViolation
+and is logged at error level rather than failing silently.
+
+Reachable hosts are loopback, the platform's own backing services (database,
+Redis, MinIO, taken from their configured URLs), and AIR_GAP_ALLOWED_HOSTS.
+Native database drivers and git subprocesses open their own sockets, so
+their call sites check the target host explicitly before connecting.
+"""
+
+import ipaddress
+import socket
+from urllib.parse import urlparse
+
+import structlog
+
+from app.core.config import settings
+
+logger = structlog.get_logger(__name__)
+
+LOOPBACK_HOSTS = {"localhost", "localhost.localdomain", "ip6-localhost"}
+
+
+class AirGapViolation(RuntimeError):
+    """Raised when a component attempts egress in air-gapped mode."""
+
+
+def is_air_gapped() -> bool:
+    """
+    Check if the application is running in air-gapped mode.
+
+    Returns:
+        bool: True if AIR_GAPPED is enabled
+    """
+    return settings.AIR_GAPPED
+
+
+def _url_host(url: str) -> str | None:
+    """Host part of a URL or host:port string."""
+    if "://" not in url:
+        url = f"//{url}"
+    try:
+        return urlparse(url).hostname
+    except ValueError:
+        return None
+
+
+def allowed_hosts() -> set[str]:
+    """
+    Hosts reachable in air-gapped mode.
+
+    Returns:
+        set[str]: Lower-cased host names and IP addresses
+    """
+    hosts = set(LOOPBACK_HOSTS)
+    for url in (settings.DATABASE_URL, settings.REDIS_URL, settings.MINIO_ENDPOINT):
+        host = _url_host(url)
+        if host:
+            hosts.add(host.lower())
+    hosts.update(h.strip().lower() for h in settings.AIR_GAP_ALLOWED_HOSTS if h.strip())
+    return hosts
+
+
+def is_host_allowed(host: str) -> bool:
+    """
+    Check whether a host may be contacted in air-gapped mode.
+
+    Args:
+        host: Host name or IP address
+
+    Returns:
+        bool: True for loopback addresses and allowlisted hosts
+    """
+    host = host.strip("[]").lower()
+    try:
+        if ipaddress.ip_address(host).is_loopback:
+            return True
+    except ValueError:
+        pass
+    return host in allowed_hosts()
+
+
+def _blocked(component: str, target: str, reason: str) -> AirGapViolation:
+    """Log a blocked egress attempt and build the error to raise."""
+    logger.error("air_gap_egress_blocked", component=component, target=target, reason=reason)
+    return AirGapViolation(f"Air-gapped mode: {component} attempted to reach {target} ({reason})")
+
+
+def ensure_host_allowed(host: str | None, component: str) -> None:
+    """
+    Fail if an outbound connection to a host is not permitted.
+
+    Args:
+        host: Target host name or IP address
+        component: Component making the connection (for the error and log)
+
+    Raises:
+        AirGapViolation: If air-gapped mode is on and the host is not allowlisted
+    """
+    if not is_air_gapped() or not host:
+        return
+    if not is_host_allowed(host):
+        raise _blocked(component, host, "host not in AIR_GAP_ALLOWED_HOSTS")
+
+
+def ensure_git_url_allowed(url: str, component: str) -> None:
+    """
+    Fail if a git remote is outside the enclave.
+
+    Local paths and file:// URLs are always allowed. Network remotes (https,
+    ssh, and scp-style user@host:path) must point at an allowlisted host.
+
+    Args:
+        url: Git remote URL or path
+        component: Component running the git operation
+
+    Raises:
+        AirGapViolation: If air-gapped mode is on and the remote is not allowed
+    """
+    if not is_air_gapped():
+        return
+    if "://" in url:
+        scheme = url.split("://", 1)[0].lower()
+        if scheme == "file":
+            return
+        host = _url_host(url)
+    elif ":" in url.split("/", 1)[0] and not url.startswith("/"):
+        # scp-like syntax: git@host:org/repo.git
+        host = url.split(":", 1)[0].rsplit("@", 1)[-1]
+    else:
+        return
+    if not host or not is_host_allowed(host):
+        raise _blocked(component, host or url, "git remote outside the enclave")
+
+
+def ensure_llm_allowed(component: str) -> None:
+    """
+    Fail if an LLM call is attempted in air-gapped mode.
+
+    Args:
+        component: Component making the call
+
+    Raises:
+        AirGapViolation: If air-gapped mode is on
+    """
+    if is_air_gapped():
+        raise _blocked(component, "LLM provider", "LLM calls are disabled")
+
+
+_original_connect = socket.socket.connect
+_original_connect_ex = socket.socket.connect_ex
+_original_sendto = socket.socket.sendto
+_original_getaddrinfo = socket.getaddrinfo
+
+# IPs resolved from allowlisted host names, so connections to them pass
+_resolved_allowed: set[str] = set()
+
+
+def _check_address(sock: socket.socket, address) -> None:
+    """Check a socket address against the allowlist."""
+    if sock.family not in (socket.AF_INET, socket.AF_INET6):
+        return
+    host = str(address[0])
+    if host in _resolved_allowed or is_host_allowed(host):
+        return
+    raise _blocked("socket", f"{host}:{address[1]}", "connection outside the enclave")
+
+
+def _guarded_connect(self, address):
+    _check_address(self, address)
+    return _original_connect(self, address)
+
+
+def _guarded_connect_ex(self, address):
+    _check_address(self, address)
+    return _original_connect_ex(self, address)
+
+
+def _guarded_sendto(self, data, *args):
+    _check_address(self, args[-1])
+    return _original_sendto(self, data, *args)
+
+
+def _guarded_getaddrinfo(host, *args, **kwargs):
+    if host is not None:
+        name = host.decode() if isinstance(host, bytes) else str(host)
+        if not is_host_allowed(name):
+            raise _blocked("dns", name, "name lookup outside the enclave")
+    results = _original_getaddrinfo(host, *args, **kwargs)
+    _resolved_allowed.update(str(r[4][0]) for r in results)
+    return results
+
+
+def install_egress_guard() -> None:
+    """
+    Block Python-level network egress outside the enclave.
+
+    Patches socket connect, sendto, and name resolution so that any library
+    (HTTP clients, cloud SDKs, telemetry exporters) trying to reach a host
+    outside the allowlist raises AirGapViolation. Safe to call more than once.
+    """
+    if socket.socket.connect is _guarded_connect:
+        return
+    socket.socket.connect = _guarded_connect
+    socket.socket.connect_ex = _guarded_connect_ex
+    socket.socket.sendto = _guarded_sendto
+    socket.getaddrinfo = _guarded_getaddrinfo
+    logger.info("air_gap_egress_guard_installed", allowed_hosts=sorted(allowed_hosts()))
+
+
+def uninstall_egress_guard() -> None:
+    """Restore the original socket functions."""
+    socket.socket.connect = _original_connect
+    socket.socket.connect_ex = _original_connect_ex
+    socket.socket.sendto = _original_sendto
+    socket.getaddrinfo = _original_getaddrinfo
+    _resolved_allowed.clear()
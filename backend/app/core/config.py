@@ -49,14 +49,70 @@ class Settings(BaseSettings):
     AZURE_OPENAI_DEPLOYMENT_NAME: str = "claude-sonnet-4"
     AZURE_OPENAI_API_VERSION: str = "2024-10-01-preview"
 
+    # LLM batching and rate limiting
+    LLM_MAX_CONCURRENCY: int = 4  # Parallel LLM calls per scan batch
+    LLM_REQUESTS_PER_MINUTE: int = 50  # Provider rate limit (0 = unlimited)
+    LLM_MAX_RETRIES: int = 5  # Retries for throttling/overload errors (429/529)
+    LLM_RETRY_BASE_DELAY_SECONDS: float = 2.0
+    LLM_RETRY_MAX_DELAY_SECONDS: float = 60.0
+
     # Scanning
     BATCH_SIZE: int = 50
     MAX_FILE_SIZE_MB: int = 10
+    REPO_CLONE_DIR: str = "/tmp/policy_miner_repos"  # Where scanned repositories are cloned
+    IMAGE_SCAN_MAX_SIZE_MB: int = 4096  # Largest container image archive accepted for upload
+    INTERPROCEDURAL_DEPTH: int = 3  # Helper call levels followed from a handler to the checks it delegates
+    SERVICE_LAYOUT_PATH: str = ""  # Optional JSON file pinning monorepo components to a service and layer
+
+    # Sandboxing of repository analysis (see app.core.sandbox); limits apply to each pass over a checkout
+    SCAN_SANDBOX_ENABLED: bool = True
+    SCAN_SANDBOX_TIMEOUT_SECONDS: int = 1800  # Time spent producing results, not waiting on the scan
+    SCAN_SANDBOX_CPU_SECONDS: int = 900
+    SCAN_SANDBOX_MEMORY_MB: int = 2048
+    SCAN_SANDBOX_REQUIRE_NAMESPACES: bool = False  # Fail scans where network/mount namespaces are unavailable
 
     # Encryption
     # In production, use a secure key from KMS/Vault
     # Generate with: python -c "from cryptography.fernet import Fernet; print(Fernet.generate_key().decode())"
     ENCRYPTION_KEY: str = "J2mtpOQ4ilLflT91hDBdAe9AT9Tw4ugn9k_3xYxtb30="
 
+    # Compliance
+    COMPLIANCE_MAPPING_PATH: str = ""  # Optional JSON file extending/overriding control mappings
+
+    # Endpoint risk scoring
+    RISK_MODEL_PATH: str = ""  # Optional JSON file overriding the endpoint risk model
+    DATA_VISIBILITY_POLICY_PATH: str = ""  # Optional JSON file saying which roles may receive each class of sensitive field
+
+    # Redaction of evidence leaving the system (LLM calls, exports)
+    REDACTION_ENABLED: bool = True
+    REDACTION_CONFIG_PATH: str = ""  # Optional JSON file with custom patterns and internal domains
+
+    # Air-gapped operation (no outbound network calls, LLM disabled)
+    AIR_GAPPED: bool = False
+    AIR_GAP_ALLOWED_HOSTS: list[str] = []  # In-enclave hosts (git mirror, scanned databases)
+
+    # IdP role sync (Keycloak/Auth0 connectors, run by celery beat)
+    IDP_ROLE_SYNC_INTERVAL_MINUTES: int = 60  # Default interval for connectors without their own
+
+    # ITSM finding sync (ServiceNow/generic connectors, run by celery beat)
+    ITSM_SYNC_INTERVAL_MINUTES: int = 30  # Default interval for connectors without their own
+
+    # SIEM event streaming (Splunk/Elastic/Sentinel connectors, run by celery beat)
+    SIEM_STREAM_INTERVAL_MINUTES: int = 5  # Default interval for connectors without their own
+
+    # Kubernetes operator reconciling PolicyScan resources (python -m app.operator)
+    OPERATOR_NAMESPACE: str = ""  # Namespace whose PolicyScans to reconcile; empty for all
+    OPERATOR_RESYNC_SECONDS: int = 15
+    OPERATOR_KUBERNETES_API_URL: str = ""  # Instead of the in-cluster API, e.g. kubectl proxy's
+    OPERATOR_WORKER_DEPLOYMENT: str = "scan-worker"
+    OPERATOR_WORKER_NAMESPACE: str = ""  # Defaults to the operator's own namespace
+    OPERATOR_MIN_WORKERS: int = 1
+    OPERATOR_MAX_WORKERS: int = 10
+
+    # High availability (leader election and worker registration in Redis)
+    LEADER_LEASE_SECONDS: int = 30  # A leader that stops renewing is replaced after this long
+    WORKER_HEARTBEAT_SECONDS: int = 10  # Registrations expire after three missed heartbeats
+    WORKER_DRAIN_SECONDS: int = 300  # On shutdown, how long a scan may run before another worker takes it over
+
 
 settings = Settings()
@@ -0,0 +1,143 @@
+"""Coordination between replicas through Redis: the shared client and leader election.
+
+Processes that must run exactly once in a cluster (celery beat, the PolicyScan
+operator) campaign for a named lease. The holder renews it every third of
+LEADER_LEASE_SECONDS; a replica that stops renewing, because it crashed or
+lost Redis, is replaced by a standby once its lease lapses.
+"""
+
+import os
+import socket
+import threading
+import time
+from collections.abc import Callable
+
+import redis
+import structlog
+
+from app.core.config import settings
+
+logger = structlog.get_logger(__name__)
+
+LEADER_PREFIX = "policy-miner:leader:"
+
+# Extend the lease only while this replica still holds it
+RENEW_SCRIPT = """
+if redis.call("get", KEYS[1]) == ARGV[1] then
+    return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0
+"""
+
+# Give the lease up only if this replica still holds it
+RELEASE_SCRIPT = """
+if redis.call("get", KEYS[1]) == ARGV[1] then
+    return redis.call("del", KEYS[1])
+end
+return 0
+"""
+
+
+def get_redis() -> redis.Redis:
+    """Redis client for REDIS_URL, decoding responses to strings."""
+    return redis.Redis.from_url(settings.REDIS_URL, decode_responses=True)
+
+
+def replica_identity() -> str:
+    """This process's identity: its pod (host) name and PID."""
+    return f"{socket.gethostname()}:{os.getpid()}"
+
+
+class LeaderElection:
+    """A named lease held by one replica at a time."""
+
+    def __init__(
+        self,
+        client: redis.Redis,
+        name: str,
+        identity: str | None = None,
+        lease_seconds: float | None = None,
+    ):
+        """Initialize election.
+
+        Args:
+            client: Redis client
+            name: What is being led, e.g. "scheduler"
+            identity: This replica's identity (defaults to host name and PID)
+            lease_seconds: How long a lease lasts unrenewed (defaults to LEADER_LEASE_SECONDS)
+        """
+        self.client = client
+        self.name = name
+        self.key = LEADER_PREFIX + name
+        self.identity = identity or replica_identity()
+        self.lease_seconds = lease_seconds or settings.LEADER_LEASE_SECONDS
+        self.renew_interval = self.lease_seconds / 3
+        self.is_leader = False
+        self._released = threading.Event()
+
+    def try_acquire(self) -> bool:
+        """Take the lease if it is free or renew it if held; returns whether this replica leads."""
+        lease_ms = int(self.lease_seconds * 1000)
+        leading = bool(
+            self.client.set(self.key, self.identity, nx=True, px=lease_ms)
+            or self.client.eval(RENEW_SCRIPT, 1, self.key, self.identity, lease_ms)
+        )
+        if leading and not self.is_leader:
+            logger.info("leadership_acquired", election=self.name, identity=self.identity)
+        self.is_leader = leading
+        return leading
+
+    def holder(self) -> str | None:
+        """Identity of the current leader, if any."""
+        return self.client.get(self.key)
+
+    def campaign(self, stopping: threading.Event) -> bool:
+        """Wait until this replica leads, or until stopping is set; returns whether it leads."""
+        logger.info("leadership_campaign_started", election=self.name, identity=self.identity)
+        while not stopping.is_set():
+            try:
+                if self.try_acquire():
+                    return True
+            except redis.RedisError as e:
+                logger.warning("leadership_campaign_failed", election=self.name, error=str(e))
+            stopping.wait(self.renew_interval)
+        return False
+
+    def keep(self, on_lost: Callable[[], None]) -> threading.Thread:
+        """Renew the lease in the background until released, calling on_lost once if it is lost.
+
+        Redis errors are retried until the lease would have lapsed, since a
+        standby may take over from then on.
+        """
+
+        def renew() -> None:
+            renewed_at = time.monotonic()
+            while not self._released.wait(self.renew_interval):
+                try:
+                    if not self.try_acquire():
+                        break
+                    renewed_at = time.monotonic()
+                except redis.RedisError as e:
+                    logger.warning("leadership_renewal_failed", election=self.name, error=str(e))
+                    if time.monotonic() - renewed_at >= self.lease_seconds:
+                        break
+            else:
+                return
+            self.is_leader = False
+            logger.error("leadership_lost", election=self.name, identity=self.identity)
+            on_lost()
+
+        thread = threading.Thread(target=renew, name=f"{self.name}-lease", daemon=True)
+        thread.start()
+        return thread
+
+    def release(self) -> None:
+        """Stop renewing and give up the lease so a standby takes over at once."""
+        self._released.set()
+        if self.is_leader:
+            try:
+                self.client.eval(RELEASE_SCRIPT, 1, self.key, self.identity)
+            except redis.RedisError as e:
+                logger.warning("leadership_release_failed", election=self.name, error=str(e))
+            self.is_leader = False
+            logger.info("leadership_released", election=self.name, identity=self.identity)
@@ -0,0 +1,331 @@
+"""
+Sandboxed analysis of scanned repositories.
+
+Scanned repositories are third-party code. Every pass that parses a checkout
+(file discovery, secret detection, pattern matching, the analyzers run after
+mining down to git blame, and the analyzers the API runs on demand) goes
+through run_pass and runs in a child interpreter, ``python -m app.core.sandbox``,
+which:
+
+- gets a minimal environment, without credentials or service URLs;
+- runs under CPU, address-space, and open-file limits, cannot write file
+  contents (RLIMIT_FSIZE 0) or, unless the worker runs as root or the pass
+  runs git, start processes, and is killed when it spends longer than
+  SCAN_SANDBOX_TIMEOUT_SECONDS producing results;
+- where unprivileged user namespaces are available, runs in its own network
+  namespace (loopback only) and mount namespace, with the checkout
+  bind-mounted read-only;
+- in every case has Python-level socket connections and name lookups blocked.
+
+Repository code is parsed, never executed. The sandbox contains parser bugs
+and inputs crafted to exhaust the worker, and keeps anything reached through
+the checkout away from the worker's network and secrets. Container runtimes'
+default seccomp profiles usually deny user namespaces; there the pod's own
+security context (read-only root filesystem, no privileges) stands in for the
+mount, and SCAN_SANDBOX_REQUIRE_NAMESPACES makes scans fail instead.
+
+The child reads one JSON request line and writes JSON lines back: which
+isolation applied, then each item the pass yields, then an end marker.
+"""
+
+import ctypes
+import importlib
+import json
+import os
+import resource
+import selectors
+import signal
+import socket
+import subprocess
+import sys
+import tempfile
+import time
+from collections.abc import Callable, Iterator
+from dataclasses import asdict, dataclass
+from pathlib import Path
+from typing import Any
+
+import structlog
+
+logger = structlog.get_logger(__name__)
+
+# Directory holding the app package, put first on the child's import path
+APP_ROOT = Path(__file__).resolve().parents[2]
+
+# mount(2) flags, and the statvfs flags a read-only remount must keep
+MS_RDONLY = 0x1
+MS_REMOUNT = 0x20
+MS_BIND = 0x1000
+MS_REC = 0x4000
+MS_PRIVATE = 0x40000
+MS_RELATIME = 0x200000
+KEPT_MOUNT_FLAGS = 0x2 | 0x4 | 0x8 | 0x400 | 0x800  # nosuid, nodev, noexec, noatime, nodiratime
+ST_RELATIME = 0x1000
+
+
+class SandboxError(RuntimeError):
+    """Raised when a sandboxed pass fails or exceeds a limit."""
+
+    def __init__(self, message: str, reason: str):
+        super().__init__(message)
+        self.reason = reason  # timeout, cpu, memory, killed, isolation, network, failed
+
+
+@dataclass
+class SandboxLimits:
+    """Limits of one sandboxed pass."""
+
+    timeout_seconds: int = 1800
+    cpu_seconds: int = 900
+    memory_mb: int = 2048
+    open_files: int = 256
+    require_namespaces: bool = False
+    processes: bool = False  # Whether the pass may start processes (git)
+
+    @classmethod
+    def from_settings(cls) -> "SandboxLimits":
+        """Limits configured by the SCAN_SANDBOX_* settings."""
+        from app.core.config import settings
+
+        return cls(
+            timeout_seconds=settings.SCAN_SANDBOX_TIMEOUT_SECONDS,
+            cpu_seconds=settings.SCAN_SANDBOX_CPU_SECONDS,
+            memory_mb=settings.SCAN_SANDBOX_MEMORY_MB,
+            require_namespaces=settings.SCAN_SANDBOX_REQUIRE_NAMESPACES,
+        )
+
+
+class Sandbox:
+    """Runs analysis passes over one checkout in sandboxed child processes."""
+
+    def __init__(self, root: Path, limits: SandboxLimits | None = None):
+        """Initialize sandbox."""
+        self.root = Path(root).resolve()
+        self.limits = limits or SandboxLimits.from_settings()
+        self.isolation: list[str] = []  # What the last pass ran under
+
+    def _command(self) -> tuple[list[str], dict[str, str]]:
+        """Command line and environment of the child."""
+        path = [str(APP_ROOT)] + [p for p in sys.path if p and os.path.isabs(p) and p != str(APP_ROOT)]
+        env = {
+            "PATH": os.defpath,
+            "PYTHONPATH": os.pathsep.join(path),
+            "PYTHONDONTWRITEBYTECODE": "1",
+            "PYTHONSAFEPATH": "1",
+            "LANG": "C.UTF-8",
+        }
+        return [sys.executable, "-P", "-m", "app.core.sandbox"], env
+
+    def run(self, func: Callable[..., Any], **kwargs) -> Iterator[Any]:
+        """Run a pass in a child process, yielding what it yields.
+
+        Args:
+            func: Module-level function taking the checkout root and kwargs,
+                yielding JSON-serializable items
+            **kwargs: JSON-serializable arguments
+
+        Raises:
+            SandboxError: If the pass fails, exceeds a limit, or cannot be isolated as required
+        """
+        request = {
+            "root": str(self.root),
+            "target": f"{func.__module__}:{func.__qualname__}",
+            "kwargs": kwargs,
+            "limits": asdict(self.limits),
+        }
+        command, env = self._command()
+        stderr = tempfile.TemporaryFile()
+        process = subprocess.Popen(
+            command,
+            stdin=subprocess.PIPE,
+            stdout=subprocess.PIPE,
+            stderr=stderr,
+            cwd="/",
+            env=env,
+            start_new_session=True,
+        )
+        waited, buffer, error, done = 0.0, b"", None, False
+        try:
+            process.stdin.write(json.dumps(request).encode() + b"\n")
+            process.stdin.close()
+            with selectors.DefaultSelector() as selector:
+                selector.register(process.stdout, selectors.EVENT_READ)
+                while True:
+                    # Only time spent waiting on the child counts; the caller may take its time between items
+                    started = time.monotonic()
+                    ready = selector.select(max(self.limits.timeout_seconds - waited, 0))
+                    waited += time.monotonic() - started
+                    if not ready:
+                        raise SandboxError(
+                            f"Analysis exceeded {self.limits.timeout_seconds}s", "timeout"
+                        )
+                    chunk = os.read(process.stdout.fileno(), 1 << 16)
+                    if not chunk:
+                        break
+                    *lines, buffer = (buffer + chunk).split(b"\n")
+                    for line in lines:
+                        message = json.loads(line)
+                        if "item" in message:
+                            yield message["item"]
+                        elif "isolation" in message:
+                            self.isolation = message["isolation"]
+                            logger.info("sandbox_started", root=str(self.root), isolation=self.isolation)
+                        elif "error" in message:
+                            error = SandboxError(message["error"], message["reason"])
+                        elif "done" in message:
+                            done = True
+
+            returncode = process.wait()
+            if error is not None:
+                raise error
+            if returncode == -signal.SIGXCPU:
+                raise SandboxError(f"Analysis exceeded {self.limits.cpu_seconds}s of CPU", "cpu")
+            if returncode == -signal.SIGKILL:
+                raise SandboxError("Analysis was killed at its CPU hard limit or out of memory", "killed")
+            if returncode != 0 or not done:
+                stderr.seek(0)
+                tail = stderr.read()[-2000:].decode(errors="replace").strip()
+                raise SandboxError(f"Analysis exited with status {returncode}: {tail}", "failed")
+        except SandboxError as e:
+            logger.error("sandbox_failed", root=str(self.root), reason=e.reason, error=str(e))
+            raise
+        finally:
+            if process.poll() is None:
+                os.killpg(process.pid, signal.SIGKILL)
+                process.wait()
+            process.stdout.close()
+            stderr.close()
+
+
+def run_pass(
+    root: str | Path, func: Callable[..., Any], sandbox: Sandbox | None = None, **kwargs
+) -> Iterator[Any]:
+    """Run a pass over a checkout, sandboxed unless SCAN_SANDBOX_ENABLED is off.
+
+    Every service parsing a clone goes through here, so repository content is
+    only parsed in the sandbox; the evidence viewer, which returns a file's
+    lines as text, is the one reader outside it.
+
+    Args:
+        root: Checkout root
+        func: Module-level function taking the checkout root and kwargs,
+            yielding JSON-serializable items
+        sandbox: Sandbox to run in, for other limits or to read its isolation
+            afterwards; one with the configured limits by default
+        **kwargs: JSON-serializable arguments
+
+    Returns:
+        What the pass yields
+    """
+    from app.core.config import settings
+
+    if not settings.SCAN_SANDBOX_ENABLED:
+        return func(str(root), **kwargs)
+    return (sandbox or Sandbox(root)).run(func, **kwargs)
+
+
+def _mount(libc, source: str | None, target: str | None, flags: int) -> None:
+    """mount(2) without a filesystem type or data."""
+    source_path, target_path = (p.encode() if p is not None else None for p in (source, target))
+    if libc.mount(source_path, target_path, None, flags, None) != 0:
+        errno = ctypes.get_errno()
+        raise OSError(errno, os.strerror(errno))
+
+
+def _enter_namespaces(root: str) -> None:
+    """Move into new user, network, and mount namespaces, with the checkout read-only.
+
+    Raises:
+        OSError: If the kernel or the container runtime does not allow it
+    """
+    uid, gid = os.getuid(), os.getgid()
+    os.unshare(os.CLONE_NEWUSER | os.CLONE_NEWNET | os.CLONE_NEWNS)
+    # Keep the same user inside, so the checkout's permissions apply as before
+    Path("/proc/self/setgroups").write_text("deny")
+    Path("/proc/self/uid_map").write_text(f"{uid} {uid} 1")
+    Path("/proc/self/gid_map").write_text(f"{gid} {gid} 1")
+
+    libc = ctypes.CDLL(None, use_errno=True)
+    _mount(libc, None, "/", MS_REC | MS_PRIVATE)
+    _mount(libc, root, root, MS_BIND | MS_REC)
+    flags = os.statvfs(root).f_flag
+    kept = flags & KEPT_MOUNT_FLAGS | (MS_RELATIME if flags & ST_RELATIME else 0)
+    _mount(libc, None, root, MS_REMOUNT | MS_BIND | MS_RDONLY | kept)
+
+
+def _block_network() -> None:
+    """Make Python-level connections and name lookups fail."""
+
+    def blocked(*args, **kwargs):
+        raise SandboxError("Network access is not available to repository analysis", "network")
+
+    socket.socket.connect = blocked
+    socket.socket.connect_ex = blocked
+    socket.socket.sendto = blocked
+    socket.getaddrinfo = blocked
+    socket.create_connection = blocked
+
+
+def isolate(root: str, limits: SandboxLimits) -> list[str]:
+    """Isolate the current process for analyzing a checkout.
+
+    Returns:
+        The isolation applied
+
+    Raises:
+        SandboxError: If namespaces are required but unavailable
+    """
+    isolation = ["minimal_environment"]
+    try:
+        _enter_namespaces(root)
+        isolation += ["network_namespace", "read_only_mount"]
+    except (AttributeError, OSError) as e:
+        if limits.require_namespaces:
+            raise SandboxError(f"Namespaces unavailable: {e}", "isolation") from e
+
+    _block_network()
+    isolation.append("socket_guard")
+
+    memory = limits.memory_mb * 1024 * 1024
+    resource.setrlimit(resource.RLIMIT_CPU, (limits.cpu_seconds, limits.cpu_seconds + 5))
+    resource.setrlimit(resource.RLIMIT_AS, (memory, memory))
+    resource.setrlimit(resource.RLIMIT_NOFILE, (limits.open_files, limits.open_files))
+    resource.setrlimit(resource.RLIMIT_FSIZE, (0, 0))
+    resource.setrlimit(resource.RLIMIT_CORE, (0, 0))
+    if not limits.processes:
+        resource.setrlimit(resource.RLIMIT_NPROC, (0, 0))
+    isolation.append("resource_limits")
+    return isolation
+
+
+def _emit(message: dict) -> None:
+    """Write a message to the parent."""
+    sys.stdout.write(json.dumps(message) + "\n")
+    sys.stdout.flush()
+
+
+def main() -> None:
+    """Child entrypoint: isolate, run the requested pass, and stream its items."""
+    request = json.loads(sys.stdin.readline())
+    limits = SandboxLimits(**request["limits"])
+    try:
+        _emit({"isolation": isolate(request["root"], limits)})
+        module, name = request["target"].split(":")
+        func = getattr(importlib.import_module(module), name)
+        for item in func(request["root"], **request["kwargs"]):
+            _emit({"item": item})
+    except SandboxError as e:
+        _emit({"error": str(e), "reason": e.reason})
+        os._exit(1)
+    except MemoryError:
+        _emit({"error": f"Analysis exceeded {limits.memory_mb}MB of memory", "reason": "memory"})
+        os._exit(1)
+    except Exception as e:
+        _emit({"error": f"{type(e).__name__}: {e}", "reason": "failed"})
+        os._exit(1)
+    _emit({"done": True})
+    os._exit(0)
+
+
+if __name__ == "__main__":
+    main()
@@ -8,6 +8,7 @@ from fastapi import FastAPI, Request, Response
 from fastapi.middleware.cors import CORSMiddleware
 
 from app.api.v1 import api_router
+from app.core.air_gap import install_egress_guard, is_air_gapped
 from app.core.config import settings
 from app.core.metrics import get_metrics, record_api_request
 
@@ -24,6 +25,10 @@ structlog.configure(
 
 logger = structlog.get_logger()
 
+# Block outbound connections before any client or SDK opens a socket
+if is_air_gapped():
+    install_egress_guard()
+
 app = FastAPI(
     title="Policy Miner API",
     description="Application Security Policy Mining and Analysis",
@@ -71,7 +76,7 @@ app.include_router(api_router, prefix="/api/v1")
 async def health_check():
     """Health check endpoint."""
     logger.info("health_check_called")
-    return {"status": "healthy", "service": "policy-miner-api"}
+    return {"status": "healthy", "service": "policy-miner-api", "air_gapped": is_air_gapped()}
 
 
 @app.get("/metrics")
@@ -89,7 +94,7 @@ async def metrics():
 @app.on_event("startup")
 async def startup_event():
     """Run on application startup."""
-    logger.info("application_starting", version="0.1.0")
+    logger.info("application_starting", version="0.1.0", air_gapped=is_air_gapped())
 
     # Create database tables
     # Import all models to ensure they're registered with Base
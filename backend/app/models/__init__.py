@@ -1,21 +1,62 @@
 """Database models."""
+from app.models.access_review import (
+    AccessReviewCampaign,
+    AccessReviewItem,
+    AccessReviewPacket,
+    AttestationDecision,
+    CampaignStatus,
+    PacketStatus,
+)
 from app.models.application import Application, CriticalityLevel
 from app.models.audit_log import AuditEventType, AuditLog
 from app.models.auto_approval import AutoApprovalDecision, AutoApprovalSettings
+from app.models.bundle_publication import (
+    BundlePublication,
+    BundleSigningAlgorithm,
+    BundleTarget,
+    BundleTargetType,
+    PublicationStatus,
+)
 from app.models.code_advisory import AdvisoryStatus, CodeAdvisory
 from app.models.conflict import ConflictStatus, ConflictType, PolicyConflict
+from app.models.custom_rule import CustomRule, CustomRuleHit, CustomRuleVersion
+from app.models.dashboard_widget import DashboardWidget
 from app.models.duplicate_policy_group import (
     DuplicateGroupStatus,
     DuplicatePolicyGroup,
     DuplicatePolicyGroupMember,
 )
+from app.models.false_positive import FalsePositiveMark, FalsePositiveTarget, SuppressionPattern
+from app.models.identity_model import IdentityModel
+from app.models.idp_connector import (
+    IdpConnector,
+    IdpConnectorType,
+    IdpRoleDrift,
+    RoleDriftKind,
+    RoleDriftStatus,
+)
+from app.models.idp_group import IdpGroup, IdpGroupRoleMapping
+from app.models.incident_alert import (
+    AuthRegression,
+    AuthRegressionStatus,
+    IncidentConnector,
+    IncidentProvider,
+    IncidentStatus,
+    RegressionIncident,
+    RouteProtectionObservation,
+)
 from app.models.inconsistent_enforcement import (
     InconsistentEnforcement,
     InconsistentEnforcementSeverity,
     InconsistentEnforcementStatus,
 )
+from app.models.itsm_connector import FindingTicket, FindingTicketStatus, ItsmConnector, ItsmConnectorType
+from app.models.lint import LintCheck, LintRule, LintRun
 from app.models.organization import BusinessUnit, Division, Organization
+from app.models.ownership import OwnershipSource, PolicyOwnership
+from app.models.pdp_migration import CallSiteStatus, MigrationCallSite, MigrationProgressSnapshot
 from app.models.policy import Evidence, Policy, PolicyStatus, RiskLevel, SourceType
+from app.models.policy_annotation import PolicyAnnotationRecord
 from app.models.policy_change import (
     ChangeType,
     PolicyChange,
@@ -30,9 +71,19 @@ from app.models.provisioning import (
     ProvisioningOperation,
     ProvisioningStatus,
 )
+from app.models.readiness import ReadinessAssessment
 from app.models.repository import DatabaseType, Repository, RepositoryStatus, RepositoryType
+from app.models.role_assignment import RoleAssignment
+from app.models.role_parameter import RoleParameterBinding, RoleParameterUsage
+from app.models.saved_view import SavedView
+from app.models.scaffold_pull_request import ScaffoldFormat, ScaffoldPullRequest, ScaffoldPullRequestStatus
+from app.models.scan_environment import EnvironmentSource, ScanEnvironment
+from app.models.scan_metrics import ScanMetricsSnapshot
 from app.models.scan_progress import ScanProgress, ScanStatus
+from app.models.scan_schedule import ScanSchedule
+from app.models.siem_connector import SiemConnector, SiemDestination, SiemFindingState, SiemFormat
 from app.models.tenant import Tenant
+from app.models.threshold import ThresholdChange, ThresholdChangeStatus, ThresholdObservation
 from app.models.user import User
 
 __all__ = [
@@ -81,4 +132,69 @@ __all__ = [
     "DuplicatePolicyGroup",
     "DuplicatePolicyGroupMember",
     "DuplicateGroupStatus",
+    "RoleAssignment",
+    "IdpGroup",
+    "IdpGroupRoleMapping",
+    "IdpConnector",
+    "IdpConnectorType",
+    "IdpRoleDrift",
+    "RoleDriftKind",
+    "RoleDriftStatus",
+    "BundleTarget",
+    "BundleTargetType",
+    "BundleSigningAlgorithm",
+    "BundlePublication",
+    "PublicationStatus",
+    "AccessReviewCampaign",
+    "AccessReviewPacket",
+    "AccessReviewItem",
+    "CampaignStatus",
+    "PacketStatus",
+    "AttestationDecision",
+    "ScanMetricsSnapshot",
+    "SavedView",
+    "DashboardWidget",
+    "SuppressionPattern",
+    "FalsePositiveMark",
+    "FalsePositiveTarget",
+    "LintRule",
+    "LintRun",
+    "LintCheck",
+    "ScanEnvironment",
+    "EnvironmentSource",
+    "ThresholdObservation",
+    "ThresholdChange",
+    "ThresholdChangeStatus",
+    "PolicyOwnership",
+    "OwnershipSource",
+    "ReadinessAssessment",
+    "CustomRule",
+    "CustomRuleVersion",
+    "CustomRuleHit",
+    "RoleParameterBinding",
+    "RoleParameterUsage",
+    "PolicyAnnotationRecord",
+    "ScaffoldPullRequest",
+    "ScaffoldFormat",
+    "ScaffoldPullRequestStatus",
+    "MigrationCallSite",
+    "MigrationProgressSnapshot",
+    "CallSiteStatus",
+    "IdentityModel",
+    "ItsmConnector",
+    "ItsmConnectorType",
+    "FindingTicket",
+    "FindingTicketStatus",
+    "RouteProtectionObservation",
+    "AuthRegression",
+    "AuthRegressionStatus",
+    "IncidentConnector",
+    "IncidentProvider",
+    "IncidentStatus",
+    "RegressionIncident",
+    "SiemConnector",
+    "SiemDestination",
+    "SiemFindingState",
+    "SiemFormat",
+    "ScanSchedule",
 ]
@@ -0,0 +1,103 @@
+"""Access review campaign models for periodic attestation of mined access."""
+
+from datetime import UTC, datetime
+from enum import Enum
+
+from sqlalchemy import JSON, Column, DateTime, ForeignKey, Integer, String, Text
+from sqlalchemy import Enum as SAEnum
+from sqlalchemy.orm import relationship
+
+from .repository import Base
+
+
+class CampaignStatus(str, Enum):
+    """Status of an access review campaign."""
+
+    OPEN = "open"  # Packets sent, awaiting attestations
+    COMPLETED = "completed"  # Every item attested
+    CANCELLED = "cancelled"
+
+
+class PacketStatus(str, Enum):
+    """Status of one reviewer's packet."""
+
+    PENDING = "pending"
+    COMPLETED = "completed"
+
+
+class AttestationDecision(str, Enum):
+    """Reviewer decision on one access item."""
+
+    PENDING = "pending"  # Not yet reviewed
+    CERTIFIED = "certified"  # Access is appropriate
+    REVOKE = "revoke"  # Access should be removed
+    MODIFY = "modify"  # Access should be narrowed (see comment)
+
+
+class AccessReviewCampaign(Base):
+    """A periodic access review (e.g., Q3 2026 quarterly review)."""
+
+    __tablename__ = "access_review_campaigns"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)
+    description = Column(Text, nullable=True)
+    status = Column(SAEnum(CampaignStatus), nullable=False, default=CampaignStatus.OPEN)
+    scope = Column(JSON, nullable=True)  # Filters used to generate packets (application_ids, include_pending)
+    due_date = Column(DateTime(timezone=True), nullable=True)
+    created_by = Column(String(255), nullable=True)
+
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC))
+    completed_at = Column(DateTime(timezone=True), nullable=True)
+
+    packets = relationship("AccessReviewPacket", back_populates="campaign", cascade="all, delete-orphan")
+
+
+class AccessReviewPacket(Base):
+    """The items one resource owner must attest within a campaign."""
+
+    __tablename__ = "access_review_packets"
+
+    id = Column(Integer, primary_key=True, index=True)
+    campaign_id = Column(
+        Integer, ForeignKey("access_review_campaigns.id", ondelete="CASCADE"), nullable=False, index=True
+    )
+    reviewer = Column(String(255), nullable=False, index=True)  # Application owner, or "unassigned"
+    application_id = Column(Integer, ForeignKey("applications.id", ondelete="SET NULL"), nullable=True)
+    application_name = Column(String(255), nullable=True)
+    status = Column(SAEnum(PacketStatus), nullable=False, default=PacketStatus.PENDING)
+
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC))
+    completed_at = Column(DateTime(timezone=True), nullable=True)
+
+    campaign = relationship("AccessReviewCampaign", back_populates="packets")
+    items = relationship("AccessReviewItem", back_populates="packet", cascade="all, delete-orphan")
+
+
+class AccessReviewItem(Base):
+    """One "who can do what" statement to attest, snapshotted from a policy."""
+
+    __tablename__ = "access_review_items"
+
+    id = Column(Integer, primary_key=True, index=True)
+    packet_id = Column(
+        Integer, ForeignKey("access_review_packets.id", ondelete="CASCADE"), nullable=False, index=True
+    )
+    policy_id = Column(Integer, ForeignKey("policies.id", ondelete="SET NULL"), nullable=True, index=True)
+
+    # Snapshot of the policy at generation time, so later rescans don't rewrite history
+    subject = Column(String(500), nullable=False)
+    resource = Column(String(500), nullable=False)
+    action = Column(String(500), nullable=False)
+    conditions = Column(Text, nullable=True)
+    evidence = Column(JSON, nullable=True)  # [{file_path, line_start, line_end, code_snippet}]
+
+    # Attestation
+    decision = Column(SAEnum(AttestationDecision), nullable=False, default=AttestationDecision.PENDING)
+    reviewed_by = Column(String(255), nullable=True)
+    review_comment = Column(Text, nullable=True)
+    reviewed_at = Column(DateTime(timezone=True), nullable=True)
+
+    packet = relationship("AccessReviewPacket", back_populates="items")
@@ -0,0 +1,92 @@
+"""Bundle publication models for pushing approved policies to enforcement."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import Boolean, Column, DateTime, Float, ForeignKey, Integer, String, Text
+from sqlalchemy import Enum as SAEnum
+from sqlalchemy.dialects.postgresql import JSONB
+
+from app.models.encrypted_types import EncryptedString
+
+from .repository import Base
+
+
+class BundleTargetType(str, enum.Enum):
+    """Where bundles are published."""
+
+    OPA_BUNDLE_SERVER = "opa_bundle_server"  # HTTP endpoint OPA's bundle plugin polls
+    OCI_REGISTRY = "oci_registry"  # OCI distribution registry (OPA pulls with type: oci)
+
+
+class BundleSigningAlgorithm(str, enum.Enum):
+    """JWT algorithms for OPA bundle signatures."""
+
+    HS256 = "HS256"
+    RS256 = "RS256"
+    ES256 = "ES256"
+
+
+class PublicationStatus(str, enum.Enum):
+    """Outcome of a bundle publication."""
+
+    PENDING = "pending"
+    SUCCESS = "success"
+    FAILED = "failed"
+
+
+class BundleTarget(Base):
+    """A bundle server or registry approved policies are published to."""
+
+    __tablename__ = "bundle_targets"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)
+    target_type = Column(SAEnum(BundleTargetType), nullable=False)
+    url = Column(String(1000), nullable=False)  # Bundle upload URL, or registry base URL
+    repository = Column(String(500), nullable=True)  # OCI repository path (e.g., policies/authz)
+    bundle_name = Column(String(255), nullable=False, default="policy-miner")
+    username = Column(String(255), nullable=True)  # Registry user; bundle servers use the token alone
+    secret = Column(EncryptedString(2000), nullable=True)  # Bearer token or registry password
+
+    signing_algorithm = Column(SAEnum(BundleSigningAlgorithm), nullable=True)  # Unsigned if null
+    signing_key = Column(EncryptedString(8000), nullable=True)  # HMAC secret or PEM private key
+    signing_key_id = Column(String(255), nullable=True)  # keyid OPA looks up in its keys config
+
+    application_id = Column(Integer, ForeignKey("applications.id", ondelete="CASCADE"), nullable=True, index=True)
+    auto_publish = Column(Boolean, default=False, nullable=False)  # Publish whenever a policy is approved
+    min_confidence = Column(Float, nullable=True)  # Leave out policies with a lower confidence score
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<BundleTarget {self.target_type.value}:{self.name}>"
+
+
+class BundlePublication(Base):
+    """One versioned bundle pushed to a target."""
+
+    __tablename__ = "bundle_publications"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    target_id = Column(Integer, ForeignKey("bundle_targets.id", ondelete="CASCADE"), nullable=False, index=True)
+
+    version = Column(Integer, nullable=False)  # Increments per target
+    revision = Column(String(255), nullable=False)  # Written to the bundle manifest
+    status = Column(SAEnum(PublicationStatus), default=PublicationStatus.PENDING, nullable=False)
+    policy_ids = Column(JSONB, nullable=False, default=list)
+    digest = Column(String(100), nullable=True)  # sha256 of the bundle archive
+    location = Column(String(1000), nullable=True)  # URL or OCI reference published to
+    signed = Column(Boolean, default=False, nullable=False)
+    triggered_by = Column(String(255), nullable=True)  # User email, or "approval" for auto-publish
+    error_message = Column(Text, nullable=True)
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    published_at = Column(DateTime(timezone=True), nullable=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<BundlePublication target={self.target_id} v{self.version} {self.status.value}>"
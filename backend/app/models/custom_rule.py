@@ -0,0 +1,92 @@
+"""Custom detection rule models: workspace rules, their versions, and per-scan hits."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Boolean, Column, DateTime, ForeignKey, Integer, String, Text, UniqueConstraint
+
+from .repository import Base
+
+
+class CustomRule(Base):
+    """A workspace-defined pattern that detects authorization checks in source.
+
+    The pattern is a regular expression matched line by line against the
+    sources in scope; named groups "role", "permission", "resource",
+    "action", and "subject" are reported with each match. Every change
+    bumps version and records a CustomRuleVersion.
+    """
+
+    __tablename__ = "custom_rules"
+    __table_args__ = (UniqueConstraint("tenant_id", "name", name="uq_custom_rule_name"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)  # e.g., "require-permission-helper"
+    description = Column(Text, nullable=True)
+    pattern = Column(Text, nullable=False)  # e.g., r"requirePermission\(['\"](?P<permission>[^'\"]+)"
+    languages = Column(JSON, nullable=False, default=list)  # e.g., ["Go", "Python"]; empty for every language
+    paths = Column(JSON, nullable=False, default=list)  # File globs, e.g., ["src/**"]; empty for every file
+    exclude_paths = Column(JSON, nullable=False, default=list)
+    severity = Column(String(20), nullable=False, default="medium")  # low, medium, high, critical
+    enabled = Column(Boolean, nullable=False, default=True)
+    version = Column(Integer, nullable=False, default=1)
+
+    created_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    updated_at = Column(
+        DateTime(timezone=True),
+        default=lambda: datetime.now(UTC),
+        onupdate=lambda: datetime.now(UTC),
+    )
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<CustomRule {self.name} v{self.version}>"
+
+
+class CustomRuleVersion(Base):
+    """A custom rule's definition as of one version."""
+
+    __tablename__ = "custom_rule_versions"
+    __table_args__ = (UniqueConstraint("rule_id", "version", name="uq_custom_rule_version"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    rule_id = Column(Integer, ForeignKey("custom_rules.id", ondelete="CASCADE"), nullable=False, index=True)
+    version = Column(Integer, nullable=False)
+
+    definition = Column(JSON, nullable=False)  # Name, pattern, scope, severity, and enabled at this version
+    change = Column(String(20), nullable=False)  # created, updated, synced, restored
+    changed_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<CustomRuleVersion rule={self.rule_id} v{self.version}>"
+
+
+class CustomRuleHit(Base):
+    """How often one rule matched in one scan of a repository.
+
+    Recorded for every enabled rule when a scan completes, including rules
+    that did not match, so hit rates cover every scan a rule was evaluated in.
+    """
+
+    __tablename__ = "custom_rule_hits"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    rule_id = Column(Integer, ForeignKey("custom_rules.id", ondelete="CASCADE"), nullable=False, index=True)
+    rule_version = Column(Integer, nullable=False)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)
+
+    hits = Column(Integer, nullable=False, default=0)
+    files = Column(Integer, nullable=False, default=0)
+    matches = Column(JSON, nullable=False, default=list)  # First matches: file_path, line, snippet, captures
+
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<CustomRuleHit rule={self.rule_id} repo={self.repository_id} hits={self.hits}>"
@@ -0,0 +1,33 @@
+"""Dashboard widget model for configurable dashboards."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, Integer, String
+
+from .repository import Base
+
+
+class DashboardWidget(Base):
+    """A widget on a user's or the workspace's dashboard."""
+
+    __tablename__ = "dashboard_widgets"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    owner_email = Column(String(255), nullable=True, index=True)  # Null for workspace-wide widgets
+
+    title = Column(String(255), nullable=False)
+    metric = Column(String(100), nullable=False)  # e.g., "policies_by_risk", "coverage_trend"
+    chart_type = Column(String(50), nullable=False)  # e.g., "number", "bar", "line"
+    filters = Column(JSON, nullable=False, default=dict)  # Scope, e.g., {"repository_id": 3, "weeks": 8}
+    position = Column(Integer, nullable=False, default=0)  # Order on the dashboard
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    updated_at = Column(
+        DateTime(timezone=True),
+        default=lambda: datetime.now(UTC),
+        onupdate=lambda: datetime.now(UTC),
+    )
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<DashboardWidget {self.title}: {self.metric}/{self.chart_type}>"
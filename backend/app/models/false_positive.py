@@ -0,0 +1,76 @@
+"""False-positive feedback models for suppressing repeated mis-detections."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import Boolean, Column, DateTime, ForeignKey, Integer, String, Text, UniqueConstraint
+from sqlalchemy import Enum as SAEnum
+from sqlalchemy.orm import relationship
+
+from .repository import Base
+
+
+class FalsePositiveTarget(str, enum.Enum):
+    """What was marked as a false positive."""
+
+    POLICY = "policy"  # A mined rule
+    SECURITY_GAP = "security_gap"  # PolicyFix
+    CONFLICT = "conflict"  # PolicyConflict
+    INCONSISTENT_ENFORCEMENT = "inconsistent_enforcement"
+
+
+class SuppressionPattern(Base):
+    """A structural pattern a workspace has marked as a false positive.
+
+    Mined rules and findings produced later by the same analyzer with the
+    same pattern are dismissed automatically, in any repository of the workspace.
+    """
+
+    __tablename__ = "suppression_patterns"
+    __table_args__ = (UniqueConstraint("tenant_id", "analyzer", "fingerprint", name="uq_suppression_pattern"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    analyzer = Column(String(100), nullable=False, index=True)  # e.g., "llm_scanner", "k8s_manifests", "conflict"
+    target_type = Column(SAEnum(FalsePositiveTarget), nullable=False)
+    fingerprint = Column(String(64), nullable=False, index=True)  # SHA-256 of analyzer and pattern
+    pattern = Column(Text, nullable=False)  # Code skeleton the fingerprint was taken of
+    reason = Column(Text, nullable=True)
+    active = Column(Boolean, nullable=False, default=True)
+
+    suppressed_count = Column(Integer, nullable=False, default=0)  # Detections dismissed automatically
+    last_suppressed_at = Column(DateTime(timezone=True), nullable=True)
+
+    created_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    marks = relationship("FalsePositiveMark", back_populates="pattern", cascade="all, delete-orphan")
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<SuppressionPattern {self.id}: {self.analyzer}>"
+
+
+class FalsePositiveMark(Base):
+    """One user decision that a rule or finding is a false positive."""
+
+    __tablename__ = "false_positive_marks"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    pattern_id = Column(Integer, ForeignKey("suppression_patterns.id", ondelete="CASCADE"), nullable=False, index=True)
+
+    analyzer = Column(String(100), nullable=False, index=True)
+    target_type = Column(SAEnum(FalsePositiveTarget), nullable=False)
+    target_id = Column(Integer, nullable=False)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="SET NULL"), nullable=True)
+    reason = Column(Text, nullable=True)
+
+    marked_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    pattern = relationship("SuppressionPattern", back_populates="marks")
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<FalsePositiveMark {self.target_type.value} {self.target_id}>"
@@ -0,0 +1,34 @@
+"""Identity model declaration: what a subject is in a workspace."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, Integer, String
+
+from .repository import Base
+
+
+class IdentityModel(Base):
+    """Declared subject types and their attributes, one per workspace.
+
+    Mined conditions are mapped onto these attributes before export, so the
+    generated policies name attributes the workspace's PDP actually supplies.
+    """
+
+    __tablename__ = "identity_models"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, unique=True, index=True)
+
+    # e.g., [{"name": "user", "identifier": "id", "attributes": [{"name": "department", "type": "string", "aliases": ["dept"]}]}]
+    subject_types = Column(JSON, nullable=False, default=list)
+
+    updated_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    updated_at = Column(
+        DateTime(timezone=True),
+        default=lambda: datetime.now(UTC),
+        onupdate=lambda: datetime.now(UTC),
+    )
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<IdentityModel tenant={self.tenant_id} subject_types={len(self.subject_types or [])}>"
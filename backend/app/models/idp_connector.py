@@ -0,0 +1,85 @@
+"""IdP connector models for live role sync and role drift tracking."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import Column, DateTime, ForeignKey, Integer, String, Text
+from sqlalchemy import Enum as SAEnum
+from sqlalchemy.dialects.postgresql import JSONB
+
+from app.models.encrypted_types import EncryptedString
+
+from .repository import Base
+
+
+class IdpConnectorType(str, enum.Enum):
+    """Identity providers with a role sync connector."""
+
+    KEYCLOAK = "keycloak"
+    AUTH0 = "auth0"
+
+
+class RoleDriftKind(str, enum.Enum):
+    """Direction of a role mismatch between the IdP and code."""
+
+    UNUSED_IN_CODE = "unused_in_code"  # Defined in the IdP, never checked in code
+    MISSING_FROM_IDP = "missing_from_idp"  # Checked in code, nobody can be granted it
+
+
+class RoleDriftStatus(str, enum.Enum):
+    """Lifecycle of a drift finding."""
+
+    OPEN = "open"  # Seen in the latest sync
+    RESOLVED = "resolved"  # No longer drifting as of a later sync
+    DISMISSED = "dismissed"  # Accepted; stays quiet in later syncs
+
+
+class IdpConnector(Base):
+    """A Keycloak realm or Auth0 tenant whose role definitions are synced."""
+
+    __tablename__ = "idp_connectors"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)
+    connector_type = Column(SAEnum(IdpConnectorType), nullable=False)
+    base_url = Column(String(500), nullable=False)  # Keycloak server URL or Auth0 domain URL
+    realm = Column(String(255), nullable=True)  # Keycloak realm
+    client_id = Column(String(255), nullable=False)  # Client-credentials client for the admin API
+    client_secret = Column(EncryptedString(1000), nullable=False)
+    role_client_id = Column(String(255), nullable=True)  # Keycloak client whose client roles to include
+    application_id = Column(Integer, ForeignKey("applications.id", ondelete="CASCADE"), nullable=True, index=True)
+
+    sync_interval_minutes = Column(Integer, nullable=True)  # Falls back to IDP_ROLE_SYNC_INTERVAL_MINUTES
+    last_synced_at = Column(DateTime(timezone=True), nullable=True)
+    last_error = Column(Text, nullable=True)
+    idp_roles = Column(JSONB, nullable=True)  # Role names from the latest successful sync
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<IdpConnector {self.connector_type.value}:{self.name}>"
+
+
+class IdpRoleDrift(Base):
+    """A role defined on one side (IdP or code) but not the other."""
+
+    __tablename__ = "idp_role_drifts"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    connector_id = Column(Integer, ForeignKey("idp_connectors.id", ondelete="CASCADE"), nullable=False, index=True)
+
+    role = Column(String(255), nullable=False)  # Normalized role name
+    kind = Column(SAEnum(RoleDriftKind), nullable=False, index=True)
+    details = Column(JSONB, nullable=True)  # IdP description or policy IDs referencing the role
+    status = Column(SAEnum(RoleDriftStatus), default=RoleDriftStatus.OPEN, nullable=False, index=True)
+
+    first_detected_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    last_seen_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    resolved_at = Column(DateTime(timezone=True), nullable=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<IdpRoleDrift {self.role}: {self.kind.value}>"
@@ -0,0 +1,47 @@
+"""Identity provider group models for tracing roles to user populations."""
+from datetime import UTC, datetime
+
+from sqlalchemy import Column, DateTime, ForeignKey, Integer, String
+from sqlalchemy.dialects.postgresql import JSONB
+
+from .repository import Base
+
+
+class IdpGroup(Base):
+    """A group and its resolved members, ingested from an IdP export or API."""
+
+    __tablename__ = "idp_groups"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    source = Column(String(50), nullable=False, index=True)  # okta, entra, ldap
+    external_id = Column(String(512), nullable=True)  # IdP group ID or LDAP DN
+    name = Column(String(255), nullable=False, index=True)
+    members = Column(JSONB, nullable=False, default=list)  # User identifiers, nested groups expanded
+
+    synced_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<IdpGroup {self.source}:{self.name} ({len(self.members or [])} members)>"
+
+
+class IdpGroupRoleMapping(Base):
+    """Grants a code-level role to every member of an IdP group."""
+
+    __tablename__ = "idp_group_role_mappings"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    source = Column(String(50), nullable=True)  # Restrict to one IdP; any source if null
+    group_name = Column(String(255), nullable=False, index=True)
+    role = Column(String(255), nullable=False)  # Stored upper-case to match mined roles
+    application_id = Column(Integer, ForeignKey("applications.id", ondelete="CASCADE"), nullable=True, index=True)
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<IdpGroupRoleMapping {self.group_name} -> {self.role}>"
@@ -0,0 +1,130 @@
+"""Incident alerting models: route protection observed per scan, auth regressions, and their incidents."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, ForeignKey, Integer, String, Text, UniqueConstraint
+from sqlalchemy import Enum as SAEnum
+
+from app.models.encrypted_types import EncryptedString
+
+from .repository import Base
+
+
+class IncidentProvider(str, enum.Enum):
+    """On-call services incidents are triggered in."""
+
+    PAGERDUTY = "pagerduty"  # Events API v2, secret is the integration routing key
+    OPSGENIE = "opsgenie"  # Alert API, secret is the API integration key
+
+
+class RouteProtectionObservation(Base):
+    """How a registered route was protected as one scan saw it."""
+
+    __tablename__ = "route_protection_observations"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True, index=True)
+
+    endpoint = Column(String(1000), nullable=False)  # e.g. "DELETE /api/expenses/{}"
+    protection = Column(String(20), nullable=True)  # auth_gap_service.Protection, or None when unprotected
+    exposure = Column(String(20), nullable=False)  # exposure_service.Exposure
+    file_path = Column(String(1000), nullable=False)
+    line = Column(Integer, nullable=False)
+    code = Column(Text, nullable=False)  # The registration, shortened
+    policy_ids = Column(JSON, nullable=True)  # Mined policies mapped to the route
+
+    observed_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<RouteProtectionObservation {self.endpoint}: {self.protection or 'none'}>"
+
+
+class AuthRegressionStatus(str, enum.Enum):
+    """State of an auth regression."""
+
+    OPEN = "open"  # The route is still unprotected
+    RESOLVED = "resolved"  # A later scan saw the route protected again, or it was removed
+
+
+class AuthRegression(Base):
+    """Critical finding raised when a scan sees an internet-facing route lose its authentication."""
+
+    __tablename__ = "auth_regressions"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)
+    previous_scan_id = Column(Integer, nullable=True)
+
+    endpoint = Column(String(1000), nullable=False, index=True)
+    previous_protection = Column(String(20), nullable=False)  # How the route was protected before
+    exposure = Column(String(20), nullable=False)
+    evidence = Column(JSON, nullable=False)  # {"before": {...}, "after": {...}} registrations with file, line, code
+    description = Column(Text, nullable=False)
+
+    status = Column(SAEnum(AuthRegressionStatus), default=AuthRegressionStatus.OPEN, nullable=False, index=True)
+    detected_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    resolved_at = Column(DateTime(timezone=True), nullable=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<AuthRegression {self.endpoint}: {self.status.value}>"
+
+
+class IncidentConnector(Base):
+    """A PagerDuty service or Opsgenie team that auth regressions page."""
+
+    __tablename__ = "incident_connectors"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)
+    provider = Column(SAEnum(IncidentProvider), nullable=False)
+    secret = Column(EncryptedString(1000), nullable=False)
+    base_url = Column(String(500), nullable=True)  # e.g. https://api.eu.opsgenie.com; provider default when unset
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=True, index=True)
+
+    last_triggered_at = Column(DateTime(timezone=True), nullable=True)
+    last_error = Column(Text, nullable=True)
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<IncidentConnector {self.provider.value}:{self.name}>"
+
+
+class IncidentStatus(str, enum.Enum):
+    """Delivery state of a regression's incident."""
+
+    TRIGGERED = "triggered"
+    RESOLVED = "resolved"
+    FAILED = "failed"  # The provider could not be reached; retried on the next scan or on demand
+
+
+class RegressionIncident(Base):
+    """The incident a connector's provider holds for one auth regression."""
+
+    __tablename__ = "regression_incidents"
+    __table_args__ = (UniqueConstraint("connector_id", "regression_id", name="uq_regression_incident"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    connector_id = Column(Integer, ForeignKey("incident_connectors.id", ondelete="CASCADE"), nullable=False, index=True)
+    regression_id = Column(Integer, ForeignKey("auth_regressions.id", ondelete="CASCADE"), nullable=False, index=True)
+
+    dedup_key = Column(String(255), nullable=False)  # PagerDuty dedup_key, Opsgenie alias
+    status = Column(SAEnum(IncidentStatus), nullable=False, index=True)
+    error = Column(Text, nullable=True)
+
+    triggered_at = Column(DateTime(timezone=True), nullable=True)
+    resolved_at = Column(DateTime(timezone=True), nullable=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<RegressionIncident {self.dedup_key}: {self.status.value}>"
@@ -0,0 +1,87 @@
+"""ITSM connector models for syncing findings with tickets in ServiceNow or another ITSM system."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import Column, DateTime, ForeignKey, Integer, String, Text, UniqueConstraint
+from sqlalchemy import Enum as SAEnum
+
+from app.models.encrypted_types import EncryptedString
+
+from .repository import Base
+
+
+class ItsmConnectorType(str, enum.Enum):
+    """ITSM systems with a connector."""
+
+    SERVICENOW = "servicenow"  # Table API, incidents or any task table
+    GENERIC = "generic"  # Any ITSM behind the generic ticket REST contract
+
+
+class FindingTicketStatus(str, enum.Enum):
+    """State of a finding as tracked through its ticket."""
+
+    OPEN = "open"  # Detected in the latest scan, ticket open
+    RISK_ACCEPTED = "risk_accepted"  # Still detected, risk accepted in the ITSM
+    RESOLVED = "resolved"  # No longer detected, ticket resolved
+
+
+class ItsmConnector(Base):
+    """A ServiceNow instance or generic ITSM endpoint that findings are ticketed in."""
+
+    __tablename__ = "itsm_connectors"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)
+    connector_type = Column(SAEnum(ItsmConnectorType), nullable=False)
+    base_url = Column(String(500), nullable=False)  # ServiceNow instance URL or generic ticket API root
+    username = Column(String(255), nullable=True)  # Basic auth user; without one the secret is a bearer token
+    secret = Column(EncryptedString(1000), nullable=False)
+    table = Column(String(100), nullable=True)  # ServiceNow table tickets are created in (default incident)
+    assignment_group = Column(String(255), nullable=True)  # ServiceNow group name or sys_id
+    risk_accepted_code = Column(String(100), nullable=True)  # ServiceNow close code meaning the risk was accepted
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=True, index=True)
+    min_severity = Column(String(20), nullable=False, default="high")  # Least severe finding ticketed
+
+    sync_interval_minutes = Column(Integer, nullable=True)  # Falls back to ITSM_SYNC_INTERVAL_MINUTES
+    last_synced_at = Column(DateTime(timezone=True), nullable=True)
+    last_error = Column(Text, nullable=True)
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ItsmConnector {self.connector_type.value}:{self.name}>"
+
+
+class FindingTicket(Base):
+    """The ticket tracking one finding in a connector's ITSM."""
+
+    __tablename__ = "finding_tickets"
+    __table_args__ = (UniqueConstraint("connector_id", "finding_key", name="uq_finding_ticket"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    connector_id = Column(Integer, ForeignKey("itsm_connectors.id", ondelete="CASCADE"), nullable=False, index=True)
+
+    finding_key = Column(String(64), nullable=False, index=True)  # See finding_export_service.finding_key
+    rule_id = Column(String(100), nullable=False)
+    repository_id = Column(Integer, nullable=True)
+    title = Column(String(500), nullable=False)
+    severity = Column(String(20), nullable=False)
+
+    ticket_id = Column(String(255), nullable=False)  # ServiceNow sys_id or generic ticket ID
+    ticket_number = Column(String(100), nullable=True)  # e.g. INC0012345
+    ticket_url = Column(String(1000), nullable=True)
+    remote_state = Column(String(100), nullable=True)  # State as the ITSM reported it at the last sync
+    status = Column(SAEnum(FindingTicketStatus), default=FindingTicketStatus.OPEN, nullable=False, index=True)
+    resolution_note = Column(Text, nullable=True)  # Close notes or justification from the ITSM
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    status_changed_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    last_synced_at = Column(DateTime(timezone=True), nullable=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<FindingTicket {self.ticket_number or self.ticket_id}: {self.status.value}>"
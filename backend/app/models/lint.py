@@ -0,0 +1,74 @@
+"""Policy lint models: organizational rules and their per-scan results."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Boolean, Column, DateTime, ForeignKey, Integer, String, Text, UniqueConstraint
+from sqlalchemy import Enum as SAEnum
+
+from .repository import Base
+
+
+class LintCheck(str, enum.Enum):
+    """What a lint rule verifies about each endpoint in its scope."""
+
+    REQUIRE_ROLE = "require_role"  # At least one role is required
+    REQUIRE_AUTHENTICATION = "require_authentication"  # Anonymous callers are not allowed
+    NO_FRONTEND_ONLY = "no_frontend_only"  # Not enforced by frontend checks alone
+    ALLOWED_MECHANISMS = "allowed_mechanisms"  # params.mechanisms lists the permitted auth mechanisms
+    FORBIDDEN_ROLES = "forbidden_roles"  # params.roles lists roles that may not be granted
+
+
+class LintRule(Base):
+    """An organizational rule evaluated against every scan's endpoints.
+
+    params scopes the rule with "methods", "paths", and "exclude_paths"
+    (path patterns like "/api/**") and carries the check's own settings.
+    """
+
+    __tablename__ = "lint_rules"
+    __table_args__ = (UniqueConstraint("tenant_id", "name", name="uq_lint_rule_name"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)  # e.g., "mutating-endpoints-require-role"
+    description = Column(Text, nullable=True)
+    check = Column(SAEnum(LintCheck), nullable=False)
+    params = Column(JSON, nullable=False, default=dict)  # e.g., {"methods": ["POST", "DELETE"]}
+    severity = Column(String(20), nullable=False, default="medium")  # low, medium, high, critical
+    enabled = Column(Boolean, nullable=False, default=True)
+    blocking = Column(Boolean, nullable=False, default=True)  # Violations can fail the CI gate
+
+    created_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    updated_at = Column(
+        DateTime(timezone=True),
+        default=lambda: datetime.now(UTC),
+        onupdate=lambda: datetime.now(UTC),
+    )
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<LintRule {self.name}: {self.check.value}>"
+
+
+class LintRun(Base):
+    """Lint results captured when a scan completes."""
+
+    __tablename__ = "lint_runs"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)
+
+    rules_evaluated = Column(Integer, nullable=False, default=0)
+    endpoints_evaluated = Column(Integer, nullable=False, default=0)
+    violations = Column(JSON, nullable=False, default=list)  # Violation dictionaries, most severe first
+    violation_counts = Column(JSON, nullable=True)  # Per severity, e.g. {"high": 2, "low": 1}
+
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<LintRun repo={self.repository_id} scan={self.scan_id}>"
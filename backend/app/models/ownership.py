@@ -0,0 +1,42 @@
+"""Ownership model: which teams own the code a mined rule came from."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, ForeignKey, Integer, String
+
+from .repository import Base
+
+
+class OwnershipSource:
+    """Where a policy's owners came from."""
+
+    CODEOWNERS = "codeowners"  # A CODEOWNERS rule matched the evidence file
+    BLAME = "blame"  # No rule matched; the authors of the evidence lines own it
+    UNOWNED = "unowned"  # Neither CODEOWNERS nor blame named anyone
+
+
+class PolicyOwnership(Base):
+    """Owners of a mined policy, attributed from CODEOWNERS and git blame."""
+
+    __tablename__ = "policy_ownerships"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    policy_id = Column(Integer, ForeignKey("policies.id", ondelete="CASCADE"), nullable=False, unique=True)
+
+    owners = Column(JSON, nullable=False, default=list)  # e.g., ["@acme/payments", "lead@acme.com"]
+    source = Column(String(20), nullable=False, default=OwnershipSource.UNOWNED)
+    codeowners_file = Column(String(255), nullable=True)  # e.g., ".github/CODEOWNERS"
+    codeowners_pattern = Column(String(500), nullable=True)  # Rule that matched, e.g. "/services/payments/"
+    file_path = Column(String(1000), nullable=True)  # Evidence file the owners were resolved for
+
+    # git blame of the evidence lines
+    authors = Column(JSON, nullable=False, default=list)  # Author emails, most lines first
+    last_commit = Column(String(40), nullable=True)
+    last_modified_at = Column(DateTime(timezone=True), nullable=True)
+
+    attributed_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<PolicyOwnership policy={self.policy_id} owners={self.owners}>"
@@ -0,0 +1,76 @@
+"""PDP migration models: inline checks tracked until PDP calls replace them, and progress per scan."""
+from datetime import UTC, datetime
+from enum import Enum
+
+from sqlalchemy import Column, DateTime, Float, ForeignKey, Integer, String, Text
+from sqlalchemy import Enum as SAEnum
+from sqlalchemy.dialects.postgresql import JSONB
+
+from .repository import Base
+
+
+class CallSiteStatus(str, Enum):
+    """Migration state of an inline check."""
+
+    PENDING = "pending"  # The inline check is still in the code
+    MIGRATED = "migrated"  # The check is gone and the file asks the PDP instead
+    REMOVED = "removed"  # The check is gone with no PDP call in its place
+
+
+class MigrationCallSite(Base):
+    """An inline authorization check and the PDP query that replaces it."""
+
+    __tablename__ = "pdp_migration_call_sites"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    application_id = Column(Integer, ForeignKey("applications.id", ondelete="SET NULL"), nullable=True, index=True)
+    policy_id = Column(Integer, ForeignKey("policies.id", ondelete="SET NULL"), nullable=True, index=True)
+
+    # Identifies the same check across scans as its line numbers move
+    fingerprint = Column(String(64), nullable=False, index=True)
+    file_path = Column(String(1000), nullable=False)
+    line_start = Column(Integer, nullable=False)
+    line_end = Column(Integer, nullable=False)
+    language = Column(String(50), nullable=True)  # Stub language; None if there is no stub for the file
+    check_snippet = Column(Text, nullable=False)  # The inline check as it was first seen
+
+    subject = Column(String(500), nullable=True)
+    action = Column(String(500), nullable=True)
+    resource = Column(String(500), nullable=True)
+    conditions = Column(Text, nullable=True)
+    opa_query = Column(JSONB, nullable=False)  # Input document for OPA's data API
+    cedar_query = Column(JSONB, nullable=False)  # Cedar is_authorized request
+    replacement = Column(Text, nullable=True)  # Code calling the integration stub instead
+
+    status = Column(SAEnum(CallSiteStatus), default=CallSiteStatus.PENDING, nullable=False, index=True)
+    first_seen_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    checked_at = Column(DateTime(timezone=True), nullable=True)
+    migrated_at = Column(DateTime(timezone=True), nullable=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<MigrationCallSite {self.file_path}:{self.line_start} {self.status.value}>"
+
+
+class MigrationProgressSnapshot(Base):
+    """A repository's migration progress as one refresh measured it."""
+
+    __tablename__ = "pdp_migration_snapshots"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)
+
+    total_call_sites = Column(Integer, nullable=False, default=0)  # Pending and migrated
+    migrated_call_sites = Column(Integer, nullable=False, default=0)
+    removed_call_sites = Column(Integer, nullable=False, default=0)
+    migrated_percent = Column(Float, nullable=False, default=0.0)
+
+    captured_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<MigrationProgressSnapshot repo={self.repository_id} {self.migrated_percent:.0f}%>"
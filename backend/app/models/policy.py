@@ -35,6 +35,15 @@ class SourceType(str, Enum):
     UNKNOWN = "unknown"
 
 
+class ExtractionMethod(str, Enum):
+    """How a policy was extracted, most reliable first."""
+
+    DECLARED_CONFIG = "declared_config"  # Policy files, manifests, and annotations (Casbin, OPA, Helm, "policy:")
+    EXPLICIT_MIDDLEWARE = "explicit_middleware"  # Guard middleware, decorators, or attributes on the route
+    INFERRED_FROM_BODY = "inferred_from_body"  # Checks the handler body performs, interpreted by the LLM
+    LLM_ASSISTED = "llm_assisted"  # The LLM's reading of code with no recognizable check
+
+
 class ValidationStatus(str, Enum):
     """Evidence validation status."""
 
@@ -66,6 +75,7 @@ class Policy(Base):
     complexity_score = Column(Float, nullable=True)
     impact_score = Column(Float, nullable=True)
     confidence_score = Column(Float, nullable=True)
+    extraction_method = Column(SAEnum(ExtractionMethod), nullable=True)  # Basis of confidence_score; null before it was tracked
     historical_score = Column(Float, nullable=True)  # Historical change frequency score
     embedding = Column(Vector(1536), nullable=True)  # Policy embedding for similarity search (1536 dims for Claude embeddings)
 
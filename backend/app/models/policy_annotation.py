@@ -0,0 +1,38 @@
+"""Inline policy annotation models: rules developers declare in comments, as last scanned."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, ForeignKey, Integer, String, Text
+
+from .repository import Base
+
+
+class PolicyAnnotationRecord(Base):
+    """A "policy:" comment found in a repository and how it compares to the code below it.
+
+    Replaced on every scan of the repository. Status is valid, stale (the
+    annotated code checks something else), or invalid (malformed).
+    """
+
+    __tablename__ = "policy_annotations"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+
+    file_path = Column(String(1000), nullable=False)
+    line = Column(Integer, nullable=False)
+    text = Column(Text, nullable=False)  # e.g., "role=MANAGER resource=expense action=create"
+    roles = Column(JSON, nullable=False, default=list)
+    permissions = Column(JSON, nullable=False, default=list)
+    resource = Column(String(500), nullable=True)
+    action = Column(String(500), nullable=True)
+    conditions = Column(Text, nullable=True)
+    target_line = Column(Integer, nullable=True)  # First line of the annotated code
+
+    status = Column(String(20), nullable=False, index=True)  # valid, stale, invalid
+    problems = Column(JSON, nullable=False, default=list)  # e.g., ["code checks role ADMIN, not MANAGER"]
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<PolicyAnnotationRecord {self.file_path}:{self.line} {self.status}>"
@@ -0,0 +1,37 @@
+"""Readiness assessment model: how analyzable a repository is before mining."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Boolean, Column, DateTime, ForeignKey, Integer, String
+
+from .repository import Base
+
+
+class ReadinessAssessment(Base):
+    """Result of assessing a repository's clone for analyzability.
+
+    Recorded before each scan mines policies, and on demand for
+    repositories being onboarded, so the score can be tracked as teams fix
+    what the assessment reports.
+    """
+
+    __tablename__ = "readiness_assessments"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True, index=True)
+
+    score = Column(Integer, nullable=False)  # 0-100
+    grade = Column(String(1), nullable=False)  # A-F
+    ready = Column(Boolean, nullable=False, default=False)
+    route_resolution_percent = Column(Integer, nullable=True)  # None when no route registrations were found
+
+    # Frameworks, languages, route counts, dynamic patterns, score components, and recommendations
+    report = Column(JSON, nullable=False, default=dict)
+
+    assessed_by = Column(String(255), nullable=True)  # User email; None when run by the scanner
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ReadinessAssessment repo={self.repository_id} score={self.score}>"
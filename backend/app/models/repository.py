@@ -1,13 +1,16 @@
 """Repository model."""
 
+import secrets
 from datetime import UTC, datetime
 from enum import Enum
 
-from sqlalchemy import JSON, Column, DateTime, Integer, String
+from sqlalchemy import JSON, Boolean, Column, DateTime, Integer, String
 from sqlalchemy import Enum as SAEnum
 from sqlalchemy.ext.declarative import declarative_base
 from sqlalchemy.orm import relationship
 
+from app.models.encrypted_types import EncryptedString
+
 Base = declarative_base()
 
 
@@ -50,6 +53,10 @@ class Repository(Base):
     connection_config = Column(JSON, nullable=True)  # Store credentials encrypted
     status = Column(SAEnum(RepositoryStatus), default=RepositoryStatus.PENDING)
     last_scan_at = Column(DateTime(timezone=True), nullable=True)
+    webhook_enabled = Column(Boolean, default=False, nullable=False)  # Scan on push webhooks from the Git provider
+    webhook_secret = Column(EncryptedString(500), nullable=True)  # Verifies the Git provider's payload signatures
+    # Opaque token the public status badge is served by
+    badge_token = Column(String(64), unique=True, index=True, default=lambda: secrets.token_urlsafe(24))
     created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
     updated_at = Column(
         DateTime(timezone=True),
@@ -0,0 +1,26 @@
+"""Role assignment models for ingested user-to-role data."""
+from datetime import UTC, datetime
+
+from sqlalchemy import Column, DateTime, ForeignKey, Integer, String
+
+from .repository import Base
+
+
+class RoleAssignment(Base):
+    """A user's role membership, ingested from an IdP export or HR system."""
+
+    __tablename__ = "role_assignments"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    user_identifier = Column(String(255), nullable=False, index=True)  # e.g., email or employee ID
+    role = Column(String(255), nullable=False, index=True)  # Stored upper-case to match mined roles
+    application_id = Column(Integer, ForeignKey("applications.id", ondelete="CASCADE"), nullable=True, index=True)
+    source = Column(String(100), nullable=True)  # e.g., "okta", "azure_ad", "csv"
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<RoleAssignment {self.user_identifier} -> {self.role}>"
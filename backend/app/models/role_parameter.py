@@ -0,0 +1,77 @@
+"""Role parameter models: values bound to dynamic role requirements, and the policies that use them."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, ForeignKey, Integer, String, Text, UniqueConstraint
+
+from .repository import Base
+
+
+class BindingSource:
+    """Where a binding's values came from."""
+
+    MANUAL = "manual"  # Bound through the API
+    SCAN_SNAPSHOT = "scan_snapshot"  # Read from the config and environment snapshot supplied with a scan
+
+
+class RoleParameterBinding(Base):
+    """Values a role parameter stands for.
+
+    A parameter such as "cfg.ApproverRole" is a role requirement looked up
+    from configuration or a database at runtime. A binding without a
+    repository applies workspace-wide; a repository's own binding overrides it.
+    Bindings resolved from a scan's config snapshot are repository bindings
+    that remember the scan they came from.
+    """
+
+    __tablename__ = "role_parameter_bindings"
+    __table_args__ = (UniqueConstraint("tenant_id", "repository_id", "name", name="uq_role_parameter_binding"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)  # e.g., "cfg.ApproverRole", "env.ADMIN_ROLE"
+    values = Column(JSON, nullable=False, default=list)  # e.g., ["finance-approver"]
+    source = Column(String(20), nullable=False, default=BindingSource.MANUAL)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)  # Scan whose snapshot bound it
+
+    bound_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    updated_at = Column(
+        DateTime(timezone=True),
+        default=lambda: datetime.now(UTC),
+        onupdate=lambda: datetime.now(UTC),
+    )
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<RoleParameterBinding {self.name}={self.values}>"
+
+
+class RoleParameterUsage(Base):
+    """A policy whose subject or conditions reference role parameters.
+
+    Keeps the policy's text as extracted, with "${name}" placeholders, so
+    it can be rendered again whenever a binding changes.
+    """
+
+    __tablename__ = "role_parameter_usages"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    policy_id = Column(Integer, ForeignKey("policies.id", ondelete="CASCADE"), nullable=False, unique=True)
+
+    parameters = Column(JSON, nullable=False, default=list)  # Parameter names, e.g., ["cfg.ApproverRole"]
+    subject_template = Column(String(500), nullable=False)  # e.g., "${cfg.ApproverRole}"
+    conditions_template = Column(Text, nullable=True)
+    # Text last written to the policy; a policy that no longer matches it was edited or re-extracted
+    rendered_subject = Column(String(500), nullable=False)
+    rendered_conditions = Column(Text, nullable=True)
+    # Parameter name -> the binding it was last rendered with: values, source, scan, and scope
+    provenance = Column(JSON, nullable=False, default=dict)
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<RoleParameterUsage policy={self.policy_id} {self.parameters}>"
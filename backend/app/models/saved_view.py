@@ -0,0 +1,34 @@
+"""Saved view model for named filter combinations."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, Integer, String, Text, UniqueConstraint
+
+from .repository import Base
+
+
+class SavedView(Base):
+    """A named filter combination saved in a workspace."""
+
+    __tablename__ = "saved_views"
+    __table_args__ = (UniqueConstraint("tenant_id", "view_type", "name", name="uq_saved_view_name"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)
+    description = Column(Text, nullable=True)
+    view_type = Column(String(50), nullable=False, index=True)  # Page the filters apply to, e.g., "policies"
+    filters = Column(JSON, nullable=False, default=dict)  # e.g., {"risk_level": "high", "application_id": 3}
+    share_token = Column(String(64), nullable=False, unique=True, index=True)  # Opaque token for share links
+
+    created_by = Column(String(255), nullable=True)  # User email
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    updated_at = Column(
+        DateTime(timezone=True),
+        default=lambda: datetime.now(UTC),
+        onupdate=lambda: datetime.now(UTC),
+    )
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<SavedView {self.view_type}: {self.name}>"
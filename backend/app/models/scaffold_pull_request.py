@@ -0,0 +1,54 @@
+"""Scaffold pull request models for handing generated policy-as-code to service teams."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import Column, DateTime, ForeignKey, Integer, String, Text
+from sqlalchemy import Enum as SAEnum
+from sqlalchemy.dialects.postgresql import JSONB
+
+from .repository import Base
+
+
+class ScaffoldFormat(str, enum.Enum):
+    """Policy language of a scaffold."""
+
+    REGO = "rego"  # OPA bundle layout, evaluated by an OPA sidecar
+    CEDAR = "cedar"  # Cedar policy set, evaluated by a Cedar agent
+
+
+class ScaffoldPullRequestStatus(str, enum.Enum):
+    """Outcome of opening a scaffold pull request."""
+
+    PENDING = "pending"
+    OPENED = "opened"
+    FAILED = "failed"
+
+
+class ScaffoldPullRequest(Base):
+    """A pull request adding generated policies and a PDP integration stub to a repository."""
+
+    __tablename__ = "scaffold_pull_requests"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    application_id = Column(Integer, ForeignKey("applications.id", ondelete="SET NULL"), nullable=True, index=True)
+
+    policy_format = Column(SAEnum(ScaffoldFormat), nullable=False)
+    language = Column(String(50), nullable=False)  # Language of the integration stub
+    branch = Column(String(255), nullable=False)
+    base_branch = Column(String(255), nullable=True)
+    title = Column(String(500), nullable=False)
+    files = Column(JSONB, nullable=False, default=list)  # Paths added by the pull request
+    policy_ids = Column(JSONB, nullable=False, default=list)
+    status = Column(SAEnum(ScaffoldPullRequestStatus), default=ScaffoldPullRequestStatus.PENDING, nullable=False)
+    url = Column(String(1000), nullable=True)  # Web URL of the pull or merge request
+    number = Column(Integer, nullable=True)  # Pull request number, or merge request IID
+    requested_by = Column(String(255), nullable=True)
+    error_message = Column(Text, nullable=True)
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ScaffoldPullRequest repo={self.repository_id} {self.policy_format.value} {self.status.value}>"
@@ -0,0 +1,44 @@
+"""Scan environment model: which deployment environment a scan represents."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, ForeignKey, Integer, String
+
+from .repository import Base
+
+
+class EnvironmentSource:
+    """How a scan's environment was determined."""
+
+    BRANCH = "branch"  # Branch name of the scanned checkout, e.g. "release/staging"
+    MANIFEST = "manifest"  # Namespace of the deployment manifests, e.g. "payments-prod"
+    MANUAL = "manual"  # Tagged through the API
+
+
+class ScanEnvironment(Base):
+    """Environment tag of a scan, with the endpoint model it observed.
+
+    Policies accumulate across scans rather than belonging to one, so the
+    tag stores a snapshot of the endpoints and environment-gated auth checks
+    at tagging time; environments are compared by their snapshots.
+    """
+
+    __tablename__ = "scan_environments"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="CASCADE"), nullable=False, unique=True)
+
+    environment = Column(String(50), nullable=False, index=True)  # dev, staging, prod, or a custom name
+    source = Column(String(20), nullable=False, default=EnvironmentSource.MANUAL)
+    ref = Column(String(500), nullable=True)  # Branch name or manifest path the environment came from
+
+    endpoints = Column(JSON, nullable=False, default=list)  # Endpoint snapshot, one dictionary per endpoint
+    gated_checks = Column(JSON, nullable=False, default=list)  # Auth checks switched by an environment check
+
+    tagged_by = Column(String(255), nullable=True)  # User email; None when tagged by the scanner
+    created_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ScanEnvironment scan={self.scan_id} {self.environment}>"
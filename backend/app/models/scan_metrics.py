@@ -0,0 +1,43 @@
+"""Per-scan aggregate metrics for trend reporting."""
+from datetime import UTC, datetime
+
+from sqlalchemy import JSON, Column, DateTime, Float, ForeignKey, Integer, String
+
+from .repository import Base
+
+
+class ScanMetricsSnapshot(Base):
+    """Aggregate metrics captured when a scan completes."""
+
+    __tablename__ = "scan_metrics_snapshots"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)
+
+    # Policy inventory
+    total_policies = Column(Integer, nullable=False, default=0)
+    approved_policies = Column(Integer, nullable=False, default=0)
+    pending_policies = Column(Integer, nullable=False, default=0)
+    high_risk_policies = Column(Integer, nullable=False, default=0)
+
+    # Coverage KPIs (see CoverageMetricsService)
+    total_endpoints = Column(Integer, nullable=False, default=0)
+    authenticated_endpoints = Column(Integer, nullable=False, default=0)
+    authorized_endpoints = Column(Integer, nullable=False, default=0)
+    conditional_endpoints = Column(Integer, nullable=False, default=0)
+    authenticated_percent = Column(Float, nullable=False, default=0.0)
+    authorized_percent = Column(Float, nullable=False, default=0.0)
+    conditional_percent = Column(Float, nullable=False, default=0.0)
+    unknown_patterns = Column(Integer, nullable=False, default=0)
+
+    # Open findings at capture time, e.g. {"conflicts": 2, "secrets": 0}
+    open_findings = Column(JSON, nullable=True)
+    critical_findings = Column(Integer, nullable=False, default=0)  # Open findings of critical severity
+
+    captured_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ScanMetricsSnapshot repo={self.repository_id} at {self.captured_at}>"
@@ -0,0 +1,38 @@
+"""Scan schedule model for scanning repositories on a cron schedule."""
+from datetime import UTC, datetime
+
+from sqlalchemy import Boolean, Column, DateTime, ForeignKey, Integer, String, Text
+
+from .repository import Base
+
+
+class ScanSchedule(Base):
+    """When a repository is scanned without anyone asking."""
+
+    __tablename__ = "scan_schedules"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(
+        Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, unique=True, index=True
+    )
+
+    cron = Column(String(100), nullable=False)  # Five-field cron expression, evaluated in UTC
+    incremental = Column(Boolean, default=True, nullable=False)  # Only scan files changed since the last scan
+    enabled = Column(Boolean, default=True, nullable=False)
+
+    next_run_at = Column(DateTime(timezone=True), nullable=True, index=True)
+    last_run_at = Column(DateTime(timezone=True), nullable=True)
+    last_task_id = Column(String(255), nullable=True)  # Celery task of the last scan queued
+    last_error = Column(Text, nullable=True)
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+    updated_at = Column(
+        DateTime(timezone=True),
+        default=lambda: datetime.now(UTC),
+        onupdate=lambda: datetime.now(UTC),
+    )
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ScanSchedule repository={self.repository_id} {self.cron!r}>"
@@ -0,0 +1,81 @@
+"""SIEM connector models for streaming findings and audit events to Splunk, Elastic, or Sentinel."""
+import enum
+from datetime import UTC, datetime
+
+from sqlalchemy import Column, DateTime, ForeignKey, Integer, String, Text, UniqueConstraint
+from sqlalchemy import Enum as SAEnum
+
+from app.models.encrypted_types import EncryptedString
+
+from .repository import Base
+
+
+class SiemDestination(str, enum.Enum):
+    """SIEMs events are streamed to."""
+
+    SPLUNK = "splunk"  # HTTP Event Collector, secret is the HEC token
+    ELASTIC = "elastic"  # Bulk API, secret is an API key
+    SENTINEL = "sentinel"  # Log Analytics Data Collector API, secret is the workspace shared key
+
+
+class SiemFormat(str, enum.Enum):
+    """Event formats."""
+
+    ECS = "ecs"  # Elastic Common Schema JSON
+    CEF = "cef"  # ArcSight Common Event Format lines
+
+
+class SiemConnector(Base):
+    """A SIEM that findings, auth regressions, and miner audit events are streamed to."""
+
+    __tablename__ = "siem_connectors"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+
+    name = Column(String(255), nullable=False)
+    destination = Column(SAEnum(SiemDestination), nullable=False)
+    event_format = Column(SAEnum(SiemFormat), nullable=False, default=SiemFormat.ECS)
+    base_url = Column(String(500), nullable=True)  # HEC or Elasticsearch URL; unused for Sentinel
+    workspace_id = Column(String(100), nullable=True)  # Sentinel Log Analytics workspace
+    secret = Column(EncryptedString(1000), nullable=False)
+    index = Column(String(255), nullable=True)  # Splunk index, Elastic index or data stream, Sentinel log type
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=True, index=True)
+
+    # Events already streamed: audit logs and auth regressions up to these IDs
+    audit_log_cursor = Column(Integer, nullable=False, default=0)
+    regression_cursor = Column(Integer, nullable=False, default=0)
+
+    sync_interval_minutes = Column(Integer, nullable=True)  # Falls back to SIEM_STREAM_INTERVAL_MINUTES
+    last_streamed_at = Column(DateTime(timezone=True), nullable=True)
+    last_error = Column(Text, nullable=True)
+
+    created_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<SiemConnector {self.destination.value}:{self.name}>"
+
+
+class SiemFindingState(Base):
+    """A finding a connector has streamed as detected and not yet as resolved."""
+
+    __tablename__ = "siem_finding_states"
+    __table_args__ = (UniqueConstraint("connector_id", "finding_key", name="uq_siem_finding_state"),)
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    connector_id = Column(Integer, ForeignKey("siem_connectors.id", ondelete="CASCADE"), nullable=False, index=True)
+
+    finding_key = Column(String(64), nullable=False)  # See finding_export_service.finding_key
+    rule_id = Column(String(100), nullable=False)
+    repository_id = Column(Integer, nullable=True)
+    title = Column(String(500), nullable=False)
+    severity = Column(String(20), nullable=False)
+    file_path = Column(String(1000), nullable=True)
+
+    streamed_at = Column(DateTime(timezone=True), default=lambda: datetime.now(UTC))
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<SiemFindingState {self.finding_key}>"
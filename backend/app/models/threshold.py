@@ -0,0 +1,83 @@
+"""Condition threshold models: numeric boundaries observed per scan and loosenings between scans."""
+from datetime import UTC, datetime
+from enum import Enum
+
+from sqlalchemy import Column, DateTime, Float, ForeignKey, Integer, String, Text
+from sqlalchemy import Enum as SAEnum
+
+from .repository import Base
+
+
+class ThresholdObservation(Base):
+    """A numeric condition boundary as one scan saw it, e.g. "amount > 5000 requires DIRECTOR"."""
+
+    __tablename__ = "threshold_observations"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)
+    policy_id = Column(Integer, ForeignKey("policies.id", ondelete="SET NULL"), nullable=True)
+
+    # Identifies the same boundary across scans regardless of its value
+    threshold_key = Column(String(64), nullable=False, index=True)
+    resource = Column(String(500), nullable=True)
+    action = Column(String(500), nullable=True)
+    attribute = Column(String(255), nullable=False)  # e.g., "amount"
+    operator = Column(String(4), nullable=False)  # >, >=, <, <=
+    value = Column(Float, nullable=False)
+    required_role = Column(String(255), nullable=True)  # Role required past the boundary, for "... requires ROLE"
+    clause = Column(Text, nullable=False)  # Condition text the boundary was parsed from
+
+    observed_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ThresholdObservation {self.attribute} {self.operator} {self.value}>"
+
+
+class ThresholdChangeStatus(str, Enum):
+    """Review status of a loosened threshold."""
+
+    PENDING = "pending"  # Detected but not reviewed
+    ACKNOWLEDGED = "acknowledged"  # Reviewed, change confirmed as intended
+    RESOLVED = "resolved"  # Threshold restored
+    DISMISSED = "dismissed"  # Not a real loosening, e.g. a misread condition
+
+
+class ThresholdChange(Base):
+    """Finding raised when a scan loosens a numeric condition boundary."""
+
+    __tablename__ = "threshold_changes"
+
+    id = Column(Integer, primary_key=True, index=True)
+    tenant_id = Column(String(100), nullable=True, index=True)
+    repository_id = Column(Integer, ForeignKey("repositories.id", ondelete="CASCADE"), nullable=False, index=True)
+    scan_id = Column(Integer, ForeignKey("scan_progress.id", ondelete="SET NULL"), nullable=True)
+    threshold_key = Column(String(64), nullable=False, index=True)
+
+    previous_policy_id = Column(Integer, nullable=True)
+    policy_id = Column(Integer, ForeignKey("policies.id", ondelete="SET NULL"), nullable=True)
+    resource = Column(String(500), nullable=True)
+    action = Column(String(500), nullable=True)
+    attribute = Column(String(255), nullable=False)
+    required_role = Column(String(255), nullable=True)
+
+    before_operator = Column(String(4), nullable=False)
+    before_value = Column(Float, nullable=False)
+    after_operator = Column(String(4), nullable=False)
+    after_value = Column(Float, nullable=False)
+
+    description = Column(Text, nullable=False)  # e.g., "amount > 5000 requires DIRECTOR became amount > 25000 ..."
+    severity = Column(String(20), nullable=False, index=True)  # low, medium, high
+
+    status = Column(SAEnum(ThresholdChangeStatus), nullable=False, default=ThresholdChangeStatus.PENDING, index=True)
+    resolution_notes = Column(Text, nullable=True)
+    resolved_by = Column(String(255), nullable=True)
+    resolved_at = Column(DateTime(timezone=True), nullable=True)
+
+    detected_at = Column(DateTime(timezone=True), nullable=False, default=lambda: datetime.now(UTC), index=True)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"<ThresholdChange {self.attribute}: {self.before_value} -> {self.after_value}>"
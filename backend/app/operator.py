@@ -0,0 +1,49 @@
+"""Kubernetes operator entrypoint reconciling PolicyScan resources (python -m app.operator).
+
+Replicas elect a leader; only the leader reconciles, and standbys take over
+when it stops renewing its lease.
+"""
+
+import signal
+import threading
+
+import structlog
+
+from app.core.config import settings
+from app.core.coordination import LeaderElection, get_redis
+from app.core.database import get_db
+from app.services.scan_operator_service import KubernetesClient, ScanOperatorService
+from app.tasks.scan_tasks import enqueue_scan
+
+logger = structlog.get_logger(__name__)
+
+
+def main() -> None:
+    """Reconcile every OPERATOR_RESYNC_SECONDS while leading, until terminated."""
+    stopping = threading.Event()
+    signal.signal(signal.SIGTERM, lambda *_: stopping.set())
+    signal.signal(signal.SIGINT, lambda *_: stopping.set())
+    election = LeaderElection(get_redis(), "operator")
+    if not election.campaign(stopping):
+        return
+    # Stop reconciling as soon as another replica may have taken over
+    election.keep(on_lost=stopping.set)
+    kubernetes = KubernetesClient.in_cluster()
+    logger.info("operator_started", namespace=settings.OPERATOR_NAMESPACE or "all")
+    try:
+        while not stopping.is_set():
+            db = next(get_db())
+            try:
+                result = ScanOperatorService(db, kubernetes, enqueue_scan).reconcile_all()
+                logger.info("policy_scans_reconciled", **result)
+            except Exception as e:
+                logger.error("policy_scan_resync_failed", error=str(e))
+            finally:
+                db.close()
+            stopping.wait(settings.OPERATOR_RESYNC_SECONDS)
+    finally:
+        election.release()
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,38 @@
+"""Celery beat entrypoint for highly available deployments (python -m app.scheduler).
+
+Run two or more replicas: they elect a leader, and only the leader runs beat,
+so each periodic task is queued once. A standby takes over when the leader
+stops renewing its lease.
+"""
+
+import os
+import signal
+import threading
+
+import structlog
+
+from app.celery_app import celery_app
+from app.core.coordination import LeaderElection, get_redis
+
+logger = structlog.get_logger(__name__)
+
+
+def main() -> None:
+    """Run beat while leading; exit when leadership is lost so the replica restarts as a standby."""
+    stopping = threading.Event()
+    signal.signal(signal.SIGTERM, lambda *_: stopping.set())
+    signal.signal(signal.SIGINT, lambda *_: stopping.set())
+    election = LeaderElection(get_redis(), "scheduler")
+    if not election.campaign(stopping):
+        return
+    # Beat shuts down on SIGTERM, so losing the lease stops it like a pod termination would
+    election.keep(on_lost=lambda: os.kill(os.getpid(), signal.SIGTERM))
+    logger.info("scheduler_started", identity=election.identity)
+    try:
+        celery_app.Beat(loglevel="INFO", schedule="/tmp/celerybeat-schedule").run()
+    finally:
+        election.release()
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,27 @@
+"""Schemas for attribute conditions lifted from handler bodies."""
+from pydantic import BaseModel, Field
+
+
+class LiftedConditionItem(BaseModel):
+    """A denial guard in a handler rewritten as the condition a request must meet."""
+
+    policy_id: int | None
+    file_path: str
+    line: int
+    guard: str = Field(..., description="Source line of the if-statement")
+    condition: str = Field(..., description="Clause text, e.g. 'expense.amount > 5000 requires DIRECTOR'")
+    kind: str = Field(..., description="comparison, implication, flag, or ownership")
+    attribute: str | None = None
+    operator: str | None = None
+    value: int | float | str | bool | None = None
+    required_role: str | None = Field(None, description="Role that lifts the limit, for implications")
+    added: bool = Field(..., description="Whether the clause was new to the policy")
+
+
+class AbacConditionResult(BaseModel):
+    """Summary of the conditions lifted for a repository's policies."""
+
+    repository_id: int
+    policies_examined: int
+    policies_updated: int = Field(0, description="Policies that gained at least one condition")
+    conditions: list[LiftedConditionItem] = Field(default_factory=list)
@@ -0,0 +1,98 @@
+"""Schemas for access review campaigns."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+from app.models.access_review import AttestationDecision, CampaignStatus, PacketStatus
+
+
+class AccessReviewCampaignCreate(BaseModel):
+    """Request to generate an access review campaign."""
+
+    name: str = Field(..., description="Campaign name (e.g., Q3 2026 access review)")
+    description: str | None = None
+    due_date: datetime | None = None
+    application_ids: list[int] | None = Field(None, description="Restrict to these applications")
+    include_pending: bool = Field(False, description="Also review policies not yet approved")
+    created_by: str | None = Field(None, description="Email of the user starting the campaign")
+
+
+class AccessReviewEvidence(BaseModel):
+    """Code evidence snapshotted with an item."""
+
+    file_path: str
+    line_start: int
+    line_end: int
+    code_snippet: str
+
+
+class AccessReviewItemResponse(BaseModel):
+    """One access statement to attest."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    packet_id: int
+    policy_id: int | None
+    subject: str
+    resource: str
+    action: str
+    conditions: str | None
+    evidence: list[AccessReviewEvidence] = []
+    decision: AttestationDecision
+    reviewed_by: str | None
+    review_comment: str | None
+    reviewed_at: datetime | None
+
+
+class AccessReviewPacketResponse(BaseModel):
+    """A reviewer's packet with its items."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    campaign_id: int
+    reviewer: str
+    application_id: int | None
+    application_name: str | None
+    status: PacketStatus
+    completed_at: datetime | None
+    items: list[AccessReviewItemResponse]
+
+
+class PacketProgress(BaseModel):
+    """Progress of one packet."""
+
+    id: int
+    reviewer: str
+    application_id: int | None
+    application_name: str | None
+    status: str
+    total_items: int
+    attested_items: int
+
+
+class AccessReviewCampaignResponse(BaseModel):
+    """Campaign with progress and outcome counts."""
+
+    id: int
+    name: str
+    description: str | None
+    status: CampaignStatus
+    due_date: datetime | None
+    created_by: str | None
+    created_at: datetime
+    completed_at: datetime | None
+    total_items: int
+    attested_items: int
+    progress: float = Field(..., description="Percentage of items attested")
+    decisions: dict[str, int] = Field(..., description="Item count per decision")
+    packets: list[PacketProgress]
+
+
+class AttestationRequest(BaseModel):
+    """A reviewer's decision on one item."""
+
+    decision: AttestationDecision
+    reviewed_by: str = Field(..., description="Reviewer email")
+    comment: str | None = Field(None, description="Justification; required to revoke or modify")
@@ -0,0 +1,45 @@
+"""Schemas for admin surface inventories."""
+from pydantic import BaseModel, Field
+
+
+class AdminSurfaceResponse(BaseModel):
+    """An admin module, management endpoint, or management port found in a repository."""
+
+    kind: str = Field(..., description="admin_path, management_endpoint, framework_admin, or management_port")
+    framework: str | None = None
+    path: str | None = Field(None, description="Mount path or prefix; null for management ports")
+    file_path: str | None = None
+    line_start: int | None = None
+    snippet: str | None = None
+    port: int | None = None
+    loopback_only: bool = Field(False, description="Bound to loopback, reachable only from the host")
+    builtin_protection: str | None = Field(None, description="Login the admin module enforces by default")
+
+
+class AdminEndpoint(BaseModel):
+    """An admin endpoint and its protection status."""
+
+    endpoint: str
+    method: str
+    path: str
+    surface: str
+    framework: str | None = None
+    policy_ids: list[int] = Field(default_factory=list)
+    roles: list[str] = Field(default_factory=list)
+    conditions: list[str] = Field(default_factory=list)
+    protection: str = Field(
+        ..., description="role_restricted, framework_default, authenticated, public, or no_policy"
+    )
+    exposed: bool = Field(..., description="Public, open to any signed-in user, or covered by no policy")
+
+
+class ServiceAdminSurface(BaseModel):
+    """Admin surface inventory of one service (repository)."""
+
+    repository_id: int
+    repository_name: str
+    cloned: bool = Field(..., description="Whether a clone was available to read surfaces from")
+    surfaces: list[AdminSurfaceResponse] = Field(default_factory=list)
+    management_ports: list[int] = Field(default_factory=list)
+    exposed_endpoints: int
+    endpoints: list[AdminEndpoint] = Field(default_factory=list)
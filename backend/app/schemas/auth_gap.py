@@ -0,0 +1,41 @@
+"""Schemas for registered routes missing authentication or authorization."""
+from pydantic import BaseModel, Field
+
+
+class AuthGapResponse(BaseModel):
+    """A registered route with no detectable authentication or authorization."""
+
+    repository_id: int
+    repository_name: str
+    endpoint: str = Field(..., description="Route, e.g. DELETE /api/expenses/{id}")
+    method: str
+    path: str
+    file_path: str
+    line: int = Field(..., description="Line of the route registration")
+    code: str = Field(..., description="The registration, on one line")
+    severity: str
+    priority: float = Field(..., description="Endpoint risk score of the route as an anonymous endpoint, 0-100")
+    exposure: str
+    conventionally_public: bool = Field(..., description="Path is public by convention (health, login, docs)")
+    reasons: list[str] = Field(default_factory=list)
+    description: str
+    policy_ids: list[int] = Field(default_factory=list)
+
+
+class AuthGapSummary(BaseModel):
+    """Counts across the report."""
+
+    routes: int = Field(..., description="Registered routes scanned")
+    gaps: int
+    by_protection: dict[str, int] = Field(
+        default_factory=dict, description="Routes per protection: policy, registration, controller, middleware, public, or none"
+    )
+    by_severity: dict[str, int] = Field(default_factory=dict)
+    repositories_scanned: int = Field(..., description="Repositories with a clone to scan")
+
+
+class AuthGapReport(BaseModel):
+    """Missing-authorization gaps across repositories, highest priority first."""
+
+    gaps: list[AuthGapResponse] = Field(default_factory=list)
+    summary: AuthGapSummary
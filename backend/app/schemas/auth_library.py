@@ -0,0 +1,60 @@
+"""Schemas for the auth library inventory."""
+from pydantic import BaseModel, Field
+
+
+class AuthLibraryUsage(BaseModel):
+    """One auth library package a service depends on."""
+
+    repository_id: int
+    repository: str
+    library: str
+    category: str = Field(..., description="authentication, token, federation, authorization, or policy_engine")
+    ecosystem: str
+    package: str
+    version: str | None = Field(None, description="Locked version, or the version the specification pins or starts at")
+    declared: str | None = Field(None, description="Version specification as written in the manifest")
+    file_path: str = Field(..., description="Manifest, lockfile, or SBOM the package is declared in")
+    line: int | None = None
+    manifests: list[str] = Field(default_factory=list)
+    direct: bool = Field(..., description="Whether the service declares the package itself rather than through another dependency")
+    deprecated: bool
+    deprecation: str | None = Field(None, description="Why the library or its version is flagged, and what replaces it")
+
+
+class AuthLibraryRollup(BaseModel):
+    """A library across services: who uses it, at which versions."""
+
+    library: str
+    category: str
+    ecosystem: str
+    services: list[str] = Field(default_factory=list)
+    versions: dict[str, list[str]] = Field(default_factory=dict, description="Version -> services running it")
+    deprecated_services: list[str] = Field(default_factory=list)
+
+
+class AuthLibraryCategory(BaseModel):
+    """Libraries competing in one category, for tracking standardization."""
+
+    category: str
+    libraries: dict[str, int] = Field(default_factory=dict, description="Library -> services using it")
+    services: int
+
+
+class AuthLibrarySummary(BaseModel):
+    """Totals of the inventory."""
+
+    services: int
+    services_with_auth_libraries: int
+    usages: int
+    libraries: int
+    deprecated_usages: int
+    version_drift: list[str] = Field(default_factory=list, description="Libraries services run at more than one version")
+
+
+class AuthLibraryInventory(BaseModel):
+    """Auth libraries across the tenant's services."""
+
+    usages: list[AuthLibraryUsage] = Field(default_factory=list)
+    libraries: list[AuthLibraryRollup] = Field(default_factory=list)
+    categories: list[AuthLibraryCategory] = Field(default_factory=list)
+    summary: AuthLibrarySummary
@@ -0,0 +1,27 @@
+"""Schemas for authentication mechanism classification."""
+from pydantic import BaseModel, Field
+
+
+class EndpointAuthMechanism(BaseModel):
+    """How callers of one endpoint authenticate."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    mechanism: str = Field(
+        ..., description="Primary mechanism: cookie_session, jwt_bearer, api_key, mtls, none, or unknown"
+    )
+    mechanisms: list[str] = Field(..., description="Every mechanism the endpoint's evidence names, primary first")
+
+
+class ServiceAuthMechanisms(BaseModel):
+    """Authentication mechanisms across one service's endpoints."""
+
+    repository_id: int
+    repository_name: str
+    endpoints: int
+    primary_mechanism: str | None = Field(
+        None, description="Most common authenticated mechanism; null when none is named"
+    )
+    by_mechanism: dict[str, int] = Field(..., description="Endpoints per primary mechanism")
@@ -0,0 +1,95 @@
+"""Schemas for generated authorization tests."""
+from pydantic import BaseModel, Field
+
+from app.services.authz_test_generation_service import AuthzTestFramework
+
+
+class GeneratedAuthzTests(BaseModel):
+    """Generated authorization test suite for a repository."""
+
+    repository_id: int
+    framework: AuthzTestFramework
+    filename: str = Field(..., description="Suggested file name for the generated suite")
+    content: str = Field(..., description="Generated test source code")
+    endpoint_count: int = Field(..., description="Number of endpoints covered")
+    case_count: int = Field(..., description="Number of role/endpoint cases generated")
+    skipped_count: int = Field(..., description="Cases skipped because they depend on conditions")
+
+
+class ContractVerificationRequest(BaseModel):
+    """Request to verify mined policies against a running application."""
+
+    base_url: str = Field(..., description="Base URL of the staging deployment")
+    credentials: dict[str, dict[str, str]] = Field(
+        default_factory=dict,
+        description="Role name -> request headers that authenticate as that role",
+    )
+    include_unsafe_methods: bool = Field(
+        False, description="Also send POST/PUT/PATCH/DELETE requests (may modify data)"
+    )
+    timeout_seconds: float = Field(10.0, gt=0, le=120)
+    max_concurrency: int = Field(5, ge=1, le=50)
+
+
+class ContractVerificationResult(BaseModel):
+    """Live outcome of one role/endpoint case."""
+
+    method: str
+    path: str
+    role: str
+    expected_allowed: bool
+    expected_status: int
+    outcome: str = Field(
+        ..., description="match, unexpected_access, unexpected_denial, error, or skipped"
+    )
+    actual_status: int | None = None
+    detail: str | None = None
+    duration_ms: int = 0
+
+
+class ContractVerificationReport(BaseModel):
+    """Mismatches between mined expectations and live enforcement."""
+
+    repository_id: int
+    base_url: str
+    total: int
+    matched: int
+    mismatched: int
+    unexpected_access: int
+    unexpected_denial: int
+    errors: int
+    skipped: int
+    results: list[ContractVerificationResult]
+
+
+class PolicyMutantResult(BaseModel):
+    """Outcome of one policy mutation."""
+
+    id: str
+    operator: str = Field(
+        ..., description="drop_role, add_role, make_public, flip_threshold, or remove_condition"
+    )
+    endpoint: str
+    description: str
+    killed: bool = Field(..., description="Whether a test case detects the mutation")
+    killed_by: str | None = Field(None, description="Name of the first test case that detects it")
+
+
+class MutationOperatorStats(BaseModel):
+    """Killed/total counts for one operator."""
+
+    total: int
+    killed: int
+
+
+class PolicyMutationReport(BaseModel):
+    """Policy test strength for a repository."""
+
+    repository_id: int
+    harness: str = Field(..., description="generated_tests or probe")
+    strength_score: float = Field(..., description="Percentage of mutants killed (0-100)")
+    total_mutants: int
+    killed: int
+    survived: int
+    by_operator: dict[str, MutationOperatorStats]
+    mutants: list[PolicyMutantResult]
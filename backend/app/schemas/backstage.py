@@ -0,0 +1,62 @@
+"""Schemas for the Backstage developer portal integration."""
+from pydantic import BaseModel, Field
+
+from app.schemas.status_badge import RepositoryStatusResponse
+
+
+class BackstageEntitySummary(RepositoryStatusResponse):
+    """A repository's status, with the annotation linking a catalog entity to it."""
+
+    annotations: dict[str, str] = Field(
+        default_factory=dict, description="Annotations to add to the catalog entity, e.g. policy-miner/repository-id"
+    )
+    source_url: str | None = None
+
+
+class RoleCount(BaseModel):
+    """A role and the number of policies granting it."""
+
+    role: str
+    policies: int
+
+
+class BackstagePolicySummary(BaseModel):
+    """Counts of a repository's mined policies."""
+
+    total: int
+    by_status: dict[str, int] = Field(default_factory=dict)
+    by_risk_level: dict[str, int] = Field(default_factory=dict)
+    resources: int = Field(..., description="Distinct resources the policies protect")
+    top_roles: list[RoleCount] = Field(default_factory=list)
+
+
+class BackstageFinding(BaseModel):
+    """An open finding, as listed on a catalog page."""
+
+    rule_id: str
+    title: str
+    severity: str
+    description: str | None = None
+    file_path: str | None = None
+    line_start: int | None = None
+    cwe: list[str] = Field(default_factory=list)
+    owasp_api: list[str] = Field(default_factory=list)
+
+
+class BackstageFindingsSummary(BaseModel):
+    """Open finding counts."""
+
+    total: int
+    by_type: dict[str, int] = Field(default_factory=dict)
+    by_cwe: dict[str, int] = Field(default_factory=dict)
+    by_owasp_api: dict[str, int] = Field(default_factory=dict)
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class BackstagePosture(BackstageEntitySummary):
+    """Authorization posture shown on a service's catalog page."""
+
+    policies: BackstagePolicySummary
+    findings_summary: BackstageFindingsSummary
+    findings: list[BackstageFinding] = Field(default_factory=list, description="Open findings, most severe first")
+    findings_truncated: bool = False
@@ -0,0 +1,43 @@
+"""Schemas for broken object level authorization (BOLA) candidate detection."""
+from pydantic import BaseModel, Field
+
+
+class TraceStepResponse(BaseModel):
+    """One step of the dataflow from a request input to a lookup."""
+
+    step: str = Field(..., description="input, assignment, lookup, or use")
+    line: int
+    code: str
+
+
+class BolaCandidateResponse(BaseModel):
+    """A lookup by a client-supplied ID that no ownership or tenancy check covers."""
+
+    repository_id: int
+    repository_name: str
+    file_path: str
+    function: str | None = Field(None, description="Handler name; None for anonymous handlers")
+    line_start: int
+    line_end: int
+    identifier: str = Field(..., description="Request input holding the object ID")
+    lookup: str = Field(..., description="Lookup the ID reaches, e.g. findById")
+    endpoint: str | None = Field(None, description="Route of the handler, when declared next to it")
+    severity: str = Field(..., description="high when the object is modified, medium when read, low for admin-only handlers")
+    description: str
+    trace: list[TraceStepResponse] = Field(default_factory=list, description="Dataflow from the request input to the lookup and its use")
+
+
+class BolaSummary(BaseModel):
+    """Counts across the reported candidates."""
+
+    total: int
+    repositories_scanned: int = Field(..., description="Repositories with a clone to scan")
+    repositories_with_findings: int
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class BolaReport(BaseModel):
+    """BOLA candidates across repositories."""
+
+    findings: list[BolaCandidateResponse] = Field(default_factory=list)
+    summary: BolaSummary
@@ -0,0 +1,65 @@
+"""Schemas for bundle targets and publications."""
+from datetime import datetime
+from typing import Literal
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class BundleTargetCreate(BaseModel):
+    """Register an OPA bundle server or OCI registry."""
+
+    name: str
+    target_type: Literal["opa_bundle_server", "oci_registry"]
+    url: str = Field(..., description="Bundle upload URL, or registry base URL (https://ghcr.io)")
+    repository: str | None = Field(None, description="OCI repository path (required for registries)")
+    bundle_name: str = Field("policy-miner", description="Bundle file name and manifest revision prefix")
+    username: str | None = Field(None, description="Registry user (token auth if omitted)")
+    secret: str | None = Field(None, description="Bearer token or registry password")
+    signing_algorithm: Literal["HS256", "RS256", "ES256"] | None = Field(None, description="Unsigned if omitted")
+    signing_key: str | None = Field(None, description="HMAC secret or PEM private key")
+    signing_key_id: str | None = Field(None, description="keyid matching OPA's keys configuration")
+    application_id: int | None = Field(None, description="Publish only this application's policies")
+    auto_publish: bool = Field(False, description="Publish a new version whenever a policy is approved")
+    min_confidence: float | None = Field(
+        None, ge=0, le=100, description="Leave out policies with a lower confidence score (0-100)"
+    )
+
+
+class BundleTargetResponse(BaseModel):
+    """A bundle target (secrets and keys are never returned)."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    target_type: str
+    url: str
+    repository: str | None
+    bundle_name: str
+    username: str | None
+    signing_algorithm: str | None
+    signing_key_id: str | None
+    application_id: int | None
+    auto_publish: bool
+    min_confidence: float | None = None
+    created_at: datetime | None
+
+
+class BundlePublicationResponse(BaseModel):
+    """One published bundle version."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    target_id: int
+    version: int
+    revision: str = Field(..., description="Revision in the bundle manifest, reported by OPA status")
+    status: str = Field(..., description="pending, success, or failed")
+    policy_ids: list[int]
+    digest: str | None = Field(None, description="sha256 of the bundle archive")
+    location: str | None = Field(None, description="URL or OCI reference the bundle was pushed to")
+    signed: bool
+    triggered_by: str | None
+    error_message: str | None
+    created_at: datetime | None
+    published_at: datetime | None
@@ -0,0 +1,17 @@
+"""Schemas for Casbin model and policy imports."""
+from pydantic import BaseModel, Field
+
+
+class CasbinScanResult(BaseModel):
+    """Summary of the Casbin rules imported from a repository."""
+
+    repository_id: int
+    enforcers: int = Field(..., description="Enforcers built in code, or assumed from the repository's only model and policy")
+    enforce_calls: int = Field(0, description="Enforce calls found")
+    unresolved_calls: int = Field(0, description="Enforce calls no imported rule can decide, e.g. with policies in a database")
+    models: list[str] = Field(default_factory=list, description="Casbin model files found")
+    policy_files: list[str] = Field(default_factory=list, description="Policy files an enforcer loads")
+    rules: int = Field(0, description="Policy rows and policies added in code that were imported")
+    policies_created: int = Field(0, description="New policies created from Casbin rules")
+    policies_merged: int = Field(0, description="Existing policies a Casbin rule corroborated")
+    policies_removed: int = Field(0, description="Policies from an earlier Casbin import that were replaced")
@@ -0,0 +1,36 @@
+"""Schemas for exporting mined policies as Cedar."""
+from typing import Any
+
+from pydantic import BaseModel, Field
+
+from app.schemas.rego_export import UntranslatedClause
+
+
+class CedarPolicy(BaseModel):
+    """Cedar statement granting what one mined policy grants."""
+
+    policy_id: int
+    endpoint: str = Field(..., description="Method and path the policy was mapped to, e.g. GET /api/expenses/{}")
+    statement: str = Field(..., description="Static policy, as Verified Permissions' CreatePolicy takes it")
+
+
+class CedarExportSummary(BaseModel):
+    """What an export contains and what it left out."""
+
+    policies: int
+    endpoints: int
+    entity_types: int
+    below_min_confidence: int = Field(..., description="Policies left out for scoring below min_confidence")
+    untranslated_clauses: int
+
+
+class CedarExportResponse(BaseModel):
+    """Cedar policies and entity schema of a repository's mined policies."""
+
+    repository_id: int
+    namespace: str = Field(..., description="Schema namespace the entity types are declared in")
+    policies: list[CedarPolicy]
+    cedar: str = Field(..., description="All statements as one policy file")
+    entity_schema: dict[str, Any] = Field(..., description="Cedar JSON schema, as Verified Permissions' PutSchema takes it")
+    untranslated: list[UntranslatedClause] = []
+    summary: CedarExportSummary
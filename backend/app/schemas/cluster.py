@@ -0,0 +1,32 @@
+"""Schemas for the state of a highly available deployment."""
+from pydantic import BaseModel, Field
+
+
+class RegisteredScan(BaseModel):
+    """Scan running on a registered worker."""
+
+    task_id: str
+    repository_id: int
+    tenant_id: str | None
+    incremental: bool
+    started_at: str
+
+
+class RegisteredWorker(BaseModel):
+    """Scan worker that registered itself and is heartbeating."""
+
+    worker_id: str = Field(..., description="Celery node name, e.g. celery@scan-worker-5d8f7-x2k4q")
+    hostname: str
+    state: str = Field(..., description="active, or draining while shutting down")
+    started_at: str
+    heartbeat_at: str
+    drain_deadline: str | None = Field(None, description="When a draining worker hands its scan to another")
+    scans: list[RegisteredScan]
+
+
+class ClusterStatus(BaseModel):
+    """Leaders and registered workers."""
+
+    leaders: dict[str, str | None] = Field(..., description="Replica leading the scheduler and operator, if any")
+    workers: list[RegisteredWorker]
+    orphaned_scans: int = Field(..., description="Scans whose worker is gone, awaiting hand-off")
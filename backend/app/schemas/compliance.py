@@ -0,0 +1,46 @@
+"""Schemas for compliance framework posture."""
+from pydantic import BaseModel, Field
+
+
+class ComplianceFramework(BaseModel):
+    """A configured compliance framework."""
+
+    id: str = Field(..., description="Framework ID (e.g., soc2, pci_dss, iso27001)")
+    name: str
+    control_count: int
+
+
+class ComplianceSignal(BaseModel):
+    """A posture signal backing a control."""
+
+    name: str
+    status: str = Field(..., description="pass, fail, or unknown")
+    value: int | float | None = None
+    detail: str
+
+
+class ComplianceControl(BaseModel):
+    """Posture of one control."""
+
+    control_id: str
+    title: str
+    status: str = Field(..., description="passing, failing, or not_assessed")
+    signals: list[ComplianceSignal]
+
+
+class FrameworkPosture(BaseModel):
+    """Posture summary for one framework."""
+
+    id: str
+    name: str
+    passing: int
+    failing: int
+    not_assessed: int
+    controls: list[ComplianceControl]
+
+
+class CompliancePosture(BaseModel):
+    """Posture across frameworks."""
+
+    generated_at: str
+    frameworks: list[FrameworkPosture]
@@ -0,0 +1,57 @@
+"""Schemas for CORS and CSRF posture."""
+from pydantic import BaseModel, Field
+
+
+class CorsSettingResponse(BaseModel):
+    """A CORS configuration found in a repository."""
+
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    scope: str | None = Field(None, description="Path pattern it applies to; null when service-wide")
+    origins: list[str] = Field(default_factory=list, description="Explicitly allowed origins")
+    wildcard_origin: bool
+    reflects_origin: bool = Field(..., description="Echoes the request's Origin header back")
+    allow_credentials: bool
+    credentialed_wildcard: bool = Field(..., description="Any origin may send credentialed requests")
+
+
+class CsrfSettingResponse(BaseModel):
+    """A CSRF protection setting or exemption found in a repository."""
+
+    file_path: str
+    line_start: int
+    status: str = Field(..., description="enabled, disabled, or exempt")
+    setting: str = Field(..., description="The code that installs, disables, or exempts protection")
+    scopes: list[str] = Field(default_factory=list, description="Exempted paths, when known")
+
+
+class EndpointCorsCsrf(BaseModel):
+    """CORS and CSRF posture of one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    requires_authentication: bool
+    cors_origins: list[str] = Field(default_factory=list)
+    wildcard_origin: bool
+    allow_credentials: bool
+    csrf: str = Field(..., description="protected, exempt, disabled, not_applicable, or unknown")
+    credentialed_wildcard: bool = Field(
+        ..., description="Authenticated endpoint any origin may call with credentials"
+    )
+
+
+class ServiceCorsCsrf(BaseModel):
+    """CORS and CSRF posture of one service (repository)."""
+
+    repository_id: int
+    repository_name: str
+    cloned: bool = Field(..., description="Whether a clone was available to read settings from")
+    cors: list[CorsSettingResponse] = Field(default_factory=list)
+    csrf: list[CsrfSettingResponse] = Field(default_factory=list)
+    csrf_protection: str = Field(..., description="enabled, partial, disabled, or none")
+    flagged_endpoints: int
+    endpoints: list[EndpointCorsCsrf] = Field(default_factory=list)
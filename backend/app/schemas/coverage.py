@@ -0,0 +1,42 @@
+"""Schemas for authorization coverage metrics."""
+from pydantic import BaseModel, Field
+
+
+class UnknownPatternBreakdown(BaseModel):
+    """Mined authorization the endpoint model could not interpret."""
+
+    unmapped_routes: int = Field(..., description="Policies whose evidence has no recognizable route")
+    unrecognized_subjects: int = Field(..., description="Policies whose subject names no recognizable role")
+
+
+class CoverageTotals(BaseModel):
+    """Endpoint counts and coverage KPIs."""
+
+    total_endpoints: int
+    authenticated_endpoints: int
+    authorized_endpoints: int = Field(..., description="Endpoints checking roles or conditions beyond authentication")
+    conditional_endpoints: int = Field(..., description="Endpoints with ABAC conditions")
+    authenticated_percent: float
+    authorized_percent: float
+    conditional_percent: float
+    unknown_patterns: int
+    auth_mechanisms: dict[str, int] = Field(
+        default_factory=dict, description="Endpoints per authentication mechanism (cookie_session, jwt_bearer, ...)"
+    )
+
+
+class RepositoryCoverage(CoverageTotals):
+    """Coverage metrics for one service."""
+
+    repository_id: int
+    repository_name: str
+    inventory_source: str = Field(..., description="source (clone parsed) or policies (mined endpoints only)")
+    unknown_pattern_breakdown: UnknownPatternBreakdown
+    unprotected_endpoints: list[str] = Field(..., description="Endpoints without an authentication check")
+
+
+class WorkspaceCoverage(BaseModel):
+    """Coverage metrics across services."""
+
+    repositories: list[RepositoryCoverage]
+    totals: CoverageTotals
@@ -0,0 +1,152 @@
+"""Schemas for custom detection rules, their versions, and their hits."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class CustomRuleDefinition(BaseModel):
+    """A custom rule as defined in the API or a rules file."""
+
+    name: str = Field(..., description="Rule name, unique within the workspace")
+    description: str | None = None
+    pattern: str = Field(
+        ...,
+        description='Regular expression matched per line; named groups "role", "permission", "resource", '
+        '"action", and "subject" are reported with each match',
+    )
+    languages: list[str] = Field(default_factory=list, description="Languages in scope; empty for every language")
+    paths: list[str] = Field(default_factory=list, description='File globs in scope, e.g. "src/**"; empty for every file')
+    exclude_paths: list[str] = Field(default_factory=list)
+    severity: str = Field("medium", description="low, medium, high, or critical")
+    enabled: bool = True
+
+
+class CustomRuleUpdate(BaseModel):
+    """Changes to a custom rule; omitted fields are left as they are."""
+
+    name: str | None = None
+    description: str | None = None
+    pattern: str | None = None
+    languages: list[str] | None = None
+    paths: list[str] | None = None
+    exclude_paths: list[str] | None = None
+    severity: str | None = None
+    enabled: bool | None = None
+
+
+class CustomRuleResponse(BaseModel):
+    """A custom rule at its current version."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    description: str | None = None
+    pattern: str
+    languages: list[str]
+    paths: list[str]
+    exclude_paths: list[str]
+    severity: str
+    enabled: bool
+    version: int
+    created_by: str | None = None
+    created_at: datetime
+    updated_at: datetime | None = None
+
+
+class CustomRuleVersionResponse(BaseModel):
+    """A custom rule's definition as of one version."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    rule_id: int
+    version: int
+    definition: dict
+    change: str = Field(..., description="created, updated, synced, or restored")
+    changed_by: str | None = None
+    created_at: datetime
+
+
+class CustomRuleSyncRequest(BaseModel):
+    """A rules file to make the workspace's rules match."""
+
+    rules: list[CustomRuleDefinition]
+    prune: bool = Field(False, description="Delete workspace rules the file does not define")
+    dry_run: bool = Field(False, description="Report the changes without applying them")
+
+
+class CustomRuleSyncResult(BaseModel):
+    """Rule names changed by a sync."""
+
+    created: list[str]
+    updated: list[str]
+    unchanged: list[str]
+    deleted: list[str]
+    dry_run: bool
+
+
+class CustomRuleDryRunRequest(BaseModel):
+    """Rules to evaluate against a repository without recording hits."""
+
+    repository_id: int
+    rules: list[CustomRuleDefinition] | None = Field(None, description="Unsaved definitions; take precedence")
+    rule_ids: list[int] | None = Field(None, description="Saved rules; every enabled rule when neither is given")
+
+
+class CustomRuleMatch(BaseModel):
+    """A line a rule matched."""
+
+    file_path: str
+    line: int
+    snippet: str
+    captures: dict[str, str] = Field(default_factory=dict)
+
+
+class CustomRuleEvaluation(BaseModel):
+    """How one rule matched a repository."""
+
+    name: str
+    hits: int
+    files: int
+    matches: list[CustomRuleMatch] = Field(..., description="First matches; hits counts every match")
+
+
+class CustomRuleDryRunResult(BaseModel):
+    """Dry-run results against a repository's clone."""
+
+    repository_id: int
+    repository_name: str
+    files_evaluated: int
+    rules: list[CustomRuleEvaluation]
+
+
+class CustomRuleHitResponse(BaseModel):
+    """A rule's hits in one scan."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    rule_id: int
+    rule_version: int
+    repository_id: int
+    scan_id: int | None = None
+    hits: int
+    files: int
+    matches: list[CustomRuleMatch]
+    created_at: datetime
+
+
+class CustomRuleStats(BaseModel):
+    """A rule's hits across every scan it was evaluated in."""
+
+    rule_id: int
+    name: str
+    version: int
+    enabled: bool
+    scans_evaluated: int
+    scans_with_hits: int
+    total_hits: int
+    current_version_hits: int = Field(..., description="Hits recorded by scans run at the current version")
+    repositories_hit: int
+    last_hit_at: datetime | None = None
@@ -0,0 +1,62 @@
+"""Schemas for configurable dashboard widgets."""
+from datetime import datetime
+from typing import Any
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class MetricDefinitionResponse(BaseModel):
+    """A metric widgets can display."""
+
+    metric: str
+    description: str
+    chart_types: list[str]
+    filters: list[str] = Field(..., description="Filter keys the metric accepts")
+
+
+class DashboardWidgetCreate(BaseModel):
+    """Request to add a widget."""
+
+    title: str
+    metric: str = Field(..., description="Metric name from the metric catalog")
+    chart_type: str = Field(..., description="number, bar, pie, line, or table")
+    filters: dict[str, Any] = Field(default_factory=dict, description="Scope, e.g., {\"repository_id\": 3}")
+    position: int = Field(0, description="Order on the dashboard")
+    shared: bool = Field(False, description="Show on every dashboard in the workspace instead of only yours")
+
+
+class DashboardWidgetUpdate(BaseModel):
+    """Request to change a widget."""
+
+    title: str | None = None
+    metric: str | None = None
+    chart_type: str | None = None
+    filters: dict[str, Any] | None = None
+    position: int | None = None
+
+
+class DashboardWidgetResponse(BaseModel):
+    """A dashboard widget."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    title: str
+    metric: str
+    chart_type: str
+    filters: dict[str, Any]
+    position: int
+    owner_email: str | None = Field(None, description="Owning user (null for workspace-wide widgets)")
+    created_at: datetime
+    updated_at: datetime | None = None
+
+
+class WidgetData(BaseModel):
+    """Pre-aggregated data for one widget."""
+
+    widget_id: int
+    title: str
+    metric: str
+    chart_type: str
+    data: Any = Field(None, description="Metric data shaped for the chart")
+    error: str | None = Field(None, description="Why the data could not be computed")
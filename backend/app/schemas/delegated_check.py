@@ -0,0 +1,28 @@
+"""Schemas for checks handlers delegate to helper functions."""
+from pydantic import BaseModel, Field
+
+
+class DelegatedCheckItem(BaseModel):
+    """A role requirement or condition enforced in a helper a handler calls."""
+
+    policy_id: int | None
+    route: str | None = Field(None, description="Endpoint of the policy, e.g. 'PUT /api/expenses/{id}/approve'")
+    handler: str | None = Field(None, description="Handler the call chain starts from; None for anonymous handlers")
+    chain: list[str] = Field(default_factory=list, description="Functions called from the handler down to the check")
+    depth: int = Field(..., description="Calls between the handler and the check")
+    file_path: str
+    line: int
+    guard: str = Field(..., description="Source line of the check")
+    roles: list[str] = Field(default_factory=list, description="Roles any of which passes the check; empty for conditions")
+    condition: str | None = Field(None, description="Clause text of an attribute check, e.g. 'not suspended'")
+    added: bool = Field(..., description="Whether the check was attributed to the policy")
+
+
+class DelegatedCheckResult(BaseModel):
+    """Summary of the delegated checks attributed to a repository's policies."""
+
+    repository_id: int
+    depth: int = Field(..., description="Call levels followed from each handler")
+    policies_examined: int
+    policies_updated: int = Field(0, description="Policies that gained a subject or condition")
+    checks: list[DelegatedCheckItem] = Field(default_factory=list)
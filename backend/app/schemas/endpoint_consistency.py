@@ -0,0 +1,49 @@
+"""Schemas for endpoints of one resource authorized inconsistently."""
+from pydantic import BaseModel, Field
+
+
+class EndpointAccessResponse(BaseModel):
+    """Who may call one endpoint of a resource, with the rule's evidence."""
+
+    method: str
+    path: str
+    access: str = Field(..., description='Who may call it, e.g. "ADMIN" or "any authenticated user"')
+    roles: list[str] = Field(default_factory=list)
+    requires_authentication: bool
+    policy_ids: list[int] = Field(default_factory=list)
+    file_path: str | None = None
+    line: int | None = None
+
+
+class EndpointInconsistencyResponse(BaseModel):
+    """Two endpoints of one resource authorized in a way that does not fit together."""
+
+    repository_id: int
+    repository_name: str
+    resource: str = Field(..., description="Collection path of the resource, parameters as {}")
+    kind: str = Field(
+        ..., description="anonymous_write, unrestricted_write, write_role_mismatch, or read_stricter_than_write"
+    )
+    severity: str
+    looser: EndpointAccessResponse = Field(..., description="The endpoint more callers can reach")
+    stricter: EndpointAccessResponse
+    description: str
+    policy_ids: list[int] = Field(default_factory=list)
+
+
+class EndpointConsistencySummary(BaseModel):
+    """Counts across the report."""
+
+    resources: int = Field(..., description="Resources with more than one endpoint")
+    endpoints: int
+    documented: int = Field(..., description="Inconsistent pairs not reported because a rule documents why")
+    inconsistencies: int
+    by_kind: dict[str, int] = Field(default_factory=dict)
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class EndpointConsistencyReport(BaseModel):
+    """Inconsistently authorized endpoints across repositories, most severe first."""
+
+    inconsistencies: list[EndpointInconsistencyResponse] = Field(default_factory=list)
+    summary: EndpointConsistencySummary
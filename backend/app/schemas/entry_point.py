@@ -0,0 +1,34 @@
+"""Schemas for non-HTTP entry point inventories."""
+from pydantic import BaseModel, Field
+
+
+class EntryPointResponse(BaseModel):
+    """A message consumer, scheduled job, background task, or CLI command and its checks."""
+
+    kind: str = Field(..., description="message_consumer, scheduled_job, background_task, or cli_command")
+    framework: str
+    name: str = Field(..., description="Handler function, method, or class")
+    trigger: str | None = Field(None, description="Topic, queue, task name, schedule, or command path it runs on")
+    file_path: str
+    line_start: int
+    line_end: int
+    roles: list[str] = Field(default_factory=list)
+    identity_checked: bool = Field(False, description="Verifies a token, signature, or principal")
+    privileged_operations: list[str] = Field(default_factory=list)
+    authorization: str = Field(..., description="role_checked, identity_checked, or unchecked")
+    unchecked_privileged: bool = Field(..., description="Performs privileged operations without any check")
+    policy_ids: list[int] = Field(default_factory=list, description="Mined policies enforced inside the handler")
+
+
+class ServiceEntryPoints(BaseModel):
+    """Non-HTTP entry point inventory of one service (repository)."""
+
+    repository_id: int
+    repository_name: str
+    cloned: bool = Field(..., description="Whether a clone was available to read entry points from")
+    by_kind: dict[str, int] = Field(default_factory=dict)
+    unchecked_privileged: int
+    entry_points: list[EntryPointResponse] = Field(default_factory=list)
+    cli_entry_points: list[EntryPointResponse] = Field(
+        default_factory=list, description="Commands of admin CLIs shipped in the repository"
+    )
@@ -0,0 +1,55 @@
+"""Schemas for highlight-ready evidence regions."""
+from pydantic import BaseModel, Field
+
+
+class SourceLine(BaseModel):
+    """A numbered source line."""
+
+    number: int
+    text: str
+
+
+class RegionHighlight(BaseModel):
+    """An evidence range to highlight inside a region."""
+
+    evidence_id: int
+    start_line: int
+    end_line: int
+
+
+class SourceRegionSchema(BaseModel):
+    """A contiguous slice of a source file."""
+
+    start_line: int
+    end_line: int
+    highlights: list[RegionHighlight]
+    lines: list[SourceLine]
+    truncated: bool = Field(False, description="Region was cut at the maximum region length")
+
+
+class EvidenceFile(BaseModel):
+    """Regions of one source file."""
+
+    file_path: str
+    language: str = Field(..., description="Syntax highlighter language ID")
+    source: str = Field(..., description="repository (cut from the scanned clone) or snippet (stored snippet only)")
+    total_lines: int | None = Field(None, description="Lines in the file, if the source was available")
+    regions: list[SourceRegionSchema]
+
+
+class EvidenceDetail(EvidenceFile):
+    """One evidence item with its surrounding context."""
+
+    evidence_id: int
+    policy_id: int
+    repository_id: int
+    line_start: int
+    line_end: int
+
+
+class PolicyEvidenceDetail(BaseModel):
+    """All evidence for a policy, grouped by file."""
+
+    policy_id: int
+    repository_id: int
+    files: list[EvidenceFile]
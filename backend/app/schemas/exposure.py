@@ -0,0 +1,38 @@
+"""Schemas for endpoint exposure inferred from deployment config."""
+from pydantic import BaseModel, Field
+
+
+class ExposureSignalResponse(BaseModel):
+    """An ingress, load balancer, or gateway definition that says who can reach the service."""
+
+    source: str = Field(..., description="ingress, load_balancer, cluster_service, mesh_gateway, api_gateway, or terraform")
+    exposure: str = Field(..., description="internet_facing or internal_only")
+    file_path: str
+    line_start: int
+    name: str
+    scopes: list[str] = Field(default_factory=list, description="Path patterns it routes; empty for the whole service")
+    hosts: list[str] = Field(default_factory=list)
+    reason: str
+
+
+class EndpointExposure(BaseModel):
+    """Inferred exposure of one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    requires_authentication: bool
+    exposure: str = Field(..., description="internet_facing, internal_only, or unknown")
+    reason: str | None = Field(None, description="The signal the exposure was inferred from")
+
+
+class ServiceExposure(BaseModel):
+    """Exposure signals and endpoint exposure of one service (repository)."""
+
+    repository_id: int
+    repository_name: str
+    cloned: bool = Field(..., description="Whether a clone was available to read deployment config from")
+    signals: list[ExposureSignalResponse] = Field(default_factory=list)
+    internet_facing_endpoints: int
+    endpoints: list[EndpointExposure] = Field(default_factory=list)
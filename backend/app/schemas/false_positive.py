@@ -0,0 +1,68 @@
+"""Schemas for false-positive feedback and suppression."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+from app.models.false_positive import FalsePositiveTarget
+
+
+class FalsePositiveCreate(BaseModel):
+    """Request to mark a mined rule or a finding as a false positive."""
+
+    target_type: FalsePositiveTarget = Field(
+        ..., description="policy, security_gap, conflict, or inconsistent_enforcement"
+    )
+    target_id: int = Field(..., description="ID of the policy or finding")
+    reason: str | None = Field(None, description="Why it is a false positive")
+
+
+class SuppressionPatternResponse(BaseModel):
+    """A learned false-positive pattern."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    analyzer: str
+    target_type: FalsePositiveTarget
+    fingerprint: str
+    pattern: str = Field(..., description="Code skeleton the fingerprint was taken of")
+    reason: str | None = None
+    active: bool
+    suppressed_count: int = Field(0, description="Detections dismissed automatically")
+    last_suppressed_at: datetime | None = None
+    created_by: str | None = None
+    created_at: datetime
+
+
+class FalsePositiveMarkResponse(BaseModel):
+    """A recorded false-positive decision and the pattern it taught."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    analyzer: str
+    target_type: FalsePositiveTarget
+    target_id: int
+    repository_id: int | None = None
+    reason: str | None = None
+    marked_by: str | None = None
+    created_at: datetime
+    pattern: SuppressionPatternResponse
+
+
+class SuppressionRun(BaseModel):
+    """Detections of a repository dismissed by the suppression model."""
+
+    repository_id: int
+    suppressed: dict[str, int] = Field(default_factory=dict, description="Dismissed detections per analyzer")
+
+
+class AnalyzerFalsePositiveRate(BaseModel):
+    """False-positive rate of one analyzer."""
+
+    analyzer: str
+    detections: int
+    marked_false_positive: int
+    suppressed: int
+    active_patterns: int
+    false_positive_rate: float = Field(..., description="Share of detections marked or suppressed (0-1)")
@@ -0,0 +1,59 @@
+"""Schemas for findings tagged with CWE and OWASP API Top 10 categories."""
+from pydantic import BaseModel, Field
+
+from app.services.finding_taxonomy import FindingType
+
+
+class TaggedFinding(BaseModel):
+    """A finding from any analyzer with its taxonomy tags."""
+
+    finding_type: FindingType
+    kind: str | None = Field(None, description="Kind within the type, e.g. a security gap type or pattern kind")
+    rule_id: str = Field(..., description="Taxonomy entry the tags come from, e.g. vulnerable_auth_pattern/jwt_none_algorithm")
+    finding_key: str | None = Field(None, description="Identifies the finding across scans while it is detected")
+    title: str
+    severity: str
+    description: str
+    cwe: list[str] = Field(default_factory=list, description="CWE identifiers, e.g. CWE-863")
+    owasp_api: list[str] = Field(default_factory=list, description="OWASP API Security Top 10 (2023) categories, e.g. API1:2023")
+    repository_id: int | None = None
+    repository_name: str | None = None
+    file_path: str | None = None
+    line_start: int | None = None
+    line_end: int | None = None
+    policy_ids: list[int] = Field(default_factory=list)
+
+
+class TaggedFindingSummary(BaseModel):
+    """Counts across the reported findings."""
+
+    total: int
+    by_type: dict[str, int] = Field(default_factory=dict)
+    by_cwe: dict[str, int] = Field(default_factory=dict)
+    by_owasp_api: dict[str, int] = Field(default_factory=dict)
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class TaggedFindingReport(BaseModel):
+    """Tagged findings across analyzers."""
+
+    findings: list[TaggedFinding] = Field(default_factory=list)
+    summary: TaggedFindingSummary
+
+
+class TaxonomyTag(BaseModel):
+    """A CWE or OWASP API category and its name."""
+
+    id: str
+    name: str
+
+
+class TaxonomyEntryResponse(BaseModel):
+    """The CWE and OWASP API tags of a finding type, or of one kind within it."""
+
+    finding_type: FindingType
+    kind: str | None = Field(None, description="Null for the tags every other kind of the type gets")
+    rule_id: str
+    title: str
+    cwe: list[TaxonomyTag]
+    owasp_api: list[TaxonomyTag]
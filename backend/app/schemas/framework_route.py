@@ -0,0 +1,19 @@
+"""Schemas for framework route configuration scans."""
+from pydantic import BaseModel, Field
+
+
+class FrameworkRouteScanResult(BaseModel):
+    """Summary of route authorization mined from framework configuration."""
+
+    repository_id: int
+    files: int = Field(..., description="Source and config files that declared guarded routes")
+    findings_by_kind: dict[str, int] = Field(
+        default_factory=dict,
+        description=(
+            "Findings per kind (hapi_route, koa_route, micronaut_route, micronaut_intercept_url, "
+            "quarkus_route, quarkus_http_permission, play_route, play_action)"
+        ),
+    )
+    policies_created: int = Field(0, description="New policies created from route configuration")
+    policies_merged: int = Field(0, description="Existing policies the route configuration corroborated")
+    policies_removed: int = Field(0, description="Policies from an earlier route scan that were replaced")
@@ -0,0 +1,76 @@
+"""Schemas for workspace identity models and condition mapping."""
+from datetime import datetime
+from typing import Any
+
+from pydantic import BaseModel, Field
+
+
+class IdentityAttribute(BaseModel):
+    """An attribute a subject type carries."""
+
+    name: str = Field(..., description="Attribute name as the PDP knows it (e.g., department)")
+    type: str = Field("string", description="string, number, boolean, list, or datetime")
+    aliases: list[str] = Field(default_factory=list, description="Spellings used in code (e.g., dept, claims.department)")
+
+
+class SubjectType(BaseModel):
+    """A kind of subject, such as a user, service account, or device."""
+
+    name: str = Field(..., description="Subject type name (e.g., user, service_account, device)")
+    identifier: str | None = Field(None, description="Attribute identifying the subject, compared by ownership checks")
+    attributes: list[IdentityAttribute] = Field(default_factory=list)
+
+
+class IdentityModelUpdate(BaseModel):
+    """Request to declare the workspace's identity model."""
+
+    subject_types: list[SubjectType]
+
+
+class IdentityModelResponse(BaseModel):
+    """The workspace's identity model."""
+
+    declared: bool = Field(..., description="False while the default model is in use")
+    subject_types: list[SubjectType]
+    updated_by: str | None = None
+    updated_at: datetime | None = None
+
+
+class ClauseMapping(BaseModel):
+    """How one condition clause maps onto the identity model."""
+
+    raw: str
+    kind: str
+    attribute: str | None = None
+    status: str = Field(..., description="mapped, unknown, or unscoped")
+    subject_type: str | None = None
+    declared_attribute: str | None = None
+    text: str = Field(..., description="Clause rewritten onto the declared attribute")
+
+
+class PolicyConditionMapping(BaseModel):
+    """A policy's condition mapped onto the identity model."""
+
+    policy_id: int
+    repository_id: int | None = None
+    conditions: str
+    mapped_conditions: str
+    clauses: list[ClauseMapping]
+
+
+class UnknownAttribute(BaseModel):
+    """A subject attribute used by mined conditions but not declared."""
+
+    attribute: str
+    policy_ids: list[int]
+    occurrences: int
+
+
+class IdentityMappingReport(BaseModel):
+    """Mined conditions mapped onto the workspace's identity model."""
+
+    declared: bool
+    policies: list[PolicyConditionMapping]
+    unknown_attributes: list[UnknownAttribute]
+    summary: dict[str, Any]
+    policies_updated: int | None = Field(None, description="Set when the mapping was applied")
@@ -0,0 +1,65 @@
+"""Schemas for end-user identity propagation across service calls."""
+from pydantic import BaseModel, Field
+
+from app.schemas.service_graph import ServiceHop
+
+
+class IdentityService(BaseModel):
+    """A service and the principals it is called as."""
+
+    name: str
+    repository_id: int
+    entry: bool = Field(..., description="Whether no internal call reaches the service, so end users call it directly")
+    receives: list[str] = Field(default_factory=list, description='"end user" and the services whose identity reaches it')
+
+
+class IdentityHop(ServiceHop):
+    """A hop and whose identity it presents to the callee."""
+
+    identity: str = Field(..., description="user, service, relayed, or none")
+    carried: list[str] = Field(default_factory=list, description="Principals the hop's credentials stand for")
+    replaces_user: bool = Field(..., description="Whether the caller receives the end user's identity but calls as itself")
+
+
+class ConfusedDeputy(BaseModel):
+    """A service identity acting on request input the caller never checks the user's right to."""
+
+    source: str
+    source_repository_id: int
+    target: str
+    target_repository_id: int | None = None
+    method: str
+    path: str
+    file_path: str
+    line_start: int
+    line_end: int
+    identity: str = Field(..., description="service or relayed")
+    credential_evidence: str | None = None
+    user_input: str = Field(..., description="Request input the call carries")
+    caller_endpoint: str | None = Field(None, description="Endpoint of the caller's handler making the call")
+    caller_roles: list[str] = Field(default_factory=list)
+    target_endpoint: str | None = None
+    target_roles: list[str] = Field(default_factory=list)
+    target_conditions: list[str] = Field(default_factory=list)
+    target_policy_ids: list[int] = Field(default_factory=list)
+    severity: str = Field(..., description="high when the caller endpoint is public, low when it checks roles")
+    description: str
+
+
+class IdentityPropagationSummary(BaseModel):
+    """Totals over the traced hops."""
+
+    hops: int
+    by_identity: dict[str, int] = Field(default_factory=dict)
+    replaced: int = Field(..., description="Hops replacing the end user's identity with the caller's")
+    deputies: int
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class IdentityPropagation(BaseModel):
+    """Whose identity each internal call carries, and the confused deputies among them."""
+
+    services: list[IdentityService]
+    hops: list[IdentityHop]
+    deputies: list[ConfusedDeputy]
+    summary: IdentityPropagationSummary
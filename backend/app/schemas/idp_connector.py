@@ -0,0 +1,69 @@
+"""Schemas for IdP role sync connectors and role drift findings."""
+from datetime import datetime
+from typing import Literal
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class IdpConnectorCreate(BaseModel):
+    """Register a Keycloak realm or Auth0 tenant for role sync."""
+
+    name: str
+    connector_type: Literal["keycloak", "auth0"]
+    base_url: str = Field(..., description="Keycloak server URL or Auth0 tenant URL (https://acme.us.auth0.com)")
+    realm: str | None = Field(None, description="Keycloak realm (required for Keycloak)")
+    client_id: str = Field(..., description="Client-credentials client with permission to read roles")
+    client_secret: str
+    role_client_id: str | None = Field(None, description="Keycloak client whose client roles are included")
+    application_id: int | None = Field(None, description="Compare only against this application's policies")
+    sync_interval_minutes: int | None = Field(None, ge=5, description="Defaults to IDP_ROLE_SYNC_INTERVAL_MINUTES")
+
+
+class IdpConnectorResponse(BaseModel):
+    """A registered connector (the client secret is never returned)."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    connector_type: str
+    base_url: str
+    realm: str | None
+    client_id: str
+    role_client_id: str | None
+    application_id: int | None
+    sync_interval_minutes: int | None
+    last_synced_at: datetime | None
+    last_error: str | None
+    idp_roles: list[str] | None = Field(None, description="Role names from the latest successful sync")
+
+
+class RoleDriftResponse(BaseModel):
+    """A role defined on only one side, IdP or code."""
+
+    id: int | None
+    connector_id: int
+    role: str
+    kind: str = Field(..., description="unused_in_code or missing_from_idp")
+    status: str = Field(..., description="open, resolved, or dismissed")
+    details: dict = Field(default_factory=dict, description="IdP description, or policy IDs checking the role")
+    first_detected_at: datetime | None
+    last_seen_at: datetime | None
+    resolved_at: datetime | None
+
+
+class RoleDriftStatusUpdate(BaseModel):
+    """Change a drift finding's status."""
+
+    status: Literal["open", "dismissed"]
+
+
+class IdpRoleSyncResult(BaseModel):
+    """Result of syncing one connector."""
+
+    connector_id: int
+    idp_roles: int
+    code_roles: int
+    opened: list[RoleDriftResponse] = Field(..., description="Drift findings opened or reopened by this sync")
+    resolved: int
+    open_drifts: int
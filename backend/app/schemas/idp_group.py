@@ -0,0 +1,94 @@
+"""Schemas for IdP group ingestion and endpoint population analysis."""
+from datetime import datetime
+from typing import Literal
+
+from pydantic import BaseModel, Field
+
+
+class IdpGroupSyncRequest(BaseModel):
+    """Live pull of groups from an IdP API."""
+
+    source: Literal["okta", "entra"]
+    token: str = Field(..., description="Okta API token or Microsoft Graph access token")
+    base_url: str | None = Field(None, description="Okta org URL; optional Graph endpoint override for Entra")
+
+
+class IdpGroupIngestResult(BaseModel):
+    """Result of ingesting groups and re-expanding role assignments."""
+
+    source: str | None = None
+    groups: int | None = Field(None, description="Groups stored for the source")
+    mappings: int | None = Field(None, description="Explicit mappings stored")
+    assignments: int = Field(..., description="User-to-role assignments derived from all IdP groups")
+    users: int = Field(..., description="Distinct users holding at least one mined role")
+    unmapped_groups: list[str] = Field(default_factory=list, description="Groups that grant no mined role")
+
+
+class GroupRoleMappingInput(BaseModel):
+    """Explicit group-to-role mapping."""
+
+    group_name: str = Field(..., description="IdP group name")
+    role: str = Field(..., description="Code-level role the group grants (e.g., ADMIN)")
+    source: Literal["okta", "entra", "ldap"] | None = Field(None, description="Limit to one IdP")
+    application_id: int | None = Field(None, description="Scope to one application; global if omitted")
+
+
+class GroupRoleMappingRequest(BaseModel):
+    """Bulk update of group-to-role mappings."""
+
+    mappings: list[GroupRoleMappingInput]
+    replace: bool = Field(False, description="Replace all existing mappings")
+
+
+class GroupRoleResult(BaseModel):
+    """A role granted by a group."""
+
+    role: str
+    application_id: int | None
+    match: str = Field(..., description="mapped (explicit mapping) or name (group name ends in the role)")
+
+
+class IdpGroupResponse(BaseModel):
+    """An ingested group."""
+
+    id: int
+    source: str
+    name: str
+    external_id: str | None
+    member_count: int
+    roles: list[GroupRoleResult]
+    synced_at: datetime | None
+
+
+class RolePopulation(BaseModel):
+    """Holders of one role allowed on an endpoint."""
+
+    role: str
+    users: int
+    groups: list[str] = Field(..., description="source:group names granting the role")
+
+
+class EndpointPopulation(BaseModel):
+    """Who can call one mined endpoint."""
+
+    endpoint: str
+    policy_ids: list[int]
+    public: bool
+    any_authenticated_user: bool
+    conditions: list[str] = Field(..., description="Runtime conditions that may narrow access further")
+    user_count: int
+    roles: list[RolePopulation]
+
+
+class EndpointPopulationReport(BaseModel):
+    """How many humans can call an endpoint."""
+
+    method: str
+    path: str
+    endpoints: list[EndpointPopulation]
+    user_count: int = Field(..., description="Distinct users allowed (upper bound when conditional)")
+    users: list[str]
+    public: bool = Field(..., description="Anonymous callers are allowed, so headcount is unbounded")
+    conditional: bool
+    roles_without_holders: list[str] = Field(..., description="Allowed roles no known user holds")
+    has_assignment_data: bool
@@ -0,0 +1,18 @@
+"""Schemas for container image scans."""
+from pydantic import BaseModel, Field
+
+
+class ImageScanResult(BaseModel):
+    """Summary of authorization config mined from a container image."""
+
+    repository_id: int
+    image: str = Field(..., description="Image reference the evidence is recorded under")
+    layers: int = Field(..., description="Number of image layers flattened")
+    files_examined: int = Field(..., description="Candidate config files left after applying all layers")
+    findings_by_kind: dict[str, int] = Field(
+        default_factory=dict, description="Findings per config kind (opa_rego, casbin_policy, nginx_auth, env_toggle)"
+    )
+    auth_disabling_findings: int = Field(0, description="Findings that switch authentication or authorization off")
+    policies_created: int = Field(0, description="New policies created from image config")
+    policies_merged: int = Field(0, description="Existing source-level policies the image config corroborated")
+    policies_removed: int = Field(0, description="Policies from an earlier scan of the same image that were replaced")
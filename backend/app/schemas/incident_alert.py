@@ -0,0 +1,69 @@
+"""Schemas for incident connectors and the auth regressions they page on."""
+from datetime import datetime
+from typing import Any, Literal
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class IncidentConnectorCreate(BaseModel):
+    """Register a PagerDuty service or Opsgenie team to page on auth regressions."""
+
+    name: str
+    provider: Literal["pagerduty", "opsgenie"]
+    secret: str = Field(..., description="PagerDuty Events API v2 routing key, or Opsgenie API integration key")
+    base_url: str | None = Field(
+        None, description="Provider API root, e.g. https://api.eu.opsgenie.com; the provider's default when unset"
+    )
+    repository_id: int | None = Field(None, description="Page only on this repository's regressions")
+
+
+class IncidentConnectorResponse(BaseModel):
+    """A registered connector (the secret is never returned)."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    provider: str
+    base_url: str | None
+    repository_id: int | None
+    last_triggered_at: datetime | None
+    last_error: str | None
+
+
+class RegressionIncidentResponse(BaseModel):
+    """The incident one connector holds for a regression."""
+
+    connector_id: int
+    dedup_key: str = Field(..., description="PagerDuty dedup_key or Opsgenie alias")
+    status: str = Field(..., description="triggered, resolved, or failed")
+    error: str | None
+    triggered_at: datetime | None
+    resolved_at: datetime | None
+
+
+class AuthRegressionResponse(BaseModel):
+    """An internet-facing route that lost its authentication between scans."""
+
+    id: int
+    repository_id: int
+    scan_id: int | None
+    previous_scan_id: int | None
+    endpoint: str
+    previous_protection: str = Field(..., description="policy, registration, controller, or middleware")
+    exposure: str
+    evidence: dict[str, Any] = Field(..., description="The route's registration before and after, with scans and commits")
+    description: str
+    status: str = Field(..., description="open or resolved")
+    detected_at: datetime | None
+    resolved_at: datetime | None
+    incidents: list[RegressionIncidentResponse] = []
+
+
+class IncidentDeliveryResult(BaseModel):
+    """Result of delivering one regression's incidents again."""
+
+    regression_id: int
+    triggered: int
+    resolved: int
+    failed: int
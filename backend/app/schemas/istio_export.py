@@ -0,0 +1,36 @@
+"""Schemas for exporting mined policies as Istio AuthorizationPolicies."""
+from pydantic import BaseModel, Field
+
+from app.schemas.rego_export import UntranslatedClause
+
+
+class IstioAuthorizationPolicy(BaseModel):
+    """ALLOW AuthorizationPolicy of one service."""
+
+    name: str
+    service: str = Field(..., description="Service the policy selects, by pod label")
+    endpoints: list[str] = Field(..., description="Methods and paths it admits, e.g. GET /api/expenses/{}")
+    mined_policy_ids: list[int]
+    rules: int = Field(..., description="One rule per mined policy")
+
+
+class IstioExportSummary(BaseModel):
+    """What an export contains and what it left to the application."""
+
+    policies: int
+    services: int
+    rules: int
+    endpoints: int
+    below_min_confidence: int = Field(..., description="Policies left out for scoring below min_confidence")
+    untranslated_clauses: int = Field(..., description="Clauses the mesh cannot check, enforced only by the application")
+
+
+class IstioExportResponse(BaseModel):
+    """Istio AuthorizationPolicies of a repository's mined policies."""
+
+    repository_id: int
+    namespace: str
+    manifests: str = Field(..., description="AuthorizationPolicy resources, as YAML documents for kubectl apply")
+    authorization_policies: list[IstioAuthorizationPolicy]
+    untranslated: list[UntranslatedClause] = []
+    summary: IstioExportSummary
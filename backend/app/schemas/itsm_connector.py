@@ -0,0 +1,78 @@
+"""Schemas for ITSM connectors and the tickets tracking findings."""
+from datetime import datetime
+from typing import Literal
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class ItsmConnectorCreate(BaseModel):
+    """Register a ServiceNow instance or generic ITSM endpoint for finding tickets."""
+
+    name: str
+    connector_type: Literal["servicenow", "generic"]
+    base_url: str = Field(..., description="ServiceNow instance URL (https://acme.service-now.com) or generic ticket API root")
+    username: str | None = Field(None, description="Basic auth user; without one the secret is sent as a bearer token")
+    secret: str = Field(..., description="Password or API token")
+    table: str | None = Field(None, description="ServiceNow table tickets are created in (default incident)")
+    assignment_group: str | None = Field(None, description="ServiceNow assignment group name or sys_id")
+    risk_accepted_code: str | None = Field(
+        None, description="ServiceNow close code that means the risk was accepted (default Risk Accepted)"
+    )
+    repository_id: int | None = Field(None, description="Ticket only this repository's findings")
+    min_severity: Literal["critical", "high", "medium", "low"] = Field("high", description="Least severe finding ticketed")
+    sync_interval_minutes: int | None = Field(None, ge=5, description="Defaults to ITSM_SYNC_INTERVAL_MINUTES")
+
+
+class ItsmConnectorResponse(BaseModel):
+    """A registered connector (the secret is never returned)."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    connector_type: str
+    base_url: str
+    username: str | None
+    table: str | None
+    assignment_group: str | None
+    risk_accepted_code: str | None
+    repository_id: int | None
+    min_severity: str
+    sync_interval_minutes: int | None
+    last_synced_at: datetime | None
+    last_error: str | None
+
+
+class FindingTicketResponse(BaseModel):
+    """The ticket tracking one finding."""
+
+    id: int | None
+    connector_id: int
+    finding_key: str = Field(..., description="Key of the finding in finding lists and exports")
+    rule_id: str
+    repository_id: int | None
+    title: str
+    severity: str
+    ticket_id: str
+    ticket_number: str | None
+    ticket_url: str | None
+    remote_state: str | None = Field(None, description="State as the ITSM reported it at the last sync")
+    status: str = Field(..., description="open, risk_accepted, or resolved")
+    resolution_note: str | None = Field(None, description="Close notes or risk acceptance justification")
+    created_at: datetime | None
+    status_changed_at: datetime | None
+    last_synced_at: datetime | None
+
+
+class ItsmSyncResult(BaseModel):
+    """Result of syncing one connector."""
+
+    connector_id: int
+    detected: int = Field(..., description="Findings at or above the connector's minimum severity")
+    created: int
+    resolved: int
+    reopened: int = Field(..., description="Tickets reopened because their finding is still or again detected")
+    risk_accepted: int = Field(..., description="Findings whose risk was accepted in the ITSM since the last sync")
+    acceptance_revoked: int = Field(..., description="Risk-accepted findings whose ticket was reopened in the ITSM")
+    open_tickets: int
+    accepted_tickets: int
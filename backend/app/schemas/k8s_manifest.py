@@ -0,0 +1,18 @@
+"""Schemas for Helm chart and Kubernetes manifest scans."""
+from pydantic import BaseModel, Field
+
+
+class K8sManifestScanResult(BaseModel):
+    """Summary of auth settings mined from a repository's manifests."""
+
+    repository_id: int
+    documents: int = Field(..., description="Kubernetes resource documents parsed")
+    files: int = Field(..., description="Manifest and Helm template files containing those documents")
+    findings_by_kind: dict[str, int] = Field(
+        default_factory=dict,
+        description="Findings per kind (k8s_ingress_auth, k8s_opa_sidecar, k8s_service_account_rbac)",
+    )
+    services: list[str] = Field(default_factory=list, description="Services the findings were attributed to")
+    policies_created: int = Field(0, description="New policies created from manifest settings")
+    policies_merged: int = Field(0, description="Existing policies the manifest settings corroborated")
+    policies_removed: int = Field(0, description="Policies from an earlier manifest scan that were replaced")
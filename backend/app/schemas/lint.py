@@ -0,0 +1,95 @@
+"""Schemas for organizational policy lint rules and their results."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+from app.models.lint import LintCheck
+
+
+class LintRuleCreate(BaseModel):
+    """Request to define a lint rule."""
+
+    name: str = Field(..., description="Rule name, unique within the workspace")
+    description: str | None = Field(None, description="What the rule requires")
+    check: LintCheck = Field(
+        ...,
+        description="require_role, require_authentication, no_frontend_only, allowed_mechanisms, or forbidden_roles",
+    )
+    params: dict = Field(
+        default_factory=dict,
+        description='Scope ("methods", "paths", "exclude_paths") and check settings ("mechanisms", "roles")',
+    )
+    severity: str = Field("medium", description="low, medium, high, or critical")
+    enabled: bool = True
+    blocking: bool = Field(True, description="Whether violations can fail the CI gate")
+
+
+class LintRuleUpdate(BaseModel):
+    """Changes to a lint rule; omitted fields are left as they are."""
+
+    description: str | None = None
+    params: dict | None = None
+    severity: str | None = None
+    enabled: bool | None = None
+    blocking: bool | None = None
+
+
+class LintRuleResponse(BaseModel):
+    """A lint rule."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    description: str | None = None
+    check: LintCheck
+    params: dict
+    severity: str
+    enabled: bool
+    blocking: bool
+    created_by: str | None = None
+    created_at: datetime
+    updated_at: datetime | None = None
+
+
+class LintViolation(BaseModel):
+    """An endpoint that breaks a lint rule."""
+
+    rule: str
+    check: str
+    severity: str
+    blocking: bool
+    endpoint: str = Field(..., description='e.g. "DELETE /api/users/{id}"')
+    message: str
+    policy_ids: list[int] = Field(default_factory=list)
+    file_path: str | None = None
+    line_start: int | None = None
+
+
+class LintReport(BaseModel):
+    """Lint results for a repository's current policies."""
+
+    repository_id: int
+    repository_name: str
+    rules_evaluated: int
+    endpoints_evaluated: int
+    violation_counts: dict[str, int] = Field(default_factory=dict, description="Violations per severity")
+    violations: list[LintViolation] = Field(default_factory=list, description="Most severe first")
+    fail_on: str = Field(..., description="Lowest severity of a blocking violation that fails the gate")
+    blocking_violations: int
+    passed: bool
+
+
+class LintRunResponse(BaseModel):
+    """Lint results stored when a scan completed."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    scan_id: int | None = None
+    rules_evaluated: int
+    endpoints_evaluated: int
+    violation_counts: dict[str, int] | None = None
+    violations: list[LintViolation] = Field(default_factory=list)
+    created_at: datetime
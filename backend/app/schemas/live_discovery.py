@@ -0,0 +1,42 @@
+"""Schemas for live endpoint discovery."""
+from pydantic import BaseModel, Field
+
+
+class LiveDiscoveryRequest(BaseModel):
+    """Running services to query."""
+
+    openapi_urls: list[str] = Field(
+        default_factory=list, description="OpenAPI document URLs, or REST base URLs to probe for one"
+    )
+    grpc_targets: list[str] = Field(default_factory=list, description="host:port of gRPC servers with reflection")
+    grpc_tls: bool = Field(False, description="Connect to gRPC servers with TLS")
+    headers: dict[str, str] = Field(default_factory=dict, description="Extra headers for OpenAPI requests")
+    repository_id: int | None = Field(None, description="Reconcile against this repository and its clone")
+    application_id: int | None = Field(None, description="Reconcile against this application's policies")
+
+
+class LiveEndpointResult(BaseModel):
+    """A live endpoint and how it relates to the static scan."""
+
+    protocol: str = Field(..., description="rest or grpc")
+    method: str
+    path: str
+    key: str = Field(..., description="Normalized route key")
+    target: str = Field(..., description="Document URL or gRPC server it was discovered on")
+    operation: str | None = None
+    requires_auth: bool | None = Field(None, description="Declared OpenAPI security; null when unknown")
+    streaming: bool = False
+    base_path: str = ""
+    status: str = Field(..., description="mined, unmined (route in source, no rule), or never_seen")
+
+
+class LiveDiscoveryReport(BaseModel):
+    """Reconciliation of live endpoints against mined routes."""
+
+    live_endpoints: int
+    counts: dict[str, int] = Field(..., description="Endpoints per status")
+    endpoints: list[LiveEndpointResult] = Field(..., description="Never-seen endpoints first")
+    mined_not_live: list[str] = Field(..., description="Mined routes no queried REST service serves")
+    unauthenticated_live: list[str] = Field(..., description="Live routes whose OpenAPI security is empty")
+    source_routes_available: bool = Field(..., description="Whether the repository clone was used")
+    errors: dict[str, str] = Field(default_factory=dict, description="Targets that could not be queried")
@@ -0,0 +1,116 @@
+"""Schemas for the management API behind the Terraform provider."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+from app.models.repository import RepositoryStatus, RepositoryType
+
+
+class WorkspaceCreate(BaseModel):
+    """Create a workspace (tenant)."""
+
+    workspace_id: str = Field(..., min_length=1, max_length=100, description="Tenant ID users and data belong to")
+    name: str = Field(..., min_length=1, max_length=255)
+    description: str | None = Field(None, max_length=1000)
+
+
+class WorkspaceUpdate(BaseModel):
+    """Rename or redescribe a workspace."""
+
+    name: str = Field(..., min_length=1, max_length=255)
+    description: str | None = Field(None, max_length=1000)
+
+
+class WorkspaceResponse(BaseModel):
+    """A workspace."""
+
+    workspace_id: str
+    name: str
+    description: str | None
+    created_at: datetime | None
+
+
+class ManagedRepositoryCreate(BaseModel):
+    """Register a repository in the caller's workspace."""
+
+    name: str = Field(..., min_length=1, max_length=255)
+    description: str | None = Field(None, max_length=1000)
+    repository_type: RepositoryType
+    source_url: str | None = Field(None, max_length=500)
+    connection_config: dict | None = Field(None, description="Credentials and connection settings; write-only")
+
+
+class ManagedRepositoryUpdate(BaseModel):
+    """Change a repository's registration."""
+
+    name: str = Field(..., min_length=1, max_length=255)
+    description: str | None = Field(None, max_length=1000)
+    source_url: str | None = Field(None, max_length=500)
+    connection_config: dict | None = Field(None, description="Replaces the stored config; omit to keep it")
+
+
+class ManagedRepositoryResponse(BaseModel):
+    """A registered repository (its connection config is never returned)."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    description: str | None
+    repository_type: RepositoryType
+    source_url: str | None
+    status: RepositoryStatus
+    last_scan_at: datetime | None
+    created_at: datetime | None
+
+
+class WebhookConfigUpdate(BaseModel):
+    """Turn a repository's push webhook on or off."""
+
+    enabled: bool = True
+    secret: str | None = Field(
+        None, min_length=16, description="Payload signing secret; generated when omitted and none is stored"
+    )
+
+
+class WebhookConfigResponse(BaseModel):
+    """A repository's push webhook settings."""
+
+    repository_id: int
+    enabled: bool
+    has_secret: bool
+    webhook_url: str = Field(..., description="Path to register with the Git provider")
+    secret: str | None = Field(None, description="Only returned when the secret was just set or generated")
+
+
+class ScanScheduleCreate(BaseModel):
+    """Scan a repository on a cron schedule."""
+
+    repository_id: int
+    cron: str = Field(..., max_length=100, description="Five-field cron expression in UTC, e.g. 0 3 * * 1-5, or @daily")
+    incremental: bool = Field(True, description="Only scan files changed since the last scan")
+    enabled: bool = True
+
+
+class ScanScheduleUpdate(BaseModel):
+    """Change a scan schedule."""
+
+    cron: str = Field(..., max_length=100)
+    incremental: bool = True
+    enabled: bool = True
+
+
+class ScanScheduleResponse(BaseModel):
+    """A repository's scan schedule."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    cron: str
+    incremental: bool
+    enabled: bool
+    next_run_at: datetime | None
+    last_run_at: datetime | None
+    last_task_id: str | None
+    last_error: str | None
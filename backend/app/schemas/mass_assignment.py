@@ -0,0 +1,38 @@
+"""Schemas for mass assignment detection."""
+from pydantic import BaseModel, Field
+
+
+class MassAssignmentCandidateResponse(BaseModel):
+    """A bind of the request body onto a model whose privileged fields it can set."""
+
+    repository_id: int
+    repository_name: str
+    file_path: str
+    function: str | None = Field(None, description="Handler or serializer name; None for anonymous handlers")
+    line_start: int
+    line_end: int
+    model: str = Field(..., description="Model the request body is bound to")
+    model_file: str = Field(..., description="File declaring the model")
+    fields: list[str] = Field(default_factory=list, description="Privileged fields the caller can set")
+    sink: str = Field(..., description="Bind or write taking the request body, e.g. Decode or create")
+    endpoint: str | None = Field(None, description="Route of the handler, when declared next to it")
+    severity: str = Field(
+        ..., description="high for role and admin fields, medium for approval and ownership fields, low for admin-only handlers"
+    )
+    description: str
+
+
+class MassAssignmentSummary(BaseModel):
+    """Counts across the reported candidates."""
+
+    total: int
+    repositories_scanned: int = Field(..., description="Repositories with a clone to scan")
+    repositories_with_findings: int
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class MassAssignmentReport(BaseModel):
+    """Mass assignment candidates across repositories."""
+
+    findings: list[MassAssignmentCandidateResponse] = Field(default_factory=list)
+    summary: MassAssignmentSummary
@@ -0,0 +1,17 @@
+"""Schemas for OPA query detection and Rego policy imports."""
+from pydantic import BaseModel, Field
+
+
+class OpaQueryScanResult(BaseModel):
+    """Summary of the Rego rules imported for a repository's OPA queries."""
+
+    repository_id: int
+    queries: int = Field(..., description="rego.New queries, opa.Decision calls, and /v1/data requests found")
+    routes: int = Field(0, description="Endpoints whose handler queries a package defined in the repository")
+    packages: list[str] = Field(default_factory=list, description="Rego packages the queries resolved to")
+    unresolved_paths: list[str] = Field(default_factory=list, description="Queried data paths no Rego package in the repository defines")
+    rego_files: list[str] = Field(default_factory=list, description="Rego files the imported rules come from")
+    rules: int = Field(0, description="Rule and endpoint pairs that were imported")
+    policies_created: int = Field(0, description="New policies created from queried Rego rules")
+    policies_merged: int = Field(0, description="Existing policies a queried Rego rule corroborated")
+    policies_removed: int = Field(0, description="Policies from an earlier OPA query import that were replaced")
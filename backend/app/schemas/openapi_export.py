@@ -0,0 +1,47 @@
+"""Schemas for annotating OpenAPI documents with mined authorization."""
+from typing import Any
+
+from pydantic import BaseModel, Field
+
+
+class OpenApiAnnotateRequest(BaseModel):
+    """OpenAPI document to annotate."""
+
+    spec: str = Field(..., min_length=1, max_length=5_000_000, description="OpenAPI 3 document as JSON or YAML")
+
+
+class OpenApiOperationAnnotation(BaseModel):
+    """What was set on one operation."""
+
+    method: str
+    path: str
+    mechanisms: list[str] = Field(..., description="Authentication mechanisms the evidence shows")
+    security: list[dict[str, list[str]]] | None = Field(
+        None, description="Security requirements set; empty for public operations, absent when left as they were"
+    )
+    required_roles: list[str] = Field(..., description="x-required-roles: the caller needs one of these")
+    mined_policy_ids: list[int]
+
+
+class OpenApiExportSummary(BaseModel):
+    """What an annotation covered and what it could not match."""
+
+    policies: int
+    operations_annotated: int
+    public_operations: int
+    unmatched_operations: int = Field(..., description="Operations no mined policy covers, left as they were")
+    unmatched_endpoints: int = Field(..., description="Mined endpoints the document lacks")
+    security_schemes: int
+    below_min_confidence: int = Field(..., description="Policies left out for scoring below min_confidence")
+
+
+class OpenApiExportResponse(BaseModel):
+    """OpenAPI document annotated with a repository's mined policies."""
+
+    repository_id: int
+    generated: bool = Field(..., description="Whether the document was generated from the mined routes")
+    spec: dict[str, Any] = Field(..., description="Annotated OpenAPI document")
+    operations: list[OpenApiOperationAnnotation]
+    unmatched_operations: list[str] = []
+    unmatched_endpoints: list[str] = []
+    summary: OpenApiExportSummary
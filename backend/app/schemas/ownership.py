@@ -0,0 +1,56 @@
+"""Schemas for policy ownership attribution and team views."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+from app.models.policy import PolicyStatus
+
+
+class PolicyOwnershipResponse(BaseModel):
+    """Owners of a mined policy."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    policy_id: int
+    repository_id: int
+    owners: list[str] = Field(default_factory=list, description='Teams, users, or emails, e.g. "@acme/payments"')
+    source: str = Field(..., description="codeowners, blame, or unowned")
+    codeowners_file: str | None = None
+    codeowners_pattern: str | None = Field(None, description="CODEOWNERS rule that matched")
+    file_path: str | None = None
+    authors: list[str] = Field(default_factory=list, description="git blame authors of the evidence, most lines first")
+    last_commit: str | None = None
+    last_modified_at: datetime | None = None
+    attributed_at: datetime
+
+
+class OwnershipRun(BaseModel):
+    """Outcome of attributing a repository's policies."""
+
+    repository_id: int
+    attributed: int = Field(..., description="Policies whose owners were resolved")
+    routed_work_items: int = Field(..., description="Work items assigned to an owner")
+
+
+class TeamSummary(BaseModel):
+    """What one owner is responsible for."""
+
+    team: str | None = Field(None, description="Owner; None collects unowned policies")
+    repository_ids: list[int] = Field(default_factory=list)
+    policies: int
+    pending_policies: int = Field(..., description="Owned policies awaiting review")
+    open_work_items: int = Field(..., description="Open work items routed to the owner")
+
+
+class TeamPolicy(BaseModel):
+    """A policy in a team's view."""
+
+    policy_id: int
+    repository_id: int
+    subject: str
+    resource: str
+    action: str
+    status: PolicyStatus
+    source: str
+    file_path: str | None = None
+    authors: list[str] = Field(default_factory=list)
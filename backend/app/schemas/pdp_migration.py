@@ -0,0 +1,68 @@
+"""Schemas for tracking the migration from inline checks to PDP calls."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class MigrationCallSiteResponse(BaseModel):
+    """An inline check, its equivalent PDP queries, and its replacement."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    application_id: int | None = None
+    policy_id: int | None = Field(None, description="Policy mined from the check; cleared once the policy is gone")
+    file_path: str
+    line_start: int
+    line_end: int
+    language: str | None = Field(None, description="Integration stub language; no replacement without one")
+    check_snippet: str
+    subject: str | None = None
+    action: str | None = None
+    resource: str | None = None
+    conditions: str | None = None
+    opa_query: dict = Field(..., description="Input document for OPA's data API")
+    cedar_query: dict = Field(..., description="Cedar is_authorized request")
+    replacement: str | None = Field(None, description="Code calling the integration stub in place of the check")
+    status: str = Field(..., description="pending, migrated, or removed")
+    first_seen_at: datetime | None = None
+    checked_at: datetime | None = None
+    migrated_at: datetime | None = None
+
+
+class MigrationProgressResponse(BaseModel):
+    """A repository's migration progress at one refresh."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    repository_id: int
+    scan_id: int | None = None
+    total_call_sites: int = Field(..., description="Pending and migrated call sites")
+    migrated_call_sites: int
+    removed_call_sites: int = Field(..., description="Checks deleted without a PDP call in their place")
+    migrated_percent: float
+    captured_at: datetime
+
+
+class MigrationOverviewEntry(BaseModel):
+    """A repository's latest migration progress."""
+
+    repository_id: int
+    repository_name: str | None = None
+    total_call_sites: int
+    migrated_call_sites: int
+    removed_call_sites: int
+    migrated_percent: float
+    captured_at: datetime
+
+
+class MigrationTrendPoint(BaseModel):
+    """Migration progress in one time bucket."""
+
+    bucket: str = Field(..., description="Bucket start date (ISO)")
+    repositories: int
+    total_call_sites: int
+    migrated_call_sites: int
+    removed_call_sites: int
+    migrated_percent: float
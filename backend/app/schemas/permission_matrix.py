@@ -0,0 +1,34 @@
+"""Schemas for the role-permission matrix."""
+from pydantic import BaseModel, Field
+
+
+class MatrixColumn(BaseModel):
+    """A resource/action column."""
+
+    resource: str
+    action: str
+
+
+class MatrixCellResponse(BaseModel):
+    """A role/resource/action cell with the policies that grant it."""
+
+    role: str
+    resource: str
+    action: str
+    access: str = Field(..., description="allow, or conditional when every grant has conditions")
+    conditions: list[str]
+    policy_ids: list[int]
+
+
+class PermissionMatrix(BaseModel):
+    """Sparse roles x actions x resources matrix, paginated by role."""
+
+    repository_id: int | None
+    application_id: int | None
+    total_roles: int
+    skip: int
+    limit: int
+    roles: list[str]
+    resources: list[str]
+    columns: list[MatrixColumn]
+    cells: list[MatrixCellResponse]
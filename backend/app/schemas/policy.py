@@ -3,7 +3,7 @@ from datetime import datetime
 
 from pydantic import BaseModel, ConfigDict, Field
 
-from app.models.policy import PolicyStatus, RiskLevel, SourceType, ValidationStatus
+from app.models.policy import ExtractionMethod, PolicyStatus, RiskLevel, SourceType, ValidationStatus
 
 
 class EvidenceBase(BaseModel):
@@ -55,6 +55,7 @@ class PolicyCreate(PolicyBase):
     complexity_score: float | None = None
     impact_score: float | None = None
     confidence_score: float | None = None
+    extraction_method: ExtractionMethod | None = None
     historical_score: float | None = None
     evidence: list[EvidenceCreate] = Field(default_factory=list)
 
@@ -70,7 +71,10 @@ class Policy(PolicyBase):
     risk_level: RiskLevel | None = None
     complexity_score: float | None = None
     impact_score: float | None = None
-    confidence_score: float | None = None
+    confidence_score: float | None = Field(None, description="Confidence (0-100) the rule is real, capped by its extraction method")
+    extraction_method: ExtractionMethod | None = Field(
+        None, description="declared_config, explicit_middleware, inferred_from_body, or llm_assisted"
+    )
     historical_score: float | None = None
     approval_comment: str | None = None
     reviewed_by: str | None = None
@@ -0,0 +1,38 @@
+"""Schemas for inline policy annotations."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class PolicyAnnotationScanResult(BaseModel):
+    """Summary of a repository's inline policy annotations."""
+
+    repository_id: int
+    annotations: int = Field(..., description='"policy:" comments found')
+    valid: int = Field(0, description="Annotations consistent with the code below them")
+    stale: int = Field(0, description="Annotations whose code checks something else; still mined, but flagged")
+    invalid: int = Field(0, description="Malformed annotations, which are not mined")
+    policies_created: int = Field(0, description="New policies created from annotations")
+    policies_merged: int = Field(0, description="Existing policies an annotation corroborated")
+    policies_removed: int = Field(0, description="Policies from an earlier annotation scan that were replaced")
+
+
+class PolicyAnnotationResponse(BaseModel):
+    """An inline policy annotation as of the repository's last scan."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    file_path: str
+    line: int
+    text: str
+    roles: list[str]
+    permissions: list[str]
+    resource: str | None = None
+    action: str | None = None
+    conditions: str | None = None
+    target_line: int | None = Field(None, description="First line of the annotated code")
+    status: str = Field(..., description="valid, stale, or invalid")
+    problems: list[str] = Field(default_factory=list, description="Why the annotation is stale or invalid")
+    created_at: datetime
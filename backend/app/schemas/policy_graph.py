@@ -0,0 +1,34 @@
+"""Schemas for the policy graph."""
+from pydantic import BaseModel, Field
+
+
+class PolicyGraphNode(BaseModel):
+    """A graph node with a stable ID."""
+
+    id: str = Field(..., description="Stable ID derived from content (e.g., subject:manager)")
+    type: str = Field(..., description="subject, permission, resource, or condition")
+    label: str
+    weight: int = Field(..., description="Number of matrix cells referencing the node")
+
+
+class PolicyGraphEdge(BaseModel):
+    """A directed edge."""
+
+    id: str
+    source: str
+    target: str
+    type: str = Field(..., description="can_access, granted, on, or when")
+    weight: int
+    conditional: bool
+    policy_ids: list[int] = Field(default_factory=list, description="Populated at full detail only")
+
+
+class PolicyGraph(BaseModel):
+    """Nodes and edges for rendering."""
+
+    detail: str
+    node_count: int
+    edge_count: int
+    truncated: bool = Field(..., description="True when max_edges was reached")
+    nodes: list[PolicyGraphNode]
+    edges: list[PolicyGraphEdge]
@@ -0,0 +1,51 @@
+"""Schemas for policy-as-code scaffold pull requests."""
+from datetime import datetime
+from typing import Literal
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class PolicyScaffoldRequest(BaseModel):
+    """Scaffold a repository's approved policies."""
+
+    repository_id: int
+    policy_format: Literal["rego", "cedar"] = Field("rego", description="Rego for OPA, or Cedar")
+    language: Literal["javascript", "python", "go", "java"] | None = Field(
+        None, description="Integration stub language; detected from the repository's clone if omitted"
+    )
+    application_id: int | None = Field(None, description="Scaffold only this application's policies")
+
+
+class PolicyScaffoldPreview(BaseModel):
+    """Generated scaffold files, without a pull request."""
+
+    repository_id: int
+    policy_format: str
+    language: str
+    policy_ids: list[int]
+    title: str
+    body: str = Field(..., description="Pull request description, listing the inline checks to migrate")
+    files: dict[str, str] = Field(..., description="Path to content of every file the pull request adds")
+
+
+class ScaffoldPullRequestResponse(BaseModel):
+    """An opened (or failed) scaffold pull request."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    application_id: int | None
+    policy_format: str
+    language: str
+    branch: str
+    base_branch: str | None
+    title: str
+    files: list[str]
+    policy_ids: list[int]
+    status: str = Field(..., description="pending, opened, or failed")
+    url: str | None = Field(None, description="Web URL of the pull or merge request")
+    number: int | None = Field(None, description="Pull request number, or merge request IID")
+    requested_by: str | None
+    error_message: str | None
+    created_at: datetime | None
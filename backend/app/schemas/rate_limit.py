@@ -0,0 +1,48 @@
+"""Schemas for rate limits and quotas."""
+from pydantic import BaseModel, Field
+
+
+class RateLimitResponse(BaseModel):
+    """A rate limit, quota, or exemption found in a repository."""
+
+    framework: str
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    limit: int | None = Field(None, description="Requests allowed per period; null for exemptions")
+    period_seconds: int | None = None
+    key: str = Field(..., description="What requests are counted by: ip, api_key, user, consumer, tenant, or global")
+    name: str | None = Field(None, description="Limiter, policy, zone, or throttle name")
+    subject: str | None = Field(None, description="Role or consumer the limit applies to; null for all callers")
+    scope: str | None = Field(None, description="Path pattern it covers; null when service-wide")
+    method: str | None = None
+    exempt: bool = False
+    rate: str = Field(..., description='e.g., "100 requests per 15 minutes per client IP"')
+
+
+class EndpointRateLimits(BaseModel):
+    """Rate limits covering one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    roles: list[str] = Field(default_factory=list)
+    requires_authentication: bool
+    rate_limited: bool
+    exempt: bool = Field(..., description="Explicitly exempted from rate limiting")
+    unlimited: bool = Field(..., description="Authenticated endpoint no limit covers")
+    rates: dict[str, list[str]] = Field(default_factory=dict, description='Rates by role or consumer; "*" for all callers')
+    limits: list[RateLimitResponse] = Field(default_factory=list)
+
+
+class ServiceRateLimits(BaseModel):
+    """Rate limits of one service (repository)."""
+
+    repository_id: int
+    repository_name: str
+    cloned: bool = Field(..., description="Whether a clone was available to read limits from")
+    limits: list[RateLimitResponse] = Field(default_factory=list)
+    unlimited_endpoints: int
+    endpoints: list[EndpointRateLimits] = Field(default_factory=list)
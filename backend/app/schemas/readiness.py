@@ -0,0 +1,115 @@
+"""Schemas for repository readiness assessments."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class ReadinessLanguage(BaseModel):
+    """Source files of one language."""
+
+    language: str
+    files: int
+    supported: bool = Field(..., description="Whether the scanner mines this language")
+
+
+class ReadinessFramework(BaseModel):
+    """A web framework detected from imports."""
+
+    name: str
+    language: str
+    support: str = Field(..., description="dedicated, routes, or scanner")
+    files: int
+
+
+class ReadinessRoutes(BaseModel):
+    """Route registrations and how many resolve to a literal endpoint."""
+
+    registrations: int
+    resolvable: int
+    dynamic: int
+    resolvable_percent: int | None = Field(None, description="None when no registrations were found")
+
+
+class ReadinessPattern(BaseModel):
+    """A pattern that needs a custom rule or a code change to mine reliably."""
+
+    kind: str = Field(..., description="dynamic_route, reflection, custom_auth_helper, or external_policy_engine")
+    file_path: str
+    line: int
+    snippet: str
+    detail: str
+    call_sites: int | None = Field(None, description="References to a custom auth helper outside its definition")
+
+
+class ReadinessPatterns(BaseModel):
+    """Dynamic patterns found, counted in full and listed up to a cap per kind."""
+
+    counts: dict[str, int] = Field(default_factory=dict)
+    items: list[ReadinessPattern] = Field(default_factory=list)
+
+
+class ReadinessRecommendation(BaseModel):
+    """Something to fix for higher-fidelity results."""
+
+    priority: str = Field(..., description="high, medium, or low")
+    area: str
+    message: str
+
+
+class ReadinessReport(BaseModel):
+    """Full assessment of how analyzable a repository is."""
+
+    score: int = Field(..., description="0-100")
+    grade: str
+    ready: bool
+    components: dict[str, int | None] = Field(..., description="Score component percentages; None if not applicable")
+    source_files: int
+    languages: list[ReadinessLanguage]
+    frameworks: list[ReadinessFramework]
+    routes: ReadinessRoutes
+    dynamic_patterns: ReadinessPatterns
+    recommendations: list[ReadinessRecommendation]
+
+
+class ReadinessAssessmentResponse(BaseModel):
+    """A recorded readiness assessment."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    scan_id: int | None = None
+    score: int
+    grade: str
+    ready: bool
+    route_resolution_percent: int | None = None
+    report: ReadinessReport
+    assessed_by: str | None = None
+    created_at: datetime
+
+
+class ReadinessAssessmentSummary(BaseModel):
+    """A recorded assessment without its report."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    scan_id: int | None = None
+    score: int
+    grade: str
+    ready: bool
+    route_resolution_percent: int | None = None
+    created_at: datetime
+
+
+class RepositoryReadiness(BaseModel):
+    """Latest readiness of a repository in the workspace."""
+
+    repository_id: int
+    repository_name: str
+    assessment_id: int | None = None
+    score: int | None = None
+    grade: str | None = None
+    ready: bool | None = None
+    assessed_at: datetime | None = None
@@ -0,0 +1,44 @@
+"""Schemas for exporting mined policies as Rego packages."""
+from typing import Any
+
+from pydantic import BaseModel, Field
+
+
+class UntranslatedClause(BaseModel):
+    """A condition clause left out of the Rego, which keeps its rule body from matching."""
+
+    policy_id: int
+    endpoint: str = Field(..., description="Method and path of the rule, e.g. GET /api/expenses/{}")
+    clause: str
+
+
+class RegoPackage(BaseModel):
+    """Rego package and input schema of one service."""
+
+    service: str
+    package: str = Field(..., description="e.g. policy_miner.services.expenses")
+    path: str = Field(..., description="Path of the module in the export archive")
+    schema_path: str = Field(..., description="Path of the input schema in the export archive")
+    rego: str
+    input_schema: dict[str, Any] = Field(..., description="JSON Schema of the package's input")
+    endpoints: int
+    policy_ids: list[int]
+    untranslated: list[UntranslatedClause] = []
+
+
+class RegoExportSummary(BaseModel):
+    """What an export contains and what it left out."""
+
+    packages: int
+    endpoints: int
+    policies: int
+    below_min_confidence: int = Field(..., description="Policies left out for scoring below min_confidence")
+    untranslated_clauses: int
+
+
+class RegoExportResponse(BaseModel):
+    """Rego packages of a repository's mined policies."""
+
+    repository_id: int
+    packages: list[RegoPackage]
+    summary: RegoExportSummary
@@ -0,0 +1,53 @@
+"""Schemas for sensitive response data exposure."""
+from pydantic import BaseModel, Field
+
+
+class ResponseExposureFinding(BaseModel):
+    """Sensitive fields an endpoint returns to roles the data visibility policy does not allow."""
+
+    repository_id: int
+    repository_name: str
+    endpoint: str = Field(..., description="Route, e.g. GET /api/employees")
+    file_path: str
+    function: str | None = Field(None, description="Handler name; None for anonymous handlers")
+    line_start: int
+    line_end: int
+    model: str | None = Field(None, description="Model or serializer the response is built from; None for object literals")
+    fields: dict[str, str] = Field(default_factory=dict, description="Sensitive field -> sensitivity class")
+    caller_roles: list[str] = Field(default_factory=list, description="Roles the endpoint's policies admit; empty when any caller is")
+    unauthorized_roles: list[str] = Field(default_factory=list, description="Admitted roles the visibility policy excludes")
+    anonymous: bool = Field(..., description="Whether the endpoint is reachable without authentication")
+    severity: str = Field(..., description="critical for anonymous endpoints, otherwise the most severe class returned")
+    description: str
+    policy_ids: list[int] = Field(default_factory=list)
+
+
+class ResponseExposureSummary(BaseModel):
+    """Counts across the reported findings."""
+
+    total: int
+    repositories_scanned: int = Field(..., description="Repositories with a clone to scan")
+    repositories_with_findings: int
+    by_severity: dict[str, int] = Field(default_factory=dict)
+    by_class: dict[str, int] = Field(default_factory=dict, description="Findings returning each sensitivity class")
+
+
+class ResponseExposureReport(BaseModel):
+    """Response data exposure findings across repositories."""
+
+    findings: list[ResponseExposureFinding] = Field(default_factory=list)
+    summary: ResponseExposureSummary
+
+
+class SensitivityClass(BaseModel):
+    """A class of sensitive fields in the data visibility policy."""
+
+    patterns: list[str] = Field(default_factory=list, description="Regexes matched against snake_case field names")
+    roles: list[str] = Field(default_factory=list, description="Roles that may receive fields of the class")
+    severity: str = "medium"
+
+
+class VisibilityPolicy(BaseModel):
+    """Which roles may receive each class of sensitive field."""
+
+    classes: dict[str, SensitivityClass] = Field(default_factory=dict)
@@ -0,0 +1,75 @@
+"""Schemas for role-change impact analysis."""
+from typing import Literal
+
+from pydantic import BaseModel, Field
+
+
+class RoleAssignmentInput(BaseModel):
+    """One user-to-role assignment."""
+
+    user_identifier: str = Field(..., description="User email or ID")
+    role: str
+    application_id: int | None = Field(None, description="Scope to one application; global if omitted")
+
+
+class RoleAssignmentIngestRequest(BaseModel):
+    """Bulk upload of role assignments."""
+
+    assignments: list[RoleAssignmentInput]
+    source: str | None = Field(None, description="Where the data came from (e.g., okta, csv)")
+    replace: bool = Field(False, description="Replace earlier assignments from the same source")
+
+
+class RoleAssignmentIngestResponse(BaseModel):
+    """Result of a role assignment upload."""
+
+    ingested: int
+
+
+class RoleChangeRequest(BaseModel):
+    """A hypothetical role change to analyze."""
+
+    operation: Literal["remove", "merge"]
+    role: str = Field(..., description="Role to remove or merge away (e.g., MANAGER)")
+    target_role: str | None = Field(None, description="Role to merge into (e.g., LEAD)")
+    repository_id: int | None = None
+    application_id: int | None = None
+
+
+class EndpointImpactResult(BaseModel):
+    """Impact of the change on one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    impact: str = Field(..., description="orphaned, narrowed, widened, consolidated, or condition_reference")
+    roles_before: list[str]
+    roles_after: list[str]
+    conditions_referencing_role: list[str]
+    users_losing_access: list[str]
+    users_gaining_access: list[str]
+
+
+class UserImpactResult(BaseModel):
+    """Impact of the change on one user."""
+
+    user_identifier: str
+    roles_before: list[str]
+    roles_after: list[str]
+    endpoints_lost: list[str]
+    endpoints_gained: list[str]
+
+
+class RoleChangeImpactReport(BaseModel):
+    """What-if report for a role change."""
+
+    operation: str
+    role: str
+    target_role: str | None
+    role_known: bool = Field(..., description="Whether any mined policy references the role")
+    has_assignment_data: bool = Field(..., description="Whether user impact could be computed")
+    endpoints_affected: int
+    users_affected: int
+    endpoints: list[EndpointImpactResult]
+    users: list[UserImpactResult]
@@ -0,0 +1,83 @@
+"""Schemas for role parameters and their bindings."""
+from datetime import datetime
+from typing import Any
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class RoleParameterSummary(BaseModel):
+    """A role parameter in use and the values it is bound to."""
+
+    name: str = Field(..., description='Parameter as it appears in policies without "${}", e.g. "cfg.ApproverRole"')
+    policy_ids: list[int]
+    repository_ids: list[int]
+    values: list[str] | None = Field(None, description="Workspace-wide values; None when unbound")
+    overrides: dict[int, list[str]] = Field(default_factory=dict, description="Values bound per repository")
+    bound: bool = Field(..., description="Whether every repository using the parameter has values")
+
+
+class RoleParameterBindRequest(BaseModel):
+    """Values for role parameters, workspace-wide or for one repository."""
+
+    repository_id: int | None = Field(None, description="Bind for this repository only; omit to bind workspace-wide")
+    bindings: dict[str, list[str] | str] = Field(
+        ..., description='Parameter name to a role or list of roles, e.g. {"cfg.ApproverRole": ["finance-approver"]}'
+    )
+
+
+class RoleParameterBindingResponse(BaseModel):
+    """A role parameter bound to values."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int | None = None
+    name: str
+    values: list[str]
+    source: str = Field("manual", description="manual, or scan_snapshot when read from a scan's config snapshot")
+    scan_id: int | None = Field(None, description="Scan whose snapshot supplied the values")
+    bound_by: str | None = None
+    created_at: datetime
+    updated_at: datetime | None = None
+
+
+class ConfigSnapshot(BaseModel):
+    """Configuration and environment of a deployment, resolving the settings mined rules reference."""
+
+    env: dict[str, Any] = Field(default_factory=dict, description='Environment variables, e.g. {"MAX_REFUND": "500"}')
+    config: dict[str, Any] = Field(
+        default_factory=dict, description='Config document, e.g. {"limits": {"max_self_approve_amount": 5000}}'
+    )
+
+
+class ConfigSnapshotApplied(BaseModel):
+    """Parameters a snapshot resolved for a repository."""
+
+    repository_id: int
+    scan_id: int | None = None
+    resolved: dict[str, list[str]] = Field(default_factory=dict, description="Parameter name to the values read from the snapshot")
+    unresolved: list[str] = Field(default_factory=list, description="Parameters in use the snapshot has no value for")
+    policies_updated: int
+
+
+class ParameterProvenance(BaseModel):
+    """The binding a policy's parameter was rendered with."""
+
+    name: str
+    values: list[str]
+    source: str
+    scope: str = Field(..., description="workspace or repository")
+    binding_id: int | None = None
+    scan_id: int | None = None
+    bound_by: str | None = None
+
+
+class PolicyParameterProvenance(BaseModel):
+    """Where the values rendered into a policy came from."""
+
+    policy_id: int
+    repository_id: int
+    subject_template: str
+    conditions_template: str | None = None
+    bindings: list[ParameterProvenance] = Field(default_factory=list)
+    unbound: list[str] = Field(default_factory=list)
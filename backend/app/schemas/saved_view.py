@@ -0,0 +1,39 @@
+"""Schemas for saved views."""
+from datetime import datetime
+from typing import Any
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class SavedViewCreate(BaseModel):
+    """Request to save a filter combination."""
+
+    name: str = Field(..., description="View name (e.g., payments-team critical findings)")
+    view_type: str = Field(..., description="Page the filters apply to (e.g., policies, conflicts)")
+    filters: dict[str, Any] = Field(..., description="Filter values keyed by query parameter name")
+    description: str | None = Field(None, description="What the view is for")
+
+
+class SavedViewUpdate(BaseModel):
+    """Request to rename a view or replace its filters."""
+
+    name: str | None = None
+    filters: dict[str, Any] | None = None
+    description: str | None = None
+
+
+class SavedViewResponse(BaseModel):
+    """A saved view."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    description: str | None = None
+    view_type: str
+    filters: dict[str, Any]
+    share_token: str
+    share_path: str = Field(..., description="API path that resolves the share link")
+    created_by: str | None = None
+    created_at: datetime
+    updated_at: datetime | None = None
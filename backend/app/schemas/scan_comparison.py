@@ -0,0 +1,69 @@
+"""Schemas for the scan comparison (diff) view."""
+from datetime import datetime
+
+from pydantic import BaseModel, Field
+
+
+class ComparisonEvidence(BaseModel):
+    """Evidence supporting one side of a compared rule."""
+
+    id: int
+    file_path: str
+    line_start: int
+    line_end: int
+    code_snippet: str
+
+
+class ComparisonRuleSide(BaseModel):
+    """A rule as it stood before or after the scan."""
+
+    policy_id: int | None = None
+    subject: str | None = None
+    resource: str | None = None
+    action: str | None = None
+    conditions: str | None = None
+    evidence: list[ComparisonEvidence] = Field(default_factory=list)
+
+
+class ConditionDiffSegment(BaseModel):
+    """One segment of an inline condition diff."""
+
+    op: str = Field(..., description="equal, insert, or delete")
+    text: str
+
+
+class RuleComparisonItem(BaseModel):
+    """An added, removed, or modified rule."""
+
+    group: str = Field(..., description="added, removed, or modified")
+    change_ids: list[int] = Field(..., description="Policy change records behind this rule")
+    resource: str | None = None
+    action: str | None = None
+    before: ComparisonRuleSide | None = Field(None, description="Rule before the scan (null if added)")
+    after: ComparisonRuleSide | None = Field(None, description="Rule after the scan (null if removed)")
+    changed_fields: list[str] = Field(default_factory=list, description="Fields that differ (modified rules)")
+    condition_diff: list[ConditionDiffSegment] = Field(
+        default_factory=list, description="Word-level before/after rendering of the conditions"
+    )
+
+
+class ComparisonSummary(BaseModel):
+    """Rule counts per group."""
+
+    added: int
+    removed: int
+    modified: int
+
+
+class ScanComparison(BaseModel):
+    """Diff between a scan and the previous one."""
+
+    repository_id: int
+    scan_id: int
+    previous_scan_id: int | None = None
+    scan_completed_at: datetime | None = None
+    previous_scan_completed_at: datetime | None = None
+    summary: ComparisonSummary
+    added: list[RuleComparisonItem]
+    removed: list[RuleComparisonItem]
+    modified: list[RuleComparisonItem]
@@ -0,0 +1,87 @@
+"""Schemas for scan environment tags and cross-environment comparison."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class ScanEnvironmentTag(BaseModel):
+    """Request to tag a scan with the environment it represents."""
+
+    environment: str = Field(..., description='e.g. "dev", "staging", "prod"; aliases like "production" are normalized')
+    ref: str | None = Field(None, description="Branch or deployment manifest the environment comes from")
+
+
+class EnvironmentEndpoint(BaseModel):
+    """An endpoint as observed in one environment."""
+
+    endpoint_id: str
+    endpoint: str
+    roles: list[str] = Field(default_factory=list)
+    requires_authentication: bool
+    conditions: list[str] = Field(default_factory=list)
+    mechanism: str
+    policy_ids: list[int] = Field(default_factory=list)
+
+
+class EnvironmentGatedCheck(BaseModel):
+    """An auth check switched on or off by an environment check in code."""
+
+    file_path: str
+    line_start: int
+    condition: str
+    kind: str = Field(..., description="disabled, or conditional when the check is only installed under the condition")
+    relaxed_in: list[str] = Field(..., description="Environments that run without the check")
+    snippet: str
+
+
+class ScanEnvironmentSummary(BaseModel):
+    """Environment tag of a scan."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    scan_id: int
+    environment: str
+    source: str = Field(..., description="branch, manifest, or manual")
+    ref: str | None = None
+    tagged_by: str | None = None
+    created_at: datetime
+
+
+class ScanEnvironmentResponse(ScanEnvironmentSummary):
+    """Environment tag of a scan with its snapshot."""
+
+    endpoints: list[EnvironmentEndpoint] = Field(default_factory=list)
+    gated_checks: list[EnvironmentGatedCheck] = Field(default_factory=list)
+
+
+class EnvironmentRelaxation(BaseModel):
+    """Where the target environment is weaker than the base."""
+
+    kind: str = Field(
+        ...,
+        description="authentication_removed, roles_removed, roles_broadened, conditions_removed, "
+        "unprotected_endpoint_added, or environment_gated",
+    )
+    severity: str
+    endpoint: str | None = None
+    message: str
+    prod_only: bool = Field(..., description="Whether the relaxation exists in prod but not the base")
+    roles_added: list[str] = Field(default_factory=list)
+    conditions_removed: list[str] = Field(default_factory=list)
+    file_path: str | None = None
+    line_start: int | None = None
+    condition: str | None = None
+
+
+class EnvironmentComparison(BaseModel):
+    """Comparison of two environments' latest tagged scans."""
+
+    repository_id: int
+    base: ScanEnvironmentSummary
+    target: ScanEnvironmentSummary
+    only_in_base: list[str] = Field(default_factory=list, description="Endpoints missing from the target")
+    only_in_target: list[str] = Field(default_factory=list, description="Endpoints missing from the base")
+    relaxation_counts: dict[str, int] = Field(default_factory=dict, description="Relaxations per severity")
+    relaxations: list[EnvironmentRelaxation] = Field(default_factory=list, description="Most severe first")
@@ -0,0 +1,67 @@
+"""Schemas for the cross-service dependency graph."""
+from pydantic import BaseModel, Field
+
+
+class ServiceNode(BaseModel):
+    """A service in the graph."""
+
+    name: str
+    repository_id: int | None = None
+    mined: bool = Field(..., description="Whether the service is a repository of the tenant")
+
+
+class ServiceEdge(BaseModel):
+    """Calls from one service to another, aggregated."""
+
+    source: str
+    target: str
+    source_repository_id: int
+    target_repository_id: int | None = None
+    protocols: list[str] = Field(default_factory=list, description="http and/or grpc")
+    credentials: list[str] = Field(default_factory=list, description="user_token, service_account, or none")
+    calls: int
+    dropped: int = Field(..., description="Calls that drop the user context before an authorization decision")
+
+
+class ServiceHop(BaseModel):
+    """One outbound call and what it does with the end user's identity."""
+
+    protocol: str
+    client: str = Field(..., description="Library or API making the call")
+    method: str = Field(..., description='HTTP method, or "*" for a gRPC stub')
+    path: str = Field(..., description="Request path, or the gRPC service name")
+    file_path: str
+    line_start: int
+    line_end: int
+    credential: str = Field(..., description="user_token, service_account, or none")
+    credential_evidence: str | None = Field(None, description="Code the credential was recognized by")
+    user_input: str | None = Field(None, description="Request input the call's URL or payload carries")
+    input_check: str | None = Field(None, description="Authorization or ownership check made before the call")
+    source_repository_id: int
+    source: str
+    target_repository_id: int | None = None
+    target: str
+    target_endpoint: str | None = Field(None, description="Callee endpoint rule the call hits")
+    target_roles: list[str] = Field(default_factory=list)
+    target_conditions: list[str] = Field(default_factory=list)
+    target_policy_ids: list[int] = Field(default_factory=list)
+    user_context: str = Field(..., description="propagated, dropped, not_required, or unknown")
+
+
+class ServiceGraphSummary(BaseModel):
+    """Totals over the graph."""
+
+    services: int
+    calls: int
+    by_credential: dict[str, int] = Field(default_factory=dict)
+    by_user_context: dict[str, int] = Field(default_factory=dict)
+    dropped: int
+
+
+class ServiceGraph(BaseModel):
+    """Services, the calls between them, and the credentials each hop carries."""
+
+    nodes: list[ServiceNode]
+    edges: list[ServiceEdge]
+    hops: list[ServiceHop]
+    summary: ServiceGraphSummary
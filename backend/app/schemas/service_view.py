@@ -0,0 +1,77 @@
+"""Schemas for layered views of multi-language monorepo services."""
+from pydantic import BaseModel, Field
+
+
+class ServiceComponent(BaseModel):
+    """A build of one language inside the repository."""
+
+    path: str = Field(..., description="Directory relative to the repository root; empty for the root")
+    language: str
+    service: str
+    layer: str = Field(..., description="edge, app, or data")
+    pinned: bool = Field(False, description="Placed by the service layout file rather than inferred")
+    policies: int = Field(0, description="Policies with evidence in the component")
+
+
+class LayerPolicy(BaseModel):
+    """A mined policy as listed in a layer."""
+
+    policy_id: int
+    subject: str
+    resource: str
+    action: str
+    conditions: str | None = None
+    source_type: str | None = None
+    component: str | None = Field(None, description="Component the policy's evidence is in")
+    file_path: str | None = None
+    line_start: int | None = None
+
+
+class ServiceLayer(BaseModel):
+    """Policies of one layer of a service."""
+
+    policies: list[LayerPolicy] = Field(default_factory=list)
+    resources: list[str] = Field(default_factory=list, description="Endpoints (edge, app) or tables (data) the policies cover")
+    roles: list[str] = Field(default_factory=list)
+
+
+class EdgeLink(BaseModel):
+    """A call from an edge component to an app endpoint of the same service."""
+
+    component: str
+    file_path: str
+    line_start: int
+    call: str = Field(..., description="Method and path the edge calls")
+    app_endpoint: str
+    app_roles: list[str] = Field(default_factory=list)
+    app_policy_ids: list[int] = Field(default_factory=list)
+    credential: str
+    user_context: str = Field(..., description="Whether the end user's identity reaches the app endpoint")
+
+
+class ServiceView(BaseModel):
+    """One service across its languages, with its policies by layer."""
+
+    name: str
+    languages: list[str] = Field(default_factory=list)
+    components: list[ServiceComponent] = Field(default_factory=list)
+    layers: dict[str, ServiceLayer] = Field(default_factory=dict, description="edge, app, and data")
+    links: list[EdgeLink] = Field(default_factory=list)
+
+
+class ServiceViewSummary(BaseModel):
+    """Counts across the returned services."""
+
+    services: int
+    multi_language_services: int
+    policies: int
+    by_layer: dict[str, int] = Field(default_factory=dict)
+
+
+class ServiceViewReport(BaseModel):
+    """Services of a repository with their layered policies."""
+
+    repository_id: int
+    repository_name: str
+    services: list[ServiceView] = Field(default_factory=list)
+    summary: ServiceViewSummary
@@ -0,0 +1,54 @@
+"""Schemas for SIEM connectors streaming findings and audit events."""
+from datetime import datetime
+from typing import Literal
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class SiemConnectorCreate(BaseModel):
+    """Register a Splunk, Elastic, or Sentinel destination for miner events."""
+
+    name: str
+    destination: Literal["splunk", "elastic", "sentinel"]
+    event_format: Literal["ecs", "cef"] = Field("ecs", description="Elastic Common Schema JSON or CEF lines")
+    base_url: str | None = Field(
+        None, description="Splunk HEC or Elasticsearch URL; for Sentinel, overrides the workspace ingestion endpoint"
+    )
+    workspace_id: str | None = Field(None, description="Sentinel Log Analytics workspace ID")
+    secret: str = Field(..., description="HEC token, Elasticsearch API key, or Log Analytics shared key")
+    index: str | None = Field(
+        None, description="Splunk index, Elastic index or data stream, or Sentinel custom log type"
+    )
+    repository_id: int | None = Field(None, description="Stream only this repository's events")
+    sync_interval_minutes: int | None = Field(None, ge=1, description="Defaults to SIEM_STREAM_INTERVAL_MINUTES")
+
+
+class SiemConnectorResponse(BaseModel):
+    """A registered connector (the secret is never returned)."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    name: str
+    destination: str
+    event_format: str
+    base_url: str | None
+    workspace_id: str | None
+    index: str | None
+    repository_id: int | None
+    audit_log_cursor: int = Field(..., description="Last audit log streamed")
+    regression_cursor: int = Field(..., description="Last auth regression streamed")
+    sync_interval_minutes: int | None
+    last_streamed_at: datetime | None
+    last_error: str | None
+
+
+class SiemStreamResult(BaseModel):
+    """Result of streaming one connector."""
+
+    connector_id: int
+    findings_detected: int = Field(..., description="Findings streamed as newly detected")
+    findings_resolved: int = Field(..., description="Findings streamed as no longer detected")
+    regressions: int = Field(..., description="Auth regressions streamed")
+    audit_events: int = Field(..., description="Miner audit events streamed")
+    events_sent: int
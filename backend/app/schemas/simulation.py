@@ -0,0 +1,67 @@
+"""Schemas for decision simulation."""
+from typing import Any
+
+from pydantic import BaseModel, Field
+
+
+class SimulationSubject(BaseModel):
+    """The caller being simulated."""
+
+    id: str | None = Field(None, description="Subject identifier, used for ownership conditions")
+    roles: list[str] = Field(default_factory=list)
+    attributes: dict[str, Any] = Field(
+        default_factory=dict, description="Subject attributes (e.g., department)"
+    )
+    authenticated: bool = True
+
+
+class SimulationResource(BaseModel):
+    """The resource being accessed."""
+
+    name: str = Field(..., description="Resource name or request path (e.g., /api/expenses/42)")
+    attributes: dict[str, Any] = Field(
+        default_factory=dict, description="Resource attributes (e.g., amount, owner_id)"
+    )
+
+
+class SimulationRequestSchema(BaseModel):
+    """Request to simulate an authorization decision."""
+
+    subject: SimulationSubject
+    action: str = Field(..., description="Action name or HTTP method")
+    resource: SimulationResource
+    context: dict[str, Any] = Field(default_factory=dict, description="Environment attributes")
+    repository_id: int | None = None
+    application_id: int | None = None
+    include_pending: bool = Field(True, description="Include policies not yet approved")
+
+
+class ConditionClauseResult(BaseModel):
+    """Outcome of one condition clause."""
+
+    clause: str
+    kind: str
+    satisfied: bool | None = Field(None, description="None when the clause could not be evaluated")
+
+
+class PolicyEvaluation(BaseModel):
+    """Evaluation of one applicable mined policy."""
+
+    policy_id: int
+    subject: str | None
+    resource: str | None
+    action: str | None
+    conditions: str | None
+    outcome: str = Field(..., description="fired, role_mismatch, condition_failed, or indeterminate")
+    reason: str
+    condition_clauses: list[ConditionClauseResult] = []
+
+
+class SimulationResponse(BaseModel):
+    """Simulated decision with the rules that fired."""
+
+    decision: str = Field(..., description="allow or deny")
+    reason: str
+    policies_evaluated: int
+    fired_policy_ids: list[int]
+    evaluations: list[PolicyEvaluation]
@@ -0,0 +1,40 @@
+"""Schemas for exporting mined policies as a SpiceDB schema and relationships."""
+from pydantic import BaseModel, Field
+
+from app.schemas.rego_export import UntranslatedClause
+
+
+class SpiceDbPermission(BaseModel):
+    """Permission of a domain object, granting what its mined policies grant."""
+
+    resource_type: str = Field(..., description="Object definition, without the prefix")
+    permission: str
+    expression: str = Field(..., description="e.g. platform->manager + owner; nil when no policy translated")
+    policy_ids: list[int] = Field(..., description="Policies the permission unions")
+
+
+class SpiceDbExportSummary(BaseModel):
+    """What an export contains and what it left out."""
+
+    policies: int
+    resource_types: int
+    permissions: int
+    roles: int
+    relationships: int
+    roles_without_members: list[str] = Field(..., description="Mined roles no ingested role assignment grants")
+    below_min_confidence: int = Field(..., description="Policies left out for scoring below min_confidence")
+    untranslated_clauses: int
+
+
+class SpiceDbExportResponse(BaseModel):
+    """SpiceDB schema and bootstrap relationships of a repository's mined policies."""
+
+    repository_id: int
+    prefix: str = Field(..., description="Prefix the object definitions are declared under")
+    zed_schema: str = Field(..., description="Schema, as WriteSchema and zed schema write take it")
+    permissions: list[SpiceDbPermission]
+    relationships: list[str] = Field(
+        ..., description="Role and everyone relationships, e.g. expense_api/platform:main#manager@expense_api/user:ana"
+    )
+    untranslated: list[UntranslatedClause] = []
+    summary: SpiceDbExportSummary
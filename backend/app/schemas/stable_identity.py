@@ -0,0 +1,36 @@
+"""Schemas for stable rule and endpoint identifiers."""
+from datetime import datetime
+
+from pydantic import BaseModel, Field
+
+
+class LineageChange(BaseModel):
+    """A recorded change to one of a rule's policies."""
+
+    change_id: int
+    change_type: str
+    policy_id: int | None = None
+    detected_at: datetime | None = None
+
+
+class RuleLineage(BaseModel):
+    """A mined rule tracked across scans by its stable identifier."""
+
+    rule_id: str = Field(..., description="Derived from roles, action, resource, conditions, and endpoint")
+    endpoint_id: str = Field(..., description="Derived from method and parameter-normalized path")
+    endpoint: str
+    current_policy_id: int = Field(..., description="Policy mined by the latest scan")
+    policy_ids: list[int] = Field(..., description="Policies of every scan that mined the rule, oldest first")
+    status: str
+    reviewed_by: str | None = None
+    application_id: int | None = None
+    locations: list[str] = Field(default_factory=list, description="Files the rule's code has lived in")
+    moved: bool = Field(..., description="The code moved between files across scans")
+    history: list[LineageChange] = Field(default_factory=list)
+
+
+class TriageCarryOver(BaseModel):
+    """Outcome of carrying triage over to the latest scan."""
+
+    repository_id: int
+    inherited: int = Field(..., description="Policies that took over a predecessor's review and owner")
@@ -0,0 +1,20 @@
+"""Schemas for per-repository policy status."""
+from datetime import datetime
+
+from pydantic import BaseModel, Field
+
+
+class RepositoryStatusResponse(BaseModel):
+    """Authentication coverage and open critical findings as of a repository's last scan."""
+
+    repository_id: int
+    repository_name: str
+    status: str = Field(..., description="passing, failing (open critical findings), or unknown (never scanned)")
+    badge_url: str = Field(..., description="Public SVG badge to embed in READMEs and catalogs, addressed by an unguessable token")
+    coverage_percent: float | None = Field(None, description="Share of endpoints requiring authentication")
+    authorized_percent: float | None = Field(None, description="Share of endpoints authorized beyond authentication")
+    total_endpoints: int | None = None
+    critical_findings: int | None = Field(None, description="Open findings of critical severity")
+    open_findings: dict[str, int] = Field(default_factory=dict, description="Open findings by type")
+    scan_id: int | None = Field(None, description="Scan the status was captured by")
+    scanned_at: datetime | None = None
@@ -0,0 +1,36 @@
+"""Schemas for step-up authentication requirements."""
+from pydantic import BaseModel, Field
+
+
+class StepUpAttributes(BaseModel):
+    """Step-up strength attributes."""
+
+    step_up: bool = Field(..., description="Whether any step-up or MFA requirement was found")
+    kinds: list[str] = Field(
+        default_factory=list, description="amr_claim, acr_claim, mfa, and/or reauthentication"
+    )
+    acr_values: list[str] = Field(default_factory=list, description="Accepted acr assurance levels")
+    amr_values: list[str] = Field(default_factory=list, description="Required amr authentication methods")
+    max_age_seconds: int | None = Field(None, description="Maximum age of the last authentication")
+
+
+class PolicyStepUp(StepUpAttributes):
+    """Step-up attributes of one policy."""
+
+    policy_id: int
+    subject: str
+    resource: str
+    action: str
+    risk_level: str | None = None
+
+
+class EndpointStepUp(StepUpAttributes):
+    """Step-up requirements of one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    risk_score: float
+    high_risk: bool
+    missing_step_up: bool = Field(..., description="High-risk authenticated endpoint with no step-up requirement")
@@ -0,0 +1,59 @@
+"""Schemas for tenant isolation constraints and gaps."""
+from pydantic import BaseModel, Field
+
+
+class TenantConstraint(BaseModel):
+    """A check in an endpoint's handler tying the objects it touches to the caller's tenant."""
+
+    repository_id: int
+    endpoint: str = Field(..., description="Route, e.g. GET /api/expenses/{id}")
+    file_path: str
+    function: str | None = Field(None, description="Handler name; None for anonymous handlers")
+    line: int
+    kind: str = Field(..., description="comparison, query_filter, assignment, or middleware")
+    code: str
+    resource_attribute: str = Field(..., description="Tenant column of the object, e.g. expense.tenant_id")
+    condition: str = Field(..., description="The check as a condition clause")
+    policy_ids: list[int] = Field(default_factory=list)
+
+
+class TenantGap(BaseModel):
+    """An endpoint touching tenant-owned models with no tenant isolation check."""
+
+    repository_id: int
+    repository_name: str
+    endpoint: str
+    file_path: str
+    function: str | None = None
+    line_start: int
+    line_end: int
+    resources: list[str] = Field(default_factory=list, description="Tenant-owned models the handler touches")
+    severity: str
+    description: str
+    policy_ids: list[int] = Field(default_factory=list)
+
+
+class TenantIsolationSummary(BaseModel):
+    """Counts across the report."""
+
+    constraints: int
+    protected_endpoints: int = Field(..., description="Endpoints with at least one tenant isolation check")
+    gaps: int
+    repositories_scanned: int = Field(..., description="Repositories with a clone to scan")
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class TenantIsolationReport(BaseModel):
+    """Tenant isolation constraints and gaps across repositories."""
+
+    constraints: list[TenantConstraint] = Field(default_factory=list)
+    gaps: list[TenantGap] = Field(default_factory=list)
+    summary: TenantIsolationSummary
+
+
+class TenantConstraintsApplied(BaseModel):
+    """Result of adding tenant isolation constraints to policy conditions."""
+
+    repository_id: int
+    constraints: int
+    policies_updated: int
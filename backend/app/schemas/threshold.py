@@ -0,0 +1,65 @@
+"""Schemas for condition threshold tracking."""
+from datetime import datetime
+
+from pydantic import BaseModel, ConfigDict, Field
+
+from app.models.threshold import ThresholdChangeStatus
+
+
+class ThresholdPoint(BaseModel):
+    """A threshold's boundary from the scan that first observed it."""
+
+    scan_id: int | None = None
+    policy_id: int | None = None
+    operator: str
+    value: float
+    observed_at: datetime
+
+
+class ThresholdHistory(BaseModel):
+    """How one condition threshold moved across scans."""
+
+    threshold_key: str
+    resource: str | None = None
+    action: str | None = None
+    attribute: str
+    required_role: str | None = Field(None, description='Role required past the boundary, for "... requires ROLE"')
+    operator: str
+    value: float
+    clause: str
+    history: list[ThresholdPoint] = Field(default_factory=list, description="One point per distinct boundary, oldest first")
+
+
+class ThresholdChangeResponse(BaseModel):
+    """A loosened threshold finding."""
+
+    model_config = ConfigDict(from_attributes=True)
+
+    id: int
+    repository_id: int
+    scan_id: int | None = None
+    threshold_key: str
+    previous_policy_id: int | None = None
+    policy_id: int | None = None
+    resource: str | None = None
+    action: str | None = None
+    attribute: str
+    required_role: str | None = None
+    before_operator: str
+    before_value: float
+    after_operator: str
+    after_value: float
+    description: str
+    severity: str
+    status: ThresholdChangeStatus
+    resolution_notes: str | None = None
+    resolved_by: str | None = None
+    resolved_at: datetime | None = None
+    detected_at: datetime
+
+
+class ThresholdChangeUpdate(BaseModel):
+    """Review decision for a threshold change."""
+
+    status: ThresholdChangeStatus
+    resolution_notes: str | None = None
@@ -0,0 +1,28 @@
+"""Schemas for historical trend metrics."""
+from pydantic import BaseModel, Field
+
+
+class CoverageTrendPoint(BaseModel):
+    """Coverage KPIs for one time bucket."""
+
+    bucket: str = Field(..., description="Bucket start date (ISO)")
+    repositories: int = Field(..., description="Repositories with a snapshot in the bucket")
+    total_policies: int
+    unknown_patterns: int
+    total_endpoints: int
+    authenticated_endpoints: int
+    authorized_endpoints: int
+    conditional_endpoints: int
+    authenticated_percent: float
+    authorized_percent: float
+    conditional_percent: float
+
+
+class FindingsWeek(BaseModel):
+    """Findings opened and closed in one week."""
+
+    week: str = Field(..., description="Week start date, Monday (ISO)")
+    opened: dict[str, int]
+    closed: dict[str, int]
+    total_opened: int
+    total_closed: int
@@ -0,0 +1,45 @@
+"""Schemas for known-vulnerable authentication pattern detection."""
+from pydantic import BaseModel, Field
+
+from app.services.vulnerable_auth_pattern_service import PatternKind
+
+
+class WeaknessResponse(BaseModel):
+    """A detected pattern kind and the weakness it maps to."""
+
+    kind: PatternKind
+    title: str
+    cwe: str = Field(..., description="CWE identifier, e.g. CWE-347")
+    cwe_name: str
+    severity: str = Field(..., description="critical, high, medium, or low")
+    remediation: str
+
+
+class PatternFindingResponse(WeaknessResponse):
+    """One occurrence of a known-vulnerable pattern."""
+
+    repository_id: int
+    repository_name: str
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    description: str = Field(..., description="What this occurrence does wrong")
+
+
+class PatternSummary(BaseModel):
+    """Counts across the reported findings."""
+
+    total: int
+    repositories_scanned: int = Field(..., description="Repositories with a clone to scan")
+    repositories_with_findings: int
+    by_kind: dict[str, int] = Field(default_factory=dict)
+    by_cwe: dict[str, int] = Field(default_factory=dict)
+    by_severity: dict[str, int] = Field(default_factory=dict)
+
+
+class VulnerableAuthPatternReport(BaseModel):
+    """Known-vulnerable authentication patterns across repositories."""
+
+    findings: list[PatternFindingResponse] = Field(default_factory=list)
+    summary: PatternSummary
@@ -0,0 +1,44 @@
+"""Schemas for exporting mined policies as an XACML PolicySet."""
+from pydantic import BaseModel, Field
+
+from app.schemas.rego_export import UntranslatedClause
+
+
+class XacmlPolicy(BaseModel):
+    """XACML Policy of one endpoint."""
+
+    policy_id: str = Field(..., description="PolicyId in the PolicySet")
+    endpoint: str = Field(..., description="Method and path the Policy targets, e.g. GET /api/expenses/{}")
+    rule_ids: list[str] = Field(..., description="One Permit rule per mined policy")
+    mined_policy_ids: list[int]
+
+
+class XacmlAttribute(BaseModel):
+    """Attribute the rules read, which a PEP must send in its requests."""
+
+    category: str
+    attribute_id: str
+    data_type: str
+
+
+class XacmlExportSummary(BaseModel):
+    """What an export contains and what it left out."""
+
+    policies: int
+    endpoints: int
+    rules: int
+    attributes: int
+    below_min_confidence: int = Field(..., description="Policies left out for scoring below min_confidence")
+    untranslated_clauses: int
+
+
+class XacmlExportResponse(BaseModel):
+    """XACML 3.0 PolicySet of a repository's mined policies."""
+
+    repository_id: int
+    policy_set_id: str
+    xacml: str = Field(..., description="PolicySet document")
+    policies: list[XacmlPolicy]
+    attributes: list[XacmlAttribute]
+    untranslated: list[UntranslatedClause] = []
+    summary: XacmlExportSummary
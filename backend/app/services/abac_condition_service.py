@@ -0,0 +1,246 @@
+"""Service for attaching attribute conditions lifted from handler bodies to mined policies.
+
+Mining reads an endpoint's guard (RequireAnyRole("MANAGER", "DIRECTOR")) but
+often misses the limits its handler enforces before doing any work, such as
+an amount above which only directors may approve. After each scan this
+service finds the handlers each policy's evidence points at, either directly
+or through the route registration naming them, lifts their denial guards
+into conditions (see guard_condition_extractor), and adds the clauses the
+policy does not state yet, citing the guard as evidence.
+"""
+
+import re
+from collections.abc import Iterator
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.bola_detection_service import _handler_end, _handler_name, _next_handler
+from app.services.condition_evaluation_service import ConditionClause, ConditionEvaluationService
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, SKIPPED_DIRECTORIES
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.guard_condition_extractor import LiftedCondition, file_bindings, lift_guards
+
+logger = structlog.get_logger(__name__)
+
+NAME = re.compile(r"[A-Za-z_]\w*")
+STRING_LITERAL = re.compile(r"""(['"`])[^'"`\n]*\1""")
+
+
+class _SourceFile:
+    """A clone source file split into handlers, with the guards lifted from each."""
+
+    def __init__(self, path: str, text: str):
+        self.path = path
+        self.lines = text.split("\n")
+        self.constants = file_bindings(text)
+        self.handlers: list[tuple[int, int, str | None]] = []
+        start = _next_handler(self.lines, 0)
+        while start is not None:
+            end = _handler_end(self.lines, start)
+            self.handlers.append((start, end, _handler_name(self.lines[start])))
+            start = _next_handler(self.lines, end)
+        self.lifted: dict[int, list[LiftedCondition]] = {}
+
+    def guards(self, start: int, end: int) -> list[LiftedCondition]:
+        """Conditions lifted from the handler starting at start."""
+        if start not in self.lifted:
+            self.lifted[start] = lift_guards(self.lines, start, end, self.constants)
+        return self.lifted[start]
+
+
+def _clause_key(clause: ConditionClause) -> tuple:
+    """What a clause requires, ignoring how it is spelled."""
+    attribute = (clause.attribute or "").split(".")[-1].replace("_", "")
+    inner = _clause_key(clause.inner) if clause.inner else None
+    return clause.kind, attribute, clause.operator, str(clause.value).lower(), clause.required_role, inner
+
+
+class AbacConditionService:
+    """Lifts attribute checks in handler bodies into the conditions of the policies they belong to."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if not repository:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    @staticmethod
+    def _load(root: Path, relative: str, files: dict[str, _SourceFile | None]) -> _SourceFile | None:
+        """Read and split a clone source file once, or None if it is not one."""
+        if relative not in files:
+            path = root / relative
+            files[relative] = None
+            max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+            inside = path.resolve().is_relative_to(root.resolve())
+            if inside and path.suffix in ROUTE_FILE_EXTENSIONS and not SKIPPED_DIRECTORIES & set(Path(relative).parts):
+                if path.is_file() and path.stat().st_size <= max_bytes:
+                    files[relative] = _SourceFile(relative, path.read_text(encoding="utf-8", errors="ignore"))
+        return files[relative]
+
+    @staticmethod
+    def _named(root: Path, name: str, suffix: str, files: dict[str, _SourceFile | None], index: dict) -> list:
+        """Handlers with a name in clone files of one language, indexed on first use."""
+        if suffix not in index:
+            index[suffix] = {}
+            for path in sorted(root.rglob(f"*{suffix}")):
+                relative = path.relative_to(root).as_posix()
+                source = AbacConditionService._load(root, relative, files)
+                for start, end, handler in source.handlers if source else []:
+                    if handler:
+                        index[suffix].setdefault(handler, []).append((source, start, end))
+        return index[suffix].get(name, [])
+
+    @staticmethod
+    def _located(root: Path, route: tuple, evidence: list, files: dict, index: dict) -> list[tuple[_SourceFile, int, int]]:
+        """Handlers evidence is in, or that the registrations of route it cites name.
+
+        Args:
+            root: Repository clone root
+            route: (method, path) the evidence's policy maps to
+            evidence: (file_path, line_start, line_end) per evidence item
+            files: Loaded source files by path
+            index: Named handler index per language
+        """
+        route = tuple(route)
+        found: list[tuple[_SourceFile, int, int]] = []
+        for file_path, line_start, line_end in evidence:
+            source = AbacConditionService._load(root, file_path, files)
+            if source is None or not line_start:
+                continue
+            first, last = line_start - 1, max(line_start, line_end or 0)
+            named = []
+            for line in source.lines[first:last]:
+                if route not in EndpointMappingService.find_routes(line):
+                    continue
+                for name in dict.fromkeys(NAME.findall(STRING_LITERAL.sub("", line))):
+                    local = [(source, s, e) for s, e, handler in source.handlers if handler == name]
+                    named.extend(local or AbacConditionService._named(root, name, Path(source.path).suffix, files, index))
+            if not named:
+                named = [(source, s, e) for s, e, _ in source.handlers if s < last and e > first]
+            found.extend(h for h in named if h[:2] not in [f[:2] for f in found])
+        return found
+
+    @staticmethod
+    def located(policy: Policy) -> dict:
+        """A policy's route and evidence locations, as the handler passes take them."""
+        rule = EndpointMappingService.map_policy(policy)
+        return {
+            "route": [rule.method, rule.path],
+            "evidence": [[e.file_path, e.line_start, e.line_end] for e in policy.evidence or []],
+        }
+
+    def lift_conditions(self, repository_id: int) -> dict:
+        """Add the conditions guarding a repository's handlers to the policies mined for them.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Counts of policies examined and updated, and every lifted condition with its clause
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        policies = self.db.query(Policy).filter(Policy.repository_id == repo.id).all()
+        guards = run_pass(root, handler_guards, policies=[self.located(p) for p in policies])
+        lifted, updated = [], 0
+        for policy, found in zip(policies, guards, strict=True):
+            stated = ConditionEvaluationService.parse(policy.conditions)
+            present = {_clause_key(c) for c in stated}
+            added = []
+            for file_path, line, guard, condition in found:
+                clause = ConditionEvaluationService.parse_clause(condition)
+                key = _clause_key(clause)
+                new = key not in present
+                if new:
+                    present.add(key)
+                    added.append(condition)
+                    if not any(e.file_path == file_path and e.line_start == line for e in policy.evidence):
+                        policy.evidence.append(
+                            Evidence(file_path=file_path, line_start=line, line_end=line, code_snippet=guard)
+                        )
+                detail = clause.inner or clause
+                lifted.append(
+                    {
+                        "policy_id": policy.id,
+                        "file_path": file_path,
+                        "line": line,
+                        "guard": guard,
+                        "condition": condition,
+                        "kind": clause.kind,
+                        "attribute": detail.attribute,
+                        "operator": detail.operator,
+                        "value": detail.value,
+                        "required_role": clause.required_role,
+                        "added": new,
+                    }
+                )
+            if added:
+                policy.conditions = "; ".join([policy.conditions, *added] if stated else added)
+                updated += 1
+        self.db.commit()
+
+        logger.info(
+            "abac_conditions_lifted",
+            repository_id=repo.id,
+            policies=len(policies),
+            updated=updated,
+            conditions=len(lifted),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repo.id,
+            "policies_examined": len(policies),
+            "policies_updated": updated,
+            "conditions": lifted,
+        }
+
+
+def handler_guards(root: str, policies: list[dict]) -> Iterator[list[list]]:
+    """Sandbox pass yielding, per policy, the guards lifted from the handlers it maps to.
+
+    Args:
+        root: Repository clone root
+        policies: Per policy, its route and evidence (see AbacConditionService.located)
+
+    Yields:
+        Per policy, in order: [file path, line, guard, condition] of each guard
+    """
+    clone = Path(root)
+    files: dict[str, _SourceFile | None] = {}
+    index: dict = {}
+    for policy in policies:
+        yield [
+            [source.path, condition.line, condition.guard, condition.condition]
+            for source, start, end in AbacConditionService._located(clone, policy["route"], policy["evidence"], files, index)
+            for condition in source.guards(start, end)
+        ]
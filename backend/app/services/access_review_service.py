@@ -0,0 +1,336 @@
+"""Service for generating and tracking access review campaigns.
+
+Turns mined policies into the periodic access reviews compliance asks for:
+a campaign groups every "who can do what" statement into one packet per
+resource owner, each statement carrying its code evidence. Reviewers attest
+each item through the API and the campaign completes when every packet has
+been reviewed. Items snapshot the policy at generation time so the record of
+what was attested does not change when repositories are rescanned.
+"""
+
+from collections import Counter
+from datetime import UTC, datetime
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.access_review import (
+    AccessReviewCampaign,
+    AccessReviewItem,
+    AccessReviewPacket,
+    AttestationDecision,
+    CampaignStatus,
+    PacketStatus,
+)
+from app.models.policy import Policy, PolicyStatus
+
+logger = structlog.get_logger(__name__)
+
+UNASSIGNED_REVIEWER = "unassigned"
+
+# Decisions that call for remediation and therefore need a justification
+REMEDIATION_DECISIONS = {AttestationDecision.REVOKE, AttestationDecision.MODIFY}
+
+
+class AccessReviewService:
+    """Generates access review packets and records attestations."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def create_campaign(
+        self,
+        name: str,
+        description: str | None = None,
+        due_date: datetime | None = None,
+        application_ids: list[int] | None = None,
+        include_pending: bool = False,
+        created_by: str | None = None,
+    ) -> AccessReviewCampaign:
+        """Generate a campaign with one packet per resource owner.
+
+        Args:
+            name: Campaign name (e.g., "Q3 2026 access review")
+            description: Optional description
+            due_date: When attestations are due
+            application_ids: Restrict the review to these applications
+            include_pending: Also review policies that have not been approved
+            created_by: Email of the user who started the campaign
+
+        Returns:
+            The created campaign
+
+        Raises:
+            ValueError: If no policies are in scope
+        """
+        query = self.db.query(Policy)
+        if self.tenant_id:
+            query = query.filter(Policy.tenant_id == self.tenant_id)
+        if application_ids:
+            query = query.filter(Policy.application_id.in_(application_ids))
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        policies = query.all()
+
+        if not policies:
+            raise ValueError("No policies in scope for an access review")
+
+        campaign = AccessReviewCampaign(
+            tenant_id=self.tenant_id,
+            name=name,
+            description=description,
+            status=CampaignStatus.OPEN,
+            scope={"application_ids": application_ids, "include_pending": include_pending},
+            due_date=due_date,
+            created_by=created_by,
+        )
+
+        packets: dict[tuple[str, int | None], AccessReviewPacket] = {}
+        for policy in sorted(policies, key=lambda p: (p.application_id or 0, p.resource, p.action)):
+            application = policy.application
+            reviewer = (application.owner if application and application.owner else UNASSIGNED_REVIEWER)
+            key = (reviewer, policy.application_id)
+            packet = packets.get(key)
+            if packet is None:
+                packet = AccessReviewPacket(
+                    reviewer=reviewer,
+                    application_id=policy.application_id,
+                    application_name=application.name if application else None,
+                    status=PacketStatus.PENDING,
+                )
+                packets[key] = packet
+                campaign.packets.append(packet)
+            packet.items.append(self._item_from_policy(policy))
+
+        self.db.add(campaign)
+        self.db.commit()
+        self.db.refresh(campaign)
+
+        logger.info(
+            "access_review_campaign_created",
+            campaign_id=campaign.id,
+            packets=len(packets),
+            items=len(policies),
+        )
+        return campaign
+
+    @staticmethod
+    def _item_from_policy(policy: Policy) -> AccessReviewItem:
+        """Snapshot a policy and its evidence as a review item."""
+        return AccessReviewItem(
+            policy_id=policy.id,
+            subject=policy.subject,
+            resource=policy.resource,
+            action=policy.action,
+            conditions=policy.conditions,
+            evidence=[
+                {
+                    "file_path": ev.file_path,
+                    "line_start": ev.line_start,
+                    "line_end": ev.line_end,
+                    "code_snippet": ev.code_snippet,
+                }
+                for ev in (policy.evidence or [])
+            ],
+            decision=AttestationDecision.PENDING,
+        )
+
+    def list_campaigns(self) -> list[AccessReviewCampaign]:
+        """List campaigns, newest first.
+
+        Returns:
+            Campaigns for the tenant
+        """
+        query = self.db.query(AccessReviewCampaign)
+        if self.tenant_id:
+            query = query.filter(AccessReviewCampaign.tenant_id == self.tenant_id)
+        return query.order_by(AccessReviewCampaign.created_at.desc()).all()
+
+    def get_campaign(self, campaign_id: int) -> AccessReviewCampaign:
+        """Get a campaign.
+
+        Args:
+            campaign_id: Campaign ID
+
+        Returns:
+            The campaign
+
+        Raises:
+            ValueError: If the campaign does not exist
+        """
+        query = self.db.query(AccessReviewCampaign).filter(AccessReviewCampaign.id == campaign_id)
+        if self.tenant_id:
+            query = query.filter(AccessReviewCampaign.tenant_id == self.tenant_id)
+        campaign = query.first()
+        if not campaign:
+            raise ValueError(f"Access review campaign {campaign_id} not found")
+        return campaign
+
+    def get_packet(self, packet_id: int) -> AccessReviewPacket:
+        """Get a reviewer packet.
+
+        Args:
+            packet_id: Packet ID
+
+        Returns:
+            The packet
+
+        Raises:
+            ValueError: If the packet does not exist
+        """
+        query = (
+            self.db.query(AccessReviewPacket)
+            .join(AccessReviewCampaign)
+            .filter(AccessReviewPacket.id == packet_id)
+        )
+        if self.tenant_id:
+            query = query.filter(AccessReviewCampaign.tenant_id == self.tenant_id)
+        packet = query.first()
+        if not packet:
+            raise ValueError(f"Access review packet {packet_id} not found")
+        return packet
+
+    def attest(
+        self,
+        item_id: int,
+        decision: AttestationDecision,
+        reviewed_by: str,
+        comment: str | None = None,
+    ) -> AccessReviewItem:
+        """Record a reviewer's decision on one item.
+
+        Completes the packet when its last item is attested, and the campaign
+        when its last packet completes.
+
+        Args:
+            item_id: Item ID
+            decision: Reviewer decision
+            reviewed_by: Reviewer email
+            comment: Justification (required to revoke or modify access)
+
+        Returns:
+            The updated item
+
+        Raises:
+            ValueError: If the item does not exist, the campaign is closed, or
+                the attestation is incomplete
+        """
+        query = (
+            self.db.query(AccessReviewItem)
+            .join(AccessReviewPacket)
+            .join(AccessReviewCampaign)
+            .filter(AccessReviewItem.id == item_id)
+        )
+        if self.tenant_id:
+            query = query.filter(AccessReviewCampaign.tenant_id == self.tenant_id)
+        item = query.first()
+        if not item:
+            raise ValueError(f"Access review item {item_id} not found")
+
+        packet = item.packet
+        campaign = packet.campaign
+        if campaign.status != CampaignStatus.OPEN:
+            raise ValueError(f"Campaign {campaign.id} is {campaign.status.value}")
+        if decision == AttestationDecision.PENDING:
+            raise ValueError("An attestation must certify, revoke, or modify access")
+        if decision in REMEDIATION_DECISIONS and not (comment and comment.strip()):
+            raise ValueError(f"A comment is required to {decision.value} access")
+
+        now = datetime.now(UTC)
+        item.decision = decision
+        item.reviewed_by = reviewed_by
+        item.review_comment = comment
+        item.reviewed_at = now
+
+        if all(i.decision != AttestationDecision.PENDING for i in packet.items):
+            packet.status = PacketStatus.COMPLETED
+            packet.completed_at = now
+        if all(p.status == PacketStatus.COMPLETED for p in campaign.packets):
+            campaign.status = CampaignStatus.COMPLETED
+            campaign.completed_at = now
+
+        self.db.commit()
+
+        logger.info(
+            "access_review_attested",
+            campaign_id=campaign.id,
+            item_id=item_id,
+            decision=decision.value,
+            reviewed_by=reviewed_by,
+        )
+        return item
+
+    def cancel_campaign(self, campaign_id: int) -> AccessReviewCampaign:
+        """Cancel an open campaign, keeping attestations recorded so far.
+
+        Args:
+            campaign_id: Campaign ID
+
+        Returns:
+            The cancelled campaign
+
+        Raises:
+            ValueError: If the campaign does not exist or is not open
+        """
+        campaign = self.get_campaign(campaign_id)
+        if campaign.status != CampaignStatus.OPEN:
+            raise ValueError(f"Campaign {campaign_id} is {campaign.status.value}")
+        campaign.status = CampaignStatus.CANCELLED
+        self.db.commit()
+        return campaign
+
+    @staticmethod
+    def summarize(campaign: AccessReviewCampaign) -> dict:
+        """Summarize campaign progress and outcomes.
+
+        Args:
+            campaign: Campaign with packets and items loaded
+
+        Returns:
+            Progress counts, per-decision totals, and per-packet status
+        """
+        items = [item for packet in campaign.packets for item in packet.items]
+        decisions = Counter(item.decision.value for item in items)
+        attested = len(items) - decisions.get(AttestationDecision.PENDING.value, 0)
+
+        return {
+            "total_items": len(items),
+            "attested_items": attested,
+            "progress": round(attested / len(items) * 100, 1) if items else 0.0,
+            "decisions": {d.value: decisions.get(d.value, 0) for d in AttestationDecision},
+            "packets": [
+                {
+                    "id": packet.id,
+                    "reviewer": packet.reviewer,
+                    "application_id": packet.application_id,
+                    "application_name": packet.application_name,
+                    "status": packet.status.value,
+                    "total_items": len(packet.items),
+                    "attested_items": sum(
+                        1 for i in packet.items if i.decision != AttestationDecision.PENDING
+                    ),
+                }
+                for packet in campaign.packets
+            ],
+        }
+
+    @staticmethod
+    def remediation_items(campaign: AccessReviewCampaign) -> list[AccessReviewItem]:
+        """List items reviewers asked to revoke or modify.
+
+        Args:
+            campaign: Campaign with packets and items loaded
+
+        Returns:
+            Items needing remediation
+        """
+        return [
+            item
+            for packet in campaign.packets
+            for item in packet.items
+            if item.decision in REMEDIATION_DECISIONS
+        ]
@@ -0,0 +1,475 @@
+"""Service for mapping each service's administrative surface.
+
+Admin functionality is where one missing check hands over the most. It is
+reached through admin path prefixes (/admin, /internal, /backoffice),
+management endpoints (Spring Boot Actuator, pprof, job dashboards),
+framework admin modules mounted with one line (Django admin, Flask-Admin,
+RailsAdmin, ActiveAdmin, AdminJS, Hangfire), and management ports that
+serve those endpoints beside the public listener. This service finds those
+surfaces in the mined policies and the repository's clone and inventories
+every admin endpoint with its protection status.
+"""
+
+import re
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass
+from enum import Enum
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.cors_csrf_service import SOURCE_EXTENSIONS, scope_matches
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+from app.services.extractor_utils import first_line, yaml_documents
+
+logger = structlog.get_logger(__name__)
+
+
+class AdminSurfaceKind(str, Enum):
+    """How an admin surface is reached."""
+
+    ADMIN_PATH = "admin_path"  # Routes under an admin path prefix
+    MANAGEMENT_ENDPOINT = "management_endpoint"  # Actuator, pprof, job dashboards, DB consoles
+    FRAMEWORK_ADMIN = "framework_admin"  # Admin module mounted from a framework or library
+    MANAGEMENT_PORT = "management_port"  # Listener serving management endpoints on its own port
+
+
+class AdminProtection(str, Enum):
+    """Protection status of an admin endpoint."""
+
+    ROLE_RESTRICTED = "role_restricted"  # Only named roles may call it
+    FRAMEWORK_DEFAULT = "framework_default"  # The admin module enforces its own login
+    AUTHENTICATED = "authenticated"  # Any signed-in user may call it
+    PUBLIC = "public"  # Anonymous callers allowed
+    NO_POLICY = "no_policy"  # Found in code, but no mined policy covers it
+
+
+# Statuses the red team should look at first
+EXPOSED_PROTECTIONS = {AdminProtection.PUBLIC, AdminProtection.AUTHENTICATED, AdminProtection.NO_POLICY}
+
+ADMIN_SEGMENTS = {
+    "admin", "administrator", "administration", "_admin", "wp-admin", "superadmin", "sysadmin", "superuser",
+    "backoffice", "back-office", "manage", "management", "console", "staff", "internal", "ops",
+}  # fmt: skip
+MANAGEMENT_SEGMENTS = {
+    "actuator", "debug", "pprof", "jmx", "jolokia", "metrics", "prometheus", "h2-console", "phpmyadmin",
+    "sidekiq", "resque", "hangfire", "horizon", "telescope", "nova", "graphiql", "flower",
+}  # fmt: skip
+
+# Path segments skipped before the segment that names the surface: /api/v1/admin
+LEADING_SEGMENT = re.compile(r"^(?:api|v\d+(?:\.\d+)?|rest)$", re.IGNORECASE)
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(
+    r"admin|management|actuator|pprof|expvar|sidekiq|hangfire|resque|containerPort|port_value", re.IGNORECASE
+)
+
+
+@dataclass
+class FrameworkSurface:
+    """A framework admin module signature."""
+
+    framework: str
+    pattern: re.Pattern  # Group 1, when present, is the mount path
+    default_path: str
+    kind: AdminSurfaceKind = AdminSurfaceKind.FRAMEWORK_ADMIN
+    builtin_protection: str | None = None  # Login the module enforces unless configured otherwise
+    file_marker: re.Pattern | None = None  # The file must also match this
+
+
+FRAMEWORK_SURFACES = [
+    FrameworkSurface(
+        "django-admin",
+        re.compile(r"""(?:path|re_path|url)\(\s*r?['"]\^?([^'"]*)['"]\s*,\s*admin\.site\.urls"""),
+        "/admin",
+        builtin_protection="staff login (is_staff)",
+    ),
+    FrameworkSurface(
+        "flask-admin",
+        re.compile(r"""\bAdmin\(\s*app\b(?:[^)\n]*?url\s*=\s*['"]([^'"]+)['"])?"""),
+        "/admin",
+        file_marker=re.compile(r"flask_admin"),
+    ),
+    FrameworkSurface("rails-admin", re.compile(r"""mount\s+RailsAdmin::Engine\s*=>\s*['"]([^'"]+)['"]"""), "/admin"),
+    FrameworkSurface("activeadmin", re.compile(r"ActiveAdmin\.routes\(self\)"), "/admin", builtin_protection="Devise admin login"),
+    FrameworkSurface(
+        "rails-mounted-app",
+        re.compile(r"""mount\s+(?:Sidekiq::Web|Resque::Server|Flipper::UI\.app\([^)]*\)|PgHero::Engine|Blazer::Engine)\s*,?\s*(?:at:\s*|=>\s*)['"]([^'"]+)['"]"""),
+        "/",
+        kind=AdminSurfaceKind.MANAGEMENT_ENDPOINT,
+    ),
+    FrameworkSurface(
+        "adminjs",
+        re.compile(r"""rootPath\s*:\s*['"]([^'"]+)['"]"""),
+        "/admin",
+        file_marker=re.compile(r"AdminJS|AdminBro"),
+    ),
+    FrameworkSurface(
+        "hangfire",
+        re.compile(r"""(?:Map|Use)HangfireDashboard\(\s*(?:"([^"]+)")?"""),
+        "/hangfire",
+        kind=AdminSurfaceKind.MANAGEMENT_ENDPOINT,
+        builtin_protection="local requests only",
+    ),
+    FrameworkSurface(
+        "go-pprof",
+        re.compile(r'_\s+"net/http/pprof"'),
+        "/debug/pprof",
+        kind=AdminSurfaceKind.MANAGEMENT_ENDPOINT,
+    ),
+    FrameworkSurface("go-expvar", re.compile(r'_\s+"expvar"'), "/debug/vars", kind=AdminSurfaceKind.MANAGEMENT_ENDPOINT),
+]
+
+# Spring Boot Actuator settings, after flattening YAML to dotted keys
+ACTUATOR_BASE_PATH = re.compile(r"^management\.endpoints\.web\.base-?path\s*[=:]\s*(\S+)", re.MULTILINE | re.IGNORECASE)
+ACTUATOR_EXPOSURE = re.compile(
+    r"^management\.endpoints\.web\.exposure\.include\s*[=:]\s*(.+)$", re.MULTILINE | re.IGNORECASE
+)
+MANAGEMENT_PORT = re.compile(r"^management\.server\.port\s*[=:]\s*(\d+)", re.MULTILINE | re.IGNORECASE)
+ENVOY_ADMIN_PORT = re.compile(r"^admin\.address\.socket_address\.port_value\s*[=:]\s*(\d+)", re.MULTILINE)
+GO_DEBUG_LISTENER = re.compile(r"""ListenAndServe\(\s*"((?:localhost|127\.0\.0\.1)?):(\d+)"\s*,\s*nil\s*\)""")
+MANAGEMENT_PORT_NAMES = re.compile(r"^(?:admin|management|mgmt|metrics|debug|actuator|jmx)(?:-\w+)?$", re.IGNORECASE)
+LOOPBACK = {"localhost", "127.0.0.1", "::1"}
+
+
+@dataclass
+class AdminSurface:
+    """An admin surface found in a repository."""
+
+    kind: str
+    framework: str | None
+    path: str | None  # Mount path or prefix; None for management ports
+    file_path: str | None = None
+    line_start: int | None = None
+    snippet: str | None = None
+    port: int | None = None
+    loopback_only: bool = False  # Bound to loopback, reachable only from the host
+    builtin_protection: str | None = None
+
+
+def surface_kind(path: str) -> AdminSurfaceKind | None:
+    """Classify a route path as an admin or management route.
+
+    Args:
+        path: Route path (e.g., "/api/v1/admin/users")
+
+    Returns:
+        The surface kind, or None for ordinary routes
+    """
+    segments = [s for s in path.lower().split("/") if s]
+    while segments and LEADING_SEGMENT.match(segments[0]):
+        segments.pop(0)
+    if not segments:
+        return None
+    if segments[0] in ADMIN_SEGMENTS:
+        return AdminSurfaceKind.ADMIN_PATH
+    if segments[0] in MANAGEMENT_SEGMENTS:
+        return AdminSurfaceKind.MANAGEMENT_ENDPOINT
+    return None
+
+
+def _flatten(node: object, prefix: str = "") -> list[str]:
+    """Flatten a YAML document into "dotted.key=value" lines."""
+    if isinstance(node, dict):
+        lines = []
+        for key, value in node.items():
+            lines.extend(_flatten(value, f"{prefix}{key}."))
+        return lines
+    if isinstance(node, list):
+        if all(not isinstance(v, dict | list) for v in node):
+            return [f"{prefix.rstrip('.')}={','.join(str(v) for v in node)}"]
+        return [line for i, v in enumerate(node) for line in _flatten(v, f"{prefix}{i}.")]
+    return [f"{prefix.rstrip('.')}={node}"]
+
+
+def _named_ports(node: object) -> list[tuple[str, int]]:
+    """Container and service ports named like management ports (Kubernetes, Compose)."""
+    found = []
+    if isinstance(node, dict):
+        name = node.get("name")
+        port = node.get("containerPort", node.get("port"))
+        if isinstance(name, str) and isinstance(port, int) and MANAGEMENT_PORT_NAMES.match(name):
+            found.append((name, port))
+        for value in node.values():
+            found.extend(_named_ports(value))
+    elif isinstance(node, list):
+        for value in node:
+            found.extend(_named_ports(value))
+    return found
+
+
+def _mount_path(path: str | None, default: str) -> str:
+    """Normalize a mount path, falling back to the module's default."""
+    path = (path if path is not None else default).strip().lstrip("^").rstrip("$")
+    return "/" + path.strip("/")
+
+
+def extract_surfaces(file_path: str, text: str) -> list[AdminSurface]:
+    """Find framework admin modules, management endpoints, and management ports.
+
+    Args:
+        file_path: Path relative to the repository root
+        text: File content
+
+    Returns:
+        Admin surfaces declared in the file
+    """
+    if not PREFILTER.search(text):
+        return []
+    surfaces = []
+    for signature in FRAMEWORK_SURFACES:
+        if signature.file_marker and not signature.file_marker.search(text):
+            continue
+        for match in signature.pattern.finditer(text):
+            line = text.count("\n", 0, match.start()) + 1
+            builtin = signature.builtin_protection
+            if signature.framework == "flask-admin" and re.search(r"def is_accessible\b", text):
+                builtin = "is_accessible override"
+            surfaces.append(
+                AdminSurface(
+                    kind=signature.kind.value,
+                    framework=signature.framework,
+                    path=_mount_path(match.group(1) if match.groups() else None, signature.default_path),
+                    file_path=file_path,
+                    line_start=line,
+                    snippet=text.split("\n")[line - 1].strip(),
+                    builtin_protection=builtin,
+                )
+            )
+
+    config = text
+    if file_path.endswith((".yml", ".yaml")):
+        documents = yaml_documents(text)
+        config = "\n".join(line for d in documents for line in _flatten(d))
+        for name, port in (p for d in documents for p in _named_ports(d)):
+            surfaces.append(
+                AdminSurface(
+                    kind=AdminSurfaceKind.MANAGEMENT_PORT.value,
+                    framework="container-port",
+                    path=None,
+                    file_path=file_path,
+                    line_start=first_line(text, str(port)),
+                    snippet=f"{name}: {port}",
+                    port=port,
+                )
+            )
+    if file_path.endswith((".yml", ".yaml", ".properties")):
+        base = ACTUATOR_BASE_PATH.search(config)
+        base_path = _mount_path(base.group(1) if base else None, "/actuator")
+        for exposure in ACTUATOR_EXPOSURE.finditer(config):
+            for endpoint in (e.strip().strip("'\"") for e in exposure.group(1).split(",")):
+                if not endpoint:
+                    continue
+                surfaces.append(
+                    AdminSurface(
+                        kind=AdminSurfaceKind.MANAGEMENT_ENDPOINT.value,
+                        framework="spring-actuator",
+                        path=f"{base_path}/**" if endpoint == "*" else f"{base_path}/{endpoint}",
+                        file_path=file_path,
+                        line_start=first_line(text, "include"),
+                        snippet=exposure.group(0).strip(),
+                    )
+                )
+        for pattern, framework in ((MANAGEMENT_PORT, "spring-actuator"), (ENVOY_ADMIN_PORT, "envoy-admin")):
+            for match in pattern.finditer(config):
+                surfaces.append(
+                    AdminSurface(
+                        kind=AdminSurfaceKind.MANAGEMENT_PORT.value,
+                        framework=framework,
+                        path=None,
+                        file_path=file_path,
+                        line_start=first_line(text, match.group(1)),
+                        snippet=match.group(0).strip(),
+                        port=int(match.group(1)),
+                    )
+                )
+    if file_path.endswith(".go") and '"net/http/pprof"' in text:
+        for match in GO_DEBUG_LISTENER.finditer(text):
+            surfaces.append(
+                AdminSurface(
+                    kind=AdminSurfaceKind.MANAGEMENT_PORT.value,
+                    framework="go-pprof",
+                    path=None,
+                    file_path=file_path,
+                    line_start=text.count("\n", 0, match.start()) + 1,
+                    snippet=match.group(0),
+                    port=int(match.group(2)),
+                    loopback_only=match.group(1) in LOOPBACK,
+                )
+            )
+    return surfaces
+
+
+def rule_protection(rule: EndpointRule) -> AdminProtection:
+    """Protection status of an endpoint rule."""
+    if rule.is_public:
+        return AdminProtection.PUBLIC
+    if rule.roles:
+        return AdminProtection.ROLE_RESTRICTED
+    return AdminProtection.AUTHENTICATED
+
+
+def admin_endpoints(policies: list[Policy], surfaces: list[AdminSurface]) -> list[dict]:
+    """Inventory admin endpoints from mined policies and surfaces found in code.
+
+    An endpoint is an admin endpoint when its path has an admin or
+    management prefix or falls under a surface's mount path. Path surfaces
+    no mined policy covers are listed with their built-in protection, if
+    the module has one, or as having no policy.
+
+    Args:
+        policies: Mined policies
+        surfaces: Surfaces found in the repository's clone
+
+    Returns:
+        Endpoint dictionaries, exposed endpoints first
+    """
+    mounted = [s for s in surfaces if s.path]
+    covered: set[int] = set()
+    endpoints = []
+    for rule in EndpointMappingService.map_policies(policies):
+        surface = next(
+            (i for i, s in enumerate(mounted) if scope_matches(f"{s.path.rstrip('/')}/*", rule.path)),
+            None,
+        )
+        kind = surface_kind(rule.path)
+        if surface is None and kind is None:
+            continue
+        if surface is not None:
+            covered.add(surface)
+            kind = AdminSurfaceKind(mounted[surface].kind)
+        protection = rule_protection(rule)
+        endpoints.append(
+            {
+                "endpoint": rule.key,
+                "method": rule.method,
+                "path": rule.path,
+                "surface": kind.value,
+                "framework": mounted[surface].framework if surface is not None else None,
+                "policy_ids": rule.policy_ids,
+                "roles": rule.roles,
+                "conditions": rule.conditions,
+                "protection": protection.value,
+                "exposed": protection in EXPOSED_PROTECTIONS,
+            }
+        )
+    for i, surface in enumerate(mounted):
+        if i in covered:
+            continue
+        protection = AdminProtection.FRAMEWORK_DEFAULT if surface.builtin_protection else AdminProtection.NO_POLICY
+        endpoints.append(
+            {
+                "endpoint": f"* {surface.path}",
+                "method": "*",
+                "path": surface.path,
+                "surface": surface.kind,
+                "framework": surface.framework,
+                "policy_ids": [],
+                "roles": [],
+                "conditions": [surface.builtin_protection] if surface.builtin_protection else [],
+                "protection": protection.value,
+                "exposed": protection in EXPOSED_PROTECTIONS,
+            }
+        )
+    endpoints.sort(key=lambda e: (not e["exposed"], e["path"], e["method"]))
+    return endpoints
+
+
+def admin_surfaces(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the admin surfaces of a repository clone in path order."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in SOURCE_EXTENSIONS:
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        text = path.read_text(encoding="utf-8", errors="ignore")
+        yield from (asdict(s) for s in extract_surfaces(relative.as_posix(), text))
+
+
+class AdminSurfaceService:
+    """Inventories admin surfaces and endpoints with their protection status."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[AdminSurface]:
+        """Read admin surfaces from a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Admin surfaces in path order
+        """
+        return [AdminSurface(**s) for s in run_pass(root, admin_surfaces)]
+
+    def _inventory(self, repository: Repository) -> dict:
+        """Admin surface inventory of one repository."""
+        root = self.clone_dir / str(repository.id)
+        cloned = root.is_dir()
+        surfaces = self.scan_clone(root) if cloned else []
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository.id)
+        endpoints = admin_endpoints(policies, surfaces)
+        exposed = sum(e["exposed"] for e in endpoints)
+        logger.info(
+            "admin_surface_mapped",
+            repository_id=repository.id,
+            surfaces=len(surfaces),
+            admin_endpoints=len(endpoints),
+            exposed_endpoints=exposed,
+        )
+        return {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "cloned": cloned,
+            "surfaces": [asdict(s) for s in surfaces],
+            "management_ports": sorted({s.port for s in surfaces if s.port is not None}),
+            "exposed_endpoints": exposed,
+            "endpoints": endpoints,
+        }
+
+    def services(self, repository_id: int | None = None, exposed_only: bool = False) -> list[dict]:
+        """Admin surface inventory per service (repository).
+
+        Args:
+            repository_id: Restrict to one repository
+            exposed_only: Keep only services with public, any-user, or unmapped admin endpoints
+
+        Returns:
+            Per-repository surfaces, management ports, and admin endpoints
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        reports = [self._inventory(repository) for repository in repositories]
+        if exposed_only:
+            reports = [r for r in reports if r["exposed_endpoints"]]
+        return reports
@@ -0,0 +1,759 @@
+"""Extract route authorization from ASP.NET Core services.
+
+ASP.NET Core controllers declare access with [Authorize] and
+[AllowAnonymous] attributes on controller classes and their actions, where
+every [Authorize] on the class and the action must pass and [AllowAnonymous]
+at either level bypasses them all. [Authorize(Policy = "...")] names a
+policy registered in AddAuthorization (or AddAuthorizationBuilder) as a
+chain of RequireRole, RequireClaim, and custom requirements, and endpoints
+without any attribute fall under the FallbackPolicy when one is set.
+Minimal APIs attach the same metadata with .RequireAuthorization() and
+.AllowAnonymous() on MapGet/MapPost endpoints and the MapGroup groups they
+belong to. Policy names are resolved against their registrations so each
+route finding carries the roles and claims it actually requires.
+"""
+
+import re
+from dataclasses import dataclass, field
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_of, line_span
+from app.services.jvm_route_extractor import ANONYMOUS, AUTHENTICATED, MAX_SNIPPET_LINES, Access, _join
+
+ASPNET_SUFFIXES = (".cs",)
+
+ASPNET_MARKER = re.compile(r"\bMicrosoft\.AspNetCore\b|\[\s*(?:Authorize|AllowAnonymous|ApiController)\b|\bWebApplication\b")
+
+
+class AspNetRouteKind:
+    """Kinds of ASP.NET Core route findings."""
+
+    CONTROLLER_ROUTE = "aspnet_controller_route"
+    MINIMAL_API = "aspnet_minimal_api"
+
+
+# A verbatim or regular string, char literal, line comment, or block comment
+COMMENT_OR_STRING = re.compile(
+    r'@"(?:[^"]|"")*"?|\$?"[^"\\\n]*(?:\\.[^"\\\n]*)*"?|\'(?:\\.|[^\'\\\n])\'|//[^\n]*|/\*.*?(?:\*/|\Z)', re.DOTALL
+)
+
+TOKEN = re.compile(r"\[|\b(?:class|record|struct|interface)\s+([A-Za-z_]\w*)|\b(public)\b|\b([A-Za-z_]\w*)\s*\(|[;{}=]")
+ATTRIBUTE = re.compile(r"([A-Za-z_][\w.]*)\s*(?:\((.*)\))?$", re.DOTALL)
+BASES = re.compile(r"\s*(?:<[^>{;]*>)?\s*(?:\([^){;]*\))?\s*:\s*([^{;]*)")
+CONSTANT = re.compile(r'\b(?:const|static\s+readonly)\s+string\s+(\w+)\s*=\s*"([^"]*)"')
+CLASS_NAME = re.compile(r"\b(?:class|record|struct|interface)\s+(\w+)")
+
+# Names that are followed by "(" but never start a method declaration
+NON_METHOD_WORDS = {
+    "if", "for", "foreach", "while", "switch", "catch", "using", "lock", "return", "new", "nameof",
+    "typeof", "sizeof", "default", "base", "this", "fixed", "checked", "unchecked", "when", "await",
+}  # fmt: skip
+
+HTTP_ATTRIBUTES = {"HttpGet": "GET", "HttpPost": "POST", "HttpPut": "PUT", "HttpPatch": "PATCH", "HttpDelete": "DELETE", "HttpHead": "HEAD"}
+MAP_VERBS = {"MapGet": "GET", "MapPost": "POST", "MapPut": "PUT", "MapPatch": "PATCH", "MapDelete": "DELETE"}
+CONTROLLER_MAPPINGS = ("MapControllers", "MapControllerRoute", "MapDefaultControllerRoute", "MapAreaControllerRoute")
+
+# Policy registrations and the requirements a policy builder chains
+AUTHORIZATION_CALL = re.compile(r"\b(AddAuthorization|AddAuthorizationCore|AddAuthorizationBuilder|Configure\s*<\s*AuthorizationOptions\s*>)\s*\(")
+POLICY_REGISTRATION = re.compile(r"\.\s*(AddPolicy|AddDefaultPolicy|AddFallbackPolicy|SetDefaultPolicy|SetFallbackPolicy)\s*\(")
+POLICY_PROPERTY = re.compile(r"\b(DefaultPolicy|FallbackPolicy)\s*=(?!=)")
+REQUIREMENT = re.compile(
+    r"\b(RequireRole|RequireClaim|RequireAuthenticatedUser|RequireUserName|RequireAssertion|AddRequirements|AddAuthenticationSchemes)\s*\("
+)
+ROUTE_CALL = re.compile(r"\b([A-Za-z_]\w*)\s*\.\s*(Map\w*|RequireAuthorization|AllowAnonymous)\s*(?:<[^>;{}()]*>)?\s*\(")
+CHAIN_CALL = re.compile(r"\s*\.\s*(\w+)\s*(?:<[^>;{}()]*>)?\s*\(")
+GROUP_ASSIGNMENT = re.compile(r"(?:\bvar|\bRouteGroupBuilder|\bIEndpointRouteBuilder)\s+(\w+)\s*=\s*$")
+ROUTE_PARAMETER = re.compile(r"\{(\*{0,2})(\w+)[^}]*\}")
+
+
+def _strip_comments(text: str) -> str:
+    """Blank out comments, keeping string literals, offsets, and newlines."""
+
+    def blank(match: re.Match) -> str:
+        token = match.group(0)
+        return re.sub(r"[^\n]", " ", token) if token.startswith("/") else token
+
+    return COMMENT_OR_STRING.sub(blank, text)
+
+
+def _mask_strings(code: str) -> str:
+    """Blank out string and char literal contents, so brackets inside them are not code."""
+
+    def blank(match: re.Match) -> str:
+        token = match.group(0)
+        if token.startswith("/"):
+            return token
+        quote = next(i for i, c in enumerate(token) if c in "\"'")
+        end = len(token) - 1 if len(token) > quote + 1 and token[-1] == token[quote] else len(token)
+        return token[: quote + 1] + re.sub(r"[^\n]", " ", token[quote + 1 : end]) + token[end:]
+
+    return COMMENT_OR_STRING.sub(blank, code)
+
+
+def _close(masked: str, open_at: int, opening: str = "(", closing: str = ")") -> int:
+    """Offset just past the bracket closing the one at open_at."""
+    depth = 0
+    for i in range(open_at, len(masked)):
+        if masked[i] == opening:
+            depth += 1
+        elif masked[i] == closing:
+            depth -= 1
+            if depth == 0:
+                return i + 1
+    return len(masked)
+
+
+def _split(masked: str, code: str, start: int, end: int) -> list[str]:
+    """Top-level comma-separated parts of code[start:end]."""
+    parts, depth, begin = [], 0, start
+    for i in range(start, end):
+        c = masked[i]
+        if c in "([{":
+            depth += 1
+        elif c in ")]}":
+            depth -= 1
+        elif c == "," and depth == 0:
+            parts.append(code[begin:i].strip())
+            begin = i + 1
+    parts.append(code[begin:end].strip())
+    return [p for p in parts if p]
+
+
+def _split_text(text: str) -> list[str]:
+    """Top-level comma-separated parts of an argument list."""
+    code = _strip_comments(text)
+    return _split(_mask_strings(code), code, 0, len(code))
+
+
+def _evaluate(expression: str, constants: dict[str, str]) -> str | None:
+    """Value of a string expression: literals, constants, nameof(), and "+" concatenations."""
+    pieces = []
+    for piece in re.split(r"\s*\+\s*", expression.strip()):
+        literal = re.fullmatch(r'[@$]?"((?:\\.|[^"\\])*)"', piece)
+        nameof = re.fullmatch(r"nameof\s*\(\s*(?:[\w.]+\.)?(\w+)\s*\)", piece)
+        if literal:
+            pieces.append(literal.group(1))
+        elif nameof:
+            pieces.append(nameof.group(1))
+        elif piece in constants:
+            pieces.append(constants[piece])
+        elif re.fullmatch(r"[\w.]+", piece) and piece.rsplit(".", 1)[-1] in constants:
+            pieces.append(constants[piece.rsplit(".", 1)[-1]])
+        else:
+            return None
+    return "".join(pieces)
+
+
+def _values(args: str, constants: dict[str, str]) -> list[str]:
+    """String values of an argument list, flattening array and collection initializers."""
+    values = []
+    for arg in _split_text(args):
+        arg = re.sub(r"^new\s*(?:\w+\s*)?\[\s*\]\s*", "", arg).strip()
+        if arg[:1] in "{[" and arg[-1:] in "}]":
+            values += _values(arg[1:-1], constants)
+            continue
+        value = _evaluate(arg, constants)
+        if value is not None:
+            values.append(value)
+    return values
+
+
+@dataclass
+class Attribute:
+    """A C# attribute and its argument text."""
+
+    name: str  # Without namespace and "Attribute" suffix
+    args: str  # Inside the parentheses
+    source: str
+    start: int
+
+    def argument(self, name: str | None, constants: dict[str, str]) -> str | None:
+        """A named argument (Name = value), or the positional one when name is None, as a string."""
+        for arg in _split_text(self.args):
+            named = re.match(r"(\w+)\s*=(?!=)\s*(.*)$", arg, re.DOTALL)
+            if (named and named.group(1) == name) or (named is None and name is None):
+                return _evaluate(named.group(2) if named else arg, constants)
+        return None
+
+
+@dataclass
+class Declaration:
+    """A class or method with the attributes in front of it."""
+
+    kind: str  # "class" or "method"
+    name: str
+    attributes: list[Attribute]
+    start: int
+    end: int
+    bases: str = ""
+    public: bool = False
+    owner: "Declaration | None" = None
+    members: list["Declaration"] = field(default_factory=list)
+
+    def find(self, *names: str) -> list[Attribute]:
+        """Attributes with one of the given names."""
+        return [a for a in self.attributes if a.name in names]
+
+
+def _attributes(masked: str, code: str, open_at: int) -> tuple[list[Attribute], int]:
+    """Attributes of the [...] list opening at open_at, and the offset past it."""
+    close = _close(masked, open_at, "[", "]")
+    inner_start = open_at + 1
+    target = re.match(r"\s*(\w+)\s*:(?!:)", masked[inner_start : close - 1])
+    if target and target.group(1) in ("assembly", "module", "return", "param", "field", "property", "type"):
+        return [], close
+    attributes = []
+    for part in _split(masked, code, inner_start, close - 1):
+        match = ATTRIBUTE.match(part)
+        if match:
+            name = match.group(1).rsplit(".", 1)[-1].removesuffix("Attribute")
+            attributes.append(Attribute(name, match.group(2) or "", f"[{' '.join(part.split())}]", open_at))
+    return attributes, close
+
+
+def parse_declarations(text: str) -> list[Declaration]:
+    """Find classes and their attributed or public methods.
+
+    Args:
+        text: C# source
+
+    Returns:
+        Top-level and nested classes, each with its methods as members
+    """
+    code = _strip_comments(text)
+    masked = _mask_strings(code)
+    classes: list[Declaration] = []
+    stack: list[tuple[Declaration, int]] = []  # (class, brace depth of its body)
+    pending: list[Attribute] = []
+    public = False
+    awaiting_body: Declaration | None = None
+    depth, pos = 0, 0
+
+    while True:
+        match = TOKEN.search(masked, pos)
+        if not match:
+            break
+        pos = match.end()
+        token = match.group(0)
+        if token == "[":
+            before = match.start() - 1
+            while before >= 0 and masked[before].isspace():
+                before -= 1
+            if before >= 0 and masked[before] not in ";{}]":
+                continue
+            attributes, pos = _attributes(masked, code, match.start())
+            pending += attributes
+        elif match.group(1):
+            start = pending[0].start if pending else match.start()
+            bases = BASES.match(masked, match.end())
+            declaration = Declaration("class", match.group(1), pending, start, match.end(), bases=bases.group(1).strip() if bases else "")
+            declaration.owner = stack[-1][0] if stack else None
+            classes.append(declaration)
+            pending, public, awaiting_body = [], False, declaration
+        elif match.group(2):
+            public = True
+        elif match.group(3):
+            name = match.group(3)
+            close = _close(masked, match.end() - 1)
+            in_class_body = stack and depth == stack[-1][1]
+            owner = stack[-1][0] if stack else None
+            if in_class_body and name not in NON_METHOD_WORDS and name != owner.name and (pending or public):
+                start = pending[0].start if pending else match.start()
+                owner.members.append(Declaration("method", name, pending, start, close, public=public, owner=owner))
+            if awaiting_body is None:
+                pending, public = [], False
+            pos = close
+        elif token == "{":
+            depth += 1
+            if awaiting_body is not None:
+                stack.append((awaiting_body, depth))
+                awaiting_body = None
+            pending, public = [], False
+        elif token == "}":
+            if stack and stack[-1][1] == depth:
+                stack.pop()
+            depth = max(depth - 1, 0)
+            pending, public = [], False
+        else:
+            if token == ";":
+                awaiting_body = None
+            pending, public = [], False
+    return classes
+
+
+@dataclass
+class _Policy:
+    """What an authorization policy requires."""
+
+    roles: list[tuple[str, ...]] = field(default_factory=list)  # Every entry must hold; any role within one
+    conditions: list[str] = field(default_factory=list)
+
+    def __add__(self, other: "_Policy") -> "_Policy":
+        """Policy requiring both."""
+        return _Policy(self.roles + other.roles, self.conditions + other.conditions)
+
+
+def parse_policy(body: str, constants: dict[str, str]) -> _Policy:
+    """Requirements of a policy builder lambda or AuthorizationPolicyBuilder chain.
+
+    Args:
+        body: Source of the builder, e.g. policy => policy.RequireRole("Admin").RequireClaim("scope", "write")
+        constants: String constants of the service
+
+    Returns:
+        The roles and other conditions the policy requires
+    """
+    code = _strip_comments(body)
+    masked = _mask_strings(code)
+    policy = _Policy()
+    pos = 0
+    while True:
+        match = REQUIREMENT.search(masked, pos)
+        if not match:
+            break
+        close = _close(masked, match.end() - 1)
+        name, args = match.group(1), code[match.end() : close - 1]
+        pos = close
+        values = _values(args, constants)
+        if name == "RequireRole" and values:
+            policy.roles.append(tuple(v.strip() for value in values for v in value.split(",") if v.strip()))
+        elif name == "RequireClaim" and values:
+            claim, allowed = values[0], values[1:]
+            policy.conditions.append(f"requires claim {claim}" + (f" = {' or '.join(allowed)}" if allowed else ""))
+        elif name == "RequireUserName" and values:
+            policy.conditions.append(f"user name is {values[0]}")
+        elif name == "RequireAssertion":
+            policy.conditions.append(f"passes assertion {' '.join(args.split())}")
+        elif name == "AddRequirements":
+            for requirement in _split_text(args):
+                policy.conditions.append(f"passes requirement {' '.join(requirement.removeprefix('new ').split())}")
+        elif name == "AddAuthenticationSchemes" and values:
+            policy.conditions.append(f"authenticated by scheme {' or '.join(values)}")
+    return policy
+
+
+@dataclass
+class AspNetContext:
+    """Constants and authorization policies shared across a service's files."""
+
+    constants: dict[str, str] = field(default_factory=dict)  # NAME and Class.NAME -> value
+    policies: dict[str, _Policy] = field(default_factory=dict)
+    default: _Policy | None = None  # DefaultPolicy, when replaced
+    fallback: _Policy | None = None  # FallbackPolicy, when set
+    conventions: list[tuple[_Policy, str]] = field(default_factory=list)  # RequireAuthorization() on MapControllers()
+
+
+def _policy_expression(expression: str, context: AspNetContext) -> _Policy | None:
+    """Policy of an assigned expression, resolving references to the default policy."""
+    if re.search(r"\bDefaultPolicy\b", expression) and not REQUIREMENT.search(expression):
+        return context.default or _Policy()
+    if expression.strip() == "null":
+        return None
+    return parse_policy(expression, context.constants)
+
+
+def _authorization_regions(masked: str) -> list[tuple[int, int]]:
+    """Spans of AddAuthorization option lambdas and AddAuthorizationBuilder chains, leaving CORS and rate limiter policies out."""
+    regions = []
+    for match in AUTHORIZATION_CALL.finditer(masked):
+        close = _close(masked, match.end() - 1)
+        if match.group(1) == "AddAuthorizationBuilder":
+            _, close = _chain(masked, masked, close)
+        regions.append((match.end(), close))
+    return regions
+
+
+def _collect_policies(text: str, context: AspNetContext) -> None:
+    """Add a file's AddAuthorization registrations to the context."""
+    code = _strip_comments(text)
+    masked = _mask_strings(code)
+    regions = _authorization_regions(masked)
+
+    def registered(offset: int) -> bool:
+        return any(start <= offset < end for start, end in regions)
+
+    for match in POLICY_REGISTRATION.finditer(masked):
+        if not registered(match.start()):
+            continue
+        close = _close(masked, match.end() - 1)
+        args = _split(masked, code, match.end(), close - 1)
+        method = match.group(1)
+        if method in ("SetDefaultPolicy", "SetFallbackPolicy"):
+            if not args:
+                continue
+            policy = _policy_expression(args[0], context)
+        else:
+            if len(args) < 2:
+                continue
+            policy = parse_policy(", ".join(args[1:]), context.constants)
+            name = _evaluate(args[0], context.constants)
+            if name is not None:
+                context.policies[name] = policy
+        if method in ("AddDefaultPolicy", "SetDefaultPolicy"):
+            context.default = policy
+        elif method in ("AddFallbackPolicy", "SetFallbackPolicy"):
+            context.fallback = policy
+    for match in POLICY_PROPERTY.finditer(masked):
+        if not registered(match.start()):
+            continue
+        end = masked.find(";", match.end())
+        end = len(masked) if end < 0 else end
+        policy = _policy_expression(code[match.end() : end], context)
+        if match.group(1) == "DefaultPolicy":
+            context.default = policy
+        else:
+            context.fallback = policy
+
+
+def build_context(files: dict[str, str]) -> AspNetContext:
+    """Collect string constants and registered authorization policies from a service's sources."""
+    context = AspNetContext()
+    for text in files.values():
+        code = _strip_comments(text)
+        for match in CONSTANT.finditer(code):
+            owner = None
+            for owner_match in CLASS_NAME.finditer(code, 0, match.start()):
+                owner = owner_match.group(1)
+            context.constants.setdefault(match.group(1), match.group(2))
+            if owner:
+                context.constants[f"{owner}.{match.group(1)}"] = match.group(2)
+    # Default policies must be known before fallbacks refer to them
+    for text in sorted(files.values(), key=lambda t: "FallbackPolicy" in t):
+        if "Policy" in text:
+            _collect_policies(text, context)
+    return context
+
+
+def authorize_policy(attribute: Attribute, context: AspNetContext) -> _Policy:
+    """Requirements of an [Authorize] attribute, resolving its policy name.
+
+    As in ASP.NET Core, the default policy applies when the attribute names
+    neither a policy nor roles.
+    """
+    constants = context.constants
+    name = attribute.argument(None, constants) or attribute.argument("Policy", constants)
+    roles = attribute.argument("Roles", constants)
+    schemes = attribute.argument("AuthenticationSchemes", constants)
+    policy = _Policy()
+    if name is None and roles is None:
+        policy += context.default or _Policy()
+    if name is not None:
+        registered = context.policies.get(name)
+        policy += registered or _Policy(conditions=[f"passes policy {name}, which no AddAuthorization call registers"])
+    if roles:
+        policy.roles.append(tuple(r.strip() for r in roles.split(",") if r.strip()))
+    if schemes:
+        policy.conditions.append(f"authenticated by scheme {' or '.join(s.strip() for s in schemes.split(','))}")
+    return policy
+
+
+def decide(requirements: list[tuple[_Policy, str]], anonymous: list[str], context: AspNetContext) -> Access | None:
+    """Combine an endpoint's authorization metadata into one access decision.
+
+    Args:
+        requirements: (policy, source) of every [Authorize] or RequireAuthorization() applying
+        anonymous: Sources of [AllowAnonymous] or AllowAnonymous() applying
+        context: Registered policies
+
+    Returns:
+        Access decision, or None for an endpoint no authorization applies to
+    """
+    if anonymous:
+        source = " and ".join(anonymous)
+        if requirements:
+            source += f", which overrides {' and '.join(s for _, s in requirements)}"
+        return Access(ANONYMOUS, source=source)
+    if not requirements:
+        if context.fallback is None:
+            return None
+        requirements = [(context.fallback, "the FallbackPolicy")]
+    roles = list(dict.fromkeys(r for policy, _ in requirements for r in policy.roles if r))
+    conditions = [c for policy, _ in requirements for c in policy.conditions]
+    conditions += [f"also holds role {' or '.join(r)}" for r in roles[1:]]
+    subject = " or ".join(roles[0]) if roles else AUTHENTICATED
+    return Access(subject, "; ".join(dict.fromkeys(conditions)) or None, " and ".join(s for _, s in requirements))
+
+
+def _metadata(declaration: Declaration, context: AspNetContext, suffix: str = "") -> tuple[list[tuple[_Policy, str]], list[str]]:
+    """([Authorize] requirements, [AllowAnonymous] sources) of a declaration."""
+    requirements = [(authorize_policy(a, context), a.source + suffix) for a in declaration.find("Authorize")]
+    return requirements, [a.source + suffix for a in declaration.find("AllowAnonymous")]
+
+
+def _template(attribute: Attribute | None, constants: dict[str, str]) -> str | None:
+    """Route template of a [Route] or [HttpGet] attribute, if it has one."""
+    if attribute is None:
+        return None
+    return attribute.argument(None, constants) or attribute.argument("template", constants)
+
+
+def _route_path(*parts: str | None) -> str:
+    """Join route templates, dropping parameter constraints and honouring "~/" and "/" overrides."""
+    joined = ""
+    for part in parts:
+        if part is None:
+            continue
+        if part.startswith(("/", "~/")):
+            joined = part.removeprefix("~")
+        else:
+            joined = f"{joined}/{part}"
+    return _join(ROUTE_PARAMETER.sub(lambda m: "{" + m.group(2) + "}", joined))
+
+
+def _is_controller(cls: Declaration) -> bool:
+    """Whether a class is an MVC or API controller."""
+    if cls.find("NonController"):
+        return False
+    return cls.name.endswith("Controller") or bool(cls.find("ApiController", "Controller")) or bool(
+        re.match(r"(?:\w+\.)*(?:Controller|ControllerBase)\b", cls.bases)
+    )
+
+
+def _finding(kind: str, file_path: str, text: str, start: int, end: int, resource: str, action: str, access: Access, what: str) -> ConfigFinding:
+    """Finding for one secured endpoint."""
+    line_start = line_of(text, start)
+    line_end = min(line_of(text, end), line_start + MAX_SNIPPET_LINES - 1)
+    return ConfigFinding(
+        kind=kind,
+        file_path=file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(text, line_start, line_end),
+        subject=access.subject,
+        resource=resource,
+        action=action,
+        conditions=access.conditions,
+        description=f"ASP.NET Core {what} secured by {access.source}",
+        disables_auth=access.subject == ANONYMOUS,
+    )
+
+
+def extract_controller_routes(file_path: str, text: str, context: AspNetContext) -> list[ConfigFinding]:
+    """Extract [Authorize] and [AllowAnonymous] access from controllers.
+
+    Actions with [HttpGet]-style or [Route] attributes combine their template
+    with the controller's [Route] prefix, replacing the [controller] and
+    [action] tokens; other public actions are reported under the
+    conventional /Controller/Action route.
+
+    Args:
+        file_path: Path of the source file
+        text: C# source
+        context: Constants and policies of the service
+
+    Returns:
+        One finding per authorized action route
+    """
+    findings = []
+    constants = context.constants
+    for cls in parse_declarations(text):
+        if not _is_controller(cls):
+            continue
+        controller = cls.name.removesuffix("Controller")
+        class_requirements, class_anonymous = _metadata(cls, context, f" on {cls.name}")
+        prefixes = [_template(a, constants) for a in cls.find("Route")] or [None]
+        for method in cls.members:
+            if not method.public or method.find("NonAction"):
+                continue
+            requirements, anonymous = _metadata(method, context)
+            access = decide(context.conventions + class_requirements + requirements, class_anonymous + anonymous, context)
+            if access is None:
+                continue
+            templates = [_template(a, constants) for a in method.find("Route")]
+            verbs = [(HTTP_ATTRIBUTES[a.name], _template(a, constants)) for a in method.attributes if a.name in HTTP_ATTRIBUTES]
+            if not verbs:
+                verbs = [("*", template) for template in templates] or [("*", None)]
+            elif templates:
+                # [HttpGet] without a template takes the action's [Route] template
+                verbs = [(verb, own or template) for verb, own in verbs for template in templates]
+            if prefixes == [None] and not any(template for _, template in verbs):
+                routes = [(verb, _route_path(controller, method.name)) for verb, _ in verbs]
+            else:
+                routes = [(verb, _route_path(prefix, template)) for prefix in prefixes for verb, template in verbs]
+            for verb, path in dict.fromkeys(routes):
+                path = path.replace("[controller]", controller).replace("[action]", method.name)
+                findings.append(
+                    _finding(
+                        AspNetRouteKind.CONTROLLER_ROUTE,
+                        file_path,
+                        text,
+                        method.start,
+                        method.end,
+                        path,
+                        verb,
+                        access,
+                        f"action {cls.name}.{method.name}",
+                    )
+                )
+    return findings
+
+
+@dataclass
+class _Group:
+    """A MapGroup route group and the metadata its endpoints inherit."""
+
+    prefix: str
+    requirements: list[tuple[_Policy, str]] = field(default_factory=list)
+    anonymous: list[str] = field(default_factory=list)
+
+
+def _endpoint_metadata(
+    call: str, args: str, context: AspNetContext, suffix: str = ""
+) -> tuple[list[tuple[_Policy, str]], list[str]]:
+    """Metadata of a .RequireAuthorization(...) or .AllowAnonymous() call."""
+    source = f".{call}({' '.join(args.split())}){suffix}"
+    if call == "AllowAnonymous":
+        return [], [source]
+    arguments = _split_text(args)
+    if not arguments:
+        return [(context.default or _Policy(), source)], []
+    requirements = []
+    for arg in arguments:
+        if "=>" in arg or re.match(r"new\s+AuthorizationPolicyBuilder\b", arg):
+            requirements.append((parse_policy(arg, context.constants), source))
+            continue
+        attribute = re.match(r"new\s+(?:[\w.]+\.)?AuthorizeAttribute\s*(?:\((.*?)\))?\s*(?:\{(.*)\})?$", arg, re.DOTALL)
+        if attribute:
+            data = ", ".join(part for part in attribute.groups() if part)
+            requirements.append((authorize_policy(Attribute("Authorize", data, source, 0), context), source))
+            continue
+        for name in _values(arg, context.constants):
+            registered = context.policies.get(name)
+            requirements.append(
+                (registered or _Policy(conditions=[f"passes policy {name}, which no AddAuthorization call registers"]), source)
+            )
+    return requirements, []
+
+
+def _chain(masked: str, code: str, pos: int) -> tuple[list[tuple[str, str]], int]:
+    """(method, arguments) of the calls chained after pos, and the offset past them."""
+    calls = []
+    while True:
+        match = CHAIN_CALL.match(masked, pos)
+        if not match:
+            return calls, pos
+        close = _close(masked, match.end() - 1)
+        calls.append((match.group(1), code[match.end() : close - 1]))
+        pos = close
+
+
+def _handler_attributes(args: list[str], context: AspNetContext) -> tuple[list[tuple[_Policy, str]], list[str]]:
+    """Metadata of attributes on a minimal API lambda handler."""
+    requirements, anonymous = [], []
+    for arg in args[1:]:
+        arg_code = _strip_comments(arg)
+        arg_masked = _mask_strings(arg_code)
+        for match in re.finditer(r"\[\s*(?=(?:[\w.]+\.)?(?:Authorize|AllowAnonymous)\b)", arg_masked):
+            attributes, _ = _attributes(arg_masked, arg_code, match.start())
+            declaration = Declaration("method", "handler", attributes, 0, 0)
+            found = _metadata(declaration, context)
+            requirements += found[0]
+            anonymous += found[1]
+    return requirements, anonymous
+
+
+def extract_minimal_api_routes(file_path: str, text: str, context: AspNetContext) -> list[ConfigFinding]:
+    """Extract RequireAuthorization() and AllowAnonymous() access from minimal API endpoints.
+
+    MapGroup groups assigned to variables pass their prefix and metadata on to
+    the endpoints and groups mapped on them. RequireAuthorization() on
+    MapControllers() is recorded in the context as a convention for every
+    controller action.
+
+    Args:
+        file_path: Path of the source file
+        text: C# source
+        context: Policies of the service; controller conventions are added to it
+
+    Returns:
+        One finding per authorized endpoint
+    """
+    code = _strip_comments(text)
+    masked = _mask_strings(code)
+    groups: dict[str, _Group] = {}
+    findings = []
+    pos = 0
+    while True:
+        match = ROUTE_CALL.search(masked, pos)
+        if not match:
+            break
+        receiver, method = match.group(1), match.group(2)
+        close = _close(masked, match.end() - 1)
+        args = _split(masked, code, match.end(), close - 1)
+        chain, pos = _chain(masked, code, close)
+        parent = groups.get(receiver, _Group(""))
+
+        if method in ("RequireAuthorization", "AllowAnonymous"):
+            # A statement adding metadata to an existing group
+            if receiver in groups:
+                for call, call_args in [(method, code[match.end() : close - 1])] + chain:
+                    if call in ("RequireAuthorization", "AllowAnonymous"):
+                        requirements, anonymous = _endpoint_metadata(call, call_args, context, f" on group {parent.prefix or '/'}")
+                        parent.requirements += requirements
+                        parent.anonymous += anonymous
+            continue
+
+        template = _evaluate(args[0], context.constants) if args else None
+        # Group prefixes always apply, unlike a controller [Route] prefix before an absolute template
+        path = _route_path(parent.prefix, template.lstrip("/") if template else None)
+        suffix = f" on group {path}" if method == "MapGroup" else ""
+        requirements, anonymous = [], []
+        for call, call_args in chain:
+            if call in ("RequireAuthorization", "AllowAnonymous"):
+                found = _endpoint_metadata(call, call_args, context, suffix)
+                requirements += found[0]
+                anonymous += found[1]
+
+        if method in CONTROLLER_MAPPINGS:
+            context.conventions += [(policy, f"{source} on {method}()") for policy, source in requirements]
+            continue
+        if template is None:
+            continue
+        if method == "MapGroup":
+            assigned = GROUP_ASSIGNMENT.search(masked[max(match.start() - 200, 0) : match.start()])
+            if assigned:
+                groups[assigned.group(1)] = _Group(path, parent.requirements + requirements, parent.anonymous + anonymous)
+            continue
+
+        handler_requirements, handler_anonymous = _handler_attributes(args, context)
+        access = decide(
+            parent.requirements + requirements + handler_requirements, parent.anonymous + anonymous + handler_anonymous, context
+        )
+        if access is None:
+            continue
+        verbs = [MAP_VERBS.get(method, "*")]
+        if method == "MapMethods" and len(args) > 1:
+            verbs = [v.upper() for v in _values(args[1], context.constants)] or ["*"]
+        for verb in verbs:
+            findings.append(
+                _finding(AspNetRouteKind.MINIMAL_API, file_path, text, match.start(), pos, path, verb, access, f"minimal API endpoint {method}")
+            )
+    return findings
+
+
+def is_aspnet_source(file_path: str) -> bool:
+    """Check whether a path may declare ASP.NET Core endpoints or policies."""
+    return PurePosixPath(file_path).suffix in ASPNET_SUFFIXES
+
+
+def extract_aspnet_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract ASP.NET Core controller and minimal API authorization from a service's files.
+
+    Args:
+        files: Relative path -> content; non-C# files are ignored
+
+    Returns:
+        Findings across all files
+    """
+    sources = {p: t for p, t in files.items() if is_aspnet_source(p)}
+    # Role and policy name constants often live in plain classes without ASP.NET imports
+    context = build_context(sources)
+    sources = {p: t for p, t in sources.items() if ASPNET_MARKER.search(t)}
+    findings = []
+    # Minimal API files run first so MapControllers() conventions reach every controller
+    for file_path, text in sources.items():
+        findings.extend(extract_minimal_api_routes(file_path, text, context))
+    for file_path, text in sources.items():
+        findings.extend(extract_controller_routes(file_path, text, context))
+    return findings
@@ -0,0 +1,347 @@
+"""Service for reporting registered routes with no detectable authentication or authorization.
+
+Coverage metrics count unprotected endpoints; fixing them needs the list,
+in the order to work through it, with the registration to change. This
+service reads every route registration in a repository's clone and
+checks each for protection the miner can see: a mined policy requiring
+authentication, auth middleware or a guard on the registration itself
+(RequireAuth(handler), @login_required, [Authorize], @PreAuthorize), a
+guard on the enclosing controller, or auth middleware mounted earlier in
+the file (r.Use(AuthMiddleware), app.use(passport.authenticate(...))).
+Routes with none are gaps, prioritized by the endpoint risk model's data
+sensitivity and exposure scores, with the registration line as evidence.
+Routes explicitly marked public ([AllowAnonymous], @PermitAll, AllowAny)
+are counted but not reported, and conventionally public paths such as
+health checks and login are reported at low severity.
+"""
+
+import re
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass, field
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.repository import Repository
+from app.services.bola_detection_service import DECORATOR_LINE, HEADER_LINES, SEVERITY_ORDER
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, ROUTE_FILE_NAMES, SKIPPED_DIRECTORIES, route_key
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+from app.services.endpoint_risk_service import EndpointRiskService, FindingHistory
+from app.services.exposure_service import ExposureService, describe_exposure, resolve_exposure
+from app.services.extractor_utils import MAX_LINE_LENGTH
+
+logger = structlog.get_logger(__name__)
+
+# Authentication or authorization named on a registration, decorator, or attribute
+GUARD = re.compile(
+    r"\b(?:Require[A-Z]\w*|require(?:[A-Z]\w*|_\w+)|[Aa]uthenticat\w*|[Aa]uthoriz\w*|auth[A-Z_]\w*|[Aa]uth(?:Middleware|Guard|Required)\b|"
+    r"login_required|permission_required|user_passes_test|staff_member_required|[Jj]wt\w*|JWT\w*|[Pp]rotect(?:ed)?\w*|"
+    r"[Ee]nsure\w*(?:Auth|Logged|Role|Permission)\w*|isAuthenticated|is_authenticated|verify_?[Tt]oken\w*|checkJwt|passport\s*\.\s*\w+|"
+    r"PreAuthorize|PostAuthorize|Secured|RolesAllowed|RequireAuthorization|UseGuards|has(?:Any)?Role|has_?permission\w*|"
+    r"Depends\s*\(\s*\w*(?:current_user|auth|user|token|principal)\w*|Security\s*\(|permission_classes|before_action\s+:(?:authenticate|require|authorize)\w*)"
+)
+# Routes deliberately open to anonymous callers
+PUBLIC_MARKER = re.compile(
+    r"\[\s*AllowAnonymous\s*\]|@(?:PermitAll|AllowAnonymous|Public|public_route|public)\b|\bAllowAny\b|\bpermitAll\s*\(\s*\)|"
+    r"\bskip_before_action\s+:authenticate\w*|\bisPublic\s*:\s*true|\bauth\s*:\s*false\b|\bAllowAnonymous\s*\(\s*\)"
+)
+# Middleware mounted on a router or app, guarding the routes registered after it
+MOUNTED_GUARD = re.compile(
+    r"\.\s*(?:Use|use|UseMiddleware|Group|group|before_request|addFilter\w*)\s*\([^\n]{0,200}?"
+    r"(?:[Aa]uth|[Jj]wt|JWT|[Tt]oken|[Pp]assport|[Ll]ogin|[Pp]rotect|[Gg]uard|[Ss]ession)(?!\w*(?:Router|Routes|Controller|Handler)\b)"
+)
+STRING_LITERAL = re.compile(r"""(['"`])[^'"`\n]*\1""")
+CLASS_DECLARATION = re.compile(r"^\s*(?:export\s+)?(?:public\s+|internal\s+)?(?:abstract\s+|sealed\s+|partial\s+)*class\s+\w+")
+# Paths open by convention: probes, docs, static files, and the sign-in flow itself
+CONVENTIONALLY_PUBLIC = re.compile(
+    r"^/(?:[\w.-]+/)*?(?:health\w*|ready\w*|live\w*|ping|metrics|status|version|docs?|swagger[\w.-]*|openapi[\w.-]*|"
+    r"redoc|static|assets|public|favicon[\w.]*|robots\.txt|login|logout|sign_?in|sign_?up|register|oauth\w*|callback|\.well-known)(?:/|$)",
+    re.IGNORECASE,
+)
+
+
+class Protection:
+    """How a registered route is protected."""
+
+    POLICY = "policy"  # A mined policy requires authentication
+    REGISTRATION = "registration"  # Guard on the registration, or decorators and attributes on its handler
+    CONTROLLER = "controller"  # Guard on the enclosing class
+    MIDDLEWARE = "middleware"  # Auth middleware mounted earlier in the file
+    PUBLIC = "public"  # Explicitly marked public
+
+
+@dataclass
+class RouteRegistration:
+    """A route registered in source, with how it is protected."""
+
+    method: str
+    path: str
+    line: int
+    code: str
+    protection: str | None = None  # One of Protection, or None when nothing protects it
+    evidence_line: int | None = None  # Line of the guard or marker
+
+
+@dataclass
+class AuthGap:
+    """A registered route with no detectable authentication or authorization."""
+
+    endpoint: str
+    method: str
+    path: str
+    file_path: str
+    line: int
+    code: str
+    severity: str
+    priority: float  # Endpoint risk score of the route as anonymous, 0-100
+    exposure: str
+    conventionally_public: bool
+    reasons: list[str]
+    description: str
+    policy_ids: list[int] = field(default_factory=list)
+
+
+def _short(code: str) -> str:
+    """Code on one line, shortened for evidence."""
+    text = " ".join(code.split())
+    return text if len(text) <= 160 else text[:157] + "..."
+
+
+def _annotations(lines: list[str], index: int) -> range:
+    """Indexes of a registration line and the decorator and attribute lines around it."""
+    first = index
+    while first > max(0, index - HEADER_LINES) and DECORATOR_LINE.match(lines[first - 1]) and lines[first - 1].strip():
+        first -= 1
+    last = index + 1
+    while last < min(len(lines), index + 1 + HEADER_LINES) and lines[last].lstrip().startswith(("@", "[")):
+        last += 1
+    return range(first, last)
+
+
+def route_registrations(text: str) -> list[RouteRegistration]:
+    """Route registrations in a source file, with the protection visible in the file.
+
+    Args:
+        text: File content
+
+    Returns:
+        Registrations in line order
+    """
+    routes = EndpointMappingService.find_routes(text)
+    if not routes:
+        return []
+    lines = text.split("\n")
+    located: dict[tuple[str, str], int] = {}
+    for index, line in enumerate(lines):
+        if len(line) <= MAX_LINE_LENGTH and "/" in line:
+            for route in EndpointMappingService.find_routes(line):
+                located.setdefault(route, index)
+
+    # Paths in string literals ("/auth/login") are not guards
+    code = [STRING_LITERAL.sub('""', line) if len(line) <= MAX_LINE_LENGTH else "" for line in lines]
+    mounted = [i for i, line in enumerate(code) if MOUNTED_GUARD.search(line)]
+    guarded_classes = []
+    for index, line in enumerate(lines):
+        if len(line) <= MAX_LINE_LENGTH and CLASS_DECLARATION.match(line):
+            header = [i for i in _annotations(lines, index) if i < index]
+            guard = next((i for i in header if GUARD.search(code[i])), None)
+            guarded_classes.append((index, guard))
+
+    registrations = []
+    for method, path in routes:
+        index = located.get((method, path))
+        if index is None:
+            # Registrations spanning lines (JAX-RS @GET above @Path) are placed at their path
+            quoted = (f'"{path}"', f'"{path.lstrip("/")}"')
+            index = next((i for i, line in enumerate(lines) if len(line) <= MAX_LINE_LENGTH and any(q in line for q in quoted)), 0)
+        registration = RouteRegistration(method, path, index + 1, _short(lines[index]))
+        window = _annotations(lines, index)
+        marker = next((i for i in window if PUBLIC_MARKER.search(code[i])), None)
+        guard = next((i for i in window if GUARD.search(code[i])), None)
+        enclosing = next((c for c in reversed(guarded_classes) if c[0] < index), None)
+        earlier = next((i for i in reversed(mounted) if i < index), None)
+        if marker is not None:
+            registration.protection, registration.evidence_line = Protection.PUBLIC, marker + 1
+        elif guard is not None:
+            registration.protection, registration.evidence_line = Protection.REGISTRATION, guard + 1
+        elif enclosing and enclosing[1] is not None:
+            registration.protection, registration.evidence_line = Protection.CONTROLLER, enclosing[1] + 1
+        elif earlier is not None:
+            registration.protection, registration.evidence_line = Protection.MIDDLEWARE, earlier + 1
+        registrations.append(registration)
+    return sorted(registrations, key=lambda r: r.line)
+
+
+def registered_routes(root: str) -> Iterator[list]:
+    """Sandbox pass yielding [relative path, registrations] for each file of a clone registering routes."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in ROUTE_FILE_EXTENSIONS and path.name not in ROUTE_FILE_NAMES:
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        registrations = route_registrations(path.read_text(encoding="utf-8", errors="ignore"))
+        if registrations:
+            yield [relative.as_posix(), [asdict(r) for r in registrations]]
+
+
+class AuthGapService:
+    """Reports registered routes without detectable authentication or authorization."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> dict[str, list[RouteRegistration]]:
+        """Route registrations across a repository clone, read in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Registrations per relative file path
+        """
+        return {
+            file_path: [RouteRegistration(**r) for r in registrations]
+            for file_path, registrations in run_pass(root, registered_routes)
+        }
+
+    def gaps(self, repository_id: int | None = None, severity: str | None = None) -> dict:
+        """Registered routes with no detectable authentication or authorization, highest priority first.
+
+        Args:
+            repository_id: Restrict to one repository
+            severity: Keep only this severity
+
+        Returns:
+            Gaps with their registration evidence, and counts of routes by protection
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        risk = EndpointRiskService(self.db, self.tenant_id, clone_dir=str(self.clone_dir))
+        results, scanned = [], 0
+        by_protection: dict[str, int] = {}
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            if not root.is_dir():
+                continue
+            scanned += 1
+            found, protections = self.scan_repository(repository, root, risk)
+            for name, count in protections.items():
+                by_protection[name] = by_protection.get(name, 0) + count
+            logger.info("auth_gaps_detected", repository_id=repository.id, gaps=len(found))
+            results.extend({**asdict(g), "repository_id": repository.id, "repository_name": repository.name} for g in found)
+
+        if severity is not None:
+            results = [r for r in results if r["severity"] == severity.lower()]
+        results.sort(key=lambda r: (SEVERITY_ORDER[r["severity"]], -r["priority"], r["repository_id"], r["file_path"], r["line"]))
+
+        by_severity: dict[str, int] = {}
+        for result in results:
+            by_severity[result["severity"]] = by_severity.get(result["severity"], 0) + 1
+        return {
+            "gaps": results,
+            "summary": {
+                "routes": sum(by_protection.values()),
+                "gaps": len(results),
+                "by_protection": by_protection,
+                "by_severity": by_severity,
+                "repositories_scanned": scanned,
+            },
+        }
+
+    def routes(self, repository: Repository, root: Path) -> list[tuple[str, RouteRegistration, EndpointRule | None]]:
+        """Each route of one cloned repository once, with its mined rule and protection.
+
+        A registration's protection becomes POLICY when a mined policy on its
+        route requires authentication and the route is not marked public.
+
+        Returns:
+            (file path, registration, mapped rule) per route
+        """
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository.id)
+        rules = {route_key(r.method, r.path): r for r in EndpointMappingService.map_policies(policies)}
+        routes, seen = [], set()
+        for file_path, registrations in self.scan_clone(root).items():
+            for registration in registrations:
+                key = route_key(registration.method, registration.path)
+                if key in seen:
+                    continue
+                seen.add(key)
+                rule = rules.get(key)
+                if rule and rule.requires_authentication and registration.protection != Protection.PUBLIC:
+                    registration.protection = Protection.POLICY
+                routes.append((file_path, registration, rule))
+        return routes
+
+    def scan_repository(self, repository: Repository, root: Path, risk: EndpointRiskService) -> tuple[list[AuthGap], dict[str, int]]:
+        """Gaps of one cloned repository, and how many of its routes each protection covers."""
+        signals = ExposureService.scan_clone(root)
+        gaps, protections = [], {}
+        for file_path, registration, rule in self.routes(repository, root):
+            name = registration.protection or "none"
+            protections[name] = protections.get(name, 0) + 1
+            if registration.protection is None:
+                gaps.append(self._gap(file_path, registration, rule, signals, risk))
+        return gaps, protections
+
+    @staticmethod
+    def _gap(file_path: str, registration: RouteRegistration, rule: EndpointRule | None, signals: list, risk: EndpointRiskService) -> AuthGap:
+        """A gap for an unprotected registration, scored as the anonymous endpoint it is."""
+        anonymous = EndpointRule(
+            method=registration.method,
+            path=registration.path,
+            requires_authentication=False,
+            resource=rule.resource if rule else "",
+            policy_ids=rule.policy_ids if rule else [],
+        )
+        exposure, signal = resolve_exposure(registration.path, signals)
+        scored = risk.score_rule(anonymous, FindingHistory(), set(), exposure, describe_exposure(exposure, signal))
+        conventional = bool(CONVENTIONALLY_PUBLIC.match(registration.path))
+        # Internet-facing gaps move up a level, internal-only ones down
+        severity = "low" if conventional else risk.weighted_severity(scored.level, exposure.value)
+        reasons = ["mined policy admits anonymous callers" if rule else "no mined policy", "no guard on the registration or its handler"]
+        description = (
+            f"{registration.method} {registration.path} is registered at {file_path}:{registration.line} "
+            "with no authentication or authorization check the miner can detect"
+            + (" (path is conventionally public; confirm it is meant to be)" if conventional else "")
+        )
+        return AuthGap(
+            endpoint=f"{registration.method} {registration.path}",
+            method=registration.method,
+            path=registration.path,
+            file_path=file_path,
+            line=registration.line,
+            code=registration.code,
+            severity=severity,
+            priority=scored.score,
+            exposure=exposure.value,
+            conventionally_public=conventional,
+            reasons=reasons + scored.factors,
+            description=description,
+            policy_ids=anonymous.policy_ids,
+        )
@@ -0,0 +1,697 @@
+"""Service for inventorying the auth libraries each service depends on.
+
+Which authentication and authorization libraries a service uses, and at
+which versions, is recorded in its dependency manifests rather than in the
+code the scan reads: package.json and its lockfile, requirements files and
+pyproject.toml, Maven and Gradle builds, go.mod, Gemfiles, .csproj package
+references, composer.json, and CycloneDX or SPDX SBOMs. This service reads
+those from each clone, matches the dependencies against a catalog of auth
+libraries (Spring Security, Passport, Casbin, jose, and the like), flags
+deprecated packages and versions below a known-safe floor, and rolls the
+usages up per library and per category so platform teams can see where
+services have standardized and which still run libraries slated for removal.
+"""
+
+import json
+import re
+import tomllib
+from collections import defaultdict
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass
+from enum import Enum
+from pathlib import Path, PurePosixPath
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.repository import Repository
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+
+logger = structlog.get_logger(__name__)
+
+
+class Ecosystem(str, Enum):
+    """Package ecosystems manifests are read for."""
+
+    NPM = "npm"
+    PYPI = "pypi"
+    MAVEN = "maven"
+    GO = "go"
+    RUBYGEMS = "rubygems"
+    NUGET = "nuget"
+    COMPOSER = "composer"
+
+
+class LibraryCategory(str, Enum):
+    """What an auth library does."""
+
+    AUTHENTICATION = "authentication"  # Sign-in frameworks and middleware
+    TOKEN = "token"  # JWT, JOSE, and token verification
+    FEDERATION = "federation"  # OAuth 2.0, OpenID Connect, and SAML clients
+    AUTHORIZATION = "authorization"  # Role and permission frameworks
+    POLICY_ENGINE = "policy_engine"  # External or embedded policy engines
+
+
+@dataclass(frozen=True)
+class AuthLibrary:
+    """A catalog entry: an auth library and the packages it ships as."""
+
+    name: str
+    ecosystem: Ecosystem
+    category: LibraryCategory
+    packages: tuple[str, ...]
+    deprecated: str | None = None  # Why and what replaces it, when the whole library is deprecated
+    minimum_version: str | None = None  # Versions below are flagged
+    minimum_reason: str | None = None
+
+
+AUTH_LIBRARIES = [
+    AuthLibrary("passport", Ecosystem.NPM, LibraryCategory.AUTHENTICATION, ("passport",)),
+    AuthLibrary("passport-jwt", Ecosystem.NPM, LibraryCategory.TOKEN, ("passport-jwt",)),
+    AuthLibrary("passport-azure-ad", Ecosystem.NPM, LibraryCategory.FEDERATION, ("passport-azure-ad",), deprecated="archived by Microsoft; use @azure/msal-node"),
+    AuthLibrary("jsonwebtoken", Ecosystem.NPM, LibraryCategory.TOKEN, ("jsonwebtoken",), minimum_version="9.0.0", minimum_reason="versions before 9.0.0 accept insecure keys and algorithms (CVE-2022-23539, CVE-2022-23540)"),
+    AuthLibrary("jose", Ecosystem.NPM, LibraryCategory.TOKEN, ("jose",)),
+    AuthLibrary("express-jwt", Ecosystem.NPM, LibraryCategory.TOKEN, ("express-jwt",), minimum_version="6.0.0", minimum_reason="versions before 6.0.0 do not require the algorithms option (CVE-2020-15084)"),
+    AuthLibrary("jwks-rsa", Ecosystem.NPM, LibraryCategory.TOKEN, ("jwks-rsa",)),
+    AuthLibrary("express-session", Ecosystem.NPM, LibraryCategory.AUTHENTICATION, ("express-session", "cookie-session")),
+    AuthLibrary("NestJS auth", Ecosystem.NPM, LibraryCategory.AUTHENTICATION, ("@nestjs/passport", "@nestjs/jwt")),
+    AuthLibrary("openid-client", Ecosystem.NPM, LibraryCategory.FEDERATION, ("openid-client", "express-openid-connect")),
+    AuthLibrary("MSAL", Ecosystem.NPM, LibraryCategory.FEDERATION, ("@azure/msal-node", "@azure/msal-browser")),
+    AuthLibrary("ADAL", Ecosystem.NPM, LibraryCategory.FEDERATION, ("adal-node", "adal-angular"), deprecated="end of support since June 2023; use MSAL"),
+    AuthLibrary("next-auth", Ecosystem.NPM, LibraryCategory.AUTHENTICATION, ("next-auth", "@auth/core")),
+    AuthLibrary("Keycloak adapter", Ecosystem.NPM, LibraryCategory.FEDERATION, ("keycloak-connect",), deprecated="deprecated by Keycloak; use a standard OpenID Connect client"),
+    AuthLibrary("CASL", Ecosystem.NPM, LibraryCategory.AUTHORIZATION, ("@casl/ability",)),
+    AuthLibrary("accesscontrol", Ecosystem.NPM, LibraryCategory.AUTHORIZATION, ("accesscontrol",)),
+    AuthLibrary("Casbin", Ecosystem.NPM, LibraryCategory.POLICY_ENGINE, ("casbin",)),
+    AuthLibrary("Oso", Ecosystem.NPM, LibraryCategory.POLICY_ENGINE, ("oso", "oso-cloud")),
+    AuthLibrary("OpenFGA", Ecosystem.NPM, LibraryCategory.POLICY_ENGINE, ("@openfga/sdk",)),
+    AuthLibrary("Cerbos", Ecosystem.NPM, LibraryCategory.POLICY_ENGINE, ("@cerbos/grpc", "@cerbos/http")),
+    AuthLibrary("PyJWT", Ecosystem.PYPI, LibraryCategory.TOKEN, ("pyjwt",), minimum_version="2.4.0", minimum_reason="versions before 2.4.0 allow algorithm confusion with public keys (CVE-2022-29217)"),
+    AuthLibrary("python-jose", Ecosystem.PYPI, LibraryCategory.TOKEN, ("python-jose",), deprecated="unmaintained, with open algorithm confusion issues (CVE-2024-33663); use PyJWT or joserfc"),
+    AuthLibrary("Authlib", Ecosystem.PYPI, LibraryCategory.FEDERATION, ("authlib",)),
+    AuthLibrary("oauth2client", Ecosystem.PYPI, LibraryCategory.FEDERATION, ("oauth2client",), deprecated="deprecated by Google; use google-auth"),
+    AuthLibrary("google-auth", Ecosystem.PYPI, LibraryCategory.FEDERATION, ("google-auth",)),
+    AuthLibrary("Flask-Login", Ecosystem.PYPI, LibraryCategory.AUTHENTICATION, ("flask-login",)),
+    AuthLibrary("Flask-JWT", Ecosystem.PYPI, LibraryCategory.TOKEN, ("flask-jwt",), deprecated="unmaintained; use Flask-JWT-Extended"),
+    AuthLibrary("Flask-JWT-Extended", Ecosystem.PYPI, LibraryCategory.TOKEN, ("flask-jwt-extended",)),
+    AuthLibrary("djangorestframework-jwt", Ecosystem.PYPI, LibraryCategory.TOKEN, ("djangorestframework-jwt",), deprecated="unmaintained; use djangorestframework-simplejwt"),
+    AuthLibrary("djangorestframework-simplejwt", Ecosystem.PYPI, LibraryCategory.TOKEN, ("djangorestframework-simplejwt",)),
+    AuthLibrary("django-allauth", Ecosystem.PYPI, LibraryCategory.AUTHENTICATION, ("django-allauth",)),
+    AuthLibrary("django-guardian", Ecosystem.PYPI, LibraryCategory.AUTHORIZATION, ("django-guardian",)),
+    AuthLibrary("MSAL", Ecosystem.PYPI, LibraryCategory.FEDERATION, ("msal",)),
+    AuthLibrary("ADAL", Ecosystem.PYPI, LibraryCategory.FEDERATION, ("adal",), deprecated="end of support since June 2023; use MSAL"),
+    AuthLibrary("Casbin", Ecosystem.PYPI, LibraryCategory.POLICY_ENGINE, ("casbin", "pycasbin")),
+    AuthLibrary("Oso", Ecosystem.PYPI, LibraryCategory.POLICY_ENGINE, ("oso", "oso-cloud")),
+    AuthLibrary("OpenFGA", Ecosystem.PYPI, LibraryCategory.POLICY_ENGINE, ("openfga-sdk",)),
+    AuthLibrary("Cerbos", Ecosystem.PYPI, LibraryCategory.POLICY_ENGINE, ("cerbos",)),
+    AuthLibrary("Spring Authorization Server", Ecosystem.MAVEN, LibraryCategory.FEDERATION, ("org.springframework.security:spring-security-oauth2-authorization-server",)),
+    AuthLibrary("Spring Security", Ecosystem.MAVEN, LibraryCategory.AUTHENTICATION, ("org.springframework.security:*", "org.springframework.boot:spring-boot-starter-security")),
+    AuthLibrary("Spring Security OAuth", Ecosystem.MAVEN, LibraryCategory.FEDERATION, ("org.springframework.security.oauth:*",), deprecated="end of life; use the OAuth 2.0 support in Spring Security 5.2+"),
+    AuthLibrary("Keycloak adapter", Ecosystem.MAVEN, LibraryCategory.FEDERATION, ("org.keycloak:keycloak-spring-boot-starter", "org.keycloak:keycloak-spring-security-adapter"), deprecated="deprecated by Keycloak; use Spring Security's OAuth 2.0 support"),
+    AuthLibrary("Apache Shiro", Ecosystem.MAVEN, LibraryCategory.AUTHORIZATION, ("org.apache.shiro:*",)),
+    AuthLibrary("jjwt", Ecosystem.MAVEN, LibraryCategory.TOKEN, ("io.jsonwebtoken:*",)),
+    AuthLibrary("java-jwt", Ecosystem.MAVEN, LibraryCategory.TOKEN, ("com.auth0:java-jwt",)),
+    AuthLibrary("Nimbus JOSE+JWT", Ecosystem.MAVEN, LibraryCategory.TOKEN, ("com.nimbusds:nimbus-jose-jwt",)),
+    AuthLibrary("pac4j", Ecosystem.MAVEN, LibraryCategory.AUTHENTICATION, ("org.pac4j:*",)),
+    AuthLibrary("Casbin", Ecosystem.MAVEN, LibraryCategory.POLICY_ENGINE, ("org.casbin:*",)),
+    AuthLibrary("Quarkus security", Ecosystem.MAVEN, LibraryCategory.AUTHENTICATION, ("io.quarkus:quarkus-oidc", "io.quarkus:quarkus-smallrye-jwt", "io.quarkus:quarkus-security")),
+    AuthLibrary("Micronaut security", Ecosystem.MAVEN, LibraryCategory.AUTHENTICATION, ("io.micronaut.security:*",)),
+    AuthLibrary("golang-jwt", Ecosystem.GO, LibraryCategory.TOKEN, ("github.com/golang-jwt/jwt", "github.com/golang-jwt/jwt/v4", "github.com/golang-jwt/jwt/v5")),
+    AuthLibrary("jwt-go", Ecosystem.GO, LibraryCategory.TOKEN, ("github.com/dgrijalva/jwt-go",), deprecated="unmaintained, with an unfixed audience check bypass (CVE-2020-26160); use github.com/golang-jwt/jwt"),
+    AuthLibrary("go-jose", Ecosystem.GO, LibraryCategory.TOKEN, ("github.com/go-jose/go-jose/v3", "github.com/go-jose/go-jose/v4", "gopkg.in/square/go-jose.v2")),
+    AuthLibrary("lestrrat-go/jwx", Ecosystem.GO, LibraryCategory.TOKEN, ("github.com/lestrrat-go/jwx", "github.com/lestrrat-go/jwx/v2")),
+    AuthLibrary("go-oidc", Ecosystem.GO, LibraryCategory.FEDERATION, ("github.com/coreos/go-oidc", "github.com/coreos/go-oidc/v3")),
+    AuthLibrary("golang.org/x/oauth2", Ecosystem.GO, LibraryCategory.FEDERATION, ("golang.org/x/oauth2",)),
+    AuthLibrary("Casbin", Ecosystem.GO, LibraryCategory.POLICY_ENGINE, ("github.com/casbin/casbin", "github.com/casbin/casbin/v2")),
+    AuthLibrary("Open Policy Agent", Ecosystem.GO, LibraryCategory.POLICY_ENGINE, ("github.com/open-policy-agent/opa",)),
+    AuthLibrary("OpenFGA", Ecosystem.GO, LibraryCategory.POLICY_ENGINE, ("github.com/openfga/go-sdk",)),
+    AuthLibrary("SpiceDB", Ecosystem.GO, LibraryCategory.POLICY_ENGINE, ("github.com/authzed/authzed-go",)),
+    AuthLibrary("Devise", Ecosystem.RUBYGEMS, LibraryCategory.AUTHENTICATION, ("devise",)),
+    AuthLibrary("OmniAuth", Ecosystem.RUBYGEMS, LibraryCategory.FEDERATION, ("omniauth",), minimum_version="2.0.0", minimum_reason="versions before 2.0.0 accept GET requests to the request phase (CVE-2015-9284)"),
+    AuthLibrary("Doorkeeper", Ecosystem.RUBYGEMS, LibraryCategory.FEDERATION, ("doorkeeper",)),
+    AuthLibrary("ruby-jwt", Ecosystem.RUBYGEMS, LibraryCategory.TOKEN, ("jwt",)),
+    AuthLibrary("Pundit", Ecosystem.RUBYGEMS, LibraryCategory.AUTHORIZATION, ("pundit",)),
+    AuthLibrary("CanCanCan", Ecosystem.RUBYGEMS, LibraryCategory.AUTHORIZATION, ("cancancan",)),
+    AuthLibrary("CanCan", Ecosystem.RUBYGEMS, LibraryCategory.AUTHORIZATION, ("cancan",), deprecated="unmaintained; use CanCanCan"),
+    AuthLibrary("Rolify", Ecosystem.RUBYGEMS, LibraryCategory.AUTHORIZATION, ("rolify",)),
+    AuthLibrary("ASP.NET Core authentication", Ecosystem.NUGET, LibraryCategory.AUTHENTICATION, ("Microsoft.AspNetCore.Authentication.*", "Microsoft.AspNetCore.Identity.*")),
+    AuthLibrary("Microsoft.Identity.Web", Ecosystem.NUGET, LibraryCategory.FEDERATION, ("Microsoft.Identity.Web", "Microsoft.Identity.Client")),
+    AuthLibrary("ADAL", Ecosystem.NUGET, LibraryCategory.FEDERATION, ("Microsoft.IdentityModel.Clients.ActiveDirectory",), deprecated="end of support since June 2023; use MSAL (Microsoft.Identity.Client)"),
+    AuthLibrary("System.IdentityModel.Tokens.Jwt", Ecosystem.NUGET, LibraryCategory.TOKEN, ("System.IdentityModel.Tokens.Jwt", "Microsoft.IdentityModel.JsonWebTokens")),
+    AuthLibrary("IdentityServer4", Ecosystem.NUGET, LibraryCategory.FEDERATION, ("IdentityServer4", "IdentityServer4.*"), deprecated="end of support since December 2022; use Duende IdentityServer or another OpenID Connect provider"),
+    AuthLibrary("Casbin", Ecosystem.NUGET, LibraryCategory.POLICY_ENGINE, ("Casbin.NET",)),
+    AuthLibrary("firebase/php-jwt", Ecosystem.COMPOSER, LibraryCategory.TOKEN, ("firebase/php-jwt",), minimum_version="6.0.0", minimum_reason="versions before 6.0.0 allow algorithm confusion when keys are not bound to an algorithm (CVE-2021-46743)"),
+    AuthLibrary("Laravel Passport", Ecosystem.COMPOSER, LibraryCategory.FEDERATION, ("laravel/passport",)),
+    AuthLibrary("Laravel Sanctum", Ecosystem.COMPOSER, LibraryCategory.AUTHENTICATION, ("laravel/sanctum",)),
+    AuthLibrary("Symfony Security", Ecosystem.COMPOSER, LibraryCategory.AUTHENTICATION, ("symfony/security-bundle", "symfony/security-core")),
+    AuthLibrary("spatie/laravel-permission", Ecosystem.COMPOSER, LibraryCategory.AUTHORIZATION, ("spatie/laravel-permission",)),
+    AuthLibrary("Casbin", Ecosystem.COMPOSER, LibraryCategory.POLICY_ENGINE, ("casbin/casbin",)),
+]
+
+# Package URL type -> ecosystem, for SBOM components
+PURL_TYPES = {
+    "npm": Ecosystem.NPM,
+    "pypi": Ecosystem.PYPI,
+    "maven": Ecosystem.MAVEN,
+    "golang": Ecosystem.GO,
+    "gem": Ecosystem.RUBYGEMS,
+    "nuget": Ecosystem.NUGET,
+    "composer": Ecosystem.COMPOSER,
+}
+
+REQUIREMENT = re.compile(r"^\s*([A-Za-z0-9][\w.-]*)\s*(?:\[[^\]]*\])?\s*(?:\(?\s*((?:[<>=!~]=?|===)\s*[^;#\s,)]+(?:\s*,\s*[<>=!~]=?\s*[^;#\s,)]+)*))?")
+POM_DEPENDENCY = re.compile(r"<dependency>(.*?)</dependency>", re.DOTALL)
+POM_PROPERTIES = re.compile(r"<properties>(.*?)</properties>", re.DOTALL)
+XML_ELEMENT = re.compile(r"<([\w.-]+)>\s*([^<]*?)\s*</\1>")
+PROPERTY_REFERENCE = re.compile(r"\$\{([^}]+)\}")
+GRADLE_DEPENDENCY = re.compile(r"""\b(?:implementation|api|compile|compileOnly|runtimeOnly|testImplementation)\s*\(?\s*(['"])([\w.-]+):([\w.-]+)(?::([^'"@]+))?\1""")
+GO_REQUIRE = re.compile(r"^\s*(?:require\s+)?([\w.-]+\.[\w.-]+/\S+)\s+(v[\w.+-]+)", re.MULTILINE)
+GEMFILE_GEM = re.compile(r"""^\s*gem\s+['"]([\w.-]+)['"](?:\s*,\s*['"]([^'"]+)['"])?""", re.MULTILINE)
+GEMFILE_LOCK_SPEC = re.compile(r"^    ([\w.-]+) \(([^)]+)\)$", re.MULTILINE)
+PACKAGE_REFERENCE = re.compile(r"""<PackageReference\s+Include\s*=\s*"([^"]+)"(?:\s+Version\s*=\s*"([^"]+)")?""", re.IGNORECASE)
+VERSION_NUMBER = re.compile(r"\d+(?:\.\d+)*")
+# Manifests whose dependencies are the service's own, rather than resolved transitively
+MANIFEST_NAMES = {
+    "package.json",
+    "package-lock.json",
+    "requirements.txt",
+    "pyproject.toml",
+    "pom.xml",
+    "build.gradle",
+    "build.gradle.kts",
+    "go.mod",
+    "Gemfile",
+    "Gemfile.lock",
+    "composer.json",
+    "bom.json",
+}
+SBOM_SUFFIXES = (".cdx.json", ".spdx.json", ".bom.json")
+
+
+@dataclass
+class Dependency:
+    """A package a manifest or SBOM declares."""
+
+    package: str
+    ecosystem: Ecosystem
+    version: str | None  # Resolved or declared version, without range operators
+    declared: str | None  # Version specification as written
+    file_path: str
+    line: int | None
+    direct: bool = True  # False for packages only a lockfile or SBOM names
+    locked: bool = False  # The version is exact, from a lockfile or SBOM
+
+
+def is_manifest(file_path: str) -> bool:
+    """Check whether a path is a dependency manifest, lockfile, or SBOM."""
+    name = PurePosixPath(file_path).name
+    return (
+        name in MANIFEST_NAMES
+        or name.endswith(SBOM_SUFFIXES + (".csproj",))
+        or (name.startswith("requirements") and name.endswith(".txt"))
+    )
+
+
+def _version(declared: str | None) -> str | None:
+    """The version a specification pins or starts its range at: "^4.17.1" and ">=2.4,<3" give "4.17.1" and "2.4"."""
+    if not declared:
+        return None
+    match = VERSION_NUMBER.search(declared)
+    return match.group(0) if match else None
+
+
+def _line(text: str, needle: str, start: int = 0) -> int | None:
+    """Line of the first occurrence of needle at or after start."""
+    at = text.find(needle, start)
+    return text.count("\n", 0, at) + 1 if at >= 0 else None
+
+
+def _package_json(file_path: str, text: str, data: dict) -> list[Dependency]:
+    """Dependencies of package.json sections."""
+    found = []
+    for section in ("dependencies", "devDependencies", "peerDependencies", "optionalDependencies"):
+        for package, declared in (data.get(section) or {}).items():
+            if isinstance(declared, str):
+                found.append(Dependency(package, Ecosystem.NPM, _version(declared), declared, file_path, _line(text, f'"{package}"')))
+    return found
+
+
+def _package_lock(file_path: str, text: str, data: dict) -> list[Dependency]:
+    """Installed packages of an npm lockfile, v2+ "packages" or v1 "dependencies"."""
+    found = []
+    packages = data.get("packages")
+    if isinstance(packages, dict):
+        direct = set((packages.get("") or {}).get("dependencies") or {}) | set((packages.get("") or {}).get("devDependencies") or {})
+        for key, entry in packages.items():
+            if not key or not isinstance(entry, dict) or "node_modules/" not in key:
+                continue
+            package = key.rsplit("node_modules/", 1)[1]
+            version = entry.get("version")
+            found.append(Dependency(package, Ecosystem.NPM, version, version, file_path, _line(text, f'"{key}"'), package in direct and key == f"node_modules/{package}", True))
+    else:
+        for package, entry in (data.get("dependencies") or {}).items():
+            if isinstance(entry, dict):
+                version = entry.get("version")
+                found.append(Dependency(package, Ecosystem.NPM, version, version, file_path, _line(text, f'"{package}"'), False, True))
+    return found
+
+
+def _requirements(file_path: str, text: str) -> list[Dependency]:
+    """Requirements of a pip requirements file."""
+    found = []
+    for number, line in enumerate(text.splitlines(), 1):
+        if line.lstrip().startswith(("#", "-")):
+            continue
+        match = REQUIREMENT.match(line)
+        if match:
+            declared = match.group(2)
+            found.append(Dependency(match.group(1), Ecosystem.PYPI, _version(declared), declared, file_path, number))
+    return found
+
+
+def _pyproject(file_path: str, text: str) -> list[Dependency]:
+    """PEP 621 and Poetry dependencies of pyproject.toml."""
+    try:
+        data = tomllib.loads(text)
+    except tomllib.TOMLDecodeError:
+        return []
+    found = []
+    project = data.get("project") or {}
+    requirements = list(project.get("dependencies") or [])
+    for extra in (project.get("optional-dependencies") or {}).values():
+        requirements += list(extra or [])
+    for requirement in requirements:
+        match = REQUIREMENT.match(requirement) if isinstance(requirement, str) else None
+        if match:
+            declared = match.group(2)
+            found.append(Dependency(match.group(1), Ecosystem.PYPI, _version(declared), declared, file_path, _line(text, match.group(1))))
+    poetry = (data.get("tool") or {}).get("poetry") or {}
+    groups = [poetry.get("dependencies") or {}] + [(g or {}).get("dependencies") or {} for g in (poetry.get("group") or {}).values()]
+    for group in groups:
+        for package, spec in group.items():
+            if package == "python":
+                continue
+            declared = spec if isinstance(spec, str) else (spec or {}).get("version") if isinstance(spec, dict) else None
+            found.append(Dependency(package, Ecosystem.PYPI, _version(declared), declared, file_path, _line(text, package)))
+    return found
+
+
+def _pom(file_path: str, text: str) -> list[Dependency]:
+    """Dependencies of a Maven POM, with ${property} versions resolved from its properties."""
+    properties = {}
+    for block in POM_PROPERTIES.finditer(text):
+        properties.update(dict(XML_ELEMENT.findall(block.group(1))))
+    found = []
+    for match in POM_DEPENDENCY.finditer(text):
+        fields = dict(XML_ELEMENT.findall(match.group(1)))
+        if "groupId" not in fields or "artifactId" not in fields:
+            continue
+        declared = fields.get("version")
+        version = PROPERTY_REFERENCE.sub(lambda m: properties.get(m.group(1), m.group(0)), declared) if declared else None
+        resolved = _version(version) if version and "${" not in version else None
+        package = f"{fields['groupId']}:{fields['artifactId']}"
+        found.append(Dependency(package, Ecosystem.MAVEN, resolved, declared, file_path, text.count("\n", 0, match.start()) + 1))
+    return found
+
+
+def _gradle(file_path: str, text: str) -> list[Dependency]:
+    """group:artifact:version dependencies of a Gradle build script."""
+    return [
+        Dependency(f"{m.group(2)}:{m.group(3)}", Ecosystem.MAVEN, _version(m.group(4)) if m.group(4) and "$" not in m.group(4) else None, m.group(4), file_path, text.count("\n", 0, m.start()) + 1)
+        for m in GRADLE_DEPENDENCY.finditer(text)
+    ]
+
+
+def _go_mod(file_path: str, text: str) -> list[Dependency]:
+    """Modules a go.mod requires; // indirect ones are transitive."""
+    found = []
+    for match in GO_REQUIRE.finditer(text):
+        line = text[match.start() : text.find("\n", match.start()) if "\n" in text[match.start() :] else len(text)]
+        if line.lstrip().startswith(("module", "go ", "replace", "exclude", "retract")):
+            continue
+        found.append(
+            Dependency(match.group(1), Ecosystem.GO, _version(match.group(2)), match.group(2), file_path, text.count("\n", 0, match.start()) + 1, "// indirect" not in line)
+        )
+    return found
+
+
+def _gemfile(file_path: str, text: str) -> list[Dependency]:
+    """Gems a Gemfile declares."""
+    return [
+        Dependency(m.group(1), Ecosystem.RUBYGEMS, _version(m.group(2)), m.group(2), file_path, text.count("\n", 0, m.start()) + 1)
+        for m in GEMFILE_GEM.finditer(text)
+    ]
+
+
+def _gemfile_lock(file_path: str, text: str) -> list[Dependency]:
+    """Locked gems of a Gemfile.lock; those under DEPENDENCIES are direct."""
+    direct_at = text.find("\nDEPENDENCIES")
+    direct = set(re.findall(r"^  ([\w.-]+)", text[direct_at:], re.MULTILINE)) if direct_at >= 0 else set()
+    return [
+        Dependency(m.group(1), Ecosystem.RUBYGEMS, _version(m.group(2)), m.group(2), file_path, text.count("\n", 0, m.start()) + 1, m.group(1) in direct, True)
+        for m in GEMFILE_LOCK_SPEC.finditer(text[: direct_at if direct_at >= 0 else len(text)])
+    ]
+
+
+def _csproj(file_path: str, text: str) -> list[Dependency]:
+    """PackageReference items of a .csproj project."""
+    return [
+        Dependency(m.group(1), Ecosystem.NUGET, _version(m.group(2)), m.group(2), file_path, text.count("\n", 0, m.start()) + 1)
+        for m in PACKAGE_REFERENCE.finditer(text)
+    ]
+
+
+def _composer(file_path: str, text: str, data: dict) -> list[Dependency]:
+    """Packages composer.json requires."""
+    found = []
+    for section in ("require", "require-dev"):
+        for package, declared in (data.get(section) or {}).items():
+            if "/" in package and isinstance(declared, str):
+                found.append(Dependency(package, Ecosystem.COMPOSER, _version(declared), declared, file_path, _line(text, f'"{package}"')))
+    return found
+
+
+def _purl(purl: str) -> tuple[Ecosystem, str, str | None] | None:
+    """Ecosystem, package name, and version of a package URL such as pkg:npm/%40nestjs/jwt@10.2.0."""
+    match = re.match(r"^pkg:([\w.-]+)/([^@?#]+)(?:@([^?#]+))?", purl or "")
+    if not match or match.group(1) not in PURL_TYPES:
+        return None
+    ecosystem = PURL_TYPES[match.group(1)]
+    name = match.group(2).replace("%40", "@").replace("%2F", "/")
+    if ecosystem == Ecosystem.MAVEN:
+        name = name.replace("/", ":", 1)
+    return ecosystem, name, match.group(3)
+
+
+def _sbom(file_path: str, text: str, data: dict) -> list[Dependency]:
+    """Packages of a CycloneDX or SPDX JSON SBOM that carry package URLs."""
+    found = []
+    entries = []
+    for component in data.get("components") or []:
+        if isinstance(component, dict):
+            entries.append((component.get("purl"), component.get("name")))
+    for package in data.get("packages") or []:
+        if isinstance(package, dict):
+            refs = [r.get("referenceLocator") for r in package.get("externalRefs") or [] if isinstance(r, dict) and r.get("referenceType") == "purl"]
+            entries.append((refs[0] if refs else None, package.get("name")))
+    for purl, name in entries:
+        parsed = _purl(purl) if isinstance(purl, str) else None
+        if parsed:
+            ecosystem, package, version = parsed
+            found.append(Dependency(package, ecosystem, _version(version), version, file_path, _line(text, str(name or purl)), False, True))
+    return found
+
+
+def parse_manifest(file_path: str, text: str) -> list[Dependency]:
+    """Read the dependencies a manifest, lockfile, or SBOM declares.
+
+    Args:
+        file_path: Path relative to the clone root
+        text: File content
+
+    Returns:
+        Dependencies in file order; none for unreadable files
+    """
+    name = PurePosixPath(file_path).name
+    if name.endswith(".json"):
+        try:
+            data = json.loads(text)
+        except ValueError:
+            return []
+        if not isinstance(data, dict):
+            return []
+        if name == "package.json":
+            return _package_json(file_path, text, data)
+        if name == "package-lock.json":
+            return _package_lock(file_path, text, data)
+        if name == "composer.json":
+            return _composer(file_path, text, data)
+        return _sbom(file_path, text, data)
+    if name.startswith("requirements") and name.endswith(".txt"):
+        return _requirements(file_path, text)
+    parsers = {
+        "pyproject.toml": _pyproject,
+        "pom.xml": _pom,
+        "build.gradle": _gradle,
+        "build.gradle.kts": _gradle,
+        "go.mod": _go_mod,
+        "Gemfile": _gemfile,
+        "Gemfile.lock": _gemfile_lock,
+    }
+    if name in parsers:
+        return parsers[name](file_path, text)
+    if name.endswith(".csproj"):
+        return _csproj(file_path, text)
+    return []
+
+
+def _normalize(package: str, ecosystem: Ecosystem) -> str:
+    """Compare PyPI names case- and separator-insensitively, and others as written."""
+    return re.sub(r"[-_.]+", "-", package).lower() if ecosystem == Ecosystem.PYPI else package
+
+
+def _matches(package: str, pattern: str) -> bool:
+    """Check a package name against a catalog pattern; a trailing "*" matches any suffix."""
+    if pattern.endswith("*"):
+        return package.startswith(pattern[:-1])
+    return package == pattern
+
+
+def match_library(dependency: Dependency) -> AuthLibrary | None:
+    """Find the catalog entry a dependency is a package of."""
+    package = _normalize(dependency.package, dependency.ecosystem)
+    for library in AUTH_LIBRARIES:
+        if library.ecosystem == dependency.ecosystem and any(_matches(package, p) for p in library.packages):
+            return library
+    return None
+
+
+def _below(version: str | None, minimum: str) -> bool:
+    """Compare dotted version numbers; unknown versions are not below."""
+    if not version or not VERSION_NUMBER.fullmatch(version):
+        return False
+    have, want = [int(p) for p in version.split(".")], [int(p) for p in minimum.split(".")]
+    width = max(len(have), len(want))
+    return have + [0] * (width - len(have)) < want + [0] * (width - len(want))
+
+
+def deprecation(library: AuthLibrary, version: str | None) -> str | None:
+    """Why a library at a version is flagged, or None."""
+    if library.deprecated:
+        return library.deprecated
+    if library.minimum_version and _below(version, library.minimum_version):
+        return library.minimum_reason
+    return None
+
+
+def match_usages(repositories: list[Repository], dependencies_by_repository: dict[int, list[Dependency]]) -> list[dict]:
+    """Match each service's dependencies against the auth library catalog.
+
+    A package declared by both a manifest and its lockfile is reported once
+    per service, with the manifest's specification and line and the
+    lockfile's exact version; packages only a lockfile or SBOM names are
+    transitive unless the lockfile marks them as direct.
+
+    Args:
+        repositories: Services (repositories) in scope
+        dependencies_by_repository: Dependencies read from each repository's clone
+
+    Returns:
+        One usage per service and package, by category, library, and service
+    """
+    usages = []
+    for repository in repositories:
+        merged: dict[str, dict] = {}
+        for dependency in dependencies_by_repository.get(repository.id, []):
+            library = match_library(dependency)
+            if library is None:
+                continue
+            usage = merged.setdefault(
+                dependency.package,
+                {"repository_id": repository.id, "repository": repository.name, "library": library, "locked": [], "declared": []},
+            )
+            (usage["locked"] if dependency.locked else usage["declared"]).append(dependency)
+        for package, usage in merged.items():
+            library, declared, locked = usage["library"], usage["declared"], usage["locked"]
+            primary = (declared or locked)[0]
+            version = next((d.version for d in locked if d.version), None) or next((d.version for d in declared if d.version), None)
+            reason = deprecation(library, version)
+            usages.append(
+                {
+                    "repository_id": usage["repository_id"],
+                    "repository": usage["repository"],
+                    "library": library.name,
+                    "category": library.category,
+                    "ecosystem": library.ecosystem,
+                    "package": package,
+                    "version": version,
+                    "declared": primary.declared if declared else None,
+                    "file_path": primary.file_path,
+                    "line": primary.line,
+                    "manifests": sorted({d.file_path for d in declared + locked}),
+                    "direct": any(d.direct for d in declared + locked),
+                    "deprecated": reason is not None,
+                    "deprecation": reason,
+                }
+            )
+    usages.sort(key=lambda u: (u["category"].value, u["library"], u["repository"], u["package"]))
+    return usages
+
+
+def rollup(repositories: list[Repository], usages: list[dict]) -> dict:
+    """Roll usages up per library and per category.
+
+    Args:
+        repositories: Services (repositories) in scope
+        usages: Usages from match_usages, possibly filtered
+
+    Returns:
+        Usages, libraries with the versions each service runs, categories
+        with the libraries competing in them, and a summary
+    """
+    libraries: dict[tuple[str, Ecosystem], dict] = {}
+    for usage in usages:
+        entry = libraries.setdefault(
+            (usage["library"], usage["ecosystem"]),
+            {"library": usage["library"], "category": usage["category"], "ecosystem": usage["ecosystem"], "services": set(), "versions": defaultdict(set), "deprecated_services": set()},
+        )
+        entry["services"].add(usage["repository"])
+        entry["versions"][usage["version"] or "unknown"].add(usage["repository"])
+        if usage["deprecated"]:
+            entry["deprecated_services"].add(usage["repository"])
+    rows = []
+    for entry in libraries.values():
+        entry["services"] = sorted(entry["services"])
+        entry["versions"] = {v: sorted(s) for v, s in sorted(entry["versions"].items())}
+        entry["deprecated_services"] = sorted(entry["deprecated_services"])
+        rows.append(entry)
+    rows.sort(key=lambda e: (e["category"].value, -len(e["services"]), e["library"]))
+
+    categories = []
+    for category in LibraryCategory:
+        in_category = [e for e in rows if e["category"] == category]
+        if in_category:
+            categories.append(
+                {
+                    "category": category,
+                    "libraries": {e["library"]: len(e["services"]) for e in in_category},
+                    "services": len({s for e in in_category for s in e["services"]}),
+                }
+            )
+    return {
+        "usages": usages,
+        "libraries": rows,
+        "categories": categories,
+        "summary": {
+            "services": len(repositories),
+            "services_with_auth_libraries": len({u["repository_id"] for u in usages}),
+            "usages": len(usages),
+            "libraries": len(rows),
+            "deprecated_usages": sum(u["deprecated"] for u in usages),
+            # Libraries services run at more than one version
+            "version_drift": sorted({e["library"] for e in rows if len(e["versions"]) > 1}),
+        },
+    }
+
+
+def declared_dependencies(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the dependencies declared across a repository clone's manifests."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    for path in sorted(clone.rglob("*")):
+        if not is_manifest(path.name):
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        text = path.read_text(encoding="utf-8", errors="ignore")
+        yield from (asdict(d) for d in parse_manifest(relative.as_posix(), text))
+
+
+class AuthLibraryService:
+    """Inventories the auth libraries and versions the tenant's services use."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None) -> list[Repository]:
+        """Load repositories for the tenant, or the one requested."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        repositories = query.order_by(Repository.id).all()
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repositories
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[Dependency]:
+        """Read the dependencies declared across a repository clone's manifests, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Dependencies in path order
+        """
+        return [
+            Dependency(**{**d, "ecosystem": Ecosystem(d["ecosystem"])})
+            for d in run_pass(root, declared_dependencies)
+        ]
+
+    def inventory(
+        self,
+        repository_id: int | None = None,
+        library: str | None = None,
+        category: LibraryCategory | None = None,
+        ecosystem: Ecosystem | None = None,
+        deprecated_only: bool = False,
+    ) -> dict:
+        """Inventory auth libraries across the tenant's services.
+
+        Filters narrow the usages; the rollups are rebuilt from what remains,
+        so a library filter shows that library's versions and services.
+
+        Args:
+            repository_id: Restrict to one repository
+            library: Keep usages of this library (case-insensitive)
+            category: Keep usages of libraries in this category
+            ecosystem: Keep usages from this package ecosystem
+            deprecated_only: Keep only deprecated libraries and flagged versions
+
+        Returns:
+            Usages, libraries, categories, and a summary
+
+        Raises:
+            ValueError: If the requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        dependencies = {}
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            dependencies[repository.id] = self.scan_clone(root) if root.is_dir() else []
+        usages = [
+            u
+            for u in match_usages(repositories, dependencies)
+            if (not library or u["library"].lower() == library.lower())
+            and (category is None or u["category"] == category)
+            and (ecosystem is None or u["ecosystem"] == ecosystem)
+            and (not deprecated_only or u["deprecated"])
+        ]
+        result = rollup(repositories, usages)
+        logger.info(
+            "auth_libraries_inventoried",
+            tenant_id=self.tenant_id,
+            usages=result["summary"]["usages"],
+            deprecated=result["summary"]["deprecated_usages"],
+        )
+        return result
@@ -0,0 +1,223 @@
+"""Service for classifying endpoints by authentication mechanism.
+
+The evidence behind a mined policy cites the middleware, filter, or
+annotation that checks the caller, and that code names the credential it
+reads: a server-side session, a JWT bearer token, an API key header, or a
+client certificate. This service reads those signatures from each policy's
+subject, conditions, description, and evidence, combines them per endpoint,
+and rolls them up per service so the mechanism can be filtered on.
+"""
+
+import re
+from collections import Counter
+from collections.abc import Iterable
+from enum import Enum
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+
+logger = structlog.get_logger(__name__)
+
+
+class AuthMechanism(str, Enum):
+    """How callers of an endpoint authenticate."""
+
+    COOKIE_SESSION = "cookie_session"
+    JWT_BEARER = "jwt_bearer"
+    API_KEY = "api_key"
+    MTLS = "mtls"
+    NONE = "none"  # Anonymous callers allowed
+    UNKNOWN = "unknown"  # Authenticated, but the evidence names no mechanism
+
+
+# Signatures per mechanism, most specific first; the first match is the primary mechanism
+MECHANISM_SIGNATURES: list[tuple[AuthMechanism, re.Pattern]] = [
+    (
+        AuthMechanism.MTLS,
+        re.compile(
+            r"\bmtls\b|mutual[\s-]?tls|client[\s_-]?cert|clientAuth|ssl[_-]client[_-](?:verify|cert|s[_-]dn)|"
+            r"x-(?:ssl-)?client-cert|x-forwarded-client-cert|requestCert|getPeerCertificate|x509|"
+            r"PeerAuthentication|CERT_REQUIRED",
+            re.IGNORECASE,
+        ),
+    ),
+    (
+        AuthMechanism.JWT_BEARER,
+        re.compile(
+            r"jwt|jsonwebtoken|jwks|oauth2ResourceServer|OAuth2PasswordBearer|HTTPBearer|"
+            r"\bbearer\b|access[_-]?token|id[_-]?token|verify_?token|decode_?token",
+            re.IGNORECASE,
+        ),
+    ),
+    (
+        AuthMechanism.API_KEY,
+        re.compile(r"api[_-]?key|x-api-key|api[_-]?token|HasAPIKey", re.IGNORECASE),
+    ),
+    (
+        AuthMechanism.COOKIE_SESSION,
+        re.compile(
+            r"express-session|cookie-session|(?:req|request|self\.request)\.session\b|\bsession\[|"
+            r"HttpSession|getSession\(|SessionAuthentication|login_required|flask_login|"
+            r"passport\.session|formLogin|CookieAuthentication|AddCookie|Set-Cookie|"
+            r"\bcookies?\b|Security\.username|withSession",
+            re.IGNORECASE,
+        ),
+    ),
+]
+
+
+def classify_text(text: str) -> list[AuthMechanism]:
+    """Find the authentication mechanisms a piece of code or prose names.
+
+    Args:
+        text: Evidence snippet, description, or conditions
+
+    Returns:
+        Mechanisms found, most specific first
+    """
+    return [mechanism for mechanism, pattern in MECHANISM_SIGNATURES if pattern.search(text)]
+
+
+def policy_mechanisms(policy: Policy) -> list[AuthMechanism]:
+    """Classify one policy by the mechanisms its text and evidence name.
+
+    Args:
+        policy: Mined policy
+
+    Returns:
+        [NONE] for policies allowing anonymous callers, the mechanisms found
+        (most specific first), or [UNKNOWN] when none are named
+    """
+    _, requires_auth = EndpointMappingService.parse_roles(policy.subject)
+    if not requires_auth:
+        return [AuthMechanism.NONE]
+    parts = [policy.subject, policy.conditions, policy.description]
+    parts += [ev.code_snippet for ev in policy.evidence or []]
+    mechanisms = classify_text("\n".join(p for p in parts if isinstance(p, str)))
+    return mechanisms or [AuthMechanism.UNKNOWN]
+
+
+def rule_mechanisms(rule: EndpointRule, policies: dict[int, Policy]) -> list[AuthMechanism]:
+    """Combine the mechanisms of the policies merged into one endpoint.
+
+    Args:
+        rule: Endpoint rule
+        policies: Policies by ID
+
+    Returns:
+        [NONE] for public endpoints, otherwise the named mechanisms in
+        precedence order, or [UNKNOWN]
+    """
+    if rule.is_public:
+        return [AuthMechanism.NONE]
+    found = {m for pid in rule.policy_ids if pid in policies for m in policy_mechanisms(policies[pid])}
+    found -= {AuthMechanism.NONE, AuthMechanism.UNKNOWN}
+    ordered = [m for m, _ in MECHANISM_SIGNATURES if m in found]
+    return ordered or [AuthMechanism.UNKNOWN]
+
+
+def classify_endpoints(policies: list[Policy]) -> list[dict]:
+    """Classify every endpoint the policies map to.
+
+    Args:
+        policies: Mined policies
+
+    Returns:
+        Endpoint dictionaries with the primary mechanism and all mechanisms found
+    """
+    by_id = {p.id: p for p in policies}
+    endpoints = []
+    for rule in EndpointMappingService.map_policies(policies):
+        mechanisms = rule_mechanisms(rule, by_id)
+        endpoints.append(
+            {
+                "endpoint": rule.key,
+                "method": rule.method,
+                "path": rule.path,
+                "policy_ids": rule.policy_ids,
+                "mechanism": mechanisms[0].value,
+                "mechanisms": [m.value for m in mechanisms],
+            }
+        )
+    return endpoints
+
+
+def count_mechanisms(endpoints: Iterable[dict]) -> dict[str, int]:
+    """Count endpoints by primary mechanism."""
+    return dict(Counter(e["mechanism"] for e in endpoints))
+
+
+class AuthMechanismService:
+    """Classifies endpoints and services by authentication mechanism."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def endpoints(
+        self,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+        mechanism: AuthMechanism | None = None,
+    ) -> list[dict]:
+        """Classify the endpoints in scope.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+            mechanism: Keep only endpoints that use this mechanism
+
+        Returns:
+            Endpoint dictionaries sorted by path then method
+        """
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id, application_id)
+        endpoints = classify_endpoints(policies)
+        if mechanism is not None:
+            endpoints = [e for e in endpoints if mechanism.value in e["mechanisms"]]
+        logger.info(
+            "auth_mechanisms_classified",
+            repository_id=repository_id,
+            application_id=application_id,
+            endpoints=len(endpoints),
+        )
+        return endpoints
+
+    def service_summary(self, mechanism: AuthMechanism | None = None) -> list[dict]:
+        """Roll endpoint mechanisms up per service (repository).
+
+        Args:
+            mechanism: Keep only services with at least one endpoint using it
+
+        Returns:
+            Per-repository endpoint counts and the dominant authenticated mechanism
+        """
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+
+        summaries = []
+        for repository in query.order_by(Repository.id).all():
+            endpoints = self.endpoints(repository_id=repository.id)
+            if mechanism is not None and not any(mechanism.value in e["mechanisms"] for e in endpoints):
+                continue
+            counts = count_mechanisms(endpoints)
+            authenticated = Counter(
+                {m: c for m, c in counts.items() if m not in (AuthMechanism.NONE.value, AuthMechanism.UNKNOWN.value)}
+            )
+            primary = authenticated.most_common(1)[0][0] if authenticated else None
+            summaries.append(
+                {
+                    "repository_id": repository.id,
+                    "repository_name": repository.name,
+                    "endpoints": sum(counts.values()),
+                    "primary_mechanism": primary,
+                    "by_mechanism": counts,
+                }
+            )
+        return summaries
@@ -0,0 +1,413 @@
+"""Service for generating executable authorization tests from mined policies.
+
+Each mined endpoint rule becomes a table of (role, expected status) cases that
+teams can commit to their own repos to lock in current authorization behavior
+and catch regressions in CI. Generated suites target Go httptest, pytest, and
+Jest with supertest.
+"""
+
+import json
+import re
+from dataclasses import dataclass
+from enum import Enum
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus
+from app.models.repository import Repository
+from app.services.endpoint_mapping_service import (
+    ANONYMOUS_ROLE,
+    AUTHENTICATED_ROLE,
+    EndpointMappingService,
+    EndpointRule,
+)
+
+logger = structlog.get_logger(__name__)
+
+# Status code we expect from a successful call, by method
+SUCCESS_STATUS_BY_METHOD = {
+    "GET": 200,
+    "POST": 201,
+    "PUT": 200,
+    "PATCH": 200,
+    "DELETE": 204,
+}
+
+
+class AuthzTestFramework(str, Enum):
+    """Supported test frameworks for generated suites."""
+
+    GO_HTTPTEST = "go_httptest"
+    PYTEST = "pytest"
+    JEST_SUPERTEST = "jest_supertest"
+
+
+@dataclass
+class AuthzTestCase:
+    """One expected authorization outcome for a role on an endpoint."""
+
+    method: str
+    path: str
+    role: str
+    allowed: bool
+    expected_status: int
+    skip_reason: str | None = None
+
+    @property
+    def name(self) -> str:
+        """Human-readable test case name."""
+        outcome = "allowed" if self.allowed else "denied"
+        return f"{self.method} {self.path} as {self.role} is {outcome}"
+
+
+class AuthzTestGenerationService:
+    """Generates per-endpoint authorization tests from mined policies."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def generate_for_repository(
+        self,
+        repository_id: int,
+        framework: AuthzTestFramework,
+        package_name: str | None = None,
+    ) -> dict:
+        """Generate an authorization test suite for a repository.
+
+        Args:
+            repository_id: Repository whose policies should be covered
+            framework: Target test framework
+            package_name: Go package name for generated Go tests
+
+        Returns:
+            Dictionary with the rendered suite and case statistics
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository, rules = self.load_endpoint_rules(repository_id)
+        cases = self.build_cases(rules)
+
+        if framework == AuthzTestFramework.GO_HTTPTEST:
+            content = self.render_go_httptest(cases, repository.name, package_name or "authz")
+            filename = "mined_authz_policies_test.go"
+        elif framework == AuthzTestFramework.PYTEST:
+            content = self.render_pytest(cases, repository.name)
+            filename = "test_mined_authz_policies.py"
+        else:
+            content = self.render_jest_supertest(cases, repository.name)
+            filename = "mined-authz-policies.test.js"
+
+        logger.info(
+            "authz_tests_generated",
+            repository_id=repository_id,
+            framework=framework.value,
+            endpoints=len(rules),
+            cases=len(cases),
+        )
+
+        return {
+            "repository_id": repository_id,
+            "framework": framework,
+            "filename": filename,
+            "content": content,
+            "endpoint_count": len(rules),
+            "case_count": len(cases),
+            "skipped_count": sum(1 for c in cases if c.skip_reason),
+        }
+
+    def load_endpoint_rules(self, repository_id: int) -> tuple[Repository, list[EndpointRule]]:
+        """Load a repository's non-rejected policies as endpoint rules.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Tuple of (repository, endpoint rules)
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        query = self.db.query(Repository).filter(Repository.id == repository_id)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        repository = query.first()
+        if not repository:
+            raise ValueError(f"Repository {repository_id} not found")
+
+        policies = (
+            self.db.query(Policy)
+            .filter(Policy.repository_id == repository_id)
+            .filter(Policy.status != PolicyStatus.REJECTED)
+            .all()
+        )
+
+        return repository, EndpointMappingService.map_policies(policies)
+
+    @staticmethod
+    def build_cases(rules: list[EndpointRule]) -> list[AuthzTestCase]:
+        """Expand endpoint rules into per-role test cases.
+
+        Every endpoint is tested against every role referenced anywhere in the
+        rule set, plus an anonymous caller. Allowed cases on endpoints with
+        attribute conditions are emitted as skipped, since the test cannot
+        know which fixture data satisfies the condition.
+
+        Args:
+            rules: Endpoint rules
+
+        Returns:
+            Test cases in endpoint order
+        """
+        roles = EndpointMappingService.collect_roles(rules) or [AUTHENTICATED_ROLE]
+        cases: list[AuthzTestCase] = []
+
+        for rule in rules:
+            path = AuthzTestGenerationService.concrete_path(rule.path)
+            success_status = SUCCESS_STATUS_BY_METHOD.get(rule.method, 200)
+
+            for role in [ANONYMOUS_ROLE, *roles]:
+                allowed = rule.allows(role)
+                if allowed:
+                    expected = success_status
+                elif role == ANONYMOUS_ROLE:
+                    expected = 401
+                else:
+                    expected = 403
+
+                skip_reason = None
+                if allowed and rule.is_conditional:
+                    skip_reason = "requires fixture satisfying: " + "; ".join(rule.conditions)
+
+                cases.append(
+                    AuthzTestCase(
+                        method=rule.method,
+                        path=path,
+                        role=role,
+                        allowed=allowed,
+                        expected_status=expected,
+                        skip_reason=skip_reason,
+                    )
+                )
+
+        return cases
+
+    @staticmethod
+    def concrete_path(path: str) -> str:
+        """Replace route parameters with a concrete placeholder value.
+
+        Args:
+            path: Route path with {id}, :id, or <id> parameters
+
+        Returns:
+            Callable path
+        """
+        path = re.sub(r"\{[^}]+\}", "1", path)
+        path = re.sub(r"<[^>]+>", "1", path)
+        return re.sub(r":\w+", "1", path)
+
+    @staticmethod
+    def render_go_httptest(cases: list[AuthzTestCase], repository_name: str, package_name: str) -> str:
+        """Render cases as a Go httptest table-driven test.
+
+        The generated file expects two hooks in the same package:
+        ``newAuthzTestHandler(t)`` returning the service's http.Handler, and
+        ``authzTokenFor(t, role)`` returning a bearer token ("" for anonymous).
+
+        Args:
+            cases: Test cases
+            repository_name: Repository name for the header comment
+            package_name: Go package name
+
+        Returns:
+            Go source code
+        """
+        rows = []
+        for case in cases:
+            rows.append(
+                "\t\t{{{name}, {method}, {path}, {role}, {allowed}, {status}, {skip}}},".format(
+                    name=json.dumps(case.name),
+                    method=json.dumps(case.method),
+                    path=json.dumps(case.path),
+                    role=json.dumps(case.role),
+                    allowed="true" if case.allowed else "false",
+                    status=case.expected_status,
+                    skip=json.dumps(case.skip_reason or ""),
+                )
+            )
+
+        return f"""// Code generated by Policy Miner. DO NOT EDIT.
+// Authorization regression tests mined from repository {json.dumps(repository_name)}.
+//
+// Provide these hooks in another _test.go file in this package:
+//
+//	func newAuthzTestHandler(t *testing.T) http.Handler
+//	func authzTokenFor(t *testing.T, role string) string // return "" for anonymous
+package {package_name}
+
+import (
+\t"net/http"
+\t"net/http/httptest"
+\t"testing"
+)
+
+func TestMinedAuthorizationPolicies(t *testing.T) {{
+\thandler := newAuthzTestHandler(t)
+
+\tcases := []struct {{
+\t\tname    string
+\t\tmethod  string
+\t\tpath    string
+\t\trole    string
+\t\tallowed bool
+\t\twant    int
+\t\tskip    string
+\t}}{{
+{chr(10).join(rows)}
+\t}}
+
+\tfor _, tc := range cases {{
+\t\ttc := tc
+\t\tt.Run(tc.name, func(t *testing.T) {{
+\t\t\tif tc.skip != "" {{
+\t\t\t\tt.Skip(tc.skip)
+\t\t\t}}
+\t\t\treq := httptest.NewRequest(tc.method, tc.path, nil)
+\t\t\tif token := authzTokenFor(t, tc.role); token != "" {{
+\t\t\t\treq.Header.Set("Authorization", "Bearer "+token)
+\t\t\t}}
+\t\t\trec := httptest.NewRecorder()
+\t\t\thandler.ServeHTTP(rec, req)
+
+\t\t\tif tc.allowed {{
+\t\t\t\tif rec.Code == http.StatusUnauthorized || rec.Code == http.StatusForbidden {{
+\t\t\t\t\tt.Fatalf("expected %s to be allowed (want %d), got %d", tc.role, tc.want, rec.Code)
+\t\t\t\t}}
+\t\t\t\treturn
+\t\t\t}}
+\t\t\tif rec.Code != tc.want {{
+\t\t\t\tt.Fatalf("expected %s to be denied with %d, got %d", tc.role, tc.want, rec.Code)
+\t\t\t}}
+\t\t}})
+\t}}
+}}
+"""
+
+    @staticmethod
+    def render_pytest(cases: list[AuthzTestCase], repository_name: str) -> str:
+        """Render cases as a parametrized pytest module.
+
+        The generated module expects ``client`` (an HTTP test client with a
+        ``request`` method) and ``auth_headers`` (role -> headers dict)
+        fixtures in the project's conftest.py.
+
+        Args:
+            cases: Test cases
+            repository_name: Repository name for the module docstring
+
+        Returns:
+            Python source code
+        """
+        params = []
+        for case in cases:
+            marks = ""
+            if case.skip_reason:
+                marks = f", marks=pytest.mark.skip(reason={json.dumps(case.skip_reason)})"
+            params.append(
+                f"    pytest.param({json.dumps(case.method)}, {json.dumps(case.path)}, "
+                f"{json.dumps(case.role)}, {case.allowed}, {case.expected_status}, "
+                f"id={json.dumps(case.name)}{marks}),"
+            )
+
+        return f'''"""Authorization regression tests mined from repository {json.dumps(repository_name)}.
+
+Generated by Policy Miner. Requires ``client`` and ``auth_headers(role)``
+fixtures in conftest.py; ``auth_headers("anonymous")`` should return {{}}.
+"""
+import pytest
+
+CASES = [
+{chr(10).join(params)}
+]
+
+
+@pytest.mark.parametrize("method,path,role,allowed,expected_status", CASES)
+def test_mined_authorization_policy(client, auth_headers, method, path, role, allowed, expected_status):
+    """Endpoint enforces the mined policy for this role."""
+    response = client.request(method, path, headers=auth_headers(role))
+
+    if allowed:
+        assert response.status_code not in (401, 403), (
+            f"{{role}} should be allowed (want {{expected_status}}), got {{response.status_code}}"
+        )
+    else:
+        assert response.status_code == expected_status
+'''
+
+    @staticmethod
+    def render_jest_supertest(cases: list[AuthzTestCase], repository_name: str) -> str:
+        """Render cases as a Jest test using supertest.
+
+        The generated file expects ``./authz-test-setup`` to export ``app``
+        and an async ``authHeaderFor(role)`` returning a headers object.
+
+        Args:
+            cases: Test cases
+            repository_name: Repository name for the header comment
+
+        Returns:
+            JavaScript source code
+        """
+        active = [c for c in cases if not c.skip_reason]
+        skipped = [c for c in cases if c.skip_reason]
+
+        def to_js(case_list: list[AuthzTestCase]) -> str:
+            return ",\n".join(
+                "  " + json.dumps(
+                    {
+                        "method": case.method,
+                        "path": case.path,
+                        "role": case.role,
+                        "allowed": case.allowed,
+                        "expectedStatus": case.expected_status,
+                        "skipReason": case.skip_reason,
+                    }
+                )
+                for case in case_list
+            )
+
+        return f"""// Generated by Policy Miner. Do not edit by hand.
+// Authorization regression tests mined from repository {json.dumps(repository_name)}.
+//
+// Requires ./authz-test-setup exporting `app` and async `authHeaderFor(role)`.
+const request = require('supertest');
+const {{ app, authHeaderFor }} = require('./authz-test-setup');
+
+const cases = [
+{to_js(active)}
+];
+
+const skippedCases = [
+{to_js(skipped)}
+];
+
+describe('mined authorization policies', () => {{
+  test.each(cases)('$method $path as $role', async ({{ method, path, role, allowed, expectedStatus }}) => {{
+    const headers = await authHeaderFor(role);
+    const res = await request(app)[method.toLowerCase()](path).set(headers);
+
+    if (allowed) {{
+      expect([401, 403]).not.toContain(res.status);
+    }} else {{
+      expect(res.status).toBe(expectedStatus);
+    }}
+  }});
+
+  if (skippedCases.length > 0) {{
+    test.skip.each(skippedCases)('$method $path as $role ($skipReason)', () => {{}});
+  }}
+}});
+"""
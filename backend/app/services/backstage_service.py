@@ -0,0 +1,174 @@
+"""Service for the Backstage developer portal integration.
+
+A Backstage plugin shows a service's authorization posture on its catalog
+page. The plugin knows the catalog entity (kind, namespace, name, and
+annotations); this service finds the repository the entity describes and
+assembles what the page shows: coverage and open critical findings as of the
+last scan, a summary of the mined policies, and the open findings.
+
+Entities are matched to repositories, most explicit first, by:
+
+- the policy-miner/repository-id annotation
+- the github.com/project-slug annotation (org/repo) against the repository URL
+- the backstage.io/source-location annotation against the repository URL
+- the entity name against the repository name
+"""
+
+import re
+from collections import Counter
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.finding_export_service import FindingExportService
+from app.services.status_badge_service import StatusBadgeService
+
+logger = structlog.get_logger(__name__)
+
+REPOSITORY_ANNOTATION = "policy-miner/repository-id"
+PROJECT_SLUG_ANNOTATION = "github.com/project-slug"
+SOURCE_LOCATION_ANNOTATION = "backstage.io/source-location"
+
+# Entries listed on a catalog page before truncating
+MAX_LISTED_FINDINGS = 25
+MAX_LISTED_ROLES = 10
+
+
+def source_key(url: str | None) -> str | None:
+    """Host and repository path of a source URL, so clone, browse, and source-location URLs compare equal.
+
+    Args:
+        url: e.g. "url:https://github.com/acme/payments/tree/main/", "git@github.com:acme/payments.git"
+
+    Returns:
+        e.g. "github.com/acme/payments", or None for an empty URL
+    """
+    if not url:
+        return None
+    key = re.sub(r"^url:", "", url.strip())
+    key = re.sub(r"^[a-z+]+://", "", key)
+    key = re.sub(r"^[^@/]+@", "", key).replace(":", "/", 1)
+    key = re.split(r"/(?:-/)?(?:tree|blob|src)/", key)[0]
+    return re.sub(r"\.git$", "", key.strip("/")).lower() or None
+
+
+class BackstageService:
+    """Serves repository posture to Backstage catalog pages."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = clone_dir or settings.REPO_CLONE_DIR
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def resolve(
+        self,
+        name: str,
+        repository_id: int | None = None,
+        project_slug: str | None = None,
+        source_location: str | None = None,
+    ) -> Repository:
+        """Repository a catalog entity describes.
+
+        Args:
+            name: Entity name
+            repository_id: Value of the policy-miner/repository-id annotation
+            project_slug: Value of the github.com/project-slug annotation
+            source_location: Value of the backstage.io/source-location annotation
+
+        Returns:
+            The matching repository
+
+        Raises:
+            ValueError: If no repository matches the entity
+        """
+        repositories = self._query(Repository).order_by(Repository.id).all()
+        if repository_id is not None:
+            match = next((r for r in repositories if r.id == repository_id), None)
+            if match is None:
+                raise ValueError(f"Repository {repository_id} not found")
+            return match
+
+        keys = {r.id: source_key(r.source_url) for r in repositories}
+        if project_slug:
+            slug = project_slug.strip("/").lower()
+            for repository in repositories:
+                if keys[repository.id] and (keys[repository.id] + "/").endswith(f"/{slug}/"):
+                    return repository
+        location = source_key(source_location)
+        if location:
+            for repository in repositories:
+                key = keys[repository.id]
+                if key and (location == key or location.startswith(f"{key}/")):
+                    return repository
+        for repository in repositories:
+            if repository.name.lower() == name.lower():
+                return repository
+        raise ValueError(f"No repository matches catalog entity {name}")
+
+    def policy_summary(self, repository_id: int) -> dict:
+        """Counts of a repository's mined policies and the roles they grant most."""
+        policies = self._query(Policy).filter(Policy.repository_id == repository_id).all()
+        roles = Counter(p.subject for p in policies if p.subject)
+        return {
+            "total": len(policies),
+            "by_status": dict(Counter(p.status.value for p in policies if p.status)),
+            "by_risk_level": dict(Counter(p.risk_level.value for p in policies if p.risk_level)),
+            "resources": len({p.resource for p in policies if p.resource}),
+            "top_roles": [{"role": role, "policies": count} for role, count in roles.most_common(MAX_LISTED_ROLES)],
+        }
+
+    def summary(self, repository: Repository) -> dict:
+        """Entity-level posture for catalog lists: status only, no findings."""
+        status = StatusBadgeService(self.db, self.tenant_id).status(repository.id)
+        return {
+            **status,
+            "annotations": {REPOSITORY_ANNOTATION: str(repository.id)},
+            "source_url": repository.source_url,
+        }
+
+    def entities(self) -> list[dict]:
+        """Posture of every repository, with the annotation linking a catalog entity to it."""
+        return [self.summary(r) for r in self._query(Repository).order_by(Repository.id).all()]
+
+    def posture(self, repository: Repository) -> dict:
+        """What a service's catalog page shows.
+
+        Args:
+            repository: Repository the entity describes
+
+        Returns:
+            Status, policy summary, and open findings, most severe first
+        """
+        exporter = FindingExportService(self.db, self.tenant_id, self.clone_dir)
+        findings = exporter.findings(repository_id=repository.id)
+        logger.info("backstage_posture_served", repository_id=repository.id, findings=len(findings))
+        return {
+            **self.summary(repository),
+            "policies": self.policy_summary(repository.id),
+            "findings_summary": exporter.summary(findings),
+            "findings": [
+                {
+                    "rule_id": f["rule_id"],
+                    "title": f["title"],
+                    "severity": f["severity"],
+                    "description": f["description"],
+                    "file_path": f["file_path"],
+                    "line_start": f["line_start"],
+                    "cwe": f["cwe"],
+                    "owasp_api": f["owasp_api"],
+                }
+                for f in findings[:MAX_LISTED_FINDINGS]
+            ],
+            "findings_truncated": len(findings) > MAX_LISTED_FINDINGS,
+        }
@@ -0,0 +1,415 @@
+"""Service for detecting broken object level authorization (BOLA / IDOR) candidates.
+
+A handler that reads an object ID from the request and passes it straight
+to a lookup (GetByID(id), Invoice.objects.get(pk=pk), findById(id)) returns
+or modifies whichever object the caller names, unless something ties the
+object to the caller: a query scoped to the current user or tenant, a
+comparison of the object's owner with the subject, or an object permission
+check. This service follows request IDs through each handler's assignments
+to the lookups they reach and reports the lookups no such check covers,
+with the dataflow trace from the request input to the lookup and its use.
+"""
+
+import re
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass, field
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.repository import Repository
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, SKIPPED_DIRECTORIES
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import MAX_LINE_LENGTH, group
+from app.services.service_call_extractor import FUNCTION_START, INPUT_PARAMETER, USER_INPUT
+
+logger = structlog.get_logger(__name__)
+
+SEVERITY_ORDER = {"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+# Handlers are read at most this many lines past their first
+MAX_HANDLER_LINES = 150
+# Decorator and attribute lines above a handler read for its route and checks
+HEADER_LINES = 4
+
+# Arrow functions and anonymous handlers passed to a route registration
+HANDLER_START = re.compile(
+    rf"(?![ \t]*(?:\}}\s*)?(?:if|elif|else|for|foreach|while|switch|catch|try|with|using|lock|synchronized|return)\b)"
+    rf"(?:{FUNCTION_START.pattern}|[^\n]*(?:=>|\bfunction\s*\w*\s*\([^()\n]*\))\s*\{{\s*$)"
+)
+
+DECORATOR_LINE = re.compile(r"^\s*(?:[@\[]|#|//|/\*|\*|$)")
+HANDLER_NAME = re.compile(
+    r"\b(?:def|func(?:\s*\([^)]*\))?|function|fun)\s+(\w+)|\b(?:const|let|var)\s+(\w+)\s*=|"
+    r"^(?![^\n]*=>)[^\n(]*?\b(\w+)\s*\("
+)
+
+# Names and request reads of object identifiers
+ID_NAME = re.compile(r"^(?:id|pk|uuid|guid|slug)$|_(?:id|pk|uuid|guid)$|[a-z0-9](?:Id|ID|Uuid|UUID|Guid|Pk)$|^ID$")
+ID_REFERENCE = re.compile(
+    r"""['"`:]\w*(?:\bid|_id|Id|ID|\bpk|_pk|uuid|Uuid|UUID|guid)['"`\]]|\.(?:id|pk|\w+_id|\w+Id)\b""", re.IGNORECASE
+)
+# (name, optional second target, value) of assignments, including Go "id, err :=" forms
+ASSIGNMENT = re.compile(
+    r"(?<![\w.$])([A-Za-z_$][\w$]*)(?:\s*,\s*[A-Za-z_$][\w$]*)?\s*(?::\s*[\w\[\]., |<>?]+?)?\s*(?::=|=(?![=>~]))\s*([^\n;]+)"
+)
+DESTRUCTURE = re.compile(
+    r"\{\s*([^{}=]+?)\s*\}\s*(?::\s*[^=]+)?=\s*(?:req|request|ctx|c)\s*\.\s*(?:params|query|body|request\s*\.\s*body)\b"
+)
+PYTHON_HEADER = re.compile(r"[ \t]*(?:async\s+)?def\s+\w+\s*\(([^)]*)\)")
+PYTHON_ROUTE_DECORATOR = re.compile(r"@\w+\s*\.\s*(?:get|post|put|patch|delete|route|api_route)\s*\(")
+CSHARP_ACTION = re.compile(r"\[Http(?:Get|Post|Put|Patch|Delete)\b|\[Route\s*\(")
+CSHARP_PARAMETERS = re.compile(r"\(([^()]*)\)\s*$")
+
+# Lookups by key; generic ones count only when given a request ID
+FETCH = re.compile(
+    r"\b((?:[Gg]et|[Ff]ind|[Ff]etch|[Ll]oad|[Rr]ead|[Ll]ookup|[Ss]elect|[Dd]elete|[Uu]pdate|[Rr]emove|[Dd]estroy)\w*?"
+    r"(?:By(?:Id|ID|Pk|PK|Uuid|UUID|Key)|_by_(?:id|pk|uuid|key))\w*|findByPk|findOne|findFirst|findUnique|"
+    r"get_object_or_404|objects\s*\.\s*(?:get|filter)|find_by|find|get|First|Find|Take|Get|FindAsync|"
+    r"(?:Single|First)OrDefault(?:Async)?|QueryRow(?:Context)?|Query(?:Context)?|GetItem|get_item)\s*\("
+)
+# Lookups whose name says they take a key; others must be called on a store
+KEYED_FETCH = re.compile(r"By(?:Id|ID|Pk|PK|Uuid|UUID|Key)|_by_(?:id|pk|uuid|key)|findByPk|findOne|findFirst|findUnique|get_object_or_404|objects")
+# Receivers of generic lookups that read the request or configuration rather than objects
+NOT_A_STORE = re.compile(
+    r"(?:\b(?:req|request|params|args|query|headers|form|environ|os|json|kwargs|ctx|c|r|vars|mux|chi|config|settings|"
+    r"session\s*\.\s*data|localStorage|sessionStorage|map|dict|cache_headers|axios|http|requests|httpx|client|router|app)|"
+    r"\b\w*(?:Header|Param|Query|Form|Claim)s?)\s*\.\s*$|^\s*$"
+)
+RECEIVER = re.compile(r"([\w$\]\)]+)\s*\.\s*$")
+# Lookups scoped to the caller: the subject, or an owner or tenant column, in the query itself
+SCOPED = re.compile(
+    r"\b(?:current_?user|currentUser|request\s*\.\s*user|req\s*\.\s*user|ctx\s*\.\s*state\s*\.\s*user|g\s*\.\s*user|"
+    r"principal|authentication|claims|session\s*\.\s*user|User\s*\.\s*Identity|GetUserId|getUserId|"
+    r"\w*(?:owner|tenant|org(?:anization)?|account|customer|author|creator|created_?by)\w*|user_?[iI][dD]|userID)\b",
+    re.IGNORECASE,
+)
+# Checks tying an object to the caller, anywhere in the handler or its decorators
+OWNERSHIP_CHECK = re.compile(
+    r"\b(?:authorize!?|authorize_resource|load_and_authorize_resource|check_object_permissions|has_object_permission|"
+    r"policy_scope|accessible_by|AuthorizeAsync|check_?(?:owner\w*|access\w*)|is_?owner\w*|isOwner\w*|verify_?(?:owner\w*|access)|"
+    r"ensure_?(?:owner\w*|access|can\w*)|enforcer?\s*\.\s*[Ee]nforce|PermissionDenied|Forbidden\w*|AccessDenied\w*|"
+    r"StatusForbidden|HTTP_403\w*|Forbid\s*\(|ForbidResult|PostAuthorize|PostFilter)\b|can(?:not)?\?"
+    r"|\b(?:abort|status|sendStatus|HttpStatus|head)\s*[(=:]?\s*[.:]?\s*(?:403|FORBIDDEN)\b"
+    r"|\b\w*(?:owner|user|tenant|org|account|author|creator|created_?by)\w*\s*(?:[!=]==?|\.equals\s*\()"
+    r"|(?:[!=]==?|\.equals\s*\()\s*[\w.()]*(?:current_?user|currentUser|request\.user|req\.user|principal|user|tenant)\w*\b",
+    re.IGNORECASE,
+)
+# Checks made once for every action of a controller
+FILE_CHECK = re.compile(
+    r"\bload_and_authorize_resource\b|\bbefore_action\s+:\w*(?:authorize|owner|correct_user|require_ownership)\w*|"
+    r"\bverify_authorized\b|\bpermission_classes\s*=\s*[^\n]*\b(?:IsOwner|ObjectPermission)\w*"
+)
+# Handlers restricted to administrators may see every object by design
+ADMIN_ROLE = re.compile(r"""\b(?:hasRole|hasAuthority|is_?admin|isAdmin|IsInRole|Roles?\s*=)\b[^\n]{0,40}?admin""", re.IGNORECASE)
+# Rails filters restricting some or all of a controller's actions to administrators
+ADMIN_FILTER = re.compile(r"\bbefore_action\s+:\w*admin\w*(?:\s*,\s*only:\s*(?:\[([^\]\n]*)\]|:(\w+)))?")
+RESPONSE = re.compile(
+    r"\breturn\b|\bres\s*\.\s*(?:json|send|status)|\bc\s*\.\s*(?:JSON|IndentedJSON|XML)|\bjsonify\b|\brender\b|\bOk\s*\(|"
+    r"\bResponseEntity\b|\bctx\s*\.\s*body\b|\bjson\s*\.\s*NewEncoder\b|\bwriteJSON\b|\brespond\w*\b",
+    re.IGNORECASE,
+)
+WRITE = re.compile(r"\.\s*(?:delete|destroy|remove|save|update\w*|Delete|Save|Update\w*|Remove|set)!?\s*(?:\(|$)")
+WRITE_FETCH = re.compile(r"^(?:delete|update|remove|destroy)|Delete|Update|Remove|Destroy", re.IGNORECASE)
+
+
+@dataclass
+class TraceStep:
+    """One step of the dataflow from a request input to a lookup."""
+
+    step: str  # input, assignment, lookup, or use
+    line: int
+    code: str
+
+
+@dataclass
+class BolaCandidate:
+    """A lookup by a client-supplied ID that no ownership or tenancy check covers."""
+
+    file_path: str
+    function: str | None  # None for anonymous handlers
+    line_start: int
+    line_end: int
+    identifier: str
+    lookup: str
+    endpoint: str | None
+    severity: str
+    description: str
+    trace: list[TraceStep] = field(default_factory=list)
+
+
+def _short(code: str) -> str:
+    """Code on one line, shortened for traces."""
+    text = " ".join(code.split())
+    return text if len(text) <= 120 else text[:117] + "..."
+
+
+def _references(names: dict[str, list[TraceStep]]) -> re.Pattern | None:
+    """Pattern matching uses of any of the names."""
+    if not names:
+        return None
+    return re.compile(r"(?<![\w.$'\"])(" + "|".join(re.escape(n) for n in sorted(names, key=len, reverse=True)) + r")(?![\w$'\"])")
+
+
+def _direct_id(code: str) -> str | None:
+    """Request read of an object ID in a piece of code."""
+    for match in USER_INPUT.finditer(code):
+        window = code[match.start() : match.end() + 40]
+        if ID_REFERENCE.search(window):
+            return window.split(")")[0].split(",")[0].split(";")[0].strip()
+    return None
+
+
+def _parameters(lines: list[str], start: int, header: str, text: str) -> list[str]:
+    """Handler parameters bound from the request that hold object IDs."""
+    names = []
+    for match in INPUT_PARAMETER.finditer(header + "\n" + lines[start]):
+        names.append(match.group(1) or match.group(2))
+    python = PYTHON_HEADER.match(lines[start])
+    if python:
+        parameters = [re.split(r"[:=]", p)[0].strip().lstrip("*") for p in python.group(1).split(",")]
+        if {"request", "req"} & set(parameters) or PYTHON_ROUTE_DECORATOR.search(header):
+            names.extend(p for p in parameters if p not in ("self", "cls", "request", "req"))
+    if CSHARP_ACTION.search(header) and "Controller" in text:
+        declared = CSHARP_PARAMETERS.search(lines[start].split("{")[0].rstrip())
+        if declared:
+            names.extend(p.split("=")[0].split()[-1] for p in declared.group(1).split(",") if len(p.split("=")[0].split()) >= 2)
+    return [n for n in names if n and ID_NAME.search(n)]
+
+
+def _next_handler(lines: list[str], start: int) -> int | None:
+    """Index of the first handler line at or after start."""
+    return next((i for i in range(start, len(lines)) if len(lines[i]) <= MAX_LINE_LENGTH and HANDLER_START.match(lines[i])), None)
+
+
+def _handler_end(lines: list[str], start: int) -> int:
+    """Index just past a handler's last line: the next handler's first, or the line limit."""
+    following = _next_handler(lines[: start + MAX_HANDLER_LINES], start + 1)
+    if following is None:
+        return min(len(lines), start + MAX_HANDLER_LINES)
+    # Decorators and attributes above the next handler belong to it
+    while following > start + 1 and DECORATOR_LINE.match(lines[following - 1]):
+        following -= 1
+    return following
+
+
+def _handler_name(line: str) -> str | None:
+    """Name a handler's first line declares, or None for anonymous handlers."""
+    match = HANDLER_NAME.search(line)
+    return next(g for g in match.groups() if g) if match else None
+
+
+def _admin_actions(text: str) -> set[str]:
+    """Actions a controller's filters restrict to administrators; "*" for all of them."""
+    actions: set[str] = set()
+    for match in ADMIN_FILTER.finditer(text):
+        listed = match.group(1) or match.group(2)
+        actions.update(re.findall(r"\w+", listed) if listed else ["*"])
+    return actions
+
+
+def _candidates_in(
+    file_path: str, text: str, lines: list[str], start: int, end: int, admin_actions: set[str]
+) -> list[BolaCandidate]:
+    """BOLA candidates in one handler."""
+    header = "\n".join(lines[max(0, start - HEADER_LINES) : start])
+    body = lines[start:end]
+    scope = header + "\n" + "\n".join(body)
+    tainted: dict[str, list[TraceStep]] = {
+        name: [TraceStep("input", start + 1, f"parameter {name}")] for name in _parameters(lines, start, header, text)
+    }
+    references = _references(tainted)
+    found = []
+    for offset, line in enumerate(body):
+        if len(line) > MAX_LINE_LENGTH:
+            continue
+        number = start + offset + 1
+        code = line.strip()
+        destructured = DESTRUCTURE.search(line)
+        if destructured:
+            for name in re.split(r"\s*,\s*", destructured.group(1)):
+                name = name.split(":")[-1].strip()
+                if ID_NAME.search(name):
+                    tainted.setdefault(name, [TraceStep("input", number, _short(code))])
+            references = _references(tainted)
+
+        for fetch in FETCH.finditer(line):
+            receiver = line[max(0, fetch.start() - 60) : fetch.start()]
+            if not KEYED_FETCH.search(fetch.group(1)) and (NOT_A_STORE.search(receiver) or not RECEIVER.search(receiver)):
+                continue
+            arguments = line[fetch.end() - 1 : group(line, fetch.end() - 1)]
+            statement = line[: fetch.start()] + arguments
+            used = references.search(arguments) if references else None
+            direct = None if used else _direct_id(arguments)
+            if not used and not direct:
+                continue
+            if SCOPED.search(statement):
+                continue
+            trace = list(tainted[used.group(1)]) if used else [TraceStep("input", number, _short(direct))]
+            trace.append(TraceStep("lookup", number, _short(code)))
+            found.append((offset, fetch.group(1).replace(" ", ""), used.group(1) if used else direct, trace))
+
+        assignment = ASSIGNMENT.search(line)
+        if assignment and not FETCH.search(assignment.group(2)):
+            name, value = assignment.groups()
+            source = references.search(value) if references else None
+            direct = None if source else _direct_id(value)
+            # Request reads taint ID-like names; anything assigned from a tainted name is tainted
+            if (direct and ID_NAME.search(name)) or (source and name not in tainted):
+                steps = list(tainted[source.group(1)]) if source else []
+                steps.append(TraceStep("input" if direct else "assignment", number, _short(code)))
+                tainted[name] = steps
+                references = _references(tainted)
+
+    if not found or OWNERSHIP_CHECK.search(scope):
+        return []
+    routes = EndpointMappingService.find_routes(header + "\n" + lines[start])
+    endpoint = f"{routes[0][0]} {routes[0][1]}" if routes else None
+    function = _handler_name(lines[start])
+    admin_only = ADMIN_ROLE.search(scope) or "*" in admin_actions or function in admin_actions
+    handler = function or (f"Handler for {endpoint}" if endpoint else "Handler")
+    candidates = []
+    for offset, lookup, identifier, trace in found:
+        assigned = ASSIGNMENT.search(body[offset][: body[offset].find(lookup)])
+        result = re.compile(rf"(?<![\w.$]){re.escape(assigned.group(1))}(?![\w$])") if assigned else None
+        writes = bool(WRITE_FETCH.search(lookup))
+        for later in range(offset + 1, len(body)):
+            line = body[later]
+            if not result or len(line) > MAX_LINE_LENGTH or not result.search(line):
+                continue
+            if WRITE.search(line):
+                trace.append(TraceStep("use", start + later + 1, _short(line)))
+                writes = True
+                break
+            if RESPONSE.search(line):
+                trace.append(TraceStep("use", start + later + 1, _short(line)))
+                break
+        severity = "low" if admin_only else "high" if writes else "medium"
+        description = (
+            f"{handler} passes client-supplied {identifier} to {lookup}() with no ownership or tenancy check"
+            + (", so any caller can modify the object" if writes else ", so any caller can read the object")
+            + (" (restricted to administrators)" if admin_only else "")
+        )
+        line_number = start + offset + 1
+        candidates.append(
+            BolaCandidate(file_path, function, line_number, line_number, identifier, lookup, endpoint, severity, description, trace)
+        )
+    return candidates
+
+
+def detect_bola(file_path: str, text: str) -> list[BolaCandidate]:
+    """Detect lookups by client-supplied IDs no ownership check covers in a source file.
+
+    Args:
+        file_path: Relative path of the file
+        text: File content
+
+    Returns:
+        Candidates in file order, with the dataflow trace of each
+    """
+    if not FETCH.search(text) or FILE_CHECK.search(text):
+        return []
+    lines = text.split("\n")
+    candidates: list[BolaCandidate] = []
+    admin_actions = _admin_actions(text)
+    start = _next_handler(lines, 0)
+    while start is not None:
+        end = _handler_end(lines, start)
+        candidates.extend(_candidates_in(file_path, text, lines, start, end, admin_actions))
+        start = _next_handler(lines, end)
+    return candidates
+
+
+def bola_candidates(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the BOLA candidates across a repository clone's source files."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in ROUTE_FILE_EXTENSIONS:
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        yield from (asdict(c) for c in detect_bola(relative.as_posix(), path.read_text(encoding="utf-8", errors="ignore")))
+
+
+class BolaDetectionService:
+    """Scans repository clones for broken object level authorization candidates."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[BolaCandidate]:
+        """Detect BOLA candidates across a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Candidates across the clone's source files
+        """
+        return [
+            BolaCandidate(**{**c, "trace": [TraceStep(**t) for t in c["trace"]]})
+            for c in run_pass(root, bola_candidates)
+        ]
+
+    def candidates(self, repository_id: int | None = None, severity: str | None = None) -> dict:
+        """BOLA candidates across the tenant's repositories.
+
+        Args:
+            repository_id: Restrict to one repository
+            severity: Keep only this severity
+
+        Returns:
+            Candidates with their dataflow traces, most severe first, and counts per severity
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        results, scanned = [], 0
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            if not root.is_dir():
+                continue
+            scanned += 1
+            found = self.scan_clone(root)
+            logger.info("bola_candidates_detected", repository_id=repository.id, candidates=len(found))
+            results.extend({**asdict(c), "repository_id": repository.id, "repository_name": repository.name} for c in found)
+
+        if severity is not None:
+            results = [r for r in results if r["severity"] == severity.lower()]
+        results.sort(key=lambda r: (SEVERITY_ORDER[r["severity"]], r["repository_id"], r["file_path"], r["line_start"]))
+
+        by_severity: dict[str, int] = {}
+        for result in results:
+            by_severity[result["severity"]] = by_severity.get(result["severity"], 0) + 1
+        return {
+            "findings": results,
+            "summary": {
+                "total": len(results),
+                "repositories_scanned": scanned,
+                "repositories_with_findings": len({r["repository_id"] for r in results}),
+                "by_severity": by_severity,
+            },
+        }
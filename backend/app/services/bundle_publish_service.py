@@ -0,0 +1,534 @@
+"""Service for publishing approved policies as signed OPA bundles.
+
+Closes the loop from mining to enforcement: approved policies are exported
+to Rego by the deterministic exporter (RegoExportService: one package per
+service under ``policy_miner.services``, one rule per endpoint), packaged as
+an OPA bundle with a ``policy_miner.allow`` rule that combines them,
+optionally signed, and pushed to a target:
+
+- An OPA bundle server: the archive is PUT to an HTTP endpoint that OPA's
+  bundle plugin polls (nginx, S3-compatible storage, or a bundle service).
+- An OCI registry: the archive is pushed as an OCI artifact with the layer
+  and config media types OPA's ``type: oci`` downloader expects, tagged with
+  the bundle version and ``latest``.
+
+Every publication gets the next version number for its target and a revision
+written to the bundle manifest, so OPA status reports show exactly which
+publication a decision came from. The same approved policies always give the
+same modules, so a bundle only changes when they do. Signatures follow the OPA
+bundle signing format (a JWT over the SHA-256 of every file in
+``.signatures.json``).
+
+Approving policies republishes auto-publish targets from a Celery task
+(app.tasks.bundle_tasks), never inside the approval request.
+"""
+
+import gzip
+import hashlib
+import io
+import json
+import re
+import tarfile
+from datetime import UTC, datetime
+from urllib.parse import urljoin, urlparse
+
+import httpx
+import structlog
+from jose import jwt
+from sqlalchemy import func
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import ensure_host_allowed
+from app.models.bundle_publication import (
+    BundlePublication,
+    BundleTarget,
+    BundleTargetType,
+    PublicationStatus,
+)
+from app.models.policy import Policy, PolicyStatus, SourceType
+
+logger = structlog.get_logger(__name__)
+
+BUNDLE_ROOT = "policy_miner"
+SIGNATURES_FILE = ".signatures.json"
+
+OCI_MANIFEST_MEDIA_TYPE = "application/vnd.oci.image.manifest.v1+json"
+OCI_CONFIG_MEDIA_TYPE = "application/vnd.oci.image.config.v1+json"
+OCI_LAYER_MEDIA_TYPE = "application/vnd.oci.image.layer.v1.tar+gzip"
+
+REGO_PACKAGE = re.compile(r"^\s*package\s+\S+", re.MULTILINE)
+
+# Combines the per-policy modules; translations use pre-1.0 Rego syntax
+AGGREGATE_MODULE = f"""package {BUNDLE_ROOT}
+
+default allow = false
+
+allow {{
+    data.{BUNDLE_ROOT}.policies[_].allow
+}}
+"""
+
+# Combines the exporter's per-service packages, which import rego.v1
+SERVICES_MODULE = f"""package {BUNDLE_ROOT}
+
+import rego.v1
+
+default allow := false
+
+allow if data.{BUNDLE_ROOT}.services[_].allow
+"""
+
+
+def canonical_json(value) -> bytes:
+    """Serialize JSON the way OPA hashes it (sorted keys, no whitespace)."""
+    return json.dumps(value, sort_keys=True, separators=(",", ":")).encode()
+
+
+def package_module(policy_id: int, rego: str) -> str:
+    """Move a translated Rego module under the bundle root.
+
+    Args:
+        policy_id: Policy the module was translated from
+        rego: Translated Rego source
+
+    Returns:
+        Rego source in package ``policy_miner.policies.policy_<id>``
+    """
+    package = f"package {BUNDLE_ROOT}.policies.policy_{policy_id}"
+    if REGO_PACKAGE.search(rego):
+        return REGO_PACKAGE.sub(package, rego, count=1).rstrip() + "\n"
+    return f"{package}\n\n{rego.strip()}\n"
+
+
+def bundle_files(modules: dict[int, str], revision: str) -> dict[str, bytes]:
+    """Lay out the files of a bundle.
+
+    Args:
+        modules: Policy ID to packaged Rego module
+        revision: Bundle revision for the manifest
+
+    Returns:
+        Bundle-relative path to file content
+    """
+    files = {
+        ".manifest": _manifest(revision, sorted(modules)),
+        f"{BUNDLE_ROOT}/main.rego": AGGREGATE_MODULE.encode(),
+    }
+    for policy_id, module in sorted(modules.items()):
+        files[f"{BUNDLE_ROOT}/policies/policy_{policy_id}.rego"] = module.encode()
+    return files
+
+
+def export_bundle_files(packages: list[dict], revision: str) -> dict[str, bytes]:
+    """Lay out the files of a bundle of the Rego exporter's service packages.
+
+    Args:
+        packages: Packages from RegoExportService.packages
+        revision: Bundle revision for the manifest
+
+    Returns:
+        Bundle-relative path to file content
+    """
+    policy_ids = sorted(i for package in packages for i in package["policy_ids"])
+    files = {".manifest": _manifest(revision, policy_ids), f"{BUNDLE_ROOT}/main.rego": SERVICES_MODULE.encode()}
+    for package in packages:
+        files[package["path"]] = package["rego"].encode()
+    return files
+
+
+def _manifest(revision: str, policy_ids: list[int]) -> bytes:
+    """The .manifest of a bundle holding the given policies."""
+    manifest = {
+        "revision": revision,
+        "roots": [BUNDLE_ROOT],
+        "rego_version": 0,
+        "metadata": {"policy_ids": policy_ids, "generated_by": "policy-miner"},
+    }
+    return canonical_json(manifest)
+
+
+def sign_files(files: dict[str, bytes], algorithm: str, key: str, key_id: str | None = None) -> bytes:
+    """Build the ``.signatures.json`` file for a bundle.
+
+    Args:
+        files: Bundle files to sign
+        algorithm: JWT algorithm (HS256, RS256, ES256)
+        key: HMAC secret or PEM private key
+        key_id: Key ID OPA uses to find the verification key
+
+    Returns:
+        Content of .signatures.json
+    """
+    payload = {
+        "files": [
+            {"name": name, "hash": hashlib.sha256(content).hexdigest(), "algorithm": "SHA-256"}
+            for name, content in sorted(files.items())
+        ]
+    }
+    headers = {"kid": key_id} if key_id else None
+    if key_id:
+        payload["keyid"] = key_id
+    token = jwt.encode(payload, key, algorithm=algorithm, headers=headers)
+    return canonical_json({"signatures": [token]})
+
+
+def build_archive(files: dict[str, bytes]) -> bytes:
+    """Pack bundle files into a reproducible gzipped tarball.
+
+    Args:
+        files: Bundle-relative path to content
+
+    Returns:
+        Bundle archive bytes
+    """
+    buffer = io.BytesIO()
+    with gzip.GzipFile(fileobj=buffer, mode="wb", mtime=0) as gz, tarfile.open(fileobj=gz, mode="w") as tar:
+        for name, content in sorted(files.items()):
+            info = tarfile.TarInfo(f"/{name}")
+            info.size = len(content)
+            info.mode = 0o644
+            tar.addfile(info, io.BytesIO(content))
+    return buffer.getvalue()
+
+
+def _digest(content: bytes) -> str:
+    """OCI content digest."""
+    return f"sha256:{hashlib.sha256(content).hexdigest()}"
+
+
+class OciRegistryClient:
+    """Minimal OCI distribution client with basic and token auth."""
+
+    def __init__(self, client: httpx.AsyncClient, base_url: str, username: str | None, secret: str | None):
+        """Initialize registry client."""
+        self.client = client
+        self.base_url = base_url.rstrip("/")
+        self.username = username
+        self.secret = secret
+        self.token: str | None = None
+
+    async def request(self, method: str, url: str, **kwargs) -> httpx.Response:
+        """Send a request, answering a Bearer challenge once if the registry issues one."""
+        headers = dict(kwargs.pop("headers", {}))
+        if self.token:
+            headers["Authorization"] = f"Bearer {self.token}"
+        elif self.username and self.secret:
+            kwargs["auth"] = (self.username, self.secret)
+        elif self.secret:
+            headers["Authorization"] = f"Bearer {self.secret}"
+
+        response = await self.client.request(method, url, headers=headers, timeout=60.0, **kwargs)
+        challenge = response.headers.get("www-authenticate", "")
+        if response.status_code == 401 and challenge.lower().startswith("bearer") and not self.token:
+            params = dict(re.findall(r'(\w+)="([^"]*)"', challenge))
+            realm = params.pop("realm", None)
+            if realm:
+                auth = (self.username, self.secret) if self.username and self.secret else None
+                token_response = await self.client.get(realm, params=params, auth=auth, timeout=30.0)
+                token_response.raise_for_status()
+                body = token_response.json()
+                self.token = body.get("token") or body.get("access_token")
+                kwargs.pop("auth", None)
+                headers["Authorization"] = f"Bearer {self.token}"
+                response = await self.client.request(method, url, headers=headers, timeout=60.0, **kwargs)
+        return response
+
+    async def push_blob(self, repository: str, content: bytes) -> str:
+        """Upload a blob unless the registry already has it.
+
+        Args:
+            repository: Repository path
+            content: Blob content
+
+        Returns:
+            Blob digest
+        """
+        digest = _digest(content)
+        existing = await self.request("HEAD", f"{self.base_url}/v2/{repository}/blobs/{digest}")
+        if existing.status_code == 200:
+            return digest
+
+        started = await self.request("POST", f"{self.base_url}/v2/{repository}/blobs/uploads/")
+        if started.status_code != 202:
+            raise ValueError(f"Registry refused blob upload ({started.status_code}): {started.text}")
+        location = urljoin(f"{self.base_url}/", started.headers["location"])
+        separator = "&" if "?" in location else "?"
+        uploaded = await self.request(
+            "PUT",
+            f"{location}{separator}digest={digest}",
+            content=content,
+            headers={"Content-Type": "application/octet-stream"},
+        )
+        if uploaded.status_code not in (201, 204):
+            raise ValueError(f"Registry rejected blob {digest} ({uploaded.status_code}): {uploaded.text}")
+        return digest
+
+    async def push_manifest(self, repository: str, tag: str, manifest: bytes) -> None:
+        """Upload a manifest under a tag."""
+        response = await self.request(
+            "PUT",
+            f"{self.base_url}/v2/{repository}/manifests/{tag}",
+            content=manifest,
+            headers={"Content-Type": OCI_MANIFEST_MEDIA_TYPE},
+        )
+        if response.status_code not in (200, 201):
+            raise ValueError(f"Registry rejected manifest {tag} ({response.status_code}): {response.text}")
+
+
+class BundlePublishService:
+    """Builds and publishes policy bundles to bundle targets."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def create_target(self, data: dict) -> BundleTarget:
+        """Register a bundle target.
+
+        Args:
+            data: Target fields
+
+        Returns:
+            Created target
+
+        Raises:
+            ValueError: If an OCI target has no repository or signing is half-configured
+        """
+        if data["target_type"] == BundleTargetType.OCI_REGISTRY and not data.get("repository"):
+            raise ValueError("OCI registry targets require a repository")
+        if bool(data.get("signing_algorithm")) != bool(data.get("signing_key")):
+            raise ValueError("Signing needs both an algorithm and a key")
+        target = BundleTarget(tenant_id=self.tenant_id, **data)
+        self.db.add(target)
+        self.db.commit()
+        self.db.refresh(target)
+        logger.info("bundle_target_created", target_id=target.id, target_type=target.target_type)
+        return target
+
+    def list_targets(self) -> list[BundleTarget]:
+        """List the tenant's bundle targets."""
+        return self._query(BundleTarget).order_by(BundleTarget.id).all()
+
+    def get_target(self, target_id: int) -> BundleTarget:
+        """Get a bundle target.
+
+        Args:
+            target_id: Target ID
+
+        Returns:
+            Bundle target
+
+        Raises:
+            ValueError: If the target does not exist
+        """
+        target = self._query(BundleTarget).filter(BundleTarget.id == target_id).first()
+        if not target:
+            raise ValueError(f"Bundle target {target_id} not found")
+        return target
+
+    def delete_target(self, target_id: int) -> None:
+        """Delete a bundle target and its publication history."""
+        target = self.get_target(target_id)
+        self.db.query(BundlePublication).filter(BundlePublication.target_id == target.id).delete(
+            synchronize_session=False
+        )
+        self.db.delete(target)
+        self.db.commit()
+
+    def list_publications(self, target_id: int) -> list[BundlePublication]:
+        """Publication history of a target, newest first."""
+        target = self.get_target(target_id)
+        return (
+            self.db.query(BundlePublication)
+            .filter(BundlePublication.target_id == target.id)
+            .order_by(BundlePublication.version.desc())
+            .all()
+        )
+
+    def approved_policies(self, target: BundleTarget) -> list[Policy]:
+        """Approved policies in a target's scope, as confident as the target requires.
+
+        Database policies are left out, since they are enforced by grants rather than by OPA.
+        """
+        query = self._query(Policy).filter(
+            Policy.status == PolicyStatus.APPROVED, Policy.source_type != SourceType.DATABASE
+        )
+        if target.application_id is not None:
+            query = query.filter(Policy.application_id == target.application_id)
+        if target.min_confidence is not None:
+            query = query.filter(Policy.confidence_score >= target.min_confidence)
+        return query.order_by(Policy.id).all()
+
+    def build_bundle(self, target: BundleTarget, revision: str) -> tuple[bytes, list[int]]:
+        """Export a target's approved policies to Rego and pack them into a bundle.
+
+        Args:
+            target: Bundle target (scope and signing settings)
+            revision: Revision to record in the manifest
+
+        Returns:
+            Bundle archive and the IDs of the policies it contains
+
+        Raises:
+            ValueError: If there are no approved policies
+        """
+        # The exporter packs its own archives with build_archive, so it is imported here
+        from app.services.rego_export_service import RegoExportService
+
+        policies = self.approved_policies(target)
+        if not policies:
+            raise ValueError("No approved policies to publish")
+
+        packages = RegoExportService(self.db, self.tenant_id).packages(policies)
+        files = export_bundle_files(packages, revision)
+        if target.signing_algorithm:
+            algorithm = getattr(target.signing_algorithm, "value", target.signing_algorithm)
+            files[SIGNATURES_FILE] = sign_files(files, algorithm, target.signing_key, target.signing_key_id)
+        return build_archive(files), sorted(p.id for p in policies)
+
+    async def publish(self, target_id: int, triggered_by: str | None = None) -> BundlePublication:
+        """Build the next bundle version for a target and push it.
+
+        A failed push is recorded on the publication rather than raised, so
+        the history shows every attempt.
+
+        Args:
+            target_id: Target ID
+            triggered_by: User email, or "approval" for auto-publish
+
+        Returns:
+            Publication record
+
+        Raises:
+            ValueError: If the target does not exist or there is nothing to publish
+        """
+        target = self.get_target(target_id)
+        latest = (
+            self.db.query(func.max(BundlePublication.version))
+            .filter(BundlePublication.target_id == target.id)
+            .scalar()
+        )
+        version = (latest or 0) + 1
+        revision = f"{target.bundle_name}-v{version}-{datetime.now(UTC):%Y%m%dT%H%M%SZ}"
+        archive, policy_ids = self.build_bundle(target, revision)
+
+        publication = BundlePublication(
+            tenant_id=self.tenant_id,
+            target_id=target.id,
+            version=version,
+            revision=revision,
+            status=PublicationStatus.PENDING,
+            policy_ids=policy_ids,
+            digest=_digest(archive),
+            signed=bool(target.signing_algorithm),
+            triggered_by=triggered_by,
+        )
+        self.db.add(publication)
+        self.db.flush()
+
+        try:
+            ensure_host_allowed(urlparse(target.url).hostname, "bundle_publish")
+            async with httpx.AsyncClient() as client:
+                if target.target_type == BundleTargetType.OCI_REGISTRY:
+                    publication.location = await self._push_oci(client, target, archive, version, revision)
+                else:
+                    publication.location = await self._push_bundle_server(client, target, archive, revision)
+            publication.status = PublicationStatus.SUCCESS
+            publication.published_at = datetime.now(UTC)
+        except Exception as e:
+            publication.status = PublicationStatus.FAILED
+            publication.error_message = str(e)
+            logger.error("bundle_publish_failed", target_id=target.id, version=version, error=str(e))
+        self.db.commit()
+
+        logger.info(
+            "bundle_published",
+            target_id=target.id,
+            version=version,
+            status=publication.status.value,
+            policies=len(policy_ids),
+            signed=publication.signed,
+        )
+        return publication
+
+    async def publish_on_approval(self, policy_ids: list[int]) -> list[BundlePublication]:
+        """Republish auto-publish targets covering newly approved policies.
+
+        Args:
+            policy_ids: Policies just approved
+
+        Returns:
+            Publications made (failures included)
+        """
+        policies = self._query(Policy).filter(Policy.id.in_(policy_ids)).all()
+        application_ids = {p.application_id for p in policies}
+        publications = []
+        for target in self._query(BundleTarget).filter(BundleTarget.auto_publish.is_(True)).all():
+            if target.application_id is not None and target.application_id not in application_ids:
+                continue
+            try:
+                publications.append(await self.publish(target.id, triggered_by="approval"))
+            except ValueError as e:
+                logger.warning("bundle_auto_publish_skipped", target_id=target.id, reason=str(e))
+        return publications
+
+    @staticmethod
+    async def _push_bundle_server(
+        client: httpx.AsyncClient, target: BundleTarget, archive: bytes, revision: str
+    ) -> str:
+        """PUT a bundle to an HTTP bundle endpoint.
+
+        Returns:
+            URL the bundle was uploaded to
+        """
+        url = target.url if target.url.endswith(".tar.gz") else f"{target.url.rstrip('/')}/{target.bundle_name}.tar.gz"
+        headers = {"Content-Type": "application/gzip", "X-Bundle-Revision": revision}
+        if target.secret:
+            headers["Authorization"] = f"Bearer {target.secret}"
+        response = await client.put(url, content=archive, headers=headers, timeout=60.0)
+        if response.status_code not in (200, 201, 204):
+            raise ValueError(f"Bundle server returned {response.status_code}: {response.text}")
+        return url
+
+    @staticmethod
+    async def _push_oci(
+        client: httpx.AsyncClient, target: BundleTarget, archive: bytes, version: int, revision: str
+    ) -> str:
+        """Push a bundle to an OCI registry tagged with its version and latest.
+
+        Returns:
+            OCI reference of the versioned tag
+        """
+        registry = OciRegistryClient(client, target.url, target.username, target.secret)
+        config = canonical_json({})
+        config_digest = await registry.push_blob(target.repository, config)
+        layer_digest = await registry.push_blob(target.repository, archive)
+        manifest = canonical_json(
+            {
+                "schemaVersion": 2,
+                "mediaType": OCI_MANIFEST_MEDIA_TYPE,
+                "config": {"mediaType": OCI_CONFIG_MEDIA_TYPE, "digest": config_digest, "size": len(config)},
+                "layers": [
+                    {
+                        "mediaType": OCI_LAYER_MEDIA_TYPE,
+                        "digest": layer_digest,
+                        "size": len(archive),
+                        "annotations": {"org.opencontainers.image.title": f"{target.bundle_name}.tar.gz"},
+                    }
+                ],
+                "annotations": {"org.opencontainers.image.revision": revision},
+            }
+        )
+        tag = f"v{version}"
+        for name in (tag, "latest"):
+            await registry.push_manifest(target.repository, name, manifest)
+        host = urlparse(target.url).netloc or target.url
+        return f"{host}/{target.repository}:{tag}"
@@ -0,0 +1,502 @@
+"""Extract Casbin authorization from enforcer call sites and the files they load.
+
+Casbin keeps rules out of the code that enforces them. The code builds an
+enforcer from a model (.conf) and a policy (usually CSV), e.g.
+casbin.NewEnforcer("model.conf", "policy.csv"), and asks it for decisions
+with enforcer.Enforce(sub, obj, act). Scanning the code alone only finds
+the call sites, and scanning the CSV alone loses which service enforces the
+rows and how the model's matcher compares them. This extractor resolves the
+files each enforcer loads, reads request and policy definitions and matchers
+from the model, and turns every policy row into a ConfigFinding whose
+evidence points at the row, the model's matcher, and the Enforce call sites
+the row can decide.
+"""
+
+import csv
+import fnmatch
+import posixpath
+import re
+from bisect import bisect_right
+from dataclasses import dataclass, field
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding, ConfigKind
+
+# Sources that may build enforcers or call them
+CASBIN_SUFFIXES = (".go", ".py", ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".java", ".kt", ".cs", ".php", ".rs")
+# Files enforcers load models and policies from
+MODEL_SUFFIX = ".conf"
+POLICY_SUFFIX = ".csv"
+
+# Constructor and Enforce arguments are read at most this far past the opening parenthesis
+MAX_ARGUMENT_SPAN = 500
+MAX_SNIPPET_LENGTH = 300
+
+CONSTRUCTOR = re.compile(
+    r"(?<![\w$.])(?:new\s+)?(?:casbin\s*\.\s*)?(?:New|new)?(?:SyncedCached|Synced|Cached|Async|Distributed)?Enforcer\s*\("
+)
+# Variable a constructor's result is assigned to, read back from the text before it on its line
+ASSIGNED = re.compile(
+    r"([A-Za-z_$][\w$]*)\s*(?:,\s*[A-Za-z_$][\w$]*\s*)?(?::\s*[\w$.<>]+\s*)?:?=\s*(?:await\s+)?(?:new\s+)?(?:casbin\s*\.\s*)?$"
+)
+DEFINITION_PREFIX = re.compile(r"\b(?:func|def|function|class|fn)\s+$|\)\s*$")
+ENFORCE_CALL = re.compile(
+    r"(?<![\w$])([A-Za-z_$][\w$]*+)\s*\.\s*(?:[Ee]nforce(?:Ex)?(?:Async)?|enforce_ex|enforce_async)\s*\("
+)
+ADD_POLICY = re.compile(
+    r"(?<![\w$])([A-Za-z_$][\w$]*+)\s*\.\s*(?:(?:Add|add)(?:Policy|PermissionForUser)|add_(?:policy|permission_for_user))\s*\("
+)
+ADD_GROUPING = re.compile(
+    r"(?<![\w$])([A-Za-z_$][\w$]*+)\s*\.\s*(?:(?:Add|add)(?:GroupingPolicy|RoleForUser)|add_(?:grouping_policy|role_for_user))\s*\("
+)
+STRING_LITERAL = re.compile(r"""^[rfb]?(['"`])([^'"`\n]*)\1$""")
+PATH_LITERAL = re.compile(r"""['"`]([^'"`\n]{1,300}?\.(?:conf|csv))['"`]""")
+IDENTIFIER = re.compile(r"^[A-Za-z_$][\w$]*$")
+POLICY_ROW = re.compile(r"^[pg]\d*\s*,")
+
+SECTION = re.compile(r"^\s*\[(\w+)\]\s*$")
+DEFINITION = re.compile(r"^\s*(\w+)\s*=\s*(.+?)\s*$")
+# The parts of a matcher every model has; anything left over is an attribute or custom check
+STANDARD_MATCHER_PART = re.compile(
+    r"\bg\d*\s*\(\s*r\.\w+\s*,\s*p\.\w+(?:\s*,\s*[rp]\.\w+)?\s*\)|"
+    r"\b(?:keyMatch\d?|regexMatch|globMatch)\s*\(\s*r\.\w+\s*,\s*p\.\w+\s*\)|"
+    r"\br\.\w+\s*==\s*p\.\w+|\bp\.\w+\s*==\s*r\.\w+|&&|\|\||[()\s]"
+)
+MATCH_FUNCTION = re.compile(r"\b(keyMatch\d?|regexMatch|globMatch)\s*\(\s*r\.(\w+)\s*,\s*p\.(\w+)\s*\)")
+EQUALITY = re.compile(r"\br\.(\w+)\s*==\s*p\.(\w+)|\bp\.(\w+)\s*==\s*r\.(\w+)")
+
+
+@dataclass
+class CasbinModel:
+    """Request and policy definitions and matcher of a Casbin model file."""
+
+    file_path: str
+    request: list[str]
+    policy: dict[str, list[str]]
+    effect: str | None
+    matcher: str
+    matcher_line: int
+
+
+@dataclass
+class EnforcerSite:
+    """Where an enforcer is built and the files it is built from."""
+
+    file_path: str
+    line: int
+    snippet: str
+    variable: str | None
+    model_path: str | None = None
+    policy_path: str | None = None
+
+
+@dataclass
+class EnforceCall:
+    """A call asking an enforcer for a decision."""
+
+    file_path: str
+    line: int
+    snippet: str
+    receiver: str
+    arguments: list[str] = field(default_factory=list)
+
+
+@dataclass
+class CasbinScan:
+    """Everything resolved from a repository's Casbin usage."""
+
+    findings: list[ConfigFinding]
+    enforcers: list[EnforcerSite]
+    calls: list[EnforceCall]
+    models: list[str]
+    policy_files: list[str]
+    unresolved_calls: int
+
+
+class _Text:
+    """A source file with a line index."""
+
+    def __init__(self, file_path: str, text: str):
+        self.file_path = file_path
+        self.text = text
+        self.starts = [0] + [m.end() for m in re.finditer("\n", text)]
+
+    def line_of(self, offset: int) -> int:
+        """1-based line of an offset."""
+        return bisect_right(self.starts, offset)
+
+    def line_text(self, offset: int) -> str:
+        """The stripped line an offset is on, truncated for snippets."""
+        start = self.text.rfind("\n", 0, offset) + 1
+        end = self.text.find("\n", offset)
+        return self.text[start : end if end != -1 else len(self.text)].strip()[:MAX_SNIPPET_LENGTH]
+
+
+def _arguments(text: str, open_paren: int) -> list[str] | None:
+    """Top-level arguments of the call whose parenthesis opens at open_paren, or None if it does not close nearby."""
+    depth, quote, start = 0, None, open_paren + 1
+    arguments = []
+    for i in range(open_paren + 1, min(len(text), open_paren + 1 + MAX_ARGUMENT_SPAN)):
+        char = text[i]
+        if quote:
+            if char == quote and text[i - 1] != "\\":
+                quote = None
+        elif char in "'\"`":
+            quote = char
+        elif char in "([{":
+            depth += 1
+        elif char in ")]}":
+            if depth == 0:
+                last = text[start:i].strip()
+                return arguments + [last] if last or arguments else arguments
+            depth -= 1
+        elif char == "," and depth == 0:
+            arguments.append(text[start:i].strip())
+            start = i + 1
+    return None
+
+
+def _literal(argument: str) -> str | None:
+    """Value of a plain string literal argument."""
+    match = STRING_LITERAL.match(argument)
+    return match[2] if match else None
+
+
+def _file_reference(source: _Text, argument: str, suffix: str) -> str | None:
+    """Model or policy path an argument names, directly, inside a call, or through a variable."""
+    for match in PATH_LITERAL.finditer(argument):
+        if match[1].endswith(suffix):
+            return match[1]
+    if IDENTIFIER.match(argument):
+        assignment = re.search(
+            rf"(?<![\w$]){re.escape(argument)}\s*(?::\s*[\w$.<>]+\s*)?:?=\s*['\"`]([^'\"`\n]{{1,300}}?{re.escape(suffix)})['\"`]",
+            source.text,
+        )
+        if assignment:
+            return assignment[1]
+    return None
+
+
+def _resolve(reference: str | None, source_path: str, paths: set[str]) -> str | None:
+    """Repository path of a file referenced from a source, relative to it, to the root, or by a unique suffix."""
+    if not reference:
+        return None
+    reference = reference.replace("\\", "/")
+    candidates = [
+        posixpath.normpath(posixpath.join(posixpath.dirname(source_path), reference)),
+        posixpath.normpath(reference.lstrip("/")),
+    ]
+    for candidate in candidates:
+        if candidate in paths:
+            return candidate
+    tail = posixpath.normpath(reference).lstrip("./").lstrip("/")
+    suffix_matches = [p for p in paths if p == tail or p.endswith("/" + tail)]
+    if len(suffix_matches) == 1:
+        return suffix_matches[0]
+    name = PurePosixPath(reference).name
+    name_matches = [p for p in paths if PurePosixPath(p).name == name]
+    return name_matches[0] if len(name_matches) == 1 else None
+
+
+def parse_model(file_path: str, text: str) -> CasbinModel | None:
+    """Read a Casbin model file.
+
+    Args:
+        file_path: Path of the model file
+        text: File content
+
+    Returns:
+        The model, or None if the file has no policy definition and matcher
+    """
+    section = None
+    request: list[str] = []
+    policy: dict[str, list[str]] = {}
+    effect = None
+    matcher, matcher_line = None, 0
+    for number, line in enumerate(text.splitlines(), start=1):
+        if line.lstrip().startswith("#"):
+            continue
+        header = SECTION.match(line)
+        if header:
+            section = header[1]
+            continue
+        definition = DEFINITION.match(line)
+        if not definition:
+            continue
+        key, value = definition[1], definition[2]
+        tokens = [t.strip() for t in value.split(",")]
+        if section == "request_definition" and key == "r":
+            request = tokens
+        elif section == "policy_definition":
+            policy[key] = tokens
+        elif section == "policy_effect":
+            effect = value
+        elif section == "matchers" and key == "m":
+            matcher, matcher_line = value, number
+    if not policy or matcher is None:
+        return None
+    return CasbinModel(file_path, request or ["sub", "obj", "act"], policy, effect, matcher, matcher_line)
+
+
+def _matcher_conditions(model: CasbinModel) -> list[str]:
+    """Conditions a model's matcher adds beyond comparing request and rule fields."""
+    conditions = [f"{p_field} matched with {function}" for function, _, p_field in MATCH_FUNCTION.findall(model.matcher)]
+    if STANDARD_MATCHER_PART.sub("", model.matcher):
+        conditions.append(f"matcher {model.matcher}")
+    return conditions
+
+
+def _pattern_matches(function: str, pattern: str, value: str) -> bool:
+    """Whether a request value matches a rule value under a Casbin match function."""
+    if function == "globMatch":
+        return fnmatch.fnmatchcase(value, pattern)
+    if function == "regexMatch":
+        try:
+            return re.search(pattern, value) is not None
+        except re.error:
+            return True
+    expression = re.escape(pattern).replace(r"\*", ".*")
+    expression = re.sub(r"\\\{\w+\\\}", "[^/]+", expression)
+    expression = re.sub(r":\w+", "[^/]+", expression)
+    return re.fullmatch(expression, value) is not None
+
+
+def _comparisons(model: CasbinModel) -> dict[str, tuple[str, str | None]]:
+    """Request field -> (rule field it is compared with, match function or None for equality)."""
+    comparisons: dict[str, tuple[str, str | None]] = {}
+    for match in EQUALITY.finditer(model.matcher):
+        r_field, p_field = (match[1], match[2]) if match[1] else (match[4], match[3])
+        comparisons[r_field] = (p_field, None)
+    for function, r_field, p_field in MATCH_FUNCTION.findall(model.matcher):
+        comparisons[r_field] = (p_field, function)
+    return comparisons
+
+
+def _admits(
+    model: CasbinModel, comparisons: dict[str, tuple[str, str | None]], call: EnforceCall, values: dict[str, str]
+) -> bool:
+    """Whether a rule can decide a call: every literal argument matches the rule field it is compared with."""
+    for name, argument in zip(model.request, call.arguments):
+        literal = _literal(argument)
+        if literal is None or name not in comparisons:
+            continue
+        p_field, function = comparisons[name]
+        rule_value = values.get(p_field)
+        if rule_value is None:
+            continue
+        if function is None and rule_value != literal:
+            return False
+        if function is not None and not _pattern_matches(function, rule_value, literal):
+            return False
+    return True
+
+
+def find_enforcers(source: _Text) -> list[EnforcerSite]:
+    """Enforcers built in a source file."""
+    sites = []
+    for match in CONSTRUCTOR.finditer(source.text):
+        line_start = source.text.rfind("\n", 0, match.start()) + 1
+        prefix = source.text[line_start : match.start()]
+        if DEFINITION_PREFIX.search(prefix):
+            continue
+        arguments = _arguments(source.text, match.end() - 1)
+        if arguments is None:
+            continue
+        assigned = ASSIGNED.search(prefix)
+        site = EnforcerSite(
+            source.file_path, source.line_of(match.start()), source.line_text(match.start()), assigned[1] if assigned else None
+        )
+        for argument in arguments:
+            site.model_path = site.model_path or _file_reference(source, argument, MODEL_SUFFIX)
+            site.policy_path = site.policy_path or _file_reference(source, argument, POLICY_SUFFIX)
+        sites.append(site)
+    return sites
+
+
+def _calls(source: _Text, pattern: re.Pattern, receivers: set[str]) -> list[EnforceCall]:
+    """Calls on enforcers; a receiver counts if it names an enforcer or the file uses Casbin."""
+    uses_casbin = "casbin" in source.text.lower()
+    calls = []
+    for match in pattern.finditer(source.text):
+        receiver = match[1]
+        if not (uses_casbin or receiver in receivers or "enforcer" in receiver.lower()):
+            continue
+        arguments = _arguments(source.text, match.end() - 1)
+        if arguments is None:
+            continue
+        calls.append(
+            EnforceCall(source.file_path, source.line_of(match.start()), source.line_text(match.start()), receiver, arguments)
+        )
+    return calls
+
+
+def _rule(
+    model: CasbinModel | None,
+    file_path: str,
+    line: int,
+    snippet: str,
+    fields: list[str],
+    origin: str,
+) -> tuple[ConfigFinding, dict[str, str]] | None:
+    """Finding for one policy row, and the row's values by policy field name."""
+    if len(fields) < 3:
+        return None
+    ptype, values = fields[0], fields[1:]
+    if ptype.startswith("g"):
+        conditions = f"domain {values[2]}" if len(values) > 2 and values[2] else None
+        resource = f"role {values[1]}" if ptype == "g" else f"{ptype} group {values[1]}"
+        finding = ConfigFinding(
+            kind=ConfigKind.CASBIN_POLICY,
+            file_path=file_path,
+            line_start=line,
+            line_end=line,
+            snippet=snippet,
+            subject=values[0],
+            resource=resource,
+            action="inherit",
+            conditions=conditions,
+            description=f"Casbin {ptype} role assignment{origin}",
+        )
+        return finding, {}
+    if not ptype.startswith("p"):
+        return None
+    names = model.policy.get(ptype) if model else None
+    names = names or ["sub", "obj", "act", "eft"][: max(3, len(values))]
+    by_name = dict(zip(names, values))
+    subject = by_name.get("sub", values[0])
+    resource = by_name.get("obj", values[1] if len(values) > 1 else "")
+    action = by_name.get("act", values[2] if len(values) > 2 else "")
+    if not resource or not action:
+        return None
+    effect = by_name.get("eft", "allow").lower()
+    conditions = [f"domain {by_name['dom']}"] if by_name.get("dom") else []
+    conditions += [f"{name} {value}" for name, value in by_name.items() if name not in ("sub", "obj", "act", "eft", "dom")]
+    if model:
+        conditions += _matcher_conditions(model)
+    finding = ConfigFinding(
+        kind=ConfigKind.CASBIN_POLICY,
+        file_path=file_path,
+        line_start=line,
+        line_end=line,
+        snippet=snippet,
+        subject=subject,
+        resource=resource,
+        action=("deny " if effect == "deny" else "") + action,
+        conditions="; ".join(conditions) or None,
+        description=f"Casbin {ptype} rule{origin}",
+    )
+    return finding, by_name
+
+
+def _policy_rows(text: str) -> list[tuple[int, str, list[str]]]:
+    """(line, raw line, fields) of a policy file's rows."""
+    rows = []
+    for number, line in enumerate(text.splitlines(), start=1):
+        stripped = line.strip()
+        if not POLICY_ROW.match(stripped):
+            continue
+        try:
+            fields = [f.strip() for f in next(csv.reader([stripped], skipinitialspace=True), [])]
+        except csv.Error:
+            continue
+        rows.append((number, stripped, fields))
+    return rows
+
+
+def _describe_origin(model: CasbinModel | None, calls: list[EnforceCall]) -> str:
+    """Description suffix naming the model and the first call site enforcing a rule."""
+    parts = []
+    if model:
+        parts.append(f" under model {PurePosixPath(model.file_path).name}")
+    if calls:
+        parts.append(f" enforced at {calls[0].file_path}:{calls[0].line}")
+    return "".join(parts)
+
+
+def extract_casbin_usage(sources: dict[str, str], files: dict[str, str]) -> CasbinScan:
+    """Resolve Casbin enforcers, their model and policy files, and the rows they enforce.
+
+    Args:
+        sources: Source files that may build or call enforcers, path -> content
+        files: Model (.conf) and policy (.csv) candidates, path -> content
+
+    Returns:
+        Findings per policy row (and per policy added in code), with evidence at
+        the row, the model's matcher, and the Enforce call sites it can decide
+    """
+    texts = [_Text(path, text) for path, text in sources.items() if "enforce" in text.lower()]
+    enforcers = [site for source in texts for site in find_enforcers(source)]
+    variables = {site.variable for site in enforcers if site.variable}
+    calls = [call for source in texts for call in _calls(source, ENFORCE_CALL, variables)]
+
+    paths = set(files)
+    models: dict[str, CasbinModel] = {}
+    for path in sorted(p for p in paths if p.endswith(MODEL_SUFFIX)):
+        model = parse_model(path, files[path])
+        if model:
+            models[path] = model
+    policy_files = sorted(p for p in paths if p.endswith(POLICY_SUFFIX) and _policy_rows(files[p]))
+
+    for site in enforcers:
+        site.model_path = _resolve(site.model_path, site.file_path, set(models))
+        site.policy_path = _resolve(site.policy_path, site.file_path, set(policy_files))
+    # Enforcers built elsewhere (a library, a DI container) still load the repository's only model and policy
+    if calls and not enforcers and len(models) == 1 and len(policy_files) == 1:
+        [model_path], [policy_path] = models, policy_files
+        enforcers.append(EnforcerSite(model_path, models[model_path].matcher_line, "", None, model_path, policy_path))
+
+    def calls_of(site: EnforcerSite) -> list[EnforceCall]:
+        named = [c for c in calls if site.variable and c.receiver == site.variable.split(".")[-1]]
+        if named:
+            return named
+        same_file = [c for c in calls if c.file_path == site.file_path]
+        return same_file or calls
+
+    findings: list[ConfigFinding] = []
+    resolved_calls: set[tuple[str, int]] = set()
+    seen_rows: set[tuple[str, int]] = set()
+    for site in enforcers:
+        if not site.policy_path:
+            continue
+        model = models.get(site.model_path or "")
+        comparisons = _comparisons(model) if model else {}
+        site_calls = calls_of(site)
+        for number, line, fields in _policy_rows(files[site.policy_path]):
+            if (site.policy_path, number) in seen_rows:
+                continue
+            row = _rule(model, site.policy_path, number, line, fields, "")
+            if row is None:
+                continue
+            finding, values = row
+            admitted = [c for c in site_calls if not model or not values or _admits(model, comparisons, c, values)]
+            finding.description += _describe_origin(model, admitted)
+            if model:
+                finding.related_evidence.append((model.file_path, model.matcher_line, model.matcher_line, f"m = {model.matcher}"))
+            if admitted:
+                finding.related_evidence.extend((c.file_path, c.line, c.line, c.snippet) for c in admitted)
+                resolved_calls.update((c.file_path, c.line) for c in admitted)
+            elif site.snippet:
+                finding.related_evidence.append((site.file_path, site.line, site.line, site.snippet))
+            seen_rows.add((site.policy_path, number))
+            findings.append(finding)
+
+    # Rules added through the management API live in code rather than a policy file
+    default_model = next(iter(models.values())) if len(models) == 1 else None
+    for source in texts:
+        for pattern, ptype in ((ADD_POLICY, "p"), (ADD_GROUPING, "g")):
+            for call in _calls(source, pattern, variables):
+                values = [_literal(a) for a in call.arguments]
+                if not values or any(v is None for v in values):
+                    continue
+                row = _rule(default_model, call.file_path, call.line, call.snippet, [ptype, *values], " added in code")
+                if row:
+                    findings.append(row[0])
+
+    return CasbinScan(
+        findings=findings,
+        enforcers=enforcers,
+        calls=calls,
+        models=sorted(models),
+        policy_files=policy_files,
+        unresolved_calls=sum(1 for c in calls if (c.file_path, c.line) not in resolved_calls),
+    )
@@ -0,0 +1,109 @@
+"""Service for importing Casbin models and policies into repository policies.
+
+Repositories that call enforcer.Enforce(sub, obj, act) keep their rules in
+the Casbin model and policy files the enforcer loads (see casbin_extractor).
+Each scan resolves those files from the enforcer constructors, imports every
+policy row, and cites the row, the model's matcher, and the call sites the
+row decides as evidence.
+"""
+
+from collections.abc import Iterator
+from dataclasses import asdict
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.services.casbin_extractor import CASBIN_SUFFIXES, MODEL_SUFFIX, POLICY_SUFFIX, extract_casbin_usage
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.config_policy_service import ConfigPolicyService
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+
+logger = structlog.get_logger(__name__)
+
+# Policies created by this service are tagged with this source
+CASBIN_LABEL = "Casbin model and policy"
+
+
+def load_sources(root: Path) -> tuple[dict[str, str], dict[str, str]]:
+    """Read a clone's Casbin call sites and candidate model and policy files.
+
+    Args:
+        root: Repository clone root
+
+    Returns:
+        (sources mentioning an enforcer, .conf and .csv files), each relative path -> content
+    """
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    sources: dict[str, str] = {}
+    files: dict[str, str] = {}
+    for path in sorted(root.rglob("*")):
+        relative = path.relative_to(root)
+        suffix = path.suffix.lower()
+        if suffix not in CASBIN_SUFFIXES and suffix not in (MODEL_SUFFIX, POLICY_SUFFIX):
+            continue
+        if SKIPPED_DIRECTORIES.intersection(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        text = path.read_text(encoding="utf-8", errors="replace")
+        if suffix in (MODEL_SUFFIX, POLICY_SUFFIX):
+            files[relative.as_posix()] = text
+        elif "enforce" in text.lower():
+            sources[relative.as_posix()] = text
+    return sources, files
+
+
+def mine_checkout(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the Casbin rules a clone's enforcers load and what resolving them found."""
+    scan = extract_casbin_usage(*load_sources(Path(root)))
+    yield {
+        "enforcers": len(scan.enforcers),
+        "enforce_calls": len(scan.calls),
+        "unresolved_calls": scan.unresolved_calls,
+        "models": scan.models,
+        "policy_files": sorted({s.policy_path for s in scan.enforcers if s.policy_path}),
+        "findings": [asdict(f) for f in scan.findings],
+    }
+
+
+class CasbinPolicyService(ConfigPolicyService):
+    """Imports the Casbin rules a repository's enforcers load."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        super().__init__(db, tenant_id)
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def scan_repository(self, repository_id: int) -> dict:
+        """Import the Casbin rules a repository enforces.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Summary of enforcers, Enforce calls, and files found, with merge results
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        (scan,) = run_pass(root, mine_checkout)
+        findings = [ConfigFinding(**f) for f in scan.pop("findings")]
+        merge = self.merge_findings(repo, findings, CASBIN_LABEL, previous=["%"], label_scoped=True)
+
+        logger.info(
+            "casbin_policies_imported",
+            repository_id=repo.id,
+            enforcers=scan["enforcers"],
+            enforce_calls=scan["enforce_calls"],
+            rules=len(findings),
+            tenant_id=self.tenant_id,
+        )
+        return {"repository_id": repo.id, **scan, "rules": len(findings), **merge}
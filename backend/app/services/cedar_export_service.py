@@ -0,0 +1,395 @@
+"""Service for exporting mined policies as Cedar policies and an entity schema.
+
+The export targets Amazon Verified Permissions: one policy store per
+repository, with the repository as the schema's namespace. The entity model
+mirrors how the mined policies read:
+
+- principals are ``User`` entities, members of ``Role`` entities named by
+  their upper-case role name, carrying the attributes conditions read from
+  the caller and an ``authenticated`` flag;
+- resources are domain objects (``Expense``), each a member of the
+  ``Endpoint`` whose route it was reached through, identified by its
+  normalized path (``/api/expenses/{}``);
+- actions are HTTP methods.
+
+A request for ``GET /api/expenses/42`` therefore asks whether the caller's
+User may perform ``Action::"GET"`` on ``Expense::"42"``, passing the expense
+with ``Endpoint::"/api/expenses/{}"`` as its parent. Routes without an
+object yet (listing, creating) pass a new entity of the type.
+
+Every mined policy mapped to an endpoint becomes one ``permit`` statement,
+so each can be created in a policy store on its own:
+
+    permit (
+        principal in Expenses::Role::"MANAGER",
+        action == Expenses::Action::"GET",
+        resource is Expenses::Expense in Expenses::Endpoint::"/api/expenses/{}"
+    )
+    when {
+        resource has amount && resource.amount <= 5000
+    };
+
+Conditions are translated from the clauses ConditionEvaluationService
+understands, guarding each optional attribute with ``has`` so the policies
+validate strictly against the schema. As with the Rego export, a clause that
+cannot be translated makes its statement never match and is listed for
+review, and database policies are not exported.
+"""
+
+import json
+import re
+from dataclasses import dataclass, field, replace
+from decimal import Decimal
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus, SourceType
+from app.models.repository import Repository
+from app.services.bundle_publish_service import build_archive
+from app.services.condition_evaluation_service import ConditionClause, ConditionEvaluationService
+from app.services.endpoint_mapping_service import HTTP_METHODS, EndpointMappingService
+from app.services.rego_export_service import IDENTIFIER, NEGATED_OPERATORS, SUBJECT_CLAUSE
+from app.services.stable_identity_service import normalize_path
+
+logger = structlog.get_logger(__name__)
+
+# Entity types every export declares; domain objects may not reuse their names
+BUILTIN_TYPES = ("User", "Role", "Endpoint")
+
+# Methods of the decimal extension standing in for ordering operators
+DECIMAL_METHODS = {
+    ">": "greaterThan",
+    ">=": "greaterThanOrEqual",
+    "<": "lessThan",
+    "<=": "lessThanOrEqual",
+}
+
+DECIMAL_TYPE = {"type": "Extension", "name": "decimal"}
+
+
+def cedar_name(text: str | None, prefix: str) -> str:
+    """Cedar type or namespace identifier.
+
+    Args:
+        text: e.g. "expense report", "billing-api"
+        prefix: Used alone when the text has no usable characters, and
+            prepended when the name would start with a digit
+
+    Returns:
+        e.g. "ExpenseReport", "BillingApi"
+    """
+    words = re.findall(r"[A-Za-z0-9]+", text or "")
+    name = "".join(w[0].upper() + w[1:] for w in words)
+    if not name:
+        return prefix
+    if name[0].isdigit():
+        name = f"{prefix}{name}"
+    return name
+
+
+def cedar_string(value) -> str:
+    """Cedar string literal."""
+    return json.dumps(str(value))
+
+
+@dataclass
+class Statement:
+    """Cedar of one policy: its scope, when clause, and the clauses left untranslated."""
+
+    principal: str
+    conditions: list[str]
+    untranslated: list[str] = field(default_factory=list)
+
+
+class ClauseTranslator:
+    """Translates parsed condition clauses into Cedar expressions."""
+
+    def __init__(self, namespace: str):
+        """Initialize translator.
+
+        Args:
+            namespace: Namespace the exported entity types are declared in
+        """
+        self.namespace = namespace
+        # Attributes read from the caller, and from each domain object type: name -> Cedar schema type
+        self.attributes: dict[str, dict[str, dict]] = {"User": {}}
+
+    def entity(self, entity_type: str, entity_id: str) -> str:
+        """Reference to an entity of the namespace."""
+        return f"{self.namespace}::{entity_type}::{cedar_string(entity_id)}"
+
+    def role_check(self, roles: list[str]) -> str:
+        """Expression requiring the principal to be a member of one of the roles."""
+        if len(roles) == 1:
+            return f"principal in {self.entity('Role', roles[0])}"
+        return "principal in [" + ", ".join(self.entity("Role", r) for r in roles) + "]"
+
+    def reference(self, variable: str, entity_type: str, attribute: str, kind: dict) -> tuple[str, str]:
+        """Presence test and access of an attribute, recording it for the schema.
+
+        Resource attributes are named by their last segment (expense.amount is amount).
+
+        Returns:
+            Tuple of (has expression, attribute access)
+        """
+        name = attribute if variable == "principal" else attribute.split(".")[-1]
+        self.attributes.setdefault(entity_type, {}).setdefault(name, kind)
+        if IDENTIFIER.match(name):
+            return f"{variable} has {name}", f"{variable}.{name}"
+        return f"{variable} has {cedar_string(name)}", f"{variable}[{cedar_string(name)}]"
+
+    def comparison(self, clause: ConditionClause, resource_type: str) -> str | None:
+        """Expression of a comparison clause, or None when Cedar cannot state it."""
+        value, operator = clause.value, clause.operator
+        if SUBJECT_CLAUSE.match(clause.raw):
+            variable, entity_type = "principal", "User"
+        else:
+            variable, entity_type = "resource", resource_type
+
+        if isinstance(value, float):
+            exponent = Decimal(str(value)).as_tuple().exponent
+            if isinstance(exponent, int) and exponent < -4:
+                return None  # decimal() holds at most four fractional digits
+            has, access = self.reference(variable, entity_type, clause.attribute, DECIMAL_TYPE)
+            literal = f'decimal("{value}")'
+            if operator in DECIMAL_METHODS:
+                return f"{has} && {access}.{DECIMAL_METHODS[operator]}({literal})"
+            return f"{has} && {access} {operator} {literal}"
+        if isinstance(value, int) and not isinstance(value, bool):
+            has, access = self.reference(variable, entity_type, clause.attribute, {"type": "Long"})
+            return f"{has} && {access} {operator} {value}"
+        if operator not in ("==", "!="):
+            return None  # Cedar orders only numbers
+        kind = {"type": "Boolean"} if isinstance(value, bool) else {"type": "String"}
+        has, access = self.reference(variable, entity_type, clause.attribute, kind)
+        literal = ("true" if value else "false") if isinstance(value, bool) else cedar_string(value)
+        return f"{has} && {access} {operator} {literal}"
+
+    def translate(self, clause: ConditionClause, resource_type: str) -> str | None:
+        """Expression satisfied when the clause holds.
+
+        Returns:
+            Expression, or None when the clause cannot be translated
+        """
+        if clause.kind == "comparison":
+            return self.comparison(clause, resource_type)
+        if clause.kind == "implication" and clause.inner.kind == "comparison":
+            inner = replace(clause.inner, operator=NEGATED_OPERATORS[clause.inner.operator])
+            expression = self.comparison(inner, resource_type)
+            if expression is None:
+                return None
+            return f"(({expression}) || {self.role_check([clause.required_role])})"
+        if clause.kind in ("ownership", "owner_of_resource"):
+            user = {"type": "Entity", "name": "User"}
+            has, access = self.reference("resource", resource_type, clause.attribute or "owner_id", user)
+            expression = f"{has} && {access} == principal"
+            if clause.required_role:
+                return f"(({expression}) || {self.role_check([clause.required_role])})"
+            return expression
+        if clause.kind == "same_tenant":
+            has, access = self.reference("resource", resource_type, clause.attribute, {"type": "String"})
+            user_has, user_access = self.reference("principal", "User", "tenant_id", {"type": "String"})
+            return f"{has} && {user_has} && {access} == {user_access}"
+        if clause.kind == "flag":
+            has, access = self.reference("resource", resource_type, clause.attribute, {"type": "Boolean"})
+            return f"{has} && {access} == {'true' if clause.value else 'false'}"
+        return None
+
+    def statement(self, policy: Policy, resource_type: str) -> Statement:
+        """Principal scope and when clause of the statement granting what one policy grants."""
+        roles, requires_authentication = EndpointMappingService.parse_roles(policy.subject)
+        principal = "principal"
+        conditions = []
+        if len(roles) == 1:
+            principal = self.role_check(roles)
+        elif roles:
+            conditions.append(self.role_check(roles))
+        elif requires_authentication:
+            conditions.append("principal.authenticated")
+
+        untranslated = []
+        for clause in ConditionEvaluationService.parse(policy.conditions):
+            expression = self.translate(clause, resource_type)
+            if expression is None:
+                text = " ".join(clause.raw.split())
+                untranslated.append(text)
+                expression = f"// Not translated, review: {text}\n    false"
+            conditions.append(expression)
+        return Statement(principal=principal, conditions=conditions, untranslated=untranslated)
+
+
+def annotations(policy: Policy) -> list[str]:
+    """Annotations carrying a policy's ID and how sure the miner is of it."""
+    lines = [f'@policy_id("{policy.id}")']
+    if policy.confidence_score is not None:
+        lines.append(f'@confidence("{policy.confidence_score:g}")')
+    if policy.extraction_method:
+        lines.append(f'@extraction_method("{policy.extraction_method.value}")')
+    return lines
+
+
+def entity_schema(namespace: str, attributes: dict[str, dict[str, dict]], actions: dict[str, set[str]]) -> dict:
+    """Cedar JSON schema of an export, as Verified Permissions' PutSchema takes it.
+
+    Args:
+        namespace: Namespace the entity types are declared in
+        attributes: Attributes read per entity type, name -> Cedar schema type
+        actions: HTTP method -> domain object types it is performed on
+
+    Returns:
+        Schema with the User, Role, and Endpoint types, one type per domain object, and one action per method
+    """
+
+    def shape(names: dict[str, dict], required: dict[str, dict] | None = None) -> dict:
+        fields = {name: {**kind, "required": False} for name, kind in sorted(names.items())}
+        fields.update({name: {**kind, "required": True} for name, kind in (required or {}).items()})
+        return {"type": "Record", "attributes": dict(sorted(fields.items()))}
+
+    entity_types = {
+        "User": {
+            "memberOfTypes": ["Role"],
+            "shape": shape(attributes["User"], {"authenticated": {"type": "Boolean"}}),
+        },
+        "Role": {},
+        "Endpoint": {},
+    }
+    for name in sorted(set(attributes) - {"User"}):
+        entity_types[name] = {"memberOfTypes": ["Endpoint"], "shape": shape(attributes[name])}
+
+    return {
+        namespace: {
+            "entityTypes": entity_types,
+            "actions": {
+                method: {"appliesTo": {"principalTypes": ["User"], "resourceTypes": sorted(actions[method])}}
+                for method in HTTP_METHODS
+                if method in actions
+            },
+        }
+    }
+
+
+class CedarExportService:
+    """Exports a repository's mined policies as Cedar for Amazon Verified Permissions."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def export(self, repository_id: int, min_confidence: float | None = None, include_pending: bool = False) -> dict:
+        """Cedar policies and entity schema of a repository's mined policies.
+
+        Args:
+            repository_id: Repository ID
+            min_confidence: Leave out policies scored below this confidence (0-100)
+            include_pending: Also export policies that have not been approved
+
+        Returns:
+            One statement per policy, the policy file, the schema, and a summary
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        query = self._query(Policy).filter(
+            Policy.repository_id == repository.id, Policy.source_type != SourceType.DATABASE
+        )
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        candidates = query.order_by(Policy.id).all()
+        policies = [
+            p for p in candidates
+            if min_confidence is None or (p.confidence_score is not None and p.confidence_score >= min_confidence)
+        ]
+
+        namespace = cedar_name(repository.name, "Repository")
+        translator = ClauseTranslator(namespace)
+        statements, untranslated = [], []
+        actions: dict[str, set[str]] = {}
+        endpoints = set()
+        for policy in policies:
+            rule = EndpointMappingService.map_policy(policy)
+            path = normalize_path(rule.path)
+            resource_type = cedar_name(policy.resource, "Resource")
+            if resource_type in BUILTIN_TYPES:
+                resource_type = f"{resource_type}Object"
+            translator.attributes.setdefault(resource_type, {})
+            actions.setdefault(rule.method, set()).add(resource_type)
+            endpoints.add((rule.method, path))
+
+            statement = translator.statement(policy, resource_type)
+            endpoint = f"{rule.method} {path}"
+            untranslated += [{"policy_id": policy.id, "endpoint": endpoint, "clause": c} for c in statement.untranslated]
+            grant = " ".join(f"{policy.subject} may {policy.action} {policy.resource}".split())
+            lines = [
+                *annotations(policy),
+                f"// Policy {policy.id}: {grant} ({endpoint})",
+                "permit (",
+                f"    {statement.principal},",
+                f"    action == {translator.entity('Action', rule.method)},",
+                f"    resource is {namespace}::{resource_type} in {translator.entity('Endpoint', path)}",
+                ")",
+            ]
+            if statement.conditions:
+                lines += ["when {", "    " + " &&\n    ".join(statement.conditions), "}"]
+            lines[-1] += ";"
+            statements.append({"policy_id": policy.id, "endpoint": endpoint, "statement": "\n".join(lines)})
+
+        schema = entity_schema(namespace, translator.attributes, actions)
+        logger.info(
+            "cedar_exported",
+            repository_id=repository.id,
+            namespace=namespace,
+            policies=len(policies),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repository.id,
+            "namespace": namespace,
+            "policies": statements,
+            "cedar": "\n\n".join(s["statement"] for s in statements) + "\n" if statements else "",
+            "entity_schema": schema,
+            "untranslated": untranslated,
+            "summary": {
+                "policies": len(policies),
+                "endpoints": len(endpoints),
+                "entity_types": len(schema[namespace]["entityTypes"]),
+                "below_min_confidence": len(candidates) - len(policies),
+                "untranslated_clauses": len(untranslated),
+            },
+        }
+
+    def archive(self, repository_id: int, min_confidence: float | None = None, include_pending: bool = False) -> bytes:
+        """Gzipped tarball of a repository's Cedar policies and schema.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        export = self.export(repository_id, min_confidence, include_pending)
+        return build_archive(
+            {
+                "policies.cedar": export["cedar"].encode(),
+                "schema.cedarschema.json": (json.dumps(export["entity_schema"], indent=2) + "\n").encode(),
+            }
+        )
@@ -5,7 +5,9 @@ from typing import Any
 from anthropic import Anthropic
 from sqlalchemy.orm import Session
 
+from app.core.air_gap import ensure_llm_allowed
 from app.models import ChangeType, Policy, PolicyChange, WorkItem, WorkItemPriority, WorkItemStatus
+from app.services.redaction_service import redact_for_egress
 
 logger = logging.getLogger(__name__)
 
@@ -333,10 +335,11 @@ class ChangeDetectionService:
 """
 
         try:
+            ensure_llm_allowed("change_detection")
             response = self.client.messages.create(
                 model="claude-sonnet-4-20250514",
                 max_tokens=1024,
-                messages=[{"role": "user", "content": prompt}]
+                messages=[{"role": "user", "content": redact_for_egress(prompt, "llm")}]
             )
 
             suggestion = response.content[0].text
@@ -0,0 +1,135 @@
+"""Discovery and pattern matching over a scanned repository's checkout.
+
+These passes read untrusted files and run in the scan sandbox
+(app.core.sandbox): they read only under the checkout, never follow
+symlinks, and touch neither the database nor the network. Each takes the
+checkout root and yields JSON-serializable items.
+"""
+
+import logging
+import os
+import re
+from collections.abc import Iterator
+
+from app.services.secret_detection_service import SecretDetectionService
+
+logger = logging.getLogger(__name__)
+
+# Common authorization patterns to search for
+AUTH_PATTERNS = [
+    r"@\w+\s*\(",  # Decorators (Python, TypeScript)
+    r"hasRole\s*\(",  # Role checks
+    r"isAuthorized\s*\(",  # Authorization checks
+    r"authorize\s*\(",  # Authorization functions
+    r"checkPermission\s*\(",  # Permission checks
+    r"if\s+.*\.(role|permission|isAdmin|isManager)",  # Conditional role checks
+    r"canAccess\s*\(",  # Access checks
+    r"@Authorize",  # C#/Java annotations
+    r"@PreAuthorize",  # Spring Security
+    r"@RolesAllowed",  # Java EE
+    r"@RequireRole",  # Custom role annotations
+]
+
+# File extensions to scan
+SUPPORTED_EXTENSIONS = {
+    ".py",
+    ".java",
+    ".cs",
+    ".js",
+    ".ts",
+    ".tsx",
+    ".jsx",
+    ".go",
+    ".rb",
+    ".php",
+    ".scala",
+    ".kt",
+}
+
+# Path fragments of dependencies, build output, and VCS metadata
+IGNORED_PATH_PARTS = [".git", "node_modules", "venv", "__pycache__", "dist", "build"]
+
+
+def source_files(
+    root: str, changed_files: list[str] | None = None, max_file_bytes: int | None = None
+) -> Iterator[tuple[str, str]]:
+    """Source files under a checkout, as (absolute path, path relative to the checkout).
+
+    Symlinks, to files or directories, are skipped: a checkout could point
+    them anywhere on the worker.
+    """
+    changed = set(changed_files) if changed_files is not None else None
+    for directory, subdirectories, files in os.walk(root, followlinks=False):
+        subdirectories[:] = [d for d in subdirectories if not os.path.islink(os.path.join(directory, d))]
+        for name in files:
+            path = os.path.join(directory, name)
+            relative = os.path.relpath(path, root)
+            if any(p in relative for p in IGNORED_PATH_PARTS):
+                continue
+            if os.path.splitext(name)[1] not in SUPPORTED_EXTENSIONS:
+                continue
+            if changed is not None and relative not in changed:
+                continue
+            try:
+                stat = os.lstat(path)
+            except OSError:
+                continue
+            if not os.path.isfile(path) or os.path.islink(path):
+                continue
+            if max_file_bytes is not None and stat.st_size > max_file_bytes:
+                continue
+            yield path, relative
+
+
+def read_source(path: str) -> str:
+    """Read a source file, refusing a symlink swapped in since it was listed."""
+    fd = os.open(path, os.O_RDONLY | os.O_NOFOLLOW)
+    with os.fdopen(fd, "rb") as f:
+        return f.read().decode("utf-8", errors="ignore")
+
+
+def count_files(
+    root: str, changed_files: list[str] | None = None, max_file_bytes: int | None = None
+) -> Iterator[int]:
+    """Yield the number of source files to scan, without reading them."""
+    yield sum(1 for _ in source_files(root, changed_files, max_file_bytes))
+
+
+def analyze_files(
+    root: str, changed_files: list[str] | None = None, max_file_bytes: int | None = None
+) -> Iterator[dict]:
+    """Yield the source files with authorization patterns or secrets.
+
+    Yields:
+        {"path", "content", "matches", "secrets"}; content is None for files
+        with secrets but no authorization patterns
+    """
+    for path, relative in source_files(root, changed_files, max_file_bytes):
+        try:
+            content = read_source(path)
+
+            # PRE-SCAN: Detect secrets BEFORE processing
+            secrets = SecretDetectionService.scan_content(content, relative).secrets_found
+
+            # Check for authorization patterns
+            matches = []
+            for pattern in AUTH_PATTERNS:
+                for match in re.finditer(pattern, content):
+                    line_num = content[:match.start()].count("\n") + 1
+                    matches.append({
+                        "pattern": pattern,
+                        "line": line_num,
+                        "text": match.group(),
+                    })
+
+            if matches or secrets:
+                yield {
+                    "path": relative,
+                    "content": content if matches else None,
+                    "matches": matches,
+                    "secrets": secrets,
+                }
+
+        except Exception as e:
+            logger.error(f"Error reading file {relative}: {e}")
+            continue
@@ -0,0 +1,264 @@
+"""Extract CLI commands and the authorization they perform.
+
+Admin CLIs shipped beside a service usually reach the same database or API
+with operator credentials, so a command that deletes tenants or grants
+roles is a privileged entry point even though no route leads to it. These
+extractors read cobra command trees (Go), click and Typer command groups
+(Python), and Thor classes (Ruby), resolve each command's full path, and
+record the identity and role checks found in the command and in the hooks
+its parent commands run before it (cobra persistent pre-runs, click group
+callbacks), without LLM calls.
+"""
+
+import re
+from pathlib import PurePosixPath
+
+from app.services.entry_point_extractor import (
+    EntryPoint,
+    EntryPointKind,
+    command_operations,
+    go_body,
+    go_enclosing,
+    identity_checked,
+    indented_end,
+    privileged_operations,
+    roles_checked,
+)
+from app.services.extractor_utils import group, line_of
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(r"cobra\.Command|\.command\(|\.group\(|typer|Thor\b")
+
+# Checks an operator CLI makes about who is running it
+CLI_IDENTITY_CHECK = re.compile(
+    r"\bos\.Get(?:e)?uid\(\)|\bos\.gete?uid\(\)|\bProcess\.e?uid\b|\buser\.Current\(\)|\bgetpass\.getuser\(\)|"
+    r"\brequire_?login\w*|\brequireLogin\w*|\bensure_?logged_?in\w*|\bensureLoggedIn\w*|\bwhoami\b"
+)
+
+
+def _cli_entry(
+    framework: str,
+    name: str,
+    path: str,
+    file_path: str,
+    text: str,
+    start: int,
+    end: int,
+    own: str,
+    inherited: str = "",
+) -> EntryPoint:
+    """Build an entry point for a command span.
+
+    own is the code the command runs (its span and named handlers) and
+    inherited the hooks its parent commands run before it; both count as
+    checks, only own code counts for privileged operations.
+    """
+    checked = own + inherited
+    return EntryPoint(
+        kind=EntryPointKind.CLI_COMMAND,
+        framework=framework,
+        name=name,
+        file_path=file_path,
+        line_start=line_of(text, start),
+        line_end=line_of(text, max(start, end - 1)),
+        trigger=path,
+        roles=roles_checked(checked),
+        identity_checked=identity_checked(checked) or bool(CLI_IDENTITY_CHECK.search(checked)),
+        privileged_operations=list(dict.fromkeys(privileged_operations(own) + command_operations(path.replace("-", "_")))),
+    )
+
+
+def _paths(parents: dict[str, str], names: dict[str, str]) -> dict[str, str]:
+    """Full command path of each command, given child -> parent and command -> name.
+
+    A root command stands for the executable and is left out of its
+    children's paths.
+    """
+    paths = {}
+    for command, name in names.items():
+        parts, current, seen = [name], command, {command}
+        while current in parents and parents[current] in names and parents[current] not in seen:
+            current = parents[current]
+            seen.add(current)
+            parts.append(names[current])
+        if len(parts) > 1 and current not in parents:
+            parts.pop()
+        paths[command] = " ".join(reversed(parts))
+    return paths
+
+
+def _ancestors(command: str, parents: dict[str, str]) -> list[str]:
+    """Parent commands of a command, nearest first."""
+    ancestors, current = [], command
+    while current in parents and parents[current] not in ancestors and parents[current] != command:
+        current = parents[current]
+        ancestors.append(current)
+    return ancestors
+
+
+# Go: cobra command literals wired together with AddCommand
+COBRA_COMMAND = re.compile(r"(?:\b(\w+)\s*:?=\s*|\breturn\s+)&cobra\.Command\{")
+COBRA_USE = re.compile(r"""\bUse\s*:\s*"([\w:.-]+)""")
+COBRA_NAMED_HOOK = re.compile(r"\b((?:Persistent)?(?:Pre)?RunE?)\s*:\s*(\w+)\s*,")
+COBRA_INLINE_PERSISTENT = re.compile(r"\bPersistentPreRunE?\s*:\s*func\b")
+COBRA_ADD = re.compile(r"\b(\w+)\.AddCommand\(")
+
+
+def _go_function(files: dict[str, str], name: str) -> str:
+    """Text of a top-level Go function defined anywhere in the package."""
+    for text in files.values():
+        span = go_body(text, name)
+        if span:
+            return text[span[0] : span[1]]
+    return ""
+
+
+def extract_cobra(files: dict[str, str]) -> list[EntryPoint]:
+    """cobra leaf commands, with checks from their run hooks and their ancestors' persistent hooks."""
+    # command key -> (file path, span start, span end, Use name, checked code, persistent hook code)
+    commands: dict[str, tuple[str, int, int, str, str, str]] = {}
+    parents: dict[str, str] = {}
+    for file_path, text in files.items():
+        for match in COBRA_COMMAND.finditer(text):
+            end = group(text, match.end() - 1)
+            literal = text[match.start() : end]
+            use = COBRA_USE.search(literal)
+            if not use:
+                continue
+            # Commands built and returned by a constructor are keyed and checked by the constructor
+            key, start, stop = match.group(1), match.start(), end
+            enclosing = go_enclosing(text, match.start())
+            if enclosing and (key is None or re.search(rf"\breturn\s+{key}\b", text[enclosing[1] : enclosing[2]])):
+                key, start, stop = enclosing
+            checked, persistent = text[start:stop], ""
+            for hook in COBRA_NAMED_HOOK.finditer(literal):
+                body = _go_function(files, hook.group(2))
+                checked += body
+                if hook.group(1).startswith("Persistent"):
+                    persistent += body
+            inline = COBRA_INLINE_PERSISTENT.search(literal)
+            if inline:
+                persistent += literal[inline.start() : group(literal, literal.find("{", inline.end()))]
+            commands[key or use.group(1)] = (file_path, start, stop, use.group(1), checked, persistent)
+        for match in COBRA_ADD.finditer(text):
+            opening = match.end() - 1
+            for child in text[opening + 1 : group(text, opening) - 1].split(","):
+                child = child.strip().removesuffix("()")
+                if child:
+                    parents[child] = match.group(1)
+    parent_keys = set(parents.values())
+    paths = _paths(parents, {key: command[3] for key, command in commands.items()})
+    entries = []
+    for key, (file_path, start, stop, _, checked, _) in commands.items():
+        if key in parent_keys:
+            continue
+        inherited = "".join(commands[a][5] for a in _ancestors(key, parents) if a in commands)
+        entries.append(_cli_entry("cobra", key, paths[key], file_path, files[file_path], start, stop, checked, inherited))
+    return entries
+
+
+# Python: click and Typer commands, groups, and callbacks
+PY_COMMAND = re.compile(
+    r"""^[ \t]*@(\w+)\.(command|group|callback)\(\s*(?:(?:name\s*=\s*)?['"]([\w:.-]+)['"])?""", re.MULTILINE
+)
+PY_DEF = re.compile(r"^[ \t]*(?:async\s+)?def\s+(\w+)", re.MULTILINE)
+TYPER_APP = re.compile(r"^(\w+)\s*=\s*typer\.Typer\(", re.MULTILINE)
+TYPER_ADD = re.compile(r"""\b(\w+)\.add_typer\(\s*(\w+)\s*(?:,\s*name\s*=\s*['"]([\w:.-]+)['"])?""")
+CLICK_MODULES = {"click", "cloup"}
+
+
+def extract_click(files: dict[str, str]) -> list[EntryPoint]:
+    """click and Typer commands, with checks from their decorators, bodies, and parent group callbacks."""
+    # command function -> (file path, span start, span end, framework)
+    commands: dict[str, tuple[str, int, int, str]] = {}
+    parents: dict[str, str] = {}
+    names: dict[str, str] = {}
+    hooks: dict[str, str] = {}  # Group or Typer app -> code its callback runs before subcommands
+    for file_path, text in files.items():
+        typer_apps = {m.group(1) for m in TYPER_APP.finditer(text)}
+        for match in TYPER_ADD.finditer(text):
+            parents[match.group(2)] = match.group(1)
+            names[match.group(2)] = match.group(3) or match.group(2)
+        for match in PY_COMMAND.finditer(text):
+            definition = PY_DEF.search(text, match.end())
+            if not definition:
+                continue
+            function, receiver, decorator = definition.group(1), match.group(1), match.group(2)
+            end = indented_end(text, definition.start())
+            if decorator == "callback":
+                hooks[receiver] = hooks.get(receiver, "") + text[match.start() : end]
+                continue
+            if receiver not in CLICK_MODULES:
+                parents[function] = receiver
+            names[function] = match.group(3) or function.replace("_", "-")
+            if decorator == "group":
+                hooks[function] = hooks.get(function, "") + text[match.start() : end]
+            else:
+                commands[function] = (file_path, match.start(), end, "typer" if receiver in typer_apps else "click")
+    paths = _paths(parents, names)
+    entries = []
+    for function, (file_path, start, end, framework) in commands.items():
+        text = files[file_path]
+        inherited = "".join(hooks.get(a, "") for a in _ancestors(function, parents))
+        entries.append(
+            _cli_entry(framework, function, paths[function], file_path, text, start, end, text[start:end], inherited)
+        )
+    return entries
+
+
+# Ruby: Thor classes, their desc'd methods, and subcommand registrations
+THOR_CLASS = re.compile(r"^[ \t]*class\s+([\w:]+)\s*<\s*Thor\b", re.MULTILINE)
+THOR_DESC = re.compile(r"""^[ \t]*desc\s+['"]([\w:.-]+)""", re.MULTILINE)
+RUBY_DEF = re.compile(r"^[ \t]*def\s+(\w+[!?]?)", re.MULTILINE)
+THOR_SUBCOMMAND = re.compile(r"""\bsubcommand\s+['"]([\w:.-]+)['"]\s*,\s*([\w:]+)""")
+
+
+def extract_thor(files: dict[str, str]) -> list[EntryPoint]:
+    """Thor commands; each desc'd method is a command under its class's subcommand name."""
+    classes: dict[str, tuple[str, int, int]] = {}
+    parents: dict[str, str] = {}
+    names: dict[str, str] = {}
+    for file_path, text in files.items():
+        for match in THOR_CLASS.finditer(text):
+            name = match.group(1).split("::")[-1]
+            classes[name] = (file_path, match.start(), indented_end(text, match.start()))
+            names.setdefault(name, name)
+            for subcommand in THOR_SUBCOMMAND.finditer(text, match.start(), classes[name][2]):
+                child = subcommand.group(2).split("::")[-1]
+                parents[child] = name
+                names[child] = subcommand.group(1)
+    entries = []
+    for class_name, (file_path, class_start, class_end) in classes.items():
+        text = files[file_path]
+        prefix = _paths(parents, names)[class_name] if class_name in parents else ""
+        for desc in THOR_DESC.finditer(text, class_start, class_end):
+            definition = RUBY_DEF.search(text, desc.end(), class_end)
+            if not definition:
+                continue
+            end = indented_end(text, definition.start())
+            path = f"{prefix} {desc.group(1)}".strip()
+            entries.append(_cli_entry("thor", definition.group(1), path, file_path, text, desc.start(), end, text[desc.start() : end]))
+    return entries
+
+
+def extract_cli_commands(files: dict[str, str]) -> list[EntryPoint]:
+    """CLI commands declared across a repository.
+
+    Command trees span files, so all sources are read together.
+
+    Args:
+        files: Relative path -> content of the repository's source files
+
+    Returns:
+        CLI command entry points in path order
+    """
+    relevant = {path: text for path, text in files.items() if PREFILTER.search(text)}
+    by_suffix: dict[str, dict[str, str]] = {}
+    for path, text in relevant.items():
+        by_suffix.setdefault(PurePosixPath(path).suffix, {})[path] = text
+    entries = (
+        extract_cobra(by_suffix.get(".go", {}))
+        + extract_click(by_suffix.get(".py", {}))
+        + extract_thor({**by_suffix.get(".rb", {}), **by_suffix.get(".thor", {})})
+    )
+    return sorted(entries, key=lambda e: (e.file_path, e.line_start))
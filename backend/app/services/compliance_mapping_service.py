@@ -0,0 +1,445 @@
+"""Service for mapping findings and coverage to compliance framework controls.
+
+Each control in SOC 2, PCI DSS, and ISO 27001 is mapped to one or more
+posture signals computed from data the miner already has (open findings,
+review status, endpoint coverage, access review recency). A control fails
+when any of its signals fails, passes when the signals that could be
+computed all pass, and is not assessed when none could be computed. The default mapping can be extended or overridden
+with a JSON file (COMPLIANCE_MAPPING_PATH) so GRC teams can align it with
+their own control interpretations.
+"""
+
+import copy
+import csv
+import io
+import json
+from collections.abc import Callable
+from datetime import UTC, datetime
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.access_review import AccessReviewCampaign, CampaignStatus
+from app.models.conflict import ConflictStatus, PolicyConflict
+from app.models.inconsistent_enforcement import InconsistentEnforcement, InconsistentEnforcementStatus
+from app.models.policy import Policy, PolicyStatus, RiskLevel
+from app.models.policy_fix import FixStatus, PolicyFix
+from app.models.secret_detection import SecretDetectionLog
+from app.services.cors_csrf_service import CorsCsrfService
+from app.services.coverage_metrics_service import CoverageMetricsService
+from app.services.vulnerable_auth_pattern_service import VulnerableAuthPatternService
+
+logger = structlog.get_logger(__name__)
+
+
+class SignalStatus:
+    """Outcome of one posture signal."""
+
+    PASS = "pass"
+    FAIL = "fail"
+    UNKNOWN = "unknown"
+
+
+class ControlStatus:
+    """Posture of one compliance control."""
+
+    PASSING = "passing"
+    FAILING = "failing"
+    NOT_ASSESSED = "not_assessed"
+
+
+# Default control mapping: framework -> controls -> posture signals
+DEFAULT_CONTROL_MAPPINGS: dict[str, dict] = {
+    "soc2": {
+        "name": "SOC 2 (2017 Trust Services Criteria)",
+        "controls": {
+            "CC6.1": {
+                "title": "Logical access security software, infrastructure, and architectures",
+                "signals": [
+                    "authorization_inventory",
+                    "authentication_coverage",
+                    "inconsistent_enforcement",
+                    "secrets_in_code",
+                ],
+            },
+            "CC6.2": {
+                "title": "Access is authorized before credentials are issued",
+                "signals": ["policy_review"],
+            },
+            "CC6.3": {
+                "title": "Access is granted, modified, and removed based on roles and least privilege",
+                "signals": ["access_review_recency", "unresolved_conflicts", "high_risk_unreviewed"],
+            },
+            "CC7.1": {
+                "title": "Detection of configuration changes and vulnerabilities",
+                "signals": ["open_security_gaps", "secrets_in_code", "vulnerable_auth_patterns"],
+            },
+            "CC8.1": {
+                "title": "Changes are authorized, tested, and approved",
+                "signals": ["policy_review"],
+            },
+        },
+    },
+    "pci_dss": {
+        "name": "PCI DSS v4.0",
+        "controls": {
+            "6.2.4": {
+                "title": "Software engineering techniques prevent common software attacks",
+                "signals": ["open_security_gaps", "cross_origin_protection", "vulnerable_auth_patterns"],
+            },
+            "7.2.1": {
+                "title": "An access control model is defined",
+                "signals": ["authorization_inventory", "authentication_coverage", "unresolved_conflicts"],
+            },
+            "7.2.2": {
+                "title": "Access is assigned based on job classification and least privilege",
+                "signals": ["inconsistent_enforcement", "high_risk_unreviewed"],
+            },
+            "7.2.4": {
+                "title": "User accounts and access privileges are reviewed periodically",
+                "signals": ["access_review_recency"],
+            },
+            "8.6.2": {
+                "title": "Passwords for system accounts are not hard coded in scripts or files",
+                "signals": ["secrets_in_code"],
+            },
+        },
+    },
+    "iso27001": {
+        "name": "ISO/IEC 27001:2022 Annex A",
+        "controls": {
+            "A.5.15": {
+                "title": "Access control",
+                "signals": ["authorization_inventory", "inconsistent_enforcement"],
+            },
+            "A.5.17": {
+                "title": "Authentication information",
+                "signals": ["secrets_in_code"],
+            },
+            "A.5.18": {
+                "title": "Access rights",
+                "signals": ["access_review_recency", "policy_review"],
+            },
+            "A.8.2": {
+                "title": "Privileged access rights",
+                "signals": ["high_risk_unreviewed"],
+            },
+            "A.8.3": {
+                "title": "Information access restriction",
+                "signals": ["authentication_coverage", "unresolved_conflicts", "inconsistent_enforcement"],
+            },
+            "A.8.28": {
+                "title": "Secure coding",
+                "signals": ["open_security_gaps", "secrets_in_code", "cross_origin_protection", "vulnerable_auth_patterns"],
+            },
+        },
+    },
+}
+
+# An access review older than this no longer counts as periodic
+ACCESS_REVIEW_MAX_AGE_DAYS = 90
+
+# Share of inventoried endpoints that must require authentication
+AUTHENTICATION_COVERAGE_TARGET_PERCENT = 95.0
+
+
+def load_control_mappings(path: str | None = None) -> dict[str, dict]:
+    """Load control mappings, merging an optional override file over the defaults.
+
+    The override file uses the same shape as DEFAULT_CONTROL_MAPPINGS. New
+    frameworks are added; controls of an existing framework are added or
+    replaced by ID.
+
+    Args:
+        path: Override file path (defaults to settings.COMPLIANCE_MAPPING_PATH)
+
+    Returns:
+        Merged mappings
+
+    Raises:
+        ValueError: If the override file cannot be read
+    """
+    mappings = copy.deepcopy(DEFAULT_CONTROL_MAPPINGS)
+    path = path if path is not None else settings.COMPLIANCE_MAPPING_PATH
+    if not path:
+        return mappings
+
+    try:
+        overrides = json.loads(Path(path).read_text())
+    except (OSError, json.JSONDecodeError) as e:
+        raise ValueError(f"Invalid compliance mapping file {path}: {e}") from e
+
+    for framework_id, framework in overrides.items():
+        target = mappings.setdefault(framework_id, {"name": framework_id, "controls": {}})
+        if "name" in framework:
+            target["name"] = framework["name"]
+        target["controls"].update(framework.get("controls", {}))
+
+    logger.info("compliance_mappings_loaded", path=path, frameworks=len(mappings))
+    return mappings
+
+
+class ComplianceMappingService:
+    """Computes per-control compliance posture."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, mappings: dict[str, dict] | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.mappings = mappings if mappings is not None else load_control_mappings()
+
+    @property
+    def signal_providers(self) -> dict[str, Callable[[], dict]]:
+        """Posture signals by name."""
+        return {
+            "authorization_inventory": self._authorization_inventory,
+            "authentication_coverage": self._authentication_coverage,
+            "policy_review": self._policy_review,
+            "high_risk_unreviewed": self._high_risk_unreviewed,
+            "unresolved_conflicts": self._unresolved_conflicts,
+            "inconsistent_enforcement": self._inconsistent_enforcement,
+            "open_security_gaps": self._open_security_gaps,
+            "secrets_in_code": self._secrets_in_code,
+            "access_review_recency": self._access_review_recency,
+            "cross_origin_protection": self._cross_origin_protection,
+            "vulnerable_auth_patterns": self._vulnerable_auth_patterns,
+        }
+
+    def _query(self, model):
+        """Query a model scoped to the tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    @staticmethod
+    def _signal(status: str, value: int | float | None, detail: str) -> dict:
+        """Build a signal result."""
+        return {"status": status, "value": value, "detail": detail}
+
+    def _authorization_inventory(self) -> dict:
+        """Pass when mined policies document the access control model."""
+        count = self._query(Policy).filter(Policy.status != PolicyStatus.REJECTED).count()
+        if not count:
+            return self._signal(SignalStatus.UNKNOWN, 0, "No authorization policies have been mined yet")
+        return self._signal(SignalStatus.PASS, count, f"{count} authorization policies inventoried with code evidence")
+
+    def _authentication_coverage(self) -> dict:
+        """Pass when nearly every inventoried endpoint requires authentication."""
+        totals = CoverageMetricsService(self.db, self.tenant_id).workspace_coverage()["totals"]
+        if not totals["total_endpoints"]:
+            return self._signal(SignalStatus.UNKNOWN, None, "No endpoints inventoried yet")
+        percent = totals["authenticated_percent"]
+        detail = (
+            f"{percent}% of {totals['total_endpoints']} endpoints require authentication "
+            f"(target {AUTHENTICATION_COVERAGE_TARGET_PERCENT}%)"
+        )
+        if percent < AUTHENTICATION_COVERAGE_TARGET_PERCENT:
+            return self._signal(SignalStatus.FAIL, percent, detail)
+        return self._signal(SignalStatus.PASS, percent, detail)
+
+    def _policy_review(self) -> dict:
+        """Fail while mined policies await review."""
+        pending = self._query(Policy).filter(Policy.status == PolicyStatus.PENDING).count()
+        if pending:
+            return self._signal(SignalStatus.FAIL, pending, f"{pending} mined policies awaiting review")
+        return self._signal(SignalStatus.PASS, 0, "All mined policies have been reviewed")
+
+    def _high_risk_unreviewed(self) -> dict:
+        """Fail while high-risk policies await review."""
+        count = (
+            self._query(Policy)
+            .filter(Policy.risk_level == RiskLevel.HIGH)
+            .filter(Policy.status == PolicyStatus.PENDING)
+            .count()
+        )
+        if count:
+            return self._signal(SignalStatus.FAIL, count, f"{count} high-risk policies not yet reviewed")
+        return self._signal(SignalStatus.PASS, 0, "No unreviewed high-risk policies")
+
+    def _unresolved_conflicts(self) -> dict:
+        """Fail while policy conflicts are unresolved."""
+        count = self._query(PolicyConflict).filter(PolicyConflict.status == ConflictStatus.PENDING).count()
+        if count:
+            return self._signal(SignalStatus.FAIL, count, f"{count} unresolved policy conflicts")
+        return self._signal(SignalStatus.PASS, 0, "No unresolved policy conflicts")
+
+    def _inconsistent_enforcement(self) -> dict:
+        """Fail while resources are enforced inconsistently across applications."""
+        count = (
+            self._query(InconsistentEnforcement)
+            .filter(
+                InconsistentEnforcement.status.in_(
+                    [InconsistentEnforcementStatus.PENDING, InconsistentEnforcementStatus.ACKNOWLEDGED]
+                )
+            )
+            .count()
+        )
+        if count:
+            return self._signal(SignalStatus.FAIL, count, f"{count} resources enforced inconsistently across applications")
+        return self._signal(SignalStatus.PASS, 0, "Authorization is enforced consistently across applications")
+
+    def _open_security_gaps(self) -> dict:
+        """Fail while authorization security gaps are unfixed."""
+        count = (
+            self._query(PolicyFix)
+            .filter(PolicyFix.status.in_([FixStatus.PENDING, FixStatus.REVIEWED]))
+            .count()
+        )
+        if count:
+            return self._signal(SignalStatus.FAIL, count, f"{count} authorization security gaps not yet fixed")
+        return self._signal(SignalStatus.PASS, 0, "No open authorization security gaps")
+
+    def _secrets_in_code(self) -> dict:
+        """Fail when scans have detected hard-coded secrets."""
+        count = self._query(SecretDetectionLog).count()
+        if count:
+            return self._signal(SignalStatus.FAIL, count, f"{count} hard-coded secrets detected during scans")
+        return self._signal(SignalStatus.PASS, 0, "No hard-coded secrets detected")
+
+    def _access_review_recency(self) -> dict:
+        """Pass when an access review campaign completed recently."""
+        latest = (
+            self._query(AccessReviewCampaign)
+            .filter(AccessReviewCampaign.status == CampaignStatus.COMPLETED)
+            .order_by(AccessReviewCampaign.completed_at.desc())
+            .first()
+        )
+        if not latest or not latest.completed_at:
+            return self._signal(SignalStatus.FAIL, None, "No completed access review campaign")
+
+        completed_at = latest.completed_at
+        if completed_at.tzinfo is None:
+            completed_at = completed_at.replace(tzinfo=UTC)
+        age_days = (datetime.now(UTC) - completed_at).days
+        if age_days > ACCESS_REVIEW_MAX_AGE_DAYS:
+            return self._signal(
+                SignalStatus.FAIL, age_days, f"Last access review completed {age_days} days ago"
+            )
+        return self._signal(SignalStatus.PASS, age_days, f"Access review '{latest.name}' completed {age_days} days ago")
+
+    def _cross_origin_protection(self) -> dict:
+        """Fail when authenticated endpoints let any origin send credentialed requests."""
+        services = [s for s in CorsCsrfService(self.db, self.tenant_id).services() if s["cloned"]]
+        if not services:
+            return self._signal(SignalStatus.UNKNOWN, None, "No repository clones to read CORS and CSRF settings from")
+        flagged = sum(s["flagged_endpoints"] for s in services)
+        disabled = sum(1 for s in services if s["csrf_protection"] == "disabled")
+        detail = (
+            f"{flagged} authenticated endpoints allow credentialed requests from any origin; "
+            f"CSRF protection disabled in {disabled} of {len(services)} services"
+        )
+        return self._signal(SignalStatus.FAIL if flagged else SignalStatus.PASS, flagged, detail)
+
+    def _vulnerable_auth_patterns(self) -> dict:
+        """Fail when known-broken authentication patterns of high or critical severity are in code."""
+        summary = VulnerableAuthPatternService(self.db, self.tenant_id).findings()["summary"]
+        if not summary["repositories_scanned"]:
+            return self._signal(SignalStatus.UNKNOWN, None, "No repository clones to scan for vulnerable auth patterns")
+        severe = summary["by_severity"].get("critical", 0) + summary["by_severity"].get("high", 0)
+        detail = f"{severe} critical or high and {summary['total'] - severe} other known-vulnerable auth patterns in {summary['repositories_scanned']} repositories"
+        return self._signal(SignalStatus.FAIL if severe else SignalStatus.PASS, severe, detail)
+
+    def list_frameworks(self) -> list[dict]:
+        """List configured frameworks.
+
+        Returns:
+            Framework IDs, names, and control counts
+        """
+        return [
+            {"id": framework_id, "name": framework["name"], "control_count": len(framework["controls"])}
+            for framework_id, framework in self.mappings.items()
+        ]
+
+    def compute_signals(self) -> dict[str, dict]:
+        """Compute every posture signal referenced by the mappings.
+
+        Returns:
+            Signal results by name; unknown signal names evaluate to "unknown"
+        """
+        needed = {
+            signal
+            for framework in self.mappings.values()
+            for control in framework["controls"].values()
+            for signal in control.get("signals", [])
+        }
+        providers = self.signal_providers
+        results = {}
+        for name in sorted(needed):
+            provider = providers.get(name)
+            if provider is None:
+                logger.warning("compliance_signal_unknown", signal=name)
+                results[name] = self._signal(SignalStatus.UNKNOWN, None, f"Unknown signal '{name}'")
+            else:
+                results[name] = provider()
+        return results
+
+    def posture(self, framework_id: str | None = None) -> dict:
+        """Summarize posture per control.
+
+        Args:
+            framework_id: Restrict to one framework
+
+        Returns:
+            Per-framework control posture with signal evidence
+
+        Raises:
+            ValueError: If the framework is not configured
+        """
+        if framework_id and framework_id not in self.mappings:
+            raise ValueError(f"Compliance framework {framework_id} not found")
+
+        signals = self.compute_signals()
+        frameworks = []
+        for fid, framework in self.mappings.items():
+            if framework_id and fid != framework_id:
+                continue
+            controls = []
+            for control_id, control in framework["controls"].items():
+                control_signals = [{"name": name, **signals[name]} for name in control.get("signals", [])]
+                statuses = {s["status"] for s in control_signals}
+                if SignalStatus.FAIL in statuses:
+                    status = ControlStatus.FAILING
+                elif SignalStatus.PASS in statuses:
+                    status = ControlStatus.PASSING
+                else:
+                    status = ControlStatus.NOT_ASSESSED
+                controls.append(
+                    {
+                        "control_id": control_id,
+                        "title": control.get("title", control_id),
+                        "status": status,
+                        "signals": control_signals,
+                    }
+                )
+            counts = {
+                status: sum(1 for c in controls if c["status"] == status)
+                for status in (ControlStatus.PASSING, ControlStatus.FAILING, ControlStatus.NOT_ASSESSED)
+            }
+            frameworks.append({"id": fid, "name": framework["name"], **counts, "controls": controls})
+
+        return {"generated_at": datetime.now(UTC).isoformat(), "frameworks": frameworks}
+
+    def export_csv(self, framework_id: str | None = None) -> str:
+        """Export posture as CSV for auditors, one row per control.
+
+        Args:
+            framework_id: Restrict to one framework
+
+        Returns:
+            CSV text
+
+        Raises:
+            ValueError: If the framework is not configured
+        """
+        report = self.posture(framework_id)
+        output = io.StringIO()
+        writer = csv.writer(output)
+        writer.writerow(["framework", "control_id", "title", "status", "evidence"])
+        for framework in report["frameworks"]:
+            for control in framework["controls"]:
+                evidence = "; ".join(f"[{s['status']}] {s['detail']}" for s in control["signals"])
+                writer.writerow([framework["id"], control["control_id"], control["title"], control["status"], evidence])
+        return output.getvalue()
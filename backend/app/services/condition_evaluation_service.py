@@ -0,0 +1,322 @@
+"""Service for parsing and evaluating mined policy conditions.
+
+Mined conditions are free text written by the extraction prompt, e.g.
+"amount > 5000 requires DIRECTOR", "User department is Finance", or
+"User is owner AND not approved", or lifted from handler code, such as the
+owner-of-resource clause "user is owner of expense.owner_id" and the tenant
+isolation clause "user is in the same tenant as expense.tenant_id". This service
+turns the common shapes into structured clauses that can be evaluated
+against request attributes. Clauses that cannot be understood evaluate to
+None (indeterminate) instead of guessing.
+"""
+
+import re
+from dataclasses import dataclass, field
+from typing import Any
+
+# Operator spellings normalized to symbols
+WORD_OPERATORS = [
+    (r"greater than or equal to|at least|no less than", ">="),
+    (r"less than or equal to|at most|no more than|up to", "<="),
+    (r"greater than|more than|over|above|exceeds", ">"),
+    (r"less than|under|below", "<"),
+    (r"is not|must not be|not equal to|isn't|!==", "!="),
+    (r"equals|must be|is|===", "=="),
+]
+
+COMPARISON_PATTERN = re.compile(
+    r"(?P<attr>[A-Za-z_][\w.]*)\s*(?P<op>>=|<=|==|!=|>|<|=)\s*(?P<value>\$?-?[\d,]+(?:\.\d+)?|'[^']*'|\"[^\"]*\"|[\w\-]+)"
+)
+
+# "<comparison> requires ROLE": the role is only required when the comparison holds
+IMPLICATION_PATTERN = re.compile(
+    r"^(?P<condition>.+?)\s+requires\s+(?:the\s+)?(?P<role>[A-Za-z][\w\-]*)(?:\s+role)?$",
+    re.IGNORECASE,
+)
+
+OWNERSHIP_PATTERN = re.compile(r"\bown(?:s|er|ership)?\b", re.IGNORECASE)
+
+# "user is owner of expense.owner_id [unless ADMIN]": the caller must be the object's recorded owner
+OWNER_OF_RESOURCE_PATTERN = re.compile(
+    r"^(?:the\s+)?(?:user|caller|subject)\s+is\s+(?:the\s+)?owner\s+of\s+(?P<attr>[A-Za-z_][\w.]*)"
+    r"(?:\s+unless\s+(?:the\s+)?(?P<role>[A-Za-z][\w\-]*)(?:\s+role)?)?$",
+    re.IGNORECASE,
+)
+
+# "user is in the same tenant as expense.tenant_id": the object must belong to the caller's tenant
+SAME_TENANT_PATTERN = re.compile(
+    r"^(?:the\s+)?(?:user|caller|subject)\s+is\s+in\s+(?:the\s+)?same\s+tenant\s+as\s+(?P<attr>[A-Za-z_][\w.]*)$",
+    re.IGNORECASE,
+)
+
+NEGATED_FLAG_PATTERN = re.compile(r"^(?:is\s+)?not\s+(?P<flag>[a-z_]+)$", re.IGNORECASE)
+
+CLAUSE_SEPARATORS = re.compile(r"\s+and\s+|\s*&&\s*|\s*;\s*", re.IGNORECASE)
+
+# Prefixes that refer to the caller rather than the resource
+SUBJECT_PREFIXES = ("current_user.", "user.", "subject.", "principal.", "req.user.", "request.user.")
+
+OWNER_KEYS = ("owner_id", "ownerid", "owner", "created_by", "user_id")
+
+# Keys holding the caller's tenant; looked up exactly, since the resource's tenant shares the last segment
+SUBJECT_TENANT_KEYS = ("user.tenant_id", "subject.tenant_id", "principal.tenant_id", "caller.tenant_id")
+
+
+@dataclass
+class ConditionClause:
+    """One evaluable piece of a mined condition."""
+
+    raw: str
+    kind: str  # comparison, implication, ownership, owner_of_resource, same_tenant, flag, unknown
+    attribute: str | None = None
+    operator: str | None = None
+    value: Any = None
+    required_role: str | None = None
+    inner: "ConditionClause | None" = None
+
+
+@dataclass
+class ConditionResult:
+    """Outcome of evaluating a condition against request attributes."""
+
+    satisfied: bool | None
+    clauses: list[dict] = field(default_factory=list)
+
+
+def _parse_value(raw: str) -> Any:
+    """Parse a literal from condition text."""
+    raw = raw.strip()
+    if len(raw) >= 2 and raw[0] == raw[-1] and raw[0] in "'\"":
+        return raw[1:-1]
+    number = raw.replace("$", "").replace(",", "")
+    try:
+        return float(number) if "." in number else int(number)
+    except ValueError:
+        return raw
+
+
+def _normalize_attribute(attribute: str) -> str:
+    """Strip caller prefixes and normalize an attribute name."""
+    lowered = attribute.strip().lower()
+    for prefix in SUBJECT_PREFIXES:
+        if lowered.startswith(prefix):
+            lowered = lowered[len(prefix):]
+            break
+    lowered = re.sub(r"^(?:the\s+)?(?:user|expense|resource|request)\s+", "", lowered)
+    return lowered.replace(" ", "_")
+
+
+class ConditionEvaluationService:
+    """Parses and evaluates mined condition text."""
+
+    @staticmethod
+    def parse(condition: str | None) -> list[ConditionClause]:
+        """Parse a condition into clauses joined by AND.
+
+        Args:
+            condition: Mined condition text
+
+        Returns:
+            Parsed clauses (empty if there is no condition)
+        """
+        if not condition or condition.strip().lower() in ("", "none", "n/a", "null"):
+            return []
+
+        return [
+            ConditionEvaluationService.parse_clause(part)
+            for part in CLAUSE_SEPARATORS.split(condition.strip())
+            if part.strip()
+        ]
+
+    @staticmethod
+    def parse_clause(text: str) -> ConditionClause:
+        """Parse a single clause.
+
+        Args:
+            text: Clause text
+
+        Returns:
+            ConditionClause (kind "unknown" if not understood)
+        """
+        text = text.strip().rstrip(".")
+
+        implication = IMPLICATION_PATTERN.match(text)
+        if implication:
+            inner = ConditionEvaluationService.parse_clause(implication.group("condition"))
+            if inner.kind == "comparison":
+                return ConditionClause(
+                    raw=text,
+                    kind="implication",
+                    required_role=implication.group("role").upper(),
+                    inner=inner,
+                )
+
+        owner_of = OWNER_OF_RESOURCE_PATTERN.match(text)
+        if owner_of:
+            role = owner_of.group("role")
+            return ConditionClause(
+                raw=text,
+                kind="owner_of_resource",
+                attribute=owner_of.group("attr").lower(),
+                required_role=role.upper() if role else None,
+            )
+
+        same_tenant = SAME_TENANT_PATTERN.match(text)
+        if same_tenant:
+            return ConditionClause(raw=text, kind="same_tenant", attribute=same_tenant.group("attr").lower())
+
+        if OWNERSHIP_PATTERN.search(text) and not COMPARISON_PATTERN.search(text):
+            return ConditionClause(raw=text, kind="ownership")
+
+        negated_flag = NEGATED_FLAG_PATTERN.match(text)
+        if negated_flag:
+            return ConditionClause(
+                raw=text, kind="flag", attribute=negated_flag.group("flag").lower(), value=False
+            )
+
+        normalized = text
+        for words, symbol in WORD_OPERATORS:
+            normalized = re.sub(rf"\s+(?:{words})\s+", f" {symbol} ", normalized, flags=re.IGNORECASE)
+
+        match = COMPARISON_PATTERN.search(normalized)
+        if match:
+            operator = "==" if match.group("op") == "=" else match.group("op")
+            value = _parse_value(match.group("value"))
+            return ConditionClause(
+                raw=text,
+                kind="comparison",
+                attribute=_normalize_attribute(match.group("attr")),
+                operator=operator,
+                value=value,
+            )
+
+        return ConditionClause(raw=text, kind="unknown")
+
+    @staticmethod
+    def _lookup(attribute: str, attributes: dict[str, Any]) -> tuple[bool, Any]:
+        """Find an attribute by normalized name, falling back to its last segment."""
+        normalized = {k.lower(): v for k, v in attributes.items()}
+        for key in (attribute, attribute.split(".")[-1], attribute.replace("_", "")):
+            if key in normalized:
+                return True, normalized[key]
+        return False, None
+
+    @staticmethod
+    def _compare(actual: Any, operator: str, expected: Any) -> bool | None:
+        """Compare two values, numerically when both are numbers."""
+        try:
+            left, right = float(actual), float(expected)
+        except (TypeError, ValueError):
+            left, right = str(actual).strip().lower(), str(expected).strip().lower()
+            if operator not in ("==", "!="):
+                return None
+
+        return {
+            ">": left > right,
+            ">=": left >= right,
+            "<": left < right,
+            "<=": left <= right,
+            "==": left == right,
+            "!=": left != right,
+        }.get(operator)
+
+    @classmethod
+    def evaluate_clause(
+        cls,
+        clause: ConditionClause,
+        attributes: dict[str, Any],
+        roles: list[str],
+        subject_id: str | None = None,
+    ) -> bool | None:
+        """Evaluate one clause.
+
+        Args:
+            clause: Parsed clause
+            attributes: Merged subject, resource, and context attributes
+            roles: Caller roles (upper-case)
+            subject_id: Caller identifier for ownership checks
+
+        Returns:
+            True/False, or None if the clause cannot be evaluated
+        """
+        if clause.kind == "comparison":
+            found, actual = cls._lookup(clause.attribute, attributes)
+            if not found:
+                return None
+            return cls._compare(actual, clause.operator, clause.value)
+
+        if clause.kind == "implication":
+            triggered = cls.evaluate_clause(clause.inner, attributes, roles, subject_id)
+            if triggered is None:
+                return None
+            return (not triggered) or clause.required_role in roles
+
+        if clause.kind == "ownership":
+            if subject_id is None:
+                return None
+            for key in OWNER_KEYS:
+                found, owner = cls._lookup(key, attributes)
+                if found:
+                    return str(owner) == str(subject_id)
+            return None
+
+        if clause.kind == "owner_of_resource":
+            if clause.required_role and clause.required_role in roles:
+                return True
+            if subject_id is None:
+                return None
+            found, owner = cls._lookup(clause.attribute, attributes)
+            return str(owner) == str(subject_id) if found else None
+
+        if clause.kind == "same_tenant":
+            normalized = {k.lower(): v for k, v in attributes.items()}
+            tenant = next((normalized[k] for k in SUBJECT_TENANT_KEYS if k in normalized), None)
+            resource = normalized.get(clause.attribute)
+            if tenant is None or resource is None:
+                return None
+            return str(resource) == str(tenant)
+
+        if clause.kind == "flag":
+            found, actual = cls._lookup(clause.attribute, attributes)
+            if not found:
+                return None
+            return bool(actual) == clause.value
+
+        return None
+
+    @classmethod
+    def evaluate(
+        cls,
+        condition: str | None,
+        attributes: dict[str, Any],
+        roles: list[str],
+        subject_id: str | None = None,
+    ) -> ConditionResult:
+        """Evaluate a mined condition.
+
+        All clauses must hold. Any False clause makes the condition False;
+        otherwise any indeterminate clause makes it indeterminate.
+
+        Args:
+            condition: Mined condition text
+            attributes: Merged subject, resource, and context attributes
+            roles: Caller roles
+            subject_id: Caller identifier for ownership checks
+
+        Returns:
+            ConditionResult with per-clause outcomes
+        """
+        upper_roles = [r.upper() for r in roles]
+        results = []
+        for clause in cls.parse(condition):
+            outcome = cls.evaluate_clause(clause, attributes, upper_roles, subject_id)
+            results.append({"clause": clause.raw, "kind": clause.kind, "satisfied": outcome})
+
+        if any(r["satisfied"] is False for r in results):
+            satisfied = False
+        elif any(r["satisfied"] is None for r in results):
+            satisfied = None
+        else:
+            satisfied = True
+
+        return ConditionResult(satisfied=satisfied, clauses=results)
@@ -0,0 +1,375 @@
+"""Extract authorization rules from deployment configuration files.
+
+Authorization is not only written in application code. OPA bundles, Casbin
+policy files, nginx auth directives, and environment toggles shipped next to
+a service decide who can call it just as much. These extractors turn such
+files into ConfigFinding records (WHO/WHAT/HOW/WHEN plus the evidence lines)
+using plain parsing, without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field
+from pathlib import PurePosixPath
+
+from app.services.extractor_utils import block_end, line_of, line_span
+
+MAX_CONDITIONS_LENGTH = 500
+
+
+class ConfigKind:
+    """Kinds of configuration an extractor understands."""
+
+    OPA_REGO = "opa_rego"
+    CASBIN_POLICY = "casbin_policy"
+    NGINX_AUTH = "nginx_auth"
+    ENV_TOGGLE = "env_toggle"
+
+
+@dataclass
+class ConfigFinding:
+    """An authorization rule found in a configuration file."""
+
+    kind: str
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    subject: str
+    resource: str
+    action: str
+    conditions: str | None = None
+    description: str = ""
+    disables_auth: bool = False
+    service: str | None = None  # Workload or service the rule applies to, if known
+    # (file, first line, last line, snippet) of other places the rule comes from, e.g. the code enforcing it
+    related_evidence: list[tuple[str, int, int, str]] = field(default_factory=list)
+
+
+# Role parameters: requirements read from configuration or a lookup at runtime.
+# RequireRole(cfg.ApproverRole) is recorded as the role "${cfg.ApproverRole}",
+# which users bind to concrete values (see role_parameter_service).
+ROLE_PARAMETER = re.compile(r"\$\{([^{}\s]+)\}")
+ENV_LOOKUP = re.compile(
+    r"^(?:os\s*\.\s*(?:Getenv|getenv|environ\s*\.\s*get|LookupEnv)\s*\(|process\s*\.\s*env\s*\[|os\s*\.\s*environ\s*\[|"
+    r"System\s*\.\s*getenv\s*\(|Environment\s*\.\s*GetEnvironmentVariable\s*\()\s*['\"`](\w+)['\"`]\s*[)\]]$"
+)
+PROCESS_ENV = re.compile(r"^process\s*\.\s*env\s*\.\s*(\w+)$")
+CONFIG_GETTER = re.compile(r"^([\w$]+(?:\s*\.\s*[\w$]+)*)\s*\.\s*[Gg]et\w*\s*\(\s*['\"`]([\w.:-]+)['\"`]\s*\)$")
+ROLE_LOOKUP = re.compile(r"^([\w$]+(?:\s*\.\s*[\w$]+)*)\s*\(\s*(?:['\"`]([\w.:-]+)['\"`])?[^()]*\)$")
+SELECTOR = re.compile(r"^(?:this\s*\.\s*)?([\w$]+(?:\s*\.\s*[\w$]+)+)$")
+CONFIG_ROOT = re.compile(r"conf|cfg|setting|option|opts|viper|env|rbac|polic", re.IGNORECASE)
+ROLE_NAME = re.compile(r"role|perm|scope|group", re.IGNORECASE)
+# Values taken from the request vary per call and are not a parameter of the rule
+REQUEST_ROOTS = {"req", "request", "ctx", "c", "r", "res", "reply", "body", "params", "query", "args", "user", "event"}
+
+
+def role_parameter(expression: str) -> str | None:
+    """Symbolic role for a requirement looked up from configuration or a store.
+
+    Environment lookups become "${env.NAME}", config getters "${config.key}",
+    selectors into a config object such as cfg.ApproverRole keep their path,
+    and role lookups such as store.RoleFor("refund") are named after the call. Request-derived values
+    and bare identifiers are not parameters.
+
+    Args:
+        expression: Argument expression of a guard call
+
+    Returns:
+        "${name}", or None if the expression is not a configured requirement
+    """
+    expression = " ".join(expression.split())
+    env = ENV_LOOKUP.match(expression) or PROCESS_ENV.match(expression)
+    if env:
+        name = f"env.{env.group(1)}"
+    elif getter := CONFIG_GETTER.match(expression):
+        receiver = getter.group(1).replace(" ", "")
+        if receiver.split(".")[0] in REQUEST_ROOTS:
+            return None
+        name = f"config.{getter.group(2)}" if CONFIG_ROOT.search(receiver) else f"{receiver}.{getter.group(2)}"
+    elif selector := SELECTOR.match(expression):
+        name = selector.group(1).replace(" ", "")
+        # Enum members and package constants (Role.Admin, auth.RoleAdmin) are not configuration
+        if name.split(".")[0] in REQUEST_ROOTS or not CONFIG_ROOT.search(name.rsplit(".", 1)[0]):
+            return None
+    elif (lookup := ROLE_LOOKUP.match(expression)) and ROLE_NAME.search(lookup.group(1)):
+        callee = lookup.group(1).replace(" ", "")
+        if callee.split(".")[0] in REQUEST_ROOTS:
+            return None
+        name = f"{callee}.{lookup.group(2)}" if lookup.group(2) else callee
+    else:
+        return None
+    return f"${{{name}}}"
+
+
+# OPA / Rego
+REGO_PACKAGE = re.compile(r"^\s*package\s+([\w.]+)", re.MULTILINE)
+REGO_RULE = re.compile(r"^(allow|deny|authz|authorized|permit)\b[^\n{]*\{", re.MULTILINE)
+REGO_ROLE_PATTERNS = [
+    re.compile(r"input\.[\w.]*roles?\[[^\]]*\]\s*==\s*\"([\w.:-]+)\""),
+    re.compile(r"\"([\w.:-]+)\"\s+in\s+input\.[\w.]*roles?\b"),
+    re.compile(r"input\.[\w.]*role\s*==\s*\"([\w.:-]+)\""),
+]
+REGO_METHOD = re.compile(r"input\.[\w.]*method\s*==\s*\"(\w+)\"")
+REGO_PATH_ARRAY = re.compile(r"input\.[\w.]*path\s*==\s*\[([^\]]*)\]")
+REGO_PATH_STRING = re.compile(r"input\.[\w.]*path\s*==\s*\"([^\"]+)\"")
+
+
+def _rego_path(body: str) -> str | None:
+    """Request path a rule body matches, if it compares input.path."""
+    match = REGO_PATH_ARRAY.search(body)
+    if match:
+        parts = [p.strip() for p in match.group(1).split(",")]
+        return "/" + "/".join(p.strip('"') if p.startswith('"') else "{" + p + "}" for p in parts if p)
+    match = REGO_PATH_STRING.search(body)
+    return match.group(1) if match else None
+
+
+def extract_rego(file_path: str, text: str, rule: str | None = None) -> list[ConfigFinding]:
+    """Extract allow/deny rules from a Rego policy.
+
+    Args:
+        file_path: Path of the .rego file
+        text: File content
+        rule: Extract only the rule with this name instead of the decision rules
+
+    Returns:
+        One finding per decision rule, or per definition of the named rule
+    """
+    package_match = REGO_PACKAGE.search(text)
+    package = package_match.group(1) if package_match else PurePosixPath(file_path).stem
+
+    pattern = re.compile(rf"^({re.escape(rule)})\b[^\n{{]*\{{", re.MULTILINE) if rule else REGO_RULE
+    findings = []
+    for match in pattern.finditer(text):
+        name = match.group(1)
+        end = block_end(text, match.end() - 1)
+        body = text[match.end() : end - 1]
+        roles = sorted({r for pattern in REGO_ROLE_PATTERNS for r in pattern.findall(body)})
+        methods = sorted({m.upper() for m in REGO_METHOD.findall(body)})
+        line_start, line_end = line_of(text, match.start()), line_of(text, end - 1)
+        conditions = " ".join(line.strip() for line in body.splitlines() if line.strip())
+
+        findings.append(
+            ConfigFinding(
+                kind=ConfigKind.OPA_REGO,
+                file_path=file_path,
+                line_start=line_start,
+                line_end=line_end,
+                snippet=line_span(text, line_start, line_end),
+                subject=" or ".join(roles) if roles else "Any caller",
+                resource=_rego_path(body) or package,
+                action=("deny " if name == "deny" else "") + ("/".join(methods) if methods else "access"),
+                conditions=conditions[:MAX_CONDITIONS_LENGTH] or None,
+                description=f"OPA {name} rule in package {package}",
+            )
+        )
+    return findings
+
+
+# Casbin
+def is_casbin_policy(text: str) -> bool:
+    """Check whether CSV content is a Casbin policy (p/g lines)."""
+    rows = [line.strip() for line in text.splitlines() if line.strip() and not line.startswith("#")]
+    return bool(rows) and all(re.match(r"^(p|g)\d*\s*,", row) for row in rows)
+
+
+def extract_casbin(file_path: str, text: str) -> list[ConfigFinding]:
+    """Extract rules from a Casbin policy CSV.
+
+    "p" lines become permissions and "g" lines role inheritance.
+
+    Args:
+        file_path: Path of the policy file
+        text: File content
+
+    Returns:
+        One finding per policy line
+    """
+    findings = []
+    for number, line in enumerate(text.splitlines(), start=1):
+        fields = [f.strip() for f in line.split(",")]
+        if len(fields) < 3 or fields[0].startswith("#"):
+            continue
+        ptype = fields[0]
+        if ptype.startswith("p") and len(fields) >= 4:
+            effect = fields[4].lower() if len(fields) > 4 else "allow"
+            finding = ConfigFinding(
+                kind=ConfigKind.CASBIN_POLICY,
+                file_path=file_path,
+                line_start=number,
+                line_end=number,
+                snippet=line,
+                subject=fields[1],
+                resource=fields[2],
+                action=("deny " if effect == "deny" else "") + fields[3],
+                conditions=None,
+                description=f"Casbin {ptype} rule",
+            )
+        elif ptype.startswith("g"):
+            finding = ConfigFinding(
+                kind=ConfigKind.CASBIN_POLICY,
+                file_path=file_path,
+                line_start=number,
+                line_end=number,
+                snippet=line,
+                subject=fields[1],
+                resource=f"role {fields[2]}",
+                action="inherit",
+                conditions=f"domain {fields[3]}" if len(fields) > 3 and fields[3] else None,
+                description=f"Casbin {ptype} role assignment",
+            )
+        else:
+            continue
+        findings.append(finding)
+    return findings
+
+
+# nginx
+NGINX_AUTH_DIRECTIVES = ("auth_basic", "auth_request", "auth_jwt", "allow", "deny", "satisfy")
+NGINX_BLOCK = re.compile(r"^\s*(server|location)\b\s*([^{]*)\{")
+
+
+def _nginx_subject(directives: dict[str, list[str]]) -> tuple[str, bool]:
+    """Who a set of nginx auth directives lets in, and whether auth is switched off."""
+    if directives.get("auth_basic") == ["off"] and not directives.get("auth_request"):
+        return "Anyone (authentication disabled)", True
+    parts = []
+    if directives.get("auth_basic"):
+        parts.append("Authenticated user (HTTP basic)")
+    if directives.get("auth_jwt"):
+        parts.append("Caller with valid JWT")
+    if directives.get("auth_request"):
+        parts.append(f"Caller approved by {directives['auth_request'][-1]}")
+    allowed = [a for a in directives.get("allow", []) if a != "all"]
+    if allowed:
+        parts.append(f"Clients from {', '.join(allowed)}")
+    if not parts:
+        return ("Nobody" if "all" in directives.get("deny", []) else "Any caller"), False
+    joiner = " or " if directives.get("satisfy") == ["any"] else " and "
+    return joiner.join(parts), False
+
+
+def extract_nginx(file_path: str, text: str) -> list[ConfigFinding]:
+    """Extract access control from nginx server and location blocks.
+
+    Args:
+        file_path: Path of the nginx config file
+        text: File content
+
+    Returns:
+        One finding per server or location block with auth directives
+    """
+    findings = []
+    # Stack of (kind, path, start line, directives, directive lines); None for other blocks
+    stack: list[tuple[str, str, int, dict[str, list[str]], list[int]] | None] = []
+    lines = text.splitlines()
+
+    for number, raw in enumerate(lines, start=1):
+        line = raw.split("#", 1)[0].strip()
+        if not line:
+            continue
+        block = NGINX_BLOCK.match(line)
+        if block:
+            kind, args = block.group(1), block.group(2).split()
+            path = args[-1] if args else "/"
+            stack.append((kind, "/" if kind == "server" else path, number, {}, []))
+        elif line.endswith("{"):
+            stack.append(None)
+        elif line.startswith("}"):
+            if not stack:
+                continue
+            context = stack.pop()
+            if context is None or not context[3]:
+                continue
+            kind, path, start, directives, directive_lines = context
+            subject, disabled = _nginx_subject(directives)
+            findings.append(
+                ConfigFinding(
+                    kind=ConfigKind.NGINX_AUTH,
+                    file_path=file_path,
+                    line_start=start,
+                    line_end=number,
+                    snippet="\n".join(lines[start - 1 : number]),
+                    subject=subject,
+                    resource=path,
+                    action="access",
+                    conditions="; ".join(lines[n - 1].strip().rstrip(";") for n in directive_lines),
+                    description=f"nginx {kind} auth configuration",
+                    disables_auth=disabled,
+                )
+            )
+        else:
+            name, _, value = line.rstrip(";").partition(" ")
+            if name in NGINX_AUTH_DIRECTIVES and stack and stack[-1] is not None:
+                stack[-1][3].setdefault(name, []).append(value.strip().strip('"'))
+                stack[-1][4].append(number)
+    return findings
+
+
+# Environment toggles
+ENV_AUTH_KEY = re.compile(r"AUTH|RBAC|ACL|OPA|PERMISSION|SECURITY|JWT|CSRF|SSO|OAUTH|LOGIN", re.IGNORECASE)
+ENV_NEGATING_KEY = re.compile(r"DISABLE|SKIP|BYPASS|INSECURE|ALLOW_ANONYMOUS|NO_AUTH", re.IGNORECASE)
+TRUTHY = {"true", "1", "yes", "on", "enabled"}
+FALSY = {"false", "0", "no", "off", "disabled"}
+
+
+def extract_env(source: str, variables: list[str]) -> list[ConfigFinding]:
+    """Extract security toggles from KEY=value environment entries.
+
+    Only boolean-valued variables whose name refers to authentication or
+    authorization are kept; a toggle that switches checks off is flagged.
+
+    Args:
+        source: Where the variables came from (image config or an .env file path)
+        variables: KEY=value entries in order
+
+    Returns:
+        One finding per security toggle
+    """
+    findings = []
+    for number, entry in enumerate(variables, start=1):
+        key, sep, value = entry.strip().removeprefix("export ").partition("=")
+        value = value.strip().strip("'\"").lower()
+        if not sep or not ENV_AUTH_KEY.search(key) or value not in TRUTHY | FALSY:
+            continue
+        negating = bool(ENV_NEGATING_KEY.search(key))
+        disables = value in TRUTHY if negating else value in FALSY
+        findings.append(
+            ConfigFinding(
+                kind=ConfigKind.ENV_TOGGLE,
+                file_path=source,
+                line_start=number,
+                line_end=number,
+                snippet=entry.strip(),
+                subject="All callers",
+                resource=key,
+                action="bypass checks" if disables else "enforce checks",
+                conditions=f"{key}={value}",
+                description="Environment toggle " + ("disabling" if disables else "enabling") + " security checks",
+                disables_auth=disables,
+            )
+        )
+    return findings
+
+
+def extract_config_file(file_path: str, text: str) -> list[ConfigFinding]:
+    """Run the extractor matching a configuration file.
+
+    Args:
+        file_path: File path (used to pick the extractor)
+        text: File content
+
+    Returns:
+        Findings, or an empty list if the file is not authorization config
+    """
+    path = PurePosixPath(file_path)
+    name = path.name.lower()
+    if path.suffix == ".rego" and not name.endswith("_test.rego"):
+        return extract_rego(file_path, text)
+    if path.suffix == ".csv" and is_casbin_policy(text):
+        return extract_casbin(file_path, text)
+    if "nginx" in file_path.lower() and (path.suffix == ".conf" or "sites-enabled" in path.parts):
+        return extract_nginx(file_path, text)
+    if name == ".env" or name.endswith(".env"):
+        return extract_env(file_path, text.splitlines())
+    return []
@@ -0,0 +1,237 @@
+"""Service for merging configuration-derived rules into repository policies.
+
+Container images and deployment manifests carry authorization rules for the
+same services whose source code the scanner mines. This service attributes
+ConfigFinding records to a repository's policy view: findings that match an
+existing rule add corroborating evidence to it, the rest become new policies
+under the service's application. Each config source is identified by its
+evidence paths, so rescanning a source replaces its earlier findings.
+"""
+
+from collections import Counter
+
+import structlog
+from sqlalchemy import or_
+from sqlalchemy.orm import Session
+
+from app.models.application import Application
+from app.models.policy import Evidence, ExtractionMethod, Policy, PolicyStatus, RiskLevel, SourceType
+from app.models.repository import Repository
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extraction_confidence import method_confidence
+from app.services.risk_scoring_service import RiskScoringService
+
+logger = structlog.get_logger(__name__)
+
+
+class ConfigPolicyService:
+    """Attributes config findings to a repository's policies."""
+
+    # How the rules this source creates were extracted, which caps their confidence
+    extraction_method = ExtractionMethod.DECLARED_CONFIG
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get the repository config findings belong to.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Repository
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        query = self.db.query(Repository).filter(Repository.id == repository_id)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        repo = query.first()
+        if not repo:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repo
+
+    @staticmethod
+    def _key(subject: str, resource: str, action: str) -> tuple[str, str, str]:
+        """Case-insensitive identity of a rule."""
+        return (subject.strip().lower(), resource.strip().lower(), action.strip().lower())
+
+    def _remove_previous(self, repo: Repository, patterns: list[str], label: str | None = None) -> int:
+        """Drop evidence from an earlier scan of the same config source.
+
+        Policies whose only evidence came from that source are deleted; source
+        policies that an earlier scan merged into just lose the extra evidence.
+
+        Args:
+            repo: Repository
+            patterns: SQL LIKE patterns matching the source's evidence paths
+            label: Only consider policies created from this source, for sources
+                whose files also hold evidence of other policies
+
+        Returns:
+            Number of policies deleted
+        """
+        query = (
+            self.db.query(Evidence)
+            .join(Policy, Evidence.policy_id == Policy.id)
+            .filter(Policy.repository_id == repo.id)
+            .filter(or_(*[Evidence.file_path.like(p) for p in patterns]))
+        )
+        if label:
+            query = query.filter(Policy.description.like(f"%(from {label})"))
+        previous = query.all()
+        previous_ids = {id(e) for e in previous}
+        deleted_policies: set[int] = set()
+        for evidence in previous:
+            policy = evidence.policy
+            if policy.id in deleted_policies:
+                continue
+            if all(id(e) in previous_ids for e in policy.evidence):
+                self.db.delete(policy)
+                deleted_policies.add(policy.id)
+            else:
+                self.db.delete(evidence)
+        self.db.flush()
+        return len(deleted_policies)
+
+    def _score(self, finding: ConfigFinding) -> dict:
+        """Risk scores for a finding, forcing HIGH when it switches auth off."""
+        complexity = RiskScoringService.calculate_complexity_score(
+            finding.subject, finding.resource, finding.action, finding.conditions, finding.snippet
+        )
+        impact = RiskScoringService.calculate_impact_score(
+            finding.subject, finding.resource, finding.action, finding.conditions
+        )
+        confidence = method_confidence(
+            self.extraction_method,
+            RiskScoringService.calculate_confidence_score(
+                1, finding.snippet, finding.subject, finding.resource, finding.action
+            ),
+        )
+        historical = RiskScoringService.calculate_historical_score()
+        risk_score = RiskScoringService.calculate_overall_risk_score(complexity, impact, confidence, historical)
+        if finding.disables_auth:
+            risk_score = max(risk_score, 70.0)
+
+        if risk_score >= 70:
+            risk_level = RiskLevel.HIGH
+        elif risk_score >= 40:
+            risk_level = RiskLevel.MEDIUM
+        else:
+            risk_level = RiskLevel.LOW
+        return {
+            "risk_score": risk_score,
+            "risk_level": risk_level,
+            "complexity_score": complexity,
+            "impact_score": impact,
+            "confidence_score": confidence,
+            "extraction_method": self.extraction_method,
+            "historical_score": historical,
+        }
+
+    def _service_applications(self, findings: list[ConfigFinding]) -> dict[str, int]:
+        """Application IDs for the service names findings are attributed to."""
+        names = {f.service.lower() for f in findings if f.service}
+        if not names:
+            return {}
+        query = self.db.query(Application)
+        if self.tenant_id:
+            query = query.filter(Application.tenant_id == self.tenant_id)
+        return {app.name.lower(): app.id for app in query.all() if app.name.lower() in names}
+
+    def merge_findings(
+        self,
+        repo: Repository,
+        findings: list[ConfigFinding],
+        label: str,
+        origin: str = "",
+        previous: list[str] | None = None,
+        label_scoped: bool = False,
+    ) -> dict:
+        """Merge config findings into a repository's policies.
+
+        A finding matching an existing rule (same subject, resource, and action)
+        adds evidence to that policy. Other findings become new policies under
+        the application named by the finding's service, falling back to the
+        application most of the repository's policies belong to.
+
+        Args:
+            repo: Repository the config belongs to
+            findings: Extracted findings
+            label: Human-readable source for policy descriptions
+            origin: Prefix added to evidence paths to identify the config source
+            previous: LIKE patterns matching evidence from earlier scans of this
+                source (defaults to everything under origin)
+            label_scoped: Only replace policies created from this source. Use it
+                when evidence paths are application source files that mined
+                policies also cite, so their evidence is kept.
+
+        Returns:
+            Counts of policies created, merged into, and removed from earlier scans
+        """
+        removed = self._remove_previous(repo, previous or [f"{origin}%"], label if label_scoped else None)
+
+        policies = self.db.query(Policy).filter(Policy.repository_id == repo.id).all()
+        by_key = {self._key(p.subject, p.resource, p.action): p for p in policies}
+        applications = Counter(p.application_id for p in policies if p.application_id)
+        default_application_id = applications.most_common(1)[0][0] if applications else None
+        service_applications = self._service_applications(findings)
+
+        created = 0
+        merged_ids: set[int] = set()
+        for finding in findings:
+            evidence = Evidence(
+                file_path=f"{origin}{finding.file_path}",
+                line_start=finding.line_start,
+                line_end=finding.line_end,
+                code_snippet=finding.snippet,
+            )
+            related = [
+                Evidence(file_path=f"{origin}{path}", line_start=start, line_end=end, code_snippet=snippet)
+                for path, start, end, snippet in finding.related_evidence
+            ]
+            key = self._key(finding.subject, finding.resource, finding.action)
+            policy = by_key.get(key)
+            if policy is not None:
+                for item in [evidence, *related]:
+                    if not any(e.file_path == item.file_path and e.line_start == item.line_start for e in policy.evidence):
+                        policy.evidence.append(item)
+                if policy.id is not None:
+                    merged_ids.add(policy.id)
+                continue
+
+            policy = Policy(
+                repository_id=repo.id,
+                application_id=service_applications.get((finding.service or "").lower(), default_application_id),
+                subject=finding.subject,
+                resource=finding.resource,
+                action=finding.action,
+                conditions=finding.conditions,
+                description=f"{finding.description} (from {label})",
+                status=PolicyStatus.PENDING,
+                source_type=SourceType.BACKEND,
+                tenant_id=repo.tenant_id,
+                **self._score(finding),
+            )
+            policy.evidence.append(evidence)
+            for item in related:
+                if not any(e.file_path == item.file_path and e.line_start == item.line_start for e in policy.evidence):
+                    policy.evidence.append(item)
+            self.db.add(policy)
+            by_key[key] = policy
+            created += 1
+
+        self.db.commit()
+        logger.info(
+            "config_findings_merged",
+            repository_id=repo.id,
+            source=label,
+            created=created,
+            merged=len(merged_ids),
+            removed=removed,
+        )
+        return {"policies_created": created, "policies_merged": len(merged_ids), "policies_removed": removed}
@@ -0,0 +1,229 @@
+"""Service for verifying mined policies against a running application.
+
+Static analysis says what an endpoint *should* enforce. This service replays
+the generated authorization test cases as real HTTP requests against a staging
+deployment, using per-role credentials, and reports every case where the
+live response disagrees with the mined expectation.
+"""
+
+import asyncio
+import time
+from collections import Counter
+from dataclasses import asdict, dataclass
+
+import httpx
+import structlog
+from sqlalchemy.orm import Session
+
+from app.services.authz_test_generation_service import AuthzTestCase, AuthzTestGenerationService
+from app.services.endpoint_mapping_service import ANONYMOUS_ROLE
+
+logger = structlog.get_logger(__name__)
+
+# Methods that cannot modify server state; others are only sent when explicitly enabled
+SAFE_METHODS = {"GET", "HEAD", "OPTIONS"}
+
+# Responses that mean the application refused the caller
+REFUSAL_STATUSES = {401, 403}
+
+# A denied caller may also get 404, since many services hide resources from
+# callers who may not see them. An allowed caller getting 404 usually just
+# means the placeholder ID does not exist, so it is not treated as a refusal.
+DENIAL_STATUSES = REFUSAL_STATUSES | {404}
+
+
+class VerificationOutcome:
+    """Possible outcomes for a verified case."""
+
+    MATCH = "match"
+    UNEXPECTED_ACCESS = "unexpected_access"  # statically denied, live request succeeded
+    UNEXPECTED_DENIAL = "unexpected_denial"  # statically allowed, live request refused
+    ERROR = "error"
+    SKIPPED = "skipped"
+
+
+@dataclass
+class VerificationResult:
+    """Live outcome of one authorization test case."""
+
+    method: str
+    path: str
+    role: str
+    expected_allowed: bool
+    expected_status: int
+    outcome: str
+    actual_status: int | None = None
+    detail: str | None = None
+    duration_ms: int = 0
+
+
+class ContractVerificationService:
+    """Replays mined authorization expectations against a live deployment."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.test_generation_service = AuthzTestGenerationService(db, tenant_id)
+
+    async def verify_repository(
+        self,
+        repository_id: int,
+        base_url: str,
+        credentials: dict[str, dict[str, str]],
+        include_unsafe_methods: bool = False,
+        timeout_seconds: float = 10.0,
+        max_concurrency: int = 5,
+    ) -> dict:
+        """Verify a repository's mined policies against a running application.
+
+        Args:
+            repository_id: Repository whose policies should be verified
+            base_url: Base URL of the staging deployment
+            credentials: Role name -> request headers that authenticate as that role
+            include_unsafe_methods: Also send POST/PUT/PATCH/DELETE requests
+            timeout_seconds: Per-request timeout
+            max_concurrency: Maximum requests in flight
+
+        Returns:
+            Verification report with per-case results and summary counts
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        _, rules = self.test_generation_service.load_endpoint_rules(repository_id)
+        cases = AuthzTestGenerationService.build_cases(rules)
+        credentials = {role.upper(): headers for role, headers in credentials.items()}
+
+        logger.info(
+            "contract_verification_started",
+            repository_id=repository_id,
+            base_url=base_url,
+            cases=len(cases),
+            roles_with_credentials=sorted(credentials),
+        )
+
+        semaphore = asyncio.Semaphore(max(1, max_concurrency))
+
+        async with httpx.AsyncClient(base_url=base_url, timeout=timeout_seconds) as client:
+
+            async def run(case: AuthzTestCase) -> VerificationResult:
+                skip_reason = self._skip_reason(case, credentials, include_unsafe_methods)
+                if skip_reason:
+                    return self._result(case, VerificationOutcome.SKIPPED, detail=skip_reason)
+                headers = {} if case.role == ANONYMOUS_ROLE else credentials[case.role.upper()]
+                async with semaphore:
+                    return await self._execute(client, case, headers)
+
+            results = await asyncio.gather(*(run(case) for case in cases))
+
+        report = self._build_report(repository_id, base_url, list(results))
+        logger.info(
+            "contract_verification_complete",
+            repository_id=repository_id,
+            matched=report["matched"],
+            mismatched=report["mismatched"],
+            errors=report["errors"],
+            skipped=report["skipped"],
+        )
+        return report
+
+    @staticmethod
+    def _skip_reason(
+        case: AuthzTestCase, credentials: dict[str, dict[str, str]], include_unsafe_methods: bool
+    ) -> str | None:
+        """Explain why a case cannot be sent, or None if it can."""
+        if case.skip_reason:
+            return case.skip_reason
+        if case.method not in SAFE_METHODS and not include_unsafe_methods:
+            return f"{case.method} requests disabled (enable include_unsafe_methods)"
+        if case.role != ANONYMOUS_ROLE and case.role.upper() not in credentials:
+            return f"no credentials provided for role {case.role}"
+        return None
+
+    async def _execute(
+        self, client: httpx.AsyncClient, case: AuthzTestCase, headers: dict[str, str]
+    ) -> VerificationResult:
+        """Send one request and classify the response.
+
+        Args:
+            client: HTTP client bound to the deployment's base URL
+            case: Test case to execute
+            headers: Authentication headers for the case's role
+
+        Returns:
+            VerificationResult for the case
+        """
+        start = time.monotonic()
+        try:
+            response = await client.request(case.method, case.path, headers=headers)
+        except httpx.HTTPError as e:
+            return self._result(
+                case,
+                VerificationOutcome.ERROR,
+                detail=f"request failed: {e}",
+                duration_ms=int((time.monotonic() - start) * 1000),
+            )
+
+        duration_ms = int((time.monotonic() - start) * 1000)
+        status = response.status_code
+
+        if status >= 500:
+            outcome, detail = VerificationOutcome.ERROR, f"server error {status}"
+        elif case.allowed and status in REFUSAL_STATUSES:
+            outcome, detail = VerificationOutcome.UNEXPECTED_DENIAL, f"{case.role} was refused with {status}"
+        elif not case.allowed and status not in DENIAL_STATUSES:
+            outcome, detail = VerificationOutcome.UNEXPECTED_ACCESS, f"{case.role} was granted {status}"
+        else:
+            outcome, detail = VerificationOutcome.MATCH, None
+
+        return self._result(case, outcome, actual_status=status, detail=detail, duration_ms=duration_ms)
+
+    @staticmethod
+    def _result(
+        case: AuthzTestCase,
+        outcome: str,
+        actual_status: int | None = None,
+        detail: str | None = None,
+        duration_ms: int = 0,
+    ) -> VerificationResult:
+        """Build a result for a case."""
+        return VerificationResult(
+            method=case.method,
+            path=case.path,
+            role=case.role,
+            expected_allowed=case.allowed,
+            expected_status=case.expected_status,
+            outcome=outcome,
+            actual_status=actual_status,
+            detail=detail,
+            duration_ms=duration_ms,
+        )
+
+    @staticmethod
+    def _build_report(repository_id: int, base_url: str, results: list[VerificationResult]) -> dict:
+        """Summarize verification results.
+
+        Args:
+            repository_id: Repository ID
+            base_url: Deployment base URL
+            results: Per-case results
+
+        Returns:
+            Report dictionary
+        """
+        counts = Counter(result.outcome for result in results)
+
+        return {
+            "repository_id": repository_id,
+            "base_url": base_url,
+            "total": len(results),
+            "matched": counts[VerificationOutcome.MATCH],
+            "mismatched": counts[VerificationOutcome.UNEXPECTED_ACCESS]
+            + counts[VerificationOutcome.UNEXPECTED_DENIAL],
+            "unexpected_access": counts[VerificationOutcome.UNEXPECTED_ACCESS],
+            "unexpected_denial": counts[VerificationOutcome.UNEXPECTED_DENIAL],
+            "errors": counts[VerificationOutcome.ERROR],
+            "skipped": counts[VerificationOutcome.SKIPPED],
+            "results": [asdict(result) for result in results],
+        }
@@ -0,0 +1,486 @@
+"""Service for mining CORS configuration and CSRF protection per service and route.
+
+Authorization decides who may call an endpoint, but a browser also attaches a
+signed-in user's credentials to requests that another site starts. CORS
+settings decide which origins may read those responses, and CSRF protection
+decides whether state-changing requests must prove they came from the
+application's own pages. This service reads both from a repository's clone,
+resolves them onto the endpoints the mined policies map to, and flags
+authenticated endpoints that any origin may call with credentials.
+"""
+
+import re
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass, field
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.coverage_metrics_service import (
+    ROUTE_FILE_EXTENSIONS,
+    ROUTE_FILE_NAMES,
+    SKIPPED_DIRECTORIES,
+    route_key,
+)
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import SourceLines, group_end
+
+logger = structlog.get_logger(__name__)
+
+# Route sources plus the config files frameworks keep CORS and CSRF settings in
+SOURCE_EXTENSIONS = ROUTE_FILE_EXTENSIONS | {".yml", ".yaml", ".properties", ".conf"}
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(r"cors|cross.?origin|access-control-allow|csrf|xsrf|forgery|nocsrf", re.IGNORECASE)
+
+# A CORS setting spans at most this many lines from its anchor
+MAX_WINDOW_LINES = 15
+
+# Requests a browser may send cross-site without CSRF concerns
+SAFE_METHODS = {"GET", "HEAD", "OPTIONS"}
+
+# Framework CORS entry points. Those in the first group allow every origin
+# when called without origin options.
+DEFAULT_WILDCARD_ANCHOR = re.compile(
+    r"\bcors\s*\(|\bCORS\s*\(|@cross_origin\b|@CrossOrigin\b|\bcors\.(?:Default|AllowAll)\s*\(|"
+    r"\bmiddleware\.CORS\s*\(|\bregister\(\s*(?:cors|fastifyCors)\b"
+)
+CORS_ANCHOR = re.compile(
+    DEFAULT_WILDCARD_ANCHOR.pattern + r"|CORSMiddleware|\bAddCors\s*\(|\bUseCors\s*\(|addCorsMappings|"
+    r"\bnew\s+CorsConfiguration\s*\(|\bcors\.(?:New|Options|Config)\b|middleware\.CORSWithConfig|"
+    r"handlers\.CORS\s*\(|Access-Control-Allow-Origin"
+)
+# Django settings are gathered per file rather than per anchor
+CORS_SETTING_LINE = re.compile(r"^\s*CORS_[A-Z_]+\s*=", re.MULTILINE)
+# Following lines that continue a CORS setting (method chains, allow-list and credential setters)
+CORS_CONTINUATION = re.compile(r"^\s*\.|origin|credentials|allow|methods|headers|max_?age|expose|cors", re.IGNORECASE)
+
+WILDCARD_ORIGIN = re.compile(
+    r"""origins?(?:_?patterns?|_?list)?\w*['"]?\s*[=:(,]\s*(?:\[\]string\s*\{|\[|\(|\{|List\.of\(|"""
+    r"""Arrays\.asList\(|new\s+[\w<>]+\[\]\s*\{)?\s*[rf]?['"]\*['"]|"""
+    r"""AllowAnyOrigin\s*\(|AllowAllOrigins\s*[:=]\s*true|ALLOW_ALL_ORIGINS\s*=\s*True|"""
+    r"""ORIGIN_ALLOW_ALL\s*=\s*True|Access-Control-Allow-Origin['"]?[\s,:=]+['"]?\*""",
+    re.IGNORECASE,
+)
+REFLECTED_ORIGIN = re.compile(
+    r"""origin\s*:\s*true\b|SetIsOriginAllowed\s*\(\s*\(?\s*\w*\s*\)?\s*=>\s*true|"""
+    r"""AllowOriginFunc\b[^\n]*return\s+true|allow_origin_regex\s*=\s*r?['"]\.\*['"]|"""
+    r"""origin\s*:\s*\(?\s*\w+\s*,\s*(\w+)\s*\)?\s*=>\s*\1\s*\(\s*null\s*,\s*true|"""
+    r"""(?:req|request)\.headers?\.origin\b|headers?\.get\(\s*['"]origin['"]|headers\[\s*['"]origin['"]\]|"""
+    r"""\$http_origin""",
+    re.IGNORECASE,
+)
+ALLOW_CREDENTIALS = re.compile(r"""credentials\w*['"]?[\s=:(,]+['"]?true|AllowCredentials\s*\(\s*\)""", re.IGNORECASE)
+ORIGIN_VALUE = re.compile(r"""['"](https?://[^'"\s]+)['"]""")
+ORIGIN_OPTIONS = re.compile(r"origin|AllowAnyOrigin|ALLOW_ALL", re.IGNORECASE)
+# Path patterns a CORS setting is registered for
+CORS_SCOPE = re.compile(
+    r"""resources\s*=\s*\{\s*r?['"]([^'"]+)['"]|\.use\(\s*['"](/[^'"]*)['"]|"""
+    r"""addMapping\(\s*"([^"]+)"|registerCorsConfiguration\(\s*"([^"]+)\""""
+)
+# A lone identifier passed as options, e.g., cors(corsOptions)
+OPTIONS_IDENTIFIER = re.compile(r"\(\s*([A-Za-z_$][\w$]*)\s*\)")
+
+
+class CsrfStatus:
+    """What a CSRF setting does."""
+
+    ENABLED = "enabled"  # Protection middleware, filter, or attribute installed
+    DISABLED = "disabled"  # Protection turned off for the whole service
+    EXEMPT = "exempt"  # Specific routes or views opted out
+
+
+CSRF_SIGNATURES: list[tuple[str, re.Pattern]] = [
+    (
+        CsrfStatus.DISABLED,
+        re.compile(
+            r"csrf\s*\(\s*\)\s*\.\s*disable\s*\(|csrf\s*\(\s*(?:AbstractHttpConfigurer|CsrfConfigurer)\s*::\s*disable|"
+            r"csrf\s*\(\s*\w+\s*->\s*\w+\s*\.\s*disable\s*\(|\.csrf\s*\{\s*(?:it\.)?disable\s*\(|"
+            r"WTF_CSRF_ENABLED\s*=\s*False|WTF_CSRF_CHECK_DEFAULT\s*=\s*False|"
+            r"protect_from_forgery\s+with:\s*:null_session|skip_forgery_protection|\bcsrf['\"]?\s*:\s*false\b",
+            re.IGNORECASE,
+        ),
+    ),
+    (
+        CsrfStatus.EXEMPT,
+        re.compile(
+            r"@csrf_exempt|csrf\.exempt|\bcsrf_exempt\s*\(|skip_before_action\s+:verify_authenticity_token|"
+            r"IgnoreAntiforgeryToken|\+\s*nocsrf|ignoringRequestMatchers\s*\(|ignoringAntMatchers\s*\(|"
+            r"DisableAntiforgery\s*\(|@CsrfExempt"
+        ),
+    ),
+    (
+        CsrfStatus.ENABLED,
+        re.compile(
+            r"\bcsurf\b|\bcsrfProtection\b|lusca\.csrf|\bcsrf\s*\(\s*\{|CSRFProtect\s*\(|CsrfViewMiddleware|"
+            r"protect_from_forgery(?!\s+with:\s*:null_session)|VerifyCsrfToken|ValidateAntiForgeryToken|"
+            r"AutoValidateAntiforgeryToken|AddAntiforgery|UseAntiforgery|csrf\.Protect\s*\(|CSRFFilter|"
+            r"@fastify/csrf|doubleCsrf|CsrfTokenRepository|nosurf\.New|middleware\.CSRF|"
+            r"SecurityFilterChain|WebSecurityConfigurerAdapter"
+        ),
+    ),
+]
+EXEMPTION_TEXT = re.compile(
+    CSRF_SIGNATURES[0][1].pattern + "|" + CSRF_SIGNATURES[1][1].pattern + r"|CSRF check disabled",
+    re.IGNORECASE,
+)
+QUOTED_PATH = re.compile(r"""['"](/[^'"\s]*)['"]""")
+IMPORT_LINE = re.compile(r"^\s*(?:import|from\s+\S+\s+import|using|package|require)\b")
+
+
+@dataclass
+class CorsSetting:
+    """One CORS configuration in a repository."""
+
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    scope: str | None = None  # Path pattern it applies to; None when service-wide
+    origins: list[str] = field(default_factory=list)
+    wildcard_origin: bool = False
+    reflects_origin: bool = False  # Echoes the request's Origin, which allows any origin
+    allow_credentials: bool = False
+
+    @property
+    def credentialed_wildcard(self) -> bool:
+        """Any origin may send credentialed requests."""
+        return (self.wildcard_origin or self.reflects_origin) and self.allow_credentials
+
+
+@dataclass
+class CsrfSetting:
+    """One CSRF protection setting or exemption in a repository."""
+
+    file_path: str
+    line_start: int
+    status: str
+    setting: str
+    scopes: list[str] = field(default_factory=list)  # Exempted paths, when known
+
+
+def _window(source: SourceLines, start: int) -> tuple[int, int]:
+    """Line range (1-based, inclusive) of the CORS setting anchored at start."""
+    first = source.line_of(start)
+    last = source.line_of(group_end(source.text, start))
+    line = source.lines[first - 1].rstrip()
+    if last == first and line.endswith("{"):
+        # A method or callback body configures the setting, e.g., addCorsMappings(registry) {
+        body_end = source.line_of(group_end(source.text, source.starts[first - 1] + len(line) - 1))
+        last = min(body_end, first + MAX_WINDOW_LINES - 1)
+    while (
+        last < len(source.lines)
+        and last - first + 1 < MAX_WINDOW_LINES
+        and CORS_CONTINUATION.search(source.lines[last])
+        and not CORS_ANCHOR.search(source.lines[last])
+    ):
+        last += 1
+    return first, last
+
+
+def _resolve_options(text: str, window: str) -> str:
+    """Append the definition of an options variable passed by name, e.g., cors(corsOptions)."""
+    match = OPTIONS_IDENTIFIER.search(window)
+    if not match or ORIGIN_OPTIONS.search(window):
+        return window
+    definition = re.search(rf"\b{re.escape(match.group(1))}\s*[=:]\s*", text)
+    if not definition:
+        return window
+    return window + "\n" + text[definition.start() : group_end(text, definition.end())]
+
+
+def _first_route(text: str) -> list[str]:
+    """Path of the first route registered in a snippet, as a one-item list."""
+    routes = EndpointMappingService.find_routes(text)
+    return [routes[0][1]] if routes else []
+
+
+def _cors_setting(source: SourceLines, first: int, last: int, window: str, anchor: str) -> CorsSetting:
+    """Parse the origins, credentials, and scope of one CORS setting."""
+    scopes = [next(g for g in m.groups() if g) for m in CORS_SCOPE.finditer(window)]
+    if not scopes:
+        # Decorators and annotations precede the route they apply to
+        scopes = _first_route(source.span(first, last + 3) if anchor.startswith("@") else source.span(first, first))
+    wildcard = bool(WILDCARD_ORIGIN.search(window))
+    if DEFAULT_WILDCARD_ANCHOR.match(anchor) and not ORIGIN_OPTIONS.search(window):
+        wildcard = True
+    return CorsSetting(
+        file_path=source.file_path,
+        line_start=first,
+        line_end=last,
+        snippet=source.span(first, last),
+        scope=scopes[0] if scopes else None,
+        origins=list(dict.fromkeys(ORIGIN_VALUE.findall(window))),
+        wildcard_origin=wildcard,
+        reflects_origin=bool(REFLECTED_ORIGIN.search(window)),
+        allow_credentials=bool(ALLOW_CREDENTIALS.search(window)),
+    )
+
+
+def extract_cors(file_path: str, text: str) -> list[CorsSetting]:
+    """Find CORS settings in one source or config file.
+
+    Args:
+        file_path: Repository-relative path
+        text: File content
+
+    Returns:
+        CORS settings in order of appearance; Django CORS_* settings in one
+        file form a single service-wide setting
+    """
+    if not PREFILTER.search(text):
+        return []
+    source = SourceLines(file_path, text)
+    results = []
+    settings_lines = []
+    for match in CORS_SETTING_LINE.finditer(text):
+        settings_lines.append((source.line_of(match.start()), source.line_of(group_end(text, match.start()))))
+    if settings_lines:
+        window = "\n".join(source.span(first, last) for first, last in settings_lines)
+        results.append(_cors_setting(source, settings_lines[0][0], settings_lines[-1][1], window, "CORS_"))
+
+    covered_until = 0
+    for match in CORS_ANCHOR.finditer(text):
+        if match.start() < covered_until:
+            continue
+        first, last = _window(source, match.start())
+        window = _resolve_options(text, source.span(first, last))
+        results.append(_cors_setting(source, first, last, window, match.group()))
+        covered_until = source.starts[last] if last < len(source.starts) else len(text)
+    return results
+
+
+def extract_csrf(file_path: str, text: str) -> list[CsrfSetting]:
+    """Find CSRF protection settings and exemptions in one source or config file.
+
+    Args:
+        file_path: Repository-relative path
+        text: File content
+
+    Returns:
+        CSRF settings in order of appearance, at most one per line
+    """
+    if not PREFILTER.search(text):
+        return []
+    lines = text.split("\n")
+    results = []
+    for number, line in enumerate(lines, start=1):
+        if IMPORT_LINE.match(line):
+            continue
+        for status, pattern in CSRF_SIGNATURES:
+            match = pattern.search(line)
+            if not match:
+                continue
+            scopes = []
+            if status == CsrfStatus.EXEMPT:
+                # Decorators and route modifiers precede the route they exempt
+                scopes = QUOTED_PATH.findall(line) or _first_route("\n".join(lines[number - 1 : number + 3]))
+            results.append(CsrfSetting(file_path, number, status, match.group().strip(), scopes))
+            break
+    return results
+
+
+def scope_matches(scope: str, path: str) -> bool:
+    """Whether a route path falls under a CORS or CSRF path pattern.
+
+    Args:
+        scope: Pattern such as "/api/*", "/api/**", r"^/api/.*", or an exact path
+        path: Route path
+
+    Returns:
+        True for prefix matches of wildcard patterns and equal exact paths
+    """
+    pattern = scope.lstrip("^").rstrip("$")
+    prefix = re.sub(r"(?:/?\*\*?|\.\*|\(\.\*\))$", "", pattern)
+    normalized = route_key("GET", path).split(" ", 1)[1]
+    if prefix == pattern:
+        return normalized == route_key("GET", pattern).split(" ", 1)[1]
+    base = route_key("GET", prefix or "/").split(" ", 1)[1].rstrip("/")
+    return normalized == base or normalized.startswith(base + "/")
+
+
+def csrf_protection(csrf: list[CsrfSetting]) -> str:
+    """Summarize a service's CSRF protection.
+
+    Args:
+        csrf: The service's CSRF settings
+
+    Returns:
+        "disabled" when turned off service-wide, "partial" when some routes
+        are exempt, "enabled" when installed, otherwise "none"
+    """
+    statuses = {c.status for c in csrf}
+    if CsrfStatus.DISABLED in statuses:
+        return "disabled"
+    if CsrfStatus.EXEMPT in statuses:
+        return "partial"
+    return "enabled" if CsrfStatus.ENABLED in statuses else "none"
+
+
+def _policy_text(policy: Policy) -> str:
+    """Conditions, description, and evidence of a policy."""
+    parts = [policy.conditions, policy.description] + [ev.code_snippet for ev in policy.evidence or []]
+    return "\n".join(p for p in parts if isinstance(p, str))
+
+
+def resolve_endpoints(policies: list[Policy], cors: list[CorsSetting], csrf: list[CsrfSetting]) -> list[dict]:
+    """Resolve CORS and CSRF settings onto the endpoints policies map to.
+
+    Service-wide CORS settings apply to every endpoint and scoped ones to the
+    paths they match. An endpoint is flagged when it requires authentication
+    and an applicable setting lets any origin send credentialed requests.
+
+    Args:
+        policies: Mined policies of one service
+        cors: CORS settings in its clone
+        csrf: CSRF settings in its clone
+
+    Returns:
+        Endpoint dictionaries with their CORS origins and CSRF status
+    """
+    by_id = {p.id: p for p in policies}
+    protection = csrf_protection(csrf)
+    exempt_scopes = [s for c in csrf if c.status == CsrfStatus.EXEMPT for s in c.scopes]
+    endpoints = []
+    for rule in EndpointMappingService.map_policies(policies):
+        applicable = [c for c in cors if c.scope is None or scope_matches(c.scope, rule.path)]
+        wildcard = any(c.wildcard_origin or c.reflects_origin for c in applicable)
+        flagged = rule.requires_authentication and any(c.credentialed_wildcard for c in applicable)
+
+        if rule.method in SAFE_METHODS:
+            csrf_status = "not_applicable"
+        elif any(scope_matches(s, rule.path) for s in exempt_scopes) or any(
+            EXEMPTION_TEXT.search(_policy_text(by_id[pid])) for pid in rule.policy_ids
+        ):
+            csrf_status = "exempt"
+        elif protection == "disabled":
+            csrf_status = "disabled"
+        elif protection != "none":
+            csrf_status = "protected"
+        else:
+            csrf_status = "unknown"
+
+        endpoints.append(
+            {
+                "endpoint": rule.key,
+                "method": rule.method,
+                "path": rule.path,
+                "policy_ids": rule.policy_ids,
+                "requires_authentication": rule.requires_authentication,
+                "cors_origins": list(dict.fromkeys(o for c in applicable for o in c.origins)),
+                "wildcard_origin": wildcard,
+                "allow_credentials": any(c.allow_credentials for c in applicable),
+                "csrf": csrf_status,
+                "credentialed_wildcard": flagged,
+            }
+        )
+    endpoints.sort(key=lambda e: (not e["credentialed_wildcard"], e["path"], e["method"]))
+    return endpoints
+
+
+def cors_csrf_settings(root: str) -> Iterator[list]:
+    """Sandbox pass yielding ["cors" or "csrf", setting] for the settings of a repository clone."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in SOURCE_EXTENSIONS and path.name not in ROUTE_FILE_NAMES:
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        text = path.read_text(encoding="utf-8", errors="ignore")
+        yield from (["cors", asdict(c)] for c in extract_cors(relative.as_posix(), text))
+        yield from (["csrf", asdict(c)] for c in extract_csrf(relative.as_posix(), text))
+
+
+class CorsCsrfService:
+    """Mines CORS and CSRF settings into each service's security posture."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> tuple[list[CorsSetting], list[CsrfSetting]]:
+        """Read CORS and CSRF settings from a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            (CORS settings, CSRF settings)
+        """
+        cors: list[CorsSetting] = []
+        csrf: list[CsrfSetting] = []
+        for kind, setting in run_pass(root, cors_csrf_settings):
+            if kind == "cors":
+                cors.append(CorsSetting(**setting))
+            else:
+                csrf.append(CsrfSetting(**setting))
+        return cors, csrf
+
+    def _posture(self, repository: Repository) -> dict:
+        """CORS and CSRF posture of one repository."""
+        root = self.clone_dir / str(repository.id)
+        cloned = root.is_dir()
+        cors, csrf = self.scan_clone(root) if cloned else ([], [])
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository.id)
+        endpoints = resolve_endpoints(policies, cors, csrf)
+        flagged = sum(e["credentialed_wildcard"] for e in endpoints)
+        logger.info(
+            "cors_csrf_posture_computed",
+            repository_id=repository.id,
+            cors_settings=len(cors),
+            csrf_settings=len(csrf),
+            flagged_endpoints=flagged,
+        )
+        return {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "cloned": cloned,
+            "cors": [{**asdict(c), "credentialed_wildcard": c.credentialed_wildcard} for c in cors],
+            "csrf": [asdict(c) for c in csrf],
+            "csrf_protection": csrf_protection(csrf),
+            "flagged_endpoints": flagged,
+            "endpoints": endpoints,
+        }
+
+    def services(self, repository_id: int | None = None, flagged_only: bool = False) -> list[dict]:
+        """CORS and CSRF posture per service (repository).
+
+        Args:
+            repository_id: Restrict to one repository
+            flagged_only: Keep only services with flagged endpoints
+
+        Returns:
+            Per-repository settings, CSRF protection summary, and endpoints
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        reports = [self._posture(repository) for repository in repositories]
+        if flagged_only:
+            reports = [r for r in reports if r["flagged_endpoints"]]
+        return reports
@@ -0,0 +1,243 @@
+"""Service for computing authorization coverage metrics per service.
+
+Coverage is measured over an endpoint inventory: every route registration
+found in the repository's cloned source, plus every endpoint the mined
+policies map to. An inventoried endpoint without a mined rule counts as
+unauthenticated, since the miner found no check guarding it. When the clone
+is not available the inventory falls back to mined endpoints only, which
+overstates coverage; the report says which inventory was used.
+"""
+
+import re
+from collections import Counter
+from collections.abc import Iterator
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.auth_mechanism_service import AuthMechanism, classify_endpoints
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import AUTHENTICATED_SUBJECTS, EndpointMappingService
+
+logger = structlog.get_logger(__name__)
+
+# Source files searched for route registrations
+ROUTE_FILE_EXTENSIONS = {".py", ".java", ".cs", ".js", ".ts", ".jsx", ".tsx", ".go", ".kt", ".rb", ".php", ".scala", ".routes"}
+
+# Route tables without an extension (Play's conf/routes)
+ROUTE_FILE_NAMES = {"routes"}
+
+SKIPPED_DIRECTORIES = {".git", "node_modules", "venv", ".venv", "__pycache__", "dist", "build", "vendor"}
+
+# Unprotected endpoints listed in a report before truncating
+MAX_LISTED_ENDPOINTS = 100
+
+
+class InventorySource:
+    """Where the endpoint inventory came from."""
+
+    SOURCE = "source"  # Routes parsed from the clone, plus mined endpoints
+    POLICIES = "policies"  # Mined endpoints only
+
+
+def route_key(method: str, path: str) -> str:
+    """Normalize a route so equivalent parameter syntaxes compare equal.
+
+    Args:
+        method: HTTP method
+        path: Route path (e.g., "/api/x/{id}", "/api/x/:id", "/api/x/<int:id>")
+
+    Returns:
+        Normalized "METHOD /path/{}" key
+    """
+    path = re.sub(r"\{[^}]*\}|:\w+|<[^>]*>", "{}", path).rstrip("/") or "/"
+    return f"{method.upper()} {path}"
+
+
+def _percent(part: int, total: int) -> float:
+    """Percentage rounded to one decimal."""
+    return round(part / total * 100, 1) if total else 0.0
+
+
+def registered_route_keys(root: str) -> Iterator[list[str]]:
+    """Sandbox pass yielding [route key, relative path] for each route registration of a clone, in path order."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    for file_path in sorted(clone.rglob("*")):
+        if file_path.suffix not in ROUTE_FILE_EXTENSIONS and file_path.name not in ROUTE_FILE_NAMES:
+            continue
+        if not file_path.is_file():
+            continue
+        relative = file_path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if file_path.stat().st_size > max_bytes:
+            continue
+        content = file_path.read_text(encoding="utf-8", errors="ignore")
+        for method, path in EndpointMappingService.find_routes(content):
+            yield [route_key(method, path), str(relative)]
+
+
+class CoverageMetricsService:
+    """Computes authorization coverage KPIs."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.all()
+
+    def repository_coverage(self, repository_id: int) -> dict:
+        """Compute coverage metrics for one repository.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Coverage metrics
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        return self._coverage(repositories[0])
+
+    def workspace_coverage(self) -> dict:
+        """Compute coverage metrics for every repository plus workspace totals.
+
+        Returns:
+            Per-repository metrics and aggregated totals
+        """
+        reports = [self._coverage(repository) for repository in self._repositories()]
+        totals = self.summarize(
+            total=sum(r["total_endpoints"] for r in reports),
+            authenticated=sum(r["authenticated_endpoints"] for r in reports),
+            authorized=sum(r["authorized_endpoints"] for r in reports),
+            conditional=sum(r["conditional_endpoints"] for r in reports),
+        )
+        totals["unknown_patterns"] = sum(r["unknown_patterns"] for r in reports)
+        totals["auth_mechanisms"] = dict(sum((Counter(r["auth_mechanisms"]) for r in reports), Counter()))
+        return {"repositories": reports, "totals": totals}
+
+    def _coverage(self, repository: Repository) -> dict:
+        """Compute coverage for a repository from its policies and clone."""
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+            repository_id=repository.id
+        )
+        repo_path = self.clone_dir / str(repository.id)
+        discovered = self.discover_routes(repo_path) if repo_path.is_dir() else None
+        report = self.compute(policies, discovered)
+        logger.info(
+            "coverage_metrics_computed",
+            repository_id=repository.id,
+            endpoints=report["total_endpoints"],
+            inventory_source=report["inventory_source"],
+        )
+        return {"repository_id": repository.id, "repository_name": repository.name, **report}
+
+    @staticmethod
+    def discover_routes(repo_path: Path) -> dict[str, str]:
+        """Find route registrations in a cloned repository, in the scan sandbox.
+
+        Args:
+            repo_path: Repository root
+
+        Returns:
+            Mapping of normalized route key to the file that registers it
+        """
+        routes: dict[str, str] = {}
+        for key, relative in run_pass(repo_path, registered_route_keys):
+            routes.setdefault(key, relative)
+        return routes
+
+    @staticmethod
+    def unknown_patterns(policies: list[Policy]) -> dict[str, int]:
+        """Count mined authorization the endpoint model could not interpret.
+
+        Args:
+            policies: Mined policies
+
+        Returns:
+            Counts of policies whose evidence has no recognizable route
+            registration and whose subject names no recognizable role
+        """
+        unmapped = 0
+        unrecognized = 0
+        for policy in policies:
+            if not any(EndpointMappingService.find_routes(ev.code_snippet or "") for ev in policy.evidence or []):
+                unmapped += 1
+            roles, _ = EndpointMappingService.parse_roles(policy.subject)
+            subject = (policy.subject or "").strip().lower()
+            if not roles and subject and subject not in AUTHENTICATED_SUBJECTS:
+                unrecognized += 1
+        return {"unmapped_routes": unmapped, "unrecognized_subjects": unrecognized}
+
+    @staticmethod
+    def summarize(total: int, authenticated: int, authorized: int, conditional: int) -> dict:
+        """Build percentage KPIs from endpoint counts.
+
+        Args:
+            total: Inventoried endpoints
+            authenticated: Endpoints requiring authentication
+            authorized: Endpoints checking roles or conditions beyond authentication
+            conditional: Endpoints with ABAC conditions
+
+        Returns:
+            Counts and percentages
+        """
+        return {
+            "total_endpoints": total,
+            "authenticated_endpoints": authenticated,
+            "authorized_endpoints": authorized,
+            "conditional_endpoints": conditional,
+            "authenticated_percent": _percent(authenticated, total),
+            "authorized_percent": _percent(authorized, total),
+            "conditional_percent": _percent(conditional, total),
+        }
+
+    @classmethod
+    def compute(cls, policies: list[Policy], discovered: dict[str, str] | None) -> dict:
+        """Compute coverage from mined policies and an optional route inventory.
+
+        Args:
+            policies: Mined policies for the service
+            discovered: Routes found in source, or None if the clone is unavailable
+
+        Returns:
+            Coverage metrics
+        """
+        rules = {route_key(r.method, r.path): r for r in EndpointMappingService.map_policies(policies)}
+        inventory = set(rules) | set(discovered or {})
+
+        authenticated = [k for k in inventory if k in rules and rules[k].requires_authentication]
+        authorized = [k for k in inventory if k in rules and (rules[k].roles or rules[k].conditions)]
+        conditional = [k for k in inventory if k in rules and rules[k].conditions]
+        unprotected = sorted(k for k in inventory if k not in rules or not rules[k].requires_authentication)
+        unknown = cls.unknown_patterns(policies)
+        # Inventoried endpoints without a mined rule have no authentication
+        mechanisms = {route_key(e["method"], e["path"]): e["mechanism"] for e in classify_endpoints(policies)}
+
+        return {
+            "inventory_source": InventorySource.SOURCE if discovered is not None else InventorySource.POLICIES,
+            **cls.summarize(len(inventory), len(authenticated), len(authorized), len(conditional)),
+            "unknown_patterns": sum(unknown.values()),
+            "unknown_pattern_breakdown": unknown,
+            "unprotected_endpoints": unprotected[:MAX_LISTED_ENDPOINTS],
+            "auth_mechanisms": dict(Counter(mechanisms.get(k, AuthMechanism.NONE.value) for k in inventory)),
+        }
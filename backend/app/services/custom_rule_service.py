@@ -0,0 +1,532 @@
+"""Manage custom detection rules and evaluate them against repository clones.
+
+Teams know their authorization helpers better than any analyzer does: a
+custom rule is a regular expression that recognizes one of those helpers,
+scoped by language and file globs, whose named groups ("role",
+"permission", "resource", "action", "subject") say what each call site
+grants. Rules are defined per workspace through the API or synced in bulk
+from a rules file kept in version control, and every change is versioned
+so a rule can be restored. A rule can be dry-run against a repository's
+clone before it is saved, and enabled rules are evaluated when a scan
+completes; the per-scan hit counts show which rules still match anything.
+"""
+
+import re
+from collections.abc import Iterator
+from fnmatch import fnmatch
+from pathlib import Path, PurePosixPath
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.custom_rule import CustomRule, CustomRuleHit, CustomRuleVersion
+from app.models.repository import Repository
+from app.services.endpoint_risk_service import SEVERITY_LEVELS
+from app.services.readiness_service import SUPPORTED_LANGUAGES, load_sources
+
+logger = structlog.get_logger(__name__)
+
+# Fields that make up a rule's definition; a change to any of them is a new version
+DEFINITION_FIELDS = ("name", "description", "pattern", "languages", "paths", "exclude_paths", "severity", "enabled")
+DEFINITION_DEFAULTS = {
+    "description": None,
+    "languages": [],
+    "paths": [],
+    "exclude_paths": [],
+    "severity": "medium",
+    "enabled": True,
+}
+SCOPE_FIELDS = ("languages", "paths", "exclude_paths")
+
+# Named groups reported with each match
+CAPTURE_GROUPS = ("role", "permission", "resource", "action", "subject")
+LANGUAGES = sorted(set(SUPPORTED_LANGUAGES.values()))
+
+MAX_PATTERN_LENGTH = 1000
+MAX_SNIPPET_LENGTH = 200
+# Matches kept per rule; hit counts always cover every match
+MAX_RECORDED_MATCHES = 20
+MAX_DRY_RUN_MATCHES = 100
+
+
+def compile_rule(definition: dict) -> re.Pattern:
+    """Validate a rule definition and compile its pattern.
+
+    Raises:
+        ValueError: If the pattern does not compile or matches empty text, or the scope or severity is invalid
+    """
+    label = f"Rule '{definition['name']}'"
+    pattern = definition.get("pattern")
+    if not isinstance(pattern, str) or not pattern.strip():
+        raise ValueError(f"{label}: pattern is required")
+    if len(pattern) > MAX_PATTERN_LENGTH:
+        raise ValueError(f"{label}: pattern is longer than {MAX_PATTERN_LENGTH} characters")
+    try:
+        compiled = re.compile(pattern)
+    except re.error as e:
+        raise ValueError(f"{label}: invalid pattern: {e}") from e
+    if compiled.search("") is not None:
+        raise ValueError(f"{label}: pattern matches empty text, so it would match every line")
+    unknown_groups = sorted(set(compiled.groupindex) - set(CAPTURE_GROUPS))
+    if unknown_groups:
+        raise ValueError(
+            f"{label}: unknown capture groups {', '.join(unknown_groups)}; use {', '.join(CAPTURE_GROUPS)}"
+        )
+    if definition.get("severity") not in SEVERITY_LEVELS:
+        raise ValueError(f"{label}: severity must be one of {', '.join(SEVERITY_LEVELS)}")
+    for key in SCOPE_FIELDS:
+        values = definition.get(key)
+        if not (isinstance(values, list) and all(isinstance(v, str) for v in values)):
+            raise ValueError(f"{label}: '{key}' must be a list of strings")
+    unknown_languages = [language for language in definition["languages"] if language not in LANGUAGES]
+    if unknown_languages:
+        raise ValueError(
+            f"{label}: unknown languages {', '.join(unknown_languages)}; use {', '.join(LANGUAGES)}"
+        )
+    if not isinstance(definition.get("enabled"), bool):
+        raise ValueError(f"{label}: 'enabled' must be true or false")
+    return compiled
+
+
+def normalize_definition(raw: dict) -> dict:
+    """Fill a rule definition's defaults and validate it.
+
+    Args:
+        raw: Definition as submitted; None values take the default
+
+    Returns:
+        Definition with exactly DEFINITION_FIELDS
+
+    Raises:
+        ValueError: If the name is missing, a field is unknown, or the definition is invalid
+    """
+    name = str(raw.get("name") or "").strip()
+    if not name:
+        raise ValueError("Rule name is required")
+    unknown = sorted(set(raw) - set(DEFINITION_FIELDS))
+    if unknown:
+        raise ValueError(f"Rule '{name}': unknown fields {', '.join(unknown)}")
+    definition = {**DEFINITION_DEFAULTS, **{k: v for k, v in raw.items() if v is not None}, "name": name}
+    compile_rule(definition)
+    return {field: definition[field] for field in DEFINITION_FIELDS}
+
+
+def definition_of(rule: CustomRule) -> dict:
+    """A stored rule's current definition."""
+    return {field: getattr(rule, field) for field in DEFINITION_FIELDS}
+
+
+def in_scope(definition: dict, file_path: str) -> bool:
+    """Whether a source file falls under a rule's languages and file globs."""
+    languages = definition.get("languages") or []
+    if languages and SUPPORTED_LANGUAGES.get(PurePosixPath(file_path).suffix) not in languages:
+        return False
+    if any(fnmatch(file_path, glob) for glob in definition.get("exclude_paths") or []):
+        return False
+    paths = definition.get("paths") or []
+    return not paths or any(fnmatch(file_path, glob) for glob in paths)
+
+
+def evaluate_rules(definitions: list[dict], sources: dict[str, str], max_matches: int = MAX_RECORDED_MATCHES) -> list[dict]:
+    """Match rules line by line against sources.
+
+    Args:
+        definitions: Validated rule definitions
+        sources: Relative path -> content
+        max_matches: Matches listed per rule
+
+    Returns:
+        Per rule, in order: name, hits, files matched, and the first matches
+    """
+    results = []
+    for definition in definitions:
+        compiled = compile_rule(definition)
+        hits, files, matches = 0, 0, []
+        for file_path, text in sources.items():
+            if not in_scope(definition, file_path):
+                continue
+            found = 0
+            for number, line in enumerate(text.splitlines(), 1):
+                for match in compiled.finditer(line):
+                    found += 1
+                    if len(matches) < max_matches:
+                        matches.append(
+                            {
+                                "file_path": file_path,
+                                "line": number,
+                                "snippet": line.strip()[:MAX_SNIPPET_LENGTH],
+                                "captures": {k: v for k, v in match.groupdict().items() if v is not None},
+                            }
+                        )
+            hits += found
+            files += bool(found)
+        results.append({"name": definition["name"], "hits": hits, "files": files, "matches": matches})
+    return results
+
+
+def evaluate_checkout(root: str, definitions: list[dict], max_matches: int = MAX_RECORDED_MATCHES) -> Iterator[dict]:
+    """Sandbox pass yielding how many files of a clone rules were matched against, and their results."""
+    sources, _ = load_sources(Path(root))
+    yield {"files_evaluated": len(sources), "rules": evaluate_rules(definitions, sources, max_matches)}
+
+
+class CustomRuleService:
+    """Manages a workspace's custom detection rules and their hits."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def list_rules(self) -> list[CustomRule]:
+        """The workspace's custom rules, ordered by name."""
+        return self._query(CustomRule).order_by(CustomRule.name.asc()).all()
+
+    def get_rule(self, rule_id: int) -> CustomRule:
+        """Get a custom rule.
+
+        Raises:
+            ValueError: If the rule does not exist
+        """
+        rule = self._query(CustomRule).filter(CustomRule.id == rule_id).first()
+        if not rule:
+            raise ValueError(f"Custom rule {rule_id} not found")
+        return rule
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if not repository:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def _add(self, definition: dict, change: str, changed_by: str | None) -> CustomRule:
+        """Stage a new rule at version 1."""
+        rule = CustomRule(tenant_id=self.tenant_id, version=1, created_by=changed_by, **definition)
+        self.db.add(rule)
+        self.db.flush()
+        self._record_version(rule, change, changed_by)
+        return rule
+
+    def _apply(self, rule: CustomRule, definition: dict, change: str, changed_by: str | None) -> None:
+        """Stage a changed definition as the rule's next version."""
+        for field, value in definition.items():
+            setattr(rule, field, value)
+        rule.version += 1
+        self._record_version(rule, change, changed_by)
+
+    def _record_version(self, rule: CustomRule, change: str, changed_by: str | None) -> None:
+        """Snapshot a rule's definition at its current version."""
+        self.db.add(
+            CustomRuleVersion(
+                tenant_id=self.tenant_id,
+                rule_id=rule.id,
+                version=rule.version,
+                definition=definition_of(rule),
+                change=change,
+                changed_by=changed_by,
+            )
+        )
+
+    def _name_taken(self, name: str, rule_id: int | None = None) -> bool:
+        """Whether another rule in the workspace already has a name."""
+        existing = self._query(CustomRule).filter(CustomRule.name == name).first()
+        return existing is not None and existing.id != rule_id
+
+    def create_rule(self, definition: dict, created_by: str | None = None) -> CustomRule:
+        """Define a custom rule for the workspace.
+
+        Args:
+            definition: Name, pattern, scope (languages, paths, exclude_paths), severity, and enabled
+            created_by: Creator's email
+
+        Returns:
+            Created rule at version 1
+
+        Raises:
+            ValueError: If the name is taken or the definition is invalid
+        """
+        definition = normalize_definition(definition)
+        if self._name_taken(definition["name"]):
+            raise ValueError(f"A custom rule named '{definition['name']}' already exists")
+        rule = self._add(definition, "created", created_by)
+        self.db.commit()
+        self.db.refresh(rule)
+        logger.info("custom_rule_created", rule_id=rule.id, tenant_id=self.tenant_id)
+        return rule
+
+    def update_rule(self, rule_id: int, changes: dict, changed_by: str | None = None) -> CustomRule:
+        """Change a rule's definition; None values are ignored.
+
+        A new version is recorded only when the definition actually changes.
+
+        Raises:
+            ValueError: If the rule does not exist, the new name is taken, or the result is invalid
+        """
+        rule = self.get_rule(rule_id)
+        changes = {key: value for key, value in changes.items() if value is not None}
+        return self._save(rule, normalize_definition({**definition_of(rule), **changes}), "updated", changed_by)
+
+    def _save(self, rule: CustomRule, updated: dict, change: str, changed_by: str | None) -> CustomRule:
+        """Commit a validated definition as the rule's next version, unless nothing changed."""
+        current = definition_of(rule)
+        if updated == current:
+            return rule
+        if updated["name"] != current["name"] and self._name_taken(updated["name"], rule.id):
+            raise ValueError(f"A custom rule named '{updated['name']}' already exists")
+        self._apply(rule, updated, change, changed_by)
+        self.db.commit()
+        self.db.refresh(rule)
+        logger.info("custom_rule_updated", rule_id=rule.id, version=rule.version, change=change)
+        return rule
+
+    def delete_rule(self, rule_id: int) -> None:
+        """Delete a rule with its versions and hits.
+
+        Raises:
+            ValueError: If the rule does not exist
+        """
+        self.db.delete(self.get_rule(rule_id))
+        self.db.commit()
+
+    def versions(self, rule_id: int) -> list[CustomRuleVersion]:
+        """A rule's versions, newest first.
+
+        Raises:
+            ValueError: If the rule does not exist
+        """
+        self.get_rule(rule_id)
+        return (
+            self._query(CustomRuleVersion)
+            .filter(CustomRuleVersion.rule_id == rule_id)
+            .order_by(CustomRuleVersion.version.desc())
+            .all()
+        )
+
+    def get_version(self, rule_id: int, version: int) -> CustomRuleVersion:
+        """Get one version of a rule.
+
+        Raises:
+            ValueError: If the rule or version does not exist
+        """
+        self.get_rule(rule_id)
+        snapshot = (
+            self._query(CustomRuleVersion)
+            .filter(CustomRuleVersion.rule_id == rule_id, CustomRuleVersion.version == version)
+            .first()
+        )
+        if snapshot is None:
+            raise ValueError(f"Custom rule {rule_id} has no version {version}")
+        return snapshot
+
+    def restore(self, rule_id: int, version: int, changed_by: str | None = None) -> CustomRule:
+        """Make an earlier version's definition current, as a new version.
+
+        Raises:
+            ValueError: If the rule or version does not exist, or the restored name is taken
+        """
+        snapshot = self.get_version(rule_id, version)
+        return self._save(self.get_rule(rule_id), normalize_definition(snapshot.definition), "restored", changed_by)
+
+    def _normalize_all(self, definitions: list[dict]) -> list[dict]:
+        """Validate a batch of definitions, reporting every problem at once.
+
+        Raises:
+            ValueError: If any definition is invalid or two share a name
+        """
+        normalized, errors, seen = [], [], set()
+        for raw in definitions:
+            try:
+                definition = normalize_definition(raw)
+            except ValueError as e:
+                errors.append(str(e))
+                continue
+            if definition["name"] in seen:
+                errors.append(f"Rule '{definition['name']}' is defined more than once")
+            seen.add(definition["name"])
+            normalized.append(definition)
+        if errors:
+            raise ValueError("; ".join(errors))
+        return normalized
+
+    def sync(
+        self,
+        definitions: list[dict],
+        prune: bool = False,
+        dry_run: bool = False,
+        changed_by: str | None = None,
+    ) -> dict:
+        """Make the workspace's rules match a rules file.
+
+        Rules are matched by name: new names are created, changed definitions
+        become a new version, and with prune, rules missing from the file are
+        deleted. Nothing is applied unless every definition is valid.
+
+        Args:
+            definitions: The rules file's definitions
+            prune: Delete workspace rules the file does not define
+            dry_run: Report the changes without applying them
+            changed_by: Email of whoever ran the sync
+
+        Returns:
+            Rule names created, updated, unchanged, and deleted
+
+        Raises:
+            ValueError: If any definition is invalid
+        """
+        normalized = self._normalize_all(definitions)
+        existing = {rule.name: rule for rule in self.list_rules()}
+        result = {"created": [], "updated": [], "unchanged": [], "deleted": [], "dry_run": dry_run}
+        for definition in normalized:
+            rule = existing.get(definition["name"])
+            if rule is None:
+                result["created"].append(definition["name"])
+                if not dry_run:
+                    self._add(definition, "synced", changed_by)
+            elif definition_of(rule) == definition:
+                result["unchanged"].append(definition["name"])
+            else:
+                result["updated"].append(definition["name"])
+                if not dry_run:
+                    self._apply(rule, definition, "synced", changed_by)
+        if prune:
+            result["deleted"] = sorted(set(existing) - {d["name"] for d in normalized})
+            if not dry_run:
+                for name in result["deleted"]:
+                    self.db.delete(existing[name])
+        if not dry_run:
+            self.db.commit()
+        logger.info(
+            "custom_rules_synced",
+            tenant_id=self.tenant_id,
+            dry_run=dry_run,
+            **{key: len(result[key]) for key in ("created", "updated", "unchanged", "deleted")},
+        )
+        return result
+
+    def dry_run(
+        self,
+        repository_id: int,
+        definitions: list[dict] | None = None,
+        rule_ids: list[int] | None = None,
+    ) -> dict:
+        """Evaluate rules against a repository's clone without recording hits.
+
+        Args:
+            repository_id: Repository whose clone is matched
+            definitions: Unsaved definitions to try; takes precedence over rule_ids
+            rule_ids: Saved rules to try; every enabled rule when neither is given
+
+        Returns:
+            Files evaluated and, per rule, its hits and first matches
+
+        Raises:
+            ValueError: If the repository or a rule does not exist, the repository is not cloned,
+                or a definition is invalid
+        """
+        repository = self.get_repository(repository_id)
+        if definitions:
+            rules = self._normalize_all(definitions)
+        elif rule_ids:
+            rules = [definition_of(self.get_rule(rule_id)) for rule_id in rule_ids]
+        else:
+            rules = [definition_of(rule) for rule in self.list_rules() if rule.enabled]
+        root = self.clone_dir / str(repository_id)
+        if not root.exists():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+        (evaluated,) = run_pass(root, evaluate_checkout, definitions=rules, max_matches=MAX_DRY_RUN_MATCHES)
+        return {"repository_id": repository.id, "repository_name": repository.name, **evaluated}
+
+    def record_hits(self, repository_id: int, scan_id: int | None = None) -> list[CustomRuleHit]:
+        """Evaluate the enabled rules against a freshly scanned clone and store their hits.
+
+        Args:
+            repository_id: Repository that was just scanned
+            scan_id: The scan
+
+        Returns:
+            Stored hits, one per enabled rule
+        """
+        rules = [rule for rule in self.list_rules() if rule.enabled]
+        root = self.clone_dir / str(repository_id)
+        if not rules or not root.exists():
+            return []
+        (evaluated,) = run_pass(root, evaluate_checkout, definitions=[definition_of(r) for r in rules])
+        hits = [
+            CustomRuleHit(
+                tenant_id=self.tenant_id,
+                rule_id=rule.id,
+                rule_version=rule.version,
+                repository_id=repository_id,
+                scan_id=scan_id,
+                hits=result["hits"],
+                files=result["files"],
+                matches=result["matches"],
+            )
+            for rule, result in zip(rules, evaluated["rules"], strict=True)
+        ]
+        self.db.add_all(hits)
+        self.db.commit()
+        logger.info(
+            "custom_rule_hits_recorded",
+            repository_id=repository_id,
+            scan_id=scan_id,
+            rules=len(hits),
+            hits=sum(h.hits for h in hits),
+        )
+        return hits
+
+    def hits(self, rule_id: int, limit: int = 20) -> list[CustomRuleHit]:
+        """A rule's recorded hits, newest first.
+
+        Raises:
+            ValueError: If the rule does not exist
+        """
+        self.get_rule(rule_id)
+        return (
+            self._query(CustomRuleHit)
+            .filter(CustomRuleHit.rule_id == rule_id)
+            .order_by(CustomRuleHit.id.desc())
+            .limit(limit)
+            .all()
+        )
+
+    def stats(self) -> list[dict]:
+        """Hit statistics of every rule across the scans it was evaluated in, ordered by name."""
+        by_rule: dict[int, list[CustomRuleHit]] = {}
+        for hit in self._query(CustomRuleHit).all():
+            by_rule.setdefault(hit.rule_id, []).append(hit)
+        stats = []
+        for rule in self.list_rules():
+            recorded = by_rule.get(rule.id, [])
+            matched = [h for h in recorded if h.hits]
+            current = [h for h in recorded if h.rule_version == rule.version]
+            stats.append(
+                {
+                    "rule_id": rule.id,
+                    "name": rule.name,
+                    "version": rule.version,
+                    "enabled": rule.enabled,
+                    "scans_evaluated": len(recorded),
+                    "scans_with_hits": len(matched),
+                    "total_hits": sum(h.hits for h in recorded),
+                    "current_version_hits": sum(h.hits for h in current),
+                    "repositories_hit": len({h.repository_id for h in matched}),
+                    "last_hit_at": max((h.created_at for h in matched), default=None),
+                }
+            )
+        return stats
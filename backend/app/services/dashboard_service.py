@@ -0,0 +1,344 @@
+"""Service for configurable dashboard widgets.
+
+A widget pairs a metric with a chart type and a filter scope (repository,
+application, time window). Widgets are stored per user, or workspace-wide
+when they have no owner, and the data endpoint returns each widget's metric
+already aggregated so the dashboard does not have to page through raw
+policies or findings.
+"""
+
+from collections import Counter
+from collections.abc import Callable
+from dataclasses import asdict, dataclass
+
+import structlog
+from sqlalchemy import or_
+from sqlalchemy.orm import Session
+
+from app.models.dashboard_widget import DashboardWidget
+from app.models.policy import Policy
+from app.services.coverage_metrics_service import CoverageMetricsService
+from app.services.trend_metrics_service import TrendInterval, TrendMetricsService
+
+logger = structlog.get_logger(__name__)
+
+
+class ChartType:
+    """Supported widget chart types."""
+
+    NUMBER = "number"
+    BAR = "bar"
+    PIE = "pie"
+    LINE = "line"
+    TABLE = "table"
+
+
+class DashboardMetric:
+    """Metrics a widget can display."""
+
+    POLICIES_BY_STATUS = "policies_by_status"
+    POLICIES_BY_RISK = "policies_by_risk"
+    COVERAGE = "coverage"
+    COVERAGE_TREND = "coverage_trend"
+    OPEN_FINDINGS = "open_findings"
+    FINDINGS_TREND = "findings_trend"
+
+
+@dataclass
+class MetricDefinition:
+    """A metric's allowed chart types and filter scope."""
+
+    metric: str
+    description: str
+    chart_types: list[str]
+    filters: list[str]
+
+
+METRIC_DEFINITIONS = {
+    d.metric: d
+    for d in [
+        MetricDefinition(
+            DashboardMetric.POLICIES_BY_STATUS,
+            "Policy count per review status",
+            [ChartType.NUMBER, ChartType.BAR, ChartType.PIE, ChartType.TABLE],
+            ["repository_id", "application_id"],
+        ),
+        MetricDefinition(
+            DashboardMetric.POLICIES_BY_RISK,
+            "Policy count per risk level",
+            [ChartType.NUMBER, ChartType.BAR, ChartType.PIE, ChartType.TABLE],
+            ["repository_id", "application_id"],
+        ),
+        MetricDefinition(
+            DashboardMetric.COVERAGE,
+            "Authenticated, authorized, and conditional endpoint coverage",
+            [ChartType.NUMBER, ChartType.BAR, ChartType.TABLE],
+            ["repository_id"],
+        ),
+        MetricDefinition(
+            DashboardMetric.COVERAGE_TREND,
+            "Coverage over time from per-scan snapshots",
+            [ChartType.LINE, ChartType.TABLE],
+            ["repository_id", "interval"],
+        ),
+        MetricDefinition(
+            DashboardMetric.OPEN_FINDINGS,
+            "Open findings per type",
+            [ChartType.NUMBER, ChartType.BAR, ChartType.PIE, ChartType.TABLE],
+            ["repository_id"],
+        ),
+        MetricDefinition(
+            DashboardMetric.FINDINGS_TREND,
+            "Findings opened and closed per week",
+            [ChartType.LINE, ChartType.BAR, ChartType.TABLE],
+            ["weeks"],
+        ),
+    ]
+}
+
+MAX_TREND_WEEKS = 104
+
+
+def validate_widget(metric: str, chart_type: str, filters: dict) -> None:
+    """Check a widget configuration against the metric catalog.
+
+    Args:
+        metric: Metric name
+        chart_type: Chart type
+        filters: Filter scope
+
+    Raises:
+        ValueError: If the metric, chart type, or filters are not supported
+    """
+    definition = METRIC_DEFINITIONS.get(metric)
+    if definition is None:
+        raise ValueError(f"Unknown metric '{metric}'")
+    if chart_type not in definition.chart_types:
+        raise ValueError(f"Metric '{metric}' cannot be shown as '{chart_type}'")
+    unsupported = sorted(set(filters) - set(definition.filters))
+    if unsupported:
+        raise ValueError(f"Metric '{metric}' does not support filters: {', '.join(unsupported)}")
+    for key in ("repository_id", "application_id", "weeks"):
+        if key in filters and not isinstance(filters[key], int):
+            raise ValueError(f"Filter '{key}' must be an integer")
+    if "weeks" in filters and not 1 <= filters["weeks"] <= MAX_TREND_WEEKS:
+        raise ValueError(f"Filter 'weeks' must be between 1 and {MAX_TREND_WEEKS}")
+    if "interval" in filters and filters["interval"] not in (TrendInterval.DAY, TrendInterval.WEEK):
+        raise ValueError("Filter 'interval' must be day or week")
+
+
+def _counts(values: list[str]) -> list[dict]:
+    """Label/value pairs sorted by label."""
+    return [{"label": label, "value": count} for label, count in sorted(Counter(values).items())]
+
+
+class DashboardService:
+    """Stores dashboard widgets and computes their data."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    @staticmethod
+    def metric_catalog() -> list[dict]:
+        """Metrics with their allowed chart types and filters."""
+        return [asdict(d) for d in METRIC_DEFINITIONS.values()]
+
+    def _query(self):
+        """Query widgets scoped to the current tenant."""
+        query = self.db.query(DashboardWidget)
+        if self.tenant_id:
+            query = query.filter(DashboardWidget.tenant_id == self.tenant_id)
+        return query
+
+    def list_widgets(self, owner_email: str | None = None) -> list[DashboardWidget]:
+        """List the widgets on a user's dashboard.
+
+        Args:
+            owner_email: Current user; their widgets follow the workspace-wide ones
+
+        Returns:
+            Workspace-wide widgets plus the user's own, ordered by position
+        """
+        query = self._query()
+        if owner_email:
+            query = query.filter(
+                or_(DashboardWidget.owner_email.is_(None), DashboardWidget.owner_email == owner_email)
+            )
+        else:
+            query = query.filter(DashboardWidget.owner_email.is_(None))
+        return query.order_by(DashboardWidget.position.asc(), DashboardWidget.id.asc()).all()
+
+    def get_widget(self, widget_id: int, owner_email: str | None = None) -> DashboardWidget:
+        """Get a widget visible to the user.
+
+        Args:
+            widget_id: Widget ID
+            owner_email: Current user
+
+        Returns:
+            Widget
+
+        Raises:
+            ValueError: If the widget does not exist or belongs to another user
+        """
+        widget = self._query().filter(DashboardWidget.id == widget_id).first()
+        if not widget or (widget.owner_email is not None and widget.owner_email != owner_email):
+            raise ValueError(f"Widget {widget_id} not found")
+        return widget
+
+    def create_widget(
+        self,
+        title: str,
+        metric: str,
+        chart_type: str,
+        filters: dict | None = None,
+        position: int = 0,
+        owner_email: str | None = None,
+    ) -> DashboardWidget:
+        """Add a widget.
+
+        Args:
+            title: Widget title
+            metric: Metric name
+            chart_type: Chart type
+            filters: Filter scope
+            position: Order on the dashboard
+            owner_email: Owning user (workspace-wide if omitted)
+
+        Returns:
+            Created widget
+
+        Raises:
+            ValueError: If the configuration is not supported
+        """
+        filters = filters or {}
+        validate_widget(metric, chart_type, filters)
+        widget = DashboardWidget(
+            tenant_id=self.tenant_id,
+            owner_email=owner_email,
+            title=title,
+            metric=metric,
+            chart_type=chart_type,
+            filters=filters,
+            position=position,
+        )
+        self.db.add(widget)
+        self.db.commit()
+        self.db.refresh(widget)
+
+        logger.info("dashboard_widget_created", widget_id=widget.id, metric=metric, tenant_id=self.tenant_id)
+        return widget
+
+    def update_widget(self, widget: DashboardWidget, **changes) -> DashboardWidget:
+        """Update a widget's configuration.
+
+        Args:
+            widget: Widget to update
+            **changes: New title, metric, chart_type, filters, or position (None leaves a field unchanged)
+
+        Returns:
+            Updated widget
+
+        Raises:
+            ValueError: If the resulting configuration is not supported
+        """
+        changes = {k: v for k, v in changes.items() if v is not None}
+        validate_widget(
+            changes.get("metric", widget.metric),
+            changes.get("chart_type", widget.chart_type),
+            changes.get("filters", widget.filters or {}),
+        )
+        for key, value in changes.items():
+            setattr(widget, key, value)
+        self.db.commit()
+        self.db.refresh(widget)
+        return widget
+
+    def delete_widget(self, widget: DashboardWidget) -> None:
+        """Delete a widget."""
+        self.db.delete(widget)
+        self.db.commit()
+
+    @property
+    def metric_providers(self) -> dict[str, Callable[[dict], object]]:
+        """Data provider per metric, called with the widget's filters."""
+        return {
+            DashboardMetric.POLICIES_BY_STATUS: lambda f: _counts(
+                [p.status.value if p.status else "unknown" for p in self._policies(f)]
+            ),
+            DashboardMetric.POLICIES_BY_RISK: lambda f: _counts(
+                [p.risk_level.value if p.risk_level else "unscored" for p in self._policies(f)]
+            ),
+            DashboardMetric.COVERAGE: self._coverage,
+            DashboardMetric.COVERAGE_TREND: lambda f: TrendMetricsService(self.db, self.tenant_id).coverage_series(
+                f.get("repository_id"), f.get("interval", TrendInterval.WEEK)
+            ),
+            DashboardMetric.OPEN_FINDINGS: lambda f: [
+                {"label": label, "value": value}
+                for label, value in TrendMetricsService(self.db, self.tenant_id)
+                .open_findings(f.get("repository_id"))
+                .items()
+            ],
+            DashboardMetric.FINDINGS_TREND: lambda f: TrendMetricsService(self.db, self.tenant_id).findings_per_week(
+                f.get("weeks", 12)
+            ),
+        }
+
+    def _policies(self, filters: dict) -> list[Policy]:
+        """Policies in a widget's scope."""
+        query = self.db.query(Policy)
+        if self.tenant_id:
+            query = query.filter(Policy.tenant_id == self.tenant_id)
+        if "repository_id" in filters:
+            query = query.filter(Policy.repository_id == filters["repository_id"])
+        if "application_id" in filters:
+            query = query.filter(Policy.application_id == filters["application_id"])
+        return query.all()
+
+    def _coverage(self, filters: dict) -> dict:
+        """Coverage totals for a repository or the workspace."""
+        service = CoverageMetricsService(self.db, self.tenant_id)
+        if "repository_id" in filters:
+            return service.repository_coverage(filters["repository_id"])
+        return service.workspace_coverage()["totals"]
+
+    def widget_data(self, widget: DashboardWidget) -> dict:
+        """Compute the pre-aggregated data for one widget.
+
+        Args:
+            widget: Widget
+
+        Returns:
+            Widget identity, chart type, and data (or an error message)
+        """
+        result = {
+            "widget_id": widget.id,
+            "title": widget.title,
+            "metric": widget.metric,
+            "chart_type": widget.chart_type,
+            "data": None,
+            "error": None,
+        }
+        provider = self.metric_providers.get(widget.metric)
+        if provider is None:
+            result["error"] = f"Unknown metric '{widget.metric}'"
+            return result
+        try:
+            result["data"] = provider(widget.filters or {})
+        except ValueError as e:
+            # e.g., the scoped repository was deleted; the other widgets still render
+            result["error"] = str(e)
+        return result
+
+    def dashboard_data(self, owner_email: str | None = None) -> list[dict]:
+        """Compute data for every widget on a user's dashboard.
+
+        Args:
+            owner_email: Current user
+
+        Returns:
+            Widget data in dashboard order
+        """
+        return [self.widget_data(w) for w in self.list_widgets(owner_email)]
@@ -6,8 +6,10 @@ import structlog
 from sqlalchemy import create_engine, text
 from sqlalchemy.exc import SQLAlchemyError
 
+from app.core.air_gap import ensure_host_allowed
 from app.models.policy import Evidence, Policy, PolicyStatus, SourceType
 from app.models.repository import DatabaseType, Repository
+from app.services.extraction_confidence import classify_snippet, method_confidence
 from app.services.llm_provider import get_llm_provider
 from app.services.risk_scoring_service import RiskScoringService
 
@@ -57,6 +59,7 @@ class DatabaseScannerService:
 
         Raises:
             ValueError: If required connection config is missing
+            AirGapViolation: If the host is outside the enclave in air-gapped mode
         """
         config = repository.connection_config or {}
         db_type = config.get("database_type")
@@ -68,6 +71,7 @@ class DatabaseScannerService:
 
         if not all([db_type, host, database, username, password]):
             raise ValueError("Missing required database connection parameters")
+        ensure_host_allowed(host, "database_scanner")
 
         # Map database types to SQLAlchemy drivers
         driver_map = {
@@ -310,6 +314,9 @@ IMPORTANT: Only extract policies where authorization/access control logic exists
                     evidence_count=1,
                 )
 
+                # Procedures check the caller in their body (IF IS_MEMBER(...)) rather than declare a guard
+                extraction_method = classify_snippet(definition)
+
                 # Create policy
                 policy = Policy(
                     subject=policy_data.get("subject", "Unknown"),
@@ -323,7 +330,8 @@ IMPORTANT: Only extract policies where authorization/access control logic exists
                     overall_risk_score=risk_scores["overall_risk_score"],
                     complexity_score=risk_scores["complexity_score"],
                     impact_score=risk_scores["impact_score"],
-                    confidence_score=risk_scores["confidence_score"],
+                    confidence_score=method_confidence(extraction_method, risk_scores["confidence_score"]),
+                    extraction_method=extraction_method,
                     historical_score=risk_scores["historical_score"],
                     repository_id=repository_id,
                     tenant_id=tenant_id,
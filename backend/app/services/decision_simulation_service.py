@@ -0,0 +1,257 @@
+"""Service for simulating authorization decisions against mined policies.
+
+Lets teams sanity-check the mined policy model before exporting it to a
+policy engine: given a subject, action, resource, and context, evaluate every
+applicable mined policy and explain which ones fired.
+"""
+
+import re
+from dataclasses import asdict, dataclass, field
+from typing import Any
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus
+from app.services.condition_evaluation_service import ConditionEvaluationService
+from app.services.endpoint_mapping_service import HTTP_METHODS, EndpointMappingService
+
+logger = structlog.get_logger(__name__)
+
+
+class RuleOutcome:
+    """Why an applicable policy did or did not grant access."""
+
+    FIRED = "fired"
+    ROLE_MISMATCH = "role_mismatch"
+    CONDITION_FAILED = "condition_failed"
+    INDETERMINATE = "indeterminate"
+
+
+class Decision:
+    """Simulation decisions."""
+
+    ALLOW = "allow"
+    DENY = "deny"
+
+
+@dataclass
+class SimulationRequest:
+    """Inputs to a simulated authorization decision."""
+
+    action: str
+    resource: str
+    subject_id: str | None = None
+    roles: list[str] = field(default_factory=list)
+    subject_attributes: dict[str, Any] = field(default_factory=dict)
+    resource_attributes: dict[str, Any] = field(default_factory=dict)
+    context: dict[str, Any] = field(default_factory=dict)
+    authenticated: bool = True
+
+
+@dataclass
+class RuleEvaluation:
+    """Evaluation of one applicable mined policy."""
+
+    policy_id: int
+    subject: str
+    resource: str
+    action: str
+    conditions: str | None
+    outcome: str
+    reason: str
+    condition_clauses: list[dict] = field(default_factory=list)
+
+
+def _tokens(text: str) -> set[str]:
+    """Lower-case word tokens with trailing plural 's' removed."""
+    return {
+        token[:-1] if len(token) > 3 and token.endswith("s") else token
+        for token in re.findall(r"[a-z0-9]+", text.lower())
+        if token not in ("api", "v1", "v2", "the", "a", "an", "of")
+    }
+
+
+def _path_matches(template: str, path: str) -> bool:
+    """Check whether a concrete path matches a route template."""
+    parts = re.split(r"(\{[^}]+\}|:\w+|<[^>]+>)", template.rstrip("/"))
+    pattern = "".join("[^/]+" if i % 2 else re.escape(part) for i, part in enumerate(parts))
+    return re.fullmatch(pattern, path.rstrip("/")) is not None
+
+
+class DecisionSimulationService:
+    """Evaluates simulated requests against the mined policy model."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def load_policies(
+        self,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+        include_pending: bool = True,
+    ) -> list[Policy]:
+        """Load the policies that make up the simulated model.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+            include_pending: Include policies that have not been approved yet
+
+        Returns:
+            Policies in scope
+        """
+        query = self.db.query(Policy)
+        if self.tenant_id:
+            query = query.filter(Policy.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Policy.repository_id == repository_id)
+        if application_id is not None:
+            query = query.filter(Policy.application_id == application_id)
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        return query.all()
+
+    def simulate(
+        self,
+        request: SimulationRequest,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+        include_pending: bool = True,
+    ) -> dict:
+        """Simulate an authorization decision.
+
+        Access is denied by default and allowed when at least one applicable
+        policy fires: the subject holds one of its roles and all of its
+        conditions hold. Conditions that cannot be evaluated from the given
+        attributes never grant access; they are reported as indeterminate so
+        the caller knows which attributes to supply.
+
+        Args:
+            request: Simulated request
+            repository_id: Restrict the model to one repository
+            application_id: Restrict the model to one application
+            include_pending: Include policies that have not been approved yet
+
+        Returns:
+            Decision with per-policy evaluations
+        """
+        policies = self.load_policies(repository_id, application_id, include_pending)
+        applicable = [p for p in policies if self.policy_applies(p, request.action, request.resource)]
+        evaluations = [self.evaluate_policy(p, request) for p in applicable]
+
+        fired = [e for e in evaluations if e.outcome == RuleOutcome.FIRED]
+        indeterminate = [e for e in evaluations if e.outcome == RuleOutcome.INDETERMINATE]
+        decision = Decision.ALLOW if fired else Decision.DENY
+
+        if fired:
+            reason = f"Allowed by {len(fired)} mined polic{'y' if len(fired) == 1 else 'ies'}"
+        elif not applicable:
+            reason = "No mined policy covers this action and resource (default deny)"
+        elif indeterminate:
+            reason = "No policy fired; some conditions could not be evaluated from the given attributes"
+        else:
+            reason = "No applicable policy grants access to this subject"
+
+        logger.info(
+            "decision_simulated",
+            action=request.action,
+            resource=request.resource,
+            decision=decision,
+            applicable=len(applicable),
+            fired=len(fired),
+        )
+
+        return {
+            "decision": decision,
+            "reason": reason,
+            "policies_evaluated": len(policies),
+            "fired_policy_ids": [e.policy_id for e in fired],
+            "evaluations": [asdict(e) for e in evaluations],
+        }
+
+    @staticmethod
+    def policy_applies(policy: Policy, action: str, resource: str) -> bool:
+        """Check whether a policy covers the requested action and resource.
+
+        Args:
+            policy: Mined policy
+            action: Requested action or HTTP method
+            resource: Requested resource name or path
+
+        Returns:
+            True if the policy is applicable
+        """
+        return DecisionSimulationService._action_matches(policy, action) and (
+            DecisionSimulationService._resource_matches(policy, resource)
+        )
+
+    @staticmethod
+    def _action_matches(policy: Policy, action: str) -> bool:
+        """Match an action by name, shared words, or implied HTTP method."""
+        policy_action = (policy.action or "").strip()
+        if policy_action.lower() == action.strip().lower():
+            return True
+        if action.upper() in HTTP_METHODS:
+            return EndpointMappingService.map_policy(policy).method == action.upper()
+        return bool(_tokens(policy_action) & _tokens(action))
+
+    @staticmethod
+    def _resource_matches(policy: Policy, resource: str) -> bool:
+        """Match a resource by name or shared words, or a request path by route template."""
+        policy_resource = (policy.resource or "").strip()
+        if policy_resource.lower() == resource.strip().lower():
+            return True
+        if resource.startswith("/"):
+            return _path_matches(EndpointMappingService.map_policy(policy).path, resource)
+        policy_tokens = _tokens(policy_resource)
+        return bool(policy_tokens) and policy_tokens <= _tokens(resource)
+
+    @staticmethod
+    def evaluate_policy(policy: Policy, request: SimulationRequest) -> RuleEvaluation:
+        """Evaluate one applicable policy against the request.
+
+        Args:
+            policy: Applicable policy
+            request: Simulated request
+
+        Returns:
+            RuleEvaluation describing the outcome
+        """
+        roles, requires_auth = EndpointMappingService.parse_roles(policy.subject)
+        subject_roles = {r.upper() for r in request.roles}
+
+        def result(outcome: str, reason: str, clauses: list[dict] | None = None) -> RuleEvaluation:
+            return RuleEvaluation(
+                policy_id=policy.id,
+                subject=policy.subject,
+                resource=policy.resource,
+                action=policy.action,
+                conditions=policy.conditions,
+                outcome=outcome,
+                reason=reason,
+                condition_clauses=clauses or [],
+            )
+
+        if requires_auth and not request.authenticated:
+            return result(RuleOutcome.ROLE_MISMATCH, "Policy requires an authenticated subject")
+        if roles and not subject_roles & set(roles):
+            return result(RuleOutcome.ROLE_MISMATCH, f"Subject has none of the roles: {', '.join(roles)}")
+
+        attributes = {**request.context, **request.resource_attributes, **request.subject_attributes}
+        condition = ConditionEvaluationService.evaluate(
+            policy.conditions, attributes, list(subject_roles), request.subject_id
+        )
+        if condition.satisfied is False:
+            return result(RuleOutcome.CONDITION_FAILED, "A policy condition does not hold", condition.clauses)
+        if condition.satisfied is None:
+            return result(
+                RuleOutcome.INDETERMINATE,
+                "A policy condition could not be evaluated from the given attributes",
+                condition.clauses,
+            )
+        return result(RuleOutcome.FIRED, "Subject role and all conditions match", condition.clauses)
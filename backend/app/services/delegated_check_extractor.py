@@ -0,0 +1,159 @@
+"""Find the role checks a handler delegates to the helper functions it calls.
+
+Handlers often leave the check itself to a helper:
+
+    func ApproveExpense(w http.ResponseWriter, r *http.Request) {
+        if err := ensureManager(user); err != nil {
+            http.Error(w, err.Error(), http.StatusForbidden)
+            return
+        }
+
+    func ensureManager(u *User) error {
+        if !u.HasRole("MANAGER") {
+            return ErrForbidden
+        }
+        return nil
+    }
+
+or ask a predicate, as in if !canApprove(user) { ... 403 }. This module reads
+the pieces the inter-procedural pass walks: the calls a function makes, the
+role requirements its guards enforce, and the roles a predicate helper
+returns. The pass itself (delegated_check_service) follows the calls to the
+configured depth and attributes what it finds back to the route.
+"""
+
+import re
+from collections.abc import Callable
+from dataclasses import dataclass
+
+from app.services.extractor_utils import MAX_LINE_LENGTH
+from app.services.guard_condition_extractor import (
+    AND_SEPARATOR,
+    ASSIGNMENT,
+    DENIAL,
+    OR_SEPARATOR,
+    _atom,
+    _guard,
+    _short,
+    _split,
+    _unwrap,
+)
+
+# Calls of named functions and methods, e.g. ensureManager(user), h.authz.EnsureManager(u), self.ensure_manager()
+CALL = re.compile(r"(?<![\w$])(?:[A-Za-z_$][\w$]*\s*\.\s*){0,3}([A-Za-z_$][\w$]*)\s*\(")
+NOT_CALLS = {
+    "if", "elif", "for", "foreach", "while", "switch", "catch", "return", "func", "function", "def", "fun", "new",
+    "typeof", "sizeof", "super", "print", "println", "len", "make", "append", "panic", "require", "import", "await",
+}  # fmt: skip
+# Guard bodies that deny from inside a helper: raising, or returning an error or false to the caller
+HELPER_DENIAL = re.compile(
+    rf"{DENIAL.pattern}|\b(?:raise|throw|abort)\b|\breturn\s+(?:false\b|False\b|[\w.]*Err\w*|err\b|errors?\s*\.|fmt\s*\.\s*Errorf|new\s+\w*(?:Error|Exception))"
+)
+# A predicate called in a guard, negated, e.g. !canApprove(user) or not is_manager(request.user)
+NEGATED_CALL = re.compile(r"^(?:!\s*|not\s+)(?:[A-Za-z_$][\w$]*\s*\.\s*){0,3}([A-Za-z_$][\w$]*)\s*\([^()]*\)$")
+RETURN = re.compile(r"^\s*return\s+(.+)$")
+
+
+@dataclass
+class RoleCheck:
+    """A denial guard requiring one of some roles, possibly through a predicate helper."""
+
+    line: int
+    guard: str  # the guard's source line
+    roles: list[str]  # any one of these passes the guard
+    predicate: str | None = None  # helper whose returned roles the guard requires
+
+
+def calls(lines: list[str], start: int, end: int) -> list[tuple[int, str]]:
+    """(line index, name) of the named calls in a function body, first occurrence of each name."""
+    seen: set[str] = set()
+    found = []
+    for index in range(start + 1, min(end, len(lines))):
+        line = lines[index]
+        if len(line) > MAX_LINE_LENGTH:
+            continue
+        for match in CALL.finditer(line):
+            name = match.group(1)
+            if name not in seen and name not in NOT_CALLS:
+                seen.add(name)
+                found.append((index, name))
+    return found
+
+
+def predicate_roles(lines: list[str], start: int, end: int) -> RoleCheck | None:
+    """Roles a predicate helper returns true for, e.g. return u.HasRole("MANAGER") || u.IsAdmin.
+
+    Returns:
+        The helper's return statement and the roles any of which makes it
+        hold, or None if the return value is not purely a role check
+    """
+    for index in range(start + 1, min(end, len(lines))):
+        returned = RETURN.match(lines[index]) if len(lines[index]) <= MAX_LINE_LENGTH else None
+        if not returned:
+            continue
+        roles = []
+        for disjunct in _split(_unwrap(returned.group(1).strip().rstrip(";")), OR_SEPARATOR):
+            atom = _atom(disjunct, {})
+            if atom is None or atom.kind != "role" or atom.negated:
+                return None
+            roles.append(atom.role)
+        return RoleCheck(index + 1, _short(lines[index]), list(dict.fromkeys(roles))) if roles else None
+    return None
+
+
+def role_checks(
+    lines: list[str],
+    start: int,
+    end: int,
+    predicate: Callable[[str], list[str] | None] | None = None,
+    helper: bool = False,
+) -> list[RoleCheck]:
+    """Role requirements the denial guards of one function enforce.
+
+    A guard denying when none of its negated role checks holds, e.g.
+    if !u.HasRole("MANAGER") && !u.HasRole("DIRECTOR"), requires any of those
+    roles; each top-level disjunct of the guard is a separate requirement.
+
+    Args:
+        lines: Lines of the source file
+        start: Index of the function's first line
+        end: Index just past its last line
+        predicate: Resolves a helper called in a guard to the roles it returns, or None
+        helper: Also treat raising or returning an error or false as a denial
+
+    Returns:
+        One entry per requirement, in source order
+    """
+    bindings: dict[str, str] = {}
+    found: list[RoleCheck] = []
+    for index in range(start, min(end, len(lines))):
+        line = lines[index]
+        if len(line) > MAX_LINE_LENGTH:
+            continue
+        guard = _guard(lines, index)
+        if guard is None:
+            assignment = ASSIGNMENT.match(line)
+            if assignment and index > start:
+                bindings[assignment.group(1)] = assignment.group(2).strip().rstrip(";")
+            continue
+        condition, negated, body = guard
+        if not (HELPER_DENIAL if helper else DENIAL).search(body):
+            continue
+        expression = condition if not negated else f"!({condition})"
+        for disjunct in _split(_unwrap(expression), OR_SEPARATOR):
+            roles, via = [], None
+            for part in _split(_unwrap(disjunct), AND_SEPARATOR):
+                atom = _atom(part, bindings)
+                if atom is not None and atom.kind == "role" and atom.negated:
+                    roles.append(atom.role)
+                    continue
+                call = NEGATED_CALL.match(_unwrap(part))
+                returned = predicate(call.group(1)) if call and predicate else None
+                if not returned:
+                    roles = []
+                    break
+                roles.extend(returned)
+                via = call.group(1)
+            if roles:
+                found.append(RoleCheck(index + 1, _short(line), list(dict.fromkeys(roles)), via))
+    return found
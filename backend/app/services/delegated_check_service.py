@@ -0,0 +1,217 @@
+"""Service for attributing checks delegated to helper functions back to routes.
+
+Route extractors and the handler condition pass only read the handler
+itself, so an endpoint whose handler calls ensureManager(user), or denies
+unless canApprove(user), is mined without the role its helper requires.
+This pass builds the call graph lazily from each policy's handlers: it
+resolves the functions a handler calls to their definitions, in the same
+file first and otherwise in clone files of the same language, and follows
+them to settings.INTERPROCEDURAL_DEPTH levels. Role requirements and
+attribute guards found on the way are added to the policy with evidence at
+the check, and a generic subject such as "Authenticated" is replaced by the
+roles the helpers require.
+"""
+
+from collections.abc import Callable, Iterator
+from pathlib import Path
+
+import structlog
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Evidence, Policy
+from app.services.abac_condition_service import AbacConditionService, _clause_key, _SourceFile
+from app.services.condition_evaluation_service import ConditionEvaluationService
+from app.services.delegated_check_extractor import HELPER_DENIAL, RoleCheck, calls, predicate_roles, role_checks
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.guard_condition_extractor import lift_guards
+
+logger = structlog.get_logger(__name__)
+
+MAX_DEPTH = 10
+# Definitions a called name may resolve to before the name is treated as too common to follow
+MAX_DEFINITIONS = 3
+
+Function = tuple[_SourceFile, int, int]
+
+
+class DelegatedCheckService(AbacConditionService):
+    """Follows handler calls into helper functions and attributes the checks found there to the route."""
+
+    @classmethod
+    def _definitions(cls, root: Path, source: _SourceFile, name: str, files: dict, index: dict) -> list[Function]:
+        """Functions a name called from a source file resolves to."""
+        local = [(source, s, e) for s, e, handler in source.handlers if handler == name]
+        found = local or cls._named(root, name, Path(source.path).suffix, files, index)
+        return found if len(found) <= MAX_DEFINITIONS else []
+
+    @classmethod
+    def _predicate(cls, root: Path, source: _SourceFile, files: dict, index: dict, predicates: dict) -> Callable:
+        """Resolver of helper names called from a source file to the roles they return."""
+
+        def resolve(name: str) -> list[str] | None:
+            key = (source.path, name)
+            if key not in predicates:
+                predicates[key] = None
+                for helper, start, end in cls._definitions(root, source, name, files, index):
+                    returned = predicate_roles(helper.lines, start, end)
+                    if returned:
+                        predicates[key] = (helper.path, returned)
+                        break
+            return predicates[key][1].roles if predicates[key] else None
+
+        return resolve
+
+    @classmethod
+    def trace(cls, root: Path, handler: Function, depth: int, files: dict, index: dict, predicates: dict) -> list[dict]:
+        """Checks delegated from one handler, at most depth calls away.
+
+        Args:
+            root: Repository clone root
+            handler: (source file, start, end) of the handler
+            depth: Call levels to follow
+            files: Loaded source files by path
+            index: Named function index per language
+            predicates: Resolved predicate helpers
+
+        Returns:
+            One entry per role requirement or condition, with the call chain leading to it
+        """
+        found: list[dict] = []
+        visited = {handler[:2]}
+        frontier = [(handler, [])]
+        for level in range(depth + 1):
+            following = []
+            for (source, start, end), chain in frontier:
+                resolve = cls._predicate(root, source, files, index, predicates)
+                for check in role_checks(source.lines, start, end, resolve, helper=level > 0):
+                    if level == 0 and check.predicate is None:
+                        continue  # the handler's own checks are the route extractors'
+                    path = chain + ([check.predicate] if check.predicate else [])
+                    if len(path) > depth:
+                        continue
+                    location: tuple[str, RoleCheck] = (source.path, check)
+                    if check.predicate:
+                        location = predicates[(source.path, check.predicate)]
+                    found.append(cls._item(path, location[0], location[1].line, location[1].guard, check.roles, None))
+                if level > 0:
+                    for condition in lift_guards(source.lines, start, end, source.constants, HELPER_DENIAL):
+                        found.append(cls._item(chain, source.path, condition.line, condition.guard, [], condition.condition))
+                if level == depth:
+                    continue
+                for _, name in calls(source.lines, start, end):
+                    for function in cls._definitions(root, source, name, files, index):
+                        if function[:2] not in visited:
+                            visited.add(function[:2])
+                            following.append((function, chain + [name]))
+            frontier = following
+        return found
+
+    @staticmethod
+    def _item(chain: list[str], file_path: str, line: int, guard: str, roles: list[str], condition: str | None) -> dict:
+        """A delegated check with the calls leading to it."""
+        return {"chain": chain, "depth": len(chain), "file_path": file_path, "line": line, "guard": guard, "roles": roles, "condition": condition}
+
+    def attribute_checks(self, repository_id: int, depth: int | None = None) -> dict:
+        """Attribute the checks a repository's handlers delegate to helpers to the policies mined for them.
+
+        Args:
+            repository_id: Repository ID
+            depth: Call levels to follow from each handler (default settings.INTERPROCEDURAL_DEPTH)
+
+        Returns:
+            Counts of policies examined and updated, and every delegated check with its call chain
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned, or the depth is out of range
+        """
+        depth = settings.INTERPROCEDURAL_DEPTH if depth is None else depth
+        if not 1 <= depth <= MAX_DEPTH:
+            raise ValueError(f"Depth must be between 1 and {MAX_DEPTH}")
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        policies = self.db.query(Policy).filter(Policy.repository_id == repo.id).all()
+        delegated = run_pass(root, delegated_checks, policies=[self.located(p) for p in policies], depth=depth)
+        attributed, updated = [], 0
+        for policy, handlers in zip(policies, delegated, strict=True):
+            rule = EndpointMappingService.map_policy(policy)
+            generic = not rule.roles
+            present = {_clause_key(c) for c in ConditionEvaluationService.parse(policy.conditions)}
+            added_conditions, role_groups = [], []
+            for handler, checks in handlers:
+                for check in checks:
+                    if check["condition"]:
+                        key = _clause_key(ConditionEvaluationService.parse_clause(check["condition"]))
+                        added = key not in present
+                        if added:
+                            present.add(key)
+                            added_conditions.append(check["condition"])
+                    else:
+                        # A subject naming roles came from the route's own guard, so helper roles only fill a generic one
+                        added = generic
+                        if added and check["roles"] not in role_groups:
+                            role_groups.append(check["roles"])
+                    if added and not any(e.file_path == check["file_path"] and e.line_start == check["line"] for e in policy.evidence):
+                        policy.evidence.append(
+                            Evidence(
+                                file_path=check["file_path"],
+                                line_start=check["line"],
+                                line_end=check["line"],
+                                code_snippet=check["guard"],
+                            )
+                        )
+                    attributed.append({"policy_id": policy.id, "route": rule.key, "handler": handler, **check, "added": added})
+            if role_groups:
+                single = len(role_groups) == 1
+                policy.subject = " and ".join(" or ".join(g) if single or len(g) == 1 else f"({' or '.join(g)})" for g in role_groups)
+            if added_conditions:
+                stated_conditions = ConditionEvaluationService.parse(policy.conditions)
+                policy.conditions = "; ".join([policy.conditions, *added_conditions] if stated_conditions else added_conditions)
+            updated += bool(role_groups or added_conditions)
+        self.db.commit()
+
+        logger.info(
+            "delegated_checks_attributed",
+            repository_id=repo.id,
+            policies=len(policies),
+            updated=updated,
+            checks=len(attributed),
+            depth=depth,
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repo.id,
+            "depth": depth,
+            "policies_examined": len(policies),
+            "policies_updated": updated,
+            "checks": attributed,
+        }
+
+
+def delegated_checks(root: str, policies: list[dict], depth: int) -> Iterator[list[list]]:
+    """Sandbox pass yielding, per policy, the checks its handlers delegate to helpers.
+
+    Args:
+        root: Repository clone root
+        policies: Per policy, its route and evidence (see AbacConditionService.located)
+        depth: Call levels to follow from each handler
+
+    Yields:
+        Per policy, in order: [handler name, checks traced from it] of each handler
+    """
+    clone = Path(root)
+    files: dict = {}
+    index: dict = {}
+    predicates: dict = {}
+    traced: dict[tuple[str, int], list[dict]] = {}
+    for policy in policies:
+        handlers = []
+        for source, start, end in DelegatedCheckService._located(clone, policy["route"], policy["evidence"], files, index):
+            if (source.path, start) not in traced:
+                traced[(source.path, start)] = DelegatedCheckService.trace(clone, (source, start, end), depth, files, index, predicates)
+            handler = next(name for s, _, name in source.handlers if s == start)
+            handlers.append([handler, traced[(source.path, start)]])
+        yield handlers
@@ -0,0 +1,937 @@
+"""Extract Django and Django REST framework authorization from Python services.
+
+Django guards function views with decorators such as @login_required and
+@permission_required, and class-based views with mixins such as
+LoginRequiredMixin. DRF views list permission_classes, falling back to
+DEFAULT_PERMISSION_CLASSES in settings (and to AllowAny without one), and
+custom BasePermission subclasses decide in has_permission, whose checks
+(is_staff, group membership, has_perm, safe methods) are read from the
+method body. A view only becomes an endpoint through urls.py, so each guard
+is mapped to the URL patterns routing to it, following include() prefixes
+and DRF router registrations. Sources are parsed with the ast module; files
+that do not parse are skipped.
+"""
+
+import ast
+import re
+from dataclasses import dataclass, field
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_span
+from app.services.jvm_route_extractor import ANONYMOUS, AUTHENTICATED, DENIED, MAX_SNIPPET_LINES, Access, _join
+
+DJANGO_MARKER = re.compile(r"\b(?:django|rest_framework)\b")
+
+
+class DjangoRouteKind:
+    """Kinds of Django findings."""
+
+    VIEW = "django_view"
+    DRF_VIEW = "drf_view"
+
+
+URL_FUNCTIONS = {"path", "re_path", "url"}
+HTTP_METHODS = ("get", "post", "put", "patch", "delete", "head", "options")
+SAFE_METHODS = ("GET", "HEAD", "OPTIONS")
+MODEL_PERMISSIONS = {"POST": "add", "PUT": "change", "PATCH": "change", "DELETE": "delete"}
+
+# Limits on recursion through nested expressions and include() chains
+MAX_DEPTH = 12
+MAX_SOURCE = 120
+
+# Generic class-based views and the methods they serve
+GENERIC_METHODS = {
+    "ListAPIView": ["GET"],
+    "RetrieveAPIView": ["GET"],
+    "CreateAPIView": ["POST"],
+    "DestroyAPIView": ["DELETE"],
+    "UpdateAPIView": ["PUT", "PATCH"],
+    "ListCreateAPIView": ["GET", "POST"],
+    "RetrieveUpdateAPIView": ["GET", "PUT", "PATCH"],
+    "RetrieveDestroyAPIView": ["GET", "DELETE"],
+    "RetrieveUpdateDestroyAPIView": ["GET", "PUT", "PATCH", "DELETE"],
+    "TemplateView": ["GET"],
+    "ListView": ["GET"],
+    "DetailView": ["GET"],
+    "RedirectView": ["GET"],
+    "FormView": ["GET", "POST"],
+    "CreateView": ["GET", "POST"],
+    "UpdateView": ["GET", "POST"],
+    "DeleteView": ["GET", "POST"],
+}
+
+# Viewset actions: action -> (routed on the detail URL, method)
+VIEWSET_ACTIONS = {
+    "list": (False, "GET"),
+    "create": (False, "POST"),
+    "retrieve": (True, "GET"),
+    "update": (True, "PUT"),
+    "partial_update": (True, "PATCH"),
+    "destroy": (True, "DELETE"),
+}
+VIEWSET_BASE_ACTIONS = {
+    "ModelViewSet": list(VIEWSET_ACTIONS),
+    "ReadOnlyModelViewSet": ["list", "retrieve"],
+    "ListModelMixin": ["list"],
+    "CreateModelMixin": ["create"],
+    "RetrieveModelMixin": ["retrieve"],
+    "UpdateModelMixin": ["update", "partial_update"],
+    "DestroyModelMixin": ["destroy"],
+}
+
+# Checks on the request's user
+ROLE_ATTRIBUTES = {"is_staff": "staff", "is_superuser": "superuser"}
+AUTH_ATTRIBUTES = {"is_authenticated", "is_active"}
+ROLE_SOURCE = re.compile(r"(?i)\b(?:roles?|groups?)\b|_(?:role|group)s?\b")
+CUSTOM_GUARD = re.compile(r"(?i)required|permission|role|staff|admin|superuser|login|authenticated")
+SAFE_ONLY = "only for safe methods (GET, HEAD, OPTIONS)"
+QUERYSET_MODEL = re.compile(r"\b(\w+)\.objects\b")
+
+
+@dataclass
+class _Rule:
+    """What one check requires; roles are alternatives."""
+
+    roles: list[str] = field(default_factory=list)
+    decision: str | None = None
+    conditions: list[str] = field(default_factory=list)
+    safe: bool = False  # Passes for safe methods only
+    model_permissions: bool = False  # Requires the model permission of the request method
+
+
+@dataclass
+class _Any:
+    """Checks of which one must pass."""
+
+    alternatives: list
+
+
+@dataclass
+class _All:
+    """Checks that must all pass."""
+
+    parts: list
+
+
+def _builtin_permission(name: str):
+    """Check tree of one of DRF's own permission classes, or None."""
+    return {
+        "AllowAny": _Rule(decision=ANONYMOUS),
+        "IsAuthenticated": _Rule(decision=AUTHENTICATED),
+        "IsAdminUser": _Rule(roles=["staff"]),
+        "IsAuthenticatedOrReadOnly": _Any([_Rule(safe=True), _Rule(decision=AUTHENTICATED)]),
+        "DjangoModelPermissions": _Rule(model_permissions=True),
+        "DjangoModelPermissionsOrAnonReadOnly": _Any([_Rule(safe=True), _Rule(model_permissions=True)]),
+        "DjangoObjectPermissions": _Rule(model_permissions=True, conditions=["object permissions are checked per instance"]),
+    }.get(name)
+
+
+def _source(node: ast.AST) -> str:
+    """Source text of an expression, shortened for descriptions."""
+    try:
+        text = ast.unparse(node)
+    except (RecursionError, ValueError):
+        return "..."
+    return text if len(text) <= MAX_SOURCE else text[: MAX_SOURCE - 3] + "..."
+
+
+def _strings(node: ast.AST | None) -> list[str]:
+    """String literals of a literal or a list, tuple, or set of them."""
+    if isinstance(node, ast.Constant) and isinstance(node.value, str):
+        return [node.value]
+    if isinstance(node, (ast.List, ast.Tuple, ast.Set)):
+        return [e.value for e in node.elts if isinstance(e, ast.Constant) and isinstance(e.value, str)]
+    return []
+
+
+def _keyword(call: ast.Call, name: str) -> ast.expr | None:
+    """Value of a call's keyword argument."""
+    return next((k.value for k in call.keywords if k.arg == name), None)
+
+
+def _call_name(node: ast.expr) -> str:
+    """Last name of a decorator or called function, as in @login_required or @decorators.action(...)."""
+    if isinstance(node, ast.Call):
+        node = node.func
+    if isinstance(node, ast.Attribute):
+        return node.attr
+    return node.id if isinstance(node, ast.Name) else ""
+
+
+def _all(rules: list[_Rule]) -> _Rule:
+    """Requirement of checks that must all pass."""
+    if any(r.decision == DENIED for r in rules):
+        return _Rule(decision=DENIED)
+    combined = _Rule()
+    for rule in rules:
+        if rule.roles and combined.roles:
+            combined.conditions.append(f"also holds role {' or '.join(rule.roles)}")
+        elif rule.roles:
+            combined.roles = list(rule.roles)
+        combined.conditions += [c for c in rule.conditions if c not in combined.conditions]
+    decisions = {r.decision for r in rules}
+    if AUTHENTICATED in decisions:
+        combined.decision = AUTHENTICATED
+    elif decisions <= {ANONYMOUS}:
+        combined.decision = ANONYMOUS
+    return combined
+
+
+def _describe(rule: _Rule) -> str:
+    """Short text of a requirement, for listing alternatives."""
+    subject = " or ".join(rule.roles) or rule.decision or AUTHENTICATED
+    return f"{subject} ({'; '.join(rule.conditions)})" if rule.conditions else subject
+
+
+def _any(rules: list[_Rule]) -> _Rule:
+    """Requirement of checks of which one must pass."""
+    passing = [r for r in rules if r.decision != DENIED or r.roles or r.conditions]
+    if not passing:
+        return _Rule(decision=DENIED)
+    if any(r.decision == ANONYMOUS and not r.roles and not r.conditions for r in passing):
+        return _Rule(decision=ANONYMOUS)
+    notes = []
+    if any(r.conditions == [SAFE_ONLY] and not r.roles for r in passing) and len(passing) > 1:
+        passing = [r for r in passing if r.conditions != [SAFE_ONLY] or r.roles]
+        notes.append("safe methods (GET, HEAD, OPTIONS) are open to anyone")
+    if all(r.roles and not r.conditions for r in passing):
+        combined = _Rule(roles=list(dict.fromkeys(role for r in passing for role in r.roles)))
+    elif len(passing) == 1:
+        combined = _Rule(roles=list(passing[0].roles), decision=passing[0].decision, conditions=list(passing[0].conditions))
+    else:
+        combined = _Rule(conditions=[f"any of: {' | '.join(_describe(r) for r in passing)}"])
+    combined.conditions += notes
+    return combined
+
+
+def _evaluate(node, method: str, model: str | None) -> _Rule:
+    """Flatten a check tree for one HTTP method ("*" when the method is unknown)."""
+    if isinstance(node, _All):
+        return _all([_evaluate(p, method, model) for p in node.parts])
+    if isinstance(node, _Any):
+        return _any([_evaluate(a, method, model) for a in node.alternatives])
+    if node.safe:
+        if method in SAFE_METHODS:
+            return _Rule(decision=ANONYMOUS)
+        return _Rule(decision=ANONYMOUS, conditions=[SAFE_ONLY]) if method == "*" else _Rule(decision=DENIED)
+    if node.model_permissions:
+        conditions = list(node.conditions)
+        if method == "*":
+            conditions.append("requires the model permission for the request method")
+        elif method in MODEL_PERMISSIONS:
+            conditions.append(f"requires permission {MODEL_PERMISSIONS[method]}_{(model or 'model').lower()}")
+        return _Rule(decision=AUTHENTICATED, conditions=conditions)
+    return node
+
+
+def _group_filter(node: ast.expr) -> list[str]:
+    """Group names of a user.groups.filter(name=...) query."""
+    if not isinstance(node, ast.Call) or not isinstance(node.func, ast.Attribute) or node.func.attr != "filter":
+        return []
+    if "groups" not in _source(node.func.value):
+        return []
+    return _strings(_keyword(node, "name")) or _strings(_keyword(node, "name__in"))
+
+
+def _check(node: ast.expr, depth: int = 0):
+    """Check tree of a boolean expression over the request and its user."""
+    if depth > MAX_DEPTH:
+        return _Rule(conditions=[f"passes {_source(node)}"])
+    if isinstance(node, ast.BoolOp):
+        parts = [_check(v, depth + 1) for v in node.values]
+        return _All(parts) if isinstance(node.op, ast.And) else _Any(parts)
+    if isinstance(node, ast.Constant) and isinstance(node.value, bool):
+        return _Rule(decision=ANONYMOUS if node.value else DENIED)
+    if isinstance(node, ast.UnaryOp) and isinstance(node.op, ast.Not):
+        return _negate(node.operand)
+    if isinstance(node, (ast.Name, ast.Attribute)) and (getattr(node, "attr", None) or getattr(node, "id", None)) == "user":
+        # Django's anonymous user is truthy too
+        return _Rule(decision=ANONYMOUS)
+    if isinstance(node, ast.Attribute):
+        if node.attr in ROLE_ATTRIBUTES:
+            return _Rule(roles=[ROLE_ATTRIBUTES[node.attr]])
+        if node.attr in AUTH_ATTRIBUTES:
+            return _Rule(decision=AUTHENTICATED)
+    if isinstance(node, ast.Call):
+        name = _call_name(node)
+        if name in ("has_perm", "has_perms") and node.args and _strings(node.args[0]):
+            return _Rule(decision=AUTHENTICATED, conditions=[f"requires permission {' and '.join(_strings(node.args[0]))}"])
+        if name == "exists" and isinstance(node.func, ast.Attribute) and _group_filter(node.func.value):
+            return _Rule(roles=_group_filter(node.func.value))
+        if name == "bool" and len(node.args) == 1:
+            return _check(node.args[0], depth + 1)
+    if isinstance(node, ast.Compare) and len(node.ops) == 1:
+        left, op, right = node.left, node.ops[0], node.comparators[0]
+        if isinstance(op, ast.In) and "method" in _source(left) and "SAFE_METHODS" in _source(right):
+            return _Rule(safe=True)
+        if isinstance(op, ast.In) and _strings(left) and ROLE_SOURCE.search(_source(right)):
+            return _Rule(roles=_strings(left))
+        if isinstance(op, (ast.Eq, ast.In)) and _strings(right) and ROLE_SOURCE.search(_source(left)):
+            return _Rule(roles=_strings(right))
+    return _Rule(conditions=[f"passes {_source(node)}"])
+
+
+def _negate(node: ast.expr):
+    """Check tree of a negated expression."""
+    if isinstance(node, ast.UnaryOp) and isinstance(node.op, ast.Not):
+        return _check(node.operand)
+    if isinstance(node, ast.Attribute) and node.attr == "is_anonymous":
+        return _Rule(decision=AUTHENTICATED)
+    return _Rule(conditions=[f"passes not {_source(node)}"])
+
+
+def _outcome(body: list[ast.stmt]) -> bool | None:
+    """Whether a branch grants (True) or refuses (False) access, or does something else."""
+    if not body:
+        return None
+    statement = body[0]
+    if isinstance(statement, ast.Raise):
+        return False
+    if isinstance(statement, ast.Return):
+        value = statement.value
+        if value is None or (isinstance(value, ast.Constant) and value.value in (False, None)):
+            return False
+        if isinstance(value, ast.Constant) and value.value is True:
+            return True
+    return None
+
+
+def _function_check(function: ast.FunctionDef | ast.AsyncFunctionDef | ast.Lambda):
+    """Check tree of a check function's body, or None if no decision is found.
+
+    Branches returning True are alternatives, branches returning False or
+    raising are requirements on the rest, and the final return decides last.
+    """
+    if isinstance(function, ast.Lambda):
+        return _check(function.body)
+    alternatives, required, returned = [], [], False
+    for statement in function.body:
+        if isinstance(statement, ast.If):
+            outcome = _outcome(statement.body)
+            if outcome is True:
+                alternatives.append(_check(statement.test))
+            elif outcome is False:
+                required.append(_negate(statement.test))
+        elif isinstance(statement, ast.Return):
+            if statement.value is not None:
+                required.append(_check(statement.value))
+            returned = True
+            break
+    options = alternatives + ([_All(required)] if returned and required else [])
+    if not options:
+        return None
+    return options[0] if len(options) == 1 else _Any(options)
+
+
+def _statements(body: list[ast.stmt]):
+    """Module-level statements, including those in if and try blocks."""
+    for statement in body:
+        if isinstance(statement, ast.If):
+            yield from _statements(statement.body + statement.orelse)
+        elif isinstance(statement, ast.Try):
+            yield from _statements(statement.body + statement.orelse + statement.finalbody)
+        else:
+            yield statement
+
+
+def _module_name(file_path: str) -> str:
+    """Dotted module name of a source path."""
+    parts = list(PurePosixPath(file_path).with_suffix("").parts)
+    if parts and parts[-1] == "__init__":
+        parts.pop()
+    return ".".join(parts)
+
+
+@dataclass
+class _Module:
+    """Top-level definitions of a parsed Python module."""
+
+    path: str
+    name: str
+    text: str
+    imports: dict[str, str] = field(default_factory=dict)  # Local name -> dotted target
+    functions: dict[str, ast.FunctionDef | ast.AsyncFunctionDef] = field(default_factory=dict)
+    classes: dict[str, ast.ClassDef] = field(default_factory=dict)
+    values: dict[str, ast.expr] = field(default_factory=dict)
+    urlpatterns: list[ast.expr] = field(default_factory=list)
+    registrations: dict[str, list[ast.Call]] = field(default_factory=dict)  # Router -> register() calls
+    calls: list[ast.Call] = field(default_factory=list)  # Top-level call statements
+
+
+def parse_module(file_path: str, text: str) -> _Module | None:
+    """Parse a Python source into its top-level definitions; None if it does not parse."""
+    try:
+        tree = ast.parse(text)
+    except (SyntaxError, ValueError, RecursionError, MemoryError):
+        return None
+    module = _Module(file_path, _module_name(file_path), text)
+    package = module.name.split(".") if file_path.endswith("__init__.py") else module.name.split(".")[:-1]
+    for statement in _statements(tree.body):
+        if isinstance(statement, ast.Import):
+            for alias in statement.names:
+                module.imports[alias.asname or alias.name.split(".")[0]] = alias.name if alias.asname else alias.name.split(".")[0]
+        elif isinstance(statement, ast.ImportFrom):
+            base = statement.module or ""
+            if statement.level:
+                parent = package[: max(len(package) - statement.level + 1, 0)]
+                base = ".".join(parent + ([statement.module] if statement.module else []))
+            for alias in statement.names:
+                module.imports[alias.asname or alias.name] = f"{base}.{alias.name}" if base else alias.name
+        elif isinstance(statement, (ast.FunctionDef, ast.AsyncFunctionDef)):
+            module.functions[statement.name] = statement
+        elif isinstance(statement, ast.ClassDef):
+            module.classes[statement.name] = statement
+        elif isinstance(statement, (ast.Assign, ast.AnnAssign)) and statement.value is not None:
+            targets = statement.targets if isinstance(statement, ast.Assign) else [statement.target]
+            for target in targets:
+                if isinstance(target, ast.Name):
+                    module.values[target.id] = statement.value
+                    if target.id == "urlpatterns":
+                        module.urlpatterns = [statement.value]
+        elif isinstance(statement, ast.AugAssign) and isinstance(statement.target, ast.Name):
+            if statement.target.id == "urlpatterns":
+                module.urlpatterns.append(statement.value)
+        elif isinstance(statement, ast.Expr) and isinstance(statement.value, ast.Call):
+            call = statement.value
+            module.calls.append(call)
+            if not isinstance(call.func, ast.Attribute) or not isinstance(call.func.value, ast.Name) or not call.args:
+                continue
+            receiver = call.func.value.id
+            if call.func.attr == "register" and len(call.args) > 1:
+                module.registrations.setdefault(receiver, []).append(call)
+            elif receiver == "urlpatterns" and call.func.attr in ("append", "extend"):
+                module.urlpatterns.append(call.args[0] if call.func.attr == "extend" else ast.List(elts=[call.args[0]]))
+    return module
+
+
+@dataclass
+class _Action:
+    """A viewset action and where it is routed."""
+
+    name: str
+    detail: bool
+    methods: list[str]
+    url_path: str = ""  # Extra actions add a path segment
+    permissions: object = None  # permission_classes given to @action
+    source: str = ""
+
+
+@dataclass
+class _View:
+    """A view's guards and the methods or viewset actions it serves."""
+
+    name: str
+    module: _Module
+    node: ast.FunctionDef | ast.AsyncFunctionDef | ast.ClassDef
+    drf: bool = False
+    guards: list = field(default_factory=list)  # Django decorators and mixins, all required
+    sources: list[str] = field(default_factory=list)
+    permissions: object = None  # DRF permission_classes
+    permission_source: str = ""
+    methods: list[str] = field(default_factory=list)
+    actions: list[_Action] | None = None  # Set for viewsets
+    lookup: str = "pk"
+    model: str | None = None
+    notes: list[str] = field(default_factory=list)
+
+    @property
+    def guarded(self) -> bool:
+        """Whether the view declares any guard of its own."""
+        return bool(self.guards or self.permissions is not None)
+
+
+class _Project:
+    """Name resolution across an application's modules."""
+
+    def __init__(self, modules: list[_Module]):
+        """Initialize project."""
+        self.modules = {m.name: m for m in modules}
+        self.views: dict[int, _View] = {}
+        self.included: set[str] = set()
+
+    def module(self, dotted: str) -> _Module | None:
+        """Module by dotted name, also matching sources below a source root such as src/."""
+        if dotted in self.modules:
+            return self.modules[dotted]
+        return next((m for name, m in sorted(self.modules.items()) if name.endswith(f".{dotted}")), None)
+
+    def dotted(self, dotted: str, depth: int = 0):
+        """Resolve a dotted name to a module or one of its definitions."""
+        module = self.module(dotted)
+        if module is not None:
+            return ("module", module)
+        if "." in dotted and depth < MAX_DEPTH:
+            parent, name = dotted.rsplit(".", 1)
+            module = self.module(parent)
+            if module is not None:
+                return self.lookup(module, name, depth + 1)
+        return None
+
+    def lookup(self, module: _Module, name: str, depth: int = 0):
+        """Resolve a name defined in or imported into a module."""
+        if depth > MAX_DEPTH:
+            return None
+        if name in module.functions:
+            return ("function", module, module.functions[name])
+        if name in module.classes:
+            return ("class", module, module.classes[name])
+        if name in module.registrations:
+            return ("router", module, name)
+        if name in module.values:
+            value = module.values[name]
+            if isinstance(value, (ast.Name, ast.Attribute)) or (isinstance(value, ast.Call) and _call_name(value) == "as_view"):
+                return self.resolve(module, value, depth + 1) or ("value", module, value)
+            return ("value", module, value)
+        if name in module.imports:
+            return self.dotted(module.imports[name], depth + 1)
+        return None
+
+    def resolve(self, module: _Module, node: ast.expr, depth: int = 0):
+        """Resolve an expression naming a module, view, router, or value."""
+        if depth > MAX_DEPTH:
+            return None
+        if isinstance(node, ast.Name):
+            return self.lookup(module, node.id, depth + 1)
+        if isinstance(node, ast.Attribute):
+            base = self.resolve(module, node.value, depth + 1)
+            if base and base[0] == "module":
+                return self.lookup(base[1], node.attr, depth + 1)
+            return None
+        if isinstance(node, ast.Call) and isinstance(node.func, ast.Attribute) and node.func.attr == "as_view":
+            return self.resolve(module, node.func.value, depth + 1)
+        return None
+
+    def chain(self, module: _Module, cls: ast.ClassDef) -> tuple[list[tuple[_Module, ast.ClassDef]], set[str]]:
+        """A class and its bases defined in the application, with the names of the other bases."""
+        chain, external, pending, seen = [], set(), [(module, cls)], set()
+        while pending and len(chain) < MAX_DEPTH:
+            current, node = pending.pop(0)
+            if id(node) in seen:
+                continue
+            seen.add(id(node))
+            chain.append((current, node))
+            for base in node.bases:
+                resolved = self.resolve(current, base)
+                if resolved and resolved[0] == "class":
+                    pending.append((resolved[1], resolved[2]))
+                else:
+                    external.add(_source(base).rsplit(".", 1)[-1])
+        return chain, external
+
+    # Permission classes
+
+    def permission(self, module: _Module, node: ast.expr, depth: int = 0):
+        """Check tree of one DRF permission class reference, including & | ~ compositions."""
+        if depth > MAX_DEPTH:
+            return _Rule(conditions=[f"passes {_source(node)}"])
+        if isinstance(node, ast.BinOp) and isinstance(node.op, (ast.BitAnd, ast.BitOr)):
+            parts = [self.permission(module, node.left, depth + 1), self.permission(module, node.right, depth + 1)]
+            return _All(parts) if isinstance(node.op, ast.BitAnd) else _Any(parts)
+        if isinstance(node, ast.UnaryOp) and isinstance(node.op, ast.Invert):
+            return _Rule(conditions=[f"fails {_source(node.operand)}"])
+        if isinstance(node, ast.Call):
+            node = node.func
+        if isinstance(node, ast.Constant) and isinstance(node.value, str):
+            resolved, name = self.dotted(node.value), node.value.rsplit(".", 1)[-1]
+        else:
+            resolved, name = self.resolve(module, node), _source(node).rsplit(".", 1)[-1]
+        if resolved and resolved[0] == "class":
+            return self.custom_permission(resolved[1], resolved[2])
+        return _builtin_permission(name) or _Rule(conditions=[f"passes {name}"])
+
+    def permission_list(self, module: _Module, node: ast.expr):
+        """Check tree of a permission_classes value; every listed class must pass."""
+        if isinstance(node, ast.Name) and node.id in module.values:
+            node = module.values[node.id]
+        if isinstance(node, (ast.List, ast.Tuple)):
+            return _All([self.permission(module, e) for e in node.elts])
+        return self.permission(module, node)
+
+    def custom_permission(self, module: _Module, cls: ast.ClassDef):
+        """Check tree of a BasePermission subclass, read from has_permission and has_object_permission."""
+        chain, external = self.chain(module, cls)
+        methods = {}
+        for _, node in reversed(chain):
+            methods.update({s.name: s for s in node.body if isinstance(s, (ast.FunctionDef, ast.AsyncFunctionDef))})
+        if "has_permission" in methods:
+            check = _function_check(methods["has_permission"]) or _Rule(conditions=[f"passes {cls.name}.has_permission"])
+        else:
+            check = next((_builtin_permission(name) for name in sorted(external) if _builtin_permission(name)), None)
+        check = check or _Rule(decision=ANONYMOUS)
+        if "has_object_permission" in methods:
+            check = _All([check, _Rule(decision=ANONYMOUS, conditions=[f"object-level check in {cls.name}.has_object_permission"])])
+        return check
+
+    def default_permissions(self) -> tuple[object, str]:
+        """DEFAULT_PERMISSION_CLASSES from settings, or DRF's own default of AllowAny."""
+        for module in self.modules.values():
+            settings = module.values.get("REST_FRAMEWORK")
+            if not isinstance(settings, ast.Dict):
+                continue
+            for key, value in zip(settings.keys, settings.values):
+                if _strings(key) == ["DEFAULT_PERMISSION_CLASSES"] and isinstance(value, (ast.List, ast.Tuple)):
+                    names = ", ".join(_strings(value))
+                    return _All([self.permission(module, e) for e in value.elts]), f"DEFAULT_PERMISSION_CLASSES [{names}] in {module.path}"
+        return _Rule(decision=ANONYMOUS), "DRF's default AllowAny (no DEFAULT_PERMISSION_CLASSES setting)"
+
+    # Views
+
+    def decorate(self, view: _View, module: _Module, decorator: ast.expr) -> None:
+        """Apply a view decorator's guard or method restriction."""
+        name = _call_name(decorator)
+        args = decorator.args if isinstance(decorator, ast.Call) else []
+        if name == "login_required":
+            view.guards.append(_Rule(decision=AUTHENTICATED))
+            view.sources.append("@login_required")
+        elif name == "permission_required":
+            permissions = _strings(args[0] if args else _keyword(decorator, "perm"))
+            view.guards.append(_Rule(decision=AUTHENTICATED, conditions=[f"requires permission {' and '.join(permissions)}"]))
+            view.sources.append(f"@permission_required({', '.join(permissions)})")
+        elif name == "user_passes_test" and args:
+            test = args[0]
+            resolved = self.resolve(module, test)
+            if isinstance(test, ast.Lambda):
+                check = _function_check(test)
+            elif resolved and resolved[0] == "function":
+                check = _function_check(resolved[2])
+            else:
+                check = None
+            view.guards.append(check or _Rule(conditions=[f"passes {_source(test)}"]))
+            view.sources.append(f"@user_passes_test({_source(test)})")
+        elif name in ("staff_member_required", "superuser_required"):
+            view.guards.append(_Rule(roles=["staff" if name.startswith("staff") else "superuser"]))
+            view.sources.append(f"@{name}")
+        elif name == "api_view":
+            view.drf = True
+            view.methods = [m.upper() for m in _strings(args[0] if args else _keyword(decorator, "http_method_names"))] or ["GET"]
+        elif name == "permission_classes" and args:
+            view.permissions = self.permission_list(module, args[0])
+            view.permission_source = f"@permission_classes({_source(args[0])})"
+        elif name == "require_http_methods" and args:
+            view.methods = [m.upper() for m in _strings(args[0])]
+        elif name in ("require_GET", "require_POST", "require_safe"):
+            view.methods = {"require_GET": ["GET"], "require_POST": ["POST"], "require_safe": ["GET", "HEAD"]}[name]
+        elif name == "method_decorator" and args:
+            inner = args[0].elts if isinstance(args[0], (ast.List, ast.Tuple)) else [args[0]]
+            for inner_decorator in inner:
+                self.decorate(view, module, inner_decorator)
+        elif name and CUSTOM_GUARD.search(name):
+            view.guards.append(_Rule(conditions=[f"checked by @{name}"]))
+            view.sources.append(f"@{name}")
+
+    def function_view(self, module: _Module, function: ast.FunctionDef | ast.AsyncFunctionDef) -> _View:
+        """Guards of a function view."""
+        view = _View(f"{module.name}.{function.name}", module, function, methods=["*"])
+        for decorator in function.decorator_list:
+            self.decorate(view, module, decorator)
+        return view
+
+    def class_view(self, module: _Module, cls: ast.ClassDef) -> _View:
+        """Guards of a class-based view, DRF view, or viewset, including inherited ones."""
+        view = _View(f"{module.name}.{cls.name}", module, cls)
+        chain, bases = self.chain(module, cls)
+        attributes: dict[str, tuple[_Module, ast.expr]] = {}
+        methods: dict[str, tuple[_Module, ast.FunctionDef | ast.AsyncFunctionDef]] = {}
+        for current, node in reversed(chain):
+            for statement in node.body:
+                if isinstance(statement, ast.Assign):
+                    for target in statement.targets:
+                        if isinstance(target, ast.Name):
+                            attributes[target.id] = (current, statement.value)
+                elif isinstance(statement, (ast.FunctionDef, ast.AsyncFunctionDef)):
+                    methods[statement.name] = (current, statement)
+        for current, node in chain:
+            for decorator in node.decorator_list:
+                self.decorate(view, current, decorator)
+        if "dispatch" in methods:
+            for decorator in methods["dispatch"][1].decorator_list:
+                self.decorate(view, methods["dispatch"][0], decorator)
+
+        if "LoginRequiredMixin" in bases:
+            view.guards.append(_Rule(decision=AUTHENTICATED))
+            view.sources.append("LoginRequiredMixin")
+        if "PermissionRequiredMixin" in bases and "permission_required" in attributes:
+            permissions = _strings(attributes["permission_required"][1])
+            view.guards.append(_Rule(decision=AUTHENTICATED, conditions=[f"requires permission {' and '.join(permissions)}"]))
+            view.sources.append(f"PermissionRequiredMixin({', '.join(permissions)})")
+        if "UserPassesTestMixin" in bases and "test_func" in methods:
+            check = _function_check(methods["test_func"][1])
+            view.guards.append(check or _Rule(conditions=[f"passes {cls.name}.test_func"]))
+            view.sources.append(f"UserPassesTestMixin ({cls.name}.test_func)")
+        for mixin, role in (("StaffuserRequiredMixin", "staff"), ("SuperuserRequiredMixin", "superuser")):
+            if mixin in bases:
+                view.guards.append(_Rule(roles=[role]))
+                view.sources.append(mixin)
+        if "GroupRequiredMixin" in bases and "group_required" in attributes:
+            view.guards.append(_Rule(roles=_strings(attributes["group_required"][1])))
+            view.sources.append("GroupRequiredMixin")
+
+        view.drf = any(b in ("APIView", "ViewSetMixin") or b.endswith(("APIView", "ViewSet")) for b in bases)
+        if view.drf:
+            if "permission_classes" in attributes:
+                current, value = attributes["permission_classes"]
+                view.permissions = self.permission_list(current, value)
+                view.permission_source = f"permission_classes = {_source(value)}"
+            if "get_permissions" in methods:
+                view.notes.append("permissions are chosen per request in get_permissions()")
+            if "lookup_field" in attributes and _strings(attributes["lookup_field"][1]):
+                view.lookup = _strings(attributes["lookup_field"][1])[0]
+            if "queryset" in attributes:
+                model = QUERYSET_MODEL.search(_source(attributes["queryset"][1]))
+                view.model = model.group(1) if model else None
+            elif "model" in attributes and isinstance(attributes["model"][1], ast.Name):
+                view.model = attributes["model"][1].id
+
+        if any(b.endswith("ViewSet") or b == "ViewSetMixin" for b in bases):
+            names = [a for b in sorted(bases) for a in VIEWSET_BASE_ACTIONS.get(b, [])]
+            names += [a for a in VIEWSET_ACTIONS if a in methods]
+            view.actions = [_Action(a, VIEWSET_ACTIONS[a][0], [VIEWSET_ACTIONS[a][1]]) for a in dict.fromkeys(names)]
+            for name, (current, method) in methods.items():
+                view.actions.extend(self.extra_actions(current, name, method))
+        else:
+            view.methods = [m.upper() for m in HTTP_METHODS if m in methods]
+            view.methods += [m for b in sorted(bases) for m in GENERIC_METHODS.get(b, []) if m not in view.methods]
+            view.methods = view.methods or ["*"]
+        return view
+
+    def extra_actions(self, module: _Module, name: str, method: ast.FunctionDef | ast.AsyncFunctionDef) -> list[_Action]:
+        """Viewset actions added with @action, each with its own path and optional permission classes."""
+        actions = []
+        for decorator in method.decorator_list:
+            decorator_name = _call_name(decorator)
+            if decorator_name not in ("action", "detail_route", "list_route"):
+                continue
+            call = decorator if isinstance(decorator, ast.Call) else ast.Call(func=decorator, args=[], keywords=[])
+            detail = _keyword(call, "detail")
+            url_path = _strings(_keyword(call, "url_path"))
+            permissions = _keyword(call, "permission_classes")
+            actions.append(
+                _Action(
+                    name,
+                    detail.value is True if isinstance(detail, ast.Constant) else decorator_name == "detail_route",
+                    [m.upper() for m in _strings(_keyword(call, "methods"))] or ["GET"],
+                    url_path[0] if url_path else name,
+                    self.permission_list(module, permissions) if permissions is not None else None,
+                    f"@action(permission_classes={_source(permissions)})" if permissions is not None else "",
+                )
+            )
+        return actions
+
+    def view(self, resolved) -> _View | None:
+        """The view a resolved name refers to, cached per definition."""
+        if not resolved or resolved[0] not in ("function", "class"):
+            return None
+        _, module, node = resolved
+        if id(node) not in self.views:
+            self.views[id(node)] = self.function_view(module, node) if resolved[0] == "function" else self.class_view(module, node)
+        return self.views[id(node)]
+
+    # URL patterns
+
+    def walk(self, module: _Module, node: ast.expr, prefix: str, stack: tuple[str, ...]) -> list[tuple]:
+        """Endpoints of a urlpatterns expression as (path, view expression, module, as_view actions)."""
+        if len(stack) > MAX_DEPTH:
+            return []
+        if isinstance(node, (ast.List, ast.Tuple)):
+            return [e for element in node.elts for e in self.walk(module, element, prefix, stack)]
+        if isinstance(node, ast.BinOp) and isinstance(node.op, ast.Add):
+            return self.walk(module, node.left, prefix, stack) + self.walk(module, node.right, prefix, stack)
+        if isinstance(node, ast.Starred):
+            return self.walk(module, node.value, prefix, stack)
+        if isinstance(node, ast.Attribute) and node.attr == "urls":
+            return self.included_urls(module, node.value, prefix, stack)
+        if isinstance(node, ast.Name):
+            resolved = self.lookup(module, node.id)
+            if resolved and resolved[0] == "value" and resolved[2] is not node:
+                return self.walk(resolved[1], resolved[2], prefix, stack + (node.id,))
+            return []
+        if not isinstance(node, ast.Call):
+            return []
+        name = _call_name(node)
+        if name == "include":
+            return self.include(module, node, prefix, stack)
+        if name not in URL_FUNCTIONS or not node.args:
+            return []
+        path = _join(prefix, _route(node.args[0], name))
+        target = node.args[1] if len(node.args) > 1 else _keyword(node, "view")
+        if target is None:
+            return []
+        if isinstance(target, ast.Call) and _call_name(target) == "include":
+            return self.include(module, target, path, stack)
+        if isinstance(target, (ast.List, ast.Tuple)):
+            return self.walk(module, target, path, stack)
+        actions = None
+        if isinstance(target, ast.Call) and _call_name(target) == "as_view" and target.args and isinstance(target.args[0], ast.Dict):
+            # A viewset routed by hand maps methods to actions
+            actions = {}
+            for method, action in zip(target.args[0].keys, target.args[0].values):
+                if _strings(method) and _strings(action):
+                    actions[_strings(method)[0].upper()] = _strings(action)[0]
+        return [(path, target, module, actions)]
+
+    def include(self, module: _Module, call: ast.Call, prefix: str, stack: tuple[str, ...]) -> list[tuple]:
+        """Endpoints of an include(): a dotted module name, a module, a router's urls, or a pattern list."""
+        target = call.args[0] if call.args else _keyword(call, "arg")
+        if isinstance(target, ast.Tuple) and target.elts:
+            target = target.elts[0]
+        if isinstance(target, ast.Constant) and isinstance(target.value, str):
+            return self.module_urls(self.module(target.value), prefix, stack)
+        if isinstance(target, ast.Attribute) and target.attr == "urls":
+            return self.included_urls(module, target.value, prefix, stack)
+        if isinstance(target, (ast.Name, ast.Attribute)):
+            resolved = self.resolve(module, target)
+            if resolved and resolved[0] == "module":
+                return self.module_urls(resolved[1], prefix, stack)
+        return self.walk(module, target, prefix, stack) if target is not None else []
+
+    def included_urls(self, module: _Module, node: ast.expr, prefix: str, stack: tuple[str, ...]) -> list[tuple]:
+        """Endpoints of a router's or a urls module's .urls."""
+        resolved = self.resolve(module, node)
+        if resolved and resolved[0] == "router":
+            return self.router_urls(resolved[1], resolved[2], prefix)
+        if resolved and resolved[0] == "module":
+            return self.module_urls(resolved[1], prefix, stack)
+        return []
+
+    def module_urls(self, module: _Module | None, prefix: str, stack: tuple[str, ...]) -> list[tuple]:
+        """Endpoints of a urls module's urlpatterns."""
+        if module is None or module.name in stack:
+            return []
+        if stack:
+            self.included.add(module.name)
+        return [e for node in module.urlpatterns for e in self.walk(module, node, prefix, stack + (module.name,))]
+
+    def router_urls(self, module: _Module, router: str, prefix: str) -> list[tuple]:
+        """Endpoints of a DRF router's registrations."""
+        return [
+            (_join(prefix, _route(call.args[0], "re_path")), call.args[1], module, None)
+            for call in module.registrations.get(router, [])
+        ]
+
+
+def _route(node: ast.expr, function: str) -> str:
+    """URL pattern of a path() or re_path() route, with parameters as {name}."""
+    if not isinstance(node, ast.Constant) or not isinstance(node.value, str):
+        return "{" + _source(node) + "}"
+    route = node.value
+    if function == "path":
+        return re.sub(r"<(?:\w+:)?(\w+)>", r"{\1}", route)
+    route = re.sub(r"\(\?P<(\w+)>(?:[^()]|\([^()]*\))*\)", r"{\1}", route.removeprefix("^").removesuffix("$"))
+    return route.replace("\\.", ".")
+
+
+def _access(rule: _Rule, source: str) -> Access:
+    """Access decision of a flattened requirement."""
+    if rule.roles:
+        subject = " or ".join(rule.roles)
+    else:
+        subject = rule.decision if rule.decision in (ANONYMOUS, DENIED) else AUTHENTICATED
+    return Access(subject, "; ".join(dict.fromkeys(rule.conditions)) or None, source)
+
+
+def _finding(view: _View, kind: str, resource: str, method: str, access: Access, label: str) -> ConfigFinding:
+    """Finding for one view method."""
+    node = view.node
+    line_start = min([d.lineno for d in node.decorator_list] + [node.lineno])
+    line_end = min(node.end_lineno or node.lineno, line_start + MAX_SNIPPET_LINES - 1)
+    return ConfigFinding(
+        kind=kind,
+        file_path=view.module.path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(view.module.text, line_start, line_end),
+        subject=access.subject,
+        resource=resource,
+        action=method,
+        conditions=access.conditions,
+        description=f"{label} secured by {access.source}",
+        disables_auth=access.subject == ANONYMOUS and not access.conditions,
+    )
+
+
+def _view_findings(
+    view: _View, resource: str, actions: dict[str, str] | None, default: tuple[object, str], routed: bool = True
+) -> list[ConfigFinding]:
+    """Findings of a view at a URL (or at its dotted name if unrouted), one per method or viewset action."""
+    kind = DjangoRouteKind.DRF_VIEW if view.drf else DjangoRouteKind.VIEW
+    label = f"{'DRF' if view.drf else 'Django'} view {view.name.rsplit('.', 1)[-1]}"
+    routes = []  # (resource, method, permissions, permission source, label)
+    if view.actions is not None:
+        for action in view.actions:
+            if actions is not None and action.name not in actions.values():
+                continue
+            path = resource
+            if routed and actions is None and action.detail:
+                path = _join(path, f"{{{view.lookup}}}")
+            if routed and actions is None and action.url_path:
+                path = _join(path, action.url_path)
+            methods = [m for m, a in actions.items() if a == action.name] if actions is not None else action.methods
+            for method in methods:
+                routes.append((path, method, action.permissions, action.source, f"{label}.{action.name}"))
+    else:
+        routes = [(resource, method, None, "", label) for method in view.methods]
+
+    findings = []
+    for path, method, permissions, permission_source, route_label in routes:
+        checks, sources = list(view.guards), list(view.sources)
+        if view.drf:
+            if permissions is None and view.permissions is not None:
+                permissions, permission_source = view.permissions, view.permission_source
+            if permissions is None:
+                permissions, permission_source = default
+            checks.append(permissions)
+            sources.append(permission_source)
+        rule = _evaluate(_All(checks), method, view.model)
+        rule.conditions = rule.conditions + [n for n in view.notes if n not in rule.conditions]
+        if not routed:
+            route_label += " (not routed in urls.py)"
+        findings.append(_finding(view, kind, path, method, _access(rule, " and ".join(sources)), route_label))
+    return findings
+
+
+def extract_django_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Django and DRF view authorization, mapped to URL patterns, from a service's files.
+
+    Args:
+        files: Relative path -> content; non-Python files are ignored
+
+    Returns:
+        One finding per routed view method or viewset action, plus guarded views no URL pattern routes to
+    """
+    sources = {p: t for p, t in sorted(files.items()) if p.endswith(".py") and DJANGO_MARKER.search(t)}
+    modules = [m for m in (parse_module(p, t) for p, t in sources.items()) if m is not None]
+    if not modules:
+        return []
+    project = _Project(modules)
+    routed = {m.name: project.module_urls(m, "", ()) for m in modules if m.urlpatterns}
+    default = project.default_permissions()
+
+    findings, seen, views_routed = [], set(), set()
+    for name, endpoints in routed.items():
+        if name in project.included:
+            continue
+        for path, target, module, actions in endpoints:
+            view = project.view(project.resolve(module, target))
+            if view is None or (not view.drf and not view.guards):
+                continue
+            views_routed.add(id(view.node))
+            for finding in _view_findings(view, path, actions, default):
+                key = (finding.file_path, finding.line_start, finding.resource, finding.action)
+                if key not in seen:
+                    seen.add(key)
+                    findings.append(finding)
+
+    # Guarded views without a URL pattern, such as ones routed dynamically or from unscanned code
+    bases = {_source(b).rsplit(".", 1)[-1] for m in modules for c in m.classes.values() for b in c.bases}
+    for module in modules:
+        candidates = [("function", module, f) for f in module.functions.values() if f.decorator_list]
+        candidates += [("class", module, c) for c in module.classes.values() if c.name not in bases]
+        for resolved in candidates:
+            if id(resolved[2]) in views_routed:
+                continue
+            view = project.view(resolved)
+            if view is None or not view.guarded:
+                continue
+            findings.extend(_view_findings(view, view.name, None, default, routed=False))
+    return findings
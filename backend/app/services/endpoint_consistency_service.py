@@ -0,0 +1,298 @@
+"""Service for flagging endpoints of one resource that are authorized inconsistently.
+
+Endpoints on the same resource usually share an authorization story: whoever
+may edit an expense may also read it, and deleting it is at least as guarded
+as editing it. When the mined rules break that pattern without anyone having
+written down why, one of them is usually wrong. This service groups each
+repository's endpoints by resource, the collection path and its item path
+(/api/expenses and /api/expenses/{id}), and compares every pair of methods:
+
+- a write open to anonymous callers while a sibling requires authentication
+- a write any authenticated user may call while a sibling requires a role
+- writes requiring different roles, e.g. DELETE requires ADMIN while PUT
+  requires only MANAGER
+- a read requiring roles a sibling write does not, so someone may change
+  what they may not see
+
+Custom actions (/api/expenses/{id}/approve) form groups of their own, since
+they are business operations expected to differ from plain CRUD. A pair is
+not reported when either rule documents its reason: a reviewer's approval
+comment, or a valid "policy:" annotation on the code it was mined from.
+"""
+
+from dataclasses import asdict, dataclass, field
+from itertools import combinations
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy
+from app.models.policy_annotation import PolicyAnnotationRecord
+from app.models.repository import Repository
+from app.services.bola_detection_service import HEADER_LINES, SEVERITY_ORDER
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+from app.services.endpoint_risk_service import WRITE_METHODS
+from app.services.stable_identity_service import normalize_path
+
+logger = structlog.get_logger(__name__)
+
+READ_METHODS = {"GET", "HEAD", "OPTIONS"}
+
+# Writes from least to most destructive; between writes requiring as many roles, the later should be stricter
+WRITE_ORDER = ["POST", "PATCH", "PUT", "DELETE"]
+
+
+class InconsistencyKind:
+    """How two endpoints of one resource disagree."""
+
+    ANONYMOUS_WRITE = "anonymous_write"  # A write open to anonymous callers, a sibling requires authentication
+    UNRESTRICTED_WRITE = "unrestricted_write"  # A write open to any authenticated user, a sibling requires a role
+    WRITE_ROLE_MISMATCH = "write_role_mismatch"  # Writes requiring different roles
+    READ_STRICTER_THAN_WRITE = "read_stricter_than_write"  # A role may write but not read
+
+
+SEVERITY = {
+    InconsistencyKind.ANONYMOUS_WRITE: "high",
+    InconsistencyKind.UNRESTRICTED_WRITE: "high",
+    InconsistencyKind.WRITE_ROLE_MISMATCH: "medium",
+    InconsistencyKind.READ_STRICTER_THAN_WRITE: "medium",
+}
+
+
+@dataclass
+class EndpointAccess:
+    """Who may call one endpoint of a resource group."""
+
+    method: str
+    path: str
+    access: str  # As read by a person, e.g. "ADMIN", "MANAGER or ADMIN", "any authenticated user"
+    roles: list[str]
+    requires_authentication: bool
+    policy_ids: list[int]
+    file_path: str | None = None
+    line: int | None = None
+
+
+@dataclass
+class EndpointInconsistency:
+    """Two endpoints of one resource authorized in a way that does not fit together."""
+
+    resource: str  # Collection path of the group, parameters as {}
+    kind: str
+    severity: str
+    looser: EndpointAccess  # The endpoint more callers can reach, or the less destructive write of a mismatch
+    stricter: EndpointAccess
+    description: str
+    policy_ids: list[int] = field(default_factory=list)
+
+
+def resource_group(path: str) -> str:
+    """Resource a route belongs to: its collection path, so /api/expenses/{id} groups with /api/expenses."""
+    normalized = normalize_path(path)
+    if normalized.endswith("/{}"):
+        return normalized[: -len("/{}")] or "/"
+    return normalized
+
+
+def _access(rule: EndpointRule) -> str:
+    """Who may call an endpoint, as read by a person."""
+    if rule.is_public:
+        return "anonymous callers"
+    if not rule.roles:
+        return "any authenticated user"
+    return " or ".join(rule.roles)
+
+
+def _endpoint(rule: EndpointRule) -> EndpointAccess:
+    """The access of one endpoint rule."""
+    return EndpointAccess(
+        method=rule.method,
+        path=rule.path,
+        access=_access(rule),
+        roles=list(rule.roles),
+        requires_authentication=rule.requires_authentication,
+        policy_ids=list(rule.policy_ids),
+        file_path=rule.file_path,
+        line=rule.line_start,
+    )
+
+
+def compare(a: EndpointRule, b: EndpointRule) -> tuple[str, EndpointRule, EndpointRule] | None:
+    """How two endpoints of one resource disagree, with the looser endpoint first.
+
+    Returns:
+        (kind, looser, stricter), or None if the pair fits together
+    """
+    if a.method == b.method or (a.is_public, set(a.roles)) == (b.is_public, set(b.roles)):
+        return None
+    for looser, stricter in ((a, b), (b, a)):
+        if looser.method not in WRITE_METHODS:
+            continue
+        if looser.is_public and not stricter.is_public:
+            return InconsistencyKind.ANONYMOUS_WRITE, looser, stricter
+        if not looser.is_public and not looser.roles and stricter.roles:
+            return InconsistencyKind.UNRESTRICTED_WRITE, looser, stricter
+    if a.is_public or b.is_public or not a.roles or not b.roles:
+        return None
+    if a.method in WRITE_METHODS and b.method in WRITE_METHODS:
+        # The wider role set goes first, then the less destructive write
+        looser, stricter = sorted((a, b), key=lambda r: (-len(r.roles), WRITE_ORDER.index(r.method)))
+        return InconsistencyKind.WRITE_ROLE_MISMATCH, looser, stricter
+    for write, read in ((a, b), (b, a)):
+        if write.method in WRITE_METHODS and read.method in READ_METHODS and set(write.roles) - set(read.roles):
+            return InconsistencyKind.READ_STRICTER_THAN_WRITE, write, read
+    return None
+
+
+def describe(kind: str, looser: EndpointAccess, stricter: EndpointAccess) -> str:
+    """Description of an inconsistency, naming both endpoints and who may call each."""
+    loose, strict = f"{looser.method} {looser.path}", f"{stricter.method} {stricter.path}"
+    if kind == InconsistencyKind.ANONYMOUS_WRITE:
+        return f"{loose} is open to anonymous callers while {strict} on the same resource requires {stricter.access}"
+    if kind == InconsistencyKind.UNRESTRICTED_WRITE:
+        return f"{loose} allows any authenticated user while {strict} on the same resource requires {stricter.access}"
+    if kind == InconsistencyKind.WRITE_ROLE_MISMATCH:
+        return f"{strict} requires {stricter.access} while {loose} on the same resource requires only {looser.access}"
+    extra = sorted(set(looser.roles) - set(stricter.roles))
+    return (
+        f"{strict} requires {stricter.access} while {loose} on the same resource allows {looser.access}, "
+        f"so {' and '.join(extra)} may change what they may not read"
+    )
+
+
+class EndpointConsistencyService:
+    """Compares how the endpoints of each resource are authorized."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self._query(Repository)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def documented_reason(policies: list[Policy], annotations: list[PolicyAnnotationRecord]) -> str | None:
+        """Why an endpoint's rule is what it is, when someone wrote it down.
+
+        Args:
+            policies: The endpoint's policies
+            annotations: Valid "policy:" annotations of the repository
+
+        Returns:
+            A reviewer's approval comment or the text of an annotation on the rule's code, or None
+        """
+        for policy in policies:
+            if (policy.approval_comment or "").strip():
+                return policy.approval_comment.strip()
+            for evidence in policy.evidence or []:
+                for annotation in annotations:
+                    if annotation.file_path != evidence.file_path:
+                        continue
+                    target = annotation.target_line or annotation.line
+                    if evidence.line_start - HEADER_LINES <= annotation.line and target <= evidence.line_end:
+                        return annotation.text
+        return None
+
+    def scan_repository(self, repository: Repository) -> tuple[list[EndpointInconsistency], dict[str, int]]:
+        """Inconsistencies among one repository's endpoints, and counts of what was compared."""
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository.id)
+        by_id = {p.id: p for p in policies}
+        annotations = (
+            self._query(PolicyAnnotationRecord)
+            .filter(PolicyAnnotationRecord.repository_id == repository.id)
+            .filter(PolicyAnnotationRecord.status == "valid")
+            .all()
+        )
+        groups: dict[str, list[EndpointRule]] = {}
+        for rule in EndpointMappingService.map_policies(policies):
+            groups.setdefault(resource_group(rule.path), []).append(rule)
+
+        found, documented = [], 0
+        for resource, rules in groups.items():
+            for a, b in combinations(rules, 2):
+                compared = compare(a, b)
+                if compared is None:
+                    continue
+                kind, looser, stricter = compared
+                pair = [by_id[pid] for pid in looser.policy_ids + stricter.policy_ids if pid in by_id]
+                if self.documented_reason(pair, annotations):
+                    documented += 1
+                    continue
+                loose, strict = _endpoint(looser), _endpoint(stricter)
+                found.append(
+                    EndpointInconsistency(
+                        resource=resource,
+                        kind=kind,
+                        severity=SEVERITY[kind],
+                        looser=loose,
+                        stricter=strict,
+                        description=describe(kind, loose, strict) + " and neither rule documents why",
+                        policy_ids=loose.policy_ids + strict.policy_ids,
+                    )
+                )
+        counts = {
+            "resources": sum(1 for rules in groups.values() if len(rules) > 1),
+            "endpoints": sum(len(rules) for rules in groups.values()),
+            "documented": documented,
+        }
+        return found, counts
+
+    def inconsistencies(self, repository_id: int | None = None, severity: str | None = None) -> dict:
+        """Endpoints of one resource authorized inconsistently, most severe first.
+
+        Args:
+            repository_id: Restrict to one repository
+            severity: Keep only this severity
+
+        Returns:
+            Inconsistencies with both endpoints' access and evidence, and summary counts
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+
+        results = []
+        totals = {"resources": 0, "endpoints": 0, "documented": 0}
+        for repository in repositories:
+            found, counts = self.scan_repository(repository)
+            for name, count in counts.items():
+                totals[name] += count
+            logger.info("endpoint_inconsistencies_detected", repository_id=repository.id, inconsistencies=len(found))
+            results.extend(
+                {**asdict(f), "repository_id": repository.id, "repository_name": repository.name} for f in found
+            )
+
+        if severity is not None:
+            results = [r for r in results if r["severity"] == severity.lower()]
+        results.sort(key=lambda r: (SEVERITY_ORDER[r["severity"]], r["repository_id"], r["resource"], r["kind"]))
+
+        by_kind: dict[str, int] = {}
+        by_severity: dict[str, int] = {}
+        for result in results:
+            by_kind[result["kind"]] = by_kind.get(result["kind"], 0) + 1
+            by_severity[result["severity"]] = by_severity.get(result["severity"], 0) + 1
+        return {
+            "inconsistencies": results,
+            "summary": {
+                **totals,
+                "inconsistencies": len(results),
+                "by_kind": by_kind,
+                "by_severity": by_severity,
+            },
+        }
@@ -0,0 +1,364 @@
+"""Service for mapping mined policies onto HTTP endpoints.
+
+Mined policies describe authorization as WHO/WHAT/HOW/WHEN text. Several
+features (test generation, simulation, role matrices) need the same policies
+viewed as endpoint rules: an HTTP method, a route path, and the roles allowed
+to call it. This service derives that view from the policy fields and the
+evidence snippets without any LLM calls.
+"""
+
+import re
+from collections import OrderedDict
+from dataclasses import dataclass, field
+
+import structlog
+
+from app.models.policy import Policy
+
+logger = structlog.get_logger(__name__)
+
+HTTP_METHODS = ["GET", "POST", "PUT", "PATCH", "DELETE"]
+
+# Action verbs mapped to the HTTP method that usually implements them
+ACTION_METHOD_KEYWORDS = [
+    ("delete", "DELETE"),
+    ("remove", "DELETE"),
+    ("destroy", "DELETE"),
+    ("patch", "PATCH"),
+    ("update", "PUT"),
+    ("edit", "PUT"),
+    ("modify", "PUT"),
+    ("approve", "PUT"),
+    ("reject", "PUT"),
+    ("create", "POST"),
+    ("add", "POST"),
+    ("submit", "POST"),
+    ("upload", "POST"),
+    ("write", "POST"),
+    ("read", "GET"),
+    ("view", "GET"),
+    ("list", "GET"),
+    ("get", "GET"),
+    ("access", "GET"),
+    ("export", "GET"),
+    ("download", "GET"),
+]
+
+# Route registration patterns across common frameworks (method, path)
+ROUTE_PATTERNS = [
+    # Express/Koa/Gin/Echo/Fastify: app.get("/path"), r.GET("/path")
+    re.compile(r"\.(get|post|put|patch|delete)\s*\(\s*['\"`](/[^'\"`]*)['\"`]", re.IGNORECASE),
+    # Hapi route objects: { method: "GET", path: "/path" }, in either key order
+    re.compile(
+        r"\bmethod\s*:\s*['\"](get|post|put|patch|delete)['\"]\s*,\s*path\s*:\s*['\"](/[^'\"]*)['\"]",
+        re.IGNORECASE,
+    ),
+    re.compile(
+        r"\bpath\s*:\s*['\"](/[^'\"]*)['\"]\s*,\s*method\s*:\s*['\"](get|post|put|patch|delete)['\"]",
+        re.IGNORECASE,
+    ),
+    # gorilla/mux: HandleFunc("/path", ...).Methods("GET"). The gap is bounded so a
+    # long line of unterminated registrations cannot cause quadratic scanning.
+    re.compile(r"HandleFunc\s*\(\s*\"(/[^\"]*)\"[^\n]{0,300}?\.Methods\s*\(\s*\"(\w+)\"", re.IGNORECASE),
+    # Spring: @GetMapping("/path"), @RequestMapping(value="/path", method=RequestMethod.GET)
+    re.compile(r"@(Get|Post|Put|Patch|Delete)Mapping\s*\(\s*(?:value\s*=\s*|path\s*=\s*)?\"(/[^\"]*)\""),
+    # Micronaut: @Get("/path"), @Post(uri = "/path")
+    re.compile(r"@(Get|Post|Put|Patch|Delete)\s*\(\s*(?:value\s*=\s*|uri\s*=\s*)?\"(/[^\"]*)\""),
+    # JAX-RS/Quarkus: @GET next to @Path("/path"), with up to a few annotations between
+    re.compile(r"@(GET|POST|PUT|PATCH|DELETE)\s+(?:@\w+(?:\([^)\n]{0,200}\))?\s+){0,4}@Path\s*\(\s*\"([^\"]*)\""),
+    re.compile(r"@Path\s*\(\s*\"(/[^\"]*)\"\s*\)\s+(?:@\w+(?:\([^)\n]{0,200}\))?\s+){0,4}@(GET|POST|PUT|PATCH|DELETE)\b"),
+    # ASP.NET: [HttpGet("path")]
+    re.compile(r"\[Http(Get|Post|Put|Patch|Delete)\s*\(\s*\"([^\"]*)\""),
+    # Flask/FastAPI: @app.route("/path", methods=["GET"]) or @router.get("/path")
+    re.compile(r"@\w+\.(get|post|put|patch|delete)\s*\(\s*['\"](/[^'\"]*)['\"]", re.IGNORECASE),
+]
+
+# Comment convention used in fixtures and handlers: "// GET /api/expenses - ..."
+COMMENT_ROUTE_PATTERN = re.compile(r"\b(GET|POST|PUT|PATCH|DELETE)\s+(/[\w\-/{}:.*]*)")
+
+# Words that indicate a subject is "any authenticated user" rather than a role
+AUTHENTICATED_SUBJECTS = {
+    "authenticated",
+    "authenticated user",
+    "authenticated users",
+    "any authenticated user",
+    "logged in user",
+    "logged-in user",
+    "user",
+    "users",
+    "any user",
+}
+
+ANONYMOUS_SUBJECTS = {"anonymous", "public", "anyone", "everyone", "unauthenticated"}
+
+ANONYMOUS_ROLE = "anonymous"
+AUTHENTICATED_ROLE = "authenticated"
+
+
+@dataclass
+class EndpointRule:
+    """An authorization rule expressed against an HTTP endpoint."""
+
+    method: str
+    path: str
+    roles: list[str] = field(default_factory=list)
+    requires_authentication: bool = True
+    conditions: list[str] = field(default_factory=list)
+    policy_ids: list[int] = field(default_factory=list)
+    resource: str = ""
+    action: str = ""
+    file_path: str | None = None
+    line_start: int | None = None
+
+    @property
+    def key(self) -> str:
+        """Stable key identifying the endpoint."""
+        return f"{self.method} {self.path}"
+
+    @property
+    def is_public(self) -> bool:
+        """Whether anonymous callers are allowed."""
+        return not self.requires_authentication
+
+    @property
+    def is_conditional(self) -> bool:
+        """Whether access depends on runtime attribute conditions."""
+        return bool(self.conditions)
+
+    def allows(self, role: str) -> bool:
+        """Check whether a role may call the endpoint (ignoring conditions).
+
+        Args:
+            role: Role name, or "anonymous"/"authenticated" pseudo-roles
+
+        Returns:
+            True if the role is allowed
+        """
+        if role == ANONYMOUS_ROLE:
+            return self.is_public
+        if not self.roles:
+            return True
+        return role.upper() in {r.upper() for r in self.roles}
+
+
+class EndpointMappingService:
+    """Maps policies to endpoint rules."""
+
+    @staticmethod
+    def parse_roles(subject: str | None) -> tuple[list[str], bool]:
+        """Parse role names out of a policy subject.
+
+        Args:
+            subject: Policy subject (e.g., "MANAGER or DIRECTOR", "Admin role")
+
+        Returns:
+            Tuple of (role names, requires_authentication). An empty role list
+            means any authenticated user.
+        """
+        if not subject:
+            return [], True
+
+        normalized = subject.strip()
+        lowered = normalized.lower()
+
+        if lowered in ANONYMOUS_SUBJECTS:
+            return [], False
+        if lowered in AUTHENTICATED_SUBJECTS:
+            return [], True
+
+        # Prefer explicitly quoted role names: hasRole('ADMIN'), "MANAGER"
+        quoted = re.findall(r"['\"]([A-Za-z][\w\-]*)['\"]", normalized)
+        if quoted:
+            candidates = quoted
+        else:
+            candidates = re.split(r",|/|\bor\b|\band\b|\||&", normalized, flags=re.IGNORECASE)
+
+        roles: list[str] = []
+        for candidate in candidates:
+            cleaned = re.sub(
+                r"\b(users?|with|role|roles|having|any|of|the|a|an)\b",
+                " ",
+                candidate,
+                flags=re.IGNORECASE,
+            )
+            cleaned = re.sub(r"^ROLE_", "", cleaned.strip())
+            cleaned = re.sub(r"[^\w\-]+", "_", cleaned).strip("_")
+            if not cleaned or cleaned.lower() in AUTHENTICATED_SUBJECTS:
+                continue
+            role = cleaned.upper()
+            if role not in roles:
+                roles.append(role)
+
+        return roles, True
+
+    @staticmethod
+    def find_routes(text: str) -> list[tuple[str, str]]:
+        """Find (method, path) route registrations in a code snippet.
+
+        Args:
+            text: Code snippet
+
+        Returns:
+            List of (METHOD, path) tuples in order of appearance
+        """
+        routes: list[tuple[str, str]] = []
+        for pattern in ROUTE_PATTERNS:
+            for match in pattern.finditer(text):
+                first, second = match.group(1), match.group(2)
+                if first.startswith("/"):
+                    method, path = second, first
+                else:
+                    method, path = first, second
+                if not path.startswith("/"):
+                    path = "/" + path
+                route = (method.upper(), path)
+                if route not in routes:
+                    routes.append(route)
+
+        for match in COMMENT_ROUTE_PATTERN.finditer(text):
+            route = (match.group(1).upper(), match.group(2))
+            if route not in routes:
+                routes.append(route)
+
+        return routes
+
+    @staticmethod
+    def infer_method(action: str | None) -> str:
+        """Infer the HTTP method implementing a policy action.
+
+        Args:
+            action: Policy action (e.g., "DELETE", "approve", "read")
+
+        Returns:
+            HTTP method, defaulting to GET
+        """
+        if not action:
+            return "GET"
+
+        upper = action.strip().upper()
+        for method in HTTP_METHODS:
+            if upper == method or upper.startswith(method + " "):
+                return method
+
+        lowered = action.lower()
+        for keyword, method in ACTION_METHOD_KEYWORDS:
+            if keyword in lowered:
+                return method
+        return "GET"
+
+    @staticmethod
+    def infer_path(resource: str | None, action: str | None = None) -> str:
+        """Infer a route path from a policy resource.
+
+        Args:
+            resource: Policy resource (e.g., "/api/expenses/{id}", "Expense Report")
+            action: Policy action, used when the action embeds a path
+
+        Returns:
+            Route path
+        """
+        for text in (resource, action):
+            if text:
+                match = re.search(r"(/[\w\-/{}:.*]+)", text)
+                if match:
+                    return match.group(1)
+
+        slug = re.sub(r"[^a-z0-9]+", "-", (resource or "resource").lower()).strip("-")
+        return f"/{slug or 'resource'}"
+
+    @classmethod
+    def map_policy(cls, policy: Policy) -> EndpointRule:
+        """Map a single policy to an endpoint rule.
+
+        Args:
+            policy: Policy to map
+
+        Returns:
+            EndpointRule for the policy
+        """
+        evidence = list(policy.evidence or [])
+        routes: list[tuple[str, str]] = []
+        for ev in evidence:
+            routes.extend(cls.find_routes(ev.code_snippet or ""))
+
+        inferred_method = cls.infer_method(policy.action)
+        method, path = inferred_method, None
+        if routes:
+            # Prefer a route whose method matches the policy action
+            matching = [r for r in routes if r[0] == inferred_method]
+            method, path = matching[0] if matching else routes[0]
+        if path is None:
+            path = cls.infer_path(policy.resource, policy.action)
+
+        roles, requires_auth = cls.parse_roles(policy.subject)
+        conditions = [policy.conditions.strip()] if policy.conditions and policy.conditions.strip() else []
+
+        first = evidence[0] if evidence else None
+        return EndpointRule(
+            method=method,
+            path=path,
+            roles=roles,
+            requires_authentication=requires_auth,
+            conditions=conditions,
+            policy_ids=[policy.id] if policy.id is not None else [],
+            resource=policy.resource or "",
+            action=policy.action or "",
+            file_path=first.file_path if first else None,
+            line_start=first.line_start if first else None,
+        )
+
+    @classmethod
+    def map_policies(cls, policies: list[Policy]) -> list[EndpointRule]:
+        """Map policies to endpoint rules, merging policies for the same endpoint.
+
+        When several policies cover one endpoint, their roles are combined
+        (any listed role may call it) and their conditions are accumulated.
+
+        Args:
+            policies: Policies to map
+
+        Returns:
+            Endpoint rules sorted by path then method
+        """
+        merged: OrderedDict[str, EndpointRule] = OrderedDict()
+
+        for policy in policies:
+            rule = cls.map_policy(policy)
+            existing = merged.get(rule.key)
+            if existing is None:
+                merged[rule.key] = rule
+                continue
+
+            if not rule.roles:
+                # A policy granting any authenticated user widens the endpoint
+                existing.roles = []
+            elif existing.roles:
+                for role in rule.roles:
+                    if role not in existing.roles:
+                        existing.roles.append(role)
+            existing.requires_authentication = (
+                existing.requires_authentication and rule.requires_authentication
+            )
+            for condition in rule.conditions:
+                if condition not in existing.conditions:
+                    existing.conditions.append(condition)
+            existing.policy_ids.extend(rule.policy_ids)
+
+        logger.debug("policies_mapped_to_endpoints", policies=len(policies), endpoints=len(merged))
+
+        return sorted(merged.values(), key=lambda r: (r.path, HTTP_METHODS.index(r.method)))
+
+    @staticmethod
+    def collect_roles(rules: list[EndpointRule]) -> list[str]:
+        """Collect every role referenced by a set of endpoint rules.
+
+        Args:
+            rules: Endpoint rules
+
+        Returns:
+            Sorted unique role names
+        """
+        roles: set[str] = set()
+        for rule in rules:
+            roles.update(rule.roles)
+        return sorted(roles)
@@ -0,0 +1,485 @@
+"""Service for composite endpoint risk scores.
+
+Policy-level risk scores describe how hard a rule is to get right. Triage
+also needs to know which endpoints matter most if the rule is wrong, so this
+service scores each endpoint on four components and combines them with
+configurable weights:
+
+- data sensitivity: keywords in the path, resource, and conditions
+- exposure: internet-facing or internal-only deployment (inferred from
+  ingress, load balancer, and gateway config), anonymous access, and
+  public/internal path hints
+- auth strength: anonymous, authenticated-only, role-based, or conditional
+- finding history: conflicts, enforcement gaps, and churn on its policies
+
+Findings are then ordered by the risk of the endpoints they touch, with
+their severity raised on internet-facing endpoints and lowered on
+internal-only ones.
+"""
+
+import copy
+import json
+from collections import defaultdict
+from dataclasses import asdict, dataclass, field
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.conflict import ConflictStatus, PolicyConflict
+from app.models.inconsistent_enforcement import InconsistentEnforcement, InconsistentEnforcementStatus
+from app.models.policy import Policy, SourceType
+from app.models.policy_change import PolicyChange
+from app.models.policy_fix import FixStatus, PolicyFix
+from app.services.auth_mechanism_service import AuthMechanism, rule_mechanisms
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+from app.services.exposure_service import EXPOSURE_ORDER, Exposure, ExposureService, describe_exposure, resolve_exposure
+from app.services.finding_taxonomy import FindingType, classify
+
+logger = structlog.get_logger(__name__)
+
+# Every key can be overridden from settings.RISK_MODEL_PATH; nested dicts are merged
+DEFAULT_RISK_MODEL = {
+    "weights": {
+        "data_sensitivity": 0.35,
+        "exposure": 0.25,
+        "auth_strength": 0.25,
+        "finding_history": 0.15,
+    },
+    # Highest matching keyword wins (0-100)
+    "sensitivity_keywords": {
+        "ssn": 100,
+        "credit_card": 100,
+        "password": 95,
+        "secret": 90,
+        "pii": 90,
+        "card": 80,
+        "payment": 80,
+        "salary": 80,
+        "payroll": 80,
+        "token": 75,
+        "admin": 70,
+        "financial": 70,
+        "personal": 70,
+        "invoice": 60,
+        "expense": 50,
+        "account": 50,
+        "config": 50,
+        "user": 40,
+    },
+    "default_sensitivity": 20,
+    "write_method_bonus": 10,
+    "exposure": {
+        "anonymous": 100,
+        "internet_facing": 90,
+        "public_hint": 75,
+        "frontend": 60,
+        "default": 40,
+        "internal_hint": 15,
+        "internal_only": 10,
+    },
+    # Severity levels a finding moves by the exposure of the endpoint it touches
+    "exposure_severity_shift": {"internet_facing": 1, "internal_only": -1},
+    "public_path_hints": ["public", "webhook", "callback", "oauth", "login", "signup", "share", "embed", "external"],
+    "internal_path_hints": ["internal", "private", "intranet"],
+    "auth_strength": {
+        "anonymous": 100,
+        "authenticated": 60,
+        "role": 30,
+        "conditional": 10,
+    },
+    # Points per finding, capped at 100; resolved findings count at resolved_factor
+    "finding_points": {
+        "conflict": 20,
+        "inconsistent_enforcement": 25,
+        "security_gap": 35,
+        "change": 5,
+    },
+    "resolved_factor": 0.5,
+    "levels": {"high": 70, "medium": 40},
+}
+
+WRITE_METHODS = {"POST", "PUT", "PATCH", "DELETE"}
+
+OPEN_ENFORCEMENT_STATUSES = (InconsistentEnforcementStatus.PENDING, InconsistentEnforcementStatus.ACKNOWLEDGED)
+
+SEVERITY_ORDER = {"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+# Finding severities from least to most severe
+SEVERITY_LEVELS = ["low", "medium", "high", "critical"]
+
+def load_risk_model(path: str | None = None) -> dict:
+    """Load the risk model, merging an optional override file over the defaults.
+
+    Args:
+        path: Override file path (defaults to settings.RISK_MODEL_PATH)
+
+    Returns:
+        Merged risk model
+
+    Raises:
+        ValueError: If the override file cannot be read
+    """
+    model = copy.deepcopy(DEFAULT_RISK_MODEL)
+    path = path if path is not None else settings.RISK_MODEL_PATH
+    if not path:
+        return model
+
+    try:
+        overrides = json.loads(Path(path).read_text())
+    except (OSError, json.JSONDecodeError) as e:
+        raise ValueError(f"Invalid risk model file {path}: {e}") from e
+
+    for key, value in overrides.items():
+        if isinstance(value, dict) and isinstance(model.get(key), dict):
+            model[key].update(value)
+        else:
+            model[key] = value
+    return model
+
+
+@dataclass
+class FindingHistory:
+    """Findings recorded against an endpoint's policies."""
+
+    open: dict[str, int] = field(default_factory=lambda: defaultdict(int))
+    resolved: dict[str, int] = field(default_factory=lambda: defaultdict(int))
+
+
+@dataclass
+class EndpointRisk:
+    """Composite risk score for one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    score: float
+    level: str
+    components: dict[str, float]
+    factors: list[str]
+    auth_mechanism: str = AuthMechanism.UNKNOWN.value
+    exposure: str = Exposure.UNKNOWN.value
+
+
+class EndpointRiskService:
+    """Scores endpoints and orders findings by endpoint risk."""
+
+    def __init__(
+        self, db: Session, tenant_id: str | None = None, model: dict | None = None, clone_dir: str | None = None
+    ):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.model = model or load_risk_model()
+        self.clone_dir = clone_dir
+
+    def _query(self, model):
+        """Query a model scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def level(self, score: float) -> str:
+        """Risk level for a score."""
+        levels = self.model["levels"]
+        if score >= levels["high"]:
+            return "high"
+        if score >= levels["medium"]:
+            return "medium"
+        return "low"
+
+    def data_sensitivity(self, rule: EndpointRule) -> tuple[float, list[str]]:
+        """Score how sensitive the data behind an endpoint is."""
+        text = " ".join([rule.path, rule.resource, *rule.conditions]).lower().replace("-", "_")
+        matches = [(k, v) for k, v in self.model["sensitivity_keywords"].items() if k in text]
+        factors = []
+        if matches:
+            keyword, score = max(matches, key=lambda m: m[1])
+            factors.append(f"sensitive data keyword '{keyword}'")
+        else:
+            score = self.model["default_sensitivity"]
+        if rule.method in WRITE_METHODS:
+            score += self.model["write_method_bonus"]
+            factors.append(f"{rule.method} modifies data")
+        return min(float(score), 100.0), factors
+
+    def exposure(
+        self,
+        rule: EndpointRule,
+        source_types: set[str],
+        inferred: Exposure = Exposure.UNKNOWN,
+        reason: str | None = None,
+    ) -> tuple[float, list[str]]:
+        """Score how reachable an endpoint is from outside.
+
+        Exposure inferred from deployment config outranks path hints; an
+        internal-only endpoint scores low even when it allows anonymous callers.
+        """
+        points = self.model["exposure"]
+        path = rule.path.lower()
+        inferred_factors = [reason] if reason else []
+        if inferred == Exposure.INTERNAL_ONLY:
+            anonymous = ["reachable without authentication"] if rule.is_public else []
+            return float(points.get("internal_only", points["internal_hint"])), inferred_factors + anonymous
+        if rule.is_public:
+            return float(points["anonymous"]), ["reachable without authentication", *inferred_factors]
+        if inferred == Exposure.INTERNET_FACING:
+            return float(points.get("internet_facing", points["public_hint"])), inferred_factors
+        hint = next((h for h in self.model["public_path_hints"] if h in path), None)
+        if hint:
+            return float(points["public_hint"]), [f"public path hint '{hint}'"]
+        if SourceType.FRONTEND.value in source_types:
+            return float(points["frontend"]), ["enforced in frontend code"]
+        hint = next((h for h in self.model["internal_path_hints"] if h in path), None)
+        if hint:
+            return float(points["internal_hint"]), [f"internal path hint '{hint}'"]
+        return float(points["default"]), []
+
+    def auth_strength(self, rule: EndpointRule) -> tuple[float, list[str]]:
+        """Score how weak an endpoint's authorization is (higher is weaker)."""
+        points = self.model["auth_strength"]
+        if rule.is_public:
+            return float(points["anonymous"]), ["no authentication"]
+        if rule.is_conditional:
+            return float(points["conditional"]), []
+        if rule.roles:
+            return float(points["role"]), []
+        return float(points["authenticated"]), ["any authenticated user"]
+
+    def finding_history(self, history: FindingHistory) -> tuple[float, list[str]]:
+        """Score the findings recorded against an endpoint."""
+        points = self.model["finding_points"]
+        resolved_factor = self.model["resolved_factor"]
+        score = sum(points.get(kind, 0) * count for kind, count in history.open.items())
+        score += sum(points.get(kind, 0) * count * resolved_factor for kind, count in history.resolved.items())
+        factors = [f"{count} open {kind.replace('_', ' ')}" for kind, count in sorted(history.open.items()) if count]
+        return min(float(score), 100.0), factors
+
+    def score_rule(
+        self,
+        rule: EndpointRule,
+        history: FindingHistory,
+        source_types: set[str],
+        exposure: Exposure = Exposure.UNKNOWN,
+        exposure_reason: str | None = None,
+    ) -> EndpointRisk:
+        """Combine the component scores for one endpoint.
+
+        Args:
+            rule: Endpoint rule
+            history: Findings recorded against the endpoint's policies
+            source_types: Source types of the endpoint's policies
+            exposure: Exposure inferred from the service's deployment config
+            exposure_reason: Why the exposure was inferred
+
+        Returns:
+            EndpointRisk with components and contributing factors
+        """
+        components, factors = {}, []
+        for name, (score, reasons) in (
+            ("data_sensitivity", self.data_sensitivity(rule)),
+            ("exposure", self.exposure(rule, source_types, exposure, exposure_reason)),
+            ("auth_strength", self.auth_strength(rule)),
+            ("finding_history", self.finding_history(history)),
+        ):
+            components[name] = round(score, 1)
+            factors.extend(reasons)
+
+        weights = self.model["weights"]
+        total_weight = sum(weights.values()) or 1.0
+        score = round(sum(components[k] * weights.get(k, 0) for k in components) / total_weight, 1)
+        return EndpointRisk(
+            endpoint=rule.key,
+            method=rule.method,
+            path=rule.path,
+            policy_ids=rule.policy_ids,
+            score=score,
+            level=self.level(score),
+            components=components,
+            factors=factors,
+            exposure=exposure.value,
+        )
+
+    def weighted_severity(self, severity: str, exposure: str) -> str:
+        """Severity of a finding moved by the exposure of the endpoint it touches."""
+        severity = str(severity).lower()
+        if severity not in SEVERITY_LEVELS:
+            return severity
+        shift = self.model.get("exposure_severity_shift", {}).get(exposure, 0)
+        index = min(max(SEVERITY_LEVELS.index(severity) + shift, 0), len(SEVERITY_LEVELS) - 1)
+        return SEVERITY_LEVELS[index]
+
+    def endpoint_exposure(self, rule: EndpointRule, policies: dict[int, Policy], signals: dict) -> tuple[Exposure, str | None]:
+        """Exposure of an endpoint across the services its policies were mined from."""
+        resolved = []
+        for repository_id in {policies[pid].repository_id for pid in rule.policy_ids if pid in policies}:
+            exposure, signal = resolve_exposure(rule.path, signals.get(repository_id, []))
+            resolved.append((exposure, describe_exposure(exposure, signal)))
+        if not resolved:
+            return Exposure.UNKNOWN, None
+        # Policies mined from several services take the most exposed one
+        return min(resolved, key=lambda r: EXPOSURE_ORDER[r[0]])
+
+    def load_history(self, policy_ids: set[int]) -> dict[int, FindingHistory]:
+        """Tally findings and changes per policy.
+
+        Args:
+            policy_ids: Policies in scope
+
+        Returns:
+            FindingHistory by policy ID
+        """
+        history: dict[int, FindingHistory] = defaultdict(FindingHistory)
+        if not policy_ids:
+            return history
+
+        def record(policy_id: int | None, kind: str, is_open: bool) -> None:
+            if policy_id in policy_ids:
+                tally = history[policy_id].open if is_open else history[policy_id].resolved
+                tally[kind] += 1
+
+        for conflict in self._query(PolicyConflict).all():
+            is_open = conflict.status == ConflictStatus.PENDING
+            record(conflict.policy_a_id, "conflict", is_open)
+            record(conflict.policy_b_id, "conflict", is_open)
+        for finding in self._query(InconsistentEnforcement).all():
+            is_open = finding.status in OPEN_ENFORCEMENT_STATUSES
+            for policy_id in finding.policy_ids or []:
+                record(policy_id, "inconsistent_enforcement", is_open)
+        for fix in self._query(PolicyFix).all():
+            record(fix.policy_id, "security_gap", fix.status not in (FixStatus.APPLIED, FixStatus.REJECTED))
+        for change in self._query(PolicyChange).all():
+            record(change.policy_id, "change", False)
+        return history
+
+    def score_endpoints(
+        self,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+        policies: list[Policy] | None = None,
+    ) -> list[dict]:
+        """Score every endpoint in scope, highest risk first.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+            policies: Pre-loaded policies (loaded from scope if omitted)
+
+        Returns:
+            Endpoint risk dictionaries sorted by descending score
+        """
+        if policies is None:
+            policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+                repository_id, application_id, include_pending=True
+            )
+        source_types = {p.id: p.source_type.value if p.source_type else None for p in policies}
+        by_id = {p.id: p for p in policies}
+        history = self.load_history(set(source_types))
+        exposure_service = ExposureService(self.db, self.tenant_id, self.clone_dir)
+        signals = exposure_service.repository_signals({p.repository_id for p in policies})
+
+        results = []
+        for rule in EndpointMappingService.map_policies(policies):
+            combined = FindingHistory()
+            for policy_id in rule.policy_ids:
+                for kind, count in history[policy_id].open.items():
+                    combined.open[kind] += count
+                for kind, count in history[policy_id].resolved.items():
+                    combined.resolved[kind] += count
+            sources = {source_types.get(pid) for pid in rule.policy_ids} - {None}
+            exposure, reason = self.endpoint_exposure(rule, by_id, signals)
+            risk = self.score_rule(rule, combined, sources, exposure, reason)
+            risk.auth_mechanism = rule_mechanisms(rule, by_id)[0].value
+            results.append(risk)
+
+        results.sort(key=lambda r: (-r.score, r.endpoint))
+        logger.info("endpoint_risk_scored", endpoints=len(results), repository_id=repository_id)
+        return [asdict(r) for r in results]
+
+    def rank_findings(self, repository_id: int | None = None, application_id: int | None = None) -> list[dict]:
+        """Order open findings by the risk of the endpoints they touch.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+
+        Returns:
+            Open findings with the highest endpoint risk among their policies, tagged with CWE and OWASP API categories
+        """
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+            repository_id, application_id, include_pending=True
+        )
+        risk_by_policy: dict[int, dict] = {}
+        for endpoint in self.score_endpoints(policies=policies):
+            for policy_id in endpoint["policy_ids"]:
+                current = risk_by_policy.get(policy_id)
+                if current is None or endpoint["score"] > current["score"]:
+                    risk_by_policy[policy_id] = endpoint
+
+        findings = []
+
+        def add(
+            finding_type: FindingType, kind: str | None, finding_id: int, severity: str, description: str, policy_ids: list[int]
+        ) -> None:
+            touched = [risk_by_policy[p] for p in policy_ids if p in risk_by_policy]
+            if not touched:
+                return
+            riskiest = max(touched, key=lambda e: e["score"])
+            entry = classify(finding_type, kind)
+            findings.append(
+                {
+                    "finding_type": finding_type.value,
+                    "finding_id": finding_id,
+                    "severity": severity,
+                    "description": description,
+                    "policy_ids": policy_ids,
+                    "cwe": list(entry.cwe),
+                    "owasp_api": [o.value for o in entry.owasp_api],
+                    "endpoint": riskiest["endpoint"],
+                    "endpoint_risk_score": riskiest["score"],
+                    "endpoint_risk_level": riskiest["level"],
+                    "exposure": riskiest["exposure"],
+                    "weighted_severity": self.weighted_severity(severity, riskiest["exposure"]),
+                }
+            )
+
+        for conflict in self._query(PolicyConflict).filter(PolicyConflict.status == ConflictStatus.PENDING).all():
+            add(
+                FindingType.CONFLICT,
+                conflict.conflict_type.value if conflict.conflict_type else None,
+                conflict.id,
+                conflict.severity,
+                conflict.description,
+                [conflict.policy_a_id, conflict.policy_b_id],
+            )
+        for finding in (
+            self._query(InconsistentEnforcement)
+            .filter(InconsistentEnforcement.status.in_(OPEN_ENFORCEMENT_STATUSES))
+            .all()
+        ):
+            add(
+                FindingType.INCONSISTENT_ENFORCEMENT,
+                None,
+                finding.id,
+                finding.severity.value if finding.severity else "medium",
+                finding.inconsistency_description,
+                list(finding.policy_ids or []),
+            )
+        for fix in self._query(PolicyFix).filter(PolicyFix.status.notin_([FixStatus.APPLIED, FixStatus.REJECTED])).all():
+            add(
+                FindingType.SECURITY_GAP,
+                fix.security_gap_type,
+                fix.id,
+                fix.severity.value if fix.severity else "medium",
+                fix.gap_description,
+                [fix.policy_id],
+            )
+
+        findings.sort(
+            key=lambda f: (-f["endpoint_risk_score"], SEVERITY_ORDER.get(f["weighted_severity"], 9), f["finding_id"])
+        )
+        return findings
@@ -0,0 +1,538 @@
+"""Extract non-HTTP entry points and the authorization they perform.
+
+Privileged work increasingly happens off the request path: a Kafka or SQS
+consumer applies whatever a message asks for, a scheduled job runs with the
+service's own credentials, and a Celery or Sidekiq task trusts the
+arguments it was enqueued with. None of these pass through the HTTP route
+model. These extractors find message consumers (Spring listeners, kafkajs,
+sqs-consumer, amqplib, sarama, kafka-go, boto3, Lambda event handlers,
+Karafka, Shoryuken), scheduled jobs (@Scheduled, node-cron, robfig/cron,
+APScheduler, NestJS @Cron, Kubernetes CronJobs), and background tasks
+(Celery, Dramatiq, Sidekiq, ActiveJob, BullMQ), and record for each handler
+the role and identity checks it performs and the privileged operations it
+carries out, without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field
+from enum import Enum
+from pathlib import PurePosixPath
+
+from app.services.extractor_utils import first_line, group, group_end, line_of, yaml_documents
+from app.services.node_route_extractor import JS_SUFFIXES
+from app.services.realtime_route_extractor import AUTH_CHECK, checked_roles, js_definition
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(
+    r"Listener|@Scheduled|@EventPattern|@MessagePattern|@Cron|@Interval|@Process|task|actor|scheduled_job|agent|"
+    r"receive_message|Records|Consumer|basic_consume|Sidekiq|Shoryuken|ApplicationJob|ActiveJob|Karafka|eachMessage|"
+    r"eachBatch|\.consume\(|Worker|cron|CronJob|AddFunc|ConsumeClaim|ReadMessage|FetchMessage|events\.\w+Event"
+)
+
+
+class EntryPointKind(str, Enum):
+    """How a non-HTTP entry point is triggered."""
+
+    MESSAGE_CONSUMER = "message_consumer"  # Handles messages from a topic, queue, or event source
+    SCHEDULED_JOB = "scheduled_job"  # Runs on a schedule with the service's own credentials
+    BACKGROUND_TASK = "background_task"  # Runs work enqueued by other code
+    CLI_COMMAND = "cli_command"  # Run by an operator from a command-line tool
+
+
+class EntryPointAuthorization(str, Enum):
+    """What an entry point checks before doing its work."""
+
+    ROLE_CHECKED = "role_checked"  # Checks a role or permission of the actor
+    IDENTITY_CHECKED = "identity_checked"  # Verifies who sent the work (token, signature, principal)
+    UNCHECKED = "unchecked"  # Acts on whatever it is handed
+
+
+# Operations that make an unchecked entry point worth a reviewer's time
+PRIVILEGED_OPERATIONS = {
+    "delete": re.compile(
+        r"\b(?:delete|destroy|purge|truncate|drop_?table|remove_?all)\w*\s*[(!]|\bDELETE\s+FROM\b|\bDROP\s+TABLE\b",
+        re.IGNORECASE,
+    ),
+    "payment": re.compile(r"\b(?:refund|payout|charge|withdraw|disburse|transfer_?funds)\w*\s*[(!]", re.IGNORECASE),
+    "access_grant": re.compile(
+        r"\b(?:grant|revoke|assign_?role|add_?role|set_?role|remove_?role|promote|make_?admin|impersonate)\w*\s*[(!]|"
+        r"\.(?:is_admin|isAdmin|is_superuser|is_staff|role|roles)\s*=[^=]",
+        re.IGNORECASE,
+    ),
+    "credentials": re.compile(
+        r"\b(?:reset_?password|set_?password|change_?password|rotate_?(?:key|secret|credential)s?|"
+        r"(?:create|issue|revoke)_?(?:api_?key|token))\w*\s*\(",
+        re.IGNORECASE,
+    ),
+    "shell": re.compile(
+        r"\bsubprocess\.|\bos\.system\(|\bexec\.Command\(|Runtime\.getRuntime\(\)\.exec|\bchild_process\b|\bexecSync\(|"
+        r"\bProcessBuilder\("
+    ),
+    "bulk_write": re.compile(r"\b(?:update_all|delete_all|bulk_update|bulk_create|updateMany|deleteMany|bulkWrite)\b"),
+}
+
+# The same categories read from command lines and command names (purge_tenants --delete)
+COMMAND_OPERATIONS = {
+    "delete": re.compile(r"(?:\b|_)(?:delete|destroy|purge|truncate|drop|wipe)", re.IGNORECASE),
+    "payment": re.compile(r"(?:\b|_)(?:refund|payout|charge|withdraw|disburse)", re.IGNORECASE),
+    "access_grant": re.compile(r"(?:\b|_)(?:grant|revoke|promote|impersonate|(?:add|assign|set|remove)[_-]?roles?)", re.IGNORECASE),
+    "credentials": re.compile(r"(?:\b|_)(?:password|rotate|api[_-]?keys?|credentials?)", re.IGNORECASE),
+}
+
+# Checks beyond request authentication that only make sense off the request path
+MESSAGE_IDENTITY_CHECK = re.compile(
+    r"\bverify_?[Ss]ignature\b|\bverifySignature\b|\bhmac\b|\bHMAC\b|\bcompare_digest\b|\bsecure_compare\b|"
+    r"\bauthorize[ds]?\w*!?\s*\(|\bhas_?[Pp]ermission\w*\s*\(|\bcheck_?[Pp]ermission\w*\s*\(|\bcan\?\s*\(|"
+    r"\bSecurityContextHolder\b|@PreAuthorize\b|@Secured\b|@RolesAllowed\b|\bPundit\b"
+)
+
+# Ruby role predicates (user.admin?) and Go role methods (HasRole("ops")), which the shared role patterns do not cover
+EXTRA_ROLE_CHECKS = [
+    re.compile(r"\.(admin|staff|superuser|moderator|owner|operator)\?"),
+    re.compile(r'\bHas(?:Any)?Role\(\s*"(?:ROLE_)?([\w-]+)"'),
+]
+
+STRING_LITERAL = re.compile(r"""['"]([^'"\n]+)['"]""")
+
+
+@dataclass
+class EntryPoint:
+    """A non-HTTP entry point and the checks found in its handler."""
+
+    kind: EntryPointKind
+    framework: str
+    name: str
+    file_path: str
+    line_start: int
+    line_end: int
+    trigger: str | None = None  # Topic, queue, task name, schedule, or command path it runs on
+    roles: list[str] = field(default_factory=list)
+    identity_checked: bool = False
+    privileged_operations: list[str] = field(default_factory=list)
+
+    @property
+    def authorization(self) -> EntryPointAuthorization:
+        """Strongest check the handler performs."""
+        if self.roles:
+            return EntryPointAuthorization.ROLE_CHECKED
+        if self.identity_checked:
+            return EntryPointAuthorization.IDENTITY_CHECKED
+        return EntryPointAuthorization.UNCHECKED
+
+    @property
+    def unchecked_privileged(self) -> bool:
+        """Performs privileged operations without checking anyone."""
+        return bool(self.privileged_operations) and self.authorization == EntryPointAuthorization.UNCHECKED
+
+
+def privileged_operations(text: str) -> list[str]:
+    """Privileged operation categories a piece of code carries out."""
+    return [name for name, pattern in PRIVILEGED_OPERATIONS.items() if pattern.search(text)]
+
+
+def command_operations(command: str) -> list[str]:
+    """Privileged operation categories a command line or command name implies."""
+    return [name for name, pattern in COMMAND_OPERATIONS.items() if pattern.search(command)]
+
+
+def roles_checked(text: str) -> list[str]:
+    """Role names a handler checks."""
+    roles = checked_roles(text)
+    for pattern in EXTRA_ROLE_CHECKS:
+        for match in pattern.finditer(text):
+            if match.group(1).upper() not in roles:
+                roles.append(match.group(1).upper())
+    return roles
+
+
+def identity_checked(text: str) -> bool:
+    """Whether a piece of code verifies who is acting."""
+    return bool(AUTH_CHECK.search(text) or MESSAGE_IDENTITY_CHECK.search(text))
+
+
+def _entry(
+    kind: EntryPointKind,
+    framework: str,
+    name: str,
+    trigger: str | None,
+    file_path: str,
+    text: str,
+    start: int,
+    end: int,
+) -> EntryPoint:
+    """Build an entry point for a handler span, classifying the checks and operations in it."""
+    body = text[start:end]
+    return EntryPoint(
+        kind=kind,
+        framework=framework,
+        name=name,
+        file_path=file_path,
+        line_start=line_of(text, start),
+        line_end=line_of(text, max(start, end - 1)),
+        trigger=trigger,
+        roles=roles_checked(body),
+        identity_checked=identity_checked(body),
+        privileged_operations=privileged_operations(body),
+    )
+
+
+def _trigger(args: str) -> str | None:
+    """First string literal of an argument list, or the arguments themselves when short."""
+    literal = STRING_LITERAL.search(args)
+    if literal:
+        return literal.group(1).strip()
+    compact = " ".join(args.split())
+    return compact if compact and len(compact) <= 80 and "{" not in compact else None
+
+
+def indented_end(text: str, start: int) -> int:
+    """Offset just past the indented block whose header line contains start.
+
+    A Ruby block's closing `end` at the header's indentation is included, as
+    are closing brackets of a signature split across lines.
+    """
+    line_start = text.rfind("\n", 0, start) + 1
+    end = text.find("\n", line_start)
+    if end == -1:
+        return len(text)
+    header = text[line_start:end]
+    indent = len(header) - len(header.lstrip())
+    offset = end
+    while offset < len(text):
+        line_end = text.find("\n", offset + 1)
+        line_end = len(text) if line_end == -1 else line_end
+        line = text[offset + 1 : line_end]
+        stripped = line.lstrip()
+        if stripped and len(line) - len(stripped) <= indent and not stripped.startswith((")", "]", "}")):
+            if stripped.rstrip() == "end" and len(line) - len(stripped) == indent:
+                end = line_end
+            break
+        if stripped:
+            end = line_end
+        offset = line_end
+    return end
+
+
+# JVM and NestJS: annotations on listener and scheduled methods
+LISTENER_ANNOTATIONS = {
+    "KafkaListener": ("kafka", EntryPointKind.MESSAGE_CONSUMER),
+    "RabbitListener": ("rabbitmq", EntryPointKind.MESSAGE_CONSUMER),
+    "SqsListener": ("sqs", EntryPointKind.MESSAGE_CONSUMER),
+    "JmsListener": ("jms", EntryPointKind.MESSAGE_CONSUMER),
+    "StreamListener": ("spring-cloud-stream", EntryPointKind.MESSAGE_CONSUMER),
+    "EventPattern": ("nestjs-microservices", EntryPointKind.MESSAGE_CONSUMER),
+    "MessagePattern": ("nestjs-microservices", EntryPointKind.MESSAGE_CONSUMER),
+    "Scheduled": ("spring-scheduled", EntryPointKind.SCHEDULED_JOB),
+    "Cron": ("nestjs-schedule", EntryPointKind.SCHEDULED_JOB),
+    "Interval": ("nestjs-schedule", EntryPointKind.SCHEDULED_JOB),
+    "Process": ("nestjs-bull", EntryPointKind.BACKGROUND_TASK),
+}
+LISTENER_ANNOTATION = re.compile(rf"@({'|'.join(LISTENER_ANNOTATIONS)})\b")
+METHOD_SIGNATURE = re.compile(
+    r"\b(\w+)\s*\([^)]*\)\s*(?::\s*[\w<>\[\],.|? ]+?)?\s*(?:throws\s+[\w.,\s]+?)?\{"
+)
+
+
+def extract_annotated(file_path: str, text: str) -> list[EntryPoint]:
+    """Listener and scheduled methods declared with annotations or decorators."""
+    entries = []
+    for match in LISTENER_ANNOTATION.finditer(text):
+        framework, kind = LISTENER_ANNOTATIONS[match.group(1)]
+        args_end = match.end()
+        args = ""
+        if text[match.end() : match.end() + 1] == "(":
+            args_end = group(text, match.end())
+            args = text[match.end() + 1 : args_end - 1]
+        # The method's own annotations (@PreAuthorize, @Secured) lie between the listener and its signature
+        signature = METHOD_SIGNATURE.search(text, args_end)
+        if not signature or LISTENER_ANNOTATION.search(text, args_end, signature.start()):
+            continue
+        end = group(text, signature.end() - 1)
+        # Annotations above the listener belong to the same method
+        start = max(text.rfind("}", 0, match.start()), text.rfind(";", 0, match.start()), text.rfind("{", 0, match.start()))
+        start = match.start() - len(text[start + 1 : match.start()].lstrip())
+        entries.append(_entry(kind, framework, signature.group(1), _trigger(args), file_path, text, start, end))
+    return entries
+
+
+# Python: task decorators and consumer loops inside functions
+PY_TASK = re.compile(
+    r"^[ \t]*@(?:(?:[\w.]+\.)(task|periodic_task|actor|scheduled_job|agent)|(shared_task|periodic_task))\b",
+    re.MULTILINE,
+)
+PY_TASKS = {
+    "task": ("celery", EntryPointKind.BACKGROUND_TASK),
+    "shared_task": ("celery", EntryPointKind.BACKGROUND_TASK),
+    "periodic_task": ("celery", EntryPointKind.SCHEDULED_JOB),
+    "actor": ("dramatiq", EntryPointKind.BACKGROUND_TASK),
+    "scheduled_job": ("apscheduler", EntryPointKind.SCHEDULED_JOB),
+    "agent": ("faust", EntryPointKind.MESSAGE_CONSUMER),
+}
+PY_DEF = re.compile(r"^[ \t]*(?:async\s+)?def\s+(\w+)", re.MULTILINE)
+PY_NAME_KWARG = re.compile(r"""\bname\s*=\s*['"]([^'"]+)['"]""")
+PY_CONSUMERS = [
+    (re.compile(r"\.receive_message\("), "sqs"),
+    (re.compile(r"""\bevent\[\s*['"]Records['"]\s*\]"""), "aws-lambda"),
+    (re.compile(r"\b(?:KafkaConsumer|AIOKafkaConsumer)\("), "kafka"),
+    (re.compile(r"\.basic_consume\("), "rabbitmq"),
+]
+PY_QUEUE = re.compile(r"""\b(?:QueueUrl|queue)\s*=\s*([\w.]+|['"][^'"]+['"])|(?:KafkaConsumer|AIOKafkaConsumer)\(\s*['"]([^'"]+)""")
+
+
+def _python_enclosing(text: str, offset: int) -> tuple[str, int, int] | None:
+    """Name and span of the innermost function containing offset."""
+    enclosing = None
+    for definition in PY_DEF.finditer(text, 0, offset):
+        if offset < indented_end(text, definition.start()):
+            enclosing = (definition.group(1), definition.start(), indented_end(text, definition.start()))
+    return enclosing
+
+
+def extract_python(file_path: str, text: str) -> list[EntryPoint]:
+    """Celery, Dramatiq, APScheduler, and Faust tasks, and SQS, Lambda, Kafka, and RabbitMQ consumers."""
+    entries = []
+    for match in PY_TASK.finditer(text):
+        name = match.group(1) or match.group(2)
+        framework, kind = PY_TASKS[name]
+        args_end = group_end(text, match.end())
+        definition = PY_DEF.search(text, args_end)
+        if not definition:
+            continue
+        args = text[match.end() : args_end].strip()[1:-1]
+        if framework == "celery":
+            task_name = PY_NAME_KWARG.search(args)
+            trigger = task_name.group(1) if task_name else definition.group(1)
+        else:
+            trigger = _trigger(args)
+        end = indented_end(text, definition.start())
+        entries.append(_entry(kind, framework, definition.group(1), trigger, file_path, text, match.start(), end))
+    seen = set()
+    for pattern, framework in PY_CONSUMERS:
+        for match in pattern.finditer(text):
+            enclosing = _python_enclosing(text, match.start())
+            if not enclosing or enclosing[1] in seen:
+                continue
+            seen.add(enclosing[1])
+            name, start, end = enclosing
+            queue = PY_QUEUE.search(text, start, end)
+            trigger = (queue.group(1) or queue.group(2)).strip("'\"") if queue else None
+            entries.append(
+                _entry(EntryPointKind.MESSAGE_CONSUMER, framework, name, trigger, file_path, text, start, end)
+            )
+    return entries
+
+
+# Ruby: Sidekiq, Shoryuken, and Karafka classes
+RUBY_CLASS = re.compile(r"^[ \t]*class\s+([\w:]+)(?:\s*<\s*([\w:]+))?", re.MULTILINE)
+RUBY_INCLUDES = {
+    "Sidekiq::Worker": ("sidekiq", EntryPointKind.BACKGROUND_TASK),
+    "Sidekiq::Job": ("sidekiq", EntryPointKind.BACKGROUND_TASK),
+    "Shoryuken::Worker": ("shoryuken", EntryPointKind.MESSAGE_CONSUMER),
+}
+RUBY_SUPERCLASSES = {
+    "ApplicationJob": ("activejob", EntryPointKind.BACKGROUND_TASK),
+    "ActiveJob::Base": ("activejob", EntryPointKind.BACKGROUND_TASK),
+    "ApplicationConsumer": ("karafka", EntryPointKind.MESSAGE_CONSUMER),
+    "Karafka::BaseConsumer": ("karafka", EntryPointKind.MESSAGE_CONSUMER),
+}
+RUBY_INCLUDE = re.compile(r"^[ \t]*include\s+([\w:]+)", re.MULTILINE)
+RUBY_QUEUE = re.compile(r"""(?:queue_as|(?:sidekiq|shoryuken)_options\s+queue:)\s*:?['"]?([\w.-]+)""")
+
+
+def extract_ruby(file_path: str, text: str) -> list[EntryPoint]:
+    """Sidekiq workers, ActiveJob jobs, Shoryuken workers, and Karafka consumers."""
+    entries = []
+    for match in RUBY_CLASS.finditer(text):
+        end = indented_end(text, match.start())
+        included = [m.group(1) for m in RUBY_INCLUDE.finditer(text, match.end(), end)]
+        framework = next((RUBY_INCLUDES[i] for i in included if i in RUBY_INCLUDES), None)
+        framework = framework or RUBY_SUPERCLASSES.get(match.group(2) or "")
+        if not framework:
+            continue
+        queue = RUBY_QUEUE.search(text, match.end(), end)
+        entries.append(
+            _entry(
+                framework[1], framework[0], match.group(1), queue.group(1) if queue else None, file_path, text,
+                match.start(), end,
+            )  # fmt: skip
+        )
+    return entries
+
+
+# JavaScript/TypeScript: consumer, worker, and cron calls taking a handler
+JS_CALLS = [
+    (re.compile(r"\.run\(\s*\{\s*each(?:Message|Batch)\s*:"), "kafka", EntryPointKind.MESSAGE_CONSUMER),
+    (re.compile(r"\bConsumer\.create\("), "sqs", EntryPointKind.MESSAGE_CONSUMER),
+    (re.compile(r"\b(?:channel|ch)\.consume\("), "rabbitmq", EntryPointKind.MESSAGE_CONSUMER),
+    (re.compile(r"\bnew\s+Worker\("), "bullmq", EntryPointKind.BACKGROUND_TASK),
+    (re.compile(r"\bcron\.schedule\("), "node-cron", EntryPointKind.SCHEDULED_JOB),
+    (re.compile(r"\bnew\s+CronJob\("), "cron", EntryPointKind.SCHEDULED_JOB),
+]
+JS_TOPIC = re.compile(r"""\btopics?\s*:\s*\[?\s*['"]([^'"]+)['"]|\bqueueUrl\s*:\s*['"]([^'"]+)['"]""")
+JS_HANDLER_NAME = re.compile(r"(?:,|each(?:Message|Batch)\s*:|handleMessage\s*:|processor\s*:)\s*([A-Za-z_$][\w$]*)\s*[,)}]")
+
+
+def extract_javascript(file_path: str, text: str) -> list[EntryPoint]:
+    """kafkajs, sqs-consumer, amqplib, BullMQ, and cron handlers."""
+    entries = []
+    for pattern, framework, kind in JS_CALLS:
+        for match in pattern.finditer(text):
+            opening = text.find("(", match.start())
+            end = group(text, opening)
+            call = text[opening + 1 : end - 1]
+            if framework in ("kafka", "sqs"):
+                topic = JS_TOPIC.search(call if framework == "sqs" else text)
+                trigger = (topic.group(1) or topic.group(2)) if topic else None
+            else:
+                trigger = _trigger(call.split(",")[0])
+            # A handler passed by name is checked where it is defined
+            handler = JS_HANDLER_NAME.search(call + ")")
+            body = js_definition(text, handler.group(1)) if handler and "=>" not in call and "function" not in call else ""
+            name = handler.group(1) if body else framework
+            entry = _entry(kind, framework, name, trigger, file_path, text, match.start(), end)
+            if body:
+                entry.roles = entry.roles or roles_checked(body)
+                entry.identity_checked = entry.identity_checked or identity_checked(body)
+                entry.privileged_operations = entry.privileged_operations or privileged_operations(body)
+            entries.append(entry)
+    return entries
+
+
+# Go: sarama, kafka-go, aws-sdk SQS, Lambda event handlers, and robfig/cron
+GO_FUNC = re.compile(r"^func\s+(?:\([^)]*\)\s*)?(\w+)\s*\(", re.MULTILINE)
+GO_CONSUME_CLAIM = re.compile(r"^func\s+\(\s*\w+\s+\*?\w+\s*\)\s+ConsumeClaim\(", re.MULTILINE)
+GO_SARAMA_TOPICS = re.compile(r"""\.Consume\(\s*\w+\s*,\s*\[\]string\{\s*"([^"]+)\"""")
+GO_KAFKA_READER = re.compile(r"\bkafka\.New(?:Reader|Consumer)\b")
+GO_KAFKA_READ = re.compile(r"\.(?:ReadMessage|FetchMessage)\((?!\))")
+GO_KAFKA_TOPIC = re.compile(r"""\bTopic\s*:\s*"([^"]+)"|\.Subscribe(?:Topics)?\(\s*(?:\[\]string\{\s*)?"([^"]+)\"""")
+GO_SQS_RECEIVE = re.compile(r"\.ReceiveMessage(?:WithContext)?\(")
+GO_SQS_QUEUE = re.compile(r"""QueueUrl\s*:\s*(?:aws\.String\()?\s*([\w.]+|"[^"]+")""")
+GO_LAMBDA = re.compile(r"^func\s+(\w+)\s*\([^)]*events\.(\w+?)Event\b", re.MULTILINE)
+GO_CRON = re.compile(r"""\.AddFunc\(\s*"([^"]+)"\s*,\s*""")
+
+
+def go_enclosing(text: str, offset: int) -> tuple[str, int, int] | None:
+    """Name and span of the top-level function containing offset."""
+    enclosing = None
+    for definition in GO_FUNC.finditer(text, 0, offset):
+        brace = text.find("{", definition.end())
+        if brace >= 0 and offset < group(text, brace):
+            enclosing = (definition.group(1), definition.start(), group(text, brace))
+    return enclosing
+
+
+def go_body(text: str, name: str) -> tuple[int, int] | None:
+    """Span of a top-level Go function by name."""
+    definition = re.search(rf"^func\s+(?:\([^)]*\)\s*)?{re.escape(name)}\s*\(", text, re.MULTILINE)
+    if not definition:
+        return None
+    brace = text.find("{", definition.end())
+    return (definition.start(), group(text, brace)) if brace >= 0 else None
+
+
+def extract_go(file_path: str, text: str) -> list[EntryPoint]:
+    """Kafka and SQS consumers, Lambda event handlers, and cron jobs."""
+    entries = []
+    for match in GO_CONSUME_CLAIM.finditer(text):
+        brace = text.find("{", match.end())
+        topics = GO_SARAMA_TOPICS.search(text)
+        entries.append(
+            _entry(
+                EntryPointKind.MESSAGE_CONSUMER, "kafka", "ConsumeClaim", topics.group(1) if topics else None,
+                file_path, text, match.start(), group(text, brace),
+            )  # fmt: skip
+        )
+    consumers = []
+    if GO_KAFKA_READER.search(text):
+        topic = GO_KAFKA_TOPIC.search(text)
+        consumers.append((GO_KAFKA_READ, "kafka", (topic.group(1) or topic.group(2)) if topic else None))
+    queue = GO_SQS_QUEUE.search(text)
+    consumers.append((GO_SQS_RECEIVE, "sqs", queue.group(1).strip('"') if queue else None))
+    seen = set()
+    for pattern, framework, trigger in consumers:
+        for match in pattern.finditer(text):
+            enclosing = go_enclosing(text, match.start())
+            if not enclosing or enclosing[1] in seen:
+                continue
+            seen.add(enclosing[1])
+            name, start, end = enclosing
+            entries.append(_entry(EntryPointKind.MESSAGE_CONSUMER, framework, name, trigger, file_path, text, start, end))
+    for match in GO_LAMBDA.finditer(text):
+        brace = text.find("{", match.end())
+        scheduled = match.group(2) in ("CloudWatch", "EventBridge")
+        kind = EntryPointKind.SCHEDULED_JOB if scheduled else EntryPointKind.MESSAGE_CONSUMER
+        entries.append(
+            _entry(kind, "aws-lambda", match.group(1), match.group(2), file_path, text, match.start(), group(text, brace))
+        )
+    for match in GO_CRON.finditer(text):
+        end = group(text, text.find("(", match.start()))
+        entry = _entry(EntryPointKind.SCHEDULED_JOB, "robfig-cron", "cron", match.group(1), file_path, text, match.start(), end)
+        named = re.match(r"(\w+)\s*\)", text[match.end() : end])
+        span = go_body(text, named.group(1)) if named else None
+        if span:
+            named_entry = _entry(EntryPointKind.SCHEDULED_JOB, "robfig-cron", named.group(1), match.group(1), file_path, text, *span)
+            entry.name, entry.roles = named_entry.name, named_entry.roles
+            entry.identity_checked = named_entry.identity_checked
+            entry.privileged_operations = named_entry.privileged_operations
+        entries.append(entry)
+    return entries
+
+
+def extract_cronjobs(file_path: str, text: str) -> list[EntryPoint]:
+    """Kubernetes CronJobs; the command they run is the handler."""
+    entries = []
+    for document in yaml_documents(text):
+        if not isinstance(document, dict) or document.get("kind") != "CronJob":
+            continue
+        name = str((document.get("metadata") or {}).get("name") or "cronjob")
+        spec = document.get("spec") or {}
+        pod = (((spec.get("jobTemplate") or {}).get("spec") or {}).get("template") or {}).get("spec") or {}
+        commands = []
+        for container in pod.get("containers") or []:
+            if isinstance(container, dict):
+                commands += [str(part) for part in (container.get("command") or []) + (container.get("args") or [])]
+        command = " ".join(commands)
+        line = first_line(text, f"name: {name}")
+        entries.append(
+            EntryPoint(
+                kind=EntryPointKind.SCHEDULED_JOB,
+                framework="kubernetes-cronjob",
+                name=name,
+                file_path=file_path,
+                line_start=line,
+                line_end=line,
+                trigger=str(spec.get("schedule")) if spec.get("schedule") else None,
+                privileged_operations=command_operations(command),
+            )
+        )
+    return entries
+
+
+def extract_entry_points(file_path: str, text: str) -> list[EntryPoint]:
+    """Non-HTTP entry points declared in one file.
+
+    Args:
+        file_path: Path of the file relative to the repository root
+        text: File content
+
+    Returns:
+        Entry points in file order
+    """
+    if not PREFILTER.search(text):
+        return []
+    suffix = PurePosixPath(file_path).suffix
+    if suffix in (".java", ".kt"):
+        entries = extract_annotated(file_path, text)
+    elif suffix in JS_SUFFIXES:
+        entries = extract_annotated(file_path, text) + extract_javascript(file_path, text)
+    elif suffix == ".py":
+        entries = extract_python(file_path, text)
+    elif suffix == ".rb":
+        entries = extract_ruby(file_path, text)
+    elif suffix == ".go":
+        entries = extract_go(file_path, text)
+    elif suffix in (".yml", ".yaml") and "CronJob" in text:
+        entries = extract_cronjobs(file_path, text)
+    else:
+        return []
+    return sorted(entries, key=lambda e: e.line_start)
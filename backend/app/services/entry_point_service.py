@@ -0,0 +1,171 @@
+"""Service for inventorying non-HTTP entry points and their authorization.
+
+Message consumers, scheduled jobs, background tasks, and admin CLI commands
+act without a request, so no route policy covers them. This service reads
+those entry points from a repository's clone, credits each with the mined
+policies whose evidence lies inside its handler, and reports the ones that
+carry out privileged operations without checking any role or identity. CLI
+commands are listed in their own section.
+"""
+
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import asdict
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.cli_command_extractor import extract_cli_commands
+from app.services.cors_csrf_service import SOURCE_EXTENSIONS
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import ANONYMOUS_ROLE
+from app.services.entry_point_extractor import EntryPoint, EntryPointKind, extract_entry_points
+
+logger = structlog.get_logger(__name__)
+
+# Policy subjects that identify the actor without naming a role
+AUTHENTICATED_SUBJECTS = {"authenticated users", "authenticated"}
+
+
+def resolve_entry_points(policies: list[Policy], entries: list[EntryPoint]) -> list[dict]:
+    """Attach the mined policies enforced inside each entry point's handler.
+
+    A policy whose evidence lies within a handler is a check that handler
+    performs: its subject counts as a role check, or as an identity check
+    when it only requires an authenticated actor.
+
+    Args:
+        policies: Mined policies of the same service
+        entries: Entry points read from the clone
+
+    Returns:
+        Entry point dictionaries, unchecked privileged ones first
+    """
+    resolved = []
+    for entry in entries:
+        policy_ids = []
+        for policy in policies:
+            inside = any(
+                evidence.file_path == entry.file_path and entry.line_start <= (evidence.line_start or 0) <= entry.line_end
+                for evidence in policy.evidence
+            )
+            if not inside:
+                continue
+            policy_ids.append(policy.id)
+            subject = (policy.subject or "").strip()
+            if subject.lower() in AUTHENTICATED_SUBJECTS:
+                entry.identity_checked = True
+            elif subject and subject.lower() != ANONYMOUS_ROLE and subject not in entry.roles:
+                entry.roles.append(subject)
+        resolved.append(
+            asdict(entry)
+            | {
+                "authorization": entry.authorization,
+                "unchecked_privileged": entry.unchecked_privileged,
+                "policy_ids": policy_ids,
+            }
+        )
+    resolved.sort(key=lambda e: (not e["unchecked_privileged"], e["file_path"], e["line_start"]))
+    return resolved
+
+
+def entry_points(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the non-HTTP entry points of a repository clone in path order, then CLI commands."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    files: dict[str, str] = {}
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in SOURCE_EXTENSIONS:
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        files[relative.as_posix()] = path.read_text(encoding="utf-8", errors="ignore")
+    for file_path, text in files.items():
+        yield from (asdict(e) for e in extract_entry_points(file_path, text))
+    # Command trees span files, so CLI commands are read from the whole clone at once
+    yield from (asdict(e) for e in extract_cli_commands(files))
+
+
+class EntryPointService:
+    """Inventories message consumers, scheduled jobs, background tasks, and CLI commands."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[EntryPoint]:
+        """Read non-HTTP entry points from a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Entry points in path order, then CLI commands
+        """
+        return [EntryPoint(**{**e, "kind": EntryPointKind(e["kind"])}) for e in run_pass(root, entry_points)]
+
+    def _inventory(self, repository: Repository) -> dict:
+        """Entry point inventory of one repository."""
+        root = self.clone_dir / str(repository.id)
+        cloned = root.is_dir()
+        entries = self.scan_clone(root) if cloned else []
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository.id)
+        entry_points = resolve_entry_points(policies, entries)
+        unchecked = sum(e["unchecked_privileged"] for e in entry_points)
+        logger.info(
+            "entry_points_inventoried",
+            repository_id=repository.id,
+            entry_points=len(entry_points),
+            unchecked_privileged=unchecked,
+        )
+        return {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "cloned": cloned,
+            "by_kind": dict(Counter(e["kind"].value for e in entry_points)),
+            "unchecked_privileged": unchecked,
+            "entry_points": [e for e in entry_points if e["kind"] != EntryPointKind.CLI_COMMAND],
+            "cli_entry_points": [e for e in entry_points if e["kind"] == EntryPointKind.CLI_COMMAND],
+        }
+
+    def services(self, repository_id: int | None = None, unchecked_only: bool = False) -> list[dict]:
+        """Non-HTTP entry point inventory per service (repository).
+
+        Args:
+            repository_id: Restrict to one repository
+            unchecked_only: Keep only services with privileged entry points that check nothing
+
+        Returns:
+            Per-repository entry points and CLI commands with the checks and operations found in them
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        reports = [self._inventory(repository) for repository in repositories]
+        if unchecked_only:
+            reports = [r for r in reports if r["unchecked_privileged"]]
+        return reports
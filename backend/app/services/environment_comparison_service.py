@@ -0,0 +1,497 @@
+"""Compare mined policies across deployment environments.
+
+A scan is tagged with the environment it represents: from the branch of the
+checkout ("release/staging"), from the namespace of its deployment manifests
+("payments-prod"), or by hand. Each tag stores a snapshot of the endpoint
+model and of the auth checks the code switches on or off with an environment
+check, such as
+
+    if (process.env.NODE_ENV !== "production") { app.use(authenticate) }
+
+Comparing two environments' snapshots reports where the target is weaker
+than the base: authentication or roles dropped, conditions removed, new
+unprotected endpoints, and auth disabled only in the target environment.
+Relaxations that exist only in prod are the ones code review rarely catches.
+"""
+
+import re
+from collections import Counter
+from collections.abc import Iterator
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.models.scan_environment import EnvironmentSource, ScanEnvironment
+from app.models.scan_progress import ScanProgress
+from app.services.auth_mechanism_service import rule_mechanisms
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, SKIPPED_DIRECTORIES
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.endpoint_risk_service import SEVERITY_ORDER
+from app.services.k8s_manifest_service import ManifestDocument, load_manifests
+from app.services.stable_identity_service import endpoint_id
+
+logger = structlog.get_logger(__name__)
+
+# Names teams use for the standard environments
+ENVIRONMENT_ALIASES = {
+    "prod": "prod",
+    "production": "prod",
+    "prd": "prod",
+    "live": "prod",
+    "staging": "staging",
+    "stage": "staging",
+    "stg": "staging",
+    "uat": "staging",
+    "preprod": "staging",
+    "dev": "dev",
+    "develop": "dev",
+    "development": "dev",
+    "local": "dev",
+    "test": "test",
+}
+
+# Environments an environment-gated check is evaluated against
+STANDARD_ENVIRONMENTS = ["dev", "staging", "prod"]
+
+# A reference to the runtime environment in code
+ENV_REFERENCE = re.compile(
+    r"NODE_ENV|RAILS_ENV|APP_ENV|FLASK_ENV|DJANGO_ENV|ASPNETCORE_ENVIRONMENT|"
+    r"\bENV(?:IRONMENT)?\b|\benv(?:ironment)?\b|Rails\.env|getenv|environ\b|@Profile|\bIs(?:Production|Development|Staging)\("
+)
+
+# An environment named in a condition
+ENV_NAME = re.compile(
+    r"""['"]!?(prod|production|prd|live|staging|stage|stg|uat|preprod|dev|develop|development|local|test)['"]"""
+    r"""|\.(production|development|staging|test)\?|\bIs(Production|Development|Staging)\(""",
+    re.IGNORECASE,
+)
+CONDITION = re.compile(r"\b(?:if|elif|elsif|unless|when)\b|\?\s|@Profile")
+NEGATION = re.compile(r"!==?|!=|\bnot\b|\bunless\b|!\s*[\w.]*Is\w+\(|['\"]!|!\s*Rails")
+
+# Code that switches authentication or authorization off
+DISABLE_AUTH = re.compile(
+    r"\b(?:skip|disable|bypass|no)[_-]?auth\w*|\bauth\w*\s*[:=]\s*(?:false|0|none|nil|null)\b|permitAll\(\)|AllowAnonymous"
+    r"|\bverify\w*\s*[:=]\s*(?:false|0)\b|(?:authenticat|authoriz)\w*\s*[:=]\s*(?:false|0|none|nil|null)\b",
+    re.IGNORECASE,
+)
+
+# Code that installs an authentication or authorization check
+ENABLE_AUTH = re.compile(
+    r"\b(?:app|router|server|api)\.(?:use|register)\([^)\n]{0,200}(?:auth|jwt|passport|session|guard|token)"
+    r"|\b(?:require|ensure|verify|check)_?(?:auth|login|token|jwt|role|session)\w*|login_required|authenticate\w*\(|isAuthenticated"
+    r"|\bauth(?:entication)?_?middleware",
+    re.IGNORECASE,
+)
+
+# Lines of a conditional's body inspected for auth code
+GATE_BODY_LINES = 3
+MAX_SOURCE_BYTES = 512 * 1024
+MAX_LINE_LENGTH = 500
+
+RELAXATION_SEVERITY = {
+    "authentication_removed": "critical",
+    "environment_gated": "critical",
+    "roles_removed": "high",
+    "unprotected_endpoint_added": "high",
+    "roles_broadened": "medium",
+    "conditions_removed": "medium",
+}
+
+
+def normalize_environment(name: str) -> str:
+    """Standard environment name for an alias, or the lowercased name."""
+    name = name.strip().lower()
+    return ENVIRONMENT_ALIASES.get(name, name)
+
+
+def _named_environment(name: str) -> str | None:
+    """Environment a branch segment or namespace names, e.g. "deploy-prod"."""
+    name = name.strip().lower()
+    if name in ENVIRONMENT_ALIASES:
+        return ENVIRONMENT_ALIASES[name]
+    tokens = re.split(r"[-_.]", name)
+    return ENVIRONMENT_ALIASES.get(tokens[-1]) if len(tokens) > 1 else None
+
+
+def branch_environment(branch: str) -> str | None:
+    """Environment a branch deploys to, judged by its last path segment."""
+    return _named_environment(branch.rsplit("/", 1)[-1])
+
+
+def checkout_branch(root: Path) -> str | None:
+    """Branch checked out in a clone, or None when detached or not a checkout."""
+    head = root / ".git" / "HEAD"
+    if not head.is_file():
+        return None
+    ref = head.read_text(encoding="utf-8", errors="ignore").strip()
+    return ref.removeprefix("ref: refs/heads/") if ref.startswith("ref: refs/heads/") else None
+
+
+def manifest_environment(documents: list[ManifestDocument]) -> tuple[str, str] | None:
+    """Environment named by the namespaces of a clone's deployment manifests.
+
+    Returns:
+        (environment, manifest path), or None unless every namespace that
+        names an environment names the same one
+    """
+    found: dict[str, str] = {}
+    for document in documents:
+        namespace = document.metadata.get("namespace")
+        environment = _named_environment(str(namespace)) if namespace else None
+        if environment:
+            found.setdefault(environment, document.file_path)
+    return next(iter(found.items())) if len(found) == 1 else None
+
+
+def _indent(line: str) -> int:
+    return len(line) - len(line.lstrip())
+
+
+def find_gated_checks(file_path: str, text: str) -> list[dict]:
+    """Find auth checks switched on or off by an environment check.
+
+    A disabled check ("skipAuth", "AUTH_ENABLED = False") relaxes the
+    environments the condition selects; a check that is only installed under
+    the condition relaxes every other environment.
+
+    Args:
+        file_path: Repository-relative path
+        text: Source code
+
+    Returns:
+        Per check: location, the condition, kind (disabled or conditional),
+        and the environments that run without it
+    """
+    checks = []
+    lines = text.splitlines()
+    for index, line in enumerate(lines):
+        if len(line) > MAX_LINE_LENGTH or not ENV_REFERENCE.search(line) or not CONDITION.search(line):
+            continue
+        names = {normalize_environment(next(g for g in m.groups() if g)) for m in ENV_NAME.finditer(line)}
+        if not names:
+            continue
+        body = [line]
+        for following in lines[index + 1 : index + 1 + GATE_BODY_LINES]:
+            if following.strip() and _indent(following) <= _indent(line) and "@Profile" not in line:
+                break
+            body.append(following[:MAX_LINE_LENGTH])
+        window = "\n".join(body)
+
+        known = STANDARD_ENVIRONMENTS + sorted(names - set(STANDARD_ENVIRONMENTS))
+        selected = [e for e in known if (e in names) != bool(NEGATION.search(line))]
+        if DISABLE_AUTH.search(window):
+            kind, relaxed = "disabled", selected
+        elif ENABLE_AUTH.search(window):
+            kind, relaxed = "conditional", [e for e in known if e not in selected]
+        else:
+            continue
+        if relaxed:
+            checks.append(
+                {
+                    "file_path": file_path,
+                    "line_start": index + 1,
+                    "condition": line.strip()[:200],
+                    "kind": kind,
+                    "relaxed_in": relaxed,
+                    "snippet": window.strip()[:1000],
+                }
+            )
+    return checks
+
+
+def scan_clone(root: Path) -> list[dict]:
+    """Environment-gated auth checks in a repository clone, in path order."""
+    checks: list[dict] = []
+    if not root.is_dir():
+        return checks
+    for path in sorted(root.rglob("*")):
+        if path.suffix not in ROUTE_FILE_EXTENSIONS or not path.is_file():
+            continue
+        relative = path.relative_to(root)
+        if SKIPPED_DIRECTORIES & set(relative.parts) or path.stat().st_size > MAX_SOURCE_BYTES:
+            continue
+        checks.extend(find_gated_checks(relative.as_posix(), path.read_text(encoding="utf-8", errors="ignore")))
+    return checks
+
+
+def gated_checks(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding a clone's environment-gated auth checks."""
+    yield from scan_clone(Path(root))
+
+
+def deployed_environment(root: str) -> Iterator[list[str] | None]:
+    """Sandbox pass yielding the (environment, source, ref) a checkout deploys to, or None.
+
+    The branch name wins; the deployment manifests are only read when it names no environment.
+    """
+    branch = checkout_branch(Path(root))
+    if branch and branch_environment(branch):
+        yield [branch_environment(branch), EnvironmentSource.BRANCH, branch]
+        return
+    found = manifest_environment(load_manifests(Path(root)))
+    yield [found[0], EnvironmentSource.MANIFEST, found[1]] if found else None
+
+
+def snapshot_endpoints(policies: list[Policy]) -> list[dict]:
+    """The endpoint model of a set of policies, as stored on an environment tag."""
+    by_id = {p.id: p for p in policies}
+    return [
+        {
+            "endpoint_id": endpoint_id(rule.method, rule.path),
+            "endpoint": rule.key,
+            "roles": sorted(rule.roles),
+            "requires_authentication": rule.requires_authentication,
+            "conditions": sorted(set(rule.conditions)),
+            "mechanism": rule_mechanisms(rule, by_id)[0].value,
+            "policy_ids": rule.policy_ids,
+        }
+        for rule in EndpointMappingService.map_policies(policies)
+    ]
+
+
+def _relaxation(kind: str, endpoint: str | None, message: str, target: str, **details) -> dict:
+    """One relaxation of the target environment."""
+    return {
+        "kind": kind,
+        "severity": RELAXATION_SEVERITY[kind],
+        "endpoint": endpoint,
+        "message": message,
+        "prod_only": target == "prod",
+        **details,
+    }
+
+
+def compare_snapshots(base_tag: ScanEnvironment, target_tag: ScanEnvironment) -> list[dict]:
+    """Relaxations of the target environment relative to the base.
+
+    Endpoints are matched by stable endpoint ID, so a path parameter renamed
+    between branches still pairs up. An environment-gated check counts when
+    it relaxes the target but not the base.
+
+    Args:
+        base_tag: Tag of the environment the target should match
+        target_tag: Tag of the environment checked for relaxations
+
+    Returns:
+        Relaxations, most severe first; prod_only marks those of prod
+    """
+    base, target = base_tag.environment, target_tag.environment
+    relaxations = []
+    baseline = {e["endpoint_id"]: e for e in base_tag.endpoints or []}
+    for current in target_tag.endpoints or []:
+        previous = baseline.get(current["endpoint_id"])
+        key = current["endpoint"]
+        if previous is None:
+            if not current["requires_authentication"]:
+                relaxations.append(
+                    _relaxation("unprotected_endpoint_added", key, f"{key} exists only in {target} and allows anonymous callers", target)
+                )
+            continue
+        if previous["requires_authentication"] and not current["requires_authentication"]:
+            relaxations.append(
+                _relaxation("authentication_removed", key, f"{key} requires authentication in {base} but not in {target}", target)
+            )
+        elif previous["roles"] and not current["roles"]:
+            relaxations.append(
+                _relaxation(
+                    "roles_removed",
+                    key,
+                    f"{key} requires {', '.join(previous['roles'])} in {base} but any authenticated user in {target}",
+                    target,
+                )
+            )
+        elif previous["roles"] and set(current["roles"]) - set(previous["roles"]):
+            added = sorted(set(current["roles"]) - set(previous["roles"]))
+            relaxations.append(
+                _relaxation("roles_broadened", key, f"{key} also admits {', '.join(added)} in {target}", target, roles_added=added)
+            )
+        removed = sorted(set(previous["conditions"]) - set(current["conditions"]))
+        if removed:
+            relaxations.append(
+                _relaxation(
+                    "conditions_removed", key, f"{key} drops {len(removed)} condition(s) in {target}", target, conditions_removed=removed
+                )
+            )
+
+    for check in target_tag.gated_checks or []:
+        if target not in check["relaxed_in"] or base in check["relaxed_in"]:
+            continue
+        verb = "disables" if check["kind"] == "disabled" else "skips"
+        relaxations.append(
+            _relaxation(
+                "environment_gated",
+                None,
+                f"{check['file_path']}:{check['line_start']} {verb} an auth check in {target} but not in {base}",
+                target,
+                file_path=check["file_path"],
+                line_start=check["line_start"],
+                condition=check["condition"],
+            )
+        )
+    relaxations.sort(key=lambda r: (SEVERITY_ORDER[r["severity"]], r["endpoint"] or "", r.get("file_path") or ""))
+    return relaxations
+
+
+class EnvironmentComparisonService:
+    """Tags scans with environments and compares policies across them."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def _require_repository(self, repository_id: int) -> Repository:
+        """Load a repository or raise ValueError."""
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def detect_environment(self, repository_id: int) -> tuple[str, str, str] | None:
+        """Environment of a repository's current checkout.
+
+        Returns:
+            (environment, source, ref) from the branch name, else from the
+            deployment manifests, or None when neither names an environment
+        """
+        root = self.clone_dir / str(repository_id)
+        if not root.is_dir():
+            return None
+        (found,) = run_pass(root, deployed_environment)
+        return tuple(found) if found else None
+
+    def tag_scan(
+        self,
+        scan_id: int,
+        environment: str,
+        ref: str | None = None,
+        source: str = EnvironmentSource.MANUAL,
+        tagged_by: str | None = None,
+    ) -> ScanEnvironment:
+        """Tag a scan with an environment and snapshot what it observed.
+
+        Only a repository's latest scan can be tagged, since the snapshot is
+        taken from the policies and checkout as they are now. Tagging a scan
+        again replaces its tag.
+
+        Args:
+            scan_id: Scan ID
+            environment: Environment name; aliases like "production" become "prod"
+            ref: Branch or manifest the environment came from
+            source: branch, manifest, or manual
+            tagged_by: Tagging user's email
+
+        Returns:
+            The scan's environment tag
+
+        Raises:
+            ValueError: If the scan does not exist or is not the repository's latest
+        """
+        environment = normalize_environment(environment)
+        if not environment:
+            raise ValueError("Environment is required")
+        scan = self._query(ScanProgress).filter(ScanProgress.id == scan_id).first()
+        if scan is None:
+            raise ValueError(f"Scan {scan_id} not found")
+        latest = self._query(ScanProgress).filter(ScanProgress.repository_id == scan.repository_id).order_by(ScanProgress.id.desc()).first()
+        if latest is not None and latest.id != scan.id:
+            raise ValueError(f"Only the latest scan of repository {scan.repository_id} can be tagged")
+
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=scan.repository_id)
+        tag = self._query(ScanEnvironment).filter(ScanEnvironment.scan_id == scan_id).first()
+        if tag is None:
+            tag = ScanEnvironment(tenant_id=self.tenant_id, repository_id=scan.repository_id, scan_id=scan_id)
+            self.db.add(tag)
+        tag.environment, tag.source, tag.ref, tag.tagged_by = environment, source, ref, tagged_by
+        tag.endpoints = snapshot_endpoints(policies)
+        root = self.clone_dir / str(scan.repository_id)
+        tag.gated_checks = list(run_pass(root, gated_checks)) if root.is_dir() else []
+        self.db.commit()
+        self.db.refresh(tag)
+        logger.info(
+            "scan_environment_tagged",
+            scan_id=scan_id,
+            environment=environment,
+            source=source,
+            endpoints=len(tag.endpoints),
+            gated_checks=len(tag.gated_checks),
+        )
+        return tag
+
+    def auto_tag(self, repository_id: int, scan_id: int) -> ScanEnvironment | None:
+        """Tag a finished scan with the environment its checkout names, if any."""
+        detected = self.detect_environment(repository_id)
+        if detected is None:
+            return None
+        environment, source, ref = detected
+        return self.tag_scan(scan_id, environment, ref=ref, source=source)
+
+    def environments(self, repository_id: int) -> list[ScanEnvironment]:
+        """Latest tag of each environment of a repository, most recent first.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        self._require_repository(repository_id)
+        tags = (
+            self._query(ScanEnvironment)
+            .filter(ScanEnvironment.repository_id == repository_id)
+            .order_by(ScanEnvironment.id.desc())
+            .all()
+        )
+        latest: dict[str, ScanEnvironment] = {}
+        for tag in tags:
+            latest.setdefault(tag.environment, tag)
+        return list(latest.values())
+
+    def compare(self, repository_id: int, base: str = "staging", target: str = "prod") -> dict:
+        """Compare the latest scans of two environments.
+
+        Args:
+            repository_id: Repository ID
+            base: Environment the target should be at least as strict as
+            target: Environment checked for relaxations
+
+        Returns:
+            Both tags, endpoints present on one side only, and relaxations of
+            the target, most severe first
+
+        Raises:
+            ValueError: If the repository or either environment's tag does not exist
+        """
+        base, target = normalize_environment(base), normalize_environment(target)
+        if base == target:
+            raise ValueError("Compare two different environments")
+        tags = {tag.environment: tag for tag in self.environments(repository_id)}
+        missing = [name for name in (base, target) if name not in tags]
+        if missing:
+            raise ValueError(f"No scan of repository {repository_id} is tagged {', '.join(missing)}")
+        base_tag, target_tag = tags[base], tags[target]
+
+        relaxations = compare_snapshots(base_tag, target_tag)
+        base_ids = {e["endpoint_id"]: e["endpoint"] for e in base_tag.endpoints or []}
+        target_ids = {e["endpoint_id"]: e["endpoint"] for e in target_tag.endpoints or []}
+        logger.info("environments_compared", repository_id=repository_id, base=base, target=target, relaxations=len(relaxations))
+        return {
+            "repository_id": repository_id,
+            "base": base_tag,
+            "target": target_tag,
+            "only_in_base": sorted(base_ids[i] for i in base_ids.keys() - target_ids.keys()),
+            "only_in_target": sorted(target_ids[i] for i in target_ids.keys() - base_ids.keys()),
+            "relaxation_counts": dict(Counter(r["severity"] for r in relaxations)),
+            "relaxations": relaxations,
+        }
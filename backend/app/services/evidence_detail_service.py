@@ -0,0 +1,292 @@
+"""Service for rendering evidence as highlight-ready source regions.
+
+The UI shows evidence as highlighted code with a few lines of surrounding
+context. Rather than shipping whole source files (or having the browser
+re-fetch them from the git host), this service cuts structured regions out
+of the scanned clone: the evidence range plus bounded context, with line
+numbers and a language hint for the syntax highlighter. When the clone is
+gone, the stored snippet is returned on its own.
+"""
+
+from collections import defaultdict
+from dataclasses import asdict, dataclass, field
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.policy import Evidence, Policy
+
+logger = structlog.get_logger(__name__)
+
+DEFAULT_CONTEXT_LINES = 5
+MAX_CONTEXT_LINES = 50
+
+# Regions longer than this are cut short so a bad line range never ships a whole file
+MAX_REGION_LINES = 400
+
+# File extension -> highlighter language ID (Prism/highlight.js names)
+LANGUAGES = {
+    ".py": "python",
+    ".java": "java",
+    ".cs": "csharp",
+    ".js": "javascript",
+    ".jsx": "jsx",
+    ".ts": "typescript",
+    ".tsx": "tsx",
+    ".go": "go",
+    ".rb": "ruby",
+    ".php": "php",
+    ".scala": "scala",
+    ".kt": "kotlin",
+    ".vue": "markup",
+    ".html": "markup",
+    ".sql": "sql",
+    ".cbl": "cobol",
+    ".cob": "cobol",
+    ".cpy": "cobol",
+    ".json": "json",
+    ".yaml": "yaml",
+    ".yml": "yaml",
+    ".xml": "xml",
+}
+
+
+class RegionSource:
+    """Where region text came from."""
+
+    REPOSITORY = "repository"  # cut from the scanned clone, with context
+    SNIPPET = "snippet"  # stored evidence snippet only
+
+
+def language_for(file_path: str) -> str:
+    """Highlighter language for a file path.
+
+    Args:
+        file_path: Source file path
+
+    Returns:
+        Language ID, or "plaintext" if unknown
+    """
+    return LANGUAGES.get(Path(file_path).suffix.lower(), "plaintext")
+
+
+@dataclass
+class Highlight:
+    """An evidence range inside a region."""
+
+    evidence_id: int
+    start_line: int
+    end_line: int
+
+
+@dataclass
+class SourceRegion:
+    """A contiguous, numbered slice of a source file."""
+
+    start_line: int
+    end_line: int
+    highlights: list[Highlight]
+    lines: list[dict] = field(default_factory=list)
+    truncated: bool = False
+
+
+class EvidenceDetailService:
+    """Builds structured source regions for evidence."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _evidence_query(self):
+        """Query evidence joined to its policy and scoped to the current tenant."""
+        query = self.db.query(Evidence, Policy).join(Policy, Evidence.policy_id == Policy.id)
+        if self.tenant_id:
+            query = query.filter(Policy.tenant_id == self.tenant_id)
+        return query
+
+    def get_evidence(self, evidence_id: int, context_lines: int = DEFAULT_CONTEXT_LINES) -> dict:
+        """Get one evidence item as a highlight-ready region.
+
+        Args:
+            evidence_id: Evidence ID
+            context_lines: Lines of context before and after the evidence range
+
+        Returns:
+            Evidence detail with file, language, and regions
+
+        Raises:
+            ValueError: If the evidence does not exist
+        """
+        row = self._evidence_query().filter(Evidence.id == evidence_id).first()
+        if not row:
+            raise ValueError(f"Evidence {evidence_id} not found")
+        evidence, policy = row
+
+        file_detail = self.build_file(policy.repository_id, evidence.file_path, [evidence], context_lines)
+        return {
+            "evidence_id": evidence.id,
+            "policy_id": policy.id,
+            "repository_id": policy.repository_id,
+            "line_start": evidence.line_start,
+            "line_end": evidence.line_end,
+            **file_detail,
+        }
+
+    def get_policy_evidence(self, policy_id: int, context_lines: int = DEFAULT_CONTEXT_LINES) -> dict:
+        """Get all evidence for a policy, grouped by file.
+
+        Evidence ranges in the same file whose context windows overlap are
+        merged into one region with several highlights.
+
+        Args:
+            policy_id: Policy ID
+            context_lines: Lines of context before and after each evidence range
+
+        Returns:
+            Policy ID and per-file regions
+
+        Raises:
+            ValueError: If the policy does not exist
+        """
+        query = self.db.query(Policy).filter(Policy.id == policy_id)
+        if self.tenant_id:
+            query = query.filter(Policy.tenant_id == self.tenant_id)
+        policy = query.first()
+        if not policy:
+            raise ValueError(f"Policy {policy_id} not found")
+
+        by_file: dict[str, list[Evidence]] = defaultdict(list)
+        for evidence in self.db.query(Evidence).filter(Evidence.policy_id == policy_id).all():
+            by_file[evidence.file_path].append(evidence)
+
+        return {
+            "policy_id": policy.id,
+            "repository_id": policy.repository_id,
+            "files": [
+                self.build_file(policy.repository_id, file_path, items, context_lines)
+                for file_path, items in sorted(by_file.items())
+            ],
+        }
+
+    def read_lines(self, repository_id: int, file_path: str) -> list[str] | None:
+        """Read a source file from the scanned clone.
+
+        Args:
+            repository_id: Repository ID
+            file_path: Path relative to the repository root
+
+        Returns:
+            File lines, or None if the clone or file is unavailable
+        """
+        repo_root = (self.clone_dir / str(repository_id)).resolve()
+        path = (repo_root / file_path).resolve()
+        if not path.is_relative_to(repo_root) or not path.is_file():
+            return None
+        try:
+            return path.read_text(encoding="utf-8", errors="replace").splitlines()
+        except OSError as e:
+            logger.warning("evidence_source_unreadable", path=str(path), error=str(e))
+            return None
+
+    def build_file(
+        self, repository_id: int, file_path: str, evidence: list[Evidence], context_lines: int
+    ) -> dict:
+        """Build the regions for the evidence in one file.
+
+        Args:
+            repository_id: Repository ID
+            file_path: Source file path
+            evidence: Evidence items in this file
+            context_lines: Lines of context around each range
+
+        Returns:
+            File path, language, source, total lines (if known), and regions
+        """
+        context_lines = max(0, min(context_lines, MAX_CONTEXT_LINES))
+        lines = self.read_lines(repository_id, file_path)
+
+        if lines is None:
+            regions = [self.snippet_region(e) for e in sorted(evidence, key=lambda e: e.line_start)]
+            source = RegionSource.SNIPPET
+        else:
+            regions = self.build_regions(lines, evidence, context_lines)
+            source = RegionSource.REPOSITORY
+
+        return {
+            "file_path": file_path,
+            "language": language_for(file_path),
+            "source": source,
+            "total_lines": len(lines) if lines is not None else None,
+            "regions": [asdict(r) for r in regions],
+        }
+
+    @staticmethod
+    def build_regions(lines: list[str], evidence: list[Evidence], context_lines: int) -> list[SourceRegion]:
+        """Cut context-padded regions out of a file, merging overlaps.
+
+        Args:
+            lines: File lines
+            evidence: Evidence items in this file
+            context_lines: Lines of context around each range
+
+        Returns:
+            Regions in file order
+        """
+        total = len(lines)
+        windows = []
+        for item in sorted(evidence, key=lambda e: (e.line_start, e.line_end)):
+            start = min(max(1, item.line_start), max(total, 1))
+            end = min(max(start, item.line_end), max(total, 1))
+            windows.append(
+                (
+                    max(1, start - context_lines),
+                    min(total, end + context_lines),
+                    Highlight(evidence_id=item.id, start_line=start, end_line=end),
+                )
+            )
+
+        regions: list[SourceRegion] = []
+        for start, end, highlight in windows:
+            if regions and start <= regions[-1].end_line + 1:
+                regions[-1].end_line = max(regions[-1].end_line, end)
+                regions[-1].highlights.append(highlight)
+            else:
+                regions.append(SourceRegion(start_line=start, end_line=end, highlights=[highlight]))
+
+        for region in regions:
+            if region.end_line - region.start_line + 1 > MAX_REGION_LINES:
+                region.end_line = region.start_line + MAX_REGION_LINES - 1
+                region.truncated = True
+            region.lines = [
+                {"number": number, "text": lines[number - 1]}
+                for number in range(region.start_line, region.end_line + 1)
+                if number <= total
+            ]
+        return regions
+
+    @staticmethod
+    def snippet_region(evidence: Evidence) -> SourceRegion:
+        """Build a region from the stored snippet when the source is unavailable.
+
+        Args:
+            evidence: Evidence item
+
+        Returns:
+            Region numbered from the evidence start line, without context
+        """
+        snippet_lines = (evidence.code_snippet or "").splitlines()
+        truncated = len(snippet_lines) > MAX_REGION_LINES
+        snippet_lines = snippet_lines[:MAX_REGION_LINES]
+        start = evidence.line_start
+        end = start + max(len(snippet_lines), 1) - 1
+        return SourceRegion(
+            start_line=start,
+            end_line=end,
+            highlights=[Highlight(evidence_id=evidence.id, start_line=start, end_line=end)],
+            lines=[{"number": start + i, "text": text} for i, text in enumerate(snippet_lines)],
+            truncated=truncated,
+        )
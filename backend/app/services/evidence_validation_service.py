@@ -6,6 +6,7 @@ from pathlib import Path
 from sqlalchemy.orm import Session
 
 from app.models.policy import Evidence, ValidationStatus
+from app.services.redaction_service import RedactionService
 
 logger = logging.getLogger(__name__)
 
@@ -84,8 +85,9 @@ class EvidenceValidationService:
             stored_snippet = evidence.code_snippet.rstrip()
             actual_snippet = actual_code.rstrip()
 
-            # Compare code snippets
-            if stored_snippet == actual_snippet:
+            # Compare code snippets. Snippets extracted from redacted scan
+            # content carry redaction markers, so also compare the redacted source.
+            if stored_snippet in (actual_snippet, RedactionService().redact(actual_snippet).text):
                 logger.info(f"Evidence {evidence_id} validated successfully")
                 evidence.validation_status = ValidationStatus.VALID
                 evidence.validation_error = None
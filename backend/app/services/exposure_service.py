@@ -0,0 +1,627 @@
+"""Service for inferring whether endpoints are internet-facing or internal-only.
+
+The same missing check matters far more on an endpoint the internet can
+reach than on one only other services inside the network can call. Exposure
+is decided outside the code, by what the repository deploys in front of it:
+Kubernetes Ingresses, LoadBalancer Services and their internal-scheme
+annotations, Istio and Gateway API routes, API gateway definitions
+(Serverless, SAM/CloudFormation, Kong declarative config), and Terraform
+load balancers. This service reads those signals from a repository's clone
+and resolves each mapped endpoint to internet_facing, internal_only, or
+unknown, so endpoint risk and finding severity can be weighted by it.
+"""
+
+import ipaddress
+import re
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass, field
+from enum import Enum
+from pathlib import Path
+
+import structlog
+import yaml
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.cors_csrf_service import scope_matches
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import first_line
+from app.services.k8s_manifest_service import ManifestDocument, helm_chart_name, parse_documents, render_helm_template
+
+logger = structlog.get_logger(__name__)
+
+# Deployment and gateway definitions are small; anything larger is not config
+MAX_CONFIG_BYTES = 1024 * 1024
+
+
+class Exposure(str, Enum):
+    """Who can reach an endpoint over the network."""
+
+    INTERNET_FACING = "internet_facing"
+    INTERNAL_ONLY = "internal_only"
+    UNKNOWN = "unknown"  # Nothing in the repository says how the service is exposed
+
+
+# Most exposed first
+EXPOSURE_ORDER = {Exposure.INTERNET_FACING: 0, Exposure.UNKNOWN: 1, Exposure.INTERNAL_ONLY: 2}
+
+
+class ExposureSource(str, Enum):
+    """Where an exposure signal was read from."""
+
+    INGRESS = "ingress"
+    LOAD_BALANCER = "load_balancer"
+    CLUSTER_SERVICE = "cluster_service"  # ClusterIP Service with no external exposure
+    MESH_GATEWAY = "mesh_gateway"  # Istio Gateway/VirtualService, Gateway API HTTPRoute
+    API_GATEWAY = "api_gateway"  # Serverless, SAM/CloudFormation, Kong
+    TERRAFORM = "terraform"
+
+
+# Host suffixes that only resolve inside a private network
+INTERNAL_HOST = re.compile(r"(?:\.internal|\.local|\.svc|\.cluster\.local|\.corp|\.intranet|\.lan|\.private)$", re.IGNORECASE)
+INTERNAL_NAME = re.compile(r"internal|private|intranet", re.IGNORECASE)
+
+# Annotations (key, value) that put a cloud load balancer on a private network
+INTERNAL_LB_ANNOTATIONS = {
+    "service.beta.kubernetes.io/aws-load-balancer-internal": {"true", "0.0.0.0/0"},
+    "service.beta.kubernetes.io/aws-load-balancer-scheme": {"internal"},
+    "service.beta.kubernetes.io/azure-load-balancer-internal": {"true"},
+    "networking.gke.io/load-balancer-type": {"internal"},
+    "cloud.google.com/load-balancer-type": {"internal"},
+    "service.beta.kubernetes.io/oci-load-balancer-internal": {"true"},
+    "alb.ingress.kubernetes.io/scheme": {"internal"},
+}
+SOURCE_RANGE_ANNOTATIONS = (
+    "nginx.ingress.kubernetes.io/whitelist-source-range",
+    "nginx.ingress.kubernetes.io/allowlist-source-range",
+    "alb.ingress.kubernetes.io/inbound-cidrs",
+)
+
+TERRAFORM_RESOURCE = re.compile(r'^resource\s+"(aws_lb|aws_alb|aws_elb|aws_api_gateway_rest_api)"\s+"([\w-]+)"\s*\{', re.MULTILINE)
+TERRAFORM_INTERNAL = re.compile(r"^\s*internal\s*=\s*(true|false)", re.MULTILINE)
+TERRAFORM_PRIVATE_ENDPOINT = re.compile(r'types\s*=\s*\[\s*"PRIVATE"', re.IGNORECASE)
+
+
+@dataclass
+class ExposureSignal:
+    """A deployment or gateway setting that decides who can reach a service."""
+
+    source: ExposureSource
+    exposure: Exposure
+    file_path: str
+    line_start: int
+    name: str
+    scopes: list[str] = field(default_factory=list)  # Path patterns it routes; empty means the whole service
+    hosts: list[str] = field(default_factory=list)
+    reason: str = ""
+
+
+def _private_ranges(value: str) -> bool:
+    """Whether every CIDR in a comma-separated allowlist is a private network."""
+    ranges = [part.strip() for part in value.split(",") if part.strip()]
+    try:
+        return bool(ranges) and all(ipaddress.ip_network(r, strict=False).is_private for r in ranges)
+    except ValueError:
+        return False
+
+
+def _internal_hosts(hosts: list[str]) -> bool:
+    """Whether all hosts are private DNS names (no hosts means any host)."""
+    return bool(hosts) and all(INTERNAL_HOST.search(h) for h in hosts)
+
+
+def _scope(path: str | None, exact: bool = False) -> str | None:
+    """Path pattern for a routed path; None when it routes everything."""
+    path = (path or "/").rstrip("*") or "/"
+    if path in ("/", ""):
+        return None
+    return path if exact else path.rstrip("/") + "/**"
+
+
+def _annotation_internal(annotations: dict) -> str | None:
+    """Annotation entry marking a resource internal, if any."""
+    for key, values in INTERNAL_LB_ANNOTATIONS.items():
+        if str(annotations.get(key, "")).lower() in values:
+            return f"{key}={annotations[key]}"
+    for key in SOURCE_RANGE_ANNOTATIONS:
+        if key in annotations and _private_ranges(str(annotations[key])):
+            return f"{key}={annotations[key]}"
+    return None
+
+
+def _ingress_signal(document: ManifestDocument) -> ExposureSignal:
+    """Exposure of an Ingress and the paths it routes."""
+    spec = document.body.get("spec") or {}
+    annotations = document.metadata.get("annotations") or {}
+    scopes, hosts = [], []
+    service_wide = False
+    for rule in spec.get("rules") or []:
+        if not isinstance(rule, dict):
+            continue
+        if rule.get("host"):
+            hosts.append(str(rule["host"]))
+        for path in (rule.get("http") or {}).get("paths") or []:
+            scope = _scope(path.get("path"), exact=path.get("pathType") == "Exact")
+            if scope is None:
+                service_wide = True
+            elif scope not in scopes:
+                scopes.append(scope)
+    if spec.get("defaultBackend") or spec.get("backend"):
+        service_wide = True
+    ingress_class = str(spec.get("ingressClassName") or annotations.get("kubernetes.io/ingress.class") or "")
+    reason = _annotation_internal(annotations)
+    if reason is None and INTERNAL_NAME.search(ingress_class):
+        reason = f"ingress class {ingress_class}"
+    if reason is None and _internal_hosts(hosts):
+        reason = f"private hosts {', '.join(hosts)}"
+    exposure = Exposure.INTERNAL_ONLY if reason else Exposure.INTERNET_FACING
+    return ExposureSignal(
+        source=ExposureSource.INGRESS,
+        exposure=exposure,
+        file_path=document.file_path,
+        line_start=document.line_start,
+        name=document.name,
+        scopes=[] if service_wide else scopes,
+        hosts=hosts,
+        reason=reason or f"Ingress {document.name}" + (f" for {', '.join(hosts)}" if hosts else ""),
+    )
+
+
+def _service_signal(document: ManifestDocument) -> ExposureSignal | None:
+    """Exposure of a Service by its type and load balancer annotations."""
+    spec = document.body.get("spec") or {}
+    service_type = str(spec.get("type") or "ClusterIP")
+    annotations = document.metadata.get("annotations") or {}
+    if service_type == "LoadBalancer":
+        reason = _annotation_internal(annotations)
+        ranges = spec.get("loadBalancerSourceRanges") or []
+        if reason is None and ranges and _private_ranges(",".join(map(str, ranges))):
+            reason = f"loadBalancerSourceRanges {', '.join(map(str, ranges))}"
+        return ExposureSignal(
+            source=ExposureSource.LOAD_BALANCER,
+            exposure=Exposure.INTERNAL_ONLY if reason else Exposure.INTERNET_FACING,
+            file_path=document.file_path,
+            line_start=document.line_start,
+            name=document.name,
+            reason=reason or f"LoadBalancer Service {document.name}",
+        )
+    if service_type == "ClusterIP":
+        return ExposureSignal(
+            source=ExposureSource.CLUSTER_SERVICE,
+            exposure=Exposure.INTERNAL_ONLY,
+            file_path=document.file_path,
+            line_start=document.line_start,
+            name=document.name,
+            reason=f"ClusterIP Service {document.name}",
+        )
+    return None
+
+
+def _route_scopes(matches: list) -> tuple[list[str], bool]:
+    """Path patterns of Istio or Gateway API route matches, and whether any routes everything."""
+    scopes, service_wide = [], not matches
+    for match in matches:
+        if not isinstance(match, dict):
+            continue
+        uri = match.get("uri") or {}
+        path = match.get("path") or {}
+        if uri:
+            scope = _scope(uri.get("prefix") or uri.get("exact") or uri.get("regex"), exact="exact" in uri)
+        elif path:
+            scope = _scope(path.get("value"), exact=path.get("type") == "Exact")
+        else:
+            scope = None
+        if scope is None:
+            service_wide = True
+        elif scope not in scopes:
+            scopes.append(scope)
+    return scopes, service_wide
+
+
+def _mesh_signal(document: ManifestDocument) -> ExposureSignal | None:
+    """Exposure of an Istio VirtualService bound to a gateway, or a Gateway API HTTPRoute."""
+    spec = document.body.get("spec") or {}
+    if document.kind == "VirtualService":
+        gateways = [str(g) for g in spec.get("gateways") or []]
+        if not gateways or gateways == ["mesh"]:
+            return None
+        routes = spec.get("http") or []
+        parents = gateways
+    elif document.kind == "HTTPRoute":
+        routes = spec.get("rules") or []
+        parents = [str((ref or {}).get("name")) for ref in spec.get("parentRefs") or [] if isinstance(ref, dict)]
+    else:
+        return None
+    hosts = [str(h) for h in spec.get("hosts") or spec.get("hostnames") or []]
+    scopes, service_wide = [], not routes
+    for route in routes:
+        if isinstance(route, dict):
+            route_scopes, wide = _route_scopes(route.get("match") or route.get("matches") or [])
+            scopes += [s for s in route_scopes if s not in scopes]
+            service_wide = service_wide or wide
+    internal_parent = next((p for p in parents if INTERNAL_NAME.search(p)), None)
+    reason = None
+    if internal_parent:
+        reason = f"gateway {internal_parent}"
+    elif _internal_hosts(hosts):
+        reason = f"private hosts {', '.join(hosts)}"
+    return ExposureSignal(
+        source=ExposureSource.MESH_GATEWAY,
+        exposure=Exposure.INTERNAL_ONLY if reason else Exposure.INTERNET_FACING,
+        file_path=document.file_path,
+        line_start=document.line_start,
+        name=document.name,
+        scopes=[] if service_wide else scopes,
+        hosts=hosts,
+        reason=reason or f"{document.kind} {document.name} via {', '.join(parents)}",
+    )
+
+
+def extract_manifest_signals(documents: list[ManifestDocument]) -> list[ExposureSignal]:
+    """Exposure signals from Kubernetes, Istio, and Gateway API manifests.
+
+    Args:
+        documents: Manifest documents
+
+    Returns:
+        Signals in document order
+    """
+    signals = []
+    for document in documents:
+        if document.kind == "Ingress":
+            signals.append(_ingress_signal(document))
+        elif document.kind == "Service":
+            signal = _service_signal(document)
+            if signal:
+                signals.append(signal)
+        elif document.kind in ("VirtualService", "HTTPRoute"):
+            signal = _mesh_signal(document)
+            if signal:
+                signals.append(signal)
+    return signals
+
+
+class _CloudFormationLoader(yaml.SafeLoader):
+    """Safe loader that reads CloudFormation intrinsic tags (!Ref, !Sub) as null."""
+
+
+_CloudFormationLoader.add_multi_constructor("!", lambda loader, suffix, node: None)
+
+
+def _yaml(text: str) -> object:
+    """Parse one YAML document, ignoring unparseable files."""
+    try:
+        return yaml.load(text, Loader=_CloudFormationLoader)  # noqa: S506 - SafeLoader subclass
+    except yaml.YAMLError:
+        return None
+
+
+def extract_gateway_signals(file_path: str, text: str) -> list[ExposureSignal]:
+    """Exposure signals from API gateway definitions and Terraform load balancers.
+
+    Args:
+        file_path: Path relative to the repository root
+        text: File content
+
+    Returns:
+        Signals for Serverless, SAM/CloudFormation, Kong, and Terraform resources
+    """
+    if file_path.endswith(".tf"):
+        return _terraform_signals(file_path, text)
+    config = _yaml(text)
+    if not isinstance(config, dict):
+        return []
+    if "functions" in config and isinstance(config.get("provider"), dict):
+        return _serverless_signals(file_path, text, config)
+    if isinstance(config.get("Resources"), dict):
+        return _cloudformation_signals(file_path, text, config["Resources"])
+    if "_format_version" in config and isinstance(config.get("services"), list):
+        return _kong_signals(file_path, text, config["services"])
+    return []
+
+
+def _serverless_signals(file_path: str, text: str, config: dict) -> list[ExposureSignal]:
+    """Serverless Framework HTTP events; a PRIVATE endpoint type keeps them in the VPC."""
+    private = str(config["provider"].get("endpointType", "")).upper() == "PRIVATE"
+    scopes = []
+    for function in (config.get("functions") or {}).values():
+        events = function.get("events") or [] if isinstance(function, dict) else []
+        for event in events:
+            if not isinstance(event, dict):
+                continue
+            # Events are either {path, method} mappings or "METHOD /path" strings
+            http = event.get("http") or event.get("httpApi")
+            path = http.get("path") if isinstance(http, dict) else str(http).split(" ", 1)[-1] if http else None
+            if path:
+                scope = _scope("/" + str(path).lstrip("/"), exact=True)
+                if scope and scope not in scopes:
+                    scopes.append(scope)
+    if not scopes:
+        return []
+    return [
+        ExposureSignal(
+            source=ExposureSource.API_GATEWAY,
+            exposure=Exposure.INTERNAL_ONLY if private else Exposure.INTERNET_FACING,
+            file_path=file_path,
+            line_start=first_line(text, "functions:"),
+            name=str(config.get("service") or "serverless"),
+            scopes=scopes,
+            reason="PRIVATE API Gateway endpoint" if private else "Serverless HTTP events",
+        )
+    ]
+
+
+def _cloudformation_signals(file_path: str, text: str, resources: dict) -> list[ExposureSignal]:
+    """SAM and CloudFormation API Gateway APIs and the function events routed through them."""
+    private = False
+    scopes = []
+    for name, resource in resources.items():
+        if not isinstance(resource, dict):
+            continue
+        kind = str(resource.get("Type") or "")
+        properties = resource.get("Properties") or {}
+        if kind in ("AWS::Serverless::Api", "AWS::ApiGateway::RestApi"):
+            endpoint = properties.get("EndpointConfiguration") or {}
+            types = endpoint.get("Types") if isinstance(endpoint, dict) else endpoint
+            private = private or "PRIVATE" in str(types).upper()
+        elif kind == "AWS::Serverless::Function":
+            for event in (properties.get("Events") or {}).values():
+                if isinstance(event, dict) and event.get("Type") in ("Api", "HttpApi"):
+                    path = (event.get("Properties") or {}).get("Path")
+                    scope = _scope(str(path), exact=True) if path else None
+                    if scope and scope not in scopes:
+                        scopes.append(scope)
+    if not scopes:
+        return []
+    return [
+        ExposureSignal(
+            source=ExposureSource.API_GATEWAY,
+            exposure=Exposure.INTERNAL_ONLY if private else Exposure.INTERNET_FACING,
+            file_path=file_path,
+            line_start=first_line(text, "Resources:"),
+            name="api-gateway",
+            scopes=scopes,
+            reason="PRIVATE API Gateway endpoint" if private else "API Gateway events",
+        )
+    ]
+
+
+def _kong_signals(file_path: str, text: str, services: list) -> list[ExposureSignal]:
+    """Kong declarative routes; Kong's proxy listener faces clients."""
+    signals = []
+    for service in services:
+        if not isinstance(service, dict):
+            continue
+        scopes, hosts = [], []
+        for route in service.get("routes") or []:
+            if not isinstance(route, dict):
+                continue
+            hosts += [str(h) for h in route.get("hosts") or []]
+            for path in route.get("paths") or []:
+                scope = _scope(str(path)) if not str(path).startswith("~") else None
+                if scope and scope not in scopes:
+                    scopes.append(scope)
+        if not scopes:
+            continue
+        internal = _internal_hosts(hosts)
+        name = str(service.get("name") or "kong-service")
+        signals.append(
+            ExposureSignal(
+                source=ExposureSource.API_GATEWAY,
+                exposure=Exposure.INTERNAL_ONLY if internal else Exposure.INTERNET_FACING,
+                file_path=file_path,
+                line_start=first_line(text, f"name: {name}"),
+                name=name,
+                scopes=scopes,
+                hosts=hosts,
+                reason=f"private hosts {', '.join(hosts)}" if internal else f"Kong service {name} routes",
+            )
+        )
+    return signals
+
+
+def _terraform_signals(file_path: str, text: str) -> list[ExposureSignal]:
+    """Terraform load balancers and REST APIs, by their internal flag or endpoint type."""
+    signals = []
+    matches = list(TERRAFORM_RESOURCE.finditer(text))
+    for index, match in enumerate(matches):
+        block = text[match.end() : matches[index + 1].start() if index + 1 < len(matches) else len(text)]
+        kind, name = match.group(1), match.group(2)
+        if kind == "aws_api_gateway_rest_api":
+            internal = bool(TERRAFORM_PRIVATE_ENDPOINT.search(block))
+            reason = "PRIVATE endpoint configuration" if internal else f"REST API {name}"
+        else:
+            flag = TERRAFORM_INTERNAL.search(block)
+            internal = bool(flag and flag.group(1) == "true")
+            reason = f"{kind}.{name} internal = true" if internal else f"{kind}.{name} internet-facing"
+        signals.append(
+            ExposureSignal(
+                source=ExposureSource.TERRAFORM,
+                exposure=Exposure.INTERNAL_ONLY if internal else Exposure.INTERNET_FACING,
+                file_path=file_path,
+                line_start=text.count("\n", 0, match.start()) + 1,
+                name=name,
+                reason=reason,
+            )
+        )
+    return signals
+
+
+def resolve_exposure(path: str, signals: list[ExposureSignal]) -> tuple[Exposure, ExposureSignal | None]:
+    """Exposure of one endpoint path given a service's signals.
+
+    Path-scoped routes decide first: an internet-facing route to the path
+    makes it internet-facing, an internal one internal-only. Otherwise an
+    internet-facing listener for the whole service decides. When public
+    routes exist but none reaches the path it is internal-only, as it is
+    when the only listeners are internal.
+
+    Args:
+        path: Endpoint path
+        signals: Exposure signals of the endpoint's service
+
+    Returns:
+        Tuple of (exposure, the signal that decided it)
+    """
+    scoped = [s for s in signals if s.scopes and any(scope_matches(scope, path) for scope in s.scopes)]
+    for exposure in (Exposure.INTERNET_FACING, Exposure.INTERNAL_ONLY):
+        match = next((s for s in scoped if s.exposure == exposure), None)
+        if match:
+            return exposure, match
+    wide = [s for s in signals if not s.scopes]
+    internet = next((s for s in wide if s.exposure == Exposure.INTERNET_FACING), None)
+    if internet:
+        return Exposure.INTERNET_FACING, internet
+    public_routes = next((s for s in signals if s.scopes and s.exposure == Exposure.INTERNET_FACING), None)
+    if public_routes:
+        return Exposure.INTERNAL_ONLY, public_routes
+    internal = next((s for s in wide if s.exposure == Exposure.INTERNAL_ONLY), None)
+    if internal:
+        return Exposure.INTERNAL_ONLY, internal
+    return Exposure.UNKNOWN, None
+
+
+def describe_exposure(exposure: Exposure, signal: ExposureSignal | None) -> str | None:
+    """Short explanation of a resolved exposure."""
+    if signal is None:
+        return None
+    if exposure == Exposure.INTERNAL_ONLY and signal.exposure == Exposure.INTERNET_FACING:
+        return f"not routed by {signal.reason} ({signal.file_path})"
+    return f"{exposure.value.replace('_', '-')} per {signal.reason} ({signal.file_path})"
+
+
+def exposure_signals(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the exposure signals of a repository clone in path order."""
+    clone = Path(root)
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in (".yaml", ".yml", ".tf", ".json") or not path.is_file():
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts) or path.stat().st_size > MAX_CONFIG_BYTES:
+            continue
+        text = path.read_text(encoding="utf-8", errors="ignore")
+        name = relative.as_posix()
+        signals: list[ExposureSignal] = []
+        if path.suffix != ".tf":
+            chart = helm_chart_name(path, clone) if "templates" in relative.parts else None
+            if chart:
+                text = render_helm_template(text, chart)
+            signals.extend(extract_manifest_signals(parse_documents(name, text)))
+        signals.extend(extract_gateway_signals(name, text))
+        yield from (asdict(s) for s in signals)
+
+
+class ExposureService:
+    """Infers endpoint exposure from deployment and gateway configuration."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[ExposureSignal]:
+        """Read exposure signals from a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Signals in path order
+        """
+        if not root.is_dir():
+            return []
+        return [
+            ExposureSignal(**{**s, "source": ExposureSource(s["source"]), "exposure": Exposure(s["exposure"])})
+            for s in run_pass(root, exposure_signals)
+        ]
+
+    def repository_signals(self, repository_ids: set[int]) -> dict[int, list[ExposureSignal]]:
+        """Exposure signals per repository, for repositories with a clone."""
+        return {rid: self.scan_clone(self.clone_dir / str(rid)) for rid in repository_ids}
+
+    def endpoints(self, policies: list[Policy], signals: list[ExposureSignal]) -> list[dict]:
+        """Resolve the exposure of every endpoint the policies map to.
+
+        Args:
+            policies: Mined policies of one service
+            signals: That service's exposure signals
+
+        Returns:
+            Endpoint dictionaries, internet-facing first
+        """
+        endpoints = []
+        for rule in EndpointMappingService.map_policies(policies):
+            exposure, signal = resolve_exposure(rule.path, signals)
+            endpoints.append(
+                {
+                    "endpoint": rule.key,
+                    "method": rule.method,
+                    "path": rule.path,
+                    "policy_ids": rule.policy_ids,
+                    "requires_authentication": rule.requires_authentication,
+                    "exposure": exposure,
+                    "reason": describe_exposure(exposure, signal),
+                }
+            )
+        endpoints.sort(key=lambda e: (EXPOSURE_ORDER[e["exposure"]], e["path"], e["method"]))
+        return endpoints
+
+    def _exposure(self, repository: Repository) -> dict:
+        """Exposure report of one repository."""
+        root = self.clone_dir / str(repository.id)
+        signals = self.scan_clone(root)
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository.id)
+        endpoints = self.endpoints(policies, signals)
+        internet = sum(e["exposure"] == Exposure.INTERNET_FACING for e in endpoints)
+        logger.info(
+            "endpoint_exposure_inferred",
+            repository_id=repository.id,
+            signals=len(signals),
+            endpoints=len(endpoints),
+            internet_facing=internet,
+        )
+        return {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "cloned": root.is_dir(),
+            "signals": [asdict(s) for s in signals],
+            "internet_facing_endpoints": internet,
+            "endpoints": endpoints,
+        }
+
+    def services(self, repository_id: int | None = None, internet_only: bool = False) -> list[dict]:
+        """Endpoint exposure per service (repository).
+
+        Args:
+            repository_id: Restrict to one repository
+            internet_only: Keep only services with internet-facing endpoints
+
+        Returns:
+            Per-repository exposure signals and endpoints with their exposure
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        reports = [self._exposure(repository) for repository in repositories]
+        if internet_only:
+            reports = [r for r in reports if r["internet_facing_endpoints"]]
+        return reports
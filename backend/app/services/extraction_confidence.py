@@ -0,0 +1,89 @@
+"""Confidence of mined policies from how they were extracted.
+
+How a rule was found says more about whether it is real than how its
+evidence reads. A rule read from a policy file or a guard on the route
+registration is almost certainly enforced as stated; a rule the LLM pieced
+together from checks inside a handler usually is, but its subject or
+condition may be off; a rule the LLM read from code with no recognizable
+check at all is the one most likely to be invented. Each method caps the
+confidence a rule can reach, and the quality of its evidence (see
+RiskScoringService.calculate_confidence_score) places it under that cap.
+"""
+
+import re
+
+from app.models.policy import ExtractionMethod
+
+# Highest confidence a rule extracted by each method can reach
+METHOD_CONFIDENCE = {
+    ExtractionMethod.DECLARED_CONFIG: 95.0,
+    ExtractionMethod.EXPLICIT_MIDDLEWARE: 90.0,
+    ExtractionMethod.INFERRED_FROM_BODY: 70.0,
+    ExtractionMethod.LLM_ASSISTED: 50.0,
+}
+
+# Share of the cap that depends on evidence quality; the rest comes with the method
+EVIDENCE_WEIGHT = 0.4
+
+# Guards declared on a route or its handler rather than performed inside it
+EXPLICIT_GUARD = re.compile(
+    r"@(?:PreAuthorize|PostAuthorize|Secured|RolesAllowed|PermitAll|DenyAll|UseGuards|Roles)\b"  # Spring, Jakarta, NestJS
+    r"|\[(?:Authorize|AllowAnonymous)\b|\.RequireAuthorization\("  # ASP.NET
+    r"|\.(?:requestMatchers|antMatchers|mvcMatchers)\([^)]*\)\s*\.(?:hasRole|hasAnyRole|hasAuthority|hasAnyAuthority|authenticated)\("
+    r"|@(?:login_required|permission_required|user_passes_test|roles?_required|roles_accepted|jwt_required|auth_required|requires_auth\w*)\b"
+    r"|Depends\(\s*(?:require|verify|check)_\w+|permission_classes\s*="  # FastAPI, DRF
+    r"|before_action\s+:(?:authenticate|authorize|require)\w*"  # Rails
+    r"|->middleware\(\s*['\"](?:auth|can|role|permission)"  # Laravel
+    r"|\.(?:get|post|put|patch|delete|all|use|route)\(\s*['\"][^'\"]*['\"]\s*,\s*[\w.]*(?i:auth|role|permission|guard|protect|require|ensure|verify)\w*"
+    r"|\b(?:Handle|HandleFunc|GET|POST|PUT|PATCH|DELETE|Group)\([^\n]*\b\w*(?:Auth|Role|Permission)\w*\("  # Go routers
+)
+
+# Checks a handler performs on the caller's identity before doing its work
+BODY_CHECK = re.compile(
+    r"\b(?:if|unless|elif|else if|guard|when)\b[^\n]*\b(?:roles?|permissions?|user|principal|claims|scopes?|isAdmin|is_admin"
+    r"|is_staff|is_superuser|hasRole|has_perm|has_permission|can|owner|tenant|is_member|is_rolemember|current_user)\b"
+    r"|throw new \w*(?:Forbidden|Unauthorized|AccessDenied)\w*|abort\(\s*40[13]|status\(\s*40[13]\)"
+    r"|HttpStatus\.(?:FORBIDDEN|UNAUTHORIZED)|raise \w*(?:Forbidden|PermissionDenied|Unauthorized)\w*",
+    re.IGNORECASE,
+)
+
+# Methods in order of preference when a rule's evidence items disagree
+METHOD_ORDER = list(METHOD_CONFIDENCE)
+
+
+def classify_snippet(code_snippet: str | None) -> ExtractionMethod:
+    """How a rule the LLM extracted from a code snippet was found.
+
+    Args:
+        code_snippet: Evidence the LLM cited
+
+    Returns:
+        EXPLICIT_MIDDLEWARE for a guard on the route, INFERRED_FROM_BODY for a check in the
+        handler, or LLM_ASSISTED when the snippet shows neither
+    """
+    snippet = code_snippet or ""
+    if EXPLICIT_GUARD.search(snippet):
+        return ExtractionMethod.EXPLICIT_MIDDLEWARE
+    if BODY_CHECK.search(snippet):
+        return ExtractionMethod.INFERRED_FROM_BODY
+    return ExtractionMethod.LLM_ASSISTED
+
+
+def classify_evidence(code_snippets: list[str]) -> ExtractionMethod:
+    """How an LLM-extracted rule was found, from the most explicit of its evidence items."""
+    methods = [classify_snippet(s) for s in code_snippets] or [ExtractionMethod.LLM_ASSISTED]
+    return min(methods, key=METHOD_ORDER.index)
+
+
+def method_confidence(method: ExtractionMethod, evidence_quality: float) -> float:
+    """Confidence (0-100) of a rule from its extraction method and evidence quality.
+
+    Args:
+        method: How the rule was extracted
+        evidence_quality: Evidence score (0-100) from RiskScoringService.calculate_confidence_score
+
+    Returns:
+        Confidence under the method's cap
+    """
+    quality = min(max(evidence_quality, 0.0), 100.0) / 100
+    return round(METHOD_CONFIDENCE[method] * (1 - EVIDENCE_WEIGHT + EVIDENCE_WEIGHT * quality), 1)
@@ -0,0 +1,148 @@
+"""Text helpers shared by the source extractors.
+
+The route, entry point, and condition extractors read source files as text
+rather than parsing each language, so they share a small set of scanning
+primitives: finding where a bracket group or brace block ends, locating
+and slicing lines, and reading YAML manifests that may not parse. Each scan
+is bounded so a broken or minified file cannot make an extractor read to
+the end of the file.
+"""
+
+import re
+from bisect import bisect_right
+
+import yaml
+
+# Lines longer than this (minified or generated code) are skipped by line-based extractors
+MAX_LINE_LENGTH = 1000
+
+# Longest bracket group read for one call or block
+MAX_GROUP_CHARS = 4000
+MAX_GROUP_DEPTH = 32
+# Longest brace block read for one rule or body
+MAX_BLOCK_CHARS = 20000
+# Characters a bracket group scan stops at; escapes are consumed with the escaped character
+GROUP_TOKEN = re.compile(r"\\.|[()\[\]{}'\"`\n]", re.DOTALL)
+
+BRACKETS = {"(": ")", "[": "]", "{": "}"}
+
+
+def group(text: str, opening: int) -> int:
+    """Offset just past the bracket group opened at opening, skipping string literals.
+
+    A group not closed within MAX_GROUP_CHARS, or nesting deeper than
+    MAX_GROUP_DEPTH, ends with its opening line, so broken sources cannot
+    make every call read to the end of the file.
+    """
+    stack, quote, n = [], "", min(len(text), opening + MAX_GROUP_CHARS)
+    for match in GROUP_TOKEN.finditer(text, opening, n):
+        c = match.group()
+        if quote:
+            if c == quote or (c == "\n" and quote != "`"):
+                quote = ""
+        elif c in "'\"`":
+            quote = c
+        elif c in BRACKETS:
+            stack.append(BRACKETS[c])
+            if len(stack) > MAX_GROUP_DEPTH:
+                break
+        elif stack and c == stack[-1]:
+            stack.pop()
+            if not stack:
+                return match.end()
+    line_end = text.find("\n", opening, n)
+    return n if line_end < 0 else line_end
+
+
+def group_end(text: str, start: int) -> int:
+    """Offset just past the bracket group opened at or after start on the same line.
+
+    Returns the end of the line when no bracket opens there, and stops at
+    MAX_GROUP_CHARS for unbalanced input.
+    """
+    line_end = text.find("\n", start)
+    line_end = len(text) if line_end == -1 else line_end
+    opening = next((i for i in range(start, line_end) if text[i] in BRACKETS), None)
+    if opening is None:
+        return line_end
+    depth = 0
+    quote = None
+    limit = min(len(text), opening + MAX_GROUP_CHARS)
+    i = opening
+    while i < limit:
+        char = text[i]
+        if quote:
+            if char == "\\":
+                i += 1
+            elif char == quote:
+                quote = None
+        elif char in "'\"`":
+            quote = char
+        elif char in BRACKETS:
+            depth += 1
+        elif char in ")]}":
+            depth -= 1
+            if depth == 0:
+                return max(i + 1, line_end)
+        i += 1
+    return limit
+
+
+def block_end(text: str, open_brace: int) -> int:
+    """Offset just past the brace closing the block opened at open_brace.
+
+    A block not closed within MAX_BLOCK_CHARS ends there.
+    """
+    depth = 0
+    limit = min(len(text), open_brace + MAX_BLOCK_CHARS)
+    for i in range(open_brace, limit):
+        if text[i] == "{":
+            depth += 1
+        elif text[i] == "}":
+            depth -= 1
+            if depth == 0:
+                return i + 1
+    return limit
+
+
+def line_of(text: str, offset: int) -> int:
+    """1-based line number of a character offset."""
+    return text.count("\n", 0, offset) + 1
+
+
+def line_span(text: str, start: int, end: int) -> str:
+    """Lines start..end (1-based, inclusive) of a text."""
+    return "\n".join(text.splitlines()[start - 1 : end])
+
+
+class SourceLines:
+    """A file's text with its lines and line offsets, for extractors locating many matches in one file."""
+
+    def __init__(self, file_path: str, text: str):
+        """Split a file into lines."""
+        self.file_path = file_path
+        self.text = text
+        self.lines = text.split("\n")
+        self.starts = [0] + [m.end() for m in re.finditer("\n", text)]
+
+    def line_of(self, offset: int) -> int:
+        """1-based line number of a character offset."""
+        return bisect_right(self.starts, offset)
+
+    def span(self, first: int, last: int) -> str:
+        """Lines first..last (1-based, inclusive)."""
+        return "\n".join(self.lines[first - 1 : last])
+
+
+def first_line(text: str, needle: str) -> int:
+    """1-based line of the first occurrence of needle, or 1."""
+    offset = text.find(needle)
+    return text.count("\n", 0, offset) + 1 if offset >= 0 else 1
+
+
+def yaml_documents(text: str) -> list[object]:
+    """Parse YAML documents, skipping files that do not parse."""
+    try:
+        return [d for d in yaml.safe_load_all(text) if d is not None]
+    except yaml.YAMLError:
+        return []
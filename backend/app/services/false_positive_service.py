@@ -0,0 +1,320 @@
+"""Service for the false-positive feedback loop into the analyzers.
+
+Marking a mined rule or a finding as a false positive dismisses it and
+records its structural pattern: the code skeleton of its evidence, with
+comments, whitespace, numbers, and free-text string literals abstracted
+away, keyed by the analyzer that produced it. Single-token literals such as
+role names and scopes are kept, so distinct checks never share a pattern.
+Patterns form a per-workspace suppression model. After each scan, new
+rules and open findings matching an active pattern are dismissed with a
+note naming the pattern, so the same mis-detection stops recurring in any
+repository of the workspace. Analytics report false-positive rates per analyzer.
+"""
+
+import hashlib
+import re
+from collections import Counter
+from datetime import UTC, datetime
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.conflict import ConflictStatus, PolicyConflict
+from app.models.false_positive import FalsePositiveMark, FalsePositiveTarget, SuppressionPattern
+from app.models.inconsistent_enforcement import InconsistentEnforcement, InconsistentEnforcementStatus
+from app.models.policy import Policy, PolicyStatus, SourceType
+from app.models.policy_fix import FixStatus, PolicyFix
+from app.services.framework_route_service import FRAMEWORK_ROUTE_LABEL
+from app.services.stable_identity_service import CODE_COMMENT
+
+logger = structlog.get_logger(__name__)
+
+# Quoted literals; free text (messages, labels) is abstracted, single tokens (roles, scopes) are kept
+STRING_LITERAL = re.compile(r"'(?:[^'\\\n]|\\.)*'|\"(?:[^\"\\\n]|\\.)*\"|`[^`]*`")
+NUMBER_LITERAL = re.compile(r"\b\d+(?:\.\d+)?\b")
+
+# Policy description suffixes written by the deterministic miners -> analyzer
+ANALYZER_LABELS = [
+    ("(from Kubernetes manifests)", "k8s_manifests"),
+    (f"(from {FRAMEWORK_ROUTE_LABEL})", "framework_routes"),
+    ("(from container image ", "container_image"),
+]
+LLM_ANALYZER = "llm_scanner"
+
+# Reviewer recorded on detections dismissed by a suppression pattern
+SUPPRESSION_REVIEWER = "false-positive suppression"
+
+OPEN_ENFORCEMENT_STATUSES = [InconsistentEnforcementStatus.PENDING, InconsistentEnforcementStatus.ACKNOWLEDGED]
+
+
+def code_skeleton(code: str) -> str:
+    """Structure of a code snippet without names of data that vary between repos."""
+    code = CODE_COMMENT.sub("", code)
+    code = STRING_LITERAL.sub(lambda m: '""' if re.search(r"\s", m.group(0)) else m.group(0).lower(), code)
+    return " ".join(NUMBER_LITERAL.sub("0", code).split())
+
+
+def policy_analyzer(policy: Policy) -> str:
+    """Analyzer that mined a policy."""
+    description = policy.description or ""
+    for label, analyzer in ANALYZER_LABELS:
+        if label in description:
+            return analyzer
+    if policy.source_type == SourceType.DATABASE:
+        return "database_scanner"
+    return LLM_ANALYZER
+
+
+def policy_pattern(policy: Policy) -> str:
+    """Structural pattern of a policy: the skeletons of its evidence."""
+    skeletons = sorted({code_skeleton(e.code_snippet or "") for e in policy.evidence or []} - {""})
+    if skeletons:
+        return "\n".join(skeletons)
+    return f"{(policy.action or '').casefold()} {(policy.resource or '').casefold()}".strip()
+
+
+def _fingerprint(analyzer: str, pattern: str) -> str:
+    """Hash identifying a pattern within a workspace."""
+    return hashlib.sha256(f"{analyzer}\n{pattern}".encode()).hexdigest()
+
+
+class FalsePositiveService:
+    """Records false positives and suppresses detections matching them."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def _policies(self, policy_ids: list[int]) -> list[Policy]:
+        """Policies by ID within the tenant."""
+        ids = [pid for pid in policy_ids if pid is not None]
+        return self._query(Policy).filter(Policy.id.in_(ids)).order_by(Policy.id).all() if ids else []
+
+    def describe(self, target_type: FalsePositiveTarget, target) -> tuple[str, str, int | None]:
+        """Analyzer, structural pattern, and repository of a rule or finding.
+
+        Args:
+            target_type: What the target is
+            target: Policy, PolicyFix, PolicyConflict, or InconsistentEnforcement
+
+        Returns:
+            Tuple of (analyzer, pattern, repository ID or None)
+        """
+        if target_type == FalsePositiveTarget.POLICY:
+            return policy_analyzer(target), policy_pattern(target), target.repository_id
+        if target_type == FalsePositiveTarget.SECURITY_GAP:
+            policies = self._policies([target.policy_id])
+            kind = target.security_gap_type or ""
+        elif target_type == FalsePositiveTarget.CONFLICT:
+            policies = self._policies([target.policy_a_id, target.policy_b_id])
+            kind = target.conflict_type.value if target.conflict_type else ""
+        else:
+            policies = self._policies(list(target.policy_ids or []))
+            kind = " ".join((target.resource_type or "").casefold().split())
+        pattern = "\n".join([kind, *sorted(policy_pattern(p) for p in policies)])
+        repository_id = policies[0].repository_id if policies else None
+        return target_type.value, pattern, repository_id
+
+    def _target(self, target_type: FalsePositiveTarget, target_id: int):
+        """Load a rule or finding within the tenant."""
+        model = {
+            FalsePositiveTarget.POLICY: Policy,
+            FalsePositiveTarget.SECURITY_GAP: PolicyFix,
+            FalsePositiveTarget.CONFLICT: PolicyConflict,
+            FalsePositiveTarget.INCONSISTENT_ENFORCEMENT: InconsistentEnforcement,
+        }[target_type]
+        target = self._query(model).filter(model.id == target_id).first()
+        if target is None:
+            raise ValueError(f"{target_type.value.replace('_', ' ').capitalize()} {target_id} not found")
+        return target
+
+    @staticmethod
+    def dismiss(target_type: FalsePositiveTarget, target, note: str, reviewer: str | None) -> None:
+        """Close a rule or finding the way its own review flow would."""
+        now = datetime.now(UTC)
+        if target_type == FalsePositiveTarget.POLICY:
+            target.status, target.approval_comment = PolicyStatus.REJECTED, note
+            target.reviewed_by, target.reviewed_at = reviewer, now
+        elif target_type == FalsePositiveTarget.SECURITY_GAP:
+            target.status, target.review_comment = FixStatus.REJECTED, note
+            target.reviewed_by, target.reviewed_at = reviewer, now
+        elif target_type == FalsePositiveTarget.CONFLICT:
+            target.status, target.resolution_strategy = ConflictStatus.RESOLVED, "false_positive"
+            target.resolution_notes, target.resolved_at = note, now
+        else:
+            target.status, target.resolution_notes = InconsistentEnforcementStatus.DISMISSED, note
+            target.resolved_by, target.resolved_at = reviewer, now
+
+    def mark(
+        self, target_type: FalsePositiveTarget, target_id: int, reason: str | None = None, marked_by: str | None = None
+    ) -> FalsePositiveMark:
+        """Mark a rule or finding as a false positive and learn its pattern.
+
+        Args:
+            target_type: What is being marked
+            target_id: ID of the policy or finding
+            reason: Why it is a false positive
+            marked_by: User email
+
+        Returns:
+            The recorded mark, with its (new or existing) suppression pattern
+
+        Raises:
+            ValueError: If the target does not exist
+        """
+        target = self._target(target_type, target_id)
+        analyzer, pattern_text, repository_id = self.describe(target_type, target)
+        fingerprint = _fingerprint(analyzer, pattern_text)
+        pattern = (
+            self._query(SuppressionPattern)
+            .filter(SuppressionPattern.analyzer == analyzer, SuppressionPattern.fingerprint == fingerprint)
+            .first()
+        )
+        if pattern is None:
+            pattern = SuppressionPattern(
+                tenant_id=self.tenant_id,
+                analyzer=analyzer,
+                target_type=target_type,
+                fingerprint=fingerprint,
+                pattern=pattern_text,
+                reason=reason,
+                created_by=marked_by,
+            )
+            self.db.add(pattern)
+        pattern.active = True
+        self.dismiss(target_type, target, f"False positive: {reason}" if reason else "False positive", marked_by)
+        mark = FalsePositiveMark(
+            tenant_id=self.tenant_id,
+            pattern=pattern,
+            analyzer=analyzer,
+            target_type=target_type,
+            target_id=target_id,
+            repository_id=repository_id,
+            reason=reason,
+            marked_by=marked_by,
+        )
+        self.db.add(mark)
+        self.db.commit()
+        self.db.refresh(mark)
+        logger.info("false_positive_marked", analyzer=analyzer, target_type=target_type.value, target_id=target_id)
+        return mark
+
+    def _open_targets(self, repository_id: int) -> list[tuple[FalsePositiveTarget, object]]:
+        """Unreviewed rules of a repository and the open findings touching them."""
+        policies = self._query(Policy).filter(Policy.repository_id == repository_id).all()
+        repository_policy_ids = {p.id for p in policies}
+        targets: list[tuple[FalsePositiveTarget, object]] = [
+            (FalsePositiveTarget.POLICY, p) for p in policies if p.status == PolicyStatus.PENDING
+        ]
+        for fix in self._query(PolicyFix).filter(PolicyFix.status == FixStatus.PENDING).all():
+            if fix.policy_id in repository_policy_ids:
+                targets.append((FalsePositiveTarget.SECURITY_GAP, fix))
+        for conflict in self._query(PolicyConflict).filter(PolicyConflict.status == ConflictStatus.PENDING).all():
+            if {conflict.policy_a_id, conflict.policy_b_id} & repository_policy_ids:
+                targets.append((FalsePositiveTarget.CONFLICT, conflict))
+        for finding in (
+            self._query(InconsistentEnforcement)
+            .filter(InconsistentEnforcement.status.in_(OPEN_ENFORCEMENT_STATUSES))
+            .all()
+        ):
+            if set(finding.policy_ids or []) & repository_policy_ids:
+                targets.append((FalsePositiveTarget.INCONSISTENT_ENFORCEMENT, finding))
+        return targets
+
+    def suppress(self, repository_id: int) -> dict[str, int]:
+        """Dismiss a repository's new detections that match an active pattern.
+
+        Args:
+            repository_id: Repository that was just scanned or analyzed
+
+        Returns:
+            Count of dismissed detections per analyzer
+        """
+        patterns = {
+            (p.analyzer, p.fingerprint): p
+            for p in self._query(SuppressionPattern).filter(SuppressionPattern.active.is_(True)).all()
+        }
+        suppressed: Counter[str] = Counter()
+        if not patterns:
+            return {}
+        now = datetime.now(UTC)
+        for target_type, target in self._open_targets(repository_id):
+            analyzer, pattern_text, _ = self.describe(target_type, target)
+            pattern = patterns.get((analyzer, _fingerprint(analyzer, pattern_text)))
+            if pattern is None:
+                continue
+            note = f"Suppressed: matches false-positive pattern {pattern.id}" + (f" ({pattern.reason})" if pattern.reason else "")
+            self.dismiss(target_type, target, note, SUPPRESSION_REVIEWER)
+            pattern.suppressed_count = (pattern.suppressed_count or 0) + 1
+            pattern.last_suppressed_at = now
+            suppressed[analyzer] += 1
+        if suppressed:
+            self.db.commit()
+        logger.info("false_positives_suppressed", repository_id=repository_id, suppressed=sum(suppressed.values()))
+        return dict(suppressed)
+
+    def patterns(self, analyzer: str | None = None) -> list[SuppressionPattern]:
+        """Suppression patterns of the workspace, most recent first."""
+        query = self._query(SuppressionPattern)
+        if analyzer:
+            query = query.filter(SuppressionPattern.analyzer == analyzer)
+        return query.order_by(SuppressionPattern.id.desc()).all()
+
+    def set_active(self, pattern_id: int, active: bool) -> SuppressionPattern:
+        """Turn a suppression pattern on or off; dismissed detections stay dismissed.
+
+        Raises:
+            ValueError: If the pattern does not exist
+        """
+        pattern = self._query(SuppressionPattern).filter(SuppressionPattern.id == pattern_id).first()
+        if pattern is None:
+            raise ValueError(f"Suppression pattern {pattern_id} not found")
+        pattern.active = active
+        self.db.commit()
+        return pattern
+
+    def analytics(self) -> list[dict]:
+        """False-positive rates per analyzer.
+
+        Returns:
+            Per analyzer: detections, user-marked false positives, automatic
+            suppressions, active patterns, and the share of detections that
+            were false positives, highest rate first
+        """
+        detections: Counter[str] = Counter(policy_analyzer(p) for p in self._query(Policy).all())
+        detections[FalsePositiveTarget.SECURITY_GAP.value] += self._query(PolicyFix).count()
+        detections[FalsePositiveTarget.CONFLICT.value] += self._query(PolicyConflict).count()
+        detections[FalsePositiveTarget.INCONSISTENT_ENFORCEMENT.value] += self._query(InconsistentEnforcement).count()
+        marked = Counter(m.analyzer for m in self._query(FalsePositiveMark).all())
+        suppressed: Counter[str] = Counter()
+        active: Counter[str] = Counter()
+        for pattern in self._query(SuppressionPattern).all():
+            suppressed[pattern.analyzer] += pattern.suppressed_count or 0
+            active[pattern.analyzer] += bool(pattern.active)
+
+        results = []
+        for analyzer in sorted(set(detections) | set(marked)):
+            if not detections[analyzer] and not marked[analyzer]:
+                continue
+            false_positives = marked[analyzer] + suppressed[analyzer]
+            total = max(detections[analyzer], false_positives)
+            results.append(
+                {
+                    "analyzer": analyzer,
+                    "detections": detections[analyzer],
+                    "marked_false_positive": marked[analyzer],
+                    "suppressed": suppressed[analyzer],
+                    "active_patterns": active[analyzer],
+                    "false_positive_rate": round(false_positives / total, 4) if total else 0.0,
+                }
+            )
+        results.sort(key=lambda r: (-r["false_positive_rate"], r["analyzer"]))
+        return results
@@ -0,0 +1,571 @@
+"""Extract FastAPI dependency-based authorization from Python services.
+
+FastAPI routes are authorized by their dependencies: parameters declared
+with Depends() or Security(), and dependencies= lists on the route, its
+APIRouter, include_router() calls, and the app. Each dependency is followed
+through its own parameters (get_current_active_user depends on
+get_current_user, which depends on an OAuth2PasswordBearer scheme), and its
+body is read for the checks that raise, such as role comparisons or
+is_superuser. Checker classes and factories like RoleChecker(["admin"]) or
+require_role("admin") are evaluated with their arguments bound. Scopes
+declared with Security(..., scopes=[...]) along the chain become the route's
+scope requirement, flagged when no dependency reads SecurityScopes to
+enforce them. Sources are parsed with the ast module; files that do not
+parse are skipped.
+"""
+
+import ast
+import copy
+import re
+from dataclasses import dataclass, field
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.django_route_extractor import (
+    MAX_DEPTH,
+    _access,
+    _All,
+    _Any,
+    _call_name,
+    _check,
+    _evaluate,
+    _keyword,
+    _Module,
+    _negate,
+    _Project,
+    _Rule,
+    _source,
+    _strings,
+    parse_module,
+)
+from app.services.extractor_utils import line_span
+from app.services.jvm_route_extractor import ANONYMOUS, AUTHENTICATED, DENIED, HTTP_VERBS, MAX_SNIPPET_LINES, Access, _join
+
+FASTAPI_MARKER = re.compile(r"^\s*(?:from|import)\s+fastapi\b", re.MULTILINE)
+
+
+class FastApiRouteKind:
+    """Kinds of FastAPI findings."""
+
+    ROUTE = "fastapi_route"
+
+
+ROUTE_DECORATORS = {verb.lower(): verb for verb in HTTP_VERBS}
+ROUTER_FACTORIES = {"APIRouter", "FastAPI"}
+DEPENDENCY_MARKERS = {"Depends", "Security"}
+
+# fastapi.security schemes; a request without credentials is refused unless auto_error=False
+SECURITY_SCHEMES = {
+    "OAuth2PasswordBearer",
+    "OAuth2AuthorizationCodeBearer",
+    "OAuth2",
+    "OpenIdConnect",
+    "HTTPBearer",
+    "HTTPBasic",
+    "HTTPDigest",
+    "APIKeyHeader",
+    "APIKeyQuery",
+    "APIKeyCookie",
+}
+
+# Names of dependencies defined outside the scanned code that authenticate the caller
+AUTH_DEPENDENCY = re.compile(r"(?i)user|auth|token|login|verify|role|permission|admin|scope|jwt|api_?key|principal")
+IDENTITY = re.compile(r"(?i)(?:\w+_)?(?:user|token|credentials?|principal|claims|api_?key|identity|sub)")
+STATUS_CODE = re.compile(r"\b(401|403)\b|HTTP_(401|403)")
+
+
+@dataclass
+class _Chain:
+    """Requirements collected while following a route's dependencies."""
+
+    checks: list = field(default_factory=list)
+    scopes: list[str] = field(default_factory=list)
+    scopes_checked: bool = False
+    via: list[str] = field(default_factory=list)  # Dependencies reached, in order
+    seen: set = field(default_factory=set)
+
+
+@dataclass
+class _Router:
+    """An APIRouter or FastAPI app and where it is included."""
+
+    name: str
+    module: _Module
+    call: ast.Call
+    app: bool
+    mounts: list[tuple["_Router", str, list[tuple[_Module, ast.expr, str]]]] = field(default_factory=list)
+
+
+@dataclass
+class _Route:
+    """A path operation and the dependencies declared on it."""
+
+    router: _Router
+    module: _Module
+    function: ast.FunctionDef | ast.AsyncFunctionDef
+    path: str
+    methods: list[str]
+    dependencies: list[tuple[_Module, ast.expr, str]]
+
+
+class _Bind(ast.NodeTransformer):
+    """Substitute bound arguments and enum members into a dependency's code."""
+
+    def __init__(self, project: "_FastApiProject", module: _Module, bindings: dict[str, ast.expr]):
+        """Initialize transformer."""
+        self.project = project
+        self.module = module
+        self.bindings = bindings
+
+    def visit_Name(self, node: ast.Name) -> ast.expr:
+        """Replace a bound parameter with its argument."""
+        return self.bindings.get(node.id, node)
+
+    def visit_Attribute(self, node: ast.Attribute) -> ast.expr:
+        """Replace self attributes set from arguments, and Role.ADMIN(.value) with its string."""
+        if isinstance(node.value, ast.Name) and f"{node.value.id}.{node.attr}" in self.bindings:
+            return self.bindings[f"{node.value.id}.{node.attr}"]
+        node = self.generic_visit(node)
+        if node.attr == "value" and isinstance(node.value, ast.Constant):
+            return node.value
+        if isinstance(node.value, ast.Name):
+            resolved = self.project.lookup(self.module, node.value.id)
+            if resolved and resolved[0] == "class":
+                member = _class_constant(resolved[2], node.attr)
+                if member is not None:
+                    return member
+        return node
+
+
+def _class_constant(cls: ast.ClassDef, name: str) -> ast.Constant | None:
+    """String value of a class attribute such as an enum member."""
+    for statement in cls.body:
+        if isinstance(statement, ast.Assign) and isinstance(statement.value, ast.Constant):
+            if any(isinstance(t, ast.Name) and t.id == name for t in statement.targets):
+                return statement.value if isinstance(statement.value.value, str) else None
+    return None
+
+
+def _parameters(function: ast.FunctionDef | ast.AsyncFunctionDef) -> list[tuple[ast.arg, ast.expr | None]]:
+    """A function's parameters with their defaults."""
+    args = function.args
+    positional = args.posonlyargs + args.args
+    defaults = [None] * (len(positional) - len(args.defaults)) + list(args.defaults)
+    return list(zip(positional, defaults)) + list(zip(args.kwonlyargs, args.kw_defaults))
+
+
+def _bind_arguments(function: ast.FunctionDef | ast.AsyncFunctionDef, call: ast.Call, skip_self: bool) -> dict[str, ast.expr]:
+    """Map a function's parameter names to the arguments of a call."""
+    names = [a.arg for a, _ in _parameters(function)][1 if skip_self else 0 :]
+    bindings = {name: arg for name, arg in zip(names, call.args) if not isinstance(arg, ast.Starred)}
+    bindings.update({k.arg: k.value for k in call.keywords if k.arg in names})
+    return bindings
+
+
+def _body(statements: list[ast.stmt]):
+    """Statements of a function body, including those nested in blocks but not in nested functions."""
+    for statement in statements:
+        yield statement
+        if isinstance(statement, (ast.FunctionDef, ast.AsyncFunctionDef, ast.ClassDef)):
+            continue
+        for name in ("body", "orelse", "finalbody"):
+            yield from _body(getattr(statement, name, []) or [])
+        for handler in getattr(statement, "handlers", []) or []:
+            yield from _body(handler.body)
+
+
+def _raised_status(statement: ast.Raise, local: dict[str, ast.expr]) -> int | None:
+    """401 or 403 if a raise refuses the request with that status."""
+    exception = statement.exc
+    if isinstance(exception, ast.Name) and exception.id in local:
+        exception = local[exception.id]
+    if not isinstance(exception, ast.Call):
+        return None
+    status = _keyword(exception, "status_code") or (exception.args[0] if exception.args else None)
+    match = STATUS_CODE.search(_source(status)) if status is not None else None
+    return int(match.group(1) or match.group(2)) if match else None
+
+
+def _identity(node: ast.expr) -> bool:
+    """Whether an expression names the caller's identity or credentials, as `user` or `payload.token` do."""
+    name = node.attr if isinstance(node, ast.Attribute) else node.id if isinstance(node, ast.Name) else ""
+    return bool(IDENTITY.fullmatch(name))
+
+
+def _refusal(node: ast.expr, depth: int = 0):
+    """Check tree of what must hold for an `if <node>: raise` guard to let the request through."""
+    if depth > MAX_DEPTH:
+        return _Rule(conditions=[f"passes not {_source(node)}"])
+    if isinstance(node, ast.BoolOp):
+        parts = [_refusal(v, depth + 1) for v in node.values]
+        return _All(parts) if isinstance(node.op, ast.Or) else _Any(parts)
+    if isinstance(node, ast.UnaryOp) and isinstance(node.op, ast.Not):
+        return _Rule(decision=AUTHENTICATED) if _identity(node.operand) else _check(node.operand)
+    if isinstance(node, ast.Compare) and len(node.ops) == 1:
+        op, right = node.ops[0], node.comparators[0]
+        if isinstance(op, ast.Is) and isinstance(right, ast.Constant) and right.value is None and _identity(node.left):
+            return _Rule(decision=AUTHENTICATED)
+        inverse = {ast.NotEq: ast.Eq, ast.NotIn: ast.In}.get(type(op))
+        if inverse:
+            return _check(ast.Compare(left=node.left, ops=[inverse()], comparators=[right]))
+    return _negate(node)
+
+
+def _informative(check) -> bool:
+    """Whether a check tree says who may pass rather than only naming an opaque condition."""
+    if isinstance(check, _Rule):
+        return bool(check.roles) or check.decision in (AUTHENTICATED, DENIED)
+    return True
+
+
+def _path(node: ast.expr | None) -> str:
+    """A route path or prefix, with computed ones such as settings.API_V1_STR shown as {settings.API_V1_STR}."""
+    if node is None:
+        return ""
+    return node.value if isinstance(node, ast.Constant) and isinstance(node.value, str) else "{" + _source(node) + "}"
+
+
+def _route_arguments(call: ast.Call) -> tuple[str, list[str]] | None:
+    """Path and methods of a route decorator or add_api_route() call."""
+    name = _call_name(call)
+    path = call.args[0] if call.args else _keyword(call, "path")
+    if path is None:
+        return None
+    route = _path(path)
+    if name in ROUTE_DECORATORS:
+        return route, [ROUTE_DECORATORS[name]]
+    methods = [m.upper() for m in _strings(_keyword(call, "methods"))]
+    return route, methods or ["GET"]
+
+
+class _FastApiProject(_Project):
+    """Routers, routes, and dependency resolution across an application's modules."""
+
+    def __init__(self, modules: list[_Module]):
+        """Initialize project."""
+        super().__init__(modules)
+        self.routers: dict[int, _Router] = {}
+        self.routes: list[_Route] = []
+        self.scopes: dict[str, dict[str, ast.expr]] = {}  # Module -> names assigned in app factories
+        for module in modules:
+            self.scopes[module.name] = {}
+            for function in module.functions.values():
+                for statement in _body(function.body):
+                    if isinstance(statement, ast.Assign) and isinstance(statement.value, ast.Call):
+                        for target in statement.targets:
+                            if isinstance(target, ast.Name) and _call_name(statement.value) in ROUTER_FACTORIES:
+                                self.scopes[module.name].setdefault(target.id, statement.value)
+        for module in modules:
+            self.collect(module)
+
+    def router(self, module: _Module, node: ast.expr) -> _Router | None:
+        """The router or app an expression refers to."""
+        resolved = self.resolve(module, node)
+        if resolved and resolved[0] == "value":
+            _, owner, value = resolved
+        elif isinstance(node, ast.Name) and node.id in self.scopes[module.name]:
+            owner, value = module, self.scopes[module.name][node.id]
+        else:
+            return None
+        if not isinstance(value, ast.Call) or _call_name(value) not in ROUTER_FACTORIES:
+            return None
+        if id(value) not in self.routers:
+            name = f"{owner.name.rsplit('.', 1)[-1]}.{_source(node).rsplit('.', 1)[-1]}"
+            self.routers[id(value)] = _Router(name, owner, value, _call_name(value) == "FastAPI")
+        return self.routers[id(value)]
+
+    def dependency_list(self, module: _Module, node: ast.expr | None, origin: str) -> list[tuple[_Module, ast.expr, str]]:
+        """Depends()/Security() entries of a dependencies= list, with where they were declared."""
+        if isinstance(node, ast.Name) and node.id in module.values:
+            node = module.values[node.id]
+        if not isinstance(node, (ast.List, ast.Tuple)):
+            return []
+        entries = []
+        for element in node.elts:
+            if isinstance(element, ast.Call) and _call_name(element) in DEPENDENCY_MARKERS:
+                entries.append((module, element, f"{_source(element)}{origin}"))
+        return entries
+
+    def collect(self, module: _Module) -> None:
+        """Record a module's path operations and include_router() calls, including those made in app factories."""
+        calls = list(module.calls)
+        for function in module.functions.values():
+            calls += [s.value for s in _body(function.body) if isinstance(s, ast.Expr) and isinstance(s.value, ast.Call)]
+            for decorator in function.decorator_list:
+                if not isinstance(decorator, ast.Call) or not isinstance(decorator.func, ast.Attribute):
+                    continue
+                if decorator.func.attr not in ROUTE_DECORATORS and decorator.func.attr != "api_route":
+                    continue
+                router = self.router(module, decorator.func.value)
+                arguments = _route_arguments(decorator)
+                if router is None or arguments is None:
+                    continue
+                dependencies = self.dependency_list(module, _keyword(decorator, "dependencies"), "")
+                self.routes.append(_Route(router, module, function, *arguments, dependencies))
+
+        for call in calls:
+            if not isinstance(call.func, ast.Attribute):
+                continue
+            parent = self.router(module, call.func.value)
+            if parent is None:
+                continue
+            if call.func.attr == "include_router" and call.args:
+                child = self.router(module, call.args[0])
+                if child is None or child is parent:
+                    continue
+                origin = f" on include_router({_source(call.args[0])})"
+                dependencies = self.dependency_list(module, _keyword(call, "dependencies"), origin)
+                child.mounts.append((parent, _path(_keyword(call, "prefix")), dependencies))
+            elif call.func.attr == "add_api_route":
+                endpoint = call.args[1] if len(call.args) > 1 else _keyword(call, "endpoint")
+                resolved = self.resolve(module, endpoint) if endpoint is not None else None
+                arguments = _route_arguments(call)
+                if resolved and resolved[0] == "function" and arguments:
+                    dependencies = self.dependency_list(module, _keyword(call, "dependencies"), "")
+                    self.routes.append(_Route(parent, resolved[1], resolved[2], *arguments, dependencies))
+
+    def paths(self, router: _Router, depth: int = 0) -> list[tuple[str, list[tuple[_Module, ast.expr, str]]]]:
+        """Prefixes a router is served under, each with the dependencies it inherits there."""
+        own_prefix = _path(_keyword(router.call, "prefix"))
+        own = self.dependency_list(router.module, _keyword(router.call, "dependencies"), f" on {router.name}")
+        if not router.mounts or depth > MAX_DEPTH:
+            return [(own_prefix, own)]
+        paths = []
+        for parent, include_prefix, include_dependencies in router.mounts:
+            for parent_prefix, inherited in self.paths(parent, depth + 1):
+                paths.append((_join(parent_prefix, include_prefix, own_prefix), inherited + include_dependencies + own))
+        return paths
+
+    # Dependencies
+
+    def markers(self, module: _Module, arg: ast.arg, default: ast.expr | None) -> list[tuple[_Module, ast.Call, ast.expr | None]]:
+        """Depends()/Security() markers of a parameter, from its default or Annotated[...] type (or alias)."""
+        markers = []
+        annotation, owner = arg.annotation, module
+        if isinstance(annotation, ast.Name):
+            resolved = self.lookup(module, annotation.id)
+            if resolved and resolved[0] == "value" and isinstance(resolved[2], ast.Subscript):
+                owner, annotation = resolved[1], resolved[2]
+        declared_type = annotation
+        if isinstance(annotation, ast.Subscript) and _source(annotation.value).endswith("Annotated"):
+            elements = annotation.slice.elts if isinstance(annotation.slice, ast.Tuple) else [annotation.slice]
+            declared_type = elements[0] if elements else None
+            for element in elements[1:]:
+                if isinstance(element, ast.Call) and _call_name(element) in DEPENDENCY_MARKERS:
+                    markers.append((owner, element, declared_type))
+        if isinstance(default, ast.Call) and _call_name(default) in DEPENDENCY_MARKERS:
+            markers.append((module, default, declared_type))
+        return markers
+
+    def follow(self, module: _Module, marker: ast.Call, declared_type: ast.expr | None, chain: _Chain, depth: int) -> None:
+        """Follow a Depends() or Security() marker."""
+        if _call_name(marker) == "Security":
+            chain.scopes += [s for s in _strings(_keyword(marker, "scopes")) if s not in chain.scopes]
+        target = marker.args[0] if marker.args else _keyword(marker, "dependency")
+        target = target if target is not None else declared_type
+        if target is not None:
+            self.dependency(module, target, chain, depth + 1)
+
+    def dependency(self, module: _Module, node: ast.expr, chain: _Chain, depth: int, bindings: dict | None = None) -> None:
+        """Collect the requirements of a dependency callable."""
+        if depth > MAX_DEPTH:
+            return
+        if bindings or isinstance(node, ast.Call):
+            # Arguments such as Role.ADMIN are resolved where the call is written
+            node = _Bind(self, module, bindings or {}).visit(copy.deepcopy(node))
+        callee = node.func if isinstance(node, ast.Call) else node
+        resolved = self.resolve(module, callee) if isinstance(callee, (ast.Name, ast.Attribute)) else None
+        if resolved is None:
+            self.external(node, chain)
+        elif resolved[0] == "function":
+            if isinstance(node, ast.Call):
+                self.factory(resolved[1], resolved[2], node, chain, depth)
+            else:
+                self.function(resolved[1], resolved[2], chain, depth)
+        elif resolved[0] == "class":
+            self.instance(resolved[1], resolved[2], node if isinstance(node, ast.Call) else None, chain, depth)
+        elif resolved[0] == "value" and isinstance(resolved[2], ast.Call):
+            label = _source(callee).rsplit(".", 1)[-1]
+            if label not in chain.via:
+                chain.via.append(label)
+            self.dependency(resolved[1], resolved[2], chain, depth + 1)
+
+    def external(self, node: ast.expr, chain: _Chain) -> None:
+        """Requirements of a dependency defined outside the scanned code, judged by its name."""
+        name = _call_name(node) or _source(node).rsplit(".", 1)[-1]
+        call = node if isinstance(node, ast.Call) else None
+        if name in SECURITY_SCHEMES:
+            auto_error = _keyword(call, "auto_error") if call else None
+            if not (isinstance(auto_error, ast.Constant) and auto_error.value is False):
+                chain.checks.append(_Rule(decision=AUTHENTICATED))
+        elif name == "current_user" and call is not None:
+            # fastapi-users: current_user(active=True, superuser=True, optional=False)
+            flags = {k.arg: k.value.value for k in call.keywords if isinstance(k.value, ast.Constant)}
+            if not flags.get("optional"):
+                chain.checks.append(_Rule(roles=["superuser"]) if flags.get("superuser") else _Rule(decision=AUTHENTICATED))
+        elif AUTH_DEPENDENCY.search(name):
+            plain = re.fullmatch(r"(?i)(?:get_)?(?:current_)?(?:active_)?(?:authenticated_)?user", name)
+            chain.checks.append(_Rule(decision=AUTHENTICATED, conditions=[] if plain else [f"passes {name}"]))
+
+    def instance(self, module: _Module, cls: ast.ClassDef, call: ast.Call | None, chain: _Chain, depth: int) -> None:
+        """Requirements of a class dependency: __init__ for Depends(Class), __call__ for Depends(Class(...))."""
+        classes, external = self.chain(module, cls)
+        if external & SECURITY_SCHEMES:
+            # Subclasses of HTTPBearer and the like refuse requests without credentials first
+            chain.checks.append(_Rule(decision=AUTHENTICATED))
+        methods = {}
+        for _, node in reversed(classes):
+            methods.update({s.name: s for s in node.body if isinstance(s, (ast.FunctionDef, ast.AsyncFunctionDef))})
+        if call is None:
+            if "__init__" in methods:
+                self.function(module, methods["__init__"], chain, depth, skip_self=True)
+            return
+        bindings = {}
+        if "__init__" in methods:
+            arguments = _bind_arguments(methods["__init__"], call, skip_self=True)
+            for statement in _body(methods["__init__"].body):
+                if isinstance(statement, ast.Assign) and isinstance(statement.value, ast.Name) and statement.value.id in arguments:
+                    for target in statement.targets:
+                        if isinstance(target, ast.Attribute) and _source(target.value) == "self":
+                            bindings[f"self.{target.attr}"] = arguments[statement.value.id]
+        if "__call__" in methods:
+            self.function(module, methods["__call__"], chain, depth, bindings, skip_self=True, label=cls.name)
+
+    def factory(self, module: _Module, function: ast.FunctionDef | ast.AsyncFunctionDef, call: ast.Call, chain: _Chain, depth: int) -> None:
+        """Requirements of the dependency a factory such as require_role("admin") returns."""
+        bindings = _bind_arguments(function, call, skip_self=False)
+        nested = {s.name: s for s in function.body if isinstance(s, (ast.FunctionDef, ast.AsyncFunctionDef))}
+        for statement in _body(function.body):
+            if isinstance(statement, ast.Return) and isinstance(statement.value, ast.Name) and statement.value.id in nested:
+                self.function(module, nested[statement.value.id], chain, depth, bindings, label=function.name)
+                return
+            if isinstance(statement, ast.Return) and isinstance(statement.value, ast.Call):
+                self.dependency(module, statement.value, chain, depth + 1, bindings)
+                return
+
+    def function(
+        self,
+        module: _Module,
+        function: ast.FunctionDef | ast.AsyncFunctionDef,
+        chain: _Chain,
+        depth: int,
+        bindings: dict | None = None,
+        skip_self: bool = False,
+        label: str | None = None,
+    ) -> None:
+        """Requirements of a dependency function: its own dependencies, then the checks in its body."""
+        key = (id(function), repr(sorted((k, ast.dump(v)) for k, v in (bindings or {}).items())))
+        if key in chain.seen or depth > MAX_DEPTH:
+            return
+        chain.seen.add(key)
+        if (label or function.name) not in chain.via:
+            chain.via.append(label or function.name)
+
+        scope_parameters = []
+        for index, (arg, default) in enumerate(_parameters(function)):
+            if skip_self and index == 0:
+                continue
+            if arg.annotation is not None and _source(arg.annotation).endswith("SecurityScopes"):
+                scope_parameters.append(arg.arg)
+            for owner, marker, declared_type in self.markers(module, arg, default):
+                self.follow(owner, marker, declared_type, chain, depth)
+
+        local = {}
+        for statement in _body(function.body):
+            if isinstance(statement, ast.Assign) and len(statement.targets) == 1 and isinstance(statement.targets[0], ast.Name):
+                local[statement.targets[0].id] = statement.value
+        binder = _Bind(self, module, bindings or {})
+        for statement in _body(function.body):
+            if isinstance(statement, ast.Raise) and _raised_status(statement, local) == 401:
+                chain.checks.append(_Rule(decision=AUTHENTICATED))
+            if not isinstance(statement, ast.If) or not statement.body or not isinstance(statement.body[0], ast.Raise):
+                continue
+            status = _raised_status(statement.body[0], local)
+            check = _refusal(binder.visit(copy.deepcopy(statement.test)))
+            if _informative(check):
+                chain.checks.append(check)
+            elif status == 403:
+                chain.checks.append(check)
+        for node in ast.walk(function):
+            if isinstance(node, ast.Attribute) and node.attr == "scopes" and _source(node.value) in scope_parameters:
+                chain.scopes_checked = True
+                break
+
+
+def _scope_conditions(chain: _Chain) -> list[str]:
+    """Conditions stating a route's scopes, and whether anything enforces them."""
+    if not chain.scopes:
+        return []
+    scopes = " and ".join(chain.scopes)
+    requirement = f"requires scope{'s' if len(chain.scopes) > 1 else ''} {scopes}"
+    if chain.scopes_checked:
+        return [requirement]
+    return [f"{requirement}, but no dependency checks SecurityScopes so the scopes are not enforced"]
+
+
+def _finding(route: _Route, resource: str, method: str, access: Access) -> ConfigFinding:
+    """Finding for one path operation."""
+    node = route.function
+    line_start = min([d.lineno for d in node.decorator_list] + [node.lineno])
+    line_end = min(node.end_lineno or node.lineno, line_start + MAX_SNIPPET_LINES - 1)
+    return ConfigFinding(
+        kind=FastApiRouteKind.ROUTE,
+        file_path=route.module.path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(route.module.text, line_start, line_end),
+        subject=access.subject,
+        resource=resource,
+        action=method,
+        conditions=access.conditions,
+        description=f"FastAPI route {node.name} secured by {access.source}",
+        disables_auth=access.subject == ANONYMOUS and not access.conditions,
+    )
+
+
+def extract_fastapi_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract per-route role and scope requirements from a FastAPI service's dependencies.
+
+    Args:
+        files: Relative path -> content; non-Python files are ignored
+
+    Returns:
+        One finding per method of each path operation whose dependencies authenticate or authorize the caller
+    """
+    sources = {p: t for p, t in sorted(files.items()) if p.endswith(".py")}
+    if not any(FASTAPI_MARKER.search(t) for t in sources.values()):
+        return []
+    modules = [m for m in (parse_module(p, t) for p, t in sources.items()) if m is not None]
+    project = _FastApiProject(modules)
+
+    findings, seen = [], set()
+    for route in project.routes:
+        parameters = [
+            (owner, marker, declared_type, _source(marker))
+            for arg, default in _parameters(route.function)
+            for owner, marker, declared_type in project.markers(route.module, arg, default)
+        ]
+        for prefix, inherited in project.paths(route.router):
+            chain, sources = _Chain(), []
+            declared = [(m, d, None, s) for m, d, s in inherited + route.dependencies] + parameters
+            for owner, marker, declared_type, source in declared:
+                before = len(chain.via)
+                checks = len(chain.checks)
+                project.follow(owner, marker, declared_type, chain, 0)
+                if len(chain.checks) == checks and _call_name(marker) != "Security":
+                    continue
+                via = chain.via[before:]
+                sources.append(f"{source} (via {', '.join(via)})" if len(via) > 1 else source)
+            if not chain.checks and not chain.scopes:
+                continue
+            resource = _join(prefix, route.path)
+            for method in route.methods:
+                rule = _evaluate(_All(chain.checks), method, None)
+                rule.conditions = rule.conditions + [c for c in _scope_conditions(chain) if c not in rule.conditions]
+                if rule.decision == ANONYMOUS and not rule.roles and chain.scopes:
+                    rule.decision = AUTHENTICATED
+                finding = _finding(route, resource, method, _access(rule, " and ".join(sources)))
+                key = (finding.file_path, finding.line_start, finding.resource, finding.action)
+                if key not in seen:
+                    seen.add(key)
+                    findings.append(finding)
+    return findings
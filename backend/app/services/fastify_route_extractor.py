@@ -0,0 +1,487 @@
+"""Extract route authorization from Fastify applications.
+
+Fastify attaches authorization to encapsulation contexts rather than to
+individual routes. A request hook added with addHook() guards every route of
+the plugin it is added in and of the plugins that plugin registers, while
+wrapping a plugin in fastify-plugin (fp) lifts its hooks into the parent
+context. Guards are usually decorators (fastify.decorate('verifyAdmin', ...))
+combined with @fastify/auth (fastify.auth([a, b], { relation: 'and' })), and
+routes can declare OpenAPI security requirements in their schema. This
+extractor follows plugins registered across files, resolves each route's
+hooks, options, and schema through the TypeScript environment of
+typescript_route_extractor, and produces one ConfigFinding per guarded route
+without LLM calls.
+
+Schema security requirements are reported as declared: Fastify itself does
+not enforce them, so the finding notes that they come from the schema.
+"""
+
+import re
+from dataclasses import dataclass, field
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import group
+from app.services.node_route_extractor import (
+    AUTHENTICATION_MIDDLEWARE,
+    IMPORT_DEFAULT,
+    REQUIRE,
+    _resolve_import,
+    join_paths,
+    statement_end,
+)
+from app.services.node_route_extractor import DECLARATION as REQUIRE_DECLARATION
+from app.services.typescript_route_extractor import (
+    IDENTIFIER,
+    MAX_RESOLVE_DEPTH,
+    Guard,
+    TsFile,
+    TypeEnvironment,
+    build_environment,
+    call_args,
+    dedupe,
+    prepare_file,
+    resolve_guard,
+    route_finding,
+    route_receivers,
+    split_top_level,
+    with_label,
+)
+
+# Files without one of these are not read as part of a Fastify application
+FASTIFY_MARKER = re.compile(
+    r"['\"](?:fastify|fastify-plugin|@fastify/[\w.-]+)['\"]|\bfastify\s*\.\s*(?:addHook|register|route|decorate)\s*\("
+)
+
+FASTIFY_RECEIVER = re.compile(r"(?:fastify|Fastify)\s*\(")
+FASTIFY_CALL = re.compile(
+    rf"(?<![\w$.])({IDENTIFIER})\s*\.\s*(get|post|put|patch|delete|options|head|all|route|addHook|register|decorate)\s*(?=[<(])"
+)
+# A function whose first parameter may be a Fastify instance: function name(fastify, ...) or (fastify, ...) =>
+FUNCTION_START = re.compile(
+    rf"(\bfunction\b\s*\*?\s*({IDENTIFIER})?\s*(?:<[^>(]*>)?\s*)?\(\s*({IDENTIFIER})\s*[,:)]"
+)
+BARE_ARROW = re.compile(rf"(?<![\w$.])({IDENTIFIER})\s*=>\s*")
+ARROW = re.compile(r"\s*(?::[^=;{}\n]{0,200}?)?=>\s*")
+NAMED_BEFORE = re.compile(
+    rf"\b(?:const|let|var)\s+({IDENTIFIER})\s*(?::[^=;]*)?=\s*(?:(?:fp|fastifyPlugin)\s*\(\s*)?(?:async\s*)?$"
+)
+WRAPPED_BEFORE = re.compile(r"\b(?:fp|fastifyPlugin)\s*\(\s*(?:async\s*)?$")
+EXPORTED_BEFORE = re.compile(
+    r"(?:\bexport\s+default|\bmodule\s*\.\s*exports\s*=)\s*(?:(?:fp|fastifyPlugin)\s*\(\s*)?(?:async\s*)?$"
+)
+EXPORTED_NAME = re.compile(
+    rf"(?:\bexport\s+default|\bmodule\s*\.\s*exports\s*=)\s*((?:fp|fastifyPlugin)\s*\(\s*)?({IDENTIFIER})\s*[,;)\n]"
+)
+WRAPPED = re.compile(rf"^(?:fp|fastifyPlugin)\s*\(\s*({IDENTIFIER})\s*[,)]")
+DYNAMIC_IMPORT = re.compile(r"^(?:require|import)\s*\(\s*['\"]([^'\"]+)['\"]\s*\)$")
+FUNCTION_LITERAL = re.compile(rf"^(?:async\s+)?(?:function\b|\([^()]*\)\s*(?::[^=]*)?=>|{IDENTIFIER}\s*=>)")
+AUTH_COMPOSITION = re.compile(rf"^(?:{IDENTIFIER}\s*\.\s*)?auth\s*\(")
+RELATION = re.compile(r"\brelation\s*:\s*['\"](and|or)['\"]")
+DECORATED = re.compile(rf"^{IDENTIFIER}\s*\.\s*({IDENTIFIER})$")
+# Checks inside inline hooks and decorated functions
+ROLE_COMPARISON = re.compile(r"\broles?\b[^;\n]{0,80}?(?:[!=]==?|includes\s*\()\s*['\"]([^'\"]+)['\"]")
+DENIAL = re.compile(r"\b(?:jwtVerify|Unauthorized|Forbidden)\b|\.\s*(?:code|status)\s*\(\s*40[13]\s*\)")
+
+HOOKS = ("onRequest", "preParsing", "preValidation", "preHandler")
+
+
+class FastifyRouteKind:
+    """Kinds of Fastify route findings."""
+
+    ROUTE = "fastify_route"
+
+
+@dataclass
+class _Plugin:
+    """An encapsulation context: a plugin function, or an instance created at a file's top level."""
+
+    start: int
+    lifted: bool = False  # Wrapped in fastify-plugin
+    parent: int | None = None
+    prefix: str = ""
+
+
+@dataclass
+class _Route:
+    """A route registered on a plugin's instance."""
+
+    plugin: int
+    ts: TsFile
+    span: tuple[int, int]
+    methods: list[str]
+    path: str
+    guards: list[Guard]
+
+
+@dataclass
+class _Registration:
+    """A plugin registered with .register()."""
+
+    plugin: int
+    ts: TsFile
+    expression: str
+    prefix: str
+
+
+@dataclass
+class _Module:
+    """Plugins and imports of one file."""
+
+    ts: TsFile
+    named: dict[str, int] = field(default_factory=dict)  # Function name -> plugin
+    default: int | None = None
+    imports: dict[str, str] = field(default_factory=dict)  # Local name -> import specifier
+
+
+@dataclass
+class _Context:
+    """State shared while resolving an application's guards."""
+
+    env: TypeEnvironment
+    decorators: dict[str, tuple[str, TsFile]] = field(default_factory=dict)  # Name -> (expression, file)
+    plugins: list[_Plugin] = field(default_factory=list)
+    hooks: dict[int, list[Guard]] = field(default_factory=dict)  # Plugin -> request hooks added in it
+    routes: list[_Route] = field(default_factory=list)
+    registrations: list[_Registration] = field(default_factory=list)
+
+
+def _function_guard(label: str, body: str) -> Guard | None:
+    """Guard of an inline hook or decorated function from the checks in its body."""
+    roles = dedupe(ROLE_COMPARISON.findall(body))
+    if roles:
+        return Guard(label, roles=roles)
+    if DENIAL.search(body) or AUTHENTICATION_MIDDLEWARE.search(body):
+        return Guard(label)
+    return None
+
+
+def _requirement(guard: Guard) -> str:
+    """What a guard requires, for describing alternatives."""
+    parts = [f"role {' or '.join(guard.roles)}"] if guard.roles else []
+    parts += [f"permission {p}" for p in guard.permissions]
+    return f"{guard.label} ({', '.join(parts)})" if parts else guard.label
+
+
+def _combined(label: str, guards: list[Guard]) -> Guard:
+    """One guard requiring everything the given guards do."""
+    unresolved = [g.unresolved for g in guards if g.unresolved]
+    return Guard(
+        label,
+        roles=dedupe([role for g in guards for role in g.roles]),
+        permissions=dedupe([p for g in guards for p in g.permissions]),
+        unresolved="; ".join(unresolved) or None,
+        conditions=dedupe([c for g in guards for c in g.conditions]),
+    )
+
+
+def _composition(label: str, expression: str, paren: int, ts: TsFile, context: _Context, depth: int) -> Guard:
+    """Guard of an @fastify/auth composition; members are alternatives unless the relation is "and"."""
+    args = split_top_level(expression[paren + 1 : group(expression, paren) - 1], ",")
+    members = split_top_level(args[0][1:-1], ",") if args and args[0].startswith("[") else args[:1]
+    relation = RELATION.search(args[1]) if len(args) > 1 else None
+    # Every member is an auth function, so one that resolves to nothing still authenticates
+    resolved = [
+        _combined(" ".join(member.split()), _resolve(member, ts, context, depth + 1)) for member in members
+    ]
+    if len(resolved) <= 1 or (relation and relation.group(1) == "and"):
+        return _combined(label, resolved)
+    alternatives = f"passes any of {', '.join(_requirement(g) for g in resolved)}"
+    if all(g.roles and not g.permissions and not g.unresolved for g in resolved):
+        return Guard(label, roles=dedupe([role for g in resolved for role in g.roles]), conditions=[alternatives])
+    return Guard(label, conditions=[alternatives])
+
+
+def _resolve(
+    expression: str, ts: TsFile, context: _Context, depth: int = 0, inline_label: str = "inline function"
+) -> list[Guard]:
+    """Guards of a hook or hook option: a function, decorator, auth composition, or array of them."""
+    expression = expression.strip()
+    if depth > MAX_RESOLVE_DEPTH or not expression:
+        return []
+    if expression.startswith("[") and expression.endswith("]"):
+        return [g for item in split_top_level(expression[1:-1], ",") for g in _resolve(item, ts, context, depth + 1, inline_label)]
+    label = " ".join(expression.split())
+    composition = AUTH_COMPOSITION.match(expression)
+    if composition:
+        return [_composition(label, expression, composition.end() - 1, ts, context, depth)]
+    if FUNCTION_LITERAL.match(expression):
+        guard = _function_guard(inline_label, expression)
+        return [guard] if guard else []
+    reference = DECORATED.match(expression)
+    if reference and reference.group(1) in context.decorators:
+        decorated, decorated_ts = context.decorators[reference.group(1)]
+        guards = _resolve(decorated, decorated_ts, context, depth + 1, label)
+        if guards:
+            return [with_label(label, g) for g in guards]
+    guard = resolve_guard(expression, context.env, ts.renames)
+    return [guard] if guard else []
+
+
+def _schema_guard(ts: TsFile, span: tuple[int, int]) -> Guard | None:
+    """Guard declared by a route schema's OpenAPI security requirements.
+
+    Requirements are alternatives; schemes within one are all required. An
+    empty list, or an empty requirement, leaves the route public.
+    """
+    src = ts.src
+    start, end = span
+    name = src.masked[start:end]
+    if re.fullmatch(IDENTIFIER, name):
+        declared = next((m for m in REQUIRE_DECLARATION.finditer(src.masked) if m.group(1) == name), None)
+        if declared is None or src.masked[declared.end() : declared.end() + 1] != "{":
+            return None
+        start = declared.end()
+        end = src.pairs.get(start, len(src.masked))
+    security = src.entries(start, end).get("security")
+    if security is None or src.masked[security[0]] != "[":
+        return None
+    requirements = []
+    for s, e in src.split(security[0] + 1, security[1] - 1):
+        schemes = src.entries(s, e)
+        requirement = {}
+        for scheme, value in schemes.items():
+            scopes = src.literal(*value)
+            requirement[scheme] = scopes if isinstance(scopes, list) else []
+        requirements.append(requirement)
+    if not requirements or not all(requirements):
+        return None
+    label = "schema security " + " or ".join(" and ".join(r) for r in requirements)
+    declared_note = "declared in the route schema"
+    if len(requirements) == 1:
+        scopes = dedupe([scope for scopes in requirements[0].values() for scope in scopes])
+        return Guard(label, permissions=scopes, conditions=[declared_note])
+    alternatives = [
+        " and ".join(f"{scheme} ({', '.join(scopes)})" if scopes else scheme for scheme, scopes in r.items())
+        for r in requirements
+    ]
+    return Guard(label, conditions=[f"accepts any of {', '.join(alternatives)}", declared_note])
+
+
+def _functions(ts: TsFile, receivers: set[str]) -> list[tuple[int, str | None, str, tuple[int, int]]]:
+    """Functions whose first parameter is a receiver name, as (start, function name, parameter, body)."""
+    src = ts.src
+    masked, n = src.masked, len(src.masked)
+    found = []
+    for match in FUNCTION_START.finditer(masked):
+        param = match.group(3)
+        if param not in receivers:
+            continue
+        paren = match.end(1) if match.group(1) is not None else match.start()
+        close = src.pairs.get(paren, n)
+        if match.group(1) is not None:
+            brace = masked.find("{", close)
+            if brace < 0:
+                continue
+            found.append((match.start(), match.group(2), param, (brace, src.pairs.get(brace, n))))
+            continue
+        arrow = ARROW.match(masked, close)
+        if arrow:
+            b = arrow.end()
+            body = (b, src.pairs.get(b, n)) if masked[b : b + 1] == "{" else (b, statement_end(src, b))
+            found.append((match.start(), None, param, body))
+    for match in BARE_ARROW.finditer(masked):
+        if match.group(1) in receivers:
+            b = match.end()
+            body = (b, src.pairs.get(b, n)) if masked[b : b + 1] == "{" else (b, statement_end(src, b))
+            found.append((match.start(), None, match.group(1), body))
+    return found
+
+
+def _parse(ts: TsFile, context: _Context) -> _Module:
+    """Collect a file's plugins, routes, hooks, and registrations."""
+    src = ts.src
+    masked = src.masked
+    module = _Module(ts)
+    for match in IMPORT_DEFAULT.finditer(masked):
+        specifier = ts.src.text[match.start(2) : match.end(2)]
+        module.imports[match.group(1)] = specifier
+    for match in REQUIRE_DECLARATION.finditer(masked):
+        required = REQUIRE.match(ts.code[match.end() : statement_end(src, match.end())].strip())
+        if required:
+            module.imports[match.group(1)] = required.group(1)
+
+    calls = list(FASTIFY_CALL.finditer(masked))
+    receivers = {m.group(1) for m in calls}
+    roots = route_receivers(ts, FASTIFY_RECEIVER, ("FastifyInstance",)) | {"fastify"}
+    scopes: list[tuple[int, str, tuple[int, int]]] = []  # (plugin, parameter, body)
+    for start, name, param, body in _functions(ts, receivers):
+        before = masked[max(0, start - 200) : start]
+        named = NAMED_BEFORE.search(before)
+        plugin = _Plugin(start, lifted=bool(WRAPPED_BEFORE.search(before)))
+        context.plugins.append(plugin)
+        index = len(context.plugins) - 1
+        scopes.append((index, param, body))
+        if name or named:
+            module.named.setdefault(name or named.group(1), index)
+        if EXPORTED_BEFORE.search(before) and module.default is None:
+            module.default = index
+    for match in EXPORTED_NAME.finditer(masked):
+        index = module.named.get(match.group(2))
+        if index is not None and module.default is None:
+            module.default = index
+            context.plugins[index].lifted |= bool(match.group(1))
+
+    root_plugins: dict[str, int] = {}
+
+    def owner(receiver: str, position: int) -> int | None:
+        enclosing = [i for i, param, (s, e) in scopes if param == receiver and s <= position < e]
+        if enclosing:
+            return max(enclosing, key=lambda i: context.plugins[i].start)
+        if receiver not in roots:
+            return None
+        if receiver not in root_plugins:
+            context.plugins.append(_Plugin(0))
+            root_plugins[receiver] = len(context.plugins) - 1
+        return root_plugins[receiver]
+
+    for match in calls:
+        receiver, verb = match.group(1), match.group(2)
+        if verb == "decorate":
+            continue
+        plugin = owner(receiver, match.start())
+        if plugin is None:
+            continue
+        args, close = call_args(ts, match.end())
+        if not args:
+            continue
+        if verb == "addHook":
+            hook = src.literal(*args[0])
+            if hook in HOOKS and len(args) > 1:
+                guards = _resolve(ts.code[args[1][0] : args[1][1]], ts, context, inline_label=f"{hook} hook")
+                context.hooks.setdefault(plugin, []).extend(guards)
+        elif verb == "register":
+            options = src.entries(*args[1]) if len(args) > 1 else {}
+            prefix = src.literal(*options["prefix"]) if "prefix" in options else ""
+            prefix = prefix if isinstance(prefix, str) else ""
+            inline = [i for i, _, _ in scopes if args[0][0] <= context.plugins[i].start < args[0][1]]
+            if inline:
+                _attach(context, min(inline, key=lambda i: context.plugins[i].start), plugin, prefix)
+            else:
+                context.registrations.append(_Registration(plugin, ts, ts.code[args[0][0] : args[0][1]].strip(), prefix))
+        else:
+            _route(ts, context, plugin, verb, args, (match.start(), close))
+    return module
+
+
+def _route(
+    ts: TsFile, context: _Context, plugin: int, verb: str, args: list[tuple[int, int]], span: tuple[int, int]
+) -> None:
+    """Record a shorthand or .route() registration with its hook options and schema."""
+    src = ts.src
+    if verb == "route":
+        options = src.entries(*args[0])
+        path = src.literal(*options["url"]) if "url" in options else None
+        methods = src.literal(*options["method"]) if "method" in options else None
+        methods = [methods] if isinstance(methods, str) else methods or []
+    else:
+        path = src.literal(*args[0])
+        options = src.entries(*args[1]) if len(args) >= 2 else {}
+        methods = ["*" if verb == "all" else verb]
+    if not isinstance(path, str) or not path.startswith("/"):
+        return
+    guards = []
+    for hook in HOOKS:
+        if hook in options:
+            s, e = options[hook]
+            guards.extend(_resolve(ts.code[s:e], ts, context, inline_label=f"{hook} hook"))
+    schema = _schema_guard(ts, options["schema"]) if "schema" in options else None
+    if schema:
+        guards.append(schema)
+    context.routes.append(_Route(plugin, ts, span, [m.upper() for m in methods], path, guards))
+
+
+def _attach(context: _Context, child: int, parent: int, prefix: str) -> None:
+    """Make a plugin a child of the context registering it, unless it already has a parent."""
+    ancestor: int | None = parent
+    while ancestor is not None:
+        if ancestor == child:
+            return
+        ancestor = context.plugins[ancestor].parent
+    plugin = context.plugins[child]
+    if plugin.parent is None:
+        plugin.parent, plugin.prefix = parent, prefix
+
+
+def _link(context: _Context, modules: dict[str, _Module]) -> None:
+    """Attach plugins registered by name or by import to the contexts registering them."""
+    for registration in context.registrations:
+        expression = registration.expression
+        wrapped = WRAPPED.match(expression)
+        name = wrapped.group(1) if wrapped else expression
+        module = modules[registration.ts.file_path]
+        dynamic = DYNAMIC_IMPORT.match(expression)
+        target = None
+        if dynamic:
+            imported = _resolve_import(module.ts.file_path, dynamic.group(1), modules)
+            target = imported.default if imported else None
+        elif name in module.named:
+            target = module.named[name]
+        elif name in module.imports:
+            imported = _resolve_import(module.ts.file_path, module.imports[name], modules)
+            target = imported.default if imported else None
+        if target is not None:
+            context.plugins[target].lifted |= bool(wrapped)
+            _attach(context, target, registration.plugin, registration.prefix)
+
+
+def _context_of(context: _Context, plugin: int) -> int:
+    """The context a plugin's hooks apply to: fastify-plugin lifts them into the registering context."""
+    seen = set()
+    while context.plugins[plugin].lifted and context.plugins[plugin].parent is not None and plugin not in seen:
+        seen.add(plugin)
+        plugin = context.plugins[plugin].parent
+    return plugin
+
+
+def extract_fastify_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Fastify route authorization from an application's files.
+
+    Args:
+        files: Relative path -> content of the application's JS/TS files
+
+    Returns:
+        Route findings across all files
+    """
+    sources = {path: files[path] for path in sorted(files) if FASTIFY_MARKER.search(files[path])}
+    if not sources:
+        return []
+    context = _Context(build_environment(files))
+    prepared = {path: prepare_file(path, text) for path, text in sources.items()}
+    for ts in prepared.values():
+        for match in FASTIFY_CALL.finditer(ts.src.masked):
+            if match.group(2) != "decorate":
+                continue
+            args, _ = call_args(ts, match.end())
+            name = ts.src.literal(*args[0]) if args else None
+            if isinstance(name, str) and len(args) > 1:
+                context.decorators.setdefault(name, (ts.code[args[1][0] : args[1][1]], ts))
+
+    modules = {path: _parse(ts, context) for path, ts in prepared.items()}
+    _link(context, modules)
+
+    # Hooks added in a plugin guard its own routes and those of every plugin it registers
+    applied: dict[int, list[Guard]] = {}
+    for plugin, guards in context.hooks.items():
+        applied.setdefault(_context_of(context, plugin), []).extend(guards)
+
+    findings = []
+    for route in context.routes:
+        guards, prefixes, seen = [], [], set()
+        plugin: int | None = route.plugin
+        while plugin is not None and plugin not in seen:
+            seen.add(plugin)
+            prefixes.insert(0, context.plugins[plugin].prefix)
+            plugin = context.plugins[plugin].parent
+        current: int | None = _context_of(context, route.plugin)
+        seen = set()
+        while current is not None and current not in seen:
+            seen.add(current)
+            guards = applied.get(current, []) + guards
+            current = context.plugins[current].parent
+        guards += route.guards
+        if not guards:
+            continue
+        path = join_paths(*prefixes, route.path)
+        for method in route.methods:
+            findings.append(route_finding(FastifyRouteKind.ROUTE, route.ts, route.span, method, path, guards, "Fastify"))
+    return findings
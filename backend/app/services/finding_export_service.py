@@ -0,0 +1,562 @@
+"""Service for tagged findings across analyzers and their SARIF and CSV exports.
+
+Findings stored by scans (conflicts, inconsistent enforcement, security
+gaps, hard-coded secrets) and findings computed from repository clones
+(credentialed wildcard CORS, exposed admin endpoints, confused deputies,
+known-vulnerable auth patterns, BOLA candidates, mass assignment,
+sensitive response fields, missing tenant isolation, routes with no
+authentication or authorization, endpoints of one resource authorized
+inconsistently) are collected into one list, each tagged with
+its CWE weaknesses and OWASP API Security Top 10 categories. The list is filterable by those tags and exports
+as SARIF 2.1.0, for code scanning dashboards, or as CSV. Each finding carries a key that stays the same
+across scans while it is detected; findings whose risk was accepted through an ITSM ticket are left out.
+"""
+
+import csv
+import hashlib
+import io
+from collections.abc import Callable
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.conflict import ConflictStatus, PolicyConflict
+from app.models.inconsistent_enforcement import InconsistentEnforcement, InconsistentEnforcementStatus
+from app.models.itsm_connector import FindingTicket, FindingTicketStatus
+from app.models.policy import Policy
+from app.models.policy_fix import FixStatus, PolicyFix
+from app.models.repository import Repository
+from app.models.secret_detection import SecretDetectionLog
+from app.services.admin_surface_service import AdminProtection, AdminSurfaceService
+from app.services.auth_gap_service import AuthGapService
+from app.services.bola_detection_service import BolaDetectionService
+from app.services.cors_csrf_service import CorsCsrfService, scope_matches
+from app.services.endpoint_consistency_service import EndpointConsistencyService
+from app.services.finding_taxonomy import (
+    CWE_NAMES,
+    OWASP_API_NAMES,
+    TAXONOMY,
+    FindingType,
+    OwaspApiCategory,
+    classify,
+    matches,
+)
+from app.services.identity_propagation_service import IdentityPropagationService
+from app.services.mass_assignment_service import MassAssignmentService
+from app.services.response_exposure_service import ResponseExposureService
+from app.services.tenant_isolation_service import TenantIsolationService
+from app.services.vulnerable_auth_pattern_service import VulnerableAuthPatternService
+
+logger = structlog.get_logger(__name__)
+
+SARIF_SCHEMA = "https://json.schemastore.org/sarif-2.1.0.json"
+TOOL_NAME = "Policy Miner"
+
+SEVERITY_ORDER = {"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+# SARIF result level and security-severity score (as code scanning dashboards read it) per severity
+SARIF_LEVELS = {"critical": "error", "high": "error", "medium": "warning", "low": "note"}
+SECURITY_SEVERITY = {"critical": "9.5", "high": "8.0", "medium": "5.5", "low": "3.0"}
+
+OPEN_ENFORCEMENT_STATUSES = [InconsistentEnforcementStatus.PENDING, InconsistentEnforcementStatus.ACKNOWLEDGED]
+OPEN_FIX_STATUSES = [FixStatus.PENDING, FixStatus.REVIEWED]
+
+# Hard-coded secrets rank as high; detections carry no severity of their own
+SECRET_SEVERITY = "high"
+ADMIN_SEVERITY = {AdminProtection.PUBLIC: "high", AdminProtection.AUTHENTICATED: "medium", AdminProtection.NO_POLICY: "medium"}
+
+CSV_COLUMNS = [
+    "finding_type",
+    "kind",
+    "rule_id",
+    "severity",
+    "cwe",
+    "owasp_api",
+    "repository",
+    "file_path",
+    "line_start",
+    "description",
+]
+
+
+def finding_key(finding: dict) -> str:
+    """Key identifying a finding across scans: its rule, repository, location file, and description."""
+    parts = (finding["rule_id"], str(finding["repository_id"]), finding["file_path"] or "", finding["description"] or "")
+    return f"fnd_{hashlib.sha256(chr(31).join(parts).encode()).hexdigest()[:16]}"
+
+
+def _cwe_uri(cwe: str) -> str:
+    """MITRE page of a CWE."""
+    return f"https://cwe.mitre.org/data/definitions/{cwe.removeprefix('CWE-')}.html"
+
+
+def to_sarif(findings: list[dict]) -> dict:
+    """SARIF 2.1.0 log of tagged findings, one rule per taxonomy entry used.
+
+    Args:
+        findings: Findings as returned by FindingExportService.findings
+
+    Returns:
+        SARIF log with CWE and OWASP API tags on every rule and result
+    """
+    rules: list[dict] = []
+    rule_index: dict[str, int] = {}
+    results = []
+    for finding in findings:
+        entry = classify(finding["finding_type"], finding["kind"])
+        if entry.rule_id not in rule_index:
+            rule_index[entry.rule_id] = len(rules)
+            tags = ["security", *entry.cwe, *(f"OWASP-{o.value}" for o in entry.owasp_api)]
+            rules.append(
+                {
+                    "id": entry.rule_id,
+                    "name": entry.title,
+                    "shortDescription": {"text": entry.title},
+                    "fullDescription": {
+                        "text": "; ".join(
+                            [f"{c} {CWE_NAMES.get(c, '')}".strip() for c in entry.cwe]
+                            + [f"OWASP {o.value} {OWASP_API_NAMES[o]}" for o in entry.owasp_api]
+                        )
+                    },
+                    "helpUri": _cwe_uri(entry.cwe[0]),
+                    "properties": {"tags": tags},
+                }
+            )
+        rule = rules[rule_index[entry.rule_id]]
+        score = SECURITY_SEVERITY.get(finding["severity"], SECURITY_SEVERITY["medium"])
+        if float(score) > float(rule["properties"].get("security-severity", "0")):
+            rule["properties"]["security-severity"] = score
+
+        result = {
+            "ruleId": entry.rule_id,
+            "ruleIndex": rule_index[entry.rule_id],
+            "level": SARIF_LEVELS.get(finding["severity"], "warning"),
+            "message": {"text": finding["description"]},
+            "properties": {
+                "severity": finding["severity"],
+                "cwe": finding["cwe"],
+                "owasp_api": finding["owasp_api"],
+                "repository": finding["repository_name"],
+                "policy_ids": finding["policy_ids"],
+            },
+        }
+        if finding["file_path"]:
+            region = {"startLine": finding["line_start"] or 1}
+            if finding["line_end"]:
+                region["endLine"] = finding["line_end"]
+            result["locations"] = [
+                {"physicalLocation": {"artifactLocation": {"uri": finding["file_path"]}, "region": region}}
+            ]
+        results.append(result)
+
+    return {
+        "$schema": SARIF_SCHEMA,
+        "version": "2.1.0",
+        "runs": [{"tool": {"driver": {"name": TOOL_NAME, "rules": rules}}, "results": results}],
+    }
+
+
+def to_csv(findings: list[dict]) -> str:
+    """CSV export of tagged findings, one row per finding."""
+    output = io.StringIO()
+    writer = csv.writer(output)
+    writer.writerow(CSV_COLUMNS)
+    for f in findings:
+        writer.writerow(
+            [
+                f["finding_type"].value,
+                f["kind"] or "",
+                f["rule_id"],
+                f["severity"],
+                " ".join(f["cwe"]),
+                " ".join(f["owasp_api"]),
+                f["repository_name"] or "",
+                f["file_path"] or "",
+                f["line_start"] or "",
+                f["description"],
+            ]
+        )
+    return output.getvalue()
+
+
+class FindingExportService:
+    """Collects findings from every analyzer with their CWE and OWASP API tags."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = clone_dir or settings.REPO_CLONE_DIR
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def _repository_names(self) -> dict[int, str]:
+        """Names of the tenant's repositories by ID."""
+        return {r.id: r.name for r in self._query(Repository).order_by(Repository.id).all()}
+
+    def _stored(self) -> list[dict]:
+        """Open findings recorded by scans, located at their policies' first evidence."""
+        raw = []
+        for conflict in self._query(PolicyConflict).filter(PolicyConflict.status == ConflictStatus.PENDING).all():
+            kind = conflict.conflict_type.value if conflict.conflict_type else None
+            raw.append((FindingType.CONFLICT, kind, conflict.severity, conflict.description, [conflict.policy_a_id, conflict.policy_b_id]))
+        for finding in (
+            self._query(InconsistentEnforcement).filter(InconsistentEnforcement.status.in_(OPEN_ENFORCEMENT_STATUSES)).all()
+        ):
+            severity = finding.severity.value if finding.severity else "medium"
+            raw.append(
+                (FindingType.INCONSISTENT_ENFORCEMENT, None, severity, finding.inconsistency_description, list(finding.policy_ids or []))
+            )
+        for fix in self._query(PolicyFix).filter(PolicyFix.status.in_(OPEN_FIX_STATUSES)).all():
+            severity = fix.severity.value if fix.severity else "medium"
+            raw.append((FindingType.SECURITY_GAP, fix.security_gap_type, severity, fix.gap_description, [fix.policy_id]))
+
+        policy_ids = {pid for *_, ids in raw for pid in ids if pid is not None}
+        policies = {p.id: p for p in self._query(Policy).filter(Policy.id.in_(policy_ids)).all()} if policy_ids else {}
+        findings = []
+        for finding_type, kind, severity, description, ids in raw:
+            located = [policies[pid] for pid in ids if pid in policies]
+            evidence = next((e for p in located for e in p.evidence or []), None)
+            findings.append(
+                {
+                    "finding_type": finding_type,
+                    "kind": kind,
+                    "severity": (severity or "medium").lower(),
+                    "description": description,
+                    "repository_id": located[0].repository_id if located else None,
+                    "repository_ids": sorted({p.repository_id for p in located}),
+                    "file_path": evidence.file_path if evidence else None,
+                    "line_start": evidence.line_start if evidence else None,
+                    "line_end": evidence.line_end if evidence else None,
+                    "policy_ids": ids,
+                }
+            )
+
+        for secret in self._query(SecretDetectionLog).order_by(SecretDetectionLog.id).all():
+            findings.append(
+                {
+                    "finding_type": FindingType.SECRET_IN_CODE,
+                    "kind": secret.secret_type,
+                    "severity": SECRET_SEVERITY,
+                    "description": secret.description,
+                    "repository_id": secret.repository_id,
+                    "file_path": secret.file_path,
+                    "line_start": secret.line_number,
+                    "line_end": secret.line_number,
+                    "policy_ids": [],
+                }
+            )
+        return findings
+
+    def _credentialed_cors(self) -> list[dict]:
+        """Authenticated endpoints any origin may call with credentials, at the CORS setting allowing it."""
+        findings = []
+        for service in CorsCsrfService(self.db, self.tenant_id, self.clone_dir).services(flagged_only=True):
+            wildcards = [s for s in service["cors"] if s["credentialed_wildcard"]]
+            for endpoint in service["endpoints"]:
+                if not endpoint["credentialed_wildcard"]:
+                    continue
+                setting = next((s for s in wildcards if s["scope"] is None or scope_matches(s["scope"], endpoint["path"])), None)
+                findings.append(
+                    {
+                        "finding_type": FindingType.CREDENTIALED_CORS,
+                        "kind": None,
+                        "severity": "high",
+                        "description": f"{endpoint['endpoint']} requires authentication but any origin may call it with credentials",
+                        "repository_id": service["repository_id"],
+                        "file_path": setting["file_path"] if setting else None,
+                        "line_start": setting["line_start"] if setting else None,
+                        "line_end": setting["line_end"] if setting else None,
+                        "policy_ids": endpoint["policy_ids"],
+                    }
+                )
+        return findings
+
+    def _exposed_admin_endpoints(self) -> list[dict]:
+        """Admin endpoints open to anonymous callers, any signed-in user, or no mined policy."""
+        findings = []
+        for service in AdminSurfaceService(self.db, self.tenant_id, self.clone_dir).services(exposed_only=True):
+            for endpoint in service["endpoints"]:
+                if not endpoint["exposed"]:
+                    continue
+                protection = AdminProtection(endpoint["protection"])
+                surface = next(
+                    (s for s in service["surfaces"] if s["file_path"] and s["path"] and scope_matches(f"{s['path'].rstrip('/')}/**", endpoint["path"])),
+                    None,
+                )
+                findings.append(
+                    {
+                        "finding_type": FindingType.EXPOSED_ADMIN_ENDPOINT,
+                        "kind": protection.value,
+                        "severity": ADMIN_SEVERITY[protection],
+                        "description": f"Admin endpoint {endpoint['endpoint']} is {protection.value.replace('_', ' ')}",
+                        "repository_id": service["repository_id"],
+                        "file_path": surface["file_path"] if surface else None,
+                        "line_start": surface["line_start"] if surface else None,
+                        "line_end": surface["line_start"] if surface else None,
+                        "policy_ids": endpoint["policy_ids"],
+                    }
+                )
+        return findings
+
+    def _confused_deputies(self) -> list[dict]:
+        """Service identities acting on request input the caller never checks, at the call site."""
+        return [
+            {
+                "finding_type": FindingType.CONFUSED_DEPUTY,
+                "kind": None,
+                "severity": deputy["severity"],
+                "description": deputy["description"],
+                "repository_id": deputy["source_repository_id"],
+                "file_path": deputy["file_path"],
+                "line_start": deputy["line_start"],
+                "line_end": deputy["line_end"],
+                "policy_ids": deputy["target_policy_ids"],
+            }
+            for deputy in IdentityPropagationService(self.db, self.tenant_id, self.clone_dir).analyze()["deputies"]
+        ]
+
+    def _vulnerable_auth_patterns(self) -> list[dict]:
+        """Known-vulnerable authentication patterns in repository clones."""
+        return [
+            {
+                "finding_type": FindingType.VULNERABLE_AUTH_PATTERN,
+                "kind": f["kind"].value,
+                "severity": f["severity"],
+                "description": f["description"],
+                "repository_id": f["repository_id"],
+                "file_path": f["file_path"],
+                "line_start": f["line_start"],
+                "line_end": f["line_end"],
+                "policy_ids": [],
+            }
+            for f in VulnerableAuthPatternService(self.db, self.tenant_id, self.clone_dir).findings()["findings"]
+        ]
+
+    def _bola_candidates(self) -> list[dict]:
+        """Lookups by client-supplied IDs no ownership check covers, at the lookup."""
+        return [
+            {
+                "finding_type": FindingType.BOLA_CANDIDATE,
+                "kind": None,
+                "severity": c["severity"],
+                "description": c["description"],
+                "repository_id": c["repository_id"],
+                "file_path": c["file_path"],
+                "line_start": c["line_start"],
+                "line_end": c["line_end"],
+                "policy_ids": [],
+            }
+            for c in BolaDetectionService(self.db, self.tenant_id, self.clone_dir).candidates()["findings"]
+        ]
+
+    def _mass_assignments(self) -> list[dict]:
+        """Request body binds reaching privileged model fields, at the bind."""
+        return [
+            {
+                "finding_type": FindingType.MASS_ASSIGNMENT,
+                "kind": None,
+                "severity": c["severity"],
+                "description": c["description"],
+                "repository_id": c["repository_id"],
+                "file_path": c["file_path"],
+                "line_start": c["line_start"],
+                "line_end": c["line_end"],
+                "policy_ids": [],
+            }
+            for c in MassAssignmentService(self.db, self.tenant_id, self.clone_dir).candidates()["findings"]
+        ]
+
+    def _response_exposures(self) -> list[dict]:
+        """Sensitive fields returned to roles the visibility policy excludes, at the response."""
+        return [
+            {
+                "finding_type": FindingType.SENSITIVE_DATA_EXPOSURE,
+                "kind": None,
+                "severity": f["severity"],
+                "description": f["description"],
+                "repository_id": f["repository_id"],
+                "file_path": f["file_path"],
+                "line_start": f["line_start"],
+                "line_end": f["line_end"],
+                "policy_ids": f["policy_ids"],
+            }
+            for f in ResponseExposureService(self.db, self.tenant_id, self.clone_dir).exposures()["findings"]
+        ]
+
+    def _tenant_isolation_gaps(self) -> list[dict]:
+        """Endpoints touching tenant-owned models without a tenant isolation check, at the handler."""
+        return [
+            {
+                "finding_type": FindingType.MISSING_TENANT_ISOLATION,
+                "kind": None,
+                "severity": g["severity"],
+                "description": g["description"],
+                "repository_id": g["repository_id"],
+                "file_path": g["file_path"],
+                "line_start": g["line_start"],
+                "line_end": g["line_start"],
+                "policy_ids": g["policy_ids"],
+            }
+            for g in TenantIsolationService(self.db, self.tenant_id, self.clone_dir).isolation()["gaps"]
+        ]
+
+    def _auth_gaps(self) -> list[dict]:
+        """Registered routes with no detectable authentication or authorization, at the registration."""
+        return [
+            {
+                "finding_type": FindingType.MISSING_AUTHORIZATION,
+                "kind": None,
+                "severity": g["severity"],
+                "description": g["description"],
+                "repository_id": g["repository_id"],
+                "file_path": g["file_path"],
+                "line_start": g["line"],
+                "line_end": g["line"],
+                "policy_ids": g["policy_ids"],
+            }
+            for g in AuthGapService(self.db, self.tenant_id, self.clone_dir).gaps()["gaps"]
+        ]
+
+    def _endpoint_inconsistencies(self) -> list[dict]:
+        """Endpoints of one resource authorized inconsistently, at the looser endpoint's rule."""
+        return [
+            {
+                "finding_type": FindingType.INCONSISTENT_ENFORCEMENT,
+                "kind": i["kind"],
+                "severity": i["severity"],
+                "description": i["description"],
+                "repository_id": i["repository_id"],
+                "file_path": i["looser"]["file_path"],
+                "line_start": i["looser"]["line"],
+                "line_end": i["looser"]["line"],
+                "policy_ids": i["policy_ids"],
+            }
+            for i in EndpointConsistencyService(self.db, self.tenant_id).inconsistencies()["inconsistencies"]
+        ]
+
+    def _sources(self) -> dict[str, tuple[set[FindingType], Callable[[], list[dict]]]]:
+        """Finding sources and the finding types each produces."""
+        return {
+            "stored": (
+                {
+                    FindingType.CONFLICT,
+                    FindingType.INCONSISTENT_ENFORCEMENT,
+                    FindingType.SECURITY_GAP,
+                    FindingType.SECRET_IN_CODE,
+                },
+                self._stored,
+            ),
+            "cors": ({FindingType.CREDENTIALED_CORS}, self._credentialed_cors),
+            "admin_surface": ({FindingType.EXPOSED_ADMIN_ENDPOINT}, self._exposed_admin_endpoints),
+            "identity_propagation": ({FindingType.CONFUSED_DEPUTY}, self._confused_deputies),
+            "vulnerable_auth_patterns": ({FindingType.VULNERABLE_AUTH_PATTERN}, self._vulnerable_auth_patterns),
+            "bola": ({FindingType.BOLA_CANDIDATE}, self._bola_candidates),
+            "mass_assignment": ({FindingType.MASS_ASSIGNMENT}, self._mass_assignments),
+            "response_exposure": ({FindingType.SENSITIVE_DATA_EXPOSURE}, self._response_exposures),
+            "tenant_isolation": ({FindingType.MISSING_TENANT_ISOLATION}, self._tenant_isolation_gaps),
+            "auth_gaps": ({FindingType.MISSING_AUTHORIZATION}, self._auth_gaps),
+            "endpoint_consistency": ({FindingType.INCONSISTENT_ENFORCEMENT}, self._endpoint_inconsistencies),
+        }
+
+    def findings(
+        self,
+        repository_id: int | None = None,
+        finding_type: FindingType | None = None,
+        cwe: str | None = None,
+        owasp: OwaspApiCategory | None = None,
+        severity: str | None = None,
+        include_accepted: bool = False,
+    ) -> list[dict]:
+        """Open findings from every analyzer with their CWE and OWASP API tags.
+
+        Args:
+            repository_id: Keep findings located in or touching one repository
+            finding_type: Keep one finding type
+            cwe: Keep findings tagged with this canonical CWE ID, e.g. "CWE-863"
+            owasp: Keep findings tagged with this OWASP API category
+            severity: Keep one severity
+            include_accepted: Keep findings whose risk was accepted through an ITSM ticket
+
+        Returns:
+            Tagged findings, most severe first
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        names = self._repository_names()
+        if repository_id is not None and repository_id not in names:
+            raise ValueError(f"Repository {repository_id} not found")
+
+        # Only run the analyzers that can produce a finding passing the filters
+        wanted = {
+            e.finding_type
+            for e in TAXONOMY
+            if (finding_type is None or e.finding_type == finding_type) and matches(e, cwe, owasp)
+        }
+        results = []
+        for name, (types, collect) in self._sources().items():
+            if not types & wanted:
+                continue
+            found = collect()
+            logger.info("tagged_findings_collected", source=name, findings=len(found))
+            results.extend(found)
+
+        accepted = set()
+        if not include_accepted:
+            tickets = self._query(FindingTicket).filter(FindingTicket.status == FindingTicketStatus.RISK_ACCEPTED).all()
+            accepted = {t.finding_key for t in tickets}
+
+        tagged = []
+        for finding in results:
+            entry = classify(finding["finding_type"], finding["kind"])
+            repository_ids = finding.pop("repository_ids", None) or [finding["repository_id"]]
+            if finding["finding_type"] not in wanted or not matches(entry, cwe, owasp):
+                continue
+            if repository_id is not None and repository_id not in repository_ids:
+                continue
+            if severity is not None and finding["severity"] != severity.lower():
+                continue
+            tagged_finding = {
+                **finding,
+                "rule_id": entry.rule_id,
+                "title": entry.title,
+                "cwe": list(entry.cwe),
+                "owasp_api": [o.value for o in entry.owasp_api],
+                "repository_name": names.get(finding["repository_id"]),
+            }
+            tagged_finding["finding_key"] = finding_key(tagged_finding)
+            if tagged_finding["finding_key"] not in accepted:
+                tagged.append(tagged_finding)
+        tagged.sort(
+            key=lambda f: (
+                SEVERITY_ORDER.get(f["severity"], 9),
+                f["finding_type"].value,
+                f["repository_id"] or 0,
+                f["file_path"] or "",
+                f["line_start"] or 0,
+            )
+        )
+        return tagged
+
+    @staticmethod
+    def summary(findings: list[dict]) -> dict:
+        """Finding counts per type, CWE, OWASP category, and severity."""
+        counts: dict[str, dict[str, int]] = {"by_type": {}, "by_cwe": {}, "by_owasp_api": {}, "by_severity": {}}
+        for f in findings:
+            for key, values in (
+                ("by_type", [f["finding_type"].value]),
+                ("by_cwe", f["cwe"]),
+                ("by_owasp_api", f["owasp_api"]),
+                ("by_severity", [f["severity"]]),
+            ):
+                for value in values:
+                    counts[key][value] = counts[key].get(value, 0) + 1
+        return {"total": len(findings), **counts}
+
@@ -0,0 +1,289 @@
+"""CWE and OWASP API Security Top 10 tags for every finding type.
+
+Each analyzer reports findings in its own vocabulary: conflicts, enforcement
+gaps, endpoints of one resource authorized inconsistently, secrets, CORS
+misconfigurations, exposed admin endpoints, confused
+deputies, known-vulnerable auth patterns, BOLA candidates, mass
+assignment of privileged fields, sensitive response fields returned to
+roles not allowed to see them, endpoints missing tenant isolation, and
+routes registered with no authentication or authorization. This module
+maps each finding type, and where it matters each kind within a type, to the
+CWE weaknesses and OWASP API Security Top 10 (2023) categories it is an
+instance of, so findings from every analyzer land in the same vulnerability
+taxonomy.
+"""
+
+import re
+from dataclasses import dataclass
+from enum import Enum
+
+from app.services.vulnerable_auth_pattern_service import WEAKNESSES, PatternKind
+
+
+class OwaspApiCategory(str, Enum):
+    """OWASP API Security Top 10 (2023) categories."""
+
+    API1 = "API1:2023"
+    API2 = "API2:2023"
+    API3 = "API3:2023"
+    API4 = "API4:2023"
+    API5 = "API5:2023"
+    API6 = "API6:2023"
+    API7 = "API7:2023"
+    API8 = "API8:2023"
+    API9 = "API9:2023"
+    API10 = "API10:2023"
+
+
+OWASP_API_NAMES = {
+    OwaspApiCategory.API1: "Broken Object Level Authorization",
+    OwaspApiCategory.API2: "Broken Authentication",
+    OwaspApiCategory.API3: "Broken Object Property Level Authorization",
+    OwaspApiCategory.API4: "Unrestricted Resource Consumption",
+    OwaspApiCategory.API5: "Broken Function Level Authorization",
+    OwaspApiCategory.API6: "Unrestricted Access to Sensitive Business Flows",
+    OwaspApiCategory.API7: "Server Side Request Forgery",
+    OwaspApiCategory.API8: "Security Misconfiguration",
+    OwaspApiCategory.API9: "Improper Inventory Management",
+    OwaspApiCategory.API10: "Unsafe Consumption of APIs",
+}
+
+# Common abbreviations accepted wherever a category is filtered on
+OWASP_API_ALIASES = {
+    "BOLA": OwaspApiCategory.API1,
+    "BOPLA": OwaspApiCategory.API3,
+    "BFLA": OwaspApiCategory.API5,
+    "SSRF": OwaspApiCategory.API7,
+}
+
+CWE_NAMES = {
+    "CWE-208": "Observable Timing Discrepancy",
+    "CWE-213": "Exposure of Sensitive Information Due to Incompatible Policies",
+    "CWE-269": "Improper Privilege Management",
+    "CWE-287": "Improper Authentication",
+    "CWE-295": "Improper Certificate Validation",
+    "CWE-306": "Missing Authentication for Critical Function",
+    "CWE-347": "Improper Verification of Cryptographic Signature",
+    "CWE-441": "Unintended Proxy or Intermediary ('Confused Deputy')",
+    "CWE-639": "Authorization Bypass Through User-Controlled Key",
+    "CWE-668": "Exposure of Resource to Wrong Sphere",
+    "CWE-696": "Incorrect Behavior Order",
+    "CWE-798": "Use of Hard-coded Credentials",
+    "CWE-862": "Missing Authorization",
+    "CWE-863": "Incorrect Authorization",
+    "CWE-915": "Improperly Controlled Modification of Dynamically-Determined Object Attributes",
+    "CWE-942": "Permissive Cross-domain Policy with Untrusted Domains",
+}
+
+CWE_ID = re.compile(r"^(?:CWE-?)?(\d+)$", re.IGNORECASE)
+OWASP_ID = re.compile(r"^API(\d+)(?::2023)?$", re.IGNORECASE)
+
+
+class FindingType(str, Enum):
+    """Finding types reported across the analyzers."""
+
+    CONFLICT = "conflict"
+    INCONSISTENT_ENFORCEMENT = "inconsistent_enforcement"
+    SECURITY_GAP = "security_gap"
+    SECRET_IN_CODE = "secret_in_code"
+    CREDENTIALED_CORS = "credentialed_cors"
+    EXPOSED_ADMIN_ENDPOINT = "exposed_admin_endpoint"
+    CONFUSED_DEPUTY = "confused_deputy"
+    VULNERABLE_AUTH_PATTERN = "vulnerable_auth_pattern"
+    BOLA_CANDIDATE = "bola_candidate"
+    MASS_ASSIGNMENT = "mass_assignment"
+    SENSITIVE_DATA_EXPOSURE = "sensitive_data_exposure"
+    MISSING_TENANT_ISOLATION = "missing_tenant_isolation"
+    MISSING_AUTHORIZATION = "missing_authorization"
+
+
+@dataclass(frozen=True)
+class TaxonomyEntry:
+    """CWE and OWASP API tags of a finding type, or of one kind within it."""
+
+    finding_type: FindingType
+    kind: str | None
+    title: str
+    cwe: tuple[str, ...]
+    owasp_api: tuple[OwaspApiCategory, ...]
+
+    @property
+    def rule_id(self) -> str:
+        """Stable identifier of the entry, e.g. for SARIF rules."""
+        return f"{self.finding_type.value}/{self.kind}" if self.kind else self.finding_type.value
+
+
+API1, API2, API3, API5, API8, API10 = (
+    OwaspApiCategory.API1,
+    OwaspApiCategory.API2,
+    OwaspApiCategory.API3,
+    OwaspApiCategory.API5,
+    OwaspApiCategory.API8,
+    OwaspApiCategory.API10,
+)
+
+# OWASP categories of the vulnerable auth patterns; their CWEs come from the pattern catalog
+PATTERN_OWASP = {
+    PatternKind.JWT_NONE_ALGORITHM: (API2,),
+    PatternKind.JWT_SIGNATURE_NOT_VERIFIED: (API2,),
+    PatternKind.JWT_AUDIENCE_NOT_VALIDATED: (API2,),
+    PatternKind.TIMING_UNSAFE_COMPARISON: (API2,),
+    PatternKind.TLS_VERIFICATION_DISABLED: (API8, API10),
+    PatternKind.ROUTE_ORDER_BYPASS: (API5,),
+}
+
+TAXONOMY: list[TaxonomyEntry] = [
+    TaxonomyEntry(FindingType.CONFLICT, None, "Conflicting authorization rules", ("CWE-863",), (API5,)),
+    TaxonomyEntry(
+        FindingType.INCONSISTENT_ENFORCEMENT,
+        None,
+        "Resource authorized differently across applications",
+        ("CWE-863",),
+        (API1, API5),
+    ),
+    TaxonomyEntry(
+        FindingType.INCONSISTENT_ENFORCEMENT,
+        "anonymous_write",
+        "Write open to anonymous callers while its resource requires authentication",
+        ("CWE-306", "CWE-863"),
+        (API5,),
+    ),
+    TaxonomyEntry(
+        FindingType.INCONSISTENT_ENFORCEMENT,
+        "unrestricted_write",
+        "Write open to any user while its resource requires a role",
+        ("CWE-863",),
+        (API5,),
+    ),
+    TaxonomyEntry(
+        FindingType.INCONSISTENT_ENFORCEMENT,
+        "write_role_mismatch",
+        "Writes to one resource require different roles",
+        ("CWE-863",),
+        (API5,),
+    ),
+    TaxonomyEntry(
+        FindingType.INCONSISTENT_ENFORCEMENT,
+        "read_stricter_than_write",
+        "Role may change a resource it may not read",
+        ("CWE-863",),
+        (API5,),
+    ),
+    TaxonomyEntry(FindingType.SECURITY_GAP, None, "Authorization logic gap", ("CWE-863",), (API1, API5)),
+    TaxonomyEntry(
+        FindingType.SECURITY_GAP, "privilege_escalation", "Privilege escalation path", ("CWE-269",), (API5,)
+    ),
+    TaxonomyEntry(FindingType.SECURITY_GAP, "always_true", "Authorization check that always passes", ("CWE-862",), (API5,)),
+    TaxonomyEntry(FindingType.SECRET_IN_CODE, None, "Hard-coded secret", ("CWE-798",), (API2, API8)),
+    TaxonomyEntry(
+        FindingType.CREDENTIALED_CORS,
+        None,
+        "Authenticated endpoint accepts credentialed requests from any origin",
+        ("CWE-942",),
+        (API8,),
+    ),
+    TaxonomyEntry(FindingType.EXPOSED_ADMIN_ENDPOINT, None, "Admin endpoint open to non-admins", ("CWE-862",), (API5,)),
+    TaxonomyEntry(
+        FindingType.EXPOSED_ADMIN_ENDPOINT, "public", "Admin endpoint open to anonymous callers", ("CWE-306",), (API5,)
+    ),
+    TaxonomyEntry(
+        FindingType.CONFUSED_DEPUTY,
+        None,
+        "Service identity acts on unchecked request input",
+        ("CWE-441", "CWE-639"),
+        (API1, API5),
+    ),
+    TaxonomyEntry(
+        FindingType.VULNERABLE_AUTH_PATTERN, None, "Known-vulnerable authentication pattern", ("CWE-287",), (API2,)
+    ),
+    TaxonomyEntry(
+        FindingType.BOLA_CANDIDATE, None, "Object looked up by client-supplied ID without an ownership check", ("CWE-639",), (API1,)
+    ),
+    TaxonomyEntry(
+        FindingType.MASS_ASSIGNMENT, None, "Request body bound to privileged model fields", ("CWE-915",), (API3,)
+    ),
+    TaxonomyEntry(
+        FindingType.SENSITIVE_DATA_EXPOSURE,
+        None,
+        "Sensitive response fields returned to roles not allowed to see them",
+        ("CWE-213",),
+        (API3,),
+    ),
+    TaxonomyEntry(
+        FindingType.MISSING_TENANT_ISOLATION,
+        None,
+        "Tenant-owned object reached without a tenant isolation check",
+        ("CWE-668", "CWE-639"),
+        (API1,),
+    ),
+    TaxonomyEntry(
+        FindingType.MISSING_AUTHORIZATION,
+        None,
+        "Route registered with no authentication or authorization",
+        ("CWE-306", "CWE-862"),
+        (API2, API5),
+    ),
+    *(
+        TaxonomyEntry(FindingType.VULNERABLE_AUTH_PATTERN, kind.value, w.title, (w.cwe,), PATTERN_OWASP[kind])
+        for kind, w in WEAKNESSES.items()
+    ),
+]
+
+_ENTRIES = {(e.finding_type, e.kind): e for e in TAXONOMY}
+
+
+def classify(finding_type: FindingType, kind: str | None = None) -> TaxonomyEntry:
+    """Tags of a finding, falling back to its type's when its kind has none of its own.
+
+    Args:
+        finding_type: Finding type
+        kind: Kind within the type, e.g. a security gap type or pattern kind
+
+    Returns:
+        The most specific taxonomy entry
+    """
+    return _ENTRIES.get((finding_type, kind)) or _ENTRIES[(finding_type, None)]
+
+
+def normalize_cwe(value: str) -> str:
+    """Canonical CWE ID from "CWE-347", "cwe347", or "347".
+
+    Raises:
+        ValueError: If the value is not a CWE ID
+    """
+    match = CWE_ID.match(value.strip())
+    if not match:
+        raise ValueError(f"Invalid CWE identifier: {value}")
+    return f"CWE-{int(match[1])}"
+
+
+def normalize_owasp(value: str) -> OwaspApiCategory:
+    """OWASP API category from "API1:2023", "api1", or an alias such as "BOLA".
+
+    Raises:
+        ValueError: If the value names no category
+    """
+    value = value.strip().upper()
+    if value in OWASP_API_ALIASES:
+        return OWASP_API_ALIASES[value]
+    match = OWASP_ID.match(value)
+    if not match or not 1 <= int(match[1]) <= len(OwaspApiCategory):
+        raise ValueError(f"Invalid OWASP API Top 10 category: {value}")
+    return OwaspApiCategory(f"API{int(match[1])}:2023")
+
+
+def matches(entry: TaxonomyEntry, cwe: str | None = None, owasp: OwaspApiCategory | None = None) -> bool:
+    """Whether an entry carries the requested CWE and OWASP category."""
+    return (cwe is None or cwe in entry.cwe) and (owasp is None or owasp in entry.owasp_api)
+
+
+def describe(entry: TaxonomyEntry) -> dict:
+    """An entry with the names of its CWEs and OWASP categories."""
+    return {
+        "finding_type": entry.finding_type,
+        "kind": entry.kind,
+        "rule_id": entry.rule_id,
+        "title": entry.title,
+        "cwe": [{"id": c, "name": CWE_NAMES.get(c, c)} for c in entry.cwe],
+        "owasp_api": [{"id": o.value, "name": OWASP_API_NAMES[o]} for o in entry.owasp_api],
+    }
@@ -0,0 +1,175 @@
+"""Service for mining declarative route authorization from web frameworks.
+
+Some frameworks keep route authorization out of the handler code the LLM
+scan reads: Hapi routes carry it as configuration, Koa apps assemble it
+from middleware stacks mounted across files, Micronaut and Quarkus split
+it between class-level annotations and application config, and Play maps
+routes-file lines to actions composed from custom builders. Gin and Echo
+groups inherit their parent's middleware chain as it stood when they were
+created, and chi subrouters that of every router they are nested in. gRPC
+services authorize in interceptors that pick the methods they check by
+name, mapped onto the RPCs of the generated service descriptors.
+Spring splits access between method security annotations and the ordered
+rules of its HttpSecurity filter chain.
+Express TypeScript backends name the roles and permissions a guard checks
+through enums, const objects, and generic type arguments, which are resolved
+against the application's own type declarations, and Fastify guards routes
+through hooks inherited along its plugin tree. NestJS guards read the role
+and permission metadata that decorators attach to controllers and handlers,
+possibly through globally registered guards. Django and DRF guard views
+with decorators, mixins, and permission classes that only become route
+authorization through urls.py, and FastAPI authorizes routes through chains
+of Depends() and Security() dependencies. ASP.NET Core resolves [Authorize]
+and RequireAuthorization() policy names against AddAuthorization registrations.
+Rails actions are guarded by inherited before_action filters, Pundit policy
+queries, and CanCanCan abilities, and reached through routes.rb. GraphQL
+APIs authorize per operation and field through schema directives and
+resolver checks rather than per route.
+WebSocket, socket.io, STOMP, and server-sent event endpoints are authorized
+at the handshake and per message rather than per route. This service runs the
+framework extractors over a repository's clone and merges the per-route
+findings into its policies.
+"""
+
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import asdict
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import ExtractionMethod
+from app.services.aspnet_route_extractor import ASPNET_SUFFIXES, extract_aspnet_routes, is_aspnet_source
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.config_policy_service import ConfigPolicyService
+from app.services.django_route_extractor import extract_django_routes
+from app.services.fastapi_route_extractor import extract_fastapi_routes
+from app.services.fastify_route_extractor import extract_fastify_routes
+from app.services.go_route_extractor import GO_SUFFIXES, extract_go_routes
+from app.services.graphql_route_extractor import GRAPHQL_SUFFIXES, extract_graphql_routes, is_graphql_schema
+from app.services.grpc_route_extractor import extract_grpc_routes
+from app.services.jvm_route_extractor import JVM_SUFFIXES, extract_jvm_routes, is_jvm_route_file
+from app.services.nestjs_route_extractor import extract_nestjs_routes
+from app.services.node_route_extractor import JS_SUFFIXES, extract_node_routes, is_node_source
+from app.services.play_route_extractor import PLAY_SUFFIXES, extract_play_routes, is_play_source
+from app.services.rails_route_extractor import RAILS_SUFFIXES, extract_rails_routes, is_rails_source
+from app.services.realtime_route_extractor import REALTIME_SUFFIXES, extract_realtime_routes, is_realtime_source
+from app.services.spring_route_extractor import extract_spring_routes
+from app.services.typescript_route_extractor import extract_typescript_routes
+
+logger = structlog.get_logger(__name__)
+
+SKIP_DIRS = {".git", "node_modules", "venv", ".venv", "vendor", "dist", "build", "coverage", "target", "obj"}
+
+# Evidence paths this service can produce, for replacing an earlier scan
+EVIDENCE_SUFFIXES = tuple(
+    dict.fromkeys(JS_SUFFIXES + JVM_SUFFIXES + PLAY_SUFFIXES + GO_SUFFIXES + REALTIME_SUFFIXES + ASPNET_SUFFIXES + RAILS_SUFFIXES + GRAPHQL_SUFFIXES + (".properties", ".yml", ".yaml"))
+)
+
+# Policies and evidence created by this service are tagged with this source
+FRAMEWORK_ROUTE_LABEL = "framework route config"
+
+
+def load_sources(root: Path) -> tuple[dict[str, str], dict[str, str], dict[str, str], dict[str, str]]:
+    """Read the files framework extractors understand from a clone.
+
+    Args:
+        root: Repository clone root
+
+    Returns:
+        (JavaScript/TypeScript sources, JVM sources and application config,
+        Scala sources and Play routes files, Go, C#, and other sources
+        with realtime endpoints such as Python), each as relative path -> content
+    """
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    node: dict[str, str] = {}
+    jvm: dict[str, str] = {}
+    play: dict[str, str] = {}
+    other: dict[str, str] = {}
+    for path in sorted(root.rglob("*")):
+        relative = path.relative_to(root)
+        name = relative.as_posix()
+        if is_node_source(name):
+            target = node
+        elif is_jvm_route_file(name):
+            target = jvm
+        elif is_play_source(name):
+            target = play
+        elif is_realtime_source(name) or is_aspnet_source(name) or is_rails_source(name) or is_graphql_schema(name):
+            target = other
+        else:
+            continue
+        if SKIP_DIRS.intersection(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        target[name] = path.read_text(encoding="utf-8", errors="replace")
+    return node, jvm, play, other
+
+
+def mine_checkout(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the framework route findings of a clone and how many files it read."""
+    node, jvm, play, other = load_sources(Path(root))
+    findings = extract_node_routes(node) + extract_jvm_routes(jvm) + extract_spring_routes(jvm) + extract_play_routes(play)
+    findings += extract_go_routes(other) + extract_grpc_routes(other)
+    findings += extract_typescript_routes(node) + extract_fastify_routes(node)
+    findings += extract_nestjs_routes(node)
+    findings += extract_django_routes(other) + extract_fastapi_routes(other) + extract_aspnet_routes(other)
+    findings += extract_rails_routes(other) + extract_graphql_routes({**node, **other})
+    findings += extract_realtime_routes({**node, **jvm, **other})
+    yield {"files": len(node) + len(jvm) + len(play) + len(other), "findings": [asdict(f) for f in findings]}
+
+
+class FrameworkRouteService(ConfigPolicyService):
+    """Mines framework route configuration into repository policies."""
+
+    extraction_method = ExtractionMethod.EXPLICIT_MIDDLEWARE
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        super().__init__(db, tenant_id)
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def scan_repository(self, repository_id: int) -> dict:
+        """Mine framework route authorization from a repository's clone.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Summary with files read, findings per kind, and merge results
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        (mined,) = run_pass(root, mine_checkout)
+        findings = [ConfigFinding(**f) for f in mined["findings"]]
+        merge = self.merge_findings(
+            repo,
+            findings,
+            FRAMEWORK_ROUTE_LABEL,
+            previous=[f"%{suffix}" for suffix in EVIDENCE_SUFFIXES],
+            label_scoped=True,
+        )
+
+        logger.info(
+            "framework_routes_scanned",
+            repository_id=repo.id,
+            files=mined["files"],
+            findings=len(findings),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repo.id,
+            "files": len({f.file_path for f in findings}),
+            "findings_by_kind": dict(Counter(f.kind for f in findings)),
+            **merge,
+        }
@@ -0,0 +1,820 @@
+"""Extract route-level authorization from Gin, Echo, and chi applications.
+
+Gin services rarely check roles inside handlers. Guards are gin.HandlerFunc
+middleware attached with .Use() or passed before the handler, and router
+groups copy the middleware of their parent at the moment Group() is called,
+so a guard added to the engine after a group was created does not protect
+that group's routes. Groups are also commonly handed to per-package
+registration functions (users.Register(api.Group("/users"))). This
+extractor follows groups through those calls, resolves middleware factories
+such as RequireRole("admin") to the c.AbortWithStatus(403) checks in their
+bodies, and records handlers that abort with 401/403 themselves, producing
+one ConfigFinding per guarded route without LLM calls.
+
+Echo groups behave the same way, but its guards are echo.MiddlewareFunc
+wrappers passed after the handler (e.GET(path, handler, RequireRole("admin"))),
+they deny by returning echo.NewHTTPError(http.StatusForbidden) or
+echo.ErrUnauthorized, and middleware added to the Echo instance itself with
+.Use() or .Pre() wraps the whole router, covering routes registered before it.
+
+chi routers nest: r.Route("/admin", func(r chi.Router) {...}) and r.Group()
+hand a subrouter to a function literal, r.Mount() attaches a router built
+elsewhere, and r.With(RequireRole("ADMIN")) adds middleware to the routes
+chained onto it. Every leaf route inherits the middleware of the routers
+above it; chi guards are func(http.Handler) http.Handler wrappers that deny
+with http.Error(w, ..., http.StatusForbidden) or w.WriteHeader(403).
+"""
+
+import re
+from dataclasses import dataclass, field
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding, role_parameter
+from app.services.node_route_extractor import _source, _Source, join_paths
+
+# Alias and mount chains longer than this are treated as unresolvable
+MAX_RESOLVE_DEPTH = 8
+
+# Mount argument index standing for the router a function returns
+MOUNTED_RESULT = -1
+
+GO_SUFFIXES = (".go",)
+
+# Files importing none of the frameworks are skipped without further parsing
+GIN_IMPORT = re.compile(r"\"github\.com/gin-gonic/gin\"")
+ECHO_IMPORT = re.compile(r"\"github\.com/labstack/echo(?:/v\d+)?\"")
+CHI_IMPORT = re.compile(r"\"github\.com/go-chi/chi(?:/v\d+)?\"")
+
+
+class GoRouteKind:
+    """Kinds of Go route findings."""
+
+    GIN_ROUTE = "gin_route"
+    ECHO_ROUTE = "echo_route"
+    CHI_ROUTE = "chi_route"
+
+
+PACKAGE = re.compile(r"^package\s+(\w+)", re.MULTILINE)
+GO_FUNC = re.compile(r"^func\s+(?:\(\s*\w*\s*\*?[\w.]+\s*\)\s*)?([A-Za-z_]\w*)\s*\(", re.MULTILINE)
+GO_DECLARATION = re.compile(r"(?<![\w.])([A-Za-z_]\w*)\s*:?=(?!=)\s*")
+# interface{} and struct{} in a result type, whose braces do not open the body
+GO_TYPE_LITERAL = re.compile(r"\b(?:interface|struct)\s*$")
+GIN_ENGINE = re.compile(r"^gin\s*\.\s*(?:Default|New)\s*\(")
+ECHO_ENGINE = re.compile(r"^echo\s*\.\s*New\s*\(")
+CHI_ENGINE = re.compile(r"^chi\s*\.\s*(?:NewRouter|NewMux)\s*\(")
+ROUTER_GROUP = re.compile(r"^([A-Za-z_]\w*)\s*\.\s*Group\s*\(")
+ROUTER_WITH = re.compile(r"^([A-Za-z_]\w*)\s*\.\s*With\s*\(")
+ROUTER_CALL = re.compile(r"(?<![\w.])([A-Za-z_]\w*)\s*\.\s*([A-Z]\w*)\s*\(")
+CHAINED_CALL = re.compile(r"\s*\.\s*([A-Z]\w*)\s*\(")
+ROUTER_TYPE = re.compile(
+    r"\*?(?:gin\s*\.\s*(?:Engine|RouterGroup|IRouter|IRoutes)|echo\s*\.\s*(?:Echo|Group)|chi\s*\.\s*(?:Router|Mux))\b"
+)
+# A chi.Router parameter of a function literal, as passed to r.Route() and r.Group()
+CHI_SUBROUTER = re.compile(r"\bfunc\s*\(\s*([A-Za-z_]\w*)\s+chi\s*\.\s*Router\s*\)\s*\{")
+RETURNED = re.compile(r"\breturn\s+([A-Za-z_]\w*)\s*(?=[;}\n])")
+HANDLER_FUNC_TYPE = re.compile(
+    r"^\s*(?:gin\s*\.\s*HandlerFunc|echo\s*\.\s*(?:MiddlewareFunc|HandlerFunc)|http\s*\.\s*Handler(?:Func)?"
+    r"|func\s*\(\s*(?:\w+\s+)?http\s*\.\s*Handler\s*\))\b"
+)
+HANDLER_SLICE = re.compile(r"^\[\]\s*(?:gin\s*\.\s*HandlerFunc|echo\s*\.\s*MiddlewareFunc)\s*\{")
+CONTEXT_PARAM = re.compile(r"\*\s*gin\s*\.\s*Context\b|(?<![\w.])(?:echo\s*\.\s*Context|http\s*\.\s*ResponseWriter)\b")
+INLINE_HANDLER = re.compile(r"^func\s*\(")
+CALLEE = re.compile(r"^(?:[A-Za-z_]\w*\s*\.\s*)*([A-Za-z_]\w*)\s*(\()?")
+IF_STATEMENT = re.compile(r"\bif\s+")
+
+# Responses that end a request as unauthenticated or forbidden
+DENIAL = re.compile(
+    r"(?:\.\s*(?:AbortWithStatus|AbortWithStatusJSON|AbortWithError|JSON|IndentedJSON|String|Status|NoContent)\s*\(\s*"
+    r"|\.\s*WriteHeader\s*\(\s*|\bNewHTTPError\s*\(\s*|\bhttp\s*\.\s*Error\s*\([^;\n]*?,\s*)"
+    r"(401|403|http\s*\.\s*StatusUnauthorized|http\s*\.\s*StatusForbidden)\b"
+    r"|\becho\s*\.\s*Err(Unauthorized|Forbidden)\b"
+)
+FORBIDDEN = ("403", "StatusForbidden", "Forbidden")
+
+# Middleware defined outside the repository is classified by name
+AUTHENTICATION_MIDDLEWARE = re.compile(r"(?:auth|jwt|token|login|bearer|oidc|apikey|api_key)", re.IGNORECASE)
+ROLE_MIDDLEWARE = re.compile(r"(?:role|permission|scope|authoriz|casbin|rbac|acl|admin)", re.IGNORECASE)
+ROLE_WORD = re.compile(r"role|scope|perm|group|admin", re.IGNORECASE)
+STRING_LITERAL = re.compile(r"\"([^\"\\\n]+)\"|`([^`\n]+)`")
+ROLE_LITERALS = [
+    # user.Role != "admin", "admin" == role
+    re.compile(r"[!=]=\s*\"([\w:.-]+)\""),
+    re.compile(r"\"([\w:.-]+)\"\s*[!=]="),
+    # HasRole(user, "admin"), slices.Contains(roles, "admin")
+    re.compile(r"\b\w*(?:[Rr]ole|[Ss]cope|[Pp]ermission)\w*\s*\([^()\n]*?\"([\w:.-]+)\"\s*\)"),
+    re.compile(r"\bContains\s*\(\s*[\w.]*(?:[Rr]oles?|[Ss]copes?|[Pp]ermissions?)\w*\s*(?:\([^()]*\))?\s*,\s*\"([\w:.-]+)\""),
+]
+
+# Router methods of each framework; chi's take a method-less path and handler
+VERBS = {
+    "gin": {"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "Any", "Handle", "Use"},
+    "echo": {"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "CONNECT", "TRACE", "Any", "Add", "Match", "Use", "Pre"},
+    "chi": {
+        "Get", "Post", "Put", "Patch", "Delete", "Head", "Options", "Connect", "Trace",
+        "Method", "MethodFunc", "Handle", "HandleFunc", "Use", "With", "Route", "Group", "Mount",
+    },
+}
+ROUTE_METHODS = {"Any": "*", "Handle": "*", "HandleFunc": "*"}
+METHOD_FIRST = {"gin": {"Handle"}, "echo": {"Add"}, "chi": {"Method", "MethodFunc"}}
+
+# Finding kind and label of each framework
+FRAMEWORKS = {
+    "gin": (GoRouteKind.GIN_ROUTE, "Gin"),
+    "echo": (GoRouteKind.ECHO_ROUTE, "Echo"),
+    "chi": (GoRouteKind.CHI_ROUTE, "chi"),
+}
+
+
+@dataclass
+class _GoFunction:
+    """A top-level function or method, or a chi subrouter function literal, in a Go source file."""
+
+    file_path: str
+    package: str
+    name: str
+    params: list[str]
+    router_params: dict[str, int]  # parameter name -> position
+    router_types: dict[str, str]  # parameter name -> type, e.g. "*echo.Group"
+    handler_factory: bool
+    handler: bool
+    body: tuple[int, int]
+
+
+@dataclass
+class _GoCheck:
+    """A 401/403 response in a function body and the condition guarding it."""
+
+    forbidden: bool
+    condition: str | None
+
+
+@dataclass
+class _GoNode:
+    """An engine, group, or router parameter visible in a function."""
+
+    kind: str  # "engine", "group", or "param"
+    position: int
+    framework: str = "gin"
+    parent: str | None = None
+    path: str = ""
+    middleware: list[str] = field(default_factory=list)
+    global_uses: bool = False  # .Use() covers routes registered before it, as on an Echo instance or chi router
+    returned: bool = False  # Returned by its function, so callers can mount it
+
+
+@dataclass
+class _GoUse:
+    """Middleware attached to an engine or group with .Use()."""
+
+    position: int
+    receiver: str
+    middleware: list[str]
+
+
+@dataclass
+class _GoMount:
+    """A router passed to a registration function, optionally as a new group.
+
+    chi's r.Mount(path, admin.Routes()) mounts the router a function returns
+    (argument MOUNTED_RESULT), and r.Route(path, func(r chi.Router) {...})
+    hands a subrouter to a function literal, identified by its body.
+    """
+
+    position: int
+    receiver: str
+    path: str
+    middleware: list[str]
+    qualifier: str | None
+    target: str
+    argument: int
+    body: tuple[int, int] | None = None
+
+
+@dataclass
+class _GoRoute:
+    """A route registered on an engine or group."""
+
+    position: int
+    receiver: str
+    method: str
+    path: str
+    middleware: list[str]
+    handler: str
+    line_start: int
+    line_end: int
+    snippet: str
+
+
+@dataclass
+class _GoScope:
+    """Routers and route wiring declared in one function body."""
+
+    function: _GoFunction
+    nodes: dict[str, _GoNode] = field(default_factory=dict)
+    uses: list[_GoUse] = field(default_factory=list)
+    mounts: list[_GoMount] = field(default_factory=list)
+    routes: list[_GoRoute] = field(default_factory=list)
+
+
+@dataclass
+class GoModule:
+    """Functions and route wiring of one Gin, Echo, or chi source file."""
+
+    file_path: str
+    package: str
+    text: str
+    functions: list[_GoFunction] = field(default_factory=list)
+    scopes: list[_GoScope] = field(default_factory=list)
+
+
+def _params(src: _Source, start: int, end: int) -> list[tuple[str, str]]:
+    """(name, type) of each parameter in a Go parameter list, sharing grouped types."""
+    params: list[tuple[str, str]] = []
+    pending: list[str] = []
+    for s, e in src.split(start, end):
+        parts = src.source(s, e).split(None, 1)
+        if len(parts) == 1:
+            pending.append(parts[0])
+            continue
+        for name in [*pending, parts[0]]:
+            params.append((name, parts[1]))
+        pending = []
+    # Unnamed parameters ("func(*gin.Context)") are types only
+    params.extend(("", name) for name in pending)
+    return params
+
+
+def _body_brace(src: _Source, close: int) -> int:
+    """Offset of the "{" opening a function body, past result types such as (interface{}, error) or struct{}.
+
+    Returns -1 when the signature ends its line without a body.
+    """
+    i = close
+    while i < len(src.masked):
+        c = src.masked[i]
+        if c == "\n":
+            return -1
+        if c in "([":
+            i = max(src.pairs.get(i, len(src.masked)), i + 1)
+            continue
+        if c == "{":
+            if not GO_TYPE_LITERAL.search(src.masked[close:i]):
+                return i
+            i = max(src.pairs.get(i, len(src.masked)), i + 1)
+            continue
+        i += 1
+    return -1
+
+
+def _functions(file_path: str, package: str, src: _Source) -> list[_GoFunction]:
+    """Top-level functions with the signature details the resolver needs."""
+    functions = []
+    for match in GO_FUNC.finditer(src.masked):
+        open_paren = match.end() - 1
+        close = src.pairs.get(open_paren, len(src.text))
+        brace = _body_brace(src, close)
+        if brace < 0:
+            continue
+        params = _params(src, open_paren + 1, close - 1)
+        functions.append(
+            _GoFunction(
+                file_path=file_path,
+                package=package,
+                name=match.group(1),
+                params=[name for name, _ in params],
+                router_params={name: i for i, (name, kind) in enumerate(params) if name and ROUTER_TYPE.search(kind)},
+                router_types={name: kind for name, kind in params if name and ROUTER_TYPE.search(kind)},
+                handler_factory=bool(HANDLER_FUNC_TYPE.match(src.masked[close:brace])),
+                handler=any(CONTEXT_PARAM.search(kind) for _, kind in params),
+                body=(brace, src.pairs.get(brace, len(src.text))),
+            )
+        )
+    return functions
+
+
+def _statement_end(src: _Source, start: int, limit: int) -> int:
+    """End of the Go expression starting at start (a top-level ";" or line break)."""
+    i = start
+    while i < limit:
+        c = src.masked[i]
+        if c in "([{":
+            i = max(src.pairs.get(i, limit), i + 1)
+            continue
+        if c in ";)]}":
+            return i
+        if c == "\n":
+            if not src.masked[i:limit].lstrip().startswith("."):
+                return i
+        i += 1
+    return limit
+
+
+def _arguments(src: _Source, open_paren: int) -> list[tuple[int, int]]:
+    """Top-level argument spans of the call whose "(" is at open_paren."""
+    return src.split(open_paren + 1, src.pairs.get(open_paren, len(src.text)) - 1)
+
+
+def _expand(src: _Source, span: tuple[int, int], aliases: dict[str, tuple[int, int]], depth: int = 0) -> list[str]:
+    """Expand a handler argument through local aliases and spread slices."""
+    expression = src.masked[span[0] : span[1]].removesuffix("...").strip()
+    if depth < MAX_RESOLVE_DEPTH and expression in aliases:
+        s, e = aliases[expression]
+        if HANDLER_SLICE.match(src.masked[s:e]):
+            brace = src.masked.index("{", s)
+            return [m for item in src.split(brace + 1, e - 1) for m in _expand(src, item, aliases, depth + 1)]
+        return _expand(src, (s, e), aliases, depth + 1)
+    return [src.source(*span)]
+
+
+def _string(src: _Source, span: tuple[int, int]) -> str | None:
+    """Value of a Go string literal, including raw strings."""
+    value = src.literal(*span)
+    return value if isinstance(value, str) else None
+
+
+def _call_arguments(expression: str) -> list[str]:
+    """Role arguments of a middleware call: literals, or parameters for configured values.
+
+    RequireRole(cfg.ApproverRole) yields "${cfg.ApproverRole}" so the rule is
+    kept with a role the user binds, rather than dropped or reported as a
+    condition on the middleware's comparison.
+    """
+    src = _source(expression)
+    open_paren = src.masked.find("(")
+    if open_paren < 0:
+        return []
+    arguments: list[str] = []
+    for span in _arguments(src, open_paren):
+        value = _string(src, span)
+        if value is None:
+            value = role_parameter(src.source(*span))
+        if value is not None:
+            arguments.append(value)
+        else:
+            arguments.extend(a or b for a, b in STRING_LITERAL.findall(src.source(*span)))
+    return arguments
+
+
+def _router_framework(kind: str) -> tuple[str, bool]:
+    """Framework of a router parameter's type, and whether .Use() on it covers earlier routes."""
+    if re.search(r"\bchi\s*\.", kind):
+        # chi panics on Use() after a route, so a router's middleware precedes all its routes
+        return "chi", True
+    if re.search(r"\becho\s*\.", kind):
+        return "echo", bool(re.search(r"\becho\s*\.\s*Echo\b", kind))
+    return "gin", False
+
+
+def _subrouters(src: _Source, start: int, end: int) -> list[tuple[str, int, tuple[int, int]]]:
+    """Outermost chi subrouter function literals in a span, as (parameter, position, body)."""
+    found: list[tuple[str, int, tuple[int, int]]] = []
+    for match in CHI_SUBROUTER.finditer(src.masked, start, end):
+        if found and match.start() < found[-1][2][1]:
+            continue
+        brace = match.end() - 1
+        found.append((match.group(1), match.start(), (brace, src.pairs.get(brace, end))))
+    return found
+
+
+def _parse_scope(src: _Source, function: _GoFunction, nested: list[_GoScope]) -> _GoScope:
+    """Collect the routers, middleware, routes, and router hand-offs of a function body.
+
+    chi subrouter function literals in the body are parsed as scopes of their
+    own and added to nested, so their router parameter, which usually
+    shadows the outer one, is kept apart from it.
+    """
+    scope = _GoScope(function=function)
+    start, end = function.body
+    for name in function.router_params:
+        framework, global_uses = _router_framework(function.router_types[name])
+        scope.nodes[name] = _GoNode(kind="param", position=start, framework=framework, global_uses=global_uses)
+    aliases: dict[str, tuple[int, int]] = {}
+
+    literals: dict[int, tuple[int, int]] = {}  # subrouter literal position -> body
+    for param, position, body in _subrouters(src, start, end):
+        literals[position] = body
+        subrouter = _GoFunction(
+            file_path=function.file_path,
+            package=function.package,
+            name=function.name,
+            params=[param],
+            router_params={param: 0},
+            router_types={param: "chi.Router"},
+            handler_factory=False,
+            handler=False,
+            body=body,
+        )
+        nested.append(_parse_scope(src, subrouter, nested))
+
+    def outside(position: int) -> bool:
+        return not any(s <= position < body[1] for s, body in literals.items())
+
+    for match in GO_DECLARATION.finditer(src.masked, start, end):
+        if not outside(match.start()):
+            continue
+        name, value_start = match.group(1), match.end()
+        value_end = _statement_end(src, value_start, end)
+        expression = src.masked[value_start:value_end]
+        group = ROUTER_GROUP.match(expression)
+        with_ = ROUTER_WITH.match(expression)
+        if GIN_ENGINE.match(expression):
+            scope.nodes[name] = _GoNode(kind="engine", position=match.start())
+        elif ECHO_ENGINE.match(expression):
+            scope.nodes[name] = _GoNode(kind="engine", position=match.start(), framework="echo", global_uses=True)
+        elif CHI_ENGINE.match(expression):
+            scope.nodes[name] = _GoNode(kind="engine", position=match.start(), framework="chi", global_uses=True)
+        elif group and group.group(1) in scope.nodes and scope.nodes[group.group(1)].framework != "chi":
+            args = _arguments(src, value_start + group.end() - 1)
+            scope.nodes[name] = _GoNode(
+                kind="group",
+                position=match.start(),
+                framework=scope.nodes[group.group(1)].framework,
+                parent=group.group(1),
+                path=(_string(src, args[0]) or "") if args else "",
+                middleware=[m for span in args[1:] for m in _expand(src, span, aliases)],
+            )
+        elif (
+            with_
+            and with_.group(1) in scope.nodes
+            and not src.masked[src.pairs.get(value_start + with_.end() - 1, value_end) : value_end].strip()
+        ):
+            # admin := r.With(RequireRole("admin"))
+            args = _arguments(src, value_start + with_.end() - 1)
+            scope.nodes[name] = _GoNode(
+                kind="group",
+                position=match.start(),
+                framework="chi",
+                parent=with_.group(1),
+                middleware=[m for span in args for m in _expand(src, span, aliases)],
+            )
+        elif not expression.startswith("func"):
+            aliases[name] = (value_start, value_end)
+
+    for match in ROUTER_CALL.finditer(src.masked, start, end):
+        receiver, verb = match.group(1), match.group(2)
+        node = scope.nodes.get(receiver)
+        if node is None or verb not in VERBS[node.framework] or not outside(match.start()):
+            continue
+        open_paren = match.end() - 1
+        inline: list[str] = []
+        while verb == "With":
+            # r.With(RequireRole("admin")).Get(...) adds middleware to one route or subrouter
+            inline += [m for span in _arguments(src, open_paren) for m in _expand(src, span, aliases)]
+            chained = CHAINED_CALL.match(src.masked, src.pairs.get(open_paren, end))
+            if not chained:
+                break
+            verb, open_paren = chained.group(1), chained.end() - 1
+        if verb == "With" or verb not in VERBS[node.framework]:
+            continue
+        close = src.pairs.get(open_paren, end)
+        args = _arguments(src, open_paren)
+        if verb in ("Use", "Pre"):
+            middleware = [m for span in args for m in _expand(src, span, aliases)]
+            if middleware:
+                scope.uses.append(_GoUse(match.start(), receiver, middleware))
+            continue
+        if verb in ("Route", "Group", "Mount"):
+            path = (_string(src, args[0]) if verb != "Group" else "") if args else None
+            if path is None or len(args) < (1 if verb == "Group" else 2):
+                continue
+            target = args[-1]
+            expression = src.masked[target[0] : target[1]].strip()
+            callee = re.match(r"^(?:([A-Za-z_]\w*)\s*\.\s*)?([A-Za-z_]\w*)\s*(\()?", expression)
+            if target[0] in literals:
+                scope.mounts.append(
+                    _GoMount(match.start(), receiver, path, inline, None, function.name, 0, body=literals[target[0]])
+                )
+            elif verb == "Mount" and expression in scope.nodes:
+                # sub := chi.NewRouter() ... r.Mount("/admin", sub)
+                mounted = scope.nodes[expression]
+                mounted.kind, mounted.parent, mounted.path, mounted.middleware = "group", receiver, path, inline
+            elif callee and (callee.group(3) is not None) == (verb == "Mount"):
+                # r.Mount("/admin", admin.Routes()) or r.Route("/users", users.Routes)
+                argument = MOUNTED_RESULT if verb == "Mount" else 0
+                scope.mounts.append(
+                    _GoMount(match.start(), receiver, path, inline, callee.group(1), callee.group(2), argument)
+                )
+            continue
+        methods = [ROUTE_METHODS.get(verb, verb.upper())]
+        if verb in METHOD_FIRST[node.framework]:
+            methods = [(_string(src, args[0]) or "").upper()] if args else []
+            args = args[1:]
+        elif verb == "Match":
+            # e.Match([]string{"GET", "POST"}, path, handler)
+            methods = [(a or b).upper() for a, b in STRING_LITERAL.findall(src.source(*args[0]))] if args else []
+            args = args[1:]
+        path = _string(src, args[0]) if args else None
+        if not any(methods) or path is None or len(args) < 2:
+            continue
+        handlers = [m for span in args[1:] for m in _expand(src, span, aliases)]
+        # Gin takes the handler last, Echo first with route middleware after it
+        if node.framework == "echo":
+            handler, middleware = handlers[0], handlers[1:]
+        else:
+            handler, middleware = handlers[-1], handlers[:-1]
+        line_start, line_end, snippet = src.snippet(match.start(), close)
+        for method in methods:
+            scope.routes.append(
+                _GoRoute(
+                    match.start(),
+                    receiver,
+                    method,
+                    path,
+                    inline + middleware,
+                    handler,
+                    line_start,
+                    line_end,
+                    snippet,
+                )
+            )
+
+    for match in RETURNED.finditer(src.masked, start, end):
+        if match.group(1) in scope.nodes and outside(match.start()):
+            scope.nodes[match.group(1)].returned = True
+    return scope
+
+
+def parse_go_module(file_path: str, text: str) -> GoModule | None:
+    """Collect Gin, Echo, and chi functions, routers, and routes from a file.
+
+    Args:
+        file_path: Path of the file
+        text: File content
+
+    Returns:
+        Module wiring, or None if the file uses none of the frameworks
+    """
+    if not any(pattern.search(text) for pattern in (GIN_IMPORT, ECHO_IMPORT, CHI_IMPORT)):
+        return None
+    src = _source(text)
+    package = PACKAGE.search(text)
+    module = GoModule(file_path=file_path, package=package.group(1) if package else "", text=text)
+    module.functions = _functions(file_path, module.package, src)
+    for function in module.functions:
+        nested: list[_GoScope] = []
+        scope = _parse_scope(src, function, nested)
+        module.scopes.extend(s for s in [scope, *nested] if s.nodes)
+    return module
+
+
+def _find_mounts(modules: list[GoModule]) -> None:
+    """Record calls that hand a router to a function taking a router parameter."""
+    targets = {f.name for m in modules for f in m.functions if f.router_params}
+    if not targets:
+        return
+    call = re.compile(r"(?<![\w.])(?:([A-Za-z_]\w*)\s*\.\s*)?(" + "|".join(sorted(targets)) + r")\s*\(")
+    for module in modules:
+        src = _source(module.text)
+        for function in module.functions:
+            scope = next((s for s in module.scopes if s.function is function), None)
+            start, end = function.body
+            for match in call.finditer(src.masked, start, end):
+                for index, span in enumerate(_arguments(src, match.end() - 1)):
+                    expression = src.masked[span[0] : span[1]]
+                    group = ROUTER_GROUP.match(expression)
+                    receiver = group.group(1) if group else expression
+                    if scope is None or receiver not in scope.nodes:
+                        continue
+                    path, middleware = "", []
+                    if group:
+                        args = _arguments(src, span[0] + group.end() - 1)
+                        path = (_string(src, args[0]) or "") if args else ""
+                        middleware = [src.source(*a) for a in args[1:]]
+                    scope.mounts.append(
+                        _GoMount(match.start(), receiver, path, middleware, match.group(1), match.group(2), index)
+                    )
+
+
+def _checks(src: _Source, body: tuple[int, int]) -> list[_GoCheck]:
+    """401/403 responses in a body with the if-condition they are nested in."""
+    checks = []
+    start, end = body
+    for denial in DENIAL.finditer(src.masked, start, end):
+        condition = None
+        for statement in IF_STATEMENT.finditer(src.masked, start, denial.start()):
+            brace = src.masked.find("{", statement.end(), denial.start())
+            if brace >= 0 and src.pairs.get(brace, end) > denial.start():
+                condition = src.source(statement.end(), brace)
+        status = denial.group(1) or denial.group(2)
+        checks.append(_GoCheck(forbidden=status.endswith(FORBIDDEN), condition=condition))
+    return checks
+
+
+def _condition_roles(condition: str) -> list[str]:
+    """Role names a denial condition compares against."""
+    if not condition or not ROLE_WORD.search(condition):
+        return []
+    roles = []
+    for pattern in ROLE_LITERALS:
+        for role in pattern.findall(condition):
+            if role not in roles:
+                roles.append(role)
+    return roles
+
+
+def _permit_condition(condition: str) -> str:
+    """Condition under which a request passes a check that denies when condition holds."""
+    if "&&" in condition or "||" in condition:
+        return f"not ({condition})"
+    if condition.startswith("!"):
+        negated = condition[1:].strip()
+        return negated[1:-1].strip() if negated.startswith("(") and negated.endswith(")") else negated
+    if condition.count("!=") == 1:
+        return condition.replace("!=", "==")
+    if condition.count("==") == 1:
+        return condition.replace("==", "!=")
+    return f"not ({condition})"
+
+
+@dataclass
+class _Guard:
+    """What one middleware or handler contributes to a route's authorization."""
+
+    roles: list[str] = field(default_factory=list)
+    conditions: list[str] = field(default_factory=list)
+
+
+class _GoAnalyzer:
+    """Resolves middleware and handlers to the checks in their bodies, across files."""
+
+    def __init__(self, modules: list[GoModule]):
+        self.functions: dict[str, list[tuple[GoModule, _GoFunction]]] = {}
+        for module in modules:
+            for function in module.functions:
+                self.functions.setdefault(function.name, []).append((module, function))
+        self.cache: dict[tuple[str, str], list[_GoCheck]] = {}
+
+    def _lookup(self, name: str) -> tuple[GoModule, _GoFunction] | None:
+        candidates = self.functions.get(name)
+        return candidates[0] if candidates else None
+
+    def _function_checks(self, module: GoModule, function: _GoFunction) -> list[_GoCheck]:
+        key = (module.file_path, function.name)
+        if key not in self.cache:
+            self.cache[key] = _checks(_source(module.text), function.body)
+        return self.cache[key]
+
+    def guard(self, expression: str, handler: bool = False) -> _Guard | None:
+        """Authorization a middleware or handler expression applies, or None if it applies none."""
+        checks: list[_GoCheck] | None = None
+        parameters: list[str] = []
+        if INLINE_HANDLER.match(expression):
+            src = _source(expression)
+            brace = src.masked.find("{")
+            checks = _checks(src, (brace, src.pairs.get(brace, len(expression)))) if brace >= 0 else []
+        callee = CALLEE.match(expression)
+        if checks is None and callee:
+            found = self._lookup(callee.group(1))
+            if found and (found[1].handler_factory or found[1].handler):
+                checks = self._function_checks(*found)
+                parameters = found[1].params if found[1].handler_factory else []
+
+        arguments = _call_arguments(expression) if callee and callee.group(2) else []
+        if checks is None:
+            # Defined outside the repository: fall back to the middleware name
+            if handler or not callee:
+                return None
+            if ROLE_MIDDLEWARE.search(callee.group(0)):
+                return _Guard(roles=arguments)
+            return _Guard() if AUTHENTICATION_MIDDLEWARE.search(callee.group(0)) else None
+        if not checks:
+            return None
+
+        result = _Guard()
+        for check in checks:
+            if not check.forbidden or not check.condition:
+                continue
+            roles = _condition_roles(check.condition)
+            if not roles and any(re.search(rf"\b{re.escape(p)}\b", check.condition) for p in parameters if p):
+                # RequireRole(role string): the role comes from the call site
+                roles = arguments
+            if roles:
+                result.roles.extend(r for r in roles if r not in result.roles)
+            else:
+                result.conditions.append(_permit_condition(check.condition))
+        return result
+
+
+def resolve_go_routes(modules: list[GoModule]) -> list[ConfigFinding]:
+    """Resolve Gin, Echo, and chi routes through groups and registration functions into per-route findings.
+
+    A route's chain is the middleware its router had when the route was
+    registered: for a group, the chain of its parent when Group() was called,
+    then the group's own middleware, then middleware it gained with .Use()
+    before the route, then the route's inline middleware and handler. An Echo
+    instance's own .Use() and .Pre() middleware applies whenever it was added,
+    as does a chi router's; a chi subrouter or mounted router starts from the
+    chain of the router it was attached to.
+    Routes whose chain performs no authentication or authorization are skipped.
+
+    Args:
+        modules: Parsed Gin, Echo, and chi modules of an application
+
+    Returns:
+        One finding per guarded route
+    """
+    _find_mounts(modules)
+    analyzer = _GoAnalyzer(modules)
+    mounted: dict[tuple[str, int], list[tuple[_GoScope, _GoMount]]] = {}
+    for module in modules:
+        for scope in module.scopes:
+            for mount in scope.mounts:
+                if mount.body is not None:
+                    mounted.setdefault((module.file_path, mount.body[0]), []).append((scope, mount))
+                    continue
+                candidates = [
+                    (m, f)
+                    for m, f in analyzer.functions.get(mount.target, [])
+                    if f.router_params or mount.argument == MOUNTED_RESULT
+                ]
+                # users.Register(api) names the package; a bare call stays in the caller's
+                package = mount.qualifier or module.package
+                candidates = [(m, f) for m, f in candidates if m.package == package] or candidates
+                for target_module, function in candidates:
+                    if mount.argument == MOUNTED_RESULT or mount.argument in function.router_params.values():
+                        mounted.setdefault((target_module.file_path, function.body[0]), []).append((scope, mount))
+
+    def contexts(scope: _GoScope, receiver: str, position: int, visiting: frozenset) -> list[tuple[str, list[str]]]:
+        node = scope.nodes.get(receiver)
+        base: list[tuple[str, list[str]]] = [("", [])]
+        key = (scope.function.file_path, scope.function.body[0])
+        if node is not None and len(visiting) < MAX_RESOLVE_DEPTH:
+            if node.kind == "group" and node.parent:
+                base = [
+                    (join_paths(prefix, node.path), stack + node.middleware)
+                    for prefix, stack in contexts(scope, node.parent, node.position, visiting | {(key, receiver)})
+                ]
+            elif (node.kind == "param" or node.returned) and key in mounted and (key, receiver) not in visiting:
+                index = scope.function.router_params[receiver] if node.kind == "param" else MOUNTED_RESULT
+                base = [
+                    (join_paths(prefix, mount.path), stack + mount.middleware)
+                    for parent, mount in mounted[key]
+                    if mount.argument == index
+                    for prefix, stack in contexts(parent, mount.receiver, mount.position, visiting | {(key, receiver)})
+                ] or base
+        local = [
+            mw
+            for use in scope.uses
+            if use.receiver == receiver and (use.position < position or (node is not None and node.global_uses))
+            for mw in use.middleware
+        ]
+        return [(prefix, stack + local) for prefix, stack in base]
+
+    findings = []
+    for scope in (s for m in modules for s in m.scopes):
+        for route in scope.routes:
+            kind, label = FRAMEWORKS[scope.nodes[route.receiver].framework]
+            seen = set()
+            for prefix, stack in contexts(scope, route.receiver, route.position, frozenset()):
+                full_path = join_paths(prefix, route.path)
+                guards, roles, conditions = [], [], []
+                chain = [(mw, False) for mw in stack + route.middleware] + [(route.handler, True)]
+                for expression, is_handler in chain:
+                    guard = analyzer.guard(expression, is_handler)
+                    if guard is None:
+                        continue
+                    guards.append("handler" if is_handler else expression)
+                    roles.extend(r for r in guard.roles if r not in roles)
+                    conditions.extend(c for c in guard.conditions if c not in conditions)
+                if not guards or (full_path, tuple(guards)) in seen:
+                    continue
+                seen.add((full_path, tuple(guards)))
+                findings.append(
+                    ConfigFinding(
+                        kind=kind,
+                        file_path=scope.function.file_path,
+                        line_start=route.line_start,
+                        line_end=route.line_end,
+                        snippet=route.snippet,
+                        subject=" or ".join(roles) if roles else "Authenticated users",
+                        resource=full_path,
+                        action=route.method,
+                        conditions="; ".join(conditions) or None,
+                        description=f"{label} route guarded by {', '.join(guards)}",
+                    )
+                )
+    return findings
+
+
+def extract_go_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Gin, Echo, and chi route authorization from an application's files.
+
+    Args:
+        files: Relative path -> content; non-Go files are ignored
+
+    Returns:
+        Route findings across all files
+    """
+    modules = []
+    for file_path, text in files.items():
+        if not is_go_source(file_path):
+            continue
+        module = parse_go_module(file_path, text)
+        if module is not None:
+            modules.append(module)
+    return resolve_go_routes(modules)
+
+
+def is_go_source(file_path: str) -> bool:
+    """Check whether a path is a non-test Go source file."""
+    return PurePosixPath(file_path).suffix in GO_SUFFIXES and not file_path.endswith("_test.go")
@@ -0,0 +1,764 @@
+"""Extract per-operation and per-field authorization from GraphQL APIs.
+
+A GraphQL API serves every query, mutation, and field through one HTTP
+route, so route-level mining sees a single endpoint. Authorization lives in
+the schema instead, as directives such as @auth(requires: ADMIN),
+@hasRole(roles: [ADMIN]), @hasScope, Amplify @auth rules, and AppSync or
+Apollo Router directives on types and fields, and in the resolvers: checks
+inside gqlgen resolver methods and JavaScript resolver maps, graphql-shield
+rule trees, and TypeGraphQL @Authorized() decorators. A schema directive
+only authorizes anything if the server implements it, so each directive is
+also looked up among gqlgen DirectiveRoot entries, graphql-tools
+getDirective() transformers, schemaDirectives and directiveResolvers maps,
+and Ariadne directive maps. This extractor parses SDL from schema files and
+gql templates and emits one finding per operation or field coordinate
+(Query.orders, Order.total), without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field, replace
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_of, line_span
+from app.services.node_route_extractor import JS_SUFFIXES, _source
+from app.services.realtime_route_extractor import AUTH_CHECK, checked_roles
+from app.services.typescript_route_extractor import (
+    CLASS,
+    Guard,
+    argument_values,
+    build_environment,
+    class_decorators,
+    class_members,
+    dedupe,
+    is_typescript_source,
+    prepare_file,
+)
+
+GRAPHQL_SUFFIXES = (".graphql", ".graphqls", ".gql")
+
+# Snippets show at most this many lines
+MAX_SNIPPET_LINES = 40
+# Nesting of directive argument values and graphql-shield rule expressions followed
+MAX_DEPTH = 16
+
+# Code files not mentioning any of these are not read for SDL, resolvers, or directive implementations
+PREFILTER = re.compile(r"graphql|gql|typeDefs|type_defs|Resolver|shield|DirectiveRoot|ariadne", re.IGNORECASE)
+
+DEFINITION_KEYWORDS = {"directive", "schema", "extend", "type", "interface", "input", "enum", "union", "scalar"}
+ROOT_OPERATIONS = {"query": "Query", "mutation": "Mutation", "subscription": "Subscription"}
+
+# Whitespace, commas, and comments are skipped ahead of each token
+TOKEN = re.compile(
+    r'(?:[\s,]|#[^\n]*)*+("""(?:[^"\\]|\\.|"(?!""))*(?:"""|\Z)|"(?:[^"\\\n]|\\.)*"?|[_A-Za-z][_0-9A-Za-z]*|'
+    r"-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?|\.\.\.|.)?",
+    re.DOTALL,
+)
+NAME = re.compile(r"[_A-Za-z][_0-9A-Za-z]*")
+# SDL embedded in code: gql`...` and graphql`...` templates, typeDefs = `...`, and Python type_defs = """..."""
+EMBEDDED_SDL = re.compile(
+    r"\b(?:gql|graphql)\s*(?:\(\s*)?`([^`]*)`|\b(?:typeDefs|type_defs|schema|sdl)\s*=\s*(?:gql\s*\(\s*)?(?:`([^`]*)`|\"\"\"((?:[^\"\\]|\\.|\"(?!\"\"))*)\"\"\")"
+)
+SDL_HINT = re.compile(r"\b(?:type|extend\s+type|directive)\s+[@_A-Za-z]")
+
+# Directives that come with GraphQL, federation, and caching, and never authorize
+NON_AUTH_DIRECTIVES = {
+    "deprecated",
+    "specifiedBy",
+    "oneOf",
+    "include",
+    "skip",
+    "defer",
+    "stream",
+    "key",
+    "external",
+    "requires",
+    "provides",
+    "shareable",
+    "inaccessible",
+    "override",
+    "tag",
+    "extends",
+    "link",
+    "composeDirective",
+    "interfaceObject",
+    "cacheControl",
+    "goField",
+    "goModel",
+    "goTag",
+}
+AUTH_DIRECTIVE = re.compile(r"(?i)auth|role|scope|permission|polic|guard|cognito|aws_iam|aws_oidc|aws_lambda|admin|owner|private|login|access")
+PUBLIC_DIRECTIVE = re.compile(r"(?i)public|skip_?auth|allow_?anonymous|anonymous|no_?auth|unauthenticated")
+ROLE_ARGUMENT = re.compile(r"(?i)role|group|requires|allow")
+PERMISSION_ARGUMENT = re.compile(r"(?i)scope|perm|polic|claim|action|privilege")
+# Directives the platform serving the schema enforces, with no implementation in the repository
+PLATFORM_DIRECTIVES = {
+    "aws_auth": "AWS AppSync",
+    "aws_cognito_user_pools": "AWS AppSync",
+    "aws_iam": "AWS AppSync",
+    "aws_oidc": "AWS AppSync",
+    "aws_lambda": "AWS AppSync",
+    "authenticated": "the Apollo Router",
+    "requiresScopes": "the Apollo Router",
+    "policy": "the Apollo Router",
+}
+# Amplify @auth(rules: [...]) is enforced by the transformer that generates the API
+AMPLIFY = "AWS Amplify"
+
+GO_RESOLVER = re.compile(r"^func\s*\(\s*\w+\s+\*?(\w+?)Resolver\s*\)\s*(\w+)\s*\(", re.MULTILINE)
+GENERATED = re.compile(r"^// Code generated .* DO NOT EDIT\.$", re.MULTILINE)
+RESOLVER_TYPE = re.compile(r"(?<![\w$.])([A-Z][\w$]*)\s*:\s*\{")
+RESOLVER_FUNCTION = re.compile(r"\s*(?:async\s+)?(?:function\b|\([^)]*\)\s*=>|[A-Za-z_$][\w$]*\s*=>)")
+SHIELD = re.compile(r"\bshield\s*\(\s*\{")
+SHIELD_RULE = re.compile(r"\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*rule\s*\(")
+SHIELD_CALL = re.compile(r"^(and|or|chain|race|not)\s*\(")
+TYPE_GRAPHQL_IMPORT = re.compile(r"['\"]type-graphql['\"]")
+CODE_FIRST_OPERATIONS = {"Query": "Query", "Mutation": "Mutation", "Subscription": "Subscription", "FieldResolver": None}
+OBJECT_TYPE = re.compile(r"=>\s*\[?\s*([A-Z][\w$]*)|^\s*['\"]?([A-Z][\w$]*)")
+# Checks for a signed-in caller specific to GraphQL servers
+GRAPHQL_AUTH_CHECK = re.compile(
+    r"\bForContext\s*\(|\b(?:ctx|context)\s*\.\s*(?:user|currentUser|auth|session)\b|\bAuthenticationError\b|\bUNAUTHENTICATED\b|"
+    r"\bForbiddenError\b|\bFORBIDDEN\b|\bErrUnauthorized\b|\bErrUnauthenticated\b|\bErrForbidden\b|info\.context\b"
+)
+
+# Role checks in Go resolvers: user.HasRole("admin"), auth.RequireRole(ctx, "admin")
+GO_ROLE_CHECK = re.compile(r'\b(?:Has|Require|Check)(?:Any)?Roles?\(\s*(?:ctx\s*,\s*)?"(?:ROLE_)?([\w-]+)"')
+
+PUBLIC = "public"
+
+
+class GraphQLRouteKind:
+    """Kinds of GraphQL findings."""
+
+    OPERATION = "graphql_operation"  # A field of the Query, Mutation, or Subscription type
+    FIELD = "graphql_field"  # A field of any other object type
+
+
+@dataclass
+class _Directive:
+    """A directive applied in the schema."""
+
+    name: str
+    args: dict
+    label: str
+
+
+@dataclass
+class _Field:
+    """A field definition and where it is declared."""
+
+    name: str
+    directives: list[_Directive]
+    file_path: str
+    text: str
+    start: int
+    end: int
+
+
+@dataclass
+class _Type:
+    """An object type or interface, merged across its definition and extensions."""
+
+    name: str
+    directives: list[_Directive] = field(default_factory=list)
+    fields: dict[str, _Field] = field(default_factory=dict)
+
+
+@dataclass
+class _Schema:
+    """Types, directive definitions, and root operation types of an API's SDL."""
+
+    types: dict[str, _Type] = field(default_factory=dict)
+    defaults: dict[str, dict] = field(default_factory=dict)  # directive -> argument defaults
+    roots: dict[str, str] = field(default_factory=lambda: dict(ROOT_OPERATIONS))
+
+
+@dataclass
+class _Check:
+    """An authorization check found outside the schema, for one field coordinate."""
+
+    guard: Guard | str  # Guard, or PUBLIC
+    field: str  # Field name as the resolver spells it
+    file_path: str
+    text: str
+    start: int
+    end: int
+
+
+class _Parser:
+    """Recursive-descent reader over SDL tokens, keeping offsets into the file."""
+
+    def __init__(self, sdl: str, offset: int):
+        self.tokens = [(m[1], m.start(1) + offset) for m in TOKEN.finditer(sdl) if m[1]]
+        self.i = 0
+        self.sdl, self.offset = sdl, offset
+
+    def peek(self, ahead: int = 0) -> str:
+        """Token ahead of the cursor, or ""."""
+        at = self.i + ahead
+        return self.tokens[at][0] if at < len(self.tokens) else ""
+
+    def take(self) -> str:
+        """Consume a token; at the end of the SDL the cursor stays put."""
+        token = self.peek()
+        self.i = min(self.i + 1, len(self.tokens))
+        return token
+
+    def position(self) -> int:
+        """File offset of the token at the cursor, or of the end of the SDL."""
+        return self.tokens[self.i][1] if self.i < len(self.tokens) else self.offset + len(self.sdl)
+
+    def skip_group(self) -> None:
+        """Skip a bracketed group starting at the cursor."""
+        closers = {"(": ")", "[": "]", "{": "}"}
+        stack = [closers[self.take()]]
+        while stack and self.i < len(self.tokens):
+            token = self.take()
+            if token in closers:
+                stack.append(closers[token])
+            elif token == stack[-1]:
+                stack.pop()
+
+    def value(self, depth: int = 0):
+        """A directive argument value: strings and names as str, lists, and objects as dicts."""
+        token = self.peek()
+        if depth > MAX_DEPTH:
+            if token in ("(", "[", "{"):
+                self.skip_group()
+            else:
+                self.take()
+            return None
+        if token == "[":
+            self.take()
+            items = []
+            while self.peek() and self.peek() != "]":
+                before = self.i
+                items.append(self.value(depth + 1))
+                if self.i == before:
+                    self.take()
+            self.take()
+            return items
+        if token == "{":
+            self.take()
+            entries = {}
+            while self.peek() and self.peek() != "}":
+                key = self.take()
+                if self.peek() == ":":
+                    self.take()
+                    entries[key] = self.value(depth + 1)
+            self.take()
+            return entries
+        if token == "$":
+            self.take()
+        token = self.take()
+        if token.startswith('"'):
+            return token.strip('"')
+        return token
+
+    def directives(self) -> list[_Directive]:
+        """Directives applied at the cursor."""
+        found = []
+        while self.peek() == "@" and NAME.fullmatch(self.peek(1)):
+            start = self.position()
+            self.take()
+            name = self.take()
+            args = {}
+            if self.peek() == "(":
+                self.take()
+                while self.peek() and self.peek() != ")":
+                    key = self.take()
+                    if self.peek() == ":":
+                        self.take()
+                        args[key] = self.value()
+                self.take()
+            end = self.tokens[self.i - 1][1] + len(self.tokens[self.i - 1][0])
+            found.append(_Directive(name, args, " ".join(self.sdl[start - self.offset : end - self.offset].split())))
+        return found
+
+    def argument_defaults(self) -> dict:
+        """Default values of an argument definition list at the cursor."""
+        defaults = {}
+        if self.peek() != "(":
+            return defaults
+        self.take()
+        while self.peek() and self.peek() != ")":
+            if self.peek().startswith('"'):
+                self.take()
+                continue
+            name = self.take()
+            if self.peek() != ":":
+                continue
+            self.take()
+            while self.peek() in ("[", "]", "!") or (NAME.fullmatch(self.peek()) and self.peek(1) != ":"):
+                self.take()
+            if self.peek() == "=":
+                self.take()
+                defaults[name] = self.value()
+            self.directives()
+        self.take()
+        return defaults
+
+    def type_reference(self) -> None:
+        """Skip a field type such as [Order!]!."""
+        while self.peek() in ("[", "]", "!"):
+            self.take()
+        if NAME.fullmatch(self.peek()):
+            self.take()
+        while self.peek() in ("]", "!"):
+            self.take()
+
+
+def parse_sdl(schema: _Schema, file_path: str, text: str, sdl: str, offset: int = 0) -> None:
+    """Add the types, directive definitions, and schema roots an SDL document declares.
+
+    Args:
+        schema: Schema to add to
+        file_path: File the SDL is in
+        text: The whole file, for snippets
+        sdl: SDL text
+        offset: Offset of the SDL in the file
+    """
+    p = _Parser(sdl, offset)
+    for at in [i for i, (token, _) in enumerate(p.tokens) if token in DEFINITION_KEYWORDS]:
+        if at < p.i:
+            continue
+        p.i = at
+        token = p.take()
+        if token == "directive" and p.peek() == "@":
+            p.take()
+            name = p.take()
+            schema.defaults[name] = p.argument_defaults()
+        elif token == "schema" or (token == "extend" and p.peek() == "schema"):
+            if token == "extend":
+                p.take()
+            p.directives()
+            if p.peek() == "{":
+                p.take()
+                while p.peek() and p.peek() != "}":
+                    operation = p.take()
+                    if p.peek() == ":":
+                        p.take()
+                        schema.roots[operation] = p.take()
+                p.take()
+        elif token in ("type", "interface") and NAME.fullmatch(p.peek()):
+            name = p.take()
+            if p.peek() == "implements":
+                p.take()
+                while NAME.fullmatch(p.peek()) or p.peek() == "&":
+                    p.take()
+            definition = schema.types.setdefault(name, _Type(name))
+            definition.directives += p.directives()
+            if p.peek() != "{":
+                continue
+            p.take()
+            while p.peek() and p.peek() != "}":
+                if p.peek().startswith('"'):
+                    p.take()
+                    continue
+                start = p.position()
+                field_name = p.take()
+                if not NAME.fullmatch(field_name):
+                    continue
+                if p.peek() == "(":
+                    p.skip_group()
+                if p.peek() != ":":
+                    continue
+                p.take()
+                p.type_reference()
+                directives = p.directives()
+                end = p.tokens[p.i - 1][1] + len(p.tokens[p.i - 1][0])
+                definition.fields.setdefault(field_name, _Field(field_name, directives, file_path, text, start, end))
+            p.take()
+        elif token in ("input", "enum", "union", "scalar") and NAME.fullmatch(p.peek()):
+            p.take()
+            p.directives()
+            if p.peek() == "{":
+                p.skip_group()
+
+
+def _strings(value) -> list[str]:
+    """Strings in a directive argument value, lists and nested lists flattened."""
+    if isinstance(value, str):
+        return [value]
+    if isinstance(value, list):
+        return [s for item in value for s in _strings(item)]
+    return []
+
+
+def _amplify(directive: _Directive, rules: list) -> Guard | str:
+    """Guard of Amplify @auth(rules: [...]); any rule may let a caller in."""
+    roles, conditions = [], []
+    for rule in rules:
+        if not isinstance(rule, dict):
+            continue
+        allow = rule.get("allow")
+        if allow == "public":
+            return PUBLIC
+        if allow == "groups":
+            roles += _strings(rule.get("groups"))
+            if rule.get("groupsField"):
+                conditions.append(f"caller is in a group named by {rule['groupsField']}")
+        elif allow == "owner":
+            conditions.append(f"caller owns the record ({rule.get('ownerField') or 'owner'})")
+        elif allow == "custom":
+            conditions.append("passes the custom Lambda authorizer")
+    if roles and conditions:
+        return Guard(directive.label, conditions=[f"any of: {' or '.join(dedupe(roles))} | {' | '.join(conditions)}"])
+    return Guard(directive.label, roles=dedupe(roles), conditions=conditions)
+
+
+def directive_guard(directive: _Directive, defaults: dict[str, dict]) -> Guard | str | None:
+    """What an authorization directive requires.
+
+    Argument values are roles or permissions by the argument's name, then
+    the directive's; defaults declared with the directive fill in omitted
+    arguments, and a directive without arguments requires a signed-in caller.
+
+    Returns:
+        Guard; PUBLIC for directives opening a field; None for directives that do not authorize
+    """
+    name = directive.name
+    if name in NON_AUTH_DIRECTIVES or not (AUTH_DIRECTIVE.search(name) or PUBLIC_DIRECTIVE.search(name)):
+        return None
+    if PUBLIC_DIRECTIVE.search(name):
+        return PUBLIC
+    args = {**defaults.get(name, {}), **directive.args}
+    if isinstance(args.get("rules"), list):
+        return _amplify(directive, args["rules"])
+    roles, permissions = [], []
+    for argument, value in args.items():
+        if ROLE_ARGUMENT.search(argument):
+            roles += _strings(value)
+        elif PERMISSION_ARGUMENT.search(argument):
+            permissions += _strings(value)
+        elif PERMISSION_ARGUMENT.search(name):
+            permissions += _strings(value)
+        else:
+            roles += _strings(value)
+    return Guard(directive.label, roles=dedupe(roles), permissions=dedupe(permissions))
+
+
+def _implementation_patterns(name: str) -> list[re.Pattern]:
+    """Ways servers register an implementation of a directive."""
+    pascal = name[:1].upper() + name[1:]
+    quoted = re.escape(name)
+    return [
+        re.compile(rf"\bDirectives\s*\.\s*{re.escape(pascal)}\s*=|\bDirectiveRoot\s*\{{[^}}]{{0,2000}}?\b{re.escape(pascal)}\s*:"),
+        re.compile(rf"\bgetDirective\s*\([^)]{{0,200}}?['\"]{quoted}['\"]|\b{quoted}DirectiveTransformer\b"),
+        re.compile(rf"\b(?:schemaDirectives|directiveResolvers)\s*:\s*\{{[^}}]{{0,2000}}?\b{quoted}\b"),
+        re.compile(rf"\bdirectives\s*=\s*\{{[^}}]{{0,2000}}?['\"]{quoted}['\"]|\bclass\s+{re.escape(pascal)}Directive\b"),
+    ]
+
+
+def find_implementation(name: str, code: dict[str, str]) -> str | None:
+    """Where a directive is implemented: a platform, a file path, or None when nothing implements it."""
+    if name in PLATFORM_DIRECTIVES:
+        return PLATFORM_DIRECTIVES[name]
+    patterns = _implementation_patterns(name)
+    for file_path in sorted(code):
+        text = code[file_path]
+        if name in text or name[:1].upper() + name[1:] in text:
+            if any(pattern.search(text) for pattern in patterns):
+                return file_path
+    return None
+
+
+def _body_guard(label: str, body: str) -> Guard | None:
+    """Guard of a resolver or rule body: the roles it compares, a signed-in check, or nothing."""
+    roles = dedupe(checked_roles(body) + [r.upper() if r.islower() and len(r) > 2 else r for r in GO_ROLE_CHECK.findall(body)])
+    if roles:
+        return Guard(label, roles=roles)
+    if AUTH_CHECK.search(body) or GRAPHQL_AUTH_CHECK.search(body):
+        return Guard(label)
+    return None
+
+
+def _go_resolvers(file_path: str, text: str, checks: dict) -> None:
+    """Checks inside gqlgen resolver methods, keyed by (type, lowercased field)."""
+    if GENERATED.search(text):
+        return
+    matches = list(GO_RESOLVER.finditer(text))
+    for match, following in zip(matches, matches[1:] + [None]):
+        # A method without its closing brace ends where the next resolver starts
+        limit = following.start() if following else len(text)
+        open_brace = text.find("{\n", match.end(), limit)
+        if open_brace < 0:
+            continue
+        close = text.find("\n}", open_brace, limit)
+        end = close + 2 if close >= 0 else limit
+        type_name = match.group(1)[:1].upper() + match.group(1)[1:]
+        guard = _body_guard(f"resolver {match.group(1)}Resolver.{match.group(2)}", text[open_brace:end])
+        if guard:
+            checks.setdefault((type_name, match.group(2).lower()), []).append(
+                _Check(guard, match.group(2)[:1].lower() + match.group(2)[1:], file_path, text, match.start(), end)
+            )
+
+
+def _closed(src, open_at: int) -> bool:
+    """Check whether a bracket closes before the end of the file; unclosed ones would span the rest of it."""
+    return src.pairs.get(open_at, len(src.masked)) < len(src.masked)
+
+
+def _js_resolvers(file_path: str, text: str, types: set[str], checks: dict) -> None:
+    """Checks inside JavaScript resolver maps: Query: { orders(parent, args, ctx) { ... } }."""
+    src = _source(text)
+    for match in RESOLVER_TYPE.finditer(src.masked):
+        type_name = match.group(1)
+        if type_name not in types:
+            continue
+        open_brace = match.end() - 1
+        if not _closed(src, open_brace):
+            continue
+        for key, (s, e) in src.entries(open_brace, src.pairs[open_brace]).items():
+            body = text[s:e]
+            # Method shorthand spans start at the key; other values must be functions, not shield rules
+            method = body.removeprefix("async").lstrip()
+            if not (method.startswith(key) and method[len(key) :].lstrip().startswith("(")) and not RESOLVER_FUNCTION.match(body):
+                continue
+            guard = _body_guard(f"resolver {type_name}.{key}", body)
+            if guard:
+                checks.setdefault((type_name, key.lower()), []).append(_Check(guard, key, file_path, text, s, e))
+
+
+def _shield_guard(expression: str, rules: dict[str, str], depth: int = 0) -> Guard | str | None:
+    """Guard of a graphql-shield rule expression: a rule, allow or deny, or an and/or/chain/race/not composition."""
+    expression = " ".join(expression.split())
+    if depth > MAX_DEPTH or not expression:
+        return None
+    if expression == "allow":
+        return PUBLIC
+    if expression == "deny":
+        return Guard("graphql-shield deny", conditions=["denied to everyone"])
+    call = SHIELD_CALL.match(expression)
+    if call:
+        src = _source(expression)
+        open_at = call.end() - 1
+        close = src.pairs.get(open_at, len(expression))
+        parts = [expression[s:e] for s, e in src.split(open_at + 1, close - 1)]
+        inner = [_shield_guard(part, rules, depth + 1) for part in parts]
+        label = f"graphql-shield {expression}"
+        if call.group(1) == "not":
+            return Guard(label, conditions=[f"does not pass {expression[open_at + 1 : close - 1].strip()}"])
+        if call.group(1) in ("and", "chain"):
+            guards = [g for g in inner if isinstance(g, Guard)]
+            if not guards:
+                return PUBLIC if PUBLIC in inner else None
+            return Guard(
+                label,
+                roles=dedupe([r for g in guards for r in g.roles]),
+                permissions=dedupe([p for g in guards for p in g.permissions]),
+                conditions=[c for g in guards for c in g.conditions],
+            )
+        if PUBLIC in inner:
+            return PUBLIC
+        guards = [g for g in inner if isinstance(g, Guard)]
+        if guards and all(g.roles and not g.conditions and not g.permissions for g in guards):
+            return Guard(label, roles=dedupe([r for g in guards for r in g.roles]))
+        return Guard(label, conditions=[f"any of: {' | '.join(parts)}"]) if guards else None
+    if expression in rules:
+        return _body_guard(f"graphql-shield {expression}", rules[expression]) or Guard(
+            f"graphql-shield {expression}", conditions=[f"passes {expression}"]
+        )
+    return Guard(f"graphql-shield {expression}", conditions=[f"passes {expression}"])
+
+
+def _shield(file_path: str, text: str, checks: dict) -> None:
+    """Checks of graphql-shield permission trees: shield({ Query: { orders: isAdmin } })."""
+    src = _source(text)
+    rules = {}
+    for match in SHIELD_RULE.finditer(src.masked):
+        open_at = match.end() - 1
+        close = src.pairs.get(open_at)
+        if close is not None and src.masked[close : close + 1] == "(" and _closed(src, close):
+            rules[match.group(1)] = text[close : src.pairs[close]]
+    for match in SHIELD.finditer(src.masked):
+        open_brace = match.end() - 1
+        if not _closed(src, open_brace):
+            continue
+        for type_name, (s, e) in src.entries(open_brace, src.pairs[open_brace]).items():
+            if src.masked[s] != "{":
+                continue
+            for field_name, (fs, fe) in src.entries(s, e).items():
+                guard = _shield_guard(text[fs:fe], rules)
+                if guard is not None:
+                    checks.setdefault((type_name, field_name.lower()), []).append(_Check(guard, field_name, file_path, text, fs, fe))
+
+
+def _type_graphql(file_path: str, text: str, env, findings: list) -> None:
+    """Findings for TypeGraphQL resolver classes: @Authorized() on classes and @Query/@Mutation/@FieldResolver methods."""
+    ts = prepare_file(file_path, text)
+    for match in CLASS.finditer(ts.src.masked):
+        decorators = class_decorators(ts, match.start())
+        resolver = next((d for d in decorators if d.name == "Resolver"), None)
+        if resolver is None:
+            continue
+        argument = ts.code[resolver.paren + 1 : resolver.end - 1] if resolver.paren >= 0 else ""
+        object_type = OBJECT_TYPE.search(argument)
+        owner = (object_type.group(1) or object_type.group(2)) if object_type else "Object"
+        class_guards = _authorized(ts, decorators, env)
+        open_brace = match.end() - 1
+        for method_decorators, span in class_members(ts, open_brace + 1, ts.src.pairs.get(open_brace, len(ts.src.masked)) - 1):
+            operation = next((d for d in method_decorators if d.name in CODE_FIRST_OPERATIONS), None)
+            if operation is None:
+                continue
+            guards = class_guards + _authorized(ts, method_decorators, env)
+            if not guards:
+                continue
+            member = re.match(r"(?:(?:public|private|protected|static|async)\s+)*([A-Za-z_$][\w$]*)", ts.src.masked[span[0] : span[1]])
+            options = re.search(r"\bname\s*:\s*['\"]([^'\"]+)['\"]", ts.code[operation.start : operation.end])
+            name = options.group(1) if options else member.group(1) if member else "field"
+            type_name = CODE_FIRST_OPERATIONS[operation.name] or owner
+            findings.append(_finding(file_path, text, operation.start, span[1], type_name, name, guards, type_name == owner))
+
+
+def _authorized(ts, decorators: list, env) -> list[Guard]:
+    """Guards of TypeGraphQL @Authorized() decorators: roles, or a signed-in caller without arguments."""
+    guards = []
+    for decorator in decorators:
+        if decorator.name != "Authorized":
+            continue
+        label = " ".join(ts.code[decorator.start : decorator.end].split())
+        spans = ts.src.split(decorator.paren + 1, decorator.end - 1) if decorator.paren >= 0 else []
+        values = [argument_values(ts.code[s:e], env, ts.renames) for s, e in spans]
+        if any(v is None for v in values):
+            guards.append(Guard(label, unresolved=f"{label} takes a value that cannot be resolved statically"))
+        else:
+            guards.append(Guard(label, roles=dedupe([r for v in values for r in v])))
+    return guards
+
+
+def _finding(
+    file_path: str, text: str, start: int, end: int, type_name: str, field_name: str, guards: list[Guard], field_level: bool
+) -> ConfigFinding:
+    """One finding for a field coordinate from its guards."""
+    roles = dedupe([role for g in guards for role in g.roles])
+    permissions = dedupe([permission for g in guards for permission in g.permissions])
+    conditions = [f"requires permission {p}" for p in permissions]
+    conditions += [c for g in guards for c in g.conditions] + [g.unresolved for g in guards if g.unresolved]
+    line_start = line_of(text, start)
+    line_end = min(line_of(text, end), line_start + MAX_SNIPPET_LINES - 1)
+    coordinate = f"{type_name}.{field_name}"
+    return ConfigFinding(
+        kind=GraphQLRouteKind.FIELD if field_level else GraphQLRouteKind.OPERATION,
+        file_path=file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(text, line_start, line_end),
+        subject=" or ".join(roles) if roles else "Authenticated users",
+        resource=coordinate,
+        action="RESOLVE" if field_level else type_name.upper(),
+        conditions="; ".join(dedupe(conditions)) or None,
+        description=f"GraphQL {coordinate} guarded by {', '.join(dedupe([g.label for g in guards]))}",
+    )
+
+
+def _public(finding: ConfigFinding, label: str) -> ConfigFinding:
+    """A finding for a field a public directive or rule opens to anyone."""
+    return replace(
+        finding,
+        subject="Anonymous",
+        conditions=None,
+        disables_auth=True,
+        description=f"GraphQL {finding.resource} open to anyone: {label}",
+    )
+
+
+def is_graphql_schema(file_path: str) -> bool:
+    """Check whether a path is a GraphQL schema file."""
+    return PurePosixPath(file_path).suffix in GRAPHQL_SUFFIXES
+
+
+def extract_graphql_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract per-operation and per-field GraphQL authorization.
+
+    Fields of the root operation types get a finding when their own
+    directives, their type's directives, a resolver, or a shield rule check
+    anything; fields of other types only when checked at the field itself.
+    Schema directives without an implementation in the repository are
+    reported as conditions, since the server may not enforce them.
+
+    Args:
+        files: Relative path -> content of schema files and JS, TS, Go, and
+            Python sources
+
+    Returns:
+        One finding per checked field coordinate
+    """
+    schema = _Schema()
+    code = {}
+    for file_path in sorted(files):
+        text = files[file_path]
+        if is_graphql_schema(file_path):
+            parse_sdl(schema, file_path, text, text)
+        elif PurePosixPath(file_path).suffix in JS_SUFFIXES + (".go", ".py") and PREFILTER.search(text):
+            code[file_path] = text
+            for match in EMBEDDED_SDL.finditer(text):
+                group = next(g for g in (1, 2, 3) if match.group(g) is not None)
+                if SDL_HINT.search(match.group(group)):
+                    parse_sdl(schema, file_path, text, match.group(group), match.start(group))
+
+    types = set(schema.types) | set(schema.roots.values())
+    checks: dict[tuple[str, str], list[_Check]] = {}
+    findings = []
+    type_graphql = {p: t for p, t in code.items() if is_typescript_source(p) and TYPE_GRAPHQL_IMPORT.search(t)}
+    # Role enums and constants live in modules that never mention GraphQL
+    env = build_environment({p: t for p, t in files.items() if is_typescript_source(p)}) if type_graphql else None
+    for file_path, text in code.items():
+        if file_path.endswith(".go"):
+            _go_resolvers(file_path, text, checks)
+        elif PurePosixPath(file_path).suffix in JS_SUFFIXES:
+            _js_resolvers(file_path, text, types, checks)
+            if "shield" in text:
+                _shield(file_path, text, checks)
+            if file_path in type_graphql:
+                _type_graphql(file_path, text, env, findings)
+
+    implementations: dict[str, str | None] = {}
+    roots = set(schema.roots.values())
+    seen = set()
+    for type_name in sorted(schema.types):
+        definition = schema.types[type_name]
+        root = type_name in roots
+        for field_name, declared in definition.fields.items():
+            own = [(d, directive_guard(d, schema.defaults)) for d in declared.directives]
+            own = [(d, g) for d, g in own if g is not None]
+            inherited = [(d, g) for d in definition.directives if (g := directive_guard(d, schema.defaults)) is not None]
+            # A field's own directives replace those of its type
+            applied = own or inherited
+            guards, public = [], None
+            for directive, guard in applied:
+                if guard == PUBLIC:
+                    public = public or directive.label
+                    continue
+                if directive.name not in implementations:
+                    implementations[directive.name] = (
+                        AMPLIFY if isinstance(directive.args.get("rules"), list) else find_implementation(directive.name, code)
+                    )
+                implemented = implementations[directive.name]
+                if implemented is None:
+                    guard.conditions = guard.conditions + [f"@{directive.name} has no implementation in the repository, so it may not be enforced"]
+                guards.append(guard)
+            for check in checks.get((type_name, field_name.lower()), []):
+                if check.guard == PUBLIC:
+                    public = public or "graphql-shield allow"
+                else:
+                    guards.append(check.guard)
+            seen.add((type_name, field_name.lower()))
+            if guards:
+                findings.append(_finding(declared.file_path, declared.text, declared.start, declared.end, type_name, field_name, guards, not root))
+            elif public:
+                finding = _finding(declared.file_path, declared.text, declared.start, declared.end, type_name, field_name, [Guard(public)], not root)
+                findings.append(_public(finding, public))
+
+    # Resolvers of schemas built in code, with no SDL in the repository
+    for (type_name, key), found in sorted(checks.items()):
+        if (type_name, key) in seen or type_name not in roots:
+            continue
+        guards = [c.guard for c in found if isinstance(c.guard, Guard)]
+        check = found[0]
+        if guards:
+            findings.append(_finding(check.file_path, check.text, check.start, check.end, type_name, check.field, guards, False))
+        else:
+            finding = _finding(check.file_path, check.text, check.start, check.end, type_name, check.field, [Guard("graphql-shield allow")], False)
+            findings.append(_public(finding, "graphql-shield allow"))
+    return findings
@@ -0,0 +1,701 @@
+"""Extract per-RPC authorization from Go gRPC services.
+
+gRPC servers authorize in interceptors rather than per route: a
+grpc.UnaryServerInterceptor or StreamServerInterceptor registered with
+grpc.NewServer() reads the caller's token or claims from the incoming
+metadata and denies with status.Error(codes.Unauthenticated) or
+codes.PermissionDenied. Which RPCs a check covers is decided inside the
+interceptor, or a helper it passes info.FullMethod to: by comparing the
+method name, letting public methods through, or looking the method up in a
+map of full method name -> roles. The RPCs themselves are only listed in
+the generated _grpc.pb.go descriptors.
+
+This extractor reads services and methods from those descriptors (the
+ServiceDesc MethodName/StreamName entries, and the *_FullMethodName
+constants code uses to refer to them), attributes each interceptor check to
+the full method names it applies to, adds checks in the service
+implementation's own methods, and emits one ConfigFinding per guarded RPC,
+without LLM calls. An RPC is reported as POST on its full method name
+(/orders.v1.OrderService/DeleteOrder), which is how gRPC calls travel over
+HTTP/2, so it maps onto endpoint rules like an HTTP route.
+"""
+
+import re
+from dataclasses import dataclass, field, replace
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_of, line_span
+from app.services.go_route_extractor import (
+    AUTHENTICATION_MIDDLEWARE,
+    CALLEE,
+    GO_DECLARATION,
+    IF_STATEMENT,
+    INLINE_HANDLER,
+    MAX_RESOLVE_DEPTH,
+    ROLE_MIDDLEWARE,
+    _arguments,
+    _body_brace,
+    _call_arguments,
+    _condition_roles,
+    _Guard,
+    _params,
+    _permit_condition,
+    _statement_end,
+    _string,
+    is_go_source,
+)
+from app.services.node_route_extractor import _source, _Source
+
+# Applications not importing gRPC are not parsed
+GRPC_IMPORT = re.compile(r"\"google\.golang\.org/grpc\"")
+
+
+class GrpcRouteKind:
+    """Kinds of gRPC findings."""
+
+    RPC = "grpc_rpc"
+
+
+GO_METHOD = re.compile(r"^func\s+(?:\(\s*\w*\s*\*?\s*(\w+)\s*\)\s*)?([A-Za-z_]\w*)\s*\(", re.MULTILINE)
+SERVICE_DESC = re.compile(r"\bvar\s+_?([A-Za-z]\w*?)_(?:ServiceDesc|serviceDesc)\s*=\s*grpc\s*\.\s*ServiceDesc\s*\{")
+DESC_FIELD = re.compile(r"\b(ServiceName|MethodName|StreamName)\s*:\s*")
+FULL_METHOD_CONSTANT = re.compile(r"\b(\w+_FullMethodName)\s*=\s*")
+REGISTRATION = re.compile(r"(?<![\w.])(?:\w+\s*\.\s*)?Register(\w+)Server\s*\(")
+IMPLEMENTATION = re.compile(r"^&?\s*(?:\w+\s*\.\s*)?(\w+)\s*(\{|\()")
+INTERCEPTOR_OPTION = re.compile(
+    r"(?<![\w.])(?:grpc\s*\.\s*(?:Chain)?(Unary|Stream)Interceptor|\w+\s*\.\s*Chain(Unary|Stream)Server)\s*\("
+)
+INTERCEPTOR_SLICE = re.compile(r"^\[\]\s*grpc\s*\.\s*(?:Unary|Stream)ServerInterceptor\s*\{")
+INTERCEPTOR_INFO = re.compile(r"\*\s*grpc\s*\.\s*(?:Unary|Stream)ServerInfo\b")
+INTERCEPTOR_TYPE = re.compile(r"^\s*grpc\s*\.\s*(?:Unary|Stream)ServerInterceptor\b")
+HANDLER_TYPE = re.compile(r"\bgrpc\s*\.\s*(?:Unary|Stream)Handler\b")
+CALL = re.compile(r"(?<![\w.])(?:[A-Za-z_]\w*\s*\.\s*)?([A-Za-z_]\w*)\s*\(")
+MAP_LITERAL = re.compile(r"(?<![\w.])([A-Za-z_]\w*)\s*:?=\s*map\s*\[\s*string\s*\]\s*([^{\n]*?)\s*\{")
+SWITCH = re.compile(r"\bswitch\s+([^{;\n]*)\{")
+CASE = re.compile(r"\b(?:case\s+([^:]*)|default\s*):")
+METHOD_LITERAL = re.compile(r"\"(/[\w.]+/[\w.]*)\"")
+CONSTANT_REFERENCE = re.compile(r"\b(\w+_FullMethodName)\b")
+INDEX = re.compile(r"(?<![\w.])(\w+)\s*\[\s*([^\[\]]+?)\s*\]")
+HAS_PREFIX = re.compile(r"\bHasPrefix\s*\(\s*([^,()]+?)\s*,\s*\"([^\"]+)\"\s*\)")
+# A helper's early "return nil" (or "return ctx, nil") lets the call through
+ALLOWED = re.compile(r"\breturn\s+(?:[\w.]+\s*,\s*)?nil\s*(?=[\n;}])")
+
+# Responses that end a call as unauthenticated or forbidden
+DENIAL = re.compile(
+    r"\bstatus\s*\.\s*(?:Error|Errorf|New|Newf)\s*\(\s*codes\s*\.\s*(Unauthenticated|PermissionDenied)\b"
+    r"|\b[Ee]rr(Unauthenticated|PermissionDenied)\b"
+)
+
+
+@dataclass
+class GrpcMethod:
+    """An RPC declared in a generated service descriptor."""
+
+    service: str  # Go name of the service, e.g. OrderService
+    name: str
+    full_name: str  # e.g. /orders.v1.OrderService/DeleteOrder
+    streaming: bool
+
+
+@dataclass
+class _Function:
+    """A top-level function or method of a Go source file."""
+
+    file_path: str
+    receiver: str | None
+    name: str
+    params: list[tuple[str, str]]
+    result: str
+    body: tuple[int, int]
+
+    @property
+    def interceptor(self) -> bool:
+        return bool(INTERCEPTOR_TYPE.match(self.result)) or any(INTERCEPTOR_INFO.search(k) for _, k in self.params)
+
+
+@dataclass
+class _MethodMap:
+    """A map literal keyed by full method name."""
+
+    file_path: str
+    roles: dict[str, list[str] | None]  # full method name -> roles; None for a set of methods
+    lines: dict[str, int]
+
+    @property
+    def listed(self) -> bool:
+        return any(self.roles.values())
+
+
+@dataclass
+class _Selector:
+    """The methods a condition on the called method's name picks."""
+
+    names: set[str] = field(default_factory=set)
+    prefixes: list[str] = field(default_factory=list)
+    negated: bool = False
+    roles: _MethodMap | None = None  # The check requires the roles listed for each method
+
+    def matches(self, full_name: str) -> bool:
+        picked = full_name in self.names or any(full_name.startswith(p) for p in self.prefixes)
+        return picked != self.negated
+
+
+@dataclass
+class _RpcCheck:
+    """A denial in an interceptor or handler, with the methods it applies to."""
+
+    forbidden: bool
+    condition: str | None
+    selectors: list[_Selector]
+
+
+@dataclass
+class _Body:
+    """What one interceptor or handler body checks, including helpers it calls."""
+
+    checks: list[_RpcCheck] = field(default_factory=list)
+    passes: list[list[_Selector]] = field(default_factory=list)  # Methods let through before any check
+    parameters: list[str] = field(default_factory=list)
+    arguments: list[str] = field(default_factory=list)
+
+
+@dataclass
+class _Interceptor:
+    """An interceptor registered on a server."""
+
+    label: str
+    streaming: bool
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    bodies: list[_Body]
+    by_name: _Guard | None = None  # Defined outside the repository, classified by name
+
+
+def _value(src: _Source, start: int, limit: int) -> str | None:
+    """String literal starting at start, ended by a comma or the end of the statement."""
+    end = _statement_end(src, start, limit)
+    comma = src.masked.find(",", start, end)
+    end = comma if comma >= 0 else end
+    while end > start and src.masked[end - 1].isspace():
+        end -= 1
+    return _string(src, (start, end))
+
+
+class _Application:
+    """Functions, descriptors, and method maps of an application's Go files."""
+
+    def __init__(self, files: dict[str, str]):
+        self.files = files
+        self.functions: dict[str, list[_Function]] = {}
+        self.constants: dict[str, str] = {}
+        self.maps: dict[str, _MethodMap] = {}
+        self.methods: list[GrpcMethod] = []
+        for file_path, text in files.items():
+            src = _source(text)
+            for match in GO_METHOD.finditer(src.masked):
+                open_paren = match.end() - 1
+                close = src.pairs.get(open_paren, len(text))
+                brace = _body_brace(src, close)
+                if brace < 0:
+                    continue
+                function = _Function(
+                    file_path=file_path,
+                    receiver=match.group(1),
+                    name=match.group(2),
+                    params=_params(src, open_paren + 1, close - 1),
+                    result=src.masked[close:brace],
+                    body=(brace, src.pairs.get(brace, len(text))),
+                )
+                self.functions.setdefault(function.name, []).append(function)
+            for match in FULL_METHOD_CONSTANT.finditer(src.masked):
+                value = _value(src, match.end(), len(text))
+                if value:
+                    self.constants[match.group(1)] = value
+        # Map keys may be constants, so maps are read once all are known
+        for file_path, text in files.items():
+            src = _source(text)
+            self.methods.extend(_descriptors(src))
+            for match in MAP_LITERAL.finditer(src.masked):
+                method_map = self._method_map(file_path, src, match)
+                if method_map.roles:
+                    self.maps[match.group(1)] = method_map
+
+    def method_name(self, src: _Source, span: tuple[int, int]) -> str | None:
+        """Full method name a map key or case label spells out or refers to."""
+        value = _string(src, span)
+        if value is None:
+            constant = CONSTANT_REFERENCE.search(src.masked[span[0] : span[1]])
+            value = self.constants.get(constant.group(1)) if constant else None
+        return value if value and value.startswith("/") else None
+
+    def _method_map(self, file_path: str, src: _Source, match: re.Match) -> _MethodMap:
+        """Entries of a map literal keyed by full method name."""
+        brace = match.end() - 1
+        listed = "string" in match.group(2)
+        method_map = _MethodMap(file_path=file_path, roles={}, lines={})
+        for s, e in src.split(brace + 1, src.pairs.get(brace, len(src.text)) - 1):
+            parts = src.split(s, e, ":")
+            name = self.method_name(src, parts[0]) if len(parts) > 1 else None
+            if name is None:
+                continue
+            roles = None
+            if listed:
+                value = src.masked.find("{", parts[1][0], e)
+                items = src.split(value + 1, src.pairs.get(value, e) - 1) if value >= 0 else [parts[1]]
+                roles = [r for r in (_string(src, item) for item in items) if r]
+            method_map.roles[name] = roles
+            method_map.lines[name] = line_of(src.text, s)
+        return method_map
+
+    def lookup(self, expression: str) -> _Function | None:
+        """Function an expression calls or refers to, preferring interceptors."""
+        callee = CALLEE.match(expression)
+        candidates = self.functions.get(callee.group(1), []) if callee else []
+        return next((f for f in candidates if f.interceptor), candidates[0] if candidates else None)
+
+
+def _descriptors(src: _Source) -> list[GrpcMethod]:
+    """RPCs of the grpc.ServiceDesc variables in a generated file."""
+    methods = []
+    for match in SERVICE_DESC.finditer(src.masked):
+        brace = match.end() - 1
+        end = src.pairs.get(brace, len(src.text))
+        service_name = None
+        for entry in DESC_FIELD.finditer(src.masked, brace, end):
+            value = _value(src, entry.end(), end)
+            if not value:
+                continue
+            if entry.group(1) == "ServiceName":
+                service_name = value
+            elif service_name:
+                methods.append(
+                    GrpcMethod(
+                        service=match.group(1),
+                        name=value,
+                        full_name=f"/{service_name}/{value}",
+                        streaming=entry.group(1) == "StreamName",
+                    )
+                )
+    return methods
+
+
+def _conjuncts(text: str) -> list[str]:
+    """Top-level && operands of a condition."""
+    src = _source(text)
+    parts, begin, i = [], 0, 0
+    while i < len(text):
+        if src.masked[i] in "([{":
+            i = max(src.pairs.get(i, len(text)), i + 1)
+            continue
+        if src.masked.startswith("&&", i):
+            parts.append(text[begin:i].strip())
+            begin = i + 2
+        i += 1
+    parts.append(text[begin:].strip())
+    return [p for p in parts if p]
+
+
+class _BodyParser:
+    """Reads the checks of one function body and the methods each applies to."""
+
+    def __init__(
+        self,
+        app: _Application,
+        function: _Function,
+        body: tuple[int, int] | None = None,
+        references: list[str] | None = None,
+        depth: int = 0,
+    ):
+        self.app, self.function, self.depth = app, function, depth
+        self.src = _source(app.files[function.file_path])
+        self.body = body or function.body
+        start, end = self.body
+        masked = self.src.masked[start:end]
+        # info.FullMethod, grpc.Method(ctx), and variables assigned from them or passed them
+        patterns = [r"\w+\s*\.\s*FullMethod\b", r"grpc\s*\.\s*Method\s*\(\s*\w+\s*\)"]
+        patterns += [rf"{re.escape(name)}\b" for name in references or []]
+        for match in re.finditer(
+            r"(?<![\w.])(\w+)\s*(?:,\s*\w+\s*)?:?=\s*(?:\w+\s*\.\s*FullMethod\b|grpc\s*\.\s*Method\s*\()", masked
+        ):
+            patterns.append(rf"{match.group(1)}\b")
+        self.reference = re.compile(r"(?<![\w.])(?:" + "|".join(patterns) + ")")
+        # roles, ok := methodRoles[info.FullMethod]
+        self.lookups: dict[str, tuple[str, _MethodMap]] = {}
+        for match in re.finditer(r"(?<![\w.])(\w+)(?:\s*,\s*(\w+))?\s*:?=\s*(\w+)\s*\[([^\[\]\n]+)\]", masked):
+            method_map = app.maps.get(match.group(3))
+            if method_map is not None and self._is_reference(match.group(4)):
+                self.lookups[match.group(1)] = ("value", method_map)
+                if match.group(2):
+                    self.lookups[match.group(2)] = ("ok", method_map)
+        handler = next((name for name, kind in function.params if HANDLER_TYPE.search(kind)), None)
+        if handler or references is None:
+            self.allowed = re.compile(rf"(?<![\w.]){re.escape(handler or 'handler')}\s*\(")
+        else:
+            self.allowed = ALLOWED
+
+    def _is_reference(self, expression: str) -> bool:
+        match = self.reference.match(expression.strip())
+        return bool(match) and match.end() == len(expression.strip())
+
+    def selector(self, part: str) -> _Selector | None:
+        """Methods a condition operand picks, or None if it is not about the method."""
+        if not self.reference.search(part):
+            for name, (kind, method_map) in self.lookups.items():
+                if not re.search(rf"(?<![\w.]){re.escape(name)}\b", part):
+                    continue
+                keys = set(method_map.roles)
+                if kind == "value" and method_map.listed:
+                    if re.search(rf"len\s*\(\s*{name}\s*\)\s*==\s*0|\b{name}\s*==\s*nil", part):
+                        return _Selector(names=keys, negated=True)
+                    # hasAnyRole(claims, roles)
+                    return _Selector(names=keys, roles=method_map)
+                return _Selector(names=keys, negated=bool(re.search(rf"!\s*{name}\b|\b{name}\s*==\s*false", part)))
+            return None
+
+        selector = _Selector(negated=part.startswith("!") or "!=" in part)
+        selector.names.update(METHOD_LITERAL.findall(part))
+        selector.names.update(self.app.constants[c] for c in CONSTANT_REFERENCE.findall(part) if c in self.app.constants)
+        for index in INDEX.finditer(part):
+            method_map = self.app.maps.get(index.group(1))
+            if method_map is None or not self._is_reference(index.group(2)):
+                continue
+            if method_map.listed:
+                # hasAnyRole(claims, methodRoles[info.FullMethod])
+                return _Selector(names=set(method_map.roles), roles=method_map)
+            selector.names.update(method_map.roles)
+        selector.prefixes.extend(prefix for target, prefix in HAS_PREFIX.findall(part) if self._is_reference(target))
+        return selector
+
+    def _condition(self, brace: int, statement_end: int) -> str:
+        """Condition of an if statement, without its init statement."""
+        return self.src.source(statement_end, brace).rsplit(";", 1)[-1].strip()
+
+    def _enclosing(self, position: int) -> tuple[list[str], list[_Selector]]:
+        """Conditions of the if statements around a position, and switch cases on the method."""
+        start, end = self.body
+        parts: list[str] = []
+        selectors: list[_Selector] = []
+        for statement in IF_STATEMENT.finditer(self.src.masked, start, position):
+            brace = self.src.masked.find("{", statement.end(), position)
+            if brace >= 0 and self.src.pairs.get(brace, end) > position:
+                parts.extend(_conjuncts(self._condition(brace, statement.end())))
+        for switch in SWITCH.finditer(self.src.masked, start, position):
+            brace = switch.end() - 1
+            close = self.src.pairs.get(brace, end)
+            if close <= position or not self.reference.search(self.src.source(*switch.span(1))):
+                continue
+            cases = list(CASE.finditer(self.src.masked, brace, close))
+            label = next((c for c in reversed(cases) if c.start() < position), None)
+            if label is None:
+                continue
+            # default: covers the methods no case names
+            labels = [c for c in cases if c.group(1) is not None] if label.group(1) is None else [label]
+            names = {
+                name
+                for c in labels
+                for span in self.src.split(*c.span(1))
+                if (name := self.app.method_name(self.src, span))
+            }
+            selectors.append(_Selector(names=names, negated=label.group(1) is None))
+        return parts, selectors
+
+    def _split(self, parts: list[str]) -> tuple[list[str], list[_Selector]]:
+        """Condition operands not about the method, and selectors from those that are."""
+        conditions, selectors = [], []
+        for part in parts:
+            selector = self.selector(part)
+            if selector is None:
+                conditions.append(part)
+            else:
+                selectors.append(selector)
+        return conditions, selectors
+
+    def parse(self) -> _Body:
+        """Denials and pass-throughs of the body and of the helpers it calls."""
+        start, end = self.body
+        result = _Body()
+        for denial in DENIAL.finditer(self.src.masked, start, end):
+            parts, selectors = self._enclosing(denial.start())
+            conditions, picked = self._split(parts)
+            result.checks.append(
+                _RpcCheck(
+                    forbidden=(denial.group(1) or denial.group(2)) == "PermissionDenied",
+                    condition=" && ".join(conditions) or None,
+                    selectors=selectors + picked,
+                )
+            )
+        for statement in IF_STATEMENT.finditer(self.src.masked, start, end):
+            brace = self.src.masked.find("{", statement.end(), end)
+            if brace < 0:
+                continue
+            block = self.src.masked[brace : self.src.pairs.get(brace, end)]
+            if not self.allowed.search(block) or DENIAL.search(block):
+                continue
+            conditions, selectors = self._split(_conjuncts(self._condition(brace, statement.end())))
+            if selectors and not conditions and all(s.roles is None for s in selectors):
+                result.passes.append(selectors)
+
+        if self.depth >= MAX_RESOLVE_DEPTH:
+            return result
+        # a.authorize(ctx, info.FullMethod): checks in helpers apply where the call is made
+        for call in CALL.finditer(self.src.masked, start, end):
+            helper = next(
+                (f for f in self.app.functions.get(call.group(1), []) if f is not self.function and not f.interceptor),
+                None,
+            )
+            if helper is None:
+                continue
+            arguments = _arguments(self.src, call.end() - 1)
+            references = [
+                helper.params[i][0]
+                for i, span in enumerate(arguments)
+                if i < len(helper.params) and self._is_reference(self.src.masked[span[0] : span[1]])
+            ]
+            nested = _BodyParser(self.app, helper, references=references, depth=self.depth + 1).parse()
+            if not nested.checks:
+                continue
+            parts, around = self._enclosing(call.start())
+            around += self._split(parts)[1]
+            # A method the helper lets through early skips its checks
+            skipped = [replace(p[0], negated=not p[0].negated) for p in nested.passes if len(p) == 1]
+            for check in nested.checks:
+                result.checks.append(_RpcCheck(check.forbidden, check.condition, around + skipped + check.selectors))
+        return result
+
+
+def _interceptor_bodies(app: _Application, file_path: str, span: tuple[int, int]) -> list[_Body]:
+    """Bodies an interceptor expression runs: its own, or the repository functions it wraps."""
+    src = _source(app.files[file_path])
+    expression = src.source(*span)
+    if INLINE_HANDLER.match(expression):
+        open_paren = src.masked.find("(", span[0])
+        close = src.pairs.get(open_paren, span[1])
+        brace = _body_brace(src, close)
+        if not 0 <= brace < span[1]:
+            return []
+        params = _params(src, open_paren + 1, close - 1)
+        literal = _Function(file_path, None, "", params, "", (brace, src.pairs.get(brace, span[1])))
+        return [_BodyParser(app, literal).parse()]
+
+    function = app.lookup(expression)
+    if function is not None and function.interceptor:
+        body = _BodyParser(app, function).parse()
+        if INTERCEPTOR_TYPE.match(function.result):
+            # RequireRole("admin") returning a grpc.UnaryServerInterceptor
+            body.parameters = [name for name, _ in function.params]
+            body.arguments = _call_arguments(expression)
+        return [body]
+
+    # grpc_auth.UnaryServerInterceptor(authenticate): the wrapped functions do the checking
+    bodies = []
+    open_paren = src.masked.find("(", span[0], span[1])
+    for s, e in _arguments(src, open_paren) if open_paren >= 0 else []:
+        wrapped = app.lookup(src.masked[s:e])
+        # Functions passed by name, not the results of calls
+        if wrapped is not None and src.masked[e - 1] != ")":
+            bodies.append(_BodyParser(app, wrapped, references=[]).parse())
+    return [b for b in bodies if b.checks]
+
+
+def _interceptors(app: _Application) -> list[_Interceptor]:
+    """Interceptors registered through grpc.NewServer() options."""
+    interceptors = []
+    for file_path, text in app.files.items():
+        if file_path.endswith(".pb.go"):
+            continue
+        src = _source(text)
+        options = list(INTERCEPTOR_OPTION.finditer(src.masked))
+        if not options:
+            continue
+        aliases: dict[str, tuple[int, int]] = {}
+        for match in GO_DECLARATION.finditer(src.masked):
+            if not src.masked.startswith("func", match.end()):
+                aliases[match.group(1)] = (match.end(), _statement_end(src, match.end(), len(text)))
+
+        def expand(span: tuple[int, int], depth: int = 0) -> list[tuple[int, int]]:
+            """Argument spans through local aliases and interceptor slices."""
+            expression = src.masked[span[0] : span[1]].removesuffix("...").strip()
+            if depth >= MAX_RESOLVE_DEPTH or expression not in aliases:
+                return [span]
+            s, e = aliases[expression]
+            if INTERCEPTOR_SLICE.match(src.masked[s:e]):
+                brace = src.masked.index("{", s)
+                return [x for item in src.split(brace + 1, src.pairs.get(brace, e) - 1) for x in expand(item, depth + 1)]
+            return expand((s, e), depth + 1)
+
+        def register(option: re.Match, depth: int = 0) -> None:
+            streaming = (option.group(1) or option.group(2)) == "Stream"
+            open_paren = option.end() - 1
+            line_start, line_end, snippet = src.snippet(option.start(), src.pairs.get(open_paren, len(text)))
+            for span in (x for argument in _arguments(src, open_paren) for x in expand(argument)):
+                nested = INTERCEPTOR_OPTION.match(src.masked, span[0])
+                if nested:
+                    if depth < MAX_RESOLVE_DEPTH:
+                        register(nested, depth + 1)
+                    continue
+                label = src.source(*span)
+                bodies = _interceptor_bodies(app, file_path, span)
+                by_name = None
+                if not bodies:
+                    # Defined outside the repository: fall back to the interceptor's name
+                    if ROLE_MIDDLEWARE.search(label):
+                        by_name = _Guard(roles=_call_arguments(label))
+                    elif AUTHENTICATION_MIDDLEWARE.search(label):
+                        by_name = _Guard()
+                    else:
+                        continue
+                interceptors.append(
+                    _Interceptor(label, streaming, file_path, line_start, line_end, snippet, bodies, by_name)
+                )
+
+        nested_in = [(o.end() - 1, src.pairs.get(o.end() - 1, len(text))) for o in options]
+        for option in options:
+            # Options inside a chain are registered through it
+            if not any(s < option.start() < e for s, e in nested_in):
+                register(option)
+    return interceptors
+
+
+def _body_guard(body: _Body, method: GrpcMethod) -> tuple[_Guard, _MethodMap | None] | None:
+    """What a body requires of calls to a method, and the role map naming it, or None if it does not check them."""
+    if any(all(s.matches(method.full_name) for s in selectors) for selectors in body.passes):
+        return None
+    checks = [c for c in body.checks if all(s.matches(method.full_name) for s in c.selectors)]
+    if not checks:
+        return None
+    guard, source = _Guard(), None
+    for check in checks:
+        if not check.forbidden:
+            continue
+        roles = []
+        for selector in check.selectors:
+            if selector.roles is not None and selector.roles.roles.get(method.full_name):
+                roles += selector.roles.roles[method.full_name]
+                source = selector.roles
+        if not roles and check.condition:
+            roles = _condition_roles(check.condition)
+            if not roles and any(re.search(rf"\b{re.escape(p)}\b", check.condition) for p in body.parameters if p):
+                # RequireRole(role string): the role comes from the registration
+                roles = body.arguments
+            if not roles:
+                condition = _permit_condition(check.condition)
+                if condition not in guard.conditions:
+                    guard.conditions.append(condition)
+        guard.roles.extend(r for r in roles if r not in guard.roles)
+    return guard, source
+
+
+def _implementations(app: _Application) -> dict[str, str | None]:
+    """Registered services by Go name, with the type implementing each where known."""
+    registered: dict[str, str | None] = {}
+    for file_path, text in app.files.items():
+        if file_path.endswith(".pb.go"):
+            continue
+        src = _source(text)
+        for match in REGISTRATION.finditer(src.masked):
+            args = _arguments(src, match.end() - 1)
+            implementation = IMPLEMENTATION.match(src.masked[args[1][0] : args[1][1]]) if len(args) > 1 else None
+            type_name = None
+            if implementation and implementation.group(2) == "{":
+                type_name = implementation.group(1)
+            elif implementation:
+                # pb.RegisterOrderServiceServer(s, newOrderServer(db))
+                constructor = next(iter(app.functions.get(implementation.group(1), [])), None)
+                result = re.match(r"^\s*\*?\s*(\w+)\s*$", constructor.result) if constructor else None
+                type_name = result.group(1) if result else None
+            registered.setdefault(match.group(1), type_name)
+    return registered
+
+
+def resolve_grpc_routes(app: _Application) -> list[ConfigFinding]:
+    """Attribute interceptor and handler checks to the RPCs of the registered services.
+
+    Without registrations in the repository, every RPC it declares is
+    considered served.
+
+    Args:
+        app: Parsed Go files of an application
+
+    Returns:
+        One finding per guarded RPC
+    """
+    interceptors = _interceptors(app)
+    registered = _implementations(app)
+    methods = [m for m in app.methods if m.service in registered] if registered else app.methods
+
+    findings = []
+    for method in methods:
+        labels, roles, conditions, evidence = [], [], [], None
+
+        def add(label: str, guard: _Guard) -> None:
+            if label not in labels:
+                labels.append(label)
+            roles.extend(r for r in guard.roles if r not in roles)
+            conditions.extend(c for c in guard.conditions if c not in conditions)
+
+        for interceptor in interceptors:
+            if interceptor.streaming != method.streaming:
+                continue
+            results = [r for r in (_body_guard(b, method) for b in interceptor.bodies) if r is not None]
+            if interceptor.by_name is not None:
+                results.append((interceptor.by_name, None))
+            for guard, method_map in results:
+                add(interceptor.label, guard)
+                if method_map is not None:
+                    # The method's own entry in the role map
+                    line = method_map.lines[method.full_name]
+                    text = app.files[method_map.file_path]
+                    evidence = (method_map.file_path, line, line, line_span(text, line, line))
+            if results and evidence is None:
+                evidence = (interceptor.file_path, interceptor.line_start, interceptor.line_end, interceptor.snippet)
+
+        implementation = registered.get(method.service)
+        for function in app.functions.get(method.name, []):
+            if function.file_path.endswith(".pb.go") or function.receiver is None:
+                continue
+            if implementation and function.receiver != implementation:
+                continue
+            result = _body_guard(_BodyParser(app, function).parse(), method)
+            if result is None:
+                continue
+            add("handler", result[0])
+            src = _source(app.files[function.file_path])
+            line_start, line_end, snippet = src.snippet(src.text.rfind("\n", 0, function.body[0]) + 1, function.body[1])
+            evidence = (function.file_path, line_start, line_end, snippet)
+            break
+
+        if not labels or evidence is None:
+            continue
+        file_path, line_start, line_end, snippet = evidence
+        findings.append(
+            ConfigFinding(
+                kind=GrpcRouteKind.RPC,
+                file_path=file_path,
+                line_start=line_start,
+                line_end=line_end,
+                snippet=snippet,
+                subject=" or ".join(roles) if roles else "Authenticated users",
+                resource=method.full_name,
+                action="POST",
+                conditions="; ".join(conditions) or None,
+                description=f"gRPC {'streaming' if method.streaming else 'unary'} RPC guarded by {', '.join(labels)}",
+            )
+        )
+    return findings
+
+
+def extract_grpc_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract per-RPC authorization from an application's Go gRPC services.
+
+    Args:
+        files: Relative path -> content; non-Go files are ignored
+
+    Returns:
+        One finding per guarded RPC
+    """
+    go_files = {path: text for path, text in files.items() if is_go_source(path)}
+    if not any(GRPC_IMPORT.search(text) for text in go_files.values()):
+        return []
+    return resolve_grpc_routes(_Application(go_files))
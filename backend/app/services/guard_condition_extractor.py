@@ -0,0 +1,409 @@
+"""Lift attribute checks guarding access denials in handler bodies into ABAC conditions.
+
+Route guards and annotations only say who may call an endpoint. Limits that
+depend on the request or the object are usually written inside the handler
+as an early return, e.g.
+
+    if expense.Amount > 5000 && !user.HasRole("DIRECTOR") {
+        http.Error(w, "Director role required", http.StatusForbidden)
+        return
+    }
+
+This extractor finds if-statements whose body denies the request (401/403,
+PermissionDenied, Forbidden...), follows the handler's local assignments and
+the file's constants back to the values they compare, and rewrites the guard
+as the condition under which the request passes, in the clause grammar of
+ConditionEvaluationService: "expense.amount > 5000 requires DIRECTOR",
+"user.department == Finance", "not approved", or, for a comparison of the
+object's owner with the caller such as if expense.OwnerID != user.ID, the
+owner-of-resource clause "user is owner of expense.owner_id". Guards on
+roles alone are left to the route extractors, and guards with no clause form
+(several comparisons that must all hold) are skipped rather than guessed.
+Limits read from configuration, as in if expense.Amount > cfg.MaxSelfApproveAmount,
+keep the setting as a parameter, "expense.amount <= ${cfg.MaxSelfApproveAmount}",
+resolved to its value from a scan's config snapshot (see role_parameter_service).
+"""
+
+import re
+from dataclasses import dataclass
+
+from app.services.config_policy_extractor import role_parameter
+from app.services.extractor_utils import MAX_LINE_LENGTH, group
+
+# Guard bodies longer than this are branches of the handler's logic, not early returns
+MAX_GUARD_BODY_LINES = 6
+# Bindings followed from a compared name back to its value before giving up
+MAX_BINDING_DEPTH = 4
+MAX_SNIPPET_LENGTH = 300
+
+IF_START = re.compile(r"^\s*(?:\}\s*)?(?:else\s+)?(if|elif|unless)\b\s*")
+# Responses and exceptions that end a request as unauthenticated or forbidden
+DENIAL = re.compile(
+    r"\b(?:40[13]|Status(?:Forbidden|Unauthorized)|HTTP_40[13]\w*|FORBIDDEN|UNAUTHORIZED|(?:HttpResponse)?Forbidden\w*|Unauthorized\w*|"
+    r"forbidden|unauthorized|PermissionDenied\w*|AccessDenied\w*|NotAuthorized\w*|AuthorizationError|Forbid)\b"
+)
+
+# Local assignments, and literal constants anywhere in the file
+ASSIGNMENT = re.compile(
+    r"^\s*(?:(?:const|let|var|val|final|auto|readonly)\s+)?([A-Za-z_$][\w$]*)\s*(?::\s*[\w.<>\[\]]+\s*)?(?::=|=)(?![=~>])\s*(.+)$"
+)
+CONSTANT = re.compile(
+    r"^\s*(?:(?:export|public|private|protected|internal|static|final|const|readonly|let|var|val)\s+)+(?:[\w<>\[\]]+\s+)?"
+    r"([A-Za-z_]\w*)\s*(?::\s*\w+\s*)?:?=\s*(-?\d[\d_]*(?:\.\d+)?|\"[^\"\n]*\"|'[^'\n]*')\s*;?\s*$"
+    r"|^([A-Z_][A-Z0-9_]*)\s*(?::\s*\w+\s*)?:?=\s*(-?\d[\d_]*(?:\.\d+)?|\"[^\"\n]*\"|'[^'\n]*')\s*;?\s*$",
+    re.MULTILINE,
+)
+
+PATH = re.compile(r"^[A-Za-z_$][\w$]*(?:\s*(?:\.|\?\.|->)\s*[A-Za-z_$][\w$]*)*(?:\(\s*\))?$")
+IDENTIFIER = re.compile(r"^[A-Za-z_$][\w$]*$")
+NUMBER = re.compile(r"^-?\d[\d_]*(?:\.\d+)?$")
+STRING = re.compile(r"""^(['"`])([^'"`\n]*)\1$""")
+NOTHING = {"nil", "null", "none", "undefined"}
+COMPARISON = re.compile(r"^(.+?)\s*(===|!==|==|!=|>=|<=|(?<![-=])>|<(?![-=]))\s*(.+)$")
+NEGATED = {">": "<=", ">=": "<", "<": ">=", "<=": ">", "==": "!=", "!=": "=="}
+FLIPPED = {">": "<", ">=": "<=", "<": ">", "<=": ">=", "==": "==", "!=": "!="}
+
+# Role checks: user.HasRole("DIRECTOR"), "admin" in user.roles, user.roles.includes('admin'), user.Role == "ADMIN"
+ROLE_CALL = re.compile(
+    r"""^(?:[\w$.]+\s*\.\s*)?(?:[Hh]as_?(?:Any)?_?[Rr]ole|[Ii]s_?[Ii]n_?[Rr]ole|[Hh]as_?[Aa]uthority|has_group|[Ii]n_?[Gg]roup)\s*\("""
+    r"""\s*(?:[\w$.]+\s*,\s*)?["'`]([\w:.\-]+)["'`]\s*\)$"""
+)
+ROLE_MEMBERSHIP = re.compile(
+    r"""^["'`]([\w:.\-]+)["'`]\s+in\s+[\w$.]*roles?$|^[\w$.]*\.\s*[Rr]oles?\s*\.\s*(?:includes|contains|Contains|include\?)\s*\(\s*["'`]([\w:.\-]+)["'`]\s*\)$"""
+    r"""|^(?:slices\s*\.\s*)?Contains\s*\(\s*[\w$.]*[Rr]oles\s*,\s*["'`]([\w:.\-]+)["'`]\s*\)$"""
+)
+ROLE_ATTRIBUTE = re.compile(r"(?:^|\.)(?:role|roles|role_name|roleName|RoleName|Role)$")
+ADMIN_FLAG = re.compile(r"(?:^|\.)\s*(?:is_?[Aa]dmin|Is[Aa]dmin|isAdmin)(?:\(\s*\))?$")
+
+# Sides of a comparison naming the caller and an object's owner
+SUBJECT_PATH = re.compile(
+    r"^(?:current_?user|currentUser|user|principal|req\.user|request\.user|ctx\.state\.user|g\.user|claims|session\.user|caller)\s*\.\s*"
+    r"(?:id|ID|Id|_id|uid|pk|sub|user_?id|userID|UserID|get_?id|GetID)(?:\(\s*\))?$"
+    r"|^(?:current_?user|currentUser|req\.user|request\.user|g\.user)$"
+    r"|^User\s*\.\s*FindFirstValue\s*\(\s*ClaimTypes\s*\.\s*NameIdentifier\s*\)$|^[\w.]*GetUserId\s*\(\s*User\s*\)$",
+    re.IGNORECASE,
+)
+OWNER_PATH = re.compile(
+    r"(?:owner\w*|created_?by\w*|author\w*|creator\w*|user_?id|userID|UserID|(?:^|\.)user|get(?:Owner|Author|Creator|User)\w*)(?:\(\s*\))?$",
+    re.IGNORECASE,
+)
+# Conversions wrapped around either side of an ownership comparison, e.g. String(doc.owner), req.user._id.toString()
+CONVERSION = re.compile(r"^(?:String|str|int|Number|UUID|ObjectId)\s*\((.+)\)$|^(.+?)\s*\.\s*(?:toString|toHexString|to_s|String)(?:\(\s*\))?$")
+# Equality calls: a.equals(b), a.Equals(b), Objects.equals(a, b)
+EQUALS = re.compile(r"\.\s*[Ee]quals\s*\(")
+
+BARE_VALUE = re.compile(r"^[\w\-]+$")
+
+
+@dataclass
+class LiftedCondition:
+    """The condition under which a request passes a denial guard in a handler."""
+
+    line: int
+    guard: str  # the guard's source line
+    condition: str  # clause text ConditionEvaluationService parses
+
+
+@dataclass
+class _Atom:
+    """One conjunct of a guard, with the polarity in which it triggers the denial."""
+
+    kind: str  # role, comparison, flag, ownership, or authentication
+    attribute: str | None = None
+    operator: str | None = None
+    value: str | None = None
+    role: str | None = None
+    negated: bool = False
+
+
+def _short(code: str) -> str:
+    """A source line trimmed for use as a snippet."""
+    code = code.strip()
+    return code if len(code) <= MAX_SNIPPET_LENGTH else code[:MAX_SNIPPET_LENGTH] + "..."
+
+
+def _unwrap(expression: str) -> str:
+    """Expression without the parentheses enclosing all of it."""
+    expression = expression.strip()
+    while expression.startswith("(") and group(expression, 0) == len(expression):
+        expression = expression[1:-1].strip()
+    return expression
+
+
+def _split(expression: str, separator: re.Pattern) -> list[str]:
+    """Split an expression at top-level separators, outside brackets and strings."""
+    parts, depth, quote, last = [], 0, "", 0
+    i = 0
+    while i < len(expression):
+        c = expression[i]
+        if quote:
+            if c == quote:
+                quote = ""
+        elif c in "'\"`":
+            quote = c
+        elif c in "([{":
+            depth += 1
+        elif c in ")]}":
+            depth -= 1
+        elif depth == 0:
+            match = separator.match(expression, i)
+            if match:
+                parts.append(expression[last:i])
+                last = i = match.end()
+                continue
+        i += 1
+    parts.append(expression[last:])
+    return [p.strip() for p in parts if p.strip()]
+
+
+OR_SEPARATOR = re.compile(r"\|\||\s+or\s+")
+AND_SEPARATOR = re.compile(r"&&|\s+and\s+")
+
+
+def _attribute(path: str) -> str:
+    """Attribute name of a member path, e.g. expense.DepartmentID -> expense.department_id, doc.getOwnerId() -> doc.owner_id."""
+    names = []
+    for segment in re.split(r"\s*(?:\.|\?\.|->)\s*", path.strip()):
+        call = re.search(r"\(\s*\)$", segment)
+        if call:
+            segment = re.sub(r"^[Gg]et(?=[A-Z])", "", segment[: call.start()])
+        names.append(re.sub(r"(?<=[a-z0-9])([A-Z])", r"_\1", segment).lower())
+    return ".".join(names)
+
+
+def _literal(text: str) -> str | None:
+    """Clause spelling of a literal, or None if text is not one."""
+    text = text.strip()
+    if NUMBER.match(text):
+        return text.replace("_", "")
+    string = STRING.match(text)
+    if string:
+        value = string.group(2)
+        return value if BARE_VALUE.match(value) else f'"{value}"'
+    if text.lower() in ("true", "false"):
+        return text.lower()
+    return None
+
+
+def _resolve(text: str, bindings: dict[str, str], depth: int = 0) -> str:
+    """Follow a name through the bindings to the expression it was last assigned."""
+    text = _unwrap(text)
+    while IDENTIFIER.match(text) and text in bindings and depth < MAX_BINDING_DEPTH:
+        text, depth = _unwrap(bindings[text]), depth + 1
+    return text
+
+
+def _atom(text: str, bindings: dict[str, str], depth: int = 0) -> _Atom | None:
+    """Classify one conjunct, or None if it has no clause form."""
+    text = _unwrap(text)
+    negated = False
+    while True:
+        if text.startswith("!") and not text.startswith("!="):
+            negated, text = not negated, _unwrap(text[1:])
+        elif text.startswith("not "):
+            negated, text = not negated, _unwrap(text[4:])
+        else:
+            break
+    if IDENTIFIER.match(text) and text in bindings and depth < MAX_BINDING_DEPTH:
+        atom = _atom(bindings[text], bindings, depth + 1)
+        if atom is not None and negated:
+            atom = _negate(atom)
+        return atom
+
+    role = ROLE_CALL.match(text) or ROLE_MEMBERSHIP.match(text)
+    if role:
+        return _Atom("role", role=next(g for g in role.groups() if g).upper(), negated=negated)
+    if ADMIN_FLAG.search(text) and PATH.match(text):
+        return _Atom("role", role="ADMIN", negated=negated)
+
+    comparison = COMPARISON.match(text)
+    equality = _equality(text) if EQUALS.search(text) else None
+    if equality or comparison:
+        left, operator, right = equality or comparison.groups()
+        operator = operator[:2] if operator in ("===", "!==") else operator
+        left, right = _resolve(left, bindings).lstrip("@"), _resolve(right, bindings).lstrip("@")
+        if operator in ("==", "!="):
+            owner = _owner(left, right) or _owner(right, left)
+            if owner:
+                atom = _Atom("ownership", attribute=_attribute(owner), operator=operator)
+                return _negate(atom) if negated else atom
+        if (_literal(left) is not None or role_parameter(left)) and PATH.match(right) and not role_parameter(right):
+            left, right, operator = right, left, FLIPPED[operator]
+        if not PATH.match(left):
+            return None
+        if right.lower() in NOTHING:
+            return _Atom("authentication")
+        value = _literal(right) or role_parameter(right)
+        if value is None:
+            if not PATH.match(right) or operator not in ("==", "!="):
+                return None
+            value = _attribute(right)
+        if ROLE_ATTRIBUTE.search(left) and BARE_VALUE.match(value) and operator in ("==", "!="):
+            return _Atom("role", role=value.upper(), negated=(operator == "!=") != negated)
+        atom = _Atom("comparison", attribute=_attribute(left), operator=operator, value=value)
+        return _negate(atom) if negated else atom
+
+    if PATH.match(text) and text.lower() not in NOTHING and _literal(text) is None:
+        return _Atom("flag", attribute=_attribute(text), negated=negated)
+    return None
+
+
+def _equality(text: str) -> tuple[str, str, str] | None:
+    """(left, "==", right) of an equality call spanning the whole text, or None."""
+    call = EQUALS.search(text)
+    if not call or group(text, call.end() - 1) != len(text):
+        return None
+    receiver, argument = text[: call.start()].strip(), text[call.end() : -1].strip()
+    if receiver in ("Objects", "Object", "object"):
+        parts = _split(argument, re.compile(r","))
+        return (parts[0], "==", parts[1]) if len(parts) == 2 else None
+    return (receiver, "==", argument) if receiver and argument else None
+
+
+def _unconverted(text: str) -> str:
+    """A compared side without the conversions wrapped around it."""
+    conversion = CONVERSION.match(text)
+    return _unwrap(conversion.group(1) or conversion.group(2)) if conversion else text
+
+
+def _owner(subject: str, owner: str) -> str | None:
+    """The owner side of a comparison of the caller with an object's owner, or None."""
+    subject, owner = _unconverted(subject), _unconverted(owner)
+    if SUBJECT_PATH.match(subject) and PATH.match(owner) and OWNER_PATH.search(owner) and not SUBJECT_PATH.match(owner):
+        return owner
+    return None
+
+
+def _negate(atom: _Atom) -> _Atom:
+    """The atom holding exactly when the given one does not."""
+    if atom.kind in ("comparison", "ownership"):
+        return _Atom(atom.kind, atom.attribute, NEGATED[atom.operator], atom.value)
+    return _Atom(atom.kind, atom.attribute, atom.operator, atom.value, atom.role, not atom.negated)
+
+
+def _comparison_text(atom: _Atom) -> str:
+    """Clause text of a comparison or flag atom in the polarity it holds."""
+    if atom.kind == "flag":
+        return f"{atom.attribute} == {'false' if atom.negated else 'true'}"
+    return f"{atom.attribute} {atom.operator} {atom.value}"
+
+
+def lift_condition(expression: str, bindings: dict[str, str] | None = None) -> list[str]:
+    """Conditions a request must meet to pass a guard that denies when expression holds.
+
+    Each top-level disjunct of the guard denies on its own, so each must fail
+    and contributes one clause. Disjuncts on roles or authentication alone
+    contribute none, and disjuncts without a clause form make the whole
+    guard unliftable.
+
+    Args:
+        expression: Guard condition, e.g. expense.Amount > 5000 && !user.HasRole("DIRECTOR")
+        bindings: Names assigned before the guard -> the expressions assigned to them
+
+    Returns:
+        Clauses joined by AND, or an empty list if the guard cannot be expressed
+    """
+    bindings = bindings or {}
+    clauses = []
+    for disjunct in _split(_unwrap(expression), OR_SEPARATOR):
+        atoms = [_atom(part, bindings) for part in _split(_unwrap(disjunct), AND_SEPARATOR)]
+        if any(a is None for a in atoms):
+            return []
+        if any(a.kind == "authentication" for a in atoms):
+            continue
+        roles = [a for a in atoms if a.kind == "role"]
+        attributes = [a for a in atoms if a.kind != "role"]
+        if not attributes:
+            continue
+        if len(attributes) > 1 or len(roles) > 1 or any(not r.negated for r in roles):
+            return []
+        [attribute] = attributes
+        if attribute.kind == "ownership":
+            if attribute.operator != "!=":
+                return []
+            # Owner-of-resource: the caller must own the object, unless they hold the bypassing role
+            clauses.append(f"user is owner of {attribute.attribute}" + (f" unless {roles[0].role}" if roles else ""))
+        elif roles:
+            clauses.append(f"{_comparison_text(attribute)} requires {roles[0].role}")
+        elif attribute.kind == "flag" and not attribute.negated:
+            clauses.append(f"not {attribute.attribute.split('.')[-1]}")
+        else:
+            clauses.append(_comparison_text(_negate(attribute)))
+    return list(dict.fromkeys(clauses))
+
+
+def _guard(lines: list[str], index: int) -> tuple[str, bool, str] | None:
+    """(condition, negated, body) of an if-statement starting on a line, or None."""
+    line = lines[index]
+    match = IF_START.match(line)
+    if not match:
+        return None
+    keyword, rest = match.group(1), line[match.end() :]
+    if rest.startswith("("):
+        end = group(rest, 0)
+        condition, tail = rest[1 : end - 1], rest[end:]
+    else:
+        # Go: if [init;] cond {   Python: if cond:   Ruby: if cond
+        body = re.search(r"\s*(?:\{|:|\bthen\b)\s*$", rest)
+        condition, tail = (rest[: body.start()], rest[body.start() :]) if body else (rest, "")
+        if ";" in condition:
+            condition = condition.rsplit(";", 1)[1]
+    tail = tail.strip().lstrip("{:").strip()
+    if tail:
+        return condition, keyword == "unless", tail
+    indent = len(line) - len(line.lstrip())
+    block = []
+    for following in lines[index + 1 : index + 1 + MAX_GUARD_BODY_LINES + 1]:
+        stripped = following.strip()
+        if not stripped:
+            continue
+        if len(following) - len(following.lstrip()) <= indent:
+            break
+        block.append(stripped)
+    if len(block) > MAX_GUARD_BODY_LINES:
+        return None
+    return condition, keyword == "unless", "\n".join(block)
+
+
+def file_bindings(text: str) -> dict[str, str]:
+    """Literal constants a file declares, e.g. const approvalLimit = 5000."""
+    bindings = {}
+    for match in CONSTANT.finditer(text):
+        name, value = (match.group(1), match.group(2)) if match.group(1) else (match.group(3), match.group(4))
+        bindings[name] = value
+    return bindings
+
+
+def lift_guards(
+    lines: list[str], start: int, end: int, constants: dict[str, str] | None = None, denial: re.Pattern = DENIAL
+) -> list[LiftedCondition]:
+    """Lift the denial guards of one handler into conditions.
+
+    Args:
+        lines: Lines of the source file
+        start: Index of the handler's first line
+        end: Index just past its last line
+        constants: File-level literal bindings (see file_bindings)
+        denial: What a guard body must contain to deny the request
+
+    Returns:
+        One entry per clause, in source order
+    """
+    bindings = dict(constants or {})
+    lifted: list[LiftedCondition] = []
+    for index in range(start, min(end, len(lines))):
+        line = lines[index]
+        if len(line) > MAX_LINE_LENGTH:
+            continue
+        guard = _guard(lines, index)
+        if guard is not None:
+            condition, negated, body = guard
+            if denial.search(body):
+                expression = condition if not negated else f"!({condition})"
+                for clause in lift_condition(expression, bindings):
+                    lifted.append(LiftedCondition(index + 1, _short(line), clause))
+            continue
+        assignment = ASSIGNMENT.match(line)
+        if assignment and index > start:
+            bindings[assignment.group(1)] = assignment.group(2).strip().rstrip(";")
+    return lifted
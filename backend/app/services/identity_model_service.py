@@ -0,0 +1,328 @@
+"""Service for declaring a workspace's identity model and mapping mined conditions onto it.
+
+Mined conditions name subject attributes however the code spelled them
+(user.dept, claims.DepartmentName, req.user.isContractor), while the PDP a
+policy is exported to only knows the attributes its identity provider
+supplies. A workspace declares its identity model here: the subject types it
+has (user, service account, device) and the attributes each one carries,
+with the aliases code uses for them. Condition clauses are then mapped onto
+declared attributes and rewritten with their declared names, and clauses
+naming a subject attribute the model does not declare are flagged, since an
+exported rule reading it would never match.
+"""
+
+import re
+from typing import Any
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.identity_model import IdentityModel
+from app.models.policy import Policy
+from app.services.condition_evaluation_service import (
+    SUBJECT_PREFIXES,
+    ConditionClause,
+    ConditionEvaluationService,
+)
+
+logger = structlog.get_logger(__name__)
+
+ATTRIBUTE_TYPES = ("string", "number", "boolean", "list", "datetime")
+
+NAME = re.compile(r"^[a-z][a-z0-9_]*$")
+
+# Clause text that starts by naming the caller, e.g. "User department is Finance"
+SUBJECT_WORDS = re.compile(r"^(?:the\s+)?(?:user|caller|subject|principal)\s", re.IGNORECASE)
+
+BARE_VALUE = re.compile(r"^[\w\-]+$")
+
+# Used until a workspace declares its own model
+DEFAULT_IDENTITY_MODEL = {
+    "subject_types": [
+        {
+            "name": "user",
+            "identifier": "id",
+            "attributes": [
+                {"name": "id", "type": "string", "aliases": ["user_id", "sub"]},
+                {"name": "email", "type": "string", "aliases": []},
+                {"name": "roles", "type": "list", "aliases": ["role", "groups"]},
+                {"name": "department", "type": "string", "aliases": ["dept"]},
+                {"name": "tenant_id", "type": "string", "aliases": ["tenant", "org_id"]},
+            ],
+        },
+    ],
+}
+
+
+class MappingStatus:
+    """How a clause's attribute relates to the identity model."""
+
+    MAPPED = "mapped"  # Names a declared attribute
+    UNKNOWN = "unknown"  # Names a subject attribute the model does not declare
+    UNSCOPED = "unscoped"  # Not a subject attribute, e.g. a resource or request field
+
+
+def _key(name: str) -> str:
+    """Compare attribute spellings ignoring case, underscores, and spaces."""
+    return re.sub(r"[_\s]", "", name.lower())
+
+
+def validate_identity_model(subject_types: list[dict]) -> list[dict]:
+    """Check and normalize declared subject types.
+
+    Args:
+        subject_types: Subject types, each with a name, attributes, and an optional identifier
+
+    Returns:
+        Subject types with lower-cased names and defaulted fields
+
+    Raises:
+        ValueError: If a name is invalid or repeated, a type is unknown, or the identifier is undeclared
+    """
+    if not isinstance(subject_types, list) or not subject_types:
+        raise ValueError("An identity model needs at least one subject type")
+
+    normalized = []
+    for subject in subject_types:
+        name = str(subject.get("name") or "").strip().lower()
+        if not NAME.match(name):
+            raise ValueError(f"Invalid subject type name '{subject.get('name')}'")
+        if any(s["name"] == name for s in normalized):
+            raise ValueError(f"Subject type '{name}' is declared twice")
+
+        attributes = []
+        for attribute in subject.get("attributes") or []:
+            attribute_name = str(attribute.get("name") or "").strip().lower()
+            if not NAME.match(attribute_name):
+                raise ValueError(f"Invalid attribute name '{attribute.get('name')}' on subject type '{name}'")
+            if any(a["name"] == attribute_name for a in attributes):
+                raise ValueError(f"Attribute '{attribute_name}' is declared twice on subject type '{name}'")
+            attribute_type = attribute.get("type") or "string"
+            if attribute_type not in ATTRIBUTE_TYPES:
+                raise ValueError(f"Unknown type '{attribute_type}' for attribute '{name}.{attribute_name}'")
+            aliases = attribute.get("aliases") or []
+            if not all(isinstance(a, str) and a.strip() for a in aliases):
+                raise ValueError(f"Aliases of '{name}.{attribute_name}' must be non-empty strings")
+            attributes.append({"name": attribute_name, "type": attribute_type, "aliases": [a.strip() for a in aliases]})
+
+        identifier = subject.get("identifier")
+        if identifier is not None and identifier not in [a["name"] for a in attributes]:
+            raise ValueError(f"Identifier '{identifier}' is not an attribute of subject type '{name}'")
+        normalized.append({"name": name, "identifier": identifier, "attributes": attributes})
+    return normalized
+
+
+class IdentityMapper:
+    """Maps condition clauses onto a declared identity model."""
+
+    def __init__(self, subject_types: list[dict]):
+        """Initialize mapper."""
+        self.subject_types = subject_types
+        self.by_name = {s["name"]: s for s in subject_types}
+
+    def resolve(self, attribute: str) -> tuple[str, str] | None:
+        """Find the declared attribute a mined attribute names.
+
+        A leading subject type ("device.os_version") restricts the search to
+        that type; otherwise types are tried in declaration order, matching
+        the full path or its last segment against names and aliases.
+
+        Returns:
+            (subject type, attribute name), or None if nothing declares it
+        """
+        segments = attribute.lower().split(".")
+        candidates = self.subject_types
+        if len(segments) > 1 and segments[0] in self.by_name:
+            candidates = [self.by_name[segments[0]]]
+            segments = segments[1:]
+        keys = {_key(".".join(segments)), _key(segments[-1])}
+
+        for subject in candidates:
+            for declared in subject["attributes"]:
+                if any(_key(name) in keys for name in [declared["name"], *declared["aliases"]]):
+                    return subject["name"], declared["name"]
+        return None
+
+    def _subject_side(self, clause: ConditionClause) -> bool:
+        """Whether a clause reads an attribute of the caller."""
+        raw = clause.raw.strip().lower()
+        if raw.startswith(SUBJECT_PREFIXES) or SUBJECT_WORDS.match(raw):
+            return True
+        return (clause.attribute or "").split(".")[0] in self.by_name
+
+    def map_clause(self, clause: ConditionClause) -> dict:
+        """Map one clause, with its text rewritten onto the declared attribute.
+
+        Returns:
+            Mapping with status, declared attribute, and rewritten text (the
+            original text when nothing was mapped)
+        """
+        if clause.kind == "implication":
+            inner = self.map_clause(clause.inner)
+            return {**inner, "raw": clause.raw, "text": f"{inner['text']} requires {clause.required_role}"}
+
+        mapping = {"raw": clause.raw, "kind": clause.kind, "attribute": clause.attribute, "status": MappingStatus.UNSCOPED, "subject_type": None, "declared_attribute": None, "text": clause.raw}
+        if clause.kind in ("ownership", "owner_of_resource"):
+            # The resource side is a resource field; the caller side is the subject's identifier
+            subject = self.subject_types[0]
+            if subject["identifier"]:
+                mapping.update(status=MappingStatus.MAPPED, subject_type=subject["name"], declared_attribute=subject["identifier"])
+            else:
+                mapping.update(status=MappingStatus.UNKNOWN, subject_type=subject["name"], attribute="identifier")
+            return mapping
+        if clause.kind == "same_tenant":
+            # The caller side is the subject's tenant attribute
+            resolved = self.resolve(f"{self.subject_types[0]['name']}.tenant_id")
+            if resolved:
+                mapping.update(status=MappingStatus.MAPPED, subject_type=resolved[0], declared_attribute=resolved[1])
+            else:
+                mapping.update(status=MappingStatus.UNKNOWN, subject_type=self.subject_types[0]["name"], attribute="tenant_id")
+            return mapping
+        if clause.kind not in ("comparison", "flag"):
+            return mapping
+
+        resolved = self.resolve(clause.attribute)
+        if resolved is None:
+            if self._subject_side(clause):
+                mapping["status"] = MappingStatus.UNKNOWN
+            return mapping
+
+        subject_type, declared = resolved
+        if clause.kind == "flag":
+            operator, value = "==", "false"
+        else:
+            operator, value = clause.operator, clause.value
+            if isinstance(value, str) and not BARE_VALUE.match(value):
+                value = f'"{value}"'
+        mapping.update(status=MappingStatus.MAPPED, subject_type=subject_type, declared_attribute=declared, text=f"{subject_type}.{declared} {operator} {value}")
+        return mapping
+
+
+class IdentityModelService:
+    """Stores a workspace's identity model and maps mined conditions onto it."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _stored(self) -> IdentityModel | None:
+        """The workspace's declared model, if any."""
+        return self.db.query(IdentityModel).filter(IdentityModel.tenant_id == self.tenant_id).first()
+
+    def get_model(self) -> dict:
+        """Get the workspace's identity model.
+
+        Returns:
+            Subject types, with declared=False when the default model is in use
+        """
+        stored = self._stored()
+        if not stored:
+            return {"declared": False, "subject_types": DEFAULT_IDENTITY_MODEL["subject_types"], "updated_by": None, "updated_at": None}
+        return {"declared": True, "subject_types": stored.subject_types, "updated_by": stored.updated_by, "updated_at": stored.updated_at}
+
+    def set_model(self, subject_types: list[dict], updated_by: str | None = None) -> dict:
+        """Declare or replace the workspace's identity model.
+
+        Args:
+            subject_types: Subject types with their attributes
+            updated_by: Editor's email
+
+        Returns:
+            The stored model
+
+        Raises:
+            ValueError: If the model is invalid
+        """
+        normalized = validate_identity_model(subject_types)
+        stored = self._stored()
+        if stored:
+            stored.subject_types = normalized
+            stored.updated_by = updated_by
+        else:
+            stored = IdentityModel(tenant_id=self.tenant_id, subject_types=normalized, updated_by=updated_by)
+            self.db.add(stored)
+        self.db.commit()
+        self.db.refresh(stored)
+
+        logger.info("identity_model_declared", tenant_id=self.tenant_id, subject_types=[s["name"] for s in normalized])
+        return self.get_model()
+
+    def _policies(self, repository_id: int | None) -> list[Policy]:
+        """Policies with conditions, scoped to the tenant."""
+        query = self.db.query(Policy).filter(Policy.conditions.isnot(None))
+        if self.tenant_id:
+            query = query.filter(Policy.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Policy.repository_id == repository_id)
+        return query.all()
+
+    def map_conditions(self, repository_id: int | None = None) -> dict[str, Any]:
+        """Map every mined condition onto the identity model.
+
+        Args:
+            repository_id: Restrict to one repository
+
+        Returns:
+            Per-policy clause mappings with rewritten conditions, the
+            undeclared subject attributes they use, and a summary
+        """
+        model = self.get_model()
+        mapper = IdentityMapper(model["subject_types"])
+        policies, unknown = [], {}
+        counts = {MappingStatus.MAPPED: 0, MappingStatus.UNKNOWN: 0, MappingStatus.UNSCOPED: 0}
+
+        for policy in self._policies(repository_id):
+            clauses = [mapper.map_clause(c) for c in ConditionEvaluationService.parse(policy.conditions) if c.kind != "unknown"]
+            if not clauses:
+                continue
+            for clause in clauses:
+                counts[clause["status"]] += 1
+                if clause["status"] == MappingStatus.UNKNOWN:
+                    unknown.setdefault(clause["attribute"], []).append(policy.id)
+            policies.append({
+                "policy_id": policy.id,
+                "repository_id": policy.repository_id,
+                "conditions": policy.conditions,
+                "mapped_conditions": " and ".join(c["text"] for c in clauses),
+                "clauses": clauses,
+            })
+
+        return {
+            "declared": model["declared"],
+            "policies": policies,
+            "unknown_attributes": [
+                {"attribute": attribute, "policy_ids": sorted(set(ids)), "occurrences": len(ids)}
+                for attribute, ids in sorted(unknown.items(), key=lambda item: (-len(item[1]), item[0]))
+            ],
+            "summary": {"policies": len(policies), "clauses": sum(counts.values()), **counts},
+        }
+
+    def apply_mapping(self, repository_id: int | None = None) -> dict[str, Any]:
+        """Rewrite mined conditions onto declared attributes, so exports use the declared names.
+
+        Only policies whose clauses all parse are rewritten, so no clause text is lost.
+
+        Args:
+            repository_id: Restrict to one repository
+
+        Returns:
+            The mapping report, with the number of policies updated
+        """
+        report = self.map_conditions(repository_id)
+        by_id = {p.id: p for p in self._policies(repository_id)}
+        updated = 0
+        for mapped in report["policies"]:
+            policy = by_id.get(mapped["policy_id"])
+            if policy is None or mapped["mapped_conditions"] == policy.conditions:
+                continue
+            if len(ConditionEvaluationService.parse(policy.conditions)) != len(mapped["clauses"]):
+                continue
+            policy.conditions = mapped["mapped_conditions"]
+            mapped["conditions"] = policy.conditions
+            updated += 1
+        if updated:
+            self.db.commit()
+
+        logger.info("identity_mapping_applied", tenant_id=self.tenant_id, repository_id=repository_id, policies_updated=updated)
+        return {**report, "policies_updated": updated}
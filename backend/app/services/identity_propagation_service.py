@@ -0,0 +1,228 @@
+"""Service for tracing end-user identity across internal service calls.
+
+The service graph records the credentials each hop carries; this service
+follows the identity behind them from the services users call directly. A
+hop forwarding the incoming token passes on whatever identity its service
+received, so a token relayed downstream of a client-credentials hop stands
+for the upstream service, not the user. A hop authenticating as its own
+service replaces the identity it received. When such a hop puts request
+input into a call the callee authorizes, and the caller never checks that
+the user may act on that input, the callee's check is satisfied by the
+service on the user's behalf: a confused deputy.
+"""
+
+from collections import Counter
+from enum import Enum
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.repository import Repository
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.service_call_extractor import CallCredential, ServiceCall
+from app.services.service_graph_service import ServiceGraphService, build_graph
+
+logger = structlog.get_logger(__name__)
+
+# Principal of calls made by end users rather than by another service
+END_USER = "end user"
+
+
+class Identity(str, Enum):
+    """Whose identity a callee sees on a hop."""
+
+    USER = "user"  # The end user's token reaches the callee
+    SERVICE = "service"  # The caller authenticates as itself, replacing the identity it received
+    RELAYED = "relayed"  # The caller forwards a token an upstream hop already replaced with a service identity
+    NONE = "none"  # The callee sees no identity
+
+
+def _carried(hop: dict, received: dict[str, set[str]]) -> set[str]:
+    """Principals a hop's credentials stand for, given what its caller receives."""
+    if hop["credential"] == CallCredential.SERVICE_ACCOUNT:
+        return {hop["source"]}
+    if hop["credential"] == CallCredential.USER_TOKEN:
+        return set(received.get(hop["source"], ()))
+    return set()
+
+
+def received_principals(hops: list[dict]) -> dict[str, set[str]]:
+    """Principals each service is called as.
+
+    Services no hop calls are entry points called by end users; every
+    other service receives the principals of the hops into it, iterated
+    until relayed tokens stop reaching new services.
+    """
+    targets = {h["target"] for h in hops}
+    received = {s: {END_USER} if s not in targets else set() for s in targets | {h["source"] for h in hops}}
+    changed = True
+    while changed:
+        changed = False
+        for hop in hops:
+            carried = _carried(hop, received)
+            if not carried <= received[hop["target"]]:
+                received[hop["target"]] |= carried
+                changed = True
+    return received
+
+
+def identity(hop: dict, carried: set[str]) -> Identity:
+    """Classify whose identity a hop presents to its callee."""
+    if hop["credential"] == CallCredential.SERVICE_ACCOUNT:
+        return Identity.SERVICE
+    if hop["credential"] == CallCredential.USER_TOKEN and END_USER in carried:
+        return Identity.USER
+    if hop["credential"] == CallCredential.USER_TOKEN and carried:
+        return Identity.RELAYED
+    return Identity.NONE
+
+
+def caller_endpoint(hop: dict, rules: list[EndpointRule]) -> EndpointRule | None:
+    """Endpoint rule of the caller's handler a hop is made from: the nearest one declared above it in the same file."""
+    above = [r for r in rules if r.file_path == hop["file_path"] and r.line_start is not None and r.line_start <= hop["line_start"]]
+    return max(above, key=lambda r: r.line_start, default=None)
+
+
+def _severity(caller: EndpointRule | None) -> str:
+    """Severity of a confused deputy by what the caller's own endpoint checks: nothing, sign-in only, or roles."""
+    if caller is not None and caller.is_public:
+        return "high"
+    if caller is None or not (caller.roles or caller.conditions):
+        return "medium"
+    return "low"
+
+
+def _deputy(hop: dict, carried: set[str], caller: EndpointRule | None) -> dict:
+    """Confused-deputy finding for a hop."""
+    if hop["identity"] == Identity.SERVICE:
+        presented = f"its own service identity ({hop['credential_evidence']})" if hop["credential_evidence"] else "its own service identity"
+    else:
+        presented = f"a token relayed from {', '.join(sorted(carried))}"
+    required = " or ".join(hop["target_roles"]) or "; ".join(hop["target_conditions"])
+    description = (
+        f"{hop['source']} calls {hop['method']} {hop['path']} on {hop['target']} as {presented} with {hop['user_input']}; "
+        f"{hop['target']} authorizes it for {required}, but {hop['source']} does not check that the user may act on that input"
+    )
+    return {
+        "source": hop["source"],
+        "source_repository_id": hop["source_repository_id"],
+        "target": hop["target"],
+        "target_repository_id": hop["target_repository_id"],
+        "method": hop["method"],
+        "path": hop["path"],
+        "file_path": hop["file_path"],
+        "line_start": hop["line_start"],
+        "line_end": hop["line_end"],
+        "identity": hop["identity"],
+        "credential_evidence": hop["credential_evidence"],
+        "user_input": hop["user_input"],
+        "caller_endpoint": caller.key if caller else None,
+        "caller_roles": list(caller.roles) if caller else [],
+        "target_endpoint": hop["target_endpoint"],
+        "target_roles": hop["target_roles"],
+        "target_conditions": hop["target_conditions"],
+        "target_policy_ids": hop["target_policy_ids"],
+        "severity": _severity(caller),
+        "description": description,
+    }
+
+
+def trace_identities(
+    repositories: list[Repository],
+    calls_by_repository: dict[int, list[ServiceCall]],
+    rules_by_repository: dict[int, list[EndpointRule]],
+) -> dict:
+    """Trace whose identity each hop carries and flag confused deputies.
+
+    A deputy is a hop presenting a service identity (its own or relayed) to
+    a callee endpoint that enforces roles or conditions, carrying request
+    input that the caller does not check the user's right to first.
+
+    Args:
+        repositories: Services (repositories) in scope
+        calls_by_repository: Outbound calls read from each repository's clone
+        rules_by_repository: Endpoint rules mined for each repository
+
+    Returns:
+        Services with the principals they are called as, hops with their
+        identity, confused deputies, and a summary
+    """
+    hops = build_graph(repositories, calls_by_repository, rules_by_repository)["hops"]
+    received = received_principals(hops)
+    targets = {h["target"] for h in hops}
+    deputies = []
+    for hop in hops:
+        carried = _carried(hop, received)
+        hop["identity"] = identity(hop, carried)
+        hop["carried"] = sorted(carried)
+        hop["replaces_user"] = hop["identity"] == Identity.SERVICE and END_USER in received.get(hop["source"], ())
+        if (
+            hop["identity"] in (Identity.SERVICE, Identity.RELAYED)
+            and (hop["target_roles"] or hop["target_conditions"])
+            and hop["user_input"]
+            and not hop["input_check"]
+        ):
+            caller = caller_endpoint(hop, rules_by_repository.get(hop["source_repository_id"], []))
+            deputies.append(_deputy(hop, carried, caller))
+
+    services = [
+        {"name": r.name, "repository_id": r.id, "entry": r.name not in targets, "receives": sorted(received.get(r.name, ()))}
+        for r in repositories
+    ]
+    deputies.sort(key=lambda d: (["high", "medium", "low"].index(d["severity"]), d["source"], d["file_path"], d["line_start"]))
+    return {
+        "services": services,
+        "hops": hops,
+        "deputies": deputies,
+        "summary": {
+            "hops": len(hops),
+            "by_identity": dict(Counter(h["identity"].value for h in hops)),
+            "replaced": sum(h["replaces_user"] for h in hops),
+            "deputies": len(deputies),
+            "by_severity": dict(Counter(d["severity"] for d in deputies)),
+        },
+    }
+
+
+class IdentityPropagationService:
+    """Traces end-user identity across the tenant's internal calls and flags confused deputies."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def analyze(self, repository_id: int | None = None) -> dict:
+        """Trace identity propagation across the tenant's services.
+
+        Identity is traced over all of the tenant's repositories, since what
+        a service is called as depends on its upstream callers; a restriction
+        to one repository keeps only the hops made by or to it.
+
+        Args:
+            repository_id: Restrict hops and deputies to calls made by or to one repository
+
+        Returns:
+            Services, hops, deputies, and a summary
+
+        Raises:
+            ValueError: If the requested repository does not exist
+        """
+        graph = ServiceGraphService(self.db, self.tenant_id, clone_dir=str(self.clone_dir))
+        result = trace_identities(*graph.load(repository_id))
+        if repository_id is not None:
+            for key in ("hops", "deputies"):
+                result[key] = [
+                    h for h in result[key] if repository_id in (h["source_repository_id"], h["target_repository_id"])
+                ]
+        logger.info(
+            "identity_propagation_traced",
+            tenant_id=self.tenant_id,
+            hops=result["summary"]["hops"],
+            replaced=result["summary"]["replaced"],
+            deputies=result["summary"]["deputies"],
+        )
+        return result
@@ -0,0 +1,662 @@
+"""Service for tracing mined roles back to identity provider groups.
+
+Code checks roles like ADMIN or EXPENSE_APPROVER, but people get those roles
+through groups in Okta, Entra ID, or an LDAP directory. This service ingests
+group memberships (from exports or the IdP's API), maps groups to code-level
+roles, and expands the result into role assignments. That turns a mined rule
+such as "ADMIN may DELETE /api/expenses/{id}" into a headcount: which humans,
+through which groups, can actually make the call.
+
+Groups map to roles through explicit mappings, or automatically when the
+group name ends in a mined role name ("expenses-admins" grants ADMIN).
+Expanded assignments are stored as RoleAssignment rows with an ``idp:``
+source, so role-impact analysis and access reviews see them too.
+"""
+
+import base64
+import json
+import re
+from collections import defaultdict
+from dataclasses import dataclass, field
+from urllib.parse import urlparse
+
+import httpx
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import ensure_host_allowed
+from app.models.idp_group import IdpGroup, IdpGroupRoleMapping
+from app.models.role_assignment import RoleAssignment
+from app.services.decision_simulation_service import DecisionSimulationService, _path_matches
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.role_impact_service import RoleImpactService
+
+logger = structlog.get_logger(__name__)
+
+ASSIGNMENT_SOURCE_PREFIX = "idp:"
+ENTRA_GRAPH_URL = "https://graph.microsoft.com"
+LDAP_GROUP_CLASSES = {"groupofnames", "groupofuniquenames", "posixgroup", "group"}
+LDAP_USER_ID_ATTRIBUTES = ("mail", "userprincipalname", "uid", "samaccountname", "cn")
+
+
+class IdpSource:
+    """Supported identity providers."""
+
+    OKTA = "okta"
+    ENTRA = "entra"
+    LDAP = "ldap"
+
+
+class RoleMatch:
+    """How a group came to grant a role."""
+
+    MAPPED = "mapped"  # Explicit group-to-role mapping
+    NAME = "name"  # Group name ends in the role name
+
+
+@dataclass
+class GroupRecord:
+    """A group read from an IdP, before nested groups are expanded."""
+
+    name: str
+    external_id: str
+    members: set[str] = field(default_factory=set)
+    nested_groups: list[str] = field(default_factory=list)  # external_ids of member groups
+
+
+def flatten_groups(groups: list[GroupRecord]) -> list[GroupRecord]:
+    """Expand nested group membership so each group lists every transitive user.
+
+    Args:
+        groups: Groups with direct members and nested group references
+
+    Returns:
+        The same groups with members expanded and nested references cleared
+    """
+    by_id = {g.external_id.lower(): g for g in groups}
+
+    def collect(group: GroupRecord, seen: set[str]) -> set[str]:
+        users = set(group.members)
+        for nested_id in group.nested_groups:
+            nested = by_id.get(nested_id.lower())
+            if nested is not None and nested_id.lower() not in seen:
+                users |= collect(nested, seen | {nested_id.lower()})
+        return users
+
+    return [
+        GroupRecord(name=g.name, external_id=g.external_id, members=collect(g, {g.external_id.lower()}))
+        for g in groups
+    ]
+
+
+def _user_id(entry: dict) -> str | None:
+    """Identifier of a user object from an Okta or Graph payload."""
+    profile = entry.get("profile") or {}
+    return (
+        profile.get("login")
+        or profile.get("email")
+        or entry.get("userPrincipalName")
+        or entry.get("mail")
+        or entry.get("login")
+        or entry.get("email")
+    )
+
+
+def parse_okta_groups(data: list | dict) -> list[GroupRecord]:
+    """Parse an Okta groups export.
+
+    Accepts a list of Okta group objects (``/api/v1/groups`` shape), each with
+    its members under "users" or "members" as user objects or logins.
+
+    Args:
+        data: Decoded JSON export
+
+    Returns:
+        Group records
+    """
+    items = data.get("groups", []) if isinstance(data, dict) else data
+    groups = []
+    for item in items:
+        name = (item.get("profile") or {}).get("name") or item.get("name")
+        if not name:
+            continue
+        members = item.get("users") or item.get("members") or []
+        groups.append(
+            GroupRecord(
+                name=name,
+                external_id=str(item.get("id") or name),
+                members={m if isinstance(m, str) else _user_id(m) for m in members} - {None},
+            )
+        )
+    return groups
+
+
+def parse_entra_groups(data: list | dict) -> list[GroupRecord]:
+    """Parse a Microsoft Entra ID (Graph) groups export.
+
+    Accepts Graph group objects (optionally wrapped in "value") with their
+    members expanded. Members that are groups become nested references.
+
+    Args:
+        data: Decoded JSON export
+
+    Returns:
+        Group records
+    """
+    items = data.get("value", []) if isinstance(data, dict) else data
+    groups = []
+    for item in items:
+        name = item.get("displayName") or item.get("mailNickname")
+        if not name:
+            continue
+        group = GroupRecord(name=name, external_id=str(item.get("id") or name))
+        for member in item.get("members") or []:
+            if member.get("@odata.type") == "#microsoft.graph.group":
+                group.nested_groups.append(str(member.get("id")))
+            elif user := _user_id(member):
+                group.members.add(user)
+        groups.append(group)
+    return groups
+
+
+def _ldif_entries(text: str) -> list[dict[str, list[str]]]:
+    """Split LDIF into entries of lower-cased attribute name to values."""
+    entries: list[dict[str, list[str]]] = []
+    current: dict[str, list[str]] = {}
+    lines: list[str] = []
+    for raw in text.splitlines():
+        if raw.startswith(" ") and lines:
+            lines[-1] += raw[1:]  # Folded continuation line
+        else:
+            lines.append(raw)
+
+    for line in lines + [""]:
+        if not line.strip():
+            if current:
+                entries.append(current)
+            current = {}
+            continue
+        if line.startswith("#") or ":" not in line:
+            continue
+        name, _, value = line.partition(":")
+        if value.startswith(":"):
+            value = base64.b64decode(value[1:].strip()).decode("utf-8", errors="replace")
+        current.setdefault(name.strip().lower(), []).append(value.strip())
+    return entries
+
+
+def _first_rdn_value(dn: str) -> str:
+    """Value of the leading RDN of a DN (uid=alice,ou=people -> alice)."""
+    return dn.split(",", 1)[0].partition("=")[2].strip() or dn
+
+
+def parse_ldif_groups(text: str) -> list[GroupRecord]:
+    """Parse groups out of an LDIF directory export.
+
+    Handles groupOfNames/groupOfUniqueNames (member DNs), posixGroup
+    (memberUid), and Active Directory groups. Member DNs are resolved to the
+    user entry's mail, UPN, or uid when the export includes user entries;
+    member DNs naming another group become nested references.
+
+    Args:
+        text: LDIF content
+
+    Returns:
+        Group records
+    """
+    entries = [e for e in _ldif_entries(text) if e.get("dn")]
+    group_entries, user_ids = [], {}
+    for entry in entries:
+        classes = {c.lower() for c in entry.get("objectclass", [])}
+        if classes & LDAP_GROUP_CLASSES or any(a in entry for a in ("member", "uniquemember", "memberuid")):
+            group_entries.append(entry)
+            continue
+        for attribute in LDAP_USER_ID_ATTRIBUTES:
+            if entry.get(attribute):
+                user_ids[entry["dn"][0].lower()] = entry[attribute][0]
+                break
+
+    group_dns = {e["dn"][0].lower() for e in group_entries}
+    groups = []
+    for entry in group_entries:
+        dn = entry["dn"][0]
+        group = GroupRecord(name=(entry.get("cn") or [_first_rdn_value(dn)])[0], external_id=dn)
+        for member_dn in entry.get("member", []) + entry.get("uniquemember", []):
+            if member_dn.lower() in group_dns:
+                group.nested_groups.append(member_dn)
+            elif member_dn:
+                group.members.add(user_ids.get(member_dn.lower(), _first_rdn_value(member_dn)))
+        group.members.update(entry.get("memberuid", []))
+        groups.append(group)
+    return groups
+
+
+def parse_export(source: str, content: str) -> list[GroupRecord]:
+    """Parse a group export from an identity provider.
+
+    Args:
+        source: "okta" or "entra" (JSON), or "ldap" (LDIF)
+        content: Export content
+
+    Returns:
+        Group records with nested groups expanded
+
+    Raises:
+        ValueError: If the source is unknown or the content cannot be parsed
+    """
+    if source == IdpSource.LDAP:
+        return flatten_groups(parse_ldif_groups(content))
+    if source not in (IdpSource.OKTA, IdpSource.ENTRA):
+        raise ValueError(f"Unsupported identity provider: {source}")
+    try:
+        data = json.loads(content)
+    except json.JSONDecodeError as e:
+        raise ValueError(f"Invalid {source} export: {e}") from e
+    parser = parse_okta_groups if source == IdpSource.OKTA else parse_entra_groups
+    return flatten_groups(parser(data))
+
+
+def _paginate(client: httpx.Client, url: str, headers: dict, params: dict | None = None) -> list[dict]:
+    """Fetch every page of an Okta (Link header) or Graph (@odata.nextLink) listing."""
+    items: list[dict] = []
+    next_url: str | None = url
+    while next_url:
+        response = client.get(next_url, headers=headers, params=params, timeout=30.0)
+        response.raise_for_status()
+        data = response.json()
+        if isinstance(data, dict):
+            items.extend(data.get("value", []))
+            next_url = data.get("@odata.nextLink")
+        else:
+            items.extend(data)
+            next_link = response.links.get("next")
+            next_url = next_link["url"] if next_link else None
+        params = None  # Continuation URLs carry their own query
+    return items
+
+
+def fetch_okta_groups(base_url: str, token: str, client: httpx.Client) -> list[GroupRecord]:
+    """Fetch groups and their members from the Okta API.
+
+    Args:
+        base_url: Okta org URL (e.g., https://acme.okta.com)
+        token: Okta API token
+        client: HTTP client
+
+    Returns:
+        Group records
+    """
+    headers = {"Authorization": f"SSWS {token}", "Accept": "application/json"}
+    base = base_url.rstrip("/")
+    groups = []
+    for item in _paginate(client, f"{base}/api/v1/groups", headers, {"limit": 200}):
+        users = _paginate(client, f"{base}/api/v1/groups/{item['id']}/users", headers, {"limit": 200})
+        groups.append(
+            GroupRecord(
+                name=item["profile"]["name"],
+                external_id=item["id"],
+                members={u for u in map(_user_id, users) if u},
+            )
+        )
+    return groups
+
+
+def fetch_entra_groups(token: str, client: httpx.Client, base_url: str = ENTRA_GRAPH_URL) -> list[GroupRecord]:
+    """Fetch groups and their transitive user members from Microsoft Graph.
+
+    Args:
+        token: Graph access token with GroupMember.Read.All
+        client: HTTP client
+        base_url: Graph endpoint (national clouds use their own)
+
+    Returns:
+        Group records
+    """
+    headers = {"Authorization": f"Bearer {token}", "Accept": "application/json"}
+    base = base_url.rstrip("/")
+    groups = []
+    for item in _paginate(client, f"{base}/v1.0/groups", headers, {"$select": "id,displayName"}):
+        users = _paginate(
+            client,
+            f"{base}/v1.0/groups/{item['id']}/transitiveMembers/microsoft.graph.user",
+            headers,
+            {"$select": "userPrincipalName,mail"},
+        )
+        groups.append(
+            GroupRecord(
+                name=item["displayName"],
+                external_id=item["id"],
+                members={u for u in map(_user_id, users) if u},
+            )
+        )
+    return groups
+
+
+def _name_tokens(name: str) -> list[str]:
+    """Upper-case words of a group name, splitting camelCase and separators."""
+    spaced = re.sub(r"([a-z0-9])([A-Z])", r"\1 \2", name)
+    return [t.upper() for t in re.findall(r"[A-Za-z0-9]+", spaced)]
+
+
+def match_role_by_name(group_name: str, roles: set[str]) -> str | None:
+    """Find the mined role a group name ends in.
+
+    "expenses-admins" matches ADMIN and "Billing Approvers" matches
+    BILLING_APPROVER; the longest matching suffix wins.
+
+    Args:
+        group_name: IdP group name
+        roles: Upper-case mined role names
+
+    Returns:
+        Matching role, or None
+    """
+    tokens = _name_tokens(group_name)
+    if not tokens:
+        return None
+    last = tokens[-1]
+    if len(last) > 3 and last.endswith("S"):
+        tokens = tokens[:-1] + [last[:-1]]
+    for start in range(len(tokens)):
+        for candidate in ("_".join(tokens[start:]), "_".join(tokens[start:-1] + [last])):
+            if candidate in roles:
+                return candidate
+    return None
+
+
+class IdpGroupService:
+    """Ingests IdP groups and resolves them to code-level role holders."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def fetch_groups(self, source: str, token: str, base_url: str | None = None) -> list[GroupRecord]:
+        """Fetch groups live from an IdP API.
+
+        Args:
+            source: "okta" or "entra"
+            token: API token
+            base_url: Okta org URL, or a Graph endpoint override for Entra
+
+        Returns:
+            Group records
+
+        Raises:
+            ValueError: If the source has no API connector or the request fails
+            AirGapViolation: If the IdP is outside the enclave in air-gapped mode
+        """
+        if source == IdpSource.OKTA and not base_url:
+            raise ValueError("An Okta org URL is required")
+        if source not in (IdpSource.OKTA, IdpSource.ENTRA):
+            raise ValueError(f"No API connector for {source}; upload an export instead")
+        ensure_host_allowed(urlparse(base_url or ENTRA_GRAPH_URL).hostname, "idp_group_sync")
+
+        try:
+            with httpx.Client() as client:
+                if source == IdpSource.OKTA:
+                    return fetch_okta_groups(base_url, token, client)
+                return fetch_entra_groups(token, client, base_url or ENTRA_GRAPH_URL)
+        except httpx.HTTPError as e:
+            logger.error("idp_group_fetch_failed", source=source, error=str(e))
+            raise ValueError(f"Failed to fetch groups from {source}: {e}") from e
+
+    def ingest_groups(self, source: str, groups: list[GroupRecord]) -> dict:
+        """Replace a source's groups and re-expand role assignments.
+
+        Args:
+            source: Identity provider the groups came from
+            groups: Groups with nested membership already expanded
+
+        Returns:
+            Group count plus the assignment sync summary
+        """
+        self._query(IdpGroup).filter(IdpGroup.source == source).delete(synchronize_session=False)
+        for group in groups:
+            self.db.add(
+                IdpGroup(
+                    tenant_id=self.tenant_id,
+                    source=source,
+                    external_id=group.external_id,
+                    name=group.name,
+                    members=sorted(group.members),
+                )
+            )
+        self.db.flush()
+
+        logger.info("idp_groups_ingested", source=source, groups=len(groups), tenant_id=self.tenant_id)
+        return {"source": source, "groups": len(groups), **self.sync_assignments()}
+
+    def set_mappings(self, mappings: list[dict], replace: bool = False) -> dict:
+        """Store explicit group-to-role mappings and re-expand assignments.
+
+        Args:
+            mappings: Dicts with group_name, role, and optional source and application_id
+            replace: Delete existing mappings first
+
+        Returns:
+            Mapping count plus the assignment sync summary
+        """
+        if replace:
+            self._query(IdpGroupRoleMapping).delete(synchronize_session=False)
+        for mapping in mappings:
+            self.db.add(
+                IdpGroupRoleMapping(
+                    tenant_id=self.tenant_id,
+                    source=mapping.get("source"),
+                    group_name=mapping["group_name"].strip(),
+                    role=mapping["role"].strip().upper(),
+                    application_id=mapping.get("application_id"),
+                )
+            )
+        self.db.flush()
+        return {"mappings": len(mappings), **self.sync_assignments()}
+
+    def known_roles(self) -> set[str]:
+        """Roles referenced by the tenant's mined policies."""
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies()
+        rules = EndpointMappingService.map_policies(policies)
+        return {r.upper() for r in EndpointMappingService.collect_roles(rules)}
+
+    def resolve_group_roles(self, groups: list[IdpGroup]) -> dict[int, list[tuple[str, int | None, str]]]:
+        """Roles each group grants.
+
+        Explicit mappings take precedence; a group without one is matched by
+        name against the mined roles.
+
+        Args:
+            groups: Stored groups
+
+        Returns:
+            Group ID to (role, application_id, match kind) tuples
+        """
+        mappings: dict[str, list[IdpGroupRoleMapping]] = defaultdict(list)
+        for mapping in self._query(IdpGroupRoleMapping).all():
+            mappings[mapping.group_name.lower()].append(mapping)
+        roles = self.known_roles()
+
+        resolved: dict[int, list[tuple[str, int | None, str]]] = {}
+        for group in groups:
+            explicit = [m for m in mappings.get(group.name.lower(), []) if m.source in (None, group.source)]
+            if explicit:
+                resolved[group.id] = [(m.role, m.application_id, RoleMatch.MAPPED) for m in explicit]
+            elif role := match_role_by_name(group.name, roles):
+                resolved[group.id] = [(role, None, RoleMatch.NAME)]
+            else:
+                resolved[group.id] = []
+        return resolved
+
+    def sync_assignments(self) -> dict:
+        """Rebuild IdP-derived role assignments from groups and mappings.
+
+        Returns:
+            Counts of assignments created, users covered, and unmapped groups
+        """
+        self._query(RoleAssignment).filter(
+            RoleAssignment.source.like(f"{ASSIGNMENT_SOURCE_PREFIX}%")
+        ).delete(synchronize_session=False)
+
+        groups = self._query(IdpGroup).all()
+        resolved = self.resolve_group_roles(groups)
+        assignments: set[tuple[str, str, int | None, str]] = set()
+        unmapped = []
+        for group in groups:
+            if not resolved.get(group.id):
+                unmapped.append(group.name)
+                continue
+            for role, application_id, _ in resolved[group.id]:
+                for user in group.members or []:
+                    assignments.add((user, role, application_id, group.source))
+
+        for user, role, application_id, source in sorted(assignments, key=str):
+            self.db.add(
+                RoleAssignment(
+                    tenant_id=self.tenant_id,
+                    user_identifier=user,
+                    role=role,
+                    application_id=application_id,
+                    source=f"{ASSIGNMENT_SOURCE_PREFIX}{source}",
+                )
+            )
+        self.db.commit()
+
+        users = {a[0] for a in assignments}
+        logger.info(
+            "idp_assignments_synced",
+            assignments=len(assignments),
+            users=len(users),
+            unmapped_groups=len(unmapped),
+            tenant_id=self.tenant_id,
+        )
+        return {"assignments": len(assignments), "users": len(users), "unmapped_groups": sorted(unmapped)}
+
+    def list_groups(self, source: str | None = None) -> list[dict]:
+        """List ingested groups with the roles they grant.
+
+        Args:
+            source: Restrict to one identity provider
+
+        Returns:
+            Group summaries sorted by source and name
+        """
+        query = self._query(IdpGroup)
+        if source:
+            query = query.filter(IdpGroup.source == source)
+        groups = query.all()
+        resolved = self.resolve_group_roles(groups)
+        return [
+            {
+                "id": group.id,
+                "source": group.source,
+                "name": group.name,
+                "external_id": group.external_id,
+                "member_count": len(group.members or []),
+                "roles": [
+                    {"role": role, "application_id": application_id, "match": match}
+                    for role, application_id, match in resolved.get(group.id, [])
+                ],
+                "synced_at": group.synced_at,
+            }
+            for group in sorted(groups, key=lambda g: (g.source, g.name.lower()))
+        ]
+
+    def endpoint_population(
+        self,
+        method: str,
+        path: str,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+    ) -> dict:
+        """Count the humans who can call an endpoint.
+
+        Combines the mined rules for the endpoint with role holders from
+        ingested assignments. Runtime conditions (ownership, amount limits)
+        are reported but not evaluated, so counts are an upper bound.
+
+        Args:
+            method: HTTP method
+            path: Route template or concrete path
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application (and its scoped assignments)
+
+        Returns:
+            Matching endpoints with per-role holders and groups, plus totals
+        """
+        method = method.upper()
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+            repository_id=repository_id, application_id=application_id
+        )
+        rules = [
+            rule
+            for rule in EndpointMappingService.map_policies(policies)
+            if rule.method == method and (rule.path == path or _path_matches(rule.path, path))
+        ]
+        users = RoleImpactService(self.db, self.tenant_id).load_assignments(application_id)
+
+        groups = self._query(IdpGroup).all()
+        resolved = self.resolve_group_roles(groups)
+        groups_by_role: dict[str, set[str]] = defaultdict(set)
+        for group in groups:
+            for role, scope, _ in resolved.get(group.id, []):
+                if scope in (None, application_id):
+                    groups_by_role[role].add(f"{group.source}:{group.name}")
+
+        endpoints, everyone, unbounded = [], set(), False
+        for rule in rules:
+            if rule.is_public or not rule.roles:
+                holders = set(users)
+                unbounded = unbounded or rule.is_public
+                breakdown = []
+            else:
+                allowed = {r.upper() for r in rule.roles}
+                holders = {user for user, roles in users.items() if roles & allowed}
+                breakdown = [
+                    {
+                        "role": role,
+                        "users": sum(1 for roles in users.values() if role in roles),
+                        "groups": sorted(groups_by_role.get(role, set())),
+                    }
+                    for role in sorted(allowed)
+                ]
+            everyone |= holders
+            endpoints.append(
+                {
+                    "endpoint": rule.key,
+                    "policy_ids": list(rule.policy_ids),
+                    "public": rule.is_public,
+                    "any_authenticated_user": not rule.roles and not rule.is_public,
+                    "conditions": list(rule.conditions),
+                    "user_count": len(holders),
+                    "roles": breakdown,
+                }
+            )
+
+        unheld = sorted(
+            {entry["role"] for endpoint in endpoints for entry in endpoint["roles"] if not entry["users"]}
+        )
+        logger.info(
+            "endpoint_population_computed",
+            method=method,
+            path=path,
+            endpoints=len(endpoints),
+            users=len(everyone),
+        )
+        return {
+            "method": method,
+            "path": path,
+            "endpoints": endpoints,
+            "user_count": len(everyone),
+            "users": sorted(everyone),
+            "public": unbounded,
+            "conditional": any(e["conditions"] for e in endpoints),
+            "roles_without_holders": unheld,
+            "has_assignment_data": bool(users),
+        }
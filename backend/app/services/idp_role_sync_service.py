@@ -0,0 +1,455 @@
+"""Service for syncing role definitions from identity providers and detecting drift.
+
+Connectors pull the role catalog from a Keycloak realm or an Auth0 tenant
+through their admin APIs and compare it with the roles mined code actually
+checks. Two kinds of drift are tracked:
+
+- unused_in_code: the IdP defines (and may grant) a role no code path checks,
+  usually a leftover from a retired feature or a typo in a role check.
+- missing_from_idp: code checks a role the IdP cannot grant, so the guarded
+  endpoints are unreachable or the check is dead.
+
+Drift findings are reconciled on every sync: new mismatches open a finding
+and are logged at warning level, mismatches that disappear are resolved, and
+dismissed findings stay quiet. Connectors are synced on a schedule by the
+``sync_idp_connectors`` celery beat task, or on demand through the API.
+"""
+
+import re
+from collections import defaultdict
+from datetime import UTC, datetime, timedelta
+from urllib.parse import urlparse
+
+import httpx
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import ensure_host_allowed
+from app.core.config import settings
+from app.models.idp_connector import (
+    IdpConnector,
+    IdpConnectorType,
+    IdpRoleDrift,
+    RoleDriftKind,
+    RoleDriftStatus,
+)
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+
+logger = structlog.get_logger(__name__)
+
+PAGE_SIZE = 100
+
+# Built-in roles every Keycloak realm or Auth0 tenant has; never drift
+BUILTIN_IDP_ROLES = re.compile(r"^(OFFLINE_ACCESS|UMA_AUTHORIZATION|DEFAULT_ROLES_.*)$")
+
+
+def normalize_role(name: str) -> str:
+    """Normalize a role name for comparison between the IdP and code.
+
+    "ROLE_expense-approver" and "Expense Approver" both become EXPENSE_APPROVER.
+
+    Args:
+        name: Role name as defined in the IdP or checked in code
+
+    Returns:
+        Upper-case role name with separators unified
+    """
+    role = re.sub(r"[^A-Za-z0-9]+", "_", name.strip()).strip("_").upper()
+    return role.removeprefix("ROLE_")
+
+
+def fetch_keycloak_roles(connector: IdpConnector, client: httpx.Client) -> dict[str, str]:
+    """Fetch realm roles (and optionally one client's roles) from Keycloak.
+
+    Args:
+        connector: Keycloak connector with a service-account client
+        client: HTTP client
+
+    Returns:
+        Role name to description
+    """
+    base = connector.base_url.rstrip("/")
+    token_response = client.post(
+        f"{base}/realms/{connector.realm}/protocol/openid-connect/token",
+        data={
+            "grant_type": "client_credentials",
+            "client_id": connector.client_id,
+            "client_secret": connector.client_secret,
+        },
+        timeout=30.0,
+    )
+    token_response.raise_for_status()
+    headers = {"Authorization": f"Bearer {token_response.json()['access_token']}"}
+    admin = f"{base}/admin/realms/{connector.realm}"
+
+    def paged(url: str) -> list[dict]:
+        items: list[dict] = []
+        while True:
+            response = client.get(
+                url, headers=headers, params={"first": len(items), "max": PAGE_SIZE}, timeout=30.0
+            )
+            response.raise_for_status()
+            page = response.json()
+            items.extend(page)
+            if len(page) < PAGE_SIZE:
+                return items
+
+    roles = paged(f"{admin}/roles")
+    if connector.role_client_id:
+        response = client.get(
+            f"{admin}/clients", headers=headers, params={"clientId": connector.role_client_id}, timeout=30.0
+        )
+        response.raise_for_status()
+        for role_client in response.json():
+            roles.extend(paged(f"{admin}/clients/{role_client['id']}/roles"))
+    return {r["name"]: r.get("description") or "" for r in roles}
+
+
+def fetch_auth0_roles(connector: IdpConnector, client: httpx.Client) -> dict[str, str]:
+    """Fetch roles from the Auth0 Management API.
+
+    Args:
+        connector: Auth0 connector with a machine-to-machine application
+        client: HTTP client
+
+    Returns:
+        Role name to description
+    """
+    base = connector.base_url.rstrip("/")
+    token_response = client.post(
+        f"{base}/oauth/token",
+        json={
+            "grant_type": "client_credentials",
+            "client_id": connector.client_id,
+            "client_secret": connector.client_secret,
+            "audience": f"{base}/api/v2/",
+        },
+        timeout=30.0,
+    )
+    token_response.raise_for_status()
+    headers = {"Authorization": f"Bearer {token_response.json()['access_token']}"}
+
+    roles: list[dict] = []
+    page = 0
+    while True:
+        response = client.get(
+            f"{base}/api/v2/roles",
+            headers=headers,
+            params={"page": page, "per_page": PAGE_SIZE, "include_totals": "true"},
+            timeout=30.0,
+        )
+        response.raise_for_status()
+        data = response.json()
+        roles.extend(data.get("roles", []))
+        if not data.get("roles") or len(roles) >= data.get("total", 0):
+            return {r["name"]: r.get("description") or "" for r in roles}
+        page += 1
+
+
+ROLE_FETCHERS = {
+    IdpConnectorType.KEYCLOAK: fetch_keycloak_roles,
+    IdpConnectorType.AUTH0: fetch_auth0_roles,
+}
+
+
+def diff_roles(idp_roles: dict[str, str], code_roles: dict[str, list[int]]) -> list[tuple[str, RoleDriftKind, dict]]:
+    """Compare IdP role definitions with roles referenced in code.
+
+    Args:
+        idp_roles: IdP role name to description
+        code_roles: Normalized code role to IDs of policies checking it
+
+    Returns:
+        (normalized role, drift kind, details) for every mismatch, sorted by role
+    """
+    idp = {
+        normalize_role(name): {"idp_name": name, "description": description}
+        for name, description in idp_roles.items()
+        if not BUILTIN_IDP_ROLES.match(normalize_role(name))
+    }
+    drifts = [(role, RoleDriftKind.UNUSED_IN_CODE, details) for role, details in idp.items() if role not in code_roles]
+    drifts += [
+        (role, RoleDriftKind.MISSING_FROM_IDP, {"policy_ids": sorted(policy_ids)})
+        for role, policy_ids in code_roles.items()
+        if role not in idp
+    ]
+    return sorted(drifts, key=lambda d: (d[0], d[1].value))
+
+
+class IdpRoleSyncService:
+    """Manages IdP connectors and reconciles role drift findings."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def create_connector(self, data: dict) -> IdpConnector:
+        """Register a Keycloak or Auth0 connector.
+
+        Args:
+            data: Connector fields (name, connector_type, base_url, realm,
+                client_id, client_secret, role_client_id, application_id,
+                sync_interval_minutes)
+
+        Returns:
+            Created connector
+
+        Raises:
+            ValueError: If a Keycloak connector has no realm
+        """
+        if data["connector_type"] == IdpConnectorType.KEYCLOAK and not data.get("realm"):
+            raise ValueError("Keycloak connectors require a realm")
+        connector = IdpConnector(tenant_id=self.tenant_id, **data)
+        self.db.add(connector)
+        self.db.commit()
+        self.db.refresh(connector)
+        logger.info("idp_connector_created", connector_id=connector.id, connector_type=connector.connector_type)
+        return connector
+
+    def list_connectors(self) -> list[IdpConnector]:
+        """List the tenant's connectors."""
+        return self._query(IdpConnector).order_by(IdpConnector.id).all()
+
+    def get_connector(self, connector_id: int) -> IdpConnector:
+        """Get a connector.
+
+        Args:
+            connector_id: Connector ID
+
+        Returns:
+            Connector
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self._query(IdpConnector).filter(IdpConnector.id == connector_id).first()
+        if not connector:
+            raise ValueError(f"IdP connector {connector_id} not found")
+        return connector
+
+    def delete_connector(self, connector_id: int) -> None:
+        """Delete a connector and its drift findings.
+
+        Args:
+            connector_id: Connector ID
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self.get_connector(connector_id)
+        self.db.query(IdpRoleDrift).filter(IdpRoleDrift.connector_id == connector.id).delete(
+            synchronize_session=False
+        )
+        self.db.delete(connector)
+        self.db.commit()
+
+    def code_roles(self, application_id: int | None = None) -> dict[str, list[int]]:
+        """Roles checked by mined policies, with the policies checking them.
+
+        Args:
+            application_id: Restrict to one application's policies
+
+        Returns:
+            Normalized role to policy IDs
+        """
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(application_id=application_id)
+        roles: dict[str, set[int]] = defaultdict(set)
+        for rule in EndpointMappingService.map_policies(policies):
+            for role in rule.roles:
+                roles[normalize_role(role)].update(rule.policy_ids)
+        return {role: sorted(ids) for role, ids in roles.items()}
+
+    def sync_connector(self, connector_id: int, client: httpx.Client | None = None) -> dict:
+        """Pull a connector's roles and reconcile drift findings.
+
+        Args:
+            connector_id: Connector ID
+            client: HTTP client (a new one is created if omitted)
+
+        Returns:
+            Sync summary with role counts and newly opened drift findings
+
+        Raises:
+            ValueError: If the connector does not exist or the IdP request fails
+            AirGapViolation: If the IdP is outside the enclave in air-gapped mode
+        """
+        connector = self.get_connector(connector_id)
+        ensure_host_allowed(urlparse(connector.base_url).hostname, "idp_role_sync")
+
+        fetch = ROLE_FETCHERS[IdpConnectorType(connector.connector_type)]
+        try:
+            if client is not None:
+                idp_roles = fetch(connector, client)
+            else:
+                with httpx.Client() as new_client:
+                    idp_roles = fetch(connector, new_client)
+        except (httpx.HTTPError, KeyError) as e:
+            connector.last_error = str(e)
+            self.db.commit()
+            logger.error("idp_role_sync_failed", connector_id=connector.id, error=str(e))
+            raise ValueError(f"Failed to fetch roles from {connector.name}: {e}") from e
+
+        code_roles = self.code_roles(connector.application_id)
+        opened, resolved = self._reconcile(connector, diff_roles(idp_roles, code_roles))
+
+        connector.idp_roles = sorted(idp_roles)
+        connector.last_synced_at = datetime.now(UTC)
+        connector.last_error = None
+        self.db.commit()
+
+        for drift in opened:
+            logger.warning(
+                "idp_role_drift_detected",
+                connector_id=connector.id,
+                role=drift.role,
+                kind=drift.kind.value,
+                tenant_id=self.tenant_id,
+            )
+        logger.info(
+            "idp_roles_synced",
+            connector_id=connector.id,
+            idp_roles=len(idp_roles),
+            code_roles=len(code_roles),
+            opened=len(opened),
+            resolved=resolved,
+        )
+        return {
+            "connector_id": connector.id,
+            "idp_roles": len(idp_roles),
+            "code_roles": len(code_roles),
+            "opened": [self.drift_to_dict(d) for d in opened],
+            "resolved": resolved,
+            "open_drifts": sum(1 for d in self._drifts(connector.id) if d.status == RoleDriftStatus.OPEN),
+        }
+
+    def _drifts(self, connector_id: int) -> list[IdpRoleDrift]:
+        """All drift findings of a connector."""
+        return self.db.query(IdpRoleDrift).filter(IdpRoleDrift.connector_id == connector_id).all()
+
+    def _reconcile(
+        self, connector: IdpConnector, current: list[tuple[str, RoleDriftKind, dict]]
+    ) -> tuple[list[IdpRoleDrift], int]:
+        """Open, refresh, and resolve drift findings against the latest diff.
+
+        Args:
+            connector: Synced connector
+            current: Mismatches found by this sync
+
+        Returns:
+            Newly opened (or reopened) findings, and the number resolved
+        """
+        now = datetime.now(UTC)
+        existing = {(d.role, RoleDriftKind(d.kind)): d for d in self._drifts(connector.id)}
+        opened = []
+        for role, kind, details in current:
+            drift = existing.pop((role, kind), None)
+            if drift is None:
+                drift = IdpRoleDrift(
+                    tenant_id=self.tenant_id,
+                    connector_id=connector.id,
+                    role=role,
+                    kind=kind,
+                    status=RoleDriftStatus.OPEN,
+                    first_detected_at=now,
+                )
+                self.db.add(drift)
+                opened.append(drift)
+            elif drift.status == RoleDriftStatus.RESOLVED:
+                drift.status = RoleDriftStatus.OPEN
+                drift.resolved_at = None
+                opened.append(drift)
+            drift.details = details
+            drift.last_seen_at = now
+
+        resolved = 0
+        for drift in existing.values():
+            if drift.status == RoleDriftStatus.OPEN:
+                drift.status = RoleDriftStatus.RESOLVED
+                drift.resolved_at = now
+                resolved += 1
+        return opened, resolved
+
+    def sync_due_connectors(self) -> list[dict]:
+        """Sync every connector whose interval has elapsed.
+
+        Without a tenant this covers every tenant's connectors, each synced
+        against its own tenant's policies. Failures are recorded on the
+        connector and do not stop other syncs.
+
+        Returns:
+            Summaries of the connectors synced successfully
+        """
+        now = datetime.now(UTC)
+        results = []
+        for connector in self._query(IdpConnector).all():
+            interval = timedelta(minutes=connector.sync_interval_minutes or settings.IDP_ROLE_SYNC_INTERVAL_MINUTES)
+            if connector.last_synced_at and connector.last_synced_at + interval > now:
+                continue
+            try:
+                service = IdpRoleSyncService(self.db, connector.tenant_id)
+                results.append(service.sync_connector(connector.id))
+            except Exception as e:
+                logger.error("idp_scheduled_sync_failed", connector_id=connector.id, error=str(e))
+        return results
+
+    def list_drifts(self, connector_id: int | None = None, status: str | None = None) -> list[dict]:
+        """List drift findings.
+
+        Args:
+            connector_id: Restrict to one connector
+            status: Restrict to one status (open, resolved, dismissed)
+
+        Returns:
+            Drift findings, most recently seen first
+        """
+        query = self._query(IdpRoleDrift)
+        if connector_id is not None:
+            query = query.filter(IdpRoleDrift.connector_id == connector_id)
+        if status:
+            query = query.filter(IdpRoleDrift.status == RoleDriftStatus(status))
+        return [self.drift_to_dict(d) for d in query.order_by(IdpRoleDrift.last_seen_at.desc()).all()]
+
+    def set_drift_status(self, drift_id: int, status: str) -> dict:
+        """Dismiss or reopen a drift finding.
+
+        Args:
+            drift_id: Drift finding ID
+            status: New status
+
+        Returns:
+            Updated finding
+
+        Raises:
+            ValueError: If the finding does not exist
+        """
+        drift = self._query(IdpRoleDrift).filter(IdpRoleDrift.id == drift_id).first()
+        if not drift:
+            raise ValueError(f"Role drift {drift_id} not found")
+        drift.status = RoleDriftStatus(status)
+        drift.resolved_at = datetime.now(UTC) if drift.status != RoleDriftStatus.OPEN else None
+        self.db.commit()
+        return self.drift_to_dict(drift)
+
+    @staticmethod
+    def drift_to_dict(drift: IdpRoleDrift) -> dict:
+        """Serialize a drift finding."""
+        return {
+            "id": drift.id,
+            "connector_id": drift.connector_id,
+            "role": drift.role,
+            "kind": RoleDriftKind(drift.kind).value,
+            "status": RoleDriftStatus(drift.status).value,
+            "details": drift.details or {},
+            "first_detected_at": drift.first_detected_at,
+            "last_seen_at": drift.last_seen_at,
+            "resolved_at": drift.resolved_at,
+        }
@@ -0,0 +1,288 @@
+"""Service for mining authorization config baked into container images.
+
+Services often ship their authorization rules inside the image rather than
+the source tree: an OPA bundle copied in at build time, a Casbin policy file,
+an nginx sidecar config, or an AUTH_DISABLED toggle set in the Dockerfile.
+This service reads an image archive (``docker save`` output or an OCI image
+layout tarball), flattens its layers, runs the config extractors over the
+resulting filesystem and environment, and merges the findings into the
+policies already mined from the service's source repository.
+
+Image-derived evidence is stored with an ``image://<image>/<path>`` file path,
+so rescanning the same image replaces its previous findings.
+"""
+
+import json
+import tarfile
+from collections import Counter
+from dataclasses import dataclass, field
+from pathlib import PurePosixPath
+
+import structlog
+
+from app.services.config_policy_extractor import extract_config_file, extract_env
+from app.services.config_policy_service import ConfigPolicyService
+
+logger = structlog.get_logger(__name__)
+
+# Config files are small; anything larger is skipped rather than read into memory
+MAX_CONFIG_FILE_BYTES = 1024 * 1024
+
+CANDIDATE_SUFFIXES = {".rego", ".csv", ".conf", ".env"}
+
+# Pseudo-path for the environment variables set in the image config
+IMAGE_ENV_PATH = "config/Env"
+
+OCI_INDEX_MEDIA_TYPES = {
+    "application/vnd.oci.image.index.v1+json",
+    "application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+
+@dataclass
+class ImageContents:
+    """Authorization-relevant contents of a flattened image."""
+
+    name: str
+    layers: int
+    env: list[str] = field(default_factory=list)
+    files: dict[str, str] = field(default_factory=dict)
+
+
+def image_origin(image_name: str) -> str:
+    """Evidence path prefix for files from an image."""
+    return f"image://{image_name}/"
+
+
+def is_candidate(path: str) -> bool:
+    """Check whether an image file could hold authorization config."""
+    parts = PurePosixPath(path)
+    if parts.suffix not in CANDIDATE_SUFFIXES and parts.name != ".env" and "sites-enabled" not in parts.parts:
+        return False
+    if parts.suffix == ".conf" and "nginx" not in path.lower():
+        return False
+    return not path.startswith(("usr/share/", "proc/", "sys/"))
+
+
+def _normalize(name: str) -> str | None:
+    """Normalize a tar member name to a relative path, rejecting traversal."""
+    path = name
+    while path.startswith("./"):
+        path = path[2:]
+    path = path.lstrip("/")
+    if not path or ".." in PurePosixPath(path).parts:
+        return None
+    return path
+
+
+class _Archive:
+    """Outer image archive with members addressable by normalized name."""
+
+    def __init__(self, archive: tarfile.TarFile):
+        """Initialize archive index."""
+        self.archive = archive
+        self.members = {_normalize(m.name): m for m in archive.getmembers()}
+
+    def open(self, name: str):
+        """Open a member for reading."""
+        member = self.members.get(_normalize(name))
+        data = self.archive.extractfile(member) if member is not None else None
+        if data is None:
+            raise ValueError(f"Image archive is missing {name}")
+        return data
+
+    def read_json(self, name: str) -> dict | list:
+        """Read a JSON member."""
+        try:
+            return json.load(self.open(name))
+        except json.JSONDecodeError as e:
+            raise ValueError(f"Invalid JSON in image archive member {name}: {e}") from e
+
+
+def _blob_path(descriptor: object, what: str) -> str:
+    """Path of the content-addressed blob an OCI descriptor points to.
+
+    Raises:
+        ValueError: If the descriptor has no digest
+    """
+    digest = descriptor.get("digest") if isinstance(descriptor, dict) else None
+    if not isinstance(digest, str) or ":" not in digest:
+        raise ValueError(f"Malformed {what}: descriptor without a digest")
+    algorithm, _, value = digest.partition(":")
+    return f"blobs/{algorithm}/{value}"
+
+
+def _docker_manifest(archive: _Archive) -> tuple[str, list[str], str | None]:
+    """Config path, layer paths, and first tag of a ``docker save`` manifest.
+
+    Raises:
+        ValueError: If manifest.json is not a list of image entries
+    """
+    entries = archive.read_json("manifest.json")
+    entry = entries[0] if isinstance(entries, list) and entries else None
+    if (
+        not isinstance(entry, dict)
+        or not isinstance(entry.get("Config"), str)
+        or not isinstance(entry.get("Layers"), list)
+        or not all(isinstance(layer, str) for layer in entry["Layers"])
+    ):
+        raise ValueError("Malformed manifest.json: expected an image entry with Config and Layers")
+    tags = entry.get("RepoTags")
+    name = tags[0] if isinstance(tags, list) and tags and isinstance(tags[0], str) else None
+    return entry["Config"], entry["Layers"], name
+
+
+def _oci_manifest(archive: _Archive, index: object) -> tuple[dict, str | None]:
+    """Resolve an OCI index to a single image manifest (linux/amd64 preferred).
+
+    Raises:
+        ValueError: If the index or a manifest it points to is malformed
+    """
+    manifests = index.get("manifests") if isinstance(index, dict) else None
+    if not isinstance(manifests, list) or not manifests or not all(isinstance(m, dict) for m in manifests):
+        raise ValueError("OCI index lists no manifests")
+    preferred = next(
+        (m for m in manifests if isinstance(m.get("platform"), dict) and m["platform"].get("architecture") == "amd64"),
+        manifests[0],
+    )
+    annotations = preferred.get("annotations")
+    name = annotations.get("org.opencontainers.image.ref.name") if isinstance(annotations, dict) else None
+    manifest = archive.read_json(_blob_path(preferred, "OCI index"))
+    if not isinstance(manifest, dict):
+        raise ValueError("Malformed OCI manifest: expected a JSON object")
+    if preferred.get("mediaType") in OCI_INDEX_MEDIA_TYPES or manifest.get("manifests"):
+        nested, nested_name = _oci_manifest(archive, manifest)
+        return nested, name or nested_name
+    return manifest, name
+
+
+def _apply_layer(layer: tarfile.TarFile, files: dict[str, str]) -> None:
+    """Apply one layer's changes (including whiteouts) to the collected files."""
+    removed: list[str] = []
+    opaque_dirs: list[str] = []
+    added: dict[str, str | None] = {}
+
+    for member in layer:
+        path = _normalize(member.name)
+        if path is None:
+            continue
+        node = PurePosixPath(path)
+        parent = "" if str(node.parent) == "." else f"{node.parent}/"
+        if node.name == ".wh..wh..opq":
+            opaque_dirs.append(parent)
+        elif node.name.startswith(".wh."):
+            removed.append(parent + node.name.removeprefix(".wh."))
+        elif member.isfile() and is_candidate(path) and member.size <= MAX_CONFIG_FILE_BYTES:
+            data = layer.extractfile(member)
+            added[path] = data.read().decode("utf-8", errors="replace") if data else None
+        elif path in files:
+            # Replaced by a directory, link, or oversized file: the lower copy is gone
+            added[path] = None
+
+    for prefix in opaque_dirs:
+        for path in [p for p in files if p.startswith(prefix)]:
+            del files[path]
+    for target in removed:
+        for path in [p for p in files if p == target or p.startswith(f"{target}/")]:
+            del files[path]
+    for path, text in added.items():
+        if text is None:
+            files.pop(path, None)
+        else:
+            files[path] = text
+
+
+def read_image_archive(archive_path: str) -> ImageContents:
+    """Flatten an image archive into its environment and config files.
+
+    Args:
+        archive_path: Path to a ``docker save`` tarball or OCI image layout tarball
+
+    Returns:
+        Image name, layer count, environment, and candidate config files
+
+    Raises:
+        ValueError: If the archive is not a readable container image
+    """
+    try:
+        archive = tarfile.open(archive_path, "r:*")
+    except (OSError, tarfile.TarError) as e:
+        raise ValueError(f"Cannot read image archive: {e}") from e
+
+    with archive:
+        image = _Archive(archive)
+        if "manifest.json" in image.members:
+            config_path, layer_paths, name = _docker_manifest(image)
+        elif "index.json" in image.members:
+            manifest, name = _oci_manifest(image, image.read_json("index.json"))
+            layers = manifest.get("layers") or []
+            if not isinstance(layers, list):
+                raise ValueError("Malformed OCI manifest: layers is not a list")
+            config_path = _blob_path(manifest.get("config"), "OCI manifest config")
+            layer_paths = [_blob_path(layer, "OCI manifest layer") for layer in layers]
+        else:
+            raise ValueError("Not a container image archive (no manifest.json or index.json)")
+
+        config = image.read_json(config_path)
+        runtime = (config.get("config") or {}) if isinstance(config, dict) else None
+        if not isinstance(runtime, dict) or not isinstance(runtime.get("Env") or [], list):
+            raise ValueError("Malformed image config: expected a JSON object with config.Env a list")
+        contents = ImageContents(
+            name=name or PurePosixPath(archive_path).stem,
+            layers=len(layer_paths),
+            env=[str(variable) for variable in runtime.get("Env") or []],
+        )
+        for layer_path in layer_paths:
+            member = image.open(layer_path)
+            try:
+                with tarfile.open(fileobj=member, mode="r:*") as layer:
+                    _apply_layer(layer, contents.files)
+            except tarfile.TarError as e:
+                raise ValueError(f"Unsupported or corrupt layer {layer_path}: {e}") from e
+
+    return contents
+
+
+class ImageScanService(ConfigPolicyService):
+    """Scans container images and merges their config into repository policies."""
+
+    def scan_image(self, repository_id: int, archive_path: str, image_name: str | None = None) -> dict:
+        """Extract authorization config from an image and merge it into a repository.
+
+        Args:
+            repository_id: Source repository of the service the image runs
+            archive_path: Path to the image archive
+            image_name: Image reference (defaults to the archive's tag)
+
+        Returns:
+            Scan summary with finding counts per kind and merge results
+
+        Raises:
+            ValueError: If the repository does not exist or the archive is unreadable
+        """
+        repo = self.get_repository(repository_id)
+        contents = read_image_archive(archive_path)
+        name = image_name or contents.name
+
+        findings = extract_env(IMAGE_ENV_PATH, contents.env)
+        for path, text in sorted(contents.files.items()):
+            findings.extend(extract_config_file(path, text))
+
+        merge = self.merge_findings(repo, findings, f"container image {name}", origin=image_origin(name))
+        logger.info(
+            "image_scanned",
+            repository_id=repo.id,
+            image=name,
+            layers=contents.layers,
+            findings=len(findings),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repo.id,
+            "image": name,
+            "layers": contents.layers,
+            "files_examined": len(contents.files),
+            "findings_by_kind": dict(Counter(f.kind for f in findings)),
+            "auth_disabling_findings": sum(1 for f in findings if f.disables_auth),
+            **merge,
+        }
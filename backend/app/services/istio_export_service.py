@@ -0,0 +1,261 @@
+"""Service for exporting mined policies as Istio AuthorizationPolicy resources.
+
+A service mesh can enforce the coarse part of what the code enforces before
+a request reaches the workload. This export writes one ALLOW
+AuthorizationPolicy per service (the application a policy belongs to, or the
+repository when it has none), selecting the service's pods by label, with
+one rule per mined policy:
+
+    - to:
+        - operation:
+            methods: ["PUT"]
+            paths: ["/api/expenses/{*}/approve"]
+      from:
+        - source:
+            requestPrincipals: ["*"]
+      when:
+        - key: request.auth.claims[roles]
+          values: ["MANAGER"]
+        - key: request.auth.claims[department]
+          values: ["Finance"]
+
+Route parameters become ``{*}`` path templates (Istio 1.22 or later). Any
+authenticated caller is a request principal, so the workload needs a
+RequestAuthentication validating the callers' JWTs. Role names and claim
+values compare exactly, as mined.
+
+Only what the mesh can see is translated: method, path, authentication,
+roles, and equality checks on caller attributes, which become JWT claims.
+Unlike the Rego and Cedar exports, which replace the application's decision,
+these policies sit in front of it, so a clause the mesh cannot check
+(ownership, resource attributes, numeric thresholds) does not block its rule.
+The application keeps enforcing it, and it is listed for review. Requests no
+rule matches are denied, so every route a service serves needs an approved
+policy before these are applied; ``dry_run`` marks them for Istio to log
+decisions without enforcing them. As with the other exports, database
+policies are not exported.
+"""
+
+import re
+
+import structlog
+import yaml
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus, SourceType
+from app.models.repository import Repository
+from app.services.condition_evaluation_service import ConditionClause, ConditionEvaluationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.rego_export_service import SUBJECT_CLAUSE
+from app.services.stable_identity_service import normalize_path
+
+logger = structlog.get_logger(__name__)
+
+API_VERSION = "security.istio.io/v1"
+DRY_RUN_ANNOTATION = "istio.io/dry-run"
+REPOSITORY_ANNOTATION = "policyminer.io/repository-id"
+
+# Methods that mean any method; their rules match on path alone
+ANY_METHODS = ("*", "ANY", "ALL")
+
+
+def k8s_name(text: str | None, fallback: str) -> str:
+    """DNS label usable as a resource name and label value, e.g. "expense-api" from "Expense API"."""
+    name = re.sub(r"[^a-z0-9]+", "-", (text or "").lower()).strip("-")[:63].rstrip("-")
+    return name or fallback
+
+
+def istio_path(path: str) -> str:
+    """Istio path of a normalized route: a segment holding a parameter matches any one segment."""
+    return "/".join("{*}" if "{}" in segment else segment for segment in path.split("/"))
+
+
+def claim_value(value) -> str:
+    """A JWT claim's value as Istio compares it, as a string."""
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    return str(value)
+
+
+def claim_condition(clause: ConditionClause) -> dict | None:
+    """``when`` condition of a clause, or None when the mesh cannot check it.
+
+    Only equality checks on the caller translate: the mesh sees the caller's
+    claims but not the resource, and compares claims as strings.
+    """
+    if not clause.attribute or not SUBJECT_CLAUSE.match(clause.raw):
+        return None
+    key = f"request.auth.claims[{clause.attribute.split('.')[-1]}]"
+    if clause.kind == "flag":
+        return {"key": key, "values": [claim_value(bool(clause.value))]}
+    if clause.kind == "comparison" and clause.operator in ("==", "!="):
+        return {"key": key, "values" if clause.operator == "==" else "notValues": [claim_value(clause.value)]}
+    return None
+
+
+class IstioExportService:
+    """Exports a repository's mined policies as Istio AuthorizationPolicy resources."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    @staticmethod
+    def rule(policy: Policy, method: str, path: str, role_claim: str) -> tuple[dict, list[str]]:
+        """AuthorizationPolicy rule admitting the requests one policy grants.
+
+        Returns:
+            Tuple of (rule, clauses left to the application)
+        """
+        roles, requires_authentication = EndpointMappingService.parse_roles(policy.subject)
+        operation = {"paths": [istio_path(path)]}
+        if method.upper() not in ANY_METHODS:
+            operation = {"methods": [method.upper()], **operation}
+        rule = {"to": [{"operation": operation}]}
+        if roles or requires_authentication:
+            rule["from"] = [{"source": {"requestPrincipals": ["*"]}}]
+
+        when, untranslated = [], []
+        if roles:
+            when.append({"key": f"request.auth.claims[{role_claim}]", "values": roles})
+        for clause in ConditionEvaluationService.parse(policy.conditions):
+            condition = claim_condition(clause)
+            if condition is None:
+                untranslated.append(" ".join(clause.raw.split()))
+            else:
+                when.append(condition)
+        if when:
+            rule["when"] = when
+        return rule, untranslated
+
+    def export(
+        self,
+        repository_id: int,
+        namespace: str = "default",
+        role_claim: str = "roles",
+        selector_label: str = "app",
+        dry_run: bool = False,
+        min_confidence: float | None = None,
+        include_pending: bool = False,
+    ) -> dict:
+        """Istio AuthorizationPolicies of a repository's mined policies, one per service.
+
+        Args:
+            repository_id: Repository ID
+            namespace: Namespace the services run in
+            role_claim: JWT claim listing the caller's roles
+            selector_label: Pod label whose value is the service name
+            dry_run: Have Istio log decisions instead of enforcing them
+            min_confidence: Leave out policies scored below this confidence (0-100)
+            include_pending: Also export policies that have not been approved
+
+        Returns:
+            The manifests, the AuthorizationPolicies with the endpoints and
+            mined policies behind each, clauses left to the application,
+            and a summary
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        query = self._query(Policy).filter(
+            Policy.repository_id == repository.id, Policy.source_type != SourceType.DATABASE
+        )
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        candidates = query.order_by(Policy.id).all()
+        policies = [
+            p for p in candidates
+            if min_confidence is None or (p.confidence_score is not None and p.confidence_score >= min_confidence)
+        ]
+
+        default_service = k8s_name(repository.name, f"repository-{repository.id}")
+        services: dict[str, list[tuple[str, str, Policy]]] = {}
+        for policy in policies:
+            mapped = EndpointMappingService.map_policy(policy)
+            service = k8s_name(policy.application.name, default_service) if policy.application else default_service
+            services.setdefault(service, []).append((normalize_path(mapped.path), mapped.method, policy))
+
+        documents, exported, untranslated = [], [], []
+        for service, routes in sorted(services.items()):
+            name = f"{service}-mined"
+            rules = []
+            for path, method, policy in sorted(routes, key=lambda r: (r[0], r[1], r[2].id)):
+                rule, clauses = self.rule(policy, method, path, role_claim)
+                rules.append(rule)
+                endpoint = f"{method} {path}"
+                untranslated += [{"policy_id": policy.id, "endpoint": endpoint, "clause": c} for c in clauses]
+            annotations = {REPOSITORY_ANNOTATION: str(repository.id)}
+            if dry_run:
+                annotations[DRY_RUN_ANNOTATION] = "true"
+            documents.append(
+                {
+                    "apiVersion": API_VERSION,
+                    "kind": "AuthorizationPolicy",
+                    "metadata": {
+                        "name": name,
+                        "namespace": namespace,
+                        "labels": {"app.kubernetes.io/managed-by": "policy-miner"},
+                        "annotations": annotations,
+                    },
+                    "spec": {
+                        "selector": {"matchLabels": {selector_label: service}},
+                        "action": "ALLOW",
+                        "rules": rules,
+                    },
+                }
+            )
+            exported.append(
+                {
+                    "name": name,
+                    "service": service,
+                    "endpoints": sorted({f"{method} {path}" for path, method, _ in routes}),
+                    "mined_policy_ids": sorted(p.id for _, _, p in routes),
+                    "rules": len(rules),
+                }
+            )
+
+        manifests = yaml.safe_dump_all(documents, sort_keys=False, default_flow_style=False)
+        logger.info(
+            "istio_exported",
+            repository_id=repository.id,
+            services=len(services),
+            policies=len(policies),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repository.id,
+            "namespace": namespace,
+            "manifests": manifests,
+            "authorization_policies": exported,
+            "untranslated": untranslated,
+            "summary": {
+                "policies": len(policies),
+                "services": len(services),
+                "rules": sum(p["rules"] for p in exported),
+                "endpoints": sum(len(p["endpoints"]) for p in exported),
+                "below_min_confidence": len(candidates) - len(policies),
+                "untranslated_clauses": len(untranslated),
+            },
+        }
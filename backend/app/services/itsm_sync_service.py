@@ -0,0 +1,537 @@
+"""Service for syncing findings with tickets in ServiceNow or another ITSM system.
+
+Organizations whose risk-acceptance workflow has to run through their ITSM
+get a ticket there for every finding at or above a connector's minimum
+severity, and the finding's state follows its ticket in both directions:
+
+- A finding gets a ticket when first detected.
+- When scans no longer detect it, its ticket is resolved; when it is detected
+  again, the ticket is reopened.
+- When the ticket is closed as risk accepted, the finding is marked
+  risk_accepted and left out of finding lists and exports until the ticket
+  is reopened in the ITSM.
+- A ticket closed any other way while the finding is still detected is
+  reopened with a note saying so, so a finding cannot be closed by fiat.
+
+Each connector type has an ItsmClient that creates, reads, and updates
+tickets. The ServiceNow client uses the Table API (incidents by default, or
+any task table) and reads risk acceptance from the close code. The generic
+client speaks a small REST contract any ITSM can be fronted with:
+
+    POST  {base_url}/tickets       {"external_id", "title", "description", "severity", "labels"}
+    GET   {base_url}/tickets/{id}
+    PATCH {base_url}/tickets/{id}  {"state", "comment"}
+
+where each call returns the ticket as {"id", "number", "url", "state",
+"resolution"} and state is open, resolved, or risk_accepted. Connectors are
+synced on a schedule by the ``sync_itsm_connectors`` celery beat task, or on
+demand through the API. Ticket descriptions are redacted like every other
+egress.
+"""
+
+from collections import Counter
+from dataclasses import dataclass
+from datetime import UTC, datetime, timedelta
+from urllib.parse import urlparse
+
+import httpx
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import ensure_host_allowed
+from app.core.config import settings
+from app.models.itsm_connector import FindingTicket, FindingTicketStatus, ItsmConnector, ItsmConnectorType
+from app.services.finding_export_service import SEVERITY_ORDER, FindingExportService
+from app.services.redaction_service import redact_for_egress
+
+logger = structlog.get_logger(__name__)
+
+FETCH_BATCH = 100
+
+# ServiceNow short_description limit
+MAX_TITLE_LENGTH = 160
+
+SERVICENOW_DEFAULT_TABLE = "incident"
+SERVICENOW_DEFAULT_RISK_ACCEPTED_CODE = "Risk Accepted"
+SERVICENOW_RESOLVE_CODE = "Solved (Permanently)"
+
+# Task states: 1 New, 2 In Progress, 3 On Hold, 6 Resolved, 7 Closed, 8 Canceled
+SERVICENOW_CLOSED_STATES = {"6", "7", "8"}
+SERVICENOW_RESOLVED_STATE = "6"
+SERVICENOW_REOPENED_STATE = "2"
+SERVICENOW_URGENCY = {"critical": "1", "high": "1", "medium": "2", "low": "3"}
+SERVICENOW_IMPACT = {"critical": "1", "high": "2", "medium": "2", "low": "3"}
+
+NOT_DETECTED_NOTE = "Resolved by Policy Miner: the latest scan no longer detects this finding."
+STILL_DETECTED_NOTE = (
+    "Reopened by Policy Miner: the latest scan still detects this finding. "
+    "Fix it, or close the ticket as risk accepted with a justification."
+)
+
+
+class TicketState:
+    """A ticket's state as the sync understands it, whatever the ITSM calls it."""
+
+    OPEN = "open"
+    RESOLVED = "resolved"  # Closed without accepting the risk
+    RISK_ACCEPTED = "risk_accepted"
+
+
+@dataclass
+class RemoteTicket:
+    """A ticket as read from the ITSM."""
+
+    ticket_id: str
+    state: str  # TicketState
+    remote_state: str  # State as the ITSM names it
+    number: str | None = None
+    url: str | None = None
+    resolution: str | None = None
+
+
+def ticket_title(finding: dict) -> str:
+    """One-line ticket title of a finding."""
+    title = f"[{finding['severity'].upper()}] {finding['title']} in {finding['repository_name'] or 'unknown repository'}"
+    return title if len(title) <= MAX_TITLE_LENGTH else title[: MAX_TITLE_LENGTH - 3] + "..."
+
+
+def ticket_description(finding: dict) -> str:
+    """Ticket body of a finding: what was found, where, and its taxonomy tags, redacted for egress."""
+    location = finding["file_path"] or "no source location"
+    if finding["file_path"] and finding["line_start"]:
+        location += f":{finding['line_start']}"
+    lines = [
+        finding["description"] or finding["title"],
+        "",
+        f"Repository: {finding['repository_name'] or finding['repository_id']}",
+        f"Location: {location}",
+        f"Rule: {finding['rule_id']}",
+    ]
+    if finding["cwe"]:
+        lines.append(f"CWE: {', '.join(finding['cwe'])}")
+    if finding["owasp_api"]:
+        lines.append(f"OWASP API Top 10: {', '.join(finding['owasp_api'])}")
+    lines += ["", f"Finding key: {finding['finding_key']}"]
+    return redact_for_egress("\n".join(lines), "itsm")
+
+
+class ItsmClient:
+    """Creates, reads, and updates one connector's tickets."""
+
+    def __init__(self, connector: ItsmConnector, client: httpx.Client):
+        """Initialize client."""
+        self.connector = connector
+        self.client = client
+        self.base = connector.base_url.rstrip("/")
+
+    def request(self, method: str, url: str, **kwargs) -> dict:
+        """Authenticated JSON request to the ITSM.
+
+        Raises:
+            httpx.HTTPError: If the request fails
+        """
+        headers = {"Accept": "application/json"}
+        if self.connector.username:
+            kwargs["auth"] = (self.connector.username, self.connector.secret)
+        else:
+            headers["Authorization"] = f"Bearer {self.connector.secret}"
+        response = self.client.request(method, url, headers=headers, timeout=30.0, **kwargs)
+        response.raise_for_status()
+        return response.json()
+
+    def create_ticket(self, finding: dict) -> RemoteTicket:
+        """Open a ticket for a finding."""
+        raise NotImplementedError
+
+    def fetch_tickets(self, ticket_ids: list[str]) -> dict[str, RemoteTicket]:
+        """Current state of tickets by ID; tickets the ITSM no longer has are missing."""
+        raise NotImplementedError
+
+    def update_ticket(self, ticket_id: str, state: str, comment: str) -> None:
+        """Move a ticket to the open or resolved state with a comment."""
+        raise NotImplementedError
+
+
+class ServiceNowClient(ItsmClient):
+    """Tickets in a ServiceNow table through the Table API."""
+
+    @property
+    def table(self) -> str:
+        """Table tickets are created in."""
+        return self.connector.table or SERVICENOW_DEFAULT_TABLE
+
+    def _ticket(self, record: dict) -> RemoteTicket:
+        """Ticket from a table record."""
+        state = str(record.get("state") or "")
+        if state not in SERVICENOW_CLOSED_STATES:
+            normalized = TicketState.OPEN
+        elif record.get("close_code") == (self.connector.risk_accepted_code or SERVICENOW_DEFAULT_RISK_ACCEPTED_CODE):
+            normalized = TicketState.RISK_ACCEPTED
+        else:
+            normalized = TicketState.RESOLVED
+        return RemoteTicket(
+            ticket_id=record["sys_id"],
+            state=normalized,
+            remote_state=state,
+            number=record.get("number"),
+            url=f"{self.base}/nav_to.do?uri={self.table}.do?sys_id={record['sys_id']}",
+            resolution=record.get("close_notes") or None,
+        )
+
+    def create_ticket(self, finding: dict) -> RemoteTicket:
+        """Open a record for a finding, correlated to it by its finding key."""
+        body = {
+            "short_description": ticket_title(finding),
+            "description": ticket_description(finding),
+            "urgency": SERVICENOW_URGENCY.get(finding["severity"], "2"),
+            "impact": SERVICENOW_IMPACT.get(finding["severity"], "2"),
+            "correlation_id": finding["finding_key"],
+            "correlation_display": "Policy Miner",
+        }
+        if self.connector.assignment_group:
+            body["assignment_group"] = self.connector.assignment_group
+        return self._ticket(self.request("POST", f"{self.base}/api/now/table/{self.table}", json=body)["result"])
+
+    def fetch_tickets(self, ticket_ids: list[str]) -> dict[str, RemoteTicket]:
+        """Current state of records, read in batches."""
+        tickets = {}
+        for start in range(0, len(ticket_ids), FETCH_BATCH):
+            batch = ticket_ids[start : start + FETCH_BATCH]
+            data = self.request(
+                "GET",
+                f"{self.base}/api/now/table/{self.table}",
+                params={
+                    "sysparm_query": f"sys_idIN{','.join(batch)}",
+                    "sysparm_fields": "sys_id,number,state,close_code,close_notes",
+                    "sysparm_limit": len(batch),
+                },
+            )
+            for record in data["result"]:
+                ticket = self._ticket(record)
+                tickets[ticket.ticket_id] = ticket
+        return tickets
+
+    def update_ticket(self, ticket_id: str, state: str, comment: str) -> None:
+        """Resolve or reopen a record, leaving the comment as a work note."""
+        body = {"work_notes": comment}
+        if state == TicketState.RESOLVED:
+            body |= {"state": SERVICENOW_RESOLVED_STATE, "close_code": SERVICENOW_RESOLVE_CODE, "close_notes": comment}
+        else:
+            body["state"] = SERVICENOW_REOPENED_STATE
+        self.request("PATCH", f"{self.base}/api/now/table/{self.table}/{ticket_id}", json=body)
+
+
+class GenericItsmClient(ItsmClient):
+    """Tickets behind the generic ticket REST contract."""
+
+    @staticmethod
+    def _ticket(data: dict) -> RemoteTicket:
+        """Ticket from a contract response; unknown states count as open."""
+        state = data.get("state") or ""
+        known = {TicketState.OPEN, TicketState.RESOLVED, TicketState.RISK_ACCEPTED}
+        return RemoteTicket(
+            ticket_id=str(data["id"]),
+            state=state if state in known else TicketState.OPEN,
+            remote_state=state,
+            number=data.get("number"),
+            url=data.get("url"),
+            resolution=data.get("resolution") or None,
+        )
+
+    def create_ticket(self, finding: dict) -> RemoteTicket:
+        """Open a ticket for a finding, identified to the ITSM by its finding key."""
+        body = {
+            "external_id": finding["finding_key"],
+            "title": ticket_title(finding),
+            "description": ticket_description(finding),
+            "severity": finding["severity"],
+            "labels": [finding["rule_id"], *finding["cwe"], *finding["owasp_api"]],
+        }
+        return self._ticket(self.request("POST", f"{self.base}/tickets", json=body))
+
+    def fetch_tickets(self, ticket_ids: list[str]) -> dict[str, RemoteTicket]:
+        """Current state of tickets, one request each."""
+        tickets = {}
+        for ticket_id in ticket_ids:
+            try:
+                tickets[ticket_id] = self._ticket(self.request("GET", f"{self.base}/tickets/{ticket_id}"))
+            except httpx.HTTPStatusError as e:
+                if e.response.status_code != 404:
+                    raise
+        return tickets
+
+    def update_ticket(self, ticket_id: str, state: str, comment: str) -> None:
+        """Move a ticket to a state with a comment."""
+        self.request("PATCH", f"{self.base}/tickets/{ticket_id}", json={"state": state, "comment": comment})
+
+
+ITSM_CLIENTS: dict[ItsmConnectorType, type[ItsmClient]] = {
+    ItsmConnectorType.SERVICENOW: ServiceNowClient,
+    ItsmConnectorType.GENERIC: GenericItsmClient,
+}
+
+
+class ItsmSyncService:
+    """Manages ITSM connectors and keeps findings and their tickets in sync."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = clone_dir or settings.REPO_CLONE_DIR
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def create_connector(self, data: dict) -> ItsmConnector:
+        """Register a ServiceNow or generic ITSM connector.
+
+        Args:
+            data: Connector fields (name, connector_type, base_url, username, secret,
+                table, assignment_group, risk_accepted_code, repository_id,
+                min_severity, sync_interval_minutes)
+
+        Returns:
+            Created connector
+
+        Raises:
+            ValueError: If the minimum severity is unknown
+        """
+        if data.get("min_severity") not in SEVERITY_ORDER:
+            raise ValueError(f"Unknown severity {data.get('min_severity')}; use one of {', '.join(SEVERITY_ORDER)}")
+        connector = ItsmConnector(tenant_id=self.tenant_id, **data)
+        self.db.add(connector)
+        self.db.commit()
+        self.db.refresh(connector)
+        logger.info("itsm_connector_created", connector_id=connector.id, connector_type=connector.connector_type)
+        return connector
+
+    def list_connectors(self) -> list[ItsmConnector]:
+        """List the tenant's connectors."""
+        return self._query(ItsmConnector).order_by(ItsmConnector.id).all()
+
+    def get_connector(self, connector_id: int) -> ItsmConnector:
+        """Get a connector.
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self._query(ItsmConnector).filter(ItsmConnector.id == connector_id).first()
+        if not connector:
+            raise ValueError(f"ITSM connector {connector_id} not found")
+        return connector
+
+    def delete_connector(self, connector_id: int) -> None:
+        """Delete a connector and its ticket links; the tickets stay in the ITSM.
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self.get_connector(connector_id)
+        self.db.query(FindingTicket).filter(FindingTicket.connector_id == connector.id).delete(
+            synchronize_session=False
+        )
+        self.db.delete(connector)
+        self.db.commit()
+
+    def _tickets(self, connector_id: int) -> list[FindingTicket]:
+        """All ticket links of a connector."""
+        return self.db.query(FindingTicket).filter(FindingTicket.connector_id == connector_id).all()
+
+    def detected_findings(self, connector: ItsmConnector) -> dict[str, dict]:
+        """Findings the connector tickets, including risk-accepted ones, by finding key."""
+        threshold = SEVERITY_ORDER[connector.min_severity]
+        exporter = FindingExportService(self.db, self.tenant_id, self.clone_dir)
+        findings = exporter.findings(repository_id=connector.repository_id, include_accepted=True)
+        return {f["finding_key"]: f for f in findings if SEVERITY_ORDER.get(f["severity"], 9) <= threshold}
+
+    def sync_connector(self, connector_id: int, client: httpx.Client | None = None) -> dict:
+        """Reconcile a connector's tickets with the findings scans currently detect.
+
+        Args:
+            connector_id: Connector ID
+            client: HTTP client (a new one is created if omitted)
+
+        Returns:
+            Sync summary with counts of tickets created, resolved, and reopened,
+            and of findings whose risk was accepted or whose acceptance was revoked
+
+        Raises:
+            ValueError: If the connector does not exist or the ITSM request fails
+            AirGapViolation: If the ITSM is outside the enclave in air-gapped mode
+        """
+        connector = self.get_connector(connector_id)
+        ensure_host_allowed(urlparse(connector.base_url).hostname, "itsm_sync")
+
+        detected = self.detected_findings(connector)
+        client_class = ITSM_CLIENTS[ItsmConnectorType(connector.connector_type)]
+        try:
+            if client is not None:
+                counts = self._reconcile(connector, client_class(connector, client), detected)
+            else:
+                with httpx.Client() as new_client:
+                    counts = self._reconcile(connector, client_class(connector, new_client), detected)
+        except (httpx.HTTPError, KeyError) as e:
+            connector.last_error = str(e)
+            self.db.commit()
+            logger.error("itsm_sync_failed", connector_id=connector.id, error=str(e))
+            raise ValueError(f"Failed to sync tickets with {connector.name}: {e}") from e
+
+        connector.last_synced_at = datetime.now(UTC)
+        connector.last_error = None
+        self.db.commit()
+
+        logger.info("itsm_tickets_synced", connector_id=connector.id, detected=len(detected), **counts)
+        tickets = self._tickets(connector.id)
+        return {
+            "connector_id": connector.id,
+            "detected": len(detected),
+            "created": counts["created"],
+            "resolved": counts["resolved"],
+            "reopened": counts["reopened"],
+            "risk_accepted": counts["risk_accepted"],
+            "acceptance_revoked": counts["acceptance_revoked"],
+            "open_tickets": sum(1 for t in tickets if t.status == FindingTicketStatus.OPEN),
+            "accepted_tickets": sum(1 for t in tickets if t.status == FindingTicketStatus.RISK_ACCEPTED),
+        }
+
+    def _reconcile(self, connector: ItsmConnector, itsm: ItsmClient, detected: dict[str, dict]) -> Counter:
+        """Pull ticket states into findings, then push detection changes into tickets.
+
+        Args:
+            connector: Synced connector
+            itsm: Client for the connector's ITSM
+            detected: Findings detected now, by finding key
+
+        Returns:
+            Counts of each change made
+        """
+        now = datetime.now(UTC)
+        counts: Counter = Counter()
+        tickets = {t.finding_key: t for t in self._tickets(connector.id)}
+        remote = itsm.fetch_tickets([t.ticket_id for t in tickets.values()]) if tickets else {}
+
+        def move(ticket: FindingTicket, status: FindingTicketStatus, change: str) -> None:
+            ticket.status = status
+            ticket.status_changed_at = now
+            counts[change] += 1
+
+        for key, ticket in tickets.items():
+            state = remote.get(ticket.ticket_id)
+            if state is not None:
+                ticket.remote_state = state.remote_state
+                ticket.ticket_number = state.number or ticket.ticket_number
+                ticket.resolution_note = state.resolution or ticket.resolution_note
+            ticket.last_synced_at = now
+
+            if key not in detected:
+                if ticket.status != FindingTicketStatus.RESOLVED:
+                    if state is not None and state.state == TicketState.OPEN:
+                        itsm.update_ticket(ticket.ticket_id, TicketState.RESOLVED, NOT_DETECTED_NOTE)
+                    move(ticket, FindingTicketStatus.RESOLVED, "resolved")
+                continue
+
+            if state is not None and state.state == TicketState.RISK_ACCEPTED:
+                if ticket.status != FindingTicketStatus.RISK_ACCEPTED:
+                    move(ticket, FindingTicketStatus.RISK_ACCEPTED, "risk_accepted")
+            elif ticket.status == FindingTicketStatus.RESOLVED or (
+                state is not None and state.state == TicketState.RESOLVED
+            ):
+                itsm.update_ticket(ticket.ticket_id, TicketState.OPEN, STILL_DETECTED_NOTE)
+                move(ticket, FindingTicketStatus.OPEN, "reopened")
+            elif ticket.status == FindingTicketStatus.RISK_ACCEPTED:
+                move(ticket, FindingTicketStatus.OPEN, "acceptance_revoked")
+
+        for key, finding in detected.items():
+            if key in tickets:
+                continue
+            created = itsm.create_ticket(finding)
+            self.db.add(
+                FindingTicket(
+                    tenant_id=self.tenant_id,
+                    connector_id=connector.id,
+                    finding_key=key,
+                    rule_id=finding["rule_id"],
+                    repository_id=finding["repository_id"],
+                    title=finding["title"],
+                    severity=finding["severity"],
+                    ticket_id=created.ticket_id,
+                    ticket_number=created.number,
+                    ticket_url=created.url,
+                    remote_state=created.remote_state,
+                    status=FindingTicketStatus.OPEN,
+                    created_at=now,
+                    status_changed_at=now,
+                    last_synced_at=now,
+                )
+            )
+            # Committed one by one so a failure later in the sync cannot orphan a ticket already opened
+            self.db.commit()
+            counts["created"] += 1
+        return counts
+
+    def sync_due_connectors(self) -> list[dict]:
+        """Sync every connector whose interval has elapsed.
+
+        Without a tenant this covers every tenant's connectors, each synced
+        against its own tenant's findings. Failures are recorded on the
+        connector and do not stop other syncs.
+
+        Returns:
+            Summaries of the connectors synced successfully
+        """
+        now = datetime.now(UTC)
+        results = []
+        for connector in self._query(ItsmConnector).all():
+            interval = timedelta(minutes=connector.sync_interval_minutes or settings.ITSM_SYNC_INTERVAL_MINUTES)
+            if connector.last_synced_at and connector.last_synced_at + interval > now:
+                continue
+            try:
+                service = ItsmSyncService(self.db, connector.tenant_id, self.clone_dir)
+                results.append(service.sync_connector(connector.id))
+            except Exception as e:
+                logger.error("itsm_scheduled_sync_failed", connector_id=connector.id, error=str(e))
+        return results
+
+    def list_tickets(self, connector_id: int | None = None, status: str | None = None) -> list[dict]:
+        """List finding tickets.
+
+        Args:
+            connector_id: Restrict to one connector
+            status: Restrict to one status (open, risk_accepted, resolved)
+
+        Returns:
+            Tickets, most recently changed first
+        """
+        query = self._query(FindingTicket)
+        if connector_id is not None:
+            query = query.filter(FindingTicket.connector_id == connector_id)
+        if status:
+            query = query.filter(FindingTicket.status == FindingTicketStatus(status))
+        return [self.ticket_to_dict(t) for t in query.order_by(FindingTicket.status_changed_at.desc()).all()]
+
+    @staticmethod
+    def ticket_to_dict(ticket: FindingTicket) -> dict:
+        """Serialize a finding ticket."""
+        return {
+            "id": ticket.id,
+            "connector_id": ticket.connector_id,
+            "finding_key": ticket.finding_key,
+            "rule_id": ticket.rule_id,
+            "repository_id": ticket.repository_id,
+            "title": ticket.title,
+            "severity": ticket.severity,
+            "ticket_id": ticket.ticket_id,
+            "ticket_number": ticket.ticket_number,
+            "ticket_url": ticket.ticket_url,
+            "remote_state": ticket.remote_state,
+            "status": FindingTicketStatus(ticket.status).value,
+            "resolution_note": ticket.resolution_note,
+            "created_at": ticket.created_at,
+            "status_changed_at": ticket.status_changed_at,
+            "last_synced_at": ticket.last_synced_at,
+        }
@@ -0,0 +1,581 @@
+"""Extract route authorization from Micronaut and Quarkus services.
+
+Spring is handled by spring_route_extractor. Micronaut
+controllers declare access with @Secured (or the jakarta annotations) on
+@Controller classes and their @Get/@Post methods, and Quarkus resources
+combine JAX-RS @Path/@GET with @RolesAllowed, @PermitAll, @Authenticated,
+and path-based HTTP permissions in application.properties. Micronaut apps
+can also guard paths with intercept-url-map in application.yml. These
+extractors turn each into one ConfigFinding per route, without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field
+from pathlib import PurePosixPath
+
+import yaml
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_of, line_span
+
+# Snippets show at most this many lines of a declaration
+MAX_SNIPPET_LINES = 30
+
+JVM_SUFFIXES = (".java", ".kt")
+
+HTTP_VERBS = ("GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS")
+
+# Names that are followed by "(" but never start a method declaration
+NON_METHOD_WORDS = {"if", "for", "while", "switch", "catch", "synchronized", "return", "new", "super", "this", "try"}
+
+
+class JvmRouteKind:
+    """Kinds of JVM framework route findings."""
+
+    MICRONAUT_ROUTE = "micronaut_route"
+    MICRONAUT_INTERCEPT_URL = "micronaut_intercept_url"
+    QUARKUS_ROUTE = "quarkus_route"
+    QUARKUS_HTTP_PERMISSION = "quarkus_http_permission"
+
+
+# Access decisions an annotation or policy resolves to
+ANONYMOUS = "Anonymous"
+AUTHENTICATED = "Authenticated users"
+DENIED = "Nobody"
+
+
+# A string literal (possibly unterminated at end of line), line comment, or block comment
+COMMENT_OR_STRING = re.compile(r'"[^"\\\n]*(?:\\.[^"\\\n]*)*"?|//[^\n]*|/\*.*?(?:\*/|\Z)', re.DOTALL)
+
+
+def _strip_comments(text: str) -> str:
+    """Blank out comments, keeping string literals, offsets, and newlines."""
+
+    def blank(match: re.Match) -> str:
+        token = match.group(0)
+        return token if token.startswith('"') else re.sub(r"[^\n]", " ", token)
+
+    return COMMENT_OR_STRING.sub(blank, text)
+
+
+def _close_paren(text: str, open_paren: int) -> int:
+    """Offset just past the parenthesis closing the one at open_paren."""
+    depth, i, n = 0, open_paren, len(text)
+    while i < n:
+        c = text[i]
+        if c == '"':
+            j = i + 1
+            while j < n and text[j] != '"' and text[j] != "\n":
+                j += 2 if text[j] == "\\" else 1
+            i = j + 1
+            continue
+        if c == "(":
+            depth += 1
+        elif c == ")":
+            depth -= 1
+            if depth == 0:
+                return i + 1
+        i += 1
+    return n
+
+
+@dataclass
+class Annotation:
+    """An annotation and its argument text."""
+
+    name: str
+    args: str
+    start: int
+
+    @property
+    def short_name(self) -> str:
+        """Name without the package qualifier."""
+        return self.name.rsplit(".", 1)[-1]
+
+    @property
+    def strings(self) -> list[str]:
+        """String literals in the arguments."""
+        return re.findall(r'"((?:\\.|[^"\\])*)"', self.args)
+
+    def value(self, *keys: str) -> str | None:
+        """The unnamed argument, or a named one, as a string literal."""
+        for key in keys:
+            named = re.search(rf'\b{key}\s*=\s*"([^"]*)"', self.args)
+            if named:
+                return named.group(1)
+        positional = re.match(r'\s*\(\s*"([^"]*)"', self.args)
+        return positional.group(1) if positional else None
+
+
+@dataclass
+class Declaration:
+    """A class or method with the annotations in front of it."""
+
+    kind: str  # "class" or "method"
+    name: str
+    annotations: list[Annotation]
+    start: int
+    end: int
+    owner: "Declaration | None" = None
+    members: list["Declaration"] = field(default_factory=list)
+
+    def find(self, *names: str) -> Annotation | None:
+        """First annotation with one of the given short names."""
+        return next((a for a in self.annotations if a.short_name in names), None)
+
+
+TOKEN = re.compile(
+    r'@(?!interface\b)([A-Za-z_][\w.]*)|\b(?:class|interface|object|record)\s+([A-Za-z_]\w*)|(?<![\w$])([A-Za-z_]\w*)\s*\(|[;{}="]'
+)
+
+
+ARGS_START = re.compile(r"\s*\(")
+
+
+def parse_declarations(text: str) -> list[Declaration]:
+    """Find annotated classes and their annotated methods.
+
+    Args:
+        text: Java or Kotlin source
+
+    Returns:
+        Top-level and nested classes, each with its annotated methods as members
+    """
+    code = _strip_comments(text)
+    classes: list[Declaration] = []
+    stack: list[tuple[Declaration, int]] = []  # (class, brace depth of its body)
+    pending: list[Annotation] = []
+    awaiting_body: Declaration | None = None
+    depth, pos = 0, 0
+
+    while True:
+        match = TOKEN.search(code, pos)
+        if not match:
+            break
+        pos = match.end()
+        token = match.group(0)
+        if match.group(1):
+            args = ""
+            paren = ARGS_START.match(code, pos)
+            if paren:
+                open_paren = paren.end() - 1
+                close = _close_paren(code, open_paren)
+                args, pos = code[open_paren:close], close
+            pending.append(Annotation(match.group(1), args, match.start()))
+        elif match.group(2):
+            start = pending[0].start if pending else match.start()
+            declaration = Declaration("class", match.group(2), pending, start, match.end())
+            declaration.owner = stack[-1][0] if stack else None
+            classes.append(declaration)
+            pending, awaiting_body = [], declaration
+        elif match.group(3):
+            name = match.group(3)
+            close = _close_paren(code, match.end() - 1)
+            in_class_body = stack and depth == stack[-1][1]
+            if name not in NON_METHOD_WORDS and pending and in_class_body:
+                owner = stack[-1][0]
+                method = Declaration("method", name, pending, pending[0].start, close, owner)
+                owner.members.append(method)
+            if awaiting_body is None:
+                pending = []
+            pos = close
+        elif token == '"':
+            end = code.find('"', pos)
+            pos = len(code) if end < 0 else end + 1
+        elif token == "{":
+            depth += 1
+            if awaiting_body is not None:
+                stack.append((awaiting_body, depth))
+                awaiting_body = None
+            pending = []
+        elif token == "}":
+            if stack and stack[-1][1] == depth:
+                stack.pop()
+            depth = max(depth - 1, 0)
+            pending = []
+        else:
+            pending = []
+    return classes
+
+
+def _join(*parts: str | None) -> str:
+    """Join route path segments, collapsing duplicate slashes."""
+    return "/" + "/".join(p.strip("/") for p in parts if p and p.strip("/"))
+
+
+@dataclass
+class Access:
+    """Who may call a route and on what condition."""
+
+    subject: str
+    conditions: str | None = None
+    source: str = ""
+
+
+def _security_access(annotation: Annotation | None) -> Access | None:
+    """Resolve a security annotation to an access decision."""
+    if annotation is None:
+        return None
+    name = annotation.short_name
+    if name == "PermitAll":
+        return Access(ANONYMOUS, source="@PermitAll")
+    if name == "DenyAll":
+        return Access(DENIED, source="@DenyAll")
+    if name == "Authenticated":
+        return Access(AUTHENTICATED, source="@Authenticated")
+    if name == "PermissionsAllowed":
+        permissions = annotation.strings
+        return Access(
+            AUTHENTICATED,
+            conditions=f"caller holds permission {' or '.join(permissions)}" if permissions else None,
+            source=f"@PermissionsAllowed{annotation.args}",
+        )
+
+    # @Secured / @RolesAllowed: role names or SecurityRule constants
+    values = annotation.strings + re.findall(r"SecurityRule\.(\w+)", annotation.args)
+    if "IS_ANONYMOUS" in values or "isAnonymous()" in values:
+        return Access(ANONYMOUS, source=f"@{name}{annotation.args}")
+    if "DENY_ALL" in values:
+        return Access(DENIED, source=f"@{name}{annotation.args}")
+    roles = [v for v in values if v not in ("IS_AUTHENTICATED", "isAuthenticated()")]
+    if roles == ["**"]:
+        roles = []
+    return Access(" or ".join(roles) if roles else AUTHENTICATED, source=f"@{name}{annotation.args}")
+
+
+SECURITY_ANNOTATIONS = ("Secured", "RolesAllowed", "PermitAll", "DenyAll", "Authenticated", "PermissionsAllowed")
+MICRONAUT_VERBS = {"Get", "Post", "Put", "Patch", "Delete", "Head", "Options"}
+
+
+@dataclass
+class JaxRsDefaults:
+    """quarkus.security.jaxrs settings for endpoints without annotations."""
+
+    deny_unannotated: bool = False
+    default_roles: list[str] = field(default_factory=list)
+
+
+def _route_finding(
+    kind: str,
+    file_path: str,
+    text: str,
+    method: Declaration,
+    verb: str,
+    path: str,
+    access: Access,
+    inherited_from: str | None = None,
+) -> ConfigFinding:
+    """Build the finding for one annotated handler method."""
+    line_start = line_of(text, method.start)
+    line_end = min(line_of(text, method.end), line_start + MAX_SNIPPET_LINES - 1)
+    framework = "Micronaut" if kind == JvmRouteKind.MICRONAUT_ROUTE else "Quarkus"
+    description = f"{framework} endpoint secured by {access.source}"
+    if inherited_from:
+        description += f" (inherited from {inherited_from})"
+    return ConfigFinding(
+        kind=kind,
+        file_path=file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(text, line_start, line_end),
+        subject=access.subject,
+        resource=path,
+        action=verb,
+        conditions=access.conditions,
+        description=description,
+        disables_auth=access.subject == ANONYMOUS,
+    )
+
+
+def extract_micronaut_routes(file_path: str, text: str) -> list[ConfigFinding]:
+    """Extract @Secured access from Micronaut controllers.
+
+    Method-level security overrides the controller's. Endpoints with neither
+    are left to intercept-url-map rules or the default (deny) and skipped.
+
+    Args:
+        file_path: Path of the source file
+        text: Java or Kotlin source
+
+    Returns:
+        One finding per secured endpoint
+    """
+    if "@Controller" not in text:
+        return []
+    findings = []
+    for cls in parse_declarations(text):
+        controller = cls.find("Controller")
+        if controller is None:
+            continue
+        base = controller.value("value") or "/"
+        class_access = _security_access(cls.find(*SECURITY_ANNOTATIONS))
+        for method in cls.members:
+            verb = next((a for a in method.annotations if a.short_name in MICRONAUT_VERBS), None)
+            if verb is None:
+                continue
+            own = _security_access(method.find(*SECURITY_ANNOTATIONS))
+            access = own or class_access
+            if access is None:
+                continue
+            path = _join(base, verb.value("value", "uri"))
+            findings.append(
+                _route_finding(
+                    JvmRouteKind.MICRONAUT_ROUTE,
+                    file_path,
+                    text,
+                    method,
+                    verb.short_name.upper(),
+                    path,
+                    access,
+                    None if own else cls.name,
+                )
+            )
+    return findings
+
+
+def extract_quarkus_routes(file_path: str, text: str, defaults: JaxRsDefaults | None = None) -> list[ConfigFinding]:
+    """Extract security annotations from Quarkus (JAX-RS) resources.
+
+    Method-level annotations override the resource class's. Unannotated
+    endpoints get quarkus.security.jaxrs defaults when configured and are
+    otherwise skipped (path permissions may still cover them).
+
+    Args:
+        file_path: Path of the source file
+        text: Java or Kotlin source
+        defaults: quarkus.security.jaxrs settings from application.properties
+
+    Returns:
+        One finding per secured endpoint
+    """
+    if "@Path" not in text:
+        return []
+    defaults = defaults or JaxRsDefaults()
+    findings = []
+    for cls in parse_declarations(text):
+        class_path = cls.find("Path")
+        class_access = _security_access(cls.find(*SECURITY_ANNOTATIONS))
+        for method in cls.members:
+            verb = next((a for a in method.annotations if a.short_name in HTTP_VERBS), None)
+            if verb is None or (class_path is None and method.find("Path") is None):
+                continue
+            own = _security_access(method.find(*SECURITY_ANNOTATIONS))
+            access = own or class_access
+            inherited_from = cls.name if own is None and class_access is not None else None
+            if access is None and defaults.default_roles:
+                access = Access(" or ".join(defaults.default_roles), source="quarkus.security.jaxrs.default-roles-allowed")
+            elif access is None and defaults.deny_unannotated:
+                access = Access(DENIED, source="quarkus.security.jaxrs.deny-unannotated-endpoints")
+            if access is None:
+                continue
+            sub_path = method.find("Path")
+            path = _join(class_path.value("value") if class_path else None, sub_path.value("value") if sub_path else None)
+            findings.append(
+                _route_finding(
+                    JvmRouteKind.QUARKUS_ROUTE, file_path, text, method, verb.short_name, path, access, inherited_from
+                )
+            )
+    return findings
+
+
+PROPERTY = re.compile(r"^\s*(?:%(\w+)\.)?(quarkus\.[\w.\-\"]+)\s*[=:]\s*(.*?)\s*$")
+PERMISSION_KEY = re.compile(r"^quarkus\.http\.auth\.permission\.\"?([\w\-]+)\"?\.([\w\-]+)$")
+POLICY_KEY = re.compile(r"^quarkus\.http\.auth\.policy\.\"?([\w\-]+)\"?\.([\w\-]+)$")
+BUILTIN_POLICIES = {"permit": ANONYMOUS, "authenticated": AUTHENTICATED, "deny": DENIED}
+
+
+def _properties(text: str) -> list[tuple[int, str | None, str, str]]:
+    """(line, profile, key, value) for each quarkus.* property."""
+    entries = []
+    for number, line in enumerate(text.splitlines(), start=1):
+        match = PROPERTY.match(line)
+        if match and not line.lstrip().startswith(("#", "!")):
+            entries.append((number, match.group(1), match.group(2), match.group(3)))
+    return entries
+
+
+def _csv(value: str) -> list[str]:
+    """Split a comma-separated property value."""
+    return [v.strip() for v in value.split(",") if v.strip()]
+
+
+def jaxrs_defaults(text: str) -> JaxRsDefaults:
+    """Read quarkus.security.jaxrs defaults from application.properties.
+
+    Args:
+        text: Properties file content
+
+    Returns:
+        Defaults for unannotated JAX-RS endpoints (profile overrides ignored)
+    """
+    defaults = JaxRsDefaults()
+    for _, profile, key, value in _properties(text):
+        if profile:
+            continue
+        if key == "quarkus.security.jaxrs.deny-unannotated-endpoints":
+            defaults.deny_unannotated = value.lower() == "true"
+        elif key == "quarkus.security.jaxrs.default-roles-allowed":
+            defaults.default_roles = _csv(value)
+    return defaults
+
+
+def extract_quarkus_permissions(file_path: str, text: str) -> list[ConfigFinding]:
+    """Extract path-based HTTP permissions from application.properties.
+
+    Each quarkus.http.auth.permission.<name> block maps paths (and optional
+    methods) to a built-in policy (permit, authenticated, deny) or a named
+    quarkus.http.auth.policy.<policy> with roles-allowed. Profile-prefixed
+    (%prod.) permissions are reported with the profile as a condition.
+
+    Args:
+        file_path: Path of the properties file
+        text: Properties file content
+
+    Returns:
+        One finding per permission path and method
+    """
+    permissions: dict[tuple[str | None, str], dict[str, tuple[int, str]]] = {}
+    policies: dict[str, dict[str, tuple[int, str]]] = {}
+    for number, profile, key, value in _properties(text):
+        permission = PERMISSION_KEY.match(key)
+        policy = POLICY_KEY.match(key)
+        if permission:
+            permissions.setdefault((profile, permission.group(1)), {})[permission.group(2)] = (number, value)
+        elif policy:
+            policies.setdefault(policy.group(1), {})[policy.group(2)] = (number, value)
+
+    lines = text.splitlines()
+    findings = []
+    for (profile, name), settings in permissions.items():
+        if "paths" not in settings or settings.get("enabled", (0, "true"))[1].lower() == "false":
+            continue
+        policy_name = settings.get("policy", (0, ""))[1]
+        policy = policies.get(policy_name, {})
+        conditions = []
+        if policy_name in BUILTIN_POLICIES:
+            subject = BUILTIN_POLICIES[policy_name]
+        elif "roles-allowed" in policy:
+            subject = " or ".join(_csv(policy["roles-allowed"][1])) or AUTHENTICATED
+        else:
+            subject = AUTHENTICATED
+        if "permissions-allowed" in policy:
+            conditions.append(f"caller holds permission {' or '.join(_csv(policy['permissions-allowed'][1]))}")
+        if profile:
+            conditions.append(f"active in the {profile} profile")
+
+        used = sorted({n for n, _ in settings.values()} | {n for n, _ in policy.values()})
+        snippet = "\n".join(lines[n - 1] for n in used)
+        methods = _csv(settings["methods"][1]) if "methods" in settings else ["*"]
+        for path in _csv(settings["paths"][1]):
+            for method in methods:
+                findings.append(
+                    ConfigFinding(
+                        kind=JvmRouteKind.QUARKUS_HTTP_PERMISSION,
+                        file_path=file_path,
+                        line_start=used[0],
+                        line_end=used[-1],
+                        snippet=snippet,
+                        subject=subject,
+                        resource=path,
+                        action=method.upper(),
+                        conditions="; ".join(conditions) or None,
+                        description=f"Quarkus HTTP permission '{name}' applying policy '{policy_name or 'authenticated'}'",
+                        disables_auth=subject == ANONYMOUS,
+                    )
+                )
+    return findings
+
+
+INTERCEPT_ACCESS = {"isAnonymous()": ANONYMOUS, "isAuthenticated()": AUTHENTICATED, "denyAll()": DENIED}
+
+
+def extract_micronaut_intercept_urls(file_path: str, text: str) -> list[ConfigFinding]:
+    """Extract micronaut.security.intercept-url-map rules from application.yml.
+
+    Args:
+        file_path: Path of the YAML file
+        text: File content
+
+    Returns:
+        One finding per pattern and method
+    """
+    if "intercept-url-map" not in text:
+        return []
+    try:
+        config = yaml.safe_load(text) or {}
+    except yaml.YAMLError:
+        return []
+    micronaut = config.get("micronaut") if isinstance(config, dict) else None
+    security = micronaut.get("security") if isinstance(micronaut, dict) else None
+    rules = security.get("intercept-url-map") if isinstance(security, dict) else None
+    if not isinstance(rules, list):
+        return []
+
+    findings = []
+    for rule in rules:
+        if not isinstance(rule, dict) or not rule.get("pattern"):
+            continue
+        pattern = str(rule["pattern"])
+        access = rule.get("access") or []
+        access = [str(a) for a in ([access] if isinstance(access, str) else access)]
+        decisions = [INTERCEPT_ACCESS[a] for a in access if a in INTERCEPT_ACCESS]
+        roles = [a for a in access if a not in INTERCEPT_ACCESS]
+        subject = " or ".join(roles) if roles else (decisions[0] if decisions else DENIED)
+
+        offset = max(text.find(pattern), 0)
+        line_start = line_of(text, offset)
+        # pattern, http-method, and the access list follow each other
+        line_end = min(line_start + 2 + len(access), len(text.splitlines()))
+        findings.append(
+            ConfigFinding(
+                kind=JvmRouteKind.MICRONAUT_INTERCEPT_URL,
+                file_path=file_path,
+                line_start=line_start,
+                line_end=line_end,
+                snippet=line_span(text, line_start, line_end),
+                subject=subject,
+                resource=pattern,
+                action=str(rule.get("http-method") or rule.get("httpMethod") or "*").upper(),
+                description="Micronaut intercept-url-map rule",
+                disables_auth=subject == ANONYMOUS,
+            )
+        )
+    return findings
+
+
+def is_jvm_route_file(file_path: str) -> bool:
+    """Check whether a path is a JVM source or Micronaut/Quarkus config file."""
+    path = PurePosixPath(file_path)
+    if path.suffix in JVM_SUFFIXES:
+        return True
+    return path.name.startswith("application") and path.suffix in (".properties", ".yml", ".yaml")
+
+
+def extract_jvm_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Micronaut and Quarkus route authorization from a service's files.
+
+    Args:
+        files: Relative path -> content of source and application config files
+
+    Returns:
+        Route findings across all files
+    """
+    config = {p: t for p, t in files.items() if PurePosixPath(p).suffix not in JVM_SUFFIXES}
+    defaults = JaxRsDefaults()
+    for file_path, text in config.items():
+        if file_path.endswith(".properties"):
+            found = jaxrs_defaults(text)
+            defaults.deny_unannotated |= found.deny_unannotated
+            defaults.default_roles = defaults.default_roles or found.default_roles
+
+    findings = []
+    for file_path, text in files.items():
+        if file_path in config:
+            if file_path.endswith(".properties"):
+                findings.extend(extract_quarkus_permissions(file_path, text))
+            else:
+                findings.extend(extract_micronaut_intercept_urls(file_path, text))
+            continue
+        findings.extend(extract_micronaut_routes(file_path, text))
+        findings.extend(extract_quarkus_routes(file_path, text, defaults))
+    return findings
@@ -0,0 +1,447 @@
+"""Service for mining auth settings from Helm charts and Kubernetes manifests.
+
+Deployment manifests decide part of who can reach a service: ingress
+annotations put external auth or IP allowlists in front of routes, OPA
+sidecars make authorization decisions next to the container, and
+service-account RBAC grants the workload itself access to the cluster API.
+This service reads the manifests in a repository's clone (rendering Helm
+templates just enough to parse), extracts those settings, and merges them
+into the policy view of the service each manifest deploys.
+"""
+
+import re
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass
+from pathlib import Path
+
+import structlog
+import yaml
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.config_policy_service import ConfigPolicyService
+
+logger = structlog.get_logger(__name__)
+
+# Manifest files are small; anything larger is not deployment config
+MAX_MANIFEST_BYTES = 1024 * 1024
+
+# Snippets show at most this many lines of a manifest document
+MAX_SNIPPET_LINES = 60
+
+SKIP_DIRS = {".git", "node_modules", "venv", ".venv", "vendor", "dist", "build"}
+
+WORKLOAD_KINDS = {"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job", "CronJob", "Pod"}
+
+# Helm template actions: whole-line control flow is dropped, inline values replaced
+HELM_LINE_ACTION = re.compile(r"^\s*\{\{-?.*-?\}\}\s*$")
+HELM_INLINE_ACTION = re.compile(r"\{\{-?(.*?)-?\}\}")
+
+OPA_IMAGE = re.compile(r"openpolicyagent/opa|opa-envoy|opa-istio", re.IGNORECASE)
+OPA_INJECT_ANNOTATIONS = ("opa-istio.openpolicyagent.org/inject", "openpolicyagent.org/inject")
+OPA_DECISION_ARG = re.compile(r"plugins\.envoy_ext_authz_grpc\.(?:path|query)=(\S+)")
+
+
+class K8sKind:
+    """Kinds of Kubernetes findings."""
+
+    INGRESS_AUTH = "k8s_ingress_auth"
+    OPA_SIDECAR = "k8s_opa_sidecar"
+    SERVICE_ACCOUNT_RBAC = "k8s_service_account_rbac"
+
+
+@dataclass
+class ManifestDocument:
+    """One YAML document of a manifest file with its line range."""
+
+    file_path: str
+    line_start: int
+    line_end: int
+    text: str
+    body: dict
+
+    @property
+    def kind(self) -> str:
+        """Resource kind."""
+        return str(self.body.get("kind", ""))
+
+    @property
+    def metadata(self) -> dict:
+        """Resource metadata."""
+        return self.body.get("metadata") or {}
+
+    @property
+    def name(self) -> str:
+        """Resource name."""
+        return str(self.metadata.get("name", ""))
+
+    @property
+    def snippet(self) -> str:
+        """Document text, capped for evidence."""
+        return "\n".join(self.text.splitlines()[:MAX_SNIPPET_LINES])
+
+
+def render_helm_template(text: str, chart_name: str) -> str:
+    """Reduce a Helm template to parseable YAML.
+
+    Control-flow lines ({{- if }}, {{- end }}, {{ include ... | nindent }}) are
+    blanked so line numbers stay aligned; inline names resolve to the chart
+    name and other values to a placeholder.
+
+    Args:
+        text: Template content
+        chart_name: Chart name from Chart.yaml
+
+    Returns:
+        YAML text with the same number of lines
+    """
+
+    def replace(match: re.Match) -> str:
+        expression = match.group(1)
+        return chart_name if re.search(r"fullname|\.name\b|\.Chart\.Name", expression) else "helm-value"
+
+    lines = ["" if HELM_LINE_ACTION.match(line) else HELM_INLINE_ACTION.sub(replace, line) for line in text.splitlines()]
+    return "\n".join(lines)
+
+
+def parse_documents(file_path: str, text: str) -> list[ManifestDocument]:
+    """Split a YAML file into Kubernetes resource documents.
+
+    Args:
+        file_path: Path relative to the repository root
+        text: File content
+
+    Returns:
+        Documents that declare an apiVersion and kind; unparseable ones are skipped
+    """
+    documents = []
+    lines = text.splitlines()
+    start = 0
+    for index in range(len(lines) + 1):
+        if index < len(lines) and not lines[index].startswith("---"):
+            continue
+        chunk = "\n".join(lines[start:index])
+        if chunk.strip():
+            try:
+                body = yaml.safe_load(chunk)
+            except yaml.YAMLError as e:
+                logger.debug("manifest_document_unparseable", file_path=file_path, line=start + 1, error=str(e))
+                body = None
+            if isinstance(body, dict) and body.get("apiVersion") and body.get("kind"):
+                documents.append(ManifestDocument(file_path, start + 1, index, chunk, body))
+        start = index + 1
+    return documents
+
+
+def _pod_spec(document: ManifestDocument) -> dict:
+    """Pod spec of a workload."""
+    spec = document.body.get("spec") or {}
+    if document.kind == "Pod":
+        return spec
+    if document.kind == "CronJob":
+        spec = (spec.get("jobTemplate") or {}).get("spec") or {}
+    return ((spec.get("template") or {}).get("spec")) or {}
+
+
+def _pod_annotations(document: ManifestDocument) -> dict:
+    """Pod template annotations of a workload."""
+    if document.kind == "Pod":
+        return document.metadata.get("annotations") or {}
+    spec = document.body.get("spec") or {}
+    if document.kind == "CronJob":
+        spec = (spec.get("jobTemplate") or {}).get("spec") or {}
+    return ((spec.get("template") or {}).get("metadata") or {}).get("annotations") or {}
+
+
+def _service_name(document: ManifestDocument) -> str:
+    """Service a resource belongs to (app.kubernetes.io/name label, else its name)."""
+    labels = document.metadata.get("labels") or {}
+    return str(labels.get("app.kubernetes.io/name") or labels.get("app") or document.name)
+
+
+def ingress_auth(annotations: dict) -> tuple[list[str], list[str], bool]:
+    """Describe the auth an ingress's annotations enforce.
+
+    Args:
+        annotations: Ingress metadata annotations
+
+    Returns:
+        Tuple of (subject descriptions, auth annotation entries, whether auth is switched off)
+    """
+    subjects, entries, disabled = [], [], False
+    for key, value in sorted(annotations.items()):
+        value = str(value)
+        suffix = key.rsplit("/", 1)[-1]
+        if key.startswith("nginx.ingress.kubernetes.io/"):
+            if suffix == "auth-url":
+                subjects.append(f"Caller approved by external auth {value}")
+            elif suffix == "auth-type":
+                subjects.append(f"Authenticated user (HTTP {value})")
+            elif suffix in ("whitelist-source-range", "allowlist-source-range"):
+                subjects.append(f"Clients from {value}")
+            elif suffix == "enable-global-auth" and value.lower() == "false":
+                disabled = True
+            elif not suffix.startswith("auth-"):
+                continue
+        elif key == "alb.ingress.kubernetes.io/auth-type":
+            if value.lower() == "none":
+                continue
+            subjects.append(f"Caller authenticated via ALB {value}")
+        elif key == "traefik.ingress.kubernetes.io/router.middlewares":
+            subjects.append(f"Caller passing Traefik middleware {value}")
+        elif key == "konghq.com/plugins":
+            subjects.append(f"Caller passing Kong plugins {value}")
+        else:
+            continue
+        entries.append(f"{key}={value}")
+    return subjects, entries, disabled
+
+
+def extract_ingresses(documents: list[ManifestDocument]) -> list[ConfigFinding]:
+    """Extract per-route auth from Ingress annotations.
+
+    Args:
+        documents: Manifest documents
+
+    Returns:
+        One finding per ingress path with auth annotations
+    """
+    findings = []
+    for document in documents:
+        if document.kind != "Ingress":
+            continue
+        subjects, entries, disabled = ingress_auth(document.metadata.get("annotations") or {})
+        if not entries:
+            continue
+        for rule in (document.body.get("spec") or {}).get("rules") or []:
+            host = rule.get("host") or "*"
+            for path in ((rule.get("http") or {}).get("paths")) or []:
+                backend = path.get("backend") or {}
+                service = (backend.get("service") or {}).get("name") or backend.get("serviceName")
+                findings.append(
+                    ConfigFinding(
+                        kind=K8sKind.INGRESS_AUTH,
+                        file_path=document.file_path,
+                        line_start=document.line_start,
+                        line_end=document.line_end,
+                        snippet=document.snippet,
+                        subject=" and ".join(subjects) if subjects else "Anyone (global auth disabled)",
+                        resource=f"{host}{path.get('path') or '/'}",
+                        action="access",
+                        conditions="; ".join(entries),
+                        description=f"Ingress {document.name} auth annotations",
+                        disables_auth=disabled and not subjects,
+                        service=str(service) if service else None,
+                    )
+                )
+    return findings
+
+
+def extract_opa_sidecars(documents: list[ManifestDocument]) -> list[ConfigFinding]:
+    """Extract OPA sidecars (explicit containers or injection annotations) from workloads.
+
+    Args:
+        documents: Manifest documents
+
+    Returns:
+        One finding per workload guarded by OPA
+    """
+    findings = []
+    for document in documents:
+        if document.kind not in WORKLOAD_KINDS:
+            continue
+        pod = _pod_spec(document)
+        containers = (pod.get("containers") or []) + (pod.get("initContainers") or [])
+        opa = [c for c in containers if OPA_IMAGE.search(str(c.get("image", "")))]
+        annotations = _pod_annotations(document)
+        injected = any(str(annotations.get(key, "")).lower() in ("enabled", "true") for key in OPA_INJECT_ANNOTATIONS)
+        if not opa and not injected:
+            continue
+
+        args = [str(a) for c in opa for a in (c.get("args") or [])]
+        decisions = sorted({m for a in args for m in OPA_DECISION_ARG.findall(a)})
+        decision = ", ".join(decisions) if decisions else "default decision"
+        findings.append(
+            ConfigFinding(
+                kind=K8sKind.OPA_SIDECAR,
+                file_path=document.file_path,
+                line_start=document.line_start,
+                line_end=document.line_end,
+                snippet=document.snippet,
+                subject=f"Callers allowed by OPA ({decision})",
+                resource=document.name,
+                action="access",
+                conditions="; ".join(args) or "injected by annotation",
+                description=f"OPA sidecar on {document.kind} {document.name}",
+                service=_service_name(document),
+            )
+        )
+    return findings
+
+
+def _rule_resource(rule: dict) -> str:
+    """Readable resource list of an RBAC rule (apiGroup/resource)."""
+    groups = rule.get("apiGroups") or [""]
+    resources = rule.get("resources") or rule.get("nonResourceURLs") or ["*"]
+    return ", ".join(f"{g}/{r}" if g else r for g in groups for r in resources)
+
+
+def extract_service_account_rbac(documents: list[ManifestDocument]) -> list[ConfigFinding]:
+    """Extract the cluster permissions each workload's service account is granted.
+
+    Role and ClusterRole rules are joined with the bindings that grant them to
+    service accounts, and attributed to the workloads running as those accounts.
+
+    Args:
+        documents: Manifest documents
+
+    Returns:
+        One finding per (service account, role rule)
+    """
+    roles = {(d.kind, d.name): d for d in documents if d.kind in ("Role", "ClusterRole")}
+    workloads: dict[str, list[ManifestDocument]] = {}
+    for document in documents:
+        if document.kind in WORKLOAD_KINDS:
+            pod = _pod_spec(document)
+            account = pod.get("serviceAccountName") or pod.get("serviceAccount") or "default"
+            workloads.setdefault(str(account), []).append(document)
+
+    findings = []
+    for binding in documents:
+        if binding.kind not in ("RoleBinding", "ClusterRoleBinding"):
+            continue
+        role_ref = binding.body.get("roleRef") or {}
+        role = roles.get((role_ref.get("kind", "Role"), role_ref.get("name", "")))
+        if role is None:
+            continue
+        namespace = binding.metadata.get("namespace") or "default"
+        for subject in binding.body.get("subjects") or []:
+            if subject.get("kind") != "ServiceAccount":
+                continue
+            account = str(subject.get("name", ""))
+            running = workloads.get(account, [])
+            service = _service_name(running[0]) if running else account
+            for rule in role.body.get("rules") or []:
+                findings.append(
+                    ConfigFinding(
+                        kind=K8sKind.SERVICE_ACCOUNT_RBAC,
+                        file_path=role.file_path,
+                        line_start=role.line_start,
+                        line_end=role.line_end,
+                        snippet=role.snippet,
+                        subject=f"service account {subject.get('namespace') or namespace}/{account}",
+                        resource=_rule_resource(rule),
+                        action=", ".join(rule.get("verbs") or []),
+                        conditions=(
+                            f"resourceNames: {', '.join(rule['resourceNames'])}" if rule.get("resourceNames") else None
+                        ),
+                        description=f"{role.kind} {role.name} bound by {binding.kind} {binding.name}",
+                        service=service,
+                    )
+                )
+    return findings
+
+
+def helm_chart_name(path: Path, root: Path) -> str | None:
+    """Name of the Helm chart a template belongs to, if any."""
+    for parent in path.parents:
+        chart = parent / "Chart.yaml"
+        if chart.is_file():
+            try:
+                meta = yaml.safe_load(chart.read_text(encoding="utf-8", errors="replace")) or {}
+            except yaml.YAMLError:
+                meta = {}
+            return str(meta.get("name") or parent.name) if isinstance(meta, dict) else parent.name
+        if parent == root:
+            break
+    return None
+
+
+def load_manifests(root: Path) -> list[ManifestDocument]:
+    """Parse every manifest document in a repository checkout.
+
+    Args:
+        root: Repository root
+
+    Returns:
+        Manifest documents with repository-relative paths
+    """
+    documents = []
+    for path in sorted(root.rglob("*")):
+        if path.suffix not in (".yaml", ".yml") or not path.is_file():
+            continue
+        relative = path.relative_to(root)
+        if SKIP_DIRS.intersection(relative.parts) or path.stat().st_size > MAX_MANIFEST_BYTES:
+            continue
+        if path.name in ("Chart.yaml", "values.yaml") or path.name.startswith("values-"):
+            continue
+        text = path.read_text(encoding="utf-8", errors="replace")
+        chart = helm_chart_name(path, root) if "templates" in relative.parts else None
+        if chart:
+            text = render_helm_template(text, chart)
+        documents.extend(parse_documents(relative.as_posix(), text))
+    return documents
+
+
+def mine_checkout(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the auth findings of a clone's manifests and what was parsed."""
+    documents = load_manifests(Path(root))
+    findings = (
+        extract_ingresses(documents) + extract_opa_sidecars(documents) + extract_service_account_rbac(documents)
+    )
+    yield {
+        "documents": len(documents),
+        "files": len({d.file_path for d in documents}),
+        "findings": [asdict(f) for f in findings],
+    }
+
+
+class K8sManifestService(ConfigPolicyService):
+    """Mines Helm charts and Kubernetes manifests in a repository clone."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        super().__init__(db, tenant_id)
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def scan_repository(self, repository_id: int) -> dict:
+        """Mine manifest auth settings from a repository's clone.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Summary with documents parsed, findings per kind, and merge results
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        (mined,) = run_pass(root, mine_checkout)
+        findings = [ConfigFinding(**f) for f in mined["findings"]]
+        merge = self.merge_findings(
+            repo, findings, "Kubernetes manifests", previous=["%.yaml", "%.yml"], label_scoped=True
+        )
+
+        logger.info(
+            "k8s_manifests_scanned",
+            repository_id=repo.id,
+            documents=mined["documents"],
+            findings=len(findings),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repo.id,
+            "documents": mined["documents"],
+            "files": mined["files"],
+            "findings_by_kind": dict(Counter(f.kind for f in findings)),
+            "services": sorted({f.service for f in findings if f.service}),
+            **merge,
+        }
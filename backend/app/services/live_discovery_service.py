@@ -0,0 +1,369 @@
+"""Service for discovering endpoints on running services.
+
+Static scanning only sees routes the analyzers can parse. Services generated
+from protobuf definitions, routes registered by frameworks the miner does not
+understand, and endpoints added by sidecars or gateways all slip through.
+This service asks running services what they expose instead:
+
+- gRPC services via server reflection (list services, then resolve each
+  service's file descriptor to enumerate its methods).
+- REST services via their OpenAPI or Swagger document, fetched from a given
+  URL or probed at the usual locations.
+
+Live endpoints are reconciled against the repository's mined rules and the
+route registrations found in its clone. Endpoints that are deployed but were
+never seen by the static scan are the ones to look at first.
+"""
+
+import json
+from dataclasses import asdict, dataclass
+from pathlib import Path
+from urllib.parse import urljoin, urlparse
+
+import httpx
+import structlog
+import yaml
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import ensure_host_allowed
+from app.core.config import settings
+from app.models.policy import Policy
+from app.services.coverage_metrics_service import CoverageMetricsService, route_key
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import HTTP_METHODS, EndpointMappingService
+
+logger = structlog.get_logger(__name__)
+
+# Probed in order when a REST target is given without a document path
+OPENAPI_PATHS = (
+    "/openapi.json",
+    "/v3/api-docs",
+    "/swagger/v1/swagger.json",
+    "/swagger.json",
+    "/v2/api-docs",
+    "/openapi.yaml",
+)
+
+GRPC_REFLECTION_SERVICES = {
+    "grpc.reflection.v1alpha.ServerReflection",
+    "grpc.reflection.v1.ServerReflection",
+    "grpc.health.v1.Health",
+}
+
+
+class LiveProtocol:
+    """Protocols live endpoints are discovered over."""
+
+    REST = "rest"
+    GRPC = "grpc"
+
+
+class LiveEndpointStatus:
+    """How a live endpoint relates to the static scan."""
+
+    MINED = "mined"  # A mined rule covers it
+    UNMINED = "unmined"  # Registered in source, but no authorization was mined for it
+    NEVER_SEEN = "never_seen"  # Not found anywhere in the static scan
+
+
+@dataclass
+class LiveEndpoint:
+    """An endpoint a running service reports."""
+
+    protocol: str
+    method: str
+    path: str
+    target: str
+    operation: str | None = None  # OpenAPI operationId or gRPC method name
+    requires_auth: bool | None = None  # From OpenAPI security; unknown for gRPC
+    streaming: bool = False
+    base_path: str = ""  # OpenAPI server path prefixed to path
+
+    @property
+    def key(self) -> str:
+        """Normalized route key."""
+        return route_key(self.method, self.path)
+
+
+def parse_openapi(document: dict, target: str) -> list[LiveEndpoint]:
+    """Enumerate operations in an OpenAPI 3 or Swagger 2 document.
+
+    Args:
+        document: Parsed OpenAPI document
+        target: Where the document was fetched from
+
+    Returns:
+        One endpoint per path and method, with the server base path applied
+    """
+    if document.get("swagger"):
+        base_path = (document.get("basePath") or "").rstrip("/")
+    else:
+        servers = document.get("servers") or [{}]
+        base_path = urlparse(servers[0].get("url") or "").path.rstrip("/")
+    global_security = document.get("security")
+
+    endpoints = []
+    for path, operations in (document.get("paths") or {}).items():
+        for method, operation in (operations or {}).items():
+            if method.upper() not in HTTP_METHODS or not isinstance(operation, dict):
+                continue
+            security = operation.get("security", global_security)
+            endpoints.append(
+                LiveEndpoint(
+                    protocol=LiveProtocol.REST,
+                    method=method.upper(),
+                    path=f"{base_path}{path}",
+                    target=target,
+                    operation=operation.get("operationId"),
+                    requires_auth=None if security is None else any(security),
+                    base_path=base_path,
+                )
+            )
+    return endpoints
+
+
+def load_openapi_document(text: str) -> dict | None:
+    """Parse an OpenAPI document as JSON, falling back to YAML."""
+    try:
+        document = json.loads(text)
+    except json.JSONDecodeError:
+        try:
+            document = yaml.safe_load(text)
+        except yaml.YAMLError:
+            return None
+    if isinstance(document, dict) and ("openapi" in document or "swagger" in document):
+        return document
+    return None
+
+
+def fetch_openapi(url: str, client: httpx.Client, headers: dict | None = None) -> list[LiveEndpoint]:
+    """Fetch a REST service's OpenAPI document and enumerate its routes.
+
+    Args:
+        url: Document URL, or the service base URL to probe
+        client: HTTP client
+        headers: Extra request headers (e.g., Authorization)
+
+    Returns:
+        Live endpoints
+
+    Raises:
+        ValueError: If no OpenAPI document could be found
+    """
+    parsed = urlparse(url)
+    candidates = [url] if parsed.path not in ("", "/") else [urljoin(url, p) for p in OPENAPI_PATHS]
+    for candidate in candidates:
+        try:
+            response = client.get(candidate, headers=headers or {}, timeout=15.0, follow_redirects=True)
+        except httpx.HTTPError as e:
+            logger.debug("openapi_probe_failed", url=candidate, error=str(e))
+            continue
+        if response.status_code != 200:
+            continue
+        document = load_openapi_document(response.text)
+        if document is not None:
+            return parse_openapi(document, candidate)
+    raise ValueError(f"No OpenAPI document found at {url}")
+
+
+def _grpc_method_endpoints(file_descriptors: list[bytes], service_name: str, target: str) -> list[LiveEndpoint]:
+    """Methods of one service from serialized FileDescriptorProtos."""
+    from google.protobuf import descriptor_pb2
+
+    endpoints = []
+    for raw in file_descriptors:
+        proto = descriptor_pb2.FileDescriptorProto.FromString(raw)
+        for service in proto.service:
+            full_name = f"{proto.package}.{service.name}" if proto.package else service.name
+            if full_name != service_name:
+                continue
+            for method in service.method:
+                endpoints.append(
+                    LiveEndpoint(
+                        protocol=LiveProtocol.GRPC,
+                        method="POST",  # gRPC calls are HTTP/2 POSTs to /package.Service/Method
+                        path=f"/{full_name}/{method.name}",
+                        target=target,
+                        operation=method.name,
+                        streaming=method.client_streaming or method.server_streaming,
+                    )
+                )
+    return endpoints
+
+
+def fetch_grpc_methods(target: str, use_tls: bool = False, timeout: float = 10.0) -> list[LiveEndpoint]:
+    """Enumerate a gRPC server's methods through server reflection.
+
+    Args:
+        target: host:port of the gRPC server
+        use_tls: Connect with TLS (system roots)
+        timeout: Per-call deadline in seconds
+
+    Returns:
+        Live endpoints, one per method (reflection and health services excluded)
+
+    Raises:
+        ValueError: If the server cannot be reached or does not support reflection
+    """
+    import grpc
+    from grpc_reflection.v1alpha import reflection_pb2, reflection_pb2_grpc
+
+    channel = grpc.secure_channel(target, grpc.ssl_channel_credentials()) if use_tls else grpc.insecure_channel(target)
+    try:
+        stub = reflection_pb2_grpc.ServerReflectionStub(channel)
+
+        def ask(request):
+            responses = stub.ServerReflectionInfo(iter([request]), timeout=timeout)
+            response = next(iter(responses))
+            if response.HasField("error_response"):
+                raise ValueError(response.error_response.error_message)
+            return response
+
+        listing = ask(reflection_pb2.ServerReflectionRequest(list_services=""))
+        services = [s.name for s in listing.list_services_response.service if s.name not in GRPC_REFLECTION_SERVICES]
+        endpoints = []
+        for service in services:
+            described = ask(reflection_pb2.ServerReflectionRequest(file_containing_symbol=service))
+            endpoints.extend(
+                _grpc_method_endpoints(
+                    list(described.file_descriptor_response.file_descriptor_proto), service, target
+                )
+            )
+        return endpoints
+    except grpc.RpcError as e:
+        raise ValueError(f"gRPC reflection failed for {target}: {e.code().name} {e.details()}") from e
+    finally:
+        channel.close()
+
+
+def _mentions(policy: Policy, name: str) -> bool:
+    """Whether a policy's resource, action, or evidence names a gRPC method."""
+    needle = name.lower()
+    texts = [policy.resource or "", policy.action or ""] + [e.code_snippet or "" for e in policy.evidence or []]
+    return any(needle in text.lower() for text in texts)
+
+
+def reconcile(
+    live: list[LiveEndpoint], policies: list[Policy], source_routes: dict[str, str] | None
+) -> dict:
+    """Compare live endpoints with what the static scan found.
+
+    REST endpoints match mined endpoints and source routes by normalized
+    route, with and without the OpenAPI server base path. gRPC methods match
+    a mined rule whose resource, action, or evidence names the method, since
+    the analyzers record gRPC handlers by method name rather than route.
+
+    Args:
+        live: Endpoints reported by running services
+        policies: Mined policies in scope
+        source_routes: Route registrations found in the clone, if available
+
+    Returns:
+        Reconciliation report with per-endpoint status and mined routes not served live
+    """
+    rules = {route_key(r.method, r.path): r for r in EndpointMappingService.map_policies(policies)}
+    source = source_routes or {}
+
+    results, live_keys = [], set()
+    for endpoint in live:
+        keys = {endpoint.key}
+        if endpoint.base_path:
+            keys.add(route_key(endpoint.method, endpoint.path.removeprefix(endpoint.base_path) or "/"))
+        live_keys |= keys
+
+        if keys & set(rules) or (
+            endpoint.protocol == LiveProtocol.GRPC and any(_mentions(p, endpoint.operation or "") for p in policies)
+        ):
+            status = LiveEndpointStatus.MINED
+        elif keys & set(source):
+            status = LiveEndpointStatus.UNMINED
+        else:
+            status = LiveEndpointStatus.NEVER_SEEN
+        results.append({**asdict(endpoint), "key": endpoint.key, "status": status})
+
+    statuses = (LiveEndpointStatus.MINED, LiveEndpointStatus.UNMINED, LiveEndpointStatus.NEVER_SEEN)
+    counts = {status: sum(1 for r in results if r["status"] == status) for status in statuses}
+    # Only meaningful when a REST service was asked for its full route list
+    rest_discovered = any(e.protocol == LiveProtocol.REST for e in live)
+    not_live = sorted(key for key in rules if key not in live_keys) if rest_discovered else []
+    return {
+        "live_endpoints": len(results),
+        "counts": counts,
+        "endpoints": sorted(results, key=lambda r: (r["status"] != LiveEndpointStatus.NEVER_SEEN, r["key"])),
+        "mined_not_live": not_live,
+        "unauthenticated_live": sorted(r["key"] for r in results if r["requires_auth"] is False),
+    }
+
+
+class LiveDiscoveryService:
+    """Discovers live endpoints and reconciles them with mined routes."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def discover(
+        self,
+        openapi_urls: list[str] | None = None,
+        grpc_targets: list[str] | None = None,
+        grpc_tls: bool = False,
+        headers: dict | None = None,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+    ) -> dict:
+        """Discover live endpoints and reconcile them with the static scan.
+
+        Targets that cannot be reached are reported as errors; the others
+        are still reconciled.
+
+        Args:
+            openapi_urls: OpenAPI document URLs or REST service base URLs
+            grpc_targets: host:port of gRPC servers with reflection enabled
+            grpc_tls: Use TLS for gRPC connections
+            headers: Extra headers for OpenAPI requests
+            repository_id: Reconcile against one repository (and its clone)
+            application_id: Reconcile against one application's policies
+
+        Returns:
+            Reconciliation report plus per-target errors
+
+        Raises:
+            ValueError: If no targets are given
+        """
+        if not openapi_urls and not grpc_targets:
+            raise ValueError("Give at least one OpenAPI URL or gRPC target")
+
+        live: list[LiveEndpoint] = []
+        errors: dict[str, str] = {}
+        with httpx.Client() as client:
+            for url in openapi_urls or []:
+                try:
+                    ensure_host_allowed(urlparse(url).hostname, "live_discovery")
+                    live.extend(fetch_openapi(url, client, headers))
+                except Exception as e:
+                    errors[url] = str(e)
+        for target in grpc_targets or []:
+            try:
+                ensure_host_allowed(target.rsplit(":", 1)[0], "live_discovery")
+                live.extend(fetch_grpc_methods(target, use_tls=grpc_tls))
+            except Exception as e:
+                errors[target] = str(e)
+
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+            repository_id=repository_id, application_id=application_id
+        )
+        source_routes = None
+        if repository_id is not None and (self.clone_dir / str(repository_id)).is_dir():
+            source_routes = CoverageMetricsService.discover_routes(self.clone_dir / str(repository_id))
+
+        report = reconcile(live, policies, source_routes)
+        logger.info(
+            "live_endpoints_discovered",
+            live=len(live),
+            never_seen=report["counts"][LiveEndpointStatus.NEVER_SEEN],
+            errors=len(errors),
+            tenant_id=self.tenant_id,
+        )
+        return {**report, "source_routes_available": source_routes is not None, "errors": errors}
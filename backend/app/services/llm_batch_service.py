@@ -0,0 +1,334 @@
+"""Batched LLM processing with rate limiting and retry handling.
+
+Large scans send hundreds of prompts to the LLM provider. This module wraps a
+provider so calls are parallelized within a configurable concurrency limit,
+paced to the provider's requests-per-minute quota, and retried with
+exponential backoff when the provider throttles (429), is overloaded (529) or
+fails with another server error, or the connection to it fails.
+"""
+
+import asyncio
+import random
+import time
+from dataclasses import dataclass
+from functools import lru_cache
+
+import structlog
+
+from app.core.config import settings
+from app.services.llm_provider import LLMProvider
+
+logger = structlog.get_logger(__name__)
+
+# Throttling; every 5xx (including 529 overloaded) is a server-side problem worth retrying
+THROTTLED_STATUS_CODE = 429
+
+
+@lru_cache(maxsize=1)
+def _transport_error_types() -> tuple[type[BaseException], ...]:
+    """Exception types the provider SDKs raise when no response was received.
+
+    SDKs that are not installed are skipped.
+    """
+    types: list[type[BaseException]] = [TimeoutError, ConnectionError]
+    try:
+        from botocore.exceptions import ConnectionError as BotocoreConnectionError
+
+        types.append(BotocoreConnectionError)  # Endpoint, connect, and read timeout errors
+    except ImportError:
+        pass
+    try:
+        import httpx
+
+        types.append(httpx.TransportError)
+    except ImportError:
+        pass
+    for module in ("openai", "anthropic"):
+        try:
+            types.append(__import__(module).APIConnectionError)  # Includes APITimeoutError
+        except (ImportError, AttributeError):
+            pass
+    return tuple(types)
+
+
+def _get_status_code(error: Exception) -> int | None:
+    """Best-effort extraction of an HTTP status code from an SDK exception.
+
+    Args:
+        error: Exception raised by the provider SDK
+
+    Returns:
+        Status code or None if not available
+    """
+    for attr in ("status_code", "status", "http_status"):
+        value = getattr(error, attr, None)
+        if isinstance(value, int):
+            return value
+
+    response = getattr(error, "response", None)
+    if response is not None:
+        # botocore ClientError stores a dict response
+        if isinstance(response, dict):
+            code = response.get("ResponseMetadata", {}).get("HTTPStatusCode")
+            if isinstance(code, int):
+                return code
+        else:
+            code = getattr(response, "status_code", None)
+            if isinstance(code, int):
+                return code
+
+    return None
+
+
+def is_retryable_llm_error(error: Exception) -> bool:
+    """Check whether a provider error is transient and worth retrying.
+
+    Args:
+        error: Exception raised by the provider
+
+    Returns:
+        True for 429 and 5xx responses, and for connection failures and
+        timeouts that produced no response
+    """
+    status_code = _get_status_code(error)
+    if status_code is not None:
+        return status_code == THROTTLED_STATUS_CODE or 500 <= status_code <= 599
+    return isinstance(error, _transport_error_types())
+
+
+def get_retry_after_seconds(error: Exception) -> float | None:
+    """Read a Retry-After hint from a provider error, if present.
+
+    Args:
+        error: Exception raised by the provider
+
+    Returns:
+        Seconds to wait, or None if the provider gave no hint
+    """
+    response = getattr(error, "response", None)
+    headers = getattr(response, "headers", None)
+    if not headers:
+        return None
+
+    value = headers.get("retry-after") or headers.get("Retry-After")
+    if value is None:
+        return None
+
+    try:
+        return max(0.0, float(value))
+    except (TypeError, ValueError):
+        return None
+
+
+class RateLimiter:
+    """Async limiter that spaces requests to stay under a per-minute quota."""
+
+    def __init__(self, requests_per_minute: int):
+        """Initialize rate limiter.
+
+        Args:
+            requests_per_minute: Maximum requests per minute (0 disables limiting)
+        """
+        self.min_interval = 60.0 / requests_per_minute if requests_per_minute > 0 else 0.0
+        self._next_slot = 0.0
+        self._lock = asyncio.Lock()
+
+    async def acquire(self) -> None:
+        """Wait until the next request slot is available."""
+        if self.min_interval <= 0:
+            return
+
+        async with self._lock:
+            now = time.monotonic()
+            wait = self._next_slot - now
+            self._next_slot = max(now, self._next_slot) + self.min_interval
+
+        if wait > 0:
+            await asyncio.sleep(wait)
+
+    def penalize(self, delay_seconds: float) -> None:
+        """Push back the next slot after the provider signalled throttling.
+
+        Args:
+            delay_seconds: Seconds all callers should back off
+        """
+        self._next_slot = max(self._next_slot, time.monotonic() + delay_seconds)
+
+
+@dataclass
+class LLMBatchResult:
+    """Outcome of a single prompt in a batch."""
+
+    index: int
+    response: str | None = None
+    error: str | None = None
+    attempts: int = 0
+    duration_ms: int = 0
+
+    @property
+    def succeeded(self) -> bool:
+        """Whether the prompt produced a response."""
+        return self.response is not None
+
+
+class LLMBatchProcessor:
+    """Runs prompts against an LLM provider with concurrency, pacing, and retries."""
+
+    def __init__(
+        self,
+        provider: LLMProvider,
+        max_concurrency: int | None = None,
+        requests_per_minute: int | None = None,
+        max_retries: int | None = None,
+        base_delay_seconds: float | None = None,
+        max_delay_seconds: float | None = None,
+    ):
+        """Initialize batch processor.
+
+        Args:
+            provider: LLM provider to call
+            max_concurrency: Parallel calls allowed (defaults to LLM_MAX_CONCURRENCY)
+            requests_per_minute: Provider quota (defaults to LLM_REQUESTS_PER_MINUTE)
+            max_retries: Retries for transient errors (defaults to LLM_MAX_RETRIES)
+            base_delay_seconds: Initial backoff delay
+            max_delay_seconds: Backoff ceiling
+        """
+        self.provider = provider
+        self.max_concurrency = max(1, max_concurrency or settings.LLM_MAX_CONCURRENCY)
+        self.max_retries = settings.LLM_MAX_RETRIES if max_retries is None else max_retries
+        self.base_delay_seconds = (
+            settings.LLM_RETRY_BASE_DELAY_SECONDS if base_delay_seconds is None else base_delay_seconds
+        )
+        self.max_delay_seconds = (
+            settings.LLM_RETRY_MAX_DELAY_SECONDS if max_delay_seconds is None else max_delay_seconds
+        )
+        self.rate_limiter = RateLimiter(
+            settings.LLM_REQUESTS_PER_MINUTE if requests_per_minute is None else requests_per_minute
+        )
+        self._semaphore = asyncio.Semaphore(self.max_concurrency)
+
+    def _backoff_delay(self, attempt: int, error: Exception) -> float:
+        """Compute the delay before the next retry.
+
+        Uses the provider's Retry-After hint when available, otherwise
+        exponential backoff with full jitter.
+
+        Args:
+            attempt: Retry attempt number (1-based)
+            error: The error that triggered the retry
+
+        Returns:
+            Delay in seconds
+        """
+        retry_after = get_retry_after_seconds(error)
+        if retry_after is not None:
+            return min(retry_after, self.max_delay_seconds)
+
+        exponential = self.base_delay_seconds * (2 ** (attempt - 1))
+        return random.uniform(0, min(exponential, self.max_delay_seconds))
+
+    async def create_message(
+        self, prompt: str, max_tokens: int = 4096, temperature: float = 0
+    ) -> tuple[str, int]:
+        """Call the provider for one prompt, retrying transient failures.
+
+        Args:
+            prompt: Prompt to send
+            max_tokens: Maximum tokens to generate
+            temperature: Sampling temperature
+
+        Returns:
+            Tuple of (response text, attempts used)
+
+        Raises:
+            Exception: The last provider error if retries are exhausted or the
+                error is not retryable
+        """
+        response, attempts, error = await self._call(prompt, max_tokens, temperature)
+        if error is not None:
+            raise error
+        return response, attempts
+
+    async def _call(
+        self, prompt: str, max_tokens: int, temperature: float
+    ) -> tuple[str | None, int, Exception | None]:
+        """Call the provider, retrying transient failures.
+
+        Returns:
+            Tuple of (response text, attempts used, error); the error is the
+            last provider error when the call failed, and None otherwise
+        """
+        attempt = 0
+        while True:
+            attempt += 1
+            await self.rate_limiter.acquire()
+            try:
+                async with self._semaphore:
+                    response = await asyncio.to_thread(
+                        self.provider.create_message,
+                        prompt=prompt,
+                        max_tokens=max_tokens,
+                        temperature=temperature,
+                    )
+                return response, attempt, None
+
+            except Exception as e:
+                if attempt > self.max_retries or not is_retryable_llm_error(e):
+                    logger.error(
+                        "llm_call_failed",
+                        attempts=attempt,
+                        retryable=is_retryable_llm_error(e),
+                        error=str(e),
+                    )
+                    return None, attempt, e
+
+                delay = self._backoff_delay(attempt, e)
+                self.rate_limiter.penalize(delay)
+                logger.warning(
+                    "llm_call_retrying",
+                    attempt=attempt,
+                    max_retries=self.max_retries,
+                    delay_seconds=round(delay, 2),
+                    error=str(e),
+                )
+                await asyncio.sleep(delay)
+
+    async def process_batch(
+        self, prompts: list[str], max_tokens: int = 4096, temperature: float = 0
+    ) -> list[LLMBatchResult]:
+        """Run a batch of prompts in parallel within the configured limits.
+
+        Failures are captured per prompt so one bad call never fails the batch.
+
+        Args:
+            prompts: Prompts to send
+            max_tokens: Maximum tokens per response
+            temperature: Sampling temperature
+
+        Returns:
+            Results in the same order as the prompts
+        """
+
+        async def run(index: int, prompt: str) -> LLMBatchResult:
+            call_start = time.monotonic()
+            response, attempts, error = await self._call(prompt, max_tokens, temperature)
+            return LLMBatchResult(
+                index=index,
+                response=response,
+                error=str(error) if error is not None else None,
+                attempts=attempts,
+                duration_ms=int((time.monotonic() - call_start) * 1000),
+            )
+
+        start = time.monotonic()
+        results = await asyncio.gather(*(run(i, p) for i, p in enumerate(prompts)))
+
+        failed = sum(1 for r in results if not r.succeeded)
+        logger.info(
+            "llm_batch_complete",
+            prompts=len(prompts),
+            failed=failed,
+            max_concurrency=self.max_concurrency,
+            duration_seconds=round(time.monotonic() - start, 2),
+        )
+        return list(results)
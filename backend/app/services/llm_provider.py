@@ -2,7 +2,9 @@
 import logging
 from abc import ABC, abstractmethod
 
+from app.core.air_gap import ensure_llm_allowed, is_air_gapped
 from app.core.config import settings
+from app.services.redaction_service import redact_for_egress
 
 logger = logging.getLogger(__name__)
 
@@ -74,6 +76,8 @@ class AWSBedrockProvider(LLMProvider):
         """
         import json
 
+        prompt = redact_for_egress(prompt, "llm")
+
         try:
             # Build request body for Anthropic Claude on Bedrock
             request_body = {
@@ -147,6 +151,7 @@ class AzureOpenAIProvider(LLMProvider):
         Returns:
             The LLM response text
         """
+        prompt = redact_for_egress(prompt, "llm")
         try:
             # Call Azure OpenAI API
             response = self.client.chat.completions.create(
@@ -197,6 +202,7 @@ class AnthropicProvider(LLMProvider):
         Returns:
             The LLM response text
         """
+        prompt = redact_for_egress(prompt, "llm")
         try:
             message = self.client.messages.create(
                 model="claude-sonnet-4-20250514",
@@ -215,15 +221,33 @@ class AnthropicProvider(LLMProvider):
             raise
 
 
+class AirGappedProvider(LLMProvider):
+    """Placeholder provider used in air-gapped mode; every call is refused."""
+
+    model_id = "disabled"
+
+    def create_message(self, prompt: str, max_tokens: int = 4096, temperature: float = 0) -> str:
+        """Refuse the call.
+
+        Raises:
+            AirGapViolation: Always
+        """
+        ensure_llm_allowed("llm_provider")
+        return ""
+
+
 def get_llm_provider() -> LLMProvider:
     """Get the configured LLM provider.
 
     Returns:
-        LLM provider instance
+        LLM provider instance (a provider that refuses every call in air-gapped mode)
 
     Raises:
         ValueError: If provider is not supported
     """
+    if is_air_gapped():
+        return AirGappedProvider()
+
     provider_name = settings.LLM_PROVIDER.lower()
 
     # Fallback to direct Anthropic API if ANTHROPIC_API_KEY is set
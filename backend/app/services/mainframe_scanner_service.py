@@ -7,6 +7,7 @@ from sqlalchemy.ext.asyncio import AsyncSession
 from app.models.policy import Policy, SourceType
 from app.models.repository import Repository
 from app.services.cobol_scanner_service import CobolScannerService
+from app.services.extraction_confidence import classify_evidence, method_confidence
 from app.services.llm_service import LLMService
 from app.services.risk_scoring_service import RiskScoringService
 
@@ -244,6 +245,8 @@ class MainframeScannerService:
                         "context": detail.get("context", ""),
                     })
 
+                extraction_method = classify_evidence([detail["text"] for detail in details])
+
                 # Create Policy
                 policy = Policy(
                     tenant_id=tenant_id,
@@ -260,7 +263,8 @@ class MainframeScannerService:
                     line_end=details[-1]["line_end"] if details else 1,
                     complexity_score=risk_scores["complexity_score"],
                     impact_score=risk_scores["impact_score"],
-                    confidence_score=risk_scores["confidence_score"],
+                    confidence_score=method_confidence(extraction_method, risk_scores["confidence_score"]),
+                    extraction_method=extraction_method,
                     risk_score=risk_scores["risk_score"],
                     risk_level=risk_scores["risk_level"],
                 )
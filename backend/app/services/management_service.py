@@ -0,0 +1,222 @@
+"""Service behind the management API used to configure the miner as code.
+
+The Terraform provider (terraform-provider-policyminer) and other automation
+manage four kinds of objects through it:
+
+- workspaces: tenants, managed by superusers; deleting one deactivates it
+  and keeps its data, and creating it again reactivates it;
+- repositories registered in the caller's workspace;
+- repository webhooks: whether pushes trigger scans, and the secret the Git
+  provider signs payloads with;
+- scan schedules (scan_schedule_service).
+
+Every object is read back with the same fields it is written with, so
+automation can detect drift; credentials in a repository's connection config
+and webhook secrets are write-only.
+"""
+
+import secrets
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.repository import Repository, RepositoryStatus
+from app.models.tenant import Tenant
+
+logger = structlog.get_logger(__name__)
+
+GITHUB_WEBHOOK_PATH = "/api/v1/webhooks/github"
+
+
+class ManagementService:
+    """Manages workspaces, repository registrations, and repository webhooks."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def list_workspaces(self) -> list[Tenant]:
+        """List active workspaces."""
+        return self.db.query(Tenant).filter(Tenant.is_active.is_(True)).order_by(Tenant.tenant_id).all()
+
+    def get_workspace(self, workspace_id: str) -> Tenant:
+        """Get an active workspace.
+
+        Raises:
+            ValueError: If the workspace does not exist or was deleted
+        """
+        tenant = self.db.query(Tenant).filter(Tenant.tenant_id == workspace_id, Tenant.is_active.is_(True)).first()
+        if tenant is None:
+            raise ValueError(f"Workspace {workspace_id} not found")
+        return tenant
+
+    def create_workspace(self, data: dict) -> Tenant:
+        """Create a workspace, or reactivate a deleted one with the same ID.
+
+        Args:
+            data: Workspace fields (workspace_id, name, description)
+
+        Returns:
+            Created workspace
+
+        Raises:
+            ValueError: If an active workspace has the ID
+        """
+        tenant = self.db.query(Tenant).filter(Tenant.tenant_id == data["workspace_id"]).first()
+        if tenant is not None and tenant.is_active:
+            raise ValueError(f"Workspace {data['workspace_id']} already exists")
+        if tenant is None:
+            tenant = Tenant(tenant_id=data["workspace_id"])
+            self.db.add(tenant)
+        tenant.name = data["name"]
+        tenant.description = data.get("description")
+        tenant.is_active = True
+        self.db.commit()
+        self.db.refresh(tenant)
+        logger.info("workspace_created", workspace_id=tenant.tenant_id)
+        return tenant
+
+    def update_workspace(self, workspace_id: str, data: dict) -> Tenant:
+        """Rename or redescribe a workspace.
+
+        Raises:
+            ValueError: If the workspace does not exist
+        """
+        tenant = self.get_workspace(workspace_id)
+        for name, value in data.items():
+            setattr(tenant, name, value)
+        self.db.commit()
+        self.db.refresh(tenant)
+        return tenant
+
+    def delete_workspace(self, workspace_id: str) -> None:
+        """Deactivate a workspace; its repositories, policies, and users are kept.
+
+        Raises:
+            ValueError: If the workspace does not exist
+        """
+        tenant = self.get_workspace(workspace_id)
+        tenant.is_active = False
+        self.db.commit()
+        logger.info("workspace_deactivated", workspace_id=workspace_id)
+
+    def list_repositories(self) -> list[Repository]:
+        """List the workspace's repositories."""
+        return self._query(Repository).order_by(Repository.id).all()
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the workspace.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def create_repository(self, data: dict) -> Repository:
+        """Register a repository in the workspace.
+
+        Args:
+            data: Repository fields (name, description, repository_type,
+                source_url, connection_config)
+
+        Returns:
+            Created repository, pending its first connection check
+        """
+        repository = Repository(tenant_id=self.tenant_id, status=RepositoryStatus.PENDING, **data)
+        self.db.add(repository)
+        self.db.commit()
+        self.db.refresh(repository)
+        logger.info("repository_registered", repository_id=repository.id, tenant_id=self.tenant_id)
+        return repository
+
+    def update_repository(self, repository_id: int, data: dict) -> Repository:
+        """Change a repository's registration; a connection config of None leaves the stored one.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        if data.get("connection_config") is None:
+            data = {k: v for k, v in data.items() if k != "connection_config"}
+        for name, value in data.items():
+            setattr(repository, name, value)
+        self.db.commit()
+        self.db.refresh(repository)
+        return repository
+
+    def delete_repository(self, repository_id: int) -> None:
+        """Deregister a repository.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        self.db.delete(self.get_repository(repository_id))
+        self.db.commit()
+        logger.info("repository_deregistered", repository_id=repository_id, tenant_id=self.tenant_id)
+
+    @staticmethod
+    def webhook_config(repository: Repository, secret: str | None = None) -> dict:
+        """A repository's webhook settings; the secret only when it was just set or generated."""
+        return {
+            "repository_id": repository.id,
+            "enabled": bool(repository.webhook_enabled),
+            "has_secret": bool(repository.webhook_secret),
+            "webhook_url": GITHUB_WEBHOOK_PATH,
+            "secret": secret,
+        }
+
+    def get_webhook(self, repository_id: int) -> dict:
+        """Get a repository's webhook settings.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        return self.webhook_config(self.get_repository(repository_id))
+
+    def configure_webhook(self, repository_id: int, enabled: bool, secret: str | None = None) -> dict:
+        """Turn a repository's push webhook on or off and set its secret.
+
+        Args:
+            repository_id: Repository ID
+            enabled: Whether pushes trigger scans
+            secret: Signing secret; one is generated when neither this nor a
+                stored secret is set
+
+        Returns:
+            Webhook settings, with the secret when it was set or generated
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        if secret is None and not repository.webhook_secret:
+            secret = secrets.token_urlsafe(32)
+        if secret is not None:
+            repository.webhook_secret = secret
+        repository.webhook_enabled = enabled
+        self.db.commit()
+        self.db.refresh(repository)
+        logger.info("webhook_configured", repository_id=repository.id, enabled=enabled)
+        return self.webhook_config(repository, secret)
+
+    def delete_webhook(self, repository_id: int) -> None:
+        """Turn a repository's webhook off and forget its secret.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        repository.webhook_enabled = False
+        repository.webhook_secret = None
+        self.db.commit()
@@ -0,0 +1,604 @@
+"""Service for detecting mass assignment of privileged model fields.
+
+A handler that binds the request body straight onto a model, e.g.
+json.NewDecoder(r.Body).Decode(&expense), User.create(req.body),
+Model.objects.create(**request.data), or @RequestBody User user followed by
+save(user), lets the caller set every field the model has, including the
+ones that decide what the object may do: its role, is_admin, or an Approved
+flag the approval endpoint is meant to set. This service indexes the
+bindable fields of the models declared in a clone (Go structs, Django,
+SQLAlchemy, and Pydantic models, Mongoose and Sequelize schemas, TypeScript,
+Java, and C# classes, Rails schema.rb tables, and Laravel $fillable lists),
+leaves out fields excluded from binding (json:"-", @JsonIgnore, [BindNever],
+DRF read_only_fields) or overwritten after the bind, and reports the
+binds that still reach a privileged field.
+"""
+
+import re
+from collections.abc import Callable, Iterator
+from dataclasses import asdict, dataclass, field
+from pathlib import Path, PurePosixPath
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.repository import Repository
+from app.services.bola_detection_service import (
+    ADMIN_ROLE,
+    HEADER_LINES,
+    SEVERITY_ORDER,
+    _handler_end,
+    _handler_name,
+    _next_handler,
+)
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, SKIPPED_DIRECTORIES
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import MAX_LINE_LENGTH, group
+
+logger = structlog.get_logger(__name__)
+
+# Model bodies are read at most this many lines past their declaration
+MAX_MODEL_LINES = 200
+
+# Fields granting privileges, and fields recording decisions or ownership the server should make
+PRIVILEGE_FIELDS = {
+    "role", "roles", "userrole", "admin", "isadmin", "superuser", "issuperuser", "staff", "isstaff", "permissions",
+    "permission", "privileges", "privilege", "scopes", "accesslevel", "accounttype", "usertype", "groups",
+}  # fmt: skip
+DECISION_FIELDS = {
+    "approved", "isapproved", "approvedby", "approvedat", "approver", "verified", "isverified", "emailverified",
+    "ownerid", "tenantid", "orgid", "organizationid", "createdby",
+}  # fmt: skip
+
+# Model declarations per language
+GO_STRUCT = re.compile(r"^type\s+([A-Z]\w*)\s+struct\s*\{\s*$")
+GO_FIELD = re.compile(r"^\s*([A-Z]\w*)\s+[\w.*\[\]]+(?:\s+`([^`\n]*)`)?")
+GO_JSON_TAG = re.compile(r"""\bjson:"([^",]*)""")
+PYTHON_MODEL = re.compile(
+    r"^class\s+(\w+)\s*\(\s*(?:[\w.]*\b(?:Model|Base|BaseModel|SQLModel|Document|DeclarativeBase|Schema)\b)[^)\n]*\)\s*:"
+)
+PYTHON_FIELD = re.compile(r"^\s+(\w+)\s*(?::\s*[^=\n]+?)?\s*=\s*(?:\w+\s*\.\s*)*\w*(?:Field|Column|column|mapped_column)\b|^\s+(\w+)\s*:\s*[\w\[\], |.\"']+(?:=.*)?$")
+SERIALIZER = re.compile(r"^class\s+(\w+)\s*\(\s*(?:serializers\s*\.\s*)?(?:Hyperlinked)?ModelSerializer\s*\)\s*:")
+SERIALIZER_MODEL = re.compile(r"^\s+model\s*=\s*([\w.]+)")
+SERIALIZER_LIST = re.compile(r"""^\s+(fields|read_only_fields|exclude)\s*=\s*(['"]__all__['"]|[\[(][^\])]*[\])])""")
+MONGOOSE_SCHEMA = re.compile(r"\b(\w+)\s*=\s*new\s+(?:mongoose\s*\.\s*)?Schema\s*(?:<[^>\n]*>)?\s*\(\s*\{")
+MONGOOSE_MODEL = re.compile(r"""\bmodel\s*(?:<[^>\n]*>)?\s*\(\s*['"](\w+)['"]\s*,\s*(\w+)""")
+SEQUELIZE_DEFINE = re.compile(r"""\.\s*define\s*\(\s*['"](\w+)['"]\s*,\s*\{""")
+SEQUELIZE_INIT = re.compile(r"\b([A-Z]\w*)\s*\.\s*init\s*\(\s*\{")
+JS_KEY = re.compile(r"""^\s*['"]?([A-Za-z_$][\w$]*)['"]?\s*:""")
+CLASS = re.compile(r"^\s*(?:export\s+)?(?:public\s+|internal\s+)?(?:abstract\s+|partial\s+|sealed\s+|data\s+)*(?:class|interface)\s+([A-Z]\w*)")
+TYPESCRIPT_FIELD = re.compile(r"^\s*(?:@\w+\([^)\n]*\)\s*)*(?:(?:public|private|protected|readonly)\s+)*([A-Za-z_$][\w$]*)[?!]?\s*:\s*[^;(){}\n]+;?\s*$")
+JAVA_FIELD = re.compile(r"^\s*(?:@[\w.]+(?:\([^)\n]*\))?\s*)*(?:private|protected|public)\s+(?!static\b)(?:final\s+)?[\w<>\[\], .?]+?\s+(\w+)\s*(?:=[^;\n]*)?;")
+CSHARP_PROPERTY = re.compile(r"^\s*(?:\[[^\]\n]*\]\s*)*public\s+(?:virtual\s+|required\s+)*[\w<>\[\]?, .]+?\s+(\w+)\s*\{\s*get;\s*((?:private|protected|internal|init)\s+)?(?:set|init);")
+BIND_EXCLUSION = re.compile(
+    r"@JsonIgnore\b|JsonProperty\s*\([^)]*READ_ONLY|\[\s*(?:JsonIgnore|BindNever|Editable\s*\(\s*false\s*\))|\bReadOnly\s*\(\s*true\s*\)"
+)
+# Annotations leaving a field out of serialized responses
+OUTPUT_EXCLUSION = re.compile(r"@JsonIgnore\b|JsonProperty\s*\([^)]*WRITE_ONLY|\[\s*JsonIgnore\b|@Exclude\b|@Transient\b")
+RAILS_TABLE = re.compile(r"""^\s*create_table\s+["'](\w+)["']""")
+RAILS_COLUMN = re.compile(r"""^\s*t\.\w+\s+["'](\w+)["']""")
+LARAVEL_MODEL = re.compile(r"class\s+(\w+)\s+extends\s+(?:Model|Authenticatable|\w*Model)\b")
+LARAVEL_FILLABLE = re.compile(r"\$fillable\s*=\s*(?:\[|array\s*\()([^\])]*)")
+STRING_ITEM = re.compile(r"""['"](\w+)['"]""")
+
+# Binds of the request body, with the model or variable they write to
+REQUEST_BODY = r"(?:req|request|ctx\s*\.\s*request|c\s*\.\s*req)\s*\.\s*body"
+PYTHON_BODY = r"(?:request\s*\.\s*(?:data|json|form|POST|get_json\s*\(\s*\))|(?:payload|body|data)\s*\.\s*(?:dict|model_dump)\s*\(\s*\)|payload|body)"
+GO_BIND = re.compile(
+    r"(?:\.\s*Decode|json\s*\.\s*Unmarshal\s*\([^,\n]*,|\.\s*(?:ShouldBind(?:JSON|With|BodyWith)?|BindJSON|Bind))\s*\(\s*&?\s*([A-Za-z_]\w*)\s*[,)]"
+)
+GO_DECLARATION = r"(?:var\s+{name}\s+\*?([\w.]+)|{name}\s*:?=\s*&?([\w.]+)\s*\{{|{name}\s*:?=\s*new\s*\(\s*([\w.]+)\s*\))"
+JS_CREATE = re.compile(
+    rf"\b([A-Z]\w*)\s*\.\s*(create|insertMany|build|bulkCreate|findByIdAndUpdate|findOneAndUpdate|updateOne|updateMany|update|upsert|save)"
+    rf"\s*\((?:[^()\n]*,\s*)?(?:{REQUEST_BODY}|\{{\s*\.\.\.\s*{REQUEST_BODY})\s*[,)}}]"
+    rf"|\bnew\s+([A-Z]\w*)\s*\(\s*(?:{REQUEST_BODY}|\{{\s*\.\.\.\s*{REQUEST_BODY})\s*[,)}}]"
+)
+JS_ASSIGN = re.compile(
+    rf"\b(?:Object\s*\.\s*assign|_\s*\.\s*(?:merge|extend|assign))\s*\(\s*([A-Za-z_$][\w$]*)\s*,\s*{REQUEST_BODY}\s*\)"
+    rf"|\b([A-Za-z_$][\w$]*)\s*\.\s*(?:set|merge)\s*\(\s*{REQUEST_BODY}\s*\)"
+)
+PYTHON_CREATE = re.compile(
+    rf"\b([A-Z]\w*)(?:\s*\.\s*objects(?:\s*\.\s*filter\s*\([^()\n]*\))?)?\s*\.\s*(create|update|update_or_create|get_or_create)?\s*\(\s*\*\*\s*{PYTHON_BODY}\s*\)"
+    rf"|\b([A-Z]\w*)\s*\(\s*\*\*\s*{PYTHON_BODY}\s*\)"
+)
+PYTHON_SETATTR = re.compile(r"\bsetattr\s*\(\s*(\w+)\s*,\s*\w+\s*,|\b(\w+)\s*\.\s*__dict__\s*\.\s*update\s*\(")
+PYTHON_BODY_LOOP = re.compile(rf"\bfor\s+\w+\s*,\s*\w+\s+in\s+{PYTHON_BODY}\s*\.\s*items\s*\(")
+RUBY_BIND = re.compile(
+    r"\b([A-Z]\w*|@?[a-z_]\w*)\s*\.\s*(new|create!?|update!?|update_attributes!?|assign_attributes)\s*\(?\s*params(?:\s*\[\s*:\w+\s*\])?\s*\)?\s*$"
+    r"|\bparams(?:\s*\.\s*require\s*\(\s*:(\w+)\s*\))?\s*\.\s*permit!"
+)
+JVM_BODY_PARAMETER = re.compile(r"(?:@RequestBody|@Valid|@Body|\[FromBody\])\s+(?:@\w+\s+)*(?:final\s+)?([A-Z][\w.]*)\s+(\w+)")
+JVM_PERSIST = r"\.\s*(?:save|saveAndFlush|saveAll|persist|merge|update|Add|AddAsync|Update|Attach)\s*\(\s*{name}\s*\)"
+PHP_BIND = re.compile(
+    r"\b([A-Z]\w*)\s*::\s*(create|forceCreate|updateOrCreate)\s*\(\s*\$request\s*->\s*(?:all|input)\s*\(\s*\)"
+    r"|\$(\w+)\s*->\s*(update|fill|forceFill)\s*\(\s*\$request\s*->\s*(?:all|input)\s*\(\s*\)"
+)
+# Fields removed from the body before it is bound
+DELETED = re.compile(rf"\bdelete\s+{REQUEST_BODY}\s*\.\s*(\w+)|\.\s*pop\s*\(\s*['\"](\w+)['\"]")
+# Variables loaded from a model: user = User.findById(...), @user = User.find(...), $user = User::find(...)
+LOADED_FROM = r"{name}\s*=\s*(?:await\s+)?(?:get_object_or_404\s*\(\s*)?([A-Z]\w*)\b"
+
+
+@dataclass
+class ModelFields:
+    """A model declared in a clone and its privileged fields a request can bind."""
+
+    name: str
+    file_path: str
+    line: int
+    fields: list[str] = field(default_factory=list)
+    excluded: list[str] = field(default_factory=list)  # privileged fields excluded from binding
+
+
+@dataclass
+class MassAssignmentCandidate:
+    """A bind of the request body onto a model whose privileged fields it can set."""
+
+    file_path: str
+    function: str | None  # None for anonymous handlers
+    line_start: int
+    line_end: int
+    model: str
+    model_file: str
+    fields: list[str]
+    sink: str
+    endpoint: str | None
+    severity: str
+    description: str
+
+
+def _normalized(name: str) -> str:
+    """Field name compared regardless of case and separators."""
+    return re.sub(r"[^a-z0-9]", "", name.lower())
+
+
+def _privileged(name: str) -> bool:
+    """Whether a field grants privileges or records a server-side decision."""
+    return _normalized(name) in PRIVILEGE_FIELDS | DECISION_FIELDS
+
+
+def _block(lines: list[str], start: int, closing: str = "}") -> list[tuple[int, str]]:
+    """Numbered lines of a declaration body, up to its closing line or the line limit."""
+    body = []
+    for index in range(start + 1, min(len(lines), start + 1 + MAX_MODEL_LINES)):
+        if lines[index].startswith(closing):
+            break
+        body.append((index, lines[index]))
+    return body
+
+
+def _excluded(lines: list[str], index: int, exclusion: re.Pattern) -> bool:
+    """Whether a field declaration, or the annotation lines just above it, carry an exclusion."""
+    first = index
+    while first > max(0, index - 4) and lines[first - 1].strip().startswith(("@", "[")):
+        first -= 1
+    return any(exclusion.search(line) for line in lines[first : index + 1])
+
+
+def _model(name: str, file_path: str, line: int, declared: list[tuple[str, bool]], keep: Callable[[str], bool]) -> ModelFields | None:
+    """A model from its declared (field, excluded) pairs, or None if none is kept."""
+    model = ModelFields(name, file_path, line)
+    for field_name, excluded in declared:
+        if keep(field_name):
+            target = model.excluded if excluded else model.fields
+            if field_name not in target:
+                target.append(field_name)
+    return model if model.fields or model.excluded else None
+
+
+def _singular(table: str) -> str:
+    """Model name of a Rails table, e.g. order_items -> OrderItem."""
+    name = re.sub(r"ies$", "y", table) if table.endswith("ies") else re.sub(r"s$", "", table)
+    return "".join(part.capitalize() for part in name.split("_"))
+
+
+def _python_models(file_path: str, lines: list[str], keep: Callable[[str], bool]) -> list[ModelFields]:
+    """Django, SQLAlchemy, and Pydantic models."""
+    models = []
+    for index, line in enumerate(lines):
+        declared = PYTHON_MODEL.match(line)
+        if declared:
+            fields = []
+            for _, body in _indented(lines, index):
+                match = PYTHON_FIELD.match(body)
+                if match:
+                    fields.append((match.group(1) or match.group(2), False))
+            model = _model(declared.group(1), file_path, index + 1, fields, keep)
+            if model:
+                models.append(model)
+    return models
+
+
+def _indented(lines: list[str], start: int) -> list[tuple[int, str]]:
+    """Numbered lines of a Python class body."""
+    body = []
+    for index in range(start + 1, min(len(lines), start + 1 + MAX_MODEL_LINES)):
+        line = lines[index]
+        if line.strip() and not line[0].isspace():
+            break
+        body.append((index, line))
+    return body
+
+
+def _js_keys(text: str, opening: int) -> list[str]:
+    """Top-level keys of the object literal opened at an offset."""
+    block = text[opening + 1 : group(text, opening) - 1]
+    keys, depth = [], 0
+    for line in block.split("\n"):
+        key = JS_KEY.match(line)
+        if key and depth == 0:
+            keys.append(key.group(1))
+        depth += line.count("{") + line.count("[") - line.count("}") - line.count("]")
+    return keys
+
+
+def parse_models(file_path: str, text: str, keep: Callable[[str], bool] = _privileged, output: bool = False) -> list[ModelFields]:
+    """Models a source file declares that have privileged fields.
+
+    Args:
+        file_path: Relative path of the file
+        text: File content
+        keep: Which fields to report, privileged ones by default
+        output: Read exclusions from serialized responses instead of from binding
+
+    Returns:
+        Models with their bindable (or serialized) and excluded kept fields
+    """
+    exclusion = OUTPUT_EXCLUSION if output else BIND_EXCLUSION
+    if not any(keep(word) for word in set(re.findall(r"\w+", text[: 200_000]))):
+        return []
+    suffix = PurePosixPath(file_path).suffix
+    lines = text.split("\n")
+    models: list[ModelFields] = []
+
+    if suffix == ".go":
+        for index, line in enumerate(lines):
+            declared = GO_STRUCT.match(line)
+            if not declared:
+                continue
+            fields = []
+            for _, body in _block(lines, index):
+                match = GO_FIELD.match(body)
+                if match:
+                    tag = GO_JSON_TAG.search(match.group(2) or "")
+                    name = tag.group(1) if tag and tag.group(1) else match.group(1)
+                    fields.append((match.group(1) if name == "-" else name, name == "-"))
+            model = _model(declared.group(1), file_path, index + 1, fields, keep)
+            if model:
+                models.append(model)
+    elif suffix == ".py":
+        models.extend(_python_models(file_path, lines, keep))
+    elif suffix in (".js", ".ts", ".jsx", ".tsx"):
+        schemas = {m.group(1): m for m in MONGOOSE_SCHEMA.finditer(text)} if "Schema" in text else {}
+        for match in MONGOOSE_MODEL.finditer(text):
+            schema = schemas.get(match.group(2))
+            if schema:
+                line = text.count("\n", 0, schema.start()) + 1
+                model = _model(match.group(1), file_path, line, [(k, False) for k in _js_keys(text, schema.end() - 1)], keep)
+                if model:
+                    models.append(model)
+        for pattern in (SEQUELIZE_DEFINE, SEQUELIZE_INIT) if "define" in text or "init" in text else ():
+            for match in pattern.finditer(text):
+                line = text.count("\n", 0, match.start()) + 1
+                model = _model(match.group(1), file_path, line, [(k, False) for k in _js_keys(text, match.end() - 1)], keep)
+                if model:
+                    models.append(model)
+        if suffix in (".ts", ".tsx"):
+            models.extend(_class_models(file_path, lines, TYPESCRIPT_FIELD, keep, exclusion))
+    elif suffix in (".java", ".kt", ".scala"):
+        models.extend(_class_models(file_path, lines, JAVA_FIELD, keep, exclusion))
+    elif suffix == ".cs":
+        models.extend(_class_models(file_path, lines, CSHARP_PROPERTY, keep, exclusion))
+    elif suffix == ".rb" and PurePosixPath(file_path).name == "schema.rb":
+        for index, line in enumerate(lines):
+            table = RAILS_TABLE.match(line)
+            if table:
+                columns = [(c.group(1), False) for _, body in _block(lines, index, "  end") for c in [RAILS_COLUMN.match(body)] if c]
+                model = _model(_singular(table.group(1)), file_path, index + 1, columns, keep)
+                if model:
+                    models.append(model)
+    elif suffix == ".php":
+        declared = LARAVEL_MODEL.search(text)
+        fillable = LARAVEL_FILLABLE.search(text)
+        if declared and fillable:
+            line = text.count("\n", 0, fillable.start()) + 1
+            model = _model(declared.group(1), file_path, line, [(f, False) for f in STRING_ITEM.findall(fillable.group(1))], keep)
+            if model:
+                models.append(model)
+    return models
+
+
+def _class_models(
+    file_path: str, lines: list[str], member: re.Pattern, keep: Callable[[str], bool], exclusion: re.Pattern
+) -> list[ModelFields]:
+    """Classes with kept members, reading members until the next class."""
+    models = []
+    starts = [i for i, line in enumerate(lines) if len(line) <= MAX_LINE_LENGTH and CLASS.match(line)]
+    for position, start in enumerate(starts):
+        end = starts[position + 1] if position + 1 < len(starts) else len(lines)
+        fields = []
+        for index in range(start + 1, min(end, start + 1 + MAX_MODEL_LINES)):
+            match = member.match(lines[index]) if len(lines[index]) <= MAX_LINE_LENGTH else None
+            if match:
+                # A non-public setter keeps a property out of binding, not out of responses
+                restricted = member is CSHARP_PROPERTY and bool(match.group(2)) and exclusion is BIND_EXCLUSION
+                fields.append((match.group(1), restricted or _excluded(lines, index, exclusion)))
+        model = _model(CLASS.match(lines[start]).group(1), file_path, start + 1, fields, keep)
+        if model:
+            models.append(model)
+    return models
+
+
+def serializer_candidates(file_path: str, text: str, models: dict[str, ModelFields]) -> list[MassAssignmentCandidate]:
+    """DRF ModelSerializers writing every field of a model with privileged fields.
+
+    Args:
+        file_path: Relative path of the file
+        text: File content
+        models: Indexed models by name
+
+    Returns:
+        One candidate per serializer that leaves a privileged field writable
+    """
+    candidates = []
+    lines = text.split("\n")
+    for index, line in enumerate(lines):
+        declared = SERIALIZER.match(line)
+        if not declared:
+            continue
+        target, lists, fields_line = None, {}, index
+        for number, body in _indented(lines, index):
+            model = SERIALIZER_MODEL.match(body)
+            if model:
+                target = models.get(model.group(1).split(".")[-1])
+            listed = SERIALIZER_LIST.match(body)
+            if listed:
+                lists[listed.group(1)] = listed.group(2)
+                if listed.group(1) == "fields":
+                    fields_line = number
+        if target is None or not lists.get("fields") and not lists.get("exclude"):
+            continue
+        writable = [
+            f
+            for f in target.fields
+            if ("__all__" in lists.get("fields", "__all__") or re.search(rf"['\"]{f}['\"]", lists["fields"]))
+            and not re.search(rf"['\"]{f}['\"]", lists.get("read_only_fields", "") + lists.get("exclude", ""))
+        ]
+        if writable:
+            candidates.append(
+                _candidate(
+                    file_path,
+                    declared.group(1),
+                    fields_line + 1,
+                    target,
+                    writable,
+                    "ModelSerializer",
+                    None,
+                    admin_only=False,
+                    subject=f"{declared.group(1)} writes every listed field of {target.name}",
+                )
+            )
+    return candidates
+
+
+def _candidate(
+    file_path: str,
+    function: str | None,
+    line: int,
+    model: ModelFields,
+    fields: list[str],
+    sink: str,
+    endpoint: str | None,
+    admin_only: bool,
+    subject: str | None = None,
+) -> MassAssignmentCandidate:
+    """A candidate with its severity and description."""
+    privileges = [f for f in fields if _normalized(f) in PRIVILEGE_FIELDS]
+    severity = "low" if admin_only else "high" if privileges else "medium"
+    handler = function or (f"Handler for {endpoint}" if endpoint else "Handler")
+    description = (
+        (subject or f"{handler} binds the request body to {model.name} with {sink}() and no field filtering")
+        + f", so a caller can set {', '.join(fields)}"
+        + (" (restricted to administrators)" if admin_only else "")
+    )
+    return MassAssignmentCandidate(
+        file_path, function, line, line, model.name, model.file_path, fields, sink, endpoint, severity, description
+    )
+
+
+def _bound_model(body: list[str], name: str) -> str | None:
+    """Model a handler variable holds: its Go type, or the model it was loaded from."""
+    if name[:1].isupper():
+        return name
+    bare = name.lstrip("@$")
+    declaration = re.compile(GO_DECLARATION.format(name=re.escape(bare)))
+    loaded = re.compile(LOADED_FROM.format(name=re.escape(name)))
+    for line in body:
+        found = declaration.search(line) or loaded.search(line)
+        if found:
+            return next(g for g in found.groups() if g).split(".")[-1]
+    return bare[:1].upper() + bare[1:]
+
+
+def _sinks(lines: list[str], start: int, end: int) -> list[tuple[int, str, str]]:
+    """(line index, model or variable, sink) of request body binds in a handler."""
+    sinks = []
+    loop = False
+    for index in range(start, end):
+        line = lines[index]
+        if len(line) > MAX_LINE_LENGTH:
+            continue
+        loop = loop or bool(PYTHON_BODY_LOOP.search(line))
+        for match in GO_BIND.finditer(line):
+            sinks.append((index, match.group(1), "Decode" if "Decode" in match.group(0) else match.group(0).split("(")[0].split(".")[-1].strip()))
+        for match in JS_CREATE.finditer(line):
+            sinks.append((index, match.group(1) or match.group(3), match.group(2) or "new"))
+        for match in JS_ASSIGN.finditer(line):
+            sinks.append((index, match.group(1) or match.group(2), "Object.assign" if match.group(1) else "set"))
+        for match in PYTHON_CREATE.finditer(line):
+            sinks.append((index, match.group(1) or match.group(3), match.group(2) or "constructor"))
+        if loop:
+            for match in PYTHON_SETATTR.finditer(line):
+                sinks.append((index, match.group(1) or match.group(2), "setattr"))
+        for match in RUBY_BIND.finditer(line):
+            if match.group(3) is not None or "permit!" in match.group(0):
+                sinks.append((index, _singular(match.group(3)) if match.group(3) else "", "permit!"))
+            else:
+                sinks.append((index, match.group(1), match.group(2)))
+        for match in PHP_BIND.finditer(line):
+            sinks.append((index, match.group(1) or f"${match.group(3)}", match.group(2) or match.group(4)))
+    return sinks
+
+
+def detect_mass_assignment(file_path: str, text: str, models: dict[str, ModelFields]) -> list[MassAssignmentCandidate]:
+    """Detect request body binds onto models with privileged fields in a source file.
+
+    Args:
+        file_path: Relative path of the file
+        text: File content
+        models: Indexed models by name (see parse_models)
+
+    Returns:
+        Candidates in file order
+    """
+    candidates = serializer_candidates(file_path, text, models) if file_path.endswith(".py") else []
+    if not models:
+        return candidates
+    lines = text.split("\n")
+    start = _next_handler(lines, 0)
+    while start is not None:
+        end = _handler_end(lines, start)
+        body = lines[start:end]
+        header = "\n".join(lines[max(0, start - HEADER_LINES) : start])
+        sinks = _sinks(lines, start, end)
+        for match in JVM_BODY_PARAMETER.finditer(header + "\n" + lines[start]):
+            persist = re.compile(JVM_PERSIST.format(name=re.escape(match.group(2))))
+            for offset, line in enumerate(body):
+                if persist.search(line):
+                    sinks.append((start + offset, match.group(1).split(".")[-1], persist.search(line).group(0).split("(")[0].strip(". ")))
+        if sinks:
+            scope = header + "\n" + "\n".join(body)
+            routes = EndpointMappingService.find_routes(header + "\n" + lines[start])
+            endpoint = f"{routes[0][0]} {routes[0][1]}" if routes else None
+            function = _handler_name(lines[start])
+            admin_only = bool(ADMIN_ROLE.search(scope))
+            for index, name, sink in sinks:
+                model = models.get(_bound_model(body, name) if name else "")
+                if model is None:
+                    continue
+                variable = name.lstrip("@$") if not name[:1].isupper() else None
+                # Fields the handler deletes from the body or overwrites after the bind stay server-controlled
+                controlled = {_normalized(a or b) for a, b in DELETED.findall(scope)}
+                if variable:
+                    assigned = re.compile(rf"\b{re.escape(variable)}\s*(?:\.|->)\s*(\w+)\s*=(?!=)")
+                    controlled |= {_normalized(f) for f in assigned.findall("\n".join(lines[index:end]))}
+                fields = [f for f in model.fields if _normalized(f) not in controlled]
+                if fields:
+                    candidates.append(
+                        _candidate(file_path, function, index + 1, model, fields, sink, endpoint, admin_only)
+                    )
+        start = _next_handler(lines, end)
+    return candidates
+
+
+def mass_assignment_candidates(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the mass assignment candidates across a repository clone.
+
+    Models are indexed across the whole clone first, since handlers
+    usually bind to models declared in another package.
+    """
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    sources: dict[str, str] = {}
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in ROUTE_FILE_EXTENSIONS:
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        sources[relative.as_posix()] = path.read_text(encoding="utf-8", errors="ignore")
+
+    models: dict[str, ModelFields] = {}
+    for path, text in sources.items():
+        for model in parse_models(path, text):
+            models.setdefault(model.name, model)
+    for path, text in sources.items():
+        yield from (asdict(c) for c in detect_mass_assignment(path, text, models))
+
+
+class MassAssignmentService:
+    """Scans repository clones for request body binds onto privileged model fields."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[MassAssignmentCandidate]:
+        """Detect mass assignment candidates across a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Candidates across the clone's source files
+        """
+        return [MassAssignmentCandidate(**c) for c in run_pass(root, mass_assignment_candidates)]
+
+    def candidates(self, repository_id: int | None = None, severity: str | None = None) -> dict:
+        """Mass assignment candidates across the tenant's repositories.
+
+        Args:
+            repository_id: Restrict to one repository
+            severity: Keep only this severity
+
+        Returns:
+            Candidates with the privileged fields each bind reaches, most severe first, and counts per severity
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        results, scanned = [], 0
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            if not root.is_dir():
+                continue
+            scanned += 1
+            found = self.scan_clone(root)
+            logger.info("mass_assignment_candidates_detected", repository_id=repository.id, candidates=len(found))
+            results.extend({**asdict(c), "repository_id": repository.id, "repository_name": repository.name} for c in found)
+
+        if severity is not None:
+            results = [r for r in results if r["severity"] == severity.lower()]
+        results.sort(key=lambda r: (SEVERITY_ORDER[r["severity"]], r["repository_id"], r["file_path"], r["line_start"]))
+
+        by_severity: dict[str, int] = {}
+        for result in results:
+            by_severity[result["severity"]] = by_severity.get(result["severity"], 0) + 1
+        return {
+            "findings": results,
+            "summary": {
+                "total": len(results),
+                "repositories_scanned": scanned,
+                "repositories_with_findings": len({r["repository_id"] for r in results}),
+                "by_severity": by_severity,
+            },
+        }
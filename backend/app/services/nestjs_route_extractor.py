@@ -0,0 +1,480 @@
+"""Extract route authorization from NestJS controllers.
+
+NestJS splits a route's authorization between guards and metadata. Guards
+run from @UseGuards() on the controller class or the handler, or globally
+from useGlobalGuards() and APP_GUARD providers, and decide in their
+canActivate() method. What they check is usually not in the guard: a
+RolesGuard reads the roles a @Roles('admin') decorator stored as metadata
+through the Reflector, so a route requires whatever its handler's or its
+controller's metadata says, depending on whether the guard reads the handler,
+the class, or overrides one with the other. Authentication guards built on
+AuthGuard('jwt') commonly let routes marked @Public() through. This
+extractor resolves each controller route's guards, follows metadata
+decorators through SetMetadata(), Reflector.createDecorator(), and
+applyDecorators() compositions, reads custom CanActivate implementations for
+the metadata keys they consult, and resolves values through the TypeScript
+environment of typescript_route_extractor, without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field, replace
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import group
+from app.services.node_route_extractor import join_paths, statement_end
+from app.services.typescript_route_extractor import (
+    HTTP_METHODS,
+    IDENTIFIER,
+    MAX_RESOLVE_DEPTH,
+    Guard,
+    GuardKind,
+    TsFile,
+    TypeEnvironment,
+    argument_values,
+    build_environment,
+    class_decorators,
+    class_members,
+    decorator_path,
+    dedupe,
+    is_typescript_source,
+    kind_of,
+    prepare_file,
+    resolve_value,
+    route_finding,
+    split_top_level,
+    string_literal,
+)
+
+# Files without this are not read as part of a NestJS application
+NEST_IMPORT = re.compile(r"['\"]@nestjs/[\w-]+['\"]")
+
+CLASS_HEADER = re.compile(
+    rf"\bclass\s+({IDENTIFIER})[ \t]*(?:<[^>{{;\n]{{0,200}}>[ \t]*)?(?:extends\s+([^{{}};\n]{{1,200}}?))?\s*(?:implements\s+([^{{}};\n]{{1,200}}?))?\s*\{{"
+)
+# Decorator factories: const Roles = (...roles: Role[]) => SetMetadata(ROLES_KEY, roles), or a function returning one
+FACTORY_ARROW = re.compile(rf"\b(?:const|let|var)\s+({IDENTIFIER})\s*(?::[^=]+)?=\s*(?:\(([^()]*)\)|({IDENTIFIER}))\s*(?::[^=]+)?=>\s*")
+FACTORY_FUNCTION = re.compile(rf"\bfunction\s+({IDENTIFIER})\s*\(([^()]*)\)\s*(?::[^{{]+)?\{{\s*return\s+")
+FACTORY_CONST = re.compile(rf"\b(?:const|let|var)\s+({IDENTIFIER})\s*=\s*(?=(?:SetMetadata|applyDecorators|Reflector\s*\.\s*createDecorator)\b)")
+CREATE_DECORATOR = re.compile(r"^Reflector\s*\.\s*createDecorator\b")
+CALL = re.compile(rf"^(?:new\s+)?({IDENTIFIER}(?:\s*\.\s*{IDENTIFIER})*)\s*(?:<[^>(]*>)?\s*(\()?")
+REFLECTOR_READ = re.compile(r"\b\w*[rR]eflector\s*\.\s*(get|getAllAndOverride|getAllAndMerge)\s*(?:<[^>(]*>)?\s*\(")
+GLOBAL_GUARDS = re.compile(r"\.\s*useGlobalGuards\s*\(")
+APP_GUARD = re.compile(r"\bprovide\s*:\s*APP_GUARD\b")
+PROVIDER_CLASS = re.compile(rf"\buse(?:Class|Existing)\s*:\s*({IDENTIFIER})")
+GLOBAL_PREFIX = re.compile(r"\.\s*setGlobalPrefix\s*\(\s*(['\"`])([^'\"`]*)\1")
+# Metadata keys that let a route skip authentication rather than naming what it requires
+BYPASS_KEY = re.compile(r"(?i)public|anonymous|skip_?auth|no_?auth|allow_?anon|unauthenticated")
+# Guards not defined in the repository, recognized by name
+AUTHENTICATION_GUARD = re.compile(r"(?i)auth|jwt|passport|session|login|token|api_?key|bearer|firebase|cognito|auth0")
+AUTHORIZATION_GUARD = re.compile(r"(?i)role|permission|scope|polic|acl|casl|abilit|admin|access|claim")
+# Signs a guard's canActivate authenticates the caller itself
+VERIFIES_IDENTITY = re.compile(
+    r"\bsuper\s*\.\s*canActivate\b|\bjwtService\s*\.\s*verify|\bverifyAsync\b|\bisAuthenticated\s*\(|\bverify(?:Id)?Token\b|"
+    r"\bvalidateToken\b|\bheaders\s*(?:\.\s*|\[\s*['\"])authorization\b",
+    re.IGNORECASE,
+)
+HARDCODED_ROLE = re.compile(
+    r"\broles?\b\s*(?:\?\.)?\s*(?:\.\s*includes\s*\(\s*|={2,3}\s*)([^()\s;&|,]+)|([^()\s;&|=!,]+)\s*={2,3}\s*[\w$.?]*?\broles?\b"
+)
+
+
+class NestRouteKind:
+    """Kinds of NestJS route findings."""
+
+    ROUTE = "nestjs_route"
+
+
+@dataclass
+class _Factory:
+    """A decorator factory: its parameters and the decorator expression it returns."""
+
+    params: list[tuple[str, bool]]  # (name, is rest parameter)
+    body: str
+    renames: dict[str, str]
+    created: bool = False  # Reflector.createDecorator(): the decorator is its own metadata key
+
+
+@dataclass
+class _Metadata:
+    """Metadata values a decorator stores under one key."""
+
+    values: list | None  # Strings, [True] for flags, or None when not resolvable statically
+    label: str
+
+
+@dataclass
+class _Read:
+    """A Reflector read in a guard: the key and whose metadata it consults."""
+
+    key: str
+    scope: str  # handler, class, override, or merge
+
+
+@dataclass
+class _GuardClass:
+    """A guard implementation in the repository."""
+
+    name: str
+    parent: str | None
+    body: str
+    reads: list[_Read] = field(default_factory=list)
+
+
+@dataclass
+class _Application:
+    """Decorator factories, guard classes, and global settings of a NestJS application."""
+
+    env: TypeEnvironment
+    factories: dict[str, _Factory] = field(default_factory=dict)
+    guards: dict[str, _GuardClass] = field(default_factory=dict)
+    global_guards: list[tuple[str, str]] = field(default_factory=list)  # (guard expression, label)
+    prefix: str = ""
+
+
+def _params(text: str) -> list[tuple[str, bool]]:
+    """Names of a parameter list, flagging the rest parameter."""
+    params = []
+    for part in split_top_level(text, ","):
+        name = re.match(rf"(\.\.\.)?\s*({IDENTIFIER})", part.strip())
+        if name:
+            params.append((name.group(2), bool(name.group(1))))
+    return params
+
+
+def _key(expression: str, env: TypeEnvironment, renames: dict[str, str]) -> str:
+    """Metadata key an expression names: a string, a const holding one, or the identifier itself."""
+    expression = expression.strip()
+    literal = string_literal(expression)
+    if literal is not None:
+        return literal
+    name = renames.get(expression, expression)
+    values = env.values.get(name)
+    return values[0] if values else name
+
+
+def _collect(ts: TsFile, app: _Application) -> None:
+    """Add a file's decorator factories, guard classes, and global guards and prefix to the application."""
+    src, code = ts.src, ts.code
+    for pattern in (FACTORY_ARROW, FACTORY_FUNCTION, FACTORY_CONST):
+        for match in pattern.finditer(src.masked):
+            start = match.end()
+            end = statement_end(src, start)
+            body = code[start:end].strip()
+            call = CALL.match(body)
+            if not call:
+                continue
+            if pattern is FACTORY_ARROW:
+                params = _params(match.group(2)) if match.group(2) is not None else [(match.group(3), False)]
+            elif pattern is FACTORY_FUNCTION:
+                params = _params(match.group(2))
+            else:
+                params = []
+            created = pattern is FACTORY_CONST and CREATE_DECORATOR.match(body) is not None
+            if created or call.group(1) in ("SetMetadata", "applyDecorators"):
+                app.factories.setdefault(match.group(1), _Factory(params, body, ts.renames, created))
+
+    for match in CLASS_HEADER.finditer(src.masked):
+        name, parent, implements = match.group(1), match.group(2), match.group(3) or ""
+        open_brace = match.end() - 1
+        if open_brace not in src.pairs:
+            continue
+        body = code[open_brace : src.pairs[open_brace]]
+        if "CanActivate" not in implements and not (parent and re.search(r"Guard\b", parent)):
+            continue
+        guard = _GuardClass(name, " ".join(parent.split()) if parent else None, body)
+        for read in REFLECTOR_READ.finditer(body):
+            close = group(body, read.end() - 1)
+            args = split_top_level(body[read.end() : close - 1], ",")
+            if not args:
+                continue
+            targets = " ".join(args[1:])
+            scope = {"getAllAndOverride": "override", "getAllAndMerge": "merge"}.get(read.group(1))
+            if scope is None:
+                scope = "class" if "getClass" in targets and "getHandler" not in targets else "handler"
+            guard.reads.append(_Read(_key(args[0], app.env, ts.renames), scope))
+        app.guards.setdefault(name, guard)
+
+    consumed = 0
+    for match in GLOBAL_GUARDS.finditer(src.masked):
+        open_at = match.end() - 1
+        if open_at not in src.pairs or open_at < consumed:
+            continue
+        consumed = src.pairs[open_at]
+        for s, e in src.split(open_at + 1, src.pairs[open_at] - 1):
+            expression = code[s:e].strip()
+            app.global_guards.append((expression, f"{_name(expression)} (useGlobalGuards)"))
+    for match in APP_GUARD.finditer(src.masked):
+        start = src.masked.rfind("{", 0, match.start())
+        end = src.pairs.get(start, len(code)) if start >= 0 else match.end()
+        provider = PROVIDER_CLASS.search(code, start if start >= 0 else match.start(), end)
+        if provider:
+            app.global_guards.append((provider.group(1), f"{provider.group(1)} (APP_GUARD)"))
+    prefix = GLOBAL_PREFIX.search(src.masked)
+    if prefix and not app.prefix:
+        app.prefix = code[prefix.start(2) : prefix.end(2)]
+
+
+def _name(expression: str) -> str:
+    """Class or function name of a guard or decorator expression."""
+    call = CALL.match(expression.strip())
+    return re.sub(r"\s+", "", call.group(1)).rsplit(".", 1)[-1] if call else expression.strip()
+
+
+def _bound_values(expression: str, bindings: dict[str, list[str]], env: TypeEnvironment, renames: dict[str, str]):
+    """Values of a metadata argument, with a factory's parameters bound to the arguments it was called with."""
+    expression = expression.strip()
+    if expression.startswith("..."):
+        expression = expression[3:].strip()
+    if expression in bindings:
+        values = []
+        for argument in bindings[expression]:
+            resolved = _bound_values(argument, {}, env, renames)
+            if resolved is None:
+                return None
+            values.extend(resolved)
+        return values
+    if expression == "true":
+        return [True]
+    if expression == "false":
+        return []
+    if expression.startswith("[") and expression.endswith("]") and bindings:
+        values = []
+        for item in split_top_level(expression[1:-1], ","):
+            resolved = _bound_values(item, bindings, env, renames)
+            if resolved is None:
+                return None
+            values.extend(resolved)
+        return values
+    return argument_values(expression, env, renames)
+
+
+def _expand(
+    expression: str,
+    label: str,
+    app: _Application,
+    renames: dict[str, str],
+    bindings: dict[str, list[str]] | None = None,
+    depth: int = 0,
+) -> tuple[dict[str, _Metadata], list[tuple[str, str]]]:
+    """Metadata and guards a decorator expression applies, following factories and compositions.
+
+    Returns:
+        (metadata key -> values, [(guard expression, label)])
+    """
+    bindings = bindings or {}
+    metadata: dict[str, _Metadata] = {}
+    guards: list[tuple[str, str]] = []
+    call = CALL.match(expression.strip())
+    if not call or depth > MAX_RESOLVE_DEPTH:
+        return metadata, guards
+    head = re.sub(r"\s+", "", call.group(1))
+    name = renames.get(head, head).rsplit(".", 1)[-1]
+    body = expression.strip()
+    args = split_top_level(body[call.end() : group(body, call.end() - 1) - 1], ",") if call.group(2) else []
+    if name == "SetMetadata" and args:
+        key = _key(args[0], app.env, renames)
+        value = _bound_values(args[1], bindings, app.env, renames) if len(args) > 1 else [True]
+        metadata[key] = _Metadata(value, label)
+    elif name == "UseGuards":
+        origin = "@UseGuards" if label.startswith("@UseGuards") else label
+        guards += [(argument, f"{' '.join(argument.split())} ({origin})") for argument in args]
+    elif name == "applyDecorators":
+        for argument in args:
+            inner_metadata, inner_guards = _expand(argument, label, app, renames, bindings, depth + 1)
+            metadata.update(inner_metadata)
+            guards += inner_guards
+    elif name in app.factories:
+        factory = app.factories[name]
+        if factory.created:
+            values = [_bound_values(a, bindings, app.env, renames) for a in args]
+            flat = None if any(v is None for v in values) else [x for v in values for x in v]
+            metadata[name] = _Metadata(flat if args else [True], label)
+        else:
+            called: dict[str, list[str]] = {}
+            for index, (param, rest) in enumerate(factory.params):
+                bound = args[index:] if rest else args[index : index + 1]
+                # Arguments naming the enclosing factory's parameters pass its arguments on
+                called[param] = [x for a in bound for x in bindings.get(a.strip().lstrip("."), [a])]
+            inner_metadata, inner_guards = _expand(factory.body, label, app, factory.renames, called, depth + 1)
+            metadata.update(inner_metadata)
+            guards += inner_guards
+    return metadata, guards
+
+
+def _decorators(ts: TsFile, decorators: list, app: _Application) -> tuple[dict[str, _Metadata], list[tuple[str, str]]]:
+    """Metadata and guards of a class's or handler's decorators."""
+    metadata: dict[str, _Metadata] = {}
+    guards: list[tuple[str, str]] = []
+    for decorator in decorators:
+        text = ts.code[decorator.start + 1 : decorator.end]
+        label = " ".join(ts.code[decorator.start : decorator.end].split())
+        found_metadata, found_guards = _expand(text, label, app, ts.renames)
+        metadata.update(found_metadata)
+        guards += found_guards
+    return metadata, guards
+
+
+def _read(read: _Read, handler: dict[str, _Metadata], controller: dict[str, _Metadata]) -> _Metadata | None:
+    """Metadata a Reflector read sees for a route."""
+    if read.scope == "handler":
+        return handler.get(read.key)
+    if read.scope == "class":
+        return controller.get(read.key)
+    if read.scope == "override":
+        return handler.get(read.key) or controller.get(read.key)
+    found = [m for m in (handler.get(read.key), controller.get(read.key)) if m]
+    if not found:
+        return None
+    if any(m.values is None for m in found):
+        return _Metadata(None, " and ".join(m.label for m in found))
+    return _Metadata(dedupe([v for m in found for v in m.values]), " and ".join(m.label for m in found))
+
+
+def _guard_chain(name: str, app: _Application) -> list[_GuardClass]:
+    """A guard class and the guard classes it extends in the repository."""
+    chain: list[_GuardClass] = []
+    while name in app.guards and len(chain) < MAX_RESOLVE_DEPTH and all(g.name != name for g in chain):
+        chain.append(app.guards[name])
+        parent = app.guards[name].parent
+        name = _name(parent) if parent else ""
+    return chain
+
+
+BYPASSED = "bypassed"
+
+
+def _resolve_guard(
+    expression: str, label: str, app: _Application, handler: dict[str, _Metadata], controller: dict[str, _Metadata]
+) -> Guard | str | None:
+    """What a guard requires of a route.
+
+    Returns:
+        Guard; BYPASSED when route metadata lets the guard pass everyone;
+        None when the guard checks nothing for the route
+    """
+    name = _name(expression)
+    chain = _guard_chain(name, app)
+    if not chain:
+        if name == "AuthGuard" or AUTHENTICATION_GUARD.search(name):
+            return Guard(label)
+        if AUTHORIZATION_GUARD.search(name):
+            return Guard(label, conditions=[f"passes {name}, which the repository does not define"])
+        return None
+
+    reads = [read for guard in chain for read in guard.reads]
+    body = "\n".join(guard.body for guard in chain)
+    authenticates = any(g.parent and _name(g.parent) == "AuthGuard" for g in chain) or VERIFIES_IDENTITY.search(body)
+    roles, permissions, unresolved, sources = [], [], [], []
+    for read in reads:
+        found = _read(read, handler, controller)
+        if found is None:
+            continue
+        if BYPASS_KEY.search(read.key):
+            if found.values and found.values[0] is True:
+                return BYPASSED
+            continue
+        sources.append(found.label)
+        if found.values is None:
+            unresolved.append(f"{found.label} takes a value that cannot be resolved statically")
+            continue
+        values = [v for v in found.values if isinstance(v, str)]
+        kind = kind_of([read.key, found.label.lstrip("@"), name]) or GuardKind.PERMISSION
+        (roles if kind == GuardKind.ROLE else permissions).extend(values)
+    guard_label = f"{label} reading {', '.join(dedupe(sources))}" if sources else label
+    if roles or permissions or unresolved:
+        return Guard(guard_label, roles=dedupe(roles), permissions=dedupe(permissions), unresolved="; ".join(unresolved) or None)
+    if reads and not authenticates:
+        # A guard reading metadata the route does not carry lets it through
+        return None
+    if authenticates:
+        return Guard(label)
+    hardcoded = []
+    for match in HARDCODED_ROLE.finditer(body):
+        resolved = resolve_value(match.group(1) or match.group(2), app.env)
+        hardcoded.extend(resolved or [])
+    if hardcoded:
+        return Guard(label, roles=dedupe(hardcoded))
+    return Guard(label, conditions=[f"passes {name}.canActivate"])
+
+
+def _controller_path(ts: TsFile, decorator) -> str:
+    """Path of @Controller('orders') or @Controller({ path: 'orders' })."""
+    if decorator.paren < 0:
+        return ""
+    spans = ts.src.split(decorator.paren + 1, decorator.end - 1)
+    if not spans:
+        return ""
+    s, e = spans[0]
+    if ts.src.masked[s] == "{":
+        entry = ts.src.entries(s, e).get("path")
+        value = ts.src.literal(*entry) if entry else None
+    else:
+        value = ts.src.literal(s, e)
+    if isinstance(value, list):
+        value = value[0] if value else ""
+    return value if isinstance(value, str) else ""
+
+
+def extract_controller_routes(ts: TsFile, app: _Application) -> list[ConfigFinding]:
+    """Routes of one file's NestJS controllers with global, controller, and handler guards resolved."""
+    src = ts.src
+    findings = []
+    for match in CLASS_HEADER.finditer(src.masked):
+        decorators = class_decorators(ts, match.start())
+        controller = next((d for d in decorators if d.name == "Controller"), None)
+        if controller is None:
+            continue
+        base = _controller_path(ts, controller)
+        class_metadata, class_guards = _decorators(ts, [d for d in decorators if d is not controller], app)
+        open_brace = match.end() - 1
+        for method_decorators, span in class_members(ts, open_brace + 1, src.pairs.get(open_brace, len(src.masked)) - 1):
+            route = next((d for d in method_decorators if d.name.lower() in HTTP_METHODS and d.name[0].isupper()), None)
+            if route is None:
+                continue
+            handler_metadata, handler_guards = _decorators(ts, [d for d in method_decorators if d is not route], app)
+            guards, bypassed = [], []
+            for expression, label in app.global_guards + class_guards + handler_guards:
+                resolved = _resolve_guard(expression, label, app, handler_metadata, class_metadata)
+                if resolved == BYPASSED:
+                    bypassed.append(label)
+                elif resolved is not None:
+                    guards.append(resolved)
+            method = "*" if route.name == "All" else route.name.upper()
+            path = join_paths(app.prefix, base, decorator_path(ts, route))
+            if guards:
+                findings.append(route_finding(NestRouteKind.ROUTE, ts, (route.start, span[1]), method, path, guards, "NestJS"))
+            elif bypassed:
+                marker = next(m.label for m in {**class_metadata, **handler_metadata}.values() if m.values and m.values[0] is True)
+                finding = route_finding(NestRouteKind.ROUTE, ts, (route.start, span[1]), method, path, [Guard(label) for label in bypassed], "NestJS")
+                findings.append(
+                    replace(
+                        finding,
+                        subject="Anonymous",
+                        disables_auth=True,
+                        description=f"NestJS route open to anyone: {marker} lets it past {', '.join(bypassed)}",
+                    )
+                )
+    return findings
+
+
+def extract_nestjs_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract NestJS route authorization from TypeScript sources.
+
+    Args:
+        files: Relative path -> content of the application's JS/TS files; only
+            TypeScript sources are read
+
+    Returns:
+        One finding per guarded controller route
+    """
+    sources = {path: text for path, text in files.items() if is_typescript_source(path)}
+    if not any(NEST_IMPORT.search(text) for text in sources.values()):
+        return []
+    app = _Application(build_environment(sources))
+    for file_path in sorted(sources):
+        _collect(prepare_file(file_path, sources[file_path]), app)
+    findings = []
+    for file_path in sorted(sources):
+        if NEST_IMPORT.search(sources[file_path]):
+            findings.extend(extract_controller_routes(prepare_file(file_path, sources[file_path]), app))
+    return findings
@@ -0,0 +1,756 @@
+"""Extract route-level authorization from Koa and Hapi applications.
+
+Express-style analysis reads auth from the middleware named in each route
+call. Hapi declares auth as route configuration instead (options.auth with
+a strategy, scopes, and mode, falling back to the server default), and Koa
+apps build guards by composing middleware into reusable stacks and mounting
+routers under shared middleware, often across files. These extractors
+resolve both into one ConfigFinding per route, without LLM calls.
+"""
+
+import posixpath
+import re
+from dataclasses import dataclass, field
+from functools import lru_cache
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_of, line_span
+
+# Snippets show at most this many lines of a route definition
+MAX_SNIPPET_LINES = 40
+
+# Alias chains longer than this are treated as unresolvable
+MAX_ALIAS_DEPTH = 8
+
+JS_SUFFIXES = (".js", ".ts", ".mjs", ".cjs", ".jsx", ".tsx")
+
+OPENERS = {"(": ")", "[": "]", "{": "}"}
+
+# Object method shorthand: handler(request, h) { ... }
+METHOD_SHORTHAND = re.compile(r"(?:async\s+)?([A-Za-z_$][\w$]*)\s*\(")
+
+# A "/" after one of these starts a regex literal rather than a division
+REGEX_PRECEDERS = set("(,=:[!&|?{};")
+
+
+class NodeRouteKind:
+    """Kinds of Node.js route findings."""
+
+    HAPI_ROUTE = "hapi_route"
+    KOA_ROUTE = "koa_route"
+
+
+def _mask(text: str) -> str:
+    """Blank out comments and string/regex contents, keeping offsets and newlines.
+
+    Quote characters stay in place so literals can still be located; their
+    contents become "x" so brackets and keywords inside them are ignored.
+    """
+    out = list(text)
+    i, n = 0, len(text)
+    previous = ""
+    while i < n:
+        c = text[i]
+        if c == "/" and i + 1 < n and text[i + 1] == "/":
+            while i < n and text[i] != "\n":
+                out[i] = " "
+                i += 1
+            continue
+        if c == "/" and i + 1 < n and text[i + 1] == "*":
+            end = text.find("*/", i + 2)
+            end = n if end < 0 else end + 2
+            for j in range(i, end):
+                if text[j] != "\n":
+                    out[j] = " "
+            i = end
+            continue
+        if c in "'\"`" or (c == "/" and (previous == "" or previous in REGEX_PRECEDERS)):
+            quote, j, in_class = c, i + 1, False
+            while j < n:
+                ch = text[j]
+                if ch == "\\":
+                    out[j] = "x"
+                    if j + 1 < n and text[j + 1] != "\n":
+                        out[j + 1] = "x"
+                    j += 2
+                    continue
+                if ch == "\n" and quote != "`":
+                    break
+                if quote == "/" and ch == "[":
+                    in_class = True
+                elif quote == "/" and ch == "]":
+                    in_class = False
+                elif ch == quote and not in_class:
+                    break
+                if ch != "\n":
+                    out[j] = "x"
+                j += 1
+            i = j + 1
+            previous = "x"
+            continue
+        if not c.isspace():
+            previous = c
+        i += 1
+    return "".join(out)
+
+
+def _pairs(masked: str) -> dict[int, int]:
+    """Map each opening bracket to the offset just past its closing bracket.
+
+    Unclosed brackets map to the end of the text.
+    """
+    pairs: dict[int, int] = {}
+    stack: list[int] = []
+    open_counts = dict.fromkeys(OPENERS, 0)
+    closers = {v: k for k, v in OPENERS.items()}
+    for i, c in enumerate(masked):
+        if c in OPENERS:
+            stack.append(i)
+            open_counts[c] += 1
+        elif c in closers and open_counts[closers[c]]:
+            # Brackets left open inside this pair close with it; stray closers are ignored
+            while True:
+                start = stack.pop()
+                open_counts[masked[start]] -= 1
+                pairs[start] = i + 1
+                if masked[start] == closers[c]:
+                    break
+    for i in stack:
+        pairs[i] = len(masked)
+    return pairs
+
+
+@dataclass
+class _Source:
+    """A JavaScript/TypeScript file prepared for bracket-aware scanning."""
+
+    text: str
+    masked: str = ""
+    pairs: dict[int, int] = field(default_factory=dict)
+
+    def __post_init__(self) -> None:
+        self.masked = _mask(self.text)
+        self.pairs = _pairs(self.masked)
+
+    def split(self, start: int, end: int, separator: str = ",") -> list[tuple[int, int]]:
+        """Split start..end at top-level separators into trimmed spans."""
+        spans, begin, i = [], start, start
+        while i < end:
+            c = self.masked[i]
+            if c in OPENERS:
+                i = max(self.pairs.get(i, end), i + 1)
+                continue
+            if c == separator:
+                spans.append((begin, i))
+                begin = i + 1
+            i += 1
+        spans.append((begin, end))
+        trimmed = []
+        for s, e in spans:
+            while s < e and self.masked[s].isspace():
+                s += 1
+            while e > s and self.masked[e - 1].isspace():
+                e -= 1
+            if s < e:
+                trimmed.append((s, e))
+        return trimmed
+
+    def source(self, start: int, end: int) -> str:
+        """Original text of a span with whitespace collapsed."""
+        return " ".join(self.text[start:end].split())
+
+    def literal(self, start: int, end: int):
+        """Value of a string, boolean, or string-array literal; None otherwise."""
+        masked = self.masked[start:end]
+        quoted = len(masked) >= 2 and masked[0] in "'\"`" and masked[-1] == masked[0]
+        if quoted and masked[1:-1] == "x" * (len(masked) - 2):
+            return self.text[start + 1 : end - 1]
+        if masked in ("true", "false"):
+            return masked == "true"
+        if masked.startswith("[") and self.pairs.get(start) == end:
+            values = [self.literal(s, e) for s, e in self.split(start + 1, end - 1)]
+            return [v for v in values if isinstance(v, str)]
+        return None
+
+    def entries(self, start: int, end: int) -> dict[str, tuple[int, int]]:
+        """Top-level key -> value span of the object literal at start..end."""
+        if self.masked[start] != "{":
+            return {}
+        result = {}
+        for s, e in self.split(start + 1, end - 1):
+            parts = self.split(s, e, ":")
+            if len(parts) < 2:
+                shorthand = METHOD_SHORTHAND.match(self.masked[s:e])
+                if shorthand:
+                    result[shorthand.group(1)] = (s, e)
+                continue
+            key = self.text[parts[0][0] : parts[0][1]].strip("'\"` ")
+            result[key] = (parts[1][0], e)
+        return result
+
+    def snippet(self, start: int, end: int) -> tuple[int, int, str]:
+        """Line range and text of a span, capped at MAX_SNIPPET_LINES."""
+        line_start = line_of(self.text, start)
+        line_end = min(line_of(self.text, end), line_start + MAX_SNIPPET_LINES - 1)
+        return line_start, line_end, line_span(self.text, line_start, line_end)
+
+
+@lru_cache(maxsize=64)
+def _source(text: str) -> _Source:
+    """Prepare a file once for the Hapi and Koa passes over it."""
+    return _Source(text)
+
+
+def join_paths(*parts: str) -> str:
+    """Join route path segments, collapsing duplicate slashes."""
+    return "/" + "/".join(p.strip("/") for p in parts if p and p.strip("/"))
+
+
+HAPI_DEFAULT_AUTH = re.compile(r"\.auth\s*\.\s*default\s*\(")
+HAPI_PATH_KEY = re.compile(r"(?<![\w$.])path\s*:")
+HAPI_ROUTE_KEYS = ("handler", "options", "config")
+
+
+@dataclass
+class HapiAuth:
+    """Resolved auth settings of a Hapi route or server default."""
+
+    disabled: bool = False
+    strategies: list[str] = field(default_factory=list)
+    mode: str | None = None
+    scopes: list[str] = field(default_factory=list)
+    entity: str | None = None
+
+    def merged_with(self, default: "HapiAuth | None") -> "HapiAuth":
+        """Fill unset fields from the server default, as Hapi does per route."""
+        if default is None or self.disabled:
+            return self
+        return HapiAuth(
+            strategies=self.strategies or default.strategies,
+            mode=self.mode or default.mode,
+            scopes=self.scopes or default.scopes,
+            entity=self.entity or default.entity,
+        )
+
+
+def _hapi_auth(src: _Source, start: int, end: int) -> HapiAuth | None:
+    """Parse an auth setting: false, a strategy name, or an auth object."""
+    value = src.literal(start, end)
+    if value is False:
+        return HapiAuth(disabled=True)
+    if isinstance(value, str):
+        return HapiAuth(strategies=[value])
+    entries = src.entries(start, end)
+    if not entries:
+        return None
+
+    def strings(key: str, within: dict[str, tuple[int, int]]) -> list[str]:
+        if key not in within:
+            return []
+        found = src.literal(*within[key])
+        return [found] if isinstance(found, str) else found or []
+
+    auth = HapiAuth(
+        strategies=strings("strategies", entries) or strings("strategy", entries),
+        mode=src.literal(*entries["mode"]) if "mode" in entries else None,
+        scopes=strings("scope", entries),
+        entity=src.literal(*entries["entity"]) if "entity" in entries else None,
+    )
+    if "access" in entries:
+        s, e = entries["access"]
+        rules = [entries["access"]] if src.masked[s] == "{" else src.split(s + 1, e - 1)
+        for rule_start, rule_end in rules:
+            access = src.entries(rule_start, rule_end)
+            auth.scopes.extend(strings("scope", access))
+            if "entity" in access and auth.entity is None:
+                auth.entity = src.literal(*access["entity"])
+    return auth
+
+
+def find_hapi_default_auth(text: str) -> HapiAuth | None:
+    """Find the server-wide auth default set with server.auth.default().
+
+    Args:
+        text: File content
+
+    Returns:
+        Default auth settings, or None if the file sets none
+    """
+    if ".auth" not in text:
+        return None
+    src = _source(text)
+    match = HAPI_DEFAULT_AUTH.search(src.masked)
+    if not match:
+        return None
+    args = src.split(match.end(), src.pairs.get(match.end() - 1, len(text)) - 1)
+    return _hapi_auth(src, *args[0]) if args else None
+
+
+def _scope_conditions(auth: HapiAuth) -> tuple[list[str], list[str]]:
+    """Split Hapi scopes into alternative roles and extra conditions."""
+    roles, conditions = [], []
+    for scope in auth.scopes:
+        if "{" in scope:
+            conditions.append(f"scope {scope} matches the request")
+        elif scope.startswith("+"):
+            conditions.append(f"caller has scope {scope[1:]}")
+        elif scope.startswith("!"):
+            conditions.append(f"caller lacks scope {scope[1:]}")
+        else:
+            roles.append(scope)
+    if auth.mode in ("optional", "try"):
+        conditions.append(f"credentials are used when present (auth mode '{auth.mode}')")
+    if auth.entity in ("user", "app"):
+        conditions.append(f"credentials belong to a{'n' if auth.entity == 'app' else ''} {auth.entity}")
+    return roles, conditions
+
+
+def _hapi_finding(
+    src: _Source, file_path: str, span: tuple[int, int], method: str, path: str, auth: HapiAuth, explicit: bool
+) -> ConfigFinding:
+    """Build the finding for one method of a Hapi route."""
+    roles, conditions = _scope_conditions(auth)
+    if auth.disabled or auth.mode in ("optional", "try"):
+        subject = "Anonymous"
+    elif roles:
+        subject = " or ".join(roles)
+    else:
+        subject = "Authenticated users"
+
+    if auth.disabled:
+        description = "Hapi route with auth disabled"
+    else:
+        strategy = ", ".join(auth.strategies) or "default"
+        description = f"Hapi route requiring auth strategy {strategy}"
+        if auth.scopes:
+            description += f" with scope {', '.join(auth.scopes)}"
+    if not explicit:
+        description += " (inherited from server.auth.default)"
+
+    line_start, line_end, snippet = src.snippet(*span)
+    return ConfigFinding(
+        kind=NodeRouteKind.HAPI_ROUTE,
+        file_path=file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=snippet,
+        subject=subject,
+        resource=path,
+        action=method,
+        conditions="; ".join(conditions) or None,
+        description=description,
+        disables_auth=auth.disabled,
+    )
+
+
+def extract_hapi_routes(file_path: str, text: str, default_auth: HapiAuth | None = None) -> list[ConfigFinding]:
+    """Extract per-route auth from Hapi route configuration objects.
+
+    Route objects are recognized wherever they appear (server.route() calls,
+    exported route arrays, plugin registrations) by their method, path, and
+    handler/options keys. Routes without auth settings inherit the server
+    default; routes with neither are skipped, since nothing guards them.
+
+    Args:
+        file_path: Path of the file
+        text: File content
+        default_auth: Server default found anywhere in the application
+
+    Returns:
+        One finding per route and method
+    """
+    if "path" not in text:
+        return []
+    src = _source(text)
+    default_auth = find_hapi_default_auth(text) or default_auth
+
+    # Objects that directly contain a "path:" key
+    candidates, stack, keys = [], [], iter(m.start() for m in HAPI_PATH_KEY.finditer(src.masked))
+    next_key = next(keys, None)
+    for i, c in enumerate(src.masked):
+        while next_key is not None and next_key <= i:
+            if stack and src.masked[stack[-1]] == "{":
+                candidates.append(stack[-1])
+            next_key = next(keys, None)
+        if next_key is None:
+            break
+        if c in OPENERS:
+            stack.append(i)
+        elif c in ")]}" and stack:
+            stack.pop()
+
+    findings = []
+    for start in dict.fromkeys(candidates):
+        end = src.pairs.get(start, len(text))
+        entries = src.entries(start, end)
+        if "method" not in entries or not any(k in entries for k in HAPI_ROUTE_KEYS):
+            continue
+        path = src.literal(*entries["path"])
+        methods = src.literal(*entries["method"])
+        methods = [methods] if isinstance(methods, str) else methods
+        if not isinstance(path, str) or not path.startswith("/") or not methods:
+            continue
+
+        auth, explicit = None, False
+        for options_key in ("options", "config"):
+            options = src.entries(*entries[options_key]) if options_key in entries else {}
+            if "auth" in options:
+                auth, explicit = _hapi_auth(src, *options["auth"]), True
+                break
+        if auth is None:
+            auth, explicit = default_auth, False
+        elif explicit:
+            auth = auth.merged_with(default_auth)
+        if auth is None:
+            continue
+
+        for method in methods:
+            findings.append(_hapi_finding(src, file_path, (start, end), method.upper(), path, auth, explicit))
+    return findings
+
+
+KOA_IMPORT = re.compile(r"['\"](?:koa|@koa/router|koa-router)['\"]")
+DECLARATION = re.compile(r"\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*")
+KOA_CONSTRUCTOR = re.compile(r"^(?:new\s+)?(Koa|Router|KoaRouter)\s*\(")
+IMPORT_DEFAULT = re.compile(r"\bimport\s+([A-Za-z_$][\w$]*)\s+from\s+['\"]([^'\"]+)['\"]")
+REQUIRE = re.compile(r"^require\s*\(\s*['\"]([^'\"]+)['\"]\s*\)$")
+EXPORT_DEFAULT = re.compile(r"\b(?:module\.exports|export\s+default)\s*=?\s*([A-Za-z_$][\w$]*)\s*;?\s*$", re.MULTILINE)
+KOA_CALL = re.compile(r"(?<![\w$.])([A-Za-z_$][\w$]*)\s*\.\s*(use|get|post|put|patch|delete|del|all|prefix)\s*\(")
+MOUNT = re.compile(r"^([A-Za-z_$][\w$]*)\s*\.\s*(?:routes|middleware)\s*\(\s*\)$")
+ALLOWED_METHODS = re.compile(r"\.\s*allowedMethods\s*\(")
+COMPOSE = re.compile(r"^(?:\w+\.)?compose\s*\(")
+
+AUTHENTICATION_MIDDLEWARE = re.compile(
+    r"\b(?:koa-?jwt|jwt|authenticate|requireAuth|ensureAuthenticated|isAuthenticated|authRequired|"
+    r"requireLogin|verifyToken|protect|bearerAuth|basicAuth)\b",
+    re.IGNORECASE,
+)
+ROLE_MIDDLEWARE = re.compile(
+    r"\b(?:requireRoles?|checkRoles?|hasRoles?|roles?|authorize|permit|requirePermissions?|hasPermissions?|"
+    r"requireScopes?|checkScopes?)\s*\(",
+    re.IGNORECASE,
+)
+UNLESS = re.compile(r"\.\s*unless\s*\(")
+QUOTED = re.compile(r"['\"`]([^'\"`]+)['\"`]")
+REGEX_LITERAL = re.compile(r"(?<![\w)\]])/((?:\\.|[^/\\\n])+)/[gimsuy]*")
+
+KOA_METHODS = {"del": "DELETE", "all": "*"}
+
+
+@dataclass
+class _KoaUse:
+    """Middleware added to an app or router with .use()."""
+
+    position: int
+    receiver: str
+    path: str
+    middleware: list[str]
+
+
+@dataclass
+class _KoaMount:
+    """A router mounted on an app or router with .use(router.routes())."""
+
+    position: int
+    receiver: str
+    prefix: str
+    target: str
+
+
+@dataclass
+class _KoaRoute:
+    """A route registered on a router."""
+
+    position: int
+    receiver: str
+    method: str
+    path: str
+    middleware: list[str]
+    line_start: int
+    line_end: int
+    snippet: str
+
+
+@dataclass
+class KoaModule:
+    """Apps, routers, and route wiring declared in one Koa source file."""
+
+    file_path: str
+    apps: set[str] = field(default_factory=set)
+    routers: dict[str, str] = field(default_factory=dict)  # name -> prefix
+    imports: dict[str, str] = field(default_factory=dict)  # name -> module specifier
+    exported: str | None = None
+    uses: list[_KoaUse] = field(default_factory=list)
+    mounts: list[_KoaMount] = field(default_factory=list)
+    routes: list[_KoaRoute] = field(default_factory=list)
+
+
+def statement_end(src: _Source, start: int) -> int:
+    """End of the expression starting at start (a top-level ";" or line break)."""
+    i, n = start, len(src.masked)
+    while i < n:
+        c = src.masked[i]
+        if c in OPENERS:
+            i = max(src.pairs.get(i, n), i + 1)
+            continue
+        if c == ";" or c in ")]}":
+            return i
+        if c == "\n":
+            rest = src.masked[i:].lstrip()
+            if not rest.startswith((".", "?", ":", "+", "&&", "||")):
+                return i
+        i += 1
+    return n
+
+
+def _expand(src: _Source, start: int, end: int, aliases: dict[str, tuple[int, int]], depth: int = 0) -> list[str]:
+    """Expand a middleware expression through aliases and compose([...]) stacks."""
+    expression = src.masked[start:end]
+    if depth < MAX_ALIAS_DEPTH:
+        if expression in aliases:
+            return _expand(src, *aliases[expression], aliases, depth + 1)
+        compose = COMPOSE.match(expression)
+        if compose:
+            args = src.split(start + compose.end(), src.pairs.get(start + compose.end() - 1, end) - 1)
+            if args and src.masked[args[0][0]] == "[":
+                s, e = args[0]
+                return [m for span in src.split(s + 1, e - 1) for m in _expand(src, *span, aliases, depth + 1)]
+    return [src.source(start, end)]
+
+
+def parse_koa_module(file_path: str, text: str) -> KoaModule | None:
+    """Collect Koa apps, routers, middleware, and routes from a file.
+
+    Args:
+        file_path: Path of the file
+        text: File content
+
+    Returns:
+        Module wiring, or None if the file does not use Koa
+    """
+    if not KOA_IMPORT.search(text):
+        return None
+    src = _source(text)
+    module = KoaModule(file_path=file_path)
+    aliases: dict[str, tuple[int, int]] = {}
+
+    for match in DECLARATION.finditer(src.masked):
+        name, start = match.group(1), match.end()
+        end = statement_end(src, start)
+        expression = src.masked[start:end]
+        constructor = KOA_CONSTRUCTOR.match(expression)
+        required = REQUIRE.match(src.text[start:end].strip())
+        if constructor and constructor.group(1) == "Koa":
+            module.apps.add(name)
+        elif constructor:
+            args = src.split(start + constructor.end(), src.pairs.get(start + constructor.end() - 1, end) - 1)
+            options = src.entries(*args[0]) if args else {}
+            prefix = src.literal(*options["prefix"]) if "prefix" in options else ""
+            module.routers[name] = prefix if isinstance(prefix, str) else ""
+        elif required:
+            module.imports[name] = required.group(1)
+        else:
+            aliases[name] = (start, end)
+    for match in IMPORT_DEFAULT.finditer(text):
+        module.imports[match.group(1)] = match.group(2)
+    exported = [m.group(1) for m in EXPORT_DEFAULT.finditer(src.masked)]
+    module.exported = next((name for name in reversed(exported) if name in module.routers), None)
+
+    if not module.apps and not module.routers:
+        return None
+    receivers = module.apps | set(module.routers)
+
+    for match in KOA_CALL.finditer(src.masked):
+        receiver, verb = match.group(1), match.group(2)
+        if receiver not in receivers:
+            continue
+        open_paren = match.end() - 1
+        close = src.pairs.get(open_paren, len(text))
+        args = src.split(open_paren + 1, close - 1)
+        literals = [src.literal(*span) for span in args]
+
+        if verb == "prefix":
+            if literals and isinstance(literals[0], str) and receiver in module.routers:
+                module.routers[receiver] = literals[0]
+            continue
+        if verb == "use":
+            path = literals[0] if literals and isinstance(literals[0], str) else ""
+            middleware = []
+            for span, value in zip(args, literals, strict=True):
+                expression = src.masked[span[0] : span[1]]
+                mount = MOUNT.match(expression)
+                if mount:
+                    module.mounts.append(_KoaMount(match.start(), receiver, path, mount.group(1)))
+                elif value is None and not ALLOWED_METHODS.search(expression):
+                    middleware.extend(_expand(src, *span, aliases))
+            if middleware:
+                module.uses.append(_KoaUse(match.start(), receiver, path, middleware))
+            continue
+
+        if receiver not in module.routers or len(args) < 2:
+            continue
+        path_index = 1 if isinstance(literals[0], str) and isinstance(literals[1], str) else 0
+        path = literals[path_index]
+        if not isinstance(path, str) or not path.startswith("/"):
+            continue
+        middleware = [m for span in args[path_index + 1 : -1] for m in _expand(src, *span, aliases)]
+        line_start, line_end, snippet = src.snippet(match.start(), close)
+        module.routes.append(
+            _KoaRoute(
+                match.start(),
+                receiver,
+                KOA_METHODS.get(verb, verb.upper()),
+                path,
+                middleware,
+                line_start,
+                line_end,
+                snippet,
+            )
+        )
+    return module
+
+
+def _resolve_import(from_file: str, specifier: str, modules: dict[str, KoaModule]) -> KoaModule | None:
+    """Find the module a relative import refers to."""
+    if not specifier.startswith("."):
+        return None
+    base = posixpath.normpath(posixpath.join(posixpath.dirname(from_file), specifier))
+    candidates = [base] + [base + s for s in JS_SUFFIXES] + [f"{base}/index{s}" for s in JS_SUFFIXES]
+    return next((modules[c] for c in candidates if c in modules), None)
+
+
+def _middleware_auth(stack: list[str], path: str) -> tuple[list[str], list[str]]:
+    """Auth middleware in a stack that applies to a path, and the roles they require."""
+    guards, roles = [], []
+    for middleware in stack:
+        role_match = ROLE_MIDDLEWARE.search(middleware)
+        if not role_match and not AUTHENTICATION_MIDDLEWARE.search(middleware):
+            continue
+        unless = UNLESS.search(middleware)
+        if unless:
+            excluded = middleware[unless.end() :]
+            if path in QUOTED.findall(excluded):
+                continue
+            patterns = []
+            for expression in REGEX_LITERAL.findall(QUOTED.sub("", excluded)):
+                try:
+                    patterns.append(re.compile(expression))
+                except re.error:
+                    continue
+            if any(p.search(path) for p in patterns):
+                continue
+            middleware = middleware[: unless.start()]
+        guards.append(middleware)
+        if role_match:
+            for role in QUOTED.findall(middleware[role_match.end() :]):
+                if role not in roles:
+                    roles.append(role)
+    return guards, roles
+
+
+def resolve_koa_routes(modules: list[KoaModule]) -> list[ConfigFinding]:
+    """Resolve Koa routes through router mounts into per-route findings.
+
+    Each route's guard stack is the app middleware registered before its
+    router was mounted, the middleware of every enclosing router registered
+    before the mount, the router's own middleware registered before the
+    route, and the route's inline middleware, in that order. Routes whose
+    stack contains no authentication or role middleware are skipped.
+
+    Args:
+        modules: Parsed Koa modules of an application
+
+    Returns:
+        One finding per guarded route
+    """
+    by_path = {m.file_path: m for m in modules}
+
+    def local_stack(module: KoaModule, receiver: str, position: int, path: str) -> list[str]:
+        return [
+            mw
+            for use in module.uses
+            if use.receiver == receiver and use.position < position and path.startswith(use.path or "/")
+            for mw in use.middleware
+        ]
+
+    # Every place a router is mounted: router id -> [(module, mount)]
+    mounted: dict[tuple[str, str], list[tuple[KoaModule, _KoaMount]]] = {}
+    for module in modules:
+        for mount in module.mounts:
+            if mount.target in module.routers:
+                target = (module.file_path, mount.target)
+            elif mount.target in module.imports:
+                imported = _resolve_import(module.file_path, module.imports[mount.target], by_path)
+                if imported is None or imported.exported is None:
+                    continue
+                target = (imported.file_path, imported.exported)
+            else:
+                continue
+            mounted.setdefault(target, []).append((module, mount))
+
+    def contexts(node: tuple[str, str], visiting: frozenset) -> list[tuple[str, list[str]]]:
+        module = by_path[node[0]]
+        if node[1] in module.apps:
+            return [("", [])]
+        if node not in mounted or node in visiting:
+            return [("", [])]
+        result = []
+        for parent_module, mount in mounted[node]:
+            parent = (parent_module.file_path, mount.receiver)
+            parent_prefix = parent_module.routers.get(mount.receiver, "")
+            for prefix, stack in contexts(parent, visiting | {node}):
+                local = local_stack(parent_module, mount.receiver, mount.position, mount.prefix or "/")
+                result.append((join_paths(prefix, parent_prefix, mount.prefix), stack + local))
+        return result
+
+    findings = []
+    for module in modules:
+        for route in module.routes:
+            seen = set()
+            for prefix, stack in contexts((module.file_path, route.receiver), frozenset()):
+                full_path = join_paths(prefix, module.routers.get(route.receiver, ""), route.path)
+                middleware = stack + local_stack(module, route.receiver, route.position, route.path) + route.middleware
+                guards, roles = _middleware_auth(middleware, full_path)
+                if not guards or (full_path, tuple(guards)) in seen:
+                    continue
+                seen.add((full_path, tuple(guards)))
+                findings.append(
+                    ConfigFinding(
+                        kind=NodeRouteKind.KOA_ROUTE,
+                        file_path=module.file_path,
+                        line_start=route.line_start,
+                        line_end=route.line_end,
+                        snippet=route.snippet,
+                        subject=" or ".join(roles) if roles else "Authenticated users",
+                        resource=full_path,
+                        action=route.method,
+                        description=f"Koa route guarded by {', '.join(guards)}",
+                    )
+                )
+    return findings
+
+
+def extract_node_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Koa and Hapi route authorization from an application's files.
+
+    Args:
+        files: Relative path -> content of the application's JS/TS files
+
+    Returns:
+        Route findings across all files
+    """
+    default_auth = next((a for a in (find_hapi_default_auth(t) for t in files.values()) if a), None)
+    findings = []
+    modules = []
+    for file_path, text in files.items():
+        findings.extend(extract_hapi_routes(file_path, text, default_auth))
+        module = parse_koa_module(file_path, text)
+        if module is not None:
+            modules.append(module)
+    return findings + resolve_koa_routes(modules)
+
+
+def is_node_source(file_path: str) -> bool:
+    """Check whether a path is a JavaScript/TypeScript source file."""
+    return PurePosixPath(file_path).suffix in JS_SUFFIXES and not file_path.endswith(".d.ts")
@@ -7,10 +7,12 @@ from anthropic import Anthropic
 from sqlalchemy import func, select
 from sqlalchemy.orm import Session
 
+from app.core.air_gap import ensure_llm_allowed
 from app.core.config import settings
 from app.models.application import Application
 from app.models.policy import Policy
 from app.models.role_mapping import MappingStatus, RoleMapping
+from app.services.redaction_service import redact_for_egress
 
 logger = logging.getLogger(__name__)
 
@@ -111,10 +113,11 @@ REASONING: your explanation
 """
 
         try:
+            ensure_llm_allowed("role_normalization")
             message = self.client.messages.create(
                 model="claude-sonnet-4-5-20250929",
                 max_tokens=1000,
-                messages=[{"role": "user", "content": prompt}],
+                messages=[{"role": "user", "content": redact_for_egress(prompt, "llm")}],
             )
 
             response_text = message.content[0].text
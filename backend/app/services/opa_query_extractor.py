@@ -0,0 +1,242 @@
+"""Extract the Rego policies application code queries OPA with.
+
+Services that delegate authorization to Open Policy Agent carry no rules of
+their own: a handler builds an input document and asks OPA for a decision,
+either in-process with rego.New(rego.Query("data.authz.allow"), ...) and the
+OPA SDK's opa.Decision(ctx, sdk.DecisionOptions{Path: "/authz/allow"}), or
+over HTTP by posting to /v1/data/authz/allow. Scanning the handler alone
+reports the endpoint as having no authorization. This extractor finds those
+queries, resolves the queried data path to a package and rule in the
+repository's .rego files, and turns the rule into ConfigFindings whose
+evidence cites the rule and the handlers that query it.
+"""
+
+import re
+from bisect import bisect_right
+from dataclasses import dataclass, field
+
+from app.services.bola_detection_service import DECORATOR_LINE, HANDLER_START, HEADER_LINES, MAX_HANDLER_LINES
+from app.services.config_policy_extractor import REGO_PACKAGE, ConfigFinding, extract_rego
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import MAX_LINE_LENGTH
+
+# Sources that may query OPA
+OPA_QUERY_SUFFIXES = (".go", ".py", ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".java", ".kt", ".cs", ".rb", ".php", ".rs")
+REGO_SUFFIX = ".rego"
+
+# Decision options are read at most this far past the opening parenthesis
+MAX_ARGUMENT_SPAN = 500
+MAX_SNIPPET_LENGTH = 300
+
+# rego.Query("data.authz.allow"), query="data.authz.allow", and constants such as authzQuery = "data.authz.allow"
+REGO_QUERY = re.compile(r"""\b(?:rego\s*\.\s*)?\w*[Qq]uery\s*(?:\(|:?=|:)\s*["'`]data((?:\.\w+)+)""")
+DECISION = re.compile(r"\.\s*Decision\s*\(")
+DECISION_PATH = re.compile(r"""\bPath\s*:\s*["'`]/?([\w/]+)["'`]""")
+# Data API calls: http://opa:8181/v1/data/authz/allow, f"{OPA_URL}/v1/data/authz"
+DATA_API = re.compile(r"/v[01]/data/([\w/]+)")
+OPA_QUERY_NEEDLE = re.compile(r"rego|Decision|/v[01]/data/|[Qq]uery")
+
+
+@dataclass
+class OpaQuery:
+    """A decision an application asks OPA for."""
+
+    file_path: str
+    line: int
+    style: str  # rego, sdk, or http
+    path: str  # queried data path, e.g. authz/allow
+    code: str
+    route: tuple[str, str] | None = None  # (METHOD, path) of the handler making the query
+    route_line: int | None = None
+    route_code: str | None = None
+    package: str | None = None  # resolved Rego package, e.g. authz
+    rule: str | None = None  # queried rule, or None for the package's decision rules
+
+
+@dataclass
+class OpaQueryScan:
+    """OPA queries in a repository and the policy findings they resolve to."""
+
+    queries: list[OpaQuery] = field(default_factory=list)
+    rego_files: list[str] = field(default_factory=list)
+    findings: list[ConfigFinding] = field(default_factory=list)
+
+    @property
+    def unresolved(self) -> list[str]:
+        """Data paths queried that no Rego package in the repository defines."""
+        return sorted({q.path for q in self.queries if q.package is None})
+
+
+def _short(code: str) -> str:
+    """A source line trimmed for use as a snippet."""
+    code = code.strip()
+    return code if len(code) <= MAX_SNIPPET_LENGTH else code[:MAX_SNIPPET_LENGTH] + "..."
+
+
+def _route(lines: list[str], index: int) -> tuple[tuple[str, str], int] | None:
+    """Route and line number of the registration nearest above a line in its handler.
+
+    Inline handlers are registered on their first line, e.g. r.GET("/x", func(c *gin.Context) {,
+    and named ones in the decorators and attributes directly above it.
+    """
+    start = None
+    for number in range(index, max(-1, index - MAX_HANDLER_LINES), -1):
+        line = lines[number]
+        if len(line) > MAX_LINE_LENGTH:
+            continue
+        routes = EndpointMappingService.find_routes(line)
+        if routes:
+            return routes[0], number + 1
+        if HANDLER_START.match(line):
+            start = number
+            break
+    if start is None:
+        return None
+    for number in range(start - 1, max(-1, start - 1 - HEADER_LINES), -1):
+        if not DECORATOR_LINE.match(lines[number]):
+            break
+        routes = EndpointMappingService.find_routes(lines[number])
+        if routes:
+            return routes[0], number + 1
+    return None
+
+
+def find_queries(file_path: str, text: str) -> list[OpaQuery]:
+    """Find the OPA decisions a source file asks for.
+
+    Args:
+        file_path: Relative path of the file
+        text: File content
+
+    Returns:
+        Queries in file order, each attributed to the route of its handler when there is one
+    """
+    if not OPA_QUERY_NEEDLE.search(text):
+        return []
+    found: list[tuple[int, str, str]] = []
+    for match in REGO_QUERY.finditer(text):
+        found.append((match.start(), "rego", match.group(1).strip(".").replace(".", "/")))
+    for match in DECISION.finditer(text):
+        option = DECISION_PATH.search(text, match.end(), match.end() + MAX_ARGUMENT_SPAN)
+        if option:
+            found.append((match.start(), "sdk", option.group(1)))
+    for match in DATA_API.finditer(text):
+        found.append((match.start(), "http", match.group(1)))
+    if not found:
+        return []
+
+    lines = text.split("\n")
+    offsets = [0]
+    for line in lines[:-1]:
+        offsets.append(offsets[-1] + len(line) + 1)
+
+    queries: list[OpaQuery] = []
+    for offset, style, path in sorted(found):
+        path = path.strip("/")
+        index = bisect_right(offsets, offset) - 1
+        if not path or any(q.line == index + 1 and q.path == path for q in queries):
+            continue
+        query = OpaQuery(file_path, index + 1, style, path, _short(lines[index]))
+        route = _route(lines, index)
+        if route is not None:
+            query.route, query.route_line = route
+            query.route_code = _short(lines[query.route_line - 1])
+        queries.append(query)
+    return queries
+
+
+def _packages(files: dict[str, str]) -> dict[str, list[str]]:
+    """Rego files by the package they declare, skipping tests."""
+    packages: dict[str, list[str]] = {}
+    for path, text in files.items():
+        if path.endswith("_test.rego"):
+            continue
+        match = REGO_PACKAGE.search(text)
+        if match:
+            packages.setdefault(match.group(1), []).append(path)
+    return packages
+
+
+def _resolve(query: OpaQuery, packages: dict[str, list[str]]) -> None:
+    """Set the package and rule a query's data path names, preferring the longest package."""
+    parts = query.path.split("/")
+    for size in range(len(parts), 0, -1):
+        package = ".".join(parts[:size])
+        if package in packages:
+            query.package = package
+            query.rule = parts[size] if size < len(parts) else None
+            return
+
+
+def _evidence(query: OpaQuery) -> tuple[str, int, int, str]:
+    """Related evidence citing a query and the route registration of its handler."""
+    if query.route_line is None or query.route_line == query.line:
+        return (query.file_path, query.line, query.line, query.code)
+    return (query.file_path, query.route_line, query.line, f"{query.route_code}\n{query.code}")
+
+
+def extract_opa_queries(sources: dict[str, str], files: dict[str, str]) -> OpaQueryScan:
+    """Resolve the OPA queries in a repository's sources to the Rego rules they evaluate.
+
+    A rule whose body names no path of its own is reported once per route
+    that queries it, so every such endpoint maps to the rule; queries made
+    outside a handler, e.g. in middleware, cite the rule as it is.
+
+    Args:
+        sources: Relative path -> content of source files
+        files: Relative path -> content of .rego files
+
+    Returns:
+        Queries found, the Rego files defining queried packages, and a finding per rule and route
+    """
+    scan = OpaQueryScan()
+    packages = _packages(files)
+    for path in sorted(sources):
+        scan.queries.extend(find_queries(path, sources[path]))
+
+    rules: dict[tuple[str, str | None], list[ConfigFinding]] = {}
+    grouped: dict[tuple[str, int, str, str], ConfigFinding] = {}
+    queried: dict[tuple[str, int, str, str], list[OpaQuery]] = {}
+    for query in scan.queries:
+        _resolve(query, packages)
+        if query.package is None:
+            continue
+        key = (query.package, query.rule)
+        if key not in rules:
+            rules[key] = [f for p in packages[query.package] for f in extract_rego(p, files[p], query.rule)]
+        for rule in rules[key]:
+            resource, action = rule.resource, rule.action
+            if query.route and not resource.startswith("/"):
+                method, route = query.route
+                resource = route
+                action = action.replace("access", method) if action.endswith("access") else action
+            group = (rule.file_path, rule.line_start, resource, action)
+            finding = grouped.get(group)
+            if finding is None:
+                finding = ConfigFinding(
+                    kind=rule.kind,
+                    file_path=rule.file_path,
+                    line_start=rule.line_start,
+                    line_end=rule.line_end,
+                    snippet=rule.snippet,
+                    subject=rule.subject,
+                    resource=resource,
+                    action=action,
+                    conditions=rule.conditions,
+                    description=rule.description,
+                )
+                grouped[group] = finding
+                queried[group] = []
+            evidence = _evidence(query)
+            if evidence not in finding.related_evidence:
+                finding.related_evidence.append(evidence)
+                queried[group].append(query)
+
+    for group, finding in grouped.items():
+        first, others = queried[group][0], len(queried[group]) - 1
+        finding.description += f" queried at {first.file_path}:{first.line}"
+        if others:
+            finding.description += f" and {others} other call site{'s' if others > 1 else ''}"
+        scan.findings.append(finding)
+    scan.rego_files = sorted({f.file_path for f in scan.findings})
+    return scan
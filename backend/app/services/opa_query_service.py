@@ -0,0 +1,110 @@
+"""Service for importing the Rego policies application code queries OPA with.
+
+Endpoints that ask OPA for a decision (rego.New, opa.Decision, or HTTP calls
+to /v1/data/...) keep their rules in the repository's Rego packages (see
+opa_query_extractor). Each scan resolves the queried packages, imports the
+rules they evaluate, and cites the rule and the querying handlers' routes as
+evidence so those endpoints map to the rule instead of showing as
+unprotected.
+"""
+
+from collections.abc import Iterator
+from dataclasses import asdict
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.config_policy_service import ConfigPolicyService
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.opa_query_extractor import OPA_QUERY_NEEDLE, OPA_QUERY_SUFFIXES, REGO_SUFFIX, extract_opa_queries
+
+logger = structlog.get_logger(__name__)
+
+# Policies created by this service are tagged with this source
+OPA_QUERY_LABEL = "OPA query"
+
+
+def load_sources(root: Path) -> tuple[dict[str, str], dict[str, str]]:
+    """Read a clone's candidate OPA call sites and Rego files.
+
+    Args:
+        root: Repository clone root
+
+    Returns:
+        (sources that may query OPA, .rego files), each relative path -> content
+    """
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    sources: dict[str, str] = {}
+    files: dict[str, str] = {}
+    for path in sorted(root.rglob("*")):
+        relative = path.relative_to(root)
+        suffix = path.suffix.lower()
+        if suffix not in OPA_QUERY_SUFFIXES and suffix != REGO_SUFFIX:
+            continue
+        if SKIPPED_DIRECTORIES.intersection(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        text = path.read_text(encoding="utf-8", errors="replace")
+        if suffix == REGO_SUFFIX:
+            files[relative.as_posix()] = text
+        elif OPA_QUERY_NEEDLE.search(text):
+            sources[relative.as_posix()] = text
+    return sources, files
+
+
+def mine_checkout(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the OPA queries of a clone and the Rego rules they resolve to."""
+    scan = extract_opa_queries(*load_sources(Path(root)))
+    yield {
+        "queries": len(scan.queries),
+        "routes": len({q.route for q in scan.queries if q.route and q.package}),
+        "packages": sorted({q.package for q in scan.queries if q.package}),
+        "unresolved_paths": scan.unresolved,
+        "rego_files": scan.rego_files,
+        "findings": [asdict(f) for f in scan.findings],
+    }
+
+
+class OpaQueryService(ConfigPolicyService):
+    """Imports the Rego rules a repository's code queries OPA for."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        super().__init__(db, tenant_id)
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def scan_repository(self, repository_id: int) -> dict:
+        """Import the Rego rules a repository queries OPA for.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Summary of queries found and resolved, with merge results
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        (scan,) = run_pass(root, mine_checkout)
+        findings = [ConfigFinding(**f) for f in scan.pop("findings")]
+        merge = self.merge_findings(repo, findings, OPA_QUERY_LABEL, previous=["%"], label_scoped=True)
+
+        logger.info(
+            "opa_query_policies_imported",
+            repository_id=repo.id,
+            queries=scan["queries"],
+            unresolved=len(scan["unresolved_paths"]),
+            rules=len(findings),
+            tenant_id=self.tenant_id,
+        )
+        return {"repository_id": repo.id, **scan, "rules": len(findings), **merge}
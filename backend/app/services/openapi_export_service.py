@@ -0,0 +1,301 @@
+"""Service for annotating OpenAPI documents with mined authorization.
+
+API gateways read who may call an operation from its OpenAPI document. This
+export takes a service's OpenAPI 3 document, or generates one from the mined
+routes, and sets on every operation a mined policy covers:
+
+- ``security``: the schemes the evidence shows callers authenticating with
+  (JWT bearer, API key, session cookie, or mutual TLS), or ``[]`` when
+  anonymous callers are allowed, overriding any document-wide requirement;
+- ``x-required-roles``: the roles of which the caller needs one, as mined.
+
+    /api/expenses/{id}/approve:
+      put:
+        security:
+          - bearerAuth: [MANAGER, DIRECTOR]
+        x-required-roles: [MANAGER, DIRECTOR]
+
+OpenAPI 3.1 lets a requirement on any scheme list the roles it needs, so
+3.1 documents carry them in ``security`` as well; 3.0 only allows OAuth
+scopes there. Schemes the document already declares are reused (an existing
+HTTP bearer scheme rather than a new ``bearerAuth``, so the gateway keeps its
+configuration); others are added. When the evidence names no mechanism, the
+document-wide requirement is used, or JWT bearer if there is none. Mutual
+TLS needs OpenAPI 3.1, so 3.0 documents keep their own requirement for it.
+
+Operations are matched to mined endpoints by method and route, with and
+without the server's base path. Operations no mined policy covers are left
+as they are and listed, as are mined endpoints the document lacks.
+"""
+
+import copy
+import re
+from urllib.parse import urlparse
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus, SourceType
+from app.models.repository import Repository
+from app.services.auth_mechanism_service import AuthMechanism, rule_mechanisms
+from app.services.coverage_metrics_service import route_key
+from app.services.endpoint_mapping_service import HTTP_METHODS, EndpointMappingService, EndpointRule
+from app.services.live_discovery_service import load_openapi_document
+
+logger = structlog.get_logger(__name__)
+
+ROLES_EXTENSION = "x-required-roles"
+
+# Scheme added for each mechanism when the document declares none like it
+SCHEMES: dict[AuthMechanism, tuple[str, dict]] = {
+    AuthMechanism.JWT_BEARER: ("bearerAuth", {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}),
+    AuthMechanism.API_KEY: ("apiKeyAuth", {"type": "apiKey", "in": "header", "name": "X-API-Key"}),
+    AuthMechanism.COOKIE_SESSION: ("cookieAuth", {"type": "apiKey", "in": "cookie", "name": "session"}),
+    AuthMechanism.MTLS: ("mutualTLS", {"type": "mutualTLS"}),
+}
+
+# Scheme types whose requirements list OAuth scopes rather than roles
+SCOPED_TYPES = ("oauth2", "openIdConnect")
+
+
+def parse_spec(text: str) -> dict:
+    """Parse an OpenAPI 3 document given as JSON or YAML.
+
+    Raises:
+        ValueError: If the text is not an OpenAPI 3 document
+    """
+    document = load_openapi_document(text)
+    if document is None:
+        raise ValueError("Not an OpenAPI document")
+    if not str(document.get("openapi", "")).startswith("3."):
+        raise ValueError("Only OpenAPI 3 documents can be annotated; convert Swagger 2 documents first")
+    return document
+
+
+def openapi_path(path: str) -> str:
+    """Route path with parameters as OpenAPI templates, e.g. /api/expenses/{id} from /api/expenses/:id."""
+    path = re.sub(r"<(?:[^:>]*:)?([^>]+)>", r"{\1}", path)
+    path = re.sub(r":(\w+)", r"{\1}", path)
+    count = iter(range(1, 100))
+    path = re.sub(r"\{\}", lambda _: f"{{param{next(count)}}}", path)
+    return path.rstrip("/") or "/"
+
+
+def matches_scheme(mechanism: AuthMechanism, scheme: dict) -> bool:
+    """Whether a declared security scheme is how callers authenticate with a mechanism."""
+    kind = scheme.get("type")
+    if mechanism == AuthMechanism.JWT_BEARER:
+        return (kind == "http" and str(scheme.get("scheme", "")).lower() == "bearer") or kind in SCOPED_TYPES
+    if mechanism == AuthMechanism.API_KEY:
+        return kind == "apiKey" and scheme.get("in") in ("header", "query")
+    if mechanism == AuthMechanism.COOKIE_SESSION:
+        return kind == "apiKey" and scheme.get("in") == "cookie"
+    if mechanism == AuthMechanism.MTLS:
+        return kind == "mutualTLS"
+    return False
+
+
+def merge_rules(rules: list[EndpointRule]) -> EndpointRule:
+    """One rule for endpoints written with different parameter syntaxes, e.g. :id and {id}."""
+    merged = copy.copy(rules[0])
+    merged.roles = list(merged.roles)
+    merged.policy_ids = list(merged.policy_ids)
+    for rule in rules[1:]:
+        merged.roles = [] if not (merged.roles and rule.roles) else merged.roles + [
+            r for r in rule.roles if r not in merged.roles
+        ]
+        merged.requires_authentication = merged.requires_authentication and rule.requires_authentication
+        merged.policy_ids += rule.policy_ids
+    return merged
+
+
+class OpenApiExportService:
+    """Annotates OpenAPI documents with the security requirements of mined policies."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    @staticmethod
+    def generate(repository: Repository, rules: list[EndpointRule]) -> dict:
+        """OpenAPI 3.1 document with one operation per mined endpoint."""
+        paths: dict[str, dict] = {}
+        for rule in rules:
+            path = openapi_path(rule.path)
+            operation_id = re.sub(r"[^A-Za-z0-9]+", "_", f"{rule.method.lower()} {path}").strip("_")
+            operation = {"operationId": operation_id}
+            if rule.action or rule.resource:
+                operation["summary"] = " ".join(f"{rule.action} {rule.resource}".split())
+            parameters = [
+                {"name": name, "in": "path", "required": True, "schema": {"type": "string"}}
+                for name in re.findall(r"\{([^}]+)\}", path)
+            ]
+            if parameters:
+                operation["parameters"] = parameters
+            operation["responses"] = {"default": {"description": "Response"}}
+            paths.setdefault(path, {}).setdefault(rule.method.lower(), operation)
+        return {
+            "openapi": "3.1.0",
+            "info": {"title": repository.name, "version": "mined"},
+            "paths": paths,
+        }
+
+    def export(
+        self,
+        repository_id: int,
+        spec: dict | None = None,
+        min_confidence: float | None = None,
+        include_pending: bool = False,
+    ) -> dict:
+        """OpenAPI document annotated with the security requirements of a repository's mined policies.
+
+        Args:
+            repository_id: Repository ID
+            spec: OpenAPI 3 document to annotate; generated from the mined routes when omitted
+            min_confidence: Leave out policies scored below this confidence (0-100)
+            include_pending: Also export policies that have not been approved
+
+        Returns:
+            The annotated document, what was set on each operation, and a summary
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        query = self._query(Policy).filter(
+            Policy.repository_id == repository.id, Policy.source_type != SourceType.DATABASE
+        )
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        candidates = query.order_by(Policy.id).all()
+        policies = [
+            p for p in candidates
+            if min_confidence is None or (p.confidence_score is not None and p.confidence_score >= min_confidence)
+        ]
+
+        by_id = {p.id: p for p in policies}
+        grouped: dict[str, list[EndpointRule]] = {}
+        for rule in EndpointMappingService.map_policies(policies):
+            grouped.setdefault(route_key(rule.method, rule.path), []).append(rule)
+        rules = {key: merge_rules(group) for key, group in grouped.items()}
+
+        generated = spec is None
+        if generated:
+            document = self.generate(repository, [group[0] for group in grouped.values()])
+        else:
+            document = copy.deepcopy(spec)
+        version_31 = str(document.get("openapi", "")).startswith("3.1")
+        servers = document.get("servers") or [{}]
+        base_path = urlparse(str(servers[0].get("url") or "")).path.rstrip("/")
+        declared = document.setdefault("components", {}).setdefault("securitySchemes", {})
+        document_security = document.get("security")
+
+        def scheme_for(mechanism: AuthMechanism) -> str | None:
+            """Name of the declared scheme for a mechanism, declaring it if needed."""
+            if mechanism == AuthMechanism.MTLS and not version_31:
+                return None
+            for name, scheme in declared.items():
+                if isinstance(scheme, dict) and matches_scheme(mechanism, scheme):
+                    return name
+            name, scheme = SCHEMES[mechanism]
+            declared.setdefault(name, scheme)
+            return name
+
+        def requirement(name: str, roles: list[str]) -> dict:
+            """Security requirement on a scheme, listing the roles where the scheme allows it."""
+            scoped = declared.get(name, {}).get("type") in SCOPED_TYPES
+            return {name: list(roles) if version_31 and not scoped else []}
+
+        operations, unmatched, matched_keys = [], [], set()
+        for path, item in (document.get("paths") or {}).items():
+            if not isinstance(item, dict):
+                continue
+            for method, operation in item.items():
+                if method.upper() not in HTTP_METHODS or not isinstance(operation, dict):
+                    continue
+                keys = [route_key(method, path), route_key(method, f"{base_path}{path}")]
+                key = next((k for k in keys if k in rules), None)
+                if key is None:
+                    unmatched.append(f"{method.upper()} {path}")
+                    continue
+                matched_keys.add(key)
+                rule = rules[key]
+                mechanisms = rule_mechanisms(rule, by_id)
+                if rule.is_public:
+                    operation["security"] = []
+                elif mechanisms == [AuthMechanism.UNKNOWN]:
+                    if document_security:
+                        operation["security"] = [
+                            {n: requirement(n, rule.roles)[n] for n in r} for r in document_security
+                        ]
+                    else:
+                        operation["security"] = [requirement(scheme_for(AuthMechanism.JWT_BEARER), rule.roles)]
+                else:
+                    # Callers may authenticate with any mechanism the evidence shows
+                    names = [n for n in (scheme_for(m) for m in mechanisms) if n]
+                    if names:
+                        operation["security"] = [requirement(n, rule.roles) for n in names]
+                if rule.roles and not rule.is_public:
+                    operation[ROLES_EXTENSION] = list(rule.roles)
+                else:
+                    operation.pop(ROLES_EXTENSION, None)
+                operations.append(
+                    {
+                        "method": method.upper(),
+                        "path": path,
+                        "mechanisms": [m.value for m in mechanisms],
+                        "security": operation.get("security"),
+                        "required_roles": operation.get(ROLES_EXTENSION, []),
+                        "mined_policy_ids": rule.policy_ids,
+                    }
+                )
+        if not declared:
+            del document["components"]["securitySchemes"]
+            if not document["components"]:
+                del document["components"]
+
+        logger.info(
+            "openapi_annotated",
+            repository_id=repository.id,
+            generated=generated,
+            operations=len(operations),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repository.id,
+            "generated": generated,
+            "spec": document,
+            "operations": operations,
+            "unmatched_operations": unmatched,
+            "unmatched_endpoints": sorted(k for k in rules if k not in matched_keys),
+            "summary": {
+                "policies": len(policies),
+                "operations_annotated": len(operations),
+                "public_operations": sum(1 for o in operations if o["security"] == []),
+                "unmatched_operations": len(unmatched),
+                "unmatched_endpoints": len(rules) - len(matched_keys),
+                "security_schemes": len(declared),
+                "below_min_confidence": len(candidates) - len(policies),
+            },
+        }
@@ -0,0 +1,375 @@
+"""Attribute mined rules to owning teams via CODEOWNERS and git blame.
+
+Each policy's evidence file is matched against the repository's CODEOWNERS
+file (CODEOWNERS, .github/CODEOWNERS, .gitlab/CODEOWNERS, or
+docs/CODEOWNERS) with GitHub's semantics: gitignore-style patterns, and the
+last matching rule wins. When no rule matches, the authors git blame names
+for the evidence lines own the policy. Blame is recorded either way so a
+team view can show who last touched a rule.
+
+Work items raised for owned policies are assigned to the first owner when
+nobody has picked them up yet, which routes findings to the right team
+without a triage pass.
+"""
+
+import re
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import dataclass, field, replace
+from datetime import datetime
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import Sandbox, SandboxLimits, run_pass
+from app.models.ownership import OwnershipSource, PolicyOwnership
+from app.models.policy import Policy, PolicyStatus
+from app.models.policy_change import PolicyChange, WorkItem, WorkItemStatus
+from app.models.repository import Repository
+
+logger = structlog.get_logger(__name__)
+
+# Where GitHub and GitLab look for CODEOWNERS, in precedence order
+CODEOWNERS_LOCATIONS = ("CODEOWNERS", ".github/CODEOWNERS", ".gitlab/CODEOWNERS", "docs/CODEOWNERS")
+
+# GitLab section headers, e.g. "[Payments]" or "^[Docs][2] @acme/writers"
+SECTION_HEADER = re.compile(r"^\^?\[[^\]]+\]")
+
+OWNER_TOKEN = re.compile(r"^(?:@[\w.\-]+(?:/[\w.\-]+)?|[^@\s]+@[^@\s]+\.[^@\s]+)$")
+
+# Authors kept per policy from blame
+MAX_AUTHORS = 5
+
+OPEN_WORK_ITEM_STATUSES = (WorkItemStatus.OPEN, WorkItemStatus.IN_PROGRESS)
+
+
+@dataclass
+class CodeOwnersRule:
+    """One CODEOWNERS line."""
+
+    pattern: str
+    owners: list[str]
+    regex: re.Pattern = field(repr=False)
+
+
+def pattern_regex(pattern: str) -> re.Pattern:
+    """Compile a CODEOWNERS (gitignore-style) pattern into a path regex.
+
+    A pattern with a leading or inner slash is anchored to the repository
+    root; otherwise it matches at any depth. A pattern matching a directory
+    also matches everything beneath it.
+    """
+    directory = pattern.endswith("/")
+    body = pattern.strip("/")
+    anchored = pattern.startswith("/") or "/" in body
+    out, i = [], 0
+    while i < len(body):
+        if body.startswith("**/", i):
+            out.append("(?:.*/)?")
+            i += 3
+        elif body.startswith("**", i):
+            out.append(".*")
+            i += 2
+        elif body[i] == "*":
+            out.append("[^/]*")
+            i += 1
+        elif body[i] == "?":
+            out.append("[^/]")
+            i += 1
+        else:
+            out.append(re.escape(body[i]))
+            i += 1
+    prefix = "^" if anchored else "^(?:.*/)?"
+    suffix = "/.*$" if directory else "(?:/.*)?$"
+    return re.compile(prefix + "".join(out) + suffix)
+
+
+def parse_codeowners(text: str) -> list[CodeOwnersRule]:
+    """Parse CODEOWNERS rules in file order.
+
+    Lines without owners are kept: on GitHub they unassign a path that an
+    earlier rule gave owners.
+    """
+    rules = []
+    for line in text.splitlines():
+        line = re.sub(r"(?:^|\s)#.*$", "", line).strip()
+        if not line or SECTION_HEADER.match(line):
+            continue
+        pattern, *tokens = line.split()
+        owners = [token for token in tokens if OWNER_TOKEN.match(token)]
+        rules.append(CodeOwnersRule(pattern=pattern, owners=owners, regex=pattern_regex(pattern)))
+    return rules
+
+
+def match_owners(rules: list[CodeOwnersRule], file_path: str) -> CodeOwnersRule | None:
+    """The rule that decides a file's owners: the last one matching."""
+    path = file_path.removeprefix("./").lstrip("/")
+    for rule in reversed(rules):
+        if rule.regex.match(path):
+            return rule
+    return None
+
+
+def load_codeowners(root: Path) -> tuple[str | None, list[CodeOwnersRule]]:
+    """The CODEOWNERS file of a clone and its rules."""
+    for location in CODEOWNERS_LOCATIONS:
+        path = root / location
+        if path.is_file():
+            return location, parse_codeowners(path.read_text(encoding="utf-8", errors="ignore"))
+    return None, []
+
+
+@dataclass
+class BlameSummary:
+    """Who wrote a range of lines, per git blame."""
+
+    authors: list[str] = field(default_factory=list)  # Most lines first
+    last_commit: str | None = None
+    last_modified_at: datetime | None = None
+
+
+def blame_lines(root: Path, file_path: str, line_start: int, line_end: int) -> BlameSummary:
+    """Blame a line range of a file in a clone.
+
+    Args:
+        root: Repository clone root
+        file_path: Repository-relative path
+        line_start: First line (1-based)
+        line_end: Last line
+
+    Returns:
+        Authors by number of lines and the most recent commit touching the
+        range; empty when the range cannot be blamed
+    """
+    from git import GitCommandError, InvalidGitRepositoryError, NoSuchPathError, Repo
+
+    try:
+        entries = Repo(root).blame("HEAD", file_path, L=f"{max(line_start, 1)},{max(line_end, line_start, 1)}")
+    except (GitCommandError, InvalidGitRepositoryError, NoSuchPathError, ValueError):
+        return BlameSummary()
+
+    lines: Counter[str] = Counter()
+    latest = None
+    for commit, commit_lines in entries:
+        lines[commit.author.email] += len(commit_lines)
+        if latest is None or commit.committed_datetime > latest.committed_datetime:
+            latest = commit
+    return BlameSummary(
+        authors=[email for email, _ in lines.most_common(MAX_AUTHORS)],
+        last_commit=latest.hexsha if latest else None,
+        last_modified_at=latest.committed_datetime if latest else None,
+    )
+
+
+def resolve_owners(root: str, evidence: list[list[list]]) -> Iterator[dict]:
+    """Sandbox pass yielding, per policy, its CODEOWNERS owners and the blame of its first evidence.
+
+    Args:
+        root: Repository clone root
+        evidence: Per policy, its evidence as [file_path, line_start, line_end], sorted by path and line
+
+    Yields:
+        Per policy, in order: owners, the CODEOWNERS file and pattern that
+        matched first, and blame authors and last commit
+    """
+    clone = Path(root)
+    codeowners_file, rules = load_codeowners(clone)
+    blamed = (clone / ".git").exists()
+    for items in evidence:
+        owners: list[str] = []
+        matched: CodeOwnersRule | None = None
+        for file_path, _, _ in items:
+            rule = match_owners(rules, file_path)
+            if rule is not None:
+                matched = matched or rule
+                owners.extend(o for o in rule.owners if o not in owners)
+
+        blame = BlameSummary()
+        if items and blamed:
+            file_path, line_start, line_end = items[0]
+            blame = blame_lines(clone, file_path, line_start, line_end or line_start)
+        yield {
+            "owners": owners,
+            "codeowners_file": codeowners_file if matched else None,
+            "codeowners_pattern": matched.pattern if matched else None,
+            "authors": blame.authors,
+            "last_commit": blame.last_commit,
+            "last_modified_at": blame.last_modified_at.isoformat() if blame.last_modified_at else None,
+        }
+
+
+class OwnershipService:
+    """Attributes policies to owners and exposes per-team views."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def attribute(self, repository_id: int, force: bool = False) -> int:
+        """Attribute a repository's policies to owners from its clone.
+
+        Args:
+            repository_id: Repository that was just scanned
+            force: Re-attribute policies that already have owners, e.g. after
+                CODEOWNERS changed
+
+        Returns:
+            Number of policies attributed
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        if self._query(Repository).filter(Repository.id == repository_id).first() is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        root = self.clone_dir / str(repository_id)
+
+        existing = {
+            o.policy_id: o
+            for o in self._query(PolicyOwnership).filter(PolicyOwnership.repository_id == repository_id).all()
+        }
+        policies = [
+            p
+            for p in self._query(Policy).filter(Policy.repository_id == repository_id).all()
+            if p.id not in existing or force
+        ]
+        evidence = [sorted(p.evidence or [], key=lambda e: (e.file_path or "", e.line_start or 0)) for p in policies]
+        located = [[[e.file_path, e.line_start, e.line_end] for e in items] for items in evidence]
+        # Blame runs git, so this pass may start processes; without a clone there is nothing to read
+        sandbox = Sandbox(root, replace(SandboxLimits.from_settings(), processes=True))
+        resolved = (
+            run_pass(root, resolve_owners, sandbox, evidence=located)
+            if root.is_dir()
+            else resolve_owners(str(root), located)
+        )
+        attributed = 0
+        for policy, items, owners in zip(policies, evidence, resolved, strict=True):
+            ownership = existing.get(policy.id)
+            if ownership is None:
+                ownership = PolicyOwnership(tenant_id=self.tenant_id, repository_id=repository_id, policy_id=policy.id)
+                self.db.add(ownership)
+            self._resolve(ownership, items, owners)
+            attributed += 1
+        if attributed:
+            self.db.commit()
+        logger.info("policy_ownership_attributed", repository_id=repository_id, policies=attributed)
+        return attributed
+
+    @staticmethod
+    def _resolve(ownership: PolicyOwnership, evidence: list, resolved: dict) -> None:
+        """Fill in a policy's owners from CODEOWNERS, falling back to blame."""
+        owners = resolved["owners"]
+        if owners:
+            source = OwnershipSource.CODEOWNERS
+        elif resolved["authors"]:
+            owners, source = resolved["authors"][:1], OwnershipSource.BLAME
+        else:
+            source = OwnershipSource.UNOWNED
+        ownership.owners, ownership.source = owners, source
+        ownership.codeowners_file = resolved["codeowners_file"]
+        ownership.codeowners_pattern = resolved["codeowners_pattern"]
+        ownership.file_path = evidence[0].file_path if evidence else None
+        ownership.authors, ownership.last_commit = resolved["authors"], resolved["last_commit"]
+        last_modified_at = resolved["last_modified_at"]
+        ownership.last_modified_at = datetime.fromisoformat(last_modified_at) if last_modified_at else None
+
+    def route_work_items(self, repository_id: int) -> int:
+        """Assign unassigned open work items of owned policies to their first owner.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Number of work items assigned
+        """
+        owners = {
+            o.policy_id: o.owners
+            for o in self._query(PolicyOwnership).filter(PolicyOwnership.repository_id == repository_id).all()
+            if o.owners
+        }
+        items = (
+            self._query(WorkItem)
+            .filter(WorkItem.repository_id == repository_id)
+            .filter(WorkItem.assigned_to.is_(None))
+            .filter(WorkItem.status.in_(OPEN_WORK_ITEM_STATUSES))
+            .all()
+        )
+        changes = {c.id: c for c in self._query(PolicyChange).filter(PolicyChange.repository_id == repository_id).all()}
+        routed = 0
+        for item in items:
+            change = changes.get(item.policy_change_id)
+            policy_id = change and (change.policy_id or change.previous_policy_id)
+            if policy_id in owners:
+                item.assigned_to = owners[policy_id][0]
+                routed += 1
+        if routed:
+            self.db.commit()
+        logger.info("work_items_routed", repository_id=repository_id, work_items=routed)
+        return routed
+
+    def policy_owners(self, policy_id: int) -> PolicyOwnership:
+        """Owners of a policy.
+
+        Raises:
+            ValueError: If the policy has not been attributed
+        """
+        ownership = self._query(PolicyOwnership).filter(PolicyOwnership.policy_id == policy_id).first()
+        if ownership is None:
+            raise ValueError(f"Policy {policy_id} has no ownership attribution")
+        return ownership
+
+    def teams(self) -> list[dict]:
+        """Per owner: repositories, policies, pending reviews, and open work items.
+
+        Returns:
+            One entry per owner, most policies first; unowned policies are
+            counted under the owner None
+        """
+        ownerships = self._query(PolicyOwnership).all()
+        statuses = {p.id: p.status for p in self._query(Policy).all()}
+        assigned = Counter(
+            item.assigned_to
+            for item in self._query(WorkItem).filter(WorkItem.status.in_(OPEN_WORK_ITEM_STATUSES)).all()
+            if item.assigned_to
+        )
+        teams: dict[str | None, dict] = {}
+        for ownership in ownerships:
+            for owner in ownership.owners or [None]:
+                team = teams.setdefault(owner, {"team": owner, "repository_ids": set(), "policies": 0, "pending_policies": 0})
+                team["repository_ids"].add(ownership.repository_id)
+                team["policies"] += 1
+                team["pending_policies"] += statuses.get(ownership.policy_id) == PolicyStatus.PENDING
+        results = [
+            {**team, "repository_ids": sorted(team["repository_ids"]), "open_work_items": assigned.get(team["team"], 0)}
+            for team in teams.values()
+        ]
+        results.sort(key=lambda t: (-t["policies"], t["team"] is None, t["team"] or ""))
+        return results
+
+    def team_policies(self, team: str) -> list[tuple[Policy, PolicyOwnership]]:
+        """Policies a team owns, with their attribution, newest first."""
+        ownerships = {o.policy_id: o for o in self._query(PolicyOwnership).all() if team in (o.owners or [])}
+        if not ownerships:
+            return []
+        policies = self._query(Policy).filter(Policy.id.in_(ownerships)).order_by(Policy.id.desc()).all()
+        return [(p, ownerships[p.id]) for p in policies]
+
+    def team_work_items(self, team: str) -> list[WorkItem]:
+        """Open work items routed to a team, newest first."""
+        return (
+            self._query(WorkItem)
+            .filter(WorkItem.assigned_to == team)
+            .filter(WorkItem.status.in_(OPEN_WORK_ITEM_STATUSES))
+            .order_by(WorkItem.id.desc())
+            .all()
+        )
@@ -0,0 +1,400 @@
+"""Service assisting the migration from inline authorization checks to PDP calls.
+
+Every inline check a repository's approved policies were mined from becomes
+a call site to migrate. Each gets the equivalent PDP query, as an OPA input
+document and as a Cedar is_authorized request shaped like the ones the
+scaffold's integration stub sends, and a replacement snippet calling that
+stub in the file's language. Attributes the policy's conditions read, such
+as amount in "amount < 5000", are added to both queries as placeholders.
+
+On each refresh the clone is checked again: a call site is pending while
+its check is still in the file, migrated once the check is gone and the file
+asks the PDP, and removed when the check disappeared with no PDP call in its
+place. The share of migrated call sites is stored per refresh, so progress
+can be charted per repository over time.
+"""
+
+import hashlib
+import json
+import re
+from collections import defaultdict
+from collections.abc import Iterator
+from datetime import UTC, date, datetime
+from pathlib import Path, PurePosixPath
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.pdp_migration import CallSiteStatus, MigrationCallSite, MigrationProgressSnapshot
+from app.models.policy import Policy, PolicyStatus
+from app.models.repository import Repository
+from app.services.policy_scaffold_service import SUFFIX_LANGUAGES
+from app.services.trend_metrics_service import TrendInterval, _aware, bucket_start
+
+logger = structlog.get_logger(__name__)
+
+# Lines of an evidence snippet that carry the check, rather than the handler around it
+CHECK_LINE = re.compile(
+    r"(?i)role|permission|authori[sz]|admin|superuser|is_staff|scope|forbidden|unauthori[sz]ed|\b40[13]\b|deny|"
+    r"allow|\bcan\w*\(|\bhas\w*\(|@PreAuthorize|@Secured|@RolesAllowed|require|owner|login"
+)
+
+# Calls to the integration stub or a PDP in general
+PDP_CALL = re.compile(
+    r"\b(?:is_allowed|isAllowed|IsAllowed)\s*\(|\bauthz\.Authorize\(|\bauthorize\(\s*[\"'][^\"']+[\"']\s*,|"
+    r"/v1/data/|is_authorized|@pdp\."
+)
+
+# Attributes a condition compares, such as amount in "amount < 5000" or the department in "user.department == ..."
+CONDITION_OPERAND = re.compile(r"([A-Za-z_][\w.]*)\s*(?:<=|>=|==|!=|<|>)\s*([A-Za-z_][\w.]*)?")
+CONDITION_KEYWORDS = {"and", "or", "not", "in", "is", "true", "false", "null", "none"}
+USER_PREFIXES = ("user.", "subject.", "principal.", "caller.")
+RESOURCE_PREFIXES = ("request.", "resource.", "req.", "ctx.", "context.", "input.")
+
+COMMENTS = {"javascript": "//", "python": "#", "go": "//", "java": "//"}
+REPLACEMENTS = {
+    "javascript": (
+        'const {{ isAllowed }} = require("./authz/pdp");\n\n'
+        "if (!(await isAllowed(req.user, {action}, {resource}, req.path))) {{\n"
+        '  return res.status(403).json({{ error: "forbidden" }});\n'
+        "}}\n"
+    ),
+    "python": (
+        "from authz.pdp import is_allowed\n\n"
+        "if not await is_allowed(user, {action}, {resource}):\n"
+        '    raise PermissionError("forbidden")\n'
+    ),
+    "go": (
+        "allowed, err := authz.IsAllowed(r.Context(), user, {action}, {resource}, r.URL.Path)\n"
+        "if err != nil || !allowed {{\n"
+        '\thttp.Error(w, "forbidden", http.StatusForbidden)\n'
+        "\treturn\n"
+        "}}\n"
+    ),
+    "java": (
+        "if (!pdp.isAllowed(user.getId(), user.getRoles(), {action}, {resource}, request.getRequestURI())) {{\n"
+        '    throw new AccessDeniedException("forbidden");\n'
+        "}}\n"
+    ),
+}
+
+
+def _normalize(line: str) -> str:
+    """Collapse whitespace so reindented code still matches."""
+    return " ".join(line.split())
+
+
+def check_lines(snippet: str) -> list[str]:
+    """The lines of an evidence snippet that carry the check, normalized."""
+    lines = [_normalize(line) for line in snippet.splitlines() if line.strip()]
+    return [line for line in lines if CHECK_LINE.search(line)] or lines
+
+
+def fingerprint(file_path: str, snippet: str) -> str:
+    """Identity of a call site that survives line number changes."""
+    return hashlib.sha256("\n".join([file_path, *check_lines(snippet)]).encode()).hexdigest()
+
+
+def condition_attributes(conditions: str | None) -> tuple[list[str], list[str]]:
+    """Attributes a policy's conditions read from the caller and from the request or resource.
+
+    Returns:
+        (user attributes, resource attributes)
+    """
+    user, resource = [], []
+    for operands in CONDITION_OPERAND.findall(conditions or ""):
+        for operand in operands:
+            if not operand or operand.lower() in CONDITION_KEYWORDS:
+                continue
+            if operand.startswith(USER_PREFIXES):
+                name, target = operand.split(".", 1)[1], user
+            else:
+                name, target = next((operand[len(p) :] for p in RESOURCE_PREFIXES if operand.startswith(p)), operand), resource
+            if name not in target:
+                target.append(name)
+    return user, resource
+
+
+def opa_query(policy: Policy) -> dict:
+    """OPA input document deciding the policy's check, with placeholders for request values."""
+    user_attributes, resource_attributes = condition_attributes(policy.conditions)
+    user = {"id": "<caller id>", "roles": ["<caller roles>"], **{a: f"<caller {a}>" for a in user_attributes}}
+    resource = {"type": policy.resource, "path": "<request path>", **{a: f"<{a}>" for a in resource_attributes}}
+    return {"input": {"user": user, "action": policy.action, "resource": resource}}
+
+
+def cedar_query(policy: Policy) -> dict:
+    """Cedar is_authorized request deciding the policy's check, with placeholders for request values."""
+    user_attributes, resource_attributes = condition_attributes(policy.conditions)
+    context = {"path": "<request path>", **{a: f"<{a}>" for a in resource_attributes}}
+    context.update({f"principal_{a}": f"<caller {a}>" for a in user_attributes})
+    return {
+        "principal": 'User::"<caller id>"',
+        "action": f'Action::"{policy.action}"',
+        "resource": f'ResourceType::"{policy.resource}"',
+        "context": context,
+    }
+
+
+def replacement_snippet(language: str | None, policy: Policy) -> str | None:
+    """Code asking the PDP through the integration stub in place of the inline check; None without a stub."""
+    if language not in REPLACEMENTS:
+        return None
+    snippet = REPLACEMENTS[language].format(action=json.dumps(policy.action), resource=json.dumps(policy.resource))
+    _, resource_attributes = condition_attributes(policy.conditions)
+    if resource_attributes:
+        note = f"{COMMENTS[language]} The condition {policy.conditions} needs {', '.join(resource_attributes)} in the PDP input"
+        snippet = f"{note}\n{snippet}"
+    return snippet
+
+
+def call_site_status(call_site: MigrationCallSite, text: str | None) -> tuple[CallSiteStatus, int | None]:
+    """Migration state of a call site in the file's current content, and the line its check is now on."""
+    if text is None:
+        return CallSiteStatus.REMOVED, None
+    wanted = set(check_lines(call_site.check_snippet))
+    # Lines the replacement shares with the check, like res.status(403), do not show the check is still there
+    wanted = wanted - set(check_lines(call_site.replacement or "")) or wanted
+    for number, line in enumerate(text.splitlines(), start=1):
+        if _normalize(line) in wanted:
+            return CallSiteStatus.PENDING, number
+    return (CallSiteStatus.MIGRATED if PDP_CALL.search(text) else CallSiteStatus.REMOVED), None
+
+
+def _read(root: Path, file_path: str) -> str | None:
+    """Current content of a file in the clone, None if it is gone."""
+    path = (root / file_path).resolve()
+    if not path.is_relative_to(root.resolve()) or not path.is_file():
+        return None
+    return path.read_text(encoding="utf-8", errors="replace")
+
+
+def check_call_sites(root: str, call_sites: list[dict]) -> Iterator[list]:
+    """Sandbox pass yielding the (status, line) of each call site in the clone's current files, in order.
+
+    Args:
+        root: Repository clone root
+        call_sites: Per call site: file_path, check_snippet, and replacement
+    """
+    contents: dict[str, str | None] = {}
+    for site in call_sites:
+        if site["file_path"] not in contents:
+            contents[site["file_path"]] = _read(Path(root), site["file_path"])
+        status, line = call_site_status(MigrationCallSite(**site), contents[site["file_path"]])
+        yield [status.value, line]
+
+
+def migrated_percent(migrated: int, total: int) -> float:
+    """Share of call sites migrated, as a percentage."""
+    return round(100.0 * migrated / total, 1) if total else 0.0
+
+
+class PdpMigrationService:
+    """Tracks the replacement of inline checks with PDP calls."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repo = self._query(Repository).filter(Repository.id == repository_id).first()
+        if not repo:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repo
+
+    def call_sites(self, repository_id: int, status: CallSiteStatus | None = None) -> list[MigrationCallSite]:
+        """A repository's call sites, by file and line."""
+        repo = self.get_repository(repository_id)
+        query = self._query(MigrationCallSite).filter(MigrationCallSite.repository_id == repo.id)
+        if status is not None:
+            query = query.filter(MigrationCallSite.status == status)
+        return query.order_by(MigrationCallSite.file_path, MigrationCallSite.line_start).all()
+
+    def sync_call_sites(self, repo: Repository) -> list[MigrationCallSite]:
+        """Add a call site for every inline check of the repository's approved policies not tracked yet.
+
+        Returns:
+            All of the repository's call sites
+        """
+        existing = {c.fingerprint: c for c in self._query(MigrationCallSite).filter(MigrationCallSite.repository_id == repo.id)}
+        policies = self._query(Policy).filter(Policy.repository_id == repo.id, Policy.status == PolicyStatus.APPROVED).all()
+        for policy in policies:
+            for evidence in policy.evidence or []:
+                key = fingerprint(evidence.file_path, evidence.code_snippet)
+                if key in existing:
+                    existing[key].policy_id = policy.id
+                    continue
+                language = SUFFIX_LANGUAGES.get(PurePosixPath(evidence.file_path).suffix)
+                call_site = MigrationCallSite(
+                    tenant_id=self.tenant_id,
+                    repository_id=repo.id,
+                    application_id=policy.application_id,
+                    policy_id=policy.id,
+                    fingerprint=key,
+                    file_path=evidence.file_path,
+                    line_start=evidence.line_start,
+                    line_end=evidence.line_end,
+                    language=language,
+                    check_snippet=evidence.code_snippet,
+                    subject=policy.subject,
+                    action=policy.action,
+                    resource=policy.resource,
+                    conditions=policy.conditions,
+                    opa_query=opa_query(policy),
+                    cedar_query=cedar_query(policy),
+                    replacement=replacement_snippet(language, policy),
+                    status=CallSiteStatus.PENDING,
+                )
+                self.db.add(call_site)
+                existing[key] = call_site
+        return list(existing.values())
+
+    def refresh(self, repository_id: int, scan_id: int | None = None) -> MigrationProgressSnapshot:
+        """Track new call sites, re-check every call site against the clone, and store the progress.
+
+        Args:
+            repository_id: Repository ID
+            scan_id: Scan that triggered the refresh
+
+        Returns:
+            The stored progress snapshot
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        now = datetime.now(UTC)
+        call_sites = self.sync_call_sites(repo)
+        checked = run_pass(
+            root,
+            check_call_sites,
+            call_sites=[
+                {"file_path": c.file_path, "check_snippet": c.check_snippet, "replacement": c.replacement}
+                for c in call_sites
+            ],
+        )
+        for call_site, (status, line) in zip(call_sites, checked, strict=True):
+            status = CallSiteStatus(status)
+            if line is not None:
+                call_site.line_end = line + (call_site.line_end - call_site.line_start)
+                call_site.line_start = line
+            if status == CallSiteStatus.MIGRATED and call_site.status != CallSiteStatus.MIGRATED:
+                call_site.migrated_at = now
+            elif status != CallSiteStatus.MIGRATED:
+                call_site.migrated_at = None
+            call_site.status = status
+            call_site.checked_at = now
+
+        counts = defaultdict(int)
+        for call_site in call_sites:
+            counts[call_site.status] += 1
+        total = counts[CallSiteStatus.PENDING] + counts[CallSiteStatus.MIGRATED]
+        snapshot = MigrationProgressSnapshot(
+            tenant_id=self.tenant_id,
+            repository_id=repo.id,
+            scan_id=scan_id,
+            total_call_sites=total,
+            migrated_call_sites=counts[CallSiteStatus.MIGRATED],
+            removed_call_sites=counts[CallSiteStatus.REMOVED],
+            migrated_percent=migrated_percent(counts[CallSiteStatus.MIGRATED], total),
+            captured_at=now,
+        )
+        self.db.add(snapshot)
+        self.db.commit()
+
+        logger.info(
+            "pdp_migration_refreshed",
+            repository_id=repo.id,
+            call_sites=len(call_sites),
+            migrated_percent=snapshot.migrated_percent,
+            tenant_id=self.tenant_id,
+        )
+        return snapshot
+
+    def progress_series(
+        self,
+        repository_id: int | None = None,
+        interval: str = TrendInterval.WEEK,
+        since: datetime | None = None,
+    ) -> list[dict]:
+        """Migration progress over time, one point per bucket.
+
+        Within a bucket each repository contributes its latest snapshot;
+        workspace points sum call sites across repositories.
+
+        Args:
+            repository_id: Restrict to one repository (workspace if omitted)
+            interval: day or week
+            since: Earliest capture time to include
+
+        Returns:
+            Points sorted by bucket
+        """
+        query = self._query(MigrationProgressSnapshot)
+        if repository_id is not None:
+            query = query.filter(MigrationProgressSnapshot.repository_id == repository_id)
+        if since is not None:
+            query = query.filter(MigrationProgressSnapshot.captured_at >= since)
+        snapshots = query.order_by(MigrationProgressSnapshot.captured_at).all()
+
+        # Latest snapshot per repository per bucket (ordered query: later wins)
+        buckets: dict[date, dict[int, MigrationProgressSnapshot]] = defaultdict(dict)
+        for snapshot in snapshots:
+            buckets[bucket_start(_aware(snapshot.captured_at), interval)][snapshot.repository_id] = snapshot
+
+        points = []
+        for start in sorted(buckets):
+            latest = list(buckets[start].values())
+            total = sum(s.total_call_sites for s in latest)
+            migrated = sum(s.migrated_call_sites for s in latest)
+            points.append(
+                {
+                    "bucket": start.isoformat(),
+                    "repositories": len(latest),
+                    "total_call_sites": total,
+                    "migrated_call_sites": migrated,
+                    "removed_call_sites": sum(s.removed_call_sites for s in latest),
+                    "migrated_percent": migrated_percent(migrated, total),
+                }
+            )
+        return points
+
+    def overview(self) -> list[dict]:
+        """Latest migration progress of every repository that has been measured."""
+        latest: dict[int, MigrationProgressSnapshot] = {}
+        for snapshot in self._query(MigrationProgressSnapshot).order_by(MigrationProgressSnapshot.captured_at):
+            latest[snapshot.repository_id] = snapshot
+        repositories = self._query(Repository).filter(Repository.id.in_(list(latest))).all() if latest else []
+        names = {r.id: r.name for r in repositories}
+        return [
+            {
+                "repository_id": repository_id,
+                "repository_name": names.get(repository_id),
+                "total_call_sites": s.total_call_sites,
+                "migrated_call_sites": s.migrated_call_sites,
+                "removed_call_sites": s.removed_call_sites,
+                "migrated_percent": s.migrated_percent,
+                "captured_at": s.captured_at,
+            }
+            for repository_id, s in sorted(latest.items(), key=lambda item: item[1].migrated_percent)
+        ]
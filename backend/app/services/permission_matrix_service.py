@@ -0,0 +1,163 @@
+"""Service for building the role x action x resource permission matrix.
+
+The matrix is sparse: a cell exists only where at least one mined policy
+grants a role an action on a resource. Cells whose every granting policy
+carries conditions are marked conditional; a single unconditional policy
+makes the cell a plain allow. Policies granted to any authenticated user or
+to anonymous callers appear under the "authenticated" and "anonymous"
+pseudo-roles.
+"""
+
+from dataclasses import asdict, dataclass, field
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import (
+    ANONYMOUS_ROLE,
+    AUTHENTICATED_ROLE,
+    EndpointMappingService,
+)
+
+logger = structlog.get_logger(__name__)
+
+
+class CellAccess:
+    """Access granted by a matrix cell."""
+
+    ALLOW = "allow"
+    CONDITIONAL = "conditional"
+
+
+@dataclass
+class MatrixCell:
+    """One role/resource/action cell."""
+
+    role: str
+    resource: str
+    action: str
+    access: str = CellAccess.CONDITIONAL
+    conditions: list[str] = field(default_factory=list)
+    policy_ids: list[int] = field(default_factory=list)
+
+
+def _matches(value: str, needle: str | None) -> bool:
+    """Case-insensitive substring filter; an empty needle matches everything."""
+    return not needle or needle.lower() in value.lower()
+
+
+class PermissionMatrixService:
+    """Aggregates mined policies into a permission matrix."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def build(
+        self,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+        include_pending: bool = True,
+        role: str | None = None,
+        resource: str | None = None,
+        action: str | None = None,
+        conditional_only: bool = False,
+        skip: int = 0,
+        limit: int = 50,
+    ) -> dict:
+        """Build the matrix for a repository, an application, or the whole workspace.
+
+        Pagination applies to roles (matrix rows); the column set always
+        covers every resource/action pair that survives the filters, so pages
+        line up in a grid.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+            include_pending: Include policies not yet approved
+            role: Role name filter (substring, case-insensitive)
+            resource: Resource filter (substring, case-insensitive)
+            action: Action filter (substring, case-insensitive)
+            conditional_only: Only keep conditional cells
+            skip: Roles to skip
+            limit: Maximum roles to return
+
+        Returns:
+            Matrix with roles, columns, cells, and pagination totals
+        """
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+            repository_id=repository_id, application_id=application_id, include_pending=include_pending
+        )
+
+        cells = self.aggregate(policies)
+        cells = [
+            cell
+            for cell in cells
+            if _matches(cell.role, role)
+            and _matches(cell.resource, resource)
+            and _matches(cell.action, action)
+            and (not conditional_only or cell.access == CellAccess.CONDITIONAL)
+        ]
+
+        all_roles = sorted({cell.role for cell in cells})
+        page_roles = all_roles[skip : skip + limit]
+        page_role_set = set(page_roles)
+        columns = sorted({(cell.resource, cell.action) for cell in cells})
+
+        logger.info(
+            "permission_matrix_built",
+            repository_id=repository_id,
+            application_id=application_id,
+            policies=len(policies),
+            cells=len(cells),
+            roles=len(all_roles),
+        )
+
+        return {
+            "repository_id": repository_id,
+            "application_id": application_id,
+            "total_roles": len(all_roles),
+            "skip": skip,
+            "limit": limit,
+            "roles": page_roles,
+            "resources": sorted({resource_name for resource_name, _ in columns}),
+            "columns": [{"resource": r, "action": a} for r, a in columns],
+            "cells": [asdict(cell) for cell in cells if cell.role in page_role_set],
+        }
+
+    @staticmethod
+    def aggregate(policies: list) -> list[MatrixCell]:
+        """Aggregate policies into matrix cells.
+
+        Args:
+            policies: Mined policies
+
+        Returns:
+            Cells sorted by role, resource, then action
+        """
+        cells: dict[tuple[str, str, str], MatrixCell] = {}
+
+        for policy in policies:
+            roles, requires_auth = EndpointMappingService.parse_roles(policy.subject)
+            if not roles:
+                roles = [AUTHENTICATED_ROLE if requires_auth else ANONYMOUS_ROLE]
+            resource = (policy.resource or "").strip()
+            action = (policy.action or "").strip().lower()
+            condition = (policy.conditions or "").strip()
+
+            for role in roles:
+                key = (role, resource.lower(), action)
+                cell = cells.get(key)
+                if cell is None:
+                    cell = MatrixCell(role=role, resource=resource, action=action)
+                    cells[key] = cell
+                if policy.id is not None:
+                    cell.policy_ids.append(policy.id)
+                if not condition:
+                    cell.access = CellAccess.ALLOW
+                elif condition not in cell.conditions:
+                    cell.conditions.append(condition)
+
+        return sorted(cells.values(), key=lambda c: (c.role, c.resource.lower(), c.action))
@@ -0,0 +1,538 @@
+"""Extract route authorization from Play Framework (Scala) applications.
+
+Play keeps routing out of controller code: conf/routes maps each
+"VERB /path controllers.Ctrl.action" line to a controller method, and that
+method's authorization is whatever action it is built from - the stock
+Action, a custom ActionBuilder, an `andThen` composition of refiners and
+filters, or a Secured-style wrapper such as withAuth/withRole, Silhouette's
+SecuredAction, or Deadbolt's Restrict. These extractors resolve the
+composition behind each routed action into one ConfigFinding per route,
+without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field, replace
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_of, line_span
+from app.services.jvm_route_extractor import ANONYMOUS, AUTHENTICATED, Access, _strip_comments
+
+# Snippets show at most this many lines of an action
+MAX_SNIPPET_LINES = 30
+
+# Declaration headers and bound expressions are scanned at most this far, so
+# unbalanced input cannot make every declaration scan to the end of the file
+MAX_HEADER_CHARS = 2000
+
+# Definitions an action composition is followed through before giving up
+MAX_RESOLVE_DEPTH = 8
+
+PLAY_SUFFIXES = (".scala",)
+
+
+class PlayRouteKind:
+    """Kinds of Play route findings."""
+
+    PLAY_ROUTE = "play_route"
+    PLAY_ACTION = "play_action"  # Secured controller action no routes file maps
+
+
+ROUTE_LINE = re.compile(r"^\s*(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS)\s+(/\S*)\s+@?([\w.$]+)\.(\w+)\s*(?:\(.*\))?\s*$")
+INCLUDE_LINE = re.compile(r"^\s*->\s+(/\S*)\s+([\w.]+)\s*$")
+MODIFIER_LINE = re.compile(r"^\s*\+\s*(.*?)\s*$")
+PATH_PARAM = re.compile(r"[:*](\w+)|\$(\w+)<[^>]*>")
+
+
+@dataclass
+class PlayRoute:
+    """One line of a routes file."""
+
+    file_path: str
+    line: int
+    verb: str
+    path: str
+    controller: str
+    action: str
+    modifiers: list[str] = field(default_factory=list)
+
+
+def is_routes_file(file_path: str) -> bool:
+    """Check whether a path is a Play routes file (conf/routes or conf/*.routes)."""
+    path = PurePosixPath(file_path)
+    return path.name == "routes" or path.suffix == ".routes"
+
+
+def is_play_source(file_path: str) -> bool:
+    """Check whether a path is a Scala source or Play routes file."""
+    return PurePosixPath(file_path).suffix in PLAY_SUFFIXES or is_routes_file(file_path)
+
+
+def parse_routes_file(file_path: str, text: str) -> tuple[list[PlayRoute], list[tuple[str, str]]]:
+    """Parse a routes file into routes and sub-router includes.
+
+    Args:
+        file_path: Path of the routes file
+        text: File content
+
+    Returns:
+        (routes with their "+ modifier" tags, (prefix, router) includes)
+    """
+    routes: list[PlayRoute] = []
+    includes: list[tuple[str, str]] = []
+    modifiers: list[str] = []
+    for number, line in enumerate(text.splitlines(), start=1):
+        if not line.strip() or line.lstrip().startswith("#"):
+            continue
+        modifier = MODIFIER_LINE.match(line)
+        if modifier:
+            modifiers.extend(modifier.group(1).split())
+            continue
+        include = INCLUDE_LINE.match(line)
+        route = ROUTE_LINE.match(line)
+        if include:
+            includes.append((include.group(1), include.group(2)))
+        elif route:
+            routes.append(PlayRoute(file_path, number, route.group(1), *route.group(2, 3, 4), modifiers=modifiers))
+        modifiers = []
+    return routes, includes
+
+
+def _prefixed(prefix: str, path: str) -> str:
+    """Apply an include prefix to a route path."""
+    if not prefix:
+        return path
+    return prefix.rstrip("/") + (path if path != "/" else "") or "/"
+
+
+def _router_file(router: str) -> str:
+    """Routes file a generated router is compiled from ("api.Routes" -> "api.routes")."""
+    package, _, name = router.rpartition(".")
+    return f"{package}.routes" if package and name == "Routes" else "routes"
+
+
+def resolve_routes(files: dict[str, str]) -> list[PlayRoute]:
+    """Parse routes files and apply the prefixes of "->" includes.
+
+    Args:
+        files: Relative path -> content of routes files
+
+    Returns:
+        Routes with full paths; path parameters (:id, *file, $id<re>) become {id}
+    """
+    parsed = {p: parse_routes_file(p, t) for p, t in files.items()}
+    by_name = {PurePosixPath(p).name: p for p in parsed}
+    included = {by_name.get(_router_file(router)) for _, includes in parsed.values() for _, router in includes}
+
+    routes: list[PlayRoute] = []
+
+    def walk(file_path: str, prefix: str, seen: set[str]) -> None:
+        own, includes = parsed[file_path]
+        routes.extend(replace(r, path=_prefixed(prefix, r.path)) for r in own)
+        for sub_prefix, router in includes:
+            target = by_name.get(_router_file(router))
+            if target and target not in seen:
+                walk(target, _prefixed(prefix, sub_prefix), seen | {target})
+
+    for file_path in parsed:
+        if file_path not in included:
+            walk(file_path, "", {file_path})
+    return [
+        replace(r, path=PATH_PARAM.sub(lambda m: "{" + (m.group(1) or m.group(2)) + "}", r.path)) for r in routes
+    ]
+
+
+def _skip_literal(code: str, i: int, limit: int) -> int:
+    """Offset just past the string or character literal starting at i."""
+    if code[i] == "'":
+        # '{' is a character literal; a lone quote is a symbol or lifetime
+        return i + 3 if i + 2 < limit and code[i + 2] == "'" else i + 1
+    end = code.find('"', i + 1, limit)
+    return limit if end < 0 else end + 1
+
+
+def _brace_pairs(code: str) -> dict[int, int]:
+    """Map each "{" offset to its closing "}", skipping string literals."""
+    pairs: dict[int, int] = {}
+    stack: list[int] = []
+    i, n = 0, len(code)
+    while i < n:
+        c = code[i]
+        if c in "\"'":
+            newline = code.find("\n", i + 1)
+            i = _skip_literal(code, i, n if newline < 0 else newline)
+            continue
+        if c == "{":
+            stack.append(i)
+        elif c == "}" and stack:
+            pairs[stack.pop()] = i
+        i += 1
+    return pairs
+
+
+@dataclass
+class ScalaClass:
+    """A class, object, or trait and what its header declares."""
+
+    name: str
+    file_path: str
+    parents: list[str]
+    params: dict[str, str]  # constructor parameter -> type
+    body_start: int
+    body_end: int
+    body: str = ""  # kept for action builders only
+
+
+@dataclass
+class ScalaDef:
+    """A def or val and the expression it is bound to."""
+
+    name: str
+    file_path: str
+    expression: str
+    start: int
+    end: int
+    owner: ScalaClass | None = None
+    kind: str = "def"  # "def" or "val"
+
+
+CLASS_DECL = re.compile(r"\b(?:class|object|trait)\s+([A-Za-z_]\w*)")
+DEF_DECL = re.compile(r"\b(def|val)\s+([A-Za-z_]\w*)")
+PARAM = re.compile(r"\b(\w+)\s*:\s*([A-Z][\w.]*)")
+PARENT = re.compile(r"\b(?:extends|with)\s+([A-Z][\w.]*)")
+CONTROLLER_PARENTS = {
+    "AbstractController",
+    "BaseController",
+    "InjectedController",
+    "Controller",
+    "MessagesAbstractController",
+    "MessagesBaseController",
+}
+
+
+def _header(code: str, pos: int) -> tuple[str, int | None]:
+    """Read a class header up to its body.
+
+    Returns:
+        (header text, offset of the body's "{" or None when there is no body)
+    """
+    limit = min(len(code), pos + MAX_HEADER_CHARS)
+    depth, i = 0, pos
+    while i < limit:
+        c = code[i]
+        if c in "\"'":
+            i = _skip_literal(code, i, limit)
+            continue
+        if c in "([":
+            depth += 1
+        elif c in ")]":
+            depth -= 1
+        elif depth <= 0 and c == "{":
+            return code[pos:i], i
+        elif depth <= 0 and c in ";}":
+            break
+        elif depth <= 0 and c == "\n":
+            following = code[i:limit].lstrip()
+            if not following.startswith(("extends", "with", "{")):
+                break
+        i += 1
+    return code[pos:i], None
+
+
+def _bound_expression(code: str, pos: int) -> tuple[str, int, bool] | None:
+    """Read the expression a def or val is bound to.
+
+    Skips type parameters, parameter lists, and the result type, then
+    reads after "=" up to the action's block or the end of the line.
+
+    Returns:
+        (expression, end offset, whether a "{" block follows) or None for
+        abstract members and procedure syntax
+    """
+    limit = min(len(code), pos + MAX_HEADER_CHARS)
+    depth, i, start = 0, pos, None
+    while i < limit:
+        c = code[i]
+        if c in "\"'":
+            i = _skip_literal(code, i, limit)
+            continue
+        if c in "([":
+            depth += 1
+        elif c in ")]":
+            depth -= 1
+        elif depth <= 0 and start is None:
+            if c == "=" and code[i + 1 : i + 2] not in (">", "=") and code[i - 1] not in "=!<>":
+                start = i + 1
+            elif c in "{};\n":
+                return None
+        elif depth <= 0:
+            expression = code[start:i].strip()
+            if c == "{":
+                return expression, i, True
+            if c in ";}" or (c == "\n" and expression and not expression.endswith(("andThen", "compose"))):
+                following = code[i:limit].lstrip()
+                if not following.startswith(("andThen", "compose", ".")):
+                    return expression, i, False
+        i += 1
+    return None
+
+
+def parse_scala(file_path: str, text: str) -> tuple[list[ScalaClass], list[ScalaDef]]:
+    """Find classes and the defs/vals bound in them.
+
+    Args:
+        file_path: Path of the Scala file
+        text: Scala source
+
+    Returns:
+        (classes, defs with their innermost enclosing class as owner)
+    """
+    code = _strip_comments(text)
+    pairs = _brace_pairs(code)
+
+    classes = []
+    for match in CLASS_DECL.finditer(code):
+        header, body = _header(code, match.end())
+        end = pairs.get(body, len(code)) if body is not None else match.end()
+        cls = ScalaClass(
+            name=match.group(1),
+            file_path=file_path,
+            parents=[p.rsplit(".", 1)[-1] for p in PARENT.findall(header)],
+            params=dict(PARAM.findall(header)),
+            body_start=body if body is not None else match.end(),
+            body_end=end,
+        )
+        if any(p.startswith("Action") for p in cls.parents):
+            cls.body = code[cls.body_start : cls.body_end]
+        classes.append(cls)
+
+    defs = []
+    order = sorted(classes, key=lambda c: c.body_start)
+    stack: list[ScalaClass] = []
+    k = 0
+    for match in DEF_DECL.finditer(code):
+        while k < len(order) and order[k].body_start < match.start():
+            while stack and stack[-1].body_end <= order[k].body_start:
+                stack.pop()
+            stack.append(order[k])
+            k += 1
+        while stack and stack[-1].body_end <= match.start():
+            stack.pop()
+        bound = _bound_expression(code, match.end())
+        if bound is None:
+            continue
+        expression, end, has_body = bound
+        if has_body:
+            end = pairs.get(end, end)  # the action's block
+        defs.append(
+            ScalaDef(
+                name=match.group(2),
+                file_path=file_path,
+                expression=" ".join(expression.split()),
+                start=match.start(),
+                end=end,
+                owner=stack[-1] if stack else None,
+                kind=match.group(1),
+            )
+        )
+    return classes, defs
+
+
+# Stock actions that run the block for any caller
+PUBLIC_ACTIONS = {"Action", "actionBuilder", "DefaultActionBuilder", "UnsecuredAction", "UserAwareAction", "SubjectNotPresent"}
+ROLE_WORDS = re.compile(r"role|restrict|group", re.IGNORECASE)
+PERMISSION_WORDS = re.compile(r"permission|pattern|scope", re.IGNORECASE)
+AUTH_WORDS = re.compile(r"auth|secur|login|signedin|loggedin|subjectpresent|identified", re.IGNORECASE)
+DENIAL = re.compile(r"\b(?:Unauthorized|Forbidden|Redirect)\b")
+COMPOSITION = re.compile(r"\s+(?:andThen|compose)\s+")
+STRING = re.compile(r'"([^"]*)"')
+ROLE_CONSTANT = re.compile(r"\bRoles?\.(\w+)")
+CALLEE = re.compile(r"^(?:new\s+)?([A-Za-z_][\w.]*)")
+
+
+def _components(expression: str) -> list[str]:
+    """Split an action composition into its builders, refiners, and filters."""
+    expression = expression.strip()
+    while expression.startswith("(") and expression.endswith(")"):
+        depth = 0
+        for i, c in enumerate(expression):
+            depth += c == "("
+            depth -= c == ")"
+            if depth == 0:
+                break
+        if i != len(expression) - 1:
+            break
+        expression = expression[1:-1].strip()
+    return [c for c in COMPOSITION.split(expression) if c]
+
+
+def _callee(component: str) -> str | None:
+    """Name of the builder a component calls ("authAction.async(...)" -> "authAction")."""
+    match = CALLEE.match(component.strip())
+    if not match:
+        return None
+    segments = [s for s in match.group(1).split(".") if s not in ("async", "apply", "andThen")]
+    return segments[-1] if segments else None
+
+
+class PlayIndex:
+    """Classes and definitions across a Play application's Scala sources."""
+
+    def __init__(self, sources: dict[str, str]):
+        """Initialize index."""
+        self.classes: dict[str, ScalaClass] = {}
+        self.defs: dict[str, ScalaDef] = {}
+        self.members: dict[tuple[str, str], ScalaDef] = {}
+        for file_path, text in sources.items():
+            classes, defs = parse_scala(file_path, text)
+            for cls in classes:
+                self.classes.setdefault(cls.name, cls)
+            for definition in defs:
+                self.defs.setdefault(definition.name, definition)
+                if definition.owner is not None:
+                    self.members.setdefault((definition.owner.name, definition.name), definition)
+
+    def controller_actions(self) -> list[ScalaDef]:
+        """Defs in controller classes."""
+        return [
+            d
+            for (owner, _), d in self.members.items()
+            if d.kind == "def"
+            and (owner.endswith("Controller") or CONTROLLER_PARENTS.intersection(self.classes[owner].parents))
+        ]
+
+    def access(self, definition: ScalaDef) -> Access | None:
+        """Resolve who may call an action from the composition it is built from."""
+        return self._resolve(definition.expression, definition.owner, 0)
+
+    def _resolve(self, expression: str, owner: ScalaClass | None, depth: int) -> Access | None:
+        """Resolve an expression by combining its composed components."""
+        decisions = [self._component(c, owner, depth) for c in _components(expression)]
+        decisions = [d for d in decisions if d is not None]
+        if not decisions:
+            return None
+        guarded = [d for d in decisions if d.subject != ANONYMOUS]
+        if not guarded:
+            return Access(ANONYMOUS, source=expression)
+        roles = [d for d in guarded if d.subject != AUTHENTICATED]
+        conditions = [d.conditions for d in guarded if d.conditions]
+        conditions += [f"caller also has role {d.subject}" for d in roles[1:]]
+        subject = roles[0].subject if roles else AUTHENTICATED
+        return Access(subject, conditions="; ".join(conditions) or None, source=expression)
+
+    def _component(self, component: str, owner: ScalaClass | None, depth: int) -> Access | None:
+        """Resolve one builder, refiner, filter, or wrapper call."""
+        name = _callee(component)
+        if name is None:
+            return None
+        values = STRING.findall(component) + ROLE_CONSTANT.findall(component)
+        if values and ROLE_WORDS.search(component):
+            return Access(" or ".join(values))
+        if values and PERMISSION_WORDS.search(component):
+            return Access(AUTHENTICATED, conditions=f"caller holds permission {' or '.join(values)}")
+        if name in PUBLIC_ACTIONS:
+            return Access(ANONYMOUS)
+
+        if depth < MAX_RESOLVE_DEPTH:
+            definition = (self.members.get((owner.name, name)) if owner else None) or self.defs.get(name)
+            if definition is not None and definition.expression:
+                resolved = self._resolve(definition.expression, definition.owner, depth + 1)
+                if resolved is not None:
+                    return resolved
+        if owner is not None and name in owner.params:
+            name = owner.params[name].rsplit(".", 1)[-1]
+        builder = self.classes.get(name)
+        if builder is not None and builder.body:
+            return self._builder(builder)
+        return Access(AUTHENTICATED) if AUTH_WORDS.search(name) else None
+
+    @staticmethod
+    def _builder(builder: ScalaClass) -> Access | None:
+        """Classify a custom ActionBuilder, ActionRefiner, or ActionFilter by what its body rejects."""
+        if not DENIAL.search(builder.body):
+            return None
+        roles = [v for line in builder.body.splitlines() if ROLE_WORDS.search(line) for v in STRING.findall(line)]
+        return Access(" or ".join(roles) if roles else AUTHENTICATED)
+
+
+def _action_finding(kind: str, text: str, action: ScalaDef, access: Access, verb: str, resource: str, routed_by: str) -> ConfigFinding:
+    """Build the finding for one controller action."""
+    line_start = line_of(text, action.start)
+    line_end = min(line_of(text, action.end), line_start + MAX_SNIPPET_LINES - 1)
+    return ConfigFinding(
+        kind=kind,
+        file_path=action.file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(text, line_start, line_end),
+        subject=access.subject,
+        resource=resource,
+        action=verb,
+        conditions=access.conditions,
+        description=f"Play action {action.owner.name}.{action.name} built from {access.source}, {routed_by}",
+        disables_auth=access.subject == ANONYMOUS,
+    )
+
+
+def extract_play_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Play route authorization from an application's files.
+
+    Routed actions become one finding per routes-file line. Secured
+    controller actions that no routes file maps are reported by name, so
+    compositions behind SIRD or generated routers are not lost.
+
+    Args:
+        files: Relative path -> content of Scala sources and routes files
+
+    Returns:
+        Route and action findings across all files
+    """
+    sources = {p: t for p, t in files.items() if PurePosixPath(p).suffix in PLAY_SUFFIXES}
+    routes = resolve_routes({p: t for p, t in files.items() if is_routes_file(p)})
+    index = PlayIndex(sources)
+
+    findings = []
+    routed: set[tuple[str, str]] = set()
+    for route in routes:
+        key = (route.controller.rsplit(".", 1)[-1], route.action)
+        action = index.members.get(key)
+        if action is None or action.kind != "def":
+            continue
+        routed.add(key)
+        access = index.access(action)
+        if access is None:
+            continue
+        if "nocsrf" in route.modifiers:
+            access = replace(
+                access, conditions="; ".join(filter(None, [access.conditions, "CSRF check disabled (+nocsrf)"]))
+            )
+        findings.append(
+            _action_finding(
+                PlayRouteKind.PLAY_ROUTE,
+                sources[action.file_path],
+                action,
+                access,
+                route.verb,
+                route.path,
+                f"routed by {route.file_path}:{route.line}",
+            )
+        )
+
+    for action in index.controller_actions():
+        if (action.owner.name, action.name) in routed:
+            continue
+        access = index.access(action)
+        if access is None or access.subject == ANONYMOUS:
+            continue
+        findings.append(
+            _action_finding(
+                PlayRouteKind.PLAY_ACTION,
+                sources[action.file_path],
+                action,
+                access,
+                "*",
+                f"{action.owner.name}.{action.name}",
+                "not mapped by any routes file",
+            )
+        )
+    return findings
@@ -0,0 +1,257 @@
+"""Extract authorization rules developers declare in inline comments.
+
+Some checks are out of static analysis's reach: a role read through a
+helper in another service, a rule enforced by a gateway, reflection. For
+those, developers annotate the code with a comment in any language's
+comment syntax:
+
+    // policy: role=MANAGER resource=expense action=create
+    # policy: permission=reports:export resource=report action=export when="region == user.region"
+
+Keys are role and permission (comma-separated lists), resource, action, and
+when (the rule's conditions); values with spaces are quoted. Comments whose
+text after "policy:" holds no key=value pair are prose and ignored.
+
+An annotation describes the code right below it, so each one is checked
+against that code: a role or permission check naming different values, or a
+route whose method or path does not fit the annotated action and resource,
+makes the annotation stale. Stale annotations are still mined, since the
+developer's statement may be what the code lost, but they are flagged.
+"""
+
+import re
+from dataclasses import dataclass, field
+
+from app.services.config_policy_extractor import ConfigFinding
+
+ANNOTATION = re.compile(
+    r"^\s*(?://+|#+|--|/\*+|\*+|<!--|;+|')\s*policy\s*:\s*(.*?)\s*(?:\*+/|-->)?\s*$", re.IGNORECASE
+)
+FIELD = re.compile(r"(\w+)\s*=\s*(\"[^\"]*\"|'[^']*'|[^\s\"']+)")
+COMMENT = re.compile(r"^\s*(?://|#(?!\[)|--|/\*|\*|<!--|;|')")
+
+LIST_FIELDS = {"role": "roles", "roles": "roles", "permission": "permissions", "permissions": "permissions"}
+TEXT_FIELDS = {"resource", "action", "when"}
+
+# Lines below an annotation that make up the code it describes
+WINDOW_LINES = 12
+MAX_ANNOTATION_LENGTH = 1000
+
+ROLE_CHECK = re.compile(r"(?i)(?:role|group)s?\w*\W{1,40}?['\"]([\w:.-]+)['\"]")
+PERMISSION_CHECK = re.compile(r"(?i)(?:permission|perm|scope|authorit(?:y|ies))\w*\W{1,40}?['\"]([\w:.*-]+)['\"]")
+ROUTE = re.compile(
+    r"(?i)(?<![\w])(get|post|put|patch|delete)(?:mapping)?\s*\(\s*(?:(?:path|value)\s*=\s*)?['\"`](/[^'\"`]*)['\"`]"
+)
+ACTION_METHODS = {
+    "create": {"POST", "PUT"},
+    "add": {"POST", "PUT"},
+    "submit": {"POST"},
+    "read": {"GET"},
+    "view": {"GET"},
+    "list": {"GET"},
+    "get": {"GET"},
+    "export": {"GET", "POST"},
+    "update": {"PUT", "PATCH", "POST"},
+    "edit": {"PUT", "PATCH", "POST"},
+    "delete": {"DELETE", "POST"},
+    "remove": {"DELETE", "POST"},
+}
+
+
+class AnnotationKind:
+    """Kinds of inline annotation findings."""
+
+    POLICY = "policy_annotation"
+
+
+class AnnotationStatus:
+    """Outcome of validating an annotation against the code it annotates."""
+
+    VALID = "valid"
+    STALE = "stale"
+    INVALID = "invalid"
+
+
+@dataclass
+class PolicyAnnotation:
+    """An inline policy annotation and what validating it found."""
+
+    file_path: str
+    line: int
+    text: str
+    roles: list[str] = field(default_factory=list)
+    permissions: list[str] = field(default_factory=list)
+    resource: str | None = None
+    action: str | None = None
+    conditions: str | None = None
+    target_line: int | None = None  # First line of the annotated code
+    target: str = ""  # The annotated code
+    status: str = AnnotationStatus.VALID
+    problems: list[str] = field(default_factory=list)
+
+
+def _normalize_role(role: str) -> str:
+    """Role name compared case-insensitively, without Spring's ROLE_ prefix."""
+    return role.lower().removeprefix("role_")
+
+
+def _stem(word: str) -> str:
+    """Singular, lowercase form of a resource name for matching against paths."""
+    word = word.lower().replace("_", "-")
+    for suffix, replacement in (("ies", "y"), ("sses", "ss"), ("s", "")):
+        if word.endswith(suffix) and len(word) > len(suffix) + 1:
+            return word[: -len(suffix)] + replacement
+    return word
+
+
+def parse_annotation(file_path: str, line: int, body: str) -> PolicyAnnotation | None:
+    """Parse an annotation's body, the text after "policy:".
+
+    Returns:
+        Annotation with any problems in its syntax, or None if the body is prose
+    """
+    fields = list(FIELD.finditer(body))
+    if not fields:
+        return None
+    annotation = PolicyAnnotation(file_path=file_path, line=line, text=body[:MAX_ANNOTATION_LENGTH])
+    leftover = FIELD.sub("", body).strip(" ,;")
+    if leftover:
+        annotation.problems.append(f"unparsed text '{leftover[:80]}'")
+    for match in fields:
+        key, value = match.group(1).lower(), match.group(2)
+        if value[:1] in "\"'":
+            value = value[1:-1]
+        value = value.strip()
+        if key in LIST_FIELDS:
+            target = getattr(annotation, LIST_FIELDS[key])
+            target.extend(v for v in (part.strip() for part in value.split(",")) if v and v not in target)
+        elif key in TEXT_FIELDS:
+            setattr(annotation, "conditions" if key == "when" else key, value or None)
+        else:
+            annotation.problems.append(f"unknown key '{key}'; use role, permission, resource, action, or when")
+    if not annotation.resource:
+        annotation.problems.append("resource is required")
+    if not annotation.action:
+        annotation.problems.append("action is required")
+    if not annotation.roles and not annotation.permissions:
+        annotation.problems.append("a role or permission is required")
+    if annotation.problems:
+        annotation.status = AnnotationStatus.INVALID
+    return annotation
+
+
+def _target(lines: list[str], index: int) -> tuple[int | None, list[str]]:
+    """The code an annotation on line index describes: from the next code line to the next annotation."""
+    start = index + 1
+    while start < len(lines) and (not lines[start].strip() or COMMENT.match(lines[start])):
+        if ANNOTATION.match(lines[start]) and FIELD.search(ANNOTATION.match(lines[start]).group(1)):
+            # Stacked annotations describe the same code
+            start += 1
+            continue
+        start += 1
+    if start >= len(lines):
+        return None, []
+    window = []
+    for text in lines[start : start + WINDOW_LINES]:
+        match = ANNOTATION.match(text)
+        if match and FIELD.search(match.group(1)):
+            break
+        window.append(text)
+    return start + 1, window
+
+
+def validate(annotation: PolicyAnnotation) -> None:
+    """Check an annotation against the code it annotates, marking it stale on a mismatch."""
+    if annotation.target_line is None:
+        annotation.problems.append("annotates no code")
+    code = annotation.target
+    checked_roles = list(dict.fromkeys(ROLE_CHECK.findall(code)))
+    if annotation.roles and checked_roles:
+        declared = {_normalize_role(r) for r in annotation.roles}
+        if not declared.intersection(_normalize_role(r) for r in checked_roles):
+            annotation.problems.append(
+                f"code checks role {', '.join(checked_roles)}, not {', '.join(annotation.roles)}"
+            )
+    checked_permissions = list(dict.fromkeys(PERMISSION_CHECK.findall(code)))
+    if annotation.permissions and checked_permissions:
+        if not {p.lower() for p in annotation.permissions}.intersection(p.lower() for p in checked_permissions):
+            annotation.problems.append(
+                f"code checks permission {', '.join(checked_permissions)}, not {', '.join(annotation.permissions)}"
+            )
+    route = ROUTE.search(code)
+    if route:
+        method, path = route.group(1).upper(), route.group(2)
+        methods = ACTION_METHODS.get((annotation.action or "").lower())
+        if methods and method not in methods:
+            annotation.problems.append(f"action {annotation.action} does not fit the route {method} {path}")
+        segments = {_stem(s) for s in re.split(r"[/.{}:<>]+", path) if s}
+        if annotation.resource and segments and _stem(annotation.resource) not in segments:
+            annotation.problems.append(f"resource {annotation.resource} does not appear in the route {method} {path}")
+    if annotation.problems:
+        annotation.status = AnnotationStatus.STALE
+
+
+def extract_annotations(file_path: str, text: str) -> list[PolicyAnnotation]:
+    """Find, parse, and validate the policy annotations of a source file.
+
+    Args:
+        file_path: Path of the file relative to the repository root
+        text: File content
+
+    Returns:
+        Annotations in file order, including invalid ones
+    """
+    if "policy" not in text.lower():
+        return []
+    lines = text.splitlines()
+    annotations = []
+    for index, line in enumerate(lines):
+        match = ANNOTATION.match(line)
+        if not match:
+            continue
+        annotation = parse_annotation(file_path, index + 1, match.group(1))
+        if annotation is None:
+            continue
+        annotation.target_line, window = _target(lines, index)
+        annotation.target = "\n".join(window)
+        if annotation.status != AnnotationStatus.INVALID:
+            validate(annotation)
+        annotations.append(annotation)
+    return annotations
+
+
+def annotation_finding(annotation: PolicyAnnotation) -> ConfigFinding:
+    """The rule a well-formed annotation declares."""
+    conditions = [f"requires permission {p}" for p in annotation.permissions]
+    if annotation.conditions:
+        conditions.append(annotation.conditions)
+    first_code = next((line.strip() for line in annotation.target.splitlines() if line.strip()), "")
+    description = f"Inline policy annotation on {first_code[:80]}" if first_code else "Inline policy annotation"
+    if annotation.status == AnnotationStatus.STALE:
+        description += f"; flagged stale: {'; '.join(annotation.problems)}"
+    return ConfigFinding(
+        kind=AnnotationKind.POLICY,
+        file_path=annotation.file_path,
+        line_start=annotation.line,
+        line_end=annotation.target_line or annotation.line,
+        snippet=f"policy: {annotation.text}" + (f"\n{first_code}" if first_code else ""),
+        subject=" or ".join(annotation.roles) or "Authenticated users",
+        resource=annotation.resource or "",
+        action=annotation.action or "",
+        conditions="; ".join(conditions) or None,
+        description=description,
+    )
+
+
+def extract_annotation_findings(files: dict[str, str]) -> tuple[list[PolicyAnnotation], list[ConfigFinding]]:
+    """Annotations of a repository's sources and the findings of the well-formed ones.
+
+    Args:
+        files: Relative path -> content
+
+    Returns:
+        (every annotation, one finding per valid or stale annotation)
+    """
+    annotations = [a for path, text in files.items() for a in extract_annotations(path, text)]
+    findings = [annotation_finding(a) for a in annotations if a.status != AnnotationStatus.INVALID]
+    return annotations, findings
@@ -0,0 +1,164 @@
+"""Service for mining inline policy annotations and flagging stale ones.
+
+Developers declare rules static analysis cannot see with comments such as
+"// policy: role=MANAGER resource=expense action=create" (see
+policy_annotation_extractor for the convention). Each scan mines well-formed
+annotations into the repository's policies, validates every annotation
+against the code below it, and keeps the results so stale and malformed
+annotations can be listed and fixed.
+"""
+
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import asdict
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy_annotation import PolicyAnnotationRecord
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.config_policy_service import ConfigPolicyService
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.policy_annotation_extractor import (
+    AnnotationStatus,
+    PolicyAnnotation,
+    extract_annotation_findings,
+)
+from app.services.readiness_service import SUPPORTED_LANGUAGES, UNSUPPORTED_LANGUAGES
+
+logger = structlog.get_logger(__name__)
+
+# Annotations are most useful where the analyzers do not reach, so unsupported languages are read too
+ANNOTATED_SUFFIXES = set(SUPPORTED_LANGUAGES) | set(UNSUPPORTED_LANGUAGES) | {".swift", ".c", ".cpp", ".sql"}
+
+# Policies created by this service are tagged with this source
+ANNOTATION_LABEL = "inline policy annotation"
+
+
+def load_sources(root: Path) -> dict[str, str]:
+    """Read the sources of a clone that mention a policy annotation.
+
+    Args:
+        root: Repository clone root
+
+    Returns:
+        Relative path -> content
+    """
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    sources: dict[str, str] = {}
+    for path in sorted(root.rglob("*")):
+        relative = path.relative_to(root)
+        if path.suffix not in ANNOTATED_SUFFIXES or SKIPPED_DIRECTORIES.intersection(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        text = path.read_text(encoding="utf-8", errors="replace")
+        if "policy" in text.lower():
+            sources[relative.as_posix()] = text
+    return sources
+
+
+def mine_checkout(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding a clone's annotations and the findings of the well-formed ones."""
+    annotations, findings = extract_annotation_findings(load_sources(Path(root)))
+    yield {"annotations": [asdict(a) for a in annotations], "findings": [asdict(f) for f in findings]}
+
+
+class PolicyAnnotationService(ConfigPolicyService):
+    """Mines inline policy annotations into repository policies."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        super().__init__(db, tenant_id)
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def scan_repository(self, repository_id: int) -> dict:
+        """Mine and validate a repository's inline policy annotations.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Summary with annotations per status and merge results
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        (mined,) = run_pass(root, mine_checkout)
+        annotations = [PolicyAnnotation(**a) for a in mined["annotations"]]
+        findings = [ConfigFinding(**f) for f in mined["findings"]]
+        merge = self.merge_findings(repo, findings, ANNOTATION_LABEL, previous=["%"], label_scoped=True)
+
+        self._query(PolicyAnnotationRecord).filter(PolicyAnnotationRecord.repository_id == repo.id).delete(
+            synchronize_session=False
+        )
+        self.db.add_all(
+            PolicyAnnotationRecord(
+                tenant_id=repo.tenant_id,
+                repository_id=repo.id,
+                file_path=a.file_path,
+                line=a.line,
+                text=a.text,
+                roles=a.roles,
+                permissions=a.permissions,
+                resource=a.resource,
+                action=a.action,
+                conditions=a.conditions,
+                target_line=a.target_line,
+                status=a.status,
+                problems=a.problems,
+            )
+            for a in annotations
+        )
+        self.db.commit()
+
+        statuses = Counter(a.status for a in annotations)
+        logger.info(
+            "policy_annotations_scanned",
+            repository_id=repo.id,
+            annotations=len(annotations),
+            stale=statuses[AnnotationStatus.STALE],
+            invalid=statuses[AnnotationStatus.INVALID],
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repo.id,
+            "annotations": len(annotations),
+            "valid": statuses[AnnotationStatus.VALID],
+            "stale": statuses[AnnotationStatus.STALE],
+            "invalid": statuses[AnnotationStatus.INVALID],
+            **merge,
+        }
+
+    def annotations(self, repository_id: int, status: str | None = None) -> list[PolicyAnnotationRecord]:
+        """A repository's annotations as of its last scan, in file order.
+
+        Args:
+            repository_id: Repository ID
+            status: Only annotations with this status
+
+        Raises:
+            ValueError: If the status is unknown
+        """
+        query = self._query(PolicyAnnotationRecord).filter(PolicyAnnotationRecord.repository_id == repository_id)
+        if status is not None:
+            statuses = (AnnotationStatus.VALID, AnnotationStatus.STALE, AnnotationStatus.INVALID)
+            if status not in statuses:
+                raise ValueError(f"Unknown status '{status}'; use {', '.join(statuses)}")
+            query = query.filter(PolicyAnnotationRecord.status == status)
+        return query.order_by(PolicyAnnotationRecord.file_path, PolicyAnnotationRecord.line).all()
@@ -0,0 +1,202 @@
+"""Service for exporting mined policies as a graph for visualization.
+
+Nodes are subjects (roles), permissions (an action on a resource),
+resources, and conditions. Node and edge IDs are derived from their content,
+so the same policy model always yields the same IDs and the UI can keep
+layout and selection stable across refreshes. Level of detail trades
+fidelity for size: the summary level collapses permissions into weighted
+role-to-resource edges, which keeps large workspaces renderable.
+"""
+
+import hashlib
+import re
+from dataclasses import asdict, dataclass, field
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.permission_matrix_service import CellAccess, PermissionMatrixService
+
+logger = structlog.get_logger(__name__)
+
+DEFAULT_MAX_EDGES = 20_000
+
+
+class GraphDetail:
+    """Graph levels of detail."""
+
+    SUMMARY = "summary"  # role -> resource, weighted by number of actions
+    STANDARD = "standard"  # role -> permission -> resource
+    FULL = "full"  # standard plus condition nodes and policy IDs on edges
+
+
+class NodeType:
+    """Graph node types."""
+
+    SUBJECT = "subject"
+    PERMISSION = "permission"
+    RESOURCE = "resource"
+    CONDITION = "condition"
+
+
+@dataclass
+class GraphNode:
+    """A graph node."""
+
+    id: str
+    type: str
+    label: str
+    weight: int = 0
+
+
+@dataclass
+class GraphEdge:
+    """A directed graph edge."""
+
+    id: str
+    source: str
+    target: str
+    type: str
+    weight: int = 1
+    conditional: bool = False
+    policy_ids: list[int] = field(default_factory=list)
+
+
+def _slug(text: str) -> str:
+    """Lower-case identifier-safe form of a label."""
+    return re.sub(r"[^a-z0-9]+", "-", text.lower()).strip("-") or "unnamed"
+
+
+def node_id(node_type: str, *parts: str) -> str:
+    """Build a stable node ID from its type and content.
+
+    Conditions are hashed since their text is arbitrary and long.
+
+    Args:
+        node_type: Node type
+        parts: Identifying labels
+
+    Returns:
+        Stable node ID (e.g., "permission:approve:expense")
+    """
+    if node_type == NodeType.CONDITION:
+        digest = hashlib.sha1(" ".join(parts).strip().lower().encode()).hexdigest()[:12]
+        return f"{node_type}:{digest}"
+    return ":".join([node_type, *(_slug(p) for p in parts)])
+
+
+class PolicyGraphService:
+    """Builds node/edge graphs from mined policies."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def build(
+        self,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+        include_pending: bool = True,
+        detail: str = GraphDetail.STANDARD,
+        role: str | None = None,
+        resource: str | None = None,
+        max_edges: int = DEFAULT_MAX_EDGES,
+    ) -> dict:
+        """Build the policy graph.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+            include_pending: Include policies not yet approved
+            detail: summary, standard, or full
+            role: Only include this role (case-insensitive)
+            resource: Only include resources containing this text
+            max_edges: Stop adding edges beyond this count and flag the graph truncated
+
+        Returns:
+            Graph with nodes, edges, and size metadata
+
+        Raises:
+            ValueError: If the level of detail is unknown
+        """
+        if detail not in (GraphDetail.SUMMARY, GraphDetail.STANDARD, GraphDetail.FULL):
+            raise ValueError(f"Unknown level of detail: {detail}")
+
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+            repository_id=repository_id, application_id=application_id, include_pending=include_pending
+        )
+        cells = [
+            cell
+            for cell in PermissionMatrixService.aggregate(policies)
+            if (not role or cell.role.lower() == role.lower())
+            and (not resource or resource.lower() in cell.resource.lower())
+        ]
+
+        nodes: dict[str, GraphNode] = {}
+        edges: dict[str, GraphEdge] = {}
+        truncated = False
+
+        def add_node(node_type: str, label: str, *parts: str) -> str:
+            nid = node_id(node_type, *parts)
+            node = nodes.setdefault(nid, GraphNode(id=nid, type=node_type, label=label))
+            node.weight += 1
+            return nid
+
+        def add_edge(source: str, target: str, edge_type: str, conditional: bool, policy_ids: list[int]) -> bool:
+            eid = f"{source}->{target}"
+            edge = edges.get(eid)
+            if edge is None:
+                if len(edges) >= max_edges:
+                    return False
+                edge = GraphEdge(id=eid, source=source, target=target, type=edge_type, weight=0)
+                edges[eid] = edge
+            edge.weight += 1
+            edge.conditional = edge.conditional or conditional
+            if detail == GraphDetail.FULL:
+                edge.policy_ids.extend(p for p in policy_ids if p not in edge.policy_ids)
+            return True
+
+        for cell in cells:
+            conditional = cell.access == CellAccess.CONDITIONAL
+            subject = add_node(NodeType.SUBJECT, cell.role, cell.role)
+            target = add_node(NodeType.RESOURCE, cell.resource, cell.resource)
+
+            if detail == GraphDetail.SUMMARY:
+                added = add_edge(subject, target, "can_access", conditional, cell.policy_ids)
+            else:
+                permission = add_node(
+                    NodeType.PERMISSION, f"{cell.action} {cell.resource}", cell.action, cell.resource
+                )
+                added = add_edge(subject, permission, "granted", conditional, cell.policy_ids)
+                added = add_edge(permission, target, "on", False, cell.policy_ids) and added
+                if detail == GraphDetail.FULL:
+                    for condition in cell.conditions:
+                        condition_node = add_node(NodeType.CONDITION, condition, condition)
+                        added = add_edge(permission, condition_node, "when", True, cell.policy_ids) and added
+
+            if not added:
+                truncated = True
+                break
+
+        # Drop nodes left without edges when truncation stopped mid-cell
+        connected = {e.source for e in edges.values()} | {e.target for e in edges.values()}
+        node_list = [n for n in nodes.values() if n.id in connected]
+
+        logger.info(
+            "policy_graph_built",
+            detail=detail,
+            nodes=len(node_list),
+            edges=len(edges),
+            truncated=truncated,
+        )
+
+        return {
+            "detail": detail,
+            "node_count": len(node_list),
+            "edge_count": len(edges),
+            "truncated": truncated,
+            "nodes": [asdict(n) for n in sorted(node_list, key=lambda n: n.id)],
+            "edges": [asdict(e) for e in sorted(edges.values(), key=lambda e: e.id)],
+        }
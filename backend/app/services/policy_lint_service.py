@@ -0,0 +1,361 @@
+"""Lint organizational rules against the canonical endpoint model.
+
+Organizations state requirements their authorization model must meet, such
+as "every mutating endpoint must require at least one role" or "no endpoint
+may rely solely on frontend checks". Each rule applies one check to the
+endpoints in its scope (HTTP methods and path patterns) with a configurable
+severity. Rules are evaluated when a scan completes and the results are
+stored per scan; the CI gate fails when a blocking rule has a violation at
+or above the requested severity. Workspaces without rules of their own are
+linted against the default rules.
+"""
+
+from collections import Counter
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.lint import LintCheck, LintRule, LintRun
+from app.models.policy import Policy, SourceType
+from app.models.repository import Repository
+from app.services.auth_mechanism_service import AuthMechanism, rule_mechanisms
+from app.services.cors_csrf_service import scope_matches
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+from app.services.endpoint_risk_service import SEVERITY_LEVELS, SEVERITY_ORDER, WRITE_METHODS
+
+logger = structlog.get_logger(__name__)
+
+# Applied when a workspace has not defined any rules
+DEFAULT_LINT_RULES = [
+    {
+        "name": "mutating-endpoints-require-role",
+        "description": "Every mutating endpoint must require at least one role",
+        "check": LintCheck.REQUIRE_ROLE,
+        "params": {"methods": sorted(WRITE_METHODS)},
+        "severity": "high",
+        "blocking": True,
+    },
+    {
+        "name": "no-frontend-only-enforcement",
+        "description": "No endpoint may rely solely on frontend checks",
+        "check": LintCheck.NO_FRONTEND_ONLY,
+        "params": {},
+        "severity": "high",
+        "blocking": True,
+    },
+]
+
+# Params each check requires, beyond the scope keys every rule accepts
+REQUIRED_PARAMS = {LintCheck.ALLOWED_MECHANISMS: "mechanisms", LintCheck.FORBIDDEN_ROLES: "roles"}
+SCOPE_PARAMS = ("methods", "paths", "exclude_paths")
+
+
+def validate_rule(check: LintCheck, params: dict, severity: str) -> None:
+    """Check that a rule's params and severity are usable.
+
+    Raises:
+        ValueError: If a param is missing or malformed, or the severity is unknown
+    """
+    if severity not in SEVERITY_LEVELS:
+        raise ValueError(f"Severity must be one of {', '.join(SEVERITY_LEVELS)}")
+    if not isinstance(params, dict):
+        raise ValueError("Params must be an object")
+    for key in SCOPE_PARAMS:
+        if key in params and not (isinstance(params[key], list) and all(isinstance(v, str) for v in params[key])):
+            raise ValueError(f"Param '{key}' must be a list of strings")
+    required = REQUIRED_PARAMS.get(check)
+    if required and not (isinstance(params.get(required), list) and params[required]):
+        raise ValueError(f"Check '{check.value}' requires a non-empty '{required}' list")
+    if check == LintCheck.ALLOWED_MECHANISMS:
+        known = {m.value for m in AuthMechanism}
+        unknown = [m for m in params["mechanisms"] if m not in known]
+        if unknown:
+            raise ValueError(f"Unknown auth mechanisms: {', '.join(unknown)}")
+
+
+def in_scope(rule: dict, endpoint: EndpointRule) -> bool:
+    """Whether an endpoint falls under a rule's methods and path patterns."""
+    params = rule.get("params") or {}
+    methods = {m.upper() for m in params.get("methods") or []}
+    if methods and endpoint.method not in methods:
+        return False
+    if any(scope_matches(pattern, endpoint.path) for pattern in params.get("exclude_paths") or []):
+        return False
+    paths = params.get("paths") or []
+    return not paths or any(scope_matches(pattern, endpoint.path) for pattern in paths)
+
+
+def check_endpoint(rule: dict, endpoint: EndpointRule, policies: dict[int, Policy]) -> str | None:
+    """Apply one rule's check to one endpoint.
+
+    Args:
+        rule: Rule definition (check, params)
+        endpoint: Endpoint rule from the canonical model
+        policies: Policies by ID
+
+    Returns:
+        Why the endpoint violates the rule, or None when it complies
+    """
+    params = rule.get("params") or {}
+    check = LintCheck(rule["check"])
+    if check == LintCheck.REQUIRE_ROLE and not endpoint.roles:
+        return "allows anonymous callers" if endpoint.is_public else "allows any authenticated user"
+    if check == LintCheck.REQUIRE_AUTHENTICATION and endpoint.is_public:
+        return "allows anonymous callers"
+    if check == LintCheck.NO_FRONTEND_ONLY:
+        sources = {policies[pid].source_type for pid in endpoint.policy_ids if pid in policies}
+        if sources == {SourceType.FRONTEND}:
+            return "is enforced only by frontend checks"
+    if check == LintCheck.ALLOWED_MECHANISMS:
+        primary = rule_mechanisms(endpoint, policies)[0]
+        if primary.value not in params.get("mechanisms", []):
+            return f"authenticates with {primary.value}"
+    if check == LintCheck.FORBIDDEN_ROLES:
+        forbidden = {r.upper() for r in params.get("roles", [])}
+        granted = [r for r in endpoint.roles if r.upper() in forbidden]
+        if granted:
+            return f"grants forbidden role {', '.join(granted)}"
+    return None
+
+
+def lint_endpoints(rules: list[dict], policies: list[Policy]) -> tuple[list[dict], int]:
+    """Evaluate rules against the endpoints the policies map to.
+
+    Args:
+        rules: Enabled rule definitions
+        policies: Policies of one repository
+
+    Returns:
+        Tuple of (violations most severe first, number of endpoints evaluated)
+    """
+    endpoints = EndpointMappingService.map_policies(policies)
+    by_id = {p.id: p for p in policies}
+    violations = []
+    for rule in rules:
+        for endpoint in endpoints:
+            if not in_scope(rule, endpoint):
+                continue
+            reason = check_endpoint(rule, endpoint, by_id)
+            if reason:
+                violations.append(
+                    {
+                        "rule": rule["name"],
+                        "check": LintCheck(rule["check"]).value,
+                        "severity": rule["severity"],
+                        "blocking": rule.get("blocking", True),
+                        "endpoint": endpoint.key,
+                        "message": f"{endpoint.key} {reason}",
+                        "policy_ids": endpoint.policy_ids,
+                        "file_path": endpoint.file_path,
+                        "line_start": endpoint.line_start,
+                    }
+                )
+    violations.sort(key=lambda v: (SEVERITY_ORDER.get(v["severity"], 9), v["rule"], v["endpoint"]))
+    return violations, len(endpoints)
+
+
+def gate(violations: list[dict], fail_on: str) -> list[dict]:
+    """Violations that fail the CI gate: blocking ones at or above fail_on."""
+    threshold = SEVERITY_ORDER[fail_on]
+    return [v for v in violations if v["blocking"] and SEVERITY_ORDER.get(v["severity"], 9) <= threshold]
+
+
+class PolicyLintService:
+    """Manages organizational lint rules and lints repositories against them."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def list_rules(self) -> list[LintRule]:
+        """The workspace's lint rules, ordered by name."""
+        return self._query(LintRule).order_by(LintRule.name.asc()).all()
+
+    def get_rule(self, rule_id: int) -> LintRule:
+        """Get a lint rule.
+
+        Raises:
+            ValueError: If the rule does not exist
+        """
+        rule = self._query(LintRule).filter(LintRule.id == rule_id).first()
+        if not rule:
+            raise ValueError(f"Lint rule {rule_id} not found")
+        return rule
+
+    def create_rule(
+        self,
+        name: str,
+        check: LintCheck,
+        params: dict | None = None,
+        severity: str = "medium",
+        description: str | None = None,
+        enabled: bool = True,
+        blocking: bool = True,
+        created_by: str | None = None,
+    ) -> LintRule:
+        """Define a lint rule for the workspace.
+
+        Args:
+            name: Rule name, unique within the workspace
+            check: What the rule verifies
+            params: Scope (methods, paths, exclude_paths) and check settings
+            severity: low, medium, high, or critical
+            description: What the rule requires, in the organization's words
+            enabled: Whether scans evaluate the rule
+            blocking: Whether violations can fail the CI gate
+            created_by: Creator's email
+
+        Returns:
+            Created rule
+
+        Raises:
+            ValueError: If the name is taken or the params or severity are invalid
+        """
+        name = name.strip()
+        if not name:
+            raise ValueError("Rule name is required")
+        params = params or {}
+        validate_rule(check, params, severity)
+        if self._query(LintRule).filter(LintRule.name == name).first():
+            raise ValueError(f"A lint rule named '{name}' already exists")
+        rule = LintRule(
+            tenant_id=self.tenant_id,
+            name=name,
+            description=description,
+            check=check,
+            params=params,
+            severity=severity,
+            enabled=enabled,
+            blocking=blocking,
+            created_by=created_by,
+        )
+        self.db.add(rule)
+        self.db.commit()
+        self.db.refresh(rule)
+        logger.info("lint_rule_created", rule_id=rule.id, check=check.value, tenant_id=self.tenant_id)
+        return rule
+
+    def update_rule(self, rule_id: int, **changes) -> LintRule:
+        """Change a rule's params, severity, or switches; None values are ignored.
+
+        Raises:
+            ValueError: If the rule does not exist or the result is invalid
+        """
+        rule = self.get_rule(rule_id)
+        changes = {key: value for key, value in changes.items() if value is not None}
+        validate_rule(rule.check, changes.get("params", rule.params or {}), changes.get("severity", rule.severity))
+        for key, value in changes.items():
+            setattr(rule, key, value)
+        self.db.commit()
+        self.db.refresh(rule)
+        return rule
+
+    def delete_rule(self, rule_id: int) -> None:
+        """Delete a rule.
+
+        Raises:
+            ValueError: If the rule does not exist
+        """
+        self.db.delete(self.get_rule(rule_id))
+        self.db.commit()
+
+    def active_rules(self) -> list[dict]:
+        """Enabled rule definitions, or the defaults when the workspace defines none."""
+        rules = self.list_rules()
+        if not rules:
+            return [dict(rule) for rule in DEFAULT_LINT_RULES]
+        return [
+            {
+                "name": rule.name,
+                "description": rule.description,
+                "check": rule.check,
+                "params": rule.params or {},
+                "severity": rule.severity,
+                "blocking": rule.blocking,
+            }
+            for rule in rules
+            if rule.enabled
+        ]
+
+    def lint(self, repository_id: int, fail_on: str = "high") -> dict:
+        """Lint a repository's current policies.
+
+        Args:
+            repository_id: Repository ID
+            fail_on: Lowest severity of a blocking violation that fails the gate
+
+        Returns:
+            Lint report with violations and the gate outcome
+
+        Raises:
+            ValueError: If the repository does not exist or fail_on is unknown
+        """
+        if fail_on not in SEVERITY_ORDER:
+            raise ValueError(f"fail_on must be one of {', '.join(SEVERITY_LEVELS)}")
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        rules = self.active_rules()
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository_id)
+        violations, endpoints = lint_endpoints(rules, policies)
+        failing = gate(violations, fail_on)
+        return {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "rules_evaluated": len(rules),
+            "endpoints_evaluated": endpoints,
+            "violation_counts": dict(Counter(v["severity"] for v in violations)),
+            "violations": violations,
+            "fail_on": fail_on,
+            "blocking_violations": len(failing),
+            "passed": not failing,
+        }
+
+    def record_run(self, repository_id: int, scan_id: int | None = None) -> LintRun:
+        """Lint a repository and store the results against a scan.
+
+        Args:
+            repository_id: Repository that was just scanned
+            scan_id: The scan
+
+        Returns:
+            Stored lint run
+        """
+        report = self.lint(repository_id)
+        run = LintRun(
+            tenant_id=self.tenant_id,
+            repository_id=repository_id,
+            scan_id=scan_id,
+            rules_evaluated=report["rules_evaluated"],
+            endpoints_evaluated=report["endpoints_evaluated"],
+            violations=report["violations"],
+            violation_counts=report["violation_counts"],
+        )
+        self.db.add(run)
+        self.db.commit()
+        logger.info(
+            "policy_lint_recorded",
+            repository_id=repository_id,
+            scan_id=scan_id,
+            violations=len(report["violations"]),
+        )
+        return run
+
+    def runs(self, repository_id: int, limit: int = 20) -> list[LintRun]:
+        """Stored lint runs of a repository, newest first."""
+        return (
+            self._query(LintRun)
+            .filter(LintRun.repository_id == repository_id)
+            .order_by(LintRun.id.desc())
+            .limit(limit)
+            .all()
+        )
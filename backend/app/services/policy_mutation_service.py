@@ -0,0 +1,242 @@
+"""Service for mutation testing mined policies.
+
+A generated authorization suite is only useful if it fails when enforcement
+changes. This service applies small mutations to each endpoint rule (drop a
+role, grant an extra role, make an endpoint public, flip a threshold, remove a
+condition) and checks whether the generated test cases would detect each one.
+The share of detected ("killed") mutants is the repository's policy test
+strength score.
+"""
+
+import copy
+import re
+from dataclasses import dataclass
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.services.authz_test_generation_service import AuthzTestCase, AuthzTestGenerationService
+from app.services.contract_verification_service import SAFE_METHODS
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+
+logger = structlog.get_logger(__name__)
+
+# Comparison operators and the boundary-shifted operator a mutant swaps in
+THRESHOLD_OPERATOR_FLIPS = {">=": ">", "<=": "<", ">": ">=", "<": "<=", "==": "!="}
+
+THRESHOLD_PATTERN = re.compile(r"(>=|<=|==|>|<)\s*\$?([\d,]+(?:\.\d+)?)")
+
+
+class MutationHarness:
+    """Test harnesses whose detection power can be scored."""
+
+    GENERATED_TESTS = "generated_tests"  # committed suites; every non-skipped case runs
+    PROBE = "probe"  # live contract verification; only safe methods are sent
+
+
+class MutationOperator:
+    """Supported mutation operators."""
+
+    DROP_ROLE = "drop_role"
+    ADD_ROLE = "add_role"
+    MAKE_PUBLIC = "make_public"
+    FLIP_THRESHOLD = "flip_threshold"
+    REMOVE_CONDITION = "remove_condition"
+
+
+@dataclass
+class PolicyMutant:
+    """A mutated copy of one endpoint rule."""
+
+    id: str
+    operator: str
+    endpoint: str
+    description: str
+    original: EndpointRule
+    mutated: EndpointRule
+    killed: bool = False
+    killed_by: str | None = None
+
+
+class PolicyMutationService:
+    """Measures how well generated tests detect policy mutations."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.test_generation_service = AuthzTestGenerationService(db, tenant_id)
+
+    def score_repository(
+        self, repository_id: int, harness: str = MutationHarness.GENERATED_TESTS
+    ) -> dict:
+        """Compute the policy test strength score for a repository.
+
+        Args:
+            repository_id: Repository ID
+            harness: Which harness' cases are considered executed
+
+        Returns:
+            Mutation report with score and per-mutant outcomes
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        _, rules = self.test_generation_service.load_endpoint_rules(repository_id)
+        cases = AuthzTestGenerationService.build_cases(rules)
+        if harness == MutationHarness.PROBE:
+            cases = [c for c in cases if c.method in SAFE_METHODS]
+        mutants = self.generate_mutants(rules)
+        self.evaluate_mutants(mutants, cases)
+
+        report = self._build_report(repository_id, harness, mutants)
+        logger.info(
+            "policy_mutation_score_computed",
+            repository_id=repository_id,
+            harness=harness,
+            mutants=report["total_mutants"],
+            killed=report["killed"],
+            strength_score=report["strength_score"],
+        )
+        return report
+
+    @staticmethod
+    def generate_mutants(rules: list[EndpointRule]) -> list[PolicyMutant]:
+        """Generate mutants for every endpoint rule.
+
+        Args:
+            rules: Original endpoint rules
+
+        Returns:
+            Mutants in rule order
+        """
+        all_roles = EndpointMappingService.collect_roles(rules)
+        mutants: list[PolicyMutant] = []
+
+        def add(rule: EndpointRule, operator: str, description: str, mutated: EndpointRule) -> None:
+            mutants.append(
+                PolicyMutant(
+                    id=f"{rule.key}#{len(mutants) + 1}",
+                    operator=operator,
+                    endpoint=rule.key,
+                    description=description,
+                    original=rule,
+                    mutated=mutated,
+                )
+            )
+
+        for rule in rules:
+            for role in rule.roles:
+                mutated = copy.deepcopy(rule)
+                mutated.roles = [r for r in rule.roles if r != role]
+                if mutated.roles:
+                    description = f"drop {role} from allowed roles"
+                else:
+                    description = f"drop {role}, leaving any authenticated user allowed"
+                add(rule, MutationOperator.DROP_ROLE, description, mutated)
+
+            if rule.roles:
+                outsider = next((r for r in all_roles if r not in rule.roles), None)
+                if outsider:
+                    mutated = copy.deepcopy(rule)
+                    mutated.roles = [*rule.roles, outsider]
+                    add(rule, MutationOperator.ADD_ROLE, f"also allow {outsider}", mutated)
+
+            if rule.requires_authentication:
+                mutated = copy.deepcopy(rule)
+                mutated.requires_authentication = False
+                add(rule, MutationOperator.MAKE_PUBLIC, "allow anonymous callers", mutated)
+
+            for index, condition in enumerate(rule.conditions):
+                flipped = PolicyMutationService.flip_threshold(condition)
+                if flipped:
+                    mutated = copy.deepcopy(rule)
+                    mutated.conditions[index] = flipped
+                    add(rule, MutationOperator.FLIP_THRESHOLD, f"'{condition}' -> '{flipped}'", mutated)
+
+                mutated = copy.deepcopy(rule)
+                del mutated.conditions[index]
+                add(rule, MutationOperator.REMOVE_CONDITION, f"remove condition '{condition}'", mutated)
+
+        return mutants
+
+    @staticmethod
+    def flip_threshold(condition: str) -> str | None:
+        """Shift the boundary of the first numeric comparison in a condition.
+
+        Args:
+            condition: Condition text (e.g., "amount > 5000")
+
+        Returns:
+            Mutated condition, or None if it has no numeric comparison
+        """
+        match = THRESHOLD_PATTERN.search(condition)
+        if not match:
+            return None
+        operator = match.group(1)
+        start, end = match.span(1)
+        return condition[:start] + THRESHOLD_OPERATOR_FLIPS[operator] + condition[end:]
+
+    @staticmethod
+    def evaluate_mutants(mutants: list[PolicyMutant], cases: list[AuthzTestCase]) -> None:
+        """Mark each mutant killed if an executed test case would fail against it.
+
+        A case kills a mutant when it targets the mutated endpoint, is not
+        skipped, and the mutated rule changes whether the case's role is
+        allowed. Condition mutants can only be killed by cases that exercise
+        the condition, which the generated suites skip today, so they survive
+        and lower the score until condition fixtures are provided.
+
+        Args:
+            mutants: Mutants to evaluate (updated in place)
+            cases: Generated test cases for the original rules
+        """
+        for mutant in mutants:
+            path = AuthzTestGenerationService.concrete_path(mutant.original.path)
+            for case in cases:
+                if case.method != mutant.original.method or case.path != path or case.skip_reason:
+                    continue
+                if mutant.mutated.allows(case.role) != case.allowed:
+                    mutant.killed = True
+                    mutant.killed_by = case.name
+                    break
+
+    @staticmethod
+    def _build_report(repository_id: int, harness: str, mutants: list[PolicyMutant]) -> dict:
+        """Summarize mutant outcomes.
+
+        Args:
+            repository_id: Repository ID
+            harness: Harness the score applies to
+            mutants: Evaluated mutants
+
+        Returns:
+            Report dictionary
+        """
+        killed = sum(1 for m in mutants if m.killed)
+        by_operator: dict[str, dict[str, int]] = {}
+        for mutant in mutants:
+            stats = by_operator.setdefault(mutant.operator, {"total": 0, "killed": 0})
+            stats["total"] += 1
+            stats["killed"] += int(mutant.killed)
+
+        return {
+            "repository_id": repository_id,
+            "harness": harness,
+            "strength_score": round(killed / len(mutants) * 100, 1) if mutants else 0.0,
+            "total_mutants": len(mutants),
+            "killed": killed,
+            "survived": len(mutants) - killed,
+            "by_operator": by_operator,
+            "mutants": [
+                {
+                    "id": m.id,
+                    "operator": m.operator,
+                    "endpoint": m.endpoint,
+                    "description": m.description,
+                    "killed": m.killed,
+                    "killed_by": m.killed_by,
+                }
+                for m in mutants
+            ],
+        }
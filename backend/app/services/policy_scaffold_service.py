@@ -0,0 +1,669 @@
+"""Service for opening policy-as-code scaffolding pull requests.
+
+Gives a service team a running start on centralized authorization: the
+approved policies mined from their repository are translated to Rego (laid
+out as an OPA bundle, as bundle publishing does) or Cedar, and committed
+together with an integration stub in the service's own language that asks
+the PDP for each decision (OPA's data API, or a Cedar agent's is_authorized
+endpoint). The pull request description lists every inline check a policy
+replaces, so the migration can proceed check by check.
+
+GitHub (including Enterprise) gets a pull request and GitLab a merge
+request, opened with the repository's access token. Every attempt is
+recorded, failures included.
+"""
+
+import base64
+from datetime import UTC, datetime
+from pathlib import Path
+from urllib.parse import quote, urlparse
+
+import httpx
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import ensure_host_allowed
+from app.core.config import settings
+from app.core.test_mode import is_test_mode
+from app.models.policy import Policy, PolicyStatus
+from app.models.repository import Repository
+from app.models.scaffold_pull_request import ScaffoldFormat, ScaffoldPullRequest, ScaffoldPullRequestStatus
+from app.services.bundle_publish_service import bundle_files, package_module
+from app.services.readiness_service import detect_frameworks, load_sources
+from app.services.translation_service import TranslationService
+
+logger = structlog.get_logger(__name__)
+
+POLICY_DIR = "policy"
+STUB_DIR = "authz"
+BRANCH_PREFIX = "policy-miner/scaffold"
+
+# Where the integration stub reaches the PDP, overridable through an environment variable
+PDPS = {
+    ScaffoldFormat.REGO: ("OPA", "OPA_URL", "http://localhost:8181/v1/data/policy_miner/allow"),
+    ScaffoldFormat.CEDAR: ("a Cedar agent", "CEDAR_AGENT_URL", "http://localhost:8180/v1/is_authorized"),
+}
+PDP_COMMANDS = {
+    ScaffoldFormat.REGO: f"opa run --server --bundle {POLICY_DIR}/",
+    ScaffoldFormat.CEDAR: f"cedar-agent, then load {POLICY_DIR}/policies.cedar through its /v1/policies API",
+}
+
+# Stub language per framework language, and per file suffix when no framework is recognized
+FRAMEWORK_LANGUAGES = {"JavaScript/TypeScript": "javascript", "Python": "python", "Java/Kotlin": "java", "Go": "go"}
+SUFFIX_LANGUAGES = {".js": "javascript", ".ts": "javascript", ".py": "python", ".java": "java", ".kt": "java", ".go": "go"}
+
+JAVASCRIPT_STUB = """// Generated by policy-miner: route authorization decided by @@PDP@@.
+//
+// Use it in place of inline role checks:
+//   router.post("/expenses/:id/approve", authorize("approve", "expense"), approveExpense);
+// or inside a handler:
+//   if (!(await isAllowed(req.user, "approve", "expense", req.path))) return res.status(403).end();
+const PDP_URL = process.env.@@ENV@@ || "@@URL@@";
+
+async function isAllowed(user, action, resourceType, path) {
+  user = user || {};
+  const response = await fetch(PDP_URL, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(@@BODY@@),
+  });
+  const decision = await response.json();
+  return response.ok && @@ALLOWED@@;
+}
+
+function authorize(action, resourceType) {
+  return async (req, res, next) => {
+    try {
+      if (await isAllowed(req.user, action, resourceType, req.path)) {
+        return next();
+      }
+      return res.status(403).json({ error: "forbidden" });
+    } catch (err) {
+      return next(err);
+    }
+  };
+}
+
+module.exports = { authorize, isAllowed };
+"""
+
+PYTHON_STUB = '''"""Generated by policy-miner: authorization decided by @@PDP@@.
+
+Use it in place of inline role checks, as a FastAPI dependency:
+
+    @app.post("/expenses/{id}/approve", dependencies=[Depends(authorize("approve", "expense"))])
+
+or by calling is_allowed() from a Django or Flask decorator.
+"""
+
+import os
+
+import httpx
+from fastapi import HTTPException, Request
+
+PDP_URL = os.environ.get("@@ENV@@", "@@URL@@")
+
+
+async def is_allowed(user, action: str, resource_type: str, path: str = "") -> bool:
+    """Ask the PDP whether a user may perform an action on a resource type."""
+    user_id = str(getattr(user, "id", "") or "")
+    roles = list(getattr(user, "roles", None) or [r for r in [getattr(user, "role", None)] if r])
+    async with httpx.AsyncClient(timeout=2.0) as client:
+        response = await client.post(PDP_URL, json=@@BODY@@)
+    response.raise_for_status()
+    decision = response.json()
+    return @@ALLOWED@@
+
+
+def authorize(action: str, resource_type: str):
+    """FastAPI dependency rejecting requests the PDP does not allow."""
+
+    async def dependency(request: Request) -> None:
+        user = getattr(request.state, "user", None)
+        if not await is_allowed(user, action, resource_type, request.url.path):
+            raise HTTPException(status_code=403, detail="forbidden")
+
+    return dependency
+'''
+
+JAVA_STUB = """package authz;
+
+import java.io.IOException;
+import java.net.URI;
+import java.net.http.HttpClient;
+import java.net.http.HttpRequest;
+import java.net.http.HttpResponse;
+import java.util.List;
+import java.util.stream.Collectors;
+
+/**
+ * Generated by policy-miner: authorization decided by @@PDP@@.
+ *
+ * <p>Register it as a bean named "pdp" and use it in place of inline role checks:
+ * {@code @PreAuthorize("@pdp.isAllowed(principal.username, principal.roles, 'approve', 'expense', '')")}
+ */
+public final class PolicyDecisionClient {
+    private static final String PDP_URL = System.getenv().getOrDefault("@@ENV@@", "@@URL@@");
+
+    private final HttpClient client = HttpClient.newHttpClient();
+
+    public boolean isAllowed(String userId, List<String> roles, String action, String resourceType, String path)
+            throws IOException, InterruptedException {
+        String rolesJson = roles.stream().map(PolicyDecisionClient::json).collect(Collectors.joining(",", "[", "]"));
+        String body = @@BODY@@;
+        HttpRequest request = HttpRequest.newBuilder(URI.create(PDP_URL))
+                .header("Content-Type", "application/json")
+                .POST(HttpRequest.BodyPublishers.ofString(body))
+                .build();
+        HttpResponse<String> response = client.send(request, HttpResponse.BodyHandlers.ofString());
+        // Swap in the service's JSON mapper to read the decision
+        String decision = response.body().replace(" ", "");
+        return response.statusCode() == 200 && @@ALLOWED@@;
+    }
+
+    private static String json(String value) {
+        return "\\"" + value.replace("\\\\", "\\\\\\\\").replace("\\"", "\\\\\\"") + "\\"";
+    }
+}
+"""
+
+GO_STUB = """// Package authz is generated by policy-miner: authorization decided by @@PDP@@.
+//
+// Wrap handlers in place of inline role checks:
+//
+//	mux.Handle("/expenses/approve", authz.Authorize("approve", "expense", currentUser)(approveExpense))
+//
+// or call IsAllowed inside a handler.
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+)
+
+var pdpURL = envOr("@@ENV@@", "@@URL@@")
+
+// User is the caller identity sent to the PDP.
+type User struct {
+	ID    string   `json:"id"`
+	Roles []string `json:"roles"`
+}
+
+// Authorize returns middleware rejecting requests the PDP does not allow.
+func Authorize(action, resourceType string, currentUser func(*http.Request) User) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, err := IsAllowed(r.Context(), currentUser(r), action, resourceType, r.URL.Path)
+			if err != nil {
+				http.Error(w, "authorization unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			if !allowed {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsAllowed asks the PDP whether a user may perform an action on a resource type.
+func IsAllowed(ctx context.Context, user User, action, resourceType, path string) (bool, error) {
+	body, err := json.Marshal(@@BODY@@)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pdpURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	@@ALLOWED@@
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+"""
+
+# language -> (stub path, template, {format: (request body, allowed expression)})
+STUBS = {
+    "javascript": (
+        f"{STUB_DIR}/pdp.js",
+        JAVASCRIPT_STUB,
+        {
+            ScaffoldFormat.REGO: (
+                "{ input: { user: { id: user.id, roles: user.roles || [user.role] }, action, resource: { type: resourceType, path } } }",
+                "decision.result === true",
+            ),
+            ScaffoldFormat.CEDAR: (
+                '{ principal: `User::"${user.id}"`, action: `Action::"${action}"`, resource: `ResourceType::"${resourceType}"`, context: { path } }',
+                'decision.decision === "Allow"',
+            ),
+        },
+    ),
+    "python": (
+        f"{STUB_DIR}/pdp.py",
+        PYTHON_STUB,
+        {
+            ScaffoldFormat.REGO: (
+                '{"input": {"user": {"id": user_id, "roles": roles}, "action": action, "resource": {"type": resource_type, "path": path}}}',
+                'decision.get("result") is True',
+            ),
+            ScaffoldFormat.CEDAR: (
+                '{"principal": f\'User::"{user_id}"\', "action": f\'Action::"{action}"\', "resource": f\'ResourceType::"{resource_type}"\', "context": {"path": path}}',
+                'decision.get("decision") == "Allow"',
+            ),
+        },
+    ),
+    "java": (
+        f"{STUB_DIR}/PolicyDecisionClient.java",
+        JAVA_STUB,
+        {
+            ScaffoldFormat.REGO: (
+                '"{\\"input\\":{\\"user\\":{\\"id\\":" + json(userId) + ",\\"roles\\":" + rolesJson + "},\\"action\\":" + json(action)\n'
+                '                + ",\\"resource\\":{\\"type\\":" + json(resourceType) + ",\\"path\\":" + json(path) + "}}}"',
+                'decision.contains("\\"result\\":true")',
+            ),
+            ScaffoldFormat.CEDAR: (
+                '"{\\"principal\\":" + json("User::\\"" + userId + "\\"") + ",\\"action\\":" + json("Action::\\"" + action + "\\"")\n'
+                '                + ",\\"resource\\":" + json("ResourceType::\\"" + resourceType + "\\"") + ",\\"context\\":{\\"path\\":" + json(path) + "}}"',
+                'decision.contains("\\"decision\\":\\"Allow\\"")',
+            ),
+        },
+    ),
+    "go": (
+        f"{STUB_DIR}/pdp.go",
+        GO_STUB,
+        {
+            ScaffoldFormat.REGO: (
+                'map[string]any{"input": map[string]any{\n'
+                '\t\t"user": user, "action": action, "resource": map[string]string{"type": resourceType, "path": path},\n'
+                "\t}}",
+                "var decision struct {\n\t\tResult bool `json:\"result\"`\n\t}\n"
+                "\tif err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {\n\t\treturn false, err\n\t}\n"
+                "\treturn decision.Result, nil",
+            ),
+            ScaffoldFormat.CEDAR: (
+                'map[string]any{\n'
+                '\t\t"principal": "User::\\"" + user.ID + "\\"", "action": "Action::\\"" + action + "\\"",\n'
+                '\t\t"resource": "ResourceType::\\"" + resourceType + "\\"", "context": map[string]string{"path": path},\n'
+                "\t}",
+                "var decision struct {\n\t\tDecision string `json:\"decision\"`\n\t}\n"
+                "\tif err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {\n\t\treturn false, err\n\t}\n"
+                '\treturn decision.Decision == "Allow", nil',
+            ),
+        },
+    ),
+}
+
+
+def integration_stub(language: str, policy_format: ScaffoldFormat) -> tuple[str, str]:
+    """Path and source of the PDP integration stub for a language.
+
+    Raises:
+        ValueError: If there is no stub for the language
+    """
+    if language not in STUBS:
+        raise ValueError(f"No integration stub for '{language}'; use {', '.join(STUBS)}")
+    path, template, requests = STUBS[language]
+    pdp, env, url = PDPS[policy_format]
+    body, allowed = requests[policy_format]
+    source = template.replace("@@PDP@@", pdp).replace("@@ENV@@", env).replace("@@URL@@", url)
+    return path, source.replace("@@BODY@@", body).replace("@@ALLOWED@@", allowed)
+
+
+def stub_language(sources: dict[str, str]) -> str | None:
+    """Language of a service's integration stub: its main framework's, else its most common source language."""
+    frameworks = [f for f in detect_frameworks(sources) if f["language"] in FRAMEWORK_LANGUAGES]
+    if frameworks:
+        return FRAMEWORK_LANGUAGES[max(frameworks, key=lambda f: f["files"])["language"]]
+    counts: dict[str, int] = {}
+    for path in sources:
+        language = SUFFIX_LANGUAGES.get(Path(path).suffix)
+        if language:
+            counts[language] = counts.get(language, 0) + 1
+    return max(counts, key=counts.get) if counts else None
+
+
+def policy_files(translations: dict[int, str], policy_format: ScaffoldFormat, revision: str) -> dict[str, str]:
+    """Generated policy files: an OPA bundle directory for Rego, one policy set for Cedar.
+
+    Args:
+        translations: Policy ID to translated policy source
+        policy_format: Policy language of the translations
+        revision: Bundle revision for the OPA manifest
+    """
+    if policy_format == ScaffoldFormat.REGO:
+        modules = {policy_id: package_module(policy_id, rego) for policy_id, rego in translations.items()}
+        return {f"{POLICY_DIR}/{path}": content.decode() for path, content in bundle_files(modules, revision).items()}
+    statements = [f"// Policy {policy_id}\n{cedar.strip()}\n" for policy_id, cedar in sorted(translations.items())]
+    return {f"{POLICY_DIR}/policies.cedar": "\n".join(statements)}
+
+
+def _evidence_locations(policy: Policy) -> str:
+    """Where a policy's inline checks live, as file:line ranges."""
+    locations = [f"{e.file_path}:{e.line_start}-{e.line_end}" for e in (policy.evidence or [])]
+    return ", ".join(locations[:5]) + (f" and {len(locations) - 5} more" if len(locations) > 5 else "") or "-"
+
+
+def scaffold_readme(repo: Repository, policies: list[Policy], policy_format: ScaffoldFormat, stub_path: str) -> str:
+    """README of the scaffold, also used as the pull request description."""
+    pdp, env, url = PDPS[policy_format]
+    layout = (
+        f"- `{POLICY_DIR}/` is an OPA bundle: one module per policy, combined by `policy_miner.allow`"
+        if policy_format == ScaffoldFormat.REGO
+        else f"- `{POLICY_DIR}/policies.cedar` holds one Cedar policy per mined policy"
+    )
+    lines = [
+        "# Centralized authorization scaffold",
+        "",
+        f"Generated by policy-miner from {len(policies)} approved policies mined from {repo.name}.",
+        "",
+        layout,
+        f"- `{stub_path}` asks {pdp} for each decision at `${env}` (default `{url}`)",
+        "",
+        f"Run the PDP with `{PDP_COMMANDS[policy_format]}`.",
+        "",
+        "## Checks to migrate",
+        "",
+        "Each policy replaces the inline checks it was mined from. Route a check through the stub, "
+        "confirm the decisions match, then delete the inline check.",
+        "",
+        "| Policy | Rule | Replaces |",
+        "| --- | --- | --- |",
+    ]
+    for policy in policies:
+        rule = f"{policy.subject} may {policy.action} {policy.resource}"
+        if policy.conditions:
+            rule += f" when {policy.conditions}"
+        lines.append(f"| {policy.id} | {rule.replace('|', '/')} | {_evidence_locations(policy)} |")
+    return "\n".join(lines) + "\n"
+
+
+def parse_remote(source_url: str | None) -> tuple[str, str, str]:
+    """Provider, web base URL, and project path of a repository's remote.
+
+    Raises:
+        ValueError: If the remote is not a GitHub or GitLab repository
+    """
+    url = source_url or ""
+    if url.startswith("git@") and ":" in url:
+        host, path = url[4:].split(":", 1)
+        scheme = "https"
+    else:
+        parsed = urlparse(url)
+        host, path, scheme = parsed.hostname or "", parsed.path, parsed.scheme or "https"
+    path = path.strip("/").removesuffix(".git")
+    provider = "gitlab" if "gitlab" in host else "github" if "github" in host else None
+    if provider is None or path.count("/") < 1:
+        raise ValueError("Scaffold pull requests need a GitHub or GitLab repository URL")
+    return provider, f"{scheme}://{host}", path
+
+
+class GitHubPullRequests:
+    """Opens pull requests through the GitHub REST API."""
+
+    def __init__(self, client: httpx.AsyncClient, web_url: str, project: str, token: str):
+        """Initialize client."""
+        self.client = client
+        # GitHub Enterprise serves the API under /api/v3
+        self.api = "https://api.github.com" if web_url == "https://github.com" else f"{web_url}/api/v3"
+        self.project = project
+        self.headers = {"Authorization": f"Bearer {token}", "Accept": "application/vnd.github+json"}
+
+    async def request(self, method: str, path: str, **kwargs) -> dict:
+        """Call the API, raising on an error response."""
+        response = await self.client.request(method, f"{self.api}/repos/{self.project}{path}", headers=self.headers, **kwargs)
+        response.raise_for_status()
+        return response.json()
+
+    async def default_branch(self) -> str:
+        """The repository's default branch."""
+        return (await self.request("GET", ""))["default_branch"]
+
+    async def open(self, base: str, branch: str, files: dict[str, str], title: str, body: str) -> tuple[str, int]:
+        """Create a branch off base, commit the files to it, and open a pull request.
+
+        Returns:
+            (pull request URL, pull request number)
+        """
+        head = await self.request("GET", f"/git/ref/heads/{quote(base)}")
+        await self.request("POST", "/git/refs", json={"ref": f"refs/heads/{branch}", "sha": head["object"]["sha"]})
+        for path, content in files.items():
+            await self.request(
+                "PUT",
+                f"/contents/{quote(path)}",
+                json={"message": f"Add {path}", "content": base64.b64encode(content.encode()).decode(), "branch": branch},
+            )
+        pull = await self.request("POST", "/pulls", json={"title": title, "head": branch, "base": base, "body": body})
+        return pull["html_url"], pull["number"]
+
+
+class GitLabMergeRequests:
+    """Opens merge requests through the GitLab REST API."""
+
+    def __init__(self, client: httpx.AsyncClient, web_url: str, project: str, token: str):
+        """Initialize client."""
+        self.client = client
+        self.api = f"{web_url}/api/v4/projects/{quote(project, safe='')}"
+        self.headers = {"PRIVATE-TOKEN": token}
+
+    async def request(self, method: str, path: str, **kwargs) -> dict:
+        """Call the API, raising on an error response."""
+        response = await self.client.request(method, f"{self.api}{path}", headers=self.headers, **kwargs)
+        response.raise_for_status()
+        return response.json()
+
+    async def default_branch(self) -> str:
+        """The project's default branch."""
+        return (await self.request("GET", ""))["default_branch"]
+
+    async def open(self, base: str, branch: str, files: dict[str, str], title: str, body: str) -> tuple[str, int]:
+        """Commit the files to a new branch off base in one commit, and open a merge request.
+
+        Returns:
+            (merge request URL, merge request IID)
+        """
+        actions = [{"action": "create", "file_path": path, "content": content} for path, content in files.items()]
+        await self.request(
+            "POST",
+            "/repository/commits",
+            json={"branch": branch, "start_branch": base, "commit_message": title, "actions": actions},
+        )
+        merge = await self.request(
+            "POST", "/merge_requests", json={"source_branch": branch, "target_branch": base, "title": title, "description": body}
+        )
+        return merge["web_url"], merge["iid"]
+
+
+PROVIDERS = {"github": GitHubPullRequests, "gitlab": GitLabMergeRequests}
+
+
+class PolicyScaffoldService:
+    """Generates policy-as-code scaffolds and opens them as pull requests."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repo = self._query(Repository).filter(Repository.id == repository_id).first()
+        if not repo:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repo
+
+    def scope_policies(self, repo: Repository, application_id: int | None = None) -> list[Policy]:
+        """Approved policies of a repository, optionally only one application's.
+
+        Raises:
+            ValueError: If there are none
+        """
+        query = self._query(Policy).filter(Policy.repository_id == repo.id, Policy.status == PolicyStatus.APPROVED)
+        if application_id is not None:
+            query = query.filter(Policy.application_id == application_id)
+        policies = query.order_by(Policy.id).all()
+        if not policies:
+            raise ValueError(f"Repository {repo.id} has no approved policies to scaffold")
+        return policies
+
+    def detect_language(self, repo: Repository) -> str:
+        """Stub language detected from a repository's clone.
+
+        Raises:
+            ValueError: If the repository is not cloned or has no language with a stub
+        """
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repo.id} has not been cloned yet; pass a language or run a scan first")
+        sources, _ = load_sources(root)
+        language = stub_language(sources)
+        if language is None:
+            raise ValueError(f"No integration stub fits repository {repo.id}; pass one of {', '.join(STUBS)}")
+        return language
+
+    async def build(
+        self,
+        repository_id: int,
+        policy_format: ScaffoldFormat = ScaffoldFormat.REGO,
+        language: str | None = None,
+        application_id: int | None = None,
+    ) -> dict:
+        """Generate a repository's scaffold without opening a pull request.
+
+        Args:
+            repository_id: Repository ID
+            policy_format: Rego or Cedar
+            language: Stub language; detected from the clone if omitted
+            application_id: Scaffold only this application's policies
+
+        Returns:
+            Scaffold with its files (path -> content), policy IDs, stub language, title, and description
+
+        Raises:
+            ValueError: If the repository does not exist, has nothing to scaffold, or a translation fails
+        """
+        repo = self.get_repository(repository_id)
+        policies = self.scope_policies(repo, application_id)
+        language = language or self.detect_language(repo)
+        stub_path, stub = integration_stub(language, policy_format)
+
+        translator = TranslationService()
+        translate = translator.translate_to_rego if policy_format == ScaffoldFormat.REGO else translator.translate_to_cedar
+        translations = {p.id: await translate(p) for p in policies}
+        revision = f"scaffold-{datetime.now(UTC):%Y%m%dT%H%M%SZ}"
+        readme = scaffold_readme(repo, policies, policy_format, stub_path)
+        files = {**policy_files(translations, policy_format, revision), stub_path: stub, f"{POLICY_DIR}/README.md": readme}
+        return {
+            "repository_id": repo.id,
+            "policy_format": policy_format.value,
+            "language": language,
+            "policy_ids": [p.id for p in policies],
+            "title": f"Add generated {policy_format.value.capitalize()} policies and a PDP integration stub",
+            "body": readme,
+            "files": files,
+        }
+
+    async def open_pull_request(
+        self,
+        repository_id: int,
+        policy_format: ScaffoldFormat = ScaffoldFormat.REGO,
+        language: str | None = None,
+        application_id: int | None = None,
+        requested_by: str | None = None,
+    ) -> ScaffoldPullRequest:
+        """Generate a repository's scaffold and open it as a pull request.
+
+        A failure to reach the Git host is recorded on the returned record
+        rather than raised, so the history shows every attempt.
+
+        Raises:
+            ValueError: If the scaffold cannot be built, or the repository has no
+                GitHub or GitLab remote or access token
+        """
+        repo = self.get_repository(repository_id)
+        provider, web_url, project = parse_remote(repo.source_url)
+        token = (repo.connection_config or {}).get("token")
+        if not token and not is_test_mode():
+            raise ValueError(f"Repository {repository_id} has no access token to open a pull request with")
+        scaffold = await self.build(repository_id, policy_format, language, application_id)
+
+        record = ScaffoldPullRequest(
+            tenant_id=self.tenant_id,
+            repository_id=repo.id,
+            application_id=application_id,
+            policy_format=policy_format,
+            language=scaffold["language"],
+            branch=f"{BRANCH_PREFIX}-{policy_format.value}-{datetime.now(UTC):%Y%m%d%H%M%S}",
+            title=scaffold["title"],
+            files=sorted(scaffold["files"]),
+            policy_ids=scaffold["policy_ids"],
+            status=ScaffoldPullRequestStatus.PENDING,
+            requested_by=requested_by,
+        )
+        self.db.add(record)
+        self.db.flush()
+
+        try:
+            if is_test_mode():
+                record.base_branch = "main"
+                record.url, record.number = f"{web_url}/{project}/pull/1", 1
+            else:
+                ensure_host_allowed(urlparse(web_url).hostname, "scaffold_pull_request")
+                async with httpx.AsyncClient(timeout=30.0) as client:
+                    host = PROVIDERS[provider](client, web_url, project, token)
+                    record.base_branch = await host.default_branch()
+                    record.url, record.number = await host.open(
+                        record.base_branch, record.branch, scaffold["files"], record.title, scaffold["body"]
+                    )
+            record.status = ScaffoldPullRequestStatus.OPENED
+        except Exception as e:
+            record.status = ScaffoldPullRequestStatus.FAILED
+            record.error_message = str(e)
+            logger.error("scaffold_pull_request_failed", repository_id=repo.id, error=str(e))
+        self.db.commit()
+
+        logger.info(
+            "scaffold_pull_request_opened",
+            repository_id=repo.id,
+            status=record.status.value,
+            policies=len(record.policy_ids),
+            policy_format=policy_format.value,
+            tenant_id=self.tenant_id,
+        )
+        return record
+
+    def list_pull_requests(self, repository_id: int) -> list[ScaffoldPullRequest]:
+        """A repository's scaffold pull requests, newest first."""
+        repo = self.get_repository(repository_id)
+        return (
+            self._query(ScaffoldPullRequest)
+            .filter(ScaffoldPullRequest.repository_id == repo.id)
+            .order_by(ScaffoldPullRequest.id.desc())
+            .all()
+        )
@@ -0,0 +1,1302 @@
+"""Extract Rails controller authorization, mapped to routes.rb endpoints.
+
+Rails controllers authenticate through before_action filters such as
+Devise's authenticate_user!, inherited from ApplicationController and lifted
+per action with skip_before_action. Pundit authorizes inside actions with
+authorize calls, answered by the query method of the record's policy class
+(OrderPolicy#update? for authorize @order in update), and CanCanCan with
+load_and_authorize_resource or authorize!, answered by the can and cannot
+rules of the Ability class and the user checks they sit under. An action
+only becomes an endpoint through config/routes.rb, so each action's checks
+are mapped to the routes that resources, namespaces, scopes, and verb routes
+generate for it, including routes.rb's own authenticate blocks. Ruby is read
+as logical lines nested by their do/end blocks, without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field, replace
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.django_route_extractor import _access, _all, _any, _Rule
+from app.services.extractor_utils import line_of, line_span
+from app.services.jvm_route_extractor import ANONYMOUS, AUTHENTICATED, DENIED, MAX_SNIPPET_LINES, _join
+
+RAILS_SUFFIXES = (".rb",)
+
+# Files mentioning none of these are skipped without further parsing
+RAILS_MARKER = re.compile(
+    r"\b(?:ActionController|ApplicationController|routes\.draw|before_action|before_filter|Pundit|"
+    r"ApplicationPolicy|CanCan|authorize!?|load_and_authorize_resource)\b|_policy\.rb$"
+)
+
+
+class RailsRouteKind:
+    """Kinds of Rails findings."""
+
+    ACTION = "rails_action"
+
+
+# Limits on recursion through policy methods, filters, and drawn route files
+MAX_DEPTH = 8
+# Ability helper methods read per service, however often initialize calls them
+MAX_HELPER_CALLS = 200
+# Longest expression source quoted in descriptions
+MAX_SOURCE = 120
+# Blocks nested deeper than this are flattened into their enclosing block
+MAX_NESTING = 64
+# Physical lines one logical line may join while brackets stay open
+MAX_JOINED_LINES = 50
+
+RUBY_TOKEN = re.compile(r"""'(?:[^'\\\n]|\\.)*'|"(?:[^"\\\n]|\\.)*"|^=begin\b.*?(?:^=end\b[^\n]*|\Z)|#[^\n]*""", re.MULTILINE | re.DOTALL)
+OPENER = re.compile(
+    r"^(?:(?:private|protected|public)\s+)?(?:class|module|def|if|unless|case|while|until|for|begin)\b"
+    r"|=\s*(?:if|unless|case|begin)\b|\bdo[ \t]*(?:\|[^|\n]{0,200}\|)?$"
+)
+CLOSER = re.compile(r"^end\b")
+ENDLESS_DEF = re.compile(r"^(?:(?:private|protected|public)\s+)?def\s+[\w.]+[?!]?\s*(?:\([^)]*\))?\s*=(?![=~])")
+ONE_LINE_END = re.compile(r"\bend$")
+DO_SUFFIX = re.compile(r"\bdo[ \t]*(?:\|[^|\n]{0,200}\|)?$")
+MODIFIER = re.compile(r"\s(if|unless)\s")
+CALL_NAME = re.compile(r"[A-Za-z_]\w*(?:\s*\.\s*\w+)*[!?]?")
+CLASS_HEADER = re.compile(r"(class|module)\s+([A-Z][\w:]*)(?:\s*<\s*([A-Z][\w:]*))?")
+DEF_HEADER = re.compile(r"(?:(private|protected|public)\s+)?def\s+(self\.)?(\w+[?!=]?)\s*(?:\(([^)]*)\)|([^;=]*))?")
+ONE_LINE_DEF = re.compile(r"(?:(private|protected|public)\s+)?def\s+(\w+[?!]?)\s*(?:\([^)]*\))?\s*(?:=(?![=~])|;)\s*(.*?)(?:;\s*end)?$", re.DOTALL)
+VISIBILITY = re.compile(r"^(private|protected|public)$")
+ALIAS = re.compile(r"^alias_method\s*\(?\s*:(\w+[?!]?)\s*,\s*:(\w+[?!]?)|^alias\s+:?(\w+[?!]?)\s+:?(\w+[?!]?)")
+ARGUMENT_KEY = re.compile(r"^([a-z_]\w*):(?!:)\s*(.*)$|^:(\w+)\s*=>\s*(.*)$", re.DOTALL)
+ROCKET = re.compile(r"""^(['"])(.*?)\1\s*=>\s*(.*)$""", re.DOTALL)
+WORD_LIST = re.compile(r"^%[iIwW]\s*[\[({<](.*)[\])}>]$", re.DOTALL)
+
+HTTP_VERBS = ("get", "post", "put", "patch", "delete")
+# Routes resources and resource generate: (action, method, on the member path, path suffix)
+RESOURCES_ACTIONS = [
+    ("index", "GET", False, ""),
+    ("create", "POST", False, ""),
+    ("new", "GET", False, "new"),
+    ("show", "GET", True, ""),
+    ("edit", "GET", True, "edit"),
+    ("update", "PATCH", True, ""),
+    ("update", "PUT", True, ""),
+    ("destroy", "DELETE", True, ""),
+]
+RESOURCE_ACTIONS = [a for a in RESOURCES_ACTIONS if a[0] != "index"]
+# routes.rb calls that define no routes of this service's controllers
+ROUTING_IGNORED = {"devise_for", "mount", "use_doorkeeper", "concern", "direct", "resolve", "health_check"}
+
+FILTERS = ("before_action", "prepend_before_action", "append_before_action", "before_filter", "prepend_before_filter")
+SKIP_FILTERS = ("skip_before_action", "skip_before_filter")
+CANCAN_FILTERS = ("load_and_authorize_resource", "authorize_resource")
+# CanCanCan's default action aliases
+CANCAN_ALIASES = {"index": "read", "show": "read", "new": "create", "edit": "update"}
+
+# Devise scopes that stand for any signed-in user rather than a role
+USER_SCOPES = {"user", "account", "member", "customer", "person", "api_user"}
+DEVISE_AUTHENTICATE = re.compile(r"authenticate_(\w+?)!")
+SIGNED_IN = re.compile(r"(\w+)_signed_in\?")
+AUTHENTICATION_NAME = re.compile(
+    r"(?i)^(?:authenticate\w*!?|require_(?:login|user|authentication|signed_in\w*)|logged_in_user|"
+    r"ensure_(?:logged_in|signed_in|authenticated)\w*|doorkeeper_authorize!|authorize_request!?|verify_(?:jwt|token)\w*)$"
+)
+GUARD_NAME = re.compile(r"(?i)admin|role|permission|authoriz|staff|manager|owner|access|superuser")
+ROLE_PREDICATE = re.compile(
+    r"(?:super_?)?admin|manager|moderator|editor|staff|superuser|support|operator|auditor|reviewer|approver|maintainer|finance|accountant",
+)
+ROLE_METHODS = ("has_role", "has_any_role", "has_cached_role", "has_all_roles", "is_role", "role", "is")
+GUEST_PREDICATES = ("nil?", "blank?", "guest?", "new_record?", "anonymous?")
+PRESENT_PREDICATES = ("present?", "persisted?", "signed_in?", "logged_in?", "authenticated?")
+DENIAL = re.compile(
+    r"^(?:return\s+)?(?:redirect_to|redirect_back|head|render|raise|fail|deny\w*|forbid\w*|access_denied\w*|"
+    r"not_authori[sz]ed\w*|unauthori[sz]ed\w*|render_(?:forbidden|unauthorized|40[13])\w*|user_not_authorized)\b"
+)
+PUNDIT_AUTHORIZE = re.compile(r"(?<![\w.:@])authorize(?![\w!?])\s*(\(?)")
+CANCAN_AUTHORIZE = re.compile(r"(?<![\w.:@])authorize!\s*(\(?)")
+POLICY_SCOPE = re.compile(r"(?<![\w.:@])policy_scope\s*\(?\s*([A-Z][\w:]*|@?\w+)")
+CAN_RULE = re.compile(r"^(can|cannot)\b")
+LAMBDA_BODY = re.compile(r"(?:->\s*(?:\(\s*(\w*)\s*\))?|lambda|proc)\s*(?:\{|do)\s*(?:\|\s*(\w+)\s*\|)?\s*(.*?)\s*(?:\}|end)$", re.DOTALL)
+
+
+def _mask(text: str) -> tuple[str, str]:
+    """(code, masked): comments blanked in both, and string contents also blanked in masked."""
+    code, masked, last = [], [], 0
+    for match in RUBY_TOKEN.finditer(text):
+        token = match.group(0)
+        code.append(text[last : match.start()])
+        masked.append(text[last : match.start()])
+        if token[0] in "'\"":
+            code.append(token)
+            masked.append(token[0] + " " * (len(token) - 2) + token[-1] if len(token) > 1 else token)
+        else:
+            blank = re.sub(r"[^\n]", " ", token)
+            code.append(blank)
+            masked.append(blank)
+        last = match.end()
+    code.append(text[last:])
+    masked.append(text[last:])
+    return "".join(code), "".join(masked)
+
+
+@dataclass
+class _Line:
+    """A logical line: physical lines joined while brackets are open or the line continues."""
+
+    code: str
+    masked: str
+    start: int
+    end: int
+
+
+@dataclass
+class _Block:
+    """A do/end, def, class, or conditional block and the lines and blocks inside it."""
+
+    header: _Line | None
+    body: list = field(default_factory=list)
+    end: int = 0
+
+
+def _trimmed(code: str, masked: str, start: int) -> _Line | None:
+    """A line with surrounding whitespace removed from code and mask alike."""
+    left = len(masked) - len(masked.lstrip())
+    right = len(masked.rstrip())
+    if left >= right:
+        return None
+    return _Line(code[left:right], masked[left:right], start + left, start + right)
+
+
+def _logical_lines(code: str, masked: str) -> list[_Line]:
+    """Logical lines of masked Ruby source."""
+    physical, offset = [], 0
+    for line in masked.split("\n"):
+        physical.append((offset, offset + len(line)))
+        offset += len(line) + 1
+    lines, i = [], 0
+    while i < len(physical):
+        start, end = physical[i]
+        depth, joined = 0, 0
+        while True:
+            segment = masked[physical[i][0] : physical[i][1]]
+            depth = max(depth + sum(segment.count(c) for c in "([{") - sum(segment.count(c) for c in ")]}"), 0)
+            end = physical[i][1]
+            stripped = segment.strip()
+            following = masked[physical[i + 1][0] : physical[i + 1][1]].lstrip() if i + 1 < len(physical) else ""
+            continued = (
+                depth > 0
+                or stripped.endswith((",", "\\", "||", "&&", "=>"))
+                or re.search(r"(?:\band|\bor|[^=!<>]=)$", stripped[-5:]) is not None
+                or (following.startswith(".") and not following.startswith(".."))
+                or following.startswith("&.")
+            )
+            if not continued or i + 1 >= len(physical) or joined >= MAX_JOINED_LINES:
+                break
+            i += 1
+            joined += 1
+        line = _trimmed(code[start:end], masked[start:end], start)
+        if line:
+            lines.append(line)
+        i += 1
+    return lines
+
+
+def parse_ruby(text: str) -> _Block:
+    """Block tree of a Ruby source file."""
+    code, masked = _mask(text)
+    root = _Block(None, end=len(text))
+    stack, overflow = [root], 0
+    for line in _logical_lines(code, masked):
+        head = line.masked
+        if CLOSER.match(head):
+            if overflow:
+                overflow -= 1
+            elif len(stack) > 1:
+                stack.pop().end = line.end
+            continue
+        if OPENER.search(head) and not ENDLESS_DEF.match(head) and not ONE_LINE_END.search(head):
+            if len(stack) > MAX_NESTING:
+                # Blocks nested deeper than any real source keep their lines in the enclosing block
+                overflow += 1
+                stack[-1].body.append(line)
+                continue
+            block = _Block(line, end=len(text))
+            stack[-1].body.append(block)
+            stack.append(block)
+        else:
+            stack[-1].body.append(line)
+    return root
+
+
+def _short(code: str) -> str:
+    """Source text of an expression on one line, shortened for descriptions."""
+    text = " ".join(code.split())
+    return text if len(text) <= MAX_SOURCE else text[: MAX_SOURCE - 3] + "..."
+
+
+def _close(masked: str, open_at: int) -> int:
+    """Offset just past the bracket closing the one at open_at."""
+    depth = 0
+    for i in range(open_at, len(masked)):
+        if masked[i] in "([{":
+            depth += 1
+        elif masked[i] in ")]}":
+            depth -= 1
+            if depth == 0:
+                return i + 1
+    return len(masked)
+
+
+def _split(code: str, masked: str, start: int, end: int) -> list[tuple[str, str]]:
+    """Top-level comma-separated parts of code[start:end], with their masks."""
+    parts, depth, begin = [], 0, start
+    for i in range(start, end):
+        c = masked[i]
+        if c in "([{":
+            depth += 1
+        elif c in ")]}":
+            depth -= 1
+        elif c == "," and depth == 0:
+            parts.append((code[begin:i].strip(), masked[begin:i].strip()))
+            begin = i + 1
+    parts.append((code[begin:end].strip(), masked[begin:end].strip()))
+    return [p for p in parts if p[0]]
+
+
+def _modifier(masked: str, start: int = 0) -> re.Match | None:
+    """Trailing if/unless modifier at the top level of a statement."""
+    depth, last = 0, start
+    for match in MODIFIER.finditer(masked, start):
+        segment = masked[last : match.start()]
+        depth += sum(segment.count(c) for c in "([{") - sum(segment.count(c) for c in ")]}")
+        last = match.start()
+        if depth == 0:
+            return match
+    return None
+
+
+def _call(code: str, masked: str) -> tuple[str | None, list[tuple[str, str]], str | None]:
+    """Method name, top-level arguments, and trailing modifier condition of a statement."""
+    name = CALL_NAME.match(masked)
+    if not name:
+        return None, [], None
+    end, condition = len(masked), None
+    do = DO_SUFFIX.search(masked, name.end())
+    if do:
+        end = do.start()
+    modifier = _modifier(masked[:end], name.end())
+    if modifier:
+        condition = code[modifier.end() : end].strip()
+        condition = f"!({condition})" if modifier.group(1) == "unless" else condition
+        end = modifier.start()
+    start = name.end()
+    if start < end and masked[start] == "(":
+        close = _close(masked, start)
+        if masked[close:end].strip() == "":
+            return re.sub(r"\s+", "", name.group(0)), _split(code, masked, start + 1, close - 1), condition
+    if start < end and not masked[start].isspace():
+        return re.sub(r"\s+", "", name.group(0)), [], condition
+    return re.sub(r"\s+", "", name.group(0)), _split(code, masked, start, end), condition
+
+
+def _value(piece: str | None):
+    """Value of a symbol, string, or array literal: a string, a list of strings, or None."""
+    if piece is None:
+        return None
+    piece = piece.strip()
+    symbol = re.fullmatch(r":(\w+[?!]?)|:\"([^\"]*)\"|:'([^']*)'", piece)
+    if symbol:
+        return next(g for g in symbol.groups() if g is not None)
+    string = re.fullmatch(r"'([^'\\]*)'|\"((?:[^\"\\#]|#(?!\{))*)\"", piece)
+    if string:
+        return next(g for g in string.groups() if g is not None)
+    words = WORD_LIST.fullmatch(piece)
+    if words:
+        return words.group(1).split()
+    if piece.startswith("[") and piece.endswith("]"):
+        _, masked = _mask(piece)
+        values = [_value(p) for p, _ in _split(piece, masked, 1, len(piece) - 1)]
+        return [v for value in values if value for v in (value if isinstance(value, list) else [value])]
+    return None
+
+
+def _values(piece: str | None) -> list[str]:
+    """Values of a literal as a list."""
+    value = _value(piece)
+    return value if isinstance(value, list) else [value] if value else []
+
+
+def _options(args: list[tuple[str, str]]) -> tuple[list[str], dict[str, str], list[tuple[str, str]]]:
+    """(positional arguments, keyword options, "path" => "controller#action" pairs) of a call."""
+    positional, options, rockets = [], {}, []
+    for code, masked in args:
+        if code.startswith("{") and code.endswith("}"):
+            inner, _, inner_rockets = _options(_split(code, masked, 1, len(code) - 1))
+            options.update(inner)
+            rockets += inner_rockets
+            continue
+        key = ARGUMENT_KEY.match(code)
+        if key:
+            options[key.group(1) or key.group(3)] = (key.group(2) if key.group(1) else key.group(4)).strip()
+            continue
+        rocket = ROCKET.match(code)
+        if rocket:
+            rockets.append((rocket.group(2), rocket.group(3).strip()))
+            continue
+        positional.append(code)
+    return positional, options, rockets
+
+
+def _camelize(name: str) -> str:
+    """Ruby constant of an underscored path: admin/order_items -> Admin::OrderItems."""
+    return "::".join("".join(w.capitalize() for w in part.split("_")) for part in name.strip("/").split("/") if part)
+
+
+def _underscore(name: str) -> str:
+    """Underscored path of a Ruby constant: Admin::OrderItems -> admin/order_items."""
+    return "/".join(re.sub(r"(?<=[a-z0-9])([A-Z])", r"_\1", part).lower() for part in name.split("::"))
+
+
+def _singular(name: str) -> str:
+    """Singular of an English plural, as Rails' inflector does for regular nouns."""
+    if name.endswith("ies"):
+        return name[:-3] + "y"
+    if re.search(r"(?:ss|x|ch|sh|ses)es$", name) or name.endswith(("sses", "xes", "ches", "shes")):
+        return name[:-2]
+    return name[:-1] if name.endswith("s") and not name.endswith("ss") else name
+
+
+def _plural(name: str) -> str:
+    """Plural of a singular English noun."""
+    if re.search(r"[^aeiou]y$", name):
+        return name[:-1] + "ies"
+    return name + "es" if name.endswith(("s", "x", "ch", "sh")) else name + "s"
+
+
+def _model(record: str) -> tuple[str | None, str]:
+    """(model class, namespace prefix) named by a Pundit or CanCanCan record argument."""
+    record = record.strip()
+    namespace = ""
+    if record.startswith("[") and record.endswith("]"):
+        _, masked = _mask(record)
+        parts = [p for p, _ in _split(record, masked, 1, len(record) - 1)]
+        namespace = "".join(_camelize(v) + "::" for p in parts[:-1] for v in _values(p))
+        record = parts[-1] if parts else ""
+    symbol = _value(record)
+    if isinstance(symbol, str):
+        return _camelize(symbol), namespace
+    constant = re.match(r"([A-Z][\w:]*)", record)
+    if constant:
+        return constant.group(1), namespace
+    variable = re.match(r"@?(\w+)", record)
+    if not variable:
+        return None, namespace
+    name = variable.group(1)
+    return ("User" if name == "current_user" else _camelize(_singular(name) if name.endswith("s") else name)), namespace
+
+
+@dataclass
+class _Method:
+    """A method and its body."""
+
+    name: str
+    items: list
+    start: int
+    end: int
+    public: bool = True
+    params: list[str] = field(default_factory=list)
+
+
+@dataclass
+class _Class:
+    """A Ruby class with its class-level statements and methods."""
+
+    name: str  # Fully qualified, as in Admin::OrdersController
+    parent: str | None
+    namespace: str
+    file_path: str
+    text: str
+    statements: list[_Line] = field(default_factory=list)
+    methods: dict[str, _Method] = field(default_factory=dict)
+    aliases: dict[str, str] = field(default_factory=dict)
+    includes: list[str] = field(default_factory=list)
+
+
+def _class_body(cls: _Class, items: list, out: dict[str, _Class]) -> None:
+    """Fill a class from its body, collecting nested classes into out."""
+    public = True
+    for item in items:
+        if isinstance(item, _Block):
+            header = item.header
+            definition = DEF_HEADER.match(header.code)
+            if definition and not definition.group(2):
+                params = re.findall(r"(\w+)", definition.group(4) or definition.group(5) or "")
+                method_public = public if not definition.group(1) else definition.group(1) == "public"
+                cls.methods[definition.group(3)] = _Method(definition.group(3), item.body, header.start, item.end, method_public, params)
+            elif CLASS_HEADER.match(header.code):
+                _collect_classes([item], cls.file_path, cls.text, cls.name, out)
+            continue
+        if VISIBILITY.match(item.masked):
+            public = item.masked == "public"
+            continue
+        one_line = ONE_LINE_DEF.match(item.code)
+        if one_line:
+            body_start = one_line.start(3)
+            body = _Line(one_line.group(3), item.masked[body_start : body_start + len(one_line.group(3))], item.start + body_start, item.end)
+            method_public = public if not one_line.group(1) else one_line.group(1) == "public"
+            cls.methods[one_line.group(2)] = _Method(one_line.group(2), [body], item.start, item.end, method_public)
+            continue
+        alias = ALIAS.match(item.code)
+        if alias:
+            new, old = (alias.group(1), alias.group(2)) if alias.group(1) else (alias.group(3), alias.group(4))
+            cls.aliases[new] = old
+            continue
+        private = re.match(r"(private|protected)\s+((?::\w+[?!]?\s*,?\s*)+)$", item.code)
+        if private:
+            for name in re.findall(r":(\w+[?!]?)", private.group(2)):
+                if name in cls.methods:
+                    cls.methods[name].public = False
+            continue
+        include = re.match(r"include\s+([A-Z][\w:]*)", item.code)
+        if include:
+            cls.includes.append(include.group(1))
+        cls.statements.append(item)
+
+
+def _collect_classes(items: list, file_path: str, text: str, namespace: str, out: dict[str, _Class]) -> None:
+    """Collect the classes declared in a list of items, under a module namespace."""
+    for item in items:
+        if not isinstance(item, _Block):
+            continue
+        header = CLASS_HEADER.match(item.header.code)
+        if not header:
+            continue
+        name = f"{namespace}::{header.group(2)}" if namespace else header.group(2)
+        if header.group(1) == "module":
+            _collect_classes(item.body, file_path, text, name, out)
+            continue
+        cls = out.setdefault(name, _Class(name, header.group(3), namespace, file_path, text))
+        cls.parent = cls.parent or header.group(3)
+        _class_body(cls, item.body, out)
+
+
+@dataclass
+class _Context:
+    """How a check expression names the acting user and where bare predicates are defined."""
+
+    users: tuple[str, ...]
+    cls: _Class | None = None
+
+
+@dataclass
+class RailsRoute:
+    """An endpoint routes.rb draws to a controller action."""
+
+    method: str
+    path: str
+    controller: str  # Underscored controller path, as in admin/orders
+    action: str
+    file_path: str
+    offset: int
+    guards: tuple = ()  # (rule, source) of enclosing authenticate blocks
+
+
+@dataclass
+class _RouteScope:
+    """Path prefix, controller namespace, and resource of the routes being drawn."""
+
+    path: str = ""
+    module: str = ""
+    controller: str | None = None
+    member: str | None = None
+    collection: str | None = None
+    guards: tuple = ()
+
+
+def _route_path(*parts: str | None) -> str:
+    """Route path with optional segments dropped and :param and *glob segments as {param}."""
+    path = _join(*parts)
+    previous = None
+    while previous != path:
+        previous, path = path, re.sub(r"\([^()]*\)", "", path)
+    path = re.sub(r"[:*](\w+)", r"{\1}", path)
+    return re.sub(r"/+", "/", path).rstrip("/") or "/"
+
+
+class _RailsProject:
+    """Classes, policies, abilities, and routes of one Rails service."""
+
+    def __init__(self, files: dict[str, str]):
+        self.files = files
+        self.classes: dict[str, _Class] = {}
+        self.trees: dict[str, _Block] = {}
+        for file_path, text in files.items():
+            tree = parse_ruby(text)
+            self.trees[file_path] = tree
+            _collect_classes(tree.body, file_path, text, "", self.classes)
+        self.short_names: dict[str, list[_Class]] = {}
+        for cls in self.classes.values():
+            self.short_names.setdefault(cls.name.rsplit("::", 1)[-1], []).append(cls)
+        self.abilities = [c for c in self.classes.values() if "CanCan::Ability" in c.includes]
+        self._ability_rules: list | None = None
+        self._helper_calls = 0
+        self._method_checks: dict[tuple, _Rule | None] = {}
+        self._filter_rules: dict[tuple, tuple[_Rule, str] | None] = {}
+
+    def lookup(self, name: str | None, namespace: str = "") -> _Class | None:
+        """Class a constant refers to from within a namespace."""
+        if not name:
+            return None
+        name = name.removeprefix("::")
+        parts = namespace.split("::") if namespace else []
+        for i in range(len(parts), -1, -1):
+            candidate = "::".join(parts[:i] + [name])
+            if candidate in self.classes:
+                return self.classes[candidate]
+        candidates = self.short_names.get(name.rsplit("::", 1)[-1], [])
+        return candidates[0] if len(candidates) == 1 else None
+
+    def ancestors(self, cls: _Class) -> list[_Class]:
+        """A class and its superclasses in the repository, nearest first."""
+        chain, seen = [], set()
+        while cls is not None and cls.name not in seen and len(chain) < MAX_DEPTH:
+            chain.append(cls)
+            seen.add(cls.name)
+            cls = self.lookup(cls.parent, cls.namespace)
+        return chain
+
+    def method(self, cls: _Class | None, name: str) -> tuple[_Class, _Method] | None:
+        """Method a class responds to, following aliases and superclasses."""
+        if cls is None:
+            return None
+        for owner in self.ancestors(cls):
+            for _ in range(MAX_DEPTH):
+                if name not in owner.aliases:
+                    break
+                name = owner.aliases[name]
+            if name in owner.methods:
+                return owner, owner.methods[name]
+        return None
+
+    def controller(self, path: str) -> _Class | None:
+        """Controller class of an underscored controller path."""
+        return self.classes.get(_camelize(path) + "Controller")
+
+    # Checks
+
+    def check(self, code: str, masked: str, context: _Context, depth: int = 0) -> _Rule:
+        """Requirement of a boolean Ruby expression over the acting user."""
+        code, masked = _unwrap(code, masked)
+        if depth > MAX_DEPTH or not code:
+            return _Rule(conditions=[f"passes {_short(code)}"])
+        for operators, combine in (((r"\|\|", r"\bor\b"), _any), ((r"&&", r"\band\b"), _all)):
+            parts = _split_operator(code, masked, operators)
+            if len(parts) > 1:
+                return combine([self.check(c, m, context, depth + 1) for c, m in parts])
+        if masked.startswith("!") and not masked.startswith("!="):
+            return self.negate(code[1:], masked[1:], context, depth + 1)
+        if re.match(r"not\s", masked):
+            return self.negate(code[4:], masked[4:], context, depth + 1)
+        if code == "true":
+            return _Rule(decision=ANONYMOUS)
+        if code in ("false", "nil"):
+            return _Rule(decision=DENIED)
+        user = _user_check(code, context)
+        if user is not None:
+            return user
+        predicate = re.fullmatch(r"(\w+[?!]?)(?:\(\s*\))?", code)
+        if predicate:
+            signed_in = SIGNED_IN.fullmatch(predicate.group(1))
+            if signed_in:
+                return _scope_rule(signed_in.group(1))
+            if predicate.group(1) in ("signed_in?", "logged_in?", "authenticated?", "current_user?"):
+                return _Rule(decision=AUTHENTICATED)
+            resolved = self.method(context.cls, predicate.group(1))
+            if resolved:
+                return self.method_check(resolved[0], resolved[1], context, depth + 1)
+        return _Rule(conditions=[f"passes {_short(code)}"])
+
+    def negate(self, code: str, masked: str, context: _Context, depth: int = 0) -> _Rule:
+        """Requirement of a negated expression."""
+        code, masked = _unwrap(code, masked)
+        if masked.startswith("!") and not masked.startswith("!="):
+            return self.check(code[1:], masked[1:], context, depth + 1)
+        users = "|".join(re.escape(u) for u in context.users)
+        guest = re.fullmatch(rf"(?:{users})(?:\s*(?:\.|&\.)\s*(\w+[?!]?))?", code) if users else None
+        if guest and guest.group(1) in GUEST_PREDICATES:
+            return _Rule(decision=AUTHENTICATED)
+        if guest and (guest.group(1) is None or guest.group(1) in PRESENT_PREDICATES):
+            return _Rule(decision=ANONYMOUS, conditions=["caller is not signed in"])
+        return _Rule(conditions=[f"passes not {_short(code)}"])
+
+    def method_check(self, cls: _Class, method: _Method, context: _Context, depth: int) -> _Rule:
+        """Requirement of a predicate method's body.
+
+        Lines returning true are alternatives, lines returning false are
+        requirements on the rest, and the last expression decides last.
+        Results are cached, and a method reached again while it is being
+        read stays a named condition.
+        """
+        label = f"passes {cls.name}#{method.name}"
+        key = (cls.name, method.name, context.users)
+        if key in self._method_checks:
+            return self._method_checks[key] or _Rule(conditions=[label])
+        if depth > MAX_DEPTH or any(isinstance(item, _Block) for item in method.items):
+            return _Rule(conditions=[label])
+        self._method_checks[key] = None
+        rule = self._method_body_check(cls, method, context, depth, label)
+        self._method_checks[key] = rule
+        return rule
+
+    def _method_body_check(self, cls: _Class, method: _Method, context: _Context, depth: int, label: str) -> _Rule:
+        """Requirement of the lines of a predicate method."""
+        context = replace(context, cls=context.cls or cls)
+        alternatives, required = [], []
+        for line in method.items:
+            returned = re.match(r"return\b\s*", line.masked)
+            code, masked = (line.code[returned.end() :], line.masked[returned.end() :]) if returned else (line.code, line.masked)
+            modifier = _modifier(masked)
+            if modifier:
+                value = code[: modifier.start()].strip()
+                condition = self.check(code[modifier.end() :], masked[modifier.end() :], context, depth + 1)
+                if modifier.group(1) == "unless":
+                    condition = self.negate(code[modifier.end() :], masked[modifier.end() :], context, depth + 1)
+                if value == "true":
+                    alternatives.append(condition)
+                elif value in ("false", "nil", ""):
+                    required.append(self.negate(code[modifier.end() :], masked[modifier.end() :], context, depth + 1) if modifier.group(1) == "if" else self.check(code[modifier.end() :], masked[modifier.end() :], context, depth + 1))
+                continue
+            if returned or line is method.items[-1]:
+                required.append(self.check(code, masked, context, depth + 1))
+                break
+        options = alternatives + ([_all(required)] if required else [])
+        if not options:
+            return _Rule(conditions=[label])
+        return options[0] if len(options) == 1 else _any(options)
+
+    # Pundit
+
+    def pundit(self, args: list[tuple[str, str]], action: str, context: _Context) -> tuple[_Rule, str] | None:
+        """Requirement and source of a Pundit authorize call in an action."""
+        positional, options, _ = _options(args)
+        if not positional:
+            return None
+        model, namespace = _model(positional[0])
+        policy_name = options.get("policy_class") or (f"{namespace}{model}Policy" if model else None)
+        if not policy_name:
+            return None
+        query = _value(positional[1]) if len(positional) > 1 else f"{action}?"
+        if not isinstance(query, str):
+            return None
+        query = query if query.endswith("?") else query + "?"
+        source = f"Pundit {policy_name}#{query}"
+        policy = self.lookup(policy_name, context.cls.namespace if context.cls else "")
+        if policy is None:
+            return _Rule(conditions=[f"passes {policy_name}#{query}, which no policy class in the repository defines"]), source
+        resolved = self.method(policy, query)
+        if resolved is None:
+            return _Rule(conditions=[f"passes {policy_name}#{query}, which {policy_name} does not define"]), source
+        return self.method_check(resolved[0], resolved[1], _Context(("user", "@user", "current_user"), policy), 0), source
+
+    # CanCanCan
+
+    def ability_rules(self) -> list[tuple[bool, list[str], list[str], _Rule, str]]:
+        """(can, actions, subjects, requirement, source) of every can and cannot rule of the Ability classes."""
+        if self._ability_rules is None:
+            self._ability_rules, aliases = [], {}
+            for ability in self.abilities:
+                initialize = ability.methods.get("initialize")
+                if initialize:
+                    users = tuple(initialize.params[:1]) or ("user",)
+                    self._ability_items(ability, initialize.items, [], _Context(users, ability), aliases, 0)
+            self._aliases = aliases
+        return self._ability_rules
+
+    def _ability_items(self, ability: _Class, items: list, guards: list[_Rule], context: _Context, aliases: dict, depth: int) -> None:
+        """Collect the rules of an Ability method body under the checks guarding them."""
+        if depth > MAX_DEPTH:
+            return
+        guards = list(guards)
+        for item in items:
+            if isinstance(item, _Block):
+                head = item.header
+                if CAN_RULE.match(head.masked):
+                    body = " ".join(line.code for line in item.body if isinstance(line, _Line))
+                    self._can(head.code, head.masked, guards, context, aliases, f"passes {_short(body)}" if body else None)
+                    continue
+                branch = re.match(r"(if|unless|elsif)\s+", head.masked)
+                case = re.match(r"case\s+(.*)$", head.code)
+                if branch:
+                    self._ability_branches(ability, item, guards, context, aliases, depth)
+                elif case:
+                    self._ability_case(ability, item, case.group(1), guards, context, aliases, depth)
+                continue
+            code, masked = item.code, item.masked
+            early = re.match(r"return\s+(if|unless)\s+", masked)
+            if early:
+                rest = code[early.end() :], masked[early.end() :]
+                guards.append(self.negate(*rest, context) if early.group(1) == "if" else self.check(*rest, context))
+                continue
+            alias = re.match(r"alias_action\b", masked)
+            if alias:
+                _, args, _ = _call(code, masked)
+                positional, options, _ = _options(args)
+                target = _value(options.get("to"))
+                if isinstance(target, str):
+                    aliases.setdefault(target, []).extend(v for p in positional for v in _values(p))
+                continue
+            if CAN_RULE.match(masked):
+                self._can(code, masked, guards, context, aliases)
+                continue
+            name, _, condition = _call(code, masked)
+            if name in ability.methods and name != "initialize" and self._helper_calls < MAX_HELPER_CALLS:
+                self._helper_calls += 1
+                inner = guards + ([self.check(condition, _mask(condition)[1], context)] if condition else [])
+                self._ability_items(ability, ability.methods[name].items, inner, context, aliases, depth + 1)
+
+    def _ability_branches(self, ability: _Class, block: _Block, guards: list[_Rule], context: _Context, aliases: dict, depth: int) -> None:
+        """Collect the rules of an if/elsif/else block, each under its branch's check."""
+        head = re.match(r"(if|unless)\s+", block.header.masked)
+        condition = block.header.code[head.end() :], block.header.masked[head.end() :]
+        branch = self.check(*condition, context) if head.group(1) == "if" else self.negate(*condition, context)
+        taken = [_short(condition[0])]
+        segment: list = []
+        for item in block.body + [None]:
+            marker = re.match(r"(elsif|else)\b\s*", item.masked) if isinstance(item, _Line) else None
+            if item is not None and not marker:
+                segment.append(item)
+                continue
+            self._ability_items(ability, segment, guards + [branch], context, aliases, depth + 1)
+            segment = []
+            if marker and marker.group(1) == "elsif":
+                rest = item.code[marker.end() :], item.masked[marker.end() :]
+                branch = self.check(*rest, context)
+                taken.append(_short(rest[0]))
+            elif marker:
+                branch = _Rule(conditions=[f"passes none of {' | '.join(taken)}"])
+
+    def _ability_case(self, ability: _Class, block: _Block, subject: str, guards: list[_Rule], context: _Context, aliases: dict, depth: int) -> None:
+        """Collect the rules of a case block over the user's role, each under its when clause."""
+        role_case = re.search(r"\brole\b", subject) is not None
+        segment, branch = [], None
+        for item in block.body + [None]:
+            marker = re.match(r"(when|else)\b\s*(.*)$", item.code, re.DOTALL) if isinstance(item, _Line) else None
+            if item is not None and not marker:
+                segment.append(item)
+                continue
+            if branch is not None:
+                self._ability_items(ability, segment, guards + [branch], context, aliases, depth + 1)
+            segment = []
+            if marker and marker.group(1) == "when":
+                values = [v for p in marker.group(2).split(",") for v in _values(p.strip())]
+                branch = _Rule(roles=values) if role_case and values else _Rule(conditions=[f"{_short(subject)} is {_short(marker.group(2))}"])
+            elif marker:
+                branch = _Rule(conditions=[f"{_short(subject)} matches no when clause"])
+
+    def _can(self, code: str, masked: str, guards: list[_Rule], context: _Context, aliases: dict, block: str | None = None) -> None:
+        """Record one can or cannot rule."""
+        name, args, condition = _call(code, masked)
+        positional, options, _ = _options(args)
+        if len(positional) < 2:
+            return
+        actions = _values(positional[0])
+        subjects = []
+        for piece in positional[1:2]:
+            subjects = _values(piece) or [m for m in re.findall(r"[A-Z][\w:]*", piece)]
+        requirements = list(guards)
+        if condition:
+            requirements.append(self.check(condition, _mask(condition)[1], context))
+        hash_conditions = [f"{k}: {v}" for k, v in options.items()] + positional[2:]
+        if hash_conditions:
+            requirements.append(_Rule(conditions=[f"{' or '.join(subjects)} matches {_short(', '.join(hash_conditions))}"]))
+        if block:
+            requirements.append(_Rule(conditions=[block]))
+        rule = _all(requirements) if requirements else _Rule(decision=ANONYMOUS)
+        self._ability_rules.append((name == "can", actions, subjects, rule, _short(code)))
+
+    def cancan(self, action: str, model: str | None) -> tuple[_Rule, str]:
+        """Requirement and source of a CanCanCan check of an action on a model."""
+        source = f"CanCanCan Ability :{action} on {model or 'the resource'}"
+        if not self.abilities:
+            return _Rule(conditions=[f"passes CanCanCan :{action} on {model}, which no Ability class in the repository defines"]), source
+        rules = self.ability_rules()
+        wanted = {action, CANCAN_ALIASES.get(action, action), "manage"}
+        wanted |= {alias for alias, actions in self._aliases.items() if action in actions}
+        matching = [r for r in rules if wanted & set(r[1]) and ({model, "all"} & set(r[2]) or (model and _underscore(model) in r[2]))]
+        allowed = [rule for can, _, _, rule, _ in matching if can]
+        if not allowed:
+            return _Rule(decision=DENIED), source
+        granted = _any(allowed)
+        refused = [f"refused by {text}" for can, _, _, _, text in matching if not can]
+        return _all([granted, _Rule(conditions=refused)]) if refused else granted, source
+
+    # Controllers
+
+    def guard(self, items: list, controller: _Class, action: str, context: _Context, depth: int = 0) -> tuple[list[_Rule], list[str]]:
+        """Checks a filter or action body performs, and their sources."""
+        rules, sources = [], []
+        for item in items:
+            if isinstance(item, _Block):
+                header = item.header
+                branch = re.match(r"(if|unless)\s+", header.masked)
+                first = next((line for line in item.body if isinstance(line, _Line)), None)
+                if branch and first is not None and DENIAL.match(first.masked):
+                    rest = header.code[branch.end() :], header.masked[branch.end() :]
+                    rules.append(self.negate(*rest, context) if branch.group(1) == "if" else self.check(*rest, context))
+                    sources.append(_short(f"{first.code} {header.code}"))
+                    continue
+                inner_rules, inner_sources = self.guard(item.body, controller, action, context, depth + 1)
+                rules += inner_rules
+                sources += inner_sources
+                continue
+            found = self._line_checks(item, controller, action, context, depth)
+            for rule, source in found:
+                rules.append(rule)
+                sources.append(source)
+        return rules, sources
+
+    def _line_checks(self, line: _Line, controller: _Class, action: str, context: _Context, depth: int) -> list[tuple[_Rule, str]]:
+        """Checks one statement of a filter or action performs."""
+        code, masked = line.code, line.masked
+        found = []
+        for match in CANCAN_AUTHORIZE.finditer(masked):
+            args = _call_arguments(code, masked, match)
+            positional, _, _ = _options(args)
+            if positional:
+                verb = _value(positional[0])
+                model = _model(positional[1])[0] if len(positional) > 1 else None
+                if isinstance(verb, str):
+                    found.append(self.cancan(verb, model))
+        for match in PUNDIT_AUTHORIZE.finditer(masked):
+            result = self.pundit(_call_arguments(code, masked, match), action, context)
+            if result:
+                found.append(result)
+        scope = POLICY_SCOPE.search(masked)
+        if scope:
+            model, _ = _model(code[scope.start(1) : scope.end(1)])
+            found.append((_Rule(conditions=[f"records limited to {model}Policy::Scope"]), f"Pundit policy_scope({model})"))
+        if found:
+            return found
+        modifier = _modifier(masked)
+        if modifier and DENIAL.match(masked):
+            rest = code[modifier.end() :], masked[modifier.end() :]
+            rule = self.negate(*rest, context) if modifier.group(1) == "if" else self.check(*rest, context)
+            return [(rule, _short(code))]
+        name, _, condition = _call(code, masked)
+        if name and condition is None and depth < MAX_DEPTH:
+            rule = self.filter_rule(name, controller, action, depth + 1)
+            if rule:
+                return [rule]
+        return []
+
+    def filter_rule(self, name: str, controller: _Class, action: str, depth: int = 0) -> tuple[_Rule, str] | None:
+        """Requirement and source of a named before_action filter, or None if it checks nothing.
+
+        Results are cached, and a filter reached again while it is being
+        read checks nothing more.
+        """
+        key = (name, controller.name, action)
+        if key not in self._filter_rules:
+            self._filter_rules[key] = None
+            self._filter_rules[key] = self._filter_rule(name, controller, action, depth)
+        return self._filter_rules[key]
+
+    def _filter_rule(self, name: str, controller: _Class, action: str, depth: int) -> tuple[_Rule, str] | None:
+        """Requirement and source of a named filter, read from its method or its name."""
+        devise = DEVISE_AUTHENTICATE.fullmatch(name)
+        if devise and not self.method(controller, name):
+            return _scope_rule(devise.group(1)), f"before_action :{name}"
+        resolved = self.method(controller, name)
+        if resolved and depth <= MAX_DEPTH:
+            rules, sources = self.guard(resolved[1].items, controller, action, _Context(("current_user", "@current_user"), controller), depth + 1)
+            if rules:
+                return _all(rules), f"before_action :{name} ({'; '.join(sources)})"
+        if AUTHENTICATION_NAME.match(name):
+            return _Rule(decision=AUTHENTICATED), f"before_action :{name}"
+        if GUARD_NAME.search(name):
+            return _Rule(conditions=[f"passes before_action :{name}"]), f"before_action :{name}"
+        return None
+
+    def action_checks(self, controller: _Class, action: str) -> tuple[list[_Rule], list[str], str] | None:
+        """Checks guarding a controller action, their sources, and the skip_before_action lifting an inherited filter.
+
+        Returns:
+            None if the controller chain does not define the action publicly
+        """
+        resolved = self.method(controller, action)
+        if resolved is None or not resolved[1].public:
+            return None
+        chain = list(reversed(self.ancestors(controller)))
+        active: dict[str, tuple[_Line, str, list[str]]] = {}
+        skipped = ""
+        for cls in chain:
+            for statement in cls.statements:
+                name, args, _ = _call(statement.code, statement.masked)
+                if name not in FILTERS + SKIP_FILTERS + CANCAN_FILTERS:
+                    continue
+                positional, options, _ = _options(args)
+                only, excepted = _values(options.get("only")), _values(options.get("except"))
+                if (only and action not in only) or action in excepted:
+                    continue
+                if name in CANCAN_FILTERS:
+                    active[f"{name}:{len(active)}"] = (statement, name, positional + [options.get("class", "")])
+                    continue
+                names = [v for p in positional for v in _values(p)]
+                if not names and positional:
+                    names = [f"lambda:{statement.start}"]
+                for filter_name in names:
+                    if name in SKIP_FILTERS:
+                        skipped = skipped or (f"{name} :{filter_name}" if active.pop(filter_name, None) else "")
+                    else:
+                        active[filter_name] = (statement, name, positional)
+
+        rules, sources = [], []
+        context = _Context(("current_user", "@current_user"), controller)
+        for filter_name, (statement, kind, positional) in active.items():
+            if kind in CANCAN_FILTERS:
+                model = _value(positional[0]) if positional and positional[0] else None
+                constant = re.match(r"[A-Z][\w:]*", positional[-1]) if positional else None
+                subject = constant.group(0) if constant else _camelize(_singular(model or _underscore(controller.name.removesuffix("Controller")).rsplit("/", 1)[-1]))
+                rule, source = self.cancan(action, subject)
+                rules.append(rule)
+                sources.append(f"{kind} ({source})")
+                continue
+            if filter_name.startswith("lambda:"):
+                body = LAMBDA_BODY.search(statement.code)
+                if body:
+                    code = body.group(3)
+                    lines = [_Line(code, _mask(code)[1], statement.start, statement.end)]
+                    inner_rules, inner_sources = self.guard(lines, controller, action, context)
+                    if inner_rules:
+                        rules.append(_all(inner_rules))
+                        sources.append(f"before_action {{ {'; '.join(inner_sources)} }}")
+                continue
+            result = self.filter_rule(filter_name, controller, action)
+            if result:
+                rules.append(result[0])
+                sources.append(result[1])
+        inline_rules, inline_sources = self.guard(resolved[1].items, controller, action, context)
+        return rules + inline_rules, sources + inline_sources, skipped
+
+    # Routes
+
+    def routes(self) -> list[RailsRoute]:
+        """Routes drawn by every config/routes.rb of the service."""
+        routes = []
+        for file_path in sorted(self.files):
+            if file_path == "config/routes.rb" or file_path.endswith("/config/routes.rb"):
+                self._draw(self.trees[file_path].body, _RouteScope(), file_path, routes, 0)
+        return routes
+
+    def _draw(self, items: list, scope: _RouteScope, file_path: str, routes: list[RailsRoute], depth: int) -> None:
+        """Collect the routes a list of routes.rb statements draws."""
+        if depth > MAX_DEPTH:
+            return
+        for item in items:
+            line = item.header if isinstance(item, _Block) else item
+            body = item.body if isinstance(item, _Block) else None
+            name, args, _ = _call(line.code, line.masked)
+            if name is None or name in ROUTING_IGNORED:
+                continue
+            positional, options, rockets = _options(args)
+            if name in ("resources", "resource"):
+                for resource in [v for p in positional for v in _values(p)]:
+                    child = self._resources(resource, name == "resources", options, scope, file_path, line.start, routes)
+                    if body is not None:
+                        self._draw(body, child, file_path, routes, depth + 1)
+            elif name == "namespace" and positional:
+                namespace = _value(positional[0])
+                if isinstance(namespace, str) and body is not None:
+                    path = _value(options.get("path")) if "path" in options else namespace
+                    module = _value(options.get("module")) or namespace
+                    child = replace(scope, path=_join(scope.path, path), module=_join(scope.module, module).strip("/"), member=None, collection=None)
+                    self._draw(body, child, file_path, routes, depth + 1)
+            elif name == "scope" and body is not None:
+                path = _value(positional[0]) if positional else _value(options.get("path"))
+                module = _value(options.get("module"))
+                controller = _value(options.get("controller"))
+                child = replace(
+                    scope,
+                    path=_join(scope.path, path) if isinstance(path, str) else scope.path,
+                    module=_join(scope.module, module).strip("/") if isinstance(module, str) else scope.module,
+                    controller=_join(scope.module, controller).strip("/") if isinstance(controller, str) else scope.controller,
+                )
+                self._draw(body, child, file_path, routes, depth + 1)
+            elif name == "controller" and positional and body is not None:
+                controller = _value(positional[0])
+                if isinstance(controller, str):
+                    self._draw(body, replace(scope, controller=_join(scope.module, controller).strip("/")), file_path, routes, depth + 1)
+            elif name in ("member", "collection") and body is not None:
+                path = scope.member if name == "member" else scope.collection
+                if path is not None:
+                    self._draw(body, replace(scope, path=path), file_path, routes, depth + 1)
+            elif name in ("authenticate", "authenticated") and body is not None:
+                guard = _scope_rule(_value(positional[0]) if positional else "user")
+                constraint = LAMBDA_BODY.search(positional[1]) if len(positional) > 1 else None
+                if constraint:
+                    expression = constraint.group(3)
+                    user = constraint.group(1) or constraint.group(2) or "user"
+                    guard = _all([guard, self.check(expression, _mask(expression)[1], _Context((user,)))])
+                source = f"routes.rb {_short(DO_SUFFIX.sub('', line.code))}"
+                self._draw(body, replace(scope, guards=scope.guards + ((guard, source),)), file_path, routes, depth + 1)
+            elif name in HTTP_VERBS + ("match", "root"):
+                self._verb(name, positional, options, rockets, scope, file_path, line.start, routes)
+            elif name == "draw" and positional:
+                drawn = _value(positional[0])
+                config = file_path.rsplit("/routes.rb", 1)[0]
+                target = f"{config}/routes/{drawn}.rb" if isinstance(drawn, str) else None
+                if target in self.trees:
+                    self._draw(self.trees[target].body, scope, target, routes, depth + 1)
+            elif body is not None:
+                # routes.draw, constraints, defaults, with_options, and conditionals draw their body as is
+                self._draw(body, scope, file_path, routes, depth + 1)
+
+    def _resources(
+        self, name: str, plural: bool, options: dict[str, str], scope: _RouteScope, file_path: str, offset: int, routes: list[RailsRoute]
+    ) -> _RouteScope:
+        """Draw the routes of a resources or resource call; returns the scope of its block."""
+        segment = _value(options.get("path")) if "path" in options else name
+        collection = _join(scope.path, segment if isinstance(segment, str) else name)
+        param = _value(options.get("param")) or "id"
+        member = _join(collection, f":{param}") if plural else collection
+        controller = _value(options.get("controller")) or (name if plural else _plural(name))
+        module = _value(options.get("module"))
+        module = _join(scope.module, module).strip("/") if isinstance(module, str) else scope.module
+        controller = _join(module, controller).strip("/")
+        only, excepted = _values(options.get("only")), _values(options.get("except"))
+        if options.get("only") in ("[]", "%i[]", "%w[]"):
+            only = ["__none__"]
+        for action, method, on_member, suffix in RESOURCES_ACTIONS if plural else RESOURCE_ACTIONS:
+            if (only and action not in only) or action in excepted:
+                continue
+            path = _join(member if on_member else collection, suffix)
+            routes.append(RailsRoute(method, _route_path(path), controller, action, file_path, offset, scope.guards))
+        nested = _join(collection, f":{_singular(name)}_{param}") if plural else collection
+        return replace(scope, path=nested, controller=controller, member=member, collection=collection)
+
+    def _verb(
+        self,
+        name: str,
+        positional: list[str],
+        options: dict[str, str],
+        rockets: list[tuple[str, str]],
+        scope: _RouteScope,
+        file_path: str,
+        offset: int,
+        routes: list[RailsRoute],
+    ) -> None:
+        """Draw a get/post/put/patch/delete, match, or root route."""
+        path = _value(positional[0]) if positional else None
+        target = _value(options.get("to"))
+        if rockets:
+            path, target = rockets[0][0], _value(rockets[0][1])
+        if name == "root":
+            path, target = "", target or path
+        if "to" in options and not isinstance(target, str):
+            return  # A redirect or a Rack application
+        controller = _value(options.get("controller"))
+        controller = _join(scope.module, controller).strip("/") if isinstance(controller, str) else scope.controller
+        action = _value(options.get("action"))
+        if isinstance(target, str) and "#" in target:
+            target_controller, action = target.split("#", 1)
+            controller = _join(scope.module, target_controller).strip("/")
+        elif isinstance(target, str):
+            action = target
+        if not isinstance(path, str):
+            return
+        if not action:
+            segments = [s for s in re.sub(r"\([^()]*\)", "", path).split("/") if s and not s.startswith((":", "*"))]
+            if not segments:
+                return
+            action = segments[-1]
+            if controller is None and len(segments) > 1:
+                controller = _join(scope.module, "/".join(segments[:-1])).strip("/")
+        if not controller:
+            return
+        on = _value(options.get("on"))
+        base = {"member": scope.member, "collection": scope.collection}.get(on) if isinstance(on, str) else None
+        methods = [name.upper()] if name in HTTP_VERBS else ["GET"]
+        if name == "match":
+            via = _values(options.get("via"))
+            methods = ["*"] if not via or "all" in via else [v.upper() for v in via]
+        for method in methods:
+            routes.append(RailsRoute(method, _route_path(base or scope.path, path), controller, action, file_path, offset, scope.guards))
+
+
+def _unwrap(code: str, masked: str) -> tuple[str, str]:
+    """An expression without surrounding whitespace and enclosing parentheses."""
+    code, masked = code.strip(), masked.strip()
+    while masked.startswith("(") and _close(masked, 0) == len(masked):
+        code, masked = code[1:-1].strip(), masked[1:-1].strip()
+    return code, masked
+
+
+def _split_operator(code: str, masked: str, operators: tuple[str, ...]) -> list[tuple[str, str]]:
+    """Operands of a top-level boolean operator."""
+    pattern = re.compile("|".join(operators))
+    parts, depth, begin, i = [], 0, 0, 0
+    while i < len(masked):
+        c = masked[i]
+        if c in "([{":
+            depth += 1
+        elif c in ")]}":
+            depth -= 1
+        elif depth == 0:
+            match = pattern.match(masked, i)
+            if match and (i == 0 or not masked[i - 1].isalnum() or match.group(0)[0] in "|&"):
+                parts.append((code[begin:i], masked[begin:i]))
+                begin = i = match.end()
+                continue
+        i += 1
+    parts.append((code[begin:], masked[begin:]))
+    return [(c.strip(), m.strip()) for c, m in parts if c.strip()]
+
+
+def _scope_rule(scope: str | list | None) -> _Rule:
+    """Requirement of a Devise scope: any signed-in user, or the role a separate scope stands for."""
+    if not isinstance(scope, str) or scope in USER_SCOPES:
+        return _Rule(decision=AUTHENTICATED)
+    return _Rule(roles=[scope])
+
+
+def _user_check(code: str, context: _Context) -> _Rule | None:
+    """Requirement of a check on the acting user itself, or None if the expression is something else."""
+    if not context.users:
+        return None
+    users = "|".join(re.escape(u) for u in context.users)
+    call = rf"(?:{users})\s*(?:\.|&\.)\s*"
+    if re.fullmatch(rf"(?:{users})", code):
+        return _Rule(decision=AUTHENTICATED)
+    predicate = re.fullmatch(rf"{call}(\w+[?!]?)", code)
+    if predicate:
+        name = predicate.group(1)
+        if name in PRESENT_PREDICATES:
+            return _Rule(decision=AUTHENTICATED)
+        if name in GUEST_PREDICATES:
+            return _Rule(decision=ANONYMOUS, conditions=["caller is not signed in"])
+        if name.endswith("?") and ROLE_PREDICATE.fullmatch(name[:-1]):
+            return _Rule(roles=[name[:-1]])
+        return None
+    role_method = re.fullmatch(rf"{call}({'|'.join(ROLE_METHODS)})\?\s*\(?(.*?)\)?", code, re.DOTALL)
+    if role_method:
+        roles = [v for p in role_method.group(2).split(",") for v in _values(p.strip())]
+        if roles:
+            return _Rule(roles=roles)
+    attribute = rf"{call}roles?(?:\s*\.\s*(?:to_s|to_sym|name|pluck\(:name\)|map\(&:name\)))?"
+    equal = re.fullmatch(rf"{attribute}\s*==\s*(.+)|(.+?)\s*==\s*{attribute}", code)
+    if equal:
+        roles = _values(equal.group(1) or equal.group(2))
+        if roles:
+            return _Rule(roles=roles)
+    member = re.fullmatch(rf"{attribute}\s*\.\s*(?:include\?|in\?)\s*\(?(.+?)\)?|(.+?)\s*\.\s*include\?\s*\(?\s*{attribute}\s*\)?", code)
+    if member:
+        roles = _values(member.group(1) or member.group(2))
+        if roles:
+            return _Rule(roles=roles)
+    return None
+
+
+def _call_arguments(code: str, masked: str, match: re.Match) -> list[tuple[str, str]]:
+    """Arguments of the authorize call a match found, up to its closing parenthesis or modifier."""
+    if match.group(1):
+        close = _close(masked, match.end() - 1)
+        return _split(code, masked, match.end(), close - 1)
+    end = len(masked)
+    modifier = _modifier(masked, match.end())
+    if modifier:
+        end = modifier.start()
+    do = DO_SUFFIX.search(masked, match.end())
+    if do:
+        end = min(end, do.start())
+    return _split(code, masked, match.end(), end)
+
+
+def _routes_by_action(routes: list[RailsRoute]) -> dict[tuple[str, str], list[RailsRoute]]:
+    """Routes grouped by (controller, action)."""
+    grouped: dict[tuple[str, str], list[RailsRoute]] = {}
+    for route in routes:
+        grouped.setdefault((route.controller, route.action), []).append(route)
+    return grouped
+
+
+def _finding(file_path: str, text: str, start: int, end: int, resource: str, method: str, access, label: str) -> ConfigFinding:
+    """Finding for one controller action endpoint."""
+    line_start = line_of(text, start)
+    line_end = min(line_of(text, end), line_start + MAX_SNIPPET_LINES - 1)
+    return ConfigFinding(
+        kind=RailsRouteKind.ACTION,
+        file_path=file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(text, line_start, line_end),
+        subject=access.subject,
+        resource=resource,
+        action=method,
+        conditions=access.conditions,
+        description=f"{label} secured by {access.source}",
+        disables_auth=access.subject == ANONYMOUS and not access.conditions,
+    )
+
+
+def is_rails_source(file_path: str) -> bool:
+    """Check whether a path is a Ruby source a Rails analyzer reads."""
+    return PurePosixPath(file_path).suffix in RAILS_SUFFIXES
+
+
+def extract_rails_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Rails controller authorization, mapped to routes.rb endpoints, from a service's files.
+
+    Args:
+        files: Relative path -> content; non-Ruby files are ignored
+
+    Returns:
+        One finding per routed action endpoint with checks, plus checked
+        public actions no route draws
+    """
+    sources = {
+        p: t
+        for p, t in sorted(files.items())
+        if is_rails_source(p) and (RAILS_MARKER.search(t) or RAILS_MARKER.search(p) or "/config/routes/" in f"/{p}")
+    }
+    if not sources:
+        return []
+    project = _RailsProject(sources)
+    routed = _routes_by_action(project.routes())
+    bases = {project.lookup(c.parent, c.namespace).name for c in project.classes.values() if project.lookup(c.parent, c.namespace)}
+
+    findings, seen = [], set()
+    for (controller_path, action), routes in routed.items():
+        controller = project.controller(controller_path)
+        label = f"Rails action {_camelize(controller_path)}Controller#{action}"
+        checks = project.action_checks(controller, action) if controller else None
+        rules, sources_, skipped = checks or ([], [], "")
+        for route in routes:
+            route_rules = [g[0] for g in route.guards] + rules
+            route_sources = [g[1] for g in route.guards] + sources_
+            if not route_rules and not skipped:
+                continue
+            if skipped and not route_rules:
+                route_rules, route_sources = [_Rule(decision=ANONYMOUS)], [skipped]
+            if checks:
+                owner, method = project.method(controller, action)
+                file_path, text, start, end = owner.file_path, owner.text, method.start, method.end
+            else:
+                file_path, text, start, end = route.file_path, project.files[route.file_path], route.offset, route.offset
+            access = _access(_all(route_rules), " and ".join(dict.fromkeys(route_sources)))
+            key = (file_path, start, route.path, route.method)
+            if key not in seen:
+                seen.add(key)
+                findings.append(_finding(file_path, text, start, end, route.path, route.method, access, label))
+
+    # Checked actions no route draws, such as ones routed from engines or unscanned code
+    for cls in project.classes.values():
+        if not cls.name.endswith("Controller") or cls.name in bases:
+            continue
+        controller_path = _underscore(cls.name.removesuffix("Controller"))
+        for action, method in cls.methods.items():
+            if (controller_path, action) in routed or not method.public:
+                continue
+            rules, sources_, _ = project.action_checks(cls, action) or ([], [], "")
+            if not rules:
+                continue
+            access = _access(_all(rules), " and ".join(dict.fromkeys(sources_)))
+            label = f"Rails action {cls.name}#{action} (not routed in routes.rb)"
+            findings.append(_finding(cls.file_path, cls.text, method.start, method.end, f"{controller_path}#{action}", "*", access, label))
+    return findings
@@ -0,0 +1,931 @@
+"""Extract rate-limit and quota configuration from application and gateway code.
+
+For partner APIs, how often a caller may do something is part of the
+authorization contract alongside who may do it. Limits are declared as
+middleware options (express-rate-limit, Flask-Limiter and SlowAPI, ASP.NET
+Core rate limiting, Bucket4j, Resilience4j, Laravel throttles, Rack::Attack,
+httprate), as Django REST framework throttle rates, or at the gateway (nginx
+limit_req zones, Kong rate-limiting plugins). These extractors resolve each
+into its limit and window, what callers are counted by (client IP, API key,
+user, consumer), the role it applies to when limits differ per role, and the
+routes it covers, without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, replace
+from pathlib import PurePosixPath
+
+import yaml
+
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import SourceLines, group_end
+
+RATE_LIMIT_SUFFIXES = (
+    ".js", ".ts", ".mjs", ".cjs", ".jsx", ".tsx", ".py", ".java", ".kt", ".cs", ".php", ".rb", ".go",
+    ".conf", ".yml", ".yaml", ".properties",
+)  # fmt: skip
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(r"rate.?limit|throttl|limit_req|Bandwidth|limiter|Limit::", re.IGNORECASE)
+
+# Lines following a decorator or attribute searched for the route it applies to
+DECORATED_ROUTE_LINES = 6
+
+# Statements spanning more lines than this are cut off
+MAX_BLOCK_LINES = 25
+
+
+class RateLimitKey:
+    """What a limit counts requests by."""
+
+    IP = "ip"
+    API_KEY = "api_key"
+    USER = "user"
+    CONSUMER = "consumer"  # Gateway consumer, usually one per partner
+    TENANT = "tenant"
+    GLOBAL = "global"  # One budget shared by every caller
+
+
+KEY_DESCRIPTIONS = {
+    RateLimitKey.IP: "client IP",
+    RateLimitKey.API_KEY: "API key",
+    RateLimitKey.USER: "user",
+    RateLimitKey.CONSUMER: "consumer",
+    RateLimitKey.TENANT: "tenant",
+    RateLimitKey.GLOBAL: "service, shared by all callers",
+}
+
+# Most specific first; matched against the code that derives the counting key
+KEY_SIGNATURES: list[tuple[str, re.Pattern]] = [
+    (RateLimitKey.API_KEY, re.compile(r"api[_-]?key|x-api-key|HTTP_X_API_KEY|credential|client[_-]?id", re.IGNORECASE)),
+    (RateLimitKey.CONSUMER, re.compile(r"\bconsumer", re.IGNORECASE)),
+    (RateLimitKey.TENANT, re.compile(r"tenant|org(?:anization)?[_-]?id|account[_-]?id", re.IGNORECASE)),
+    (
+        RateLimitKey.USER,
+        re.compile(
+            r"user[_-]?id|req\.user|request\.user|->user\(\)|current_user|User\.Identity|principal|"
+            r"auth\(\)->id|\$http_authorization|\buser\b",
+            re.IGNORECASE,
+        ),
+    ),
+    (
+        RateLimitKey.IP,
+        re.compile(
+            r"\bip\b|remote[_-]?addr|get_remote_address|RemoteIpAddress|ByIP|RealIP|x-forwarded-for|\.ip\(\)",
+            re.IGNORECASE,
+        ),
+    ),
+    (RateLimitKey.GLOBAL, re.compile(r"LimitAll|GlobalLimiter|\$server_name|\$host\b")),
+]
+
+UNIT_SECONDS = {
+    "ms": 0.001,
+    "s": 1,
+    "sec": 1,
+    "second": 1,
+    "m": 60,
+    "min": 60,
+    "minute": 60,
+    "h": 3600,
+    "hour": 3600,
+    "d": 86400,
+    "day": 86400,
+    "month": 2592000,
+    "year": 31536000,
+}
+
+# "100/hour", "5 per minute", "10 per 5 minutes", "1000/d"
+RATE = re.compile(
+    r"(\d+)\s*(?:/|per)\s*(\d+)?\s*(seconds?|secs?|minutes?|mins?|hours?|days?|months?|years?|s|m|h|d)\b",
+    re.IGNORECASE,
+)
+DURATIONS = [
+    # TimeSpan.FromMinutes(1), Duration.ofSeconds(30)
+    re.compile(r"(?:TimeSpan\.From|Duration\.of)(Second|Minute|Hour|Day)s?\(\s*(\d+)", re.IGNORECASE),
+    # Ruby 5.minutes
+    re.compile(r"(\d+)\.(second|minute|hour|day)s?\b"),
+    # Go 10*time.Second, time.Minute
+    re.compile(r"(?:(\d+)\s*\*\s*)?time\.(Second|Minute|Hour)\b"),
+    # Config values: 1s, 500ms, 10m
+    re.compile(r"\b(\d+)\s*(ms|s|m|h|d)\b"),
+]
+STRING = re.compile(r"""['"]([^'"\n]*)['"]""")
+NUMBER = re.compile(r"(?<![\w.])(\d+)(?![\w.])")
+
+# Role tests that select one of several limits
+ROLE_TEST = re.compile(
+    r"""(?i:role|tier|plan|scope|group|subscription)\w*[^'"\n;]{0,25}?['"]([A-Za-z][\w-]*)['"]|"""
+    r"""(?:hasRole|IsInRole|has_role|is_role)\(\s*['"]([\w-]+)['"]|\bis([A-Z][a-z]+)\(\)|"""
+    r"""\bcase\s+['"]([\w-]+)['"]|\bcase\s+(?:\w+\.)?([A-Z][A-Z_]+)\s*(?:->|:)"""
+)
+ELSE = re.compile(r"\belse\b")
+PYTHON_CONDITION = re.compile(r"^\s*if\b")
+COMMENT_LINE = re.compile(r"^\s*(?://|#|\*|/\*)")
+
+# Routes find_routes does not cover: Flask .route(), ASP.NET minimal APIs, Laravel Route::
+EXTRA_ROUTES = [
+    re.compile(r"""@\w+\.route\(\s*['"](/[^'"]*)['"](?:[^)\n]*methods\s*=\s*\[\s*['"](\w+))?"""),
+    re.compile(r"""\.Map(Get|Post|Put|Patch|Delete)\(\s*"(/?[^"]*)\""""),
+    re.compile(r"""Route::(get|post|put|patch|delete)\(\s*['"](/?[^'"]*)['"]"""),
+]
+
+
+@dataclass
+class RateLimit:
+    """One rate limit or quota, or an exemption from them."""
+
+    framework: str
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    limit: int | None  # Requests allowed per period; None for exemptions
+    period_seconds: int | None
+    key: str
+    name: str | None = None  # Limiter, policy, zone, or throttle name
+    subject: str | None = None  # Role the limit applies to when limits differ per role
+    scope: str | None = None  # Path pattern it covers; None when service-wide
+    method: str | None = None
+    exempt: bool = False
+
+    @property
+    def rate(self) -> str:
+        """Human-readable limit, e.g., "100 requests per 15 minutes per client IP"."""
+        if self.exempt:
+            return "exempt from rate limits"
+        per_key = KEY_DESCRIPTIONS.get(self.key, self.key)
+        return f"{self.limit} requests per {describe_period(self.period_seconds)} per {per_key}"
+
+
+def describe_period(seconds: int | None) -> str:
+    """Describe a window in its largest whole unit ("minute", "15 minutes")."""
+    if not seconds:
+        return "unknown period"
+    for unit, size in (("day", 86400), ("hour", 3600), ("minute", 60), ("second", 1)):
+        if seconds % size == 0:
+            count = seconds // size
+            return unit if count == 1 else f"{count} {unit}s"
+    return f"{seconds} seconds"
+
+
+def _unit_seconds(unit: str) -> float:
+    """Seconds in a unit name or abbreviation, singular or plural."""
+    unit = unit.lower()
+    if unit not in UNIT_SECONDS and unit.endswith("s"):
+        unit = unit[:-1]
+    return UNIT_SECONDS.get(unit, 0)
+
+
+def parse_rate(text: str) -> tuple[int, int] | None:
+    """Parse a rate string such as "100/hour" or "10 per 5 minutes" into (limit, seconds)."""
+    match = RATE.search(text)
+    if not match:
+        return None
+    seconds = int((int(match.group(2)) if match.group(2) else 1) * _unit_seconds(match.group(3)))
+    return int(match.group(1)), max(seconds, 1)
+
+
+def parse_duration(text: str) -> int | None:
+    """Seconds in the first duration expression found in code or config text."""
+    for index, pattern in enumerate(DURATIONS):
+        match = pattern.search(text)
+        if not match:
+            continue
+        if index == 0:
+            unit, amount = match.group(1), match.group(2)
+        elif index == 2:
+            amount, unit = match.group(1) or "1", match.group(2)
+        else:
+            amount, unit = match.group(1), match.group(2)
+        return max(int(int(amount) * _unit_seconds(unit)), 1)
+    return None
+
+
+def classify_key(text: str, default: str) -> str:
+    """What the code deriving a limit's counting key counts by."""
+    for key, pattern in KEY_SIGNATURES:
+        if pattern.search(text):
+            return key
+    return default
+
+
+def _role(match: re.Match) -> str:
+    """Role name captured by ROLE_TEST."""
+    return next(g for g in match.groups() if g)
+
+
+def _branches(text: str, tokens: list[tuple[int, int, object]]) -> list[tuple[str | None, object]]:
+    """Assign each limit token the role test that selects it, if any.
+
+    JavaScript, Java, and PHP put the test before the value ("role ===
+    'partner' ? 1000 : 100"); Python conditional expressions put it after
+    ("'1000/hour' if role == 'partner' else '100/hour'").
+    """
+    results = []
+    for i, (start, end, value) in enumerate(tokens):
+        previous_end = tokens[i - 1][1] if i else 0
+        next_start = tokens[i + 1][0] if i + 1 < len(tokens) else len(text)
+        after = text[end:next_start]
+        role = None
+        if PYTHON_CONDITION.match(after) and (match := ROLE_TEST.search(after)):
+            role = _role(match)
+        else:
+            before = text[previous_end:start]
+            matches = list(ROLE_TEST.finditer(before))
+            if matches and not ELSE.search(before, matches[-1].end()):
+                role = _role(matches[-1])
+        results.append((role, value))
+    return results
+
+
+def _routes(text: str) -> list[tuple[str | None, str]]:
+    """(method, path) routes in a snippet; method is None when the route has none."""
+    routes: list[tuple[str | None, str]] = list(EndpointMappingService.find_routes(text))
+    for index, pattern in enumerate(EXTRA_ROUTES):
+        for match in pattern.finditer(text):
+            if index == 0:
+                method, path = (match.group(2) or "GET").upper(), match.group(1)
+            else:
+                method, path = match.group(1).upper(), match.group(2)
+            path = path if path.startswith("/") else "/" + path
+            if (method, path) not in routes:
+                routes.append((method, path))
+    return sorted(routes, key=lambda r: text.find(r[1]))
+
+
+def _decorated_route(source: SourceLines, line: int) -> tuple[str | None, str | None]:
+    """Method and path of the first route registered just below a decorator or attribute."""
+    routes = _routes(source.span(line, line + DECORATED_ROUTE_LINES))
+    return routes[0] if routes else (None, None)
+
+
+def _limit(source: SourceLines, framework: str, first: int, last: int, **fields) -> RateLimit:
+    """Build a RateLimit citing lines first..last of a source."""
+    last = min(last, first + MAX_BLOCK_LINES - 1)
+    return RateLimit(
+        framework=framework,
+        file_path=source.file_path,
+        line_start=first,
+        line_end=last,
+        snippet=source.span(first, last),
+        **fields,
+    )
+
+
+def _span(source: SourceLines, start: int) -> tuple[int, int, str]:
+    """Lines and text of the bracket group opened on the line at offset start."""
+    end = group_end(source.text, start)
+    return source.line_of(start), source.line_of(end), source.text[start:end]
+
+
+# express-rate-limit, express-slow-down
+EXPRESS_DEFINITION = re.compile(
+    r"\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:await\s+)?(?:rateLimit|slowDown|RateLimit)\s*\("
+)
+EXPRESS_INLINE = re.compile(r"(?<![\w$])(?:rateLimit|slowDown)\s*\(\s*\{")
+EXPRESS_OPTION = re.compile(r"^\s*([A-Za-z_$][\w$]*)\s*:", re.MULTILINE)
+EXPRESS_MOUNT = re.compile(r"""\.(use|get|post|put|patch|delete|all)\s*\(\s*(?:['"`](/[^'"`]*)['"`]\s*,)?""")
+
+
+def _express_options(options: str) -> dict[str, str]:
+    """Top-level option values of an options object, by name."""
+    starts = [(m.group(1), m.end()) for m in EXPRESS_OPTION.finditer(options)]
+    values = {}
+    for i, (name, start) in enumerate(starts):
+        end = starts[i + 1][1] - len(starts[i + 1][0]) - 1 if i + 1 < len(starts) else len(options)
+        values.setdefault(name, options[start:end])
+    if not starts:
+        # Single-line objects: { windowMs: 60000, max: 5 }
+        for match in re.finditer(r"([A-Za-z_$][\w$]*)\s*:\s*([^,}]+)", options):
+            values.setdefault(match.group(1), match.group(2))
+    return values
+
+
+def _express_limits(source: SourceLines, start: int, name: str | None) -> list[RateLimit]:
+    """Limits defined by one rateLimit({...}) call."""
+    first, last, options = _span(source, start)
+    values = _express_options(options)
+    window = values.get("windowMs", "60000")
+    factors = [int(n) for n in re.findall(r"\d+", window.split("//")[0])] or [60000]
+    milliseconds = 1
+    for factor in factors:
+        milliseconds *= factor
+    period = max(milliseconds // 1000, 1)
+    key = classify_key(values.get("keyGenerator", ""), RateLimitKey.IP)
+    limit_text = values.get("max") or values.get("limit") or values.get("delayAfter") or "5"
+    tokens = [(m.start(), m.end(), int(m.group(1))) for m in NUMBER.finditer(limit_text)]
+    return [
+        _limit(source, "express-rate-limit", first, last, limit=limit, period_seconds=period, key=key, name=name, subject=role)
+        for role, limit in _branches(limit_text, tokens)
+    ]
+
+
+def _mounts(sources: list[SourceLines], name: str) -> list[tuple[str | None, str | None]]:
+    """(scope, method) of every app.use/route call that passes a limiter by name."""
+    argument = re.compile(rf"(?<![\w$.]){re.escape(name)}(?![\w$(.])")
+    mounts = []
+    for source in sources:
+        for line in source.lines:
+            if COMMENT_LINE.match(line) or not argument.search(line):
+                continue
+            match = EXPRESS_MOUNT.search(line)
+            if not match or match.end() > argument.search(line).start():
+                continue
+            verb, path = match.group(1), match.group(2)
+            if verb == "use":
+                mounts.append((f"{path.rstrip('/')}/*" if path else None, None))
+            elif path:
+                mounts.append((path, None if verb == "all" else verb.upper()))
+    return mounts
+
+
+def extract_express(sources: list[SourceLines]) -> list[RateLimit]:
+    """express-rate-limit limiters resolved onto the routes and mounts that use them."""
+    results = []
+    for source in sources:
+        defined = set()
+        for match in EXPRESS_DEFINITION.finditer(source.text):
+            defined.add(match.end() - 1)
+            limits = _express_limits(source, match.end() - 1, match.group(1))
+            for scope, method in _mounts(sources, match.group(1)):
+                results.extend(replace(limit, scope=scope, method=method) for limit in limits)
+        for match in EXPRESS_INLINE.finditer(source.text):
+            opening = source.text.index("(", match.start())
+            if opening in defined:
+                continue
+            line = source.lines[source.line_of(match.start()) - 1]
+            mount = EXPRESS_MOUNT.search(line)
+            scope, method = None, None
+            if mount and mount.group(2):
+                verb, path = mount.group(1), mount.group(2)
+                scope, method = (f"{path.rstrip('/')}/*", None) if verb == "use" else (path, verb.upper())
+            results.extend(
+                replace(limit, scope=scope, method=method if method != "ALL" else None)
+                for limit in _express_limits(source, opening, None)
+            )
+    return results
+
+
+# Flask-Limiter and SlowAPI
+PY_LIMITER = re.compile(r"\bLimiter\s*\(")
+PY_DECORATOR = re.compile(r"^\s*@(\w+)\.(limit|shared_limit)\s*\(", re.MULTILINE)
+PY_EXEMPT = re.compile(r"^\s*@(\w+)\.exempt\b", re.MULTILINE)
+KEY_FUNC = re.compile(r"key_func\s*=\s*([\w.]+(?:\([^)]*\))?)")
+DEFAULT_LIMITS = re.compile(r"(default_limits|application_limits)\s*=\s*\[([^\]]*)\]")
+
+
+def _rate_tokens(text: str) -> list[tuple[int, int, tuple[int, int]]]:
+    """Rate strings in a snippet, as (start, end, (limit, seconds)) tokens."""
+    tokens = []
+    for literal in STRING.finditer(text):
+        for part in literal.group(1).split(";"):
+            rate = parse_rate(part)
+            if rate:
+                tokens.append((literal.start(), literal.end(), rate))
+    return tokens
+
+
+def extract_python_limiter(sources: list[SourceLines]) -> list[RateLimit]:
+    """Flask-Limiter/SlowAPI default limits, per-route decorators, and exemptions."""
+    default_key = RateLimitKey.IP
+    results = []
+    for source in sources:
+        for match in PY_LIMITER.finditer(source.text):
+            first, last, call = _span(source, match.end() - 1)
+            key_func = KEY_FUNC.search(call)
+            default_key = classify_key(key_func.group(1) if key_func else "", RateLimitKey.IP)
+            for defaults in DEFAULT_LIMITS.finditer(call):
+                key = RateLimitKey.GLOBAL if defaults.group(1) == "application_limits" else default_key
+                for _, _, (limit, period) in _rate_tokens(defaults.group(2)):
+                    results.append(
+                        _limit(source, "flask-limiter", first, last, limit=limit, period_seconds=period, key=key)
+                    )
+
+    for source in sources:
+        for match in PY_DECORATOR.finditer(source.text):
+            first, last, call = _span(source, match.end() - 1)
+            key_func = KEY_FUNC.search(call)
+            key = classify_key(key_func.group(1), default_key) if key_func else default_key
+            method, path = _decorated_route(source, last + 1)
+            name = STRING.search(call[call.find("scope") :]).group(1) if "scope=" in call else None
+            for role, (limit, period) in _branches(call, _rate_tokens(call)):
+                results.append(
+                    _limit(
+                        source, "flask-limiter", first, last, limit=limit, period_seconds=period, key=key,
+                        name=name, subject=role, scope=path, method=method,
+                    )  # fmt: skip
+                )
+        for match in PY_EXEMPT.finditer(source.text):
+            line = source.line_of(match.start())
+            method, path = _decorated_route(source, line + 1)
+            results.append(
+                _limit(
+                    source, "flask-limiter", line, line, limit=None, period_seconds=None, key=RateLimitKey.GLOBAL,
+                    scope=path, method=method, exempt=True,
+                )  # fmt: skip
+            )
+    return results
+
+
+# Django REST framework
+DRF_RATES = re.compile(r"""DEFAULT_THROTTLE_RATES['"]?\s*[:=]\s*\{""")
+DRF_ENTRY = re.compile(r"""['"]([\w.-]+)['"]\s*:\s*['"]([^'"]+)['"]""")
+
+
+def extract_drf(sources: list[SourceLines]) -> list[RateLimit]:
+    """DEFAULT_THROTTLE_RATES entries; anonymous callers are counted by IP, others by user."""
+    results = []
+    for source in sources:
+        for match in DRF_RATES.finditer(source.text):
+            first, last, rates = _span(source, match.end() - 1)
+            for entry in DRF_ENTRY.finditer(rates):
+                rate = parse_rate(entry.group(2))
+                if not rate:
+                    continue
+                scope = entry.group(1)
+                anonymous = scope == "anon"
+                results.append(
+                    _limit(
+                        source, "django-rest-framework", first, last, limit=rate[0], period_seconds=rate[1],
+                        key=RateLimitKey.IP if anonymous else RateLimitKey.USER, name=scope,
+                        subject="Anonymous" if anonymous else None,
+                    )  # fmt: skip
+                )
+    return results
+
+
+# ASP.NET Core rate limiting
+ASPNET_LIMITER = re.compile(r"\.Add(FixedWindow|SlidingWindow|TokenBucket|Concurrency)Limiter\(\s*(?:policyName:\s*)?\"([^\"]+)\"")
+ASPNET_POLICY = re.compile(r"\.AddPolicy\(\s*(?:policyName:\s*)?\"([^\"]+)\"")
+ASPNET_GLOBAL = re.compile(r"\bGlobalLimiter\s*=")
+ASPNET_PERMITS = re.compile(r"\b(?:PermitLimit|TokenLimit)\s*=\s*([^,;}\n]+)")
+ASPNET_PERIOD = re.compile(r"\b(?:Window|ReplenishmentPeriod)\s*=\s*([^,;}\n]+)")
+PARTITION_KEY = re.compile(r"partitionKey\s*:\s*([^,\n]+)")
+ASPNET_ENABLE = re.compile(r"\[EnableRateLimiting\(\s*\"([^\"]+)\"\s*\)\]")
+ASPNET_REQUIRE = re.compile(r"\.RequireRateLimiting\(\s*\"([^\"]+)\"")
+ASPNET_DISABLE = re.compile(r"\[DisableRateLimiting\]|\.DisableRateLimiting\(\)")
+
+
+def _aspnet_limits(source: SourceLines, first: int, last: int, body: str, name: str | None, key: str) -> list[RateLimit]:
+    """Permit limits in a limiter or partition body, per role where they differ."""
+    period_match = ASPNET_PERIOD.search(body)
+    period = parse_duration(period_match.group(1)) if period_match else None
+    tokens = [
+        (m.start(1) + n.start(), m.start(1) + n.end(), int(n.group(1)))
+        for m in ASPNET_PERMITS.finditer(body)
+        for n in NUMBER.finditer(m.group(1))
+    ]
+    return [
+        _limit(source, "aspnet-rate-limiting", first, last, limit=limit, period_seconds=period, key=key, name=name, subject=role)
+        for role, limit in _branches(body, tokens)
+    ]
+
+
+def _minimal_api_route(text: str, offset: int) -> tuple[str | None, str | None]:
+    """Route of the minimal API endpoint a chained call at offset configures."""
+    routes = list(EXTRA_ROUTES[1].finditer(text[max(0, offset - 400) : offset]))
+    if not routes:
+        return None, None
+    path = routes[-1].group(2)
+    return routes[-1].group(1).upper(), path if path.startswith("/") else "/" + path
+
+
+def extract_aspnet(sources: list[SourceLines]) -> list[RateLimit]:
+    """ASP.NET Core limiter policies resolved onto the endpoints that require them."""
+    policies: dict[str, list[RateLimit]] = {}
+    results = []
+    for source in sources:
+        for match in ASPNET_LIMITER.finditer(source.text):
+            first, last, body = _span(source, match.start() + 1)
+            # Unpartitioned limiters share one budget across all callers
+            policies[match.group(2)] = _aspnet_limits(source, first, last, body, match.group(2), RateLimitKey.GLOBAL)
+        for match in ASPNET_POLICY.finditer(source.text):
+            first, last, body = _span(source, match.start() + 1)
+            if "RateLimitPartition" not in body:
+                continue  # An authorization or CORS policy
+            partition = PARTITION_KEY.search(body)
+            key = classify_key(partition.group(1), RateLimitKey.GLOBAL) if partition else RateLimitKey.GLOBAL
+            policies[match.group(1)] = _aspnet_limits(source, first, last, body, match.group(1), key)
+        for match in ASPNET_GLOBAL.finditer(source.text):
+            first, last, body = _span(source, match.end())
+            partition = PARTITION_KEY.search(body)
+            key = classify_key(partition.group(1), RateLimitKey.GLOBAL) if partition else RateLimitKey.GLOBAL
+            results.extend(_aspnet_limits(source, first, last, body, None, key))
+
+    for source in sources:
+        for match in ASPNET_ENABLE.finditer(source.text):
+            method, path = _decorated_route(source, source.line_of(match.end()))
+            results.extend(replace(limit, scope=path, method=method) for limit in policies.get(match.group(1), []))
+        for match in ASPNET_REQUIRE.finditer(source.text):
+            method, path = _minimal_api_route(source.text, match.start())
+            results.extend(replace(limit, scope=path, method=method) for limit in policies.get(match.group(1), []))
+        for match in ASPNET_DISABLE.finditer(source.text):
+            line = source.line_of(match.start())
+            if match.group().startswith("["):
+                method, path = _decorated_route(source, line)
+            else:
+                method, path = _minimal_api_route(source.text, match.start())
+            results.append(
+                _limit(
+                    source, "aspnet-rate-limiting", line, line, limit=None, period_seconds=None,
+                    key=RateLimitKey.GLOBAL, scope=path, method=method, exempt=True,
+                )  # fmt: skip
+            )
+    return results
+
+
+# Bucket4j and Resilience4j
+BANDWIDTH = re.compile(r"Bandwidth\.(?:simple|classic)\(\s*(\d+)|\.capacity\(\s*(\d+)\s*\)")
+BUCKET_KEY_LINE = re.compile(r"^.*(?:computeIfAbsent|resolveBucket|getBucket|proxyManager|buckets\.get).*$", re.MULTILINE | re.IGNORECASE)
+R4J_ANNOTATION = re.compile(r"@RateLimiter\(\s*(?:name\s*=\s*)?\"([^\"]+)\"")
+R4J_PROPERTY = re.compile(
+    r"resilience4j\.ratelimiter\.instances\.([\w-]+)\.(limit-?for-?period|limitForPeriod|limit-?refresh-?period|limitRefreshPeriod)"
+    r"\s*[=:]\s*(\S+)",
+    re.IGNORECASE,
+)
+
+
+def _r4j_yaml(text: str) -> dict[str, dict[str, str]]:
+    """Resilience4j rate limiter instances configured in a YAML document."""
+    try:
+        documents = list(yaml.safe_load_all(text))
+    except yaml.YAMLError:
+        return {}
+    instances: dict[str, dict[str, str]] = {}
+    for document in documents:
+        node = document
+        for part in ("resilience4j", "ratelimiter", "instances"):
+            node = node.get(part) if isinstance(node, dict) else None
+        if not isinstance(node, dict):
+            continue
+        for name, settings in node.items():
+            if isinstance(settings, dict):
+                instances[str(name)] = {str(k).replace("-", "").lower(): str(v) for k, v in settings.items()}
+    return instances
+
+
+def extract_jvm_limits(sources: list[SourceLines]) -> list[RateLimit]:
+    """Bucket4j bandwidths (service-wide) and Resilience4j limiters on annotated endpoints."""
+    results = []
+    instances: dict[str, tuple[SourceLines, int, dict[str, str]]] = {}
+    for source in sources:
+        suffix = PurePosixPath(source.file_path).suffix
+        if suffix in (".java", ".kt"):
+            tokens = [(m.start(), m.end(), int(m.group(1) or m.group(2))) for m in BANDWIDTH.finditer(source.text)]
+            key = classify_key("\n".join(BUCKET_KEY_LINE.findall(source.text)), RateLimitKey.GLOBAL)
+            for (start, end, _), (role, limit) in zip(tokens, _branches(source.text, tokens), strict=True):
+                period = parse_duration(source.text[end : end + 200])
+                line = source.line_of(start)
+                results.append(
+                    _limit(source, "bucket4j", line, line, limit=limit, period_seconds=period, key=key, subject=role)
+                )
+        elif suffix == ".properties":
+            for match in R4J_PROPERTY.finditer(source.text):
+                _, _, settings = instances.setdefault(match.group(1), (source, source.line_of(match.start()), {}))
+                settings[match.group(2).replace("-", "").lower()] = match.group(3)
+        elif suffix in (".yml", ".yaml") and "ratelimiter" in source.text:
+            for name, settings in _r4j_yaml(source.text).items():
+                line = next((i for i, text in enumerate(source.lines, start=1) if re.search(rf"\b{re.escape(name)}\s*:", text)), 1)
+                instances.setdefault(name, (source, line, settings))
+
+    for source in sources:
+        for match in R4J_ANNOTATION.finditer(source.text):
+            if match.group(1) not in instances:
+                continue
+            config_source, line, settings = instances[match.group(1)]
+            if "limitforperiod" not in settings or not settings["limitforperiod"].isdigit():
+                continue
+            method, path = _decorated_route(source, source.line_of(match.end()))
+            results.append(
+                _limit(
+                    config_source, "resilience4j", line, line, limit=int(settings["limitforperiod"]),
+                    period_seconds=parse_duration(settings.get("limitrefreshperiod", "")) or 1,
+                    key=RateLimitKey.GLOBAL, name=match.group(1), scope=path, method=method,
+                )  # fmt: skip
+            )
+    return results
+
+
+# Laravel
+LARAVEL_LIMITER = re.compile(r"""RateLimiter::for\(\s*['"]([^'"]+)['"]""")
+LARAVEL_LIMIT = re.compile(r"Limit::(?:(perSecond|perMinute|perHour|perDay)\(\s*(\d+)|perMinutes\(\s*(\d+)\s*,\s*(\d+)|(none)\(\))")
+LARAVEL_BY = re.compile(r"->by\(([^;]*?)\)\s*(?:[;:)]|$)", re.MULTILINE)
+LARAVEL_THROTTLE = re.compile(r"""['"]throttle:([\w,-]+)['"]""")
+LARAVEL_PREFIX = re.compile(r"""prefix\(\s*['"]([^'"]+)['"]""")
+LARAVEL_PERIODS = {"perSecond": 1, "perMinute": 60, "perHour": 3600, "perDay": 86400}
+
+
+def _laravel_limits(source: SourceLines, first: int, last: int, body: str, name: str) -> list[RateLimit]:
+    """Limits returned by one RateLimiter::for callback, per role where they differ."""
+    tokens = []
+    for match in LARAVEL_LIMIT.finditer(body):
+        if match.group(5):
+            value = (None, None)
+        elif match.group(1):
+            value = (int(match.group(2)), LARAVEL_PERIODS[match.group(1)])
+        else:
+            value = (int(match.group(4)), int(match.group(3)) * 60)
+        by = LARAVEL_BY.search(body, match.end())
+        tokens.append((match.start(), match.end(), (value, by.group(1) if by else "")))
+    results = []
+    for role, ((limit, period), by) in _branches(body, tokens):
+        results.append(
+            _limit(
+                source, "laravel", first, last, limit=limit, period_seconds=period,
+                key=classify_key(by, RateLimitKey.GLOBAL), name=name, subject=role, exempt=limit is None,
+            )  # fmt: skip
+        )
+    return results
+
+
+def extract_laravel(sources: list[SourceLines]) -> list[RateLimit]:
+    """Laravel named limiters and throttle middleware, resolved onto routes and prefixes."""
+    named: dict[str, list[RateLimit]] = {}
+    for source in sources:
+        for match in LARAVEL_LIMITER.finditer(source.text):
+            first, last, body = _span(source, match.start() + len("RateLimiter::for"))
+            named[match.group(1)] = _laravel_limits(source, first, last, body, match.group(1))
+
+    results = []
+    for source in sources:
+        for match in LARAVEL_THROTTLE.finditer(source.text):
+            line_number = source.line_of(match.start())
+            line = source.lines[line_number - 1]
+            if COMMENT_LINE.match(line):
+                continue
+            routes = _routes(line)
+            prefix = LARAVEL_PREFIX.search(line)
+            if routes:
+                method, scope = routes[0]
+            else:
+                method, scope = None, f"/{prefix.group(1).strip('/')}/*" if prefix else None
+            arguments = match.group(1).split(",")
+            if arguments[0].isdigit():
+                minutes = int(arguments[1]) if len(arguments) > 1 and arguments[1].isdigit() else 1
+                results.append(
+                    _limit(
+                        source, "laravel", line_number, line_number, limit=int(arguments[0]),
+                        period_seconds=minutes * 60, key=RateLimitKey.USER, scope=scope, method=method,
+                    )  # fmt: skip
+                )
+            else:
+                results.extend(replace(limit, scope=scope, method=method) for limit in named.get(arguments[0], []))
+    return results
+
+
+# Rack::Attack
+RACK_THROTTLE = re.compile(r"""\bthrottle\(\s*['"]([^'"]+)['"]\s*,\s*limit:\s*(.+?),\s*period:\s*(.+?)\)\s*(?:do\b|\{)""")
+RACK_PATH = re.compile(r"""req\.path\s*==\s*['"]([^'"]+)['"]|start_with\?\(\s*['"]([^'"]+)['"]""")
+RACK_METHOD = re.compile(r"""req\.(get|post|put|patch|delete)\?|request_method\s*==\s*['"](\w+)['"]""")
+
+
+def extract_rack_attack(sources: list[SourceLines]) -> list[RateLimit]:
+    """Rack::Attack throttles, scoped by the request path and method their block tests."""
+    results = []
+    for source in sources:
+        if not source.file_path.endswith(".rb"):
+            continue
+        for match in RACK_THROTTLE.finditer(source.text):
+            first = source.line_of(match.start())
+            last = next(
+                (i for i in range(first, min(len(source.lines), first + MAX_BLOCK_LINES)) if re.match(r"^\s*(?:end\b|\})", source.lines[i])),
+                first,
+            ) + 1
+            block = source.span(first, last)
+            path = RACK_PATH.search(block)
+            scope = None
+            if path:
+                scope = path.group(1) or f"{path.group(2).rstrip('/')}/*"
+            verb = RACK_METHOD.search(block)
+            method = (verb.group(1) or verb.group(2)).upper() if verb else None
+            key = classify_key(block[block.find("|") :], RateLimitKey.GLOBAL)
+            period = parse_duration(match.group(3)) or (int(match.group(3)) if match.group(3).isdigit() else None)
+            limit_text = match.group(2)
+            tokens = [(m.start(), m.end(), int(m.group(1))) for m in NUMBER.finditer(limit_text)]
+            for role, limit in _branches(limit_text, tokens):
+                results.append(
+                    _limit(
+                        source, "rack-attack", first, last, limit=limit, period_seconds=period, key=key,
+                        name=match.group(1), subject=role, scope=scope, method=method,
+                    )  # fmt: skip
+                )
+    return results
+
+
+# Go: httprate and golang.org/x/time/rate
+HTTPRATE = re.compile(r"httprate\.(Limit|LimitByIP|LimitByRealIP|LimitAll)\(\s*(\d+)\s*,\s*([^,)\n]+)")
+GO_RATE = re.compile(r"rate\.NewLimiter\(\s*(?:rate\.Limit\(\s*(\d+)\s*\)|(\d+)|rate\.Every\(([^)]+)\))")
+
+
+def extract_go_limits(sources: list[SourceLines]) -> list[RateLimit]:
+    """httprate middleware on routes or routers, and x/time/rate limiters."""
+    results = []
+    for source in sources:
+        if not source.file_path.endswith(".go"):
+            continue
+        for match in HTTPRATE.finditer(source.text):
+            line_number = source.line_of(match.start())
+            line = source.lines[line_number - 1]
+            _, _, call = _span(source, match.start() + len("httprate.") + len(match.group(1)))
+            if match.group(1) == "LimitAll":
+                key = RateLimitKey.GLOBAL
+            elif match.group(1) == "Limit":
+                key = classify_key(call, RateLimitKey.IP)
+            else:
+                key = RateLimitKey.IP
+            routes = _routes(line)
+            method, scope = routes[0] if routes and ".Use(" not in line else (None, None)
+            results.append(
+                _limit(
+                    source, "httprate", line_number, line_number, limit=int(match.group(2)),
+                    period_seconds=parse_duration(match.group(3)), key=key, scope=scope, method=method,
+                )  # fmt: skip
+            )
+        for match in GO_RATE.finditer(source.text):
+            line_number = source.line_of(match.start())
+            if match.group(3):
+                limit, period = 1, parse_duration(match.group(3))
+            else:
+                limit, period = int(match.group(1) or match.group(2)), 1
+            results.append(
+                _limit(source, "x/time/rate", line_number, line_number, limit=limit, period_seconds=period, key=RateLimitKey.GLOBAL)
+            )
+    return results
+
+
+# nginx
+NGINX_ZONE = re.compile(r"limit_req_zone\s+(\$\w+)\s+zone=([\w-]+):\S+\s+rate=(\d+)r/([sm])")
+NGINX_USE = re.compile(r"limit_req\s+zone=([\w-]+)")
+NGINX_LOCATION = re.compile(r"^\s*location\s+(?:[=~^*]+\s*)?(\S+)\s*\{")
+NGINX_KEYS = {
+    "$binary_remote_addr": RateLimitKey.IP,
+    "$remote_addr": RateLimitKey.IP,
+    "$server_name": RateLimitKey.GLOBAL,
+    "$host": RateLimitKey.GLOBAL,
+}
+
+
+def extract_nginx(sources: list[SourceLines]) -> list[RateLimit]:
+    """limit_req zones resolved onto the location blocks that apply them."""
+    zones: dict[str, tuple[SourceLines, int, int, int, str]] = {}
+    for source in sources:
+        for match in NGINX_ZONE.finditer(source.text):
+            period = 1 if match.group(4) == "s" else 60
+            key = NGINX_KEYS.get(match.group(1)) or classify_key(match.group(1), RateLimitKey.GLOBAL)
+            zones[match.group(2)] = (source, source.line_of(match.start()), int(match.group(3)), period, key)
+
+    results = []
+    for source in sources:
+        if "limit_req" not in source.text:
+            continue
+        locations: list[str | None] = []
+        for number, line in enumerate(source.lines, start=1):
+            location = NGINX_LOCATION.match(line)
+            if location:
+                locations.append(location.group(1))
+            elif line.strip().endswith("{"):
+                locations.append(locations[-1] if locations else None)
+            use = NGINX_USE.search(line)
+            if use and use.group(1) in zones and not COMMENT_LINE.match(line):
+                zone_source, zone_line, limit, period, key = zones[use.group(1)]
+                path = next((p for p in reversed(locations) if p), None)
+                scope = None if path in (None, "/") else f"{path.rstrip('/')}/*"
+                results.append(
+                    _limit(
+                        source, "nginx", number, number, limit=limit, period_seconds=period, key=key,
+                        name=use.group(1), scope=scope,
+                    )  # fmt: skip
+                )
+            closes = line.count("}") - (1 if line.strip().endswith("{") and "}" in line else 0)
+            for _ in range(max(closes, 0)):
+                if locations:
+                    locations.pop()
+    return results
+
+
+# Kong declarative configuration
+KONG_PLUGINS = {"rate-limiting", "rate-limiting-advanced", "response-ratelimiting"}
+KONG_PLUGIN_LINE = re.compile(r"name:\s*['\"]?(rate-limiting(?:-advanced)?|response-ratelimiting)\b")
+KONG_UNITS = {"second": 1, "minute": 60, "hour": 3600, "day": 86400, "month": 2592000, "year": 31536000}
+KONG_LIMIT_BY = {
+    "consumer": RateLimitKey.CONSUMER,
+    "credential": RateLimitKey.API_KEY,
+    "ip": RateLimitKey.IP,
+    "service": RateLimitKey.GLOBAL,
+    "path": RateLimitKey.GLOBAL,
+    "consumer-group": RateLimitKey.CONSUMER,
+}
+
+
+def _kong_limits(plugin: dict) -> list[tuple[int, int, str]]:
+    """(limit, seconds, key) of each window a rate-limiting plugin configures."""
+    config = plugin.get("config") or {}
+    if not isinstance(config, dict):
+        return []
+    limit_by = str(config.get("limit_by") or config.get("identifier") or "consumer")
+    key = KONG_LIMIT_BY.get(limit_by)
+    if key is None:
+        key = classify_key(str(config.get("header_name") or limit_by), RateLimitKey.GLOBAL)
+    if plugin.get("name") == "rate-limiting-advanced":
+        limits = config.get("limit") or []
+        windows = config.get("window_size") or []
+        return [(int(n), int(w), key) for n, w in zip(limits, windows, strict=False) if str(n).isdigit() and str(w).isdigit()]
+    return [(int(config[u]), s, key) for u, s in KONG_UNITS.items() if str(config.get(u, "")).isdigit()]
+
+
+def extract_kong(sources: list[SourceLines]) -> list[RateLimit]:
+    """Kong rate-limiting plugins attached globally or to services, routes, and consumers."""
+    results = []
+    for source in sources:
+        if PurePosixPath(source.file_path).suffix not in (".yml", ".yaml") or "ratelimiting" not in source.text.replace("-", ""):
+            continue
+        try:
+            documents = [d for d in yaml.safe_load_all(source.text) if isinstance(d, dict)]
+        except yaml.YAMLError:
+            continue
+        plugin_lines = [source.line_of(m.start()) for m in KONG_PLUGIN_LINE.finditer(source.text)]
+
+        def _next_line() -> int:
+            return plugin_lines.pop(0) if plugin_lines else 1
+
+        for document in documents:
+            routes_by_name: dict[str, list[tuple[str | None, str | None]]] = {}
+
+            def _route_scopes(route: dict) -> list[tuple[str | None, str | None]]:
+                methods = route.get("methods") or [None]
+                paths = route.get("paths") or ["/"]
+                return [
+                    (None if str(p) == "/" else f"{str(p).rstrip('/')}/*", str(m).upper() if m else None)
+                    for p in paths
+                    for m in methods
+                ]
+
+            def _add(plugins, scopes, subject=None, label=None):
+                for plugin in plugins or []:
+                    if not isinstance(plugin, dict) or plugin.get("name") not in KONG_PLUGINS:
+                        continue
+                    line = _next_line()
+                    for limit, period, key in _kong_limits(plugin):
+                        for scope, method in scopes:
+                            results.append(
+                                _limit(
+                                    source, "kong", line, line, limit=limit, period_seconds=period,
+                                    key=RateLimitKey.CONSUMER if subject else key, name=label, subject=subject,
+                                    scope=scope, method=method,
+                                )  # fmt: skip
+                            )
+
+            services = [s for s in document.get("services") or [] if isinstance(s, dict)]
+            standalone = [r for r in document.get("routes") or [] if isinstance(r, dict)]
+            for route in standalone + [r for s in services for r in s.get("routes") or [] if isinstance(r, dict)]:
+                routes_by_name[str(route.get("name"))] = _route_scopes(route)
+
+            _add(document.get("plugins"), [(None, None)], label="global")
+            for service in services:
+                service_routes = [r for r in service.get("routes") or [] if isinstance(r, dict)]
+                scopes = [s for r in service_routes for s in _route_scopes(r)] or [(None, None)]
+                _add(service.get("plugins"), scopes, label=str(service.get("name") or "service"))
+                for route in service_routes:
+                    _add(route.get("plugins"), _route_scopes(route), label=str(route.get("name") or "route"))
+            for route in standalone:
+                _add(route.get("plugins"), _route_scopes(route), label=str(route.get("name") or "route"))
+            for consumer in document.get("consumers") or []:
+                if isinstance(consumer, dict):
+                    subject = str(consumer.get("username") or consumer.get("custom_id") or "consumer")
+                    _add(consumer.get("plugins"), [(None, None)], subject=subject, label=f"consumer {subject}")
+    return results
+
+
+EXTRACTORS = [
+    extract_express,
+    extract_python_limiter,
+    extract_drf,
+    extract_aspnet,
+    extract_jvm_limits,
+    extract_laravel,
+    extract_rack_attack,
+    extract_go_limits,
+    extract_nginx,
+    extract_kong,
+]
+
+
+def is_rate_limit_source(file_path: str) -> bool:
+    """Check whether a path may declare rate limits."""
+    return PurePosixPath(file_path).suffix in RATE_LIMIT_SUFFIXES and not file_path.endswith(".d.ts")
+
+
+def extract_rate_limits(files: dict[str, str]) -> list[RateLimit]:
+    """Extract rate limits from a repository's sources and config.
+
+    Args:
+        files: Relative path -> content
+
+    Returns:
+        Rate limits and exemptions, per role where limits differ
+    """
+    sources = [SourceLines(path, text) for path, text in sorted(files.items()) if PREFILTER.search(text)]
+    results = []
+    for extractor in EXTRACTORS:
+        results.extend(extractor(sources))
+    return results
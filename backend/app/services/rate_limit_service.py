@@ -0,0 +1,188 @@
+"""Service for mining rate limits and quotas per service and endpoint.
+
+Partner agreements state how often each caller may use an API, so the limits
+a service enforces belong next to the policies saying who may call it. This
+service reads rate-limit middleware and gateway configuration from a
+repository's clone, resolves each limit onto the endpoints the mined policies
+map to (by path scope, method, and the role or consumer it applies to), and
+reports authenticated endpoints that no limit covers.
+"""
+
+from collections.abc import Iterator
+from dataclasses import asdict
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.cors_csrf_service import scope_matches
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import ANONYMOUS_ROLE, EndpointMappingService, EndpointRule
+from app.services.rate_limit_extractor import (
+    RateLimit,
+    RateLimitKey,
+    extract_rate_limits,
+    is_rate_limit_source,
+)
+
+logger = structlog.get_logger(__name__)
+
+
+def limit_applies(limit: RateLimit, rule: EndpointRule) -> bool:
+    """Whether a limit covers an endpoint.
+
+    Limits without a scope or method cover every route. A role-specific
+    limit covers endpoints that role may call; limits for anonymous callers
+    only cover public endpoints. Gateway consumer limits follow the consumer
+    to every endpoint.
+
+    Args:
+        limit: Mined rate limit
+        rule: Endpoint rule
+
+    Returns:
+        True if the limit applies
+    """
+    if limit.scope is not None and not scope_matches(limit.scope, rule.path):
+        return False
+    if limit.method is not None and limit.method != rule.method:
+        return False
+    if limit.subject is None or limit.key == RateLimitKey.CONSUMER:
+        return True
+    if limit.subject.lower() == ANONYMOUS_ROLE:
+        return rule.is_public
+    return rule.allows(limit.subject)
+
+
+def resolve_endpoints(policies: list[Policy], limits: list[RateLimit]) -> list[dict]:
+    """Attach the rate limits covering each endpoint the policies map to.
+
+    Args:
+        policies: Mined policies
+        limits: Rate limits mined from the same service
+
+    Returns:
+        Endpoint dictionaries, unlimited authenticated endpoints first
+    """
+    endpoints = []
+    for rule in EndpointMappingService.map_policies(policies):
+        applicable = [limit for limit in limits if limit_applies(limit, rule)]
+        # An exemption names its route; exemptions of unknown routes are not applied
+        exempt = any(limit.exempt and limit.scope is not None for limit in applicable)
+        enforced = [] if exempt else [limit for limit in applicable if not limit.exempt]
+        rates: dict[str, list[str]] = {}
+        for limit in enforced:
+            rates.setdefault(limit.subject or "*", []).append(limit.rate)
+        endpoints.append(
+            {
+                "endpoint": rule.key,
+                "method": rule.method,
+                "path": rule.path,
+                "policy_ids": rule.policy_ids,
+                "roles": rule.roles,
+                "requires_authentication": rule.requires_authentication,
+                "rate_limited": bool(enforced),
+                "exempt": exempt,
+                "unlimited": rule.requires_authentication and not enforced,
+                "rates": rates,
+                "limits": [asdict(limit) | {"rate": limit.rate} for limit in enforced],
+            }
+        )
+    endpoints.sort(key=lambda e: (not e["unlimited"], e["path"], e["method"]))
+    return endpoints
+
+
+def rate_limits(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the rate limits and exemptions of a repository clone."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    files = {}
+    for path in sorted(clone.rglob("*")):
+        relative = path.relative_to(clone)
+        if not is_rate_limit_source(relative.as_posix()) or SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        files[relative.as_posix()] = path.read_text(encoding="utf-8", errors="ignore")
+    yield from (asdict(r) for r in extract_rate_limits(files))
+
+
+class RateLimitService:
+    """Mines rate limits and quotas into each service's authorization contract."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[RateLimit]:
+        """Read rate limits from a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Rate limits and exemptions
+        """
+        return [RateLimit(**r) for r in run_pass(root, rate_limits)]
+
+    def _limits(self, repository: Repository) -> dict:
+        """Rate limits of one repository, resolved onto its endpoints."""
+        root = self.clone_dir / str(repository.id)
+        cloned = root.is_dir()
+        limits = self.scan_clone(root) if cloned else []
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository.id)
+        endpoints = resolve_endpoints(policies, limits)
+        unlimited = sum(e["unlimited"] for e in endpoints)
+        logger.info(
+            "rate_limits_mined",
+            repository_id=repository.id,
+            limits=len(limits),
+            endpoints=len(endpoints),
+            unlimited_endpoints=unlimited,
+        )
+        return {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "cloned": cloned,
+            "limits": [asdict(limit) | {"rate": limit.rate} for limit in limits],
+            "unlimited_endpoints": unlimited,
+            "endpoints": endpoints,
+        }
+
+    def services(self, repository_id: int | None = None, unlimited_only: bool = False) -> list[dict]:
+        """Rate limits per service (repository).
+
+        Args:
+            repository_id: Restrict to one repository
+            unlimited_only: Keep only services with authenticated endpoints no limit covers
+
+        Returns:
+            Per-repository limits and endpoints with the limits covering them
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        reports = [self._limits(repository) for repository in repositories]
+        if unlimited_only:
+            reports = [r for r in reports if r["unlimited_endpoints"]]
+        return reports
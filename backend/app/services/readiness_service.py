@@ -0,0 +1,720 @@
+"""Assess how analyzable a repository is before mining it.
+
+Mining fidelity depends on the repository more than on the miner: routes
+registered with literal paths in a framework that has a dedicated extractor
+resolve deterministically; routes whose paths are built at runtime, handlers
+dispatched through reflection, home-grown authorization helpers, and
+decisions delegated to an external policy engine do not. The assessment
+reads a clone without LLM calls and reports which languages and frameworks
+it found and how well each is supported, what share of route registrations
+resolve to a literal endpoint, and which dynamic patterns need custom rules,
+combined into a 0-100 score with the fixes that would raise it most.
+"""
+
+import re
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.readiness import ReadinessAssessment
+from app.models.repository import Repository
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+
+logger = structlog.get_logger(__name__)
+
+# Patterns listed per kind before truncating; counts always cover every match
+MAX_LISTED_PATTERNS = 25
+
+# Minimum score of a repository considered ready for mining
+READY_SCORE = 75
+
+GRADES = [(90, "A"), (75, "B"), (60, "C"), (40, "D")]
+
+PRIORITY_ORDER = {"high": 0, "medium": 1, "low": 2}
+
+COMPONENT_WEIGHTS = {
+    "language_coverage": 0.2,
+    "framework_support": 0.25,
+    "route_resolution": 0.35,
+    "dynamic_patterns": 0.2,
+}
+
+# Dynamic patterns per source file at which the dynamic pattern component reaches zero
+MAX_PATTERN_DENSITY = 0.2
+
+# Languages the scanner mines, by file extension
+SUPPORTED_LANGUAGES = {
+    ".py": "Python",
+    ".java": "Java",
+    ".kt": "Kotlin",
+    ".scala": "Scala",
+    ".cs": "C#",
+    ".js": "JavaScript/TypeScript",
+    ".jsx": "JavaScript/TypeScript",
+    ".ts": "JavaScript/TypeScript",
+    ".tsx": "JavaScript/TypeScript",
+    ".go": "Go",
+    ".rb": "Ruby",
+    ".php": "PHP",
+}
+
+# Backend languages the scanner does not mine
+UNSUPPORTED_LANGUAGES = {
+    ".rs": "Rust",
+    ".ex": "Elixir",
+    ".exs": "Elixir",
+    ".erl": "Erlang",
+    ".clj": "Clojure",
+    ".hs": "Haskell",
+    ".fs": "F#",
+    ".cr": "Crystal",
+    ".pl": "Perl",
+}
+
+# Test sources register routes and use reflection without affecting production authorization
+TEST_PATH = re.compile(r"(?:^|/)(?:tests?|__tests__|spec)/|[._-](?:test|spec)\.\w+$|_test\.go$|Tests?\.(?:java|kt|cs)$")
+
+
+class FrameworkSupport:
+    """How deeply a framework is analyzed."""
+
+    DEDICATED = "dedicated"  # A framework extractor resolves route-level auth configuration
+    ROUTES = "routes"  # Routes are inventoried; auth is mined from handler code
+    SCANNER = "scanner"  # Handler code is mined; routes are not inventoried
+
+
+SUPPORT_WEIGHTS = {FrameworkSupport.DEDICATED: 1.0, FrameworkSupport.ROUTES: 0.8, FrameworkSupport.SCANNER: 0.5}
+
+# Framework score of a repository in a supported language but no recognized framework
+UNRECOGNIZED_FRAMEWORK_WEIGHT = 0.5
+
+
+@dataclass(frozen=True)
+class Framework:
+    """A web framework recognized by its imports."""
+
+    name: str
+    language: str
+    support: str
+    marker: re.Pattern
+
+
+FRAMEWORKS = [
+    Framework("Koa", "JavaScript/TypeScript", FrameworkSupport.DEDICATED, re.compile(r"['\"](?:koa|@koa/router|koa-router)['\"]")),
+    Framework("Hapi", "JavaScript/TypeScript", FrameworkSupport.DEDICATED, re.compile(r"['\"](?:@hapi/hapi|hapi)['\"]")),
+    Framework("Express", "JavaScript/TypeScript", FrameworkSupport.ROUTES, re.compile(r"(?:require\s*\(\s*|from\s+)['\"]express['\"]")),
+    Framework("Fastify", "JavaScript/TypeScript", FrameworkSupport.DEDICATED, re.compile(r"(?:require\s*\(\s*|from\s+)['\"]fastify['\"]")),
+    Framework("NestJS", "JavaScript/TypeScript", FrameworkSupport.DEDICATED, re.compile(r"['\"]@nestjs/(?:common|core)['\"]")),
+    Framework("Micronaut", "Java/Kotlin", FrameworkSupport.DEDICATED, re.compile(r"\bio\.micronaut\.")),
+    Framework("Quarkus", "Java/Kotlin", FrameworkSupport.DEDICATED, re.compile(r"\bio\.quarkus\.")),
+    Framework("Play", "Scala", FrameworkSupport.DEDICATED, re.compile(r"\bplay\.(?:api\.)?mvc\b")),
+    Framework("Spring", "Java/Kotlin", FrameworkSupport.DEDICATED, re.compile(r"\borg\.springframework\.(?:web|security|stereotype)\b")),
+    Framework("JAX-RS", "Java/Kotlin", FrameworkSupport.ROUTES, re.compile(r"\b(?:javax|jakarta)\.ws\.rs\b")),
+    Framework("FastAPI", "Python", FrameworkSupport.DEDICATED, re.compile(r"^\s*(?:from|import)\s+fastapi\b", re.MULTILINE)),
+    Framework("Flask", "Python", FrameworkSupport.ROUTES, re.compile(r"^\s*(?:from|import)\s+flask\b", re.MULTILINE)),
+    Framework("Django", "Python", FrameworkSupport.DEDICATED, re.compile(r"^\s*(?:from|import)\s+django\b", re.MULTILINE)),
+    Framework("ASP.NET Core", "C#", FrameworkSupport.DEDICATED, re.compile(r"\busing\s+Microsoft\.AspNetCore\b")),
+    Framework("Gin", "Go", FrameworkSupport.DEDICATED, re.compile(r"\"github\.com/gin-gonic/gin\"")),
+    Framework("Echo", "Go", FrameworkSupport.DEDICATED, re.compile(r"\"github\.com/labstack/echo")),
+    Framework("chi", "Go", FrameworkSupport.DEDICATED, re.compile(r"\"github\.com/go-chi/chi")),
+    Framework("gorilla/mux", "Go", FrameworkSupport.ROUTES, re.compile(r"\"github\.com/gorilla/mux\"")),
+    Framework("gRPC", "Go", FrameworkSupport.DEDICATED, re.compile(r"\"google\.golang\.org/grpc\"")),
+    Framework("Rails", "Ruby", FrameworkSupport.DEDICATED, re.compile(r"\b(?:ActionController|Rails\.application)\b")),
+    Framework("Laravel", "PHP", FrameworkSupport.SCANNER, re.compile(r"\bIlluminate\\")),
+    Framework(
+        "GraphQL",
+        "JavaScript/TypeScript, Go, Python",
+        FrameworkSupport.DEDICATED,
+        re.compile(
+            r"['\"](?:@apollo/server|apollo-server[\w-]*|graphql-yoga|@graphql-tools/[\w-]+|type-graphql|graphql-shield)['\"]|"
+            r"\"github\.com/99designs/gqlgen|^\s*(?:from|import)\s+(?:ariadne|strawberry|graphene)\b",
+            re.MULTILINE,
+        ),
+    ),
+]
+
+# Route registrations; "arg" is the path argument, absent for class-level mappings
+ROUTE_REGISTRATIONS = [
+    re.compile(
+        r"(?<![\w$.])(?P<receiver>[A-Za-z_$][\w$]*)\s*\.\s*(?:get|post|put|patch|delete|route|api_route|add_url_rule|"
+        r"GET|POST|PUT|PATCH|DELETE|Get|Post|Put|Patch|Delete|HandleFunc)\s*\(\s*(?P<arg>[^,)\n]*)"
+    ),
+    re.compile(r"@(?:Get|Post|Put|Patch|Delete|Request)Mapping\b(?:\s*\(\s*(?:value\s*=\s*|path\s*=\s*)?(?P<arg>[^,)\n]*))?"),
+    re.compile(r"@(?:Get|Post|Put|Patch|Delete)\s*\(\s*(?:value\s*=\s*|uri\s*=\s*)?(?P<arg>[^,)\n]*)"),
+    re.compile(r"\[(?:Http(?:Get|Post|Put|Patch|Delete)|Route)\b(?:\s*\(\s*(?P<arg>[^,)\n]*))?"),
+    re.compile(r"@Path\s*\(\s*(?P<arg>[^,)\n]*)"),
+]
+ROUTER_RECEIVER = re.compile(
+    r"^(?:app|api|server|fastify|router|routes?|r|e|g|mux|bp|blueprint|v\d+|\w*(?:Router|router|Routes|routes|Group|group|_bp|App|Api))$"
+)
+# Route methods chosen at runtime: router[method](...), getattr(app, method)(...)
+COMPUTED_ROUTE = re.compile(
+    r"(?<![\w$.])(?:app|router|\w*Router)\s*\[\s*(?!['\"])[^\]\n]+\]\s*\(|\bgetattr\s*\(\s*(?:app|router|\w+_router|bp)\s*,\s*(?!['\"])"
+)
+STRING_LITERAL = re.compile(r"^[rbu]?(['\"`])(.*)\1$", re.IGNORECASE | re.DOTALL)
+INTERPOLATED = re.compile(r"\$\{|#\{")
+
+# Reflective lookups and runtime dispatch: (pattern, languages it applies to, description)
+REFLECTION = [
+    (re.compile(r"\bgetattr\s*\(\s*(?!(?:app|router|bp)\s*,)[\w.]+\s*,\s*(?!['\"])[A-Za-z_]"), ("Python",), "attribute looked up by a computed name"),
+    (re.compile(r"\bimportlib\.import_module\s*\(|\b__import__\s*\("), ("Python",), "module imported by a computed name"),
+    (
+        re.compile(r"\brequire\s*\(\s*(?!(?:'[^'\n]*'|\"[^\"\n]*\"|`[^`$\n]*`)\s*\))(?!\))"),
+        ("JavaScript/TypeScript",),
+        "module required by a computed path",
+    ),
+    (re.compile(r"(?<![\w.$])eval\s*\(|\bnew\s+Function\s*\("), ("JavaScript/TypeScript", "Python", "Ruby", "PHP"), "code evaluated at runtime"),
+    (re.compile(r"\bClass\.forName\s*\(\s*(?!\")|\.get(?:Declared)?Method\s*\(\s*(?!\")"), ("Java", "Kotlin", "Scala"), "method resolved by reflection"),
+    (re.compile(r"\.MethodByName\s*\("), ("Go",), "method resolved by reflection"),
+    (re.compile(r"\.(?:public_)?send\s*\(\s*(?![:'\"])[\w@]|\bdefine_method\s*\("), ("Ruby",), "method dispatched by a computed name"),
+    (re.compile(r"\bcall_user_func(?:_array)?\s*\(|\$\w+->\$\w+\s*\("), ("PHP",), "method dispatched by a computed name"),
+    (re.compile(r"\.GetMethod\s*\(\s*(?!\")|\bActivator\.CreateInstance\s*\("), ("C#",), "method resolved by reflection"),
+]
+
+# Definitions of authorization helpers: (pattern capturing the name, languages, whether the name must look like a check)
+HELPER_DEFINITIONS = [
+    (re.compile(r"^\s*(?:async\s+)?def\s+([A-Za-z_]\w*)\s*\(", re.MULTILINE), ("Python",), True),
+    (re.compile(r"\bfunction\s+([A-Za-z_$][\w$]*)\s*\("), ("JavaScript/TypeScript", "PHP"), True),
+    (
+        re.compile(r"\b(?:const|let)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s*)?(?:\([^)\n]*\)|[A-Za-z_$][\w$]*)\s*=>"),
+        ("JavaScript/TypeScript",),
+        True,
+    ),
+    (re.compile(r"\bclass\s+(\w+Guard)\s+implements\s+CanActivate\b"), ("JavaScript/TypeScript",), False),
+    (re.compile(r"^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)\s*\(", re.MULTILINE), ("Go",), True),
+    (re.compile(r"@interface\s+(\w*(?:Auth|Role|Permission|Secured|Access|Scope)\w*)"), ("Java", "Kotlin"), False),
+    (re.compile(r"\bclass\s+(\w+Attribute)\s*:\s*[\w.]*(?:Authorize|ActionFilter)\w*"), ("C#",), False),
+    (re.compile(r"^\s*def\s+(?:self\.)?([a-z_]\w*[?!]?)", re.MULTILINE), ("Ruby",), True),
+]
+HELPER_VERB = re.compile(
+    r"^(?:require|check|ensure|verify|has|can|must|allow|authorize|is_?allowed|assert)|(?:required|only|guard|middleware)$",
+    re.IGNORECASE,
+)
+HELPER_SUBJECT = re.compile(r"role|perm|access|admin|scope|owner|auth", re.IGNORECASE)
+# Helpers the scanner's own patterns already recognize
+KNOWN_HELPERS = {"hasrole", "isauthorized", "authorize", "checkpermission", "canaccess", "requirerole"}
+
+# External policy engines; a file is only searched if it mentions one of the lowercase terms
+POLICY_ENGINES = [
+    ("Casbin", ("casbin", "enforce"), re.compile(r"\bcasbin\b|\bNewEnforcer\s*\(|\benforcer\.[Ee]nforce\s*\(", re.IGNORECASE)),
+    ("Open Policy Agent", ("/v1/data/", "open-policy-agent", "opa."), re.compile(r"/v1/data/|open-policy-agent|\bopa\.(?:eval|query|Decision)", re.IGNORECASE)),
+    ("Oso", ("oso",), re.compile(r"\bfrom\s+oso\s+import\b|\bOso\s*\(\s*\)|['\"]oso['\"]|\boso\.(?:authorize|is_allowed)\s*\(")),
+    ("Cerbos", ("cerbos",), re.compile(r"\bcerbos\b", re.IGNORECASE)),
+    ("OpenFGA", ("openfga",), re.compile(r"\bopenfga\b", re.IGNORECASE)),
+    ("SpiceDB", ("spicedb", "authzed"), re.compile(r"\b(?:spicedb|authzed)\b", re.IGNORECASE)),
+    ("Permit.io", ("permitio", "permit.check"), re.compile(r"\bpermitio\b|\bpermit\.check\s*\(", re.IGNORECASE)),
+]
+
+
+class DynamicPatternKind:
+    """Kinds of patterns static mining cannot fully resolve."""
+
+    DYNAMIC_ROUTE = "dynamic_route"  # Route path or method computed at runtime
+    REFLECTION = "reflection"  # Handlers reached through reflection or runtime dispatch
+    CUSTOM_AUTH_HELPER = "custom_auth_helper"  # Authorization helper defined in the repository
+    EXTERNAL_POLICY_ENGINE = "external_policy_engine"  # Decisions delegated to a policy engine
+
+
+@dataclass
+class DynamicPattern:
+    """A pattern that needs a custom rule or a code change to mine reliably."""
+
+    kind: str
+    file_path: str
+    line: int
+    snippet: str
+    detail: str
+
+
+def _percent(part: int, total: int) -> int | None:
+    """Whole percentage, or None for an empty total."""
+    return round(part / total * 100) if total else None
+
+
+def _pattern(kind: str, file_path: str, text: str, offset: int, detail: str) -> DynamicPattern:
+    """Pattern at a character offset of a file, with its line as the snippet."""
+    line = text.count("\n", 0, offset) + 1
+    line_start = text.rfind("\n", 0, offset) + 1
+    line_end = text.find("\n", offset)
+    snippet = text[line_start : line_end if line_end >= 0 else len(text)].strip()
+    return DynamicPattern(kind=kind, file_path=file_path, line=line, snippet=snippet[:200], detail=detail)
+
+
+def load_sources(root: Path) -> tuple[dict[str, str], Counter]:
+    """Read the supported sources of a clone and count files per unsupported language.
+
+    Args:
+        root: Repository clone root
+
+    Returns:
+        (relative path -> content of supported sources, unsupported language -> file count)
+    """
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    sources: dict[str, str] = {}
+    unsupported: Counter = Counter()
+    for path in sorted(root.rglob("*")):
+        relative = path.relative_to(root)
+        if SKIPPED_DIRECTORIES.intersection(relative.parts) or not path.is_file():
+            continue
+        if path.suffix in UNSUPPORTED_LANGUAGES:
+            unsupported[UNSUPPORTED_LANGUAGES[path.suffix]] += 1
+        elif path.suffix in SUPPORTED_LANGUAGES and path.stat().st_size <= max_bytes:
+            sources[relative.as_posix()] = path.read_text(encoding="utf-8", errors="replace")
+    return sources, unsupported
+
+
+def detect_frameworks(sources: dict[str, str]) -> list[dict]:
+    """Frameworks imported by the sources, with their support level and file counts."""
+    counts: Counter = Counter()
+    for text in sources.values():
+        for framework in FRAMEWORKS:
+            if framework.marker.search(text):
+                counts[framework.name] += 1
+    return [
+        {"name": f.name, "language": f.language, "support": f.support, "files": counts[f.name]}
+        for f in FRAMEWORKS
+        if counts[f.name]
+    ]
+
+
+def classify_registration(arg: str | None, annotation: bool = False) -> bool | None:
+    """Whether a route registration's path argument resolves to a literal endpoint.
+
+    Args:
+        arg: Path argument as written, None for an annotation without arguments
+        annotation: Whether the registration is an annotation or attribute,
+            whose arguments are always paths (possibly relative)
+
+    Returns:
+        True for a literal path (or a mapping inheriting its class path), False
+        for a path built at runtime, None if the call is not a route registration
+    """
+    arg = (arg or "").strip().lstrip("{[").strip()
+    if not arg:
+        return True
+    literal = STRING_LITERAL.match(arg)
+    if literal and not INTERPOLATED.search(arg):
+        value = literal.group(2)
+        return True if annotation or value.startswith("/") or not value else None
+    return False
+
+
+def find_routes(file_path: str, text: str) -> tuple[int, list[DynamicPattern]]:
+    """Count literal route registrations in a file and report computed ones.
+
+    Args:
+        file_path: Path of the file
+        text: File content
+
+    Returns:
+        (literal registrations, dynamic route patterns)
+    """
+    resolvable, dynamic = 0, []
+    for pattern in ROUTE_REGISTRATIONS:
+        for match in pattern.finditer(text):
+            receiver = match.groupdict().get("receiver")
+            arg = match.group("arg")
+            # Calls need a router-like receiver and a path: config.get(key) and router.get() are lookups
+            if receiver is not None and (not ROUTER_RECEIVER.match(receiver) or not arg.strip()):
+                continue
+            resolved = classify_registration(arg, annotation=receiver is None)
+            if resolved is None:
+                continue
+            if resolved:
+                resolvable += 1
+            else:
+                dynamic.append(
+                    _pattern(DynamicPatternKind.DYNAMIC_ROUTE, file_path, text, match.start(), f"route path {arg.strip()} is computed")
+                )
+    for match in COMPUTED_ROUTE.finditer(text):
+        dynamic.append(_pattern(DynamicPatternKind.DYNAMIC_ROUTE, file_path, text, match.start(), "route method is computed"))
+    return resolvable, dynamic
+
+
+def find_reflection(file_path: str, text: str) -> list[DynamicPattern]:
+    """Reflective lookups and runtime dispatch in a file."""
+    language = SUPPORTED_LANGUAGES.get(Path(file_path).suffix)
+    found = []
+    for pattern, languages, detail in REFLECTION:
+        if language not in languages:
+            continue
+        for match in pattern.finditer(text):
+            found.append(_pattern(DynamicPatternKind.REFLECTION, file_path, text, match.start(), detail))
+    return found
+
+
+def find_auth_helpers(file_path: str, text: str) -> list[DynamicPattern]:
+    """Authorization helpers a file defines that the scanner does not already recognize."""
+    language = SUPPORTED_LANGUAGES.get(Path(file_path).suffix)
+    found = []
+    for pattern, languages, needs_verb in HELPER_DEFINITIONS:
+        if language not in languages:
+            continue
+        for match in pattern.finditer(text):
+            name = match.group(1)
+            if name.lower().replace("_", "") in KNOWN_HELPERS or not HELPER_SUBJECT.search(name):
+                continue
+            if needs_verb and not HELPER_VERB.search(name):
+                continue
+            found.append(_pattern(DynamicPatternKind.CUSTOM_AUTH_HELPER, file_path, text, match.start(1), name))
+    return found
+
+
+def find_policy_engines(file_path: str, text: str) -> list[DynamicPattern]:
+    """First reference to each external policy engine in a file."""
+    lowered = text.lower()
+    found = []
+    for engine, terms, pattern in POLICY_ENGINES:
+        if not any(term in lowered for term in terms):
+            continue
+        match = pattern.search(text)
+        if match:
+            found.append(_pattern(DynamicPatternKind.EXTERNAL_POLICY_ENGINE, file_path, text, match.start(), engine))
+    return found
+
+
+def _helper_calls(sources: dict[str, str], helpers: list[DynamicPattern]) -> Counter:
+    """Call sites of each helper across the sources, excluding its definition."""
+    names = sorted({h.detail for h in helpers})
+    calls: Counter = Counter()
+    if not names:
+        return calls
+    call = re.compile(r"(?<![\w$])(" + "|".join(re.escape(n) for n in names) + r")\b")
+    for text in sources.values():
+        calls.update(m.group(1) for m in call.finditer(text))
+    for helper in helpers:
+        calls[helper.detail] -= 1
+    return calls
+
+
+def _plural(count: int, noun: str) -> str:
+    """Count with a noun pluralized by appending "s"."""
+    return f"{count} {noun}" if count == 1 else f"{count} {noun}s"
+
+
+def _grade(score: int) -> str:
+    """Letter grade of a score."""
+    return next((grade for threshold, grade in GRADES if score >= threshold), "F")
+
+
+def score_components(
+    source_files: int,
+    unsupported_files: int,
+    frameworks: list[dict],
+    resolvable: int,
+    dynamic_routes: int,
+    pattern_count: int,
+) -> dict[str, float | None]:
+    """Score components from 0 to 1; None for components that do not apply."""
+    total_files = source_files + unsupported_files
+    framework_files = sum(f["files"] for f in frameworks)
+    if framework_files:
+        framework_support = sum(SUPPORT_WEIGHTS[f["support"]] * f["files"] for f in frameworks) / framework_files
+    else:
+        framework_support = UNRECOGNIZED_FRAMEWORK_WEIGHT if source_files else 0.0
+    registrations = resolvable + dynamic_routes
+    density = pattern_count / source_files if source_files else 0.0
+    return {
+        "language_coverage": source_files / total_files if total_files else 0.0,
+        "framework_support": framework_support,
+        "route_resolution": resolvable / registrations if registrations else None,
+        "dynamic_patterns": max(0.0, 1 - density / MAX_PATTERN_DENSITY) if source_files else 0.0,
+    }
+
+
+def readiness_score(components: dict[str, float | None]) -> int:
+    """Weighted 0-100 score over the components that apply."""
+    applicable = {k: v for k, v in components.items() if v is not None}
+    weight = sum(COMPONENT_WEIGHTS[k] for k in applicable)
+    if not weight:
+        return 0
+    return round(sum(COMPONENT_WEIGHTS[k] * v for k, v in applicable.items()) / weight * 100)
+
+
+def recommendations(report: dict) -> list[dict]:
+    """What to fix for higher-fidelity results, most impactful first."""
+    items = []
+    source_files = report["source_files"]
+    if not source_files:
+        items.append(
+            {"priority": "high", "area": "languages", "message": "No source files in a supported language were found"}
+        )
+    for language in report["languages"]:
+        if not language["supported"]:
+            share = language["files"] / max(source_files + language["files"], 1)
+            items.append(
+                {
+                    "priority": "high" if share > 0.2 else "medium",
+                    "area": "languages",
+                    "message": f"{_plural(language['files'], language['language'] + ' file')} not scanned, so authorization there is not mined",
+                }
+            )
+    patterns = report["dynamic_patterns"]
+    engines = sorted({p["detail"] for p in patterns["items"] if p["kind"] == DynamicPatternKind.EXTERNAL_POLICY_ENGINE})
+    if engines:
+        items.append(
+            {
+                "priority": "high",
+                "area": "policy_engines",
+                "message": f"Authorization is delegated to {', '.join(engines)}; import its policies, since code scanning only sees the call sites",
+            }
+        )
+    routes = report["routes"]
+    if routes["dynamic"]:
+        first = next(p for p in patterns["items"] if p["kind"] == DynamicPatternKind.DYNAMIC_ROUTE)
+        items.append(
+            {
+                "priority": "high" if (routes["resolvable_percent"] or 0) < 60 else "medium",
+                "area": "routes",
+                "message": (
+                    f"{_plural(routes['dynamic'], 'route registration')} computing the path or method at runtime "
+                    f"(first at {first['file_path']}:{first['line']}); use literal paths or add custom rules mapping them to endpoints"
+                ),
+            }
+        )
+    if source_files and not report["frameworks"]:
+        items.append(
+            {
+                "priority": "medium",
+                "area": "frameworks",
+                "message": "No supported web framework was detected; routes cannot be inventoried, so coverage falls back to mined endpoints",
+            }
+        )
+    for framework in report["frameworks"]:
+        if framework["support"] == FrameworkSupport.SCANNER:
+            items.append(
+                {
+                    "priority": "medium",
+                    "area": "frameworks",
+                    "message": f"{framework['name']} routes are not inventoried ({framework['files']} files); their coverage relies on mined policies only",
+                }
+            )
+    helpers = [p for p in patterns["items"] if p["kind"] == DynamicPatternKind.CUSTOM_AUTH_HELPER]
+    if helpers:
+        names = list(dict.fromkeys(h["detail"] for h in helpers))
+        shown = ", ".join(names[:5]) + (f" and {len(names) - 5} more" if len(names) > 5 else "")
+        items.append(
+            {
+                "priority": "medium",
+                "area": "custom_rules",
+                "message": f"Add custom rules for authorization helpers defined in the repository so their call sites are mined deterministically: {shown}",
+            }
+        )
+    if patterns["counts"].get(DynamicPatternKind.REFLECTION):
+        items.append(
+            {
+                "priority": "low",
+                "area": "reflection",
+                "message": f"{_plural(patterns['counts'][DynamicPatternKind.REFLECTION], 'reflective lookup')} may hide handlers and checks from static analysis",
+            }
+        )
+    items.sort(key=lambda item: PRIORITY_ORDER[item["priority"]])
+    return items
+
+
+def assess_clone(root: Path) -> dict:
+    """Assess how analyzable a repository clone is.
+
+    Args:
+        root: Repository clone root
+
+    Returns:
+        Score, grade, readiness, score components, languages, frameworks,
+        route resolution, dynamic patterns, and recommendations
+    """
+    sources, unsupported = load_sources(root)
+    languages = Counter(SUPPORTED_LANGUAGES[Path(p).suffix] for p in sources)
+    frameworks = detect_frameworks(sources)
+
+    resolvable = 0
+    patterns: list[DynamicPattern] = []
+    for file_path, text in sources.items():
+        if TEST_PATH.search(file_path):
+            continue
+        patterns.extend(find_auth_helpers(file_path, text))
+        patterns.extend(find_policy_engines(file_path, text))
+        patterns.extend(find_reflection(file_path, text))
+        # Only backend files register routes; frontend API calls look the same
+        if any(f.marker.search(text) for f in FRAMEWORKS):
+            literal, dynamic = find_routes(file_path, text)
+            resolvable += literal
+            patterns.extend(dynamic)
+
+    # Helpers nobody calls do not affect mining
+    helpers = [p for p in patterns if p.kind == DynamicPatternKind.CUSTOM_AUTH_HELPER]
+    calls = _helper_calls(sources, helpers)
+    patterns = [p for p in patterns if p.kind != DynamicPatternKind.CUSTOM_AUTH_HELPER or calls[p.detail] > 0]
+
+    counts = Counter(p.kind for p in patterns)
+    dynamic_routes = counts[DynamicPatternKind.DYNAMIC_ROUTE]
+    components = score_components(
+        len(sources), sum(unsupported.values()), frameworks, resolvable, dynamic_routes, len(patterns)
+    )
+    score = readiness_score(components)
+    listed: Counter = Counter()
+    items = []
+    for pattern in patterns:
+        listed[pattern.kind] += 1
+        if listed[pattern.kind] <= MAX_LISTED_PATTERNS:
+            item = asdict(pattern)
+            if pattern.kind == DynamicPatternKind.CUSTOM_AUTH_HELPER:
+                item["call_sites"] = calls[pattern.detail]
+            items.append(item)
+
+    report = {
+        "score": score,
+        "grade": _grade(score),
+        "ready": score >= READY_SCORE,
+        "components": {k: None if v is None else round(v * 100) for k, v in components.items()},
+        "source_files": len(sources),
+        "languages": [
+            *({"language": name, "files": n, "supported": True} for name, n in languages.most_common()),
+            *({"language": name, "files": n, "supported": False} for name, n in unsupported.most_common()),
+        ],
+        "frameworks": frameworks,
+        "routes": {
+            "registrations": resolvable + dynamic_routes,
+            "resolvable": resolvable,
+            "dynamic": dynamic_routes,
+            "resolvable_percent": _percent(resolvable, resolvable + dynamic_routes),
+        },
+        "dynamic_patterns": {"counts": dict(counts), "items": items},
+    }
+    report["recommendations"] = recommendations(report)
+    return report
+
+
+def assess_checkout(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the assessment of a clone."""
+    yield assess_clone(Path(root))
+
+
+class ReadinessService:
+    """Assesses repository clones for analyzability and records the results."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Load a repository of the tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repo = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repo is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repo
+
+    def assess(
+        self, repository_id: int, scan_id: int | None = None, assessed_by: str | None = None
+    ) -> ReadinessAssessment:
+        """Assess a repository's clone and record the result.
+
+        Args:
+            repository_id: Repository ID
+            scan_id: Scan the assessment precedes, when run by the scanner
+            assessed_by: User who requested the assessment
+
+        Returns:
+            The recorded assessment
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        (report,) = run_pass(root, assess_checkout)
+        assessment = ReadinessAssessment(
+            tenant_id=self.tenant_id,
+            repository_id=repo.id,
+            scan_id=scan_id,
+            score=report["score"],
+            grade=report["grade"],
+            ready=report["ready"],
+            route_resolution_percent=report["routes"]["resolvable_percent"],
+            report=report,
+            assessed_by=assessed_by,
+        )
+        self.db.add(assessment)
+        self.db.commit()
+        self.db.refresh(assessment)
+        logger.info(
+            "readiness_assessed",
+            repository_id=repo.id,
+            scan_id=scan_id,
+            score=report["score"],
+            dynamic_patterns=sum(report["dynamic_patterns"]["counts"].values()),
+        )
+        return assessment
+
+    def history(self, repository_id: int) -> list[ReadinessAssessment]:
+        """Assessments of a repository, newest first.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        self.get_repository(repository_id)
+        return (
+            self._query(ReadinessAssessment)
+            .filter(ReadinessAssessment.repository_id == repository_id)
+            .order_by(ReadinessAssessment.id.desc())
+            .all()
+        )
+
+    def latest(self, repository_id: int) -> ReadinessAssessment:
+        """Most recent assessment of a repository.
+
+        Raises:
+            ValueError: If the repository does not exist or was never assessed
+        """
+        assessments = self.history(repository_id)
+        if not assessments:
+            raise ValueError(f"Repository {repository_id} has not been assessed yet")
+        return assessments[0]
+
+    def workspace(self) -> list[dict]:
+        """Latest assessment of every repository, least ready first.
+
+        Returns:
+            Per repository: its name and latest score, grade, and readiness,
+            or None for repositories never assessed
+        """
+        latest: dict[int, ReadinessAssessment] = {}
+        for assessment in self._query(ReadinessAssessment).order_by(ReadinessAssessment.id.asc()).all():
+            latest[assessment.repository_id] = assessment
+        rows = []
+        for repo in self._query(Repository).order_by(Repository.id.asc()).all():
+            assessment = latest.get(repo.id)
+            rows.append(
+                {
+                    "repository_id": repo.id,
+                    "repository_name": repo.name,
+                    "assessment_id": assessment.id if assessment else None,
+                    "score": assessment.score if assessment else None,
+                    "grade": assessment.grade if assessment else None,
+                    "ready": assessment.ready if assessment else None,
+                    "assessed_at": assessment.created_at if assessment else None,
+                }
+            )
+        rows.sort(key=lambda r: (r["score"] is not None, r["score"] or 0))
+        return rows
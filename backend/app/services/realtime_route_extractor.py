@@ -0,0 +1,473 @@
+"""Extract authorization from WebSocket, socket.io, STOMP, and SSE endpoints.
+
+Realtime endpoints fall outside the request/response route model: a
+WebSocket or server-sent event stream is authorized once, at the HTTP
+upgrade or stream request, and socket.io events and STOMP destinations are
+then authorized (or not) per message, inside the connection. These
+extractors find gorilla/websocket and coder/websocket upgrade handlers,
+socket.io connection middleware and event handlers, Spring STOMP endpoints,
+message security rules, and @MessageMapping methods, and SSE streams in
+Express, Spring, FastAPI/Flask, and Go. Each handshake, stream, and message
+handler becomes a ConfigFinding carrying the checks found at that level,
+without LLM calls.
+"""
+
+import re
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.cors_csrf_service import scope_matches
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import line_of, line_span
+from app.services.node_route_extractor import JS_SUFFIXES, _source
+
+# Snippets show at most this many lines of a handler
+MAX_SNIPPET_LINES = 40
+
+# Lines above an SSE response searched for the route mapping it belongs to
+MAPPING_LOOKBACK_LINES = 12
+
+REALTIME_SUFFIXES = JS_SUFFIXES + (".go", ".java", ".kt", ".py")
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(r"websocket|socket\.io|stomp|MessageMapping|event-stream|SseEmitter|ServerSentEvent|EventSource", re.IGNORECASE)
+
+ANONYMOUS = "Anonymous"
+AUTHENTICATED = "Authenticated users"
+
+# Inbound message and subscription actions; both map to the connection's GET in the HTTP model
+SEND = "SEND"
+SUBSCRIBE = "SUBSCRIBE"
+
+
+class RealtimeRouteKind:
+    """Kinds of realtime endpoint findings."""
+
+    WEBSOCKET_UPGRADE = "websocket_upgrade"
+    WEBSOCKET_MESSAGE = "websocket_message"
+    SOCKETIO_EVENT = "socketio_event"
+    STOMP_DESTINATION = "stomp_destination"
+    SSE_STREAM = "sse_stream"
+
+
+AUTH_CHECK = re.compile(
+    r"\b(?:authenticate\w*|isAuthenticated|requireAuth\w*|ensureAuthenticated|authRequired|verify(?:Token|Jwt|JWT)|"
+    r"jwt\.(?:verify|Parse\w*)|ParseWithClaims|validateToken|getUserPrincipal|Principal|currentUser|current_user|"
+    r"get_current_user|login_required|Unauthorized|StatusUnauthorized|UNAUTHORIZED)\b|handshake\.auth|"
+    r"\bAuthorization\b|\btoken\b|\bauth\w*Middleware\b|socket\.(?:data\.)?user\b"
+)
+ROLE_CHECKS = [
+    re.compile(r"""has(?:Any)?(?:Role|Authority)\(\s*['"](?:ROLE_)?([\w-]+)['"]"""),
+    re.compile(r"""@(?:Secured|RolesAllowed)\(\s*\{?\s*"(?:ROLE_)?([\w-]+)\""""),
+    re.compile(r"""\brole\w*\s*[!=]==?\s*['"]([\w-]+)['"]|['"]([\w-]+)['"]\s*[!=]==?\s*[\w.]*role\b""", re.IGNORECASE),
+    re.compile(r"""\broles?\.(?:includes|contains|indexOf|has)\(\s*['"]([\w-]+)['"]"""),
+    re.compile(r"""\b(?:require|check|has)_?[Rr]oles?\(\s*['"]([\w-]+)['"]"""),
+    re.compile(r"""\.(?:Is|is)(Admin|Moderator|Staff|Owner|Operator|Superuser)\b"""),
+]
+
+
+def checked_roles(text: str) -> list[str]:
+    """Role names a piece of code checks, in order of appearance."""
+    found = []
+    for pattern in ROLE_CHECKS:
+        for match in pattern.finditer(text):
+            role = next(g for g in match.groups() if g)
+            found.append((match.start(), role.upper() if role.islower() and len(role) > 2 else role))
+    roles = []
+    for _, role in sorted(found):
+        if role not in roles:
+            roles.append(role)
+    return roles
+
+
+def _subject(text: str) -> str | None:
+    """Subject a handler's checks enforce, or None when it checks nothing."""
+    roles = checked_roles(text)
+    if roles:
+        return " or ".join(roles)
+    if AUTH_CHECK.search(text):
+        return AUTHENTICATED
+    return None
+
+
+def _finding(
+    kind: str,
+    file_path: str,
+    text: str,
+    start: int,
+    end: int,
+    subject: str | None,
+    resource: str,
+    action: str,
+    description: str,
+    inherited: str | None = None,
+) -> ConfigFinding:
+    """Build a finding for a handler span; unchecked handlers inherit the connection's subject."""
+    line_start = line_of(text, start)
+    line_end = min(line_of(text, end), line_start + MAX_SNIPPET_LINES - 1)
+    if subject is None and inherited is not None:
+        subject, description = inherited, f"{description}; no per-message check, relies on the connection's"
+    subject = subject or ANONYMOUS
+    return ConfigFinding(
+        kind=kind,
+        file_path=file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(text, line_start, line_end),
+        subject=subject,
+        resource=resource,
+        action=action,
+        description=description,
+        disables_auth=subject == ANONYMOUS,
+    )
+
+
+def _group(text: str, opening: int) -> int:
+    """Offset just past the bracket group opened at opening, ignoring strings and comments."""
+    return _source(text).pairs.get(opening, len(text))
+
+
+# Go: gorilla/websocket, coder/nhooyr websocket, SSE handlers
+GO_HANDLER = re.compile(
+    r"func\s+(?:\(\s*\w+\s+\*?\w+\s*\)\s*)?(\w+)\s*\([^)]*(?:http\.ResponseWriter|\*gin\.Context|echo\.Context)[^)]*\)\s*(?:error\s*)?\{"
+)
+GO_INLINE_HANDLER = re.compile(
+    r"\.(?:HandleFunc|Handle|GET|Get|Any)\(\s*\"(/[^\"]*)\"\s*,\s*([^\n]*?)func\s*\([^)]*(?:http\.ResponseWriter|\*gin\.Context|echo\.Context)[^)]*\)\s*(?:error\s*)?\{"
+)
+GO_UPGRADE = re.compile(r"\.Upgrade\(\s*[\w.]+\s*,|websocket\.(?:Accept|Upgrade)\(")
+GO_READ = re.compile(r"\.ReadMessage\(\)|\.ReadJSON\(|wsjson\.Read\(|\.Read\(\s*ctx")
+GO_CASE = re.compile(r"\bcase\s+\"([\w.:/-]+)\"\s*:")
+GO_NEXT_CASE = re.compile(r"\n\s*(?:case\s|default\s*:)")
+ANY_ORIGIN = re.compile(r"CheckOrigin\s*:\s*func\s*\([^)]*\)\s*bool\s*\{\s*return\s+true|InsecureSkipVerify\s*:\s*true")
+
+
+def _go_routes(files: dict[str, str], name: str) -> list[tuple[str, str]]:
+    """(path, wrapping middleware) of each registration of a named Go handler."""
+    pattern = re.compile(rf"\.(?:HandleFunc|Handle|GET|Get|Any)\(\s*\"(/[^\"]*)\"\s*,\s*([^\n]*?)(?<![\w.])(?:\w+\.)?{re.escape(name)}\b")
+    return [(m.group(1), m.group(2)) for text in files.values() for m in pattern.finditer(text)]
+
+
+def _go_messages(file_path: str, text: str, body_start: int, body_end: int, resource: str, inherited: str) -> list[ConfigFinding]:
+    """Per-message-type handlers in a WebSocket read loop's switch."""
+    read = GO_READ.search(text, body_start, body_end)
+    if not read:
+        return []
+    findings = []
+    for case in GO_CASE.finditer(text, read.end(), body_end):
+        following = GO_NEXT_CASE.search(text, case.end(), body_end)
+        end = following.start() if following else body_end
+        findings.append(
+            _finding(
+                RealtimeRouteKind.WEBSOCKET_MESSAGE, file_path, text, case.start(), end, _subject(text[case.end() : end]),
+                f"{resource.rstrip('/')}/{case.group(1)}", SEND, f"WebSocket message type {case.group(1)!r} on {resource}",
+                inherited,
+            )  # fmt: skip
+        )
+    return findings
+
+
+def extract_go_realtime(files: dict[str, str]) -> list[ConfigFinding]:
+    """Go WebSocket upgrade handlers, their message types, and SSE handlers."""
+    findings = []
+    for file_path, text in files.items():
+        if not file_path.endswith(".go"):
+            continue
+        handlers = []
+        for match in GO_HANDLER.finditer(text):
+            for path, wrapper in _go_routes(files, match.group(1)):
+                handlers.append((match, path, wrapper))
+        for match in GO_INLINE_HANDLER.finditer(text):
+            handlers.append((match, match.group(1), match.group(2)))
+        for match, path, wrapper in handlers:
+            body_start, body_end = match.end() - 1, _group(text, match.end() - 1)
+            upgrade = GO_UPGRADE.search(text, body_start, body_end)
+            if upgrade:
+                # Checks after the upgrade no longer affect the handshake
+                subject = _subject(wrapper + text[body_start : upgrade.start()])
+                description = f"WebSocket upgrade handler for {path}"
+                if ANY_ORIGIN.search(text):
+                    description += "; accepts any Origin (cross-site WebSocket hijacking)"
+                finding = _finding(RealtimeRouteKind.WEBSOCKET_UPGRADE, file_path, text, match.start(), body_end, subject, path, "GET", description)
+                findings.append(finding)
+                findings.extend(_go_messages(file_path, text, upgrade.end(), body_end, path, finding.subject))
+            elif "text/event-stream" in text[body_start:body_end]:
+                findings.append(
+                    _finding(
+                        RealtimeRouteKind.SSE_STREAM, file_path, text, match.start(), body_end,
+                        _subject(wrapper + text[body_start:body_end]), path, "GET", f"Server-sent event stream {path}",
+                    )  # fmt: skip
+                )
+    return findings
+
+
+# socket.io
+SOCKETIO_MARKER = re.compile(r"socket\.io")
+SIO_NAMESPACE_VAR = re.compile(r"\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*[A-Za-z_$][\w$]*\.of\(\s*['\"]([^'\"]+)['\"]\s*\)")
+SIO_TARGET = r"(?<![\w$.])([A-Za-z_$][\w$]*)(?:\.of\(\s*['\"]([^'\"]+)['\"]\s*\))?"
+SIO_USE = re.compile(SIO_TARGET + r"\.use\(")
+SIO_CONNECTION = re.compile(SIO_TARGET + r"\.on\(\s*['\"]connect(?:ion)?['\"]\s*,")
+SIO_EVENT = re.compile(r"(?<![\w$.])([A-Za-z_$][\w$]*)\.on\(\s*['\"]([^'\"]+)['\"]\s*,\s*")
+SIO_LIFECYCLE_EVENTS = {"connect", "connection", "disconnect", "disconnecting", "error", "connect_error"}
+
+
+def js_definition(text: str, name: str) -> str:
+    """Text of a named function or arrow function defined in a file, if any."""
+    match = re.search(rf"(?:function\s+{re.escape(name)}\s*\(|\b(?:const|let|var)\s+{re.escape(name)}\s*=)", text)
+    if not match:
+        return ""
+    brace = text.find("{", match.end())
+    return text[match.start() : _group(text, brace)] if brace >= 0 else ""
+
+
+def _namespace(receiver: str, inline: str | None, namespaces: dict[str, str]) -> str:
+    """socket.io namespace of a server, namespace variable, or inline .of() call."""
+    return inline or namespaces.get(receiver, "/")
+
+
+def _socketio_path(namespace: str) -> str:
+    """Handshake path of a socket.io namespace (namespaces share the /socket.io transport)."""
+    return "/socket.io" if namespace == "/" else f"/socket.io{namespace}"
+
+
+def extract_socketio(files: dict[str, str]) -> list[ConfigFinding]:
+    """socket.io connections per namespace and the events handled on them."""
+    findings = []
+    for file_path, text in files.items():
+        if PurePosixPath(file_path).suffix not in JS_SUFFIXES or not SOCKETIO_MARKER.search(text):
+            continue
+        namespaces = {m.group(1): m.group(2) for m in SIO_NAMESPACE_VAR.finditer(text)}
+        middleware: dict[str, str] = {}
+        for use in SIO_USE.finditer(text):
+            argument = text[use.end() : _group(text, use.end() - 1) - 1].strip()
+            body = js_definition(text, argument) if re.fullmatch(r"[A-Za-z_$][\w$]*", argument) else argument
+            namespace = _namespace(use.group(1), use.group(2), namespaces)
+            middleware[namespace] = middleware.get(namespace, "") + argument + body
+        for connection in SIO_CONNECTION.finditer(text):
+            namespace = _namespace(connection.group(1), connection.group(2), namespaces)
+            path = _socketio_path(namespace)
+            end = _group(text, text.rfind(".on(", connection.start(), connection.end()) + 3)
+            events = [e for e in SIO_EVENT.finditer(text, connection.end(), end) if e.group(2) not in SIO_LIFECYCLE_EVENTS]
+            preamble_end = events[0].start() if events else end
+            guards = middleware.get(namespace, "") + (middleware.get("/", "") if namespace != "/" else "")
+            subject = _subject(guards + text[connection.end() : preamble_end])
+            connection_finding = _finding(
+                RealtimeRouteKind.WEBSOCKET_UPGRADE, file_path, text, connection.start(), preamble_end, subject, path, "GET",
+                f"socket.io connection to namespace {namespace}",
+            )  # fmt: skip
+            findings.append(connection_finding)
+            for event in events:
+                event_end = _group(text, event.start() + event.group(0).index("("))
+                handler = text[event.end() : event_end]
+                if re.fullmatch(r"\s*[A-Za-z_$][\w$.]*\s*\)?\s*;?\s*", handler):
+                    handler = js_definition(text, handler.strip(" );\n").split(".")[-1])
+                findings.append(
+                    _finding(
+                        RealtimeRouteKind.SOCKETIO_EVENT, file_path, text, event.start(), event_end, _subject(handler),
+                        f"{path}/{event.group(2)}", SEND, f"socket.io event {event.group(2)!r} on namespace {namespace}",
+                        connection_finding.subject,
+                    )  # fmt: skip
+                )
+    return findings
+
+
+# Spring STOMP
+STOMP_ENDPOINT = re.compile(r"\.addEndpoint\(\s*\"([^\"]+)\"")
+STOMP_APP_PREFIX = re.compile(r"setApplicationDestinationPrefixes\(\s*\"([^\"]+)\"")
+STOMP_INTERCEPTORS = re.compile(r"\.(?:addInterceptors|setHandshakeHandler)\(([^;]*)")
+STOMP_INBOUND_INTERCEPTOR = re.compile(r"configureClientInboundChannel[\s\S]{0,400}?interceptors\(([^;]*)")
+MESSAGE_RULE = re.compile(
+    r"\.(simpDestMatchers|simpSubscribeDestMatchers|simpMessageDestMatchers|anyMessage|nullDestMatcher)\(((?:\s*\"[^\"]*\"\s*,?)*)\)\s*"
+    r"\.(hasRole|hasAnyRole|hasAuthority|hasAnyAuthority|authenticated|permitAll|denyAll)\(([^)]*)\)"
+)
+MESSAGE_MAPPING = re.compile(r"@(Message|Subscribe)Mapping\(\s*(?:value\s*=\s*)?\{?\s*\"([^\"]*)\"")
+METHOD_SIGNATURE = re.compile(r"\b(?:public|private|protected|fun)\b[^;{=]*?\(")
+CLASS_DECLARATION = re.compile(r"\bclass\s+\w+")
+HTTP_SECURITY = re.compile(r"SecurityFilterChain|WebSecurityConfigurerAdapter|@EnableWebSecurity")
+PERMIT_ALL_PATHS = re.compile(r"requestMatchers\(([^)]*)\)\s*\.permitAll\(\)|antMatchers\(([^)]*)\)\s*\.permitAll\(\)")
+QUOTED = re.compile(r"\"([^\"]*)\"")
+
+
+def _rule_subject(method: str, arguments: str) -> str | None:
+    """Subject a message security rule requires; None for denyAll."""
+    if method == "permitAll":
+        return ANONYMOUS
+    if method == "authenticated":
+        return AUTHENTICATED
+    if method == "denyAll":
+        return None
+    roles = [r.removeprefix("ROLE_") for r in re.findall(r"['\"]([^'\"]+)['\"]", arguments)]
+    return " or ".join(roles) if roles else AUTHENTICATED
+
+
+def extract_stomp(files: dict[str, str]) -> list[ConfigFinding]:
+    """Spring STOMP endpoints, message security rules, and @MessageMapping handlers."""
+    jvm = {p: t for p, t in files.items() if PurePosixPath(p).suffix in (".java", ".kt")}
+    joined = "\n".join(jvm.values())
+    prefix_match = STOMP_APP_PREFIX.search(joined)
+    app_prefix = prefix_match.group(1).rstrip("/") if prefix_match else ""
+    permitted = [p for m in PERMIT_ALL_PATHS.finditer(joined) for p in QUOTED.findall(m.group(1) or m.group(2))]
+    inbound = STOMP_INBOUND_INTERCEPTOR.search(joined)
+    if not HTTP_SECURITY.search(joined) and not inbound:
+        connection_subject = ANONYMOUS
+    else:
+        connection_subject = AUTHENTICATED
+    rules: list[tuple[str, str, str | None]] = []  # (matcher, destination pattern, subject)
+    for match in MESSAGE_RULE.finditer(joined):
+        subject = _rule_subject(match.group(3), match.group(4))
+        for pattern in QUOTED.findall(match.group(2)) or [""]:
+            rules.append((match.group(1), pattern, subject))
+
+    findings = []
+    for file_path, text in jvm.items():
+        for match in STOMP_ENDPOINT.finditer(text):
+            path = match.group(1)
+            interceptors = STOMP_INTERCEPTORS.search(text, match.end())
+            guard = interceptors.group(1) if interceptors and "addEndpoint" not in interceptors.group(1) else ""
+            subject = _subject(guard) or connection_subject
+            if any(scope_matches(p, path) for p in permitted):
+                subject = _subject(guard) or ANONYMOUS
+            line_end = text.find(";", match.end())
+            findings.append(
+                _finding(
+                    RealtimeRouteKind.WEBSOCKET_UPGRADE, file_path, text, match.start(), line_end if line_end > 0 else match.end(),
+                    subject, path, "GET", f"STOMP WebSocket endpoint {path}",
+                )  # fmt: skip
+            )
+
+        class_match = CLASS_DECLARATION.search(text)
+        class_prefix = ""
+        for match in MESSAGE_MAPPING.finditer(text):
+            if class_match and match.start() < class_match.start():
+                class_prefix = match.group(2).rstrip("/")
+                continue
+            signature = METHOD_SIGNATURE.search(text, match.end())
+            if not signature:
+                continue
+            # Annotations between the previous member and this method
+            annotations_start = max(text.rfind("}", 0, match.start()), text.rfind(";", 0, match.start()), 0)
+            annotations = text[annotations_start : signature.start()]
+            destination = f"{app_prefix}{class_prefix}/{match.group(2).lstrip('/')}"
+            action = SUBSCRIBE if match.group(1) == "Subscribe" else SEND
+            subject = _subject(annotations) if checked_roles(annotations) or "@PreAuthorize" in annotations else None
+            source = "method annotation" if subject else "no message security rule"
+            if subject is None:
+                matcher = "simpSubscribeDestMatchers" if action == SUBSCRIBE else "simpDestMatchers"
+                for kind, pattern, rule_subject in rules:
+                    if kind == "anyMessage" or (kind in (matcher, "simpMessageDestMatchers") and scope_matches(pattern, destination)):
+                        subject = rule_subject or "Nobody (denyAll)"
+                        source = f"message security {kind}({repr(pattern) if pattern else ''})"
+                        break
+            brace = text.find("{", signature.end())
+            end = _group(text, brace) if brace >= 0 else signature.end()
+            findings.append(
+                _finding(
+                    RealtimeRouteKind.STOMP_DESTINATION, file_path, text, match.start(), end, subject, destination, action,
+                    f"STOMP {'subscription' if action == SUBSCRIBE else 'message'} destination {destination} ({source})",
+                    connection_subject,
+                )  # fmt: skip
+            )
+    return findings
+
+
+# Server-sent events in Express, Spring, FastAPI/Flask (Go streams are handled with WebSockets)
+SSE_MARKER = re.compile(r"text/event-stream|TEXT_EVENT_STREAM|SseEmitter|ServerSentEvent|EventSourceResponse")
+EXPRESS_ROUTE = re.compile(r"(?<![\w$.])[A-Za-z_$][\w$]*\.(get|post|all)\(\s*['\"`](/[^'\"`]*)['\"`]\s*,")
+JAVA_MAPPING = re.compile(r"@(?:Get|Post|Request)Mapping\b[^\n]*")
+REQUEST_MAPPING = re.compile(r"@RequestMapping\(\s*(?:value\s*=\s*|path\s*=\s*)?\"(/[^\"]*)\"")
+PY_ROUTE = re.compile(r"^[ \t]*@\w+\.(get|post|route)\(\s*['\"](/[^'\"]*)['\"]", re.MULTILINE)
+PY_DEF = re.compile(r"^(?:async\s+)?def\s", re.MULTILINE)
+
+
+def extract_sse(files: dict[str, str]) -> list[ConfigFinding]:
+    """Server-sent event streams and the checks on the requests that open them."""
+    findings = []
+    for file_path, text in files.items():
+        if not SSE_MARKER.search(text):
+            continue
+        suffix = PurePosixPath(file_path).suffix
+        if suffix in JS_SUFFIXES:
+            for route in EXPRESS_ROUTE.finditer(text):
+                end = _group(text, route.start() + route.group(0).index("("))
+                call = text[route.end() : end]
+                if "text/event-stream" not in call:
+                    continue
+                method = "GET" if route.group(1) == "all" else route.group(1).upper()
+                findings.append(
+                    _finding(
+                        RealtimeRouteKind.SSE_STREAM, file_path, text, route.start(), end, _subject(call), route.group(2), method,
+                        f"Server-sent event stream {route.group(2)}",
+                    )  # fmt: skip
+                )
+        elif suffix in (".java", ".kt"):
+            seen = set()
+            for marker in SSE_MARKER.finditer(text):
+                window_start = max(0, marker.start())
+                for _ in range(MAPPING_LOOKBACK_LINES):
+                    window_start = max(text.rfind("\n", 0, window_start - 1) + 1, 0) if window_start else 0
+                mappings = list(JAVA_MAPPING.finditer(text, window_start, marker.start()))
+                if not mappings:
+                    continue
+                mapping = mappings[-1]
+                routes = EndpointMappingService.find_routes(mapping.group(0))
+                routes += [("GET", m.group(1)) for m in REQUEST_MAPPING.finditer(mapping.group(0))]
+                if not routes or routes[0][1] in seen:
+                    continue
+                method, path = routes[0]
+                seen.add(path)
+                # The method's own annotations start after the previous member
+                start = max(text.rfind("}", 0, mapping.start()), text.rfind(";", 0, mapping.start())) + 1
+                start = mapping.start() - len(text[start : mapping.start()].lstrip())
+                brace = text.find("{", marker.end())
+                end = _group(text, brace) if brace >= 0 else marker.end()
+                findings.append(
+                    _finding(
+                        RealtimeRouteKind.SSE_STREAM, file_path, text, start, end, _subject(text[start:end]), path, method,
+                        f"Server-sent event stream {path}",
+                    )  # fmt: skip
+                )
+        elif suffix == ".py":
+            for route in PY_ROUTE.finditer(text):
+                definition = PY_DEF.search(text, route.end())
+                if not definition:
+                    continue
+                following = PY_DEF.search(text, definition.end())
+                decorators = text.rfind("\n\n", 0, route.start())
+                start = decorators + 2 if decorators >= 0 else 0
+                end = text.rfind("\n@", definition.end(), following.start()) if following else -1
+                end = end if end > 0 else (following.start() if following else len(text))
+                body = text[start:end]
+                if not SSE_MARKER.search(body):
+                    continue
+                method = "GET" if route.group(1) == "route" else route.group(1).upper()
+                findings.append(
+                    _finding(
+                        RealtimeRouteKind.SSE_STREAM, file_path, text, route.start(), end, _subject(body), route.group(2), method,
+                        f"Server-sent event stream {route.group(2)}",
+                    )  # fmt: skip
+                )
+    return findings
+
+
+def extract_realtime_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract WebSocket, socket.io, STOMP, and SSE authorization from an application's files.
+
+    Args:
+        files: Relative path -> content of the application's sources
+
+    Returns:
+        Handshake, stream, and per-message findings across all files
+    """
+    relevant = {p: t for p, t in files.items() if is_realtime_source(p) and PREFILTER.search(t)}
+    if not relevant:
+        return []
+    # Named Go handlers may be registered in files that do not mention websockets
+    go = {p: t for p, t in files.items() if p.endswith(".go")} if any(p.endswith(".go") for p in relevant) else {}
+    go_findings = [f for f in extract_go_realtime(go) if f.file_path in relevant]
+    # Message security and HTTP security usually live in configuration classes of their own
+    stomp_findings = [f for f in extract_stomp(files) if f.file_path in relevant]
+    return go_findings + extract_socketio(relevant) + stomp_findings + extract_sse(relevant)
+
+
+def is_realtime_source(file_path: str) -> bool:
+    """Check whether a path may declare realtime endpoints."""
+    return PurePosixPath(file_path).suffix in REALTIME_SUFFIXES and not file_path.endswith(".d.ts")
@@ -0,0 +1,170 @@
+"""Redaction service for content leaving the system.
+
+Evidence snippets and prompts built from them can carry credentials, staff
+email addresses, and internal hostnames. Every egress path (LLM calls,
+downloadable exports) passes its text through this service first. Detected
+secrets reuse the secret scanner's patterns; emails, internal hostnames,
+private IP addresses, and any configured custom patterns are replaced with
+typed markers so the output stays readable.
+
+Custom patterns and extra internal domains are configured with a JSON file at
+settings.REDACTION_CONFIG_PATH:
+
+    {"patterns": {"employee_id": "EMP-\\\\d{6}"}, "internal_domains": ["corp.example.com"]}
+"""
+
+import json
+import re
+from dataclasses import dataclass, field
+from functools import lru_cache
+from pathlib import Path
+
+import structlog
+
+from app.core.config import settings
+from app.services.secret_detection_service import REDACTION_MARKER, SECRET_PATTERNS
+
+logger = structlog.get_logger(__name__)
+
+EMAIL_PATTERN = r"\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b"
+
+# Top-level labels that only resolve inside private networks. ".local" is left
+# out because it collides with attribute access in code (threading.local).
+INTERNAL_TLDS = ["internal", "localdomain", "lan", "corp", "intranet"]
+
+PRIVATE_IP_PATTERN = (
+    r"\b(?:10(?:\.\d{1,3}){3}"
+    r"|192\.168(?:\.\d{1,3}){2}"
+    r"|172\.(?:1[6-9]|2\d|3[01])(?:\.\d{1,3}){2})\b"
+)
+
+
+class RedactionCategory:
+    """Kinds of redacted content."""
+
+    SECRET = "secret"
+    EMAIL = "email"
+    HOSTNAME = "hostname"
+
+
+MARKERS = {
+    RedactionCategory.SECRET: REDACTION_MARKER,
+    RedactionCategory.EMAIL: "[REDACTED_EMAIL]",
+    RedactionCategory.HOSTNAME: "[REDACTED_HOST]",
+}
+
+
+@dataclass
+class RedactionResult:
+    """Redacted text with replacement counts per category."""
+
+    text: str
+    counts: dict[str, int] = field(default_factory=dict)
+
+    @property
+    def total(self) -> int:
+        """Total replacements."""
+        return sum(self.counts.values())
+
+
+@lru_cache(maxsize=8)
+def load_redaction_config(path: str) -> dict:
+    """Load custom redaction patterns and internal domains.
+
+    Args:
+        path: Config file path (empty for none)
+
+    Returns:
+        Dictionary with "patterns" and "internal_domains"
+
+    Raises:
+        ValueError: If the file cannot be read or a pattern does not compile
+    """
+    config = {"patterns": {}, "internal_domains": []}
+    if not path:
+        return config
+
+    try:
+        loaded = json.loads(Path(path).read_text())
+    except (OSError, json.JSONDecodeError) as e:
+        raise ValueError(f"Invalid redaction config file {path}: {e}") from e
+
+    config["patterns"] = dict(loaded.get("patterns", {}))
+    config["internal_domains"] = [d.lower().lstrip(".") for d in loaded.get("internal_domains", [])]
+    for name, pattern in config["patterns"].items():
+        try:
+            re.compile(pattern)
+        except re.error as e:
+            raise ValueError(f"Invalid redaction pattern '{name}': {e}") from e
+    return config
+
+
+class RedactionService:
+    """Strips secrets, emails, and internal hostnames from outgoing text."""
+
+    def __init__(self, patterns: dict[str, str] | None = None, internal_domains: list[str] | None = None):
+        """Initialize service.
+
+        Args:
+            patterns: Custom patterns by name (defaults to the configured file)
+            internal_domains: Extra domains whose hosts are internal (defaults to the configured file)
+        """
+        if patterns is None or internal_domains is None:
+            config = load_redaction_config(settings.REDACTION_CONFIG_PATH)
+            patterns = config["patterns"] if patterns is None else patterns
+            internal_domains = config["internal_domains"] if internal_domains is None else internal_domains
+
+        self.rules: list[tuple[str, str, re.Pattern]] = [
+            (RedactionCategory.SECRET, MARKERS[RedactionCategory.SECRET], re.compile(c["pattern"]))
+            for c in SECRET_PATTERNS.values()
+        ]
+        self.rules.extend(
+            (name, f"[REDACTED_{name.upper()}]", re.compile(pattern)) for name, pattern in patterns.items()
+        )
+        self.rules.append((RedactionCategory.EMAIL, MARKERS[RedactionCategory.EMAIL], re.compile(EMAIL_PATTERN)))
+
+        suffixes = [re.escape(t) for t in INTERNAL_TLDS] + [re.escape(d) for d in internal_domains]
+        host_pattern = rf"\b(?:[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?\.)+(?:{'|'.join(suffixes)})\b"
+        host_marker = MARKERS[RedactionCategory.HOSTNAME]
+        self.rules.append((RedactionCategory.HOSTNAME, host_marker, re.compile(host_pattern, re.IGNORECASE)))
+        self.rules.append((RedactionCategory.HOSTNAME, host_marker, re.compile(PRIVATE_IP_PATTERN)))
+
+    def redact(self, text: str | None) -> RedactionResult:
+        """Redact text.
+
+        Secrets are replaced first so that credentials embedded in URLs or
+        connection strings are removed whole rather than piecemeal.
+
+        Args:
+            text: Text to redact
+
+        Returns:
+            RedactionResult with the redacted text and counts per category
+        """
+        if not text:
+            return RedactionResult(text=text or "")
+
+        counts: dict[str, int] = {}
+        for category, marker, pattern in self.rules:
+            text, replaced = pattern.subn(marker, text)
+            if replaced:
+                counts[category] = counts.get(category, 0) + replaced
+        return RedactionResult(text=text, counts=counts)
+
+
+def redact_for_egress(text: str | None, channel: str) -> str:
+    """Redact text about to leave the system.
+
+    Args:
+        text: Outgoing text
+        channel: Egress channel for logging (e.g., "llm", "export")
+
+    Returns:
+        Redacted text (unchanged if REDACTION_ENABLED is off)
+    """
+    if not settings.REDACTION_ENABLED or not text:
+        return text or ""
+    result = RedactionService().redact(text)
+    if result.total:
+        logger.info("egress_content_redacted", channel=channel, counts=result.counts)
+    return result.text
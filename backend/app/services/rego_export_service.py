@@ -0,0 +1,476 @@
+"""Service for exporting mined policies as Rego packages for runtime enforcement.
+
+The policy scaffold ships one LLM-translated module per policy; this
+exporter instead writes the mined model out deterministically, in the shape
+a team would write by hand: one package per service of the repository (see
+ServiceViewService), one rule per endpoint, and an ``allow`` rule combining
+them. Each policy mapped to an endpoint becomes one body of the endpoint's
+rule, so several policies on one endpoint grant access independently:
+
+    get_api_expenses_item if {
+        input.request.method == "GET"
+        input.request.path = ["api", "expenses", _]
+        has_role({"MANAGER"})
+        input.resource.attributes.amount <= 5000
+    }
+
+Conditions are translated from the clauses ConditionEvaluationService
+understands. A clause with an alternative ("amount > 5000 requires
+DIRECTOR", "user is owner of expense.owner_id unless ADMIN") becomes one body
+per alternative. A clause that cannot be translated keeps its body but
+makes it never match, so an export is never more permissive than the mined
+policy; such clauses are listed for review. Database policies are not
+exported, since they are enforced by grants rather than at an HTTP endpoint.
+
+Each package comes with a JSON Schema of its input (the roles, methods, and
+attributes its rules read), referenced from the package's METADATA so
+``opa check --schema schemas/`` type-checks the rules against it.
+"""
+
+import json
+import re
+from dataclasses import dataclass, field
+from itertools import product
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.policy import Policy, PolicyStatus, SourceType
+from app.models.repository import Repository
+from app.services.bundle_publish_service import build_archive
+from app.services.condition_evaluation_service import (
+    SUBJECT_PREFIXES,
+    ConditionClause,
+    ConditionEvaluationService,
+)
+from app.services.endpoint_mapping_service import HTTP_METHODS, EndpointMappingService
+from app.services.service_view_service import (
+    ServiceViewService,
+    component_of,
+    component_service,
+    find_components,
+    load_service_layout,
+)
+from app.services.stable_identity_service import normalize_path
+
+logger = structlog.get_logger(__name__)
+
+PACKAGE_ROOT = "policy_miner.services"
+
+# Words Rego reserves, which cannot name a package segment or rule
+REGO_KEYWORDS = {
+    "as", "contains", "default", "else", "every", "false", "if", "import",
+    "in", "not", "null", "package", "some", "true", "with",
+}
+
+NEGATED_OPERATORS = {">": "<=", ">=": "<", "<": ">=", "<=": ">", "==": "!=", "!=": "=="}
+
+# Clause text naming a caller attribute ("user.department", "User department is Finance")
+SUBJECT_CLAUSE = re.compile(
+    rf"^(?:the\s+)?(?:{'|'.join(re.escape(p) for p in SUBJECT_PREFIXES)}|(?:user|caller)\s)", re.IGNORECASE
+)
+
+IDENTIFIER = re.compile(r"^[A-Za-z_]\w*$")
+
+HAS_ROLE_RULE = """# Caller holds one of the roles; role names compare case-insensitively
+has_role(roles) if {
+\tsome role in input.subject.roles
+\tupper(role) in roles
+}
+"""
+
+
+def rego_name(text: str, prefix: str) -> str:
+    """Identifier usable as a Rego package segment or rule name.
+
+    Args:
+        text: e.g. "billing-api", "GET /api/expenses/{}"
+        prefix: Prepended when the name would start with a digit or be a keyword
+
+    Returns:
+        e.g. "billing_api", "get_api_expenses_item"
+    """
+    name = re.sub(r"[^a-z0-9]+", "_", text.replace("{}", "item").lower()).strip("_")
+    if not name or name[0].isdigit() or name in REGO_KEYWORDS:
+        name = f"{prefix}_{name}".rstrip("_")
+    return name
+
+
+def rego_value(value) -> str:
+    """Rego literal of a condition value."""
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    if isinstance(value, int | float):
+        return repr(value)
+    return json.dumps(str(value).lower())
+
+
+def json_type(value) -> str:
+    """JSON Schema type of a condition value."""
+    if isinstance(value, bool):
+        return "boolean"
+    if isinstance(value, int | float):
+        return "number"
+    return "string"
+
+
+def path_pattern(path: str) -> str:
+    """Rego array pattern matching a route's path segments, parameters as wildcards."""
+    segments = [s for s in normalize_path(path).split("/") if s]
+    return "[" + ", ".join("_" if s == "{}" else json.dumps(s) for s in segments) + "]"
+
+
+@dataclass
+class Translation:
+    """Rego of one policy: alternative rule bodies and the clauses left untranslated."""
+
+    bodies: list[list[str]]
+    untranslated: list[str] = field(default_factory=list)
+
+
+class ClauseTranslator:
+    """Translates parsed condition clauses into Rego expressions."""
+
+    def __init__(self):
+        """Initialize translator."""
+        self.attributes: dict[str, dict[str, str]] = {"subject": {}, "resource": {}}
+
+    def reference(self, owner: str, attribute: str, kind: str) -> str:
+        """Reference to a subject or resource attribute, recording it for the input schema.
+
+        Resource attributes are named by their last segment (expense.amount is amount).
+        """
+        name = attribute if owner == "subject" else attribute.split(".")[-1]
+        self.attributes[owner].setdefault(name, kind)
+        key = f".{name}" if IDENTIFIER.match(name) else f"[{json.dumps(name)}]"
+        return f"input.{owner}.attributes{key}"
+
+    def comparison(self, clause: ConditionClause, negate: bool = False) -> str:
+        """Expression of a comparison clause, or of its negation."""
+        owner = "subject" if SUBJECT_CLAUSE.match(clause.raw) else "resource"
+        operator = NEGATED_OPERATORS[clause.operator] if negate else clause.operator
+        reference = self.reference(owner, clause.attribute, json_type(clause.value))
+        if isinstance(clause.value, str):
+            reference = f"lower({reference})"
+        return f"{reference} {operator} {rego_value(clause.value)}"
+
+    def alternatives(self, clause: ConditionClause) -> list[list[str]] | None:
+        """Alternative expression lists any one of which satisfies the clause.
+
+        Returns:
+            Alternatives, or None when the clause cannot be translated
+        """
+        if clause.kind == "comparison":
+            return [[self.comparison(clause)]]
+        if clause.kind == "implication" and clause.inner.kind == "comparison":
+            return [[self.comparison(clause.inner, negate=True)], [f'has_role({{"{clause.required_role}"}})']]
+        if clause.kind in ("ownership", "owner_of_resource"):
+            owner = self.reference("resource", clause.attribute or "owner_id", "string")
+            alternatives = [[f"{owner} == input.subject.id"]]
+            if clause.required_role:
+                alternatives.append([f'has_role({{"{clause.required_role}"}})'])
+            return alternatives
+        if clause.kind == "same_tenant":
+            tenant = self.reference("resource", clause.attribute, "string")
+            return [[f"{tenant} == {self.reference('subject', 'tenant_id', 'string')}"]]
+        if clause.kind == "flag":
+            return [[f"{self.reference('resource', clause.attribute, 'boolean')} == {rego_value(clause.value)}"]]
+        return None
+
+
+def translate_policy(policy: Policy, method: str, path: str, translator: ClauseTranslator) -> Translation:
+    """Bodies of an endpoint rule granting what one policy grants.
+
+    Args:
+        policy: Mined policy
+        method: HTTP method of its endpoint
+        path: Route path of its endpoint
+        translator: Translator collecting the attributes the package reads
+
+    Returns:
+        One body per combination of condition alternatives
+    """
+    roles, requires_authentication = EndpointMappingService.parse_roles(policy.subject)
+    common = [f'input.request.method == "{method}"', f"input.request.path = {path_pattern(path)}"]
+    if requires_authentication:
+        common.append("input.subject.authenticated")
+    if roles:
+        common.append("has_role({" + ", ".join(json.dumps(r) for r in roles) + "})")
+
+    untranslated = []
+    choices = []
+    for clause in ConditionEvaluationService.parse(policy.conditions):
+        alternatives = translator.alternatives(clause)
+        if alternatives is None:
+            text = " ".join(clause.raw.split())
+            untranslated.append(text)
+            alternatives = [[f"# Not translated, review: {text}", "false"]]
+        choices.append(alternatives)
+
+    bodies = [common + [e for alternative in combination for e in alternative] for combination in product(*choices)]
+    return Translation(bodies=bodies, untranslated=untranslated)
+
+
+def policy_comment(policy: Policy) -> str:
+    """Comment opening a policy's body: what it grants and how sure the miner is."""
+    grant = " ".join(f"{policy.subject} may {policy.action} {policy.resource}".split())
+    method = policy.extraction_method.value if policy.extraction_method else "unknown method"
+    confidence = f"{policy.confidence_score:g}" if policy.confidence_score is not None else "unscored"
+    return f"# Policy {policy.id}: {grant} (confidence {confidence}, {method})"
+
+
+def input_schema(package: str, methods: list[str], roles: list[str], attributes: dict[str, dict[str, str]]) -> dict:
+    """JSON Schema of a package's input.
+
+    Args:
+        package: Package the schema describes
+        methods: HTTP methods its rules match
+        roles: Roles its rules check
+        attributes: Subject and resource attributes its rules read, name -> JSON type
+
+    Returns:
+        Draft-07 JSON Schema
+    """
+
+    def attribute_object(names: dict[str, str]) -> dict:
+        return {"type": "object", "properties": {name: {"type": kind} for name, kind in sorted(names.items())}}
+
+    return {
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "title": f"Input of {package}",
+        "type": "object",
+        "required": ["subject", "request"],
+        "properties": {
+            "subject": {
+                "type": "object",
+                "required": ["authenticated", "roles"],
+                "properties": {
+                    "id": {"type": "string", "description": "Caller identifier, compared with resource owners"},
+                    "authenticated": {"type": "boolean"},
+                    "roles": {
+                        "type": "array",
+                        "items": {"type": "string"},
+                        "description": "Caller roles; the rules check " + (", ".join(roles) or "none"),
+                    },
+                    "attributes": attribute_object(attributes["subject"]),
+                },
+            },
+            "request": {
+                "type": "object",
+                "required": ["method", "path"],
+                "properties": {
+                    "method": {"type": "string", "enum": methods},
+                    "path": {
+                        "type": "array",
+                        "items": {"type": "string"},
+                        "description": 'Path segments, e.g. ["api", "expenses", "42"]',
+                    },
+                },
+            },
+            "resource": {"type": "object", "properties": {"attributes": attribute_object(attributes["resource"])}},
+        },
+    }
+
+
+class RegoExportService:
+    """Exports a repository's mined policies as Rego packages, one per service."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def services(self, repository: Repository, policies: list[Policy]) -> dict[str, list[Policy]]:
+        """Policies grouped by the service their evidence lives in."""
+        paths = ServiceViewService._files(self.clone_dir / str(repository.id))
+        components = find_components(paths, repository.name, load_service_layout())
+        default = component_service("", repository.name)
+        grouped: dict[str, list[Policy]] = {}
+        for policy in policies:
+            evidence = policy.evidence[0] if policy.evidence else None
+            component = component_of(evidence.file_path if evidence else None, components)
+            grouped.setdefault(component.service if component else default, []).append(policy)
+        return grouped
+
+    def packages(self, policies: list[Policy]) -> list[dict]:
+        """Rego packages of policies from any of the tenant's repositories, one per service, by service name.
+
+        Raises:
+            ValueError: If a policy's repository does not exist
+        """
+        grouped: dict[str, list[Policy]] = {}
+        for repository_id in sorted({p.repository_id for p in policies}):
+            repository = self.get_repository(repository_id)
+            members = [p for p in policies if p.repository_id == repository_id]
+            for service, found in self.services(repository, members).items():
+                grouped.setdefault(service, []).extend(found)
+        return [self.package(name, members) for name, members in sorted(grouped.items())]
+
+    def package(self, service: str, policies: list[Policy]) -> dict:
+        """Rego package and input schema of one service.
+
+        Args:
+            service: Service name
+            policies: Policies mined for the service
+
+        Returns:
+            Package with its Rego source, input schema, file paths, and untranslated clauses
+        """
+        slug = rego_name(service, "service")
+        package = f"{PACKAGE_ROOT}.{slug}"
+        translator = ClauseTranslator()
+
+        endpoints: dict[tuple[str, str], list[tuple[Policy, Translation]]] = {}
+        for policy in policies:
+            rule = EndpointMappingService.map_policy(policy)
+            path = normalize_path(rule.path)
+            endpoints.setdefault((rule.method, path), []).append(
+                (policy, translate_policy(policy, rule.method, path, translator))
+            )
+
+        rules, blocks, untranslated = [], [], []
+        ordered = sorted(endpoints.items(), key=lambda e: (e[0][1], HTTP_METHODS.index(e[0][0])))
+        for (method, path), members in ordered:
+            name = rego_name(f"{method} {path}", "endpoint")
+            first = members[0][0]
+            rules.append(name)
+            ids = [p.id for p, _ in members]
+            scores = [p.confidence_score for p, _ in members if p.confidence_score is not None]
+            lines = [
+                "# METADATA",
+                f"# title: {json.dumps(f'{method} {path}')}",
+                f"# description: {json.dumps(' '.join(f'{first.action} {first.resource}'.split()))}",
+                "# custom:",
+                f"#   policy_ids: {ids}",
+            ]
+            if scores:
+                lines.append(f"#   min_confidence: {min(scores):g}")
+            for policy, translation in members:
+                untranslated += [
+                    {"policy_id": policy.id, "endpoint": f"{method} {path}", "clause": clause}
+                    for clause in translation.untranslated
+                ]
+                for body in translation.bodies:
+                    lines.append(f"{name} if {{")
+                    lines.append(f"\t{policy_comment(policy)}")
+                    lines += [f"\t{expression}" for expression in body]
+                    lines.append("}")
+                    lines.append("")
+            blocks.append("\n".join(lines))
+
+        header = [
+            "# METADATA",
+            f"# title: {json.dumps(service)}",
+            "# description: Authorization mined from the service's code, one rule per endpoint",
+            "# scope: package",
+            "# schemas:",
+            f"#   - input: schema.{slug}.input",
+            f"package {package}",
+            "",
+            "import rego.v1",
+            "",
+            "default allow := false",
+            "",
+            *[f"allow if {name}" for name in rules],
+            "",
+            HAS_ROLE_RULE,
+        ]
+        rego = "\n".join(header) + "\n" + "\n".join(blocks).rstrip() + "\n"
+
+        methods = [m for m in HTTP_METHODS if any(method == m for method, _ in endpoints)]
+        roles = sorted({r for p in policies for r in EndpointMappingService.parse_roles(p.subject)[0]})
+        return {
+            "service": service,
+            "package": package,
+            "path": f"{package.replace('.', '/')}/policy.rego",
+            "schema_path": f"schemas/{slug}/input.json",
+            "rego": rego,
+            "input_schema": input_schema(package, methods, roles, translator.attributes),
+            "endpoints": len(endpoints),
+            "policy_ids": sorted(p.id for p in policies),
+            "untranslated": untranslated,
+        }
+
+    def export(self, repository_id: int, min_confidence: float | None = None, include_pending: bool = False) -> dict:
+        """Rego packages of a repository's mined policies.
+
+        Args:
+            repository_id: Repository ID
+            min_confidence: Leave out policies scored below this confidence (0-100)
+            include_pending: Also export policies that have not been approved
+
+        Returns:
+            One package per service and a summary of what was exported and left out
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        query = self._query(Policy).filter(
+            Policy.repository_id == repository.id, Policy.source_type != SourceType.DATABASE
+        )
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        candidates = query.order_by(Policy.id).all()
+        policies = [
+            p for p in candidates
+            if min_confidence is None or (p.confidence_score is not None and p.confidence_score >= min_confidence)
+        ]
+
+        grouped = self.services(repository, policies)
+        packages = [self.package(name, members) for name, members in sorted(grouped.items())]
+
+        logger.info(
+            "rego_exported",
+            repository_id=repository.id,
+            packages=len(packages),
+            policies=len(policies),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repository.id,
+            "packages": packages,
+            "summary": {
+                "packages": len(packages),
+                "endpoints": sum(p["endpoints"] for p in packages),
+                "policies": len(policies),
+                "below_min_confidence": len(candidates) - len(policies),
+                "untranslated_clauses": sum(len(p["untranslated"]) for p in packages),
+            },
+        }
+
+    def archive(self, repository_id: int, min_confidence: float | None = None, include_pending: bool = False) -> bytes:
+        """Gzipped tarball of a repository's Rego packages and input schemas.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        export = self.export(repository_id, min_confidence, include_pending)
+        files = {}
+        for package in export["packages"]:
+            files[package["path"]] = package["rego"].encode()
+            files[package["schema_path"]] = (json.dumps(package["input_schema"], indent=2) + "\n").encode()
+        return build_archive(files)
@@ -0,0 +1,597 @@
+"""Page on-call when a scan sees an internet-facing route lose its authentication.
+
+Removing a guard from a route anyone on the internet can call is the one
+change a scan can find that should not wait for someone to open the
+dashboard. Each scan records how every registered route is protected (see
+AuthGapService) and how it is exposed (see ExposureService). A route that
+the previous scan saw protected, by a mined policy requiring
+authentication, a guard on its registration or controller, or middleware
+mounted before it, and that this scan sees unprotected and internet-facing
+is an auth regression. Routes explicitly marked public and conventionally
+public paths (health checks, login) are not.
+
+Each regression triggers an incident through every incident connector
+covering its repository, a PagerDuty service (Events API v2) or an Opsgenie
+team (Alert API), with the registration before and after (redacted, as all
+egress is), the scans, and their commits attached. When a later scan sees
+the route protected again, or no longer registered, the regression is
+resolved and so are its incidents.
+Deliveries that fail are retried on the next scan or on demand.
+"""
+
+import hashlib
+from datetime import UTC, datetime
+from pathlib import Path
+from urllib.parse import urlparse
+
+import httpx
+import structlog
+from sqlalchemy import or_
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import AirGapViolation, ensure_host_allowed
+from app.core.config import settings
+from app.models.incident_alert import (
+    AuthRegression,
+    AuthRegressionStatus,
+    IncidentConnector,
+    IncidentProvider,
+    IncidentStatus,
+    RegressionIncident,
+    RouteProtectionObservation,
+)
+from app.models.repository import Repository
+from app.models.scan_progress import ScanProgress
+from app.services.auth_gap_service import CONVENTIONALLY_PUBLIC, AuthGapService, Protection, _short
+from app.services.exposure_service import Exposure, ExposureService, resolve_exposure
+from app.services.redaction_service import redact_for_egress
+
+logger = structlog.get_logger(__name__)
+
+PAGERDUTY_EVENTS_URL = "https://events.pagerduty.com"
+OPSGENIE_API_URL = "https://api.opsgenie.com"
+
+# Provider field limits
+PAGERDUTY_SUMMARY_LENGTH = 1024
+OPSGENIE_MESSAGE_LENGTH = 130
+OPSGENIE_DESCRIPTION_LENGTH = 15000
+
+SOURCE = "Policy Miner"
+
+# Protections whose loss is a regression, as the incident describes them
+PROTECTION_LABELS = {
+    Protection.POLICY: "a mined policy requiring authentication",
+    Protection.REGISTRATION: "a guard on its registration",
+    Protection.CONTROLLER: "a guard on its controller",
+    Protection.MIDDLEWARE: "auth middleware mounted before it",
+}
+
+RESOLVED_NOTE = "Resolved by Policy Miner: the latest scan sees the route protected again or no longer registered."
+
+
+def _cut(text: str, length: int) -> str:
+    """Text cut to a provider field limit."""
+    return text if len(text) <= length else text[: length - 3] + "..."
+
+
+def dedup_key(regression: AuthRegression) -> str:
+    """Key the provider deduplicates a regression's incident by."""
+    digest = hashlib.sha256(f"{regression.repository_id}:{regression.endpoint}".encode()).hexdigest()[:12]
+    return f"policy-miner-auth-regression-{regression.id}-{digest}"
+
+
+def incident_summary(regression: AuthRegression, repository_name: str) -> str:
+    """One-line incident title."""
+    return f"Authentication removed from internet-facing {regression.endpoint} in {repository_name}"
+
+
+def incident_details(regression: AuthRegression, repository_name: str) -> dict[str, str]:
+    """Evidence attached to an incident, flat and as strings so both providers take it as is."""
+    before, after = regression.evidence["before"], regression.evidence["after"]
+    details = {
+        "regression_id": str(regression.id),
+        "repository": repository_name,
+        "endpoint": regression.endpoint,
+        "exposure": regression.exposure,
+        "previous_protection": PROTECTION_LABELS.get(regression.previous_protection, regression.previous_protection),
+        "before": f"{before['file_path']}:{before['line']}",
+        "before_code": redact_for_egress(before["code"], "incident"),
+        "after": f"{after['file_path']}:{after['line']}",
+        "after_code": redact_for_egress(after["code"], "incident"),
+    }
+    for side, values in (("previous_scan", before), ("scan", after)):
+        if values.get("scan_id") is not None:
+            details[f"{side}_id"] = str(values["scan_id"])
+        if values.get("commit"):
+            details[f"{side}_commit"] = values["commit"]
+    if before.get("policy_ids"):
+        details["previous_policy_ids"] = ", ".join(str(i) for i in before["policy_ids"])
+    return details
+
+
+class IncidentClient:
+    """Triggers and resolves one connector's incidents."""
+
+    default_url = ""
+
+    def __init__(self, connector: IncidentConnector, client: httpx.Client):
+        """Initialize client."""
+        self.connector = connector
+        self.client = client
+        self.base = (connector.base_url or self.default_url).rstrip("/")
+
+    def post(self, url: str, body: dict, headers: dict | None = None) -> dict:
+        """JSON request to the provider.
+
+        Raises:
+            httpx.HTTPError: If the request fails
+        """
+        response = self.client.post(url, json=body, headers={"Accept": "application/json", **(headers or {})}, timeout=30.0)
+        response.raise_for_status()
+        return response.json()
+
+    def trigger(self, key: str, summary: str, details: dict[str, str], description: str) -> None:
+        """Open an incident, or update the open one with the same key."""
+        raise NotImplementedError
+
+    def resolve(self, key: str) -> None:
+        """Resolve the incident with the key."""
+        raise NotImplementedError
+
+
+class PagerDutyClient(IncidentClient):
+    """Incidents on a PagerDuty service through Events API v2; the secret is its routing key."""
+
+    default_url = PAGERDUTY_EVENTS_URL
+
+    def trigger(self, key: str, summary: str, details: dict[str, str], description: str) -> None:
+        """Send a critical trigger event with the evidence as custom details."""
+        self.post(
+            f"{self.base}/v2/enqueue",
+            {
+                "routing_key": self.connector.secret,
+                "event_action": "trigger",
+                "dedup_key": key,
+                "client": SOURCE,
+                "payload": {
+                    "summary": _cut(summary, PAGERDUTY_SUMMARY_LENGTH),
+                    "source": details["repository"],
+                    "severity": "critical",
+                    "component": details["endpoint"],
+                    "group": details["repository"],
+                    "class": "auth_regression",
+                    "custom_details": {**details, "description": description},
+                },
+            },
+        )
+
+    def resolve(self, key: str) -> None:
+        """Send a resolve event."""
+        self.post(
+            f"{self.base}/v2/enqueue",
+            {"routing_key": self.connector.secret, "event_action": "resolve", "dedup_key": key},
+        )
+
+
+class OpsgenieClient(IncidentClient):
+    """Alerts for an Opsgenie team through the Alert API; the secret is an API integration key."""
+
+    default_url = OPSGENIE_API_URL
+
+    @property
+    def headers(self) -> dict:
+        """Integration key authorization."""
+        return {"Authorization": f"GenieKey {self.connector.secret}"}
+
+    def trigger(self, key: str, summary: str, details: dict[str, str], description: str) -> None:
+        """Create a P1 alert aliased by the key, so a repeat does not open a second one."""
+        self.post(
+            f"{self.base}/v2/alerts",
+            {
+                "message": _cut(summary, OPSGENIE_MESSAGE_LENGTH),
+                "alias": key,
+                "description": _cut(description, OPSGENIE_DESCRIPTION_LENGTH),
+                "details": details,
+                "entity": details["endpoint"],
+                "source": SOURCE,
+                "priority": "P1",
+                "tags": ["policy-miner", "auth-regression"],
+            },
+            self.headers,
+        )
+
+    def resolve(self, key: str) -> None:
+        """Close the alert with the key."""
+        self.post(
+            f"{self.base}/v2/alerts/{key}/close?identifierType=alias",
+            {"source": SOURCE, "note": RESOLVED_NOTE},
+            self.headers,
+        )
+
+
+INCIDENT_CLIENTS: dict[IncidentProvider, type[IncidentClient]] = {
+    IncidentProvider.PAGERDUTY: PagerDutyClient,
+    IncidentProvider.OPSGENIE: OpsgenieClient,
+}
+
+
+class RegressionAlertService:
+    """Detects auth regressions between scans and pages on-call through incident connectors."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def create_connector(self, data: dict) -> IncidentConnector:
+        """Register a PagerDuty or Opsgenie connector.
+
+        Args:
+            data: Connector fields (name, provider, secret, base_url, repository_id)
+
+        Returns:
+            Created connector
+        """
+        connector = IncidentConnector(tenant_id=self.tenant_id, **data)
+        self.db.add(connector)
+        self.db.commit()
+        self.db.refresh(connector)
+        logger.info("incident_connector_created", connector_id=connector.id, provider=connector.provider)
+        return connector
+
+    def list_connectors(self) -> list[IncidentConnector]:
+        """List the tenant's connectors."""
+        return self._query(IncidentConnector).order_by(IncidentConnector.id).all()
+
+    def get_connector(self, connector_id: int) -> IncidentConnector:
+        """Get a connector.
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self._query(IncidentConnector).filter(IncidentConnector.id == connector_id).first()
+        if not connector:
+            raise ValueError(f"Incident connector {connector_id} not found")
+        return connector
+
+    def delete_connector(self, connector_id: int) -> None:
+        """Delete a connector and its incident links; open incidents stay open in the provider.
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self.get_connector(connector_id)
+        self.db.query(RegressionIncident).filter(RegressionIncident.connector_id == connector.id).delete(
+            synchronize_session=False
+        )
+        self.db.delete(connector)
+        self.db.commit()
+
+    def get_regression(self, regression_id: int) -> AuthRegression:
+        """Get a regression.
+
+        Raises:
+            ValueError: If the regression does not exist
+        """
+        regression = self._query(AuthRegression).filter(AuthRegression.id == regression_id).first()
+        if not regression:
+            raise ValueError(f"Auth regression {regression_id} not found")
+        return regression
+
+    def list_regressions(self, repository_id: int | None = None, status: str | None = None) -> list[dict]:
+        """Regressions, newest first, with their incidents.
+
+        Raises:
+            ValueError: If the status is unknown
+        """
+        query = self._query(AuthRegression)
+        if repository_id is not None:
+            query = query.filter(AuthRegression.repository_id == repository_id)
+        if status is not None:
+            try:
+                query = query.filter(AuthRegression.status == AuthRegressionStatus(status))
+            except ValueError as e:
+                raise ValueError(f"Unknown status {status}; use open or resolved") from e
+        return [self.regression_to_dict(r) for r in query.order_by(AuthRegression.id.desc()).all()]
+
+    def regression_to_dict(self, regression: AuthRegression) -> dict:
+        """Regression as the API returns it."""
+        incidents = self.db.query(RegressionIncident).filter(RegressionIncident.regression_id == regression.id).all()
+        return {
+            "id": regression.id,
+            "repository_id": regression.repository_id,
+            "scan_id": regression.scan_id,
+            "previous_scan_id": regression.previous_scan_id,
+            "endpoint": regression.endpoint,
+            "previous_protection": regression.previous_protection,
+            "exposure": regression.exposure,
+            "evidence": regression.evidence,
+            "description": regression.description,
+            "status": regression.status,
+            "detected_at": regression.detected_at,
+            "resolved_at": regression.resolved_at,
+            "incidents": [
+                {
+                    "connector_id": i.connector_id,
+                    "dedup_key": i.dedup_key,
+                    "status": i.status,
+                    "error": i.error,
+                    "triggered_at": i.triggered_at,
+                    "resolved_at": i.resolved_at,
+                }
+                for i in incidents
+            ],
+        }
+
+    def observe(self, repository: Repository, scan_id: int | None) -> list[RouteProtectionObservation]:
+        """Record how each route of a repository's clone is protected and exposed."""
+        root = self.clone_dir / str(repository.id)
+        if not root.is_dir():
+            return []
+        signals = ExposureService.scan_clone(root)
+        observations = []
+        for file_path, registration, rule in AuthGapService(self.db, self.tenant_id, str(self.clone_dir)).routes(
+            repository, root
+        ):
+            exposure, _ = resolve_exposure(registration.path, signals)
+            observation = RouteProtectionObservation(
+                tenant_id=self.tenant_id,
+                repository_id=repository.id,
+                scan_id=scan_id,
+                endpoint=f"{registration.method} {registration.path}",
+                protection=registration.protection,
+                exposure=exposure.value,
+                file_path=file_path,
+                line=registration.line,
+                code=_short(registration.code),
+                policy_ids=rule.policy_ids if rule else [],
+            )
+            self.db.add(observation)
+            observations.append(observation)
+        self.db.commit()
+        return observations
+
+    def _previous_observations(self, repository_id: int, scan_id: int | None) -> list[RouteProtectionObservation]:
+        """Observations of the latest earlier scan of a repository."""
+        query = self._query(RouteProtectionObservation).filter(RouteProtectionObservation.repository_id == repository_id)
+        if scan_id is not None:
+            query = query.filter(RouteProtectionObservation.scan_id != scan_id)
+        latest = query.order_by(RouteProtectionObservation.observed_at.desc()).first()
+        if latest is None:
+            return []
+        return query.filter(RouteProtectionObservation.scan_id == latest.scan_id).all()
+
+    def _commit(self, scan_id: int | None) -> str | None:
+        """Commit a scan ran against."""
+        if scan_id is None:
+            return None
+        scan = self.db.query(ScanProgress).filter(ScanProgress.id == scan_id).first()
+        return scan.git_commit_hash if scan else None
+
+    @staticmethod
+    def regressed(before: RouteProtectionObservation, after: RouteProtectionObservation) -> bool:
+        """Whether a route lost the authentication it had, on an internet-facing path that is not meant to be public."""
+        path = after.endpoint.split(" ", 1)[1]
+        return (
+            before.protection in PROTECTION_LABELS
+            and after.protection is None
+            and after.exposure == Exposure.INTERNET_FACING.value
+            and not CONVENTIONALLY_PUBLIC.match(path)
+        )
+
+    def detect(
+        self, repository: Repository, scan_id: int | None, current: list[RouteProtectionObservation], previous: list[RouteProtectionObservation]
+    ) -> tuple[list[AuthRegression], list[AuthRegression]]:
+        """Open regressions for routes that lost their authentication, and resolve ones that got it back.
+
+        Returns:
+            Tuple of (regressions opened, regressions resolved)
+        """
+        now = datetime.now(UTC)
+        by_endpoint = {o.endpoint: o for o in current}
+        open_regressions = {
+            r.endpoint: r
+            for r in self._query(AuthRegression).filter(
+                AuthRegression.repository_id == repository.id, AuthRegression.status == AuthRegressionStatus.OPEN
+            )
+        }
+
+        resolved = []
+        for endpoint, regression in open_regressions.items():
+            observation = by_endpoint.get(endpoint)
+            if observation is None or observation.protection is not None:
+                regression.status = AuthRegressionStatus.RESOLVED
+                regression.resolved_at = now
+                resolved.append(regression)
+
+        opened = []
+        for before in previous:
+            after = by_endpoint.get(before.endpoint)
+            if after is None or before.endpoint in open_regressions or not self.regressed(before, after):
+                continue
+            description = (
+                f"{after.endpoint} in {repository.name} was protected by {PROTECTION_LABELS[before.protection]} "
+                f"at {before.file_path}:{before.line}; the latest scan sees it registered at "
+                f"{after.file_path}:{after.line} with no authentication or authorization the miner can detect, "
+                "and it is reachable from the internet."
+            )
+            regression = AuthRegression(
+                tenant_id=self.tenant_id,
+                repository_id=repository.id,
+                scan_id=scan_id,
+                previous_scan_id=before.scan_id,
+                endpoint=after.endpoint,
+                previous_protection=before.protection,
+                exposure=after.exposure,
+                evidence={
+                    "before": {
+                        "file_path": before.file_path,
+                        "line": before.line,
+                        "code": before.code,
+                        "protection": before.protection,
+                        "policy_ids": before.policy_ids or [],
+                        "scan_id": before.scan_id,
+                        "commit": self._commit(before.scan_id),
+                    },
+                    "after": {
+                        "file_path": after.file_path,
+                        "line": after.line,
+                        "code": after.code,
+                        "scan_id": scan_id,
+                        "commit": self._commit(scan_id),
+                    },
+                },
+                description=description,
+                status=AuthRegressionStatus.OPEN,
+                detected_at=now,
+            )
+            self.db.add(regression)
+            opened.append(regression)
+        self.db.commit()
+        return opened, resolved
+
+    def _connectors(self, repository_id: int) -> list[IncidentConnector]:
+        """Connectors covering a repository."""
+        return [c for c in self.list_connectors() if c.repository_id in (None, repository_id)]
+
+    def deliver(self, regression: AuthRegression, repository_name: str, client: httpx.Client | None = None) -> dict:
+        """Bring a regression's incidents in line with it: triggered while open, resolved once it is.
+
+        Failed deliveries are recorded on the incident and its connector rather than raised.
+
+        Args:
+            regression: Regression to deliver
+            repository_name: Name of its repository, for the incident
+            client: HTTP client (a new one is created if omitted)
+
+        Returns:
+            Counts of incidents triggered, resolved, and failed
+        """
+        if client is None:
+            with httpx.Client() as new_client:
+                return self.deliver(regression, repository_name, new_client)
+
+        counts = {"triggered": 0, "resolved": 0, "failed": 0}
+        incidents = {
+            i.connector_id: i
+            for i in self.db.query(RegressionIncident).filter(RegressionIncident.regression_id == regression.id)
+        }
+        summary = incident_summary(regression, repository_name)
+        details = incident_details(regression, repository_name)
+        for connector in self._connectors(regression.repository_id):
+            incident = incidents.get(connector.id)
+            if regression.status == AuthRegressionStatus.OPEN:
+                if incident is not None and incident.status == IncidentStatus.TRIGGERED:
+                    continue
+            elif incident is None or incident.status != IncidentStatus.TRIGGERED:
+                continue
+            if incident is None:
+                incident = RegressionIncident(
+                    tenant_id=self.tenant_id,
+                    connector_id=connector.id,
+                    regression_id=regression.id,
+                    dedup_key=dedup_key(regression),
+                    status=IncidentStatus.FAILED,
+                )
+                self.db.add(incident)
+
+            provider = INCIDENT_CLIENTS[IncidentProvider(connector.provider)](connector, client)
+            now = datetime.now(UTC)
+            try:
+                ensure_host_allowed(urlparse(provider.base).hostname, "incident_alerting")
+                if regression.status == AuthRegressionStatus.OPEN:
+                    provider.trigger(incident.dedup_key, summary, details, regression.description)
+                    incident.status, incident.triggered_at = IncidentStatus.TRIGGERED, now
+                    connector.last_triggered_at = now
+                    counts["triggered"] += 1
+                else:
+                    provider.resolve(incident.dedup_key)
+                    incident.status, incident.resolved_at = IncidentStatus.RESOLVED, now
+                    counts["resolved"] += 1
+                incident.error = connector.last_error = None
+            except (httpx.HTTPError, AirGapViolation) as e:
+                # A resolve that failed stays triggered, so it is retried
+                if regression.status == AuthRegressionStatus.OPEN:
+                    incident.status = IncidentStatus.FAILED
+                incident.error = connector.last_error = str(e)
+                counts["failed"] += 1
+                logger.error(
+                    "incident_delivery_failed", connector_id=connector.id, regression_id=regression.id, error=str(e)
+                )
+            self.db.commit()
+        return counts
+
+    def record(self, repository_id: int, scan_id: int | None = None, client: httpx.Client | None = None) -> dict:
+        """Observe a scan's routes, detect regressions against the previous scan, and page on them.
+
+        Every open regression of the repository, and every resolved one with
+        an incident still triggered, is delivered again, so incidents that
+        failed to trigger or resolve are retried.
+
+        Args:
+            repository_id: Repository ID
+            scan_id: Scan the observations belong to
+            client: HTTP client (a new one is created if omitted)
+
+        Returns:
+            Counts of routes observed, regressions opened and resolved, and incidents delivered
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+
+        previous = self._previous_observations(repository.id, scan_id)
+        current = self.observe(repository, scan_id)
+        opened, resolved = self.detect(repository, scan_id, current, previous) if previous else ([], [])
+
+        triggered = self.db.query(RegressionIncident.regression_id).filter(
+            RegressionIncident.status == IncidentStatus.TRIGGERED
+        )
+        undelivered = self._query(AuthRegression).filter(
+            AuthRegression.repository_id == repository.id,
+            or_(AuthRegression.status == AuthRegressionStatus.OPEN, AuthRegression.id.in_(triggered)),
+        ).all()
+        counts = {"triggered": 0, "resolved": 0, "failed": 0}
+        for regression in undelivered:
+            for name, count in self.deliver(regression, repository.name, client).items():
+                counts[name] += count
+
+        logger.info(
+            "auth_regressions_recorded",
+            repository_id=repository.id,
+            scan_id=scan_id,
+            opened=len(opened),
+            resolved=len(resolved),
+            **counts,
+        )
+        return {
+            "repository_id": repository.id,
+            "routes": len(current),
+            "regressions_opened": len(opened),
+            "regressions_resolved": len(resolved),
+            "incidents_triggered": counts["triggered"],
+            "incidents_resolved": counts["resolved"],
+            "incidents_failed": counts["failed"],
+        }
+
+    def redeliver(self, regression_id: int, client: httpx.Client | None = None) -> dict:
+        """Retry a regression's incidents that failed to trigger or resolve.
+
+        Raises:
+            ValueError: If the regression does not exist
+        """
+        regression = self.get_regression(regression_id)
+        repository = self.db.query(Repository).filter(Repository.id == regression.repository_id).first()
+        counts = self.deliver(regression, repository.name if repository else str(regression.repository_id), client)
+        return {"regression_id": regression.id, **counts}
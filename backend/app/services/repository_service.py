@@ -11,6 +11,7 @@ from git import GitCommandError, Repo
 from sqlalchemy import create_engine, select
 from sqlalchemy.orm import Session
 
+from app.core.air_gap import ensure_git_url_allowed, ensure_host_allowed
 from app.models.repository import DatabaseType, Repository, RepositoryStatus
 from app.schemas.repository import RepositoryCreate, RepositoryUpdate
 
@@ -120,6 +121,8 @@ class RepositoryService:
 
         temp_dir = None
         try:
+            ensure_git_url_allowed(repository.source_url, "repository_verification")
+
             # Create temporary directory for git operations
             temp_dir = tempfile.mkdtemp(prefix="policy_miner_git_")
             logger.debug("created_temp_dir", temp_dir=temp_dir)
@@ -215,6 +218,8 @@ class RepositoryService:
             return False
 
         try:
+            ensure_host_allowed(host, "repository_verification")
+
             if db_type == DatabaseType.POSTGRESQL.value:
                 # Test PostgreSQL connection
                 conn = psycopg2.connect(
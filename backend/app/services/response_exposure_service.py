@@ -0,0 +1,518 @@
+"""Service for sensitive response fields reaching roles not allowed to see them.
+
+Mined policies say who may call an endpoint, not what it returns to them. An
+employee directory endpoint open to every EMPLOYEE that serializes the whole
+Employee model hands out salaries and social security numbers the HR data
+policy reserves for HR. This service reads what each policy's handlers
+return: object literals, the models of the variables they serialize
+(indexed across the clone, minus fields excluded from responses such as
+json:"-" or @JsonIgnore), DRF serializers, and FastAPI response models. It
+then compares the sensitive fields with the roles the endpoint's policies
+admit. Which fields are sensitive, and which roles may receive each class of
+them, comes from the data visibility policy: the defaults below, overridable
+from a JSON file (settings.DATA_VISIBILITY_POLICY_PATH).
+"""
+
+import copy
+import json
+import re
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass, field
+from pathlib import Path
+
+import structlog
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.abac_condition_service import AbacConditionService, _SourceFile
+from app.services.bola_detection_service import HEADER_LINES, SEVERITY_ORDER
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, SKIPPED_DIRECTORIES
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import MAX_LINE_LENGTH, group
+from app.services.guard_condition_extractor import _attribute, _split
+from app.services.mass_assignment_service import (
+    SERIALIZER,
+    SERIALIZER_LIST,
+    SERIALIZER_MODEL,
+    STRING_ITEM,
+    ModelFields,
+    _bound_model,
+    _indented,
+    parse_models,
+)
+
+logger = structlog.get_logger(__name__)
+
+# Classes of sensitive fields: name patterns (on snake_case names), the roles allowed to receive them, and severity.
+# Every class can be overridden or added from settings.DATA_VISIBILITY_POLICY_PATH.
+DEFAULT_VISIBILITY_POLICY = {
+    "classes": {
+        "credential": {
+            "patterns": [r"password", r"passwd", r"(?:^|_)secret(?:_|$)", r"api_?key", r"(?:access|refresh|auth)_token", r"private_key", r"(?:otp|mfa|totp)_"],
+            "roles": [],
+            "severity": "high",
+        },
+        "government_id": {
+            "patterns": [r"(?:^|_)ssn(?:_|$)", r"social_?security", r"(?:^|_)tax_?id", r"national_?id", r"passport", r"drivers?_licen[cs]e"],
+            "roles": ["ADMIN", "HR"],
+            "severity": "high",
+        },
+        "payment": {
+            "patterns": [r"card_?number", r"credit_?card", r"(?:^|_)cv[vc]2?(?:_|$)", r"(?:^|_)iban(?:_|$)", r"(?:bank_)?account_number", r"routing_number"],
+            "roles": ["ADMIN", "FINANCE", "BILLING"],
+            "severity": "high",
+        },
+        "health": {
+            "patterns": [r"diagnos[ie]s", r"medical", r"health_record", r"(?:^|_)insurance_(?:id|number)"],
+            "roles": ["ADMIN", "HR"],
+            "severity": "high",
+        },
+        "compensation": {
+            "patterns": [r"salary", r"(?:^|_)wages?(?:_|$)", r"(?:hourly|pay)_rate", r"(?:^|_)bonus(?:_|$)", r"compensation", r"payroll"],
+            "roles": ["ADMIN", "HR", "PAYROLL", "FINANCE"],
+            "severity": "medium",
+        },
+        "personal": {
+            "patterns": [r"date_of_birth", r"(?:^|_)dob(?:_|$)", r"birth_?date", r"home_address", r"personal_(?:email|phone)"],
+            "roles": ["ADMIN", "HR"],
+            "severity": "medium",
+        },
+    }
+}
+
+# Responses and the argument they serialize; group 1 opens the call when there is one
+RESPONSE = re.compile(
+    r"json\s*\.\s*NewEncoder\s*\([^()\n]*\)\s*\.\s*Encode\s*(\()"
+    r"|\b(?:c|ctx|e|context)\s*\.\s*(?:JSON|IndentedJSON|PureJSON|JSONPretty|Status\s*\(\s*\d+\s*\)\s*\.\s*JSON)\s*(\()"
+    r"|\b(?:res|response|reply)\s*(?:\.\s*status\s*\([^()\n]*\)\s*)?\.\s*(?:json|send)\s*(\()"
+    r"|\b(?:jsonify|JsonResponse|JSONResponse|Response|Ok|Json|ResponseEntity\s*\.\s*ok|ResponseEntity\s*\.\s*status\s*\([^()\n]*\)\s*\.\s*body)\s*(\()"
+    r"|\bctx\s*\.\s*body\s*=\s*|\brender\s*\(?\s*json:\s*"
+)
+# Values returned directly, serialized by the framework (FastAPI, Spring, NestJS, ASP.NET)
+RETURNED = re.compile(r"^\s*return\s+(?!(?:res|response|c|ctx|render|redirect|HttpResponse|None|nil|null|err|true|false)\b)(?=[\w{(\[&*])")
+RESPONSE_MODEL = re.compile(r"response_model\s*=\s*(?:list\s*\[\s*|List\s*\[\s*)?([A-Z]\w*)")
+RETURN_TYPE = re.compile(
+    r"^\s*(?:(?:public|protected|private|internal|static|async|final|virtual|override)\s+)+([\w<>\[\], ?.]+?)\s+\w+\s*\(|\)\s*:\s*([\w<>\[\], |.]+?)\s*\{\s*$"
+)
+TYPE_NAME = re.compile(r"\b([A-Z]\w*)")
+COMMA = re.compile(r",")
+LITERAL_KEY = re.compile(r"""(?:^|[{,(]\s*)["']?([A-Za-z_$][\w$]*)["']?\s*(?::(?!=)|=(?!=))""")
+SPREAD = re.compile(r"\.\.\.\s*([A-Za-z_$][\w$]*)|\*\*\s*([A-Za-z_]\w*)")
+LEADING_NAME = re.compile(r"^[&*\[\s(]*([@$]?[A-Za-z_][\w$]*)")
+SERIALIZED_DATA = re.compile(r"^([A-Z]\w*Serializer)\s*\(|^(\w+)\s*\.\s*data\b")
+GO_SLICE = r"\b(?:var\s+{name}\s+|{name}\s*:?=\s*(?:make\s*\(\s*)?)\[\]\s*\*?([\w.]+)"
+LOADED_BY_QUERY = r"\b{name}\s*(?::=|=)[^\n]{{0,120}}?\b(?:query|select|get|find\w*|First|Find)\s*\(\s*&?\s*(?:\[\]\s*)?([A-Z]\w*)\b"
+
+# Fields a handler removes from what it returns, or whitelists
+REMOVED = re.compile(
+    r"\bdelete\s+[\w$.]+\s*\.\s*(\w+)|\.\s*(\w+)\s*=\s*(?:\"\"|''|nil|null|None|undefined)\s*;?\s*$|['\"]-(\w+)['\"]"
+    r"|\bomit\s*\([^()\n]*?['\"](\w+)['\"]|\bexcept:\s*(?:\[\s*)?:(\w+)|\.\s*pop\s*\(\s*['\"](\w+)['\"]",
+    re.MULTILINE,
+)
+WHITELIST = re.compile(
+    r"""\.\s*select\s*\(\s*['"]([\w ]+)['"]\s*\)|\.\s*(?:only|values)\s*\(([^()\n]*)\)|\bonly:\s*\[([^\]\n]*)\]|\battributes:\s*\[([^\]\n]*)\]"""
+)
+
+
+def load_visibility_policy(path: str | None = None) -> dict:
+    """Load the data visibility policy, merging an optional override file over the defaults.
+
+    Args:
+        path: Override file path (defaults to settings.DATA_VISIBILITY_POLICY_PATH)
+
+    Returns:
+        Merged policy
+
+    Raises:
+        ValueError: If the override file cannot be read
+    """
+    policy = copy.deepcopy(DEFAULT_VISIBILITY_POLICY)
+    path = path if path is not None else settings.DATA_VISIBILITY_POLICY_PATH
+    if not path:
+        return policy
+
+    try:
+        overrides = json.loads(Path(path).read_text())
+    except (OSError, json.JSONDecodeError) as e:
+        raise ValueError(f"Invalid data visibility policy file {path}: {e}") from e
+
+    for name, value in (overrides.get("classes") or {}).items():
+        if isinstance(value, dict) and name in policy["classes"]:
+            policy["classes"][name].update(value)
+        else:
+            policy["classes"][name] = value
+    return policy
+
+
+class FieldClassifier:
+    """Sensitivity class of a field name under a visibility policy."""
+
+    def __init__(self, policy: dict):
+        self.policy = policy
+        self.classes = {
+            name: (re.compile("|".join(spec.get("patterns") or ["(?!)"])), {r.upper() for r in spec.get("roles") or []}, spec.get("severity", "medium"))
+            for name, spec in policy["classes"].items()
+        }
+
+    def __call__(self, name: str) -> str | None:
+        """Class of a field, or None if it is not sensitive."""
+        snake = _attribute(name)
+        return next((c for c, (pattern, _, _) in self.classes.items() if pattern.search(snake)), None)
+
+    def allowed(self, name: str) -> set[str]:
+        """Roles a class may be returned to."""
+        return self.classes[name][1]
+
+    def severity(self, name: str) -> str:
+        """Severity of returning a class to a role it is not allowed to."""
+        return self.classes[name][2]
+
+
+@dataclass
+class ResponseFields:
+    """What a handler returns: its sensitive fields, and the model they come from."""
+
+    line: int
+    code: str
+    model: str | None  # None for object literals
+    fields: list[str] = field(default_factory=list)
+
+
+@dataclass
+class ExposureFinding:
+    """Sensitive fields an endpoint returns to roles the visibility policy does not allow."""
+
+    endpoint: str
+    file_path: str
+    function: str | None
+    line_start: int
+    line_end: int
+    model: str | None
+    fields: dict[str, str]  # field -> sensitivity class
+    caller_roles: list[str]  # empty when any authenticated caller is admitted
+    unauthorized_roles: list[str]
+    anonymous: bool
+    severity: str
+    description: str
+    policy_ids: list[int] = field(default_factory=list)
+
+
+def serializer_models(sources: dict[str, str], models: dict[str, ModelFields]) -> dict[str, ModelFields]:
+    """DRF ModelSerializers as models of the fields they output.
+
+    Args:
+        sources: File path -> content of the clone's Python files
+        models: Indexed models by name
+
+    Returns:
+        Serializers with the sensitive fields of their model they list (or all of them), minus excluded ones
+    """
+    serializers = {}
+    for path, text in sources.items():
+        lines = text.split("\n")
+        for index, line in enumerate(lines):
+            declared = SERIALIZER.match(line)
+            if not declared:
+                continue
+            target, lists = None, {}
+            for _, body in _indented(lines, index):
+                model = SERIALIZER_MODEL.match(body)
+                if model:
+                    target = models.get(model.group(1).split(".")[-1])
+                listed = SERIALIZER_LIST.match(body)
+                if listed:
+                    lists[listed.group(1)] = listed.group(2)
+            if target is None:
+                continue
+            listed = lists.get("fields", "__all__")
+            excluded = set(STRING_ITEM.findall(lists.get("exclude", "")))
+            output = [f for f in target.fields if ("__all__" in listed or f in STRING_ITEM.findall(listed)) and f not in excluded]
+            serializers[declared.group(1)] = ModelFields(declared.group(1), path, index + 1, output)
+    return serializers
+
+
+def _argument(lines: list[str], index: int, match: re.Match) -> str:
+    """The expression a response serializes: the call's last argument, or the rest of the line."""
+    opening = next((match.start(g) for g in range(1, match.re.groups + 1) if match.group(g)), None)
+    if opening is None:
+        rest = lines[index][match.end() :]
+        return re.split(r",\s*(?:status|only|except|include):", rest)[0].strip().rstrip(";")
+    text = "\n".join(lines[index : index + 15])
+    end = group(text, opening)
+    arguments = _split(text[opening + 1 : end - 1], COMMA)
+    if not arguments:
+        return ""
+    # c.JSON(200, x) and ResponseEntity.status(...).body(x) take the status first; keyword arguments are fields
+    if all(LITERAL_KEY.match("(" + a) and "=" in a.split("(")[0] for a in arguments):
+        return "(" + ", ".join(arguments) + ")"
+    return arguments[-1] if re.match(r"^\s*(?:\d+|http\.\w+|HttpStatus\.\w+|status\.\w+)\s*$", arguments[0]) else arguments[0]
+
+
+def response_fields(
+    lines: list[str], start: int, end: int, models: dict[str, ModelFields], classify
+) -> list[ResponseFields]:
+    """Sensitive fields a handler returns, one entry per response.
+
+    Args:
+        lines: Lines of the source file
+        start: Index of the handler's first line
+        end: Index just past its last line
+        models: Indexed models and serializers by name
+        classify: Field classifier
+
+    Returns:
+        Responses with at least one sensitive field the handler does not remove
+    """
+    body = lines[start:end]
+    header = lines[max(0, start - HEADER_LINES) : start + 1]
+    scope = "\n".join(line for line in body if len(line) <= MAX_LINE_LENGTH)
+    removed = {_attribute(next(g for g in m.groups() if g)) for m in REMOVED.finditer(scope)}
+    whitelist = {_attribute(n) for m in WHITELIST.finditer(scope) for n in re.findall(r"\w+", next(g for g in m.groups() if g))}
+    response_model = next((m.group(1) for line in header for m in [RESPONSE_MODEL.search(line)] if m), None)
+    signature = lines[start] if start < len(lines) and len(lines[start]) <= MAX_LINE_LENGTH else ""
+    declared = next((m.group(1) or m.group(2) for m in [RETURN_TYPE.search(signature)] if m), None)
+
+    held: dict[str, str | None] = {}
+
+    def model_of(name: str) -> str | None:
+        if name not in held:
+            held[name] = _model_of(body, name, models)
+        return held[name]
+
+    found = []
+    for index in range(start + 1, min(end, len(lines))):
+        line = lines[index]
+        if len(line) > MAX_LINE_LENGTH:
+            continue
+        match = RESPONSE.search(line) or RETURNED.match(line)
+        if not match:
+            continue
+        expression = _argument(lines, index, match).strip()
+        model, names = None, []
+        if response_model and response_model in models:
+            model = response_model
+        elif expression[:1] in "{(" or re.match(r"^(?:gin\.H|map\[[^\]]*\][\w{}]*|fiber\.Map|dict)\s*[{(]", expression):
+            names = LITERAL_KEY.findall(expression)
+            # Shorthand properties, e.g. { users, total }, carry the fields of the model they hold
+            for name in re.findall(r"[{,]\s*([A-Za-z_$][\w$]*)\s*(?=[,}])", expression):
+                nested = model_of(name)
+                names += models[nested].fields if nested in models else [name]
+            spread = SPREAD.search(expression)
+            if spread:
+                model = _bound_model(body, spread.group(1) or spread.group(2))
+        else:
+            serialized = SERIALIZED_DATA.match(expression)
+            if serialized and serialized.group(1):
+                model = serialized.group(1)
+            elif serialized:
+                assigned = re.search(rf"\b{re.escape(serialized.group(2))}\s*=\s*([A-Z]\w*Serializer)\b", scope)
+                model = assigned.group(1) if assigned else None
+            else:
+                name = LEADING_NAME.match(expression)
+                if name and name.group(1) not in ("true", "false", "None", "nil", "null", "new"):
+                    model = model_of(name.group(1))
+                if model not in models and declared:
+                    model = next((t for t in reversed(TYPE_NAME.findall(declared)) if t in models), model)
+        fields = list(dict.fromkeys(names + (models[model].fields if model in models else [])))
+        fields = [
+            f for f in fields if classify(f) and _attribute(f) not in removed and (not whitelist or _attribute(f) in whitelist)
+        ]
+        if fields:
+            found.append(ResponseFields(index + 1, line.strip()[:300], model if model in models else None, fields))
+    return found
+
+
+def _model_of(body: list[str], name: str, models: dict[str, ModelFields]) -> str | None:
+    """Model a returned variable holds, trying singular names for collections."""
+    bare = name.lstrip("@$")
+    body = [line for line in body if bare in line and len(line) <= MAX_LINE_LENGTH]
+    model = _bound_model(body, name)
+    if model in models:
+        return model
+    queries = [re.compile(GO_SLICE.format(name=re.escape(bare))), re.compile(LOADED_BY_QUERY.format(name=re.escape(bare)))]
+    loaded = next((m.group(1).split(".")[-1] for line in body for q in queries for m in [q.search(line)] if m), None)
+    if loaded in models:
+        return loaded
+    singular = re.sub(r"(?:ies)$", "y", model) if model and model.endswith("ies") else (model or "").rstrip("s")
+    return singular if singular in models else model
+
+
+class ResponseExposureService(AbacConditionService):
+    """Compares the sensitive fields endpoints return with the roles their policies admit."""
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self._query(Repository)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @classmethod
+    def _models(cls, root: Path, files: dict[str, _SourceFile | None], classify: FieldClassifier) -> dict[str, ModelFields]:
+        """Sensitive fields of the models and serializers declared across a clone."""
+        models: dict[str, ModelFields] = {}
+        python: dict[str, str] = {}
+        for path in sorted(root.rglob("*")):
+            relative = path.relative_to(root).as_posix()
+            if path.suffix not in ROUTE_FILE_EXTENSIONS or SKIPPED_DIRECTORIES & set(Path(relative).parts):
+                continue
+            source = cls._load(root, relative, files)
+            if source is None:
+                continue
+            text = "\n".join(source.lines)
+            # Every field is kept for now: serializers list fields of their model by name
+            for model in parse_models(relative, text, keep=lambda f: True, output=True):
+                models.setdefault(model.name, model)
+            if path.suffix == ".py" and "Serializer" in text:
+                python[relative] = text
+        for name, serializer in serializer_models(python, models).items():
+            models.setdefault(name, serializer)
+        for model in models.values():
+            model.fields = [f for f in model.fields if classify(f)]
+        return {name: model for name, model in models.items() if model.fields}
+
+    def exposures(self, repository_id: int | None = None, severity: str | None = None) -> dict:
+        """Sensitive response fields reaching roles the visibility policy does not allow.
+
+        Args:
+            repository_id: Restrict to one repository
+            severity: Keep only this severity
+
+        Returns:
+            Findings most severe first, and counts per severity and sensitivity class
+
+        Raises:
+            ValueError: If a requested repository does not exist, or the visibility policy file is invalid
+        """
+        classify = FieldClassifier(load_visibility_policy())
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        results, scanned = [], 0
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            if not root.is_dir():
+                continue
+            scanned += 1
+            found = self.scan_repository(repository, root, classify)
+            logger.info("response_exposures_detected", repository_id=repository.id, findings=len(found))
+            results.extend({**asdict(f), "repository_id": repository.id, "repository_name": repository.name} for f in found)
+
+        if severity is not None:
+            results = [r for r in results if r["severity"] == severity.lower()]
+        results.sort(key=lambda r: (SEVERITY_ORDER[r["severity"]], r["repository_id"], r["file_path"], r["line_start"]))
+
+        by_severity: dict[str, int] = {}
+        by_class: dict[str, int] = {}
+        for result in results:
+            by_severity[result["severity"]] = by_severity.get(result["severity"], 0) + 1
+            for name in dict.fromkeys(result["fields"].values()):
+                by_class[name] = by_class.get(name, 0) + 1
+        return {
+            "findings": results,
+            "summary": {
+                "total": len(results),
+                "repositories_scanned": scanned,
+                "repositories_with_findings": len({r["repository_id"] for r in results}),
+                "by_severity": by_severity,
+                "by_class": by_class,
+            },
+        }
+
+    def scan_repository(self, repository: Repository, root: Path, classify: FieldClassifier) -> list[ExposureFinding]:
+        """Exposure findings for one cloned repository."""
+        policies = self.db.query(Policy).filter(Policy.repository_id == repository.id).all()
+        if not policies:
+            return []
+
+        # Callers per endpoint across its policies: roles, or None once any authenticated (or anonymous) caller is admitted
+        endpoints: dict[str, dict] = {}
+        located: dict[str, list[dict]] = {}
+        for policy in policies:
+            rule = EndpointMappingService.map_policy(policy)
+            endpoint = endpoints.setdefault(rule.key, {"roles": set(), "open": False, "anonymous": False, "policies": []})
+            endpoint["roles"] |= {r.upper() for r in rule.roles}
+            endpoint["open"] = endpoint["open"] or not rule.roles
+            endpoint["anonymous"] = endpoint["anonymous"] or not rule.requires_authentication
+            endpoint["policies"].append(policy.id)
+            located.setdefault(rule.key, []).append(self.located(policy))
+
+        responses = run_pass(root, exposed_responses, endpoints=list(located.values()), visibility=classify.policy)
+        findings = []
+        for (key, endpoint), found in zip(endpoints.items(), responses, strict=True):
+            for path, function, response in found:
+                finding = self._finding(key, endpoint, path, function, ResponseFields(**response), classify)
+                if finding:
+                    findings.append(finding)
+        return findings
+
+    @staticmethod
+    def _finding(key: str, endpoint: dict, path: str, function: str | None, response: ResponseFields, classify: FieldClassifier):
+        """A finding for the fields of one response some admitted caller may not see, or None."""
+        callers = sorted(endpoint["roles"])
+        fields, unauthorized = {}, set()
+        for name in response.fields:
+            sensitivity = classify(name)
+            allowed = classify.allowed(sensitivity)
+            denied = {r for r in callers if r not in allowed}
+            if endpoint["open"] or denied:
+                fields[name] = sensitivity
+                unauthorized |= denied
+        if not fields:
+            return None
+        severity = "critical" if endpoint["anonymous"] else min((classify.severity(c) for c in fields.values()), key=SEVERITY_ORDER.get)
+        callers_text = (
+            "anonymous callers" if endpoint["anonymous"] else "any authenticated user" if endpoint["open"] else ", ".join(sorted(unauthorized))
+        )
+        allowed_text = sorted({r for c in fields.values() for r in classify.allowed(c)})
+        shown = ", ".join(f"{name} ({sensitivity.replace('_', ' ')})" for name, sensitivity in fields.items())
+        description = (
+            f"{key} returns {shown} to {callers_text}, which the data visibility policy restricts to "
+            + (", ".join(allowed_text) if allowed_text else "no role")
+        )
+        return ExposureFinding(
+            endpoint=key,
+            file_path=path,
+            function=function,
+            line_start=response.line,
+            line_end=response.line,
+            model=response.model,
+            fields=fields,
+            caller_roles=callers,
+            unauthorized_roles=sorted(unauthorized),
+            anonymous=endpoint["anonymous"],
+            severity=severity,
+            description=description,
+            policy_ids=endpoint["policies"],
+        )
+
+
+def exposed_responses(root: str, endpoints: list[list[dict]], visibility: dict) -> Iterator[list[list]]:
+    """Sandbox pass yielding, per endpoint, the sensitive fields its handlers return.
+
+    Args:
+        root: Repository clone root
+        endpoints: Per endpoint, the route and evidence of each of its policies (see AbacConditionService.located)
+        visibility: Data visibility policy
+
+    Yields:
+        Per endpoint, in order: [file path, handler function, response fields] of each response
+    """
+    clone = Path(root)
+    classify = FieldClassifier(visibility)
+    files: dict[str, _SourceFile | None] = {}
+    index: dict = {}
+    models = ResponseExposureService._models(clone, files, classify)
+    for policies in endpoints:
+        handlers = []
+        for policy in policies:
+            for handler in ResponseExposureService._located(clone, policy["route"], policy["evidence"], files, index):
+                if handler[:2] not in [h[:2] for h in handlers]:
+                    handlers.append(handler)
+        yield [
+            [source.path, next((name for s, _, name in source.handlers if s == start), None), asdict(response)]
+            for source, start, end in handlers
+            for response in response_fields(source.lines, start, end, models, classify)
+        ]
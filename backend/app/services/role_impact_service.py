@@ -0,0 +1,313 @@
+"""Service for what-if impact analysis of role changes.
+
+Answers questions like "if we remove MANAGER, or merge it into LEAD, which
+endpoints and which users are affected?" The analysis runs over the mined
+policies viewed as endpoint rules, joined with any role-assignment data that
+has been ingested for the tenant. Without assignment data only the endpoint
+side of the report is populated.
+"""
+
+import re
+from collections import defaultdict
+from dataclasses import asdict, dataclass, field
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.role_assignment import RoleAssignment
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+
+logger = structlog.get_logger(__name__)
+
+
+class RoleChangeOperation:
+    """Supported hypothetical role changes."""
+
+    REMOVE = "remove"  # Delete the role; holders lose what only it granted
+    MERGE = "merge"  # Fold the role into a target role; holders become target holders
+
+
+class EndpointImpact:
+    """How an endpoint's access changes."""
+
+    ORPHANED = "orphaned"  # No role left that may call it
+    NARROWED = "narrowed"  # Fewer roles may call it
+    WIDENED = "widened"  # Holders of another role gain access through the merge
+    CONSOLIDATED = "consolidated"  # Both merged roles were allowed; nobody gains or loses
+    CONDITION_REFERENCE = "condition_reference"  # Only a condition mentions the role
+
+
+@dataclass
+class RoleChange:
+    """A hypothetical role change."""
+
+    operation: str
+    role: str
+    target_role: str | None = None
+
+    def apply(self, roles: list[str] | set[str]) -> list[str]:
+        """Return the roles that remain after the change.
+
+        Args:
+            roles: Roles before the change
+
+        Returns:
+            Sorted roles after the change
+        """
+        result = {r for r in roles if r != self.role}
+        if self.operation == RoleChangeOperation.MERGE and self.role in roles:
+            result.add(self.target_role)
+        return sorted(result)
+
+
+@dataclass
+class EndpointChange:
+    """Impact of a role change on one endpoint."""
+
+    endpoint: str
+    method: str
+    path: str
+    policy_ids: list[int]
+    impact: str
+    roles_before: list[str]
+    roles_after: list[str]
+    conditions_referencing_role: list[str] = field(default_factory=list)
+    users_losing_access: list[str] = field(default_factory=list)
+    users_gaining_access: list[str] = field(default_factory=list)
+
+
+class RoleImpactService:
+    """Computes what-if impact of removing or merging roles."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def ingest_assignments(
+        self,
+        assignments: list[dict],
+        source: str | None = None,
+        replace: bool = False,
+    ) -> int:
+        """Store user-to-role assignments.
+
+        Args:
+            assignments: Dicts with user_identifier, role, and optional application_id
+            source: Where the data came from (e.g., "okta")
+            replace: Delete previously ingested assignments from the same source first
+
+        Returns:
+            Number of assignments stored
+        """
+        if replace:
+            query = self.db.query(RoleAssignment).filter(RoleAssignment.source == source)
+            if self.tenant_id:
+                query = query.filter(RoleAssignment.tenant_id == self.tenant_id)
+            query.delete(synchronize_session=False)
+
+        for assignment in assignments:
+            self.db.add(
+                RoleAssignment(
+                    tenant_id=self.tenant_id,
+                    user_identifier=assignment["user_identifier"].strip(),
+                    role=assignment["role"].strip().upper(),
+                    application_id=assignment.get("application_id"),
+                    source=source,
+                )
+            )
+        self.db.commit()
+
+        logger.info("role_assignments_ingested", count=len(assignments), source=source, replace=replace)
+        return len(assignments)
+
+    def load_assignments(self, application_id: int | None = None) -> dict[str, set[str]]:
+        """Load role memberships grouped by user.
+
+        Args:
+            application_id: Include application-specific assignments for this
+                application in addition to global ones
+
+        Returns:
+            Mapping of user identifier to roles
+        """
+        query = self.db.query(RoleAssignment)
+        if self.tenant_id:
+            query = query.filter(RoleAssignment.tenant_id == self.tenant_id)
+
+        users: dict[str, set[str]] = defaultdict(set)
+        for assignment in query.all():
+            if assignment.application_id not in (None, application_id):
+                continue
+            users[assignment.user_identifier].add(assignment.role.upper())
+        return dict(users)
+
+    def analyze(
+        self,
+        operation: str,
+        role: str,
+        target_role: str | None = None,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+    ) -> dict:
+        """Analyze the impact of a hypothetical role change.
+
+        Args:
+            operation: "remove" or "merge"
+            role: Role being removed or merged away
+            target_role: Role to merge into (required for merge)
+            repository_id: Restrict the analysis to one repository
+            application_id: Restrict the analysis to one application
+
+        Returns:
+            Impact report with affected endpoints and users
+
+        Raises:
+            ValueError: If the change is not well-formed
+        """
+        change = RoleChange(
+            operation=operation,
+            role=role.strip().upper(),
+            target_role=target_role.strip().upper() if target_role else None,
+        )
+        if operation not in (RoleChangeOperation.REMOVE, RoleChangeOperation.MERGE):
+            raise ValueError(f"Unsupported operation: {operation}")
+        if operation == RoleChangeOperation.MERGE and not change.target_role:
+            raise ValueError("A target role is required to merge a role")
+        if change.target_role == change.role:
+            raise ValueError("Cannot merge a role into itself")
+
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(
+            repository_id=repository_id, application_id=application_id
+        )
+        rules = EndpointMappingService.map_policies(policies)
+        users = self.load_assignments(application_id)
+
+        endpoints = [
+            endpoint for rule in rules if (endpoint := self.endpoint_change(rule, change, users)) is not None
+        ]
+        user_impacts = self._user_impacts(endpoints, users, change)
+
+        logger.info(
+            "role_change_impact_analyzed",
+            operation=operation,
+            role=change.role,
+            target_role=change.target_role,
+            endpoints_affected=len(endpoints),
+            users_affected=len(user_impacts),
+        )
+
+        return {
+            "operation": operation,
+            "role": change.role,
+            "target_role": change.target_role,
+            "role_known": change.role in EndpointMappingService.collect_roles(rules),
+            "has_assignment_data": bool(users),
+            "endpoints_affected": len(endpoints),
+            "users_affected": len(user_impacts),
+            "endpoints": [asdict(e) for e in endpoints],
+            "users": user_impacts,
+        }
+
+    @staticmethod
+    def endpoint_change(
+        rule: EndpointRule, change: RoleChange, users: dict[str, set[str]]
+    ) -> EndpointChange | None:
+        """Compute the impact of a role change on one endpoint.
+
+        Endpoints open to any authenticated user are unaffected by role
+        changes unless a condition mentions the role.
+
+        Args:
+            rule: Endpoint rule
+            change: Hypothetical change
+            users: Role memberships by user
+
+        Returns:
+            EndpointChange, or None if the endpoint is unaffected
+        """
+        mention = re.compile(rf"\b{re.escape(change.role)}\b", re.IGNORECASE)
+        conditions = [c for c in rule.conditions if mention.search(c)]
+
+        before = sorted(rule.roles)
+        after = change.apply(rule.roles) if rule.roles else []
+
+        if change.role in rule.roles:
+            if not after:
+                impact = EndpointImpact.ORPHANED
+            elif change.operation == RoleChangeOperation.MERGE:
+                impact = (
+                    EndpointImpact.CONSOLIDATED
+                    if change.target_role in rule.roles
+                    else EndpointImpact.WIDENED
+                )
+            else:
+                impact = EndpointImpact.NARROWED
+        elif rule.roles and change.operation == RoleChangeOperation.MERGE and change.target_role in rule.roles:
+            # Holders of the merged role become target holders and gain access
+            impact = EndpointImpact.WIDENED
+        elif conditions:
+            impact = EndpointImpact.CONDITION_REFERENCE
+        else:
+            return None
+
+        losing, gaining = [], []
+        if rule.roles:
+            for user, roles in sorted(users.items()):
+                had = bool(roles & set(rule.roles))
+                has = bool(set(change.apply(roles)) & set(after))
+                if had and not has:
+                    losing.append(user)
+                elif has and not had:
+                    gaining.append(user)
+
+        return EndpointChange(
+            endpoint=rule.key,
+            method=rule.method,
+            path=rule.path,
+            policy_ids=list(rule.policy_ids),
+            impact=impact,
+            roles_before=before,
+            roles_after=after,
+            conditions_referencing_role=conditions,
+            users_losing_access=losing,
+            users_gaining_access=gaining,
+        )
+
+    @staticmethod
+    def _user_impacts(
+        endpoints: list[EndpointChange], users: dict[str, set[str]], change: RoleChange
+    ) -> list[dict]:
+        """Summarize per-user access changes.
+
+        Args:
+            endpoints: Affected endpoints
+            users: Role memberships by user
+            change: Hypothetical change
+
+        Returns:
+            One entry per user whose roles or endpoint access change
+        """
+        lost: dict[str, list[str]] = defaultdict(list)
+        gained: dict[str, list[str]] = defaultdict(list)
+        for endpoint in endpoints:
+            for user in endpoint.users_losing_access:
+                lost[user].append(endpoint.endpoint)
+            for user in endpoint.users_gaining_access:
+                gained[user].append(endpoint.endpoint)
+
+        impacts = []
+        for user, roles in sorted(users.items()):
+            if change.role not in roles and user not in lost and user not in gained:
+                continue
+            impacts.append(
+                {
+                    "user_identifier": user,
+                    "roles_before": sorted(roles),
+                    "roles_after": change.apply(roles),
+                    "endpoints_lost": lost.get(user, []),
+                    "endpoints_gained": gained.get(user, []),
+                }
+            )
+        return impacts
@@ -0,0 +1,443 @@
+"""Bind dynamic role requirements to the values they stand for.
+
+A guard such as RequireRole(cfg.ApproverRole) reads its role from
+configuration or a database at runtime, so the analyzers record the rule
+with the role "${cfg.ApproverRole}" instead of dropping it. Users bind a
+parameter to one or more values, workspace-wide or for one repository, and
+every policy using it is rendered with those values. The extracted text is
+kept, so a policy is rendered again when a binding changes or is removed.
+
+A scan can also be given a snapshot of the deployment's configuration and
+environment variables. Parameters the snapshot has a value for, such as a
+threshold compared in "amount > ${cfg.MaxSelfApproveAmount}", are bound
+for the repository from it, and each rendered policy records which binding
+supplied each value and whether it came from a scan's snapshot.
+"""
+
+import re
+from pathlib import Path
+
+import structlog
+from sqlalchemy import or_
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.models.role_parameter import BindingSource, RoleParameterBinding, RoleParameterUsage
+from app.services.config_policy_extractor import ROLE_PARAMETER
+
+logger = structlog.get_logger(__name__)
+
+PARAMETER_NAME = re.compile(r"^[^{}\s]+$")
+MAX_NAME_LENGTH = 255
+MAX_VALUES = 50
+MAX_SNAPSHOT_DEPTH = 10
+# Roots of selectors that stand for the configuration object itself: cfg.Limits.Max reads limits.max
+CONFIG_OBJECTS = {"cfg", "conf", "config", "configuration", "settings", "options", "opts", "viper", "app_config", "appconfig"}
+
+
+def parameters_in(*texts: str | None) -> list[str]:
+    """Names of the role parameters referenced in policy text, in order of appearance."""
+    names: list[str] = []
+    for text in texts:
+        for name in ROLE_PARAMETER.findall(text or ""):
+            if name not in names:
+                names.append(name)
+    return names
+
+
+def render(text: str | None, values: dict[str, list[str]]) -> str | None:
+    """Replace bound parameters with their values; unbound ones stay "${name}"."""
+    if text is None:
+        return None
+    return ROLE_PARAMETER.sub(lambda m: " or ".join(values[m.group(1)]) if m.group(1) in values else m.group(0), text)
+
+
+def normalize_values(name: str, values) -> list[str]:
+    """Validate a binding's values; a single value may be given as a string.
+
+    Raises:
+        ValueError: If the name or values are invalid
+    """
+    if not isinstance(name, str) or not PARAMETER_NAME.match(name) or len(name) > MAX_NAME_LENGTH:
+        raise ValueError(f"Invalid parameter name '{name}'; use the name shown without ${{}}")
+    if isinstance(values, str):
+        values = [values]
+    if not isinstance(values, list) or not values or not all(isinstance(v, str) and v.strip() for v in values):
+        raise ValueError(f"Parameter '{name}': values must be a non-empty string or list of strings")
+    if len(values) > MAX_VALUES:
+        raise ValueError(f"Parameter '{name}': at most {MAX_VALUES} values can be bound")
+    return list(dict.fromkeys(v.strip() for v in values))
+
+
+def _key(name: str) -> str:
+    """Spelling-insensitive key: MaxSelfApproveAmount, max_self_approve_amount and max-self-approve-amount match."""
+    return re.sub(r"[_\-\s]", "", name).lower()
+
+
+def _flatten(value, prefix: str = "", depth: int = 0) -> dict[str, object]:
+    """Dotted paths of a nested config document to their leaf values."""
+    if not isinstance(value, dict) or depth > MAX_SNAPSHOT_DEPTH:
+        return {prefix: value} if prefix else {}
+    flat: dict[str, object] = {}
+    for key, child in value.items():
+        flat.update(_flatten(child, f"{prefix}.{key}" if prefix else str(key), depth + 1))
+    return flat
+
+
+def _snapshot_value(value) -> list[str] | None:
+    """Binding values of a snapshot leaf, or None for values that cannot be bound."""
+    if isinstance(value, bool):
+        return [str(value).lower()]
+    if isinstance(value, (str, int, float)):
+        return [str(value)] if str(value).strip() else None
+    if isinstance(value, list) and value and all(isinstance(v, (str, int, float)) and not isinstance(v, bool) for v in value):
+        return [str(v) for v in value]
+    return None
+
+
+def snapshot_values(names: list[str], snapshot: dict) -> dict[str, list[str]]:
+    """Values a config and environment snapshot gives the named parameters.
+
+    "env.NAME" reads the snapshot's env variables; "config.a.b" and
+    selectors into a config object ("cfg.Limits.MaxAmount") read its config
+    document by path, ignoring case and underscores so Go field names match
+    YAML keys. Other selectors are looked up by their full path.
+
+    Args:
+        names: Parameter names without "${}"
+        snapshot: {"env": {NAME: value}, "config": nested document}
+
+    Returns:
+        Name to values, for the names the snapshot resolves
+    """
+    env = {str(k): v for k, v in (snapshot.get("env") or {}).items()}
+    config = {_key(path): value for path, value in _flatten(snapshot.get("config") or {}).items()}
+    resolved = {}
+    for name in names:
+        root, _, rest = name.partition(".")
+        if root == "env":
+            value = env.get(rest)
+        else:
+            paths = [rest] if root.lower() in CONFIG_OBJECTS else []
+            paths.append(name)
+            value = next((config[_key(p)] for p in paths if p and _key(p) in config), None)
+        values = _snapshot_value(value) if value is not None else None
+        if values is not None:
+            resolved[name] = values[:MAX_VALUES]
+    return resolved
+
+
+def _provenance(binding: RoleParameterBinding) -> dict:
+    """How a binding supplied a parameter's values, as recorded on the policies it rendered."""
+    return {
+        "values": binding.values,
+        "source": binding.source or BindingSource.MANUAL,
+        "scope": "workspace" if binding.repository_id is None else "repository",
+        "binding_id": binding.id,
+        "scan_id": binding.scan_id,
+        "bound_by": binding.bound_by,
+    }
+
+
+class RoleParameterService:
+    """Records policies using role parameters and renders them with bound values."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if not repository:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def get_binding(self, binding_id: int) -> RoleParameterBinding:
+        """Get a binding.
+
+        Raises:
+            ValueError: If the binding does not exist
+        """
+        binding = self._query(RoleParameterBinding).filter(RoleParameterBinding.id == binding_id).first()
+        if not binding:
+            raise ValueError(f"Role parameter binding {binding_id} not found")
+        return binding
+
+    def bindings(self, repository_id: int | None = None) -> list[RoleParameterBinding]:
+        """Workspace bindings and, when a repository is given, only those applying to it."""
+        query = self._query(RoleParameterBinding)
+        if repository_id is not None:
+            query = query.filter(
+                or_(RoleParameterBinding.repository_id.is_(None), RoleParameterBinding.repository_id == repository_id)
+            )
+        return query.order_by(RoleParameterBinding.name).all()
+
+    def _effective(self, bindings: list[RoleParameterBinding], repository_id: int) -> dict[str, RoleParameterBinding]:
+        """Effective bindings in a repository: its own bindings override workspace ones."""
+        effective = {b.name: b for b in bindings if b.repository_id is None}
+        effective.update({b.name: b for b in bindings if b.repository_id == repository_id})
+        return effective
+
+    def _usages(self, repository_id: int | None = None) -> list[RoleParameterUsage]:
+        """Usages, optionally of one repository."""
+        query = self._query(RoleParameterUsage)
+        if repository_id is not None:
+            query = query.filter(RoleParameterUsage.repository_id == repository_id)
+        return query.all()
+
+    def record_usages(
+        self, repository_id: int, snapshot: dict | None = None, scan_id: int | None = None
+    ) -> list[RoleParameterUsage]:
+        """Record the policies of a freshly scanned repository that use role parameters, then render them.
+
+        A policy whose text still contains placeholders was just extracted,
+        so its text becomes the template. A recorded policy whose text no
+        longer matches what was last rendered was edited, and is left alone.
+
+        Args:
+            repository_id: Repository that was just scanned
+            snapshot: Config and environment snapshot supplied with the scan
+            scan_id: The scan
+
+        Returns:
+            The repository's usages
+        """
+        usages = {u.policy_id: u for u in self._usages(repository_id)}
+        policies = (
+            self._query(Policy)
+            .filter(Policy.repository_id == repository_id)
+            .filter(or_(Policy.subject.contains("${"), Policy.conditions.contains("${")))
+            .all()
+        )
+        templated = set()
+        for policy in policies:
+            names = parameters_in(policy.subject, policy.conditions)
+            if not names:
+                continue
+            templated.add(policy.id)
+            usage = usages.get(policy.id)
+            if usage is None:
+                usage = RoleParameterUsage(tenant_id=self.tenant_id, repository_id=repository_id, policy_id=policy.id)
+                self.db.add(usage)
+                usages[policy.id] = usage
+            usage.parameters = names
+            usage.subject_template = usage.rendered_subject = policy.subject
+            usage.conditions_template = usage.rendered_conditions = policy.conditions
+
+        rendered = [policy_id for policy_id in usages if policy_id not in templated]
+        if rendered:
+            current = {p.id: (p.subject, p.conditions) for p in self._query(Policy).filter(Policy.id.in_(rendered)).all()}
+            for policy_id in rendered:
+                usage = usages[policy_id]
+                if current.get(policy_id) != (usage.rendered_subject, usage.rendered_conditions):
+                    self.db.delete(usage)
+                    del usages[policy_id]
+        self.db.commit()
+        if snapshot:
+            self.apply_snapshot(repository_id, snapshot, scan_id)
+        else:
+            self.apply(repository_id)
+        logger.info("role_parameter_usages_recorded", repository_id=repository_id, usages=len(usages))
+        return list(usages.values())
+
+    def apply_snapshot(self, repository_id: int, snapshot: dict, scan_id: int | None = None) -> dict:
+        """Bind the repository's parameters from a config and environment snapshot and render its policies.
+
+        Only parameters the repository's policies use are read from the
+        snapshot, so the rest of it (credentials included) is never stored.
+        A value from the snapshot replaces the repository's own binding of
+        the same parameter, since it is what the scanned deployment runs with.
+
+        Args:
+            repository_id: Repository whose policies to render
+            snapshot: {"env": {NAME: value}, "config": nested document}
+            scan_id: Scan the snapshot was supplied with
+
+        Returns:
+            Parameters resolved and left unresolved, and the number of policies whose text changed
+
+        Raises:
+            ValueError: If the snapshot is not an object of env and config objects
+        """
+        if not isinstance(snapshot, dict) or any(
+            not isinstance(snapshot.get(part) or {}, dict) for part in ("env", "config")
+        ):
+            raise ValueError('Snapshot must be an object with "env" and "config" objects')
+        names = sorted({name for usage in self._usages(repository_id) for name in usage.parameters})
+        resolved = snapshot_values(names, snapshot)
+        existing = {
+            b.name: b
+            for b in self._query(RoleParameterBinding).filter(RoleParameterBinding.repository_id == repository_id).all()
+        }
+        for name, values in resolved.items():
+            binding = existing.get(name)
+            if binding is None:
+                binding = RoleParameterBinding(tenant_id=self.tenant_id, repository_id=repository_id, name=name)
+                self.db.add(binding)
+            binding.values = values
+            binding.source = BindingSource.SCAN_SNAPSHOT
+            binding.scan_id = scan_id
+            binding.bound_by = None
+        self.db.commit()
+        changed = self.apply(repository_id)
+        logger.info(
+            "role_parameter_snapshot_applied",
+            repository_id=repository_id,
+            scan_id=scan_id,
+            resolved=len(resolved),
+            policies=changed,
+        )
+        return {
+            "repository_id": repository_id,
+            "scan_id": scan_id,
+            "resolved": resolved,
+            "unresolved": [name for name in names if name not in resolved],
+            "policies_updated": changed,
+        }
+
+    def apply(self, repository_id: int | None = None) -> int:
+        """Render every usage with the current bindings.
+
+        Args:
+            repository_id: Only render this repository's policies
+
+        Returns:
+            Number of policies whose text changed
+        """
+        usages = self._usages(repository_id)
+        if not usages:
+            return 0
+        bindings = self.bindings()
+        policies = {p.id: p for p in self._query(Policy).filter(Policy.id.in_([u.policy_id for u in usages])).all()}
+        changed = 0
+        for usage in usages:
+            policy = policies.get(usage.policy_id)
+            if policy is None:
+                continue
+            effective = self._effective(bindings, usage.repository_id)
+            values = {name: b.values for name, b in effective.items()}
+            subject = render(usage.subject_template, values)
+            conditions = render(usage.conditions_template, values)
+            if (policy.subject, policy.conditions) != (subject, conditions):
+                policy.subject, policy.conditions = subject, conditions
+                changed += 1
+            usage.rendered_subject, usage.rendered_conditions = subject, conditions
+            usage.provenance = {name: _provenance(effective[name]) for name in usage.parameters if name in effective}
+        self.db.commit()
+        return changed
+
+    def provenance(self, policy_id: int) -> dict:
+        """The bindings a policy's parameters were last rendered with.
+
+        Raises:
+            ValueError: If the policy uses no role parameters
+        """
+        usage = self._query(RoleParameterUsage).filter(RoleParameterUsage.policy_id == policy_id).first()
+        if not usage:
+            raise ValueError(f"Policy {policy_id} uses no role parameters")
+        provenance = usage.provenance or {}
+        return {
+            "policy_id": policy_id,
+            "repository_id": usage.repository_id,
+            "subject_template": usage.subject_template,
+            "conditions_template": usage.conditions_template,
+            "bindings": [{"name": name, **provenance[name]} for name in usage.parameters if name in provenance],
+            "unbound": [name for name in usage.parameters if name not in provenance],
+        }
+
+    def parameters(self, repository_id: int | None = None) -> list[dict]:
+        """Every parameter in use, with the policies using it and the values it is bound to.
+
+        Args:
+            repository_id: Only parameters used in this repository
+
+        Returns:
+            One row per parameter, by name
+        """
+        bindings = self.bindings()
+        rows: dict[str, dict] = {}
+        for usage in self._usages(repository_id):
+            for name in usage.parameters:
+                row = rows.setdefault(name, {"name": name, "policy_ids": [], "repository_ids": [], "values": None})
+                row["policy_ids"].append(usage.policy_id)
+                if usage.repository_id not in row["repository_ids"]:
+                    row["repository_ids"].append(usage.repository_id)
+        for row in rows.values():
+            row["values"] = next((b.values for b in bindings if b.name == row["name"] and b.repository_id is None), None)
+            row["overrides"] = {
+                b.repository_id: b.values
+                for b in bindings
+                if b.name == row["name"] and b.repository_id is not None and repository_id in (None, b.repository_id)
+            }
+            # Bound when every repository using it renders concrete values
+            row["bound"] = all(row["values"] is not None or r in row["overrides"] for r in row["repository_ids"])
+        return [rows[name] for name in sorted(rows)]
+
+    def bind_all(
+        self, bindings: dict, repository_id: int | None = None, bound_by: str | None = None
+    ) -> list[RoleParameterBinding]:
+        """Bind parameters to values and render the policies using them.
+
+        Nothing is bound unless every binding is valid.
+
+        Args:
+            bindings: Parameter name to a value or list of values
+            repository_id: Bind for this repository only; None binds workspace-wide
+            bound_by: User email
+
+        Returns:
+            The bindings written
+
+        Raises:
+            ValueError: If a name or its values are invalid
+        """
+        normalized = {name: normalize_values(name, values) for name, values in bindings.items()}
+        scope = (
+            RoleParameterBinding.repository_id.is_(None)
+            if repository_id is None
+            else RoleParameterBinding.repository_id == repository_id
+        )
+        existing = {b.name: b for b in self._query(RoleParameterBinding).filter(scope).all()}
+        written = []
+        for name, values in normalized.items():
+            binding = existing.get(name)
+            if binding is None:
+                binding = RoleParameterBinding(tenant_id=self.tenant_id, repository_id=repository_id, name=name)
+                self.db.add(binding)
+            binding.values = values
+            binding.source = BindingSource.MANUAL
+            binding.scan_id = None
+            binding.bound_by = bound_by
+            written.append(binding)
+        self.db.commit()
+        changed = self.apply(repository_id)
+        logger.info("role_parameters_bound", repository_id=repository_id, parameters=len(written), policies=changed)
+        return written
+
+    def unbind(self, binding_id: int) -> None:
+        """Remove a binding and render the policies using it again.
+
+        Raises:
+            ValueError: If the binding does not exist
+        """
+        binding = self.get_binding(binding_id)
+        repository_id = binding.repository_id
+        self.db.delete(binding)
+        self.db.commit()
+        self.apply(repository_id)
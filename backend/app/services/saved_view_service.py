@@ -0,0 +1,268 @@
+"""Service for saved views (named filter combinations).
+
+Recurring review workflows ("payments-team critical findings") start from the
+same filters every time. A saved view stores those filters under a name in
+the workspace and carries an opaque share token, so a link to the view can be
+passed to teammates.
+"""
+
+import secrets
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.saved_view import SavedView
+
+logger = structlog.get_logger(__name__)
+
+MAX_FILTERS = 50
+
+SHARE_PATH_PREFIX = "/api/v1/saved-views/shared/"
+
+
+class SavedViewType:
+    """Pages whose filters can be saved."""
+
+    POLICIES = "policies"
+    CONFLICTS = "conflicts"
+    INCONSISTENT_ENFORCEMENT = "inconsistent_enforcement"
+    POLICY_FIXES = "policy_fixes"
+    SECRETS = "secrets"
+    CHANGES = "changes"
+    ACCESS_REVIEWS = "access_reviews"
+    PERMISSION_MATRIX = "permission_matrix"
+    POLICY_GRAPH = "policy_graph"
+
+    ALL = (
+        POLICIES,
+        CONFLICTS,
+        INCONSISTENT_ENFORCEMENT,
+        POLICY_FIXES,
+        SECRETS,
+        CHANGES,
+        ACCESS_REVIEWS,
+        PERMISSION_MATRIX,
+        POLICY_GRAPH,
+    )
+
+
+def share_path(view: SavedView) -> str:
+    """Path that resolves a view's share link."""
+    return f"{SHARE_PATH_PREFIX}{view.share_token}"
+
+
+def validate_filters(filters: dict) -> dict:
+    """Check that filters are a flat mapping of names to scalars or scalar lists.
+
+    Args:
+        filters: Filter values keyed by query parameter name
+
+    Returns:
+        The filters
+
+    Raises:
+        ValueError: If the filters are not a flat mapping
+    """
+    if not isinstance(filters, dict):
+        raise ValueError("Filters must be an object")
+    if len(filters) > MAX_FILTERS:
+        raise ValueError(f"A view can have at most {MAX_FILTERS} filters")
+
+    scalar = (str, int, float, bool, type(None))
+    for key, value in filters.items():
+        if not isinstance(key, str) or not key:
+            raise ValueError("Filter names must be non-empty strings")
+        if isinstance(value, list):
+            if not all(isinstance(v, scalar) for v in value):
+                raise ValueError(f"Filter '{key}' must be a list of plain values")
+        elif not isinstance(value, scalar):
+            raise ValueError(f"Filter '{key}' must be a plain value or list")
+    return filters
+
+
+class SavedViewService:
+    """Creates, lists, and resolves saved views."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self):
+        """Query saved views scoped to the current tenant."""
+        query = self.db.query(SavedView)
+        if self.tenant_id:
+            query = query.filter(SavedView.tenant_id == self.tenant_id)
+        return query
+
+    def _ensure_unique(self, name: str, view_type: str, exclude_id: int | None = None) -> None:
+        """Reject a name already used for the same page in this workspace."""
+        query = self._query().filter(SavedView.view_type == view_type).filter(SavedView.name == name)
+        if exclude_id is not None:
+            query = query.filter(SavedView.id != exclude_id)
+        if query.first():
+            raise ValueError(f"A {view_type} view named '{name}' already exists")
+
+    def create_view(
+        self,
+        name: str,
+        view_type: str,
+        filters: dict,
+        description: str | None = None,
+        created_by: str | None = None,
+    ) -> SavedView:
+        """Save a named filter combination.
+
+        Args:
+            name: View name, unique per page within the workspace
+            view_type: Page the filters apply to
+            filters: Filter values keyed by query parameter name
+            description: Optional description
+            created_by: Creator's email
+
+        Returns:
+            Saved view
+
+        Raises:
+            ValueError: If the name, page, or filters are invalid
+        """
+        name = name.strip()
+        if not name:
+            raise ValueError("View name is required")
+        if view_type not in SavedViewType.ALL:
+            raise ValueError(f"Unknown view type '{view_type}'")
+        validate_filters(filters)
+        self._ensure_unique(name, view_type)
+
+        view = SavedView(
+            tenant_id=self.tenant_id,
+            name=name,
+            description=description,
+            view_type=view_type,
+            filters=filters,
+            share_token=secrets.token_urlsafe(24),
+            created_by=created_by,
+        )
+        self.db.add(view)
+        self.db.commit()
+        self.db.refresh(view)
+
+        logger.info("saved_view_created", view_id=view.id, view_type=view_type, tenant_id=self.tenant_id)
+        return view
+
+    def list_views(self, view_type: str | None = None) -> list[SavedView]:
+        """List saved views in the workspace.
+
+        Args:
+            view_type: Restrict to one page
+
+        Returns:
+            Views ordered by name
+        """
+        query = self._query()
+        if view_type:
+            query = query.filter(SavedView.view_type == view_type)
+        return query.order_by(SavedView.name.asc()).all()
+
+    def get_view(self, view_id: int) -> SavedView:
+        """Get a saved view.
+
+        Args:
+            view_id: View ID
+
+        Returns:
+            Saved view
+
+        Raises:
+            ValueError: If the view does not exist
+        """
+        view = self._query().filter(SavedView.id == view_id).first()
+        if not view:
+            raise ValueError(f"Saved view {view_id} not found")
+        return view
+
+    def get_shared_view(self, share_token: str) -> SavedView:
+        """Resolve a share link.
+
+        Share links only resolve inside the workspace that owns the view.
+
+        Args:
+            share_token: Token from the share link
+
+        Returns:
+            Saved view
+
+        Raises:
+            ValueError: If no view has this token
+        """
+        view = self._query().filter(SavedView.share_token == share_token).first()
+        if not view:
+            raise ValueError("Shared view not found")
+        return view
+
+    def update_view(
+        self,
+        view_id: int,
+        name: str | None = None,
+        filters: dict | None = None,
+        description: str | None = None,
+    ) -> SavedView:
+        """Rename a view or replace its filters.
+
+        Args:
+            view_id: View ID
+            name: New name
+            filters: Replacement filters
+            description: New description
+
+        Returns:
+            Updated view
+
+        Raises:
+            ValueError: If the view does not exist or the update is invalid
+        """
+        view = self.get_view(view_id)
+        if name is not None:
+            name = name.strip()
+            if not name:
+                raise ValueError("View name is required")
+            self._ensure_unique(name, view.view_type, exclude_id=view.id)
+            view.name = name
+        if filters is not None:
+            view.filters = validate_filters(filters)
+        if description is not None:
+            view.description = description
+        self.db.commit()
+        self.db.refresh(view)
+        return view
+
+    def regenerate_share_token(self, view_id: int) -> SavedView:
+        """Issue a new share token, invalidating existing links.
+
+        Args:
+            view_id: View ID
+
+        Returns:
+            Updated view
+
+        Raises:
+            ValueError: If the view does not exist
+        """
+        view = self.get_view(view_id)
+        view.share_token = secrets.token_urlsafe(24)
+        self.db.commit()
+        self.db.refresh(view)
+        return view
+
+    def delete_view(self, view_id: int) -> None:
+        """Delete a saved view.
+
+        Args:
+            view_id: View ID
+
+        Raises:
+            ValueError: If the view does not exist
+        """
+        view = self.get_view(view_id)
+        self.db.delete(view)
+        self.db.commit()
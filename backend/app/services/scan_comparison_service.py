@@ -0,0 +1,357 @@
+"""Service for comparing a scan's mined policies with the previous scan.
+
+Change detection records one PolicyChange per added, deleted, or modified
+policy, keyed on the full subject/resource/action/conditions tuple, so an
+edited condition shows up as a delete plus an add. This service pairs those
+back into modifications by resource and action, renders an inline
+before/after diff of the conditions, and attaches the evidence for both
+sides, which is the shape the scan diff UI renders.
+"""
+
+import difflib
+import re
+from collections import defaultdict
+from dataclasses import asdict, dataclass, field
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Evidence
+from app.models.policy_change import ChangeType, PolicyChange
+from app.models.scan_progress import ScanProgress, ScanStatus
+
+logger = structlog.get_logger(__name__)
+
+RULE_FIELDS = ("subject", "resource", "action", "conditions")
+
+
+class ComparisonGroup:
+    """Groups in the scan diff."""
+
+    ADDED = "added"
+    REMOVED = "removed"
+    MODIFIED = "modified"
+
+
+class DiffOp:
+    """Inline diff segment operations."""
+
+    EQUAL = "equal"
+    INSERT = "insert"
+    DELETE = "delete"
+
+
+@dataclass
+class RuleSide:
+    """One side (before or after) of a compared rule."""
+
+    policy_id: int | None
+    subject: str | None
+    resource: str | None
+    action: str | None
+    conditions: str | None
+    evidence: list[dict] = field(default_factory=list)
+
+
+@dataclass
+class RuleComparison:
+    """A rule as it appears in the scan diff."""
+
+    group: str
+    change_ids: list[int]
+    resource: str | None
+    action: str | None
+    before: RuleSide | None
+    after: RuleSide | None
+    changed_fields: list[str] = field(default_factory=list)
+    condition_diff: list[dict] = field(default_factory=list)
+
+
+def _rule_key(resource: str | None, action: str | None) -> tuple[str, str]:
+    """Identity used to pair a removed rule with its replacement."""
+    return ((resource or "").strip().lower(), (action or "").strip().lower())
+
+
+def condition_diff(before: str | None, after: str | None) -> list[dict]:
+    """Render a word-level inline diff of two conditions.
+
+    Args:
+        before: Condition text before the change
+        after: Condition text after the change
+
+    Returns:
+        Ordered segments with op (equal, insert, delete) and text
+    """
+    old_tokens = re.findall(r"\S+|\s+", before or "")
+    new_tokens = re.findall(r"\S+|\s+", after or "")
+    segments: list[dict] = []
+
+    def emit(op: str, tokens: list[str]) -> None:
+        text = "".join(tokens)
+        if not text:
+            return
+        if segments and segments[-1]["op"] == op:
+            segments[-1]["text"] += text
+        else:
+            segments.append({"op": op, "text": text})
+
+    matcher = difflib.SequenceMatcher(a=old_tokens, b=new_tokens, autojunk=False)
+    for tag, i1, i2, j1, j2 in matcher.get_opcodes():
+        if tag == "equal":
+            emit(DiffOp.EQUAL, old_tokens[i1:i2])
+        else:
+            emit(DiffOp.DELETE, old_tokens[i1:i2])
+            emit(DiffOp.INSERT, new_tokens[j1:j2])
+    return segments
+
+
+class ScanComparisonService:
+    """Builds the added/removed/modified view between two scans."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query a model scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def resolve_scans(
+        self, repository_id: int, scan_id: int | None = None
+    ) -> tuple[ScanProgress, ScanProgress | None, ScanProgress | None]:
+        """Find a completed scan and its neighbours.
+
+        Args:
+            repository_id: Repository ID
+            scan_id: Scan to compare (defaults to the latest completed scan)
+
+        Returns:
+            The scan, the previous completed scan, and the next completed scan
+
+        Raises:
+            ValueError: If the repository has no such completed scan
+        """
+        scans = (
+            self._query(ScanProgress)
+            .filter(ScanProgress.repository_id == repository_id)
+            .filter(ScanProgress.status == ScanStatus.COMPLETED)
+            .order_by(ScanProgress.started_at.asc())
+            .all()
+        )
+        if not scans:
+            raise ValueError(f"Repository {repository_id} has no completed scans")
+
+        index = len(scans) - 1
+        if scan_id is not None:
+            index = next((i for i, s in enumerate(scans) if s.id == scan_id), None)
+            if index is None:
+                raise ValueError(f"Completed scan {scan_id} not found for repository {repository_id}")
+
+        previous = scans[index - 1] if index > 0 else None
+        following = scans[index + 1] if index + 1 < len(scans) else None
+        return scans[index], previous, following
+
+    def load_changes(
+        self, repository_id: int, scan: ScanProgress, following: ScanProgress | None
+    ) -> list[PolicyChange]:
+        """Load changes detected by a scan.
+
+        Change detection runs at the end of each scan, so a scan's changes
+        are the ones detected after it started and before the next scan did.
+
+        Args:
+            repository_id: Repository ID
+            scan: Scan to load changes for
+            following: Next completed scan, if any
+
+        Returns:
+            Policy changes in detection order
+        """
+        query = self._query(PolicyChange).filter(PolicyChange.repository_id == repository_id)
+        if scan.started_at:
+            query = query.filter(PolicyChange.detected_at >= scan.started_at)
+        if following and following.started_at:
+            query = query.filter(PolicyChange.detected_at < following.started_at)
+        return query.order_by(PolicyChange.id.asc()).all()
+
+    def load_evidence(self, policy_ids: set[int]) -> dict[int, list[dict]]:
+        """Load evidence for the policies on either side of the diff.
+
+        Args:
+            policy_ids: Policy IDs
+
+        Returns:
+            Evidence dictionaries by policy ID
+        """
+        if not policy_ids:
+            return {}
+        rows = (
+            self.db.query(Evidence)
+            .filter(Evidence.policy_id.in_(policy_ids))
+            .order_by(Evidence.file_path, Evidence.line_start)
+            .all()
+        )
+        evidence: dict[int, list[dict]] = defaultdict(list)
+        for row in rows:
+            evidence[row.policy_id].append(
+                {
+                    "id": row.id,
+                    "file_path": row.file_path,
+                    "line_start": row.line_start,
+                    "line_end": row.line_end,
+                    "code_snippet": row.code_snippet,
+                }
+            )
+        return evidence
+
+    def compare(self, repository_id: int, scan_id: int | None = None) -> dict:
+        """Build the scan diff view.
+
+        Args:
+            repository_id: Repository ID
+            scan_id: Scan to compare with its predecessor (defaults to the latest)
+
+        Returns:
+            Scan identifiers, group counts, and rules grouped by change
+
+        Raises:
+            ValueError: If the repository has no such completed scan
+        """
+        scan, previous, following = self.resolve_scans(repository_id, scan_id)
+        changes = self.load_changes(repository_id, scan, following)
+        rules = self.pair_changes(changes)
+
+        policy_ids = {
+            side.policy_id
+            for rule in rules
+            for side in (rule.before, rule.after)
+            if side is not None and side.policy_id is not None
+        }
+        evidence = self.load_evidence(policy_ids)
+        for rule in rules:
+            for side in (rule.before, rule.after):
+                if side is not None and side.policy_id is not None:
+                    side.evidence = evidence.get(side.policy_id, [])
+
+        groups: dict[str, list[dict]] = {
+            ComparisonGroup.ADDED: [],
+            ComparisonGroup.REMOVED: [],
+            ComparisonGroup.MODIFIED: [],
+        }
+        for rule in sorted(rules, key=lambda r: _rule_key(r.resource, r.action)):
+            groups[rule.group].append(asdict(rule))
+
+        logger.info(
+            "scan_comparison_built",
+            repository_id=repository_id,
+            scan_id=scan.id,
+            added=len(groups[ComparisonGroup.ADDED]),
+            removed=len(groups[ComparisonGroup.REMOVED]),
+            modified=len(groups[ComparisonGroup.MODIFIED]),
+        )
+
+        return {
+            "repository_id": repository_id,
+            "scan_id": scan.id,
+            "previous_scan_id": previous.id if previous else None,
+            "scan_completed_at": scan.completed_at,
+            "previous_scan_completed_at": previous.completed_at if previous else None,
+            "summary": {group: len(items) for group, items in groups.items()},
+            **groups,
+        }
+
+    @staticmethod
+    def pair_changes(changes: list[PolicyChange]) -> list[RuleComparison]:
+        """Turn recorded changes into diff rules.
+
+        A deleted and an added policy on the same resource and action are
+        reported as one modification, since that is how a condition or role
+        edit is recorded.
+
+        Args:
+            changes: Policy changes from one scan
+
+        Returns:
+            Compared rules
+        """
+
+        def before(change: PolicyChange) -> RuleSide:
+            return RuleSide(
+                policy_id=change.previous_policy_id,
+                subject=change.before_subject,
+                resource=change.before_resource,
+                action=change.before_action,
+                conditions=change.before_conditions,
+            )
+
+        def after(change: PolicyChange) -> RuleSide:
+            return RuleSide(
+                policy_id=change.policy_id,
+                subject=change.after_subject,
+                resource=change.after_resource,
+                action=change.after_action,
+                conditions=change.after_conditions,
+            )
+
+        def modified(change_ids: list[int], old: RuleSide, new: RuleSide) -> RuleComparison:
+            return RuleComparison(
+                group=ComparisonGroup.MODIFIED,
+                change_ids=change_ids,
+                resource=new.resource,
+                action=new.action,
+                before=old,
+                after=new,
+                changed_fields=[f for f in RULE_FIELDS if getattr(old, f) != getattr(new, f)],
+                condition_diff=condition_diff(old.conditions, new.conditions),
+            )
+
+        rules: list[RuleComparison] = []
+        added: dict[tuple[str, str], list[PolicyChange]] = defaultdict(list)
+        deleted: dict[tuple[str, str], list[PolicyChange]] = defaultdict(list)
+        for change in changes:
+            if change.change_type == ChangeType.MODIFIED:
+                rules.append(modified([change.id], before(change), after(change)))
+            elif change.change_type == ChangeType.ADDED:
+                added[_rule_key(change.after_resource, change.after_action)].append(change)
+            elif change.change_type == ChangeType.DELETED:
+                deleted[_rule_key(change.before_resource, change.before_action)].append(change)
+
+        for key, additions in added.items():
+            removals = deleted.pop(key, [])
+            for addition, removal in zip(additions, removals, strict=False):
+                rules.append(modified([removal.id, addition.id], before(removal), after(addition)))
+            for addition in additions[len(removals):]:
+                side = after(addition)
+                rules.append(
+                    RuleComparison(
+                        group=ComparisonGroup.ADDED,
+                        change_ids=[addition.id],
+                        resource=side.resource,
+                        action=side.action,
+                        before=None,
+                        after=side,
+                        condition_diff=condition_diff(None, side.conditions),
+                    )
+                )
+            if len(removals) > len(additions):
+                deleted[key] = removals[len(additions):]
+
+        for removals in deleted.values():
+            for removal in removals:
+                side = before(removal)
+                rules.append(
+                    RuleComparison(
+                        group=ComparisonGroup.REMOVED,
+                        change_ids=[removal.id],
+                        resource=side.resource,
+                        action=side.action,
+                        before=side,
+                        after=None,
+                        condition_diff=condition_diff(side.conditions, None),
+                    )
+                )
+        return rules
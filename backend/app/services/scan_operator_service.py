@@ -0,0 +1,392 @@
+"""Service behind the Kubernetes operator reconciling PolicyScan resources.
+
+Platform teams declare scans in-cluster instead of through the UI:
+
+    apiVersion: policyminer.io/v1alpha1
+    kind: PolicyScan
+    metadata:
+      name: expense-api-nightly
+    spec:
+      repositoryId: 4
+      schedule: "0 3 * * 1-5"   # Optional; without it the scan runs once per spec change
+      incremental: true
+      suspend: false
+
+Each resync the operator (``python -m app.operator``) lists the PolicyScans,
+queues the scans that are due on the scan workers, follows each scan's
+progress, and writes the outcome to the resource's status as conditions:
+
+- ``Ready``: the spec is valid and the repository exists;
+- ``Scanning``: a scan is queued or running;
+- ``Succeeded``: how the last finished scan ended.
+
+It then scales the scan worker Deployment to the number of scans in flight,
+one scan per worker, within OPERATOR_MIN_WORKERS and OPERATOR_MAX_WORKERS.
+The operator only talks to the Kubernetes REST API, with its service
+account's token, so it needs no client library.
+"""
+
+import os
+from collections.abc import Callable
+from dataclasses import dataclass
+from datetime import UTC, datetime, timedelta
+from pathlib import Path
+
+import httpx
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.models.repository import Repository
+from app.models.scan_progress import ScanProgress, ScanStatus
+from app.services.scan_schedule_service import next_run
+
+logger = structlog.get_logger(__name__)
+
+GROUP = "policyminer.io"
+VERSION = "v1alpha1"
+PLURAL = "policyscans"
+
+SERVICE_ACCOUNT_DIR = Path("/var/run/secrets/kubernetes.io/serviceaccount")
+
+# A queued scan whose progress has not appeared by then was lost with its worker
+SCAN_START_TIMEOUT = timedelta(hours=1)
+
+IN_FLIGHT = (ScanStatus.QUEUED, ScanStatus.PROCESSING)
+
+
+def timestamp(moment: datetime) -> str:
+    """Kubernetes timestamp of a moment, e.g. 2026-03-06T22:17:00Z."""
+    return moment.astimezone(UTC).strftime("%Y-%m-%dT%H:%M:%SZ")
+
+
+def parse_timestamp(text: str | None) -> datetime | None:
+    """Moment of a Kubernetes timestamp."""
+    if not text:
+        return None
+    return datetime.strptime(text, "%Y-%m-%dT%H:%M:%SZ").replace(tzinfo=UTC)
+
+
+def set_condition(
+    conditions: list[dict], kind: str, status: str, reason: str, message: str, generation: int | None, now: datetime
+) -> None:
+    """Set a condition, keeping its transition time while its status is unchanged."""
+    current = next((c for c in conditions if c["type"] == kind), None)
+    condition = {
+        "type": kind,
+        "status": status,
+        "reason": reason,
+        "message": message,
+        "observedGeneration": generation,
+        "lastTransitionTime": timestamp(now),
+    }
+    if current is None:
+        conditions.append(condition)
+        return
+    if current["status"] == status:
+        condition["lastTransitionTime"] = current["lastTransitionTime"]
+    current.update(condition)
+
+
+class KubernetesClient:
+    """Minimal Kubernetes REST client for PolicyScans and the worker Deployment's scale."""
+
+    def __init__(self, api_url: str, token: str | None = None, ca_path: str | bool = True):
+        """Initialize client.
+
+        Args:
+            api_url: API server URL
+            token: Bearer token; None behind ``kubectl proxy``
+            ca_path: CA bundle verifying the API server, or True for the system's
+        """
+        headers = {"Authorization": f"Bearer {token}"} if token else {}
+        self.http = httpx.Client(base_url=api_url, headers=headers, verify=ca_path, timeout=30.0)
+
+    @classmethod
+    def in_cluster(cls) -> "KubernetesClient":
+        """Client using the pod's service account, or OPERATOR_KUBERNETES_API_URL when set (e.g. kubectl proxy)."""
+        if settings.OPERATOR_KUBERNETES_API_URL:
+            return cls(settings.OPERATOR_KUBERNETES_API_URL)
+        host, port = os.environ["KUBERNETES_SERVICE_HOST"], os.environ["KUBERNETES_SERVICE_PORT"]
+        return cls(
+            f"https://{host}:{port}",
+            token=(SERVICE_ACCOUNT_DIR / "token").read_text().strip(),
+            ca_path=str(SERVICE_ACCOUNT_DIR / "ca.crt"),
+        )
+
+    @staticmethod
+    def own_namespace() -> str:
+        """Namespace the operator runs in."""
+        path = SERVICE_ACCOUNT_DIR / "namespace"
+        return path.read_text().strip() if path.exists() else "default"
+
+    def list_policy_scans(self, namespace: str | None = None) -> list[dict]:
+        """PolicyScans of a namespace, or of every namespace."""
+        scope = f"/namespaces/{namespace}" if namespace else ""
+        response = self.http.get(f"/apis/{GROUP}/{VERSION}{scope}/{PLURAL}")
+        response.raise_for_status()
+        return response.json().get("items", [])
+
+    def patch_status(self, namespace: str, name: str, status: dict) -> None:
+        """Replace a PolicyScan's status."""
+        response = self.http.patch(
+            f"/apis/{GROUP}/{VERSION}/namespaces/{namespace}/{PLURAL}/{name}/status",
+            json={"status": status},
+            headers={"Content-Type": "application/merge-patch+json"},
+        )
+        response.raise_for_status()
+
+    def get_replicas(self, namespace: str, deployment: str) -> int:
+        """Replicas a Deployment is scaled to."""
+        response = self.http.get(f"/apis/apps/v1/namespaces/{namespace}/deployments/{deployment}/scale")
+        response.raise_for_status()
+        return response.json().get("spec", {}).get("replicas", 0)
+
+    def set_replicas(self, namespace: str, deployment: str, replicas: int) -> None:
+        """Scale a Deployment."""
+        response = self.http.patch(
+            f"/apis/apps/v1/namespaces/{namespace}/deployments/{deployment}/scale",
+            json={"spec": {"replicas": replicas}},
+            headers={"Content-Type": "application/merge-patch+json"},
+        )
+        response.raise_for_status()
+
+
+@dataclass
+class PolicyScanSpec:
+    """Spec of a PolicyScan."""
+
+    repository_id: int
+    tenant_id: str | None
+    schedule: str | None
+    incremental: bool
+    suspend: bool
+
+    @classmethod
+    def parse(cls, spec: dict) -> "PolicyScanSpec":
+        """Parse a resource's spec.
+
+        Raises:
+            ValueError: If repositoryId is missing or not a number
+        """
+        repository_id = spec.get("repositoryId")
+        if not isinstance(repository_id, int) or isinstance(repository_id, bool):
+            raise ValueError("spec.repositoryId must be a repository ID")
+        return cls(
+            repository_id=repository_id,
+            tenant_id=spec.get("tenantId") or None,
+            schedule=spec.get("schedule") or None,
+            incremental=spec.get("incremental", True),
+            suspend=spec.get("suspend", False),
+        )
+
+
+class ScanOperatorService:
+    """Reconciles PolicyScan resources with scans and scales the scan workers."""
+
+    def __init__(
+        self,
+        db: Session,
+        kubernetes: KubernetesClient,
+        enqueue: Callable[[int, str | None, bool], str],
+    ):
+        """Initialize service.
+
+        Args:
+            db: Database session
+            kubernetes: Kubernetes API client
+            enqueue: Queues a scan (repository ID, tenant ID, incremental) and returns the task ID
+        """
+        self.db = db
+        self.kubernetes = kubernetes
+        self.enqueue = enqueue
+
+    def _repository(self, spec: PolicyScanSpec) -> Repository | None:
+        """Repository a spec names, within its tenant when it names one."""
+        query = self.db.query(Repository).filter(Repository.id == spec.repository_id)
+        if spec.tenant_id:
+            query = query.filter(Repository.tenant_id == spec.tenant_id)
+        return query.first()
+
+    def _progress(self, repository_id: int, queued_at: datetime) -> ScanProgress | None:
+        """Earliest scan of a repository started since a scan was queued."""
+        return (
+            self.db.query(ScanProgress)
+            .filter(ScanProgress.repository_id == repository_id, ScanProgress.created_at >= queued_at.replace(tzinfo=None))
+            .order_by(ScanProgress.id)
+            .first()
+        )
+
+    def _active_scans(self) -> int:
+        """Scans queued or running on the workers, from any source."""
+        return self.db.query(ScanProgress).filter(ScanProgress.status.in_(IN_FLIGHT)).count()
+
+    def _follow(self, last: dict, repository_id: int, conditions: list[dict], generation: int | None, now: datetime) -> bool:
+        """Update the last scan from its progress.
+
+        Returns:
+            Whether the scan is still queued or running
+        """
+        queued_at = parse_timestamp(last["queuedAt"])
+        progress = self._progress(repository_id, queued_at)
+        if progress is None:
+            if now - queued_at > SCAN_START_TIMEOUT:
+                last["completedAt"] = timestamp(now)
+                last["result"] = "failed"
+                set_condition(conditions, "Scanning", "False", "ScanLost", "The queued scan never started", generation, now)
+                set_condition(
+                    conditions, "Succeeded", "False", "ScanNotStarted",
+                    f"No worker started the scan within {SCAN_START_TIMEOUT}", generation, now,
+                )
+                return False
+            set_condition(conditions, "Scanning", "True", "Queued", "Waiting for a scan worker", generation, now)
+            return True
+
+        last["scanId"] = progress.id
+        if progress.status in IN_FLIGHT:
+            message = f"{progress.processed_files or 0} of {progress.total_files or 0} files processed"
+            set_condition(conditions, "Scanning", "True", "Processing", message, generation, now)
+            return True
+
+        last["completedAt"] = timestamp(progress.completed_at.replace(tzinfo=UTC) if progress.completed_at else now)
+        last["policiesExtracted"] = progress.policies_extracted or 0
+        set_condition(conditions, "Scanning", "False", "Idle", "No scan is running", generation, now)
+        if progress.status == ScanStatus.COMPLETED:
+            last["result"] = "completed"
+            message = f"{last['policiesExtracted']} policies extracted from {progress.processed_files or 0} files"
+            set_condition(conditions, "Succeeded", "True", "ScanCompleted", message, generation, now)
+        else:
+            last["result"] = "failed"
+            set_condition(
+                conditions, "Succeeded", "False", "ScanFailed", progress.error_message or "Scan failed", generation, now
+            )
+        return False
+
+    def reconcile(self, resource: dict, now: datetime | None = None) -> dict:
+        """Status a PolicyScan should have, queueing its scan when due.
+
+        A scheduled resource scans at each cron run; an unscheduled one scans
+        once per spec generation. A new scan never starts while the last one
+        is in flight, and none start while the resource is suspended.
+
+        Args:
+            resource: PolicyScan resource
+            now: Current time (defaults to now)
+
+        Returns:
+            New status
+        """
+        now = now or datetime.now(UTC)
+        generation = resource["metadata"].get("generation")
+        status = dict(resource.get("status") or {})
+        conditions = [dict(c) for c in status.get("conditions", [])]
+        status["conditions"] = conditions
+        generation_changed = status.get("observedGeneration") != generation
+        status["observedGeneration"] = generation
+
+        try:
+            spec = PolicyScanSpec.parse(resource.get("spec") or {})
+            if spec.schedule:
+                next_run(spec.schedule, now)
+        except ValueError as e:
+            set_condition(conditions, "Ready", "False", "InvalidSpec", str(e), generation, now)
+            status.pop("nextScanTime", None)
+            return status
+        repository = self._repository(spec)
+        if repository is None:
+            set_condition(
+                conditions, "Ready", "False", "RepositoryNotFound",
+                f"Repository {spec.repository_id} not found", generation, now,
+            )
+            status.pop("nextScanTime", None)
+            return status
+        set_condition(conditions, "Ready", "True", "RepositoryFound", f"Scanning {repository.name}", generation, now)
+        status["repositoryName"] = repository.name
+        if not any(c["type"] == "Scanning" for c in conditions):
+            set_condition(conditions, "Scanning", "False", "Idle", "No scan is running", generation, now)
+        if not any(c["type"] == "Succeeded" for c in conditions):
+            set_condition(conditions, "Succeeded", "Unknown", "NotScanned", "No scan has finished yet", generation, now)
+
+        last = dict(status.get("lastScan") or {})
+        in_flight = bool(last) and not last.get("completedAt") and self._follow(
+            last, repository.id, conditions, generation, now
+        )
+        if last:
+            status["lastScan"] = last
+
+        if spec.suspend:
+            status.pop("nextScanTime", None)
+            return status
+        if spec.schedule:
+            next_scan = parse_timestamp(status.get("nextScanTime"))
+            due = next_scan is not None and next_scan <= now and not generation_changed
+            if due or generation_changed or next_scan is None:
+                status["nextScanTime"] = timestamp(next_run(spec.schedule, now))
+        else:
+            status.pop("nextScanTime", None)
+            due = status.get("scannedGeneration") != generation
+        if not due or in_flight:
+            # A run due while the last scan is in flight is skipped; a spec change waits for it
+            return status
+
+        try:
+            task_id = self.enqueue(repository.id, repository.tenant_id, spec.incremental)
+        except Exception as e:
+            # A scheduled scan waits for its next run; an unscheduled one is retried next resync
+            logger.error("policy_scan_not_queued", repository_id=repository.id, error=str(e))
+            set_condition(conditions, "Scanning", "False", "QueueFailed", str(e), generation, now)
+            return status
+        status["scannedGeneration"] = generation
+        status["lastScan"] = {"taskId": task_id, "queuedAt": timestamp(now), "incremental": spec.incremental}
+        set_condition(conditions, "Scanning", "True", "Queued", "Waiting for a scan worker", generation, now)
+        return status
+
+    def desired_workers(self, waiting: int) -> int:
+        """Workers for the scans in flight plus those queued but not yet started, one scan each."""
+        wanted = self._active_scans() + waiting
+        return max(settings.OPERATOR_MIN_WORKERS, min(settings.OPERATOR_MAX_WORKERS, wanted))
+
+    def reconcile_all(self, now: datetime | None = None) -> dict:
+        """Reconcile every PolicyScan, then scale the scan workers.
+
+        Returns:
+            Counts of resources reconciled and failed, and the worker replicas
+        """
+        now = now or datetime.now(UTC)
+        reconciled = failed = waiting = 0
+        for resource in self.kubernetes.list_policy_scans(settings.OPERATOR_NAMESPACE or None):
+            metadata = resource["metadata"]
+            try:
+                status = self.reconcile(resource, now)
+                current = resource.get("status") or {}
+                if status != current:
+                    # A merge patch only removes the fields it sets to null
+                    removed = {name: None for name in current if name not in status}
+                    self.kubernetes.patch_status(metadata["namespace"], metadata["name"], {**removed, **status})
+                reconciled += 1
+            except Exception as e:
+                failed += 1
+                logger.error(
+                    "policy_scan_reconcile_failed", namespace=metadata.get("namespace"), name=metadata.get("name"), error=str(e)
+                )
+                continue
+            scanning = next((c for c in status["conditions"] if c["type"] == "Scanning"), None)
+            if scanning and scanning["status"] == "True" and "scanId" not in status.get("lastScan", {}):
+                waiting += 1
+
+        replicas = self.scale_workers(waiting)
+        return {"reconciled": reconciled, "failed": failed, "workers": replicas}
+
+    def scale_workers(self, waiting: int) -> int:
+        """Scale the worker Deployment for the scans in flight and those waiting to start.
+
+        Returns:
+            Replicas the Deployment is scaled to
+        """
+        namespace = settings.OPERATOR_WORKER_NAMESPACE or self.kubernetes.own_namespace()
+        deployment = settings.OPERATOR_WORKER_DEPLOYMENT
+        replicas = self.desired_workers(waiting)
+        if self.kubernetes.get_replicas(namespace, deployment) != replicas:
+            self.kubernetes.set_replicas(namespace, deployment, replicas)
+            logger.info("scan_workers_scaled", deployment=deployment, replicas=replicas)
+        return replicas
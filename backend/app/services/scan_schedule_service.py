@@ -0,0 +1,296 @@
+"""Service for scanning repositories on a cron schedule.
+
+Each repository can have one schedule: a five-field cron expression
+(minute, hour, day of month, month, day of week) evaluated in UTC, as in
+``0 3 * * 1-5`` for 03:00 on weekdays. The ``@hourly``, ``@daily``,
+``@weekly``, ``@monthly``, and ``@yearly`` shorthands are accepted too.
+
+The ``run_scan_schedules`` celery beat task checks every minute for
+schedules whose next run has passed and queues a scan for each, incremental
+unless the schedule asks for full scans. A run missed while workers were down
+is queued once when they return, not once per missed occurrence.
+"""
+
+from collections.abc import Callable
+from dataclasses import dataclass
+from datetime import UTC, datetime, timedelta
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.repository import Repository
+from app.models.scan_schedule import ScanSchedule
+
+logger = structlog.get_logger(__name__)
+
+MACROS = {
+    "@hourly": "0 * * * *",
+    "@daily": "0 0 * * *",
+    "@midnight": "0 0 * * *",
+    "@weekly": "0 0 * * 0",
+    "@monthly": "0 0 1 * *",
+    "@yearly": "0 0 1 1 *",
+    "@annually": "0 0 1 1 *",
+}
+
+MONTHS = ("jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec")
+MONTH_NAMES = {name: i for i, name in enumerate(MONTHS, start=1)}
+WEEKDAY_NAMES = {name: i for i, name in enumerate(("sun", "mon", "tue", "wed", "thu", "fri", "sat"))}
+
+# Name, lowest and highest value, and value names of each field
+FIELDS = (
+    ("minute", 0, 59, {}),
+    ("hour", 0, 23, {}),
+    ("day of month", 1, 31, {}),
+    ("month", 1, 12, MONTH_NAMES),
+    ("day of week", 0, 7, WEEKDAY_NAMES),  # 0 and 7 are both Sunday
+)
+
+# How far ahead to look for the next run before calling an expression unsatisfiable (e.g. 30 February)
+SEARCH_LIMIT = timedelta(days=366 * 5)
+
+
+def _parse_value(text: str, field: str, names: dict[str, int]) -> int:
+    """A field value, by number or name."""
+    value = names.get(text.lower())
+    if value is not None:
+        return value
+    if not text.isdigit():
+        raise ValueError(f"Invalid {field} value {text!r}")
+    return int(text)
+
+
+def _parse_field(text: str, field: str, low: int, high: int, names: dict[str, int]) -> set[int]:
+    """Values a cron field matches, e.g. "1-5" is {1, 2, 3, 4, 5} and "*/15" {0, 15, 30, 45}."""
+    values = set()
+    for part in text.split(","):
+        span, _, step_text = part.partition("/")
+        step = int(step_text) if step_text.isdigit() else None
+        if step_text and not step:
+            raise ValueError(f"Invalid {field} step {step_text!r}")
+        if span == "*":
+            start, end = low, high
+        elif "-" in span:
+            first, _, last = span.partition("-")
+            start, end = _parse_value(first, field, names), _parse_value(last, field, names)
+        else:
+            start = _parse_value(span, field, names)
+            end = high if step else start
+        if not low <= start <= end <= high:
+            raise ValueError(f"{field.capitalize()} {span!r} is outside {low}-{high}")
+        values.update(range(start, end + 1, step or 1))
+    return values
+
+
+@dataclass
+class CronExpression:
+    """A parsed five-field cron expression."""
+
+    minutes: set[int]
+    hours: set[int]
+    days: set[int]
+    months: set[int]
+    weekdays: set[int]  # 0 is Sunday
+    any_day: bool  # Day of month is *
+    any_weekday: bool  # Day of week is *
+
+    @classmethod
+    def parse(cls, expression: str) -> "CronExpression":
+        """Parse an expression.
+
+        Raises:
+            ValueError: If the expression is not a valid cron expression
+        """
+        text = MACROS.get(expression.strip().lower(), expression)
+        parts = text.split()
+        if len(parts) != 5:
+            raise ValueError(f"Cron expression {expression!r} must have 5 fields, not {len(parts)}")
+        minutes, hours, days, months, weekdays = (
+            _parse_field(part, *spec) for part, spec in zip(parts, FIELDS, strict=True)
+        )
+        return cls(
+            minutes=minutes,
+            hours=hours,
+            days=days,
+            months=months,
+            weekdays={d % 7 for d in weekdays},
+            any_day=parts[2] == "*",
+            any_weekday=parts[4] == "*",
+        )
+
+    def matches_day(self, moment: datetime) -> bool:
+        """Whether a date is a run day; when both day fields are restricted, either may match, as in cron."""
+        day = moment.day in self.days
+        weekday = (moment.weekday() + 1) % 7 in self.weekdays
+        if self.any_day and self.any_weekday:
+            return True
+        if self.any_day:
+            return weekday
+        if self.any_weekday:
+            return day
+        return day or weekday
+
+    def next_run(self, after: datetime) -> datetime:
+        """First run strictly after a moment.
+
+        Raises:
+            ValueError: If the expression never matches, e.g. 0 0 30 2 *
+        """
+        moment = after.astimezone(UTC).replace(second=0, microsecond=0) + timedelta(minutes=1)
+        limit = moment + SEARCH_LIMIT
+        while moment < limit:
+            if moment.month not in self.months:
+                moment = (moment.replace(day=1) + timedelta(days=32)).replace(day=1, hour=0, minute=0)
+            elif not self.matches_day(moment):
+                moment = (moment + timedelta(days=1)).replace(hour=0, minute=0)
+            elif moment.hour not in self.hours:
+                moment = (moment + timedelta(hours=1)).replace(minute=0)
+            elif moment.minute not in self.minutes:
+                moment += timedelta(minutes=1)
+            else:
+                return moment
+        raise ValueError("Cron expression never matches a date")
+
+
+def next_run(expression: str, after: datetime) -> datetime:
+    """First run of an expression after a moment.
+
+    Raises:
+        ValueError: If the expression is invalid or never matches
+    """
+    return CronExpression.parse(expression).next_run(after)
+
+
+class ScanScheduleService:
+    """Manages repository scan schedules and queues the scans that are due."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def _schedule_next(self, schedule: ScanSchedule, now: datetime) -> None:
+        """Set when an enabled schedule next runs, validating its expression either way."""
+        expression = CronExpression.parse(schedule.cron)
+        schedule.next_run_at = expression.next_run(now) if schedule.enabled else None
+
+    def create_schedule(self, data: dict) -> ScanSchedule:
+        """Schedule a repository's scans.
+
+        Args:
+            data: Schedule fields (repository_id, cron, incremental, enabled)
+
+        Returns:
+            Created schedule
+
+        Raises:
+            ValueError: If the repository does not exist or is already scheduled, or the cron expression is invalid
+        """
+        repository = self._query(Repository).filter(Repository.id == data["repository_id"]).first()
+        if repository is None:
+            raise ValueError(f"Repository {data['repository_id']} not found")
+        if self._query(ScanSchedule).filter(ScanSchedule.repository_id == repository.id).first():
+            raise ValueError(f"Repository {repository.id} already has a scan schedule")
+        schedule = ScanSchedule(tenant_id=self.tenant_id, **data)
+        self._schedule_next(schedule, datetime.now(UTC))
+        self.db.add(schedule)
+        self.db.commit()
+        self.db.refresh(schedule)
+        logger.info("scan_schedule_created", schedule_id=schedule.id, repository_id=repository.id, cron=schedule.cron)
+        return schedule
+
+    def list_schedules(self) -> list[ScanSchedule]:
+        """List the tenant's schedules."""
+        return self._query(ScanSchedule).order_by(ScanSchedule.id).all()
+
+    def get_schedule(self, schedule_id: int) -> ScanSchedule:
+        """Get a schedule.
+
+        Raises:
+            ValueError: If the schedule does not exist
+        """
+        schedule = self._query(ScanSchedule).filter(ScanSchedule.id == schedule_id).first()
+        if schedule is None:
+            raise ValueError(f"Scan schedule {schedule_id} not found")
+        return schedule
+
+    def update_schedule(self, schedule_id: int, data: dict) -> ScanSchedule:
+        """Change a schedule's cron expression, scan kind, or whether it is enabled.
+
+        Args:
+            schedule_id: Schedule ID
+            data: Fields to change (cron, incremental, enabled)
+
+        Returns:
+            Updated schedule
+
+        Raises:
+            ValueError: If the schedule does not exist or the cron expression is invalid
+        """
+        schedule = self.get_schedule(schedule_id)
+        for name, value in data.items():
+            setattr(schedule, name, value)
+        self._schedule_next(schedule, datetime.now(UTC))
+        self.db.commit()
+        self.db.refresh(schedule)
+        return schedule
+
+    def delete_schedule(self, schedule_id: int) -> None:
+        """Delete a schedule; scans already queued still run.
+
+        Raises:
+            ValueError: If the schedule does not exist
+        """
+        self.db.delete(self.get_schedule(schedule_id))
+        self.db.commit()
+
+    def _due(self, now: datetime) -> list[ScanSchedule]:
+        """Enabled schedules whose next run is at or before a moment, earliest first."""
+        return (
+            self._query(ScanSchedule)
+            .filter(ScanSchedule.enabled.is_(True), ScanSchedule.next_run_at <= now)
+            .order_by(ScanSchedule.next_run_at)
+            .all()
+        )
+
+    def run_due_schedules(self, enqueue: Callable[[ScanSchedule], str], now: datetime | None = None) -> list[dict]:
+        """Queue a scan for every enabled schedule whose next run has passed.
+
+        Without a tenant this covers every tenant's schedules. A schedule
+        whose scan cannot be queued records the error and waits for its
+        next run.
+
+        Args:
+            enqueue: Queues a schedule's scan and returns the task ID
+            now: Current time (defaults to now)
+
+        Returns:
+            Schedule, repository, and task IDs of the scans queued
+        """
+        now = now or datetime.now(UTC)
+        queued = []
+        for schedule in self._due(now):
+            try:
+                schedule.last_task_id = enqueue(schedule)
+                schedule.last_error = None
+                queued.append(
+                    {
+                        "schedule_id": schedule.id,
+                        "repository_id": schedule.repository_id,
+                        "task_id": schedule.last_task_id,
+                    }
+                )
+            except Exception as e:
+                schedule.last_error = str(e)
+                logger.error("scheduled_scan_not_queued", schedule_id=schedule.id, error=str(e))
+            schedule.last_run_at = now
+            self._schedule_next(schedule, now)
+        self.db.commit()
+        return queued
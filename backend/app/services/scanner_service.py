@@ -3,6 +3,7 @@ import logging
 import math
 import os
 import re
+import shutil
 from collections.abc import AsyncGenerator
 from datetime import datetime
 from pathlib import Path
@@ -12,6 +13,7 @@ import psutil
 from git import Repo
 from sqlalchemy.orm import Session
 
+from app.core.air_gap import ensure_git_url_allowed
 from app.core.config import settings
 from app.core.metrics import (
     increment_error_count,
@@ -20,15 +22,19 @@ from app.core.metrics import (
     record_scan_duration,
     set_active_scans,
 )
+from app.core.sandbox import Sandbox, run_pass
 from app.models.policy import Evidence, Policy, PolicyStatus, RiskLevel, SourceType
 from app.models.repository import Repository, RepositoryStatus, RepositoryType
 from app.models.scan_progress import ScanProgress, ScanStatus
 from app.models.secret_detection import SecretDetectionLog
 from app.services.audit_service import AuditService
+from app.services.checkout_analysis import AUTH_PATTERNS, SUPPORTED_EXTENSIONS, analyze_files, count_files
 from app.services.csharp_scanner_service import CSharpScannerService
 from app.services.database_scanner_service import DatabaseScannerService
+from app.services.extraction_confidence import classify_evidence, method_confidence
 from app.services.java_scanner_service import JavaScannerService
 from app.services.javascript_scanner import JavaScriptScannerService
+from app.services.llm_batch_service import LLMBatchProcessor
 from app.services.llm_provider import get_llm_provider
 from app.services.python_scanner_service import PythonScannerService
 from app.services.risk_scoring_service import RiskScoringService
@@ -36,37 +42,6 @@ from app.services.secret_detection_service import SecretDetectionService
 
 logger = logging.getLogger(__name__)
 
-# Common authorization patterns to search for
-AUTH_PATTERNS = [
-    r"@\w+\s*\(",  # Decorators (Python, TypeScript)
-    r"hasRole\s*\(",  # Role checks
-    r"isAuthorized\s*\(",  # Authorization checks
-    r"authorize\s*\(",  # Authorization functions
-    r"checkPermission\s*\(",  # Permission checks
-    r"if\s+.*\.(role|permission|isAdmin|isManager)",  # Conditional role checks
-    r"canAccess\s*\(",  # Access checks
-    r"@Authorize",  # C#/Java annotations
-    r"@PreAuthorize",  # Spring Security
-    r"@RolesAllowed",  # Java EE
-    r"@RequireRole",  # Custom role annotations
-]
-
-# File extensions to scan
-SUPPORTED_EXTENSIONS = {
-    ".py",
-    ".java",
-    ".cs",
-    ".js",
-    ".ts",
-    ".tsx",
-    ".jsx",
-    ".go",
-    ".rb",
-    ".php",
-    ".scala",
-    ".kt",
-}
-
 # Frontend indicators
 FRONTEND_INDICATORS = {
     "path_patterns": ["frontend", "client", "ui", "src/components", "src/pages", "src/views", "public", "web"],
@@ -89,11 +64,13 @@ class ScannerService:
         """Initialize scanner service."""
         self.db = db
         self.llm_provider = get_llm_provider()
+        self.llm_batch_processor = LLMBatchProcessor(self.llm_provider)
         self.java_scanner = JavaScannerService()
         self.csharp_scanner = CSharpScannerService()
         self.python_scanner = PythonScannerService()
         self.javascript_scanner = JavaScriptScannerService()
         self.database_scanner = DatabaseScannerService()
+        self.sandbox: Sandbox | None = None
         self.process = psutil.Process(os.getpid())
         self.initial_memory_mb = self.process.memory_info().rss / 1024 / 1024
 
@@ -163,7 +140,11 @@ class ScannerService:
             return SourceType.UNKNOWN
 
     async def scan_repository(
-        self, repository_id: int, tenant_id: str | None = None, incremental: bool = False
+        self,
+        repository_id: int,
+        tenant_id: str | None = None,
+        incremental: bool = False,
+        config_snapshot: dict | None = None,
     ) -> dict[str, Any]:
         """Scan a repository and extract policies using streaming analysis.
 
@@ -171,6 +152,8 @@ class ScannerService:
             repository_id: ID of the repository to scan
             tenant_id: Optional tenant ID for multi-tenancy
             incremental: If True, only scan changed files since last scan
+            config_snapshot: Config and environment of the scanned deployment, binding the
+                settings mined rules reference to concrete values
 
         Returns:
             Dictionary with scan results including memory metrics
@@ -231,6 +214,14 @@ class ScannerService:
             scan_progress.git_commit_hash = current_commit
             scan_progress.is_incremental = 1 if incremental else 0
 
+            # Assess analyzability before mining, so results can be read against it
+            try:
+                from app.services.readiness_service import ReadinessService
+
+                ReadinessService(self.db, repo.tenant_id, str(repo_path.parent)).assess(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error assessing repository readiness: {e}")
+
             # Get changed files if incremental scan
             changed_files = set()
             if incremental:
@@ -279,25 +270,18 @@ class ScannerService:
                     scan_progress.current_batch = batch_num
                     self.db.commit()
 
-                    # Process each file in the batch
-                    for file_info in current_batch:
-                        try:
-                            policies = await self._extract_policies_from_file(
-                                repo, file_info["path"], file_info["content"], file_info["matches"], repo_path
-                            )
-                            policies_created += len(policies)
-
-                            # Update progress
-                            scan_progress.processed_files += 1
-                            scan_progress.policies_extracted = policies_created
-                            self.db.commit()
-
-                        except Exception as e:
-                            logger.error(f"Error processing file {file_info['path']}: {e}")
-                            errors_count += 1
-                            scan_progress.errors_count = errors_count
-                            self.db.commit()
-                            continue
+                    # Process the batch with parallel, rate-limited LLM calls
+                    batch_policies, batch_errors = await self._process_file_batch(
+                        repo, current_batch, repo_path
+                    )
+                    policies_created += batch_policies
+                    errors_count += batch_errors
+
+                    # Update progress
+                    scan_progress.processed_files += len(current_batch)
+                    scan_progress.policies_extracted = policies_created
+                    scan_progress.errors_count = errors_count
+                    self.db.commit()
 
                     # Track peak memory usage
                     current_memory_mb = self._get_memory_usage_mb()
@@ -321,24 +305,16 @@ class ScannerService:
                 scan_progress.current_batch = batch_num
                 self.db.commit()
 
-                for file_info in current_batch:
-                    try:
-                        policies = await self._extract_policies_from_file(
-                            repo, file_info["path"], file_info["content"], file_info["matches"], repo_path
-                        )
-                        policies_created += len(policies)
-
-                        scan_progress.processed_files += 1
-                        scan_progress.policies_extracted = policies_created
-                        self.db.commit()
+                batch_policies, batch_errors = await self._process_file_batch(
+                    repo, current_batch, repo_path
+                )
+                policies_created += batch_policies
+                errors_count += batch_errors
 
-                    except Exception as e:
-                        logger.error(f"Error processing file {file_info['path']}: {e}")
-                        errors_count += 1
-                        scan_progress.errors_count = errors_count
-                        increment_error_count("file_processing", "scanner_service")
-                        self.db.commit()
-                        continue
+                scan_progress.processed_files += len(current_batch)
+                scan_progress.policies_extracted = policies_created
+                scan_progress.errors_count = errors_count
+                self.db.commit()
 
                 current_memory_mb = self._get_memory_usage_mb()
                 peak_memory_mb = max(peak_memory_mb, current_memory_mb)
@@ -393,6 +369,156 @@ class ScannerService:
                 except Exception as e:
                     logger.error(f"Error detecting changes: {e}")
 
+            # Attribute Helm/Kubernetes auth settings to the service's policies
+            try:
+                from app.services.k8s_manifest_service import K8sManifestService
+
+                K8sManifestService(self.db, repo.tenant_id, str(repo_path.parent)).scan_repository(repo.id)
+            except Exception as e:
+                logger.error(f"Error mining Kubernetes manifests: {e}")
+
+            # Mine route auth declared as framework configuration (Hapi, Koa, Micronaut, Quarkus, Play)
+            # and at WebSocket, socket.io, STOMP, and SSE handshakes and message handlers
+            try:
+                from app.services.framework_route_service import FrameworkRouteService
+
+                FrameworkRouteService(self.db, repo.tenant_id, str(repo_path.parent)).scan_repository(repo.id)
+            except Exception as e:
+                logger.error(f"Error mining framework routes: {e}")
+
+            # Mine rules developers declared in "policy:" comments and flag stale ones
+            try:
+                from app.services.policy_annotation_service import PolicyAnnotationService
+
+                PolicyAnnotationService(self.db, repo.tenant_id, str(repo_path.parent)).scan_repository(repo.id)
+            except Exception as e:
+                logger.error(f"Error mining policy annotations: {e}")
+
+            # Import the Casbin model and policy files enforcers load, citing their Enforce call sites
+            try:
+                from app.services.casbin_policy_service import CasbinPolicyService
+
+                CasbinPolicyService(self.db, repo.tenant_id, str(repo_path.parent)).scan_repository(repo.id)
+            except Exception as e:
+                logger.error(f"Error importing Casbin policies: {e}")
+
+            # Import the Rego rules handlers query OPA for, so those endpoints are not reported unprotected
+            try:
+                from app.services.opa_query_service import OpaQueryService
+
+                OpaQueryService(self.db, repo.tenant_id, str(repo_path.parent)).scan_repository(repo.id)
+            except Exception as e:
+                logger.error(f"Error importing OPA query policies: {e}")
+
+            # Add the attribute checks handlers enforce before any work to their policies' conditions
+            try:
+                from app.services.abac_condition_service import AbacConditionService
+
+                AbacConditionService(self.db, repo.tenant_id, str(repo_path.parent)).lift_conditions(repo.id)
+            except Exception as e:
+                logger.error(f"Error lifting handler conditions: {e}")
+
+            # Attribute role checks handlers leave to helper functions to the routes calling them
+            try:
+                from app.services.delegated_check_service import DelegatedCheckService
+
+                DelegatedCheckService(self.db, repo.tenant_id, str(repo_path.parent)).attribute_checks(repo.id)
+            except Exception as e:
+                logger.error(f"Error attributing delegated checks: {e}")
+
+            # Render rules whose roles and limits come from configuration with the values bound to them,
+            # binding them from the scan's config snapshot when one was supplied
+            try:
+                from app.services.role_parameter_service import RoleParameterService
+
+                RoleParameterService(self.db, repo.tenant_id).record_usages(repo.id, config_snapshot, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error rendering role parameters: {e}")
+
+            # Carry triage and ownership over to rules re-mined after moves and renames
+            try:
+                from app.services.stable_identity_service import StableIdentityService
+
+                StableIdentityService(self.db, repo.tenant_id).carry_over(repo.id)
+            except Exception as e:
+                logger.error(f"Error carrying over triage: {e}")
+
+            # Dismiss new detections matching the workspace's learned false positives
+            try:
+                from app.services.false_positive_service import FalsePositiveService
+
+                FalsePositiveService(self.db, repo.tenant_id).suppress(repo.id)
+            except Exception as e:
+                logger.error(f"Error suppressing false positives: {e}")
+
+            # Lint the scan against the workspace's organizational rules
+            try:
+                from app.services.policy_lint_service import PolicyLintService
+
+                PolicyLintService(self.db, repo.tenant_id).record_run(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error linting policies: {e}")
+
+            # Record how often each custom detection rule matched this scan's clone
+            try:
+                from app.services.custom_rule_service import CustomRuleService
+
+                CustomRuleService(self.db, repo.tenant_id, str(repo_path.parent)).record_hits(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error recording custom rule hits: {e}")
+
+            # Tag the scan with the environment its branch or deployment manifests name
+            try:
+                from app.services.environment_comparison_service import EnvironmentComparisonService
+
+                EnvironmentComparisonService(self.db, repo.tenant_id, str(repo_path.parent)).auto_tag(
+                    repo.id, scan_progress.id
+                )
+            except Exception as e:
+                logger.error(f"Error tagging scan environment: {e}")
+
+            # Record numeric condition thresholds and flag ones loosened since the last scan
+            try:
+                from app.services.threshold_tracking_service import ThresholdTrackingService
+
+                ThresholdTrackingService(self.db, repo.tenant_id).record(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error tracking condition thresholds: {e}")
+
+            # Page on-call when an internet-facing route lost the authentication it had in the last scan
+            try:
+                from app.services.regression_alert_service import RegressionAlertService
+
+                RegressionAlertService(self.db, repo.tenant_id, str(repo_path.parent)).record(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error alerting on auth regressions: {e}")
+
+            # Attribute policies to owners from CODEOWNERS and blame, then route their work items
+            try:
+                from app.services.ownership_service import OwnershipService
+
+                ownership_service = OwnershipService(self.db, repo.tenant_id, str(repo_path.parent))
+                ownership_service.attribute(repo.id)
+                ownership_service.route_work_items(repo.id)
+            except Exception as e:
+                logger.error(f"Error attributing policy ownership: {e}")
+
+            # Snapshot aggregate metrics for trend reporting
+            try:
+                from app.services.trend_metrics_service import TrendMetricsService
+
+                TrendMetricsService(self.db, repo.tenant_id).capture_snapshot(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error capturing scan metrics: {e}")
+
+            # Measure how many inline checks have been replaced with PDP calls
+            try:
+                from app.services.pdp_migration_service import PdpMigrationService
+
+                PdpMigrationService(self.db, repo.tenant_id, str(repo_path.parent)).refresh(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error measuring PDP migration progress: {e}")
+
             return {
                 "status": "completed",
                 "scan_id": scan_progress.id,
@@ -403,6 +529,7 @@ class ScannerService:
                 "errors_count": errors_count,
                 "batches_processed": batch_num,
                 "changes_detected": changes_detected,
+                "sandbox": {"isolation": self.sandbox.isolation if self.sandbox else []},
                 "performance": {
                     "duration_seconds": round(scan_duration_seconds, 2),
                     "start_memory_mb": round(start_memory_mb, 2),
@@ -493,6 +620,15 @@ class ScannerService:
             # If we can't get the diff, fall back to full scan
             return set()
 
+    @staticmethod
+    def _checks_out_symlinks(clone_dir: Path) -> bool:
+        """Whether an existing clone checks symlinks out as symlinks."""
+        try:
+            with Repo(clone_dir).config_reader("repository") as config:
+                return config.get_value("core", "symlinks", True) is not False
+        except Exception:
+            return True
+
     async def _clone_repository(self, repo: Repository) -> Path:
         """Clone repository to temporary directory.
 
@@ -502,17 +638,20 @@ class ScannerService:
         Returns:
             Path to cloned repository
         """
-        clone_dir = Path("/tmp/policy_miner_repos") / str(repo.id)
+        clone_dir = Path(settings.REPO_CLONE_DIR) / str(repo.id)
         clone_dir.mkdir(parents=True, exist_ok=True)
 
-        if (clone_dir / ".git").exists():
+        if (clone_dir / ".git").exists() and not self._checks_out_symlinks(clone_dir):
             logger.info(f"Repository already cloned at {clone_dir}, pulling latest changes")
             # Pull latest changes instead of re-cloning
             git_repo = Repo(clone_dir)
             origin = git_repo.remotes.origin
+            ensure_git_url_allowed(origin.url, "scanner")
             origin.pull()
             return clone_dir
 
+        ensure_git_url_allowed(repo.source_url, "scanner")
+
         # Build clone URL with credentials if provided
         clone_url = repo.source_url
         if repo.connection_config:
@@ -526,45 +665,52 @@ class ScannerService:
                 password = repo.connection_config.get("password")
                 clone_url = clone_url.replace("https://", f"https://{username}:{password}@")
 
+        if (clone_dir / ".git").exists():
+            # Cloned before symlinks were checked out as plain files
+            shutil.rmtree(clone_dir)
+
         logger.info(f"Cloning repository to {clone_dir}")
-        # Don't use depth=1 anymore so we can do git diff
-        Repo.clone_from(clone_url, clone_dir)
+        # Don't use depth=1 anymore so we can do git diff. Symlinks are checked out as
+        # files holding their target, so nothing reading the checkout can follow one
+        # out of it; submodules are not fetched. The setting is written before the
+        # first checkout rather than passed as --config, which GitPython rejects as unsafe.
+        git_repo = Repo.clone_from(clone_url, clone_dir, no_checkout=True, multi_options=["--no-recurse-submodules"])
+        with git_repo.config_writer("repository") as config:
+            config.set_value("core", "symlinks", False)
+        git_repo.head.reset(index=True, working_tree=True)
 
         return clone_dir
 
-    async def _count_authorization_files(self, repo_path: Path, changed_files: set[str] | None = None) -> int:
-        """Count files with potential authorization code without loading them into memory.
+    def _analyze(self, repo_path: Path, func, changed_files: set[str] | None):
+        """Run an analysis pass over a checkout, sandboxed unless SCAN_SANDBOX_ENABLED is off.
 
         Args:
             repo_path: Path to repository
+            func: Pass from checkout_analysis
             changed_files: Optional set of changed files to filter by (for incremental scans)
 
         Returns:
-            Count of files to be scanned
+            What the pass yields
         """
-        count = 0
-        for file_path in repo_path.rglob("*"):
-            # Skip non-files and common ignore patterns
-            if not file_path.is_file():
-                continue
-            if any(p in str(file_path) for p in [".git", "node_modules", "venv", "__pycache__", "dist", "build"]):
-                continue
-            if file_path.suffix not in SUPPORTED_EXTENSIONS:
-                continue
+        kwargs = {
+            "changed_files": sorted(changed_files) if changed_files is not None else None,
+            "max_file_bytes": settings.MAX_FILE_SIZE_MB * 1024 * 1024,
+        }
+        if self.sandbox is None or self.sandbox.root != Path(repo_path).resolve():
+            self.sandbox = Sandbox(repo_path)
+        return run_pass(repo_path, func, self.sandbox, **kwargs)
 
-            # Check file size
-            if file_path.stat().st_size > settings.MAX_FILE_SIZE_MB * 1024 * 1024:
-                continue
-
-            # Filter by changed files if incremental scan
-            if changed_files is not None:
-                relative_path = str(file_path.relative_to(repo_path))
-                if relative_path not in changed_files:
-                    continue
+    async def _count_authorization_files(self, repo_path: Path, changed_files: set[str] | None = None) -> int:
+        """Count files with potential authorization code without loading them into memory.
 
-            count += 1
+        Args:
+            repo_path: Path to repository
+            changed_files: Optional set of changed files to filter by (for incremental scans)
 
-        return count
+        Returns:
+            Count of files to be scanned
+        """
+        return sum(self._analyze(repo_path, count_files, changed_files))
 
     async def _stream_authorization_files(
         self, repo_path: Path, repository: Repository, changed_files: set[str] | None = None
@@ -572,7 +718,8 @@ class ScannerService:
         """Stream files containing authorization code one at a time (generator).
 
         This is a memory-efficient streaming version that yields files as they are discovered,
-        rather than loading all files into memory at once.
+        rather than loading all files into memory at once. The checkout is read in the
+        scan sandbox; secrets it reports are logged here.
 
         Args:
             repo_path: Path to repository
@@ -582,76 +729,38 @@ class ScannerService:
         Yields:
             File information dictionaries one at a time
         """
-        for file_path in repo_path.rglob("*"):
-            # Skip non-files and common ignore patterns
-            if not file_path.is_file():
-                continue
-            if any(p in str(file_path) for p in [".git", "node_modules", "venv", "__pycache__", "dist", "build"]):
-                continue
-            if file_path.suffix not in SUPPORTED_EXTENSIONS:
-                continue
-
-            # Check file size
-            if file_path.stat().st_size > settings.MAX_FILE_SIZE_MB * 1024 * 1024:
-                continue
-
-            relative_path = file_path.relative_to(repo_path)
-
-            # Filter by changed files if incremental scan
-            if changed_files is not None:
-                if str(relative_path) not in changed_files:
-                    continue
-
-            try:
-                content = file_path.read_text(encoding="utf-8", errors="ignore")
-
-                # PRE-SCAN: Detect secrets BEFORE processing
-                secret_result = SecretDetectionService.scan_content(content, str(relative_path))
-
-                # Log detected secrets to audit trail
-                if secret_result.has_secrets:
-                    for secret in secret_result.secrets_found:
-                        secret_log = SecretDetectionLog(
-                            repository_id=repository.id,
-                            tenant_id=repository.tenant_id,
-                            file_path=str(relative_path),
-                            secret_type=secret["type"],
-                            description=secret["description"],
-                            line_number=secret["line"],
-                            preview=secret["preview"],
-                        )
-                        self.db.add(secret_log)
-
-                    # Commit secret logs immediately
-                    self.db.commit()
-
-                    logger.warning(
-                        f"Found {len(secret_result.secrets_found)} secrets in {relative_path}. "
-                        "Secrets logged for audit."
+        for file_info in self._analyze(repo_path, analyze_files, changed_files):
+            relative_path = file_info["path"]
+
+            # Log detected secrets to audit trail
+            if file_info["secrets"]:
+                for secret in file_info["secrets"]:
+                    secret_log = SecretDetectionLog(
+                        repository_id=repository.id,
+                        tenant_id=repository.tenant_id,
+                        file_path=relative_path,
+                        secret_type=secret["type"],
+                        description=secret["description"],
+                        line_number=secret["line"],
+                        preview=secret["preview"],
                     )
+                    self.db.add(secret_log)
 
-                # Check for authorization patterns
-                matches = []
-                for pattern in AUTH_PATTERNS:
-                    for match in re.finditer(pattern, content):
-                        line_num = content[:match.start()].count("\n") + 1
-                        matches.append({
-                            "pattern": pattern,
-                            "line": line_num,
-                            "text": match.group(),
-                        })
-
-                # Only yield files that have authorization patterns
-                if matches:
-                    yield {
-                        "path": str(relative_path),
-                        "content": content,
-                        "matches": matches,
-                    }
+                # Commit secret logs immediately
+                self.db.commit()
 
-            except Exception as e:
-                logger.error(f"Error reading file {file_path}: {e}")
-                continue
+                logger.warning(
+                    f"Found {len(file_info['secrets'])} secrets in {relative_path}. "
+                    "Secrets logged for audit."
+                )
+
+            # Only yield files that have authorization patterns
+            if file_info["matches"]:
+                yield {
+                    "path": relative_path,
+                    "content": file_info["content"],
+                    "matches": file_info["matches"],
+                }
 
     async def _find_authorization_files(self, repo_path: Path, repository: Repository) -> list[dict[str, Any]]:
         """Find files containing authorization code and scan for secrets.
@@ -843,6 +952,66 @@ class ScannerService:
 
         return auth_files
 
+    async def _process_file_batch(
+        self, repo: Repository, batch: list[dict[str, Any]], repo_path: Path
+    ) -> tuple[int, int]:
+        """Extract policies from a batch of files using parallel LLM calls.
+
+        Prompts are sent through the batch processor, which parallelizes within
+        the configured concurrency limit, respects the provider rate limit, and
+        retries throttling/overload errors. A file whose LLM call ultimately
+        fails is counted as an error without failing the scan.
+
+        Args:
+            repo: Repository model
+            batch: File information dictionaries (path, content, matches)
+            repo_path: Path to the repository root (for evidence validation)
+
+        Returns:
+            Tuple of (policies created, files that failed)
+        """
+        errors_count = 0
+        prepared: list[dict[str, Any]] = []
+
+        for file_info in batch:
+            try:
+                prompt = self._prepare_extraction_prompt(
+                    repo, file_info["path"], file_info["content"], file_info["matches"]
+                )
+                prepared.append({**file_info, "prompt": prompt})
+            except Exception as e:
+                logger.error(f"Error preparing prompt for {file_info['path']}: {e}")
+                errors_count += 1
+                increment_error_count("file_processing", "scanner_service")
+
+        results = await self.llm_batch_processor.process_batch(
+            [item["prompt"] for item in prepared], max_tokens=4096, temperature=0
+        )
+
+        policies_created = 0
+        for item, result in zip(prepared, results, strict=True):
+            if not result.succeeded:
+                logger.error(
+                    f"LLM extraction failed for {item['path']} after {result.attempts} attempts: "
+                    f"{result.error}"
+                )
+                errors_count += 1
+                increment_error_count("llm_call", "scanner_service")
+                continue
+
+            try:
+                policies = self._save_extracted_policies(
+                    repo, item["path"], item["content"], result.response, repo_path, result.duration_ms
+                )
+                policies_created += len(policies)
+            except Exception as e:
+                logger.error(f"Error processing file {item['path']}: {e}")
+                self.db.rollback()
+                errors_count += 1
+                increment_error_count("file_processing", "scanner_service")
+
+        return policies_created, errors_count
+
     async def _extract_policies_from_file(
         self, repo: Repository, file_path: str, content: str, matches: list[dict], repo_path: Path
     ) -> list[Policy]:
@@ -858,6 +1027,44 @@ class ScannerService:
         Returns:
             List of created Policy objects
         """
+        prompt = self._prepare_extraction_prompt(repo, file_path, content, matches)
+
+        import time
+        start_time = time.time()
+
+        try:
+            # Call LLM provider (AWS Bedrock or Azure OpenAI) with retry on throttling
+            response_text, _ = await self.llm_batch_processor.create_message(
+                prompt=prompt,
+                max_tokens=4096,
+                temperature=0,
+            )
+
+            # Calculate response time
+            response_time_ms = int((time.time() - start_time) * 1000)
+
+            return self._save_extracted_policies(
+                repo, file_path, content, response_text, repo_path, response_time_ms
+            )
+
+        except Exception as e:
+            logger.error(f"Error calling LLM provider: {e}")
+            return []
+
+    def _prepare_extraction_prompt(
+        self, repo: Repository, file_path: str, content: str, matches: list[dict]
+    ) -> str:
+        """Build the extraction prompt, check it for secrets, and audit it.
+
+        Args:
+            repo: Repository model
+            file_path: Path to the file
+            content: File content (should already be redacted)
+            matches: Authorization pattern matches
+
+        Returns:
+            Prompt ready to send to the LLM
+        """
         # Prepare prompt for Claude
         prompt = self._build_extraction_prompt(file_path, content, matches)
 
@@ -865,9 +1072,6 @@ class ScannerService:
         SecretDetectionService.validate_no_secrets_in_prompt(prompt, file_path)
 
         # Log AI prompt to audit trail
-        import time
-        start_time = time.time()
-
         AuditService.log_ai_prompt(
             db=self.db,
             tenant_id=repo.tenant_id,
@@ -881,89 +1085,98 @@ class ScannerService:
             },
         )
 
-        try:
-            # Call LLM provider (AWS Bedrock or Azure OpenAI)
-            response_text = self.llm_provider.create_message(
-                prompt=prompt,
-                max_tokens=4096,
-                temperature=0,
-            )
+        return prompt
 
-            # Calculate response time
-            response_time_ms = int((time.time() - start_time) * 1000)
+    def _save_extracted_policies(
+        self,
+        repo: Repository,
+        file_path: str,
+        content: str,
+        response_text: str,
+        repo_path: Path,
+        response_time_ms: int,
+    ) -> list[Policy]:
+        """Audit the LLM response, then persist and post-process its policies.
 
-            # Log AI response to audit trail
-            AuditService.log_ai_response(
-                db=self.db,
-                tenant_id=repo.tenant_id,
-                response=response_text,
-                model=self.llm_provider.model_id if hasattr(self.llm_provider, 'model_id') else "unknown",
-                provider=settings.LLM_PROVIDER,
-                repository_id=repo.id,
-                response_time_ms=response_time_ms,
-                additional_context={
-                    "file_path": file_path,
-                },
-            )
+        Args:
+            repo: Repository model
+            file_path: Path to the file
+            content: File content
+            response_text: Raw LLM response
+            repo_path: Path to the repository root (for evidence validation)
+            response_time_ms: LLM response time in milliseconds
+
+        Returns:
+            List of created Policy objects
+        """
+        # Log AI response to audit trail
+        AuditService.log_ai_response(
+            db=self.db,
+            tenant_id=repo.tenant_id,
+            response=response_text,
+            model=self.llm_provider.model_id if hasattr(self.llm_provider, 'model_id') else "unknown",
+            provider=settings.LLM_PROVIDER,
+            repository_id=repo.id,
+            response_time_ms=response_time_ms,
+            additional_context={
+                "file_path": file_path,
+            },
+        )
 
-            # Parse response
-            policies = self._parse_claude_response(response_text, repo, file_path, content)
+        # Parse response
+        policies = self._parse_claude_response(response_text, repo, file_path, content)
 
-            # Save policies to database
-            for policy in policies:
-                self.db.add(policy)
+        # Save policies to database
+        for policy in policies:
+            self.db.add(policy)
 
-            self.db.commit()
+        self.db.commit()
 
-            # Generate embeddings for policies
-            from app.services.similarity_service import similarity_service
-            for policy in policies:
+        # Generate embeddings for policies
+        from app.services.similarity_service import similarity_service
+        for policy in policies:
+            try:
+                embedding = similarity_service.generate_embedding(policy)
+                policy.embedding = embedding
+            except Exception as e:
+                logger.error(f"Failed to generate embedding for policy {policy.id}: {e}")
+
+        self.db.commit()
+
+        # Validate evidence immediately after extraction
+        from app.services.evidence_validation_service import EvidenceValidationService
+        validation_service = EvidenceValidationService(self.db)
+
+        for policy in policies:
+            for evidence in policy.evidence:
                 try:
-                    embedding = similarity_service.generate_embedding(policy)
-                    policy.embedding = embedding
+                    validation_service.validate_evidence(evidence.id, repo_path)
                 except Exception as e:
-                    logger.error(f"Failed to generate embedding for policy {policy.id}: {e}")
+                    logger.error(f"Failed to validate evidence {evidence.id}: {e}")
 
-            self.db.commit()
-
-            # Validate evidence immediately after extraction
-            from app.services.evidence_validation_service import EvidenceValidationService
-            validation_service = EvidenceValidationService(self.db)
+        # Apply auto-approval if enabled
+        if repo.tenant_id:
+            from app.services.auto_approval_service import AutoApprovalService
+            auto_approval_service = AutoApprovalService(self.db)
 
             for policy in policies:
-                for evidence in policy.evidence:
-                    try:
-                        validation_service.validate_evidence(evidence.id, repo_path)
-                    except Exception as e:
-                        logger.error(f"Failed to validate evidence {evidence.id}: {e}")
-
-            # Apply auto-approval if enabled
-            if repo.tenant_id:
-                from app.services.auto_approval_service import AutoApprovalService
-                auto_approval_service = AutoApprovalService(self.db)
-
-                for policy in policies:
-                    try:
-                        should_approve, reasoning = auto_approval_service.evaluate_policy(
-                            repo.tenant_id, policy
+                try:
+                    should_approve, reasoning = auto_approval_service.evaluate_policy(
+                        repo.tenant_id, policy
+                    )
+                    if should_approve:
+                        policy.status = PolicyStatus.APPROVED
+                        logger.info(
+                            f"Auto-approved policy {policy.id}: {reasoning}",
+                            tenant_id=repo.tenant_id
                         )
-                        if should_approve:
-                            policy.status = PolicyStatus.APPROVED
-                            logger.info(
-                                f"Auto-approved policy {policy.id}: {reasoning}",
-                                tenant_id=repo.tenant_id
-                            )
-                    except Exception as e:
-                        logger.error(f"Error in auto-approval for policy {policy.id}: {e}")
-                        # Continue without auto-approval
-
-                self.db.commit()
+                except Exception as e:
+                    logger.error(f"Error in auto-approval for policy {policy.id}: {e}")
+                    # Continue without auto-approval
 
-            return policies
+            self.db.commit()
 
-        except Exception as e:
-            logger.error(f"Error calling LLM provider: {e}")
-            return []
+        return policies
 
     def _build_extraction_prompt(self, file_path: str, content: str, matches: list[dict]) -> str:
         """Build prompt for Claude to extract policies.
@@ -1123,8 +1336,13 @@ Return ONLY the JSON array, no other text."""
                 impact_score = RiskScoringService.calculate_impact_score(
                     subject, resource, action, conditions
                 )
-                confidence_score = RiskScoringService.calculate_confidence_score(
-                    len(evidence_items), code_snippet, subject, resource, action
+                # Confidence is capped by how the rule was found: a guard on the route or a check in the handler
+                extraction_method = classify_evidence([ev.get("code_snippet", "") for ev in evidence_items])
+                confidence_score = method_confidence(
+                    extraction_method,
+                    RiskScoringService.calculate_confidence_score(
+                        len(evidence_items), code_snippet, subject, resource, action
+                    ),
                 )
                 historical_score = RiskScoringService.calculate_historical_score()
 
@@ -1154,6 +1372,7 @@ Return ONLY the JSON array, no other text."""
                     complexity_score=complexity_score,
                     impact_score=impact_score,
                     confidence_score=confidence_score,
+                    extraction_method=extraction_method,
                     historical_score=historical_score,
                     tenant_id=repo.tenant_id,
                     source_type=source_type,
@@ -1263,6 +1482,14 @@ Return ONLY the JSON array, no other text."""
                 memory_increase_mb=round(memory_increase_mb, 2),
             )
 
+            # Snapshot aggregate metrics for trend reporting
+            try:
+                from app.services.trend_metrics_service import TrendMetricsService
+
+                TrendMetricsService(self.db, repo.tenant_id).capture_snapshot(repo.id, scan_progress.id)
+            except Exception as e:
+                logger.error(f"Error capturing scan metrics: {e}")
+
             return {
                 "repository_id": repo.id,
                 "scan_type": "database",
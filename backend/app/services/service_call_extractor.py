@@ -0,0 +1,715 @@
+"""Extract internal HTTP and gRPC calls between services.
+
+An authorization decision in one service often rests on a call made by
+another: a gateway forwards the user's bearer token to the orders service,
+a worker calls billing with its own client-credentials token, and a BFF
+reaches inventory over plaintext gRPC with no credentials at all. These
+extractors find the outbound calls of requests, httpx, fetch, axios, Go's
+net/http, RestTemplate, WebClient, Feign, HttpClient, and gRPC channels and
+stubs, name the callee from the URL host or the configuration key its base
+URL comes from, and classify the credentials the call carries, without LLM
+calls. Calls to hosts outside the cluster (public domains) are left out.
+"""
+
+import re
+from dataclasses import dataclass
+from enum import Enum
+from functools import lru_cache
+from pathlib import PurePosixPath
+
+from app.services.extractor_utils import group, line_of
+
+
+class CallProtocol(str, Enum):
+    """Transport of a service call."""
+
+    HTTP = "http"
+    GRPC = "grpc"
+
+
+class CallCredential(str, Enum):
+    """Whose credentials a service call carries."""
+
+    USER_TOKEN = "user_token"  # Forwards the end user's token or exchanges it on their behalf
+    SERVICE_ACCOUNT = "service_account"  # Authenticates as the calling service (client credentials, API key, mTLS)
+    NONE = "none"  # Sends no credentials
+
+
+@dataclass
+class ServiceCall:
+    """An outbound call to another service and the credentials it carries."""
+
+    protocol: CallProtocol
+    client: str  # Library or API making the call
+    target: str  # Service name of the callee, from a URL host or configuration key
+    method: str  # HTTP method, or "*" for a gRPC stub
+    path: str  # Request path, or the gRPC service name
+    file_path: str
+    line_start: int
+    line_end: int
+    credential: CallCredential
+    credential_evidence: str | None = None  # Code the credential was recognized by
+    user_input: str | None = None  # Request input the call's URL or payload carries
+    input_check: str | None = None  # Authorization or ownership check the caller makes before the call
+
+
+SOURCE_SUFFIXES = (".py", ".js", ".mjs", ".cjs", ".ts", ".java", ".kt", ".go", ".cs", ".rb")
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(
+    r"requests\.|httpx|fetch\(|axios|got\.|http\.(?:Get|Post|Head|NewRequest)|RestTemplate|WebClient|RestClient|FeignClient|"
+    r"HttpClient|HttpRequestMessage|HTTParty|grpc|Grpc|GrpcChannel"
+)
+
+# Lines before a call searched for the credentials it attaches, within its function
+CONTEXT_LINES_BEFORE = 8
+MAX_CONTEXT_CHARS = 2000
+FUNCTION_START = re.compile(
+    r"[ \t]*(?:(?:async\s+)?def\s|(?:export\s+)?(?:async\s+)?function\b|func\b|"
+    r"(?:(?:public|private|protected|internal|static|async|override|suspend|fun)\s+)+[^;=\n]*\(|(?:async\s+)?\w+\s*\([^()\n]*\)\s*\{\s*$)"
+)
+
+# Forwarding the incoming request's token, or exchanging it for one on the user's behalf
+USER_TOKEN = re.compile(
+    r"\b(?:req|request|ctx|context|c|r|incoming|httpRequest|servletRequest|HttpContext\.Request)\s*\.\s*"
+    r"(?:headers?|Header|Headers|get|GetHeader|getHeader|META)\b[^\n]{0,60}?(?:HTTP_)?authorization"
+    r"|getHeader\s*\(\s*(?:HttpHeaders\.AUTHORIZATION|\"Authorization\")|\bFromIncomingContext\b"
+    r"|\bforward(?:ed)?_?(?:auth|token)\w*|\bpropagat\w*_?(?:token|auth)\w*"
+    r"|\b(?:Servlet|Server)BearerExchangeFilterFunction\b|\bTokenRelay\b|\bGetTokenAsync\s*\("
+    r"|\buser_?token\b|\bsubject_token\b|\bon_?behalf_?of\b|\btoken[-_]?exchange\b",
+    re.IGNORECASE,
+)
+
+# Credentials of the calling service itself
+SERVICE_CREDENTIAL = re.compile(
+    r"\bclient_?credentials\b|\bclientcredentials\.|\bservice[_-]?(?:account|token|key|jwt|credentials?)\b|\bs2s\b|"
+    r"\bmachine[_-]?token\b|\bx-api-key\b|\bapi[_-]?key\b|\binternal[_-]?(?:token|secret|key)\b|"
+    r"\bfetch_id_token\b|\bIDTokenCredentials\b|\bworkload[_-]?identity\b|\bManagedIdentityCredential\b|"
+    r"\bDefaultAzureCredential\b|\bAcquireTokenForClient\b|\bmtls\b|\bclient[_-]?cert\w*|\bcert\s*=|\bcertificate_chain\b|"
+    r"\bLoadX509KeyPair\b|\bClientCertificates\b|\bWithPerRPCCredentials\b|\boauth\.NewOauthAccess\b|\bTokenSource\b|"
+    r"\bCallCredentials\b|\baccess_token_call_credentials\b|\bbasic_?auth\b|\bsetBasicAuth\b|\bauth\s*=\s*\(",
+    re.IGNORECASE,
+)
+
+# Values taken from the incoming request: path, query, and body parameters
+USER_INPUT = re.compile(
+    r"\b(?:req|request|ctx|c|r|Request|HttpContext\.Request)\s*\.\s*(?:params|query|body|args|form|json|GET|POST|data|files|"
+    r"path_params|query_params|match_info|Query|Form|RouteValues|Body|PathValue|FormValue|URL\s*\.\s*Query)\b"
+    r"|\b(?:c|ctx)\s*\.\s*(?:Param|Query|PostForm|DefaultQuery|QueryParam|FormValue|Bind\w*)\s*\(|\bmux\.Vars\s*\(|\bchi\.URLParam\s*\("
+    r"|(?<![\w.])params\s*(?:\[|\.(?:require|permit|fetch)\b)|\bgetParameter\s*\(|\bgetPathVariable\w*\s*\("
+)
+# Handler parameters bound from the request
+INPUT_PARAMETER = re.compile(
+    r"@(?:PathVariable|RequestParam|RequestBody|PathParam|QueryParam|FormParam)\b(?:\s*\([^)]*\))?\s+(?:final\s+)?[\w.<>\[\], ?]+?\s+(\w+)\s*[,)]"
+    r"|\[\s*From(?:Route|Query|Body|Form)\b[^\]]*\]\s*[\w.<>\[\]?]+\s+(\w+)"
+)
+PYTHON_HANDLER = re.compile(r"[ \t]*(?:async\s+)?def\s+\w+\s*\(([^)]*)\)")
+# Checks that the user may act on the input, made before the call
+INPUT_CHECK = re.compile(
+    r"\b(?:authorize!?|check_?(?:permission|access|owner\w*)|has_?(?:permission|perm|role|authority|access)\w*|hasRole|hasAuthority|"
+    r"is_?(?:authorized|owner|allowed)\w*|user_can\w*|can(?:_access)?\?|permission_required|verify_?(?:owner\w*|access)|ensure_?(?:owner\w*|access|can\w*)|"
+    r"PermissionDenied|Forbidden\w*|AccessDenied\w*|StatusForbidden|HTTP_403\w*|enforcer\s*\.\s*enforce|AuthorizeAsync|isAuthorized|"
+    r"get_object_or_404\s*\([^\n]{0,80}\b(?:user|owner)\b)"
+    r"|\b(?:abort|status|sendStatus|HttpStatus)\s*\(?\s*\.?\s*(?:403|FORBIDDEN)\b"
+    r"|\b\w*(?:owner|user)_?[iI]d\s*[!=]==?|[!=]==?\s*(?:current_?user|request\.user|req\.user|user)\s*\.\s*[iI][dD]\b",
+    re.IGNORECASE,
+)
+
+HTTP_VERBS = {
+    "get": "GET", "head": "HEAD", "post": "POST", "put": "PUT", "patch": "PATCH", "delete": "DELETE",
+    "getforobject": "GET", "getforentity": "GET", "postforobject": "POST", "postforentity": "POST",
+    "postforlocation": "POST", "patchforobject": "PATCH", "postform": "POST",
+    "getasync": "GET", "getstringasync": "GET", "getfromjsonasync": "GET", "postasync": "POST",
+    "postasjsonasync": "POST", "putasync": "PUT", "putasjsonasync": "PUT", "patchasync": "PATCH",
+    "deleteasync": "DELETE",
+}  # fmt: skip
+VERB_ARGUMENT = re.compile(r"""\bmethod\s*[:=]\s*['"](\w+)['"]|HttpMethod\.(\w+)|http\.Method(\w+)|^\s*['"](GET|POST|PUT|PATCH|DELETE|HEAD)['"]""", re.IGNORECASE)
+
+# (client, pattern); group "verb" names the HTTP method when the call does
+HTTP_CALLS = [
+    ("fetch", re.compile(r"(?<![\w.])fetch\s*\(")),
+    ("axios", re.compile(r"\baxios\s*(?:\.\s*(?P<verb>get|post|put|patch|delete|head|request)\s*)?\(")),
+    ("got", re.compile(r"\bgot\s*\.\s*(?P<verb>get|post|put|patch|delete)\s*\(")),
+    ("requests", re.compile(r"\b(?P<client>requests|httpx)\s*\.\s*(?P<verb>get|post|put|patch|delete|head|request)\s*\(")),
+    ("net/http", re.compile(r"\bhttp\s*\.\s*(?:(?P<verb>Get|Post|Head|PostForm)|NewRequest(?:WithContext)?)\s*\(")),
+    ("RestTemplate", re.compile(
+        r"\b\w*[rR]estTemplate\s*\.\s*(?P<verb>getForObject|getForEntity|postForObject|postForEntity|postForLocation|"
+        r"patchForObject|put|delete|exchange)\s*\("
+    )),
+    ("HttpClient", re.compile(
+        r"\b_?\w*(?:[cC]lient|[hH]ttp)\s*\.\s*(?P<verb>GetAsync|GetStringAsync|GetFromJsonAsync|PostAsync|PostAsJsonAsync|"
+        r"PutAsync|PutAsJsonAsync|PatchAsync|DeleteAsync)\s*\("
+    )),
+    ("HttpRequestMessage", re.compile(r"\bnew\s+HttpRequestMessage\s*\(")),
+    ("HTTParty", re.compile(r"\bHTTParty\s*\.\s*(?P<verb>get|post|put|patch|delete)\s*\(")),
+]  # fmt: skip
+
+# Clients created once with a base URL and reused for many calls
+CLIENT_INSTANCES = [
+    ("axios", re.compile(r"\b(?:const|let|var)\s+(\w+)\s*=\s*axios\s*\.\s*create\s*\("), re.compile(r"\bbaseURL\s*:\s*")),
+    ("httpx", re.compile(r"(?:\bself\.)?\b(\w+)\s*=\s*httpx\s*\.\s*(?:Async)?Client\s*\("), re.compile(r"\bbase_url\s*=\s*")),
+    (
+        "WebClient",
+        re.compile(r"(?:\bthis\.)?\b(\w+)\s*=\s*(?:WebClient|RestClient)\s*\.\s*builder\s*\(\s*\)"),
+        re.compile(r"\.\s*baseUrl\s*\(\s*"),
+    ),
+    ("HttpClient", re.compile(r"\b(\w+)\s*\.\s*BaseAddress\s*=\s*new\s+Uri\s*\("), re.compile(r"new\s+Uri\s*\(\s*")),
+]
+INSTANCE_CALL = re.compile(
+    r"(?:\b(?:this|self)\s*\.\s*)?\b(\w+)\s*\.\s*(?:(get|post|put|patch|delete)\s*\(\s*\)\s*\.\s*uri|(get|post|put|patch|delete|head|"
+    r"request|GetAsync|PostAsync|PutAsync|PatchAsync|DeleteAsync|GetFromJsonAsync|PostAsJsonAsync|PutAsJsonAsync))\s*\("
+)
+INSTANCE_ALIAS = re.compile(r"(?:\b(?:this|self)\s*\.\s*)?\b(\w+)\s*=\s*(?:\b(?:this|self)\s*\.\s*)?(\w+)\s*;?\s*$", re.MULTILINE)
+
+# Feign declares the callee on an interface whose methods are the calls
+FEIGN_CLIENT = re.compile(r"@FeignClient\s*\(")
+FEIGN_MAPPING = re.compile(r"@(Get|Post|Put|Patch|Delete|Request)Mapping\s*(?:\(\s*(?:value\s*=\s*|path\s*=\s*)?\"([^\"]*)\")?")
+
+# gRPC channels, and the stubs created on them
+GRPC_CHANNELS = [
+    ("grpc-go", re.compile(r"\bgrpc\s*\.\s*(?:Dial|DialContext|NewClient)\s*\(")),
+    ("grpcio", re.compile(r"\bgrpc\s*\.\s*(?:aio\s*\.\s*)?(?:insecure|secure)_channel\s*\(")),
+    ("grpc-java", re.compile(r"\b(?:ManagedChannelBuilder|NettyChannelBuilder)\s*\.\s*(?:forAddress|forTarget)\s*\(")),
+    ("grpc-dotnet", re.compile(r"\bGrpcChannel\s*\.\s*ForAddress\s*\(")),
+    ("grpc-js", re.compile(r"\bnew\s+[\w.]*?(\w+)\s*\((?=[^()\n]*\bcredentials\s*\.\s*create(?:Insecure|Ssl)\b)")),
+]
+GRPC_STUBS = re.compile(
+    r"\b\w+\s*\.\s*New(\w+?)Client\s*\(|\b\w+_pb2_grpc\s*\.\s*(\w+?)Stub\s*\(|\b(\w+?)Grpc\s*\.\s*new(?:Blocking|Future)?Stub\s*\("
+    r"|\bnew\s+(?:\w+\s*\.\s*)*(\w+?)Client\s*\(\s*channel\b"
+)
+GRPC_INSECURE = re.compile(r"\binsecure\s*\.\s*NewCredentials\b|\bWithInsecure\b|\binsecure_channel\b|\busePlaintext\b|\bcreateInsecure\b|\bInsecure\b")
+GRPC_USER_METADATA = re.compile(r"\b(?:AppendToOutgoingContext|NewOutgoingContext)\b[^\n]{0,80}authorization|metadata\s*=\s*[^\n]{0,80}authorization", re.IGNORECASE)
+
+URL_SCHEME = re.compile(r"^[a-z][\w+.-]*://", re.IGNORECASE)
+STRING_PIECE = re.compile(r"""^(?P<prefix>[fFrRbB$@]{0,2})(?P<quote>['"`])(?P<body>.*)(?P=quote)$""", re.DOTALL)
+INTERPOLATION = re.compile(r"\$\{([^}]*)\}|#\{([^}]*)\}|\{([^{}]*)\}|%[sdv]")
+ENV_REFERENCE = re.compile(
+    r"""(?:[gG]etenv|environ\.get|environ\s*\[|process\.env\s*\.|process\.env\s*\[|System\.getenv|GetEnvironmentVariable|ENV\s*\[|ENV\.fetch)\s*\(?\s*['"]?([A-Za-z_]\w*)"""
+)
+PROPERTY_PLACEHOLDER = re.compile(r"\$\{([\w.-]+)(?::[^}]*)?\}")
+ASSIGNMENT = re.compile(r"(?<![\w.$])([A-Za-z_$][\w$]*)\s*(?::\s*[\w\[\]., |]+?)?\s*(?::=|=(?!=))\s*([^\n;]+)")
+VALUE_INJECTION = re.compile(r"""@Value\(\s*"\$\{([\w.-]+)[^"]*"\s*\)\s*(?:private|protected|public)?\s*(?:final\s+)?String\s+(\w+)\b""")
+CONFIG_KEY = re.compile(r"""\[\s*['"]([\w:.-]+)['"]\s*\]""")
+PLACEHOLDER = "\x00"
+
+# Words that describe how a service is reached rather than which service it is
+GENERIC_WORDS = {
+    "http", "https", "url", "urls", "uri", "host", "hostname", "base", "baseurl", "endpoint", "endpoints", "addr",
+    "address", "api", "apis", "service", "services", "svc", "server", "client", "internal", "env", "config",
+    "configuration", "settings", "os", "process", "self", "this", "getenv", "environ", "value", "remote", "upstream",
+    "target", "grpc", "rest", "port", "default", "cluster", "local", "cfg", "conf", "app", "get", "system", "properties",
+}  # fmt: skip
+INTERNAL_SUFFIXES = (".svc", ".svc.cluster.local", ".cluster.local", ".internal", ".local", ".consul", ".lan")
+
+
+def service_key(name: str | None) -> str | None:
+    """Normalized service name: "ORDERS_SERVICE_URL", "orders-service", and "ordersBaseUrl" all become "orders"."""
+    if not name:
+        return None
+    words = [w.lower() for w in re.findall(r"[A-Z]+(?![a-z])|[A-Z]?[a-z]+|\d+", name)]
+    words = [w for w in words if w not in GENERIC_WORDS and not w.isdigit()]
+    return "-".join(words) or None
+
+
+def _internal_host(host: str) -> bool:
+    """Whether a host names a service inside the cluster rather than a public or loopback address."""
+    host = host.lower()
+    if host in ("localhost", "127.0.0.1", "0.0.0.0") or re.fullmatch(r"[\d.]+", host):
+        return False
+    return "." not in host or host.endswith(INTERNAL_SUFFIXES)
+
+
+def _arguments(text: str, start: int, end: int) -> list[str]:
+    """Top-level comma-separated arguments of text[start:end]."""
+    parts, begin, i = [], start, start
+    while i < end:
+        c = text[i]
+        if c in "([{'\"`":
+            i = group(text, i) if c in "([{" else _string_end(text, i)
+            continue
+        if c == ",":
+            parts.append(text[begin:i].strip())
+            begin = i + 1
+        i += 1
+    parts.append(text[begin:end].strip())
+    return [p for p in parts if p]
+
+
+def _string_end(text: str, start: int) -> int:
+    """Offset just past the string literal opening at start."""
+    quote, i = text[start], start + 1
+    while i < len(text):
+        if text[i] == "\\":
+            i += 2
+            continue
+        if text[i] == quote or (text[i] == "\n" and quote != "`"):
+            return i + 1
+        i += 1
+    return len(text)
+
+
+def _template(expression: str) -> tuple[str, list[str]]:
+    """A URL expression as literal text with placeholders, and the expressions filling them in order."""
+    expression = expression.strip()
+    formatted = re.match(r"(?:fmt\.Sprintf|String\.format|format)\s*\(", expression)
+    if formatted:
+        args = _arguments(expression, formatted.end(), group(expression, formatted.end() - 1) - 1)
+        if args:
+            literal = STRING_PIECE.match(args[0])
+            if literal:
+                return INTERPOLATION.sub(PLACEHOLDER, literal.group("body")), args[1:]
+    # "...".format(...) keeps its placeholders in the literal
+    expression = re.sub(r"""(['"])\s*\.format\s*\(.*$""", r"\1", expression, flags=re.DOTALL)
+    template, references = "", []
+    for piece in _split_concatenation(expression):
+        literal = STRING_PIECE.match(piece)
+        if literal:
+            # Interpolations, format placeholders, and URI template variables alike become placeholders
+            body = literal.group("body")
+            for match in INTERPOLATION.finditer(body):
+                references.append(next((g for g in match.groups() if g), ""))
+            template += INTERPOLATION.sub(PLACEHOLDER, body)
+        else:
+            template += PLACEHOLDER
+            references.append(piece)
+    return template, references
+
+
+def _split_concatenation(expression: str) -> list[str]:
+    """Operands of a top-level "+" concatenation."""
+    parts, begin, i = [], 0, 0
+    while i < len(expression):
+        c = expression[i]
+        if c in "([{":
+            i = group(expression, i)
+            continue
+        if c in "'\"`":
+            i = _string_end(expression, i)
+            continue
+        if c == "+":
+            parts.append(expression[begin:i].strip())
+            begin = i + 1
+        i += 1
+    parts.append(expression[begin:].strip())
+    return [p for p in parts if p]
+
+
+def _parameter(reference: str) -> str:
+    """Path parameter name for an interpolated expression: {order_id} for req.params.order_id."""
+    names = re.findall(r"[A-Za-z_]\w*", reference)
+    return names[-1] if names and not reference.strip().endswith(")") else "param"
+
+
+def _normalize_path(path: str, references: list[str] | None = None) -> str:
+    """Request path with placeholders as {name} parameters, a leading slash, and no query string."""
+    path = path.split("?", 1)[0].split("#", 1)[0]
+    names = iter(references or [])
+    path = re.sub(r"\x00+", lambda match: "{" + _parameter(next(names, "")) + "}", path)
+    path = re.sub(r"/+", "/", "/" + path)
+    return path.rstrip("/") or "/"
+
+
+def _resolve(reference: str, text: str, depth: int = 0) -> tuple[str | None, str]:
+    """(service name, path prefix) a base URL reference stands for.
+
+    Environment variables and configuration keys name the service; variables
+    are followed to their assignment in the same file, whose default URL or
+    environment variable names it.
+    """
+    reference = reference.strip()
+    env = ENV_REFERENCE.search(reference)
+    if env:
+        default = re.search(r"""['"]([a-z][\w+.-]*://[^'"]+)['"]""", reference)
+        if default:
+            target, path = _url(default.group(0), text, depth + 1)
+            if target:
+                return target, path
+        return service_key(env.group(1)), ""
+    placeholder = PROPERTY_PLACEHOLDER.search(reference)
+    if placeholder:
+        return service_key(placeholder.group(1)), ""
+    config = CONFIG_KEY.search(reference)
+    if config:
+        return service_key(config.group(1)), ""
+    name = re.fullmatch(r"(?:[\w$]+\s*\.\s*)*([A-Za-z_$][\w$]*)", reference)
+    if not name:
+        return None, ""
+    name = name.group(1)
+    if depth < 2:
+        assignments, properties = _assignments(text)
+        assignment = assignments.get(name)
+        if assignment and assignment != reference:
+            target, path = _url(assignment, text, depth + 1)
+            if target:
+                return target, path
+        if name in properties:
+            return service_key(properties[name]), ""
+    return service_key(name), ""
+
+
+@lru_cache(maxsize=8)
+def _assignments(text: str) -> tuple[dict[str, str], dict[str, str]]:
+    """First value assigned to each variable in a file, and the property key each @Value field is injected from."""
+    assignments: dict[str, str] = {}
+    for match in ASSIGNMENT.finditer(text):
+        assignments.setdefault(match.group(1), match.group(2).strip())
+    properties = {match.group(2): match.group(1) for match in reversed(list(VALUE_INJECTION.finditer(text)))}
+    return assignments, properties
+
+
+def _url(expression: str, text: str, depth: int = 0) -> tuple[str | None, str]:
+    """(service name, path) of a URL expression, or (None, "") when it names no internal service."""
+    template, references = _template(expression)
+    if not template.strip():
+        return None, ""
+    if template == PLACEHOLDER:
+        # A bare variable: follow it to the URL it holds
+        return _resolve(references[0], text, depth) if references else (None, "")
+    scheme = URL_SCHEME.match(template)
+    if scheme:
+        authority_end = template.find("/", scheme.end())
+        authority = template[scheme.end() : authority_end if authority_end >= 0 else len(template)]
+        path = template[authority_end:] if authority_end >= 0 else "/"
+        host = re.split(r"[:@]", authority.split("@")[-1])[0]
+        parameters = references[authority.count(PLACEHOLDER) :]
+        if PLACEHOLDER in host:
+            index = template[: scheme.end() + authority.find(PLACEHOLDER)].count(PLACEHOLDER)
+            target, _ = _resolve(references[index], text, depth) if index < len(references) else (None, "")
+            return target, _normalize_path(path, parameters)
+        if not _internal_host(host):
+            return None, ""
+        return service_key(host.split(".")[0]), _normalize_path(path, parameters)
+    if template.startswith(PLACEHOLDER):
+        target, prefix = _resolve(references[0], text, depth)
+        return target, _normalize_path(prefix + template[1:], references[1:])
+    return None, _normalize_path(template, references) if template.startswith("/") else ""
+
+
+def _context(text: str, start: int, end: int) -> str:
+    """The call and the lines before it in the same function, searched for the credentials it carries."""
+    line_start = text.rfind("\n", 0, start) + 1
+    for _ in range(CONTEXT_LINES_BEFORE):
+        line_end = text.find("\n", line_start)
+        if line_start == 0 or FUNCTION_START.match(text, line_start, len(text) if line_end < 0 else line_end):
+            break
+        line_start = text.rfind("\n", 0, line_start - 1) + 1
+    return text[max(line_start, start - MAX_CONTEXT_CHARS) : end]
+
+
+def _expression(text: str, start: int) -> str:
+    """The expression starting at start, up to the comma, semicolon, or bracket that ends it."""
+    i = start
+    while i < len(text):
+        c = text[i]
+        if c in "([{":
+            i = group(text, i)
+            continue
+        if c in "'\"`":
+            i = _string_end(text, i)
+            continue
+        if c in ",;)]}\n":
+            break
+        i += 1
+    return text[start:i].strip()
+
+
+def _short(code: str) -> str:
+    """Code on one line, shortened for evidence."""
+    text = " ".join(code.split())
+    return text if len(text) <= 80 else text[:77] + "..."
+
+
+def classify_user_input(context: str, call: str) -> tuple[str | None, str | None]:
+    """Request input a call carries and the check the code before it makes on the user's right to it.
+
+    Input is read straight from the request in the call expression, or
+    reaches it through handler parameters bound from the request and
+    variables assigned from either.
+
+    Args:
+        context: The call and the lines before it in the same function
+        call: The call expression
+
+    Returns:
+        (input evidence, check evidence), each None if not found
+    """
+    inputs: dict[str, str] = {}
+    handler = PYTHON_HANDLER.match(context)
+    if handler:
+        names = [re.split(r"[:=]", p)[0].strip().lstrip("*") for p in handler.group(1).split(",")]
+        if {"request", "req"} & set(names):
+            inputs.update({n: f"parameter {n}" for n in names if n and n not in ("self", "cls", "request", "req")})
+    for match in INPUT_PARAMETER.finditer(context):
+        name = match.group(1) or match.group(2)
+        inputs[name] = f"parameter {name}"
+    for match in ASSIGNMENT.finditer(context):
+        name, value = match.groups()
+        direct = USER_INPUT.search(value)
+        if direct or any(re.search(rf"(?<![\w.]){re.escape(n)}\b", value) for n in inputs):
+            inputs.setdefault(name, _short(f"{name} = {value}"))
+    evidence = None
+    direct = USER_INPUT.search(call)
+    if direct:
+        evidence = _short(call[direct.start() : direct.start() + 60].split(",")[0].split(")")[0].split("}")[0])
+    else:
+        evidence = next((origin for name, origin in inputs.items() if re.search(rf"(?<![\w.]){re.escape(name)}\b", call)), None)
+    if evidence is None:
+        return None, None
+    check = INPUT_CHECK.search(context[: max(len(context) - len(call), 0)])
+    return evidence, _short(check.group(0)) if check else None
+
+
+def classify_credentials(text: str) -> tuple[CallCredential, str | None]:
+    """Credentials the code around a call attaches, and the code they were recognized by.
+
+    Forwarding the user's token wins over service credentials seen nearby,
+    since a token exchange uses both.
+    """
+    for credential, pattern in ((CallCredential.USER_TOKEN, USER_TOKEN), (CallCredential.SERVICE_ACCOUNT, SERVICE_CREDENTIAL)):
+        match = pattern.search(text)
+        if match:
+            return credential, " ".join(match.group(0).split())
+    return CallCredential.NONE, None
+
+
+def _verb(name: str | None, args: list[str]) -> str:
+    """HTTP method of a call, from the client method or its arguments."""
+    if name and name.lower() in HTTP_VERBS:
+        return HTTP_VERBS[name.lower()]
+    for arg in args:
+        match = VERB_ARGUMENT.search(arg)
+        if match:
+            return next(g for g in match.groups() if g).upper()
+    return "GET"
+
+
+def _url_argument(client: str, args: list[str]) -> str | None:
+    """The argument holding the URL."""
+    if not args:
+        return None
+    if client == "net/http" and len(args) >= 2 and VERB_ARGUMENT.search(args[0]) or client == "HttpRequestMessage":
+        return args[1] if len(args) > 1 else None
+    if client == "net/http" and len(args) >= 3 and re.fullmatch(r"ctx|context\.\w+\(\)|\w*[cC]tx", args[0]):
+        return args[2]
+    if re.match(r"""['"](?:GET|POST|PUT|PATCH|DELETE|HEAD)['"]""", args[0], re.IGNORECASE) and len(args) > 1:
+        return args[1]
+    if args[0].startswith("{"):
+        url = re.search(r"\burl\s*:\s*([^,\n}]+)", args[0])
+        return url.group(1) if url else None
+    return args[0]
+
+
+def _call(
+    protocol: CallProtocol,
+    client: str,
+    target: str | None,
+    method: str,
+    path: str,
+    file_path: str,
+    text: str,
+    start: int,
+    end: int,
+    extra: str = "",
+    context_end: int | None = None,
+) -> ServiceCall | None:
+    """Build a call, classifying the credentials in the code around it.
+
+    Args:
+        extra: Client setup code whose credentials the call also carries
+        context_end: Offset the code searched for credentials extends to, when
+            headers are set after the call expression (Go's http.NewRequest)
+    """
+    if not target:
+        return None
+    context = _context(text, start, max(end, context_end or 0))
+    credential, evidence = classify_credentials(context + "\n" + extra)
+    user_input, input_check = classify_user_input(context[: len(context) - max(context_end or 0, end) + end], text[start:end])
+    return ServiceCall(
+        protocol=protocol,
+        client=client,
+        target=target,
+        method=method,
+        path=path or "/",
+        file_path=file_path,
+        line_start=line_of(text, start),
+        line_end=line_of(text, end),
+        credential=credential,
+        credential_evidence=evidence,
+        user_input=user_input,
+        input_check=input_check,
+    )
+
+
+@dataclass
+class _Instance:
+    """A client created with a base URL."""
+
+    client: str
+    target: str | None
+    prefix: str
+    setup: str  # Creation code, searched for default headers
+
+
+def _instances(text: str) -> dict[str, _Instance]:
+    """Clients created with a base URL, by variable name."""
+    instances = {}
+    for client, pattern, base in CLIENT_INSTANCES:
+        for match in pattern.finditer(text):
+            statement_end = text.find(";", match.end())
+            paren = text.rfind("(", match.start(), match.end())
+            end = max(group(text, paren) if paren >= 0 else match.end(), statement_end if client == "WebClient" and statement_end >= 0 else 0)
+            setup = text[match.start() : end]
+            found = base.search(setup)
+            target, prefix = _url(_expression(setup, found.end()), text) if found else (None, "")
+            instances[match.group(1)] = _Instance(client, target, prefix, setup)
+    # Clients injected and then stored in a field: _client = client;
+    for match in INSTANCE_ALIAS.finditer(text):
+        if match.group(2) in instances and match.group(1) not in instances:
+            instances[match.group(1)] = instances[match.group(2)]
+    return instances
+
+
+def extract_http_calls(file_path: str, text: str) -> list[ServiceCall]:
+    """Extract outbound HTTP calls to internal services from one source file."""
+    calls = []
+    taken: set[int] = set()
+    for client, pattern in HTTP_CALLS:
+        for match in pattern.finditer(text):
+            close = group(text, match.end() - 1)
+            args = _arguments(text, match.end(), close - 1)
+            groups = match.groupdict()
+            url = _url_argument(client, args)
+            if url is None:
+                continue
+            if client == "HttpClient" and url.lstrip().startswith("new HttpRequestMessage"):
+                continue
+            target, path = _url(url, text)
+            label = groups.get("client") or client
+            sent = text.find(".Do(", close) if "NewRequest" in match.group(0) else -1
+            call = _call(
+                CallProtocol.HTTP, label, target, _verb(groups.get("verb"), args), path, file_path, text, match.start(), close, context_end=sent
+            )
+            if call:
+                calls.append(call)
+                taken.add(match.start())
+
+    instances = _instances(text)
+    for match in INSTANCE_CALL.finditer(text):
+        instance = instances.get(match.group(1))
+        if instance is None or match.start() in taken:
+            continue
+        close = group(text, match.end() - 1)
+        args = _arguments(text, match.end(), close - 1)
+        if not args:
+            continue
+        target, path = _url(args[0], text)
+        if target is None and instance.target:
+            template, references = _template(args[0])
+            target, path = instance.target, _normalize_path(instance.prefix + "/" + template, references)
+        verb = _verb(match.group(2) or match.group(3), args)
+        # WebClient requests add headers further along the same chain
+        statement_end = text.find(";", close) if match.group(2) else -1
+        call = _call(
+            CallProtocol.HTTP, instance.client, target, verb, path, file_path, text, match.start(), close, instance.setup, statement_end
+        )
+        if call:
+            calls.append(call)
+    return calls
+
+
+def extract_feign_calls(file_path: str, text: str) -> list[ServiceCall]:
+    """Extract the calls a Feign client interface declares, one per mapped method."""
+    calls = []
+    # Request interceptors configured in the same file attach the client's credentials
+    _, interceptor = classify_credentials(text) if FEIGN_CLIENT.search(text) else (None, None)
+    for match in FEIGN_CLIENT.finditer(text):
+        close = group(text, match.end() - 1)
+        args = text[match.end() : close - 1]
+        name = re.search(r"""\b(?:name|value)\s*=\s*"([^"]+)"|^\s*"([^"]+)"\s*$""", args)
+        url = re.search(r"""\burl\s*=\s*("[^"]*")""", args)
+        target, prefix = _url(url.group(1), text) if url else (None, "")
+        target = target or (service_key(name.group(1) or name.group(2)) if name else None)
+        body_start = text.find("{", close)
+        if body_start < 0:
+            continue
+        body_end = group(text, body_start)
+        class_path = re.search(r"""\bpath\s*=\s*"([^"]*)\"""", args)
+        prefix += class_path.group(1) if class_path else ""
+        for mapping in FEIGN_MAPPING.finditer(text, body_start, body_end):
+            verb = mapping.group(1).upper() if mapping.group(1) != "Request" else "*"
+            statement_end = text.find(";", mapping.end(), body_end)
+            end = statement_end if statement_end >= 0 else mapping.end()
+            call = _call(
+                CallProtocol.HTTP,
+                "Feign",
+                target,
+                verb,
+                _normalize_path(prefix + "/" + (mapping.group(2) or "")),
+                file_path,
+                text,
+                mapping.start(),
+                end,
+                interceptor or "",
+            )
+            if call:
+                # Parameters of a Feign method are its callers' arguments, not request input
+                call.user_input = call.input_check = None
+                calls.append(call)
+    return calls
+
+
+def extract_grpc_calls(file_path: str, text: str) -> list[ServiceCall]:
+    """Extract gRPC channels to internal services, one call per stub created in the file."""
+    stubs = list(dict.fromkeys(next(g for g in m.groups() if g) for m in GRPC_STUBS.finditer(text)))
+    calls = []
+    for client, pattern in GRPC_CHANNELS:
+        for match in pattern.finditer(text):
+            close = group(text, match.end() - 1)
+            args = _arguments(text, match.end(), close - 1)
+            if not args:
+                continue
+            address = args[1] if client == "grpc-go" and "DialContext" in match.group(0) and len(args) > 1 else args[0]
+            # A grpc-js client is its own channel
+            names = [match.group(1)] if client == "grpc-js" else stubs or ["*"]
+            target = _grpc_target(address, text)
+            insecure = GRPC_INSECURE.search(text[match.start() : close])
+            for stub in names:
+                call = _call(CallProtocol.GRPC, client, target, "*", stub, file_path, text, match.start(), close, "" if insecure else text)
+                if call is None:
+                    continue
+                if GRPC_USER_METADATA.search(text):
+                    call.credential, call.credential_evidence = CallCredential.USER_TOKEN, "authorization metadata on outgoing calls"
+                calls.append(call)
+    return calls
+
+
+def _grpc_target(address: str, text: str) -> str | None:
+    """Service name of a gRPC target address such as "orders:50051" or "dns:///orders.default.svc:443"."""
+    literal = STRING_PIECE.match(address.strip())
+    if literal and PLACEHOLDER not in INTERPOLATION.sub(PLACEHOLDER, literal.group("body")):
+        host = re.sub(r"^(?:dns|https?):/*", "", literal.group("body")).split(":")[0].split("/")[0]
+        return service_key(host.split(".")[0]) if host and _internal_host(host) else None
+    target, _ = _resolve(address, text) if not literal else _url(address, text)
+    return target
+
+
+def is_service_call_source(file_path: str) -> bool:
+    """Check whether a path may make calls to other services."""
+    return PurePosixPath(file_path).suffix in SOURCE_SUFFIXES and not file_path.endswith(".d.ts")
+
+
+def extract_service_calls(files: dict[str, str]) -> list[ServiceCall]:
+    """Extract HTTP and gRPC calls to internal services from a service's files.
+
+    Args:
+        files: Relative path -> content; files that cannot make calls are ignored
+
+    Returns:
+        Calls in file order
+    """
+    calls = []
+    for file_path, text in files.items():
+        if not is_service_call_source(file_path) or not PREFILTER.search(text):
+            continue
+        calls += extract_http_calls(file_path, text) + extract_feign_calls(file_path, text) + extract_grpc_calls(file_path, text)
+    return calls
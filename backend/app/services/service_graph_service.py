@@ -0,0 +1,269 @@
+"""Service for building the dependency graph of cross-service calls.
+
+Each repository is treated as one service. This service reads the internal
+HTTP and gRPC calls in every clone, resolves each call's target to the
+repository of the same name and to the endpoint rule it hits, and builds a
+call graph whose hops are annotated with the credentials they carry. A hop
+that calls an endpoint enforcing roles or conditions without forwarding the
+end user's token drops the user context: the callee decides on the calling
+service's identity, not on the user the request was made for.
+"""
+
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import asdict
+from enum import Enum
+from pathlib import Path
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.repository import Repository
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.decision_simulation_service import DecisionSimulationService, _path_matches
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+from app.services.service_call_extractor import (
+    CallCredential,
+    CallProtocol,
+    ServiceCall,
+    extract_service_calls,
+    is_service_call_source,
+    service_key,
+)
+
+logger = structlog.get_logger(__name__)
+
+
+class UserContext(str, Enum):
+    """What happens to the end user's identity on a hop."""
+
+    PROPAGATED = "propagated"  # The user's token reaches the callee
+    DROPPED = "dropped"  # The callee authorizes on roles or conditions but only sees the caller's identity
+    NOT_REQUIRED = "not_required"  # The callee endpoint makes no authorization decision
+    UNKNOWN = "unknown"  # The callee or its endpoint is not among the mined services
+
+
+def match_rule(call: ServiceCall, rules: list[EndpointRule]) -> EndpointRule | None:
+    """Find the callee endpoint rule a call hits.
+
+    HTTP calls match on method and path template; gRPC calls match the
+    first rule whose path or resource names the called gRPC service.
+
+    Args:
+        call: Outbound call
+        rules: Endpoint rules of the callee
+
+    Returns:
+        Matching rule, or None
+    """
+    if call.protocol == CallProtocol.GRPC:
+        service = call.path.lower()
+        return next((r for r in rules if service in r.path.lower() or service in (r.resource or "").lower()), None)
+    for rule in rules:
+        if rule.method not in (call.method, "*"):
+            continue
+        if rule.path == call.path or _path_matches(rule.path, call.path):
+            return rule
+    return None
+
+
+def user_context(call: ServiceCall, rule: EndpointRule | None) -> UserContext:
+    """Classify what a hop does with the end user's identity."""
+    if call.credential == CallCredential.USER_TOKEN:
+        return UserContext.PROPAGATED
+    if rule is None:
+        return UserContext.UNKNOWN
+    if rule.roles or rule.conditions:
+        return UserContext.DROPPED
+    return UserContext.NOT_REQUIRED
+
+
+def build_graph(
+    repositories: list[Repository],
+    calls_by_repository: dict[int, list[ServiceCall]],
+    rules_by_repository: dict[int, list[EndpointRule]],
+) -> dict:
+    """Build the service call graph from per-repository calls and endpoint rules.
+
+    Args:
+        repositories: Services (repositories) in scope
+        calls_by_repository: Outbound calls read from each repository's clone
+        rules_by_repository: Endpoint rules mined for each repository
+
+    Returns:
+        Graph with nodes, aggregated edges, per-call hops, and a summary
+    """
+    by_key = {service_key(r.name): r for r in repositories}
+    names = {r.id: r.name for r in repositories}
+    hops = []
+    for repository in repositories:
+        for call in calls_by_repository.get(repository.id, []):
+            target = by_key.get(call.target)
+            rule = match_rule(call, rules_by_repository.get(target.id, [])) if target else None
+            hops.append(
+                asdict(call)
+                | {
+                    "source_repository_id": repository.id,
+                    "source": repository.name,
+                    "target_repository_id": target.id if target else None,
+                    "target": target.name if target else call.target,
+                    "target_endpoint": rule.key if rule else None,
+                    "target_roles": list(rule.roles) if rule else [],
+                    "target_conditions": list(rule.conditions) if rule else [],
+                    "target_policy_ids": list(rule.policy_ids) if rule else [],
+                    "user_context": user_context(call, rule),
+                }
+            )
+
+    edges: dict[tuple[str, str], dict] = {}
+    for hop in hops:
+        edge = edges.setdefault(
+            (hop["source"], hop["target"]),
+            {
+                "source": hop["source"],
+                "target": hop["target"],
+                "source_repository_id": hop["source_repository_id"],
+                "target_repository_id": hop["target_repository_id"],
+                "protocols": [],
+                "credentials": [],
+                "calls": 0,
+                "dropped": 0,
+            },
+        )
+        for key, value in (("protocols", hop["protocol"].value), ("credentials", hop["credential"].value)):
+            if value not in edge[key]:
+                edge[key].append(value)
+        edge["calls"] += 1
+        edge["dropped"] += hop["user_context"] == UserContext.DROPPED
+
+    nodes = [{"name": r.name, "repository_id": r.id, "mined": True} for r in repositories]
+    unresolved = sorted({h["target"] for h in hops if h["target_repository_id"] is None})
+    nodes += [{"name": name, "repository_id": None, "mined": False} for name in unresolved if name not in names.values()]
+    hops.sort(key=lambda h: (h["user_context"] != UserContext.DROPPED, h["source"], h["file_path"], h["line_start"]))
+    return {
+        "nodes": nodes,
+        "edges": sorted(edges.values(), key=lambda e: (-e["dropped"], e["source"], e["target"])),
+        "hops": hops,
+        "summary": {
+            "services": len(nodes),
+            "calls": len(hops),
+            "by_credential": dict(Counter(h["credential"].value for h in hops)),
+            "by_user_context": dict(Counter(h["user_context"].value for h in hops)),
+            "dropped": sum(h["user_context"] == UserContext.DROPPED for h in hops),
+        },
+    }
+
+
+def service_calls(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the outbound service calls of a repository clone in path order."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    files: dict[str, str] = {}
+    for path in sorted(clone.rglob("*")):
+        if not is_service_call_source(path.name):
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        files[relative.as_posix()] = path.read_text(encoding="utf-8", errors="ignore")
+    yield from (asdict(c) for c in extract_service_calls(files))
+
+
+class ServiceGraphService:
+    """Builds the cross-service call graph with the credentials each hop carries."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[ServiceCall]:
+        """Read outbound service calls from a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Calls in path order
+        """
+        return [
+            ServiceCall(**{**c, "protocol": CallProtocol(c["protocol"]), "credential": CallCredential(c["credential"])})
+            for c in run_pass(root, service_calls)
+        ]
+
+    def load(
+        self, repository_id: int | None = None
+    ) -> tuple[list[Repository], dict[int, list[ServiceCall]], dict[int, list[EndpointRule]]]:
+        """Load the tenant's services with their outbound calls and endpoint rules.
+
+        Args:
+            repository_id: Repository that must exist among them
+
+        Returns:
+            (repositories, calls by repository ID, endpoint rules by repository ID)
+
+        Raises:
+            ValueError: If the requested repository does not exist
+        """
+        repositories = self._repositories()
+        if repository_id is not None and all(r.id != repository_id for r in repositories):
+            raise ValueError(f"Repository {repository_id} not found")
+        simulation = DecisionSimulationService(self.db, self.tenant_id)
+        calls, rules = {}, {}
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            calls[repository.id] = self.scan_clone(root) if root.is_dir() else []
+            rules[repository.id] = EndpointMappingService.map_policies(
+                simulation.load_policies(repository_id=repository.id)
+            )
+        return repositories, calls, rules
+
+    def graph(self, repository_id: int | None = None, dropped_only: bool = False) -> dict:
+        """Build the service dependency graph of the tenant.
+
+        Targets are resolved against all of the tenant's repositories, so a
+        restriction to one repository keeps only the calls it makes and the
+        calls made to it.
+
+        Args:
+            repository_id: Restrict to calls made by or to one repository
+            dropped_only: Keep only hops that drop the user context
+
+        Returns:
+            Graph with nodes, edges, hops, and a summary
+
+        Raises:
+            ValueError: If the requested repository does not exist
+        """
+        repositories, calls, rules = self.load(repository_id)
+        graph = build_graph(repositories, calls, rules)
+        if repository_id is not None or dropped_only:
+            graph["hops"] = [
+                h
+                for h in graph["hops"]
+                if (repository_id is None or repository_id in (h["source_repository_id"], h["target_repository_id"]))
+                and (not dropped_only or h["user_context"] == UserContext.DROPPED)
+            ]
+            kept = {(h["source"], h["target"]) for h in graph["hops"]}
+            graph["edges"] = [e for e in graph["edges"] if (e["source"], e["target"]) in kept]
+        logger.info(
+            "service_graph_built",
+            tenant_id=self.tenant_id,
+            services=graph["summary"]["services"],
+            calls=graph["summary"]["calls"],
+            dropped=graph["summary"]["dropped"],
+        )
+        return graph
@@ -0,0 +1,388 @@
+"""Service for one view of a monorepo service whose code spans several languages.
+
+A service in a monorepo is rarely one build: a Go backend, the TypeScript BFF
+in front of it, and the SQL that defines its tables are three components with
+policies mined by three analyzers. This service finds the components of a
+clone from their build manifests (go.mod, package.json, pom.xml, ...) and
+SQL directories, names the service each belongs to from its path with layer
+words such as api, bff, and migrations stripped (services/expenses/api and
+services/expenses/bff are both "expenses"; backend/ and bff/ at the root are
+the repository's one service), and files every mined policy under its
+service and layer: edge (BFFs, gateways, frontends), app (backends), or data
+(database policies). Calls the edge makes into the app layer are linked to
+the app endpoints they hit. Components the heuristics misplace can be pinned
+from a JSON layout file (settings.SERVICE_LAYOUT_PATH).
+"""
+
+import json
+import re
+from collections import Counter
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass
+from enum import Enum
+from pathlib import Path, PurePosixPath
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.policy import Policy, SourceType
+from app.models.repository import Repository
+from app.services.coverage_metrics_service import SKIPPED_DIRECTORIES
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.service_call_extractor import (
+    CallCredential,
+    CallProtocol,
+    ServiceCall,
+    extract_service_calls,
+    is_service_call_source,
+    service_key,
+)
+from app.services.service_graph_service import match_rule, user_context
+
+logger = structlog.get_logger(__name__)
+
+
+class Layer(str, Enum):
+    """Where in a service's request path a component sits."""
+
+    EDGE = "edge"  # BFFs, gateways, and frontends in front of the backend
+    APP = "app"  # Backends deciding on the service's resources
+    DATA = "data"  # Database schemas, grants, and row-level policies
+
+
+# Build manifests marking a component, and its language
+MANIFESTS = {
+    "go.mod": "go",
+    "package.json": "javascript",
+    "pyproject.toml": "python",
+    "requirements.txt": "python",
+    "setup.py": "python",
+    "Pipfile": "python",
+    "pom.xml": "java",
+    "build.gradle": "java",
+    "build.gradle.kts": "kotlin",
+    "Gemfile": "ruby",
+    "composer.json": "php",
+    "Cargo.toml": "rust",
+}
+# Path words naming a layer rather than the service
+EDGE_WORDS = {"bff", "gateway", "edge", "web", "frontend", "ui", "client", "proxy", "portal"}
+DATA_WORDS = {"db", "database", "databases", "migrations", "migration", "sql", "schema", "schemas", "data", "ddl"}
+APP_WORDS = {"backend", "server", "api", "app", "core", "service", "svc", "worker"}
+# Path segments grouping components rather than naming them
+CONTAINER_SEGMENTS = {"services", "apps", "packages", "cmd", "src", "internal", "libs", "modules", "components", "projects"}
+
+
+@dataclass
+class Component:
+    """A build of one language inside a clone, and the service and layer it belongs to."""
+
+    path: str  # Directory relative to the clone root; "" for the root
+    language: str
+    service: str
+    layer: Layer
+    pinned: bool = False  # Placed by the layout file rather than inferred
+
+
+def load_service_layout(path: str | None = None) -> list[dict]:
+    """Load pinned components from an optional layout file.
+
+    The file holds {"components": [{"path": "web/checkout", "service": "checkout", "layer": "edge"}]};
+    "language" may be given too.
+
+    Args:
+        path: Layout file path (defaults to settings.SERVICE_LAYOUT_PATH)
+
+    Returns:
+        Pinned components, possibly empty
+
+    Raises:
+        ValueError: If the layout file cannot be read or names an unknown layer
+    """
+    path = path if path is not None else settings.SERVICE_LAYOUT_PATH
+    if not path:
+        return []
+
+    try:
+        components = json.loads(Path(path).read_text()).get("components") or []
+        for component in components:
+            if component.get("layer"):
+                Layer(component["layer"])
+    except (OSError, json.JSONDecodeError, AttributeError, ValueError) as e:
+        raise ValueError(f"Invalid service layout file {path}: {e}") from e
+    return components
+
+
+def _words(segment: str) -> list[str]:
+    """Lowercase words of a path segment, e.g. expenseBff -> [expense, bff]."""
+    return [w.lower() for w in re.findall(r"[A-Z]+(?![a-z])|[A-Z]?[a-z]+|\d+", segment)]
+
+
+def component_service(path: str, default: str) -> str:
+    """Service a component directory belongs to: its innermost path segment naming something other than a layer."""
+    for segment in reversed(PurePosixPath(path).parts):
+        if segment.lower() in CONTAINER_SEGMENTS:
+            continue
+        # Singular words, so expenses-api and expense-bff are one service
+        words = [re.sub(r"(?<=[^s])s$", "", w) for w in _words(segment) if w not in EDGE_WORDS | DATA_WORDS | APP_WORDS]
+        name = service_key("-".join(words))
+        if name:
+            return name
+    return service_key(default) or default.lower()
+
+
+def component_layer(path: str, language: str) -> Layer:
+    """Layer a component's path or language puts it in."""
+    words = {w for segment in PurePosixPath(path).parts for w in _words(segment)}
+    if language == "sql" or (words & DATA_WORDS and not words & EDGE_WORDS):
+        return Layer.DATA
+    if words & EDGE_WORDS:
+        return Layer.EDGE
+    return Layer.APP
+
+
+def find_components(paths: list[str], repository_name: str, layout: list[dict] | None = None) -> list[Component]:
+    """Components of a clone from the paths of its files.
+
+    Args:
+        paths: Relative file paths of the clone
+        repository_name: Name of the repository, the service of components whose path names none
+        layout: Pinned components from the layout file
+
+    Returns:
+        Components, innermost first, so the first one containing a file is the file's component
+    """
+    found: dict[str, str] = {}
+    present = set(paths)
+    sql = []
+    for path in paths:
+        pure = PurePosixPath(path)
+        directory = "" if str(pure.parent) == "." else str(pure.parent)
+        language = MANIFESTS.get(pure.name) or ("csharp" if pure.suffix == ".csproj" else None)
+        if language:
+            if language == "javascript" and str(PurePosixPath(directory, "tsconfig.json")) in present:
+                language = "typescript"
+            found.setdefault(directory, language)
+        elif pure.suffix == ".sql":
+            sql.append(directory)
+    # SQL next to a build manifest is that build's own queries
+    for directory in sql:
+        found.setdefault(directory, "sql")
+
+    components = {
+        directory: Component(directory, language, component_service(directory, repository_name), component_layer(directory, language))
+        for directory, language in found.items()
+    }
+    for pinned in layout or []:
+        directory = str(pinned.get("path", "")).strip("/")
+        existing = components.get(directory)
+        components[directory] = Component(
+            directory,
+            pinned.get("language") or (existing.language if existing else "unknown"),
+            pinned.get("service") or (existing.service if existing else component_service(directory, repository_name)),
+            Layer(pinned["layer"]) if pinned.get("layer") else existing.layer if existing else component_layer(directory, ""),
+            pinned=True,
+        )
+    return sorted(components.values(), key=lambda c: (-len(PurePosixPath(c.path).parts) if c.path else 0, c.path))
+
+
+def component_of(file_path: str | None, components: list[Component]) -> Component | None:
+    """Innermost component containing a file."""
+    if not file_path:
+        return None
+    for component in components:
+        if not component.path or file_path == component.path or file_path.startswith(component.path + "/"):
+            return component
+    return None
+
+
+def policy_layer(policy: Policy, component: Component | None) -> Layer:
+    """Layer of a policy: data for database policies, otherwise its component's, else by source type."""
+    if policy.source_type == SourceType.DATABASE:
+        return Layer.DATA
+    if component is not None:
+        return component.layer
+    return Layer.EDGE if policy.source_type == SourceType.FRONTEND else Layer.APP
+
+
+def clone_files(root: str) -> Iterator[str]:
+    """Sandbox pass yielding the relative paths of a clone's files, skipping vendored and build directories."""
+    clone = Path(root)
+    for path in sorted(clone.rglob("*")):
+        relative = path.relative_to(clone)
+        if not SKIPPED_DIRECTORIES & set(relative.parts) and path.is_file():
+            yield relative.as_posix()
+
+
+def file_service_calls(root: str, paths: list[str]) -> Iterator[dict]:
+    """Sandbox pass yielding the outbound service calls of the given clone files."""
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    files = {}
+    for relative in paths:
+        path = Path(root) / relative
+        if is_service_call_source(relative) and path.stat().st_size <= max_bytes:
+            files[relative] = path.read_text(encoding="utf-8", errors="ignore")
+    yield from (asdict(c) for c in extract_service_calls(files))
+
+
+class ServiceViewService:
+    """Unifies the policies mined from every language of a monorepo service into layered views."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        query = self.db.query(Repository).filter(Repository.id == repository_id)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        repository = query.first()
+        if not repository:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    @staticmethod
+    def _files(root: Path) -> list[str]:
+        """Relative paths of a clone's files, skipping vendored and build directories, listed in the scan sandbox."""
+        if not root.is_dir():
+            return []
+        return list(run_pass(root, clone_files))
+
+    def _calls(self, root: Path, paths: list[str]) -> list[ServiceCall]:
+        """Outbound service calls of the given clone files, read in the scan sandbox."""
+        return [
+            ServiceCall(**{**c, "protocol": CallProtocol(c["protocol"]), "credential": CallCredential(c["credential"])})
+            for c in run_pass(root, file_service_calls, paths=paths)
+        ]
+
+    def services(self, repository_id: int, service: str | None = None) -> dict:
+        """Services of a repository with their components and their policies by layer.
+
+        Args:
+            repository_id: Repository ID
+            service: Keep only this service
+
+        Returns:
+            One entry per service with its languages, components, layered policies, and edge-to-app links
+
+        Raises:
+            ValueError: If the repository or the requested service does not exist, or the layout file is invalid
+        """
+        repository = self.get_repository(repository_id)
+        root = self.clone_dir / str(repository.id)
+        paths = self._files(root)
+        components = find_components(paths, repository.name, load_service_layout())
+        policies = self.db.query(Policy).filter(Policy.repository_id == repository.id).all()
+
+        views: dict[str, dict] = {}
+
+        def view(name: str) -> dict:
+            return views.setdefault(
+                name,
+                {
+                    "name": name,
+                    "languages": [],
+                    "components": [],
+                    "layers": {layer.value: {"policies": [], "resources": [], "roles": []} for layer in Layer},
+                    "links": [],
+                },
+            )
+
+        for component in sorted(components, key=lambda c: c.path):
+            entry = view(component.service)
+            entry["components"].append(asdict(component) | {"layer": component.layer.value, "policies": 0})
+            if component.language not in entry["languages"]:
+                entry["languages"].append(component.language)
+
+        default = component_service("", repository.name)
+        members: dict[tuple[str, Layer], list[Policy]] = {}
+        for policy in policies:
+            evidence = policy.evidence[0] if policy.evidence else None
+            component = component_of(evidence.file_path if evidence else None, components)
+            layer = policy_layer(policy, component)
+            name = component.service if component else default
+            members.setdefault((name, layer), []).append(policy)
+            entry = view(name)
+            if component:
+                next(c for c in entry["components"] if c["path"] == component.path)["policies"] += 1
+            entry["layers"][layer.value]["policies"].append(
+                {
+                    "policy_id": policy.id,
+                    "subject": policy.subject,
+                    "resource": policy.resource,
+                    "action": policy.action,
+                    "conditions": policy.conditions,
+                    "source_type": policy.source_type.value if policy.source_type else None,
+                    "component": component.path if component else None,
+                    "file_path": evidence.file_path if evidence else None,
+                    "line_start": evidence.line_start if evidence else None,
+                }
+            )
+
+        rules = {key: EndpointMappingService.map_policies(group) for key, group in members.items()}
+        for (name, layer), group in members.items():
+            layered = views[name]["layers"][layer.value]
+            if layer == Layer.DATA:
+                layered["resources"] = sorted({p.resource for p in group})
+            else:
+                layered["resources"] = [r.key for r in rules[(name, layer)]]
+            layered["roles"] = EndpointMappingService.collect_roles(rules[(name, layer)])
+
+        # Calls from each edge component into the app endpoints of its own service
+        for component in components:
+            app_rules = rules.get((component.service, Layer.APP), [])
+            if component.layer != Layer.EDGE or not app_rules:
+                continue
+            inside = [p for p in paths if component_of(p, components) is component]
+            for call in self._calls(root, inside):
+                rule = match_rule(call, app_rules)
+                if rule is None:
+                    continue
+                views[component.service]["links"].append(
+                    {
+                        "component": component.path,
+                        "file_path": call.file_path,
+                        "line_start": call.line_start,
+                        "call": f"{call.method} {call.path}",
+                        "app_endpoint": rule.key,
+                        "app_roles": list(rule.roles),
+                        "app_policy_ids": list(rule.policy_ids),
+                        "credential": call.credential.value,
+                        "user_context": user_context(call, rule).value,
+                    }
+                )
+
+        if service is not None and service not in views:
+            raise ValueError(f"Service {service} not found in repository {repository_id}")
+        selected = [views[service]] if service is not None else sorted(views.values(), key=lambda v: v["name"])
+        by_layer = Counter({layer.value: 0 for layer in Layer})
+        for entry in selected:
+            for layer, layered in entry["layers"].items():
+                by_layer[layer] += len(layered["policies"])
+        logger.info(
+            "service_views_built",
+            repository_id=repository.id,
+            services=len(selected),
+            components=len(components),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "services": selected,
+            "summary": {
+                "services": len(selected),
+                "multi_language_services": sum(len(v["languages"]) > 1 for v in selected),
+                "policies": sum(by_layer.values()),
+                "by_layer": dict(by_layer),
+            },
+        }
@@ -0,0 +1,669 @@
+"""Service for streaming findings, auth regressions, and audit events to a SIEM.
+
+A SOC correlating a policy regression with runtime alerts needs the
+miner's events next to the rest of its telemetry, in a format its parsers
+already know. Each SIEM connector streams three kinds of events:
+
+- findings (finding_export_service), once when first detected and once when
+  scans no longer detect them;
+- auth regressions (regression_alert_service), when detected;
+- miner audit events (audit_service): logins, approvals, provisioning,
+  scans. Prompt and response text of AI calls is never sent, and event
+  names and messages are redacted like every other egress.
+
+Events are rendered as Elastic Common Schema documents or as CEF lines and
+delivered to Splunk's HTTP Event Collector, Elasticsearch's Bulk API (into
+an index or data stream), or Microsoft Sentinel through the Log Analytics
+Data Collector API, where ECS field names are flattened with underscores.
+
+Connectors are streamed on a schedule by the ``stream_siem_events`` celery
+beat task, or on demand through the API. Delivery is at least once: a
+stream that fails part way resends its events next time, and every event
+carries a stable ``event.id`` (CEF ``externalId``) to deduplicate on.
+"""
+
+import base64
+import hashlib
+import hmac
+import json
+from dataclasses import dataclass, field, replace
+from datetime import UTC, datetime, timedelta
+from email.utils import format_datetime
+from urllib.parse import urlparse
+
+import httpx
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.air_gap import ensure_host_allowed
+from app.core.config import settings
+from app.models.audit_log import AuditEventType, AuditLog
+from app.models.incident_alert import AuthRegression
+from app.models.repository import Repository
+from app.models.siem_connector import SiemConnector, SiemDestination, SiemFindingState, SiemFormat
+from app.services.finding_export_service import TOOL_NAME, FindingExportService
+from app.services.redaction_service import redact_for_egress
+
+logger = structlog.get_logger(__name__)
+
+ECS_VERSION = "8.11.0"
+PRODUCT_VERSION = "0.1.0"
+
+# Events per request to the SIEM
+BATCH_SIZE = 500
+
+# Audit events read per stream; the rest follow on the next one
+MAX_AUDIT_EVENTS = 5000
+
+ELASTIC_DEFAULT_INDEX = "logs-policy_miner-default"
+SENTINEL_DEFAULT_LOG_TYPE = "PolicyMiner"
+SENTINEL_API_VERSION = "2016-04-01"
+
+# ECS event.severity, as Elastic Security's risk score ranges read it, and CEF severity (0-10)
+ECS_SEVERITY = {"critical": 99, "high": 73, "medium": 47, "low": 21, "info": 0}
+CEF_SEVERITY = {"critical": 10, "high": 8, "medium": 5, "low": 3, "info": 1}
+
+# ECS event.category and event.type of each miner audit event
+AUDIT_CATEGORIES = {
+    AuditEventType.USER_LOGIN: ("authentication", "start"),
+    AuditEventType.POLICY_APPROVAL: ("configuration", "allowed"),
+    AuditEventType.POLICY_REJECTION: ("configuration", "denied"),
+    AuditEventType.POLICY_UPDATE: ("configuration", "change"),
+    AuditEventType.POLICY_DELETE: ("configuration", "deletion"),
+    AuditEventType.PROVISIONING: ("iam", "change"),
+    AuditEventType.CONFLICT_RESOLUTION: ("configuration", "change"),
+    AuditEventType.SCAN_START: ("configuration", "start"),
+    AuditEventType.SCAN_COMPLETE: ("configuration", "end"),
+    AuditEventType.REPOSITORY_CREATE: ("configuration", "creation"),
+    AuditEventType.REPOSITORY_UPDATE: ("configuration", "change"),
+    AuditEventType.REPOSITORY_DELETE: ("configuration", "deletion"),
+}
+
+
+@dataclass
+class SiemEvent:
+    """A finding, regression, or audit event, before it is rendered for a SIEM."""
+
+    event_id: str
+    timestamp: datetime
+    dataset: str  # finding, regression, or audit
+    action: str  # e.g. finding-detected, auth-regression-detected, policy_approval
+    kind: str  # ECS event.kind: alert or event
+    category: str  # ECS event.category
+    event_type: str  # ECS event.type
+    severity: str  # critical, high, medium, low, or info
+    signature: str  # Rule ID or audit event type; the CEF signature ID
+    name: str
+    message: str
+    tenant_id: str | None = None
+    repository_id: int | None = None
+    repository_name: str | None = None
+    file_path: str | None = None
+    line: int | None = None
+    method: str | None = None
+    path: str | None = None
+    user_email: str | None = None
+    policy_id: int | None = None
+    finding_key: str | None = None
+    cwe: list[str] = field(default_factory=list)
+    owasp_api: list[str] = field(default_factory=list)
+    labels: dict[str, str] = field(default_factory=dict)
+    record_id: int | None = None  # AuthRegression or AuditLog ID, for the connector's cursors
+
+
+def _prune(value):
+    """A document without empty fields."""
+    if isinstance(value, dict):
+        pruned = {k: _prune(v) for k, v in value.items()}
+        return {k: v for k, v in pruned.items() if v not in (None, "", [], {})}
+    return value
+
+
+def to_ecs(event: SiemEvent) -> dict:
+    """Elastic Common Schema document of an event."""
+    return _prune(
+        {
+            "@timestamp": event.timestamp.isoformat(),
+            "ecs": {"version": ECS_VERSION},
+            "message": event.message,
+            "event": {
+                "id": event.event_id,
+                "kind": event.kind,
+                "category": [event.category],
+                "type": [event.event_type],
+                "action": event.action,
+                "dataset": f"policy_miner.{event.dataset}",
+                "module": "policy_miner",
+                "provider": "policy-miner",
+                "severity": ECS_SEVERITY[event.severity],
+            },
+            "observer": {"vendor": TOOL_NAME, "product": TOOL_NAME, "version": PRODUCT_VERSION},
+            "rule": {"id": event.signature, "name": event.name} if event.dataset != "audit" else None,
+            "vulnerability": {
+                "id": event.cwe,
+                "classification": "CWE" if event.cwe else None,
+                "category": event.owasp_api,
+                "severity": event.severity,
+            }
+            if event.dataset != "audit"
+            else None,
+            "file": {"path": event.file_path},
+            "url": {"path": event.path},
+            "http": {"request": {"method": event.method}},
+            "user": {"email": event.user_email},
+            "organization": {"id": event.tenant_id},
+            "labels": event.labels,
+            "policy_miner": {
+                "repository": {"id": event.repository_id, "name": event.repository_name},
+                "line": event.line,
+                "policy_id": event.policy_id,
+                "finding_key": event.finding_key,
+            },
+        }
+    )
+
+
+def _cef_header(value) -> str:
+    """CEF header field with pipes and backslashes escaped."""
+    return str(value).replace("\\", "\\\\").replace("|", "\\|")
+
+
+def _cef_value(value) -> str:
+    """CEF extension value with backslashes, equals signs, and line breaks escaped."""
+    text = str(value).replace("\\", "\\\\").replace("=", "\\=")
+    return text.replace("\r\n", "\\n").replace("\n", "\\n").replace("\r", "\\r")
+
+
+def to_cef(event: SiemEvent) -> str:
+    """CEF line of an event."""
+    extensions = {
+        "rt": int(event.timestamp.timestamp() * 1000),
+        "act": event.action,
+        "cat": event.dataset,
+        "msg": event.message,
+        "externalId": event.event_id,
+        "suser": event.user_email,
+        "filePath": event.file_path,
+        "request": event.path,
+        "requestMethod": event.method,
+    }
+    custom = [
+        ("cs1", "repository", event.repository_name),
+        ("cs2", "cwe", ",".join(event.cwe)),
+        ("cs3", "owaspApi", ",".join(event.owasp_api)),
+        ("cs4", "tenant", event.tenant_id),
+        ("cs5", "findingKey", event.finding_key),
+        ("cn1", "repositoryId", event.repository_id),
+        ("cn2", "line", event.line),
+        ("cn3", "policyId", event.policy_id),
+    ]
+    for key, label, value in custom:
+        if value not in (None, ""):
+            extensions[f"{key}Label"] = label
+            extensions[key] = value
+    header = "|".join(
+        _cef_header(v)
+        for v in ("CEF:0", TOOL_NAME, TOOL_NAME, PRODUCT_VERSION, event.signature, event.name, CEF_SEVERITY[event.severity])
+    )
+    extension = " ".join(f"{k}={_cef_value(v)}" for k, v in extensions.items() if v not in (None, ""))
+    return f"{header}|{extension}"
+
+
+def flatten(document: dict, prefix: str = "") -> dict:
+    """Document with nested fields joined by underscores, as Log Analytics column names must be."""
+    flat = {}
+    for key, value in document.items():
+        name = f"{prefix}{key.lstrip('@')}"
+        if isinstance(value, dict):
+            flat.update(flatten(value, f"{name}_"))
+        else:
+            flat[name] = value
+    return flat
+
+
+class SiemClient:
+    """Delivers one connector's events."""
+
+    def __init__(self, connector: SiemConnector, client: httpx.Client):
+        """Initialize client."""
+        self.connector = connector
+        self.client = client
+        self.base = (connector.base_url or "").rstrip("/")
+
+    @property
+    def host(self) -> str | None:
+        """Host events are sent to."""
+        return urlparse(self.base).hostname
+
+    def render(self, event: SiemEvent) -> dict | str:
+        """Event in the connector's format, its free text redacted for egress."""
+        event = replace(
+            event, name=redact_for_egress(event.name, "siem"), message=redact_for_egress(event.message, "siem")
+        )
+        return to_cef(event) if self.connector.event_format == SiemFormat.CEF else to_ecs(event)
+
+    def send(self, events: list[SiemEvent]) -> None:
+        """Deliver a batch of events.
+
+        Raises:
+            httpx.HTTPError: If the request fails
+            ValueError: If the SIEM rejects events
+        """
+        raise NotImplementedError
+
+
+class SplunkClient(SiemClient):
+    """Splunk HTTP Event Collector; the secret is a HEC token."""
+
+    def send(self, events: list[SiemEvent]) -> None:
+        """Post events to the collector, one JSON object each."""
+        sourcetype = f"policy_miner:{SiemFormat(self.connector.event_format).value}"
+        lines = []
+        for event in events:
+            entry = {
+                "time": event.timestamp.timestamp(),
+                "source": "policy-miner",
+                "sourcetype": sourcetype,
+                "event": self.render(event),
+            }
+            if self.connector.index:
+                entry["index"] = self.connector.index
+            lines.append(json.dumps(entry))
+        response = self.client.post(
+            f"{self.base}/services/collector/event",
+            content="\n".join(lines),
+            headers={"Authorization": f"Splunk {self.connector.secret}"},
+            timeout=30.0,
+        )
+        response.raise_for_status()
+
+
+class ElasticClient(SiemClient):
+    """Elasticsearch Bulk API; the secret is an API key."""
+
+    def send(self, events: list[SiemEvent]) -> None:
+        """Create one document per event; CEF lines are kept as the message."""
+        action = json.dumps({"create": {"_index": self.connector.index or ELASTIC_DEFAULT_INDEX}})
+        lines = []
+        for event in events:
+            rendered = self.render(event)
+            if isinstance(rendered, str):
+                rendered = {"@timestamp": event.timestamp.isoformat(), "message": rendered}
+            lines += [action, json.dumps(rendered)]
+        response = self.client.post(
+            f"{self.base}/_bulk",
+            content="\n".join(lines) + "\n",
+            headers={"Authorization": f"ApiKey {self.connector.secret}", "Content-Type": "application/x-ndjson"},
+            timeout=30.0,
+        )
+        response.raise_for_status()
+        result = response.json()
+        if result.get("errors"):
+            failed = [i["create"] for i in result.get("items", []) if i.get("create", {}).get("error")]
+            reason = failed[0]["error"].get("reason") if failed else "unknown error"
+            raise ValueError(f"Elasticsearch rejected {len(failed)} events: {reason}")
+
+
+class SentinelClient(SiemClient):
+    """Microsoft Sentinel through the Log Analytics Data Collector API; the secret is the workspace shared key."""
+
+    def __init__(self, connector: SiemConnector, client: httpx.Client):
+        """Initialize client; the workspace's ingestion endpoint unless a base URL overrides it."""
+        super().__init__(connector, client)
+        if not self.base:
+            self.base = f"https://{connector.workspace_id}.ods.opinsights.azure.com"
+
+    def signature(self, body: bytes, date: str) -> str:
+        """SharedKey authorization of a request body."""
+        signed = f"POST\n{len(body)}\napplication/json\nx-ms-date:{date}\n/api/logs"
+        digest = hmac.new(base64.b64decode(self.connector.secret), signed.encode(), hashlib.sha256).digest()
+        return f"SharedKey {self.connector.workspace_id}:{base64.b64encode(digest).decode()}"
+
+    def send(self, events: list[SiemEvent]) -> None:
+        """Post events as custom log records, timed by their timestamp field."""
+        records = []
+        for event in events:
+            rendered = self.render(event)
+            if isinstance(rendered, str):
+                records.append({"timestamp": event.timestamp.isoformat(), "message": rendered})
+            else:
+                records.append(flatten(rendered))
+        body = json.dumps(records).encode()
+        date = format_datetime(datetime.now(UTC), usegmt=True)
+        response = self.client.post(
+            f"{self.base}/api/logs?api-version={SENTINEL_API_VERSION}",
+            content=body,
+            headers={
+                "Authorization": self.signature(body, date),
+                "Content-Type": "application/json",
+                "Log-Type": self.connector.index or SENTINEL_DEFAULT_LOG_TYPE,
+                "x-ms-date": date,
+                "time-generated-field": "timestamp",
+            },
+            timeout=30.0,
+        )
+        response.raise_for_status()
+
+
+SIEM_CLIENTS: dict[SiemDestination, type[SiemClient]] = {
+    SiemDestination.SPLUNK: SplunkClient,
+    SiemDestination.ELASTIC: ElasticClient,
+    SiemDestination.SENTINEL: SentinelClient,
+}
+
+
+class SiemExportService:
+    """Manages SIEM connectors and streams events to them."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = clone_dir or settings.REPO_CLONE_DIR
+
+    def _query(self, model):
+        """Tenant-scoped query."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def create_connector(self, data: dict) -> SiemConnector:
+        """Register a Splunk, Elastic, or Sentinel connector.
+
+        Args:
+            data: Connector fields (name, destination, event_format, base_url,
+                workspace_id, secret, index, repository_id, sync_interval_minutes)
+
+        Returns:
+            Created connector
+
+        Raises:
+            ValueError: If the destination is missing the URL or workspace it needs
+        """
+        destination = SiemDestination(data["destination"])
+        if destination == SiemDestination.SENTINEL and not data.get("workspace_id"):
+            raise ValueError("Sentinel connectors need the Log Analytics workspace_id")
+        if destination != SiemDestination.SENTINEL and not data.get("base_url"):
+            raise ValueError(f"{destination.value} connectors need a base_url")
+        connector = SiemConnector(tenant_id=self.tenant_id, **data)
+        self.db.add(connector)
+        self.db.commit()
+        self.db.refresh(connector)
+        logger.info("siem_connector_created", connector_id=connector.id, destination=connector.destination)
+        return connector
+
+    def list_connectors(self) -> list[SiemConnector]:
+        """List the tenant's connectors."""
+        return self._query(SiemConnector).order_by(SiemConnector.id).all()
+
+    def get_connector(self, connector_id: int) -> SiemConnector:
+        """Get a connector.
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self._query(SiemConnector).filter(SiemConnector.id == connector_id).first()
+        if not connector:
+            raise ValueError(f"SIEM connector {connector_id} not found")
+        return connector
+
+    def delete_connector(self, connector_id: int) -> None:
+        """Delete a connector and its record of streamed findings.
+
+        Raises:
+            ValueError: If the connector does not exist
+        """
+        connector = self.get_connector(connector_id)
+        self.db.query(SiemFindingState).filter(SiemFindingState.connector_id == connector.id).delete(
+            synchronize_session=False
+        )
+        self.db.delete(connector)
+        self.db.commit()
+
+    def _repository_names(self) -> dict[int, str]:
+        """Names of the tenant's repositories by ID."""
+        return {r.id: r.name for r in self._query(Repository).all()}
+
+    def _states(self, connector_id: int) -> list[SiemFindingState]:
+        """Findings a connector has streamed as detected."""
+        return self.db.query(SiemFindingState).filter(SiemFindingState.connector_id == connector_id).all()
+
+    def _regressions(self, connector: SiemConnector) -> list[AuthRegression]:
+        """Auth regressions after the connector's cursor, oldest first."""
+        query = self._query(AuthRegression).filter(AuthRegression.id > connector.regression_cursor)
+        if connector.repository_id is not None:
+            query = query.filter(AuthRegression.repository_id == connector.repository_id)
+        return query.order_by(AuthRegression.id).all()
+
+    def _audit_logs(self, connector: SiemConnector) -> list[AuditLog]:
+        """Audit logs after the connector's cursor, oldest first."""
+        query = self._query(AuditLog).filter(AuditLog.id > connector.audit_log_cursor)
+        if connector.repository_id is not None:
+            query = query.filter(AuditLog.repository_id == connector.repository_id)
+        return query.order_by(AuditLog.id).limit(MAX_AUDIT_EVENTS).all()
+
+    def finding_events(
+        self, connector: SiemConnector, now: datetime
+    ) -> tuple[list[SiemEvent], dict[str, dict], list[SiemFindingState]]:
+        """Events of findings detected or resolved since the connector's last stream.
+
+        Returns:
+            Tuple of (events, findings newly detected by key, states of findings resolved)
+        """
+        exporter = FindingExportService(self.db, self.tenant_id, self.clone_dir)
+        detected = {f["finding_key"]: f for f in exporter.findings(repository_id=connector.repository_id)}
+        states = {s.finding_key: s for s in self._states(connector.id)}
+
+        events = []
+        new = {k: f for k, f in detected.items() if k not in states}
+        for key, finding in new.items():
+            events.append(
+                SiemEvent(
+                    event_id=f"{key}-detected",
+                    timestamp=now,
+                    dataset="finding",
+                    action="finding-detected",
+                    kind="alert",
+                    category="vulnerability",
+                    event_type="info",
+                    severity=finding["severity"],
+                    signature=finding["rule_id"],
+                    name=finding["title"],
+                    message=finding["description"] or finding["title"],
+                    tenant_id=self.tenant_id,
+                    repository_id=finding["repository_id"],
+                    repository_name=finding["repository_name"],
+                    file_path=finding["file_path"],
+                    line=finding["line_start"],
+                    finding_key=key,
+                    cwe=finding["cwe"],
+                    owasp_api=finding["owasp_api"],
+                )
+            )
+        resolved = [s for k, s in states.items() if k not in detected]
+        for state in resolved:
+            events.append(
+                SiemEvent(
+                    event_id=f"{state.finding_key}-resolved",
+                    timestamp=now,
+                    dataset="finding",
+                    action="finding-resolved",
+                    kind="event",
+                    category="vulnerability",
+                    event_type="end",
+                    severity=state.severity,
+                    signature=state.rule_id,
+                    name=state.title,
+                    message=f"{state.title} is no longer detected",
+                    tenant_id=self.tenant_id,
+                    repository_id=state.repository_id,
+                    file_path=state.file_path,
+                    finding_key=state.finding_key,
+                )
+            )
+        return events, new, resolved
+
+    def regression_events(self, connector: SiemConnector, names: dict[int, str]) -> list[SiemEvent]:
+        """Events of auth regressions detected after the connector's cursor."""
+        events = []
+        for regression in self._regressions(connector):
+            method, _, path = regression.endpoint.partition(" ")
+            after = regression.evidence["after"]
+            events.append(
+                SiemEvent(
+                    event_id=f"auth-regression-{regression.id}",
+                    timestamp=regression.detected_at,
+                    dataset="regression",
+                    action="auth-regression-detected",
+                    kind="alert",
+                    category="configuration",
+                    event_type="change",
+                    severity="critical",
+                    signature="auth_regression",
+                    name="Authentication removed from internet-facing endpoint",
+                    message=regression.description,
+                    tenant_id=self.tenant_id,
+                    repository_id=regression.repository_id,
+                    repository_name=names.get(regression.repository_id),
+                    file_path=after["file_path"],
+                    line=after["line"],
+                    method=method,
+                    path=path,
+                    cwe=["CWE-306"],
+                    owasp_api=["API2:2023"],
+                    labels={"previous_protection": regression.previous_protection, "exposure": regression.exposure},
+                    record_id=regression.id,
+                )
+            )
+        return events
+
+    def audit_events(self, connector: SiemConnector, names: dict[int, str]) -> list[SiemEvent]:
+        """Events of miner audit logs written after the connector's cursor."""
+        events = []
+        for log in self._audit_logs(connector):
+            event_type = AuditEventType(log.event_type)
+            category, kind = AUDIT_CATEGORIES.get(event_type, ("configuration", "info"))
+            labels = {k: v for k, v in (("ai_model", log.ai_model), ("ai_provider", log.ai_provider)) if v}
+            events.append(
+                SiemEvent(
+                    event_id=f"audit-{log.id}",
+                    timestamp=log.created_at,
+                    dataset="audit",
+                    action=event_type.value,
+                    kind="event",
+                    category=category,
+                    event_type=kind,
+                    severity="info",
+                    signature=event_type.value,
+                    name=event_type.value.replace("_", " ").capitalize(),
+                    message=log.event_description,
+                    tenant_id=log.tenant_id,
+                    repository_id=log.repository_id,
+                    repository_name=names.get(log.repository_id),
+                    user_email=log.user_email,
+                    policy_id=log.policy_id,
+                    labels=labels,
+                    record_id=log.id,
+                )
+            )
+        return events
+
+    def stream_connector(self, connector_id: int, client: httpx.Client | None = None) -> dict:
+        """Send a connector the events since its last stream.
+
+        Args:
+            connector_id: Connector ID
+            client: HTTP client (a new one is created if omitted)
+
+        Returns:
+            Counts of events sent by kind
+
+        Raises:
+            ValueError: If the connector does not exist or the SIEM request fails
+            AirGapViolation: If the SIEM is outside the enclave in air-gapped mode
+        """
+        connector = self.get_connector(connector_id)
+        client_class = SIEM_CLIENTS[SiemDestination(connector.destination)]
+        now = datetime.now(UTC)
+        names = self._repository_names()
+
+        findings, new, resolved = self.finding_events(connector, now)
+        regressions = self.regression_events(connector, names)
+        audits = self.audit_events(connector, names)
+        events = findings + regressions + audits
+
+        def send(siem: SiemClient) -> None:
+            ensure_host_allowed(siem.host, "siem_export")
+            for start in range(0, len(events), BATCH_SIZE):
+                siem.send(events[start : start + BATCH_SIZE])
+
+        try:
+            if client is not None:
+                send(client_class(connector, client))
+            else:
+                with httpx.Client() as new_client:
+                    send(client_class(connector, new_client))
+        except (httpx.HTTPError, ValueError) as e:
+            connector.last_error = str(e)
+            self.db.commit()
+            logger.error("siem_stream_failed", connector_id=connector.id, error=str(e))
+            raise ValueError(f"Failed to stream events to {connector.name}: {e}") from e
+
+        for key, finding in new.items():
+            self.db.add(
+                SiemFindingState(
+                    tenant_id=self.tenant_id,
+                    connector_id=connector.id,
+                    finding_key=key,
+                    rule_id=finding["rule_id"],
+                    repository_id=finding["repository_id"],
+                    title=finding["title"][:500],
+                    severity=finding["severity"],
+                    file_path=finding["file_path"],
+                    streamed_at=now,
+                )
+            )
+        for state in resolved:
+            self.db.delete(state)
+        if regressions:
+            connector.regression_cursor = regressions[-1].record_id
+        if audits:
+            connector.audit_log_cursor = audits[-1].record_id
+        connector.last_streamed_at = now
+        connector.last_error = None
+        self.db.commit()
+
+        result = {
+            "connector_id": connector.id,
+            "findings_detected": len(new),
+            "findings_resolved": len(resolved),
+            "regressions": len(regressions),
+            "audit_events": len(audits),
+            "events_sent": len(events),
+        }
+        logger.info("siem_events_streamed", **result)
+        return result
+
+    def stream_due_connectors(self) -> list[dict]:
+        """Stream every connector whose interval has elapsed.
+
+        Without a tenant this covers every tenant's connectors, each streamed
+        its own tenant's events. Failures are recorded on the connector and
+        do not stop other streams.
+
+        Returns:
+            Summaries of the connectors streamed successfully
+        """
+        now = datetime.now(UTC)
+        results = []
+        for connector in self._query(SiemConnector).all():
+            interval = timedelta(minutes=connector.sync_interval_minutes or settings.SIEM_STREAM_INTERVAL_MINUTES)
+            if connector.last_streamed_at and connector.last_streamed_at + interval > now:
+                continue
+            try:
+                service = SiemExportService(self.db, connector.tenant_id, self.clone_dir)
+                results.append(service.stream_connector(connector.id))
+            except Exception as e:
+                logger.error("siem_scheduled_stream_failed", connector_id=connector.id, error=str(e))
+        return results
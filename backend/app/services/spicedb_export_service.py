@@ -0,0 +1,322 @@
+"""Service for exporting mined role and ownership policies as a SpiceDB schema.
+
+Relationship-based systems (AuthZed SpiceDB and other Zanzibar
+implementations) decide access by walking a graph of relationships, so
+adopting one starts with a schema and the tuples that bootstrap it. This
+export derives both from what the code enforces, with the repository as the
+schema's prefix:
+
+- ``user`` is every caller;
+- ``platform`` is a singleton (``platform:main``) holding global roles: one
+  relation per mined role, plus ``everyone`` (``user:*``) for policies that
+  only need a signed-in caller;
+- each domain object (``expense``) has a ``platform`` relation to it, one
+  relation per ownership attribute the policies check (``owner_id`` becomes
+  ``owner``), and one permission per mined action.
+
+A permission is the union of its policies, each the intersection of its
+role check and ownership clauses:
+
+    definition expense_api/expense {
+        relation platform: expense_api/platform
+        relation owner: expense_api/user
+
+        // Policy 7: MANAGER may approve Expense (POST /api/expenses/{}/approve)
+        // Policy 9: authenticated user who owns the expense may read Expense (GET /api/expenses/{})
+        permission approve = platform->manager
+        permission read = owner
+    }
+
+Attribute conditions (amount thresholds, department checks) have no
+relationship equivalent. As with the Rego and Cedar exports, a policy with a
+clause that cannot be translated is left out of its permission, so the
+permission grants less rather than more, and the clause is listed for review.
+
+The bootstrap relationships grant ``everyone`` and the roles users hold
+through ingested role assignments, including those expanded from IdP groups.
+Applications still write each object's ``platform`` and owner relationships
+as objects are created.
+"""
+
+import re
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus, SourceType
+from app.models.repository import Repository
+from app.services.bundle_publish_service import build_archive
+from app.services.condition_evaluation_service import ConditionEvaluationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.role_impact_service import RoleImpactService
+from app.services.stable_identity_service import normalize_path
+
+logger = structlog.get_logger(__name__)
+
+# Object definitions every export declares; domain objects may not reuse their names
+BUILTIN_TYPES = ("user", "platform")
+
+PLATFORM_ID = "main"
+EVERYONE = "everyone"
+
+# Characters SpiceDB allows in object IDs; others are written as =XX
+OBJECT_ID_CHARACTERS = re.compile(r"[A-Za-z0-9/_|\-+]")
+
+
+def spicedb_name(text: str | None, prefix: str) -> str:
+    """SpiceDB prefix, definition, relation, or permission name.
+
+    Args:
+        text: e.g. "ExpenseReport", "billing-api", "MANAGER"
+        prefix: Used alone when the text has no usable characters, and
+            prepended when the name would be too short or start with a digit
+
+    Returns:
+        e.g. "expense_report", "billing_api", "manager"
+    """
+    snake = re.sub(r"([a-z0-9])([A-Z])", r"\1_\2", text or "")
+    name = re.sub(r"[^a-z0-9]+", "_", snake.lower()).strip("_")
+    if not name:
+        return prefix
+    if name[0].isdigit() or len(name) < 3:
+        name = f"{prefix}_{name}"
+    return name[:64].rstrip("_")
+
+
+def object_id(value: str) -> str:
+    """SpiceDB object ID of an identifier, with disallowed characters hex-escaped.
+
+    e.g. alice@acme.io is alice=40acme=2Eio.
+    """
+    return "".join(
+        c if OBJECT_ID_CHARACTERS.match(c) else "".join(f"={b:02X}" for b in c.encode()) for c in value
+    )
+
+
+def ownership_relation(attribute: str | None) -> str:
+    """Relation standing in for an ownership attribute, e.g. owner_id is owner."""
+    name = spicedb_name((attribute or "owner_id").split(".")[-1], "owner").removesuffix("_id")
+    return f"{name}_relation" if len(name) < 3 or name == "platform" else name
+
+
+def union(terms: list[str]) -> str:
+    """Union of terms, parenthesized when it joins several."""
+    return terms[0] if len(terms) == 1 else "(" + " + ".join(terms) + ")"
+
+
+def zed_file(schema: str, relationships: list[str]) -> str:
+    """Validation file with the schema and relationships, as ``zed import`` and the playground load it."""
+
+    def block(text: str) -> str:
+        return "\n".join(f"  {line}" if line else "" for line in text.splitlines())
+
+    return f"schema: |-\n{block(schema)}\nrelationships: |-\n{block(chr(10).join(relationships))}\n"
+
+
+class SpiceDbExportService:
+    """Exports a repository's mined role and ownership policies as a SpiceDB schema and relationships."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    @staticmethod
+    def policy_expression(policy: Policy, roles: list[str]) -> tuple[str, set[str], list[str]]:
+        """Permission expression granting what one policy grants.
+
+        Args:
+            policy: Mined policy
+            roles: Relation names of the roles the subject names
+
+        Returns:
+            Tuple of (expression, ownership relations it reads, clauses left untranslated)
+        """
+        terms, relations, untranslated = [], set(), []
+        for clause in ConditionEvaluationService.parse(policy.conditions):
+            if clause.kind in ("ownership", "owner_of_resource"):
+                relation = ownership_relation(clause.attribute)
+                relations.add(relation)
+                if clause.required_role:
+                    terms.append(union([relation, f"platform->{spicedb_name(clause.required_role, 'role')}"]))
+                else:
+                    terms.append(relation)
+            else:
+                untranslated.append(" ".join(clause.raw.split()))
+
+        if roles:
+            terms.insert(0, union([f"platform->{r}" for r in roles]))
+        elif not terms:
+            # Relationship checks always name a user, so public and signed-in policies both grant everyone
+            terms.append(f"platform->{EVERYONE}")
+        return " & ".join(terms), relations, untranslated
+
+    def export(self, repository_id: int, min_confidence: float | None = None, include_pending: bool = False) -> dict:
+        """SpiceDB schema and bootstrap relationships of a repository's mined policies.
+
+        Args:
+            repository_id: Repository ID
+            min_confidence: Leave out policies scored below this confidence (0-100)
+            include_pending: Also export policies that have not been approved
+
+        Returns:
+            The schema, one entry per permission, the relationships, and a summary
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        query = self._query(Policy).filter(
+            Policy.repository_id == repository.id, Policy.source_type != SourceType.DATABASE
+        )
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        candidates = query.order_by(Policy.id).all()
+        policies = [
+            p for p in candidates
+            if min_confidence is None or (p.confidence_score is not None and p.confidence_score >= min_confidence)
+        ]
+
+        prefix = spicedb_name(repository.name, "repository")
+        # Resource type -> ownership relations, and -> permission -> (policy comments, expressions, policy IDs)
+        ownership: dict[str, set[str]] = {}
+        permissions: dict[str, dict[str, dict]] = {}
+        roles: dict[str, str] = {}  # Relation name -> mined role
+        everyone = False
+        untranslated = []
+        for policy in policies:
+            rule = EndpointMappingService.map_policy(policy)
+            endpoint = f"{rule.method} {normalize_path(rule.path)}"
+            resource_type = spicedb_name(policy.resource, "resource")
+            if resource_type in BUILTIN_TYPES:
+                resource_type = f"{resource_type}_object"
+            permission_name = spicedb_name(policy.action or rule.method, "action")
+            permission = permissions.setdefault(resource_type, {}).setdefault(
+                permission_name, {"comments": [], "expressions": [], "policy_ids": []}
+            )
+            ownership.setdefault(resource_type, set())
+
+            subject_roles, _ = EndpointMappingService.parse_roles(policy.subject)
+            relations = [spicedb_name(r, "role") for r in subject_roles]
+            expression, owned_by, clauses = self.policy_expression(policy, relations)
+            grant = " ".join(f"{policy.subject} may {policy.action} {policy.resource}".split())
+            if clauses:
+                untranslated += [{"policy_id": policy.id, "endpoint": endpoint, "clause": c} for c in clauses]
+                permission["comments"].append(f"// Policy {policy.id} not translated, review: {grant} ({endpoint})")
+                continue
+
+            roles.update(zip(relations, (r.upper() for r in subject_roles)))
+            for clause in ConditionEvaluationService.parse(policy.conditions):
+                if clause.required_role:
+                    roles[spicedb_name(clause.required_role, "role")] = clause.required_role.upper()
+            everyone = everyone or f"platform->{EVERYONE}" in expression
+            ownership[resource_type] |= owned_by
+            permission["comments"].append(f"// Policy {policy.id}: {grant} ({endpoint})")
+            if expression not in permission["expressions"]:
+                permission["expressions"].append(expression)
+            permission["policy_ids"].append(policy.id)
+
+        lines = [f"definition {prefix}/user {{}}", "", f"definition {prefix}/platform {{"]
+        if everyone:
+            lines.append(f"    relation {EVERYONE}: {prefix}/user:*")
+        lines += [f"    relation {name}: {prefix}/user" for name in sorted(roles)]
+        lines.append("}")
+
+        exported_permissions = []
+        for resource_type in sorted(permissions):
+            lines += ["", f"definition {prefix}/{resource_type} {{", f"    relation platform: {prefix}/platform"]
+            lines += [f"    relation {name}: {prefix}/user" for name in sorted(ownership[resource_type])]
+            for name, permission in sorted(permissions[resource_type].items()):
+                if name in ("platform", *ownership[resource_type]):
+                    name = f"{name}_action"  # Relations and permissions share one namespace
+                expressions = permission["expressions"]
+                if len(expressions) > 1:
+                    expressions = [f"({e})" if "&" in e else e for e in expressions]
+                body = " + ".join(expressions) or "nil"
+                lines += ["", *(f"    {c}" for c in permission["comments"]), f"    permission {name} = {body}"]
+                exported_permissions.append(
+                    {
+                        "resource_type": resource_type,
+                        "permission": name,
+                        "expression": body,
+                        "policy_ids": permission["policy_ids"],
+                    }
+                )
+            lines.append("}")
+        schema = "\n".join(lines) + "\n"
+
+        application_ids = {p.application_id for p in policies} - {None}
+        assignments = RoleImpactService(self.db, self.tenant_id).load_assignments(
+            application_ids.pop() if len(application_ids) == 1 else None
+        )
+        platform = f"{prefix}/platform:{PLATFORM_ID}"
+        relationships = [f"{platform}#{EVERYONE}@{prefix}/user:*"] if everyone else []
+        held = set()
+        for user in sorted(assignments):
+            for name, role in sorted(roles.items()):
+                if role in assignments[user]:
+                    relationships.append(f"{platform}#{name}@{prefix}/user:{object_id(user)}")
+                    held.add(name)
+
+        logger.info(
+            "spicedb_exported",
+            repository_id=repository.id,
+            prefix=prefix,
+            policies=len(policies),
+            relationships=len(relationships),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repository.id,
+            "prefix": prefix,
+            "zed_schema": schema,
+            "permissions": exported_permissions,
+            "relationships": relationships,
+            "untranslated": untranslated,
+            "summary": {
+                "policies": len(policies),
+                "resource_types": len(permissions),
+                "permissions": len(exported_permissions),
+                "roles": len(roles),
+                "relationships": len(relationships),
+                "roles_without_members": sorted(roles[name] for name in set(roles) - held),
+                "below_min_confidence": len(candidates) - len(policies),
+                "untranslated_clauses": len(untranslated),
+            },
+        }
+
+    def archive(self, repository_id: int, min_confidence: float | None = None, include_pending: bool = False) -> bytes:
+        """Gzipped tarball of a repository's SpiceDB schema, relationships, and a zed import file.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        export = self.export(repository_id, min_confidence, include_pending)
+        relationships = export["relationships"]
+        return build_archive(
+            {
+                "schema.zed": export["zed_schema"].encode(),
+                "relationships.txt": ("\n".join(relationships) + "\n" if relationships else "").encode(),
+                "bootstrap.yaml": zed_file(export["zed_schema"], relationships).encode(),
+            }
+        )
@@ -0,0 +1,599 @@
+"""Extract Spring Security authorization from Java and Kotlin services.
+
+Spring declares access in two places. Method security puts @PreAuthorize
+(a SpEL expression), @Secured, or @RolesAllowed on controller handlers or
+service methods, directly or through meta-annotations such as an @IsAdmin
+annotation carrying @PreAuthorize. The HttpSecurity DSL maps URL patterns
+to access rules in the security filter chain, where the first matching rule
+wins, so a rule behind a broader earlier one never applies. Both become
+ConfigFinding records alongside the other JVM framework extractors, so
+mixed organizations get one inventory without LLM calls.
+"""
+
+import re
+from dataclasses import dataclass, field
+
+from app.services.config_policy_extractor import ConfigFinding
+from app.services.extractor_utils import line_of, line_span
+from app.services.jvm_route_extractor import (
+    ANONYMOUS,
+    AUTHENTICATED,
+    DENIED,
+    JVM_SUFFIXES,
+    MAX_SNIPPET_LINES,
+    Access,
+    Annotation,
+    Declaration,
+    _close_paren,
+    _join,
+    _strip_comments,
+    parse_declarations,
+)
+
+SPRING_MARKER = re.compile(r"\borg\.springframework\.|@(?:PreAuthorize|Secured|RolesAllowed)\b")
+
+
+class SpringRouteKind:
+    """Kinds of Spring Security findings."""
+
+    ROUTE = "spring_route"
+    METHOD_SECURITY = "spring_method_security"
+    HTTP_SECURITY = "spring_http_security"
+
+
+CONTROLLER_ANNOTATIONS = ("RestController", "Controller")
+MAPPING_VERBS = {"GetMapping": "GET", "PostMapping": "POST", "PutMapping": "PUT", "PatchMapping": "PATCH", "DeleteMapping": "DELETE"}
+METHOD_SECURITY = ("PreAuthorize", "Secured", "RolesAllowed", "PermitAll", "DenyAll")
+
+STRING = re.compile(r'"((?:\\.|[^"\\])*)"')
+CONSTANT = re.compile(r'(?:static\s+final\s+String|const\s+val)\s+(\w+)\s*(?::\s*String\s*)?=\s*"([^"]*)"')
+CLASS_NAME = re.compile(r"\b(?:class|interface|object)\s+(\w+)")
+META_ANNOTATION = re.compile(r"@interface\s+(\w+)|annotation\s+class\s+(\w+)")
+REQUEST_METHOD = re.compile(r"RequestMethod\.(\w+)")
+
+# SpEL security expressions
+SPEL_CALL = re.compile(
+    r"^(hasRole|hasAnyRole|hasAuthority|hasAnyAuthority|isAuthenticated|isFullyAuthenticated|isRememberMe|"
+    r"permitAll|denyAll|isAnonymous)\s*(?:\((.*)\))?$",
+    re.DOTALL,
+)
+SPEL_STRING = re.compile(r"'([^']*)'|\"([^\"]*)\"")
+SPEL_OPERATOR = re.compile(r"\s+(and|or)\s+|\s*(&&|\|\|)\s*", re.IGNORECASE)
+SPEL_DECISIONS = {
+    "isAuthenticated": AUTHENTICATED,
+    "isFullyAuthenticated": AUTHENTICATED,
+    "isRememberMe": AUTHENTICATED,
+    "permitAll": ANONYMOUS,
+    "isAnonymous": ANONYMOUS,
+    "denyAll": DENIED,
+}
+
+# HttpSecurity DSL: matchers followed by the access rule for them
+CHAIN_MARKER = re.compile(r"\b(?:HttpSecurity|ServerHttpSecurity|SecurityFilterChain|SecurityWebFilterChain)\b")
+CHAIN_TOKEN = re.compile(
+    r"\.\s*(antMatchers|mvcMatchers|requestMatchers|regexMatchers|pathMatchers|anyRequest|anyExchange)\s*\("
+    r"|\.\s*(hasRole|hasAnyRole|hasAuthority|hasAnyAuthority|authenticated|fullyAuthenticated|rememberMe|"
+    r"permitAll|denyAll|anonymous|access|hasIpAddress|not)\s*\("
+    r"|\bauthorize\s*\("
+)
+HTTP_METHOD = re.compile(r"HttpMethod\.(\w+)")
+DSL_DECISIONS = {
+    "authenticated": AUTHENTICATED,
+    "fullyAuthenticated": AUTHENTICATED,
+    "rememberMe": AUTHENTICATED,
+    "permitAll": ANONYMOUS,
+    "anonymous": ANONYMOUS,
+    "denyAll": DENIED,
+}
+KOTLIN_ACCESS = re.compile(r"^(\w+)\s*(?:\((.*)\))?$", re.DOTALL)
+
+
+@dataclass
+class _Requirement:
+    """What one SpEL clause or DSL rule requires."""
+
+    roles: list[str] = field(default_factory=list)
+    authorities: list[str] = field(default_factory=list)
+    decision: str | None = None
+    other: list[str] = field(default_factory=list)
+
+    @property
+    def pure_roles(self) -> bool:
+        """Whether the requirement is only a role check."""
+        return bool(self.roles) and not (self.authorities or self.decision or self.other)
+
+
+def _role(value: str) -> str:
+    """Role name without Spring's ROLE_ prefix."""
+    return value.removeprefix("ROLE_")
+
+
+def _grant(requirement: _Requirement, values: list[str], as_role: bool) -> None:
+    """Add roles, or authorities, treating ROLE_-prefixed authorities as roles."""
+    for value in values:
+        if as_role or value.startswith("ROLE_"):
+            requirement.roles.append(_role(value))
+        else:
+            requirement.authorities.append(value)
+
+
+def _split_spel(expression: str, operator: str) -> list[str]:
+    """Split a SpEL expression at top-level "and" or "or" operators."""
+    parts, depth, quote, start, i = [], 0, "", 0, 0
+    while i < len(expression):
+        c = expression[i]
+        if quote:
+            quote = "" if c == quote else quote
+        elif c in "'\"":
+            quote = c
+        elif c in "([":
+            depth += 1
+        elif c in ")]":
+            depth -= 1
+        elif depth == 0:
+            match = SPEL_OPERATOR.match(expression, i)
+            if match and (match.group(1) or match.group(2)).lower() in (operator, {"and": "&&", "or": "||"}[operator]):
+                parts.append(expression[start:i])
+                start = i = match.end()
+                continue
+        i += 1
+    parts.append(expression[start:])
+    return [p.strip() for p in parts if p.strip()]
+
+
+def _unwrap(expression: str) -> str:
+    """Drop parentheses enclosing a whole expression."""
+    while expression.startswith("(") and _close_paren(expression, 0) == len(expression):
+        expression = expression[1:-1].strip()
+    return expression
+
+
+def _spel_clause(clause: str) -> _Requirement:
+    """Requirement of one conjunct of a SpEL expression."""
+    clause = _unwrap(clause)
+    call = SPEL_CALL.match(clause)
+    requirement = _Requirement()
+    if call is None:
+        nested = _spel(clause) if len(_split_spel(clause, "or")) > 1 or len(_split_spel(clause, "and")) > 1 else None
+        if nested is not None and nested.pure_roles:
+            return nested
+        requirement.other.append(clause)
+        return requirement
+    name, args = call.group(1), call.group(2) or ""
+    values = [a or b for a, b in SPEL_STRING.findall(args)]
+    if name in SPEL_DECISIONS:
+        requirement.decision = SPEL_DECISIONS[name]
+    else:
+        _grant(requirement, values, as_role=name in ("hasRole", "hasAnyRole"))
+    return requirement
+
+
+def _spel(expression: str) -> _Requirement | None:
+    """Requirement of a SpEL expression when every alternative is a role check; None otherwise."""
+    alternatives = [_conjunction(d) for d in _split_spel(_unwrap(expression), "or")]
+    if all(a.pure_roles for a in alternatives):
+        return _Requirement(roles=list(dict.fromkeys(r for a in alternatives for r in a.roles)))
+    return None
+
+
+def _conjunction(expression: str) -> _Requirement:
+    """Requirement of clauses that must all hold."""
+    combined = _Requirement()
+    for clause in _split_spel(expression, "and"):
+        requirement = _spel_clause(clause)
+        if requirement.roles and combined.roles:
+            combined.other.append(f"also holds role {' or '.join(requirement.roles)}")
+        else:
+            combined.roles += requirement.roles
+        combined.authorities += requirement.authorities
+        combined.other += requirement.other
+        if requirement.decision == DENIED or combined.decision is None:
+            combined.decision = requirement.decision or combined.decision
+    if combined.decision == ANONYMOUS and (combined.roles or combined.authorities):
+        combined.decision = None
+    return combined
+
+
+def _access(requirement: _Requirement, source: str) -> Access:
+    """Access decision of a single requirement."""
+    if requirement.decision in (ANONYMOUS, DENIED) and not requirement.other:
+        return Access(requirement.decision, source=source)
+    conditions = [f"requires authority {' or '.join(requirement.authorities)}"] if requirement.authorities else []
+    conditions += [f"when {other}" for other in requirement.other]
+    subject = " or ".join(dict.fromkeys(requirement.roles)) or AUTHENTICATED
+    return Access(subject, conditions="; ".join(conditions) or None, source=source)
+
+
+def spel_access(expression: str, source: str) -> Access:
+    """Resolve a SpEL security expression, e.g. "hasRole('ADMIN') and #id == principal.id".
+
+    Alternatives that are all role checks become one subject; otherwise the
+    alternatives are listed as conditions.
+
+    Args:
+        expression: SpEL expression
+        source: Annotation or rule the expression came from, for descriptions
+
+    Returns:
+        Access decision
+    """
+    expression = " ".join(expression.split())
+    alternatives = _split_spel(_unwrap(expression), "or")
+    requirements = [_conjunction(a) for a in alternatives]
+    if len(requirements) == 1:
+        return _access(requirements[0], source)
+    if any(r.decision == ANONYMOUS and not r.other for r in requirements):
+        return Access(ANONYMOUS, source=source)
+    if all(r.pure_roles for r in requirements):
+        return Access(" or ".join(dict.fromkeys(role for r in requirements for role in r.roles)), source=source)
+    if all(r.authorities and not (r.roles or r.other) for r in requirements):
+        authorities = list(dict.fromkeys(a for r in requirements for a in r.authorities))
+        return Access(AUTHENTICATED, conditions=f"requires authority {' or '.join(authorities)}", source=source)
+    roles = [role for r in requirements for role in r.roles] if all(r.roles for r in requirements) else []
+    return Access(
+        " or ".join(dict.fromkeys(roles)) or AUTHENTICATED,
+        conditions=f"passes any of {', '.join(alternatives)}",
+        source=source,
+    )
+
+
+def _annotation_values(annotation: Annotation, constants: dict[str, str]) -> list[str]:
+    """String values of an annotation's arguments, resolving String constants."""
+    values = annotation.strings
+    for reference in re.findall(r"\b([A-Z]\w*(?:\.[A-Z_]\w*)?|[A-Z_][A-Z0-9_]+)\b", STRING.sub("", annotation.args)):
+        if reference in constants:
+            values.append(constants[reference])
+    return values
+
+
+def annotation_access(annotation: Annotation, constants: dict[str, str]) -> Access | None:
+    """Resolve a method security annotation to an access decision."""
+    name = annotation.short_name
+    source = f"@{name}{annotation.args}"
+    if name == "PermitAll":
+        return Access(ANONYMOUS, source=source)
+    if name == "DenyAll":
+        return Access(DENIED, source=source)
+    values = _annotation_values(annotation, constants)
+    if name == "PreAuthorize":
+        return spel_access(" ".join(values), source) if values else None
+    if name not in ("Secured", "RolesAllowed"):
+        return None
+    if "IS_AUTHENTICATED_ANONYMOUSLY" in values:
+        return Access(ANONYMOUS, source=source)
+    roles = [_role(v) for v in values if not v.startswith("IS_AUTHENTICATED_")]
+    return Access(" or ".join(dict.fromkeys(roles)) or AUTHENTICATED, source=source)
+
+
+@dataclass
+class SpringContext:
+    """Declarations shared across a service's files."""
+
+    constants: dict[str, str] = field(default_factory=dict)  # NAME and Class.NAME -> value
+    meta: dict[str, Annotation] = field(default_factory=dict)  # Meta-annotation name -> security annotation
+
+
+def build_context(files: dict[str, str]) -> SpringContext:
+    """Collect String constants and security meta-annotations from a service's sources."""
+    context = SpringContext()
+    for text in files.values():
+        code = _strip_comments(text)
+        for match in CONSTANT.finditer(code):
+            owner = None
+            for owner_match in CLASS_NAME.finditer(code, 0, match.start()):
+                owner = owner_match.group(1)
+            context.constants.setdefault(match.group(1), match.group(2))
+            if owner:
+                context.constants[f"{owner}.{match.group(1)}"] = match.group(2)
+        meta_names = {a or b for a, b in META_ANNOTATION.findall(code)}
+        if not meta_names:
+            continue
+        for declaration in parse_declarations(text):
+            security = declaration.find(*METHOD_SECURITY)
+            if declaration.name in meta_names and security is not None:
+                context.meta[declaration.name] = security
+    return context
+
+
+def _security(declaration: Declaration, context: SpringContext) -> Access | None:
+    """Access a declaration's own or meta-annotated security declares."""
+    for annotation in declaration.annotations:
+        if annotation.short_name in METHOD_SECURITY:
+            access = annotation_access(annotation, context.constants)
+        elif annotation.short_name in context.meta:
+            access = annotation_access(context.meta[annotation.short_name], context.constants)
+            if access is not None:
+                access.source = f"@{annotation.short_name} ({access.source})"
+        else:
+            continue
+        if access is not None:
+            return access
+    return None
+
+
+def _mapping_paths(annotation: Annotation | None) -> list[str]:
+    """Paths of a @RequestMapping-style annotation; [""] when it names none."""
+    if annotation is None:
+        return [""]
+    args = annotation.args
+    named = re.search(r"\b(?:value|path)\s*=\s*(\{[^}]*\}|\[[^\]]*\]|\"[^\"]*\")", args)
+    if named:
+        paths = STRING.findall(named.group(1))
+    else:
+        positional = re.match(r"\s*\(\s*(\{[^}]*\}|\[[^\]]*\]|\"[^\"]*\")", args)
+        paths = STRING.findall(positional.group(1)) if positional else []
+    return paths or [""]
+
+
+def _mapping(method: Declaration) -> tuple[list[str], list[str]] | None:
+    """(HTTP methods, paths) of a handler method, or None if it is not one."""
+    for annotation in method.annotations:
+        if annotation.short_name in MAPPING_VERBS:
+            return [MAPPING_VERBS[annotation.short_name]], _mapping_paths(annotation)
+        if annotation.short_name == "RequestMapping":
+            verbs = [v.upper() for v in REQUEST_METHOD.findall(annotation.args)] or ["*"]
+            return verbs, _mapping_paths(annotation)
+    return None
+
+
+def _declaration_finding(
+    kind: str, file_path: str, text: str, method: Declaration, resource: str, action: str, access: Access, inherited: str | None
+) -> ConfigFinding:
+    """Finding for one secured method."""
+    line_start = line_of(text, method.start)
+    line_end = min(line_of(text, method.end), line_start + MAX_SNIPPET_LINES - 1)
+    description = f"Spring {'endpoint' if kind == SpringRouteKind.ROUTE else 'method'} secured by {access.source}"
+    if inherited:
+        description += f" (inherited from {inherited})"
+    return ConfigFinding(
+        kind=kind,
+        file_path=file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=line_span(text, line_start, line_end),
+        subject=access.subject,
+        resource=resource,
+        action=action,
+        conditions=access.conditions,
+        description=description,
+        disables_auth=access.subject == ANONYMOUS,
+    )
+
+
+def extract_method_security(file_path: str, text: str, context: SpringContext) -> list[ConfigFinding]:
+    """Extract method security from controllers and services.
+
+    Handler methods of @RestController/@Controller classes become one
+    finding per HTTP method and path, combining the class's @RequestMapping
+    prefix; other secured methods are reported by Class.method. A method's
+    own security overrides the class's.
+
+    Args:
+        file_path: Path of the source file
+        text: Java or Kotlin source
+        context: Constants and meta-annotations of the service
+
+    Returns:
+        One finding per secured route or method
+    """
+    findings = []
+    for cls in parse_declarations(text):
+        controller = cls.find(*CONTROLLER_ANNOTATIONS) is not None
+        prefixes = _mapping_paths(cls.find("RequestMapping"))
+        class_access = _security(cls, context)
+        for method in cls.members:
+            own = _security(method, context)
+            access = own or class_access
+            if access is None:
+                continue
+            inherited = None if own else cls.name
+            mapping = _mapping(method) if controller else None
+            if mapping is None:
+                findings.append(
+                    _declaration_finding(
+                        SpringRouteKind.METHOD_SECURITY, file_path, text, method, f"{cls.name}.{method.name}", "call", access, inherited
+                    )
+                )
+                continue
+            verbs, paths = mapping
+            for prefix in prefixes:
+                for path in paths:
+                    for verb in verbs:
+                        findings.append(
+                            _declaration_finding(
+                                SpringRouteKind.ROUTE, file_path, text, method, _join(prefix, path), verb, access, inherited
+                            )
+                        )
+    return findings
+
+
+@dataclass
+class _ChainRule:
+    """One matcher-to-access rule of a security filter chain."""
+
+    index: int
+    patterns: list[str]
+    method: str | None
+    access: Access
+    start: int
+    end: int
+
+
+def _dsl_access(name: str, args: str, constants: dict[str, str], negated: bool = False) -> Access:
+    """Access of a Java DSL rule such as .hasRole("ADMIN") or .access("SpEL")."""
+    values = [constants.get(v, v) for v in STRING.findall(args)] or [
+        constants[r] for r in re.findall(r"[\w.]+", args) if r in constants
+    ]
+    source = f".{name}({args.strip()})"
+    requirement = _Requirement()
+    if name == "access":
+        access = spel_access(" ".join(values), source) if values else Access(AUTHENTICATED, "custom authorization manager", source)
+    elif name in DSL_DECISIONS:
+        access = Access(DSL_DECISIONS[name], source=source)
+    elif name == "hasIpAddress":
+        access = Access(ANONYMOUS, f"caller address in {', '.join(values)}", source)
+    else:
+        _grant(requirement, values, as_role=name in ("hasRole", "hasAnyRole"))
+        access = _access(requirement, source)
+    if negated:
+        return Access(AUTHENTICATED, f"does not pass {source}", f".not(){source}")
+    return access
+
+
+def _kotlin_rule(args: list[str], constants: dict[str, str]) -> tuple[list[str], str | None, Access] | None:
+    """Patterns, method, and access of a Kotlin DSL authorize(...) call."""
+    if not args:
+        return None
+    method = None
+    if len(args) == 3:
+        found = HTTP_METHOD.search(args[0])
+        method = found.group(1).upper() if found else None
+        args = args[1:]
+    rule = KOTLIN_ACCESS.match(args[-1].strip())
+    if rule is None:
+        return None
+    patterns = STRING.findall(args[0]) or (["/**"] if args[0].strip() in ("anyRequest", "anyExchange") else [])
+    if not patterns or len(args) < 2:
+        return None
+    return patterns, method, _dsl_access(rule.group(1), rule.group(2) or "", constants)
+
+
+def _pattern_regex(pattern: str) -> re.Pattern:
+    """Ant-style path pattern as a regex."""
+    regex = ""
+    for token in re.split(r"(\*\*|\*|\{[^}]*\})", pattern):
+        if token == "**":
+            regex += ".*"
+        elif token == "*" or token.startswith("{"):
+            regex += "[^/]*"
+        else:
+            regex += re.escape(token)
+    return re.compile(regex.replace("/.*", "(?:/.*)?"))
+
+
+def _covers(earlier: _ChainRule, later: _ChainRule) -> bool:
+    """Whether every request a later rule matches is already matched by an earlier one."""
+    if earlier.method is not None and earlier.method != later.method:
+        return False
+    samples = [re.sub(r"\{[^}]*\}", "x", re.sub(r"\*\*?", "x", p)) for p in later.patterns]
+    regexes = [_pattern_regex(p) for p in earlier.patterns]
+    return all(any(r.fullmatch(sample) for r in regexes) for sample in samples)
+
+
+def parse_filter_chain(text: str, constants: dict[str, str] | None = None) -> list[_ChainRule]:
+    """Matcher-to-access rules of the HttpSecurity configuration in a source file, in declaration order."""
+    constants = constants or {}
+    code = _strip_comments(text)
+    rules: list[_ChainRule] = []
+    patterns: list[str] | None = None
+    method: str | None = None
+    negated = False
+    start = 0
+    pos = 0
+    while True:
+        match = CHAIN_TOKEN.search(code, pos)
+        if not match:
+            break
+        open_paren = match.end() - 1
+        close = _close_paren(code, open_paren)
+        args = code[open_paren + 1 : close - 1]
+        pos = close
+        if match.group(1):
+            found = HTTP_METHOD.search(args)
+            method = found.group(1).upper() if found else None
+            patterns = STRING.findall(args) or (["/**"] if match.group(1) in ("anyRequest", "anyExchange") else [])
+            start, negated = match.start(), False
+        elif match.group(2) == "not":
+            negated = True
+        elif match.group(2) and patterns:
+            access = _dsl_access(match.group(2), args, constants, negated)
+            rules.append(_ChainRule(len(rules) + 1, patterns, method, access, start, close))
+            patterns, method, negated = None, None, False
+        elif match.group(0).startswith("authorize"):
+            parsed = _kotlin_rule(_split_args(args), constants)
+            if parsed:
+                rules.append(_ChainRule(len(rules) + 1, parsed[0], parsed[1], parsed[2], match.start(), close))
+    return rules
+
+
+def _split_args(args: str) -> list[str]:
+    """Top-level comma-separated arguments."""
+    parts, depth, quote, start = [], 0, False, 0
+    for i, c in enumerate(args):
+        if c == '"':
+            quote = not quote
+        elif quote:
+            continue
+        elif c in "([{":
+            depth += 1
+        elif c in ")]}":
+            depth -= 1
+        elif c == "," and depth == 0:
+            parts.append(args[start:i].strip())
+            start = i + 1
+    parts.append(args[start:].strip())
+    return [p for p in parts if p]
+
+
+def extract_http_security(file_path: str, text: str, context: SpringContext | None = None) -> list[ConfigFinding]:
+    """Extract HttpSecurity authorization rules from a security configuration.
+
+    Rules are evaluated in order and the first match applies, so a rule whose
+    patterns an earlier rule already matches is reported as shadowed.
+
+    Args:
+        file_path: Path of the source file
+        text: Java or Kotlin source
+        context: Constants of the service
+
+    Returns:
+        One finding per rule pattern
+    """
+    if not CHAIN_MARKER.search(text):
+        return []
+    rules = parse_filter_chain(text, (context or SpringContext()).constants)
+    findings = []
+    for rule in rules:
+        shadow = next((earlier for earlier in rules[: rule.index - 1] if _covers(earlier, rule)), None)
+        conditions = [rule.access.conditions] if rule.access.conditions else []
+        if shadow:
+            conditions.append(f"shadowed by rule {shadow.index} ({', '.join(shadow.patterns)}), which matches first")
+        line_start = line_of(text, rule.start)
+        line_end = line_of(text, rule.end)
+        for pattern in rule.patterns:
+            findings.append(
+                ConfigFinding(
+                    kind=SpringRouteKind.HTTP_SECURITY,
+                    file_path=file_path,
+                    line_start=line_start,
+                    line_end=line_end,
+                    snippet=line_span(text, line_start, line_end),
+                    subject=rule.access.subject,
+                    resource=pattern,
+                    action=rule.method or "*",
+                    conditions="; ".join(conditions) or None,
+                    description=f"Spring Security filter chain rule {rule.index} applying {rule.access.source}",
+                    disables_auth=rule.access.subject == ANONYMOUS and not shadow,
+                )
+            )
+    return findings
+
+
+def extract_spring_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Spring method security and filter chain rules from a service's files.
+
+    Args:
+        files: Relative path -> content; non-JVM files are ignored
+
+    Returns:
+        Findings across all files
+    """
+    jvm = {p: t for p, t in files.items() if p.endswith(JVM_SUFFIXES)}
+    # Role constants often live in plain classes without Spring imports
+    context = build_context(jvm)
+    sources = {p: t for p, t in jvm.items() if SPRING_MARKER.search(t)}
+    findings = []
+    for file_path, text in sources.items():
+        findings.extend(extract_method_security(file_path, text, context))
+        findings.extend(extract_http_security(file_path, text, context))
+    return findings
@@ -0,0 +1,229 @@
+"""Stable identifiers for endpoints and mined rules across scans.
+
+Every scan stores its policies and evidence as new rows, and the file paths
+and line numbers in that evidence change whenever code is moved or renamed.
+This service derives identifiers from what a rule says instead: an endpoint
+ID from its method and parameter-normalized path, a rule ID from its roles,
+normalized action, resource, and conditions plus the endpoint it guards,
+and a code fingerprint from its evidence with comments and whitespace
+stripped. Policies sharing an identity form a lineage. After each scan the
+newest policy in a lineage inherits the triage status, review comment,
+reviewer, and owning application of its last reviewed predecessor, so
+triage survives refactors instead of resetting.
+"""
+
+import hashlib
+import re
+from collections import defaultdict
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus
+from app.models.policy_change import PolicyChange
+from app.models.repository import Repository
+from app.services.endpoint_mapping_service import EndpointMappingService
+
+logger = structlog.get_logger(__name__)
+
+# Path parameters in any framework's syntax: :id, {id}, <int:id>, [id], *
+PATH_PARAMETER = re.compile(r":\w+|\{[^}/]*\}|<[^>/]*>|\[[^\]/]*\]|\*+")
+
+# Line and block comments stripped before fingerprinting evidence
+CODE_COMMENT = re.compile(r"/\*.*?\*/|//[^\n]*|(?<![\w'\"])#[^\n]*", re.DOTALL)
+
+# Policy fields that record a review decision
+TRIAGE_FIELDS = ("status", "approval_comment", "reviewed_by", "reviewed_at")
+
+
+def _digest(prefix: str, *parts: str) -> str:
+    """Short content hash of the given parts."""
+    return f"{prefix}_{hashlib.sha256(chr(31).join(parts).encode()).hexdigest()[:16]}"
+
+
+def _normalize(value: str | None) -> str:
+    """Case-folded text with quotes dropped and whitespace collapsed."""
+    return " ".join(re.sub(r"['\"`]", "", value or "").casefold().split())
+
+
+def normalize_path(path: str) -> str:
+    """Route path with parameters replaced by {} and trailing slashes dropped."""
+    path = PATH_PARAMETER.sub("{}", path.split("?", 1)[0])
+    return re.sub(r"/{2,}", "/", "/" + path.strip("/"))
+
+
+def endpoint_id(method: str, path: str) -> str:
+    """Stable identifier of an endpoint, independent of parameter names."""
+    return _digest("ep", method.upper(), normalize_path(path))
+
+
+def rule_id(policy: Policy) -> str:
+    """Stable identifier of a mined rule, independent of where its code lives.
+
+    Roles are compared as a set, so "Admin or Manager" and "MANAGER, ADMIN"
+    identify the same rule.
+    """
+    rule = EndpointMappingService.map_policy(policy)
+    roles, requires_authentication = EndpointMappingService.parse_roles(policy.subject)
+    audience = ",".join(sorted(roles)) or ("authenticated" if requires_authentication else "anonymous")
+    return _digest(
+        "rule",
+        audience,
+        _normalize(policy.action),
+        _normalize(policy.resource),
+        _normalize(policy.conditions),
+        endpoint_id(rule.method, rule.path),
+    )
+
+
+def code_fingerprint(policy: Policy) -> str | None:
+    """Fingerprint of a policy's evidence code, or None without evidence."""
+    snippets = sorted(
+        "".join(CODE_COMMENT.sub("", evidence.code_snippet or "").split()) for evidence in policy.evidence or []
+    )
+    snippets = [s for s in snippets if s]
+    return _digest("code", *snippets) if snippets else None
+
+
+def _reviewed(policy: Policy) -> bool:
+    """Whether a policy carries a review decision."""
+    return policy.status != PolicyStatus.PENDING or bool(policy.reviewed_by)
+
+
+def build_lineages(policies: list[Policy]) -> list[tuple[str, list[Policy]]]:
+    """Group policies of one repository into lineages of the same rule.
+
+    Policies with the same rule ID belong together. A policy whose rule was
+    reworded between scans still joins the lineage whose newest policy has
+    the same code fingerprint, as long as that fingerprint is unambiguous.
+
+    Args:
+        policies: Policies of one repository, in any order
+
+    Returns:
+        (rule ID, policies oldest first) per lineage, oldest lineage first
+    """
+    lineages: dict[str, list[Policy]] = {}
+    fingerprints: dict[str, str | None] = {}  # Lineage -> fingerprint of its newest policy
+    for policy in sorted(policies, key=lambda p: p.id):
+        identity, fingerprint = rule_id(policy), code_fingerprint(policy)
+        if identity not in lineages and fingerprint:
+            candidates = [key for key, value in fingerprints.items() if value == fingerprint]
+            if len(candidates) == 1:
+                identity = candidates[0]
+        lineages.setdefault(identity, []).append(policy)
+        fingerprints[identity] = fingerprint
+    return list(lineages.items())
+
+
+class StableIdentityService:
+    """Tracks mined rules and their endpoints across scans by stable identifiers."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _require_repository(self, repository_id: int) -> None:
+        """Raise ValueError unless the tenant has the repository."""
+        query = self.db.query(Repository).filter(Repository.id == repository_id)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if query.first() is None:
+            raise ValueError(f"Repository {repository_id} not found")
+
+    def _policies(self, repository_id: int) -> list[Policy]:
+        """All policies ever mined from a repository, including rejected ones."""
+        query = self.db.query(Policy).filter(Policy.repository_id == repository_id)
+        if self.tenant_id:
+            query = query.filter(Policy.tenant_id == self.tenant_id)
+        return query.order_by(Policy.id).all()
+
+    def carry_over(self, repository_id: int) -> int:
+        """Copy triage and ownership onto the newest policy of each lineage.
+
+        Only policies nobody has reviewed yet inherit; a decision made on the
+        newest policy is never overwritten.
+
+        Args:
+            repository_id: Repository that was just scanned
+
+        Returns:
+            Number of policies that inherited a predecessor's triage
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        self._require_repository(repository_id)
+        inherited = 0
+        for _, lineage in build_lineages(self._policies(repository_id)):
+            current = lineage[-1]
+            previous = next((p for p in reversed(lineage[:-1]) if _reviewed(p)), None)
+            if previous is None or _reviewed(current):
+                continue
+            for name in TRIAGE_FIELDS:
+                setattr(current, name, getattr(previous, name))
+            if current.application_id is None:
+                current.application_id = previous.application_id
+            inherited += 1
+        if inherited:
+            self.db.commit()
+        logger.info("triage_carried_over", repository_id=repository_id, policies=inherited)
+        return inherited
+
+    def lineages(self, repository_id: int) -> list[dict]:
+        """Lineage of every rule mined from a repository.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Per rule: its stable IDs, current triage, the files its code has
+            lived in, and the change history of all its policies, newest rule first
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        self._require_repository(repository_id)
+        lineages = build_lineages(self._policies(repository_id))
+        policy_ids = {p.id for _, lineage in lineages for p in lineage}
+        history: dict[int, list[PolicyChange]] = defaultdict(list)
+        if policy_ids:
+            query = self.db.query(PolicyChange).filter(PolicyChange.repository_id == repository_id)
+            if self.tenant_id:
+                query = query.filter(PolicyChange.tenant_id == self.tenant_id)
+            for change in query.order_by(PolicyChange.id).all():
+                for pid in {change.policy_id, change.previous_policy_id} & policy_ids:
+                    history[pid].append(change)
+
+        results = []
+        for identity, lineage in lineages:
+            current = lineage[-1]
+            rule = EndpointMappingService.map_policy(current)
+            locations = list(dict.fromkeys(e.file_path for p in lineage for e in p.evidence or [] if e.file_path))
+            changes = list({c.id: c for p in lineage for c in history.get(p.id, [])}.values())
+            results.append(
+                {
+                    "rule_id": identity,
+                    "endpoint_id": endpoint_id(rule.method, rule.path),
+                    "endpoint": rule.key,
+                    "current_policy_id": current.id,
+                    "policy_ids": [p.id for p in lineage],
+                    "status": current.status,
+                    "reviewed_by": current.reviewed_by,
+                    "application_id": current.application_id,
+                    "locations": locations,
+                    "moved": len(locations) > 1,
+                    "history": [
+                        {
+                            "change_id": c.id,
+                            "change_type": c.change_type,
+                            "policy_id": c.policy_id,
+                            "detected_at": c.detected_at,
+                        }
+                        for c in sorted(changes, key=lambda c: c.id)
+                    ],
+                }
+            )
+        results.sort(key=lambda r: -r["current_policy_id"])
+        return results
@@ -0,0 +1,219 @@
+"""Service for per-repository policy status and the SVG badge showing it.
+
+Teams embed the badge in READMEs and platform catalogs to show, at a glance,
+how much of a service's API is behind authentication and whether it has open
+critical findings. Both come from the metrics snapshot captured when each
+scan completes (see TrendMetricsService), so the badge changes with every
+scan and serving it never recomputes coverage.
+
+READMEs are public, so the embeddable badge is served by an unguessable
+per-repository token rather than the repository ID; status by ID stays
+scoped to the caller's tenant. Regenerating the token retires old links.
+"""
+
+import secrets
+from html import escape
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.repository import Repository
+from app.models.scan_metrics import ScanMetricsSnapshot
+
+logger = structlog.get_logger(__name__)
+
+BADGE_LABEL = "auth coverage"
+
+# Badge colors (shields.io palette); coverage colors from the highest threshold met
+CRITICAL_COLOR = "#e05d44"
+UNKNOWN_COLOR = "#9f9f9f"
+COVERAGE_COLORS = [(90.0, "#4c1"), (75.0, "#97ca00"), (50.0, "#dfb317"), (0.0, "#fe7d37")]
+
+# Average glyph width of 11px Verdana, for sizing badge segments without font metrics
+CHARACTER_WIDTH = 6.5
+SEGMENT_PADDING = 10
+
+BADGE_TEMPLATE = """<svg xmlns="http://www.w3.org/2000/svg" width="{width}" height="20" role="img" aria-label="{label}: {message}">
+<title>{label}: {message}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{width}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="{label_width}" height="20" fill="#555"/><rect x="{label_width}" width="{message_width}" height="20" fill="{color}"/><rect width="{width}" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{label_x}" y="14">{label}</text><text x="{message_x}" y="14">{message}</text>
+</g>
+</svg>
+"""
+
+
+class BadgeStatus:
+    """Overall state a repository's badge shows."""
+
+    PASSING = "passing"  # Scanned, no open critical findings
+    FAILING = "failing"  # Open critical findings
+    UNKNOWN = "unknown"  # Never scanned to completion
+
+
+def _segment_width(text: str) -> int:
+    """Width of a badge segment holding the text."""
+    return round(len(text) * CHARACTER_WIDTH) + SEGMENT_PADDING
+
+
+def render_badge(label: str, message: str, color: str) -> str:
+    """Flat two-segment SVG badge in the shields.io style.
+
+    Args:
+        label: Text of the grey left segment
+        message: Text of the colored right segment
+        color: Fill of the right segment
+
+    Returns:
+        SVG document
+    """
+    label_width, message_width = _segment_width(label), _segment_width(message)
+    return BADGE_TEMPLATE.format(
+        width=label_width + message_width,
+        label_width=label_width,
+        message_width=message_width,
+        label_x=label_width / 2,
+        message_x=label_width + message_width / 2,
+        label=escape(label),
+        message=escape(message),
+        color=color,
+    )
+
+
+def badge_content(status: dict) -> tuple[str, str]:
+    """Message and color of a repository's badge.
+
+    Args:
+        status: Repository status, as returned by StatusBadgeService.status
+
+    Returns:
+        (message, color), e.g. ("87% | 2 critical", "#e05d44")
+    """
+    if status["status"] == BadgeStatus.UNKNOWN:
+        return "not scanned", UNKNOWN_COLOR
+    message = f"{status['coverage_percent']:g}%"
+    if status["status"] == BadgeStatus.FAILING:
+        return f"{message} | {status['critical_findings']} critical", CRITICAL_COLOR
+    color = next(c for threshold, c in COVERAGE_COLORS if status["coverage_percent"] >= threshold)
+    return message, color
+
+
+class StatusBadgeService:
+    """Serves per-repository status from the latest scan snapshot."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query a model scoped to the tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def status(self, repository_id: int) -> dict:
+        """Authentication coverage and open critical findings as of the repository's last scan.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Status with the badge's state and the snapshot it was read from
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        return self._status(self._repository(repository_id))
+
+    def _repository(self, repository_id: int) -> Repository:
+        """Get a repository of the tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def _badge_token(self, repository: Repository) -> str:
+        """The repository's badge token, issued on first use for repositories created without one."""
+        if not repository.badge_token:
+            repository.badge_token = secrets.token_urlsafe(24)
+            self.db.commit()
+        return repository.badge_token
+
+    def _status(self, repository: Repository) -> dict:
+        """Status of a repository from its latest snapshot."""
+        snapshot = (
+            self._query(ScanMetricsSnapshot)
+            .filter(ScanMetricsSnapshot.repository_id == repository.id)
+            .order_by(ScanMetricsSnapshot.captured_at.desc())
+            .first()
+        )
+        status = {
+            "repository_id": repository.id,
+            "repository_name": repository.name,
+            "badge_url": f"/api/v1/status/badges/{self._badge_token(repository)}.svg",
+        }
+        if snapshot is None:
+            return {**status, "status": BadgeStatus.UNKNOWN}
+
+        critical = snapshot.critical_findings or 0
+        return {
+            **status,
+            "status": BadgeStatus.FAILING if critical else BadgeStatus.PASSING,
+            "coverage_percent": snapshot.authenticated_percent,
+            "authorized_percent": snapshot.authorized_percent,
+            "total_endpoints": snapshot.total_endpoints,
+            "critical_findings": critical,
+            "open_findings": snapshot.open_findings or {},
+            "scan_id": snapshot.scan_id,
+            "scanned_at": snapshot.captured_at,
+        }
+
+    def badge(self, repository_id: int) -> str:
+        """SVG badge of the repository's status.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        message, color = badge_content(self.status(repository_id))
+        return render_badge(BADGE_LABEL, message, color)
+
+    def public_badge(self, badge_token: str) -> str:
+        """SVG badge of the repository a badge token was issued to, whatever its tenant.
+
+        Args:
+            badge_token: Token from the badge URL
+
+        Raises:
+            ValueError: If no repository has this token
+        """
+        repository = self.db.query(Repository).filter(Repository.badge_token == badge_token).first()
+        if not badge_token or repository is None:
+            raise ValueError("Badge not found")
+        message, color = badge_content(self._status(repository))
+        return render_badge(BADGE_LABEL, message, color)
+
+    def regenerate_badge_token(self, repository_id: int) -> dict:
+        """Issue a new badge token, so embedded badges using the old one stop resolving.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Status with the new badge URL
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._repository(repository_id)
+        repository.badge_token = secrets.token_urlsafe(24)
+        self.db.commit()
+        logger.info("badge_token_regenerated", repository_id=repository.id, tenant_id=self.tenant_id)
+        return self._status(repository)
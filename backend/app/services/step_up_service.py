@@ -0,0 +1,224 @@
+"""Service for detecting step-up authentication and MFA requirements.
+
+A role check says who may perform an action; step-up says how recently and
+how strongly they must have proven it. The evidence behind a mined policy
+shows step-up as a token claim check (amr containing "mfa", an acr level),
+an MFA guard or decorator, or a re-authentication prompt (password
+confirmation, fresh-login requirement, max_age). This service records those
+as strength attributes per policy and flags high-risk endpoints whose
+policies require none of them.
+"""
+
+import re
+from dataclasses import asdict, dataclass, field
+from enum import Enum
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, RiskLevel
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.endpoint_risk_service import EndpointRiskService
+
+logger = structlog.get_logger(__name__)
+
+
+class StepUpKind(str, Enum):
+    """Ways a policy demands stronger or fresher authentication."""
+
+    AMR_CLAIM = "amr_claim"  # Token amr claim checked (e.g., contains "mfa")
+    ACR_CLAIM = "acr_claim"  # Token acr assurance level checked
+    MFA = "mfa"  # MFA guard, decorator, or verified-device check
+    REAUTHENTICATION = "reauthentication"  # Password confirmation, fresh login, or max_age
+
+
+STEP_UP_SIGNATURES: list[tuple[StepUpKind, re.Pattern]] = [
+    (StepUpKind.AMR_CLAIM, re.compile(r"\bamr\b", re.IGNORECASE)),
+    (StepUpKind.ACR_CLAIM, re.compile(r"\bacr(?:_values)?\b|AuthnContextClassRef", re.IGNORECASE)),
+    (
+        StepUpKind.MFA,
+        re.compile(
+            r"mfa|2fa|two[_-]?factor|multi[_-]?factor|otp_required|\btotp\b|webauthn|django_otp|"
+            r"is_verified\(\)|step[_-]?up",
+            re.IGNORECASE,
+        ),
+    ),
+    (
+        StepUpKind.REAUTHENTICATION,
+        re.compile(
+            r"re-?auth(?:enticat\w*)?\b|confirm[._-]?password|password[._-]?confirm|RequirePasswordConfirmation|"
+            r"fresh_login_required|login_fresh|recent[_-]?login|sudo[_-]?mode|prompt\s*[=:]\s*['\"]?login|"
+            r"auth_time|max[_-]?age",
+            re.IGNORECASE,
+        ),
+    ),
+]
+
+# Lines naming a claim; the string literals on them are the accepted values
+CLAIM_LINES = {
+    StepUpKind.AMR_CLAIM: re.compile(r"\bamr\b", re.IGNORECASE),
+    StepUpKind.ACR_CLAIM: re.compile(r"\bacr(?:_values)?\b", re.IGNORECASE),
+}
+CLAIM_NAMES = {"amr", "acr", "acr_values"}
+STRING = re.compile(r"""['"]([^'"\n]{1,100})['"]""")
+MAX_AGE = re.compile(r"max[_-]?age\s*[=:]\s*['\"]?(\d+)", re.IGNORECASE)
+
+
+@dataclass
+class StepUpStrength:
+    """Step-up attributes of a policy or endpoint."""
+
+    kinds: list[str] = field(default_factory=list)
+    acr_values: list[str] = field(default_factory=list)
+    amr_values: list[str] = field(default_factory=list)
+    max_age_seconds: int | None = None
+
+    @property
+    def step_up(self) -> bool:
+        """Whether any step-up requirement was found."""
+        return bool(self.kinds)
+
+    def merge(self, other: "StepUpStrength") -> None:
+        """Add another policy's requirements (the strictest max_age wins)."""
+        for name in ("kinds", "acr_values", "amr_values"):
+            values = getattr(self, name)
+            values.extend(v for v in getattr(other, name) if v not in values)
+        if other.max_age_seconds is not None and (
+            self.max_age_seconds is None or other.max_age_seconds < self.max_age_seconds
+        ):
+            self.max_age_seconds = other.max_age_seconds
+
+
+def detect_step_up(text: str) -> StepUpStrength:
+    """Find step-up requirements in code or prose.
+
+    Args:
+        text: Evidence snippets, conditions, and description
+
+    Returns:
+        Detected kinds, accepted acr/amr values, and the max_age if one is set
+    """
+    strength = StepUpStrength(kinds=[kind.value for kind, pattern in STEP_UP_SIGNATURES if pattern.search(text)])
+    for line in text.splitlines():
+        for kind, claim in CLAIM_LINES.items():
+            if not claim.search(line):
+                continue
+            target = strength.amr_values if kind == StepUpKind.AMR_CLAIM else strength.acr_values
+            for value in STRING.findall(line):
+                if value.lower() not in CLAIM_NAMES and value not in target:
+                    target.append(value)
+    ages = [int(m) for m in MAX_AGE.findall(text)]
+    strength.max_age_seconds = min(ages) if ages else None
+    return strength
+
+
+def policy_step_up(policy: Policy) -> StepUpStrength:
+    """Detect the step-up requirements of one policy.
+
+    Args:
+        policy: Mined policy
+
+    Returns:
+        Step-up attributes read from its conditions, description, and evidence
+    """
+    parts = [policy.conditions, policy.description] + [ev.code_snippet for ev in policy.evidence or []]
+    return detect_step_up("\n".join(p for p in parts if isinstance(p, str)))
+
+
+class StepUpService:
+    """Records step-up strength and flags high-risk endpoints without it."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _load(self, repository_id: int | None, application_id: int | None) -> list[Policy]:
+        """Load the policies in scope."""
+        return DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id, application_id)
+
+    def policies(self, repository_id: int | None = None, application_id: int | None = None) -> list[dict]:
+        """Step-up strength attributes for each policy in scope.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+
+        Returns:
+            Policy dictionaries with their step-up attributes
+        """
+        results = []
+        for policy in self._load(repository_id, application_id):
+            strength = policy_step_up(policy)
+            results.append(
+                {
+                    "policy_id": policy.id,
+                    "subject": policy.subject,
+                    "resource": policy.resource,
+                    "action": policy.action,
+                    "risk_level": policy.risk_level.value if policy.risk_level else None,
+                    "step_up": strength.step_up,
+                    **asdict(strength),
+                }
+            )
+        return results
+
+    def endpoints(
+        self,
+        repository_id: int | None = None,
+        application_id: int | None = None,
+        missing_only: bool = False,
+    ) -> list[dict]:
+        """Step-up requirements per endpoint, flagging high-risk ones without any.
+
+        An endpoint is high risk when its composite endpoint risk is high or
+        one of its policies was scored high risk. Public endpoints are not
+        flagged; they lack authentication altogether.
+
+        Args:
+            repository_id: Restrict to one repository
+            application_id: Restrict to one application
+            missing_only: Return only flagged endpoints
+
+        Returns:
+            Endpoint dictionaries, flagged endpoints first, then by descending risk
+        """
+        policies = self._load(repository_id, application_id)
+        by_id = {p.id: p for p in policies}
+        risks = {e["endpoint"]: e for e in EndpointRiskService(self.db, self.tenant_id).score_endpoints(policies=policies)}
+
+        results = []
+        for rule in EndpointMappingService.map_policies(policies):
+            strength = StepUpStrength()
+            for policy_id in rule.policy_ids:
+                strength.merge(policy_step_up(by_id[policy_id]))
+            risk = risks.get(rule.key, {})
+            high_risk = risk.get("level") == "high" or any(
+                by_id[pid].risk_level == RiskLevel.HIGH for pid in rule.policy_ids
+            )
+            missing = high_risk and rule.requires_authentication and not strength.step_up
+            if missing_only and not missing:
+                continue
+            results.append(
+                {
+                    "endpoint": rule.key,
+                    "method": rule.method,
+                    "path": rule.path,
+                    "policy_ids": rule.policy_ids,
+                    "risk_score": risk.get("score", 0.0),
+                    "high_risk": high_risk,
+                    "step_up": strength.step_up,
+                    "missing_step_up": missing,
+                    **asdict(strength),
+                }
+            )
+
+        results.sort(key=lambda e: (not e["missing_step_up"], -e["risk_score"], e["endpoint"]))
+        logger.info(
+            "step_up_requirements_detected",
+            repository_id=repository_id,
+            endpoints=len(results),
+            missing_step_up=sum(e["missing_step_up"] for e in results),
+        )
+        return results
@@ -0,0 +1,419 @@
+"""Service for mining tenant isolation constraints and the endpoints missing them.
+
+In a multi-tenant application every object belongs to a tenant, and a
+handler that loads one by ID must tie it to the caller's tenant: compare the
+object's tenant with the caller's (user.TenantID != expense.TenantID), scope
+the query itself (WHERE tenant_id = ?, filter(tenant_id=request.user.tenant_id)),
+or run behind tenant middleware. Role checks do not do this; a MANAGER of one
+tenant passes RequireRole("MANAGER") on another tenant's expense. This service
+finds the models carrying a tenant column, reads the handlers of each mined
+endpoint for such checks, surfaces them as "user is in the same tenant as
+expense.tenant_id" constraints, and reports the endpoints that touch
+tenant-owned models with no check at all.
+"""
+
+import re
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass, field
+from pathlib import Path
+
+import structlog
+
+from app.core.sandbox import run_pass
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.abac_condition_service import AbacConditionService, _clause_key, _SourceFile
+from app.services.bola_detection_service import DECORATOR_LINE, FETCH, HEADER_LINES, SEVERITY_ORDER
+from app.services.condition_evaluation_service import ConditionEvaluationService
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, SKIPPED_DIRECTORIES
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.extractor_utils import MAX_LINE_LENGTH
+from app.services.guard_condition_extractor import _attribute
+from app.services.mass_assignment_service import _normalized, _singular, parse_models
+
+logger = structlog.get_logger(__name__)
+
+# Columns naming the tenant an object belongs to, compared without case or separators
+TENANT_FIELDS = {"tenantid", "tenant", "orgid", "organizationid", "organisationid", "workspaceid", "companyid"}
+
+TENANT_NEEDLE = re.compile(r"tenant|org|workspace|company", re.IGNORECASE)
+OPERAND = r"(?<![\w$.])(?:[\w$]+(?:\(\s*\))?\s*(?:\?\.|\.|->)\s*){0,4}\w*(?:tenant|org|organi[sz]ation|workspace|company)_?id\w*(?:\(\s*\))?"
+# user.TenantID != expense.TenantID, Objects.equals(a.getTenantId(), b.getTenantId())
+TENANT_COMPARISON = re.compile(
+    rf"({OPERAND})\s*(?:[!=]==?|\.\s*equals\s*\()\s*({OPERAND})|\bObjects\s*\.\s*equals\s*\(\s*({OPERAND})\s*,\s*({OPERAND})\s*\)",
+    re.IGNORECASE,
+)
+# Queries filtered on the tenant column: WHERE tenant_id = ?, filter(tenant_id=...), { tenantId: ... }, Eq("tenant_id", ...)
+TENANT_FILTER = re.compile(
+    r"""(?:['"`]|\b)(\w*?(?:tenant|org|organi[sz]ation|workspace|company)_?id)['"`]?\s*(?:=(?!=)|:(?!:)|==|,|\s+IN\b)""",
+    re.IGNORECASE,
+)
+DATA_ACCESS = re.compile(
+    r"\.\s*(?:Where|where|filter|filter_by|Filter|find\w*|Find\w*|First|Take|query|Query\w*|Exec\w*|objects|scope\w*|Scope\w*)\b|"
+    r"\.\s*(?:save|Save|create|Create|insert\w*|Insert\w*|update\w*|Update\w*|delete|Delete|destroy)\s*\(|"
+    r"\b(?:SELECT|UPDATE|DELETE\s+FROM|INSERT\s+INTO)\b|\bwhere\s*:"
+)
+# Tenant middleware and decorators: RequireTenant, @tenant_required, @TenantScoped, r.Use(TenantMiddleware)
+TENANT_MIDDLEWARE = re.compile(
+    r"\b(?:[Rr]equire|[Ee]nsure|[Vv]erify|[Ww]ith|[Ss]et)_?(?:[Cc]urrent_?)?[Tt]enant\w*|\b[Tt]enant_?(?:[Rr]equired|[Ss]coped?|[Gg]uard|[Mm]iddleware|[Ii]solation|[Ff]ilter)\w*\b|"
+    r"\bapp\s*\.\s*current_tenant\b"
+)
+FILE_MIDDLEWARE = re.compile(r"\.\s*(?:Use|use|UseMiddleware)\s*\(\s*[\w.]*[Tt]enant\w*")
+# The caller's side of a comparison or assignment
+SUBJECT_HINT = re.compile(
+    r"\b(?:user|current_?user|currentUser|caller|principal|claims?|session|auth\w*|identity|me|actor|ctx|context|request|req|g)\s*(?:\.|\?\.|->|\[)|"
+    r"(?<![\w.])(?:getTenant\w*|current_?tenant\w*|caller_?tenant\w*)\b|^\s*(?:tenant|org|workspace)_?id\s*$",
+    re.IGNORECASE,
+)
+SQL_TABLE = re.compile(r"\b(?:FROM|INTO|UPDATE|JOIN)\s+[\"`]?(\w+)", re.IGNORECASE)
+WRITE_METHODS = {"POST", "PUT", "PATCH", "DELETE"}
+
+
+@dataclass
+class TenantCheck:
+    """A check tying the objects a handler touches to the caller's tenant."""
+
+    line: int
+    kind: str  # comparison, query_filter, assignment, or middleware
+    code: str
+    resource_attribute: str  # e.g. expense.tenant_id
+
+    @property
+    def condition(self) -> str:
+        """The check as a mined condition clause."""
+        return f"user is in the same tenant as {self.resource_attribute}"
+
+
+@dataclass
+class TenantGap:
+    """An endpoint touching tenant-owned models with no tenant isolation check."""
+
+    endpoint: str
+    file_path: str
+    function: str | None
+    line_start: int
+    line_end: int
+    resources: list[str]
+    severity: str
+    description: str
+    policy_ids: list[int] = field(default_factory=list)
+
+
+def _tenant_field(name: str) -> bool:
+    """Whether a model field records the tenant an object belongs to."""
+    return _normalized(name) in TENANT_FIELDS
+
+
+def _short(code: str) -> str:
+    """Code on one line, shortened for evidence."""
+    text = " ".join(code.split())
+    return text if len(text) <= 120 else text[:117] + "..."
+
+
+def _resource_side(left: str, right: str) -> str:
+    """The operand naming the object's tenant: the one not read from the caller."""
+    if SUBJECT_HINT.search(right) and not SUBJECT_HINT.search(left):
+        return left
+    return right
+
+
+def _resource_attribute(operand: str, resource: str | None = None) -> str:
+    """Clause attribute of a tenant operand, e.g. expense.getTenantId() -> expense.tenant_id."""
+    attribute = _attribute(re.sub(r"\s+", "", operand).replace("$", ""))
+    segments = attribute.split(".")
+    # Short receivers (e, x) say less than the model the handler touches
+    owner = segments[-2] if len(segments) > 1 and len(segments[-2]) > 2 else resource or "resource"
+    return f"{owner.lower()}.{segments[-1]}"
+
+
+def tenant_checks(lines: list[str], start: int, end: int, resource: str | None = None) -> list[TenantCheck]:
+    """Tenant isolation checks in a handler and the decorator lines above it.
+
+    Args:
+        lines: File lines
+        start: Index of the handler's first line
+        end: Index just past its last line
+        resource: Model name used for checks that do not name the object, e.g. query filters
+
+    Returns:
+        Checks in line order
+    """
+    checks = []
+    first = start
+    while first > max(0, start - HEADER_LINES) and DECORATOR_LINE.match(lines[first - 1]):
+        first -= 1
+    for index in range(first, min(end, len(lines))):
+        line = lines[index]
+        if len(line) > MAX_LINE_LENGTH or not TENANT_NEEDLE.search(line):
+            continue
+        code = _short(line)
+        comparison = TENANT_COMPARISON.search(line)
+        query = DATA_ACCESS.search(line)
+        filtered = TENANT_FILTER.search(line) if query else None
+        if comparison and not filtered:
+            left, right = [g for g in comparison.groups() if g]
+            operand = _resource_side(left, right)
+            checks.append(TenantCheck(index + 1, "comparison", code, _resource_attribute(operand, resource)))
+        elif filtered:
+            checks.append(TenantCheck(index + 1, "query_filter", code, f"{(resource or 'resource').lower()}.{_attribute(filtered.group(1))}"))
+        elif TENANT_MIDDLEWARE.search(line):
+            checks.append(TenantCheck(index + 1, "middleware", code, f"{(resource or 'resource').lower()}.tenant_id"))
+        else:
+            # Objects stamped with the caller's tenant on creation: expense.TenantID = user.TenantID
+            assigned = re.search(rf"({OPERAND})\s*=(?![=>])\s*([^;\n]+)", line, re.IGNORECASE)
+            if assigned and SUBJECT_HINT.search(assigned.group(2)) and TENANT_NEEDLE.search(assigned.group(2)):
+                checks.append(TenantCheck(index + 1, "assignment", code, _resource_attribute(assigned.group(1), resource)))
+    return checks
+
+
+def tenant_resources(lines: list[str], start: int, end: int, models: dict[str, str]) -> list[str]:
+    """Tenant-owned models a handler reads or writes, by name or by SQL table.
+
+    Args:
+        lines: File lines
+        start: Index of the handler's first line
+        end: Index just past its last line
+        models: Tenant column of each tenant-owned model, by model name
+
+    Returns:
+        Model names in order of first use; empty unless the handler accesses data
+    """
+    if not models:
+        return []
+    body = [line for line in lines[start:end] if len(line) <= MAX_LINE_LENGTH]
+    text = "\n".join(body)
+    if not (DATA_ACCESS.search(text) or FETCH.search(text)):
+        return []
+    found = {}
+    # Expense in GetExpenseByID and models.Expense, or expense and expenses as variable names
+    spellings = {m: m for m in models} | {_attribute(m): m for m in models}
+    names = re.compile(r"(?<![A-Za-z])(" + "|".join(re.escape(m) for m in sorted(models, key=len, reverse=True)) + r")(?![a-z])|"
+                       r"\b(" + "|".join(re.escape(_attribute(m)) for m in models) + r")(?:e?s)?\b")
+    for line in body:
+        for match in names.finditer(line):
+            found.setdefault(spellings[match.group(1) or match.group(2)], None)
+        for table in SQL_TABLE.findall(line):
+            model = _singular(table.lower())
+            if model in models:
+                found.setdefault(model, None)
+    return list(found)
+
+
+class TenantIsolationService(AbacConditionService):
+    """Mines tenant isolation checks and reports endpoints touching tenant-owned models without one."""
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self._query(Repository)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @classmethod
+    def _models(cls, root: Path, files: dict[str, _SourceFile | None]) -> tuple[dict[str, str], set[str]]:
+        """Tenant column of each tenant-owned model in a clone, and files applying tenant middleware to all routes."""
+        models: dict[str, str] = {}
+        covered = set()
+        for path in sorted(root.rglob("*")):
+            relative = path.relative_to(root).as_posix()
+            if path.suffix not in ROUTE_FILE_EXTENSIONS or SKIPPED_DIRECTORIES & set(Path(relative).parts):
+                continue
+            source = cls._load(root, relative, files)
+            if source is None:
+                continue
+            text = "\n".join(source.lines)
+            if not TENANT_NEEDLE.search(text):
+                continue
+            for model in parse_models(relative, text, keep=_tenant_field):
+                models.setdefault(model.name, (model.fields or model.excluded)[0])
+            if FILE_MIDDLEWARE.search(text):
+                covered.add(relative)
+        return models, covered
+
+    def scan_repository(self, repository: Repository, root: Path) -> tuple[list[dict], list[TenantGap]]:
+        """Tenant isolation constraints and gaps of one cloned repository's endpoints."""
+        policies = self.db.query(Policy).filter(Policy.repository_id == repository.id).all()
+        if not policies:
+            return [], []
+
+        endpoints: dict[str, dict] = {}
+        located: dict[str, list[dict]] = {}
+        for policy in policies:
+            rule = EndpointMappingService.map_policy(policy)
+            endpoint = endpoints.setdefault(rule.key, {"rule": rule, "roles": set(), "policies": []})
+            endpoint["roles"] |= {r.upper() for r in rule.roles}
+            endpoint["policies"].append(policy)
+            located.setdefault(rule.key, []).append(self.located(policy))
+
+        accesses = iter(run_pass(root, tenant_accesses, endpoints=list(located.values())))
+        models = next(accesses)
+        if not models:
+            return [], []
+
+        constraints, gaps = [], []
+        for (key, endpoint), access in zip(endpoints.items(), accesses, strict=True):
+            if not access["touched"]:
+                continue
+            policy_ids = [p.id for p in endpoint["policies"]]
+            for file_path, function, found in access["found"]:
+                check = TenantCheck(**found)
+                constraints.append({
+                    "endpoint": key,
+                    "file_path": file_path,
+                    "function": function,
+                    **asdict(check),
+                    "condition": check.condition,
+                    "policy_ids": policy_ids,
+                })
+            if not access["found"]:
+                gaps.append(self._gap(key, endpoint, access["located"], access["touched"], models))
+        return constraints, gaps
+
+    @staticmethod
+    def _gap(key: str, endpoint: dict, located: list, touched: list[str], models: dict[str, str]) -> TenantGap:
+        """A gap for an endpoint whose handlers never tie the models they touch to the caller's tenant."""
+        file_path, start, end, function = located
+        rule = endpoint["rule"]
+        roles = endpoint["roles"]
+        # Platform administrators may act across tenants by design
+        cross_tenant = bool(roles) and all("ADMIN" in r for r in roles)
+        writes = rule.method in WRITE_METHODS
+        severity = "low" if cross_tenant else "critical" if not rule.requires_authentication else "high" if writes else "medium"
+        columns = ", ".join(f"{m}.{models[m]}" for m in touched)
+        description = (
+            f"{key} {'modifies' if writes else 'reads'} tenant-owned {', '.join(touched)} without comparing {columns} "
+            "to the caller's tenant or scoping the query to it"
+            + (", so callers of any tenant reach every tenant's objects" if not cross_tenant else " (restricted to administrators)")
+        )
+        return TenantGap(key, file_path, function, start + 1, end, touched, severity, description, [p.id for p in endpoint["policies"]])
+
+    def isolation(self, repository_id: int | None = None, severity: str | None = None) -> dict:
+        """Tenant isolation constraints and gaps across the tenant's repositories.
+
+        Args:
+            repository_id: Restrict to one repository
+            severity: Keep only gaps of this severity
+
+        Returns:
+            Constraints, gaps most severe first, and counts
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        constraints, gaps, scanned = [], [], 0
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            if not root.is_dir():
+                continue
+            scanned += 1
+            found, missing = self.scan_repository(repository, root)
+            logger.info("tenant_isolation_mined", repository_id=repository.id, constraints=len(found), gaps=len(missing))
+            constraints.extend({**c, "repository_id": repository.id} for c in found)
+            gaps.extend({**asdict(g), "repository_id": repository.id, "repository_name": repository.name} for g in missing)
+
+        if severity is not None:
+            gaps = [g for g in gaps if g["severity"] == severity.lower()]
+        gaps.sort(key=lambda g: (SEVERITY_ORDER[g["severity"]], g["repository_id"], g["file_path"], g["line_start"]))
+
+        by_severity: dict[str, int] = {}
+        for gap in gaps:
+            by_severity[gap["severity"]] = by_severity.get(gap["severity"], 0) + 1
+        return {
+            "constraints": constraints,
+            "gaps": gaps,
+            "summary": {
+                "constraints": len(constraints),
+                "protected_endpoints": len({(c["repository_id"], c["endpoint"]) for c in constraints}),
+                "gaps": len(gaps),
+                "repositories_scanned": scanned,
+                "by_severity": by_severity,
+            },
+        }
+
+    def apply_constraints(self, repository_id: int) -> dict:
+        """Add mined tenant isolation constraints to the conditions of their policies.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Counts of constraints and policies updated
+
+        Raises:
+            ValueError: If the repository does not exist or has not been cloned
+        """
+        repo = self.get_repository(repository_id)
+        root = self.clone_dir / str(repo.id)
+        if not root.is_dir():
+            raise ValueError(f"Repository {repository_id} has not been cloned yet; run a scan first")
+
+        constraints, _ = self.scan_repository(repo, root)
+        policies = {p.id: p for p in self.db.query(Policy).filter(Policy.repository_id == repo.id).all()}
+        updated = set()
+        for constraint in constraints:
+            clause = ConditionEvaluationService.parse_clause(constraint["condition"])
+            for policy_id in constraint["policy_ids"]:
+                policy = policies.get(policy_id)
+                if policy is None:
+                    continue
+                stated = ConditionEvaluationService.parse(policy.conditions)
+                if _clause_key(clause) in {_clause_key(c) for c in stated}:
+                    continue
+                policy.conditions = "; ".join([policy.conditions, constraint["condition"]] if stated else [constraint["condition"]])
+                if not any(e.file_path == constraint["file_path"] and e.line_start == constraint["line"] for e in policy.evidence):
+                    policy.evidence.append(
+                        Evidence(
+                            file_path=constraint["file_path"],
+                            line_start=constraint["line"],
+                            line_end=constraint["line"],
+                            code_snippet=constraint["code"],
+                        )
+                    )
+                updated.add(policy_id)
+        self.db.commit()
+
+        logger.info("tenant_constraints_applied", repository_id=repo.id, constraints=len(constraints), updated=len(updated), tenant_id=self.tenant_id)
+        return {"repository_id": repo.id, "constraints": len(constraints), "policies_updated": len(updated)}
+
+
+def tenant_accesses(root: str, endpoints: list[list[dict]]) -> Iterator[dict]:
+    """Sandbox pass yielding a clone's tenant-owned models, then the tenant-owned models each endpoint touches and checks.
+
+    Args:
+        root: Repository clone root
+        endpoints: Per endpoint, the route and evidence of each of its policies (see AbacConditionService.located)
+
+    Yields:
+        The tenant column of each tenant-owned model; unless there are none, then per endpoint, in order,
+        the models its handlers touch, [file path, handler function, check] of each check, and
+        [file path, start, end, handler function] of the first handler touching one
+    """
+    clone = Path(root)
+    files: dict[str, _SourceFile | None] = {}
+    index: dict = {}
+    models, covered = TenantIsolationService._models(clone, files)
+    yield models
+    if not models:
+        return
+    for policies in endpoints:
+        handlers = []
+        for policy in policies:
+            for handler in TenantIsolationService._located(clone, policy["route"], policy["evidence"], files, index):
+                if handler[:2] not in [h[:2] for h in handlers]:
+                    handlers.append(handler)
+        touched, found, located = [], [], None
+        for source, start, end in handlers:
+            resources = tenant_resources(source.lines, start, end, models)
+            if not resources:
+                continue
+            function = next((name for s, _, name in source.handlers if s == start), None)
+            located = located or [source.path, start, end, function]
+            touched.extend(r for r in resources if r not in touched)
+            checks = tenant_checks(source.lines, start, end, resources[0])
+            if not checks and source.path in covered:
+                checks = [TenantCheck(start + 1, "middleware", "tenant middleware applied to every route in the file", f"{resources[0].lower()}.tenant_id")]
+            found.extend([source.path, function, asdict(check)] for check in checks)
+        yield {"touched": touched, "found": found, "located": located}
@@ -0,0 +1,335 @@
+"""Track numeric condition thresholds across scans and flag loosenings.
+
+Raising an approval limit from $5,000 to $25,000 is a one-character diff
+that reads like routine maintenance in code review, yet it widens who can do
+what more than most role changes. Each scan records every numeric boundary
+in the mined conditions, keyed by the rule it belongs to (subject roles,
+resource, action, endpoint), the attribute it compares, and its direction,
+but not its value. When a boundary moves between scans in the direction that
+admits more requests, a threshold change finding is raised.
+
+Whether a move loosens depends on what the boundary guards. In a permit
+condition like "amount <= 5000" the allowed region is below the limit, so
+raising it loosens. In "amount > 5000 requires DIRECTOR" the region above the
+limit needs an extra role, so raising it loosens too, while lowering
+"account_age_days >= 30" loosens a permit the other way. Turning "<" into
+"<=" at the same value is a boundary loosening as well.
+"""
+
+import hashlib
+from dataclasses import dataclass
+from datetime import UTC, datetime
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.models.threshold import ThresholdChange, ThresholdChangeStatus, ThresholdObservation
+from app.services.condition_evaluation_service import ConditionClause, ConditionEvaluationService
+from app.services.decision_simulation_service import DecisionSimulationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.stable_identity_service import endpoint_id
+
+logger = structlog.get_logger(__name__)
+
+BOUNDARY_OPERATORS = {">", ">=", "<", "<="}
+
+# Relative move of a threshold that is high severity on its own
+LARGE_CHANGE_RATIO = 0.5
+
+
+@dataclass
+class Threshold:
+    """A numeric boundary in a policy's conditions."""
+
+    key: str
+    policy_id: int
+    resource: str | None
+    action: str | None
+    attribute: str
+    operator: str
+    value: float
+    required_role: str | None
+    clause: str
+
+    @property
+    def restricts(self) -> bool:
+        """Whether crossing the boundary adds a requirement rather than granting access."""
+        return self.required_role is not None
+
+
+def _region_above(operator: str) -> bool:
+    """Whether a comparison selects values above its boundary."""
+    return operator in (">", ">=")
+
+
+def threshold_key(policy: Policy, attribute: str, operator: str, required_role: str | None) -> str:
+    """Identity of a boundary across scans, independent of its value."""
+    rule = EndpointMappingService.map_policy(policy)
+    roles, _ = EndpointMappingService.parse_roles(policy.subject)
+    parts = [
+        ",".join(sorted(roles)),
+        (policy.resource or "").strip().casefold(),
+        (policy.action or "").strip().casefold(),
+        endpoint_id(rule.method, rule.path),
+        attribute,
+        "above" if _region_above(operator) else "below",
+        required_role or "",
+    ]
+    return hashlib.sha256(chr(31).join(parts).encode()).hexdigest()[:32]
+
+
+def extract_thresholds(policy: Policy) -> list[Threshold]:
+    """Numeric boundaries in a policy's conditions.
+
+    Args:
+        policy: Mined policy
+
+    Returns:
+        One threshold per numeric comparison, including the comparison of a
+        "... requires ROLE" clause
+    """
+    thresholds = []
+    for clause in ConditionEvaluationService.parse(policy.conditions):
+        comparison: ConditionClause | None = clause.inner if clause.kind == "implication" else clause
+        if comparison is None or comparison.kind != "comparison" or comparison.operator not in BOUNDARY_OPERATORS:
+            continue
+        if isinstance(comparison.value, bool) or not isinstance(comparison.value, int | float):
+            continue
+        role = clause.required_role if clause.kind == "implication" else None
+        thresholds.append(
+            Threshold(
+                key=threshold_key(policy, comparison.attribute, comparison.operator, role),
+                policy_id=policy.id,
+                resource=policy.resource,
+                action=policy.action,
+                attribute=comparison.attribute,
+                operator=comparison.operator,
+                value=float(comparison.value),
+                required_role=role,
+                clause=clause.raw,
+            )
+        )
+    return thresholds
+
+
+def loosened(before_operator: str, before_value: float, after: Threshold) -> bool | None:
+    """Whether a boundary move admits more requests.
+
+    Args:
+        before_operator: Operator previously observed
+        before_value: Value previously observed
+        after: Boundary as observed now
+
+    Returns:
+        True if loosened, False if tightened, None if unchanged
+    """
+    if (before_operator, before_value) == (after.operator, after.value):
+        return None
+    above = _region_above(after.operator)
+    if after.value != before_value:
+        # The compared region grows when an "above" boundary drops or a "below" boundary rises
+        grows = after.value < before_value if above else after.value > before_value
+    else:
+        grows = after.operator.endswith("=")
+    # A permit loosens when its region grows, a role requirement when its region shrinks
+    return grows != after.restricts
+
+
+def _format(attribute: str, operator: str, value: float, required_role: str | None) -> str:
+    number = int(value) if value.is_integer() else value
+    return f"{attribute} {operator} {number}" + (f" requires {required_role}" if required_role else "")
+
+
+def change_severity(before_value: float, after: Threshold) -> str:
+    """Severity of a loosening: boundary-only moves are low, large or approval limits high."""
+    if before_value == after.value:
+        return "low"
+    ratio = abs(after.value - before_value) / abs(before_value) if before_value else float("inf")
+    return "high" if after.restricts or ratio >= LARGE_CHANGE_RATIO else "medium"
+
+
+class ThresholdTrackingService:
+    """Records condition thresholds per scan and raises findings when they loosen."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def _require_repository(self, repository_id: int) -> None:
+        """Raise ValueError unless the tenant has the repository."""
+        if self._query(Repository).filter(Repository.id == repository_id).first() is None:
+            raise ValueError(f"Repository {repository_id} not found")
+
+    def current_thresholds(self, repository_id: int) -> list[Threshold]:
+        """Boundaries of a repository's policies, taking each from its newest policy."""
+        policies = DecisionSimulationService(self.db, self.tenant_id).load_policies(repository_id=repository_id)
+        latest: dict[str, Threshold] = {}
+        for policy in sorted(policies, key=lambda p: p.id):
+            for threshold in extract_thresholds(policy):
+                latest[threshold.key] = threshold
+        return list(latest.values())
+
+    def _observations(self, repository_id: int) -> list[ThresholdObservation]:
+        """All observations of a repository, oldest first."""
+        return (
+            self._query(ThresholdObservation)
+            .filter(ThresholdObservation.repository_id == repository_id)
+            .order_by(ThresholdObservation.id.asc())
+            .all()
+        )
+
+    def record(self, repository_id: int, scan_id: int | None = None) -> list[ThresholdChange]:
+        """Record a scan's thresholds and raise findings for loosened ones.
+
+        Args:
+            repository_id: Repository that was just scanned
+            scan_id: The scan
+
+        Returns:
+            Threshold change findings raised by this scan
+        """
+        previous: dict[str, ThresholdObservation] = {}
+        for observation in self._observations(repository_id):
+            previous[observation.threshold_key] = observation
+
+        changes = []
+        for threshold in self.current_thresholds(repository_id):
+            before = previous.get(threshold.key)
+            if before is not None and loosened(before.operator, before.value, threshold):
+                old = _format(before.attribute, before.operator, before.value, before.required_role)
+                new = _format(threshold.attribute, threshold.operator, threshold.value, threshold.required_role)
+                change = ThresholdChange(
+                    tenant_id=self.tenant_id,
+                    repository_id=repository_id,
+                    scan_id=scan_id,
+                    threshold_key=threshold.key,
+                    previous_policy_id=before.policy_id,
+                    policy_id=threshold.policy_id,
+                    resource=threshold.resource,
+                    action=threshold.action,
+                    attribute=threshold.attribute,
+                    required_role=threshold.required_role,
+                    before_operator=before.operator,
+                    before_value=before.value,
+                    after_operator=threshold.operator,
+                    after_value=threshold.value,
+                    description=f"Threshold loosened on {threshold.action} {threshold.resource}: {old} became {new}",
+                    severity=change_severity(before.value, threshold),
+                    status=ThresholdChangeStatus.PENDING,
+                )
+                self.db.add(change)
+                changes.append(change)
+            self.db.add(
+                ThresholdObservation(
+                    tenant_id=self.tenant_id,
+                    repository_id=repository_id,
+                    scan_id=scan_id,
+                    policy_id=threshold.policy_id,
+                    threshold_key=threshold.key,
+                    resource=threshold.resource,
+                    action=threshold.action,
+                    attribute=threshold.attribute,
+                    operator=threshold.operator,
+                    value=threshold.value,
+                    required_role=threshold.required_role,
+                    clause=threshold.clause,
+                )
+            )
+        self.db.commit()
+        logger.info("thresholds_recorded", repository_id=repository_id, scan_id=scan_id, loosened=len(changes))
+        return changes
+
+    def history(self, repository_id: int) -> list[dict]:
+        """How each threshold of a repository moved over time.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Per threshold: what it guards, its current boundary, and the scans
+            at which its value or operator changed, most recently changed first
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        self._require_repository(repository_id)
+        timelines: dict[str, list[ThresholdObservation]] = {}
+        for observation in self._observations(repository_id):
+            timeline = timelines.setdefault(observation.threshold_key, [])
+            if not timeline or (timeline[-1].operator, timeline[-1].value) != (observation.operator, observation.value):
+                timeline.append(observation)
+
+        results = []
+        for key, timeline in timelines.items():
+            current = timeline[-1]
+            results.append(
+                {
+                    "threshold_key": key,
+                    "resource": current.resource,
+                    "action": current.action,
+                    "attribute": current.attribute,
+                    "required_role": current.required_role,
+                    "operator": current.operator,
+                    "value": current.value,
+                    "clause": current.clause,
+                    "history": [
+                        {
+                            "scan_id": o.scan_id,
+                            "policy_id": o.policy_id,
+                            "operator": o.operator,
+                            "value": o.value,
+                            "observed_at": o.observed_at,
+                        }
+                        for o in timeline
+                    ],
+                }
+            )
+        results.sort(key=lambda r: -max(o["scan_id"] or 0 for o in r["history"]))
+        return results
+
+    def list_changes(
+        self, repository_id: int | None = None, status: ThresholdChangeStatus | None = None
+    ) -> list[ThresholdChange]:
+        """Threshold change findings, newest first."""
+        query = self._query(ThresholdChange)
+        if repository_id is not None:
+            query = query.filter(ThresholdChange.repository_id == repository_id)
+        if status is not None:
+            query = query.filter(ThresholdChange.status == status)
+        return query.order_by(ThresholdChange.id.desc()).all()
+
+    def update_status(
+        self,
+        change_id: int,
+        status: ThresholdChangeStatus,
+        resolution_notes: str | None = None,
+        resolved_by: str | None = None,
+    ) -> ThresholdChange:
+        """Review a threshold change finding.
+
+        Raises:
+            ValueError: If the finding does not exist
+        """
+        change = self._query(ThresholdChange).filter(ThresholdChange.id == change_id).first()
+        if change is None:
+            raise ValueError(f"Threshold change {change_id} not found")
+        change.status = status
+        change.resolution_notes = resolution_notes
+        if status == ThresholdChangeStatus.PENDING:
+            change.resolved_by, change.resolved_at = None, None
+        else:
+            change.resolved_by, change.resolved_at = resolved_by, datetime.now(UTC)
+        self.db.commit()
+        self.db.refresh(change)
+        logger.info("threshold_change_reviewed", change_id=change_id, status=status.value)
+        return change
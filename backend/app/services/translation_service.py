@@ -145,6 +145,8 @@ class TranslationService:
             "conditions": policy.conditions,
             "description": policy.description,
             "source_type": policy.source_type.value if policy.source_type else "unknown",
+            "extraction_method": policy.extraction_method.value if policy.extraction_method else None,
+            "confidence_score": policy.confidence_score,
         }
 
         return json.dumps(json_policy, indent=2)
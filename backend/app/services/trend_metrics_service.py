@@ -0,0 +1,290 @@
+"""Service for historical trend metrics.
+
+A snapshot of aggregate metrics (policy counts, coverage KPIs, open
+findings) is stored each time a scan completes, so the dashboard can chart
+coverage over time. Findings opened and closed per week are computed from
+the finding tables' own timestamps, which also covers findings raised
+between scans.
+"""
+
+from collections import defaultdict
+from datetime import UTC, date, datetime, timedelta
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.conflict import ConflictStatus, PolicyConflict
+from app.models.inconsistent_enforcement import InconsistentEnforcement, InconsistentEnforcementStatus
+from app.models.policy import Policy, PolicyStatus, RiskLevel
+from app.models.policy_fix import FixSeverity, FixStatus, PolicyFix
+from app.models.scan_metrics import ScanMetricsSnapshot
+from app.models.secret_detection import SecretDetectionLog
+from app.services.coverage_metrics_service import CoverageMetricsService
+
+logger = structlog.get_logger(__name__)
+
+
+class TrendInterval:
+    """Time-series bucket sizes."""
+
+    DAY = "day"
+    WEEK = "week"
+
+
+FINDING_TYPES = ["conflicts", "inconsistent_enforcement", "security_gaps", "secrets"]
+
+
+def bucket_start(timestamp: datetime, interval: str = TrendInterval.WEEK) -> date:
+    """Return the first day of the bucket containing a timestamp.
+
+    Args:
+        timestamp: Point in time
+        interval: day or week (weeks start on Monday)
+
+    Returns:
+        Bucket start date
+    """
+    day = timestamp.date()
+    if interval == TrendInterval.WEEK:
+        return day - timedelta(days=day.weekday())
+    return day
+
+
+def _aware(timestamp: datetime) -> datetime:
+    """Treat naive timestamps (legacy columns) as UTC."""
+    return timestamp if timestamp.tzinfo else timestamp.replace(tzinfo=UTC)
+
+
+class TrendMetricsService:
+    """Captures per-scan snapshots and serves time series."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query a model scoped to the tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def open_findings(self, repository_id: int | None = None) -> dict[str, int]:
+        """Count currently open findings by type.
+
+        Args:
+            repository_id: Restrict repository-scoped findings to one repository
+
+        Returns:
+            Open finding counts
+        """
+        conflicts = self._query(PolicyConflict).filter(PolicyConflict.status == ConflictStatus.PENDING)
+        gaps = self._query(PolicyFix).filter(PolicyFix.status.in_([FixStatus.PENDING, FixStatus.REVIEWED]))
+        secrets = self._query(SecretDetectionLog)
+        if repository_id is not None:
+            conflicts = conflicts.join(Policy, PolicyConflict.policy_a_id == Policy.id).filter(
+                Policy.repository_id == repository_id
+            )
+            gaps = gaps.join(Policy, PolicyFix.policy_id == Policy.id).filter(Policy.repository_id == repository_id)
+            secrets = secrets.filter(SecretDetectionLog.repository_id == repository_id)
+
+        return {
+            "conflicts": conflicts.count(),
+            # Cross-application findings are workspace-wide
+            "inconsistent_enforcement": self._query(InconsistentEnforcement)
+            .filter(
+                InconsistentEnforcement.status.in_(
+                    [InconsistentEnforcementStatus.PENDING, InconsistentEnforcementStatus.ACKNOWLEDGED]
+                )
+            )
+            .count(),
+            "security_gaps": gaps.count(),
+            "secrets": secrets.count(),
+        }
+
+    def critical_findings(self, repository_id: int) -> int:
+        """Count a repository's open findings of critical severity.
+
+        Args:
+            repository_id: Repository ID
+
+        Returns:
+            Open critical conflicts and security gaps on the repository's policies
+        """
+        conflicts = (
+            self._query(PolicyConflict)
+            .filter(PolicyConflict.status == ConflictStatus.PENDING)
+            .filter(PolicyConflict.severity == "critical")
+            .join(Policy, PolicyConflict.policy_a_id == Policy.id)
+            .filter(Policy.repository_id == repository_id)
+        )
+        gaps = (
+            self._query(PolicyFix)
+            .filter(PolicyFix.status.in_([FixStatus.PENDING, FixStatus.REVIEWED]))
+            .filter(PolicyFix.severity == FixSeverity.CRITICAL)
+            .join(Policy, PolicyFix.policy_id == Policy.id)
+            .filter(Policy.repository_id == repository_id)
+        )
+        return conflicts.count() + gaps.count()
+
+    def capture_snapshot(self, repository_id: int, scan_id: int | None = None) -> ScanMetricsSnapshot:
+        """Store aggregate metrics for a repository after a scan.
+
+        Args:
+            repository_id: Repository ID
+            scan_id: Completed scan ID
+
+        Returns:
+            The stored snapshot
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        coverage = CoverageMetricsService(self.db, self.tenant_id).repository_coverage(repository_id)
+        policies = self._query(Policy).filter(Policy.repository_id == repository_id)
+
+        snapshot = ScanMetricsSnapshot(
+            tenant_id=self.tenant_id,
+            repository_id=repository_id,
+            scan_id=scan_id,
+            total_policies=policies.count(),
+            approved_policies=policies.filter(Policy.status == PolicyStatus.APPROVED).count(),
+            pending_policies=policies.filter(Policy.status == PolicyStatus.PENDING).count(),
+            high_risk_policies=policies.filter(Policy.risk_level == RiskLevel.HIGH).count(),
+            total_endpoints=coverage["total_endpoints"],
+            authenticated_endpoints=coverage["authenticated_endpoints"],
+            authorized_endpoints=coverage["authorized_endpoints"],
+            conditional_endpoints=coverage["conditional_endpoints"],
+            authenticated_percent=coverage["authenticated_percent"],
+            authorized_percent=coverage["authorized_percent"],
+            conditional_percent=coverage["conditional_percent"],
+            unknown_patterns=coverage["unknown_patterns"],
+            open_findings=self.open_findings(repository_id),
+            critical_findings=self.critical_findings(repository_id),
+        )
+        self.db.add(snapshot)
+        self.db.commit()
+
+        logger.info("scan_metrics_captured", repository_id=repository_id, scan_id=scan_id)
+        return snapshot
+
+    def coverage_series(
+        self,
+        repository_id: int | None = None,
+        interval: str = TrendInterval.WEEK,
+        since: datetime | None = None,
+    ) -> list[dict]:
+        """Coverage over time, one point per bucket.
+
+        Within a bucket each repository contributes its latest snapshot;
+        workspace points sum endpoint counts across repositories and derive
+        percentages from the sums.
+
+        Args:
+            repository_id: Restrict to one repository (workspace if omitted)
+            interval: day or week
+            since: Earliest capture time to include
+
+        Returns:
+            Points sorted by bucket
+        """
+        query = self._query(ScanMetricsSnapshot)
+        if repository_id is not None:
+            query = query.filter(ScanMetricsSnapshot.repository_id == repository_id)
+        if since is not None:
+            query = query.filter(ScanMetricsSnapshot.captured_at >= since)
+        snapshots = query.order_by(ScanMetricsSnapshot.captured_at).all()
+
+        # Latest snapshot per repository per bucket (ordered query: later wins)
+        buckets: dict[date, dict[int, ScanMetricsSnapshot]] = defaultdict(dict)
+        for snapshot in snapshots:
+            buckets[bucket_start(_aware(snapshot.captured_at), interval)][snapshot.repository_id] = snapshot
+
+        points = []
+        for start in sorted(buckets):
+            latest = list(buckets[start].values())
+            totals = CoverageMetricsService.summarize(
+                total=sum(s.total_endpoints for s in latest),
+                authenticated=sum(s.authenticated_endpoints for s in latest),
+                authorized=sum(s.authorized_endpoints for s in latest),
+                conditional=sum(s.conditional_endpoints for s in latest),
+            )
+            points.append(
+                {
+                    "bucket": start.isoformat(),
+                    "repositories": len(latest),
+                    "total_policies": sum(s.total_policies for s in latest),
+                    "unknown_patterns": sum(s.unknown_patterns for s in latest),
+                    **totals,
+                }
+            )
+        return points
+
+    def _timestamps(self, model, column, since: datetime, *criteria) -> list[datetime]:
+        """Fetch non-null timestamps at or after a point in time."""
+        query = self._query(model).filter(column.isnot(None)).filter(column >= since)
+        for criterion in criteria:
+            query = query.filter(criterion)
+        return [_aware(row[0]) for row in query.with_entities(column).all()]
+
+    def findings_per_week(self, weeks: int = 12, now: datetime | None = None) -> list[dict]:
+        """Findings opened and closed per week.
+
+        Args:
+            weeks: Number of weeks to report, ending with the current week
+            now: Reference time (defaults to the current time)
+
+        Returns:
+            One entry per week with opened/closed counts by finding type
+        """
+        now = now or datetime.now(UTC)
+        first_week = bucket_start(now) - timedelta(weeks=weeks - 1)
+        since = datetime.combine(first_week, datetime.min.time(), tzinfo=UTC)
+
+        events = {
+            "conflicts": (
+                self._timestamps(PolicyConflict, PolicyConflict.created_at, since),
+                self._timestamps(PolicyConflict, PolicyConflict.resolved_at, since),
+            ),
+            "inconsistent_enforcement": (
+                self._timestamps(InconsistentEnforcement, InconsistentEnforcement.created_at, since),
+                self._timestamps(InconsistentEnforcement, InconsistentEnforcement.resolved_at, since),
+            ),
+            "security_gaps": (
+                self._timestamps(PolicyFix, PolicyFix.created_at, since),
+                self._timestamps(
+                    PolicyFix,
+                    PolicyFix.reviewed_at,
+                    since,
+                    PolicyFix.status.in_([FixStatus.APPLIED, FixStatus.REJECTED]),
+                ),
+            ),
+            # Detected secrets have no resolution workflow
+            "secrets": (self._timestamps(SecretDetectionLog, SecretDetectionLog.detected_at, since), []),
+        }
+
+        series = {
+            first_week + timedelta(weeks=i): {
+                "opened": dict.fromkeys(FINDING_TYPES, 0),
+                "closed": dict.fromkeys(FINDING_TYPES, 0),
+            }
+            for i in range(weeks)
+        }
+        for finding_type, (opened, closed) in events.items():
+            for kind, timestamps in (("opened", opened), ("closed", closed)):
+                for timestamp in timestamps:
+                    week = bucket_start(timestamp)
+                    if week in series:
+                        series[week][kind][finding_type] += 1
+
+        return [
+            {
+                "week": week.isoformat(),
+                "opened": counts["opened"],
+                "closed": counts["closed"],
+                "total_opened": sum(counts["opened"].values()),
+                "total_closed": sum(counts["closed"].values()),
+            }
+            for week, counts in sorted(series.items())
+        ]
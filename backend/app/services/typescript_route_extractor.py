@@ -0,0 +1,936 @@
+"""Extract type-level route authorization from Express and controller-based TypeScript backends.
+
+TypeScript services often move authorization into the type system: guard
+factories are generic over a role or permission type (requireRole<Role.Admin>(),
+authorize<'orders:write'>()), their arguments are enum members or `as const`
+object keys rather than string literals, middleware is declared once with a
+typed annotation (const adminOnly: RoleGuard<Role.Admin> = ...), and
+controller classes carry the requirement as decorator metadata
+(@Authorized(Role.Admin), @Security('jwt', [Scope.OrdersRead])). Matching
+middleware names alone misses all of these. This extractor builds a
+workspace-wide type environment (enums, const objects, literal unions, type
+aliases, and the signatures of auth utilities) and resolves each route's
+middleware and decorators through it, producing one ConfigFinding per
+guarded route without LLM calls. Fastify applications are analyzed by
+fastify_route_extractor, which resolves their guards through the same
+environment.
+
+Scope change: resolution was meant to go through the TypeScript compiler
+API or tsserver. This extractor does not run the compiler; clones are
+analyzed without installing their dependencies and the scanner image ships
+no Node runtime, so it reads the project's own sources instead. Types the
+compiler would resolve from dependencies, and conditional or mapped types,
+are not evaluated: a guard taking such a value is reported as unresolved
+rather than guessed. Running the checker remains open.
+"""
+
+import re
+from dataclasses import dataclass, field
+from functools import lru_cache
+from pathlib import PurePosixPath
+
+from app.services.config_policy_extractor import ConfigFinding, role_parameter
+from app.services.extractor_utils import group
+from app.services.node_route_extractor import (
+    AUTHENTICATION_MIDDLEWARE,
+    ROLE_MIDDLEWARE,
+    _source,
+    _Source,
+    join_paths,
+    statement_end,
+)
+
+# Alias and type chains longer than this are treated as unresolvable
+MAX_RESOLVE_DEPTH = 8
+
+# Type argument lists longer than this are taken for comparisons, not generics
+MAX_TYPE_ARGUMENTS = 2000
+
+TS_SUFFIXES = (".ts", ".tsx", ".mts", ".cts")
+
+# Files importing none of these are only read for the type environment
+EXPRESS_IMPORT = re.compile(r"(?:from\s+|require\s*\(\s*)['\"]express['\"]")
+CONTROLLER_IMPORT = re.compile(r"(?:from\s+|require\s*\(\s*)['\"](?:routing-controllers|tsoa)['\"]")
+
+MASKED_RUN = re.compile(r"x+")
+IDENTIFIER = r"[A-Za-z_$][\w$]*"
+ENUM = re.compile(rf"\benum\s+({IDENTIFIER})\s*\{{")
+TYPE_ALIAS = re.compile(rf"\btype\s+({IDENTIFIER})\s*(<)?")
+ALIAS_EQUALS = re.compile(r"\s*=\s*")
+CONTINUATION = re.compile(r"\s*[|&]")
+# Annotations containing "=" (function types) are not captured
+DECLARATION = re.compile(rf"\b(?:const|let|var)\s+({IDENTIFIER})\s*(?::\s*([^=;\n]+?))?\s*=(?![=>])\s*")
+FUNCTION = re.compile(rf"\bfunction\s+({IDENTIFIER})\s*")
+NAMED_IMPORT = re.compile(r"\bimport\s+(?:type\s+)?\{([^}]*)\}\s*from\b")
+ARROW_HEAD = re.compile(r"^(?:async\s+)?(?=[<(])")
+ARROW_BODY = re.compile(r"^\s*(?::\s*[^=]+?)?\s*=>\s*")
+CALL_HEAD = re.compile(rf"^({IDENTIFIER}(?:\s*\.\s*{IDENTIFIER})*)\s*(?=[<(])")
+DOTTED = re.compile(rf"^{IDENTIFIER}(?:\s*\.\s*{IDENTIFIER})*$")
+TYPE_REFERENCE = re.compile(rf"^({IDENTIFIER}(?:\.{IDENTIFIER})*)\s*(<)?")
+TYPEOF_VALUES = re.compile(rf"^\(?\s*typeof\s+({IDENTIFIER})\s*\)?\s*\[\s*keyof\s+typeof\s+\1\s*\]$")
+KEYOF_TYPEOF = re.compile(rf"^keyof\s+typeof\s+({IDENTIFIER})$")
+ARRAY_TYPE = re.compile(r"^(?:ReadonlyArray|Array)\s*<(.*)>$", re.DOTALL)
+AS_SUFFIX = re.compile(r"(?<!\s)\s+(?:as|satisfies)\s+[^,)\]]+$")
+
+EXPRESS_RECEIVER = re.compile(r"(?:express\s*\(|(?:express\s*\.\s*)?Router\s*\()")
+TYPED_RECEIVER = re.compile(rf"(?<![\w$])({IDENTIFIER})\s*:\s*(?:express\s*\.\s*)?(Router|Express|Application|FastifyInstance)\b")
+ROUTE_CALL = re.compile(
+    rf"(?<![\w$.])({IDENTIFIER})\s*\.\s*(get|post|put|patch|delete|options|head|all|use|route)\s*(?=[<(])"
+)
+DECORATOR = re.compile(rf"@({IDENTIFIER}(?:\.{IDENTIFIER})*)\s*")
+CLASS = re.compile(rf"\bclass\s+{IDENTIFIER}[^{{;]*\{{")
+CLASS_MODIFIERS = re.compile(r"\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?")
+MEMBER = re.compile(rf"(?:(?:public|private|protected|static|async|readonly|override)\s+)*({IDENTIFIER})\s*[<(]")
+
+# Type and function names that say what a guard's values are
+ROLE_TYPE = re.compile(r"role", re.IGNORECASE)
+PERMISSION_TYPE = re.compile(r"perm|scope|privilege|abilit|claim|^actions?$", re.IGNORECASE)
+
+HTTP_METHODS = {"get", "post", "put", "patch", "delete", "options", "head", "all"}
+CONTROLLER_DECORATORS = {"Controller", "JsonController", "Route"}
+AUTHORIZED_DECORATOR = "Authorized"  # routing-controllers: @Authorized() or @Authorized(roles)
+SECURITY_DECORATOR = "Security"  # tsoa: @Security(name, scopes)
+MIDDLEWARE_DECORATOR = "UseBefore"  # routing-controllers: middleware run before the action
+
+
+class TsRouteKind:
+    """Kinds of TypeScript route findings."""
+
+    EXPRESS_ROUTE = "express_route"
+    CONTROLLER_ROUTE = "ts_controller_route"
+
+
+class GuardKind:
+    """What the values a guard resolves to are."""
+
+    ROLE = "role"
+    PERMISSION = "permission"
+
+
+@dataclass
+class AuthUtility:
+    """A function whose signature says it checks roles or permissions."""
+
+    name: str
+    kind: str
+    type_params: list[tuple[str, str | None, str | None]]  # (name, constraint, default)
+    params: list[tuple[str | None, bool]]  # (type annotation, is rest parameter)
+
+
+@dataclass
+class TypeEnvironment:
+    """Values, types, and auth utilities declared across an application's sources."""
+
+    values: dict[str, list[str]] = field(default_factory=dict)  # "Role.Admin" -> ["admin"], "ADMIN" -> ["admin"]
+    keys: dict[str, list[str]] = field(default_factory=dict)  # const object -> its keys
+    enums: dict[str, list[str]] = field(default_factory=dict)  # enum -> every member value
+    types: dict[str, str] = field(default_factory=dict)  # type alias -> its type expression
+    utilities: dict[str, AuthUtility] = field(default_factory=dict)
+    aliases: dict[str, tuple[str, dict[str, str]]] = field(default_factory=dict)  # const -> (initializer, renames)
+    annotations: dict[str, str] = field(default_factory=dict)  # const -> declared type
+
+
+@dataclass
+class Guard:
+    """What one piece of middleware or one decorator requires."""
+
+    label: str
+    roles: list[str] = field(default_factory=list)
+    permissions: list[str] = field(default_factory=list)
+    unresolved: str | None = None
+    conditions: list[str] = field(default_factory=list)
+
+
+@dataclass
+class TsFile:
+    """A TypeScript file prepared for parsing."""
+
+    file_path: str
+    src: _Source
+    code: str  # Text with comments blanked and strings kept, aligned with src.masked
+    renames: dict[str, str]  # Local import alias -> imported name
+
+
+@lru_cache(maxsize=64)
+def prepare_file(file_path: str, text: str) -> TsFile:
+    """Mask a file once for the environment and route passes over it, and collect its renamed imports."""
+    src = _source(text)
+    # String contents are masked as "x"; restore them from the original text
+    code = MASKED_RUN.sub(lambda m: src.text[m.start() : m.end()], src.masked)
+    renames = {}
+    for match in NAMED_IMPORT.finditer(src.masked):
+        for specifier in match.group(1).split(","):
+            imported, _, local = specifier.replace("type ", "").partition(" as ")
+            if local.strip():
+                renames[local.strip()] = imported.strip()
+    return TsFile(file_path, src, code, renames)
+
+
+def _angle_end(text: str, start: int) -> int:
+    """Offset just past the ">" closing the type arguments opened at start; -1 if they do not close."""
+    depth = 0
+    for i in range(start, min(len(text), start + MAX_TYPE_ARGUMENTS)):
+        c = text[i]
+        if c == "<":
+            depth += 1
+        elif c == ">" and text[i - 1] != "=":
+            depth -= 1
+            if depth == 0:
+                return i + 1
+        elif c == ";":
+            return -1
+    return -1
+
+
+def split_top_level(text: str, separator: str) -> list[str]:
+    """Split a type or value expression at top-level separators."""
+    parts, depth, begin, quote = [], 0, 0, None
+    for i, c in enumerate(text):
+        if quote:
+            if c == quote and text[i - 1] != "\\":
+                quote = None
+            continue
+        if c in "'\"`":
+            quote = c
+        elif c in "<([{":
+            depth += 1
+        elif c in ")]}" or (c == ">" and text[i - 1 : i] != "="):
+            depth = max(0, depth - 1)
+        elif c == separator and depth == 0:
+            parts.append(text[begin:i])
+            begin = i + 1
+    parts.append(text[begin:])
+    return [p.strip() for p in parts if p.strip()]
+
+
+def string_literal(expression: str) -> str | None:
+    """Value of a plain string literal; None for anything else, including interpolated templates."""
+    expression = expression.strip()
+    if len(expression) >= 2 and expression[0] in "'\"`" and expression[-1] == expression[0]:
+        inner = expression[1:-1]
+        if expression[0] != "`" or "${" not in inner:
+            return inner
+    return None
+
+
+def dedupe(values: list[str]) -> list[str]:
+    """Values in first-seen order without repeats."""
+    return list(dict.fromkeys(values))
+
+
+def _base_type(type_text: str) -> str:
+    """The element type of an array type: "readonly R[]" and "Array<R>" give "R"."""
+    type_text = type_text.strip()
+    if type_text.startswith("readonly "):
+        type_text = type_text[len("readonly ") :].strip()
+    while type_text.endswith("[]"):
+        type_text = type_text[:-2].strip()
+    array = ARRAY_TYPE.match(type_text)
+    return array.group(1).strip() if array else type_text
+
+
+def _type_end(masked: str, start: int) -> int:
+    """End of a type alias body; unions and intersections may continue on following lines."""
+    depth = 0
+    for i in range(start, len(masked)):
+        c = masked[i]
+        if c in "([{<":
+            depth += 1
+        elif c in ")]}" or (c == ">" and masked[i - 1] != "="):
+            depth -= 1
+            if depth < 0:
+                return i
+        elif depth == 0 and c == ";":
+            return i
+        elif depth == 0 and c == "\n":
+            before = masked[start:i].rstrip()
+            if before and before[-1] not in "|&=" and not CONTINUATION.match(masked, i):
+                return i
+    return len(masked)
+
+
+def kind_of(names: list[str]) -> str | None:
+    """Guard kind named by the first type or function name that says what it checks."""
+    for name in names:
+        if ROLE_TYPE.search(name):
+            return GuardKind.ROLE
+        if PERMISSION_TYPE.search(name.rsplit(".", 1)[-1]):
+            return GuardKind.PERMISSION
+    return None
+
+
+def _type_params(text: str) -> list[tuple[str, str | None, str | None]]:
+    """Parse "<R extends Role = Role.Admin, T>" into (name, constraint, default) tuples."""
+    params = []
+    for part in split_top_level(text.strip()[1:-1], ","):
+        pieces = split_top_level(part, "=")
+        declaration, default = (pieces[0], pieces[1]) if len(pieces) == 2 else (part, None)
+        name, _, constraint = declaration.partition(" extends ")
+        params.append((name.strip(), constraint.strip() or None, default))
+    return params
+
+
+def _params(text: str) -> list[tuple[str | None, bool]]:
+    """Parse a parameter list into (type annotation, is rest) tuples."""
+    params = []
+    for part in split_top_level(text, ","):
+        declaration = split_top_level(part, "=")[0] if split_top_level(part, "=") else part
+        _, _, annotation = declaration.partition(":")
+        params.append((annotation.strip() or None, part.startswith("...")))
+    return params
+
+
+def _collect(ts: TsFile, env: TypeEnvironment) -> None:
+    """Add a file's enums, consts, type aliases, and auth utilities to the environment."""
+    src, code = ts.src, ts.code
+
+    for match in ENUM.finditer(src.masked):
+        name, open_brace = match.group(1), match.end() - 1
+        members = []
+        for s, e in src.split(open_brace + 1, src.pairs.get(open_brace, len(code)) - 1):
+            member, _, value = code[s:e].partition("=")
+            member = member.strip().strip("'\"")
+            literal = string_literal(value) if value else None
+            member_value = literal if literal is not None else member
+            env.values.setdefault(f"{name}.{member}", [member_value])
+            members.append(member_value)
+        env.enums.setdefault(name, members)
+
+    for match in TYPE_ALIAS.finditer(src.masked):
+        start = match.end()
+        if match.group(2):
+            start = _angle_end(src.masked, match.end() - 1)
+            if start < 0:
+                continue
+        equals = ALIAS_EQUALS.match(src.masked, start)
+        if equals:
+            start = equals.end()
+            env.types.setdefault(match.group(1), code[start : _type_end(src.masked, start)].strip())
+
+    for match in DECLARATION.finditer(src.masked):
+        name, start = match.group(1), match.end()
+        end = statement_end(src, start)
+        if match.group(2):
+            env.annotations.setdefault(name, code[match.start(2) : match.end(2)].strip())
+        if src.masked[start : start + 1] == "{":
+            close = src.pairs.get(start, end)
+            if not AS_SUFFIX.sub("", " " + code[close:end].strip()).strip():
+                keys = []
+                for key, (s, e) in src.entries(start, close).items():
+                    value = string_literal(AS_SUFFIX.sub("", code[s:e].strip()))
+                    if value is not None:
+                        env.values.setdefault(f"{name}.{key}", [value])
+                        keys.append(key)
+                if keys:
+                    env.keys.setdefault(name, keys)
+                    env.values.setdefault(name, [env.values[f"{name}.{key}"][0] for key in keys])
+                continue
+        expression = AS_SUFFIX.sub("", code[start:end].strip())
+        literal = string_literal(expression)
+        if literal is not None:
+            env.values.setdefault(name, [literal])
+            continue
+        if expression.startswith("[") and expression.endswith("]"):
+            items = [string_literal(item) for item in split_top_level(expression[1:-1], ",")]
+            if items and None not in items:
+                env.values.setdefault(name, items)
+                continue
+        arrow = ARROW_HEAD.match(src.masked[start:end])
+        if arrow:
+            _collect_function(ts, name, start + arrow.end(), end, env)
+        else:
+            env.aliases.setdefault(name, (expression, ts.renames))
+
+    for match in FUNCTION.finditer(src.masked):
+        _collect_function(ts, match.group(1), match.end(), len(code), env)
+
+
+def _collect_function(ts: TsFile, name: str, start: int, end: int, env: TypeEnvironment) -> None:
+    """Record a function as an auth utility if its signature names a role or permission type.
+
+    Arrow functions without parameters that return a call, such as
+    decorator shorthands (const AdminOnly = () => Authorized(Role.Admin)),
+    are recorded as aliases of the call instead.
+    """
+    src, code = ts.src, ts.code
+    type_params: list[tuple[str, str | None, str | None]] = []
+    i = start
+    if src.masked[i : i + 1] == "<":
+        close = _angle_end(src.masked, i)
+        if close < 0:
+            return
+        type_params = _type_params(code[i:close])
+        i = close
+    while i < end and src.masked[i].isspace():
+        i += 1
+    if src.masked[i : i + 1] != "(":
+        return
+    close = src.pairs.get(i, end)
+    params = _params(code[i + 1 : close - 1])
+    body = ARROW_BODY.match(src.masked[close:end])
+    if body and not params and not type_params:
+        returned = AS_SUFFIX.sub("", code[close + body.end() : end].strip())
+        if CALL_HEAD.match(returned):
+            env.aliases.setdefault(name, (returned, ts.renames))
+        return
+
+    bound = {param: _base_type(constraint) for param, constraint, _ in type_params if constraint}
+    type_names = [bound.get(_base_type(t), _base_type(t)) for t, _ in params if t] + list(bound.values())
+    kind = kind_of(type_names)
+    if kind is None and type_names and ROLE_MIDDLEWARE.search(f"{name}("):
+        kind = kind_of([name]) or GuardKind.PERMISSION
+    if kind is not None:
+        env.utilities.setdefault(name, AuthUtility(name, kind, type_params, params))
+
+
+def build_environment(files: dict[str, str]) -> TypeEnvironment:
+    """Collect the type environment of an application's TypeScript sources.
+
+    Args:
+        files: Relative path -> content
+
+    Returns:
+        Environment; the first declaration of a name, by path, wins
+    """
+    env = TypeEnvironment()
+    for file_path in sorted(files):
+        if PurePosixPath(file_path).suffix in TS_SUFFIXES:
+            _collect(prepare_file(file_path, files[file_path]), env)
+    return env
+
+
+def resolve_type(type_text: str, env: TypeEnvironment, depth: int = 0) -> list[str]:
+    """String values a type admits: literals, enum members, unions, aliases, and typeof lookups.
+
+    Args:
+        type_text: Type expression
+        env: Type environment of the application
+        depth: Alias chain depth so far
+
+    Returns:
+        Values in declaration order; empty when the type is not a finite set of strings
+    """
+    if depth > MAX_RESOLVE_DEPTH:
+        return []
+    values: list[str] = []
+    for part in split_top_level(type_text, "|"):
+        part = _base_type(part.strip())
+        literal = string_literal(part)
+        typeof_values = TYPEOF_VALUES.match(part)
+        keyof = KEYOF_TYPEOF.match(part)
+        reference = TYPE_REFERENCE.match(part)
+        if literal is not None:
+            values.append(literal)
+        elif typeof_values:
+            values.extend(env.values.get(typeof_values.group(1), []))
+        elif keyof:
+            values.extend(env.keys.get(keyof.group(1), []))
+        elif "." in part and part in env.values:
+            values.extend(env.values[part])
+        elif part in env.enums:
+            values.extend(env.enums[part])
+        elif part in env.types:
+            values.extend(resolve_type(env.types[part], env, depth + 1))
+        elif part.startswith("(") and part.endswith(")"):
+            values.extend(resolve_type(part[1:-1], env, depth + 1))
+        elif reference and reference.group(2):
+            # A generic wrapper such as Guard<Role.Admin> admits what its arguments do
+            close = _angle_end(part, reference.end() - 1)
+            for argument in split_top_level(part[reference.end() : close - 1] if close > 0 else "", ","):
+                values.extend(resolve_type(argument, env, depth + 1))
+    return dedupe(values)
+
+
+def resolve_value(expression: str, env: TypeEnvironment, renames: dict[str, str] | None = None) -> list[str] | None:
+    """String values of an argument expression: literals, arrays, spreads, enum members, and consts.
+
+    Returns:
+        Values, or None when part of the expression cannot be resolved statically
+    """
+    expression = AS_SUFFIX.sub("", expression.strip())
+    if expression.startswith("..."):
+        expression = expression[3:].strip()
+    literal = string_literal(expression)
+    if literal is not None:
+        return [literal]
+    if expression.startswith("[") and expression.endswith("]"):
+        values: list[str] = []
+        for item in split_top_level(expression[1:-1], ","):
+            resolved = resolve_value(item, env, renames)
+            if resolved is None:
+                return None
+            values.extend(resolved)
+        return values
+    if DOTTED.match(expression):
+        head, dot, rest = re.sub(r"\s+", "", expression).partition(".")
+        name = (renames or {}).get(head, head) + dot + rest
+        if name in env.values:
+            return list(env.values[name])
+    return None
+
+
+def argument_values(expression: str, env: TypeEnvironment, renames: dict[str, str]) -> list[str] | None:
+    """Values of a guard argument, or a parameter when it is read from configuration at runtime."""
+    values = resolve_value(expression, env, renames)
+    if values is None and (parameter := role_parameter(AS_SUFFIX.sub("", expression.strip()))):
+        return [parameter]
+    return values
+
+
+def with_label(label: str, guard: Guard) -> Guard:
+    """A resolved guard reported under the expression that referenced it."""
+    return Guard(label, guard.roles, guard.permissions, guard.unresolved, guard.conditions)
+
+
+def _guard(label: str, kind: str, values: list[str], unresolved: str | None = None) -> Guard:
+    """A guard requiring values of a kind."""
+    if kind == GuardKind.ROLE:
+        return Guard(label, roles=values, unresolved=unresolved)
+    return Guard(label, permissions=values, unresolved=unresolved)
+
+
+def _annotated_guard(name: str, label: str, env: TypeEnvironment) -> Guard | None:
+    """Guard declared through a const's type annotation, e.g. RoleGuard<Role.Admin>."""
+    annotation = env.annotations.get(name, "")
+    reference = TYPE_REFERENCE.match(annotation)
+    if not reference or not reference.group(2):
+        return None
+    close = _angle_end(annotation, reference.end() - 1)
+    arguments = split_top_level(annotation[reference.end() : close - 1] if close > 0 else "", ",")
+    values = dedupe([value for argument in arguments for value in resolve_type(argument, env)])
+    kind = kind_of([reference.group(1)] + arguments)
+    return _guard(label, kind, values) if kind and values else None
+
+
+def _apply_utility(
+    utility: AuthUtility,
+    label: str,
+    type_args: list[str],
+    args: list[str],
+    env: TypeEnvironment,
+    renames: dict[str, str],
+) -> Guard:
+    """Values an auth utility call requires, from its arguments, type arguments, or type defaults."""
+    explicit = {name: type_args[i] for i, (name, _, _) in enumerate(utility.type_params) if i < len(type_args)}
+    values: list[str] = []
+    runtime = False
+    for index, (annotation, rest) in enumerate(utility.params):
+        bound = args[index:] if rest else args[index : index + 1]
+        resolved = [argument_values(argument, env, renames) for argument in bound]
+        if any(r is None for r in resolved):
+            # A runtime value of a type parameter still narrows to the explicit type argument
+            runtime = runtime or _base_type(annotation or "") not in explicit
+            continue
+        values.extend(value for r in resolved for value in r)
+    if not values and not runtime:
+        for name, _, default in utility.type_params:
+            chosen = explicit.get(name) or default
+            if chosen:
+                values.extend(resolve_type(chosen, env))
+    if values:
+        return _guard(label, utility.kind, dedupe(values))
+    reason = "takes a value that cannot be resolved statically" if runtime else f"requires an unspecified {utility.kind}"
+    return _guard(label, utility.kind, [], f"{label} {reason}")
+
+
+def resolve_guard(
+    expression: str, env: TypeEnvironment, renames: dict[str, str] | None = None, depth: int = 0
+) -> Guard | None:
+    """Resolve a middleware expression or decorator call to what it requires.
+
+    Calls to auth utilities take their values from the arguments bound to
+    their typed parameters, explicit type arguments, or a type parameter's
+    default. Declared middleware is followed through its initializer and,
+    when that does not resolve, its type annotation. Other middleware falls
+    back to name heuristics.
+
+    Args:
+        expression: Middleware expression or decorator call, comments removed
+        env: Type environment of the application
+        renames: Import aliases of the file the expression is in
+        depth: Alias chain depth so far
+
+    Returns:
+        Guard, or None when the expression is not auth middleware
+    """
+    expression = expression.strip()
+    label = " ".join(expression.split())
+    renames = renames or {}
+    if depth > MAX_RESOLVE_DEPTH:
+        return None
+
+    call = CALL_HEAD.match(expression)
+    if call:
+        callee = re.sub(r"\s+", "", call.group(1))
+        head, dot, rest = callee.partition(".")
+        name = (renames.get(head, head) + dot + rest).rsplit(".", 1)[-1]
+        i, type_args = call.end(), []
+        if expression[i : i + 1] == "<":
+            close = _angle_end(expression, i)
+            if close < 0:
+                return None
+            type_args = split_top_level(expression[i + 1 : close - 1], ",")
+            i = close + len(expression[close:]) - len(expression[close:].lstrip())
+        args = split_top_level(expression[i + 1 : group(expression, i) - 1], ",") if expression[i : i + 1] == "(" else []
+        if name in env.utilities:
+            return _apply_utility(env.utilities[name], label, type_args, args, env, renames)
+        if name in env.aliases and not args and not type_args:
+            initializer, initializer_renames = env.aliases[name]
+            aliased = resolve_guard(initializer, env, initializer_renames, depth + 1)
+            if aliased:
+                return with_label(label, aliased)
+        if ROLE_MIDDLEWARE.search(f"{name}("):
+            values = [value for argument in type_args for value in resolve_type(argument, env)]
+            unresolved = None
+            for argument in args:
+                resolved = argument_values(argument, env, renames)
+                if resolved is None:
+                    unresolved = f"{label} takes a value that cannot be resolved statically"
+                else:
+                    values.extend(resolved)
+            return _guard(label, kind_of([name]) or GuardKind.PERMISSION, dedupe(values), unresolved)
+        return Guard(label) if AUTHENTICATION_MIDDLEWARE.search(label) else None
+
+    if DOTTED.match(expression):
+        name = renames.get(expression, expression)
+        aliased = None
+        if name in env.aliases:
+            initializer, initializer_renames = env.aliases[name]
+            aliased = resolve_guard(initializer, env, initializer_renames, depth + 1)
+        if aliased is None or aliased.unresolved:
+            annotated = _annotated_guard(name, label, env)
+            if annotated:
+                return annotated
+        if aliased:
+            return with_label(label, aliased)
+        if AUTHENTICATION_MIDDLEWARE.search(expression):
+            return Guard(label)
+    return None
+
+
+def call_args(ts: TsFile, open_at: int) -> tuple[list[tuple[int, int]], int]:
+    """Argument spans of the call whose type arguments or parenthesis start at open_at.
+
+    Returns:
+        (argument spans, offset just past the closing parenthesis), or ([], -1) if there is no call
+    """
+    src = ts.src
+    if src.masked[open_at : open_at + 1] == "<":
+        open_at = _angle_end(src.masked, open_at)
+        if open_at < 0:
+            return [], -1
+        while open_at < len(src.masked) and src.masked[open_at].isspace():
+            open_at += 1
+    if src.masked[open_at : open_at + 1] != "(":
+        return [], -1
+    close = src.pairs.get(open_at, len(src.masked))
+    return src.split(open_at + 1, close - 1), close
+
+
+def _guards(ts: TsFile, spans: list[tuple[int, int]], env: TypeEnvironment) -> list[Guard]:
+    """Resolve middleware spans, expanding arrays of middleware."""
+    guards = []
+    for s, e in spans:
+        if ts.src.masked[s] == "[" and ts.src.pairs.get(s) == e:
+            guards.extend(_guards(ts, ts.src.split(s + 1, e - 1), env))
+            continue
+        guard = resolve_guard(ts.code[s:e], env, ts.renames)
+        if guard:
+            guards.append(guard)
+    return guards
+
+
+def route_finding(
+    kind: str, ts: TsFile, span: tuple[int, int], method: str, path: str, guards: list[Guard], framework: str
+) -> ConfigFinding:
+    """One route finding from its resolved guards."""
+    roles = dedupe([role for g in guards for role in g.roles])
+    permissions = dedupe([permission for g in guards for permission in g.permissions])
+    conditions = [f"requires permission {p}" for p in permissions]
+    conditions += [c for g in guards for c in g.conditions] + [g.unresolved for g in guards if g.unresolved]
+    line_start, line_end, snippet = ts.src.snippet(*span)
+    return ConfigFinding(
+        kind=kind,
+        file_path=ts.file_path,
+        line_start=line_start,
+        line_end=line_end,
+        snippet=snippet,
+        subject=" or ".join(roles) if roles else "Authenticated users",
+        resource=path,
+        action=method,
+        conditions="; ".join(conditions) or None,
+        description=f"{framework} route guarded by {', '.join(g.label for g in guards)}",
+    )
+
+
+def route_receivers(ts: TsFile, constructor: re.Pattern, types: tuple[str, ...]) -> set[str]:
+    """Names bound to an app or router: constructed in the file or typed as one."""
+    src = ts.src
+    receivers = {m.group(1) for m in DECLARATION.finditer(src.masked) if constructor.match(src.masked, m.end())}
+    receivers |= {m.group(1) for m in TYPED_RECEIVER.finditer(src.masked) if m.group(2) in types}
+    return receivers
+
+
+@dataclass
+class _Use:
+    """Middleware added to an Express app or router with .use()."""
+
+    position: int
+    path: str
+    guards: list[Guard]
+
+
+def extract_express_routes(ts: TsFile, env: TypeEnvironment) -> list[ConfigFinding]:
+    """Express routes of one file with receiver-level and inline middleware resolved.
+
+    Middleware added with .use() guards routes registered on the same app or
+    router after it; routers mounted in the same file inherit the mounting
+    receiver's prefix and the middleware it had when they were mounted.
+    """
+    src = ts.src
+    receivers = route_receivers(ts, EXPRESS_RECEIVER, ("Router", "Express", "Application"))
+    uses: dict[str, list[_Use]] = {}
+    mounts: dict[str, tuple[str, str, int]] = {}  # router -> (parent, prefix, position)
+    routes = []
+    for match in ROUTE_CALL.finditer(src.masked):
+        receiver, verb = match.group(1), match.group(2)
+        if receiver not in receivers or verb == "route":
+            continue
+        args, close = call_args(ts, match.end())
+        if not args:
+            continue
+        path = src.literal(*args[0])
+        if verb == "use":
+            prefix = path if isinstance(path, str) else ""
+            rest = args[1:] if isinstance(path, str) else args
+            for s, e in rest:
+                if src.masked[s:e] in receivers:
+                    mounts.setdefault(src.masked[s:e], (receiver, prefix, match.start()))
+            guards = _guards(ts, [(s, e) for s, e in rest if src.masked[s:e] not in receivers], env)
+            if guards:
+                uses.setdefault(receiver, []).append(_Use(match.start(), prefix, guards))
+        elif isinstance(path, str) and path.startswith("/") and len(args) >= 2:
+            routes.append(((match.start(), close), receiver, verb, path, _guards(ts, args[1:-1], env)))
+
+    def stack(receiver: str, position: int, path: str, visiting: frozenset) -> tuple[str, list[Guard]]:
+        local = [
+            guard
+            for use in uses.get(receiver, [])
+            if use.position < position and path.startswith(use.path or "/")
+            for guard in use.guards
+        ]
+        if receiver not in mounts or receiver in visiting:
+            return "", local
+        parent, prefix, mounted_at = mounts[receiver]
+        parent_prefix, inherited = stack(parent, mounted_at, prefix or "/", visiting | {receiver})
+        return join_paths(parent_prefix, prefix), inherited + local
+
+    findings = []
+    for span, receiver, verb, path, inline in routes:
+        prefix, guards = stack(receiver, span[0], path, frozenset())
+        if guards + inline:
+            method = "*" if verb == "all" else verb.upper()
+            full_path = join_paths(prefix, path)
+            findings.append(route_finding(TsRouteKind.EXPRESS_ROUTE, ts, span, method, full_path, guards + inline, "Express"))
+    return findings
+
+
+@dataclass
+class Decorator:
+    """A decorator and the span of its expression."""
+
+    name: str
+    start: int  # The "@"
+    end: int
+    paren: int  # Offset of the argument list's "(", or -1
+
+
+def _decorator_at(ts: TsFile, position: int) -> Decorator | None:
+    """Parse the decorator starting at position."""
+    match = DECORATOR.match(ts.src.masked, position)
+    if not match:
+        return None
+    end = match.end()
+    if ts.src.masked[end : end + 1] == "<":
+        end = _angle_end(ts.src.masked, end)
+        if end < 0:
+            return None
+    paren = end if ts.src.masked[end : end + 1] == "(" else -1
+    if paren >= 0:
+        end = ts.src.pairs.get(paren, len(ts.src.masked))
+    return Decorator(match.group(1).rsplit(".", 1)[-1], position, end, paren)
+
+
+def _skip_space(masked: str, i: int, end: int) -> int:
+    """First non-whitespace offset at or after i."""
+    while i < end and masked[i].isspace():
+        i += 1
+    return i
+
+
+def class_decorators(ts: TsFile, class_start: int) -> list[Decorator]:
+    """The decorators stacked directly before a class declaration."""
+    masked = ts.src.masked
+    found = []
+    for match in DECORATOR.finditer(masked, max(0, class_start - MAX_TYPE_ARGUMENTS), class_start):
+        decorator = _decorator_at(ts, match.start())
+        if decorator:
+            found.append(decorator)
+    stack: list[Decorator] = []
+    cursor = class_start
+    for decorator in reversed(found):
+        if decorator.end > cursor or not CLASS_MODIFIERS.fullmatch(masked[decorator.end : cursor]):
+            break
+        stack.insert(0, decorator)
+        cursor = decorator.start
+    return stack
+
+
+def class_members(ts: TsFile, body_start: int, body_end: int):
+    """Class methods with their decorators, as (decorators, method span) in declaration order."""
+    masked, pairs = ts.src.masked, ts.src.pairs
+    pending: list[Decorator] = []
+    i = _skip_space(masked, body_start, body_end)
+    while i < body_end:
+        if masked[i] == "@":
+            decorator = _decorator_at(ts, i)
+            if decorator is None:
+                i += 1
+                continue
+            pending.append(decorator)
+            i = _skip_space(masked, decorator.end, body_end)
+            continue
+        member = MEMBER.match(masked, i)
+        if member and member.end() <= body_end:
+            j = member.end() - 1
+            if masked[j] == "<":
+                j = max(_angle_end(masked, j), j + 1)
+                j = _skip_space(masked, j, body_end)
+            j = pairs.get(j, body_end) if masked[j : j + 1] == "(" else j + 1
+            brace = masked.find("{", j, body_end)
+            semicolon = masked.find(";", j, body_end)
+            if brace < 0 or (0 <= semicolon < brace):
+                stop = body_end if semicolon < 0 else semicolon + 1
+            else:
+                stop = pairs.get(brace, body_end)
+            yield pending, (i, stop)
+            pending, i = [], _skip_space(masked, stop, body_end)
+            continue
+        # A property or anything else: skip to the end of its statement
+        pending = []
+        j = i
+        while j < body_end and masked[j] not in ";\n":
+            j = max(pairs.get(j, j + 1), j + 1) if masked[j] in "([{" else j + 1
+        i = _skip_space(masked, j + 1, body_end)
+
+
+def _metadata_guards(
+    label: str, name: str, args: list[str], env: TypeEnvironment, renames: dict[str, str], depth: int = 0
+) -> list[Guard] | None:
+    """Guards of a routing-controllers or tsoa decorator, or of a shorthand returning one; None for others."""
+    if name == AUTHORIZED_DECORATOR:
+        values = [argument_values(argument, env, renames) for argument in args]
+        if any(v is None for v in values):
+            return [Guard(label, unresolved=f"{label} takes a value that cannot be resolved statically")]
+        return [Guard(label, roles=dedupe([role for v in values for role in v]))]
+    if name == SECURITY_DECORATOR:
+        scopes = argument_values(args[1], env, renames) if len(args) > 1 else []
+        unresolved = f"{label} takes a value that cannot be resolved statically" if scopes is None else None
+        return [Guard(label, permissions=scopes or [], unresolved=unresolved)]
+    if name == MIDDLEWARE_DECORATOR:
+        items = [item for a in args for item in (split_top_level(a[1:-1], ",") if a.startswith("[") else [a])]
+        return [guard for item in items if (guard := resolve_guard(item, env, renames))]
+    if name in env.aliases and not args and depth < MAX_RESOLVE_DEPTH:
+        expression, alias_renames = env.aliases[name]
+        call = CALL_HEAD.match(expression)
+        if call and expression[call.end() : call.end() + 1] == "(":
+            callee = re.sub(r"\s+", "", call.group(1)).rsplit(".", 1)[-1]
+            alias_args = split_top_level(expression[call.end() + 1 : group(expression, call.end()) - 1], ",")
+            resolved = _metadata_guards(label, alias_renames.get(callee, callee), alias_args, env, alias_renames, depth + 1)
+            if resolved is not None:
+                return [with_label(label, guard) for guard in resolved]
+    return None
+
+
+def _decorator_guards(ts: TsFile, decorator: Decorator, env: TypeEnvironment) -> list[Guard]:
+    """Guards a routing-controllers, tsoa, or custom auth decorator declares."""
+    src, code = ts.src, ts.code
+    label = " ".join(code[decorator.start : decorator.end].split())
+    spans = src.split(decorator.paren + 1, decorator.end - 1) if decorator.paren >= 0 else []
+    name = ts.renames.get(decorator.name, decorator.name)
+    guards = _metadata_guards(label, name, [code[s:e] for s, e in spans], env, ts.renames)
+    if guards is not None:
+        return guards
+    guard = resolve_guard(code[decorator.start + 1 : decorator.end], env, ts.renames)
+    return [with_label(label, guard)] if guard else []
+
+
+def decorator_path(ts: TsFile, decorator: Decorator) -> str:
+    """The literal path argument of a controller or route decorator."""
+    if decorator.paren < 0:
+        return ""
+    spans = ts.src.split(decorator.paren + 1, decorator.end - 1)
+    value = ts.src.literal(*spans[0]) if spans else None
+    return value if isinstance(value, str) else ""
+
+
+def extract_controller_routes(ts: TsFile, env: TypeEnvironment) -> list[ConfigFinding]:
+    """Actions of routing-controllers and tsoa controllers with decorator metadata resolved.
+
+    Auth decorators on the class guard every action; those on an action add to them.
+    """
+    src = ts.src
+    findings = []
+    for match in CLASS.finditer(src.masked):
+        decorators = class_decorators(ts, match.start())
+        controller = next((d for d in decorators if d.name in CONTROLLER_DECORATORS), None)
+        if controller is None:
+            continue
+        base = decorator_path(ts, controller)
+        class_guards = [g for d in decorators if d is not controller for g in _decorator_guards(ts, d, env)]
+        open_brace = match.end() - 1
+        for method_decorators, span in class_members(ts, open_brace + 1, src.pairs.get(open_brace, len(src.masked)) - 1):
+            route = next((d for d in method_decorators if d.name.lower() in HTTP_METHODS), None)
+            if route is None:
+                continue
+            guards = class_guards + [
+                g for d in method_decorators if d is not route for g in _decorator_guards(ts, d, env)
+            ]
+            if guards:
+                method = "*" if route.name.lower() == "all" else route.name.upper()
+                path = join_paths(base, decorator_path(ts, route))
+                findings.append(
+                    route_finding(TsRouteKind.CONTROLLER_ROUTE, ts, (route.start, span[1]), method, path, guards, "Controller")
+                )
+    return findings
+
+
+def extract_typescript_routes(files: dict[str, str]) -> list[ConfigFinding]:
+    """Extract Express and controller route authorization from TypeScript sources.
+
+    Args:
+        files: Relative path -> content of the application's JS/TS files; only
+            TypeScript sources are read
+
+    Returns:
+        Route findings across all files
+    """
+    sources = {path: text for path, text in files.items() if is_typescript_source(path)}
+    env = build_environment(sources)
+    findings = []
+    for file_path in sorted(sources):
+        text = sources[file_path]
+        express, controllers = EXPRESS_IMPORT.search(text), CONTROLLER_IMPORT.search(text)
+        if not (express or controllers):
+            continue
+        ts = prepare_file(file_path, text)
+        if express:
+            findings.extend(extract_express_routes(ts, env))
+        if controllers:
+            findings.extend(extract_controller_routes(ts, env))
+    return findings
+
+
+def is_typescript_source(file_path: str) -> bool:
+    """Check whether a path is a TypeScript source file."""
+    return PurePosixPath(file_path).suffix in TS_SUFFIXES and not file_path.endswith(".d.ts")
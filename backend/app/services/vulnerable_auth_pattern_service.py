@@ -0,0 +1,599 @@
+"""Service for detecting known-vulnerable authentication patterns.
+
+A handful of authentication bugs recur across codebases in nearly the same
+shape: JWT verification that accepts unsigned tokens or skips the signature,
+tokens accepted without checking the audience they were issued for, secrets
+compared with an early-exit equality check, certificate verification turned
+off wholesale, and routes registered so that the check meant to guard them
+never runs for them. This service finds those shapes in a repository's clone
+and reports each occurrence as a finding mapped to the CWE weakness it is an
+instance of.
+"""
+
+import re
+from bisect import bisect_right
+from collections.abc import Iterator
+from dataclasses import asdict, dataclass
+from enum import Enum
+from pathlib import Path, PurePosixPath
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.core.config import settings
+from app.core.sandbox import run_pass
+from app.models.repository import Repository
+from app.services.coverage_metrics_service import ROUTE_FILE_EXTENSIONS, SKIPPED_DIRECTORIES
+from app.services.jvm_route_extractor import ANONYMOUS, JVM_SUFFIXES
+from app.services.node_route_extractor import JS_SUFFIXES, KOA_CALL, _middleware_auth, _source
+from app.services.spring_route_extractor import CHAIN_MARKER, _covers, parse_filter_chain
+
+logger = structlog.get_logger(__name__)
+
+# Files not mentioning any of these are skipped without further parsing
+PREFILTER = re.compile(
+    r"jwt|token|secret|verify|passw|signature|hmac|digest|api.?key|cert|ssl|tls|trust|rejectUnauthorized|"
+    r"Matchers|anyRequest|\.use\s*\(",
+    re.IGNORECASE,
+)
+
+# Lines longer than this are minified or generated and not checked for comparisons
+MAX_COMPARISON_LINE = 1000
+
+# Snippets show at most this many lines
+MAX_SNIPPET_LINES = 10
+
+SEVERITY_ORDER = {"critical": 0, "high": 1, "medium": 2, "low": 3}
+
+
+class PatternKind(str, Enum):
+    """Kinds of known-vulnerable authentication patterns."""
+
+    JWT_NONE_ALGORITHM = "jwt_none_algorithm"
+    JWT_SIGNATURE_NOT_VERIFIED = "jwt_signature_not_verified"
+    JWT_AUDIENCE_NOT_VALIDATED = "jwt_audience_not_validated"
+    TIMING_UNSAFE_COMPARISON = "timing_unsafe_comparison"
+    TLS_VERIFICATION_DISABLED = "tls_verification_disabled"
+    ROUTE_ORDER_BYPASS = "route_order_bypass"
+
+
+@dataclass(frozen=True)
+class Weakness:
+    """The weakness a pattern kind is an instance of, and how to fix it."""
+
+    kind: PatternKind
+    title: str
+    cwe: str
+    cwe_name: str
+    severity: str
+    remediation: str
+
+
+WEAKNESSES: dict[PatternKind, Weakness] = {
+    w.kind: w
+    for w in [
+        Weakness(
+            PatternKind.JWT_NONE_ALGORITHM,
+            "JWT verification accepts unsigned tokens",
+            "CWE-347",
+            "Improper Verification of Cryptographic Signature",
+            "critical",
+            "Pin the accepted algorithms to the one the issuer signs with and never allow \"none\".",
+        ),
+        Weakness(
+            PatternKind.JWT_SIGNATURE_NOT_VERIFIED,
+            "JWT signature is not verified",
+            "CWE-347",
+            "Improper Verification of Cryptographic Signature",
+            "critical",
+            "Verify the signature against the issuer's key before trusting any claim in the token.",
+        ),
+        Weakness(
+            PatternKind.JWT_AUDIENCE_NOT_VALIDATED,
+            "JWT audience is not validated",
+            "CWE-287",
+            "Improper Authentication",
+            "medium",
+            "Require the audience this service expects so tokens issued for other services are rejected.",
+        ),
+        Weakness(
+            PatternKind.TIMING_UNSAFE_COMPARISON,
+            "Secret compared with a non-constant-time equality check",
+            "CWE-208",
+            "Observable Timing Discrepancy",
+            "medium",
+            "Compare secrets with a constant-time function such as hmac.compare_digest, crypto.timingSafeEqual, "
+            "subtle.ConstantTimeCompare, or MessageDigest.isEqual.",
+        ),
+        Weakness(
+            PatternKind.TLS_VERIFICATION_DISABLED,
+            "TLS certificate verification is disabled",
+            "CWE-295",
+            "Improper Certificate Validation",
+            "high",
+            "Keep certificate and hostname verification on; trust a private CA by configuring its bundle instead.",
+        ),
+        Weakness(
+            PatternKind.ROUTE_ORDER_BYPASS,
+            "Route order lets requests past an authorization check",
+            "CWE-696",
+            "Incorrect Behavior Order",
+            "high",
+            "Register authentication before the routes it guards, and order matcher rules from most to least specific.",
+        ),
+    ]
+}
+
+# Patterns recognizable from a single match: (kind, lowercase needles the file must contain one of, regex, description)
+LINE_PATTERNS: list[tuple[PatternKind, tuple[str, ...], re.Pattern, str]] = [
+    (
+        PatternKind.JWT_NONE_ALGORITHM,
+        ("none",),
+        re.compile(r"""\balgorithms?\s*[=:]\s*\[?[^\]\n;]{0,200}?['"]none['"]""", re.IGNORECASE),
+        "JWT verification allows the \"none\" algorithm, so unsigned tokens are accepted",
+    ),
+    (
+        PatternKind.JWT_NONE_ALGORITHM,
+        ("none",),
+        re.compile(r"\b(?:SigningMethodNone|UnsafeAllowNoneSignatureType)\b"),
+        "golang-jwt accepts tokens signed with the none method",
+    ),
+    (
+        PatternKind.JWT_NONE_ALGORITHM,
+        ("algorithm.none", "plainjwt"),
+        re.compile(r"\bAlgorithm\.none\s*\(|\bPlainJWT\.parse\s*\("),
+        "Unsigned JWTs are created or parsed as valid tokens",
+    ),
+    (
+        PatternKind.JWT_NONE_ALGORITHM,
+        ("parseclaimsjwt", "parseplaintextjwt"),
+        re.compile(r"\.parse(?:Claims|Plaintext)Jwt\s*\("),
+        "jjwt parses the token as an unsigned JWT, so no signature is required",
+    ),
+    (
+        PatternKind.JWT_NONE_ALGORITHM,
+        ("requiresignedtokens",),
+        re.compile(r"\bRequireSignedTokens\s*=\s*false\b"),
+        "Token validation accepts tokens without a signature",
+    ),
+    (
+        PatternKind.JWT_SIGNATURE_NOT_VERIFIED,
+        ("verify_signature",),
+        re.compile(r"""['"]verify_signature['"]\s*:\s*False\b"""),
+        "PyJWT decodes the token with signature verification turned off",
+    ),
+    (
+        PatternKind.JWT_SIGNATURE_NOT_VERIFIED,
+        ("verify",),
+        re.compile(r"\bjwt\.decode\s*\([^)\n]{0,300}\bverify\s*=\s*False\b"),
+        "jwt.decode(..., verify=False) skips signature verification",
+    ),
+    (
+        PatternKind.JWT_SIGNATURE_NOT_VERIFIED,
+        ("parseunverified",),
+        re.compile(r"\.ParseUnverified\s*\("),
+        "golang-jwt parses the token without verifying its signature",
+    ),
+    (
+        PatternKind.JWT_AUDIENCE_NOT_VALIDATED,
+        ("verify_aud",),
+        re.compile(r"""['"]verify_aud['"]\s*:\s*False\b"""),
+        "The token is decoded without checking its audience",
+    ),
+    (
+        PatternKind.JWT_AUDIENCE_NOT_VALIDATED,
+        ("validateaudience",),
+        re.compile(r"\bValidateAudience\s*=\s*false\b"),
+        "Token validation does not check the audience",
+    ),
+    (
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        ("verify",),
+        re.compile(r"\bverify\s*=\s*False\b"),
+        "HTTP client call with verify=False accepts any server certificate",
+    ),
+    (
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        ("_create_unverified_context", "cert_none", "check_hostname"),
+        re.compile(r"\bssl\._create_unverified_context\b|\b(?:cert_reqs|verify_mode)\s*=\s*(?:ssl\.)?CERT_NONE\b|\bcheck_hostname\s*=\s*False\b"),
+        "SSL context skips certificate or hostname verification",
+    ),
+    (
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        ("rejectunauthorized", "node_tls_reject_unauthorized"),
+        re.compile(r"\brejectUnauthorized\s*:\s*false\b|\bNODE_TLS_REJECT_UNAUTHORIZED\b\W{0,6}0"),
+        "Node.js TLS connections accept any server certificate",
+    ),
+    (
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        ("insecureskipverify",),
+        re.compile(r"\bInsecureSkipVerify\s*:\s*true\b"),
+        "Go TLS config skips certificate verification",
+    ),
+    (
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        ("noophostnameverifier", "trustallstrategy", "allow_all_hostname_verifier", "insecuretrustmanagerfactory"),
+        re.compile(r"\b(?:NoopHostnameVerifier|TrustAllStrategy|ALLOW_ALL_HOSTNAME_VERIFIER|InsecureTrustManagerFactory)\b"),
+        "HTTP client trusts every certificate or hostname",
+    ),
+    (
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        ("dangerousacceptany", "validationcallback"),
+        re.compile(
+            r"\bDangerousAcceptAnyServerCertificateValidator\b|"
+            r"\bServerCertificate(?:Custom)?ValidationCallback\s*\+?=\s*\([^)\n]{0,120}\)\s*=>\s*true\b"
+        ),
+        "Certificate validation callback accepts any server certificate",
+    ),
+    (
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        ("verify_none", "curlopt_ssl_verifypeer"),
+        re.compile(r"\bVERIFY_NONE\b|\bCURLOPT_SSL_VERIFYPEER\s*,\s*(?:false|0)\b"),
+        "TLS peer verification is turned off",
+    ),
+]
+COMMENT_LINE = re.compile(r"^\s*(?:#|//|/\*|\*)")
+
+# JWT libraries verified without an audience anywhere in the file
+JSONWEBTOKEN_IMPORT = re.compile(r"['\"](?:jsonwebtoken|express-jwt)['\"]")
+JS_JWT_VERIFY = re.compile(r"\bjwt\s*\.\s*verify\s*\(|\bexpress[Jj]wt\s*\(")
+JS_JWT_DECODE = re.compile(r"\bjwt\s*\.\s*decode\s*\(")
+JS_VERIFY_CALL = re.compile(r"\.\s*verify\s*\(")
+GO_JWT_IMPORT = re.compile(r"\"github\.com/(?:golang-jwt/jwt|dgrijalva/jwt-go|form3tech-oss/jwt-go)")
+GO_JWT_PARSE = re.compile(r"\bjwt\.Parse(?:WithClaims)?\s*\(|\.ParseWithClaims\s*\(")
+JVM_JWT_PARSER = re.compile(r"\bJwts\.parser(?:Builder)?\s*\(|\bJWT\.require\s*\(")
+AUDIENCE = re.compile(r"audience|[\"']aud[\"']", re.IGNORECASE)
+
+# Equality checks on secret-looking values
+SECRET_WORD = re.compile(r"secret|token|api[_-]?key|apikey|signature|hmac|digest|passw(?:or)?d|webhook[_-]?key", re.IGNORECASE)
+NOT_SECRET_WORD = re.compile(
+    r"type|kind|len|size|count|name|id$|expir|format|prefix|scheme|url|path|header|field|version|status|algorithm|"
+    r"exists|present|enabled|required|length|mode|source|index|method|policy|endpoint|hint|reset|confirm",
+    re.IGNORECASE,
+)
+OPERAND = r"[A-Za-z_$][\w$]*(?:\??\.[A-Za-z_$][\w$]*|->[A-Za-z_]\w*|\[[^\]\n]{0,80}\]|\([^()\n]{0,80}\))*"
+COMPARISON = re.compile(
+    rf"(?<![\w$.>\])])({OPERAND})\s*(?:===?|!==?)\s*({OPERAND})(?![\w$])"
+    rf"|(?<![\w$.>\])])({OPERAND})\.[Ee]quals\(\s*({OPERAND})\s*\)"
+    rf"|\bArrays\.equals\(\s*({OPERAND})\s*,\s*({OPERAND})\s*\)"
+)
+NON_VALUES = {"None", "null", "nil", "undefined", "true", "false", "True", "False", "NULL", "nullptr"}
+CONSTANT_TIME = re.compile(
+    r"compare_digest|timingSafeEqual|ConstantTimeCompare|MessageDigest\.isEqual|secure_compare|hash_equals|"
+    r"FixedTimeEquals|constantTimeEquals|safe_str_cmp",
+)
+COMPARISON_SUFFIXES = (".py", ".go", ".rb", ".php", ".cs") + JS_SUFFIXES + JVM_SUFFIXES
+
+# Express and Koa apps run middleware in registration order
+ROUTER_IMPORT = re.compile(r"['\"](?:express|koa|@koa/router|koa-router)['\"]")
+
+
+@dataclass
+class PatternFinding:
+    """One occurrence of a known-vulnerable pattern."""
+
+    kind: PatternKind
+    file_path: str
+    line_start: int
+    line_end: int
+    snippet: str
+    description: str
+
+
+class _Source:
+    """A source file with a line index for offset lookups."""
+
+    def __init__(self, file_path: str, text: str):
+        self.file_path = file_path
+        self.text = text
+        self.lines = text.split("\n")
+        self.starts = [0]
+        for line in self.lines[:-1]:
+            self.starts.append(self.starts[-1] + len(line) + 1)
+
+    def line_of(self, offset: int) -> int:
+        """1-based line number of a character offset."""
+        return bisect_right(self.starts, offset)
+
+    def finding(self, kind: PatternKind, start: int, end: int, description: str) -> PatternFinding:
+        """A finding spanning the lines of start..end."""
+        first = self.line_of(start)
+        last = min(self.line_of(max(start, end - 1)), first + MAX_SNIPPET_LINES - 1)
+        return PatternFinding(kind, self.file_path, first, last, "\n".join(self.lines[first - 1 : last]), description)
+
+
+def _line_patterns(source: _Source) -> list[PatternFinding]:
+    """Findings of the single-match patterns, skipping commented-out lines."""
+    findings = []
+    lowered = source.text.lower()
+    for kind, needles, pattern, description in LINE_PATTERNS:
+        if not any(needle in lowered for needle in needles):
+            continue
+        for match in pattern.finditer(source.text):
+            line = source.lines[source.line_of(match.start()) - 1]
+            if COMMENT_LINE.match(line):
+                continue
+            # PyJWT's verify=False skips the signature rather than TLS
+            if kind == PatternKind.TLS_VERIFICATION_DISABLED and "decode(" in line:
+                continue
+            findings.append(source.finding(kind, match.start(), match.end(), description))
+    return findings
+
+
+def _jwt_usage(source: _Source) -> list[PatternFinding]:
+    """JWT verification calls in files that never name an audience, and decode-only jsonwebtoken use."""
+    text, suffix = source.text, PurePosixPath(source.file_path).suffix
+    calls: list[tuple[re.Match, str]] = []
+    if suffix in JS_SUFFIXES and JSONWEBTOKEN_IMPORT.search(text):
+        calls = [(m, "is called without an audience option") for m in JS_JWT_VERIFY.finditer(text)]
+        if not JS_VERIFY_CALL.search(text):
+            return [
+                source.finding(
+                    PatternKind.JWT_SIGNATURE_NOT_VERIFIED,
+                    m.start(),
+                    m.end(),
+                    "jsonwebtoken's decode() reads the payload without checking the signature, and this file never calls verify()",
+                )
+                for m in JS_JWT_DECODE.finditer(text)
+            ]
+    elif suffix == ".go" and GO_JWT_IMPORT.search(text):
+        calls = [(m, "parses the token without jwt.WithAudience or an aud check") for m in GO_JWT_PARSE.finditer(text)]
+    elif suffix in JVM_SUFFIXES:
+        calls = [(m, "builds a verifier without requiring an audience") for m in JVM_JWT_PARSER.finditer(text)]
+    if not calls or AUDIENCE.search(text):
+        return []
+    return [
+        source.finding(
+            PatternKind.JWT_AUDIENCE_NOT_VALIDATED,
+            m.start(),
+            m.end(),
+            f"{' '.join(m.group(0).rstrip('(').split())}() {reason}, so tokens issued for other services are accepted",
+        )
+        for m, reason in calls
+    ]
+
+
+def _secret_name(operand: str) -> bool:
+    """Whether the last segment of an operand names a secret."""
+    if operand in NON_VALUES:
+        return False
+    last = re.split(r"\?\.|\.|->|\[", operand)[-1].strip("'\"]) ")
+    last = re.sub(r"\(.*$", "", last) or last
+    return bool(SECRET_WORD.search(last)) and not NOT_SECRET_WORD.search(last)
+
+
+def _comparisons(source: _Source) -> list[PatternFinding]:
+    """Equality checks where one side names a secret and neither side is a null or boolean."""
+    if not source.file_path.endswith(COMPARISON_SUFFIXES):
+        return []
+    findings = []
+    for index, line in enumerate(source.lines):
+        if len(line) > MAX_COMPARISON_LINE or not SECRET_WORD.search(line) or COMMENT_LINE.match(line):
+            continue
+        if "==" not in line and "!=" not in line and "quals(" not in line:
+            continue
+        if CONSTANT_TIME.search(line):
+            continue
+        for match in COMPARISON.finditer(line):
+            left, right = [g for g in match.groups() if g is not None]
+            if left in NON_VALUES or right in NON_VALUES or not (_secret_name(left) or _secret_name(right)):
+                continue
+            start = source.starts[index] + match.start()
+            findings.append(
+                source.finding(
+                    PatternKind.TIMING_UNSAFE_COMPARISON,
+                    start,
+                    start + len(match.group(0)),
+                    f"{' '.join(match.group(0).split())} returns as soon as a character differs, leaking how much of the secret matched",
+                )
+            )
+    return findings
+
+
+def _spring_order(source: _Source) -> list[PatternFinding]:
+    """Filter chain rules a broader earlier permitAll() rule shadows."""
+    if not source.file_path.endswith(JVM_SUFFIXES) or not CHAIN_MARKER.search(source.text):
+        return []
+    rules = parse_filter_chain(source.text)
+    findings = []
+    for rule in rules:
+        if rule.access.subject == ANONYMOUS:
+            continue
+        shadow = next((earlier for earlier in rules[: rule.index - 1] if _covers(earlier, rule)), None)
+        if shadow is None or shadow.access.subject != ANONYMOUS:
+            continue
+        findings.append(
+            source.finding(
+                PatternKind.ROUTE_ORDER_BYPASS,
+                rule.start,
+                rule.end,
+                f"Rule {rule.index} ({', '.join(rule.patterns)} {rule.access.source}) never applies: rule {shadow.index} "
+                f"({', '.join(shadow.patterns)} {shadow.access.source}) matches the same requests first and lets anyone through",
+            )
+        )
+    return findings
+
+
+def _under(path: str, prefix: str) -> bool:
+    """Whether a route path falls under a middleware mount path."""
+    return path == prefix or path.startswith(prefix + "/")
+
+
+def _express_order(source: _Source) -> list[PatternFinding]:
+    """Routes registered before the path-scoped auth middleware meant to guard them."""
+    if not source.file_path.endswith(JS_SUFFIXES) or not ROUTER_IMPORT.search(source.text):
+        return []
+    src = _source(source.text)
+    routes, uses = [], []
+    for match in KOA_CALL.finditer(src.masked):
+        receiver, verb = match.group(1), match.group(2)
+        if verb == "prefix":
+            continue
+        open_paren = match.end() - 1
+        close = src.pairs.get(open_paren, len(src.masked))
+        args = src.split(open_paren + 1, close - 1)
+        literals = [src.literal(*span) for span in args]
+        path = literals[0] if literals and isinstance(literals[0], str) else ""
+        middleware = [src.source(*span) for span, value in zip(args, literals, strict=True) if value is None]
+        if verb == "use":
+            prefix = path.rstrip("*").rstrip("/")
+            guards, _ = _middleware_auth(middleware, path)
+            if guards and prefix:
+                uses.append((match.start(), receiver, path, prefix, guards))
+        elif path.startswith("/") and len(args) >= 2:
+            guards, _ = _middleware_auth(middleware[:-1], path)
+            if not guards:
+                routes.append((match.start(), close, receiver, verb, path))
+    findings = []
+    for start, end, receiver, verb, path in routes:
+        use = next((u for u in uses if u[0] > start and u[1] == receiver and _under(path, u[3])), None)
+        if use is None:
+            continue
+        guards = ", ".join(use[4])
+        findings.append(
+            source.finding(
+                PatternKind.ROUTE_ORDER_BYPASS,
+                start,
+                end,
+                f"{verb.upper()} {path} is registered before {receiver}.use('{use[2]}', {guards}) on line "
+                f"{source.line_of(use[0])}, so {guards} never runs for it",
+            )
+        )
+    return findings
+
+
+def detect_patterns(file_path: str, text: str) -> list[PatternFinding]:
+    """Detect known-vulnerable authentication patterns in a source file.
+
+    Args:
+        file_path: Relative path of the file
+        text: File content
+
+    Returns:
+        Findings in file order, at most one per kind and line
+    """
+    if not PREFILTER.search(text):
+        return []
+    source = _Source(file_path, text)
+    found = _line_patterns(source) + _jwt_usage(source) + _comparisons(source) + _spring_order(source) + _express_order(source)
+    seen, findings = set(), []
+    for finding in sorted(found, key=lambda f: (f.line_start, f.kind.value)):
+        if (finding.kind, finding.line_start) not in seen:
+            seen.add((finding.kind, finding.line_start))
+            findings.append(finding)
+    return findings
+
+
+def catalog() -> list[dict]:
+    """The detected pattern kinds with their CWE mappings."""
+    return [asdict(w) for w in WEAKNESSES.values()]
+
+
+def pattern_findings(root: str) -> Iterator[dict]:
+    """Sandbox pass yielding the known-vulnerable patterns across a repository clone's source files."""
+    clone = Path(root)
+    max_bytes = settings.MAX_FILE_SIZE_MB * 1024 * 1024
+    for path in sorted(clone.rglob("*")):
+        if path.suffix not in ROUTE_FILE_EXTENSIONS:
+            continue
+        relative = path.relative_to(clone)
+        if SKIPPED_DIRECTORIES & set(relative.parts):
+            continue
+        if not path.is_file() or path.stat().st_size > max_bytes:
+            continue
+        text = path.read_text(encoding="utf-8", errors="ignore")
+        yield from (asdict(f) for f in detect_patterns(relative.as_posix(), text))
+
+
+class VulnerableAuthPatternService:
+    """Scans repository clones for known-vulnerable authentication patterns."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None, clone_dir: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+        self.clone_dir = Path(clone_dir or settings.REPO_CLONE_DIR)
+
+    def _repositories(self, repository_id: int | None = None) -> list[Repository]:
+        """Load repositories for the tenant."""
+        query = self.db.query(Repository)
+        if self.tenant_id:
+            query = query.filter(Repository.tenant_id == self.tenant_id)
+        if repository_id is not None:
+            query = query.filter(Repository.id == repository_id)
+        return query.order_by(Repository.id).all()
+
+    @staticmethod
+    def scan_clone(root: Path) -> list[PatternFinding]:
+        """Detect known-vulnerable patterns across a repository clone, in the scan sandbox.
+
+        Args:
+            root: Repository clone root
+
+        Returns:
+            Findings across the clone's source files
+        """
+        return [PatternFinding(**{**f, "kind": PatternKind(f["kind"])}) for f in run_pass(root, pattern_findings)]
+
+    def findings(
+        self,
+        repository_id: int | None = None,
+        kind: PatternKind | None = None,
+        cwe: str | None = None,
+        severity: str | None = None,
+    ) -> dict:
+        """Known-vulnerable authentication patterns across the tenant's repositories.
+
+        Args:
+            repository_id: Restrict to one repository
+            kind: Keep only this pattern kind
+            cwe: Keep only kinds mapped to this CWE, e.g. "CWE-347"
+            severity: Keep only this severity
+
+        Returns:
+            Findings with their CWE mapping, most severe first, and counts per kind, CWE, and severity
+
+        Raises:
+            ValueError: If a requested repository does not exist
+        """
+        repositories = self._repositories(repository_id)
+        if repository_id is not None and not repositories:
+            raise ValueError(f"Repository {repository_id} not found")
+        results, scanned = [], 0
+        for repository in repositories:
+            root = self.clone_dir / str(repository.id)
+            if not root.is_dir():
+                continue
+            scanned += 1
+            found = self.scan_clone(root)
+            logger.info("vulnerable_auth_patterns_detected", repository_id=repository.id, findings=len(found))
+            for finding in found:
+                weakness = asdict(WEAKNESSES[finding.kind])
+                results.append({**weakness, **asdict(finding), "repository_id": repository.id, "repository_name": repository.name})
+
+        if kind is not None:
+            results = [r for r in results if r["kind"] == kind]
+        if cwe is not None:
+            results = [r for r in results if r["cwe"].lower() == cwe.lower()]
+        if severity is not None:
+            results = [r for r in results if r["severity"] == severity.lower()]
+        results.sort(key=lambda r: (SEVERITY_ORDER[r["severity"]], r["repository_id"], r["file_path"], r["line_start"]))
+
+        def counts(key: str) -> dict[str, int]:
+            tally: dict[str, int] = {}
+            for result in results:
+                value = result[key].value if isinstance(result[key], Enum) else result[key]
+                tally[value] = tally.get(value, 0) + 1
+            return tally
+
+        return {
+            "findings": results,
+            "summary": {
+                "total": len(results),
+                "repositories_scanned": scanned,
+                "repositories_with_findings": len({r["repository_id"] for r in results}),
+                "by_kind": counts("kind"),
+                "by_cwe": counts("cwe"),
+                "by_severity": counts("severity"),
+            },
+        }
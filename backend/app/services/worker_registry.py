@@ -0,0 +1,212 @@
+"""Registry of running scan workers and the scans they hold, kept in Redis.
+
+Each worker registers on start and heartbeats every WORKER_HEARTBEAT_SECONDS;
+its registration expires after three missed heartbeats. Scans record which
+worker runs them, so a scan whose worker is gone can be handed to another:
+
+- On shutdown a worker drains, finishing its current scan. A scan still
+  running after WORKER_DRAIN_SECONDS is queued again and the worker exits.
+- A worker that dies without draining (OOM kill, node loss) leaves scans
+  whose worker has no registration; the scheduler leader queues them again.
+
+Handed-off scans start over as new tasks. The original task's message is
+redelivered by the broker (tasks are acknowledged late), so the handed-off
+task ID is remembered and its redelivery skipped.
+"""
+
+import json
+import os
+import socket
+from collections.abc import Callable
+from datetime import UTC, datetime, timedelta
+
+import redis
+import structlog
+
+from app.core.config import settings
+
+logger = structlog.get_logger(__name__)
+
+WORKER_PREFIX = "policy-miner:workers:"
+SCANS_KEY = "policy-miner:scans"
+HANDED_OFF_PREFIX = "policy-miner:handed-off:"
+
+# Longer than the broker takes to redeliver an unacknowledged task (its visibility timeout)
+HANDED_OFF_TTL = timedelta(days=1)
+
+
+class WorkerRegistry:
+    """Registers workers and hands off the scans of workers that are gone."""
+
+    def __init__(self, client: redis.Redis):
+        """Initialize registry."""
+        self.client = client
+        self.ttl = settings.WORKER_HEARTBEAT_SECONDS * 3
+
+    def _save(self, worker: dict) -> None:
+        """Write a worker's registration, expiring after three missed heartbeats."""
+        self.client.set(WORKER_PREFIX + worker["worker_id"], json.dumps(worker), ex=self.ttl)
+
+    def get_worker(self, worker_id: str) -> dict | None:
+        """A worker's registration, if it is alive."""
+        data = self.client.get(WORKER_PREFIX + worker_id)
+        return json.loads(data) if data else None
+
+    def register(self, worker_id: str, now: datetime | None = None) -> dict:
+        """Register a worker that is ready for scans."""
+        now = now or datetime.now(UTC)
+        worker = {
+            "worker_id": worker_id,
+            "hostname": socket.gethostname(),
+            "pid": os.getpid(),
+            "state": "active",
+            "started_at": now.isoformat(),
+            "heartbeat_at": now.isoformat(),
+            "drain_deadline": None,
+        }
+        self._save(worker)
+        logger.info("worker_registered", worker_id=worker_id)
+        return worker
+
+    def heartbeat(self, worker_id: str, now: datetime | None = None) -> None:
+        """Keep a worker's registration alive, re-registering it if it had expired."""
+        now = now or datetime.now(UTC)
+        worker = self.get_worker(worker_id)
+        if worker is None:
+            logger.warning("worker_registration_expired", worker_id=worker_id)
+            self.register(worker_id, now)
+            return
+        worker["heartbeat_at"] = now.isoformat()
+        self._save(worker)
+
+    def drain(self, worker_id: str, now: datetime | None = None) -> None:
+        """Mark a worker as shutting down: it takes no new scans and finishes or hands off its own."""
+        now = now or datetime.now(UTC)
+        worker = self.get_worker(worker_id) or self.register(worker_id, now)
+        worker["state"] = "draining"
+        worker["drain_deadline"] = (now + timedelta(seconds=settings.WORKER_DRAIN_SECONDS)).isoformat()
+        self._save(worker)
+        logger.info("worker_draining", worker_id=worker_id, drain_deadline=worker["drain_deadline"])
+
+    def deregister(self, worker_id: str) -> None:
+        """Remove a worker that has shut down."""
+        self.client.delete(WORKER_PREFIX + worker_id)
+        logger.info("worker_deregistered", worker_id=worker_id)
+
+    def list_workers(self) -> list[dict]:
+        """Registered workers with the scans each is running, by worker ID."""
+        scans = self.list_scans()
+        workers = []
+        for key in sorted(self.client.scan_iter(match=WORKER_PREFIX + "*")):
+            data = self.client.get(key)
+            if data:
+                worker = json.loads(data)
+                worker["scans"] = [s for s in scans if s["worker_id"] == worker["worker_id"]]
+                workers.append(worker)
+        return workers
+
+    def start_scan(
+        self,
+        worker_id: str,
+        task_id: str,
+        repository_id: int,
+        tenant_id: str | None,
+        incremental: bool,
+        now: datetime | None = None,
+    ) -> bool:
+        """Record that a worker started a scan task.
+
+        Returns:
+            False if the task was handed off to another, so this delivery must not run
+        """
+        if self.client.exists(HANDED_OFF_PREFIX + task_id):
+            logger.info("handed_off_scan_skipped", task_id=task_id, repository_id=repository_id)
+            return False
+        scan = {
+            "task_id": task_id,
+            "worker_id": worker_id,
+            "repository_id": repository_id,
+            "tenant_id": tenant_id,
+            "incremental": incremental,
+            "started_at": (now or datetime.now(UTC)).isoformat(),
+        }
+        self.client.hset(SCANS_KEY, task_id, json.dumps(scan))
+        return True
+
+    def finish_scan(self, task_id: str) -> None:
+        """Forget a scan task that finished, successfully or not."""
+        self.client.hdel(SCANS_KEY, task_id)
+
+    def list_scans(self) -> list[dict]:
+        """Scans in flight, earliest first."""
+        scans = [json.loads(s) for s in self.client.hgetall(SCANS_KEY).values()]
+        return sorted(scans, key=lambda s: s["started_at"])
+
+    def _hand_off(self, scan: dict, enqueue: Callable[[int, str | None, bool], str], reason: str) -> dict | None:
+        """Queue a scan again for another worker.
+
+        Claiming the scan first means only one caller hands it off when the
+        leader and a draining worker race.
+
+        Raises:
+            Exception: If the scan could not be queued; it stays recorded
+        """
+        if not self.client.hdel(SCANS_KEY, scan["task_id"]):
+            return None
+        self.client.set(HANDED_OFF_PREFIX + scan["task_id"], scan["worker_id"], ex=int(HANDED_OFF_TTL.total_seconds()))
+        try:
+            task_id = enqueue(scan["repository_id"], scan["tenant_id"], scan["incremental"])
+        except Exception:
+            # Put the scan back so the next recovery retries it
+            self.client.delete(HANDED_OFF_PREFIX + scan["task_id"])
+            self.client.hset(SCANS_KEY, scan["task_id"], json.dumps(scan))
+            raise
+        logger.warning(
+            "scan_handed_off",
+            reason=reason,
+            worker_id=scan["worker_id"],
+            repository_id=scan["repository_id"],
+            from_task_id=scan["task_id"],
+            to_task_id=task_id,
+        )
+        return {**scan, "reason": reason, "new_task_id": task_id}
+
+    def hand_off_worker(self, worker_id: str, enqueue: Callable[[int, str | None, bool], str]) -> list[dict]:
+        """Hand off the scans a draining worker could not finish in time.
+
+        Args:
+            worker_id: Worker shutting down
+            enqueue: Queues a scan (repository ID, tenant ID, incremental) and returns the task ID
+
+        Returns:
+            Scans handed off, with their new task IDs
+        """
+        handed_off = []
+        for scan in self.list_scans():
+            if scan["worker_id"] == worker_id:
+                result = self._hand_off(scan, enqueue, "drain_timeout")
+                if result:
+                    handed_off.append(result)
+        return handed_off
+
+    def recover_orphaned_scans(self, enqueue: Callable[[int, str | None, bool], str]) -> list[dict]:
+        """Hand off the scans of workers whose registration has expired.
+
+        Args:
+            enqueue: Queues a scan (repository ID, tenant ID, incremental) and returns the task ID
+
+        Returns:
+            Scans handed off, with their new task IDs
+        """
+        recovered = []
+        for scan in self.list_scans():
+            if self.client.exists(WORKER_PREFIX + scan["worker_id"]):
+                continue
+            try:
+                result = self._hand_off(scan, enqueue, "worker_lost")
+            except Exception as e:
+                logger.error("orphaned_scan_not_requeued", task_id=scan["task_id"], error=str(e))
+                continue
+            if result:
+                recovered.append(result)
+        return recovered
@@ -0,0 +1,442 @@
+"""Service for exporting mined policies as an XACML 3.0 PolicySet.
+
+Enterprise PBAC products that only ingest XACML get one PolicySet per
+repository, combined deny-unless-permit so a request no mined policy grants
+is denied rather than left NotApplicable. Inside it:
+
+- one Policy per endpoint, targeting the HTTP method (the standard
+  ``action-id``) and the normalized route path (``/api/expenses/{}``) as the
+  ``urn:policy-miner:resource:endpoint`` resource attribute;
+- one Permit Rule per mined policy on the endpoint, combined
+  permit-overrides so each grants access on its own. Its Target requires one
+  of the policy's roles (the standard ``role`` subject attribute, compared
+  ignoring case) and its Condition the policy's attribute checks:
+
+      <Apply FunctionId="urn:oasis:names:tc:xacml:1.0:function:integer-less-than-or-equal">
+        <Apply FunctionId="urn:oasis:names:tc:xacml:1.0:function:integer-one-and-only">
+          <AttributeDesignator AttributeId="urn:policy-miner:resource:amount" ... />
+        </Apply>
+        <AttributeValue DataType="http://www.w3.org/2001/XMLSchema#integer">5000</AttributeValue>
+      </Apply>
+
+Every Permit carries an audit obligation naming the mined policy, endpoint,
+and subject, and the PolicySet carries one for every Deny, so the PEP logs
+each decision with the policy behind it.
+
+Conditions are translated from the clauses ConditionEvaluationService
+understands. A missing attribute makes its rule Indeterminate, which the
+combining algorithms turn into Deny. As with the Rego and Cedar exports, a
+clause that cannot be translated makes its rule never match and is listed
+for review, and database policies are not exported. The export also lists
+every attribute the rules read, which is what a PEP must send.
+"""
+
+import re
+import xml.etree.ElementTree as ET
+from dataclasses import replace
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.models.policy import Policy, PolicyStatus, SourceType
+from app.models.repository import Repository
+from app.services.condition_evaluation_service import ConditionClause, ConditionEvaluationService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.rego_export_service import NEGATED_OPERATORS, SUBJECT_CLAUSE, rego_name
+from app.services.stable_identity_service import normalize_path
+
+logger = structlog.get_logger(__name__)
+
+XACML_NS = "urn:oasis:names:tc:xacml:3.0:core:schema:wd-17"
+XSD = "http://www.w3.org/2001/XMLSchema#"
+FUNCTION = "urn:oasis:names:tc:xacml:1.0:function:"
+FUNCTION_3 = "urn:oasis:names:tc:xacml:3.0:function:"
+
+DENY_UNLESS_PERMIT = "urn:oasis:names:tc:xacml:3.0:policy-combining-algorithm:deny-unless-permit"
+PERMIT_OVERRIDES = "urn:oasis:names:tc:xacml:3.0:rule-combining-algorithm:permit-overrides"
+
+SUBJECT = "urn:oasis:names:tc:xacml:1.0:subject-category:access-subject"
+RESOURCE = "urn:oasis:names:tc:xacml:3.0:attribute-category:resource"
+ACTION = "urn:oasis:names:tc:xacml:3.0:attribute-category:action"
+
+SUBJECT_ID = "urn:oasis:names:tc:xacml:1.0:subject:subject-id"
+ROLE = "urn:oasis:names:tc:xacml:2.0:subject:role"
+ACTION_ID = "urn:oasis:names:tc:xacml:1.0:action:action-id"
+ENDPOINT = "urn:policy-miner:resource:endpoint"
+AUTHENTICATED = "urn:policy-miner:subject:authenticated"
+
+AUDIT_OBLIGATION = "urn:policy-miner:obligation:audit"
+
+# XACML function name suffix of each comparison operator
+COMPARISON_FUNCTIONS = {
+    ">": "greater-than",
+    ">=": "greater-than-or-equal",
+    "<": "less-than",
+    "<=": "less-than-or-equal",
+    "==": "equal",
+}
+
+
+def urn_segment(text: str | None, fallback: str) -> str:
+    """Text usable in an XACML identifier URN, e.g. "expense-api" from "Expense API"."""
+    segment = re.sub(r"[^a-z0-9._-]+", "-", (text or "").lower()).strip("-")
+    return segment or fallback
+
+
+def data_type(value) -> str:
+    """XML Schema type of a condition value."""
+    if isinstance(value, bool):
+        return "boolean"
+    if isinstance(value, int):
+        return "integer"
+    if isinstance(value, float):
+        return "double"
+    return "string"
+
+
+def literal(value) -> str:
+    """Text of an AttributeValue."""
+    if isinstance(value, bool):
+        return "true" if value else "false"
+    return str(value)
+
+
+def attribute_value(value, kind: str | None = None) -> ET.Element:
+    """AttributeValue element of a literal."""
+    element = ET.Element("AttributeValue", DataType=XSD + (kind or data_type(value)))
+    element.text = literal(value)
+    return element
+
+
+def apply(function: str, *arguments: ET.Element) -> ET.Element:
+    """Apply element calling a function on its arguments."""
+    element = ET.Element("Apply", FunctionId=function)
+    element.extend(arguments)
+    return element
+
+
+def match(function_id: str, value, designator: ET.Element) -> ET.Element:
+    """Target Match of an attribute against a literal."""
+    element = ET.Element("Match", MatchId=function_id)
+    element.append(attribute_value(value))
+    element.append(designator)
+    return element
+
+
+def target(*any_ofs: list[list[ET.Element]]) -> ET.Element:
+    """Target requiring every AnyOf, each a list of AllOfs, each a list of Matches."""
+    element = ET.Element("Target")
+    for all_ofs in any_ofs:
+        any_of = ET.SubElement(element, "AnyOf")
+        for matches in all_ofs:
+            ET.SubElement(any_of, "AllOf").extend(matches)
+    return element
+
+
+class ClauseTranslator:
+    """Translates parsed condition clauses into XACML expressions."""
+
+    def __init__(self):
+        """Initialize translator."""
+        # Attributes the rules read: (category, attribute ID) -> XML Schema type
+        self.attributes: dict[tuple[str, str], str] = {
+            (SUBJECT, SUBJECT_ID): "string",
+            (ACTION, ACTION_ID): "string",
+            (RESOURCE, ENDPOINT): "string",
+        }
+
+    def designator(self, category: str, attribute_id: str, kind: str) -> ET.Element:
+        """AttributeDesignator of a bag, recording the attribute as one a PEP sends."""
+        self.attributes.setdefault((category, attribute_id), kind)
+        return ET.Element(
+            "AttributeDesignator",
+            Category=category,
+            AttributeId=attribute_id,
+            DataType=XSD + kind,
+            MustBePresent="false",
+        )
+
+    def attribute(self, clause_text: str, attribute: str, kind: str) -> ET.Element:
+        """Designator of a condition's attribute: the caller's or, by its last segment, the resource's."""
+        if SUBJECT_CLAUSE.match(clause_text):
+            return self.designator(SUBJECT, f"urn:policy-miner:subject:{attribute}", kind)
+        return self.designator(RESOURCE, f"urn:policy-miner:resource:{attribute.split('.')[-1]}", kind)
+
+    def role_check(self, role: str) -> ET.Element:
+        """Expression requiring the subject to hold a role, ignoring case."""
+        return apply(
+            FUNCTION_3 + "any-of",
+            ET.Element("Function", FunctionId=FUNCTION_3 + "string-equal-ignore-case"),
+            attribute_value(role),
+            self.designator(SUBJECT, ROLE, "string"),
+        )
+
+    def comparison(self, clause: ConditionClause) -> ET.Element | None:
+        """Expression of a comparison clause, or None when XACML cannot state it."""
+        value, operator = clause.value, clause.operator
+        kind = data_type(value)
+        if kind in ("string", "boolean") and operator not in ("==", "!="):
+            return None  # Only numbers are ordered
+        name = COMPARISON_FUNCTIONS["==" if operator == "!=" else operator]
+        expression = apply(
+            FUNCTION + f"{kind}-{name}",
+            apply(FUNCTION + f"{kind}-one-and-only", self.attribute(clause.raw, clause.attribute, kind)),
+            attribute_value(value),
+        )
+        return apply(FUNCTION + "not", expression) if operator == "!=" else expression
+
+    def owner_check(self, attribute: str) -> ET.Element:
+        """Expression requiring a resource attribute to hold the subject's ID."""
+        return apply(
+            FUNCTION + "string-at-least-one-member-of",
+            self.designator(RESOURCE, f"urn:policy-miner:resource:{attribute.split('.')[-1]}", "string"),
+            self.designator(SUBJECT, SUBJECT_ID, "string"),
+        )
+
+    def translate(self, clause: ConditionClause) -> ET.Element | None:
+        """Expression satisfied when the clause holds.
+
+        Returns:
+            Expression, or None when the clause cannot be translated
+        """
+        if clause.kind == "comparison":
+            return self.comparison(clause)
+        if clause.kind == "implication" and clause.inner.kind == "comparison":
+            inner = replace(clause.inner, operator=NEGATED_OPERATORS[clause.inner.operator])
+            expression = self.comparison(inner)
+            if expression is None:
+                return None
+            return apply(FUNCTION + "or", expression, self.role_check(clause.required_role))
+        if clause.kind in ("ownership", "owner_of_resource"):
+            expression = self.owner_check(clause.attribute or "owner_id")
+            if clause.required_role:
+                return apply(FUNCTION + "or", expression, self.role_check(clause.required_role))
+            return expression
+        if clause.kind == "same_tenant":
+            return apply(
+                FUNCTION + "string-at-least-one-member-of",
+                self.designator(RESOURCE, f"urn:policy-miner:resource:{clause.attribute.split('.')[-1]}", "string"),
+                self.designator(SUBJECT, "urn:policy-miner:subject:tenant_id", "string"),
+            )
+        if clause.kind == "flag":
+            return apply(
+                FUNCTION + "boolean-is-in",
+                attribute_value(bool(clause.value)),
+                self.attribute(clause.raw, clause.attribute, "boolean"),
+            )
+        return None
+
+    def audit(self, fulfill_on: str, assignments: dict[str, ET.Element]) -> ET.Element:
+        """ObligationExpressions asking the PEP to audit a decision."""
+        expressions = ET.Element("ObligationExpressions")
+        obligation = ET.SubElement(expressions, "ObligationExpression", ObligationId=AUDIT_OBLIGATION, FulfillOn=fulfill_on)
+        for name, expression in assignments.items():
+            assignment = ET.SubElement(
+                obligation, "AttributeAssignmentExpression", AttributeId=f"{AUDIT_OBLIGATION}:{name}"
+            )
+            assignment.append(expression)
+        return expressions
+
+    def rule(self, policy: Policy, policy_set_id: str, endpoint: str) -> tuple[ET.Element, list[str]]:
+        """Permit rule granting what one policy grants.
+
+        Returns:
+            Tuple of (Rule element, clauses left untranslated)
+        """
+        roles, requires_authentication = EndpointMappingService.parse_roles(policy.subject)
+        rule = ET.Element("Rule", RuleId=f"{policy_set_id}:policy:{policy.id}", Effect="Permit")
+        grant = " ".join(f"{policy.subject} may {policy.action} {policy.resource}".split())
+        ET.SubElement(rule, "Description").text = f"Policy {policy.id}: {grant} ({endpoint})"
+        if roles:
+            role_matches = [
+                [match(FUNCTION_3 + "string-equal-ignore-case", role, self.designator(SUBJECT, ROLE, "string"))]
+                for role in roles
+            ]
+            rule.append(target(role_matches))
+        else:
+            rule.append(target())
+
+        conditions, untranslated = [], []
+        if not roles and requires_authentication:
+            conditions.append(
+                apply(
+                    FUNCTION + "boolean-is-in",
+                    attribute_value(True),
+                    self.designator(SUBJECT, AUTHENTICATED, "boolean"),
+                )
+            )
+        for clause in ConditionEvaluationService.parse(policy.conditions):
+            expression = self.translate(clause)
+            if expression is None:
+                text = " ".join(clause.raw.split())
+                untranslated.append(text)
+                conditions.append(ET.Comment(f" Not translated, review: {text} "))
+                expression = attribute_value(False)
+            conditions.append(expression)
+        if conditions:
+            condition = ET.SubElement(rule, "Condition")
+            expressions = [c for c in conditions if c.tag is not ET.Comment]
+            if len(expressions) == 1:
+                condition.extend(conditions)
+            else:
+                condition.append(apply(FUNCTION + "and", *conditions))
+
+        assignments = {
+            "policy-id": attribute_value(policy.id),
+            "endpoint": attribute_value(endpoint),
+            "subject-id": self.designator(SUBJECT, SUBJECT_ID, "string"),
+        }
+        if policy.confidence_score is not None:
+            assignments["confidence"] = attribute_value(float(policy.confidence_score))
+        rule.append(self.audit("Permit", assignments))
+        return rule, untranslated
+
+
+def attribute_list(attributes: dict[tuple[str, str], str]) -> list[dict]:
+    """Attributes the rules read, as a PEP must send them."""
+    categories = {SUBJECT: "subject", ACTION: "action", RESOURCE: "resource"}
+    return [
+        {"category": category, "attribute_id": attribute_id, "data_type": XSD + kind}
+        for (category, attribute_id), kind in sorted(
+            attributes.items(), key=lambda item: (list(categories).index(item[0][0]), item[0][1])
+        )
+    ]
+
+
+class XacmlExportService:
+    """Exports a repository's mined policies as an XACML 3.0 PolicySet."""
+
+    def __init__(self, db: Session, tenant_id: str | None = None):
+        """Initialize service."""
+        self.db = db
+        self.tenant_id = tenant_id
+
+    def _query(self, model):
+        """Query scoped to the current tenant."""
+        query = self.db.query(model)
+        if self.tenant_id:
+            query = query.filter(model.tenant_id == self.tenant_id)
+        return query
+
+    def get_repository(self, repository_id: int) -> Repository:
+        """Get a repository of the current tenant.
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self._query(Repository).filter(Repository.id == repository_id).first()
+        if repository is None:
+            raise ValueError(f"Repository {repository_id} not found")
+        return repository
+
+    def export(self, repository_id: int, min_confidence: float | None = None, include_pending: bool = False) -> dict:
+        """XACML PolicySet of a repository's mined policies.
+
+        Args:
+            repository_id: Repository ID
+            min_confidence: Leave out policies scored below this confidence (0-100)
+            include_pending: Also export policies that have not been approved
+
+        Returns:
+            The PolicySet document, its policies and rules, the attributes a
+            PEP must send, and a summary
+
+        Raises:
+            ValueError: If the repository does not exist
+        """
+        repository = self.get_repository(repository_id)
+        query = self._query(Policy).filter(
+            Policy.repository_id == repository.id, Policy.source_type != SourceType.DATABASE
+        )
+        if include_pending:
+            query = query.filter(Policy.status != PolicyStatus.REJECTED)
+        else:
+            query = query.filter(Policy.status == PolicyStatus.APPROVED)
+        candidates = query.order_by(Policy.id).all()
+        policies = [
+            p for p in candidates
+            if min_confidence is None or (p.confidence_score is not None and p.confidence_score >= min_confidence)
+        ]
+
+        policy_set_id = f"urn:policy-miner:{urn_segment(repository.name, f'repository-{repository.id}')}"
+        endpoints: dict[tuple[str, str], list[Policy]] = {}
+        for policy in policies:
+            rule = EndpointMappingService.map_policy(policy)
+            endpoints.setdefault((rule.method, normalize_path(rule.path)), []).append(policy)
+
+        translator = ClauseTranslator()
+        policy_set = ET.Element(
+            "PolicySet",
+            xmlns=XACML_NS,
+            PolicySetId=policy_set_id,
+            Version="1.0",
+            PolicyCombiningAlgId=DENY_UNLESS_PERMIT,
+        )
+        ET.SubElement(policy_set, "Description").text = f"Authorization mined from {repository.name}"
+        policy_set.append(target())
+        exported, untranslated = [], []
+        for (method, path), endpoint_policies in sorted(endpoints.items()):
+            endpoint = f"{method} {path}"
+            policy_id = f"{policy_set_id}:{rego_name(endpoint, 'endpoint')}"
+            element = ET.SubElement(
+                policy_set, "Policy", PolicyId=policy_id, Version="1.0", RuleCombiningAlgId=PERMIT_OVERRIDES
+            )
+            ET.SubElement(element, "Description").text = endpoint
+            element.append(
+                target(
+                    [[
+                        match(FUNCTION + "string-equal", method, translator.designator(ACTION, ACTION_ID, "string")),
+                        match(FUNCTION + "string-equal", path, translator.designator(RESOURCE, ENDPOINT, "string")),
+                    ]]
+                )
+            )
+            rule_ids = []
+            for policy in endpoint_policies:
+                rule, clauses = translator.rule(policy, policy_set_id, endpoint)
+                element.append(rule)
+                rule_ids.append(rule.get("RuleId"))
+                untranslated += [{"policy_id": policy.id, "endpoint": endpoint, "clause": c} for c in clauses]
+            exported.append(
+                {
+                    "policy_id": policy_id,
+                    "endpoint": endpoint,
+                    "rule_ids": rule_ids,
+                    "mined_policy_ids": [p.id for p in endpoint_policies],
+                }
+            )
+        policy_set.append(
+            translator.audit(
+                "Deny",
+                {
+                    "action-id": translator.designator(ACTION, ACTION_ID, "string"),
+                    "endpoint": translator.designator(RESOURCE, ENDPOINT, "string"),
+                    "subject-id": translator.designator(SUBJECT, SUBJECT_ID, "string"),
+                },
+            )
+        )
+
+        ET.indent(policy_set)
+        document = '<?xml version="1.0" encoding="UTF-8"?>\n' + ET.tostring(policy_set, encoding="unicode") + "\n"
+        logger.info(
+            "xacml_exported",
+            repository_id=repository.id,
+            policy_set_id=policy_set_id,
+            policies=len(policies),
+            tenant_id=self.tenant_id,
+        )
+        return {
+            "repository_id": repository.id,
+            "policy_set_id": policy_set_id,
+            "xacml": document,
+            "policies": exported,
+            "attributes": attribute_list(translator.attributes),
+            "untranslated": untranslated,
+            "summary": {
+                "policies": len(policies),
+                "endpoints": len(endpoints),
+                "rules": sum(len(p["rule_ids"]) for p in exported),
+                "attributes": len(translator.attributes),
+                "below_min_confidence": len(candidates) - len(policies),
+                "untranslated_clauses": len(untranslated),
+            },
+        }
@@ -0,0 +1,38 @@
+"""Celery tasks for publishing OPA bundles."""
+
+import asyncio
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.celery_app import celery_app
+from app.core.database import get_db
+from app.models.bundle_publication import PublicationStatus
+from app.services.bundle_publish_service import BundlePublishService
+
+logger = structlog.get_logger(__name__)
+
+
+@celery_app.task(name="publish_bundles_on_approval")
+def publish_bundles_on_approval_task(policy_ids: list[int], tenant_id: str | None = None) -> dict:
+    """
+    Republish the auto-publish bundle targets covering newly approved policies.
+
+    Queued by the approval endpoints, so building and pushing bundles never
+    holds up an approval.
+
+    Args:
+        policy_ids: Policies just approved
+        tenant_id: Tenant the policies belong to
+
+    Returns:
+        Dictionary with the number of publications made and how many failed
+    """
+    db: Session = next(get_db())
+    try:
+        publications = asyncio.run(BundlePublishService(db, tenant_id).publish_on_approval(policy_ids))
+        failed = sum(p.status == PublicationStatus.FAILED for p in publications)
+        logger.info("Bundles published on approval", publications=len(publications), failed=failed)
+        return {"publications": len(publications), "failed": failed}
+    finally:
+        db.close()
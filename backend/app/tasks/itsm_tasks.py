@@ -0,0 +1,31 @@
+"""Celery tasks for ITSM finding ticket sync."""
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.celery_app import celery_app
+from app.core.database import get_db
+from app.services.itsm_sync_service import ItsmSyncService
+
+logger = structlog.get_logger(__name__)
+
+
+@celery_app.task(name="sync_itsm_connectors")
+def sync_itsm_connectors_task() -> dict:
+    """
+    Periodic task syncing finding tickets with ServiceNow and generic ITSM connectors.
+
+    Runs from celery beat; each connector is only synced once its own
+    interval has elapsed.
+
+    Returns:
+        Dictionary with the number of connectors synced and tickets created
+    """
+    db: Session = next(get_db())
+    try:
+        results = ItsmSyncService(db).sync_due_connectors()
+        created = sum(r["created"] for r in results)
+        logger.info("ITSM connector sync completed", connectors_synced=len(results), tickets_created=created)
+        return {"connectors_synced": len(results), "tickets_created": created}
+    finally:
+        db.close()
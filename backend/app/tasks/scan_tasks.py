@@ -4,8 +4,10 @@ import structlog
 from sqlalchemy.orm import Session
 
 from app.celery_app import celery_app
+from app.core.coordination import get_redis
 from app.core.database import get_db
 from app.services.scanner_service import ScannerService
+from app.services.worker_registry import WorkerRegistry
 
 logger = structlog.get_logger(__name__)
 
@@ -16,6 +18,7 @@ def scan_repository_task(
     repository_id: int,
     tenant_id: str | None = None,
     incremental: bool = False,
+    config_snapshot: dict | None = None,
 ) -> dict:
     """
     Async task to scan a repository and extract policies.
@@ -24,6 +27,7 @@ def scan_repository_task(
         repository_id: ID of the repository to scan
         tenant_id: Optional tenant ID for multi-tenancy
         incremental: If True, only scan changed files since last scan
+        config_snapshot: Optional {"env": ..., "config": ...} snapshot to resolve settings with
 
     Returns:
         Dictionary with scan results
@@ -36,6 +40,11 @@ def scan_repository_task(
         incremental=incremental,
     )
 
+    # Record the scan against this worker so it can be handed off if the worker goes away
+    registry = WorkerRegistry(get_redis())
+    if not registry.start_scan(self.request.hostname, self.request.id, repository_id, tenant_id, incremental):
+        return {"repository_id": repository_id, "status": "handed_off"}
+
     # Update task state to STARTED
     self.update_state(
         state="STARTED",
@@ -65,6 +74,7 @@ def scan_repository_task(
                     repository_id=repository_id,
                     tenant_id=tenant_id,
                     incremental=incremental,
+                    config_snapshot=config_snapshot,
                 )
             )
 
@@ -91,6 +101,12 @@ def scan_repository_task(
 
     finally:
         db.close()
+        registry.finish_scan(self.request.id)
+
+
+def enqueue_scan(repository_id: int, tenant_id: str | None = None, incremental: bool = False) -> str:
+    """Queue a repository's scan and return the task ID."""
+    return scan_repository_task.delay(repository_id, tenant_id=tenant_id, incremental=incremental).id
 
 
 @celery_app.task(bind=True, name="bulk_scan_repositories")
@@ -0,0 +1,39 @@
+"""Celery tasks for scheduled repository scans."""
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.celery_app import celery_app
+from app.core.database import get_db
+from app.models.scan_schedule import ScanSchedule
+from app.services.scan_schedule_service import ScanScheduleService
+from app.tasks.scan_tasks import scan_repository_task
+
+logger = structlog.get_logger(__name__)
+
+
+def enqueue_scan(schedule: ScanSchedule) -> str:
+    """Queue a schedule's scan and return the task ID."""
+    result = scan_repository_task.delay(
+        schedule.repository_id, tenant_id=schedule.tenant_id, incremental=schedule.incremental
+    )
+    return result.id
+
+
+@celery_app.task(name="run_scan_schedules")
+def run_scan_schedules_task() -> dict:
+    """
+    Periodic task queueing the scans of schedules whose next run has passed.
+
+    Runs from celery beat every minute, the finest cron resolution.
+
+    Returns:
+        Dictionary with the number of scans queued
+    """
+    db: Session = next(get_db())
+    try:
+        queued = ScanScheduleService(db).run_due_schedules(enqueue_scan)
+        logger.info("Scheduled scans queued", scans_queued=len(queued))
+        return {"scans_queued": len(queued)}
+    finally:
+        db.close()
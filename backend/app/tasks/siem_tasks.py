@@ -0,0 +1,31 @@
+"""Celery tasks for SIEM event streaming."""
+
+import structlog
+from sqlalchemy.orm import Session
+
+from app.celery_app import celery_app
+from app.core.database import get_db
+from app.services.siem_export_service import SiemExportService
+
+logger = structlog.get_logger(__name__)
+
+
+@celery_app.task(name="stream_siem_events")
+def stream_siem_events_task() -> dict:
+    """
+    Periodic task streaming findings, auth regressions, and audit events to SIEM connectors.
+
+    Runs from celery beat; each connector is only streamed once its own
+    interval has elapsed.
+
+    Returns:
+        Dictionary with the number of connectors streamed and events sent
+    """
+    db: Session = next(get_db())
+    try:
+        results = SiemExportService(db).stream_due_connectors()
+        sent = sum(r["events_sent"] for r in results)
+        logger.info("SIEM event streaming completed", connectors_streamed=len(results), events_sent=sent)
+        return {"connectors_streamed": len(results), "events_sent": sent}
+    finally:
+        db.close()
@@ -0,0 +1,26 @@
+"""Celery tasks for keeping scans running when workers go away."""
+
+import structlog
+
+from app.celery_app import celery_app
+from app.core.coordination import get_redis
+from app.services.worker_registry import WorkerRegistry
+from app.tasks.scan_tasks import enqueue_scan
+
+logger = structlog.get_logger(__name__)
+
+
+@celery_app.task(name="recover_orphaned_scans")
+def recover_orphaned_scans_task() -> dict:
+    """
+    Periodic task queueing again the scans of workers that died without draining.
+
+    Runs from celery beat every minute, on the scheduler leader only.
+
+    Returns:
+        Dictionary with the number of scans handed off
+    """
+    recovered = WorkerRegistry(get_redis()).recover_orphaned_scans(enqueue_scan)
+    if recovered:
+        logger.warning("Orphaned scans handed off", scans=len(recovered))
+    return {"scans_recovered": len(recovered)}
@@ -1,6 +1,77 @@
-"""Celery worker entrypoint."""
+"""Celery worker entrypoint.
+
+The worker registers itself and heartbeats while running. On SIGTERM it
+drains: it takes no new scans and finishes its current one, handing the scan
+to another worker if it is still running after WORKER_DRAIN_SECONDS.
+"""
+
+import os
+import threading
+
+import structlog
+from celery.signals import worker_ready, worker_shutdown, worker_shutting_down
 
 from app.celery_app import celery_app
+from app.core.config import settings
+from app.core.coordination import get_redis
+from app.services.worker_registry import WorkerRegistry
+from app.tasks.scan_tasks import enqueue_scan
+
+logger = structlog.get_logger(__name__)
+
+registry = WorkerRegistry(get_redis())
+stopped = threading.Event()
+drain_timers: list[threading.Timer] = []
+
+
+def heartbeat(worker_id: str) -> None:
+    """Heartbeat until the worker stops, surviving Redis outages."""
+    while not stopped.wait(settings.WORKER_HEARTBEAT_SECONDS):
+        try:
+            registry.heartbeat(worker_id)
+        except Exception as e:
+            logger.warning("worker_heartbeat_failed", worker_id=worker_id, error=str(e))
+
+
+def hand_off(worker_id: str) -> None:
+    """Hand off a scan that outlasted the drain period, then exit without finishing it."""
+    try:
+        handed_off = registry.hand_off_worker(worker_id, enqueue_scan)
+    except Exception as e:
+        logger.error("scan_hand_off_failed", worker_id=worker_id, error=str(e))
+        return
+    if handed_off:
+        logger.warning("worker_exiting_after_hand_off", worker_id=worker_id, scans=len(handed_off))
+        os._exit(1)
+
+
+@worker_ready.connect
+def on_worker_ready(sender, **kwargs) -> None:
+    """Register the worker and start heartbeating."""
+    registry.register(sender.hostname)
+    threading.Thread(target=heartbeat, args=(sender.hostname,), name="worker-heartbeat", daemon=True).start()
+
+
+@worker_shutting_down.connect
+def on_worker_shutting_down(sender, **kwargs) -> None:
+    """Drain, giving the current scan WORKER_DRAIN_SECONDS before handing it off."""
+    if drain_timers:
+        return  # A second signal (cold shutdown) does not restart the drain period
+    registry.drain(sender)
+    timer = threading.Timer(settings.WORKER_DRAIN_SECONDS, hand_off, args=(sender,))
+    timer.daemon = True
+    timer.start()
+    drain_timers.append(timer)
+
+
+@worker_shutdown.connect
+def on_worker_shutdown(sender, **kwargs) -> None:
+    """Deregister once the current scan has finished."""
+    stopped.set()
+    for timer in drain_timers:
+        timer.cancel()
+    registry.deregister(sender.hostname)
+
 
 if __name__ == "__main__":
     celery_app.worker_main([
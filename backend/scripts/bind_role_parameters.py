@@ -0,0 +1,101 @@
+"""Bind role parameters to their values from a YAML or JSON file.
+
+Rules whose roles are read from configuration or a database at runtime,
+such as RequireRole(cfg.ApproverRole), are mined with the role
+"${cfg.ApproverRole}". The file maps each parameter to a role or list of
+roles, at the top level or under "parameters":
+
+    parameters:
+      cfg.ApproverRole: finance-approver
+      env.ADMIN_ROLES: [admin, superadmin]
+
+    python scripts/bind_role_parameters.py roles.yaml
+    python scripts/bind_role_parameters.py roles.staging.yaml --repository 3
+
+Reports the parameters still unbound afterwards. Exits non-zero when the
+file is invalid or the API rejects it.
+"""
+
+import argparse
+import logging
+import os
+import sys
+from pathlib import Path
+
+import httpx
+import yaml
+
+# Configure logging
+logging.basicConfig(
+    level=logging.INFO,
+    format="%(asctime)s - %(name)s - %(levelname)s - %(message)s",
+)
+logger = logging.getLogger(__name__)
+
+
+def load_bindings(path: Path) -> dict:
+    """Read a bindings file.
+
+    Raises:
+        ValueError: If the file does not map parameter names to values
+    """
+    document = yaml.safe_load(path.read_text()) or {}
+    bindings = document.get("parameters", document) if isinstance(document, dict) else document
+    if not isinstance(bindings, dict) or not all(isinstance(name, str) for name in bindings):
+        raise ValueError(f"{path} must map parameter names to a role or list of roles")
+    return bindings
+
+
+def request(client: httpx.Client, method: str, path: str, payload: dict | None = None, params: dict | None = None):
+    """Call the API, raising with the API's detail on an error response."""
+    response = client.request(method, path, json=payload, params=params)
+    if response.is_error:
+        try:
+            detail = response.json().get("detail", response.text)
+        except ValueError:
+            detail = response.text
+        raise RuntimeError(f"{method} {path} failed ({response.status_code}): {detail}")
+    return response.json()
+
+
+def main() -> None:
+    """Main entry point for CLI execution."""
+    parser = argparse.ArgumentParser(description=__doc__, formatter_class=argparse.RawDescriptionHelpFormatter)
+    parser.add_argument("bindings_file", type=Path, help="YAML or JSON file of parameter values")
+    parser.add_argument(
+        "--api-url",
+        default=os.environ.get("POLICY_MINER_API_URL", "http://localhost:8000"),
+        help="API base URL (default: $POLICY_MINER_API_URL)",
+    )
+    parser.add_argument(
+        "--token",
+        default=os.environ.get("POLICY_MINER_TOKEN"),
+        help="Bearer token of the workspace user (default: $POLICY_MINER_TOKEN)",
+    )
+    parser.add_argument("--repository", type=int, help="Bind for this repository only instead of workspace-wide")
+    args = parser.parse_args()
+
+    headers = {"Authorization": f"Bearer {args.token}"} if args.token else {}
+    try:
+        bindings = load_bindings(args.bindings_file)
+        with httpx.Client(base_url=f"{args.api_url.rstrip('/')}/api/v1", headers=headers, timeout=120) as client:
+            written = request(
+                client, "PUT", "/role-parameters/bindings", {"repository_id": args.repository, "bindings": bindings}
+            )
+            params = {"repository_id": args.repository} if args.repository is not None else None
+            parameters = request(client, "GET", "/role-parameters/", params=params)
+    except (OSError, ValueError, RuntimeError, httpx.HTTPError, yaml.YAMLError) as e:
+        logger.error(str(e))
+        sys.exit(1)
+
+    scope = f"repository {args.repository}" if args.repository is not None else "the workspace"
+    logger.info(f"Bound {len(written)} parameters for {scope}")
+    for binding in written:
+        logger.info(f"  {binding['name']} = {', '.join(binding['values'])}")
+    unbound = [p for p in parameters if not p["bound"]]
+    for parameter in unbound:
+        logger.warning(f"Unbound: {parameter['name']} ({len(parameter['policy_ids'])} policies)")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,54 @@
+"""Generate a synthetic sample-app corpus for analyzer benchmarking."""
+
+import argparse
+import json
+import logging
+import sys
+from dataclasses import asdict
+from pathlib import Path
+
+# Add backend directory to Python path
+backend_dir = Path(__file__).parent.parent
+sys.path.insert(0, str(backend_dir))
+
+# flake8: noqa: E402
+# Import after sys.path modification
+from tests.fixtures.sample_app_generator import RENDERERS, generate_corpus, write_corpus
+
+# Configure logging
+logging.basicConfig(
+    level=logging.INFO,
+    format="%(asctime)s - %(name)s - %(levelname)s - %(message)s",
+)
+logger = logging.getLogger(__name__)
+
+
+def main() -> None:
+    """Main entry point for CLI execution."""
+    parser = argparse.ArgumentParser(description=__doc__)
+    parser.add_argument("output_dir", type=Path, help="Directory to write the corpus to")
+    parser.add_argument("--framework", action="append", choices=sorted(RENDERERS), help="Frameworks to generate")
+    parser.add_argument("--seeds", type=int, default=3, help="Number of seeds per framework")
+    parser.add_argument("--rules", type=int, default=8, help="Endpoints per generated app")
+    args = parser.parse_args()
+
+    corpus = generate_corpus(args.framework, list(range(args.seeds)), args.rules)
+    paths = write_corpus(corpus, args.output_dir)
+
+    # Ground truth alongside the sources so external analyzers can be scored
+    manifest = {
+        sample.filename: {
+            "framework": sample.framework,
+            "language": sample.language,
+            "seed": sample.seed,
+            "expected_rules": [asdict(rule) for rule in sample.expected_rules],
+        }
+        for sample in corpus
+    }
+    (args.output_dir / "ground_truth.json").write_text(json.dumps(manifest, indent=2))
+
+    logger.info(f"Wrote {len(paths)} sample apps and ground_truth.json to {args.output_dir}")
+
+
+if __name__ == "__main__":
+    main()
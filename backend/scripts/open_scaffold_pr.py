@@ -0,0 +1,103 @@
+"""Open a pull request scaffolding a service's move to centralized policy.
+
+Adds the repository's approved policies, translated to Rego (an OPA
+bundle) or Cedar, and an integration stub wiring the service to the PDP.
+The stub's language is detected from the repository unless given:
+
+    python scripts/open_scaffold_pr.py 3
+    python scripts/open_scaffold_pr.py 3 --format cedar --language python --application 7
+    python scripts/open_scaffold_pr.py 3 --preview ./scaffold
+
+--preview writes the files to a local directory instead of opening a pull
+request. Exits non-zero when the API rejects the request or the Git host
+rejects the pull request.
+"""
+
+import argparse
+import logging
+import os
+import sys
+from pathlib import Path
+
+import httpx
+
+# Configure logging
+logging.basicConfig(
+    level=logging.INFO,
+    format="%(asctime)s - %(name)s - %(levelname)s - %(message)s",
+)
+logger = logging.getLogger(__name__)
+
+
+def request(client: httpx.Client, method: str, path: str, payload: dict | None = None, params: dict | None = None):
+    """Call the API, raising with the API's detail on an error response."""
+    response = client.request(method, path, json=payload, params=params)
+    if response.is_error:
+        try:
+            detail = response.json().get("detail", response.text)
+        except ValueError:
+            detail = response.text
+        raise RuntimeError(f"{method} {path} failed ({response.status_code}): {detail}")
+    return response.json()
+
+
+def write_preview(directory: Path, files: dict[str, str]) -> None:
+    """Write scaffold files under a directory, refusing paths that escape it."""
+    root = directory.resolve()
+    for name, content in files.items():
+        target = (root / name).resolve()
+        if not target.is_relative_to(root):
+            raise ValueError(f"Refusing to write {name} outside {directory}")
+        target.parent.mkdir(parents=True, exist_ok=True)
+        target.write_text(content)
+
+
+def main() -> None:
+    """Main entry point for CLI execution."""
+    parser = argparse.ArgumentParser(description=__doc__, formatter_class=argparse.RawDescriptionHelpFormatter)
+    parser.add_argument("repository_id", type=int, help="Repository to scaffold")
+    parser.add_argument("--format", choices=["rego", "cedar"], default="rego", help="Policy language (default: rego)")
+    parser.add_argument("--language", choices=["javascript", "python", "go", "java"], help="Integration stub language")
+    parser.add_argument("--application", type=int, help="Scaffold only this application's policies")
+    parser.add_argument("--preview", type=Path, help="Write the files here instead of opening a pull request")
+    parser.add_argument(
+        "--api-url",
+        default=os.environ.get("POLICY_MINER_API_URL", "http://localhost:8000"),
+        help="API base URL (default: $POLICY_MINER_API_URL)",
+    )
+    parser.add_argument(
+        "--token",
+        default=os.environ.get("POLICY_MINER_TOKEN"),
+        help="Bearer token of the workspace user (default: $POLICY_MINER_TOKEN)",
+    )
+    args = parser.parse_args()
+
+    headers = {"Authorization": f"Bearer {args.token}"} if args.token else {}
+    payload = {
+        "repository_id": args.repository_id,
+        "policy_format": args.format,
+        "language": args.language,
+        "application_id": args.application,
+    }
+    try:
+        with httpx.Client(base_url=f"{args.api_url.rstrip('/')}/api/v1", headers=headers, timeout=300) as client:
+            if args.preview:
+                scaffold = request(client, "POST", "/policy-scaffolds/preview", payload)
+                write_preview(args.preview, scaffold["files"])
+            else:
+                record = request(client, "POST", "/policy-scaffolds/", payload)
+    except (OSError, ValueError, RuntimeError, httpx.HTTPError) as e:
+        logger.error(str(e))
+        sys.exit(1)
+
+    if args.preview:
+        logger.info(f"Wrote {len(scaffold['files'])} files for {len(scaffold['policy_ids'])} policies to {args.preview}")
+        return
+    if record["status"] != "opened":
+        logger.error(f"Pull request failed: {record['error_message']}")
+        sys.exit(1)
+    logger.info(f"Opened {record['url']} ({len(record['policy_ids'])} policies, {record['language']} stub)")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,104 @@
+"""Sync custom detection rules from a YAML or JSON rules file to a workspace.
+
+The file holds a list of rule definitions, either at the top level or under
+"rules". Rules are matched by name; every rule is validated before anything
+is applied. Exits non-zero when the file is invalid or the API rejects it.
+
+    python scripts/sync_custom_rules.py rules.yaml --dry-run --repository 3
+    python scripts/sync_custom_rules.py rules.yaml --prune
+"""
+
+import argparse
+import logging
+import os
+import sys
+from pathlib import Path
+
+import httpx
+import yaml
+
+# Configure logging
+logging.basicConfig(
+    level=logging.INFO,
+    format="%(asctime)s - %(name)s - %(levelname)s - %(message)s",
+)
+logger = logging.getLogger(__name__)
+
+
+def load_rules(path: Path) -> list[dict]:
+    """Read a rules file.
+
+    Raises:
+        ValueError: If the file does not hold a list of rule definitions
+    """
+    document = yaml.safe_load(path.read_text()) or []
+    rules = document.get("rules", []) if isinstance(document, dict) else document
+    if not isinstance(rules, list) or not all(isinstance(rule, dict) for rule in rules):
+        raise ValueError(f"{path} must hold a list of rule definitions")
+    return rules
+
+
+def request(client: httpx.Client, method: str, path: str, payload: dict) -> dict:
+    """Call the API, raising with the API's detail on an error response."""
+    response = client.request(method, path, json=payload)
+    if response.is_error:
+        try:
+            detail = response.json().get("detail", response.text)
+        except ValueError:
+            detail = response.text
+        raise RuntimeError(f"{method} {path} failed ({response.status_code}): {detail}")
+    return response.json()
+
+
+def main() -> None:
+    """Main entry point for CLI execution."""
+    parser = argparse.ArgumentParser(description=__doc__, formatter_class=argparse.RawDescriptionHelpFormatter)
+    parser.add_argument("rules_file", type=Path, help="YAML or JSON rules file")
+    parser.add_argument(
+        "--api-url",
+        default=os.environ.get("POLICY_MINER_API_URL", "http://localhost:8000"),
+        help="API base URL (default: $POLICY_MINER_API_URL)",
+    )
+    parser.add_argument(
+        "--token",
+        default=os.environ.get("POLICY_MINER_TOKEN"),
+        help="Bearer token of the workspace user (default: $POLICY_MINER_TOKEN)",
+    )
+    parser.add_argument("--prune", action="store_true", help="Delete workspace rules the file does not define")
+    parser.add_argument("--dry-run", action="store_true", help="Report the changes without applying them")
+    parser.add_argument("--repository", type=int, help="Also evaluate the file's rules against this repository")
+    args = parser.parse_args()
+
+    headers = {"Authorization": f"Bearer {args.token}"} if args.token else {}
+    try:
+        rules = load_rules(args.rules_file)
+        with httpx.Client(base_url=f"{args.api_url.rstrip('/')}/api/v1", headers=headers, timeout=120) as client:
+            if args.repository is not None:
+                evaluation = request(
+                    client, "POST", "/custom-rules/dry-run", {"repository_id": args.repository, "rules": rules}
+                )
+                logger.info(
+                    f"Evaluated {len(rules)} rules against {evaluation['repository_name']} "
+                    f"({evaluation['files_evaluated']} files)"
+                )
+                for rule in evaluation["rules"]:
+                    logger.info(f"  {rule['name']}: {rule['hits']} hits in {rule['files']} files")
+                    for match in rule["matches"][:5]:
+                        logger.info(f"    {match['file_path']}:{match['line']}  {match['snippet']}")
+
+            result = request(
+                client, "PUT", "/custom-rules/sync", {"rules": rules, "prune": args.prune, "dry_run": args.dry_run}
+            )
+    except (OSError, ValueError, RuntimeError, httpx.HTTPError, yaml.YAMLError) as e:
+        logger.error(str(e))
+        sys.exit(1)
+
+    verb = "Would apply" if result["dry_run"] else "Applied"
+    logger.info(f"{verb}: {', '.join(f'{len(result[k])} {k}' for k in ('created', 'updated', 'unchanged', 'deleted'))}")
+    for key in ("created", "updated", "deleted"):
+        for name in result[key]:
+            logger.info(f"  {key}: {name}")
+
+
+if __name__ == "__main__":
+    main()
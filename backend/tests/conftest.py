@@ -1,9 +1,24 @@
 """Test configuration and fixtures."""
+from unittest.mock import MagicMock, Mock
+
 import pytest
 from sqlalchemy import create_engine
 from sqlalchemy.orm import sessionmaker
 
-from app.models.repository import Base
+from app.models.application import Application
+from app.models.policy import Evidence, ExtractionMethod, Policy, PolicyStatus, SourceType
+from app.models.repository import Base, Repository
+from tests.fixtures.snapshot import assert_snapshot, update_requested
+
+
+def pytest_addoption(parser):
+    """Register custom command-line options."""
+    parser.addoption(
+        "--update-snapshots",
+        action="store_true",
+        default=False,
+        help="Rewrite analyzer golden snapshots instead of comparing against them",
+    )
 
 
 @pytest.fixture
@@ -15,3 +30,110 @@ def db():
     session = testing_session_local()
     yield session
     session.close()
+
+
+@pytest.fixture
+def make_db():
+    """Build mock sessions whose queries return the given rows per model.
+
+    Filters chain; iterating, all(), and order_by().all() return the model's
+    rows, first() the first row, order_by().first() the last (the newest, for
+    services ordering by recency), and count() how many there are.
+    """
+
+    def build(rows: dict) -> MagicMock:
+        db = MagicMock()
+
+        def query(model):
+            found = rows.get(model, [])
+            q = MagicMock()
+            q.filter.return_value = q
+            q.__iter__.side_effect = lambda: iter(found)
+            q.all.return_value = found
+            q.order_by.return_value.all.return_value = found
+            q.order_by.return_value.first.return_value = found[-1] if found else None
+            q.first.return_value = found[0] if found else None
+            q.count.return_value = len(found)
+            return q
+
+        db.query.side_effect = query
+        return db
+
+    return build
+
+
+@pytest.fixture
+def make_policy():
+    """Build approved backend policies mined from one route registration, for the exporters."""
+
+    def build(
+        policy_id,
+        subject,
+        action,
+        snippet,
+        resource="Expense",
+        conditions=None,
+        confidence=90.0,
+        file_path="routes.js",
+        application=None,
+        line=10,
+        approval_comment=None,
+    ):
+        policy = Mock(spec=Policy)
+        policy.id = policy_id
+        policy.subject = subject
+        policy.resource = resource
+        policy.action = action
+        policy.conditions = conditions
+        policy.confidence_score = confidence
+        policy.extraction_method = ExtractionMethod.EXPLICIT_MIDDLEWARE
+        policy.status = PolicyStatus.APPROVED
+        policy.source_type = SourceType.BACKEND
+        policy.description = None
+        policy.approval_comment = approval_comment
+        policy.application_id = None
+        policy.application = None
+        if application:
+            policy.application = Mock(spec=Application)
+            policy.application.name = application
+        ev = Mock(spec=Evidence)
+        ev.code_snippet = snippet
+        ev.file_path = file_path
+        ev.line_start = ev.line_end = line
+        policy.evidence = [ev]
+        return policy
+
+    return build
+
+
+@pytest.fixture
+def make_export_service():
+    """Build an exporter over repository 4 in tenant acme whose policy query returns the given policies."""
+
+    def build(service_class, policies, *args, repository_name="Expense API"):
+        repository = Mock(spec=Repository)
+        repository.id, repository.name = 4, repository_name
+        db = MagicMock()
+
+        def query(model):
+            q = MagicMock()
+            q.filter.return_value = q
+            q.first.return_value = repository
+            q.order_by.return_value.all.return_value = policies
+            return q
+
+        db.query.side_effect = query
+        return service_class(db, "acme", *args)
+
+    return build
+
+
+@pytest.fixture
+def snapshot(request):
+    """Compare a value against its golden snapshot (or rewrite it in update mode)."""
+    update = update_requested(request.config.getoption("--update-snapshots"))
+
+    def check(name, value):
+        assert_snapshot(name, value, update=update)
+
+    return check
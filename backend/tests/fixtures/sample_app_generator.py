@@ -0,0 +1,540 @@
+"""
+Synthetic sample-app corpus generator for analyzer development.
+
+Generates small applications that exercise known authorization patterns for
+each supported framework, together with the ground-truth endpoint rules they
+encode. Output is fully determined by the seed, so a corpus can be regenerated
+anywhere and used as a reproducible accuracy benchmark for new analyzers.
+
+Usage:
+    app = generate_sample_app("go_mux", seed=7, rule_count=8)
+    score = score_endpoint_rules(app.expected_rules, my_analyzer(app.content))
+"""
+
+import random
+import re
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from app.services.endpoint_mapping_service import EndpointRule
+
+ROLES = ["EMPLOYEE", "MANAGER", "DIRECTOR", "ADMIN", "AUDITOR"]
+
+RESOURCES = ["expenses", "invoices", "reports", "projects", "customers", "contracts"]
+
+# (method, path suffix, handler verb)
+OPERATIONS = [
+    ("GET", "", "list"),
+    ("POST", "", "create"),
+    ("GET", "/{id}", "get"),
+    ("PUT", "/{id}", "update"),
+    ("DELETE", "/{id}", "delete"),
+    ("PUT", "/{id}/approve", "approve"),
+]
+
+# Attribute conditions: (ground-truth description, per-language check expression)
+CONDITIONS = [
+    (
+        "amount > 5000 requires DIRECTOR",
+        {
+            "python": 'item.amount > 5000 and not current_user.has_role("DIRECTOR")',
+            "javascript": 'item.amount > 5000 && !req.user.roles.includes("DIRECTOR")',
+            "java": 'item.getAmount() > 5000 && !user.hasRole("DIRECTOR")',
+            "csharp": 'item.Amount > 5000 && !User.IsInRole("DIRECTOR")',
+            "go": 'item.Amount > 5000 && !user.HasRole("DIRECTOR")',
+        },
+    ),
+    (
+        "department must be Finance",
+        {
+            "python": 'current_user.department != "Finance"',
+            "javascript": 'req.user.department !== "Finance"',
+            "java": '!"Finance".equals(user.getDepartment())',
+            "csharp": 'User.FindFirst("department")?.Value != "Finance"',
+            "go": 'user.Department != "Finance"',
+        },
+    ),
+    (
+        "user must own the resource",
+        {
+            "python": "item.owner_id != current_user.id",
+            "javascript": "item.ownerId !== req.user.id",
+            "java": "!item.getOwnerId().equals(user.getId())",
+            "csharp": 'item.OwnerId != User.FindFirst("sub")?.Value',
+            "go": "item.OwnerID != user.ID",
+        },
+    ),
+]
+
+FRAMEWORK_LANGUAGES = {
+    "flask": "python",
+    "fastapi": "python",
+    "express": "javascript",
+    "spring": "java",
+    "aspnet": "csharp",
+    "go_mux": "go",
+}
+
+FRAMEWORK_EXTENSIONS = {
+    "flask": ".py",
+    "fastapi": ".py",
+    "express": ".js",
+    "spring": ".java",
+    "aspnet": ".cs",
+    "go_mux": ".go",
+}
+
+
+@dataclass
+class SampleApp:
+    """A generated application and the authorization rules it encodes."""
+
+    framework: str
+    language: str
+    seed: int
+    filename: str
+    content: str
+    expected_rules: list[EndpointRule] = field(default_factory=list)
+
+
+@dataclass
+class BenchmarkScore:
+    """Accuracy of an analyzer against a sample app's ground truth."""
+
+    true_positives: int
+    false_positives: int
+    false_negatives: int
+    missing: list[str] = field(default_factory=list)
+    unexpected: list[str] = field(default_factory=list)
+    role_mismatches: list[str] = field(default_factory=list)
+
+    @property
+    def precision(self) -> float:
+        """Correct rules / extracted rules."""
+        extracted = self.true_positives + self.false_positives
+        return self.true_positives / extracted if extracted else 0.0
+
+    @property
+    def recall(self) -> float:
+        """Correct rules / expected rules."""
+        expected = self.true_positives + self.false_negatives
+        return self.true_positives / expected if expected else 0.0
+
+    @property
+    def f1(self) -> float:
+        """Harmonic mean of precision and recall."""
+        if self.precision + self.recall == 0:
+            return 0.0
+        return 2 * self.precision * self.recall / (self.precision + self.recall)
+
+
+def normalize_path(path: str) -> str:
+    """Normalize framework-specific route parameters to {name} form.
+
+    Args:
+        path: Route path (e.g., "/api/x/<int:id>", "/api/x/:id", "api/x/{id}")
+
+    Returns:
+        Normalized path with a leading slash
+    """
+    path = re.sub(r"<(?:\w+:)?(\w+)>", r"{\1}", path)
+    path = re.sub(r":(\w+)", r"{\1}", path)
+    if not path.startswith("/"):
+        path = "/" + path
+    return path.rstrip("/") or "/"
+
+
+def _singular(resource: str) -> str:
+    """Singular form of a resource name."""
+    return resource[:-1] if resource.endswith("s") else resource
+
+
+def _camel(*parts: str) -> str:
+    """Join parts as lowerCamelCase."""
+    head, *tail = parts
+    return head + "".join(p.capitalize() for p in tail)
+
+
+def _pascal(*parts: str) -> str:
+    """Join parts as PascalCase."""
+    return "".join(p.capitalize() for p in parts)
+
+
+def generate_rules(seed: int, rule_count: int = 8) -> list[EndpointRule]:
+    """Generate a deterministic set of ground-truth endpoint rules.
+
+    Args:
+        seed: Random seed
+        rule_count: Number of endpoints to generate
+
+    Returns:
+        Endpoint rules with unique method/path pairs
+    """
+    rng = random.Random(seed)
+    candidates = [
+        (resource, method, suffix, verb)
+        for resource in RESOURCES
+        for method, suffix, verb in OPERATIONS
+    ]
+    rng.shuffle(candidates)
+
+    rules: list[EndpointRule] = []
+    for resource, method, suffix, verb in candidates[:rule_count]:
+        # Roughly a quarter of endpoints accept any authenticated user
+        roles = [] if rng.random() < 0.25 else sorted(rng.sample(ROLES, rng.randint(1, 2)))
+        conditions = []
+        if verb in ("update", "approve", "get") and rng.random() < 0.4:
+            conditions = [rng.choice(CONDITIONS)[0]]
+        rules.append(
+            EndpointRule(
+                method=method,
+                path=f"/api/{resource}{suffix}",
+                roles=roles,
+                conditions=conditions,
+                resource=resource,
+                action=verb,
+            )
+        )
+
+    return sorted(rules, key=lambda r: (r.path, r.method))
+
+
+def _condition_expr(rule: EndpointRule, language: str) -> str | None:
+    """Look up the language-specific check for a rule's condition."""
+    if not rule.conditions:
+        return None
+    for description, expressions in CONDITIONS:
+        if description == rule.conditions[0]:
+            return expressions[language]
+    return None
+
+
+def _handler_name(rule: EndpointRule, style: str) -> str:
+    """Build a handler name for a rule."""
+    noun = rule.resource if rule.action == "list" else _singular(rule.resource)
+    if style == "snake":
+        return f"{rule.action}_{noun}"
+    if style == "camel":
+        return _camel(rule.action, noun)
+    return _pascal(rule.action, noun)
+
+
+def render_flask(rules: list[EndpointRule]) -> str:
+    """Render rules as a Flask application."""
+    lines = [
+        '"""Generated Flask sample app."""',
+        "from flask import Flask, abort, jsonify",
+        "from flask_login import current_user, login_required",
+        "",
+        "from auth import require_any_role, require_role",
+        "",
+        "app = Flask(__name__)",
+    ]
+    for rule in rules:
+        path = rule.path.replace("{id}", "<int:id>")
+        args = "id" if "{id}" in rule.path else ""
+        lines += ["", "", f'@app.{rule.method.lower()}("{path}")', "@login_required"]
+        if len(rule.roles) == 1:
+            lines.append(f'@require_role("{rule.roles[0]}")')
+        elif rule.roles:
+            lines.append("@require_any_role(" + ", ".join(f'"{r}"' for r in rule.roles) + ")")
+        lines.append(f"def {_handler_name(rule, 'snake')}({args}):")
+        lines.append(f"    item = {rule.resource}_repo.find({args or 'None'})")
+        condition = _condition_expr(rule, "python")
+        if condition:
+            lines += [f"    if {condition}:", "        abort(403)"]
+        lines.append("    return jsonify(item)")
+    return "\n".join(lines) + "\n"
+
+
+def render_fastapi(rules: list[EndpointRule]) -> str:
+    """Render rules as a FastAPI router."""
+    lines = [
+        '"""Generated FastAPI sample app."""',
+        "from fastapi import APIRouter, Depends, HTTPException",
+        "",
+        "from auth import get_current_user, require_roles",
+        "",
+        "router = APIRouter()",
+    ]
+    for rule in rules:
+        dependency = (
+            "require_roles(" + ", ".join(f'"{r}"' for r in rule.roles) + ")"
+            if rule.roles
+            else "get_current_user"
+        )
+        params = ["id: int"] if "{id}" in rule.path else []
+        params.append(f"current_user=Depends({dependency})")
+        lines += [
+            "",
+            "",
+            f'@router.{rule.method.lower()}("{rule.path}")',
+            f"async def {_handler_name(rule, 'snake')}({', '.join(params)}):",
+            f"    item = await {rule.resource}_repo.find({'id' if '{id}' in rule.path else 'None'})",
+        ]
+        condition = _condition_expr(rule, "python")
+        if condition:
+            lines += [f"    if {condition}:", '        raise HTTPException(status_code=403, detail="Forbidden")']
+        lines.append("    return item")
+    return "\n".join(lines) + "\n"
+
+
+def render_express(rules: list[EndpointRule]) -> str:
+    """Render rules as an Express router."""
+    lines = [
+        "// Generated Express sample app.",
+        "const express = require('express');",
+        "const { requireAuth, requireRole, requireAnyRole } = require('./auth');",
+        "",
+        "const router = express.Router();",
+    ]
+    for rule in rules:
+        path = rule.path.replace("{id}", ":id")
+        middleware = ["requireAuth"]
+        if len(rule.roles) == 1:
+            middleware.append(f"requireRole('{rule.roles[0]}')")
+        elif rule.roles:
+            middleware.append("requireAnyRole(" + ", ".join(f"'{r}'" for r in rule.roles) + ")")
+        lines += [
+            "",
+            f"router.{rule.method.lower()}('{path}', {', '.join(middleware)}, async (req, res) => {{",
+            f"  const item = await {rule.resource}Repo.find(req.params.id);",
+        ]
+        condition = _condition_expr(rule, "javascript")
+        if condition:
+            lines += [f"  if ({condition}) {{", "    return res.status(403).json({ error: 'Forbidden' });", "  }"]
+        lines += ["  res.json(item);", "});"]
+    lines += ["", "module.exports = router;"]
+    return "\n".join(lines) + "\n"
+
+
+def render_spring(rules: list[EndpointRule]) -> str:
+    """Render rules as a Spring Boot controller."""
+    lines = [
+        "// Generated Spring Boot sample app.",
+        "package com.example.generated;",
+        "",
+        "import org.springframework.security.access.AccessDeniedException;",
+        "import org.springframework.security.access.prepost.PreAuthorize;",
+        "import org.springframework.security.core.annotation.AuthenticationPrincipal;",
+        "import org.springframework.web.bind.annotation.*;",
+        "",
+        "@RestController",
+        "public class GeneratedController {",
+    ]
+    for rule in rules:
+        if not rule.roles:
+            expression = "isAuthenticated()"
+        elif len(rule.roles) == 1:
+            expression = f"hasRole('{rule.roles[0]}')"
+        else:
+            expression = "hasAnyRole(" + ", ".join(f"'{r}'" for r in rule.roles) + ")"
+        params = "@PathVariable Long id, " if "{id}" in rule.path else ""
+        lines += [
+            "",
+            f'    @{rule.method.capitalize()}Mapping("{rule.path}")',
+            f'    @PreAuthorize("{expression}")',
+            f"    public Object {_handler_name(rule, 'camel')}({params}@AuthenticationPrincipal User user) {{",
+            f"        Item item = {rule.resource}Repo.find({'id' if params else 'null'});",
+        ]
+        condition = _condition_expr(rule, "java")
+        if condition:
+            lines += [
+                f"        if ({condition}) {{",
+                '            throw new AccessDeniedException("Forbidden");',
+                "        }",
+            ]
+        lines += ["        return item;", "    }"]
+    lines.append("}")
+    return "\n".join(lines) + "\n"
+
+
+def render_aspnet(rules: list[EndpointRule]) -> str:
+    """Render rules as an ASP.NET Core controller."""
+    lines = [
+        "// Generated ASP.NET Core sample app.",
+        "using Microsoft.AspNetCore.Authorization;",
+        "using Microsoft.AspNetCore.Mvc;",
+        "",
+        "namespace Generated.Controllers",
+        "{",
+        "    [ApiController]",
+        "    public class GeneratedController : ControllerBase",
+        "    {",
+    ]
+    for rule in rules:
+        authorize = f'[Authorize(Roles = "{",".join(rule.roles)}")]' if rule.roles else "[Authorize]"
+        params = "int id" if "{id}" in rule.path else ""
+        lines += [
+            "",
+            f'        [Http{rule.method.capitalize()}("{rule.path.lstrip("/")}")]',
+            f"        {authorize}",
+            f"        public IActionResult {_handler_name(rule, 'pascal')}({params})",
+            "        {",
+            f"            var item = _{rule.resource}.Find({'id' if params else 'null'});",
+        ]
+        condition = _condition_expr(rule, "csharp")
+        if condition:
+            lines += [f"            if ({condition})", "            {", "                return Forbid();", "            }"]
+        lines += ["            return Ok(item);", "        }"]
+    lines += ["    }", "}"]
+    return "\n".join(lines) + "\n"
+
+
+def render_go_mux(rules: list[EndpointRule]) -> str:
+    """Render rules as a gorilla/mux application."""
+    handlers = []
+    routes = []
+    for rule in rules:
+        name = _handler_name(rule, "pascal")
+        if not rule.roles:
+            wrapped = f"RequireAuth({name})"
+        elif len(rule.roles) == 1:
+            wrapped = f'RequireRole("{rule.roles[0]}")({name})'
+        else:
+            wrapped = "RequireAnyRole(" + ", ".join(f'"{r}"' for r in rule.roles) + f")({name})"
+        routes.append(f'\tr.HandleFunc("{rule.path}", {wrapped}).Methods("{rule.method}")')
+
+        body = [
+            f"// {rule.method} {rule.path}",
+            f"func {name}(w http.ResponseWriter, r *http.Request) {{",
+            "\tuser := GetUserFromContext(r.Context())",
+            f'\titem := {rule.resource}Repo.Find(mux.Vars(r)["id"])',
+        ]
+        condition = _condition_expr(rule, "go")
+        if condition:
+            body += [
+                f"\tif {condition} {{",
+                '\t\thttp.Error(w, "Forbidden", http.StatusForbidden)',
+                "\t\treturn",
+                "\t}",
+            ]
+        body += ["\t_ = user", "\tjson.NewEncoder(w).Encode(item)", "}"]
+        handlers.append("\n".join(body))
+
+    return (
+        "// Generated gorilla/mux sample app.\n"
+        "package handlers\n\n"
+        'import (\n\t"encoding/json"\n\t"net/http"\n\n\t"github.com/gorilla/mux"\n)\n\n'
+        + "\n\n".join(handlers)
+        + "\n\nfunc RegisterRoutes(r *mux.Router) {\n"
+        + "\n".join(routes)
+        + "\n}\n"
+    )
+
+
+RENDERERS = {
+    "flask": render_flask,
+    "fastapi": render_fastapi,
+    "express": render_express,
+    "spring": render_spring,
+    "aspnet": render_aspnet,
+    "go_mux": render_go_mux,
+}
+
+
+def generate_sample_app(framework: str, seed: int = 0, rule_count: int = 8) -> SampleApp:
+    """Generate one sample application for a framework.
+
+    Args:
+        framework: One of RENDERERS
+        seed: Random seed controlling the generated rules
+        rule_count: Number of endpoints to generate
+
+    Returns:
+        SampleApp with source and ground truth
+
+    Raises:
+        ValueError: If the framework is not supported
+    """
+    if framework not in RENDERERS:
+        raise ValueError(f"Unsupported framework: {framework}")
+
+    rules = generate_rules(seed, rule_count)
+    return SampleApp(
+        framework=framework,
+        language=FRAMEWORK_LANGUAGES[framework],
+        seed=seed,
+        filename=f"generated_{framework}_{seed}{FRAMEWORK_EXTENSIONS[framework]}",
+        content=RENDERERS[framework](rules),
+        expected_rules=rules,
+    )
+
+
+def generate_corpus(
+    frameworks: list[str] | None = None, seeds: list[int] | None = None, rule_count: int = 8
+) -> list[SampleApp]:
+    """Generate a corpus of sample apps across frameworks and seeds.
+
+    Args:
+        frameworks: Frameworks to include (defaults to all)
+        seeds: Seeds to generate (defaults to [0, 1, 2])
+        rule_count: Number of endpoints per app
+
+    Returns:
+        Generated sample apps
+    """
+    return [
+        generate_sample_app(framework, seed, rule_count)
+        for framework in (frameworks or list(RENDERERS))
+        for seed in (seeds if seeds is not None else [0, 1, 2])
+    ]
+
+
+def write_corpus(corpus: list[SampleApp], output_dir: Path) -> list[Path]:
+    """Write a corpus to disk.
+
+    Args:
+        corpus: Sample apps to write
+        output_dir: Destination directory
+
+    Returns:
+        Paths of written files
+    """
+    output_dir.mkdir(parents=True, exist_ok=True)
+    paths = []
+    for sample in corpus:
+        path = output_dir / sample.filename
+        path.write_text(sample.content)
+        paths.append(path)
+    return paths
+
+
+def score_endpoint_rules(expected: list[EndpointRule], extracted: list[EndpointRule]) -> BenchmarkScore:
+    """Score extracted endpoint rules against ground truth.
+
+    An extracted rule is a true positive when its method and normalized path
+    match an expected rule and it grants exactly the same roles.
+
+    Args:
+        expected: Ground-truth rules
+        extracted: Rules produced by the analyzer under test
+
+    Returns:
+        BenchmarkScore
+    """
+    expected_by_key = {f"{r.method} {normalize_path(r.path)}": r for r in expected}
+    extracted_by_key = {f"{r.method.upper()} {normalize_path(r.path)}": r for r in extracted}
+
+    true_positives = 0
+    role_mismatches = []
+    for key, rule in extracted_by_key.items():
+        truth = expected_by_key.get(key)
+        if truth is None:
+            continue
+        if {r.upper() for r in rule.roles} == {r.upper() for r in truth.roles}:
+            true_positives += 1
+        else:
+            role_mismatches.append(key)
+
+    missing = sorted(set(expected_by_key) - set(extracted_by_key))
+    unexpected = sorted(set(extracted_by_key) - set(expected_by_key))
+
+    return BenchmarkScore(
+        true_positives=true_positives,
+        false_positives=len(unexpected) + len(role_mismatches),
+        false_negatives=len(missing) + len(role_mismatches),
+        missing=missing,
+        unexpected=unexpected,
+        role_mismatches=sorted(role_mismatches),
+    )
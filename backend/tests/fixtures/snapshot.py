@@ -0,0 +1,131 @@
+"""
+Golden-snapshot helpers for analyzer outputs.
+
+Each snapshot is a committed JSON file under tests/snapshots holding the
+canonical output of one analyzer on one fixture. Tests compare fresh output
+against it and fail with a readable diff on any change. Regenerate snapshots
+after an intentional change with:
+
+    pytest tests/test_analyzer_snapshots.py --update-snapshots
+    # or
+    UPDATE_SNAPSHOTS=1 pytest tests/test_analyzer_snapshots.py
+"""
+
+import dataclasses
+import difflib
+import json
+import os
+from enum import Enum
+from pathlib import Path
+from typing import Any
+
+SNAPSHOT_DIR = Path(__file__).parent.parent / "snapshots"
+
+
+class SnapshotMismatchError(AssertionError):
+    """Raised when analyzer output differs from its committed snapshot."""
+
+
+def update_requested(option_value: bool = False) -> bool:
+    """Check whether snapshots should be rewritten instead of compared.
+
+    Args:
+        option_value: Value of the --update-snapshots pytest option
+
+    Returns:
+        True if the option is set or UPDATE_SNAPSHOTS=1
+    """
+    return option_value or os.getenv("UPDATE_SNAPSHOTS", "").lower() in ("1", "true", "yes")
+
+
+def to_canonical(value: Any) -> Any:
+    """Convert analyzer output into a stable JSON-compatible structure.
+
+    Dataclasses become dicts, enums their values, tuples lists, and sets
+    sorted lists, so two runs with the same findings always serialize the
+    same way regardless of discovery order in sets.
+
+    Args:
+        value: Analyzer output
+
+    Returns:
+        JSON-compatible value
+    """
+    if dataclasses.is_dataclass(value) and not isinstance(value, type):
+        return to_canonical(dataclasses.asdict(value))
+    if isinstance(value, Enum):
+        return value.value
+    if isinstance(value, dict):
+        return {str(k): to_canonical(v) for k, v in value.items()}
+    if isinstance(value, set | frozenset):
+        return sorted((to_canonical(v) for v in value), key=lambda v: json.dumps(v, sort_keys=True))
+    if isinstance(value, list | tuple):
+        return [to_canonical(v) for v in value]
+    if isinstance(value, Path):
+        return value.as_posix()
+    return value
+
+
+def serialize(value: Any) -> str:
+    """Serialize canonical output as the snapshot file format."""
+    return json.dumps(to_canonical(value), indent=2, sort_keys=True, ensure_ascii=False) + "\n"
+
+
+def _summarize_list_changes(expected: Any, actual: Any) -> list[str]:
+    """Describe added/removed entries when both sides are lists of findings."""
+    if not isinstance(expected, list) or not isinstance(actual, list):
+        return []
+
+    expected_items = {json.dumps(item, sort_keys=True) for item in expected}
+    actual_items = {json.dumps(item, sort_keys=True) for item in actual}
+    lines = [f"  + {item}" for item in sorted(actual_items - expected_items)]
+    lines += [f"  - {item}" for item in sorted(expected_items - actual_items)]
+    if lines:
+        lines.insert(0, f"{len(actual_items - expected_items)} added, {len(expected_items - actual_items)} removed:")
+    return lines
+
+
+def assert_snapshot(name: str, value: Any, update: bool = False, snapshot_dir: Path = SNAPSHOT_DIR) -> None:
+    """Compare analyzer output against a committed snapshot.
+
+    Args:
+        name: Snapshot name (file is <name>.json)
+        value: Analyzer output
+        update: Rewrite the snapshot instead of comparing
+        snapshot_dir: Directory holding snapshots
+
+    Raises:
+        SnapshotMismatchError: If the snapshot is missing or differs
+    """
+    path = snapshot_dir / f"{name}.json"
+    actual = serialize(value)
+
+    if update:
+        path.parent.mkdir(parents=True, exist_ok=True)
+        if not path.exists() or path.read_text() != actual:
+            path.write_text(actual)
+        return
+
+    if not path.exists():
+        raise SnapshotMismatchError(
+            f"Snapshot {path.name} does not exist. Run with --update-snapshots to create it."
+        )
+
+    expected = path.read_text()
+    if expected == actual:
+        return
+
+    diff = difflib.unified_diff(
+        expected.splitlines(),
+        actual.splitlines(),
+        fromfile=f"snapshots/{path.name} (committed)",
+        tofile=f"snapshots/{path.name} (current)",
+        lineterm="",
+    )
+    summary = _summarize_list_changes(json.loads(expected), json.loads(actual))
+    raise SnapshotMismatchError(
+        "\n".join(
+            [f"Analyzer output changed for snapshot {name}.", *summary, "", *diff, ""]
+            + ["If this change is intended, rerun with --update-snapshots and commit the result."]
+        )
+    )
@@ -0,0 +1,186 @@
+"""
+Malformed and adversarial source generator for analyzer fuzzing.
+
+Starts from well-formed seed files (the committed sample apps plus generated
+sample-app corpora) and applies seeded mutations: truncation, random bytes,
+unbalanced brackets, deep nesting, very long lines, and patterns shaped to
+provoke regex backtracking. The same seed always yields the same inputs, so
+any crash found in CI can be replayed locally with FUZZ_SEED.
+"""
+
+import random
+from collections.abc import Callable, Iterator
+from dataclasses import dataclass
+from pathlib import Path
+
+from tests.fixtures.sample_app_generator import FRAMEWORK_LANGUAGES, generate_sample_app
+
+SAMPLE_APPS_DIR = Path(__file__).parent.parent / "test_data" / "sample_apps"
+
+# Committed sample apps by analyzer language
+SAMPLE_APP_FILES = {
+    "python": ["flask_app.py", "django_app.py", "fastapi_app.py"],
+    "javascript": ["express_app.js", "react_app.tsx", "angular_app.ts"],
+    "java": ["java_spring_app.java"],
+    "csharp": ["dotnet_app.cs"],
+    "go": ["go_app.go"],
+    "cobol": ["cobol_app.cbl"],
+}
+
+# Nesting openers per language for deep-nesting inputs
+NESTING = {
+    "python": ("if x:\n", "    "),
+    "javascript": ("if (x) {\n", "  "),
+    "java": ("if (x) {\n", "  "),
+    "csharp": ("if (x) {\n", "  "),
+    "go": ("if x {\n", "\t"),
+    "cobol": ("           IF X = 1\n", ""),
+}
+
+# Fragments shaped to stress the route/role regexes used by the analyzers
+BACKTRACKING_FRAGMENTS = [
+    'HandleFunc("/a", ',
+    '.get("/',
+    "@GetMapping(\"/",
+    '[HttpGet("',
+    "hasRole('",
+    "// GET /",
+    "@require_role(",
+    "EXEC CICS ",
+]
+
+
+@dataclass
+class FuzzInput:
+    """One fuzzed source file."""
+
+    name: str
+    language: str
+    content: str
+
+
+def load_seeds(language: str) -> list[str]:
+    """Load well-formed seed sources for a language.
+
+    Args:
+        language: Analyzer language
+
+    Returns:
+        Seed file contents
+    """
+    seeds = [
+        (SAMPLE_APPS_DIR / filename).read_text()
+        for filename in SAMPLE_APP_FILES.get(language, [])
+        if (SAMPLE_APPS_DIR / filename).exists()
+    ]
+    for framework, framework_language in FRAMEWORK_LANGUAGES.items():
+        if framework_language == language:
+            seeds.append(generate_sample_app(framework, seed=0).content)
+    return seeds
+
+
+class SourceFuzzer:
+    """Deterministic generator of malformed source files."""
+
+    def __init__(self, seed: int = 0):
+        """Initialize fuzzer.
+
+        Args:
+            seed: Random seed; the same seed always yields the same inputs
+        """
+        self.seed = seed
+        self.rng = random.Random(seed)
+
+    def truncate(self, content: str, language: str) -> str:
+        """Cut the file at a random point, mid-token."""
+        return content[: self.rng.randint(0, len(content))]
+
+    def random_bytes(self, content: str, language: str) -> str:
+        """Overwrite random positions with arbitrary code points."""
+        chars = list(content)
+        for _ in range(max(1, len(chars) // 50)):
+            if not chars:
+                break
+            code_point = self.rng.choice([0, 0xFFFD, 0x202E, self.rng.randint(1, 0x10FFF)])
+            chars[self.rng.randrange(len(chars))] = chr(code_point)
+        return "".join(chars)
+
+    def unbalance(self, content: str, language: str) -> str:
+        """Delete or duplicate brackets and quotes."""
+        chars = list(content)
+        targets = [i for i, c in enumerate(chars) if c in "(){}[]\"'`"]
+        for index in self.rng.sample(targets, min(len(targets), 20)):
+            chars[index] = self.rng.choice(["", chars[index] * 3])
+        return "".join(chars)
+
+    def deep_nesting(self, content: str, language: str) -> str:
+        """Append a very deeply nested block."""
+        opener, indent = NESTING.get(language, ("(", ""))
+        depth = self.rng.randint(200, 1000)
+        return content + "".join(indent * i + opener for i in range(depth))
+
+    def long_line(self, content: str, language: str) -> str:
+        """Insert one enormous line of repeated tokens."""
+        token = self.rng.choice(["a", "x = 1; ", "'", "\\", "/*", "<", " "])
+        position = self.rng.randint(0, len(content))
+        return content[:position] + token * self.rng.randint(50_000, 200_000) + content[position:]
+
+    def backtracking(self, content: str, language: str) -> str:
+        """Insert unterminated pattern prefixes repeated on one line."""
+        fragment = self.rng.choice(BACKTRACKING_FRAGMENTS)
+        return content + fragment * self.rng.randint(2_000, 5_000) + "\n"
+
+    def shuffle_lines(self, content: str, language: str) -> str:
+        """Reorder lines so declarations and bodies no longer line up."""
+        lines = content.splitlines()
+        self.rng.shuffle(lines)
+        return "\n".join(lines)
+
+    def mixed_newlines(self, content: str, language: str) -> str:
+        """Mix CRLF, CR, and form feeds into the file."""
+        return "".join(
+            self.rng.choice(["\r\n", "\r", "\n\f"]) if c == "\n" else c for c in content
+        )
+
+    @property
+    def mutations(self) -> list[Callable[[str, str], str]]:
+        """All mutation operators."""
+        return [
+            self.truncate,
+            self.random_bytes,
+            self.unbalance,
+            self.deep_nesting,
+            self.long_line,
+            self.backtracking,
+            self.shuffle_lines,
+            self.mixed_newlines,
+        ]
+
+    def generate(self, language: str, iterations: int = 1) -> Iterator[FuzzInput]:
+        """Generate fuzzed inputs for a language.
+
+        Every mutation is applied to every seed on each iteration, plus a few
+        fixed edge cases (empty file, binary junk).
+
+        Args:
+            language: Analyzer language
+            iterations: Rounds of mutation per seed
+
+        Yields:
+            FuzzInput instances
+        """
+        yield FuzzInput(name="empty", language=language, content="")
+        yield FuzzInput(
+            name="binary",
+            language=language,
+            content=bytes(self.rng.randrange(256) for _ in range(4096)).decode("latin-1"),
+        )
+
+        for seed_index, seed_content in enumerate(load_seeds(language)):
+            for iteration in range(iterations):
+                for mutation in self.mutations:
+                    yield FuzzInput(
+                        name=f"{language}-seed{seed_index}-{mutation.__name__}-{iteration}",
+                        language=language,
+                        content=mutation(seed_content, language),
+                    )
@@ -0,0 +1,134 @@
+"""Tests for lifting attribute checks in handler bodies into policy conditions."""
+import shutil
+from pathlib import Path
+from unittest.mock import MagicMock, Mock
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.abac_condition_service import AbacConditionService
+from app.services.condition_evaluation_service import ConditionEvaluationService
+from app.services.guard_condition_extractor import file_bindings, lift_condition, lift_guards
+
+GO_APP = Path(__file__).parent / "test_data" / "sample_apps" / "go_app.go"
+
+DJANGO = """APPROVAL_LIMIT = 10_000
+
+
+def approve(request, pk):
+    report = Report.objects.get(pk=pk)
+    limit = APPROVAL_LIMIT
+    is_cfo = request.user.has_role("cfo")
+    if report.total > limit and not is_cfo:
+        raise PermissionDenied("CFO approval required")
+    if report.approved:
+        raise PermissionDenied
+    if report.total > 0:
+        notify(report)
+    return render(request, "report.html")
+"""
+
+EXPRESS = """router.put('/documents/:id', async (req, res) => {
+  const doc = await Document.findById(req.params.id);
+  if (!doc) return res.sendStatus(404);
+  if (doc.ownerId !== req.user.id) return res.status(403).json({ error: 'forbidden' });
+  if (!req.user.roles.includes('editor')) {
+    return res.sendStatus(403);
+  }
+  if (doc.locked && doc.region != 'eu') throw new ForbiddenError();
+  res.json(await doc.save());
+});
+"""
+
+
+def test_guards_lift_through_assignments_and_constants():
+    """Test the sample approval limit, local and file bindings, and flags, owners, and role-only guards."""
+    lines = GO_APP.read_text().split("\n")
+    [approve] = lift_guards(lines, 94, 121)
+    assert (approve.line, approve.condition) == (107, "expense.amount > 5000 requires DIRECTOR")
+    clause = ConditionEvaluationService.parse_clause(approve.condition)
+    assert (clause.kind, clause.required_role, clause.inner.attribute, clause.inner.operator, clause.inner.value) == (
+        "implication",
+        "DIRECTOR",
+        "expense.amount",
+        ">",
+        5000,
+    )
+    [finance] = lift_guards(lines, 135, 152)
+    assert finance.condition == "user.department == Finance"
+
+    lines = DJANGO.split("\n")
+    limit, approved = lift_guards(lines, 3, len(lines), file_bindings(DJANGO))
+    assert (limit.line, limit.condition) == (8, "report.total > 10000 requires CFO")
+    assert (approved.line, approved.condition) == (10, "not approved")
+
+    # Not-found and role-only guards contribute nothing; conjunctions of attributes have no clause form
+    lines = EXPRESS.split("\n")
+    assert [c.condition for c in lift_guards(lines, 0, len(lines))] == ["user is owner of doc.owner_id"]
+    assert lift_condition("report.Total > limit || user == nil", {"limit": "5000"}) == ["report.total <= 5000"]
+    assert lift_condition("!expense.Submitted") == ["expense.submitted == true"]
+    assert lift_condition("user.Region != expense.Region") == ["user.region == expense.region"]
+
+    # Limits read from configuration stay parameters until a scan's config snapshot binds them
+    assert lift_condition('expense.Amount > cfg.MaxSelfApproveAmount && !user.HasRole("DIRECTOR")') == [
+        "expense.amount > ${cfg.MaxSelfApproveAmount} requires DIRECTOR"
+    ]
+    assert lift_condition("settings.MIN_AGE > user.Age") == ["user.age >= ${settings.MIN_AGE}"]
+    assert lift_condition("refund.Amount > limit", {"limit": 'os.Getenv("MAX_REFUND")'}) == ["refund.amount <= ${env.MAX_REFUND}"]
+
+
+def test_ownership_guards_become_owner_of_resource_conditions():
+    """Test owner comparisons across languages, role bypasses, and evaluation against the caller."""
+    assert lift_condition("expense.OwnerID != user.ID") == ["user is owner of expense.owner_id"]
+    assert lift_condition("!expense.getOwnerId().equals(user.getId())") == ["user is owner of expense.owner_id"]
+    assert lift_condition("expense.OwnerId != User.FindFirstValue(ClaimTypes.NameIdentifier)") == ["user is owner of expense.owner_id"]
+    assert lift_condition("String(doc.author) !== String(req.user._id)") == ["user is owner of doc.author"]
+    assert lift_condition("@post.user != current_user") == ["user is owner of post.user"]
+    assert lift_condition("doc.ownerId !== req.user.id && !req.user.roles.includes('admin')") == [
+        "user is owner of doc.owner_id unless ADMIN"
+    ]
+    # The caller owning the object is not a denial
+    assert lift_condition("expense.OwnerID == user.ID") == []
+
+    clause = ConditionEvaluationService.parse_clause("user is owner of doc.owner_id unless ADMIN")
+    assert (clause.kind, clause.attribute, clause.required_role) == ("owner_of_resource", "doc.owner_id", "ADMIN")
+    owned = {"owner_id": "u1"}
+    assert [
+        ConditionEvaluationService.evaluate(clause.raw, owned, roles, subject_id=subject).satisfied
+        for roles, subject in (([], "u1"), ([], "u2"), (["ADMIN"], "u2"), ([], None))
+    ] == [True, False, True, None]
+
+
+def test_lifted_conditions_are_added_to_the_policies_of_their_handlers(tmp_path):
+    """Test handlers are found through a cited route table and directly, and stated clauses are not repeated."""
+    (tmp_path / "3" / "handlers").mkdir(parents=True)
+    shutil.copy(GO_APP, tmp_path / "3" / "handlers" / "expenses.go")
+    lines = GO_APP.read_text().split("\n")
+
+    approve = Policy(id=1, repository_id=3, subject="MANAGER or DIRECTOR", resource="/api/expenses/{id}/approve", action="PUT")
+    approve.evidence = [Evidence(file_path="handlers/expenses.go", line_start=154, line_end=160, code_snippet="\n".join(lines[153:160]))]
+    report = Policy(id=2, repository_id=3, subject="Authenticated", resource="/api/reports/financial", action="GET", conditions="None")
+    report.evidence = [Evidence(file_path="handlers/expenses.go", line_start=136, line_end=151, code_snippet="\n".join(lines[135:151]))]
+    stated = Policy(id=3, repository_id=3, subject="DIRECTOR", resource="/api/expenses/{id}/approve", action="PUT")
+    stated.conditions = "amount > 5000 requires DIRECTOR"
+    stated.evidence = [Evidence(file_path="handlers/expenses.go", line_start=157, line_end=157, code_snippet=lines[156])]
+    missing = Policy(id=4, repository_id=3, subject="ADMIN", resource="/api/users", action="GET")
+    missing.evidence = [Evidence(file_path="../outside.go", line_start=1, line_end=3, code_snippet="")]
+
+    repo = Mock(spec=Repository, id=3, tenant_id="acme")
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    db.query.return_value.filter.return_value.all.return_value = [approve, report, stated, missing]
+
+    result = AbacConditionService(db, "acme", str(tmp_path)).lift_conditions(3)
+
+    assert (result["policies_examined"], result["policies_updated"]) == (4, 2)
+    assert approve.conditions == "expense.amount > 5000 requires DIRECTOR"
+    assert [(e.line_start, e.code_snippet) for e in approve.evidence][-1] == (107, 'if expense.Amount > 5000 && !user.HasRole("DIRECTOR") {')
+    assert report.conditions == "user.department == Finance"
+    assert stated.conditions == "amount > 5000 requires DIRECTOR"
+    assert [(c["policy_id"], c["kind"], c["attribute"], c["value"], c["required_role"], c["added"]) for c in result["conditions"]] == [
+        (1, "implication", "expense.amount", 5000, "DIRECTOR", True),
+        (2, "comparison", "department", "Finance", None, True),
+        (3, "implication", "expense.amount", 5000, "DIRECTOR", False),
+    ]
+    db.commit.assert_called_once()
@@ -0,0 +1,143 @@
+"""Tests for access review campaign generation and attestation."""
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.access_review import (
+    AccessReviewCampaign,
+    AccessReviewItem,
+    AccessReviewPacket,
+    AttestationDecision,
+    CampaignStatus,
+    PacketStatus,
+)
+from app.models.application import Application
+from app.models.policy import Evidence, Policy, PolicyStatus
+from app.services.access_review_service import UNASSIGNED_REVIEWER, AccessReviewService
+
+
+def make_policy(policy_id, resource, action, application=None):
+    """Create an approved policy with one evidence record."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = "MANAGER"
+    policy.resource = resource
+    policy.action = action
+    policy.conditions = None
+    policy.status = PolicyStatus.APPROVED
+    policy.application = application
+    policy.application_id = application.id if application else None
+    evidence = Mock(spec=Evidence)
+    evidence.file_path = "app.py"
+    evidence.line_start = 10
+    evidence.line_end = 12
+    evidence.code_snippet = "@require_role('MANAGER')"
+    policy.evidence = [evidence]
+    return policy
+
+
+def mock_db(result):
+    """Create a session whose chained queries return the given result."""
+    query = MagicMock()
+    query.filter.return_value = query
+    query.join.return_value = query
+    query.all.return_value = result if isinstance(result, list) else [result]
+    query.first.return_value = result
+    db = MagicMock()
+    db.query.return_value = query
+    return db
+
+
+def make_campaign(item_count=2):
+    """Create an open campaign with one packet of pending items."""
+    campaign = AccessReviewCampaign(id=1, name="Q3", status=CampaignStatus.OPEN)
+    packet = AccessReviewPacket(id=1, reviewer="owner@example.com", status=PacketStatus.PENDING)
+    packet.campaign = campaign
+    campaign.packets = [packet]
+    packet.items = []
+    for index in range(item_count):
+        item = AccessReviewItem(
+            id=index + 1,
+            subject="MANAGER",
+            resource="Expense",
+            action="approve",
+            decision=AttestationDecision.PENDING,
+        )
+        item.packet = packet
+        packet.items.append(item)
+    return campaign
+
+
+def test_create_campaign_groups_packets_by_owner():
+    """Test that packets are generated per application owner with evidence."""
+    billing = Mock(spec=Application)
+    billing.id = 1
+    billing.name = "Billing"
+    billing.owner = "owner@example.com"
+    policies = [
+        make_policy(1, "Invoice", "approve", billing),
+        make_policy(2, "Invoice", "delete", billing),
+        make_policy(3, "Report", "view"),
+    ]
+    service = AccessReviewService(mock_db(policies), tenant_id="tenant-1")
+
+    campaign = service.create_campaign("Q3 2026 access review", created_by="auditor@example.com")
+
+    assert campaign.status == CampaignStatus.OPEN
+    reviewers = {p.reviewer: p for p in campaign.packets}
+    assert set(reviewers) == {"owner@example.com", UNASSIGNED_REVIEWER}
+    assert len(reviewers["owner@example.com"].items) == 2
+    assert reviewers["owner@example.com"].application_name == "Billing"
+    item = reviewers[UNASSIGNED_REVIEWER].items[0]
+    assert item.policy_id == 3
+    assert item.evidence[0]["code_snippet"] == "@require_role('MANAGER')"
+    assert item.decision == AttestationDecision.PENDING
+
+
+def test_create_campaign_requires_policies():
+    """Test that an empty scope is rejected."""
+    service = AccessReviewService(mock_db([]), tenant_id="tenant-1")
+    with pytest.raises(ValueError, match="No policies"):
+        service.create_campaign("Empty review")
+
+
+def test_attestation_completes_packet_and_campaign():
+    """Test that attesting the last item completes the packet and campaign."""
+    campaign = make_campaign()
+    first, second = campaign.packets[0].items
+
+    AccessReviewService(mock_db(first)).attest(1, AttestationDecision.CERTIFIED, "owner@example.com")
+    assert campaign.packets[0].status == PacketStatus.PENDING
+    assert campaign.status == CampaignStatus.OPEN
+
+    AccessReviewService(mock_db(second)).attest(
+        2, AttestationDecision.REVOKE, "owner@example.com", comment="Role no longer needed"
+    )
+    assert campaign.packets[0].status == PacketStatus.COMPLETED
+    assert campaign.status == CampaignStatus.COMPLETED
+    assert campaign.completed_at is not None
+
+    summary = AccessReviewService.summarize(campaign)
+    assert summary["progress"] == 100.0
+    assert summary["decisions"]["certified"] == 1
+    assert summary["decisions"]["revoke"] == 1
+    assert AccessReviewService.remediation_items(campaign) == [second]
+
+
+@pytest.mark.parametrize(
+    "decision,comment,status,message",
+    [
+        (AttestationDecision.REVOKE, None, CampaignStatus.OPEN, "comment is required"),
+        (AttestationDecision.PENDING, None, CampaignStatus.OPEN, "must certify"),
+        (AttestationDecision.CERTIFIED, None, CampaignStatus.CANCELLED, "cancelled"),
+    ],
+)
+def test_invalid_attestations(decision, comment, status, message):
+    """Test that incomplete attestations and closed campaigns are rejected."""
+    campaign = make_campaign()
+    campaign.status = status
+    item = campaign.packets[0].items[0]
+
+    with pytest.raises(ValueError, match=message):
+        AccessReviewService(mock_db(item)).attest(1, decision, "owner@example.com", comment=comment)
+    assert item.decision == AttestationDecision.PENDING
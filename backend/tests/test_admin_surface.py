@@ -0,0 +1,162 @@
+"""Tests for admin surface mapping."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.admin_surface_service import (
+    AdminSurfaceKind,
+    AdminSurfaceService,
+    admin_endpoints,
+    extract_surfaces,
+    surface_kind,
+)
+
+DJANGO_URLS = """from django.contrib import admin
+urlpatterns = [
+    path('staff-admin/', admin.site.urls),
+    path('api/', include('api.urls')),
+]
+"""
+
+FLASK_ADMIN = """from flask_admin import Admin
+admin = Admin(app, name='ops', url='/backstage')
+"""
+
+APPLICATION_YML = """management:
+  server:
+    port: 8081
+  endpoints:
+    web:
+      exposure:
+        include: health,env,heapdump
+"""
+
+DEPLOYMENT = """apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: api
+        ports:
+        - name: http
+          containerPort: 8080
+        - name: admin
+          containerPort: 9000
+"""
+
+PPROF = """import _ "net/http/pprof"
+
+func main() {
+	go http.ListenAndServe("localhost:6060", nil)
+}
+"""
+
+
+def make_policy(policy_id, subject, snippet):
+    """Create a policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = "User"
+    policy.action = "manage"
+    policy.conditions = None
+    policy.description = None
+    ev = Mock(spec=Evidence)
+    ev.code_snippet = snippet
+    ev.file_path = "app.js"
+    ev.line_start = 1
+    policy.evidence = [ev]
+    return policy
+
+
+POLICIES = [
+    make_policy(1, "ADMIN", "router.delete('/admin/users/:id', requireRole('ADMIN'), remove)"),
+    make_policy(2, "Authenticated users", "router.get('/api/v1/internal/flags', requireAuth, flags)"),
+    make_policy(3, "Anonymous", "app.get('/actuator/env', env)"),
+    make_policy(4, "Authenticated users", "router.get('/orders', requireAuth, list)"),
+]
+
+
+def test_surface_kind_classifies_path_prefixes():
+    """Test admin and management prefixes, including after API version segments."""
+    assert surface_kind("/admin/users/:id") == AdminSurfaceKind.ADMIN_PATH
+    assert surface_kind("/api/v2/backoffice/refunds") == AdminSurfaceKind.ADMIN_PATH
+    assert surface_kind("/actuator/heapdump") == AdminSurfaceKind.MANAGEMENT_ENDPOINT
+    assert surface_kind("/orders/:id/admins") is None
+    assert surface_kind("/") is None
+
+
+def test_extract_framework_modules_and_management_ports():
+    """Test Django admin, Flask-Admin, Actuator exposure, container ports, and pprof."""
+    [django] = extract_surfaces("urls.py", DJANGO_URLS)
+    assert (django.framework, django.path, django.line_start, django.builtin_protection) == (
+        "django-admin",
+        "/staff-admin",
+        3,
+        "staff login (is_staff)",
+    )
+    [flask] = extract_surfaces("admin.py", FLASK_ADMIN)
+    assert (flask.path, flask.builtin_protection) == ("/backstage", None)
+
+    actuator = extract_surfaces("src/main/resources/application.yml", APPLICATION_YML)
+    assert [(s.kind, s.path, s.port) for s in actuator] == [
+        ("management_endpoint", "/actuator/health", None),
+        ("management_endpoint", "/actuator/env", None),
+        ("management_endpoint", "/actuator/heapdump", None),
+        ("management_port", None, 8081),
+    ]
+
+    [port] = extract_surfaces("k8s/deployment.yaml", DEPLOYMENT)
+    assert (port.port, port.snippet, port.line_start) == (9000, "admin: 9000", 12)
+
+    pprof = extract_surfaces("main.go", PPROF)
+    assert [(s.kind, s.path, s.port, s.loopback_only) for s in pprof] == [
+        ("management_endpoint", "/debug/pprof", None, False),
+        ("management_port", None, 6060, True),
+    ]
+    assert extract_surfaces("app.py", "def administer(): pass") == []
+
+
+def test_admin_endpoints_report_protection_status():
+    """Test protection statuses for mapped endpoints and modules no policy covers."""
+    surfaces = extract_surfaces("urls.py", DJANGO_URLS) + extract_surfaces("admin.py", FLASK_ADMIN)
+    surfaces += extract_surfaces("application.yml", APPLICATION_YML)
+    endpoints = {e["endpoint"]: e for e in admin_endpoints(POLICIES, surfaces)}
+
+    assert "GET /orders" not in endpoints
+    assert endpoints["DELETE /admin/users/:id"]["protection"] == "role_restricted"
+    assert endpoints["DELETE /admin/users/:id"]["exposed"] is False
+    assert endpoints["GET /api/v1/internal/flags"]["protection"] == "authenticated"
+    env = endpoints["GET /actuator/env"]
+    assert (env["protection"], env["framework"], env["surface"]) == ("public", "spring-actuator", "management_endpoint")
+    assert endpoints["* /staff-admin"]["protection"] == "framework_default"
+    assert endpoints["* /backstage"]["protection"] == "no_policy"
+    assert endpoints["* /actuator/heapdump"]["exposed"] is True
+
+
+def test_services_inventory_from_clone(tmp_path):
+    """Test the per-service inventory reads the clone and lists exposed endpoints first."""
+    (tmp_path / "7" / "config").mkdir(parents=True)
+    (tmp_path / "7" / "config" / "application.yml").write_text(APPLICATION_YML)
+    (tmp_path / "7" / "main.go").write_text(PPROF)
+    (tmp_path / "7" / "vendor").mkdir()
+    (tmp_path / "7" / "vendor" / "urls.py").write_text(DJANGO_URLS)
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 7, "billing"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+
+    service = AdminSurfaceService(db, "acme", clone_dir=str(tmp_path))
+    with patch("app.services.admin_surface_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        [report] = service.services(repository_id=7)
+        assert service.services(exposed_only=True)[0]["repository_id"] == 7
+
+    assert report["cloned"] is True
+    assert report["management_ports"] == [6060, 8081]
+    assert not any(s["framework"] == "django-admin" for s in report["surfaces"])
+    assert report["exposed_endpoints"] == 5
+    assert [e["exposed"] for e in report["endpoints"]] == [True] * 5 + [False]
+    assert report["endpoints"][-1]["endpoint"] == "DELETE /admin/users/:id"
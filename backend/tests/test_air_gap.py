@@ -0,0 +1,95 @@
+"""Tests for air-gapped operation mode."""
+import socket
+from unittest.mock import patch
+
+import pytest
+
+from app.core import air_gap
+from app.core.air_gap import (
+    AirGapViolation,
+    ensure_git_url_allowed,
+    ensure_host_allowed,
+    ensure_llm_allowed,
+    install_egress_guard,
+    uninstall_egress_guard,
+)
+from app.core.config import settings
+from app.services.llm_provider import AirGappedProvider, get_llm_provider
+
+
+@pytest.fixture
+def air_gapped():
+    """Enable air-gapped mode with one in-enclave host."""
+    with (
+        patch.object(settings, "AIR_GAPPED", True),
+        patch.object(settings, "AIR_GAP_ALLOWED_HOSTS", ["git.enclave.local"]),
+        patch.object(settings, "DATABASE_URL", "postgresql://u:p@postgres:5432/db"),
+        patch.object(settings, "REDIS_URL", "redis://redis:6379"),
+        patch.object(settings, "MINIO_ENDPOINT", "minio:9000"),
+    ):
+        yield
+
+
+def test_checks_are_noops_when_connected():
+    """Test nothing is blocked when air-gapped mode is off."""
+    with patch.object(settings, "AIR_GAPPED", False):
+        ensure_host_allowed("api.github.com", "test")
+        ensure_git_url_allowed("https://github.com/org/repo.git", "test")
+        ensure_llm_allowed("test")
+
+
+def test_allowed_hosts_include_backing_services(air_gapped):
+    """Test loopback, backing services, and configured hosts are reachable."""
+    for host in ("localhost", "127.0.0.1", "::1", "postgres", "redis", "minio", "GIT.enclave.local"):
+        ensure_host_allowed(host, "test")
+
+    with pytest.raises(AirGapViolation, match="database_scanner attempted to reach db.example.com"):
+        ensure_host_allowed("db.example.com", "database_scanner")
+
+
+def test_git_urls(air_gapped):
+    """Test local and in-enclave remotes pass while external remotes fail."""
+    for url in (
+        "/srv/repos/app",
+        "file:///srv/repos/app",
+        "https://git.enclave.local/org/app.git",
+        "git@git.enclave.local:org/app.git",
+    ):
+        ensure_git_url_allowed(url, "scanner")
+
+    for url in ("https://github.com/org/app.git", "git@github.com:org/app.git", "ssh://git@gitlab.com/org/app"):
+        with pytest.raises(AirGapViolation):
+            ensure_git_url_allowed(url, "scanner")
+
+
+def test_llm_provider_refuses_calls(air_gapped):
+    """Test the LLM provider is replaced with one that fails every call."""
+    provider = get_llm_provider()
+
+    assert isinstance(provider, AirGappedProvider)
+    with pytest.raises(AirGapViolation, match="LLM calls are disabled"):
+        provider.create_message("Extract policies")
+
+
+def test_egress_guard_blocks_external_sockets(air_gapped):
+    """Test the socket guard blocks external hosts but allows loopback."""
+    server = socket.socket(socket.AF_INET, socket.SOCK_STREAM)
+    server.bind(("127.0.0.1", 0))
+    server.listen(1)
+    install_egress_guard()
+    try:
+        client = socket.create_connection(server.getsockname(), timeout=1)
+        client.close()
+
+        with pytest.raises(AirGapViolation, match="name lookup outside the enclave"):
+            socket.getaddrinfo("api.anthropic.com", 443)
+
+        sock = socket.socket(socket.AF_INET, socket.SOCK_STREAM)
+        with pytest.raises(AirGapViolation, match="203.0.113.10:443"):
+            sock.connect(("203.0.113.10", 443))
+        sock.close()
+    finally:
+        uninstall_egress_guard()
+        server.close()
+
+    assert socket.socket.connect is air_gap._original_connect
@@ -0,0 +1,289 @@
+"""Fuzz analyzers with malformed and adversarial source files.
+
+Each analyzer must survive every fuzzed input without raising and within a
+bounded time. Scale up locally or in nightly CI with:
+
+    FUZZ_ITERATIONS=20 FUZZ_SEED=1234 pytest tests/test_analyzer_fuzzing.py
+"""
+import os
+import time
+from collections.abc import Callable
+
+import pytest
+
+from app.services.admin_surface_service import extract_surfaces
+from app.services.aspnet_route_extractor import extract_aspnet_routes
+from app.services.auth_gap_service import route_registrations
+from app.services.bola_detection_service import detect_bola
+from app.services.casbin_extractor import extract_casbin_usage
+from app.services.cli_command_extractor import extract_cli_commands
+from app.services.cobol_scanner_service import CobolScannerService
+from app.services.cors_csrf_service import extract_cors, extract_csrf
+from app.services.delegated_check_extractor import calls, predicate_roles, role_checks
+from app.services.django_route_extractor import extract_django_routes
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.entry_point_extractor import extract_entry_points
+from app.services.environment_comparison_service import find_gated_checks
+from app.services.exposure_service import extract_gateway_signals, extract_manifest_signals
+from app.services.fastapi_route_extractor import extract_fastapi_routes
+from app.services.fastify_route_extractor import extract_fastify_routes
+from app.services.go_route_extractor import extract_go_routes
+from app.services.graphql_route_extractor import extract_graphql_routes
+from app.services.grpc_route_extractor import extract_grpc_routes
+from app.services.guard_condition_extractor import file_bindings, lift_guards
+from app.services.jvm_route_extractor import extract_jvm_routes
+from app.services.k8s_manifest_service import parse_documents
+from app.services.mass_assignment_service import detect_mass_assignment, parse_models
+from app.services.nestjs_route_extractor import extract_nestjs_routes
+from app.services.node_route_extractor import extract_node_routes
+from app.services.opa_query_extractor import extract_opa_queries
+from app.services.play_route_extractor import extract_play_routes
+from app.services.policy_annotation_extractor import extract_annotations
+from app.services.rails_route_extractor import extract_rails_routes
+from app.services.rate_limit_extractor import extract_rate_limits
+from app.services.readiness_service import find_auth_helpers, find_policy_engines, find_reflection, find_routes
+from app.services.realtime_route_extractor import extract_realtime_routes
+from app.services.response_exposure_service import FieldClassifier, load_visibility_policy, response_fields, serializer_models
+from app.services.secret_detection_service import SecretDetectionService
+from app.services.service_call_extractor import extract_service_calls
+from app.services.spring_route_extractor import extract_spring_routes
+from app.services.tenant_isolation_service import tenant_checks, tenant_resources
+from app.services.typescript_route_extractor import extract_typescript_routes
+from app.services.vulnerable_auth_pattern_service import detect_patterns
+from tests.fixtures.source_fuzzer import SourceFuzzer
+
+FUZZ_SEED = int(os.getenv("FUZZ_SEED", "0"))
+FUZZ_ITERATIONS = int(os.getenv("FUZZ_ITERATIONS", "1"))
+MAX_SECONDS_PER_INPUT = float(os.getenv("FUZZ_MAX_SECONDS_PER_INPUT", "2"))
+
+LANGUAGES = ["python", "javascript", "java", "csharp", "go", "cobol"]
+
+
+def _tree_sitter_analyzer(module: str, cls: str, method: str) -> Callable[[str], object]:
+    """Build an analyzer callable for a tree-sitter scanner, skipping if unavailable."""
+    pytest.importorskip("tree_sitter_languages")
+    scanner_module = __import__(f"app.services.{module}", fromlist=[cls])
+    scanner = getattr(scanner_module, cls)()
+
+    def analyze(content: str) -> object:
+        if method == "analyze_file":
+            return scanner.analyze_file(content, "fuzz")
+        if scanner.has_authorization_code(content):
+            return scanner.extract_authorization_details(content, "fuzz")
+        return []
+
+    return analyze
+
+
+def _cobol_analyzer() -> Callable[[str], object]:
+    """Build the COBOL analyzer callable."""
+    scanner = CobolScannerService()
+
+    def analyze(content: str) -> object:
+        scanner.has_authorization_code(content)
+        return scanner.extract_authorization_details(content, "fuzz")
+
+    return analyze
+
+
+# Fuzzed content is given every file type the rate-limit extractors dispatch on
+RATE_LIMIT_FILE_NAMES = [
+    "fuzz.js", "fuzz.py", "Fuzz.cs", "Fuzz.java", "fuzz.php", "fuzz.rb", "fuzz.go", "nginx.conf", "kong.yml", "app.properties",
+]  # fmt: skip
+
+# Fuzzed content is given every file type the entry point extractors dispatch on
+ENTRY_POINT_FILE_NAMES = ["Fuzz.java", "fuzz.ts", "fuzz.py", "fuzz.rb", "fuzz.go", "cronjob.yaml"]
+
+# Analyzer name -> (languages it handles, factory)
+def _response_exposure_analyzer() -> Callable[[str], object]:
+    """Build the response exposure analyzer callable."""
+    classify = FieldClassifier(load_visibility_policy(""))
+
+    def analyze(content: str) -> object:
+        found = []
+        for name in ("fuzz.go", "fuzz.py", "fuzz.ts", "Fuzz.java", "Fuzz.cs", "fuzz.rb"):
+            models = {m.name: m for m in parse_models(name, f"type User struct {{\n\tSalary int\n}}\n{content}", lambda f: True, True)}
+            models.update(serializer_models({name: content}, models))
+            lines = content.split("\n")
+            found.append(response_fields(lines, 0, len(lines), models, classify))
+        return found
+
+    return analyze
+
+
+ANALYZERS: dict[str, tuple[list[str], Callable[[], Callable[[str], object]]]] = {
+    "endpoint_mapping": (LANGUAGES, lambda: EndpointMappingService.find_routes),
+    "admin_surface": (LANGUAGES, lambda: lambda c: (extract_surfaces("fuzz.go", c), extract_surfaces("fuzz.yml", c))),
+    "entry_points": (LANGUAGES, lambda: lambda c: [extract_entry_points(n, c) for n in ENTRY_POINT_FILE_NAMES]),
+    "cli_commands": (LANGUAGES, lambda: lambda c: extract_cli_commands({"fuzz.go": c, "fuzz.py": c, "fuzz.rb": c})),
+    "exposure": (
+        LANGUAGES,
+        lambda: lambda c: (
+            [extract_gateway_signals(n, c) for n in ("serverless.yml", "main.tf")],
+            extract_manifest_signals(parse_documents("fuzz.yaml", c)),
+        ),
+    ),
+    "environment_gates": (LANGUAGES, lambda: lambda c: find_gated_checks("fuzz", c)),
+    "cors_csrf": (LANGUAGES, lambda: lambda c: (extract_cors("fuzz", c), extract_csrf("fuzz", c))),
+    "aspnet_routes": (
+        ["csharp"],
+        lambda: lambda c: extract_aspnet_routes({"Program.cs": f"using Microsoft.AspNetCore.Authorization;\n{c}"}),
+    ),
+    "rails_routes": (
+        LANGUAGES,
+        lambda: lambda c: extract_rails_routes(
+            {"config/routes.rb": f"Rails.application.routes.draw do\n{c}\nend", "app/controllers/fuzz_controller.rb": f"class FuzzController < ApplicationController\n{c}"}
+        ),
+    ),
+    "django_routes": (
+        ["python"],
+        lambda: lambda c: extract_django_routes({"app/urls.py": f"from django.urls import path\n{c}", "app/views.py": c}),
+    ),
+    "fastapi_routes": (
+        ["python"],
+        lambda: lambda c: extract_fastapi_routes({"app/main.py": f"from fastapi import FastAPI\n{c}", "app/deps.py": c}),
+    ),
+    "fastify_routes": (
+        ["javascript"],
+        lambda: lambda c: extract_fastify_routes({"fuzz.js": f"const fastify = require('fastify')();\n{c}"}),
+    ),
+    "gin_routes": (["go"], lambda: lambda c: extract_go_routes({"fuzz.go": f"import \"github.com/gin-gonic/gin\"\n{c}"})),
+    "echo_routes": (["go"], lambda: lambda c: extract_go_routes({"fuzz.go": f"import \"github.com/labstack/echo/v4\"\n{c}"})),
+    "chi_routes": (["go"], lambda: lambda c: extract_go_routes({"fuzz.go": f"import \"github.com/go-chi/chi/v5\"\n{c}"})),
+    "grpc_routes": (["go"], lambda: lambda c: extract_grpc_routes({"fuzz.go": f"import \"google.golang.org/grpc\"\n{c}"})),
+    "graphql_routes": (
+        LANGUAGES,
+        lambda: lambda c: extract_graphql_routes(
+            {"schema.graphql": c, "resolvers.ts": f"import {{ gql }} from 'graphql-tag';\n{c}", "schema.resolvers.go": f"package graph\n{c}"}
+        ),
+    ),
+    "jvm_routes": (["java"], lambda: lambda c: extract_jvm_routes({"Fuzz.java": c})),
+    "nestjs_routes": (
+        ["javascript"],
+        lambda: lambda c: extract_nestjs_routes({"fuzz.controller.ts": f"import {{ Controller }} from '@nestjs/common';\n{c}"}),
+    ),
+    "node_routes": (["javascript"], lambda: lambda c: extract_node_routes({"fuzz.js": c})),
+    "play_routes": (["java"], lambda: lambda c: extract_play_routes({"Fuzz.scala": c, "conf/routes": c})),
+    "policy_annotations": (
+        LANGUAGES,
+        lambda: lambda c: extract_annotations("fuzz", f"// policy: role=admin resource=order action=read\n{c}"),
+    ),
+    "realtime_routes": (
+        LANGUAGES,
+        lambda: lambda c: extract_realtime_routes({"fuzz.go": c, "fuzz.js": c, "Fuzz.java": c, "fuzz.py": c}),
+    ),
+    "readiness_patterns": (
+        LANGUAGES,
+        lambda: lambda c: (
+            find_routes("fuzz.js", c),
+            find_reflection("fuzz.py", c),
+            find_auth_helpers("fuzz.go", c),
+            find_policy_engines("fuzz.py", c),
+        ),
+    ),
+    "spring_routes": (
+        ["java"],
+        lambda: lambda c: extract_spring_routes({"Fuzz.java": f"import org.springframework.security.config.annotation.web.builders.HttpSecurity;\n{c}"}),
+    ),
+    "typescript_routes": (
+        ["javascript"],
+        lambda: lambda c: extract_typescript_routes(
+            {"fuzz.ts": f"import express from 'express';\nimport {{ Get }} from 'routing-controllers';\n{c}"}
+        ),
+    ),
+    "service_calls": (
+        LANGUAGES,
+        lambda: lambda c: extract_service_calls({"fuzz.py": c, "fuzz.js": c, "Fuzz.java": c, "fuzz.go": c, "Fuzz.cs": c}),
+    ),
+    "rate_limits": (LANGUAGES, lambda: lambda c: extract_rate_limits({name: c for name in RATE_LIMIT_FILE_NAMES})),
+    "vulnerable_auth_patterns": (
+        LANGUAGES,
+        lambda: lambda c: [
+            detect_patterns(name, f"{header}\n{c}")
+            for name, header in (("fuzz.py", "import jwt"), ("fuzz.js", "const express = require('express');"), ("Fuzz.java", "class HttpSecurity {}"), ("fuzz.go", ""))
+        ],
+    ),
+    "bola": (LANGUAGES, lambda: lambda c: detect_bola("fuzz", c)),
+    "casbin": (
+        LANGUAGES,
+        lambda: lambda c: extract_casbin_usage(
+            {"main.go": f'import "github.com/casbin/casbin/v2"\n{c}', "authz.py": f"import casbin\n{c}"},
+            {"model.conf": c, "policy.csv": c},
+        ),
+    ),
+    "opa_queries": (
+        LANGUAGES,
+        lambda: lambda c: extract_opa_queries(
+            {"server.go": c, "app.py": f'requests.post("http://opa:8181/v1/data/authz")\n{c}'},
+            {"authz.rego": f"package authz\n{c}"},
+        ),
+    ),
+    "mass_assignment": (
+        LANGUAGES,
+        lambda: lambda c: [
+            detect_mass_assignment(name, c, {m.name: m for m in parse_models(name, f"type User struct {{\n\tRole string\n}}\n{c}")})
+            for name in ("fuzz.go", "fuzz.py", "fuzz.ts", "Fuzz.java", "Fuzz.cs", "fuzz.rb", "db/schema.rb", "fuzz.php")
+        ],
+    ),
+    "delegated_checks": (
+        LANGUAGES,
+        lambda: lambda c: [
+            calls(c.split("\n"), 0, len(c)),
+            predicate_roles(c.split("\n"), 0, len(c)),
+            role_checks(c.split("\n"), 0, len(c), lambda name: ["ADMIN"], helper=True),
+        ],
+    ),
+    "response_exposure": (LANGUAGES, _response_exposure_analyzer),
+    "tenant_isolation": (
+        LANGUAGES,
+        lambda: lambda c: [
+            tenant_checks(c.split("\n"), 0, len(c), "Expense"),
+            tenant_resources(c.split("\n"), 0, len(c), {"Expense": "tenant_id", "OrderItem": "org_id"}),
+        ],
+    ),
+    "auth_gaps": (LANGUAGES, lambda: route_registrations),
+    "guard_conditions": (LANGUAGES, lambda: lambda c: lift_guards(c.split("\n"), 0, len(c), file_bindings(c))),
+    "secret_detection": (LANGUAGES, lambda: lambda c: SecretDetectionService.scan_content(c, "fuzz")),
+    "cobol": (["cobol"], _cobol_analyzer),
+    "python": (["python"], lambda: _tree_sitter_analyzer("python_scanner_service", "PythonScannerService", "")),
+    "java": (["java"], lambda: _tree_sitter_analyzer("java_scanner_service", "JavaScannerService", "")),
+    "csharp": (["csharp"], lambda: _tree_sitter_analyzer("csharp_scanner_service", "CSharpScannerService", "")),
+    "javascript": (
+        ["javascript"],
+        lambda: _tree_sitter_analyzer("javascript_scanner", "JavaScriptScannerService", "analyze_file"),
+    ),
+}
+
+CASES = [(name, language) for name, (languages, _) in ANALYZERS.items() for language in languages]
+
+
+@pytest.mark.parametrize("analyzer_name,language", CASES)
+def test_analyzer_survives_fuzzed_input(analyzer_name, language):
+    """Analyzer never raises and finishes each input within the time budget."""
+    _, factory = ANALYZERS[analyzer_name]
+    analyze = factory()
+    fuzzer = SourceFuzzer(seed=FUZZ_SEED)
+
+    for fuzz_input in fuzzer.generate(language, FUZZ_ITERATIONS):
+        start = time.monotonic()
+        try:
+            analyze(fuzz_input.content)
+        except Exception as e:
+            pytest.fail(
+                f"{analyzer_name} raised {type(e).__name__} on {fuzz_input.name} "
+                f"(replay with FUZZ_SEED={FUZZ_SEED}): {e}"
+            )
+        elapsed = time.monotonic() - start
+        assert elapsed < MAX_SECONDS_PER_INPUT, (
+            f"{analyzer_name} took {elapsed:.1f}s on {fuzz_input.name} "
+            f"(replay with FUZZ_SEED={FUZZ_SEED})"
+        )
+
+
+def test_fuzzer_is_deterministic():
+    """Same seed yields identical inputs, so failures can be replayed."""
+    first = [i.content for i in SourceFuzzer(seed=7).generate("go")]
+    second = [i.content for i in SourceFuzzer(seed=7).generate("go")]
+
+    assert first == second
+    assert len(first) > 2
@@ -0,0 +1,84 @@
+"""Golden-snapshot tests for deterministic analyzer output on fixture apps.
+
+Every fixture app under tests/test_data has a committed snapshot per
+analyzer in tests/snapshots. To add an analyzer, register it in
+SNAPSHOT_ANALYZERS and run with --update-snapshots to record its baseline.
+"""
+from collections.abc import Callable
+from pathlib import Path
+
+import pytest
+
+from app.services.cobol_scanner_service import CobolScannerService
+from app.services.endpoint_mapping_service import EndpointMappingService
+from tests.fixtures.snapshot import SnapshotMismatchError, assert_snapshot, serialize
+
+TEST_DATA_DIR = Path(__file__).parent / "test_data"
+
+FIXTURE_APPS = sorted(
+    [p for p in (TEST_DATA_DIR / "sample_apps").iterdir() if p.is_file()]
+    + [TEST_DATA_DIR / "reference_app_with_known_policies.py"]
+)
+
+
+def routes_snapshot(content: str, path: Path) -> list[dict]:
+    """Canonical route registrations."""
+    return [{"method": m, "path": p} for m, p in EndpointMappingService.find_routes(content)]
+
+
+def cobol_snapshot(content: str, path: Path) -> list[dict]:
+    """Canonical COBOL authorization details."""
+    return CobolScannerService().extract_authorization_details(content, path.name)
+
+
+# Analyzer name -> (file suffixes it applies to, canonical output function)
+SNAPSHOT_ANALYZERS: dict[str, tuple[set[str] | None, Callable[[str, Path], object]]] = {
+    "routes": (None, routes_snapshot),
+    "cobol": ({".cbl", ".cob", ".cpy"}, cobol_snapshot),
+}
+
+CASES = [
+    (analyzer, path)
+    for analyzer, (suffixes, _) in SNAPSHOT_ANALYZERS.items()
+    for path in FIXTURE_APPS
+    if suffixes is None or path.suffix in suffixes
+]
+
+
+@pytest.mark.parametrize("analyzer,fixture_path", CASES)
+def test_analyzer_output_matches_snapshot(analyzer, fixture_path, snapshot):
+    """Analyzer output on each fixture app matches its committed snapshot."""
+    _, produce = SNAPSHOT_ANALYZERS[analyzer]
+    output = produce(fixture_path.read_text(), fixture_path)
+
+    snapshot(f"{fixture_path.stem}.{analyzer}", output)
+
+
+class TestSnapshotHelpers:
+    """Tests for the snapshot framework itself."""
+
+    def test_serialization_is_order_independent(self):
+        """Test that sets and dict ordering do not affect snapshots."""
+        assert serialize({"b": {3, 1, 2}, "a": (1,)}) == serialize({"a": [1], "b": {2, 3, 1}})
+
+    def test_update_then_match(self, tmp_path):
+        """Test that update mode writes a snapshot that then matches."""
+        assert_snapshot("example", [{"method": "GET"}], update=True, snapshot_dir=tmp_path)
+        assert_snapshot("example", [{"method": "GET"}], snapshot_dir=tmp_path)
+
+    def test_missing_snapshot_fails(self, tmp_path):
+        """Test that a missing snapshot fails rather than silently passing."""
+        with pytest.raises(SnapshotMismatchError, match="update-snapshots"):
+            assert_snapshot("missing", [], snapshot_dir=tmp_path)
+
+    def test_mismatch_reports_diff(self, tmp_path):
+        """Test that mismatches include added/removed findings and a unified diff."""
+        assert_snapshot("routes", [{"method": "GET", "path": "/a"}], update=True, snapshot_dir=tmp_path)
+
+        with pytest.raises(SnapshotMismatchError) as exc_info:
+            assert_snapshot("routes", [{"method": "GET", "path": "/b"}], snapshot_dir=tmp_path)
+
+        message = str(exc_info.value)
+        assert "1 added, 1 removed" in message
+        assert '+ {"method": "GET", "path": "/b"}' in message
+        assert '-    "path": "/a"' in message
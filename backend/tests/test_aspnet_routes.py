@@ -0,0 +1,209 @@
+"""Tests for ASP.NET Core authorization mining."""
+from app.services.aspnet_route_extractor import AspNetRouteKind, extract_aspnet_routes
+
+PROGRAM = """using Microsoft.AspNetCore.Authorization;
+
+var builder = WebApplication.CreateBuilder(args);
+
+builder.Services.AddCors(options => options.AddPolicy("Admins", policy => policy.AllowAnyOrigin()));
+builder.Services.AddAuthorization(options =>
+{
+    options.AddPolicy(Policies.CanRefund, policy => policy.RequireRole(Roles.Admin, "Support").RequireClaim("scope", "orders.refund"));
+    options.AddPolicy("Adults", policy =>
+    {
+        policy.RequireAuthenticatedUser();
+        policy.AddRequirements(new MinimumAgeRequirement(21));
+    });
+});
+
+var app = builder.Build();
+app.MapControllers();
+app.Run();
+"""
+
+CONSTANTS = """namespace Shop;
+
+public static class Roles
+{
+    public const string Admin = "Admin";
+}
+
+public static class Policies
+{
+    public const string CanRefund = "CanRefund";
+}
+"""
+
+CONTROLLER = """using Microsoft.AspNetCore.Authorization;
+using Microsoft.AspNetCore.Mvc;
+
+[ApiController]
+[Route("api/[controller]")]
+[Authorize]
+public class OrdersController : ControllerBase
+{
+    private readonly IOrders _orders = new Orders();
+
+    public OrdersController(IOrders orders) => _orders = orders;
+
+    [HttpGet("{id:int}")]
+    public IActionResult Get(int id) => Ok(_orders.Find(id));
+
+    // [HttpDelete] on a commented-out action is ignored
+    [HttpPost("{id}/refund"), Authorize(Policy = Policies.CanRefund)]
+    public async Task<IActionResult> Refund([FromRoute] int id)
+    {
+        if (id < 0) { return BadRequest(); }
+        return Ok();
+    }
+
+    [HttpDelete("{id}")]
+    [Authorize(Roles = "Admin, Ops")]
+    [Authorize(Roles = Roles.Admin + "Lead")]
+    public IActionResult Delete(int id) => NoContent();
+
+    [HttpGet("/health")]
+    [AllowAnonymous]
+    public IActionResult Health() => Ok();
+
+    [Authorize("Adults")]
+    [HttpGet("wine")]
+    public IActionResult Wine() => Ok();
+
+    [HttpGet("legacy")]
+    [Authorize(Policy = "Legacy")]
+    public IActionResult Legacy() => Ok();
+
+    [NonAction]
+    public void Helper() { }
+}
+"""
+
+
+def _by_route(findings):
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_controller_attributes_combine_and_resolve_registered_policies():
+    """Test class and action [Authorize] attributes are all required, policies resolve, and [AllowAnonymous] wins."""
+    files = {"Program.cs": PROGRAM, "Shop/Constants.cs": CONSTANTS, "Shop/Controllers/OrdersController.cs": CONTROLLER, "README.md": "[Authorize]"}
+    findings = extract_aspnet_routes(files)
+    assert {f.kind for f in findings} == {AspNetRouteKind.CONTROLLER_ROUTE}
+    routes = _by_route(findings)
+
+    assert sorted(routes) == [
+        ("DELETE", "/api/Orders/{id}"),
+        ("GET", "/api/Orders/legacy"),
+        ("GET", "/api/Orders/wine"),
+        ("GET", "/api/Orders/{id}"),
+        ("GET", "/health"),
+        ("POST", "/api/Orders/{id}/refund"),
+    ]
+    get = routes[("GET", "/api/Orders/{id}")]
+    assert (get.subject, get.conditions) == ("Authenticated users", None)
+    assert get.description == "ASP.NET Core action OrdersController.Get secured by [Authorize] on OrdersController"
+    assert (get.line_start, get.line_end) == (13, 14)
+
+    refund = routes[("POST", "/api/Orders/{id}/refund")]
+    assert refund.subject == "Admin or Support"
+    assert refund.conditions == "requires claim scope = orders.refund"
+    assert refund.description.endswith("[Authorize] on OrdersController and [Authorize(Policy = Policies.CanRefund)]")
+
+    delete = routes[("DELETE", "/api/Orders/{id}")]
+    assert delete.subject == "Admin or Ops"
+    assert delete.conditions == "also holds role AdminLead"
+    assert routes[("GET", "/api/Orders/wine")].conditions == "passes requirement MinimumAgeRequirement(21)"
+    assert routes[("GET", "/api/Orders/legacy")].conditions == "passes policy Legacy, which no AddAuthorization call registers"
+
+    health = routes[("GET", "/health")]
+    assert (health.subject, health.disables_auth) == ("Anonymous", True)
+    assert health.description.endswith("secured by [AllowAnonymous], which overrides [Authorize] on OrdersController")
+
+
+def test_minimal_api_groups_pass_metadata_to_their_endpoints():
+    """Test RequireAuthorization() and AllowAnonymous() on endpoints, groups, and lambda handlers."""
+    program = """using Microsoft.AspNetCore.Authorization;
+
+var builder = WebApplication.CreateBuilder(args);
+builder.Services.AddAuthorizationBuilder()
+    .AddPolicy("Staff", policy => policy.RequireRole("Staff"))
+    .AddPolicy("Writers", policy => policy.RequireClaim("permission", "posts.write", "posts.admin"));
+var app = builder.Build();
+
+app.MapGet("/", () => "hello");
+app.MapGet("/me", (ClaimsPrincipal user) => user.Identity!.Name).RequireAuthorization();
+
+var api = app.MapGroup("/api").RequireAuthorization("Staff");
+var posts = api.MapGroup("/posts/{blog:guid}");
+posts.RequireAuthorization("Writers");
+posts.MapPost("/", CreatePost).WithName("CreatePost");
+posts.MapGet("/feed", GetFeed).AllowAnonymous();
+api.MapMethods("/reports", new[] { "GET", "HEAD" }, GetReports)
+    .RequireAuthorization(new AuthorizeAttribute { Roles = "Auditor" });
+app.MapDelete("/cache", [Authorize(Roles = "Ops")] () => Results.NoContent());
+app.MapPut("/flags", () => Results.Ok()).RequireAuthorization(policy => policy.RequireUserName("release-bot"));
+"""
+    findings = extract_aspnet_routes({"Program.cs": program})
+    assert {f.kind for f in findings} == {AspNetRouteKind.MINIMAL_API}
+    routes = _by_route(findings)
+
+    # The unprotected "/" endpoint is not a finding
+    assert sorted(routes) == [
+        ("DELETE", "/cache"),
+        ("GET", "/api/posts/{blog}/feed"),
+        ("GET", "/api/reports"),
+        ("GET", "/me"),
+        ("HEAD", "/api/reports"),
+        ("POST", "/api/posts/{blog}"),
+        ("PUT", "/flags"),
+    ]
+    assert routes[("GET", "/me")].subject == "Authenticated users"
+    assert routes[("GET", "/me")].description == "ASP.NET Core minimal API endpoint MapGet secured by .RequireAuthorization()"
+    create = routes[("POST", "/api/posts/{blog}")]
+    assert create.subject == "Staff"
+    assert create.conditions == "requires claim permission = posts.write or posts.admin"
+    assert create.description.endswith(
+        'secured by .RequireAuthorization("Staff") on group /api and .RequireAuthorization("Writers") on group /api/posts/{blog}'
+    )
+    feed = routes[("GET", "/api/posts/{blog}/feed")]
+    assert (feed.subject, feed.disables_auth) == ("Anonymous", True)
+    reports = routes[("HEAD", "/api/reports")]
+    assert (reports.subject, reports.conditions) == ("Staff", "also holds role Auditor")
+    assert (reports.line_start, reports.line_end) == (17, 18)
+    assert routes[("DELETE", "/cache")].subject == "Ops"
+    assert routes[("PUT", "/flags")].conditions == "user name is release-bot"
+
+
+def test_fallback_default_policies_and_controller_conventions():
+    """Test the FallbackPolicy covers endpoints without metadata and the DefaultPolicy backs bare [Authorize]."""
+    program = """using Microsoft.AspNetCore.Authorization;
+
+var builder = WebApplication.CreateBuilder(args);
+builder.Services.AddAuthorization(options =>
+{
+    options.DefaultPolicy = new AuthorizationPolicyBuilder("Bearer").RequireAuthenticatedUser().AddAuthenticationSchemes("Bearer").Build();
+    options.FallbackPolicy = options.DefaultPolicy;
+});
+var app = builder.Build();
+app.MapGet("/status", () => "ok");
+app.MapControllers().RequireAuthorization(policy => policy.RequireRole("Employee"));
+"""
+    controller = """using Microsoft.AspNetCore.Mvc;
+
+public class HomeController : Controller
+{
+    public IActionResult Index() => View();
+
+    [HttpPost]
+    public IActionResult Contact(ContactForm form) => View();
+}
+"""
+    routes = _by_route(extract_aspnet_routes({"Program.cs": program, "Controllers/HomeController.cs": controller}))
+
+    status = routes[("GET", "/status")]
+    assert (status.subject, status.conditions) == ("Authenticated users", "authenticated by scheme Bearer")
+    assert status.description == "ASP.NET Core minimal API endpoint MapGet secured by the FallbackPolicy"
+    index = routes[("*", "/Home/Index")]
+    assert index.subject == "Employee"
+    assert index.description.endswith('secured by .RequireAuthorization(policy => policy.RequireRole("Employee")) on MapControllers()')
+    assert routes[("POST", "/Home/Contact")].kind == AspNetRouteKind.CONTROLLER_ROUTE
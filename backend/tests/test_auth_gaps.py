@@ -0,0 +1,88 @@
+"""Tests for reporting registered routes with no detectable authentication or authorization."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.auth_gap_service import AuthGapService, Protection, route_registrations
+
+GO_ROUTES = """package api
+
+func RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/health", Health).Methods("GET")
+	r.HandleFunc("/api/expenses", RequireAuth(ListExpenses)).Methods("GET")
+	r.HandleFunc("/api/expenses/{id}", DeleteExpense).Methods("DELETE")
+	r.HandleFunc("/api/reports", Reports).Methods("GET")
+}
+"""
+
+EXPRESS = """const router = require('express').Router();
+
+router.get('/status', status);
+router.use(passport.authenticate('jwt', { session: false }));
+router.post('/payments', createPayment);
+"""
+
+CONTROLLER = """[Authorize]
+[ApiController]
+public class InvoicesController : ControllerBase
+{
+    [HttpGet("/invoices")]
+    public IActionResult List() => Ok();
+
+    [AllowAnonymous]
+    [HttpGet("/invoices/public")]
+    public IActionResult Public() => Ok();
+}
+"""
+
+
+def test_registrations_carry_the_protection_visible_in_the_file():
+    """Test guards on the registration, mounted middleware, controller guards, and public markers."""
+    go = route_registrations(GO_ROUTES)
+    assert [(r.method, r.path, r.line, r.protection) for r in go] == [
+        ("GET", "/health", 4, None),
+        ("GET", "/api/expenses", 5, Protection.REGISTRATION),
+        ("DELETE", "/api/expenses/{id}", 6, None),
+        ("GET", "/api/reports", 7, None),
+    ]
+    assert go[2].code == 'r.HandleFunc("/api/expenses/{id}", DeleteExpense).Methods("DELETE")'
+
+    status, payments = route_registrations(EXPRESS)
+    assert (status.path, status.protection) == ("/status", None)
+    assert (payments.path, payments.protection, payments.evidence_line) == ("/payments", Protection.MIDDLEWARE, 4)
+
+    listed, public = route_registrations(CONTROLLER)
+    assert (listed.path, listed.protection, listed.evidence_line) == ("/invoices", Protection.CONTROLLER, 1)
+    assert (public.path, public.protection, public.evidence_line) == ("/invoices/public", Protection.PUBLIC, 8)
+    assert route_registrations("def handler(): pass") == []
+
+
+def test_gaps_are_prioritized_with_registration_evidence(tmp_path):
+    """Test mined policies as protection, severity and priority ordering, and the summary counts."""
+    (tmp_path / "3" / "api").mkdir(parents=True)
+    (tmp_path / "3" / "api" / "routes.go").write_text(GO_ROUTES)
+    (tmp_path / "3" / "node_modules").mkdir()
+    (tmp_path / "3" / "node_modules" / "routes.go").write_text(GO_ROUTES)
+    reports = Mock(spec=Policy, id=8, repository_id=3, subject="ANALYST", resource="/api/reports", action="GET", conditions=None)
+    reports.description = None
+    reports.evidence = [Mock(spec=Evidence, file_path="api/handlers.go", line_start=12, code_snippet='r.HandleFunc("/api/reports", RequireRole("ANALYST")(Reports)).Methods("GET")')]
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 3, "expenses"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    query.all.return_value = [reports]
+    service = AuthGapService(db, "acme", clone_dir=str(tmp_path))
+
+    result = service.gaps()
+    delete, health = result["gaps"]
+    assert (delete["endpoint"], delete["file_path"], delete["line"], delete["repository_name"]) == (
+        "DELETE /api/expenses/{id}", "api/routes.go", 6, "expenses"
+    )
+    assert delete["priority"] > health["priority"]
+    assert delete["description"].startswith("DELETE /api/expenses/{id} is registered at api/routes.go:6 with no authentication")
+    assert (health["severity"], health["conventionally_public"]) == ("low", True)
+    assert result["summary"]["by_protection"] == {"none": 2, "registration": 1, "policy": 1}
+    assert (result["summary"]["routes"], result["summary"]["gaps"], result["summary"]["repositories_scanned"]) == (4, 2, 1)
+    assert [g["endpoint"] for g in service.gaps(severity="LOW")["gaps"]] == ["GET /health"]
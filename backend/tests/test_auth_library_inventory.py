@@ -0,0 +1,172 @@
+"""Tests for the auth library inventory."""
+from unittest.mock import Mock
+
+from app.services.auth_library_service import (
+    Ecosystem,
+    LibraryCategory,
+    is_manifest,
+    match_usages,
+    parse_manifest,
+    rollup,
+)
+
+PACKAGE_JSON = """{
+  "name": "orders",
+  "dependencies": {
+    "express": "^4.18.2",
+    "jsonwebtoken": "^8.5.1",
+    "passport": "~0.6.0"
+  },
+  "devDependencies": {
+    "jest": "^29.0.0"
+  }
+}
+"""
+
+PACKAGE_LOCK = """{
+  "name": "orders",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"express": "^4.18.2", "jsonwebtoken": "^8.5.1", "passport": "~0.6.0"}},
+    "node_modules/jsonwebtoken": {"version": "8.5.1"},
+    "node_modules/passport": {"version": "0.6.0"},
+    "node_modules/jwks-rsa": {"version": "3.1.0"}
+  }
+}
+"""
+
+POM = """<project>
+  <properties>
+    <jjwt.version>0.11.5</jjwt.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-security</artifactId>
+      <version>3.2.1</version>
+    </dependency>
+    <dependency>
+      <groupId>io.jsonwebtoken</groupId>
+      <artifactId>jjwt-api</artifactId>
+      <version>${jjwt.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.springframework.security.oauth</groupId>
+      <artifactId>spring-security-oauth2</artifactId>
+      <version>2.5.2.RELEASE</version>
+    </dependency>
+  </dependencies>
+</project>
+"""
+
+REQUIREMENTS = """# Runtime
+Django==4.2.7
+PyJWT[crypto]>=2.8.0,<3
+python_jose==3.3.0
+-r base.txt
+"""
+
+GO_MOD = """module example.com/ledger
+
+go 1.21
+
+require (
+	github.com/casbin/casbin/v2 v2.77.2
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible // indirect
+)
+"""
+
+SBOM = """{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"type": "library", "name": "jose", "version": "5.1.0", "purl": "pkg:npm/jose@5.1.0"},
+    {"type": "library", "name": "nimbus-jose-jwt", "group": "com.nimbusds", "version": "9.37", "purl": "pkg:maven/com.nimbusds/nimbus-jose-jwt@9.37"}
+  ]
+}
+"""
+
+
+def _by_package(dependencies):
+    """Dependencies keyed by package."""
+    return {d.package: d for d in dependencies}
+
+
+def test_manifests_lockfiles_and_sboms_are_parsed():
+    """Test each manifest format yields packages with versions, lines, and direct or transitive provenance."""
+    assert all(is_manifest(p) for p in ("web/package.json", "requirements-dev.txt", "pom.xml", "go.mod", "bom.cdx.json", "Api.csproj"))
+    assert not is_manifest("src/app.ts")
+
+    npm = _by_package(parse_manifest("package.json", PACKAGE_JSON))
+    assert (npm["jsonwebtoken"].version, npm["jsonwebtoken"].declared, npm["jsonwebtoken"].line) == ("8.5.1", "^8.5.1", 5)
+    assert "jest" in npm
+    maven = _by_package(parse_manifest("pom.xml", POM))
+    assert maven["io.jsonwebtoken:jjwt-api"].version == "0.11.5"
+    assert maven["org.springframework.boot:spring-boot-starter-security"].line == 6
+    pypi = _by_package(parse_manifest("requirements.txt", REQUIREMENTS))
+    assert (pypi["PyJWT"].version, pypi["PyJWT"].declared, pypi["PyJWT"].line) == ("2.8.0", ">=2.8.0,<3", 3)
+    assert sorted(pypi) == ["Django", "PyJWT", "python_jose"]
+    go = _by_package(parse_manifest("go.mod", GO_MOD))
+    assert (go["github.com/casbin/casbin/v2"].direct, go["github.com/dgrijalva/jwt-go"].direct) == (True, False)
+    sbom = _by_package(parse_manifest("bom.cdx.json", SBOM))
+    assert sbom["com.nimbusds:nimbus-jose-jwt"].ecosystem == Ecosystem.MAVEN
+    assert (sbom["jose"].version, sbom["jose"].locked) == ("5.1.0", True)
+    assert parse_manifest("package.json", "{not json") == []
+
+
+def _repository(id, name):
+    """A mock repository."""
+    repository = Mock(id=id)
+    repository.name = name
+    return repository
+
+
+def test_usages_match_the_catalog_and_flag_deprecations():
+    """Test catalog matching, lockfile versions over manifest ranges, and deprecated libraries and version floors."""
+    orders, billing = _repository(1, "orders"), _repository(2, "billing")
+    usages = match_usages(
+        [orders, billing],
+        {
+            1: parse_manifest("package.json", PACKAGE_JSON) + parse_manifest("package-lock.json", PACKAGE_LOCK),
+            2: parse_manifest("pom.xml", POM) + parse_manifest("requirements.txt", REQUIREMENTS),
+        },
+    )
+
+    by_key = {(u["repository"], u["package"]): u for u in usages}
+    assert ("orders", "express") not in by_key
+    jwt = by_key[("orders", "jsonwebtoken")]
+    assert (jwt["library"], jwt["category"], jwt["version"], jwt["declared"]) == ("jsonwebtoken", LibraryCategory.TOKEN, "8.5.1", "^8.5.1")
+    assert (jwt["file_path"], jwt["manifests"], jwt["direct"]) == ("package.json", ["package-lock.json", "package.json"], True)
+    assert jwt["deprecated"] and "9.0.0" in jwt["deprecation"]
+    jwks = by_key[("orders", "jwks-rsa")]
+    assert (jwks["direct"], jwks["declared"], jwks["deprecated"]) == (False, None, False)
+    assert by_key[("billing", "org.springframework.boot:spring-boot-starter-security")]["library"] == "Spring Security"
+    oauth = by_key[("billing", "org.springframework.security.oauth:spring-security-oauth2")]
+    assert (oauth["library"], oauth["deprecated"]) == ("Spring Security OAuth", True)
+    assert by_key[("billing", "python_jose")]["library"] == "python-jose"
+    assert not by_key[("billing", "PyJWT")]["deprecated"]
+
+
+def test_rollup_tracks_versions_and_competing_libraries_per_category():
+    """Test per-library version spread across services and per-category library counts."""
+    repositories = [_repository(1, "orders"), _repository(2, "payments"), _repository(3, "ledger")]
+    usages = match_usages(
+        repositories,
+        {
+            1: parse_manifest("package.json", PACKAGE_JSON),
+            2: parse_manifest("package.json", PACKAGE_JSON.replace("^8.5.1", "^9.0.2")),
+            3: parse_manifest("bom.cdx.json", SBOM) + parse_manifest("go.mod", GO_MOD),
+        },
+    )
+    result = rollup(repositories, usages)
+
+    libraries = {e["library"]: e for e in result["libraries"]}
+    assert libraries["jsonwebtoken"]["versions"] == {"8.5.1": ["orders"], "9.0.2": ["payments"]}
+    assert libraries["jsonwebtoken"]["deprecated_services"] == ["orders"]
+    assert libraries["jwt-go"]["deprecated_services"] == ["ledger"]
+    token = next(c for c in result["categories"] if c["category"] == LibraryCategory.TOKEN)
+    assert token["libraries"] == {"jsonwebtoken": 2, "Nimbus JOSE+JWT": 1, "jose": 1, "jwt-go": 1}
+    assert token["services"] == 3
+    assert result["summary"]["version_drift"] == ["jsonwebtoken"]
+    assert result["summary"]["deprecated_usages"] == 2
+    assert result["summary"]["services_with_auth_libraries"] == 3
@@ -0,0 +1,101 @@
+"""Tests for authentication mechanism classification."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.auth_mechanism_service import (
+    AuthMechanism,
+    AuthMechanismService,
+    classify_endpoints,
+    classify_text,
+    policy_mechanisms,
+)
+from app.services.coverage_metrics_service import CoverageMetricsService, route_key
+
+
+def make_policy(policy_id, subject, action, snippet, description=None):
+    """Create a policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = "Order"
+    policy.action = action
+    policy.conditions = None
+    policy.description = description
+    ev = Mock(spec=Evidence)
+    ev.code_snippet = snippet
+    ev.file_path = "app.js"
+    ev.line_start = 1
+    policy.evidence = [ev]
+    return policy
+
+
+POLICIES = [
+    make_policy(1, "Authenticated users", "list", "router.get('/orders', passport.authenticate('jwt'), list)"),
+    make_policy(2, "ADMIN", "delete", "app.delete('/orders/:id', requireSession, requireRole('ADMIN'))\n// req.session.user"),
+    make_policy(3, "Anonymous", "view", "app.get('/health', (req, res) => res.send('ok'))"),
+    make_policy(4, "PARTNER", "create", "app.post('/orders', checkApiKey, create)"),
+    make_policy(5, "OPS", "update", "app.put('/orders/:id', update)", description="Requires a valid client certificate"),
+    make_policy(6, "ADMIN", "delete", "app.delete('/orders/:id', verifyJwt, remove)"),
+    make_policy(7, "Authenticated users", "patch", "app.patch('/orders/:id', isLoggedIn, patch)"),
+]
+
+
+def test_classify_text_orders_mechanisms_by_specificity():
+    """Test signatures across frameworks, most specific first."""
+    assert classify_text("http.oauth2ResourceServer().jwt()") == [AuthMechanism.JWT_BEARER]
+    assert classify_text("@login_required\ndef view(request):") == [AuthMechanism.COOKIE_SESSION]
+    assert classify_text("APIKeyHeader(name='X-API-Key')") == [AuthMechanism.API_KEY]
+    assert classify_text("if request.headers['X-SSL-Client-Verify'] != 'SUCCESS': abort(401)") == [AuthMechanism.MTLS]
+    assert classify_text("Authorization: Bearer <token>; fallback to req.session") == [
+        AuthMechanism.JWT_BEARER,
+        AuthMechanism.COOKIE_SESSION,
+    ]
+    assert classify_text("if user.is_admin:") == []
+
+
+def test_policy_mechanisms_public_and_unknown():
+    """Test anonymous policies are none and unnamed mechanisms are unknown."""
+    assert policy_mechanisms(POLICIES[2]) == [AuthMechanism.NONE]
+    assert policy_mechanisms(POLICIES[4]) == [AuthMechanism.MTLS]
+    assert policy_mechanisms(POLICIES[6]) == [AuthMechanism.UNKNOWN]
+
+
+def test_endpoints_combine_policies_and_filter_by_mechanism():
+    """Test merged endpoints list every mechanism and the filter matches any of them."""
+    by_endpoint = {e["endpoint"]: e for e in classify_endpoints(POLICIES)}
+
+    assert by_endpoint["GET /orders"]["mechanism"] == "jwt_bearer"
+    assert by_endpoint["GET /health"]["mechanism"] == "none"
+    assert by_endpoint["POST /orders"]["mechanism"] == "api_key"
+    deleted = by_endpoint["DELETE /orders/:id"]
+    assert (deleted["mechanism"], deleted["mechanisms"], deleted["policy_ids"]) == (
+        "jwt_bearer",
+        ["jwt_bearer", "cookie_session"],
+        [2, 6],
+    )
+
+    service = AuthMechanismService(MagicMock(), "acme")
+    with patch("app.services.auth_mechanism_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        sessions = service.endpoints(mechanism=AuthMechanism.COOKIE_SESSION)
+    assert [e["endpoint"] for e in sessions] == ["DELETE /orders/:id"]
+
+
+def test_service_summary_and_coverage_report():
+    """Test the per-service rollup and the coverage report breakdown."""
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 4, "orders"
+    db = MagicMock()
+    db.query.return_value.filter.return_value.order_by.return_value.all.return_value = [repository]
+    service = AuthMechanismService(db, "acme")
+
+    with patch("app.services.auth_mechanism_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        [summary] = service.service_summary()
+        assert service.service_summary(AuthMechanism.MTLS)[0]["repository_id"] == 4
+
+    assert summary["endpoints"] == 6
+    assert summary["primary_mechanism"] == "jwt_bearer"
+    assert summary["by_mechanism"] == {"jwt_bearer": 2, "api_key": 1, "mtls": 1, "none": 1, "unknown": 1}
+
+    report = CoverageMetricsService.compute(POLICIES[:2], {route_key("GET", "/metrics"): "app.js"})
+    assert report["auth_mechanisms"] == {"jwt_bearer": 1, "cookie_session": 1, "none": 1}
@@ -0,0 +1,168 @@
+"""Tests for endpoint mapping and authorization test generation."""
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.policy import Evidence, Policy, PolicyStatus
+from app.services.authz_test_generation_service import (
+    AuthzTestFramework,
+    AuthzTestGenerationService,
+)
+from app.services.endpoint_mapping_service import EndpointMappingService
+
+
+def make_policy(policy_id, subject, resource, action, conditions=None, snippet=None):
+    """Create a policy with optional evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = resource
+    policy.action = action
+    policy.conditions = conditions
+    policy.status = PolicyStatus.PENDING
+    evidence = []
+    if snippet:
+        ev = Mock(spec=Evidence)
+        ev.code_snippet = snippet
+        ev.file_path = "main.go"
+        ev.line_start = 10
+        evidence.append(ev)
+    policy.evidence = evidence
+    return policy
+
+
+@pytest.fixture
+def go_policies():
+    """Policies resembling those mined from the sample Go app."""
+    return [
+        make_policy(
+            1,
+            "MANAGER or DIRECTOR",
+            "Expense",
+            "approve",
+            conditions="amount > 5000 requires DIRECTOR",
+            snippet='r.HandleFunc("/api/expenses/{id}/approve", RequireAnyRole(approve)).Methods("PUT")',
+        ),
+        make_policy(
+            2,
+            "ADMIN",
+            "Expense",
+            "delete",
+            snippet='// DELETE /api/expenses/{id} - admin only\nRequireRole("ADMIN")',
+        ),
+        make_policy(3, "authenticated user", "/api/expenses", "read"),
+    ]
+
+
+class TestEndpointMapping:
+    """Tests for EndpointMappingService."""
+
+    def test_parse_roles_from_subject(self):
+        """Test role extraction from common subject phrasings."""
+        assert EndpointMappingService.parse_roles("MANAGER or DIRECTOR") == (["MANAGER", "DIRECTOR"], True)
+        assert EndpointMappingService.parse_roles("hasRole('ROLE_ADMIN')") == (["ADMIN"], True)
+        assert EndpointMappingService.parse_roles("Authenticated users") == ([], True)
+        assert EndpointMappingService.parse_roles("anonymous") == ([], False)
+
+    def test_find_routes(self):
+        """Test route extraction across frameworks."""
+        assert EndpointMappingService.find_routes('app.get("/api/users", auth)') == [("GET", "/api/users")]
+        assert EndpointMappingService.find_routes('HandleFunc("/x", h).Methods("POST")') == [("POST", "/x")]
+        assert EndpointMappingService.find_routes('@DeleteMapping("/items/{id}")') == [
+            ("DELETE", "/items/{id}")
+        ]
+
+    def test_infer_method_and_path(self):
+        """Test fallbacks when evidence has no route."""
+        assert EndpointMappingService.infer_method("approve") == "PUT"
+        assert EndpointMappingService.infer_method("DELETE") == "DELETE"
+        assert EndpointMappingService.infer_method(None) == "GET"
+        assert EndpointMappingService.infer_path("Expense Report") == "/expense-report"
+
+    def test_map_policies_merges_same_endpoint(self):
+        """Test that policies on one endpoint combine their roles."""
+        policies = [
+            make_policy(1, "MANAGER", "/api/reports", "read"),
+            make_policy(2, "AUDITOR", "/api/reports", "view"),
+        ]
+
+        rules = EndpointMappingService.map_policies(policies)
+
+        assert len(rules) == 1
+        assert rules[0].roles == ["MANAGER", "AUDITOR"]
+        assert rules[0].policy_ids == [1, 2]
+
+    def test_authenticated_policy_widens_endpoint(self):
+        """Test that an any-user policy is not narrowed by a role policy."""
+        policies = [
+            make_policy(1, "authenticated user", "/api/reports", "read"),
+            make_policy(2, "AUDITOR", "/api/reports", "read"),
+        ]
+
+        rules = EndpointMappingService.map_policies(policies)
+
+        assert rules[0].roles == []
+        assert rules[0].allows("AUDITOR")
+        assert not rules[0].allows("anonymous")
+
+
+class TestAuthzTestGeneration:
+    """Tests for AuthzTestGenerationService."""
+
+    def test_build_cases_expected_statuses(self, go_policies):
+        """Test allowed/denied expectations for each role."""
+        rules = EndpointMappingService.map_policies(go_policies)
+        cases = AuthzTestGenerationService.build_cases(rules)
+        by_key = {(c.method, c.path, c.role): c for c in cases}
+
+        delete_admin = by_key[("DELETE", "/api/expenses/1", "ADMIN")]
+        assert delete_admin.allowed and delete_admin.expected_status == 204
+        assert by_key[("DELETE", "/api/expenses/1", "MANAGER")].expected_status == 403
+        assert by_key[("DELETE", "/api/expenses/1", "anonymous")].expected_status == 401
+        assert by_key[("GET", "/api/expenses", "MANAGER")].allowed
+
+    def test_conditional_allowed_cases_are_skipped(self, go_policies):
+        """Test that condition-dependent allowed cases are skipped, denials are kept."""
+        rules = EndpointMappingService.map_policies(go_policies)
+        cases = AuthzTestGenerationService.build_cases(rules)
+        approve = [c for c in cases if c.path == "/api/expenses/1/approve"]
+
+        director = next(c for c in approve if c.role == "DIRECTOR")
+        admin = next(c for c in approve if c.role == "ADMIN")
+        assert director.skip_reason and "5000" in director.skip_reason
+        assert admin.skip_reason is None and admin.expected_status == 403
+
+    @pytest.mark.parametrize(
+        "framework,filename,marker",
+        [
+            (AuthzTestFramework.GO_HTTPTEST, "mined_authz_policies_test.go", "httptest.NewRequest"),
+            (AuthzTestFramework.PYTEST, "test_mined_authz_policies.py", "@pytest.mark.parametrize"),
+            (AuthzTestFramework.JEST_SUPERTEST, "mined-authz-policies.test.js", "require('supertest')"),
+        ],
+    )
+    def test_generate_for_repository(self, go_policies, framework, filename, marker):
+        """Test rendering a suite for each framework."""
+        db = MagicMock()
+        repository = MagicMock()
+        repository.name = "expense-service"
+        db.query.return_value.filter.return_value.filter.return_value.first.return_value = repository
+        db.query.return_value.filter.return_value.filter.return_value.all.return_value = go_policies
+
+        service = AuthzTestGenerationService(db, tenant_id="tenant-1")
+        result = service.generate_for_repository(1, framework, package_name="expenses")
+
+        assert result["filename"] == filename
+        assert marker in result["content"]
+        assert result["endpoint_count"] == 3
+        assert result["case_count"] == 3 * 4  # anonymous + 3 roles per endpoint
+        assert result["skipped_count"] == 2
+
+    def test_generate_for_missing_repository(self):
+        """Test that an unknown repository raises ValueError."""
+        db = MagicMock()
+        db.query.return_value.filter.return_value.filter.return_value.first.return_value = None
+
+        service = AuthzTestGenerationService(db, tenant_id="tenant-1")
+
+        with pytest.raises(ValueError):
+            service.generate_for_repository(99, AuthzTestFramework.PYTEST)
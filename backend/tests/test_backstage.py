@@ -0,0 +1,85 @@
+"""Tests for the Backstage developer portal integration."""
+from unittest.mock import Mock, patch
+
+import pytest
+
+from app.models.policy import Policy, PolicyStatus, RiskLevel
+from app.models.repository import Repository
+from app.services.backstage_service import REPOSITORY_ANNOTATION, BackstageService, source_key
+from app.services.finding_taxonomy import FindingType
+
+
+def make_repository(repository_id, name, source_url):
+    """Create a repository."""
+    repository = Mock(spec=Repository, id=repository_id, source_url=source_url)
+    repository.name = name
+    return repository
+
+
+REPOSITORIES = [
+    make_repository(1, "payments-api", "https://github.com/acme/payments.git"),
+    make_repository(2, "billing", "git@gitlab.com:acme/billing-service.git"),
+    make_repository(3, "ledger", None),
+]
+
+
+def test_source_urls_compare_across_forms():
+    """Test clone, SSH, browse, and source-location URLs reduce to host and repository path."""
+    assert source_key("https://github.com/acme/payments.git") == "github.com/acme/payments"
+    assert source_key("git@github.com:acme/payments.git") == "github.com/acme/payments"
+    assert source_key("url:https://github.com/Acme/payments/tree/main/") == "github.com/acme/payments"
+    assert source_key("url:https://gitlab.com/acme/billing-service/-/tree/main/src") == "gitlab.com/acme/billing-service"
+    assert source_key("") is None
+
+
+def test_entities_resolve_by_annotation_then_name(make_db):
+    """Test each annotation, the entity name fallback, and entities matching nothing."""
+    service = BackstageService(make_db({Repository: REPOSITORIES}), "acme")
+    assert service.resolve("anything", repository_id=3).id == 3
+    assert service.resolve("payments", project_slug="acme/payments").id == 1
+    assert service.resolve("billing-svc", source_location="url:https://gitlab.com/acme/billing-service/-/tree/main/").id == 2
+    assert service.resolve("Ledger").id == 3
+    with pytest.raises(ValueError):
+        service.resolve("payments", project_slug="acme/pay")
+    with pytest.raises(ValueError):
+        service.resolve("ledger", repository_id=9)
+
+
+def test_posture_combines_status_policies_and_findings(make_db):
+    """Test the catalog page payload and finding truncation."""
+    policies = [
+        Mock(spec=Policy, subject="ADMIN", resource="Payment", status=PolicyStatus.APPROVED, risk_level=RiskLevel.HIGH),
+        Mock(spec=Policy, subject="ADMIN", resource="Refund", status=PolicyStatus.PENDING, risk_level=None),
+        Mock(spec=Policy, subject="CLERK", resource="Payment", status=PolicyStatus.PENDING, risk_level=RiskLevel.LOW),
+    ]
+    finding = {
+        "finding_type": FindingType.BOLA_CANDIDATE,
+        "rule_id": "bola_candidate",
+        "title": "Object looked up by client-supplied ID without an ownership check",
+        "severity": "high",
+        "description": "GET /payments/{id} loads Payment by id",
+        "file_path": "api/payments.py",
+        "line_start": 14,
+        "cwe": ["CWE-639"],
+        "owasp_api": ["API1:2023"],
+    }
+    service = BackstageService(make_db({Repository: REPOSITORIES, Policy: policies}), "acme")
+    status = {"repository_id": 1, "repository_name": "payments-api", "status": "passing", "badge_url": "/b.svg"}
+    with (
+        patch("app.services.backstage_service.StatusBadgeService.status", return_value=status),
+        patch("app.services.backstage_service.FindingExportService.findings", return_value=[finding] * 30) as findings,
+    ):
+        posture = service.posture(REPOSITORIES[0])
+    findings.assert_called_once_with(repository_id=1)
+
+    assert (posture["status"], posture["annotations"]) == ("passing", {REPOSITORY_ANNOTATION: "1"})
+    assert posture["policies"] == {
+        "total": 3,
+        "by_status": {"approved": 1, "pending": 2},
+        "by_risk_level": {"high": 1, "low": 1},
+        "resources": 2,
+        "top_roles": [{"role": "ADMIN", "policies": 2}, {"role": "CLERK", "policies": 1}],
+    }
+    assert (posture["findings_summary"]["total"], posture["findings_summary"]["by_severity"]) == (30, {"high": 30})
+    assert (len(posture["findings"]), posture["findings_truncated"]) == (25, True)
+    assert posture["findings"][0]["line_start"] == 14
@@ -0,0 +1,164 @@
+"""Tests for broken object level authorization (BOLA) candidate detection."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.repository import Repository
+from app.services.bola_detection_service import BolaDetectionService, detect_bola
+
+GO_HANDLERS = """package api
+
+func (h *Handler) GetInvoice(c *gin.Context) {
+	invoiceID := c.Param("id")
+	id := strings.TrimSpace(invoiceID)
+	invoice, err := h.repo.GetByID(c, id)
+	if err != nil {
+		c.JSON(404, nil)
+		return
+	}
+	c.JSON(200, invoice)
+}
+
+func (h *Handler) GetOwnInvoice(c *gin.Context) {
+	id := c.Param("id")
+	user := c.MustGet("user").(*User)
+	invoice, _ := h.repo.GetByID(c, id)
+	if invoice.UserID != user.ID {
+		c.AbortWithStatus(403)
+		return
+	}
+	c.JSON(200, invoice)
+}
+
+func (h *Handler) GetTenantInvoice(c *gin.Context) {
+	id := c.Param("id")
+	invoice, _ := h.repo.GetByIDForTenant(c, id, tenantID(c))
+	c.JSON(200, invoice)
+}
+"""
+
+EXPRESS = """const router = require('express').Router();
+
+router.delete('/documents/:id', async (req, res) => {
+  const { id } = req.params;
+  const doc = await Document.findById(id);
+  if (!doc) {
+    return res.sendStatus(404);
+  }
+  await doc.remove();
+  res.status(204).end();
+});
+
+router.get('/notes/:noteId', async (req, res) => {
+  const note = await Note.findOne({ _id: req.params.noteId, owner: req.user.id });
+  res.json(note);
+});
+"""
+
+DJANGO = """def receipt_detail(request, pk):
+    receipt = get_object_or_404(Receipt, pk=pk)
+    return render(request, "receipt.html", {"receipt": receipt})
+
+
+def invoice_detail(request, pk):
+    invoice = Invoice.objects.get(pk=pk, owner=request.user)
+    return render(request, "invoice.html", {"invoice": invoice})
+
+
+def helper(items, key):
+    return items.get(key)
+"""
+
+SPRING = """@RestController
+public class OrderController {
+    @GetMapping("/orders/{id}")
+    public Order get(@PathVariable Long id) {
+        return orderRepository.findById(id).orElseThrow();
+    }
+
+    @PreAuthorize("hasRole('ADMIN')")
+    @DeleteMapping("/orders/{id}")
+    public void delete(@PathVariable Long id) {
+        orderRepository.deleteById(id);
+    }
+}
+"""
+
+RAILS = """class OrdersController < ApplicationController
+  before_action :require_admin, only: [:destroy]
+
+  def show
+    @order = Order.find(params[:id])
+    render json: @order
+  end
+
+  def mine
+    @order = current_user.orders.find(params[:id])
+    render json: @order
+  end
+
+  def destroy
+    @order = Order.find(params[:id])
+    @order.destroy
+    head :no_content
+  end
+end
+"""
+
+
+def test_lookups_by_request_ids_without_ownership_checks_are_traced():
+    """Test IDs are followed through assignments, and scoped or owner-checked lookups are not reported."""
+    [invoice] = detect_bola("api/handlers.go", GO_HANDLERS)
+    assert (invoice.function, invoice.line_start, invoice.identifier, invoice.lookup) == ("GetInvoice", 6, "id", "GetByID")
+    assert invoice.severity == "medium"
+    assert [(s.step, s.line) for s in invoice.trace] == [("input", 4), ("assignment", 5), ("lookup", 6), ("use", 11)]
+    assert invoice.trace[0].code == 'invoiceID := c.Param("id")'
+
+    [document] = detect_bola("routes/documents.js", EXPRESS)
+    assert (document.function, document.endpoint, document.severity) == (None, "DELETE /documents/:id", "high")
+    assert document.description.startswith("Handler for DELETE /documents/:id passes client-supplied id to findById()")
+    assert [(s.step, s.code) for s in document.trace][-1] == ("use", "await doc.remove();")
+
+    [receipt] = detect_bola("views.py", DJANGO)
+    assert (receipt.function, receipt.identifier, receipt.lookup) == ("receipt_detail", "pk", "get_object_or_404")
+    assert receipt.trace[0].code == "parameter pk"
+
+
+def test_severity_reflects_writes_and_admin_only_handlers():
+    """Test route attribution, annotated parameters, and role restrictions in annotations and controller filters."""
+    get, delete = detect_bola("OrderController.java", SPRING)
+    assert (get.endpoint, get.severity) == ("GET /orders/{id}", "medium")
+    assert (delete.endpoint, delete.lookup, delete.severity) == ("DELETE /orders/{id}", "deleteById", "low")
+    assert delete.description.endswith("so any caller can modify the object (restricted to administrators)")
+
+    show, destroy = detect_bola("app/controllers/orders_controller.rb", RAILS)
+    assert (show.function, show.identifier, show.severity) == ("show", "params[:id]", "medium")
+    assert (destroy.function, destroy.severity) == ("destroy", "low")
+    assert destroy.trace[-1].code == "@order.destroy"
+
+    assert detect_bola("orders_controller.rb", "class A\n  load_and_authorize_resource\n" + RAILS) == []
+
+
+def test_service_reports_candidates_per_repository(tmp_path):
+    """Test clone scanning, the severity filter, and the summary counts."""
+    (tmp_path / "4" / "api").mkdir(parents=True)
+    (tmp_path / "4" / "api" / "handlers.go").write_text(GO_HANDLERS)
+    (tmp_path / "4" / "routes.js").write_text(EXPRESS)
+    (tmp_path / "4" / "node_modules").mkdir()
+    (tmp_path / "4" / "node_modules" / "lib.js").write_text(EXPRESS)
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 4, "billing"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    service = BolaDetectionService(db, "acme", clone_dir=str(tmp_path))
+
+    result = service.candidates()
+    assert result["summary"] == {
+        "total": 2,
+        "repositories_scanned": 1,
+        "repositories_with_findings": 1,
+        "by_severity": {"high": 1, "medium": 1},
+    }
+    assert [(f["file_path"], f["repository_name"]) for f in result["findings"]] == [("routes.js", "billing"), ("api/handlers.go", "billing")]
+    assert result["findings"][0]["trace"][0] == {"step": "input", "line": 4, "code": "const { id } = req.params;"}
+    assert [f["severity"] for f in service.candidates(severity="MEDIUM")["findings"]] == ["medium"]
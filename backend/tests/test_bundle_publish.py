@@ -0,0 +1,153 @@
+"""Tests for publishing approved policies as OPA bundles."""
+import base64
+import hashlib
+import io
+import json
+import tarfile
+from unittest.mock import AsyncMock, MagicMock, Mock, patch
+
+import pytest
+
+from app.models.bundle_publication import BundleTarget, BundleTargetType, PublicationStatus
+from app.models.policy import Policy
+from app.services.bundle_publish_service import (
+    BundlePublishService,
+    OciRegistryClient,
+    build_archive,
+    bundle_files,
+    package_module,
+    sign_files,
+)
+
+REGO = """package authz
+
+allow {
+    input.user.role == "ADMIN"
+}"""
+
+
+def read_archive(archive: bytes) -> dict[str, bytes]:
+    """Extract a bundle archive into path -> content."""
+    with tarfile.open(fileobj=io.BytesIO(archive), mode="r:gz") as tar:
+        return {m.name: tar.extractfile(m).read() for m in tar.getmembers()}
+
+
+def test_bundle_layout_and_signature():
+    """Test modules move under the bundle root and the signature covers every file."""
+    module = package_module(7, REGO)
+    assert module.startswith("package policy_miner.policies.policy_7\n")
+    assert package_module(8, "allow { true }").startswith("package policy_miner.policies.policy_8\n\n")
+
+    files = bundle_files({7: module}, "authz-v3")
+    files[".signatures.json"] = sign_files(files, "HS256", "secret", "miner-key")
+    archive = build_archive(files)
+
+    contents = read_archive(archive)
+    assert set(contents) == {
+        "/.manifest",
+        "/.signatures.json",
+        "/policy_miner/main.rego",
+        "/policy_miner/policies/policy_7.rego",
+    }
+    manifest = json.loads(contents["/.manifest"])
+    assert manifest["revision"] == "authz-v3"
+    assert manifest["roots"] == ["policy_miner"]
+
+    (token,) = json.loads(contents["/.signatures.json"])["signatures"]
+    payload = json.loads(base64.urlsafe_b64decode(token.split(".")[1] + "=="))
+    hashes = {f["name"]: f["hash"] for f in payload["files"]}
+    assert hashes["policy_miner/policies/policy_7.rego"] == hashlib.sha256(module.encode()).hexdigest()
+    assert ".signatures.json" not in hashes
+    assert payload["keyid"] == "miner-key"
+    assert build_archive(files) == archive  # Reproducible
+
+
+def test_bundle_holds_the_rego_exporters_packages(make_policy, make_export_service):
+    """Test bundles are built from the deterministic exporter, identically for the same policies."""
+    policies = [
+        make_policy(1, "MANAGER", "read", "router.get('/api/expenses/:id', requireRole('MANAGER'), show)"),
+        make_policy(2, "anonymous", "read", "app.get('/api/invoices', list)"),
+    ]
+    for policy in policies:
+        policy.repository_id = 4
+    service = make_export_service(BundlePublishService, policies)
+    target = Mock(spec=BundleTarget, application_id=None, min_confidence=None, signing_algorithm=None)
+
+    archive, policy_ids = service.build_bundle(target, "authz-v1")
+
+    assert policy_ids == [1, 2]
+    assert service.build_bundle(target, "authz-v1")[0] == archive
+    contents = read_archive(archive)
+    packages = [name for name in contents if name.startswith("/policy_miner/services/")]
+    assert set(contents) == {"/.manifest", "/policy_miner/main.rego", *packages} and len(packages) == 1
+    assert b"allow if data.policy_miner.services[_].allow" in contents["/policy_miner/main.rego"]
+    assert b"allow if get_api_expenses_item" in contents[packages[0]]
+    assert json.loads(contents["/.manifest"])["metadata"]["policy_ids"] == [1, 2]
+
+
+@pytest.mark.asyncio
+async def test_oci_push_answers_token_challenge():
+    """Test the registry client exchanges a Bearer challenge for a token and uploads blobs."""
+    client = MagicMock()
+    client.request = AsyncMock(
+        side_effect=[
+            Mock(status_code=401, headers={"www-authenticate": 'Bearer realm="https://auth.io/token",service="reg",scope="repository:p:push"'}),
+            Mock(status_code=404, headers={}),
+            Mock(status_code=202, headers={"location": "/v2/p/blobs/uploads/abc?state=1"}),
+            Mock(status_code=201, headers={}),
+        ]
+    )
+    client.get = AsyncMock(return_value=Mock(json=Mock(return_value={"token": "tok"})))
+    registry = OciRegistryClient(client, "https://reg.io", "bot", "pw")
+
+    digest = await registry.push_blob("p", b"bundle")
+
+    assert digest == f"sha256:{hashlib.sha256(b'bundle').hexdigest()}"
+    assert client.get.call_args.kwargs["params"] == {"service": "reg", "scope": "repository:p:push"}
+    assert client.get.call_args.kwargs["auth"] == ("bot", "pw")
+    upload = client.request.call_args_list[3]
+    assert upload.args == ("PUT", f"https://reg.io/v2/p/blobs/uploads/abc?state=1&digest={digest}")
+    assert upload.kwargs["headers"]["Authorization"] == "Bearer tok"
+
+
+@pytest.mark.asyncio
+async def test_publish_records_versions_and_failures():
+    """Test publishing numbers versions per target and records push failures."""
+    target = Mock(
+        spec=BundleTarget, id=2, bundle_name="authz", url="https://bundles.acme.io/authz",
+        target_type=BundleTargetType.OPA_BUNDLE_SERVER, signing_algorithm=None, secret=None,
+    )
+    db = MagicMock()
+    db.query.return_value.filter.return_value.scalar.return_value = 4
+    service = BundlePublishService(db, tenant_id="acme")
+    service.get_target = MagicMock(return_value=target)
+    service.build_bundle = MagicMock(return_value=(b"archive", [1, 3]))
+
+    with patch.object(BundlePublishService, "_push_bundle_server", AsyncMock(side_effect=ValueError("503"))):
+        publication = await service.publish(2, triggered_by="sam@acme.io")
+
+    assert publication.version == 5
+    assert publication.revision.startswith("authz-v5-")
+    assert publication.policy_ids == [1, 3]
+    assert publication.status == PublicationStatus.FAILED
+    assert publication.error_message == "503"
+    assert publication.digest == f"sha256:{hashlib.sha256(b'archive').hexdigest()}"
+    db.commit.assert_called()
+
+
+@pytest.mark.asyncio
+async def test_publish_on_approval_respects_target_scope():
+    """Test auto-publish only republishes targets covering the approved policies."""
+    policy = Mock(spec=Policy, id=9, application_id=4)
+    everywhere = Mock(spec=BundleTarget, id=1, application_id=None)
+    same_app = Mock(spec=BundleTarget, id=2, application_id=4)
+    other_app = Mock(spec=BundleTarget, id=3, application_id=5)
+    service = BundlePublishService(MagicMock(), tenant_id="acme")
+    service._query = MagicMock()
+    service._query.return_value.filter.return_value.all.side_effect = [[policy], [everywhere, same_app, other_app]]
+    service.publish = AsyncMock(side_effect=lambda target_id, triggered_by: target_id)
+
+    published = await service.publish_on_approval([9])
+
+    assert published == [1, 2]
+    assert service.publish.call_args.kwargs == {"triggered_by": "approval"}
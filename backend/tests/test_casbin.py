@@ -0,0 +1,130 @@
+"""Tests for Casbin enforcer detection and model and policy import."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.casbin_extractor import extract_casbin_usage, parse_model
+from app.services.casbin_policy_service import CASBIN_LABEL, CasbinPolicyService
+
+MODEL = """[request_definition]
+r = sub, dom, obj, act
+
+[policy_definition]
+p = sub, dom, obj, act, eft
+
+[role_definition]
+g = _, _, _
+
+[policy_effect]
+e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
+
+[matchers]
+m = g(r.sub, p.sub, r.dom) && r.dom == p.dom && keyMatch2(r.obj, p.obj) && r.act == p.act
+"""
+
+POLICY = """# tenant-scoped rules
+p, admin, acme, /reports/:id, GET, allow
+p, guest, acme, /admin/*, POST, deny
+g, alice, admin, acme
+"""
+
+GO_SERVER = """package main
+
+import "github.com/casbin/casbin/v2"
+
+func main() {
+	e, err := casbin.NewEnforcer("../../config/rbac_model.conf", "../../config/policy.csv")
+	if ok, _ := e.Enforce(user, "acme", "/reports/42", "GET"); !ok {
+		return
+	}
+	allowed, _ := e.Enforce(user, tenant, path, method)
+}
+"""
+
+
+def test_policy_rows_are_read_through_the_model_and_linked_to_enforce_calls():
+    """Test rows map by the model's field names and cite the matcher and the calls they can decide."""
+    scan = extract_casbin_usage({"cmd/server/main.go": GO_SERVER}, {"config/rbac_model.conf": MODEL, "config/policy.csv": POLICY})
+
+    assert scan.models == ["config/rbac_model.conf"]
+    [enforcer] = scan.enforcers
+    assert (enforcer.variable, enforcer.model_path, enforcer.policy_path) == ("e", "config/rbac_model.conf", "config/policy.csv")
+    assert [c.line for c in scan.calls] == [7, 10]
+    assert scan.unresolved_calls == 0
+
+    reports, admin, role = scan.findings
+    assert (reports.subject, reports.resource, reports.action) == ("admin", "/reports/:id", "GET")
+    assert reports.conditions == "domain acme; obj matched with keyMatch2"
+    assert (reports.file_path, reports.line_start) == ("config/policy.csv", 2)
+    assert reports.description == "Casbin p rule under model rbac_model.conf enforced at cmd/server/main.go:7"
+    assert [(path, line) for path, line, _, _ in reports.related_evidence] == [
+        ("config/rbac_model.conf", 14),
+        ("cmd/server/main.go", 7),
+        ("cmd/server/main.go", 10),
+    ]
+    # The literal call asks about /reports/42, which the /admin/* rule cannot decide
+    assert admin.action == "deny POST"
+    assert [(path, line) for path, line, _, _ in admin.related_evidence][1:] == [("cmd/server/main.go", 10)]
+    assert (role.subject, role.resource, role.action, role.conditions) == ("alice", "role admin", "inherit", "domain acme")
+
+
+def test_unresolvable_enforcers_fall_back_and_code_added_policies_are_mined():
+    """Test path variables, the single-pair fallback, database adapters, and AddPolicy literals."""
+    model = parse_model("rbac.conf", MODEL.replace("keyMatch2(r.obj, p.obj)", "r.obj == p.obj && r.sub.Age > 18"))
+    assert model.request == ["sub", "dom", "obj", "act"]
+    assert model.matcher_line == 14
+
+    node = """const { newEnforcer } = require('casbin');
+const modelPath = 'authz/rbac.conf';
+const enforcer = await newEnforcer(modelPath, new SequelizeAdapter(db));
+await enforcer.addPolicy('ops', 'acme', '/deploys', 'POST');
+if (await enforcer.enforce(req.user, req.tenant, req.path, req.method)) next();
+"""
+    scan = extract_casbin_usage({"src/authz.js": node}, {"authz/rbac.conf": MODEL, "config/policy.csv": POLICY})
+    [enforcer] = scan.enforcers
+    assert enforcer.model_path == "authz/rbac.conf"
+    assert enforcer.policy_path is None
+    assert scan.unresolved_calls == 1
+    [added] = scan.findings
+    assert (added.subject, added.resource, added.action, added.file_path) == ("ops", "/deploys", "POST", "src/authz.js")
+    assert added.description == "Casbin p rule added in code"
+
+    # Enforcers built outside the repository still load its only model and policy
+    python = "def check(enforcer, user):\n    return enforcer.enforce(user, 'acme', '/reports/1', 'GET')\n"
+    scan = extract_casbin_usage({"app/views.py": python}, {"authz/rbac.conf": MODEL, "config/policy.csv": POLICY})
+    assert len(scan.findings) == 3
+    assert ("app/views.py", 2) in [(path, line) for path, line, _, _ in scan.findings[0].related_evidence]
+
+
+def test_scan_repository_merges_rules_with_call_site_evidence(tmp_path):
+    """Test an import adds row, matcher, and call-site evidence to an already mined policy."""
+    root = tmp_path / "7"
+    (root / "cmd" / "server").mkdir(parents=True)
+    (root / "config").mkdir()
+    (root / "cmd" / "server" / "main.go").write_text(GO_SERVER)
+    (root / "config" / "rbac_model.conf").write_text(MODEL)
+    (root / "config" / "policy.csv").write_text(POLICY)
+    (root / "config" / "users.csv").write_text("name,email\nalice,alice@example.com\n")
+
+    repo = Mock(spec=Repository, id=7, tenant_id="acme")
+    mined = Policy(id=3, repository_id=7, subject="admin", resource="/reports/:id", action="GET")
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    db.query.return_value.join.return_value.filter.return_value.filter.return_value.filter.return_value.all.return_value = []
+    db.query.return_value.filter.return_value.all.return_value = [mined]
+
+    result = CasbinPolicyService(db, "acme", str(tmp_path)).scan_repository(7)
+
+    assert result["enforcers"] == 1
+    assert result["enforce_calls"] == 2
+    assert result["policy_files"] == ["config/policy.csv"]
+    assert result["rules"] == 3
+    assert (result["policies_created"], result["policies_merged"]) == (2, 1)
+    assert [(e.file_path, e.line_start) for e in mined.evidence] == [
+        ("config/policy.csv", 2),
+        ("config/rbac_model.conf", 14),
+        ("cmd/server/main.go", 7),
+        ("cmd/server/main.go", 10),
+    ]
+    created = [call.args[0] for call in db.add.call_args_list]
+    assert all(p.description.endswith(f"(from {CASBIN_LABEL})") for p in created)
@@ -0,0 +1,119 @@
+"""Tests for exporting mined policies as Cedar for Amazon Verified Permissions."""
+import gzip
+import io
+import json
+import tarfile
+
+from app.services.cedar_export_service import CedarExportService, cedar_name
+
+
+def test_names_are_valid_cedar_identifiers():
+    """Test namespaces and entity types from repository and resource names."""
+    assert cedar_name("expense-api", "Repository") == "ExpenseApi"
+    assert cedar_name("expense report", "Resource") == "ExpenseReport"
+    assert cedar_name("3ds", "Repository") == "Repository3ds"
+    assert cedar_name("", "Resource") == "Resource"
+
+
+def test_one_statement_per_policy_with_an_entity_schema(make_policy, make_export_service):
+    """Test scopes, translated conditions, fail-closed clauses, and the schema."""
+    policies = [
+        make_policy(
+            1, "MANAGER", "read", "router.get('/api/expenses/:id', requireRole('MANAGER'), show)",
+            conditions="user is owner of expense.owner_id unless ADMIN",
+        ),
+        make_policy(
+            2, "AUDITOR or MANAGER", "read", "router.get('/api/expenses/:id', requireRole('AUDITOR', 'MANAGER'), show)",
+            conditions="user department is Finance",
+        ),
+        make_policy(
+            3, "MANAGER", "approve", "router.put('/api/expenses/:id/approve', requireRole('MANAGER'), approve)",
+            conditions="amount > 5000 requires DIRECTOR; vibes are good",
+        ),
+        make_policy(4, "authenticated", "read", "app.get('/api/invoices', list)", resource="Invoice"),
+        make_policy(5, "anonymous", "read", "app.get('/api/status', status)", resource="Role"),
+    ]
+    result = make_export_service(CedarExportService, policies, repository_name="expense-api").export(4)
+
+    assert result["namespace"] == "ExpenseApi"
+    owner, auditor, approve, invoices, status = (p["statement"] for p in result["policies"])
+    assert owner.startswith('@policy_id("1")\n@confidence("90")\n@extraction_method("explicit_middleware")\n')
+    assert (
+        "permit (\n"
+        '    principal in ExpenseApi::Role::"MANAGER",\n'
+        '    action == ExpenseApi::Action::"GET",\n'
+        '    resource is ExpenseApi::Expense in ExpenseApi::Endpoint::"/api/expenses/{}"\n'
+        ")\n"
+    ) in owner
+    assert (
+        "((resource has owner_id && resource.owner_id == principal) || "
+        'principal in ExpenseApi::Role::"ADMIN")\n};'
+    ) in owner
+    assert '    principal,\n' in auditor
+    assert (
+        'principal in [ExpenseApi::Role::"AUDITOR", ExpenseApi::Role::"MANAGER"] &&\n'
+        '    principal has department && principal.department == "Finance"'
+    ) in auditor
+    assert (
+        "((resource has amount && resource.amount <= 5000) || "
+        'principal in ExpenseApi::Role::"DIRECTOR") &&\n'
+        "    // Not translated, review: vibes are good\n    false\n};"
+    ) in approve
+    assert "when {\n    principal.authenticated\n};" in invoices
+    assert status.endswith('resource is ExpenseApi::RoleObject in ExpenseApi::Endpoint::"/api/status"\n);')
+    assert result["cedar"].count("permit (") == 5
+    assert result["untranslated"] == [
+        {"policy_id": 3, "endpoint": "PUT /api/expenses/{}/approve", "clause": "vibes are good"}
+    ]
+
+    schema = result["entity_schema"]["ExpenseApi"]
+    assert sorted(schema["entityTypes"]) == ["Endpoint", "Expense", "Invoice", "Role", "RoleObject", "User"]
+    assert schema["entityTypes"]["User"] == {
+        "memberOfTypes": ["Role"],
+        "shape": {
+            "type": "Record",
+            "attributes": {
+                "authenticated": {"type": "Boolean", "required": True},
+                "department": {"type": "String", "required": False},
+            },
+        },
+    }
+    assert schema["entityTypes"]["Expense"]["memberOfTypes"] == ["Endpoint"]
+    assert schema["entityTypes"]["Expense"]["shape"]["attributes"] == {
+        "amount": {"type": "Long", "required": False},
+        "owner_id": {"type": "Entity", "name": "User", "required": False},
+    }
+    assert list(schema["actions"]) == ["GET", "PUT"]
+    assert schema["actions"]["GET"]["appliesTo"] == {
+        "principalTypes": ["User"], "resourceTypes": ["Expense", "Invoice", "RoleObject"],
+    }
+    assert result["summary"] == {
+        "policies": 5, "endpoints": 4, "entity_types": 6, "below_min_confidence": 0, "untranslated_clauses": 1,
+    }
+
+
+def test_decimals_ordering_and_the_archive(make_policy, make_export_service):
+    """Test decimal comparisons, string ordering left untranslated, min_confidence, and the archive layout."""
+    policies = [
+        make_policy(
+            1, "ADMIN", "delete", "router.delete('/api/expenses/:id', remove)",
+            conditions="amount <= 99.5 and status > open",
+        ),
+        make_policy(2, "ADMIN", "read", "router.get('/api/expenses', list)", confidence=40.0),
+    ]
+    service = make_export_service(CedarExportService, policies, repository_name="expense-api")
+
+    result = service.export(4, min_confidence=50)
+    (statement,) = result["policies"]
+    assert 'resource has amount && resource.amount.lessThanOrEqual(decimal("99.5"))' in statement["statement"]
+    assert [u["clause"] for u in result["untranslated"]] == ["status > open"]
+    assert result["summary"]["below_min_confidence"] == 1
+
+    with tarfile.open(fileobj=io.BytesIO(gzip.decompress(service.archive(4, min_confidence=50)))) as tar:
+        members = {member.name: member for member in tar.getmembers()}
+        schema = json.load(tar.extractfile(members["/schema.cedarschema.json"]))
+        cedar = tar.extractfile(members["/policies.cedar"]).read().decode()
+    assert schema["ExpenseApi"]["entityTypes"]["Expense"]["shape"]["attributes"]["amount"] == {
+        "type": "Extension", "name": "decimal", "required": False,
+    }
+    assert cedar == result["cedar"]
@@ -0,0 +1,178 @@
+"""Tests for CLI command authorization mining."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.repository import Repository
+from app.services.cli_command_extractor import extract_cli_commands
+from app.services.entry_point_extractor import EntryPointAuthorization, EntryPointKind
+from app.services.entry_point_service import EntryPointService
+
+COBRA_ROOT = """package cmd
+
+var rootCmd = &cobra.Command{
+	Use:   "opsctl",
+	Short: "Operator tool",
+}
+
+var tenantsCmd = &cobra.Command{
+	Use:               "tenants",
+	PersistentPreRunE: requireOperator,
+}
+
+func init() {
+	rootCmd.AddCommand(tenantsCmd, versionCmd)
+	tenantsCmd.AddCommand(newPurgeCmd())
+}
+
+func requireOperator(cmd *cobra.Command, args []string) error {
+	if !claims.HasRole("operator") {
+		return errors.New("operator role required")
+	}
+	return nil
+}
+"""
+
+COBRA_PURGE = """package cmd
+
+func newPurgeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "purge [tenant]",
+		RunE: runPurge,
+	}
+	return cmd
+}
+
+func runPurge(cmd *cobra.Command, args []string) error {
+	return store.DeleteTenant(args[0])
+}
+
+var versionCmd = &cobra.Command{
+	Use: "version",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(version)
+	},
+}
+"""
+
+CLICK_APP = """import click
+
+
+@click.group()
+def cli():
+    \"\"\"Admin commands.\"\"\"
+
+
+@cli.group()
+@click.pass_context
+def users(ctx):
+    ctx.obj = require_login()
+
+
+@users.command("grant-admin")
+@click.argument("email")
+def grant_admin(email):
+    User.get(email).is_admin = True
+
+
+@cli.command()
+def reindex():
+    search.rebuild()
+"""
+
+TYPER_APP = """import typer
+
+app = typer.Typer()
+keys_app = typer.Typer()
+app.add_typer(keys_app, name="keys")
+
+
+@keys_app.command()
+def rotate(service: str):
+    vault.rotate_secret(service)
+
+
+@app.command()
+def whoami_check():
+    if os.geteuid() != 0:
+        raise typer.Exit(1)
+    subprocess.run(["systemctl", "restart", "api"])
+"""
+
+THOR_APP = """class AdminCLI < Thor
+  desc "refund ORDER_ID", "Refund an order"
+  def refund(order_id)
+    Order.find(order_id).refund!
+  end
+
+  subcommand "accounts", AccountsCLI
+end
+
+class AccountsCLI < Thor
+  desc "delete ID", "Delete an account"
+  def delete(id)
+    authorize!(:destroy, Account)
+    Account.find(id).destroy
+  end
+end
+"""
+
+
+def _by_path(entries):
+    """Index CLI entry points by command path."""
+    return {e.trigger: e for e in entries}
+
+
+def test_cobra_tree_resolves_paths_and_persistent_hooks():
+    """Test command paths across files, constructor commands, and inherited persistent pre-runs."""
+    commands = _by_path(extract_cli_commands({"cmd/root.go": COBRA_ROOT, "cmd/purge.go": COBRA_PURGE}))
+
+    assert set(commands) == {"tenants purge", "version"}
+    purge = commands["tenants purge"]
+    assert (purge.kind, purge.framework, purge.name) == (EntryPointKind.CLI_COMMAND, "cobra", "newPurgeCmd")
+    assert (purge.line_start, purge.line_end, purge.privileged_operations) == (3, 9, ["delete"])
+    assert (purge.roles, purge.authorization) == (["OPERATOR"], EntryPointAuthorization.ROLE_CHECKED)
+    assert commands["version"].authorization == EntryPointAuthorization.UNCHECKED
+    assert not commands["version"].unchecked_privileged
+
+
+def test_click_and_typer_groups_and_callbacks():
+    """Test group names, dashed command names, group callbacks, and uid checks."""
+    commands = _by_path(extract_cli_commands({"manage.py": CLICK_APP, "tool.py": TYPER_APP}))
+
+    grant = commands["users grant-admin"]
+    assert (grant.framework, grant.privileged_operations) == ("click", ["access_grant"])
+    assert grant.authorization == EntryPointAuthorization.IDENTITY_CHECKED
+    assert commands["reindex"].privileged_operations == []
+    rotate = commands["keys rotate"]
+    assert (rotate.framework, rotate.privileged_operations, rotate.unchecked_privileged) == ("typer", ["credentials"], True)
+    assert commands["whoami-check"].identity_checked
+
+
+def test_thor_subcommands_and_permission_checks():
+    """Test desc'd methods become commands under their subcommand names."""
+    commands = _by_path(extract_cli_commands({"lib/cli.rb": THOR_APP}))
+
+    assert (commands["refund"].line_start, commands["refund"].unchecked_privileged) == (2, True)
+    delete = commands["accounts delete"]
+    assert (delete.name, delete.privileged_operations, delete.identity_checked) == ("delete", ["delete"], True)
+
+
+def test_services_list_cli_entry_points_in_their_own_section(tmp_path):
+    """Test CLI commands are inventoried beside async entry points and count toward flags."""
+    (tmp_path / "8" / "cmd").mkdir(parents=True)
+    (tmp_path / "8" / "cmd" / "root.go").write_text(COBRA_ROOT)
+    (tmp_path / "8" / "cmd" / "purge.go").write_text(COBRA_PURGE)
+    (tmp_path / "8" / "tool.py").write_text(TYPER_APP)
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 8, "ops"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+
+    service = EntryPointService(db, "acme", clone_dir=str(tmp_path))
+    with patch("app.services.entry_point_service.DecisionSimulationService.load_policies", return_value=[]):
+        [report] = service.services(repository_id=8, unchecked_only=True)
+
+    assert (report["entry_points"], report["by_kind"], report["unchecked_privileged"]) == ([], {"cli_command": 4}, 1)
+    assert [e["trigger"] for e in report["cli_entry_points"]] == ["keys rotate", "tenants purge", "version", "whoami-check"]
+    assert report["cli_entry_points"][0]["authorization"] == EntryPointAuthorization.UNCHECKED
@@ -0,0 +1,104 @@
+"""Tests for compliance framework mapping."""
+import json
+from datetime import UTC, datetime, timedelta
+from unittest.mock import MagicMock
+
+import pytest
+
+from app.services.compliance_mapping_service import (
+    DEFAULT_CONTROL_MAPPINGS,
+    ComplianceMappingService,
+    ControlStatus,
+    SignalStatus,
+    load_control_mappings,
+)
+
+
+def signal(status, value=0):
+    """Build a signal result."""
+    return {"status": status, "value": value, "detail": f"{status} detail"}
+
+
+@pytest.fixture
+def service():
+    """Create a service with every signal passing except hard-coded secrets."""
+    service = ComplianceMappingService(MagicMock(), tenant_id="tenant-1", mappings=DEFAULT_CONTROL_MAPPINGS)
+    signals = {name: signal(SignalStatus.PASS) for name in service.signal_providers}
+    signals["secrets_in_code"] = signal(SignalStatus.FAIL, 3)
+    signals["access_review_recency"] = signal(SignalStatus.UNKNOWN, None)
+    service.compute_signals = MagicMock(return_value=signals)
+    return service
+
+
+def test_posture_per_control(service):
+    """Test that control status follows its signals."""
+    report = service.posture("pci_dss")
+
+    assert [f["id"] for f in report["frameworks"]] == ["pci_dss"]
+    controls = {c["control_id"]: c for c in report["frameworks"][0]["controls"]}
+    assert controls["8.6.2"]["status"] == ControlStatus.FAILING
+    assert controls["7.2.1"]["status"] == ControlStatus.PASSING
+    assert controls["7.2.4"]["status"] == ControlStatus.NOT_ASSESSED
+    assert report["frameworks"][0]["failing"] == 1
+
+
+def test_unknown_framework(service):
+    """Test that an unconfigured framework is rejected."""
+    with pytest.raises(ValueError, match="not found"):
+        service.posture("hipaa")
+
+
+def test_export_csv(service):
+    """Test the auditor CSV export."""
+    lines = service.export_csv("iso27001").splitlines()
+
+    assert lines[0] == "framework,control_id,title,status,evidence"
+    assert len(lines) == 1 + len(DEFAULT_CONTROL_MAPPINGS["iso27001"]["controls"])
+    assert any(line.startswith("iso27001,A.5.17,") and "failing" in line for line in lines)
+
+
+def test_mapping_overrides(tmp_path):
+    """Test that an override file adds frameworks and replaces controls by ID."""
+    path = tmp_path / "mappings.json"
+    path.write_text(
+        json.dumps(
+            {
+                "soc2": {"controls": {"CC6.1": {"title": "Custom", "signals": ["policy_review"]}}},
+                "internal": {"name": "Internal standard", "controls": {"AC-1": {"signals": ["no_such_signal"]}}},
+            }
+        )
+    )
+
+    mappings = load_control_mappings(str(path))
+
+    assert mappings["soc2"]["controls"]["CC6.1"]["signals"] == ["policy_review"]
+    assert "CC6.2" in mappings["soc2"]["controls"]
+    assert mappings["internal"]["name"] == "Internal standard"
+    # Defaults are never mutated by overrides
+    assert DEFAULT_CONTROL_MAPPINGS["soc2"]["controls"]["CC6.1"]["title"] != "Custom"
+
+    service = ComplianceMappingService(MagicMock(), mappings={"internal": mappings["internal"]})
+    report = service.posture()
+    assert report["frameworks"][0]["controls"][0]["status"] == ControlStatus.NOT_ASSESSED
+
+    path.write_text("{not json")
+    with pytest.raises(ValueError, match="Invalid compliance mapping"):
+        load_control_mappings(str(path))
+
+
+def test_access_review_recency_signal():
+    """Test that access reviews only count while recent."""
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value = query
+    service = ComplianceMappingService(db, tenant_id="tenant-1", mappings={})
+
+    query.first.return_value = None
+    assert service.signal_providers["access_review_recency"]()["status"] == SignalStatus.FAIL
+
+    query.first.return_value = MagicMock(completed_at=datetime.now(UTC) - timedelta(days=10))
+    assert service.signal_providers["access_review_recency"]()["status"] == SignalStatus.PASS
+
+    query.first.return_value = MagicMock(completed_at=datetime.now(UTC) - timedelta(days=200))
+    assert service.signal_providers["access_review_recency"]()["status"] == SignalStatus.FAIL
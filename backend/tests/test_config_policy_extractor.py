@@ -0,0 +1,114 @@
+"""Tests for configuration file policy extraction."""
+from app.services.config_policy_extractor import (
+    ConfigKind,
+    extract_casbin,
+    extract_config_file,
+    extract_env,
+    extract_nginx,
+    extract_rego,
+)
+from app.services.extractor_utils import MAX_BLOCK_CHARS
+
+REGO = """package httpapi.authz
+
+import rego.v1
+
+default allow := false
+
+allow if {
+    input.method == "GET"
+    input.path == ["api", "expenses"]
+    "manager" in input.user.roles
+}
+
+deny contains msg if {
+    input.method == "DELETE"
+    input.user.role == "contractor"
+    msg := "contractors cannot delete"
+}
+"""
+
+NGINX = """server {
+    listen 80;
+    location /health {
+        auth_basic off;
+    }
+    location /admin/ {
+        satisfy any;
+        allow 10.0.0.0/8;
+        deny all;
+        auth_basic "Admin";
+        auth_basic_user_file /etc/nginx/htpasswd;
+    }
+    location / {
+        proxy_pass http://app:8080;
+    }
+}
+"""
+
+
+def test_extract_rego_rules():
+    """Test allow and deny rules become findings with roles, methods, and paths."""
+    allow, deny = extract_rego("opa/authz.rego", REGO)
+
+    assert allow.kind == ConfigKind.OPA_REGO
+    assert (allow.subject, allow.resource, allow.action) == ("manager", "/api/expenses", "GET")
+    assert (allow.line_start, allow.line_end) == (7, 11)
+    assert allow.snippet.startswith("allow if {")
+    assert 'input.method == "GET"' in allow.conditions
+    assert (deny.subject, deny.resource, deny.action) == ("contractor", "httpapi.authz", "deny DELETE")
+
+
+def test_unclosed_rego_rule_is_cut_off():
+    """Test a rule whose brace never closes stops at the block limit instead of the end of the file."""
+    text = "package authz\n\nallow if {\n" + 'input.method == "GET"\n' * (MAX_BLOCK_CHARS // 10)
+
+    (allow,) = extract_rego("opa/broken.rego", text)
+
+    assert allow.line_end < text.count("\n")
+
+
+def test_extract_casbin_policy():
+    """Test p lines become permissions and g lines role inheritance."""
+    findings = extract_casbin("casbin/policy.csv", "p, admin, /reports, GET\np, intern, /reports, DELETE, deny\ng, alice, admin\n")
+
+    assert [(f.subject, f.resource, f.action) for f in findings] == [
+        ("admin", "/reports", "GET"),
+        ("intern", "/reports", "deny DELETE"),
+        ("alice", "role admin", "inherit"),
+    ]
+    assert findings[2].line_start == 3
+
+
+def test_extract_nginx_locations():
+    """Test location blocks with auth directives become findings."""
+    health, admin = extract_nginx("etc/nginx/conf.d/app.conf", NGINX)
+
+    assert health.resource == "/health"
+    assert health.disables_auth is True
+    assert admin.resource == "/admin/"
+    assert admin.subject == "Authenticated user (HTTP basic) or Clients from 10.0.0.0/8"
+    assert (admin.line_start, admin.line_end) == (6, 12)
+    assert "satisfy any" in admin.conditions
+
+
+def test_extract_env_toggles():
+    """Test boolean security toggles are kept and disabling ones are flagged."""
+    findings = extract_env(
+        "config/Env", ["PATH=/usr/bin", "AUTH_DISABLED=true", "RBAC_ENABLED=false", "CSRF_PROTECTION=on", "LOG_LEVEL=debug"]
+    )
+
+    assert [(f.resource, f.disables_auth) for f in findings] == [
+        ("AUTH_DISABLED", True),
+        ("RBAC_ENABLED", True),
+        ("CSRF_PROTECTION", False),
+    ]
+    assert findings[0].line_start == 2
+
+
+def test_extract_config_file_dispatch():
+    """Test files are routed to the matching extractor by path."""
+    assert extract_config_file("policy/authz_test.rego", REGO) == []
+    assert extract_config_file("data/users.csv", "id,name\n1,alice\n") == []
+    assert extract_config_file("etc/nginx/sites-enabled/default", NGINX)[0].kind == ConfigKind.NGINX_AUTH
+    assert extract_config_file("app/.env", "SKIP_AUTH=1\n")[0].disables_auth is True
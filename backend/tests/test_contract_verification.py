@@ -0,0 +1,131 @@
+"""Tests for verifying mined policies against a running application."""
+from unittest.mock import AsyncMock, MagicMock, patch
+
+import httpx
+import pytest
+
+from app.services.contract_verification_service import (
+    ContractVerificationService,
+    VerificationOutcome,
+)
+from app.services.endpoint_mapping_service import EndpointRule
+
+
+@pytest.fixture
+def service():
+    """Create a service whose repository has two endpoints."""
+    service = ContractVerificationService(MagicMock(), tenant_id="tenant-1")
+    rules = [
+        EndpointRule(method="GET", path="/api/reports/{id}", roles=["AUDITOR"]),
+        EndpointRule(method="DELETE", path="/api/reports/{id}", roles=["ADMIN"]),
+    ]
+    service.test_generation_service.load_endpoint_rules = MagicMock(return_value=(MagicMock(), rules))
+    return service
+
+
+def make_response(status_code: int) -> MagicMock:
+    """Create a mock HTTP response."""
+    response = MagicMock()
+    response.status_code = status_code
+    return response
+
+
+def by_role(report: dict, method: str) -> dict:
+    """Index report results for one method by role."""
+    return {r["role"]: r for r in report["results"] if r["method"] == method}
+
+
+@pytest.mark.asyncio
+async def test_matching_enforcement(service):
+    """Test that a deployment enforcing the mined policy reports no mismatches."""
+
+    async def respond(method, path, headers):
+        if not headers:
+            return make_response(401)
+        return make_response(200 if headers["Authorization"] == "Bearer auditor" else 403)
+
+    with patch("httpx.AsyncClient") as mock_client:
+        mock_client.return_value.__aenter__.return_value.request = AsyncMock(side_effect=respond)
+
+        report = await service.verify_repository(
+            1,
+            base_url="https://staging.example.com",
+            credentials={
+                "auditor": {"Authorization": "Bearer auditor"},
+                "ADMIN": {"Authorization": "Bearer admin"},
+            },
+        )
+
+    gets = by_role(report, "GET")
+    assert gets["anonymous"]["outcome"] == VerificationOutcome.MATCH
+    assert gets["AUDITOR"]["outcome"] == VerificationOutcome.MATCH
+    assert gets["ADMIN"]["outcome"] == VerificationOutcome.MATCH
+    assert report["mismatched"] == 0
+    # DELETE cases are not sent without include_unsafe_methods
+    assert all(r["outcome"] == VerificationOutcome.SKIPPED for r in by_role(report, "DELETE").values())
+
+
+@pytest.mark.asyncio
+async def test_reports_unexpected_access_and_denial(service):
+    """Test that both directions of mismatch are reported."""
+
+    async def respond(method, path, headers):
+        # Broken deployment: admin can read, auditor cannot
+        if headers.get("Authorization") == "Bearer admin":
+            return make_response(200)
+        return make_response(403)
+
+    with patch("httpx.AsyncClient") as mock_client:
+        mock_client.return_value.__aenter__.return_value.request = AsyncMock(side_effect=respond)
+
+        report = await service.verify_repository(
+            1,
+            base_url="https://staging.example.com",
+            credentials={
+                "AUDITOR": {"Authorization": "Bearer auditor"},
+                "ADMIN": {"Authorization": "Bearer admin"},
+            },
+            include_unsafe_methods=True,
+        )
+
+    gets = by_role(report, "GET")
+    deletes = by_role(report, "DELETE")
+    assert gets["ADMIN"]["outcome"] == VerificationOutcome.UNEXPECTED_ACCESS
+    assert gets["AUDITOR"]["outcome"] == VerificationOutcome.UNEXPECTED_DENIAL
+    assert deletes["ADMIN"]["outcome"] == VerificationOutcome.MATCH
+    assert report["unexpected_access"] == 1
+    assert report["unexpected_denial"] == 1
+
+
+@pytest.mark.asyncio
+async def test_missing_credentials_and_errors(service):
+    """Test skipped roles without credentials and request failures."""
+    with patch("httpx.AsyncClient") as mock_client:
+        mock_client.return_value.__aenter__.return_value.request = AsyncMock(
+            side_effect=httpx.HTTPError("connection refused")
+        )
+
+        report = await service.verify_repository(1, base_url="https://staging.example.com", credentials={})
+
+    gets = by_role(report, "GET")
+    assert gets["anonymous"]["outcome"] == VerificationOutcome.ERROR
+    assert "connection refused" in gets["anonymous"]["detail"]
+    assert gets["AUDITOR"]["outcome"] == VerificationOutcome.SKIPPED
+    assert "no credentials" in gets["AUDITOR"]["detail"]
+
+
+@pytest.mark.asyncio
+async def test_not_found_counts_as_denial_only(service):
+    """Test that 404 satisfies a denial but not a refusal of an allowed role."""
+    with patch("httpx.AsyncClient") as mock_client:
+        mock_client.return_value.__aenter__.return_value.request = AsyncMock(return_value=make_response(404))
+
+        report = await service.verify_repository(
+            1,
+            base_url="https://staging.example.com",
+            credentials={"AUDITOR": {"Authorization": "Bearer auditor"}},
+        )
+
+    gets = by_role(report, "GET")
+    assert gets["anonymous"]["outcome"] == VerificationOutcome.MATCH
+    assert gets["AUDITOR"]["outcome"] == VerificationOutcome.MATCH
@@ -0,0 +1,140 @@
+"""Tests for CORS and CSRF policy extraction."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.cors_csrf_service import (
+    CorsCsrfService,
+    CsrfStatus,
+    extract_cors,
+    extract_csrf,
+    scope_matches,
+)
+
+EXPRESS = """const cors = require('cors')
+const corsOptions = {
+  origin: (origin, callback) => callback(null, true),
+  credentials: true,
+}
+app.use(cors(corsOptions))
+app.get('/public/feed', cors(), feed)
+app.use(csrf({ cookie: true }))
+"""
+
+FASTAPI = """app.add_middleware(
+    CORSMiddleware,
+    allow_origins=["https://app.example.com", "https://admin.example.com"],
+    allow_methods=["*"],
+    allow_credentials=True,
+)
+"""
+
+SPRING = """@Bean
+SecurityFilterChain filterChain(HttpSecurity http) throws Exception {
+    http.csrf(csrf -> csrf.ignoringRequestMatchers("/webhooks/**"));
+    return http.build();
+}
+
+public void addCorsMappings(CorsRegistry registry) {
+    registry.addMapping("/api/**")
+        .allowedOriginPatterns("*")
+        .allowCredentials(true);
+}
+"""
+
+DJANGO = """MIDDLEWARE = ["django.middleware.csrf.CsrfViewMiddleware"]
+CORS_ALLOWED_ORIGINS = [
+    "https://shop.example.com",
+]
+DEBUG = False
+CORS_ALLOW_CREDENTIALS = True
+"""
+
+
+def make_policy(policy_id, subject, snippet, conditions=None):
+    """Create a policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = "Order"
+    policy.action = "access"
+    policy.conditions = conditions
+    policy.description = None
+    ev = Mock(spec=Evidence)
+    ev.code_snippet = snippet
+    ev.file_path = "app.js"
+    ev.line_start = 1
+    policy.evidence = [ev]
+    return policy
+
+
+def test_extract_cors_across_frameworks():
+    """Test origins, credentials, reflection, scopes, and framework defaults."""
+    service_wide, feed = extract_cors("app.js", EXPRESS)
+    assert (service_wide.scope, service_wide.reflects_origin, service_wide.allow_credentials) == (None, True, True)
+    assert service_wide.credentialed_wildcard
+    assert (feed.scope, feed.wildcard_origin, feed.allow_credentials) == ("/public/feed", True, False)
+
+    [fastapi] = extract_cors("main.py", FASTAPI)
+    assert fastapi.origins == ["https://app.example.com", "https://admin.example.com"]
+    assert (fastapi.wildcard_origin, fastapi.allow_credentials, fastapi.line_end) == (False, True, 5)
+
+    [spring] = extract_cors("WebConfig.java", SPRING)
+    assert (spring.scope, spring.credentialed_wildcard) == ("/api/**", True)
+
+    [django] = extract_cors("settings.py", DJANGO)
+    assert django.origins == ["https://shop.example.com"]
+    assert (django.allow_credentials, django.wildcard_origin) == (True, False)
+    assert extract_cors("view.py", "x = request.headers['Authorization']") == []
+
+
+def test_extract_csrf_settings_and_exemptions():
+    """Test enabled middleware, service-wide disabling, and scoped exemptions."""
+    assert [(c.status, c.line_start) for c in extract_csrf("app.js", EXPRESS)] == [(CsrfStatus.ENABLED, 8)]
+    spring = {c.status: c for c in extract_csrf("SecurityConfig.java", SPRING)}
+    assert spring[CsrfStatus.EXEMPT].scopes == ["/webhooks/**"]
+    assert extract_csrf("Config.java", "http.csrf().disable();")[0].status == CsrfStatus.DISABLED
+    exempt = extract_csrf("views.py", "@csrf_exempt\n@app.post('/hooks/stripe')\ndef hook():")
+    assert [(c.status, c.scopes) for c in exempt] == [(CsrfStatus.EXEMPT, ["/hooks/stripe"])]
+
+
+def test_scope_matches_patterns():
+    """Test wildcard prefixes, exact paths, and parameter syntaxes."""
+    assert scope_matches("/api/**", "/api/orders/:id")
+    assert scope_matches(r"^/api/.*", "/api")
+    assert not scope_matches("/api/*", "/apiary")
+    assert scope_matches("/orders/{id}", "/orders/:orderId")
+    assert scope_matches("/**", "/anything")
+
+
+def test_services_flag_credentialed_wildcard_endpoints(tmp_path):
+    """Test settings from the clone resolve onto endpoints and flag credentialed wildcards."""
+    (tmp_path / "3").mkdir()
+    (tmp_path / "3" / "app.js").write_text(EXPRESS)
+    (tmp_path / "3" / "node_modules").mkdir()
+    (tmp_path / "3" / "node_modules" / "lib.js").write_text("app.use(cors({ origin: '*' }))")
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 3, "orders"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    policies = [
+        make_policy(1, "Authenticated users", "app.post('/orders', requireAuth, create)"),
+        make_policy(2, "Anonymous", "app.get('/public/feed', cors(), feed)"),
+        make_policy(3, "PARTNER", "app.post('/webhooks/partner', verifySignature, hook)", "CSRF check disabled"),
+    ]
+
+    service = CorsCsrfService(db, "acme", clone_dir=str(tmp_path))
+    with patch("app.services.cors_csrf_service.DecisionSimulationService.load_policies", return_value=policies):
+        [report] = service.services(repository_id=3)
+        assert service.services(flagged_only=True)[0]["repository_id"] == 3
+
+    assert (report["cloned"], report["csrf_protection"], report["flagged_endpoints"]) == (True, "enabled", 2)
+    assert len(report["cors"]) == 2
+    endpoints = {e["endpoint"]: e for e in report["endpoints"]}
+    assert endpoints["POST /orders"]["credentialed_wildcard"] is True
+    assert endpoints["POST /orders"]["csrf"] == "protected"
+    assert endpoints["GET /public/feed"]["credentialed_wildcard"] is False
+    assert endpoints["GET /public/feed"]["csrf"] == "not_applicable"
+    assert endpoints["POST /webhooks/partner"]["csrf"] == "exempt"
@@ -0,0 +1,102 @@
+"""Tests for authorization coverage metrics."""
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.policy import Evidence, Policy
+from app.services.coverage_metrics_service import (
+    CoverageMetricsService,
+    InventorySource,
+    route_key,
+)
+
+
+def make_policy(policy_id, subject, resource, action, conditions=None, snippet=None):
+    """Create a policy with optional evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = resource
+    policy.action = action
+    policy.conditions = conditions
+    evidence = []
+    if snippet:
+        ev = Mock(spec=Evidence)
+        ev.code_snippet = snippet
+        ev.file_path = "app.js"
+        ev.line_start = 1
+        evidence.append(ev)
+    policy.evidence = evidence
+    return policy
+
+
+POLICIES = [
+    make_policy(1, "Authenticated users", "Expense", "list", snippet="app.get('/api/expenses', auth, list)"),
+    make_policy(2, "ADMIN", "Expense", "delete", snippet="app.delete('/api/expenses/:id', requireRole('ADMIN'))"),
+    make_policy(
+        3,
+        "MANAGER",
+        "Expense",
+        "approve",
+        conditions="amount < 5000",
+        snippet="app.put('/api/expenses/:id/approve', requireRole('MANAGER'))",
+    ),
+    make_policy(4, "Members of the finance distribution list", "Report", "view"),
+]
+
+
+def test_route_key_normalizes_parameters():
+    """Test that parameter syntaxes compare equal."""
+    assert route_key("get", "/api/x/:id/") == route_key("GET", "/api/x/{id}") == route_key("GET", "/api/x/<int:id>")
+
+
+def test_compute_with_source_inventory():
+    """Test KPIs when the clone adds endpoints the miner found no checks for."""
+    discovered = {
+        route_key("GET", "/api/expenses"): "app.js",
+        route_key("DELETE", "/api/expenses/{id}"): "app.js",
+        route_key("PUT", "/api/expenses/{id}/approve"): "app.js",
+        route_key("GET", "/health"): "app.js",
+    }
+
+    report = CoverageMetricsService.compute(POLICIES[:3], discovered)
+
+    assert report["inventory_source"] == InventorySource.SOURCE
+    assert report["total_endpoints"] == 4
+    assert report["authenticated_percent"] == 75.0
+    assert report["authorized_percent"] == 50.0
+    assert report["conditional_percent"] == 25.0
+    assert report["unprotected_endpoints"] == ["GET /health"]
+    assert report["unknown_patterns"] == 0
+
+
+def test_compute_without_clone_counts_unknown_patterns():
+    """Test fallback to mined endpoints and unknown pattern counts."""
+    report = CoverageMetricsService.compute(POLICIES, None)
+
+    assert report["inventory_source"] == InventorySource.POLICIES
+    assert report["total_endpoints"] == 4
+    assert report["unknown_pattern_breakdown"]["unmapped_routes"] == 1
+    assert report["unknown_patterns"] >= 1
+
+
+def test_discover_routes(tmp_path):
+    """Test route discovery in a cloned repository, skipping vendored code."""
+    (tmp_path / "src").mkdir()
+    (tmp_path / "src" / "app.py").write_text('@app.get("/api/items/{id}")\ndef get_item(id): ...\n')
+    (tmp_path / "node_modules").mkdir()
+    (tmp_path / "node_modules" / "lib.js").write_text("router.get('/vendored', h)\n")
+
+    routes = CoverageMetricsService.discover_routes(tmp_path)
+
+    assert routes == {"GET /api/items/{}": "src/app.py"}
+
+
+def test_repository_coverage_not_found():
+    """Test that an unknown repository is rejected."""
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.all.return_value = []
+    service = CoverageMetricsService(db, tenant_id="tenant-1")
+
+    with pytest.raises(ValueError, match="not found"):
+        service.repository_coverage(99)
@@ -0,0 +1,172 @@
+"""Tests for custom detection rules."""
+from datetime import UTC, datetime
+from unittest.mock import Mock
+
+import pytest
+
+from app.models.custom_rule import CustomRule, CustomRuleHit, CustomRuleVersion
+from app.models.repository import Repository
+from app.services.custom_rule_service import (
+    CustomRuleService,
+    evaluate_rules,
+    normalize_definition,
+)
+
+GO_HANDLERS = """package handlers
+
+func DeleteOrder(c *gin.Context) {
+	if !authz.RequirePermission(c, "orders:delete") {
+		return
+	}
+}
+
+func Refund(c *gin.Context) {
+	authz.RequirePermission(c, "payments:refund")
+}
+"""
+
+PERMISSION_RULE = {
+    "name": "require-permission",
+    "pattern": r'RequirePermission\(c, "(?P<resource>\w+):(?P<action>\w+)"\)',
+    "languages": ["Go"],
+}
+
+
+def make_rule(rule_id=1, **definition):
+    """Create a stored rule from a definition."""
+    return CustomRule(id=rule_id, version=1, **normalize_definition({**PERMISSION_RULE, **definition}))
+
+
+@pytest.mark.parametrize(
+    "changes,error",
+    [
+        ({"name": " "}, "name is required"),
+        ({"pattern": "RequirePermission("}, "invalid pattern"),
+        ({"pattern": r"\w*"}, "matches empty text"),
+        ({"pattern": r"(?P<perm>\w+)"}, "unknown capture groups perm"),
+        ({"languages": ["Cobol"]}, "unknown languages Cobol"),
+        ({"paths": "src/**"}, "'paths' must be a list"),
+        ({"severity": "urgent"}, "severity must be one of"),
+        ({"owner": "payments"}, "unknown fields owner"),
+    ],
+)
+def test_invalid_definitions_are_rejected(changes, error):
+    """Test patterns, capture groups, scope, and severity are validated."""
+    with pytest.raises(ValueError, match=error):
+        normalize_definition({**PERMISSION_RULE, **changes})
+
+
+def test_rules_match_in_scope_files_and_report_captures():
+    """Test matches are counted in full, scoped by language and globs, with their named groups."""
+    sources = {"handlers/orders.go": GO_HANDLERS, "vendor.py": 'RequirePermission(c, "a:b")\n'}
+    rules = [
+        normalize_definition(PERMISSION_RULE),
+        normalize_definition({**PERMISSION_RULE, "name": "excluded", "exclude_paths": ["handlers/*"]}),
+    ]
+
+    results = evaluate_rules(rules, sources, max_matches=1)
+
+    assert [(r["name"], r["hits"], r["files"]) for r in results] == [("require-permission", 2, 1), ("excluded", 0, 0)]
+    assert results[0]["matches"] == [
+        {
+            "file_path": "handlers/orders.go",
+            "line": 4,
+            "snippet": 'if !authz.RequirePermission(c, "orders:delete") {',
+            "captures": {"resource": "orders", "action": "delete"},
+        }
+    ]
+
+
+def test_changes_are_versioned_and_can_be_restored(make_db):
+    """Test an update records a new version only when the definition changes, and restore reapplies one."""
+    rule = make_rule(severity="low")
+    original = CustomRuleVersion(rule_id=1, version=1, definition=normalize_definition({**PERMISSION_RULE, "severity": "low"}))
+    db = make_db({CustomRule: [rule], CustomRuleVersion: [original]})
+    service = CustomRuleService(db, "acme")
+
+    service.update_rule(1, {"severity": "low", "description": None})
+    assert rule.version == 1 and not db.add.called
+
+    service.update_rule(1, {"severity": "high"}, changed_by="dev@acme.com")
+    version = db.add.call_args.args[0]
+    assert (rule.version, rule.severity) == (2, "high")
+    assert (version.version, version.change, version.definition["severity"]) == (2, "updated", "high")
+
+    service.restore(1, 1, changed_by="dev@acme.com")
+    restored = db.add.call_args.args[0]
+    assert (rule.version, rule.severity, restored.change) == (3, "low", "restored")
+
+    with pytest.raises(ValueError, match="invalid pattern"):
+        service.update_rule(1, {"pattern": "("})
+    assert rule.version == 3
+
+
+def test_sync_reports_every_invalid_rule_and_applies_all_or_nothing(make_db):
+    """Test a sync creates, updates, and prunes by name, and applies nothing when any rule is invalid."""
+    unchanged, stale, removed = make_rule(1), make_rule(2, name="role-check"), make_rule(3, name="legacy")
+    db = make_db({CustomRule: [unchanged, stale, removed]})
+    service = CustomRuleService(db, "acme")
+    definitions = [
+        PERMISSION_RULE,
+        {**PERMISSION_RULE, "name": "role-check", "severity": "critical"},
+        {**PERMISSION_RULE, "name": "scope-check"},
+    ]
+
+    with pytest.raises(ValueError, match="Rule 'bad': invalid pattern.*Rule 'role-check' is defined more than once"):
+        service.sync([*definitions, {"name": "bad", "pattern": "("}, definitions[1]])
+    db.commit.assert_not_called()
+
+    plan = service.sync(definitions, prune=True, dry_run=True)
+    assert plan == {
+        "created": ["scope-check"],
+        "updated": ["role-check"],
+        "unchanged": ["require-permission"],
+        "deleted": ["legacy"],
+        "dry_run": True,
+    }
+    assert stale.version == 1 and not db.add.called and not db.delete.called
+
+    service.sync(definitions, prune=True, changed_by="ci@acme.com")
+    assert (stale.version, stale.severity) == (2, "critical")
+    db.delete.assert_called_once_with(removed)
+    added = [c.args[0] for c in db.add.call_args_list]
+    assert {type(a).__name__ for a in added} == {"CustomRule", "CustomRuleVersion"}
+    assert {a.change for a in added if isinstance(a, CustomRuleVersion)} == {"synced"}
+    db.commit.assert_called_once()
+
+
+def test_scan_hits_are_recorded_and_summarized(tmp_path, make_db):
+    """Test enabled rules are evaluated against the clone and hit stats cover every scan."""
+    (tmp_path / "3" / "handlers").mkdir(parents=True)
+    (tmp_path / "3" / "handlers" / "orders.go").write_text(GO_HANDLERS)
+    rule, disabled = make_rule(1), make_rule(2, name="off", enabled=False)
+    db = make_db({CustomRule: [rule, disabled], Repository: [Mock(spec=Repository, id=3)]})
+
+    hits = CustomRuleService(db, "acme", str(tmp_path)).record_hits(3, scan_id=9)
+
+    assert [(h.rule_id, h.rule_version, h.hits, h.files, h.scan_id) for h in hits] == [(1, 1, 2, 1, 9)]
+    assert hits[0].matches[1]["captures"] == {"resource": "payments", "action": "refund"}
+
+    preview = CustomRuleService(db, "acme", str(tmp_path)).dry_run(3, definitions=[{**PERMISSION_RULE, "languages": ["Python"]}])
+    assert (preview["files_evaluated"], preview["rules"][0]["hits"]) == (1, 0)
+
+    rule.version = 2
+    seen = datetime(2026, 10, 1, tzinfo=UTC)
+    recorded = [
+        CustomRuleHit(rule_id=1, rule_version=1, repository_id=3, hits=2, created_at=seen),
+        CustomRuleHit(rule_id=1, rule_version=2, repository_id=4, hits=0, created_at=datetime.now(UTC)),
+    ]
+    stats = CustomRuleService(make_db({CustomRule: [rule, disabled], CustomRuleHit: recorded}), "acme").stats()
+    assert stats[0] == {
+        "rule_id": 1,
+        "name": "require-permission",
+        "version": 2,
+        "enabled": True,
+        "scans_evaluated": 2,
+        "scans_with_hits": 1,
+        "total_hits": 2,
+        "current_version_hits": 0,
+        "repositories_hit": 1,
+        "last_hit_at": seen,
+    }
+    assert stats[1]["scans_evaluated"] == 0
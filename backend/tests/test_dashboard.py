@@ -0,0 +1,99 @@
+"""Tests for configurable dashboard widgets."""
+from unittest.mock import MagicMock, Mock, patch
+
+import pytest
+
+from app.models.dashboard_widget import DashboardWidget
+from app.models.policy import Policy, PolicyStatus, RiskLevel
+from app.services.dashboard_service import (
+    ChartType,
+    DashboardMetric,
+    DashboardService,
+    validate_widget,
+)
+from app.services.trend_metrics_service import TrendMetricsService
+
+
+def make_policy(status, risk_level):
+    """Create a mock policy."""
+    policy = Mock(spec=Policy)
+    policy.status = status
+    policy.risk_level = risk_level
+    return policy
+
+
+def make_widget(metric, chart_type=ChartType.BAR, filters=None, owner_email=None):
+    """Create a widget."""
+    return DashboardWidget(
+        id=1, title="Widget", metric=metric, chart_type=chart_type, filters=filters or {}, owner_email=owner_email
+    )
+
+
+@pytest.mark.parametrize(
+    "metric,chart_type,filters,message",
+    [
+        ("unknown", ChartType.BAR, {}, "Unknown metric"),
+        (DashboardMetric.COVERAGE_TREND, ChartType.PIE, {}, "cannot be shown"),
+        (DashboardMetric.FINDINGS_TREND, ChartType.LINE, {"repository_id": 1}, "does not support"),
+        (DashboardMetric.FINDINGS_TREND, ChartType.LINE, {"weeks": 500}, "between 1 and"),
+        (DashboardMetric.POLICIES_BY_RISK, ChartType.PIE, {"repository_id": "3"}, "integer"),
+    ],
+)
+def test_validate_widget_rejects(metric, chart_type, filters, message):
+    """Test unsupported widget configurations are rejected."""
+    with pytest.raises(ValueError, match=message):
+        validate_widget(metric, chart_type, filters)
+
+
+def test_policies_by_risk_data():
+    """Test policy counts are aggregated per risk level."""
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.all.return_value = [
+        make_policy(PolicyStatus.APPROVED, RiskLevel.HIGH),
+        make_policy(PolicyStatus.PENDING, RiskLevel.HIGH),
+        make_policy(PolicyStatus.PENDING, None),
+    ]
+    service = DashboardService(db, tenant_id="tenant-1")
+
+    data = service.widget_data(make_widget(DashboardMetric.POLICIES_BY_RISK, filters={"repository_id": 2}))
+
+    assert data["error"] is None
+    assert data["data"] == [{"label": "high", "value": 2}, {"label": "unscored", "value": 1}]
+
+
+def test_widget_data_reports_provider_errors():
+    """Test a failing widget reports its error instead of breaking the dashboard."""
+    service = DashboardService(MagicMock())
+    with patch.object(TrendMetricsService, "open_findings", side_effect=ValueError("Repository 9 not found")):
+        data = service.widget_data(make_widget(DashboardMetric.OPEN_FINDINGS, filters={"repository_id": 9}))
+
+    assert data["data"] is None
+    assert data["error"] == "Repository 9 not found"
+
+
+def test_get_widget_hides_other_users_widgets():
+    """Test personal widgets are only visible to their owner."""
+    db = MagicMock()
+    db.query.return_value.filter.return_value.first.return_value = make_widget(
+        DashboardMetric.COVERAGE, owner_email="a@example.com"
+    )
+    service = DashboardService(db)
+
+    assert service.get_widget(1, "a@example.com").owner_email == "a@example.com"
+    with pytest.raises(ValueError, match="not found"):
+        service.get_widget(1, "b@example.com")
+
+
+def test_update_widget_validates_merged_configuration():
+    """Test updates are checked against the widget's resulting configuration."""
+    service = DashboardService(MagicMock())
+    widget = make_widget(DashboardMetric.COVERAGE_TREND, chart_type=ChartType.LINE)
+
+    with pytest.raises(ValueError, match="cannot be shown"):
+        service.update_widget(widget, chart_type=ChartType.PIE)
+
+    service.update_widget(widget, title="Coverage", filters={"interval": "day"}, metric=None)
+    assert widget.title == "Coverage"
+    assert widget.metric == DashboardMetric.COVERAGE_TREND
@@ -0,0 +1,150 @@
+"""Tests for condition evaluation and decision simulation."""
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.policy import Evidence, Policy, PolicyStatus
+from app.services.condition_evaluation_service import ConditionEvaluationService
+from app.services.decision_simulation_service import (
+    Decision,
+    DecisionSimulationService,
+    RuleOutcome,
+    SimulationRequest,
+)
+
+
+def make_policy(policy_id, subject, resource, action, conditions=None, snippet=None):
+    """Create a policy with optional evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = resource
+    policy.action = action
+    policy.conditions = conditions
+    policy.status = PolicyStatus.PENDING
+    evidence = []
+    if snippet:
+        ev = Mock(spec=Evidence)
+        ev.code_snippet = snippet
+        ev.file_path = "main.go"
+        ev.line_start = 10
+        evidence.append(ev)
+    policy.evidence = evidence
+    return policy
+
+
+@pytest.fixture
+def service():
+    """Create a service backed by policies resembling the sample expense app."""
+    policies = [
+        make_policy(
+            1,
+            "MANAGER or DIRECTOR",
+            "Expense",
+            "approve",
+            conditions="amount > 5000 requires DIRECTOR",
+            snippet='r.HandleFunc("/api/expenses/{id}/approve", RequireAnyRole(approve)).Methods("PUT")',
+        ),
+        make_policy(2, "ADMIN", "Expense", "delete"),
+        make_policy(3, "Authenticated users", "Expense", "update", conditions="User is owner AND not approved"),
+    ]
+    service = DecisionSimulationService(MagicMock(), tenant_id="tenant-1")
+    service.load_policies = MagicMock(return_value=policies)
+    return service
+
+
+@pytest.mark.parametrize(
+    "condition,attributes,roles,expected",
+    [
+        ("amount > 5000 requires DIRECTOR", {"amount": 6000}, ["MANAGER"], False),
+        ("amount > 5000 requires DIRECTOR", {"amount": 6000}, ["DIRECTOR"], True),
+        ("amount > 5000 requires DIRECTOR", {"amount": 100}, ["MANAGER"], True),
+        ("amount > 5000 requires DIRECTOR", {}, ["MANAGER"], None),
+        ("User department is Finance", {"department": "finance"}, [], True),
+        ("user.department == 'Finance'", {"department": "HR"}, [], False),
+        ("amount at most $1,000", {"amount": 999}, [], True),
+        ("something nobody can parse", {"amount": 1}, [], None),
+        (None, {}, [], True),
+    ],
+)
+def test_condition_evaluation(condition, attributes, roles, expected):
+    """Test evaluation of common mined condition shapes."""
+    assert ConditionEvaluationService.evaluate(condition, attributes, roles).satisfied is expected
+
+
+def test_ownership_and_flag_conditions():
+    """Test ownership and negated flag clauses joined by AND."""
+    condition = "User is owner AND not approved"
+    clauses = ConditionEvaluationService.parse(condition)
+    assert [c.kind for c in clauses] == ["ownership", "flag"]
+
+    owned = {"owner_id": "u1", "approved": False}
+    assert ConditionEvaluationService.evaluate(condition, owned, [], subject_id="u1").satisfied is True
+    assert ConditionEvaluationService.evaluate(condition, owned, [], subject_id="u2").satisfied is False
+    assert ConditionEvaluationService.evaluate(condition, owned, []).satisfied is None
+
+
+def test_simulate_allows_when_condition_holds(service):
+    """Test that a matching role and satisfied condition fire the policy."""
+    result = service.simulate(
+        SimulationRequest(
+            action="approve",
+            resource="Expense",
+            roles=["MANAGER"],
+            resource_attributes={"amount": 100},
+        )
+    )
+
+    assert result["decision"] == Decision.ALLOW
+    assert result["fired_policy_ids"] == [1]
+    assert result["evaluations"][0]["outcome"] == RuleOutcome.FIRED
+
+
+def test_simulate_denies_when_threshold_requires_other_role(service):
+    """Test that a failing condition denies and reports the clause."""
+    result = service.simulate(
+        SimulationRequest(
+            action="PUT",
+            resource="/api/expenses/42/approve",
+            roles=["MANAGER"],
+            resource_attributes={"amount": 6000},
+        )
+    )
+
+    assert result["decision"] == Decision.DENY
+    assert [e["policy_id"] for e in result["evaluations"]] == [1]
+    evaluation = result["evaluations"][0]
+    assert evaluation["outcome"] == RuleOutcome.CONDITION_FAILED
+    assert evaluation["condition_clauses"][0]["satisfied"] is False
+
+
+def test_simulate_reports_role_mismatch_and_default_deny(service):
+    """Test role mismatches and requests no policy covers."""
+    result = service.simulate(SimulationRequest(action="delete", resource="Expense", roles=["MANAGER"]))
+    assert result["decision"] == Decision.DENY
+    assert result["evaluations"][0]["outcome"] == RuleOutcome.ROLE_MISMATCH
+
+    result = service.simulate(SimulationRequest(action="export", resource="Invoice", roles=["ADMIN"]))
+    assert result["decision"] == Decision.DENY
+    assert result["evaluations"] == []
+    assert "default deny" in result["reason"]
+
+
+def test_simulate_indeterminate_without_attributes(service):
+    """Test that missing attributes never grant access."""
+    result = service.simulate(SimulationRequest(action="update", resource="Expense", roles=["EMPLOYEE"]))
+
+    assert result["decision"] == Decision.DENY
+    assert result["evaluations"][0]["outcome"] == RuleOutcome.INDETERMINATE
+    assert "could not be evaluated" in result["reason"]
+
+    result = service.simulate(
+        SimulationRequest(
+            action="update",
+            resource="Expense",
+            subject_id="u1",
+            resource_attributes={"owner_id": "u1", "approved": False},
+        )
+    )
+    assert result["decision"] == Decision.ALLOW
+    assert result["fired_policy_ids"] == [3]
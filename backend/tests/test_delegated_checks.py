@@ -0,0 +1,127 @@
+"""Tests for attributing checks delegated to helper functions back to routes."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.delegated_check_extractor import calls, predicate_roles, role_checks
+from app.services.delegated_check_service import DelegatedCheckService
+
+HANDLERS = """package handlers
+
+func ApproveExpense(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if err := authz.EnsureManager(user); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if !canExport(user) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func canExport(u *User) bool {
+	return u.HasRole("AUDITOR") || u.HasRole("DIRECTOR")
+}
+
+func DeleteExpense(w http.ResponseWriter, r *http.Request) {
+	if err := authz.EnsureManager(GetUserFromContext(r.Context())); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+}
+
+func RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/expenses/{id}/approve", RequireAuth(ApproveExpense)).Methods("PUT")
+	r.HandleFunc("/api/expenses/{id}", RequireRole("ADMIN")(DeleteExpense)).Methods("DELETE")
+}
+"""
+
+AUTHZ = """package authz
+
+func EnsureManager(u *User) error {
+	if err := ensureActive(u); err != nil {
+		return err
+	}
+	if !u.HasRole("MANAGER") {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func ensureActive(u *User) error {
+	if u.Suspended {
+		return ErrForbidden
+	}
+	return nil
+}
+"""
+
+DJANGO = """def ensure_reviewer(user):
+    if not user.has_role("reviewer") and not user.is_admin:
+        raise PermissionDenied
+
+
+def is_finance(user):
+    return user.role == "finance"
+
+
+def close_period(request):
+    ensure_reviewer(request.user)
+    if not is_finance(request.user):
+        return HttpResponseForbidden()
+    return render(request, "closed.html")
+"""
+
+
+def test_helpers_are_read_for_calls_role_requirements_and_predicates():
+    """Test raising helpers, role-returning predicates, and calls in a handler body."""
+    lines = DJANGO.split("\n")
+    assert [c.roles for c in role_checks(lines, 0, 4, helper=True)] == [["REVIEWER", "ADMIN"]]
+    returned = predicate_roles(lines, 5, 8)
+    assert (returned.line, returned.roles) == (7, ["FINANCE"])
+    assert [name for _, name in calls(lines, 9, len(lines))] == ["ensure_reviewer", "is_finance", "HttpResponseForbidden", "render"]
+
+    [check] = role_checks(lines, 9, len(lines), lambda name: ["FINANCE"] if name == "is_finance" else None)
+    assert (check.line, check.roles, check.predicate) == (12, ["FINANCE"], "is_finance")
+    # Returning an error only denies from inside a helper
+    assert role_checks(AUTHZ.split("\n"), 2, 11) == []
+
+
+def test_delegated_checks_are_attributed_to_routes_to_the_configured_depth(tmp_path):
+    """Test helpers in other files, predicates, depth limits, and subjects already naming roles."""
+    root = tmp_path / "5"
+    (root / "handlers").mkdir(parents=True)
+    (root / "authz").mkdir()
+    (root / "handlers" / "expenses.go").write_text(HANDLERS)
+    (root / "authz" / "authz.go").write_text(AUTHZ)
+    lines = HANDLERS.split("\n")
+
+    approve = Policy(id=1, repository_id=5, subject="Authenticated", resource="/api/expenses/{id}/approve", action="PUT")
+    approve.evidence = [Evidence(file_path="handlers/expenses.go", line_start=28, line_end=28, code_snippet=lines[27])]
+    delete = Policy(id=2, repository_id=5, subject="ADMIN", resource="/api/expenses/{id}", action="DELETE")
+    delete.evidence = [Evidence(file_path="handlers/expenses.go", line_start=29, line_end=29, code_snippet=lines[28])]
+    repo = Mock(spec=Repository, id=5, tenant_id="acme")
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    db.query.return_value.filter.return_value.all.return_value = [approve, delete]
+    service = DelegatedCheckService(db, "acme", str(tmp_path))
+
+    result = service.attribute_checks(5, depth=1)
+    assert (result["depth"], result["policies_updated"]) == (1, 1)
+    assert approve.subject == "(AUDITOR or DIRECTOR) and MANAGER"
+    assert approve.conditions is None
+    assert [(c["policy_id"], c["handler"], c["chain"], c["file_path"], c["line"], c["roles"], c["added"]) for c in result["checks"]] == [
+        (1, "ApproveExpense", ["canExport"], "handlers/expenses.go", 17, ["AUDITOR", "DIRECTOR"], True),
+        (1, "ApproveExpense", ["EnsureManager"], "authz/authz.go", 7, ["MANAGER"], True),
+        (2, "DeleteExpense", ["EnsureManager"], "authz/authz.go", 7, ["MANAGER"], False),
+    ]
+    assert delete.subject == "ADMIN"
+    assert [(e.file_path, e.line_start) for e in approve.evidence][1:] == [("handlers/expenses.go", 17), ("authz/authz.go", 7)]
+
+    approve.subject = "Authenticated"
+    result = service.attribute_checks(5, depth=2)
+    assert approve.conditions == "not suspended"
+    [suspended] = [c for c in result["checks"] if c["condition"]][:1]
+    assert (suspended["chain"], suspended["depth"], suspended["line"], suspended["added"]) == (["EnsureManager", "ensureActive"], 2, 14, True)
@@ -0,0 +1,179 @@
+"""Tests for Django and DRF view authorization mining."""
+from app.services.django_route_extractor import DjangoRouteKind, extract_django_routes
+
+PROJECT_URLS = """from django.urls import include, path
+
+urlpatterns = [path("shop/", include("shop.urls")), path("ops/", include("tools.urls"))]
+"""
+
+SHOP_URLS = """from django.urls import include, path, re_path
+from rest_framework.routers import DefaultRouter
+
+from . import views
+from .api import OrderViewSet
+
+router = DefaultRouter()
+router.register(r"orders", OrderViewSet, basename="order")
+
+urlpatterns = [
+    path("", views.index),
+    path("dashboard/", views.dashboard, name="dashboard"),
+    re_path(r"^invoices/(?P<pk>[0-9]+)/$", views.InvoiceView.as_view()),
+    path("api/", include(router.urls)),
+]
+"""
+
+SHOP_VIEWS = """from django.contrib.auth.decorators import login_required, permission_required
+from django.contrib.auth.mixins import LoginRequiredMixin, PermissionRequiredMixin
+from django.views import View
+
+
+def index(request):
+    return render(request, "index.html")
+
+
+@login_required
+@permission_required("shop.view_dashboard")
+def dashboard(request):
+    return render(request, "dashboard.html")
+
+
+class InvoiceView(LoginRequiredMixin, PermissionRequiredMixin, View):
+    permission_required = "shop.view_invoice"
+
+    def get(self, request, pk):
+        return render(request, "invoice.html")
+"""
+
+TOOLS = {
+    "tools/urls.py": 'from django.urls import path\nfrom tools.views import purge\n\nurlpatterns = [path("purge/", purge)]\n',
+    "tools/views.py": """from django.contrib.auth.decorators import user_passes_test
+from django.views.decorators.http import require_POST
+
+
+@require_POST
+@user_passes_test(lambda u: u.is_superuser or u.groups.filter(name="ops").exists())
+def purge(request):
+    cache.clear()
+""",
+}
+
+PERMISSIONS = """from rest_framework.permissions import SAFE_METHODS, BasePermission
+
+
+class IsManagerOrReadOnly(BasePermission):
+    def has_permission(self, request, view):
+        if request.method in SAFE_METHODS:
+            return True
+        return request.user and request.user.groups.filter(name="managers").exists()
+"""
+
+API = """from rest_framework import permissions, viewsets
+from rest_framework.decorators import action
+
+from .permissions import IsManagerOrReadOnly
+
+
+class OrderViewSet(viewsets.ModelViewSet):
+    queryset = Order.objects.all()
+    permission_classes = [permissions.IsAuthenticated & IsManagerOrReadOnly]
+
+    @action(detail=True, methods=["post"], permission_classes=[permissions.IsAdminUser])
+    def refund(self, request, pk=None):
+        return Response(status=202)
+"""
+
+
+def _by_route(findings):
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_decorated_and_mixin_views_are_mapped_through_included_urls():
+    """Test decorators and mixins guard the URL patterns that route to them, under their include() prefixes."""
+    files = {"project/urls.py": PROJECT_URLS, "shop/urls.py": SHOP_URLS, "shop/views.py": SHOP_VIEWS, **TOOLS}
+    findings = [f for f in extract_django_routes(files) if f.kind == DjangoRouteKind.VIEW]
+    routes = _by_route(findings)
+
+    # The unguarded index view is not a finding
+    assert sorted(routes) == [("*", "/shop/dashboard"), ("GET", "/shop/invoices/{pk}"), ("POST", "/ops/purge")]
+    dashboard = routes[("*", "/shop/dashboard")]
+    assert dashboard.subject == "Authenticated users"
+    assert dashboard.conditions == "requires permission shop.view_dashboard"
+    assert dashboard.description == "Django view dashboard secured by @login_required and @permission_required(shop.view_dashboard)"
+    assert dashboard.file_path == "shop/views.py"
+    invoice = routes[("GET", "/shop/invoices/{pk}")]
+    assert invoice.conditions == "requires permission shop.view_invoice"
+    assert invoice.description.endswith("LoginRequiredMixin and PermissionRequiredMixin(shop.view_invoice)")
+    assert routes[("POST", "/ops/purge")].subject == "superuser or ops"
+
+
+def test_viewset_permissions_are_resolved_per_action_and_method():
+    """Test router registrations, & compositions, custom has_permission bodies, and @action overrides."""
+    files = {"shop/urls.py": SHOP_URLS, "shop/views.py": SHOP_VIEWS, "shop/permissions.py": PERMISSIONS, "shop/api.py": API}
+    routes = _by_route(f for f in extract_django_routes(files) if f.kind == DjangoRouteKind.DRF_VIEW)
+
+    assert sorted(routes) == [
+        ("DELETE", "/api/orders/{pk}"),
+        ("GET", "/api/orders"),
+        ("GET", "/api/orders/{pk}"),
+        ("PATCH", "/api/orders/{pk}"),
+        ("POST", "/api/orders"),
+        ("POST", "/api/orders/{pk}/refund"),
+        ("PUT", "/api/orders/{pk}"),
+    ]
+    # Reads pass IsManagerOrReadOnly's safe-method branch; writes need the managers group
+    assert routes[("GET", "/api/orders")].subject == "Authenticated users"
+    create = routes[("POST", "/api/orders")]
+    assert create.subject == "managers"
+    assert create.description == (
+        "DRF view OrderViewSet.create secured by permission_classes = [permissions.IsAuthenticated & IsManagerOrReadOnly]"
+    )
+    refund = routes[("POST", "/api/orders/{pk}/refund")]
+    assert refund.subject == "staff"
+    assert refund.description == "DRF view OrderViewSet.refund secured by @action(permission_classes=[permissions.IsAdminUser])"
+
+
+def test_drf_defaults_apply_to_views_without_permission_classes():
+    """Test DEFAULT_PERMISSION_CLASSES from settings, DRF's AllowAny fallback, and guarded views no URL routes to."""
+    views = """from rest_framework import generics
+from rest_framework.decorators import api_view, permission_classes
+from rest_framework.permissions import IsAuthenticatedOrReadOnly
+from django.contrib.auth.decorators import login_required
+
+
+@api_view(["GET", "POST"])
+@permission_classes([IsAuthenticatedOrReadOnly])
+def comments(request):
+    return Response([])
+
+
+class ArticleList(generics.ListCreateAPIView):
+    queryset = Article.objects.all()
+
+
+@login_required
+def export(request):
+    return export_all()
+"""
+    urls = """from django.urls import path
+from api import views
+
+urlpatterns = [path("comments/", views.comments), path("articles/", views.ArticleList.as_view())]
+"""
+    open_findings = _by_route(extract_django_routes({"api/views.py": views, "api/urls.py": urls}))
+
+    assert open_findings[("GET", "/comments")].subject == "Anonymous"
+    assert open_findings[("GET", "/comments")].disables_auth
+    assert open_findings[("POST", "/comments")].subject == "Authenticated users"
+    articles = open_findings[("POST", "/articles")]
+    assert (articles.subject, articles.disables_auth) == ("Anonymous", True)
+    assert articles.description == "DRF view ArticleList secured by DRF's default AllowAny (no DEFAULT_PERMISSION_CLASSES setting)"
+    unrouted = open_findings[("*", "api.views.export")]
+    assert unrouted.description == "Django view export (not routed in urls.py) secured by @login_required"
+
+    settings = 'REST_FRAMEWORK = {"DEFAULT_PERMISSION_CLASSES": ["rest_framework.permissions.DjangoModelPermissions"]}\n'
+    files = {"api/views.py": views, "api/urls.py": urls, "config/settings.py": f"# Django settings\n{settings}"}
+    defaulted = _by_route(extract_django_routes(files))
+    assert defaulted[("GET", "/articles")].conditions is None
+    assert defaulted[("POST", "/articles")].conditions == "requires permission add_article"
+    assert not defaulted[("POST", "/articles")].disables_auth
@@ -0,0 +1,79 @@
+"""Tests for flagging endpoints of one resource that are authorized inconsistently."""
+from unittest.mock import Mock
+
+from app.models.policy import Policy
+from app.models.policy_annotation import PolicyAnnotationRecord
+from app.models.repository import Repository
+from app.services.endpoint_consistency_service import (
+    EndpointConsistencyService,
+    InconsistencyKind,
+    compare,
+    resource_group,
+)
+from app.services.endpoint_mapping_service import EndpointRule
+
+
+def make_service(make_db, policies, annotations=()):
+    """Service over one repository with the given policies and annotations."""
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 4, "expenses"
+    db = make_db({Repository: [repository], Policy: policies, PolicyAnnotationRecord: list(annotations)})
+    return EndpointConsistencyService(db, "acme")
+
+
+def test_pairs_are_compared_by_method_and_access():
+    """Test each inconsistency kind, the looser endpoint first, and pairs that fit together."""
+    admin_delete = EndpointRule("DELETE", "/api/expenses/:id", roles=["ADMIN"])
+    manager_put = EndpointRule("PUT", "/api/expenses/:id", roles=["MANAGER"])
+    anyone_post = EndpointRule("POST", "/api/expenses")
+    anonymous_patch = EndpointRule("PATCH", "/api/expenses/:id", requires_authentication=False)
+    auditor_get = EndpointRule("GET", "/api/expenses/:id", roles=["AUDITOR"])
+    anyone_get = EndpointRule("GET", "/api/expenses")
+
+    assert compare(admin_delete, manager_put) == (InconsistencyKind.WRITE_ROLE_MISMATCH, manager_put, admin_delete)
+    assert compare(admin_delete, anyone_post) == (InconsistencyKind.UNRESTRICTED_WRITE, anyone_post, admin_delete)
+    assert compare(anyone_get, anonymous_patch) == (InconsistencyKind.ANONYMOUS_WRITE, anonymous_patch, anyone_get)
+    assert compare(auditor_get, manager_put) == (InconsistencyKind.READ_STRICTER_THAN_WRITE, manager_put, auditor_get)
+    assert compare(anyone_get, admin_delete) is None
+    assert compare(anyone_get, anyone_post) is None
+    assert compare(admin_delete, EndpointRule("DELETE", "/api/expenses", roles=["MANAGER"])) is None
+
+    assert resource_group("/api/expenses/:id") == resource_group("/api/expenses/") == "/api/expenses"
+    assert resource_group("/api/expenses/:id/approve") == "/api/expenses/{}/approve"
+    assert resource_group("/{id}") == "/"
+
+
+def test_undocumented_inconsistencies_are_reported_with_evidence(make_db, make_policy):
+    """Test grouping by resource, both endpoints' evidence, and suppression by a documented reason."""
+    policies = [
+        make_policy(1, "ADMIN", "delete", "router.delete('/api/expenses/:id', requireRole('ADMIN'), remove)", line=10),
+        make_policy(2, "MANAGER", "update", "router.put('/api/expenses/:id', requireRole('MANAGER'), update)", line=12),
+        make_policy(3, "ADMIN", "approve", "router.post('/api/expenses/:id/approve', requireRole('ADMIN'), approve)", line=14),
+    ]
+    result = make_service(make_db, policies).inconsistencies()
+    (finding,) = result["inconsistencies"]
+    assert (finding["resource"], finding["kind"], finding["severity"], finding["repository_name"]) == (
+        "/api/expenses", "write_role_mismatch", "medium", "expenses"
+    )
+    assert (finding["stricter"]["method"], finding["stricter"]["line"], finding["looser"]["access"]) == ("DELETE", 10, "MANAGER")
+    assert finding["description"] == (
+        "DELETE /api/expenses/:id requires ADMIN while PUT /api/expenses/:id on the same resource requires only MANAGER "
+        "and neither rule documents why"
+    )
+    assert result["summary"] == {
+        "resources": 1,
+        "endpoints": 3,
+        "documented": 0,
+        "inconsistencies": 1,
+        "by_kind": {"write_role_mismatch": 1},
+        "by_severity": {"medium": 1},
+    }
+
+    annotation = Mock(spec=PolicyAnnotationRecord, file_path="routes.js", line=9, target_line=10)
+    annotation.text = "role=ADMIN resource=expense action=delete"
+    documented = make_service(make_db, policies, [annotation]).inconsistencies()
+    assert (documented["inconsistencies"], documented["summary"]["documented"]) == ([], 1)
+
+    policies[1].approval_comment = "Managers edit drafts; deletion is audited separately"
+    assert make_service(make_db, policies).inconsistencies()["summary"]["documented"] == 1
+    assert make_service(make_db, policies).inconsistencies(severity="high")["inconsistencies"] == []
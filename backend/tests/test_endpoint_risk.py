@@ -0,0 +1,167 @@
+"""Tests for composite endpoint risk scoring."""
+import json
+from unittest.mock import MagicMock, Mock, patch
+
+import pytest
+
+from app.models.conflict import ConflictStatus, PolicyConflict
+from app.models.inconsistent_enforcement import InconsistentEnforcement
+from app.models.policy import Evidence, Policy, SourceType
+from app.models.policy_change import PolicyChange
+from app.models.policy_fix import FixSeverity, FixStatus, PolicyFix
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.endpoint_risk_service import (
+    DEFAULT_RISK_MODEL,
+    EndpointRiskService,
+    FindingHistory,
+    load_risk_model,
+)
+
+
+def make_policy(policy_id, subject, resource, action, snippet, conditions=None, source_type=SourceType.BACKEND):
+    """Create a policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = resource
+    policy.action = action
+    policy.conditions = conditions
+    policy.source_type = source_type
+    ev = Mock(spec=Evidence)
+    ev.code_snippet = snippet
+    ev.file_path = "app.js"
+    ev.line_start = 1
+    policy.evidence = [ev]
+    return policy
+
+
+POLICIES = [
+    make_policy(1, "anonymous", "Payment", "create", "app.post('/api/public/payments', create)"),
+    make_policy(2, "ADMIN", "Report", "view", "app.get('/internal/reports', requireRole('ADMIN'))"),
+    make_policy(
+        3,
+        "MANAGER",
+        "Expense",
+        "approve",
+        "app.put('/api/expenses/:id/approve', requireRole('MANAGER'))",
+        conditions="amount < 5000",
+    ),
+]
+
+
+def test_components():
+    """Test each component for a public payment endpoint."""
+    service = EndpointRiskService(MagicMock(), model=load_risk_model(""))
+    rule = EndpointRule(method="POST", path="/api/public/payments", requires_authentication=False, resource="Payment")
+
+    sensitivity, factors = service.data_sensitivity(rule)
+    assert sensitivity == 90.0
+    assert "sensitive data keyword 'payment'" in factors
+    assert service.exposure(rule, set())[0] == 100.0
+    assert service.auth_strength(rule)[0] == 100.0
+
+    history = FindingHistory()
+    history.open["security_gap"] = 2
+    history.resolved["conflict"] = 1
+    score, factors = service.finding_history(history)
+    assert score == 80.0
+    assert factors == ["2 open security gap"]
+
+
+def test_score_endpoints_orders_by_risk(make_db):
+    """Test endpoints are ranked highest risk first, with finding history counted."""
+    conflict = Mock(spec=PolicyConflict, policy_a_id=3, policy_b_id=99, status=ConflictStatus.PENDING)
+    change = Mock(spec=PolicyChange, policy_id=3)
+    db = make_db({PolicyConflict: [conflict], PolicyChange: [change]})
+    service = EndpointRiskService(db, tenant_id="tenant-1", model=load_risk_model(""))
+
+    results = service.score_endpoints(policies=POLICIES)
+
+    assert [r["endpoint"] for r in results] == [
+        "POST /api/public/payments",
+        "PUT /api/expenses/:id/approve",
+        "GET /internal/reports",
+    ]
+    assert results[0]["level"] == "high"
+    assert results[1]["components"]["finding_history"] == 22.5
+    assert results[2]["level"] == "low"
+
+
+def test_rank_findings_by_endpoint_risk(make_db):
+    """Test open findings inherit the risk of their riskiest endpoint."""
+    fix = Mock(spec=PolicyFix, id=7, policy_id=2, severity=FixSeverity.CRITICAL, gap_description="gap")
+    fix.status, fix.security_gap_type = FixStatus.PENDING, "privilege_escalation"
+    conflict = Mock(spec=PolicyConflict, id=8, policy_a_id=1, policy_b_id=3, severity="low", description="conflict")
+    conflict.status = ConflictStatus.PENDING
+    db = make_db({PolicyFix: [fix], PolicyConflict: [conflict], InconsistentEnforcement: []})
+    service = EndpointRiskService(db, model=load_risk_model(""))
+
+    with patch(
+        "app.services.endpoint_risk_service.DecisionSimulationService.load_policies", return_value=POLICIES
+    ):
+        findings = service.rank_findings()
+
+    assert [(f["finding_type"], f["finding_id"]) for f in findings] == [("conflict", 8), ("security_gap", 7)]
+    assert findings[0]["endpoint"] == "POST /api/public/payments"
+    assert (findings[1]["cwe"], findings[1]["owasp_api"]) == (["CWE-269"], ["API5:2023"])
+
+
+def test_exposure_from_clone_weights_risk_and_severity(tmp_path, make_db):
+    """Test inferred exposure outranks path hints and shifts finding severity."""
+    (tmp_path / "4" / "deploy").mkdir(parents=True)
+    (tmp_path / "4" / "deploy" / "ingress.yaml").write_text(
+        "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: web\n"
+        "spec:\n  rules:\n    - http:\n        paths:\n          - path: /internal/reports\n"
+    )
+    policies = [
+        make_policy(2, "ADMIN", "Report", "view", "app.get('/internal/reports', requireRole('ADMIN'))"),
+        make_policy(4, "ADMIN", "Job", "run", "app.post('/jobs/run', requireRole('ADMIN'))"),
+    ]
+    for policy in policies:
+        policy.repository_id = 4
+    fix = Mock(spec=PolicyFix, id=7, policy_id=2, severity=FixSeverity.HIGH, gap_description="gap")
+    fix.status = FixStatus.PENDING
+    other = Mock(spec=PolicyFix, id=9, policy_id=4, severity=FixSeverity.HIGH, gap_description="gap")
+    other.status = FixStatus.PENDING
+    db = make_db({PolicyFix: [fix, other], PolicyConflict: [], InconsistentEnforcement: []})
+    service = EndpointRiskService(db, model=load_risk_model(""), clone_dir=str(tmp_path))
+
+    reports, jobs = service.score_endpoints(policies=policies)
+    assert (reports["path"], reports["exposure"], reports["components"]["exposure"]) == (
+        "/internal/reports",
+        "internet_facing",
+        90.0,
+    )
+    assert "internet-facing per Ingress web (deploy/ingress.yaml)" in reports["factors"]
+    # Not routed by the only public Ingress
+    assert (jobs["exposure"], jobs["components"]["exposure"]) == ("internal_only", 10.0)
+
+    with patch("app.services.endpoint_risk_service.DecisionSimulationService.load_policies", return_value=policies):
+        findings = service.rank_findings()
+    assert [(f["finding_id"], f["exposure"], f["weighted_severity"]) for f in findings] == [
+        (7, "internet_facing", "critical"),
+        (9, "internal_only", "medium"),
+    ]
+    assert service.weighted_severity("critical", "internet_facing") == "critical"
+
+
+def test_load_risk_model_override(tmp_path):
+    """Test override files merge into the default model."""
+    path = tmp_path / "risk.json"
+    path.write_text(json.dumps({"weights": {"exposure": 0.5}, "levels": {"high": 80}, "default_sensitivity": 30}))
+
+    model = load_risk_model(str(path))
+
+    assert model["weights"]["exposure"] == 0.5
+    assert model["weights"]["data_sensitivity"] == DEFAULT_RISK_MODEL["weights"]["data_sensitivity"]
+    assert model["levels"] == {"high": 80, "medium": 40}
+    assert model["default_sensitivity"] == 30
+
+
+def test_load_risk_model_invalid(tmp_path):
+    """Test unreadable override files raise ValueError."""
+    path = tmp_path / "risk.json"
+    path.write_text("{not json")
+
+    with pytest.raises(ValueError, match="Invalid risk model file"):
+        load_risk_model(str(path))
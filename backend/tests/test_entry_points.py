@@ -0,0 +1,222 @@
+"""Tests for non-HTTP entry point authorization mining."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.entry_point_extractor import EntryPointAuthorization, EntryPointKind, extract_entry_points
+from app.services.entry_point_service import EntryPointService
+
+SPRING_LISTENERS = """@Component
+public class OrderListeners {
+    @KafkaListener(topics = "orders.refunds", groupId = "billing")
+    public void onRefund(RefundRequest request) {
+        paymentGateway.refund(request.getOrderId(), request.getAmount());
+    }
+
+    @PreAuthorize("hasRole('ADMIN')")
+    @RabbitListener(queues = "user-admin")
+    public void onPromote(PromoteCommand command) {
+        userService.grantRole(command.getUserId(), "ADMIN");
+    }
+
+    @Scheduled(cron = "0 0 3 * * *")
+    public void purgeExpired() {
+        accountRepository.deleteAllByExpiredTrue();
+    }
+}
+"""
+
+CELERY_TASKS = """from celery import shared_task
+
+
+@shared_task(bind=True, name="billing.refund")
+def refund_order(self, order_id, actor_id):
+    order = Order.objects.get(pk=order_id)
+    if not has_permission(actor_id, "refund"):
+        raise PermissionDenied()
+    order.refund()
+
+
+@app.task
+def delete_account(user_id):
+    User.objects.filter(pk=user_id).delete()
+
+
+def poll():
+    while True:
+        response = sqs.receive_message(QueueUrl=QUEUE_URL, MaxNumberOfMessages=10)
+        for message in response.get("Messages", []):
+            subprocess.run(["cleanup", message["Body"]])
+
+
+def handler(event, context):
+    for record in event["Records"]:
+        process(record)
+"""
+
+SIDEKIQ_JOBS = """class PurgeWorker
+  include Sidekiq::Worker
+  sidekiq_options queue: :maintenance
+
+  def perform(account_id)
+    Account.find(account_id).destroy!
+  end
+end
+
+class ReportJob < ApplicationJob
+  queue_as :reports
+
+  def perform(user)
+    return unless user.admin?
+    Report.generate(user)
+  end
+end
+"""
+
+NODE_CONSUMERS = """const consumer = kafka.consumer({ groupId: 'admin' })
+await consumer.subscribe({ topic: 'user-roles' })
+await consumer.run({
+  eachMessage: async ({ message }) => {
+    const cmd = JSON.parse(message.value)
+    await users.assignRole(cmd.userId, cmd.role)
+  },
+})
+cron.schedule('0 * * * *', purgeSessions)
+async function purgeSessions() {
+  await db.sessions.deleteMany({ expired: true })
+}
+const worker = new Worker('emails', async (job) => {
+  await mailer.send(job.data)
+})
+"""
+
+GO_CONSUMERS = """package main
+
+func (h *handler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		if !verifySignature(msg) {
+			continue
+		}
+		h.store.Delete(string(msg.Key))
+	}
+	return nil
+}
+
+func main() {
+	c := cron.New()
+	c.AddFunc("@daily", rotateKeys)
+	group.Consume(ctx, []string{"deletions"}, h)
+}
+
+func rotateKeys() {
+	keys.RotateSecrets()
+}
+
+func handle(ctx context.Context, e events.SQSEvent) error {
+	return nil
+}
+"""
+
+CRONJOB = """apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: purge-tenants
+spec:
+  schedule: "0 4 * * *"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: purge
+              image: app
+              command: ["python", "manage.py"]
+              args: ["purge_tenants", "--delete"]
+"""
+
+
+def _by_name(entries):
+    """Index entry points by handler name."""
+    return {e.name: e for e in entries}
+
+
+def test_spring_listeners_and_scheduled_methods():
+    """Test listener triggers, annotations above the listener, and privileged operations."""
+    entries = _by_name(extract_entry_points("OrderListeners.java", SPRING_LISTENERS))
+
+    refund = entries["onRefund"]
+    assert (refund.kind, refund.framework, refund.trigger) == (EntryPointKind.MESSAGE_CONSUMER, "kafka", "orders.refunds")
+    assert (refund.privileged_operations, refund.unchecked_privileged) == (["payment"], True)
+    promote = entries["onPromote"]
+    assert (promote.roles, promote.line_start, promote.authorization) == (["ADMIN"], 8, EntryPointAuthorization.ROLE_CHECKED)
+    purge = entries["purgeExpired"]
+    assert (purge.kind, purge.trigger, purge.privileged_operations) == (EntryPointKind.SCHEDULED_JOB, "0 0 3 * * *", ["delete"])
+
+
+def test_python_and_ruby_tasks_and_consumers():
+    """Test Celery task names, permission checks, SQS and Lambda consumers, and Ruby job classes."""
+    entries = _by_name(extract_entry_points("tasks.py", CELERY_TASKS))
+    assert (entries["refund_order"].trigger, entries["refund_order"].authorization) == (
+        "billing.refund",
+        EntryPointAuthorization.IDENTITY_CHECKED,
+    )
+    assert entries["delete_account"].unchecked_privileged
+    poll = entries["poll"]
+    assert (poll.framework, poll.trigger, poll.privileged_operations) == ("sqs", "QUEUE_URL", ["shell"])
+    assert (entries["handler"].framework, entries["handler"].line_end) == ("aws-lambda", 26)
+
+    jobs = _by_name(extract_entry_points("app/workers/purge_worker.rb", SIDEKIQ_JOBS))
+    assert (jobs["PurgeWorker"].framework, jobs["PurgeWorker"].trigger, jobs["PurgeWorker"].line_end) == ("sidekiq", "maintenance", 8)
+    assert jobs["PurgeWorker"].unchecked_privileged
+    assert (jobs["ReportJob"].framework, jobs["ReportJob"].roles) == ("activejob", ["ADMIN"])
+
+
+def test_node_go_and_kubernetes_entry_points():
+    """Test inline and named handlers, signature checks, cron functions, and CronJob commands."""
+    node = extract_entry_points("consumer.js", NODE_CONSUMERS)
+    assert [(e.framework, e.name, e.trigger) for e in node] == [
+        ("kafka", "kafka", "user-roles"),
+        ("node-cron", "purgeSessions", "0 * * * *"),
+        ("bullmq", "bullmq", "emails"),
+    ]
+    assert node[0].privileged_operations == ["access_grant"]
+    assert node[1].privileged_operations == ["delete", "bulk_write"]
+
+    go = _by_name(extract_entry_points("main.go", GO_CONSUMERS))
+    claim = go["ConsumeClaim"]
+    assert (claim.trigger, claim.authorization) == ("deletions", EntryPointAuthorization.IDENTITY_CHECKED)
+    assert (go["rotateKeys"].trigger, go["rotateKeys"].privileged_operations) == ("@daily", ["credentials"])
+    assert (go["handle"].framework, go["handle"].trigger) == ("aws-lambda", "SQS")
+
+    [cronjob] = extract_entry_points("deploy/cronjob.yaml", CRONJOB)
+    assert (cronjob.name, cronjob.trigger, cronjob.line_start) == ("purge-tenants", "0 4 * * *", 4)
+    assert cronjob.unchecked_privileged
+
+
+def test_services_credit_policies_mined_inside_handlers(tmp_path):
+    """Test a policy mined inside a handler counts as its check and flags sort first."""
+    (tmp_path / "5" / "workers").mkdir(parents=True)
+    (tmp_path / "5" / "workers" / "purge_worker.rb").write_text(SIDEKIQ_JOBS)
+    (tmp_path / "5" / "vendor").mkdir()
+    (tmp_path / "5" / "vendor" / "tasks.py").write_text(CELERY_TASKS)
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 5, "maintenance"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    policy = Mock(spec=Policy)
+    policy.id, policy.subject = 9, "SUPPORT"
+    evidence = Mock(spec=Evidence)
+    evidence.file_path, evidence.line_start = "workers/purge_worker.rb", 6
+    policy.evidence = [evidence]
+
+    service = EntryPointService(db, "acme", clone_dir=str(tmp_path))
+    with patch("app.services.entry_point_service.DecisionSimulationService.load_policies", return_value=[policy]):
+        [report] = service.services(repository_id=5)
+        assert service.services(unchecked_only=True) == []
+
+    assert (report["cloned"], report["by_kind"], report["unchecked_privileged"]) == (True, {"background_task": 2}, 0)
+    purge = report["entry_points"][0]
+    assert (purge["name"], purge["roles"], purge["policy_ids"]) == ("PurgeWorker", ["SUPPORT"], [9])
+    assert purge["authorization"] == EntryPointAuthorization.ROLE_CHECKED
@@ -0,0 +1,154 @@
+"""Tests for scan environment tagging and cross-environment comparison."""
+from unittest.mock import MagicMock, Mock, patch
+
+import pytest
+
+from app.models.policy import Evidence, Policy, SourceType
+from app.models.repository import Repository
+from app.models.scan_environment import EnvironmentSource, ScanEnvironment
+from app.models.scan_progress import ScanProgress
+from app.services.environment_comparison_service import (
+    EnvironmentComparisonService,
+    branch_environment,
+    compare_snapshots,
+    find_gated_checks,
+)
+
+
+def make_policy(policy_id, subject, action, snippet):
+    """Create a backend policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id, policy.subject, policy.resource, policy.action = policy_id, subject, "Invoice", action
+    policy.conditions, policy.description, policy.source_type = None, None, SourceType.BACKEND
+    evidence = Mock(spec=Evidence)
+    evidence.code_snippet, evidence.file_path, evidence.line_start = snippet, "routes.js", policy_id
+    policy.evidence = [evidence]
+    return policy
+
+
+def make_tag(environment, endpoints, gated_checks=None, tag_id=1):
+    """Create a stored environment tag."""
+    tag = Mock(spec=ScanEnvironment)
+    tag.id, tag.repository_id, tag.scan_id, tag.environment = tag_id, 3, tag_id, environment
+    tag.endpoints, tag.gated_checks = endpoints, gated_checks or []
+    return tag
+
+
+def endpoint(key, roles=(), authenticated=True, conditions=()):
+    """Snapshot entry of one endpoint."""
+    return {
+        "endpoint_id": f"ep_{key}",
+        "endpoint": key,
+        "roles": list(roles),
+        "requires_authentication": authenticated,
+        "conditions": list(conditions),
+        "mechanism": "jwt_bearer",
+        "policy_ids": [],
+    }
+
+
+def test_find_gated_checks_reports_environments_running_without_auth():
+    """Test disabled and conditionally installed checks resolve to the environments they relax."""
+    js = "if (process.env.NODE_ENV !== 'production') {\n  app.use(authenticate)\n}\napp.listen(80)\n"
+    py = 'if os.getenv("ENV") == "dev":\n    AUTH_ENABLED = False\n'
+    unrelated = "if (env === 'prod') {\n  logger.level = 'warn'\n}\n"
+
+    [prod_gap] = find_gated_checks("server.js", js)
+    [dev_gap] = find_gated_checks("settings.py", py)
+
+    assert (prod_gap["kind"], prod_gap["relaxed_in"], prod_gap["line_start"]) == ("conditional", ["prod"], 1)
+    assert "app.listen" not in prod_gap["snippet"]
+    assert (dev_gap["kind"], dev_gap["relaxed_in"]) == ("disabled", ["dev"])
+    assert find_gated_checks("log.js", unrelated) == []
+
+
+def test_detect_environment_from_branch_then_manifest_namespace(tmp_path):
+    """Test the branch name wins and manifests are the fallback."""
+    staging = tmp_path / "1"
+    (staging / ".git").mkdir(parents=True)
+    (staging / ".git" / "HEAD").write_text("ref: refs/heads/release/staging\n")
+    manifests = tmp_path / "2" / "deploy"
+    manifests.mkdir(parents=True)
+    (manifests / "deployment.yaml").write_text(
+        "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n  namespace: payments-prod\n"
+    )
+    service = EnvironmentComparisonService(MagicMock(), "acme", str(tmp_path))
+
+    assert service.detect_environment(1) == ("staging", EnvironmentSource.BRANCH, "release/staging")
+    assert service.detect_environment(2) == ("prod", EnvironmentSource.MANIFEST, "deploy/deployment.yaml")
+    assert service.detect_environment(3) is None
+    assert branch_environment("feature/dev-tools") is None
+    assert branch_environment("deploy-prod") == "prod"
+
+
+def test_compare_snapshots_flags_prod_relaxations():
+    """Test each kind of relaxation of prod relative to staging."""
+    gate = {
+        "file_path": "server.js",
+        "line_start": 4,
+        "condition": "if (process.env.NODE_ENV === 'production') {",
+        "kind": "disabled",
+        "relaxed_in": ["prod"],
+        "snippet": "",
+    }
+    everywhere = {**gate, "line_start": 9, "relaxed_in": ["dev", "staging", "prod"]}
+    staging = make_tag(
+        "staging",
+        [
+            endpoint("GET /invoices"),
+            endpoint("DELETE /invoices/{id}", roles=["ADMIN"]),
+            endpoint("PUT /invoices/{id}", roles=["ADMIN"], conditions=["amount < 5000"]),
+        ],
+    )
+    prod = make_tag(
+        "prod",
+        [
+            endpoint("GET /invoices", authenticated=False),
+            endpoint("DELETE /invoices/{id}"),
+            endpoint("PUT /invoices/{id}", roles=["ADMIN", "CLERK"]),
+            endpoint("GET /debug", authenticated=False),
+        ],
+        gated_checks=[gate, everywhere],
+    )
+
+    relaxations = compare_snapshots(staging, prod)
+
+    assert [(r["kind"], r["endpoint"]) for r in relaxations] == [
+        ("environment_gated", None),
+        ("authentication_removed", "GET /invoices"),
+        ("roles_removed", "DELETE /invoices/{id}"),
+        ("unprotected_endpoint_added", "GET /debug"),
+        ("roles_broadened", "PUT /invoices/{id}"),
+        ("conditions_removed", "PUT /invoices/{id}"),
+    ]
+    assert relaxations[0]["line_start"] == 4
+    assert relaxations[4]["roles_added"] == ["CLERK"]
+    assert relaxations[5]["conditions_removed"] == ["amount < 5000"]
+    assert all(r["prod_only"] for r in relaxations)
+
+
+def test_tag_scan_snapshots_latest_scan_and_compare_uses_latest_tags(make_db):
+    """Test tagging snapshots the endpoint model and comparison pairs environments."""
+    policies = [make_policy(1, "ADMIN", "delete", "app.delete('/invoices/:id', requireRole('ADMIN'), remove)")]
+    scan = Mock(spec=ScanProgress, id=6, repository_id=3)
+    db = make_db({ScanProgress: [scan]})
+    with patch("app.services.environment_comparison_service.DecisionSimulationService.load_policies", return_value=policies):
+        tag = EnvironmentComparisonService(db, "acme", "/nonexistent").tag_scan(6, "Production", tagged_by="sec@example.com")
+
+    assert (tag.environment, tag.source, tag.repository_id, tag.gated_checks) == ("prod", EnvironmentSource.MANUAL, 3, [])
+    assert [(e["endpoint"], e["roles"]) for e in tag.endpoints] == [("DELETE /invoices/:id", ["ADMIN"])]
+
+    older = make_db({ScanProgress: [Mock(spec=ScanProgress, id=5, repository_id=3), scan]})
+    with pytest.raises(ValueError, match="latest scan"):
+        EnvironmentComparisonService(older, "acme").tag_scan(5, "prod")
+
+    tags = [make_tag("prod", [endpoint("GET /a", authenticated=False)], tag_id=9), make_tag("staging", [endpoint("GET /a")], tag_id=8)]
+    tags.append(make_tag("prod", [endpoint("GET /a")], tag_id=2))
+    service = EnvironmentComparisonService(make_db({Repository: [Mock(spec=Repository, id=3)], ScanEnvironment: tags}), "acme")
+
+    comparison = service.compare(3, "stage", "prod")
+
+    assert (comparison["base"].id, comparison["target"].id) == (8, 9)
+    assert comparison["relaxation_counts"] == {"critical": 1}
+    with pytest.raises(ValueError, match="dev"):
+        service.compare(3, "dev", "prod")
@@ -0,0 +1,106 @@
+"""Tests for highlight-ready evidence regions."""
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.policy import Evidence, Policy
+from app.services.evidence_detail_service import (
+    MAX_REGION_LINES,
+    EvidenceDetailService,
+    RegionSource,
+    language_for,
+)
+
+
+def make_evidence(evidence_id, line_start, line_end, file_path="app/views.py", snippet=""):
+    """Create mock evidence."""
+    evidence = Mock(spec=Evidence)
+    evidence.id = evidence_id
+    evidence.policy_id = 1
+    evidence.file_path = file_path
+    evidence.line_start = line_start
+    evidence.line_end = line_end
+    evidence.code_snippet = snippet
+    return evidence
+
+
+@pytest.fixture
+def clone_dir(tmp_path):
+    """Scanned clone of repository 7 with a 100-line source file."""
+    source = tmp_path / "7" / "app" / "views.py"
+    source.parent.mkdir(parents=True)
+    source.write_text("\n".join(f"line {n}" for n in range(1, 101)))
+    return tmp_path
+
+
+@pytest.mark.parametrize(
+    "file_path,language",
+    [("a/b.py", "python"), ("x.TSX", "tsx"), ("PAYROLL.cbl", "cobol"), ("Makefile", "plaintext")],
+)
+def test_language_for(file_path, language):
+    """Test highlighter language detection."""
+    assert language_for(file_path) == language
+
+
+def test_build_regions_adds_context_and_merges_overlaps():
+    """Test context padding, clamping, and merging of nearby ranges."""
+    lines = [f"line {n}" for n in range(1, 101)]
+    evidence = [make_evidence(1, 10, 12), make_evidence(2, 15, 16), make_evidence(3, 98, 120)]
+
+    regions = EvidenceDetailService.build_regions(lines, evidence, context_lines=3)
+
+    assert [(r.start_line, r.end_line) for r in regions] == [(7, 19), (95, 100)]
+    assert [h.evidence_id for h in regions[0].highlights] == [1, 2]
+    assert regions[0].lines[0] == {"number": 7, "text": "line 7"}
+    assert regions[1].highlights[0].end_line == 100
+
+
+def test_build_regions_caps_region_length():
+    """Test that a huge line range never ships the whole file."""
+    lines = ["x"] * (MAX_REGION_LINES * 3)
+    regions = EvidenceDetailService.build_regions(lines, [make_evidence(1, 1, len(lines))], context_lines=0)
+
+    assert regions[0].truncated is True
+    assert len(regions[0].lines) == MAX_REGION_LINES
+
+
+def test_get_evidence_from_clone(clone_dir):
+    """Test a region cut from the scanned clone."""
+    db = MagicMock()
+    policy = Mock(spec=Policy, id=1, repository_id=7)
+    db.query.return_value.join.return_value.filter.return_value.filter.return_value.first.return_value = (
+        make_evidence(5, 50, 51),
+        policy,
+    )
+    service = EvidenceDetailService(db, tenant_id="tenant-1", clone_dir=str(clone_dir))
+
+    detail = service.get_evidence(5, context_lines=2)
+
+    assert detail["source"] == RegionSource.REPOSITORY
+    assert detail["language"] == "python"
+    assert detail["total_lines"] == 100
+    assert [line["number"] for line in detail["regions"][0]["lines"]] == [48, 49, 50, 51, 52, 53]
+
+
+def test_falls_back_to_snippet_and_rejects_traversal(clone_dir):
+    """Test the stored snippet is used when the file is missing or outside the clone."""
+    service = EvidenceDetailService(MagicMock(), clone_dir=str(clone_dir))
+    evidence = make_evidence(9, 20, 21, file_path="../../etc/passwd", snippet="if user.is_admin:\n    allow()")
+
+    detail = service.build_file(7, evidence.file_path, [evidence], context_lines=5)
+
+    assert detail["source"] == RegionSource.SNIPPET
+    assert detail["total_lines"] is None
+    assert detail["regions"][0]["lines"] == [
+        {"number": 20, "text": "if user.is_admin:"},
+        {"number": 21, "text": "    allow()"},
+    ]
+
+
+def test_get_evidence_not_found():
+    """Test missing evidence raises ValueError."""
+    db = MagicMock()
+    db.query.return_value.join.return_value.filter.return_value.first.return_value = None
+
+    with pytest.raises(ValueError, match="not found"):
+        EvidenceDetailService(db).get_evidence(404)
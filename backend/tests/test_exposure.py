@@ -0,0 +1,266 @@
+"""Tests for endpoint exposure inference from deployment and gateway config."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.exposure_service import (
+    Exposure,
+    ExposureService,
+    ExposureSignal,
+    ExposureSource,
+    extract_gateway_signals,
+    extract_manifest_signals,
+    resolve_exposure,
+)
+from app.services.k8s_manifest_service import parse_documents
+
+MANIFESTS = """apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: storefront
+spec:
+  ingressClassName: nginx
+  rules:
+    - host: shop.example.com
+      http:
+        paths:
+          - path: /api/public
+            pathType: Prefix
+            backend:
+              service:
+                name: storefront
+                port:
+                  number: 80
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: backoffice
+  annotations:
+    alb.ingress.kubernetes.io/scheme: internal
+spec:
+  rules:
+    - http:
+        paths:
+          - path: /admin
+            pathType: Prefix
+            backend:
+              service:
+                name: storefront
+                port:
+                  number: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: reports
+  annotations:
+    service.beta.kubernetes.io/aws-load-balancer-internal: "true"
+spec:
+  type: LoadBalancer
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: metrics
+spec:
+  type: LoadBalancer
+  loadBalancerSourceRanges:
+    - 10.0.0.0/8
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: storefront
+spec:
+  ports:
+    - port: 80
+---
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: checkout
+spec:
+  hosts:
+    - checkout.example.com
+  gateways:
+    - istio-system/public-gateway
+  http:
+    - match:
+        - uri:
+            prefix: /checkout
+      route:
+        - destination:
+            host: checkout
+---
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: checkout-mesh
+spec:
+  hosts:
+    - checkout
+  http:
+    - route:
+        - destination:
+            host: checkout
+"""
+
+SERVERLESS = """service: invoices
+provider:
+  name: aws
+  endpointType: PRIVATE
+functions:
+  list:
+    handler: handler.list
+    events:
+      - http:
+          path: invoices/{id}
+          method: get
+"""
+
+SAM_TEMPLATE = """Transform: AWS::Serverless-2016-10-31
+Resources:
+  PublicApi:
+    Type: AWS::Serverless::Api
+    Properties:
+      StageName: prod
+  OrdersFunction:
+    Type: AWS::Serverless::Function
+    Properties:
+      Handler: !Sub "${AWS::StackName}-orders"
+      Events:
+        GetOrder:
+          Type: Api
+          Properties:
+            Path: /orders/{id}
+            Method: get
+"""
+
+KONG = """_format_version: "3.0"
+services:
+  - name: billing
+    url: http://billing:8080
+    routes:
+      - name: billing-api
+        paths:
+          - /billing
+  - name: ledger
+    url: http://ledger:8080
+    routes:
+      - hosts:
+          - ledger.internal
+        paths:
+          - /ledger
+"""
+
+TERRAFORM = """resource "aws_lb" "api" {
+  name               = "api"
+  internal           = false
+  load_balancer_type = "application"
+}
+
+resource "aws_lb" "jobs" {
+  internal = true
+}
+
+resource "aws_api_gateway_rest_api" "partners" {
+  name = "partners"
+  endpoint_configuration {
+    types = ["PRIVATE"]
+  }
+}
+"""
+
+
+def test_manifest_signals_from_ingress_load_balancers_and_istio():
+    """Test Ingress, LoadBalancer, ClusterIP, and VirtualService exposure."""
+    signals = extract_manifest_signals(parse_documents("k8s/app.yaml", MANIFESTS))
+
+    summary = [(s.source, s.name, s.exposure, s.scopes) for s in signals]
+    assert summary == [
+        (ExposureSource.INGRESS, "storefront", Exposure.INTERNET_FACING, ["/api/public/**"]),
+        (ExposureSource.INGRESS, "backoffice", Exposure.INTERNAL_ONLY, ["/admin/**"]),
+        (ExposureSource.LOAD_BALANCER, "reports", Exposure.INTERNAL_ONLY, []),
+        (ExposureSource.LOAD_BALANCER, "metrics", Exposure.INTERNAL_ONLY, []),
+        (ExposureSource.CLUSTER_SERVICE, "storefront", Exposure.INTERNAL_ONLY, []),
+        (ExposureSource.MESH_GATEWAY, "checkout", Exposure.INTERNET_FACING, ["/checkout/**"]),
+    ]
+    assert signals[0].hosts == ["shop.example.com"]
+    assert signals[1].reason == "alb.ingress.kubernetes.io/scheme=internal"
+    assert signals[3].reason == "loadBalancerSourceRanges 10.0.0.0/8"
+
+
+def test_gateway_signals_from_serverless_sam_kong_and_terraform():
+    """Test API gateway definitions and Terraform load balancers."""
+    [serverless] = extract_gateway_signals("serverless.yml", SERVERLESS)
+    [sam] = extract_gateway_signals("template.yaml", SAM_TEMPLATE)
+    billing, ledger = extract_gateway_signals("kong.yml", KONG)
+    terraform = extract_gateway_signals("infra/main.tf", TERRAFORM)
+
+    assert (serverless.exposure, serverless.scopes, serverless.line_start) == (
+        Exposure.INTERNAL_ONLY,
+        ["/invoices/{id}"],
+        5,
+    )
+    assert (sam.exposure, sam.scopes) == (Exposure.INTERNET_FACING, ["/orders/{id}"])
+    assert (billing.exposure, billing.scopes) == (Exposure.INTERNET_FACING, ["/billing/**"])
+    assert (ledger.exposure, ledger.reason) == (Exposure.INTERNAL_ONLY, "private hosts ledger.internal")
+    assert [(s.name, s.exposure, s.line_start) for s in terraform] == [
+        ("api", Exposure.INTERNET_FACING, 1),
+        ("jobs", Exposure.INTERNAL_ONLY, 7),
+        ("partners", Exposure.INTERNAL_ONLY, 11),
+    ]
+    assert extract_gateway_signals("values.yaml", "replicas: 2\n") == []
+
+
+def test_resolve_exposure_precedence():
+    """Test path-scoped routes decide before service-wide listeners."""
+    signals = extract_manifest_signals(parse_documents("k8s/app.yaml", MANIFESTS))
+
+    assert resolve_exposure("/api/public/products", signals)[0] == Exposure.INTERNET_FACING
+    assert resolve_exposure("/admin/users", signals)[0] == Exposure.INTERNAL_ONLY
+    # Public routes exist but none reaches the path
+    exposure, signal = resolve_exposure("/internal/health", signals)
+    assert (exposure, signal.name) == (Exposure.INTERNAL_ONLY, "storefront")
+    load_balancer = ExposureSignal(
+        ExposureSource.LOAD_BALANCER, Exposure.INTERNET_FACING, "svc.yaml", 1, "edge", reason="LoadBalancer Service edge"
+    )
+    assert resolve_exposure("/internal/health", [*signals, load_balancer]) == (Exposure.INTERNET_FACING, load_balancer)
+    assert resolve_exposure("/anything", []) == (Exposure.UNKNOWN, None)
+
+
+def test_services_report_endpoint_exposure(tmp_path):
+    """Test endpoints of a cloned repository resolve against its config, skipping vendored files."""
+    (tmp_path / "3" / "deploy").mkdir(parents=True)
+    (tmp_path / "3" / "deploy" / "app.yaml").write_text(MANIFESTS)
+    (tmp_path / "3" / "node_modules").mkdir()
+    (tmp_path / "3" / "node_modules" / "main.tf").write_text(TERRAFORM)
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 3, "storefront"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    policies = []
+    for policy_id, snippet in ((1, "app.get('/api/public/products', list)"), (2, "app.get('/admin/users', list)")):
+        policy = Mock(spec=Policy)
+        policy.id, policy.subject, policy.resource, policy.action = policy_id, "ADMIN", "User", "view"
+        policy.conditions = None
+        evidence = Mock(spec=Evidence)
+        evidence.code_snippet, evidence.file_path, evidence.line_start = snippet, "app.js", 1
+        policy.evidence = [evidence]
+        policies.append(policy)
+
+    service = ExposureService(db, "acme", clone_dir=str(tmp_path))
+    with patch("app.services.exposure_service.DecisionSimulationService.load_policies", return_value=policies):
+        [report] = service.services(repository_id=3, internet_only=True)
+
+    assert report["cloned"] is True
+    assert len(report["signals"]) == 6
+    assert report["internet_facing_endpoints"] == 1
+    assert [(e["endpoint"], e["exposure"]) for e in report["endpoints"]] == [
+        ("GET /api/public/products", Exposure.INTERNET_FACING),
+        ("GET /admin/users", Exposure.INTERNAL_ONLY),
+    ]
+    assert report["endpoints"][0]["reason"] == "internet-facing per Ingress storefront for shop.example.com (deploy/app.yaml)"
@@ -0,0 +1,42 @@
+"""Tests for confidence scoring of mined policies by extraction method."""
+import pytest
+
+from app.models.policy import ExtractionMethod
+from app.services.config_policy_service import ConfigPolicyService
+from app.services.extraction_confidence import classify_evidence, classify_snippet, method_confidence
+from app.services.framework_route_service import FrameworkRouteService
+
+
+@pytest.mark.parametrize(
+    ("snippet", "method"),
+    [
+        ("@PreAuthorize(\"hasRole('ADMIN')\")\npublic void delete(Long id)", ExtractionMethod.EXPLICIT_MIDDLEWARE),
+        ("[Authorize(Roles = \"Manager\")]", ExtractionMethod.EXPLICIT_MIDDLEWARE),
+        ("router.delete('/expenses/:id', requireRole('ADMIN'), remove)", ExtractionMethod.EXPLICIT_MIDDLEWARE),
+        ("@login_required\ndef approve(request, pk):", ExtractionMethod.EXPLICIT_MIDDLEWARE),
+        ("r.HandleFunc(\"/admin\", RequireRole(\"ADMIN\", Admin)).Methods(\"GET\")", ExtractionMethod.EXPLICIT_MIDDLEWARE),
+        ("if (req.user.role !== 'MANAGER') { return res.status(403).end(); }", ExtractionMethod.INFERRED_FROM_BODY),
+        ("if expense.owner_id != current_user.id:\n    raise PermissionDenied()", ExtractionMethod.INFERRED_FROM_BODY),
+        ("IF IS_MEMBER('db_owner') = 0 RETURN", ExtractionMethod.INFERRED_FROM_BODY),
+        ("const total = items.reduce((a, b) => a + b.amount, 0);", ExtractionMethod.LLM_ASSISTED),
+        ("", ExtractionMethod.LLM_ASSISTED),
+    ],
+)
+def test_snippets_are_classified_by_where_the_check_lives(snippet, method):
+    """Test route guards, handler checks, and snippets with neither."""
+    assert classify_snippet(snippet) == method
+
+
+def test_confidence_is_capped_by_method():
+    """Test the most explicit evidence item wins and evidence quality moves a rule under its method's cap."""
+    assert classify_evidence(["total += 1", "if user.is_admin:"]) == ExtractionMethod.INFERRED_FROM_BODY
+    assert classify_evidence([]) == ExtractionMethod.LLM_ASSISTED
+
+    assert method_confidence(ExtractionMethod.EXPLICIT_MIDDLEWARE, 100) == 90.0
+    assert method_confidence(ExtractionMethod.EXPLICIT_MIDDLEWARE, 0) == 54.0
+    assert method_confidence(ExtractionMethod.LLM_ASSISTED, 100) == 50.0
+    assert method_confidence(ExtractionMethod.LLM_ASSISTED, 100) < method_confidence(ExtractionMethod.INFERRED_FROM_BODY, 40)
+    assert method_confidence(ExtractionMethod.DECLARED_CONFIG, 250) == 95.0
+
+    assert ConfigPolicyService.extraction_method == ExtractionMethod.DECLARED_CONFIG
+    assert FrameworkRouteService.extraction_method == ExtractionMethod.EXPLICIT_MIDDLEWARE
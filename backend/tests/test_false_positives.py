@@ -0,0 +1,125 @@
+"""Tests for the false-positive feedback loop and suppression model."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.conflict import PolicyConflict
+from app.models.false_positive import FalsePositiveMark, FalsePositiveTarget, SuppressionPattern
+from app.models.inconsistent_enforcement import InconsistentEnforcement
+from app.models.policy import Evidence, Policy, PolicyStatus, SourceType
+from app.models.policy_fix import FixStatus, PolicyFix
+from app.services.false_positive_service import (
+    SUPPRESSION_REVIEWER,
+    FalsePositiveService,
+    _fingerprint,
+    code_skeleton,
+    policy_analyzer,
+    policy_pattern,
+)
+
+
+def make_policy(policy_id, repository_id, snippet, description="Mined rule"):
+    """Create a pending policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id, policy.repository_id, policy.description = policy_id, repository_id, description
+    policy.subject, policy.resource, policy.action = "ADMIN", "Audit Log", "view"
+    policy.status, policy.source_type = PolicyStatus.PENDING, SourceType.BACKEND
+    evidence = Mock(spec=Evidence)
+    evidence.code_snippet = snippet
+    policy.evidence = [evidence]
+    return policy
+
+
+# The same logging call mis-read as an admin check, in two services
+BILLING_SNIPPET = 'logger.info("Billing admin dashboard loaded in %d ms", 42);  // timing'
+PAYROLL_SNIPPET = 'logger.info("Payroll admin page up in %d ms", 7);'
+
+
+def test_code_skeleton_abstracts_data_but_keeps_authorization_literals():
+    """Test skeletons match across repos while authorization literals stay distinct."""
+    assert code_skeleton("logger.info('Report ready', 3)  # done") == "logger.info(\"\", 0)"
+    assert code_skeleton("hasRole('ADMIN')") == "hasRole('admin')"
+    assert code_skeleton("hasRole('ADMIN')") != code_skeleton("hasRole('VIEWER')")
+    assert policy_pattern(make_policy(1, 1, BILLING_SNIPPET)) != policy_pattern(make_policy(2, 1, "x = 1"))
+
+    assert policy_analyzer(make_policy(1, 1, "", "Ingress auth (from Kubernetes manifests)")) == "k8s_manifests"
+    assert policy_analyzer(make_policy(1, 1, "", "Route auth (from framework route config)")) == "framework_routes"
+    assert policy_analyzer(make_policy(1, 1, "")) == "llm_scanner"
+
+
+def test_mark_policy_dismisses_it_and_learns_its_pattern(make_db):
+    """Test marking a rule rejects it and records a new suppression pattern."""
+    policy = make_policy(4, 2, BILLING_SNIPPET)
+    db = make_db({Policy: [policy]})
+
+    mark = FalsePositiveService(db, "acme").mark(FalsePositiveTarget.POLICY, 4, "logging, not a check", "dev@example.com")
+
+    assert (policy.status, policy.approval_comment) == (PolicyStatus.REJECTED, "False positive: logging, not a check")
+    assert policy.reviewed_by == "dev@example.com"
+    pattern = mark.pattern
+    assert isinstance(mark, FalsePositiveMark) and isinstance(pattern, SuppressionPattern)
+    assert (mark.analyzer, mark.repository_id, pattern.tenant_id) == ("llm_scanner", 2, "acme")
+    assert "admin" not in pattern.pattern
+    db.commit.assert_called_once()
+
+
+def test_suppress_dismisses_matching_detections_in_other_repositories(make_db):
+    """Test a learned pattern dismisses the same mis-detection elsewhere, leaving the rest."""
+    learned = make_policy(4, 2, BILLING_SNIPPET)
+    service = FalsePositiveService(MagicMock(), "acme")
+    analyzer, pattern_text, _ = service.describe(FalsePositiveTarget.POLICY, learned)
+    pattern = SuppressionPattern(
+        id=1,
+        analyzer=analyzer,
+        fingerprint=_fingerprint(analyzer, pattern_text),
+        pattern=pattern_text,
+        reason="logging",
+        active=True,
+        suppressed_count=0,
+    )
+    recurring = make_policy(9, 5, PAYROLL_SNIPPET)
+    real = make_policy(10, 5, "if (!user.hasRole('AUDITOR')) throw new Forbidden();")
+    fix = Mock(spec=PolicyFix, id=3, policy_id=10, security_gap_type="always_true")
+    fix.status = FixStatus.PENDING
+    db = make_db({SuppressionPattern: [pattern], Policy: [recurring, real], PolicyFix: [fix], PolicyConflict: []})
+
+    assert FalsePositiveService(db, "acme").suppress(5) == {"llm_scanner": 1}
+
+    assert (recurring.status, recurring.reviewed_by) == (PolicyStatus.REJECTED, SUPPRESSION_REVIEWER)
+    assert recurring.approval_comment == "Suppressed: matches false-positive pattern 1 (logging)"
+    assert real.status == PolicyStatus.PENDING
+    assert fix.status == FixStatus.PENDING
+    assert pattern.suppressed_count == 1
+
+
+def test_analytics_reports_false_positive_rate_per_analyzer(make_db):
+    """Test rates combine user marks and automatic suppressions."""
+    policies = [make_policy(i, 1, "") for i in range(1, 9)]
+    policies += [make_policy(20, 1, "", "Ingress (from Kubernetes manifests)")]
+    marks = [Mock(spec=FalsePositiveMark, analyzer="llm_scanner"), Mock(spec=FalsePositiveMark, analyzer="conflict")]
+    patterns = [
+        Mock(spec=SuppressionPattern, analyzer="llm_scanner", suppressed_count=3, active=True),
+        Mock(spec=SuppressionPattern, analyzer="conflict", suppressed_count=0, active=False),
+    ]
+    conflicts = [Mock(spec=PolicyConflict), Mock(spec=PolicyConflict)]
+    db = make_db(
+        {
+            Policy: policies,
+            PolicyConflict: conflicts,
+            InconsistentEnforcement: [],
+            FalsePositiveMark: marks,
+            SuppressionPattern: patterns,
+        }
+    )
+
+    results = {r["analyzer"]: r for r in FalsePositiveService(db).analytics()}
+
+    assert list(results) == ["conflict", "llm_scanner", "k8s_manifests"]
+    assert results["llm_scanner"] | {"analyzer": None} == {
+        "analyzer": None,
+        "detections": 8,
+        "marked_false_positive": 1,
+        "suppressed": 3,
+        "active_patterns": 1,
+        "false_positive_rate": 0.5,
+    }
+    assert (results["conflict"]["false_positive_rate"], results["conflict"]["active_patterns"]) == (0.5, 0)
+    assert results["k8s_manifests"]["false_positive_rate"] == 0.0
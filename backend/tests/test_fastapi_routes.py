@@ -0,0 +1,231 @@
+"""Tests for FastAPI dependency-based authorization mining."""
+from app.services.fastapi_route_extractor import FastApiRouteKind, extract_fastapi_routes
+
+SECURITY = """from typing import Annotated
+
+from fastapi import Depends, HTTPException, Security, status
+from fastapi.security import OAuth2PasswordBearer, SecurityScopes
+
+from app.models import Role
+
+oauth2_scheme = OAuth2PasswordBearer(tokenUrl="token", scopes={"items": "Read items", "me": "Read yourself"})
+
+
+async def get_current_user(security_scopes: SecurityScopes, token: Annotated[str, Depends(oauth2_scheme)]):
+    credentials_exception = HTTPException(status_code=status.HTTP_401_UNAUTHORIZED, detail="Could not validate")
+    try:
+        token_data = decode_token(token)
+    except JWTError:
+        raise credentials_exception
+    for scope in security_scopes.scopes:
+        if scope not in token_data.scopes:
+            raise HTTPException(status_code=401, detail="Not enough permissions")
+    return load_user(token_data.sub)
+
+
+async def get_current_active_user(current_user: Annotated[User, Security(get_current_user, scopes=["me"])]):
+    if current_user.disabled:
+        raise HTTPException(status_code=400, detail="Inactive user")
+    return current_user
+
+
+CurrentUser = Annotated[User, Depends(get_current_active_user)]
+
+
+def get_current_active_superuser(current_user: CurrentUser):
+    if not current_user.is_superuser:
+        raise HTTPException(status_code=403, detail="Not enough privileges")
+    return current_user
+
+
+class RoleChecker:
+    def __init__(self, allowed_roles):
+        self.allowed_roles = allowed_roles
+
+    def __call__(self, user: CurrentUser):
+        if user.role not in self.allowed_roles:
+            raise HTTPException(status_code=403, detail="Operation not permitted")
+
+
+def require_role(role):
+    def checker(user: CurrentUser):
+        if user.role != role:
+            raise HTTPException(status.HTTP_403_FORBIDDEN)
+        return user
+
+    return checker
+"""
+
+MODELS = """import enum
+
+
+class Role(str, enum.Enum):
+    ADMIN = "admin"
+    EDITOR = "editor"
+"""
+
+ITEMS = """from typing import Annotated
+
+from fastapi import APIRouter, Depends, Security
+
+from app.core.security import RoleChecker, get_current_active_user, require_role
+from app.models import Role
+
+router = APIRouter(prefix="/items", tags=["items"])
+
+
+@router.get("/")
+async def read_items(user: Annotated[User, Security(get_current_active_user, scopes=["items"])], db=Depends(get_db)):
+    return []
+
+
+@router.post("/", dependencies=[Depends(RoleChecker([Role.ADMIN, Role.EDITOR]))])
+async def create_item(item: Item):
+    return item
+
+
+@router.delete("/{item_id}")
+async def delete_item(item_id: int, user=Depends(require_role("admin"))):
+    return None
+
+
+@router.get("/public")
+def public_items():
+    return []
+"""
+
+ADMIN = """from fastapi import APIRouter, Depends
+
+from app.core.security import get_current_active_superuser
+
+router = APIRouter(dependencies=[Depends(get_current_active_superuser)])
+
+
+@router.api_route("/purge", methods=["POST", "DELETE"])
+def purge():
+    cache.clear()
+"""
+
+MAIN = """from fastapi import FastAPI
+
+from app.api import admin, items
+
+
+def create_app():
+    app = FastAPI()
+    app.include_router(items.router, prefix="/api/v1")
+    app.include_router(admin.router, prefix="/admin")
+    return app
+"""
+
+FILES = {
+    "app/core/security.py": SECURITY,
+    "app/models.py": MODELS,
+    "app/api/items.py": ITEMS,
+    "app/api/admin.py": ADMIN,
+    "app/main.py": MAIN,
+}
+
+
+def _by_route(findings):
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_dependency_chains_resolve_roles_through_checkers_and_factories():
+    """Test Depends() chains are followed into checker classes, factories, and Annotated aliases."""
+    findings = extract_fastapi_routes(FILES)
+    assert {f.kind for f in findings} == {FastApiRouteKind.ROUTE}
+    routes = _by_route(findings)
+
+    # The unguarded public route is not a finding
+    assert sorted(routes) == [
+        ("DELETE", "/admin/purge"),
+        ("DELETE", "/api/v1/items/{item_id}"),
+        ("GET", "/api/v1/items"),
+        ("POST", "/admin/purge"),
+        ("POST", "/api/v1/items"),
+    ]
+    assert routes[("POST", "/api/v1/items")].subject == "admin or editor"
+    assert routes[("DELETE", "/api/v1/items/{item_id}")].subject == "admin"
+    create = routes[("POST", "/api/v1/items")]
+    assert create.description == (
+        "FastAPI route create_item secured by Depends(RoleChecker([Role.ADMIN, Role.EDITOR])) "
+        "(via RoleChecker, get_current_active_user, get_current_user, oauth2_scheme)"
+    )
+    assert create.file_path == "app/api/items.py"
+    purge = routes[("POST", "/admin/purge")]
+    assert purge.subject == "superuser"
+    assert purge.description.startswith("FastAPI route purge secured by Depends(get_current_active_superuser) on admin.router")
+
+
+def test_security_scopes_accumulate_along_the_chain():
+    """Test Security() scopes from the route and its sub-dependencies combine into one requirement."""
+    routes = _by_route(extract_fastapi_routes(FILES))
+
+    read = routes[("GET", "/api/v1/items")]
+    assert read.subject == "Authenticated users"
+    assert read.conditions == "requires scopes items and me"
+    # Scopes declared further down the chain apply to every route using it
+    assert routes[("DELETE", "/api/v1/items/{item_id}")].conditions == "requires scope me"
+
+    unchecked = SECURITY.replace("    for scope in security_scopes.scopes:", "    for scope in []:")
+    routes = _by_route(extract_fastapi_routes({**FILES, "app/core/security.py": unchecked}))
+    assert routes[("GET", "/api/v1/items")].conditions == (
+        "requires scopes items and me, but no dependency checks SecurityScopes so the scopes are not enforced"
+    )
+
+
+def test_external_dependencies_and_app_level_dependencies():
+    """Test security schemes, fastapi-users dependencies, HTTPBearer subclasses, and app dependencies."""
+    main = """from fastapi import Depends, FastAPI, HTTPException
+from fastapi.security import HTTPBearer
+from fastapi_users import FastAPIUsers
+
+from auth_lib import verify_api_key
+
+fastapi_users = FastAPIUsers(get_user_manager, [backend])
+current_superuser = fastapi_users.current_user(active=True, superuser=True)
+optional_user = fastapi_users.current_user(optional=True)
+
+
+class JWTBearer(HTTPBearer):
+    async def __call__(self, request):
+        credentials = await super().__call__(request)
+        if not self.verify_jwt(credentials.credentials):
+            raise HTTPException(status_code=403, detail="Invalid token")
+        return credentials.credentials
+
+
+app = FastAPI()
+internal = FastAPI(dependencies=[Depends(verify_api_key)])
+
+
+@app.get("/stats", dependencies=[Depends(JWTBearer())])
+def stats():
+    return {}
+
+
+@app.get("/users")
+def users(user=Depends(current_superuser)):
+    return []
+
+
+@app.get("/maybe")
+def maybe(user=Depends(optional_user)):
+    return {}
+
+
+@internal.get("/health/deep")
+def deep_health():
+    return {}
+"""
+    routes = _by_route(extract_fastapi_routes({"service/main.py": main}))
+
+    assert sorted(routes) == [("GET", "/health/deep"), ("GET", "/stats"), ("GET", "/users")]
+    stats = routes[("GET", "/stats")]
+    assert (stats.subject, stats.conditions) == ("Authenticated users", "passes self.verify_jwt(credentials.credentials)")
+    assert routes[("GET", "/users")].subject == "superuser"
+    deep = routes[("GET", "/health/deep")]
+    assert (deep.subject, deep.conditions) == ("Authenticated users", "passes verify_api_key")
+    assert deep.description == "FastAPI route deep_health secured by Depends(verify_api_key) on main.internal"
+    assert not any(f.disables_auth for f in routes.values())
@@ -0,0 +1,120 @@
+"""Tests for Fastify route authorization mining."""
+from app.services.fastify_route_extractor import FastifyRouteKind, extract_fastify_routes
+
+APP = """const Fastify = require('fastify');
+const adminRoutes = require('./routes/admin');
+
+const app = Fastify({ logger: true });
+app.register(require('./plugins/auth'));
+app.register(require('@fastify/auth'));
+
+app.get('/health', async () => ({ ok: true }));
+app.register(adminRoutes, { prefix: '/admin' });
+
+app.register(async (api) => {
+  api.addHook('onRequest', api.authenticate);
+  api.get('/me', me);
+  api.post('/orders', { preHandler: api.auth([api.isEditor, api.isAdmin]) }, createOrder);
+  api.delete('/orders/:id', { preHandler: api.auth([api.authenticate, api.isAdmin], { relation: 'and' }) }, remove);
+  api.get('/reports', { preHandler: api.auth([api.isAdmin, api.verifyApiKey]) }, reports);
+}, { prefix: '/api' });
+"""
+
+# fastify-plugin lifts the decorators and hooks of this plugin into the app
+AUTH_PLUGIN = """const fp = require('fastify-plugin');
+
+module.exports = fp(async function (fastify, opts) {
+  fastify.decorate('authenticate', async function (request, reply) {
+    try {
+      await request.jwtVerify();
+    } catch (err) {
+      reply.send(err);
+    }
+  });
+  fastify.decorate('isAdmin', async (request, reply) => {
+    if (request.user.role !== 'admin') reply.code(403).send();
+  });
+  fastify.decorate('isEditor', requireRole('editor'));
+  fastify.decorate('verifyApiKey', (request, reply, done) => done());
+});
+"""
+
+ADMIN_ROUTES = """import { FastifyInstance } from 'fastify';
+import { Role } from '../roles';
+
+export default async function adminRoutes(fastify: FastifyInstance) {
+  fastify.addHook('preHandler', requireRole(Role.Owner));
+  fastify.get<{ Params: { id: string } }>('/tenants/:id', tenant);
+  fastify.route({
+    method: ['PUT', 'PATCH'],
+    url: '/tenants/:id',
+    preHandler: [fastify.isAdmin],
+    handler: updateTenant,
+  });
+}
+"""
+
+SCHEMA_ROUTES = """import Fastify from 'fastify';
+
+const server = Fastify();
+const listOrders = { querystring: {}, security: [{ bearerAuth: ['orders:read'] }] };
+
+server.get('/orders', { schema: listOrders }, list);
+server.get('/docs', { schema: { security: [] } }, docs);
+server.get('/status', { schema: { security: [{ apiKey: [] }, { bearerAuth: ['status:read'] }] } }, status);
+"""
+
+FILES = {
+    "src/app.js": APP,
+    "src/plugins/auth.js": AUTH_PLUGIN,
+    "src/routes/admin.ts": ADMIN_ROUTES,
+    "src/roles.ts": "export enum Role { Owner = 'owner', Admin = 'admin' }\n",
+}
+
+
+def by_route(findings):
+    """Index findings by (method, path)."""
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_hooks_apply_to_the_plugin_tree_they_are_added_in():
+    """Test addHook guards its plugin's routes and registered plugins, across files and with prefixes."""
+    findings = extract_fastify_routes(FILES)
+    routes = by_route(findings)
+
+    # /health is registered on the app, outside the plugin whose hook authenticates
+    assert ("GET", "/health") not in routes
+    assert all(f.kind == FastifyRouteKind.ROUTE for f in findings)
+    assert routes[("GET", "/api/me")].subject == "Authenticated users"
+    assert routes[("GET", "/api/me")].description == "Fastify route guarded by api.authenticate"
+    # The admin plugin's typed hook resolves through the enum, and the route adds its own preHandler
+    assert routes[("GET", "/admin/tenants/:id")].subject == "owner"
+    assert routes[("PATCH", "/admin/tenants/:id")].subject == "owner or admin"
+    assert routes[("PATCH", "/admin/tenants/:id")].file_path == "src/routes/admin.ts"
+
+
+def test_auth_compositions_follow_their_relation():
+    """Test @fastify/auth members are alternatives by default and all required with relation "and"."""
+    routes = by_route(extract_fastify_routes(FILES))
+
+    orders = routes[("POST", "/api/orders")]
+    assert orders.subject == "editor or admin"
+    assert orders.conditions == "passes any of api.isEditor (role editor), api.isAdmin (role admin)"
+    assert routes[("DELETE", "/api/orders/:id")].subject == "admin"
+    assert routes[("DELETE", "/api/orders/:id")].conditions is None
+    # An alternative without a role check leaves the route open to any authenticated caller
+    reports = routes[("GET", "/api/reports")]
+    assert reports.subject == "Authenticated users"
+    assert reports.conditions == "passes any of api.isAdmin (role admin), api.verifyApiKey"
+
+
+def test_schema_security_requirements_are_mapped_as_declared():
+    """Test OpenAPI security in inline and referenced schemas; an empty list leaves a route public."""
+    routes = by_route(extract_fastify_routes({"server.ts": SCHEMA_ROUTES}))
+
+    assert set(routes) == {("GET", "/orders"), ("GET", "/status")}
+    assert routes[("GET", "/orders")].conditions == "requires permission orders:read; declared in the route schema"
+    assert routes[("GET", "/status")].conditions == (
+        "accepts any of apiKey, bearerAuth (status:read); declared in the route schema"
+    )
+    assert routes[("GET", "/status")].description == "Fastify route guarded by schema security apiKey or bearerAuth"
@@ -0,0 +1,179 @@
+"""Tests for CWE and OWASP API Top 10 tagging of findings."""
+import csv
+import io
+from unittest.mock import Mock, patch
+
+import pytest
+
+from app.models.conflict import ConflictStatus, ConflictType, PolicyConflict
+from app.models.inconsistent_enforcement import InconsistentEnforcement
+from app.models.itsm_connector import FindingTicket, FindingTicketStatus
+from app.models.policy import Evidence, Policy
+from app.models.policy_fix import FixSeverity, PolicyFix
+from app.models.repository import Repository
+from app.models.secret_detection import SecretDetectionLog
+from app.services.finding_export_service import FindingExportService, to_csv, to_sarif
+from app.services.finding_taxonomy import (
+    TAXONOMY,
+    FindingType,
+    OwaspApiCategory,
+    classify,
+    normalize_cwe,
+    normalize_owasp,
+)
+from app.services.vulnerable_auth_pattern_service import WEAKNESSES, PatternKind
+
+
+def test_every_finding_type_is_tagged():
+    """Test each type has general tags, kinds refine them, and filters accept common spellings."""
+    assert {e.finding_type for e in TAXONOMY if e.kind is None} == set(FindingType)
+    assert all(e.cwe and e.owasp_api for e in TAXONOMY)
+    for kind, weakness in WEAKNESSES.items():
+        assert classify(FindingType.VULNERABLE_AUTH_PATTERN, kind.value).cwe == (weakness.cwe,)
+
+    escalation = classify(FindingType.SECURITY_GAP, "privilege_escalation")
+    assert (escalation.rule_id, escalation.cwe) == ("security_gap/privilege_escalation", ("CWE-269",))
+    # Gap types the analyzer invents fall back to the general security gap tags
+    assert classify(FindingType.SECURITY_GAP, "missing_tenant_check").rule_id == "security_gap"
+    assert OwaspApiCategory.API1 in classify(FindingType.CONFUSED_DEPUTY).owasp_api
+
+    assert normalize_cwe("cwe347") == normalize_cwe(" 347 ") == "CWE-347"
+    assert normalize_owasp("bola") == normalize_owasp("API1:2023") == OwaspApiCategory.API1
+    assert normalize_owasp("bfla") == OwaspApiCategory.API5
+    with pytest.raises(ValueError):
+        normalize_owasp("API11")
+    with pytest.raises(ValueError):
+        normalize_cwe("CVE-2024-1")
+
+
+def _finding(finding_type, kind, severity, file_path=None, line=None):
+    """A tagged finding as the service returns it."""
+    entry = classify(finding_type, kind)
+    return {
+        "finding_type": finding_type,
+        "kind": kind,
+        "rule_id": entry.rule_id,
+        "title": entry.title,
+        "severity": severity,
+        "description": f"{entry.title} found",
+        "cwe": list(entry.cwe),
+        "owasp_api": [o.value for o in entry.owasp_api],
+        "repository_id": 1,
+        "repository_name": "billing",
+        "file_path": file_path,
+        "line_start": line,
+        "line_end": line,
+        "policy_ids": [],
+    }
+
+
+def test_sarif_and_csv_exports_carry_tags():
+    """Test one SARIF rule per taxonomy entry with CWE and OWASP tags, result levels, and locations."""
+    findings = [
+        _finding(FindingType.VULNERABLE_AUTH_PATTERN, "jwt_none_algorithm", "critical", "auth.py", 4),
+        _finding(FindingType.SECRET_IN_CODE, "aws_access_key", "high", "config.py", 9),
+        _finding(FindingType.SECRET_IN_CODE, "github_token", "high", "deploy.sh", 2),
+        _finding(FindingType.CONFLICT, "contradictory", "low"),
+    ]
+
+    sarif = to_sarif(findings)
+    [run] = sarif["runs"]
+    assert sarif["version"] == "2.1.0"
+    rules = {r["id"]: r for r in run["tool"]["driver"]["rules"]}
+    assert sorted(rules) == ["conflict", "secret_in_code", "vulnerable_auth_pattern/jwt_none_algorithm"]
+    assert rules["vulnerable_auth_pattern/jwt_none_algorithm"]["properties"] == {
+        "tags": ["security", "CWE-347", "OWASP-API2:2023"],
+        "security-severity": "9.5",
+    }
+    assert rules["secret_in_code"]["helpUri"] == "https://cwe.mitre.org/data/definitions/798.html"
+    first, _, third, conflict = run["results"]
+    assert (first["level"], first["properties"]["cwe"]) == ("error", ["CWE-347"])
+    assert first["locations"][0]["physicalLocation"] == {
+        "artifactLocation": {"uri": "auth.py"},
+        "region": {"startLine": 4, "endLine": 4},
+    }
+    assert third["ruleIndex"] == run["results"][1]["ruleIndex"]
+    assert (conflict["level"], "locations" in conflict) == ("note", False)
+
+    rows = list(csv.DictReader(io.StringIO(to_csv(findings))))
+    assert (rows[1]["cwe"], rows[1]["owasp_api"]) == ("CWE-798", "API2:2023 API8:2023")
+    assert rows[3]["file_path"] == ""
+
+
+def test_service_filters_findings_by_tags(make_db):
+    """Test stored findings are located at their policy evidence and filters skip analyzers they exclude."""
+    repository = Mock(spec=Repository, id=3)
+    repository.name = "orders"
+    evidence = Mock(spec=Evidence, file_path="src/orders.py", line_start=12, line_end=14)
+    policy = Mock(spec=Policy, id=5, repository_id=3, evidence=[evidence])
+    fix = Mock(spec=PolicyFix, id=1, policy_id=5, security_gap_type="privilege_escalation", severity=FixSeverity.HIGH)
+    fix.gap_description = "Any editor can grant themselves admin"
+    conflict = Mock(spec=PolicyConflict, id=2, policy_a_id=5, policy_b_id=6, conflict_type=ConflictType.CONTRADICTORY)
+    conflict.severity, conflict.description, conflict.status = "medium", "Allow and deny for DELETE /orders", ConflictStatus.PENDING
+    secret = Mock(spec=SecretDetectionLog, id=4, repository_id=3, file_path="settings.py", secret_type="api_key", line_number=7)
+    secret.description = "Hard-coded API key"
+    db = make_db(
+        {
+            Repository: [repository],
+            Policy: [policy],
+            PolicyFix: [fix],
+            PolicyConflict: [conflict],
+            InconsistentEnforcement: [],
+            SecretDetectionLog: [secret],
+        }
+    )
+    service = FindingExportService(db, "acme")
+
+    # Only stored findings and endpoint inconsistencies are tagged with these CWEs, so no clone analyzer runs
+    with (
+        patch("app.services.finding_export_service.CorsCsrfService") as cors,
+        patch(
+            "app.services.finding_export_service.EndpointConsistencyService.inconsistencies",
+            return_value={"inconsistencies": []},
+        ) as consistency,
+    ):
+        [conflict_finding] = service.findings(cwe="CWE-863")
+        [escalation] = service.findings(cwe="CWE-269", repository_id=3)
+    cors.assert_not_called()
+    consistency.assert_called_once_with()
+    assert (conflict_finding["rule_id"], conflict_finding["policy_ids"]) == ("conflict", [5, 6])
+    assert (escalation["rule_id"], escalation["severity"]) == ("security_gap/privilege_escalation", "high")
+    assert (escalation["file_path"], escalation["line_start"], escalation["repository_name"]) == ("src/orders.py", 12, "orders")
+
+    pattern = {"kind": PatternKind.JWT_NONE_ALGORITHM, "severity": "critical", "description": "alg none",
+               "repository_id": 3, "file_path": "auth.py", "line_start": 4, "line_end": 4}
+    with patch(
+        "app.services.finding_export_service.VulnerableAuthPatternService.findings", return_value={"findings": [pattern]}
+    ):
+        authentication = service.findings(owasp=OwaspApiCategory.API2)
+    assert [(f["finding_type"], f["cwe"]) for f in authentication] == [
+        (FindingType.VULNERABLE_AUTH_PATTERN, ["CWE-347"]),
+        (FindingType.SECRET_IN_CODE, ["CWE-798"]),
+    ]
+    assert service.summary(authentication)["by_owasp_api"] == {"API2:2023": 2, "API8:2023": 1}
+    with pytest.raises(ValueError):
+        service.findings(repository_id=99)
+
+
+def test_findings_accepted_through_itsm_are_left_out(make_db):
+    """Test finding keys are stable across collections and risk-accepted findings are dropped unless asked for."""
+    repository = Mock(spec=Repository, id=3)
+    repository.name = "orders"
+    secrets = [
+        Mock(spec=SecretDetectionLog, id=i, repository_id=3, file_path=f"settings{i}.py", secret_type="api_key", line_number=7)
+        for i in (1, 2)
+    ]
+    for secret in secrets:
+        secret.description = "Hard-coded API key"
+    rows = {Repository: [repository], SecretDetectionLog: secrets}
+    service = FindingExportService(make_db(rows), "acme")
+
+    first, second = service.findings(finding_type=FindingType.SECRET_IN_CODE)
+    assert first["finding_key"].startswith("fnd_") and first["finding_key"] != second["finding_key"]
+    assert [f["finding_key"] for f in service.findings(finding_type=FindingType.SECRET_IN_CODE)] == [
+        first["finding_key"], second["finding_key"],
+    ]
+
+    rows[FindingTicket] = [Mock(spec=FindingTicket, finding_key=first["finding_key"], status=FindingTicketStatus.RISK_ACCEPTED)]
+    assert [f["file_path"] for f in service.findings(finding_type=FindingType.SECRET_IN_CODE)] == ["settings2.py"]
+    assert len(service.findings(finding_type=FindingType.SECRET_IN_CODE, include_accepted=True)) == 2
@@ -0,0 +1,420 @@
+"""Tests for Gin, Echo, and chi route authorization mining."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.repository import Repository
+from app.services.framework_route_service import FrameworkRouteService
+from app.services.go_route_extractor import GoRouteKind, extract_go_routes
+
+GIN_MAIN = """package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/app/middleware"
+	"example.com/app/users"
+)
+
+func main() {
+	r := gin.Default()
+	r.GET("/health", health)
+
+	api := r.Group("/api", middleware.AuthRequired())
+	api.GET("/me", me)
+
+	admin := api.Group("/admin")
+	admin.Use(middleware.RequireRole("admin"))
+	{
+		admin.DELETE("/tenants/:id", removeTenant)
+	}
+	users.Register(api.Group("/users"))
+
+	// Applies to routes registered from here on, not to the groups above
+	r.Use(middleware.AuthRequired())
+	r.POST("/reports/:id/approve", approveReport)
+	r.Any("/legacy/*path", gin.BasicAuth(gin.Accounts{"ops": "secret"}), legacy)
+}
+
+func approveReport(c *gin.Context) {
+	if !canApprove(c) {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+"""
+
+GIN_MIDDLEWARE = """package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthRequired rejects requests without a bearer token
+func AuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole rejects callers without the given role
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+		c.Next()
+	}
+}
+"""
+
+GIN_USERS = """package users
+
+import "github.com/gin-gonic/gin"
+
+func Register(rg *gin.RouterGroup) {
+	rg.GET("", list)
+	rg.PUT("/:id/roles", func(c *gin.Context) {
+		if user := currentUser(c); user.Role != "owner" {
+			c.AbortWithStatus(403)
+			return
+		}
+		assignRoles(c)
+	})
+}
+"""
+
+FILES = {"main.go": GIN_MAIN, "middleware/auth.go": GIN_MIDDLEWARE, "users/routes.go": GIN_USERS}
+
+
+def test_gin_routes_resolve_groups_middleware_factories_and_registration_functions():
+    """Test Gin guards resolve through nested groups, .Use(), and groups handed to other packages."""
+    findings = extract_go_routes(FILES)
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    # /health is registered before r.Use(), so no guard applies to it
+    assert set(by_route) == {
+        ("GET", "/api/me"),
+        ("DELETE", "/api/admin/tenants/:id"),
+        ("GET", "/api/users"),
+        ("PUT", "/api/users/:id/roles"),
+        ("POST", "/reports/:id/approve"),
+        ("*", "/legacy/*path"),
+    }
+    assert all(f.kind == GoRouteKind.GIN_ROUTE for f in findings)
+    assert by_route[("GET", "/api/me")].subject == "Authenticated users"
+
+    tenants = by_route[("DELETE", "/api/admin/tenants/:id")]
+    assert tenants.subject == "admin"
+    assert tenants.description == "Gin route guarded by middleware.AuthRequired(), middleware.RequireRole(\"admin\")"
+    assert tenants.line_start == 22 and "removeTenant" in tenants.snippet
+
+    users = by_route[("GET", "/api/users")]
+    assert (users.subject, users.file_path) == ("Authenticated users", "users/routes.go")
+    assert by_route[("PUT", "/api/users/:id/roles")].subject == "owner"
+
+
+def test_gin_handler_checks_and_late_middleware():
+    """Test handlers that abort with 403 contribute conditions, and .Use() only covers later routes."""
+    findings = extract_go_routes(FILES)
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    approve = by_route[("POST", "/reports/:id/approve")]
+    assert approve.subject == "Authenticated users"
+    assert approve.conditions == "canApprove(c)"
+    assert approve.description == "Gin route guarded by middleware.AuthRequired(), handler"
+
+    legacy = by_route[("*", "/legacy/*path")]
+    assert "gin.BasicAuth(gin.Accounts{\"ops\": \"secret\"})" in legacy.description
+
+    # Without the middleware package, guards are recognized by name only
+    guarded = {(f.action, f.resource) for f in extract_go_routes({"main.go": GIN_MAIN, "users/routes.go": GIN_USERS})}
+    assert ("DELETE", "/api/admin/tenants/:id") in guarded
+    assert extract_go_routes({"main_test.go": GIN_MAIN}) == []
+
+
+def test_gin_functions_with_type_literal_results():
+    """Test bodies are found past results such as chan struct{} and (interface{}, error)."""
+    main = """package main
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"example.com/app/middleware"
+	"example.com/app/users"
+)
+
+func routes() (r *gin.Engine, stop chan struct{}) {
+	r = gin.New()
+	r.Use(middleware.AuthRequired())
+	r.GET("/reports", listReports)
+	users.Register(r.Group("/users"))
+	stop = make(chan struct{})
+	return r, stop
+}
+"""
+    registration = GIN_USERS.replace("func Register(rg *gin.RouterGroup) {", "func Register(rg *gin.RouterGroup) (interface{}, error) {")
+    findings = extract_go_routes({"main.go": main, "middleware/auth.go": GIN_MIDDLEWARE, "users/routes.go": registration})
+    by_route = {(f.action, f.resource): f.subject for f in findings}
+
+    assert by_route == {
+        ("GET", "/reports"): "Authenticated users",
+        ("GET", "/users"): "Authenticated users",
+        ("PUT", "/users/:id/roles"): "owner",
+    }
+
+
+def test_scan_repository_reads_gin_sources(tmp_path):
+    """Test the framework route scan mines Gin routes from a clone."""
+    for name, text in FILES.items():
+        (tmp_path / "4" / name).parent.mkdir(parents=True, exist_ok=True)
+        (tmp_path / "4" / name).write_text(text)
+    (tmp_path / "4" / "vendor" / "gin").mkdir(parents=True)
+    (tmp_path / "4" / "vendor" / "gin" / "main.go").write_text(GIN_MAIN)
+
+    repo = Mock(spec=Repository, id=4, tenant_id="acme")
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    previous = db.query.return_value.join.return_value.filter.return_value.filter.return_value
+    previous.filter.return_value.all.return_value = []
+    db.query.return_value.filter.return_value.all.return_value = []
+
+    result = FrameworkRouteService(db, "acme", str(tmp_path)).scan_repository(4)
+
+    assert result["findings_by_kind"] == {GoRouteKind.GIN_ROUTE: 6}
+    assert result["files"] == 2
+    assert result["policies_created"] == 6
+
+
+ECHO_MAIN = """package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+
+	"example.com/app/auth"
+	"example.com/app/billing"
+)
+
+func main() {
+	e := echo.New()
+	e.GET("/health", health)
+
+	api := e.Group("/api", auth.Authenticate)
+	api.GET("/me", me)
+	api.DELETE("/tenants/:id", removeTenant, auth.RequireRole("admin"))
+	api.Match([]string{"PUT", "PATCH"}, "/invoices/:id", updateInvoice, auth.RequireRole("accountant"))
+	billing.Routes(api.Group("/billing"))
+
+	// Wraps the whole router, including the routes above
+	e.Pre(echomw.RemoveTrailingSlash())
+	e.Use(echomw.KeyAuth(validateKey))
+	e.POST("/reports/:id/approve", approveReport)
+}
+
+func approveReport(c echo.Context) error {
+	if !canApprove(c) {
+		return echo.NewHTTPError(http.StatusForbidden, "cannot approve")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+"""
+
+ECHO_AUTH = """package auth
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Authenticate rejects requests without a session
+func Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Get("user") == nil {
+			return echo.ErrUnauthorized
+		}
+		return next(c)
+	}
+}
+
+// RequireRole rejects callers without the given role
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Get("role") != role {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+			}
+			return next(c)
+		}
+	}
+}
+"""
+
+ECHO_BILLING = """package billing
+
+import "github.com/labstack/echo/v4"
+
+func Routes(g *echo.Group) {
+	g.Use(auditLog)
+	g.Add("POST", "/refunds", func(c echo.Context) error {
+		if user := currentUser(c); user.Role != "finance" {
+			return echo.ErrForbidden
+		}
+		return refund(c)
+	})
+}
+"""
+
+
+def test_echo_routes_resolve_groups_wrappers_and_instance_middleware():
+    """Test Echo route middleware after the handler, MiddlewareFunc wrappers, and e.Use() covering earlier routes."""
+    findings = extract_go_routes({"main.go": ECHO_MAIN, "auth/auth.go": ECHO_AUTH, "billing/routes.go": ECHO_BILLING})
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    assert set(by_route) == {
+        ("GET", "/health"),
+        ("GET", "/api/me"),
+        ("DELETE", "/api/tenants/:id"),
+        ("PUT", "/api/invoices/:id"),
+        ("PATCH", "/api/invoices/:id"),
+        ("POST", "/api/billing/refunds"),
+        ("POST", "/reports/:id/approve"),
+    }
+    assert all(f.kind == GoRouteKind.ECHO_ROUTE for f in findings)
+    assert by_route[("GET", "/health")].description == "Echo route guarded by echomw.KeyAuth(validateKey)"
+    assert by_route[("GET", "/api/me")].subject == "Authenticated users"
+
+    tenants = by_route[("DELETE", "/api/tenants/:id")]
+    assert tenants.subject == "admin"
+    assert tenants.description == (
+        "Echo route guarded by echomw.KeyAuth(validateKey), auth.Authenticate, auth.RequireRole(\"admin\")"
+    )
+    assert by_route[("PATCH", "/api/invoices/:id")].subject == "accountant"
+
+    refunds = by_route[("POST", "/api/billing/refunds")]
+    assert (refunds.subject, refunds.file_path) == ("finance", "billing/routes.go")
+
+    approve = by_route[("POST", "/reports/:id/approve")]
+    assert approve.conditions == "canApprove(c)"
+    assert approve.description == "Echo route guarded by echomw.KeyAuth(validateKey), handler"
+
+
+CHI_MAIN = """package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+
+	"example.com/app/admin"
+	"example.com/app/authz"
+)
+
+func main() {
+	r := chi.NewRouter()
+	r.Get("/health", health)
+
+	r.Route("/api", func(r chi.Router) {
+		r.Use(jwtauth.Verifier(tokenAuth), jwtauth.Authenticator(tokenAuth))
+		r.Get("/me", me)
+
+		r.Route("/invoices", func(r chi.Router) {
+			r.Get("/", listInvoices)
+			r.With(authz.RequireRole("ADMIN")).Delete("/{id}", deleteInvoice)
+			r.Group(func(r chi.Router) {
+				r.Use(authz.RequireRole("ACCOUNTANT"))
+				r.Post("/{id}/pay", payInvoice)
+			})
+		})
+		r.Mount("/admin", admin.Routes())
+	})
+
+	r.Method("POST", "/webhooks", http.HandlerFunc(webhook))
+	http.ListenAndServe(":8080", r)
+}
+"""
+
+CHI_AUTHZ = """package authz
+
+import "net/http"
+
+// RequireRole rejects callers without the given role
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasRole(r.Context(), role) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+"""
+
+CHI_ADMIN = """package admin
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func Routes() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/audit", audit)
+	r.Use(adminOnly)
+	return r
+}
+
+func adminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims(r)["role"] != "superuser" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+"""
+
+
+def test_chi_routes_inherit_middleware_through_nested_routers():
+    """Test r.Route(), r.Group(), r.With(), and r.Mount() pass middleware down to every leaf route."""
+    findings = extract_go_routes({"main.go": CHI_MAIN, "authz/roles.go": CHI_AUTHZ, "admin/routes.go": CHI_ADMIN})
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    assert set(by_route) == {
+        ("GET", "/api/me"),
+        ("GET", "/api/invoices"),
+        ("DELETE", "/api/invoices/{id}"),
+        ("POST", "/api/invoices/{id}/pay"),
+        ("GET", "/api/admin/audit"),
+    }
+    assert all(f.kind == GoRouteKind.CHI_ROUTE for f in findings)
+    assert by_route[("GET", "/api/invoices")].subject == "Authenticated users"
+
+    delete = by_route[("DELETE", "/api/invoices/{id}")]
+    assert delete.subject == "ADMIN"
+    assert delete.description == (
+        "chi route guarded by jwtauth.Verifier(tokenAuth), jwtauth.Authenticator(tokenAuth), "
+        "authz.RequireRole(\"ADMIN\")"
+    )
+    assert delete.line_start == 23 and "deleteInvoice" in delete.snippet
+    assert by_route[("POST", "/api/invoices/{id}/pay")].subject == "ACCOUNTANT"
+
+    # chi middleware precedes every route of its router, wherever Use() is written
+    audit = by_route[("GET", "/api/admin/audit")]
+    assert (audit.subject, audit.file_path) == ("superuser", "admin/routes.go")
@@ -0,0 +1,241 @@
+"""Tests for GraphQL directive and resolver authorization mining."""
+from app.services.graphql_route_extractor import GraphQLRouteKind, extract_graphql_routes
+
+SCHEMA = '''directive @hasRole(role: Role! = ADMIN) on FIELD_DEFINITION | OBJECT
+directive @isAuthenticated on FIELD_DEFINITION
+directive @hasScope(scopes: [String!]!) on FIELD_DEFINITION
+
+enum Role {
+  ADMIN
+  SUPPORT
+}
+
+"""
+Orders placed by customers.
+"""
+type Order {
+  id: ID!
+  total: Float! @hasRole(role: SUPPORT)
+  notes: String
+}
+
+type Query {
+  orders(first: Int = 10): [Order!]! @isAuthenticated
+  order(id: ID!): Order @hasRole
+  health: String
+  reports: [String!]! @hasScope(scopes: ["reports:read"])
+}
+
+type Mutation {
+  refundOrder(id: ID!): Order
+  placeOrder(input: PlaceOrderInput!): Order @isAuthenticated @deprecated(reason: "use checkout")
+}
+
+input PlaceOrderInput {
+  sku: String!
+}
+'''
+
+SERVER = """package main
+
+import (
+	"github.com/99designs/gqlgen/graphql/handler"
+	"example.com/shop/graph"
+	"example.com/shop/graph/generated"
+)
+
+func main() {
+	c := generated.Config{Resolvers: &graph.Resolver{}}
+	c.Directives.HasRole = directives.HasRole
+	c.Directives.IsAuthenticated = directives.IsAuthenticated
+	srv := handler.NewDefaultServer(generated.NewExecutableSchema(c))
+	_ = srv
+}
+"""
+
+RESOLVERS = """package graph
+
+func (r *mutationResolver) RefundOrder(ctx context.Context, id string) (*model.Order, error) {
+	user := auth.ForContext(ctx)
+	if user == nil || !user.HasRole("finance") {
+		return nil, ErrForbidden
+	}
+	return r.Orders.Refund(ctx, id)
+}
+
+func (r *queryResolver) Health(ctx context.Context) (*string, error) {
+	ok := "ok"
+	return &ok, nil
+}
+"""
+
+
+def _coordinates(findings):
+    """Findings keyed by field coordinate."""
+    return {f.resource: f for f in findings}
+
+
+def test_schema_directives_resolve_with_defaults_and_implementations():
+    """Test SDL directives with argument defaults, gqlgen implementations, and resolver checks per field."""
+    findings = extract_graphql_routes(
+        {"graph/schema.graphqls": SCHEMA, "server.go": SERVER, "graph/schema.resolvers.go": RESOLVERS}
+    )
+
+    coordinates = _coordinates(findings)
+    assert sorted(coordinates) == [
+        "Mutation.placeOrder",
+        "Mutation.refundOrder",
+        "Order.total",
+        "Query.order",
+        "Query.orders",
+        "Query.reports",
+    ]
+    order = coordinates["Query.order"]
+    assert (order.kind, order.action, order.subject, order.conditions) == (GraphQLRouteKind.OPERATION, "QUERY", "ADMIN", None)
+    assert (order.file_path, order.line_start) == ("graph/schema.graphqls", 21)
+    assert order.description == "GraphQL Query.order guarded by @hasRole"
+    assert coordinates["Query.orders"].subject == "Authenticated users"
+    total = coordinates["Order.total"]
+    assert (total.kind, total.action, total.subject) == (GraphQLRouteKind.FIELD, "RESOLVE", "SUPPORT")
+    # Nothing registers an implementation of @hasScope, so the server may ignore it
+    assert coordinates["Query.reports"].conditions == (
+        "requires permission reports:read; @hasScope has no implementation in the repository, so it may not be enforced"
+    )
+    refund = coordinates["Mutation.refundOrder"]
+    assert (refund.action, refund.subject) == ("MUTATION", "FINANCE")
+    assert refund.description == "GraphQL Mutation.refundOrder guarded by resolver mutationResolver.RefundOrder"
+    assert coordinates["Mutation.placeOrder"].description == "GraphQL Mutation.placeOrder guarded by @isAuthenticated"
+
+
+APOLLO = """import { ApolloServer } from '@apollo/server';
+import { gql } from 'graphql-tag';
+import { mapSchema, getDirective, MapperKind } from '@graphql-tools/utils';
+
+const typeDefs = gql`
+  directive @auth(requires: [String!]) on OBJECT | FIELD_DEFINITION
+  directive @public on FIELD_DEFINITION
+
+  type Invoice @auth(requires: ["accountant"]) {
+    id: ID!
+    amount: Float!
+    pdfUrl: String @public
+  }
+
+  type Query {
+    invoices: [Invoice!]!
+    me: String
+    catalog: [String!]!
+  }
+`;
+
+function authDirectiveTransformer(schema) {
+  return mapSchema(schema, {
+    [MapperKind.OBJECT_FIELD]: (fieldConfig) => {
+      const auth = getDirective(schema, fieldConfig, 'auth')?.[0];
+      return fieldConfig;
+    },
+  });
+}
+
+const resolvers = {
+  Query: {
+    invoices: (parent, args, context) => {
+      if (!context.user) throw new AuthenticationError('sign in');
+      return context.db.invoices();
+    },
+    me(parent, args, ctx) {
+      return ctx.user.name;
+    },
+    catalog: () => [],
+  },
+};
+"""
+
+PERMISSIONS = """import { rule, shield, and, or, allow } from 'graphql-shield';
+
+const isAuthenticated = rule()(async (parent, args, ctx) => ctx.user !== null);
+const isAdmin = rule()(async (parent, args, ctx) => ctx.user.role === 'admin');
+const isOwner = rule()(async (parent, { id }, ctx) => ctx.user.id === id);
+
+export const permissions = shield({
+  Query: {
+    catalog: allow,
+    auditLog: and(isAuthenticated, isAdmin),
+  },
+  Mutation: {
+    deleteInvoice: or(isAdmin, isOwner),
+  },
+});
+"""
+
+
+def test_resolver_maps_and_shield_rules_guard_operations():
+    """Test gql templates, type-level directives, resolver-map checks, and graphql-shield rule trees."""
+    findings = extract_graphql_routes({"src/schema.ts": APOLLO, "src/permissions.ts": PERMISSIONS})
+
+    coordinates = _coordinates(findings)
+    assert coordinates["Invoice.amount"].subject == "accountant"
+    assert coordinates["Invoice.amount"].conditions is None
+    pdf = coordinates["Invoice.pdfUrl"]
+    assert (pdf.subject, pdf.disables_auth) == ("Anonymous", True)
+    assert coordinates["Query.invoices"].description == "GraphQL Query.invoices guarded by resolver Query.invoices"
+    assert coordinates["Query.me"].subject == "Authenticated users"
+    catalog = coordinates["Query.catalog"]
+    assert (catalog.subject, catalog.description) == ("Anonymous", "GraphQL Query.catalog open to anyone: graphql-shield allow")
+    # Shield rules for fields the schema in this repository does not declare are kept
+    audit = coordinates["Query.auditLog"]
+    assert (audit.subject, audit.file_path, audit.line_start) == ("ADMIN", "src/permissions.ts", 10)
+    delete = coordinates["Mutation.deleteInvoice"]
+    assert delete.conditions == "any of: isAdmin | isOwner"
+
+
+TYPE_GRAPHQL = """import { Resolver, Query, Mutation, Authorized, FieldResolver, Root } from 'type-graphql';
+import { Role } from './role';
+
+@Resolver(() => Recipe)
+@Authorized()
+export class RecipeResolver {
+  @Query(() => [Recipe])
+  recipes() {}
+
+  @Authorized(Role.Admin)
+  @Mutation(() => Recipe, { name: 'removeRecipe' })
+  remove() {}
+
+  @Authorized([Role.Admin, Role.Editor])
+  @FieldResolver()
+  ratings(@Root() recipe: Recipe) {}
+}
+"""
+
+ROLE = """export enum Role {
+  Admin = 'ADMIN',
+  Editor = 'EDITOR',
+}
+"""
+
+AMPLIFY = '''type Post @model @auth(rules: [{ allow: owner }, { allow: groups, groups: ["Moderators"] }]) {
+  id: ID!
+  title: String!
+}
+
+type Query {
+  feed: [Post] @aws_cognito_user_pools(cognito_groups: ["Readers"])
+}
+'''
+
+
+def test_code_first_resolvers_and_amplify_rules():
+    """Test TypeGraphQL @Authorized on resolver classes and methods, and Amplify and AppSync directive rules."""
+    findings = extract_graphql_routes(
+        {"src/recipe.resolver.ts": TYPE_GRAPHQL, "src/role.ts": ROLE, "amplify/backend/api/schema.graphql": AMPLIFY}
+    )
+
+    coordinates = _coordinates(findings)
+    assert coordinates["Query.recipes"].subject == "Authenticated users"
+    assert coordinates["Mutation.removeRecipe"].subject == "ADMIN"
+    ratings = coordinates["Recipe.ratings"]
+    assert (ratings.kind, ratings.subject) == (GraphQLRouteKind.FIELD, "ADMIN or EDITOR")
+    title = coordinates["Post.title"]
+    assert title.conditions == "any of: Moderators | caller owns the record (owner)"
+    assert coordinates["Query.feed"].subject == "Readers"
@@ -0,0 +1,254 @@
+"""Tests for gRPC interceptor authorization mining."""
+from app.services.grpc_route_extractor import GrpcRouteKind, extract_grpc_routes
+
+ORDERS_GRPC_PB = """// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package ordersv1
+
+import (
+	grpc "google.golang.org/grpc"
+)
+
+const (
+	OrderService_GetOrder_FullMethodName     = "/orders.v1.OrderService/GetOrder"
+	OrderService_ListOrders_FullMethodName   = "/orders.v1.OrderService/ListOrders"
+	OrderService_DeleteOrder_FullMethodName  = "/orders.v1.OrderService/DeleteOrder"
+	OrderService_RefundOrder_FullMethodName  = "/orders.v1.OrderService/RefundOrder"
+	OrderService_WatchOrders_FullMethodName  = "/orders.v1.OrderService/WatchOrders"
+)
+
+func RegisterOrderServiceServer(s grpc.ServiceRegistrar, srv OrderServiceServer) {
+	s.RegisterService(&OrderService_ServiceDesc, srv)
+}
+
+var OrderService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "orders.v1.OrderService",
+	HandlerType: (*OrderServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetOrder",
+			Handler:    _OrderService_GetOrder_Handler,
+		},
+		{
+			MethodName: "ListOrders",
+			Handler:    _OrderService_ListOrders_Handler,
+		},
+		{
+			MethodName: "DeleteOrder",
+			Handler:    _OrderService_DeleteOrder_Handler,
+		},
+		{
+			MethodName: "RefundOrder",
+			Handler:    _OrderService_RefundOrder_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrders",
+			Handler:       _OrderService_WatchOrders_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "orders/v1/orders.proto",
+}
+"""
+
+MAIN = """package main
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+
+	ordersv1 "example.com/app/gen/orders/v1"
+	"example.com/app/auth"
+)
+
+func main() {
+	lis, _ := net.Listen("tcp", ":9090")
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auth.UnaryInterceptor),
+		grpc.StreamInterceptor(auth.StreamInterceptor),
+	)
+	ordersv1.RegisterOrderServiceServer(s, &orderServer{})
+	s.Serve(lis)
+}
+"""
+
+AUTH = """package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	ordersv1 "example.com/app/gen/orders/v1"
+)
+
+var publicMethods = map[string]bool{
+	ordersv1.OrderService_ListOrders_FullMethodName: true,
+}
+
+var methodRoles = map[string][]string{
+	ordersv1.OrderService_DeleteOrder_FullMethodName: {"admin"},
+	ordersv1.OrderService_RefundOrder_FullMethodName: {"admin", "support"},
+}
+
+func UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+	claims, err := authenticate(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "missing token")
+	}
+	if roles, ok := methodRoles[info.FullMethod]; ok && !hasAnyRole(claims, roles) {
+		return nil, status.Errorf(codes.PermissionDenied, "requires one of %v", roles)
+	}
+	return handler(ctx, req)
+}
+
+func StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(ss.Context())
+	if len(md.Get("authorization")) == 0 {
+		return status.Error(codes.Unauthenticated, "missing token")
+	}
+	return handler(srv, ss)
+}
+"""
+
+SERVER = """package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ordersv1 "example.com/app/gen/orders/v1"
+)
+
+type orderServer struct {
+	ordersv1.UnimplementedOrderServiceServer
+}
+
+func (s *orderServer) GetOrder(ctx context.Context, req *ordersv1.GetOrderRequest) (*ordersv1.Order, error) {
+	order := s.load(req.Id)
+	if order.OwnerID != userID(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "not your order")
+	}
+	return order, nil
+}
+"""
+
+FILES = {
+    "gen/orders/v1/orders_grpc.pb.go": ORDERS_GRPC_PB,
+    "main.go": MAIN,
+    "auth/interceptor.go": AUTH,
+    "server.go": SERVER,
+}
+
+
+def test_grpc_interceptors_map_checks_to_full_method_names():
+    """Test interceptor checks resolve per RPC through public-method sets, role maps, and handlers."""
+    findings = extract_grpc_routes(FILES)
+    by_method = {f.resource: f for f in findings}
+
+    # ListOrders is let through before any check
+    assert set(by_method) == {
+        "/orders.v1.OrderService/GetOrder",
+        "/orders.v1.OrderService/DeleteOrder",
+        "/orders.v1.OrderService/RefundOrder",
+        "/orders.v1.OrderService/WatchOrders",
+    }
+    assert all(f.kind == GrpcRouteKind.RPC and f.action == "POST" for f in findings)
+
+    delete = by_method["/orders.v1.OrderService/DeleteOrder"]
+    assert delete.subject == "admin"
+    assert delete.description == "gRPC unary RPC guarded by auth.UnaryInterceptor"
+    # Evidence is the method's entry in the role map
+    assert delete.file_path == "auth/interceptor.go" and "DeleteOrder" in delete.snippet
+
+    assert by_method["/orders.v1.OrderService/RefundOrder"].subject == "admin or support"
+
+    get = by_method["/orders.v1.OrderService/GetOrder"]
+    assert get.subject == "Authenticated users"
+    assert get.description == "gRPC unary RPC guarded by auth.UnaryInterceptor, handler"
+    assert get.file_path == "server.go" and "OwnerID" in get.snippet
+    assert "order.OwnerID" in get.conditions
+
+    watch = by_method["/orders.v1.OrderService/WatchOrders"]
+    assert watch.subject == "Authenticated users"
+    assert watch.description == "gRPC streaming RPC guarded by auth.StreamInterceptor"
+    assert watch.file_path == "main.go" and watch.line_start == 16
+
+
+def test_grpc_requires_import_and_reads_interceptor_factories():
+    """Test non-gRPC code is skipped and role arguments come from interceptor factories."""
+    assert extract_grpc_routes({"main.go": MAIN.replace("google.golang.org/grpc", "example.com/rpc")}) == []
+
+    main = MAIN.replace(
+        "grpc.ChainUnaryInterceptor(auth.UnaryInterceptor)",
+        "grpc.UnaryInterceptor(auth.RequireRole(\"auditor\"))",
+    )
+    factory = """package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func RequireRole(role string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod == "/orders.v1.OrderService/GetOrder" {
+			return handler(ctx, req)
+		}
+		if roleFrom(ctx) != role {
+			return nil, status.Error(codes.PermissionDenied, "forbidden")
+		}
+		return handler(ctx, req)
+	}
+}
+"""
+    files = {"gen/orders/v1/orders_grpc.pb.go": ORDERS_GRPC_PB, "main.go": main, "auth/role.go": factory}
+    by_method = {f.resource: f for f in extract_grpc_routes(files)}
+
+    # GetOrder is let through; the stream interceptor is not in the files, so it is classified by name
+    assert set(by_method) == {
+        "/orders.v1.OrderService/ListOrders",
+        "/orders.v1.OrderService/DeleteOrder",
+        "/orders.v1.OrderService/RefundOrder",
+        "/orders.v1.OrderService/WatchOrders",
+    }
+    assert by_method["/orders.v1.OrderService/ListOrders"].subject == "auditor"
+    assert by_method["/orders.v1.OrderService/ListOrders"].description == (
+        "gRPC unary RPC guarded by auth.RequireRole(\"auditor\")"
+    )
+    assert by_method["/orders.v1.OrderService/WatchOrders"].subject == "Authenticated users"
+
+
+def test_grpc_interceptors_with_interface_results():
+    """Test interceptors declared with interface{} parameters and results are read, not cut off at interface{}."""
+    auth = AUTH.replace("req any", "req interface{}").replace("(any, error)", "(interface{}, error)")
+    by_method = {f.resource: f.subject for f in extract_grpc_routes({**FILES, "auth/interceptor.go": auth})}
+
+    assert by_method["/orders.v1.OrderService/DeleteOrder"] == "admin"
+    assert by_method["/orders.v1.OrderService/RefundOrder"] == "admin or support"
+    assert "/orders.v1.OrderService/ListOrders" not in by_method
+
+    # The same check written inline in grpc.NewServer
+    inline = """func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if roleFrom(ctx) != "admin" {
+				return nil, status.Error(codes.PermissionDenied, "forbidden")
+			}
+			return handler(ctx, req)
+		}"""
+    main = MAIN.replace("grpc.ChainUnaryInterceptor(auth.UnaryInterceptor)", f"grpc.UnaryInterceptor({inline})")
+    by_method = {f.resource: f.subject for f in extract_grpc_routes({"gen/orders/v1/orders_grpc.pb.go": ORDERS_GRPC_PB, "main.go": main})}
+    assert by_method["/orders.v1.OrderService/GetOrder"] == "admin"
@@ -0,0 +1,159 @@
+"""Tests for leader election, worker registration, and scan hand-off."""
+import threading
+from datetime import UTC, datetime
+from unittest.mock import Mock
+
+import pytest
+
+from app.core.coordination import RELEASE_SCRIPT, RENEW_SCRIPT, LeaderElection
+from app.services.worker_registry import WorkerRegistry
+
+NOW = datetime(2026, 3, 6, 22, 17, tzinfo=UTC)
+
+
+class FakeRedis:
+    """The Redis commands coordination uses, without expiry; tests delete keys to expire them."""
+
+    def __init__(self):
+        self.data = {}
+        self.hashes = {}
+
+    def set(self, key, value, nx=False, px=None, ex=None):
+        if nx and key in self.data:
+            return None
+        self.data[key] = value
+        return True
+
+    def get(self, key):
+        return self.data.get(key)
+
+    def delete(self, key):
+        return int(self.data.pop(key, None) is not None)
+
+    def exists(self, key):
+        return int(key in self.data)
+
+    def scan_iter(self, match):
+        return [k for k in self.data if k.startswith(match.rstrip("*"))]
+
+    def eval(self, script, numkeys, key, identity, *args):
+        if self.data.get(key) != identity:
+            return 0
+        if script == RELEASE_SCRIPT:
+            del self.data[key]
+        assert script in (RENEW_SCRIPT, RELEASE_SCRIPT)
+        return 1
+
+    def hset(self, name, key, value):
+        self.hashes.setdefault(name, {})[key] = value
+
+    def hdel(self, name, key):
+        return int(self.hashes.get(name, {}).pop(key, None) is not None)
+
+    def hgetall(self, name):
+        return dict(self.hashes.get(name, {}))
+
+
+def test_one_replica_leads_until_it_releases_or_lapses():
+    """Test acquisition, renewal, standby takeover, and that only the holder can release."""
+    client = FakeRedis()
+    first = LeaderElection(client, "scheduler", identity="scheduler-0:7", lease_seconds=30)
+    second = LeaderElection(client, "scheduler", identity="scheduler-1:7", lease_seconds=30)
+
+    assert first.try_acquire() and first.try_acquire()
+    assert not second.try_acquire()
+    assert second.holder() == "scheduler-0:7"
+
+    second.release()  # Not the leader, so the lease stays
+    assert first.holder() == "scheduler-0:7"
+
+    first.release()
+    assert not first.is_leader
+    assert second.campaign(threading.Event())
+    assert second.holder() == "scheduler-1:7"
+
+    del client.data[second.key]  # Lease lapsed while the leader was partitioned away
+    assert first.try_acquire()
+    assert not second.try_acquire() and not second.is_leader
+
+    stopping = threading.Event()
+    stopping.set()
+    assert not second.campaign(stopping)
+
+
+def test_losing_the_lease_calls_back_once():
+    """Test the renewal thread notices another replica took over."""
+    client = FakeRedis()
+    election = LeaderElection(client, "operator", identity="operator-0:1", lease_seconds=0.06)
+    lost = threading.Event()
+    assert election.try_acquire()
+    thread = election.keep(on_lost=lost.set)
+
+    client.data[election.key] = "operator-1:1"
+    assert lost.wait(1)
+    thread.join(1)
+    assert not election.is_leader and not thread.is_alive()
+
+
+def test_workers_register_heartbeat_and_drain():
+    """Test registrations with their scans, re-registration after expiry, and draining."""
+    client = FakeRedis()
+    registry = WorkerRegistry(client)
+    registry.register("celery@scan-worker-a", NOW)
+    registry.register("celery@scan-worker-b", NOW)
+    assert registry.start_scan("celery@scan-worker-b", "task-1", 4, "payments", True, NOW)
+
+    workers = registry.list_workers()
+    assert [w["worker_id"] for w in workers] == ["celery@scan-worker-a", "celery@scan-worker-b"]
+    assert workers[1]["state"] == "active"
+    assert [s["repository_id"] for s in workers[1]["scans"]] == [4] and workers[0]["scans"] == []
+
+    client.delete("policy-miner:workers:celery@scan-worker-a")
+    registry.heartbeat("celery@scan-worker-a", NOW)
+    assert registry.get_worker("celery@scan-worker-a")["state"] == "active"
+
+    registry.drain("celery@scan-worker-b", NOW)
+    worker = registry.get_worker("celery@scan-worker-b")
+    assert (worker["state"], worker["drain_deadline"]) == ("draining", "2026-03-06T22:22:00+00:00")
+
+    registry.finish_scan("task-1")
+    registry.deregister("celery@scan-worker-b")
+    assert [w["worker_id"] for w in registry.list_workers()] == ["celery@scan-worker-a"]
+    assert registry.list_scans() == []
+
+
+def test_scans_of_gone_workers_are_handed_off_once():
+    """Test drain timeouts and lost workers requeue scans, and redeliveries of the old task are skipped."""
+    client = FakeRedis()
+    registry = WorkerRegistry(client)
+    registry.register("celery@scan-worker-a", NOW)
+    registry.register("celery@scan-worker-b", NOW)
+    registry.start_scan("celery@scan-worker-a", "task-1", 4, "payments", True, NOW)
+    registry.start_scan("celery@scan-worker-b", "task-2", 5, None, False, NOW)
+    enqueue = Mock(side_effect=["task-3", "task-4"])
+
+    handed_off = registry.hand_off_worker("celery@scan-worker-a", enqueue)
+    assert [(s["task_id"], s["new_task_id"], s["reason"]) for s in handed_off] == [("task-1", "task-3", "drain_timeout")]
+    enqueue.assert_called_once_with(4, "payments", True)
+    assert not registry.start_scan("celery@scan-worker-b", "task-1", 4, "payments", True, NOW)
+
+    assert registry.recover_orphaned_scans(enqueue) == []  # worker-b is alive
+    client.delete("policy-miner:workers:celery@scan-worker-b")
+    recovered = registry.recover_orphaned_scans(enqueue)
+    assert [(s["task_id"], s["new_task_id"], s["reason"]) for s in recovered] == [("task-2", "task-4", "worker_lost")]
+    assert registry.list_scans() == []
+    assert registry.recover_orphaned_scans(enqueue) == []
+
+
+def test_scan_stays_recorded_when_it_cannot_be_requeued():
+    """Test a broker failure leaves the orphaned scan for the next recovery."""
+    client = FakeRedis()
+    registry = WorkerRegistry(client)
+    registry.start_scan("celery@scan-worker-a", "task-1", 4, "payments", True, NOW)
+
+    assert registry.recover_orphaned_scans(Mock(side_effect=RuntimeError("broker down"))) == []
+    assert [s["task_id"] for s in registry.list_scans()] == ["task-1"]
+    assert registry.start_scan("celery@scan-worker-a", "task-1", 4, "payments", True, NOW)
+
+    with pytest.raises(RuntimeError):
+        registry.hand_off_worker("celery@scan-worker-a", Mock(side_effect=RuntimeError("broker down")))
@@ -0,0 +1,68 @@
+"""Tests for mapping mined conditions onto a declared identity model."""
+from unittest.mock import MagicMock
+
+import pytest
+
+from app.models.identity_model import IdentityModel
+from app.models.policy import Policy
+from app.services.identity_model_service import IdentityModelService, validate_identity_model
+
+SUBJECT_TYPES = [
+    {
+        "name": "User",
+        "identifier": "uid",
+        "attributes": [
+            {"name": "uid", "type": "string"},
+            {"name": "department", "type": "string", "aliases": ["dept", "claims.DepartmentName"]},
+            {"name": "approval_limit", "type": "number", "aliases": ["max_approval"]},
+            {"name": "suspended", "type": "boolean"},
+        ],
+    },
+    {"name": "device", "attributes": [{"name": "managed", "type": "boolean"}]},
+]
+
+
+def test_identity_models_are_validated():
+    """Test name normalization and rejection of unknown types and undeclared identifiers."""
+    [user, device] = validate_identity_model(SUBJECT_TYPES)
+    assert (user["name"], user["identifier"], device["identifier"]) == ("user", "uid", None)
+    assert user["attributes"][1]["aliases"] == ["dept", "claims.DepartmentName"]
+
+    with pytest.raises(ValueError, match="Unknown type 'money'"):
+        validate_identity_model([{"name": "user", "attributes": [{"name": "limit", "type": "money"}]}])
+    with pytest.raises(ValueError, match="Identifier 'id' is not an attribute"):
+        validate_identity_model([{"name": "user", "identifier": "id", "attributes": []}])
+    with pytest.raises(ValueError, match="declared twice"):
+        validate_identity_model([{"name": "user"}, {"name": "USER"}])
+
+
+def test_conditions_are_mapped_onto_declared_attributes_and_unknown_ones_flagged():
+    """Test alias mapping, subject-type prefixes, flags, ownership, and unknown subject attributes."""
+    db = MagicMock()
+    stored = IdentityModel(tenant_id="acme", subject_types=validate_identity_model(SUBJECT_TYPES))
+    db.query.return_value.filter.return_value.first.return_value = stored
+    approve = Policy(id=1, repository_id=5, conditions="user.dept == Finance and amount > 5000 requires DIRECTOR")
+    device = Policy(id=2, repository_id=5, conditions="device.managed == true; user.clearance >= 3")
+    owner = Policy(id=3, repository_id=5, conditions="user is owner of expense.owner_id and not suspended")
+    partial = Policy(id=4, repository_id=5, conditions="User claims.DepartmentName is \"Human Resources\" and reviewed somewhere")
+    db.query.return_value.filter.return_value.filter.return_value.all.return_value = [approve, device, owner, partial]
+    service = IdentityModelService(db, "acme")
+
+    report = service.map_conditions()
+    assert report["declared"] is True
+    mapped = {p["policy_id"]: p for p in report["policies"]}
+    assert [(c["status"], c["declared_attribute"]) for c in mapped[1]["clauses"]] == [("mapped", "department"), ("unscoped", None)]
+    assert mapped[1]["mapped_conditions"] == "user.department == Finance and amount > 5000 requires DIRECTOR"
+    assert mapped[2]["mapped_conditions"] == "device.managed == true and user.clearance >= 3"
+    assert mapped[3]["mapped_conditions"] == "user is owner of expense.owner_id and user.suspended == false"
+    assert mapped[3]["clauses"][0]["declared_attribute"] == "uid"
+    assert mapped[4]["mapped_conditions"] == 'user.department == "Human Resources"'
+    assert report["unknown_attributes"] == [{"attribute": "clearance", "policy_ids": [2], "occurrences": 1}]
+    assert report["summary"] == {"policies": 4, "clauses": 7, "mapped": 5, "unknown": 1, "unscoped": 1}
+
+    # The partly parsed condition keeps its text rather than losing a clause
+    result = service.apply_mapping()
+    assert result["policies_updated"] == 3
+    assert approve.conditions == "user.department == Finance and amount > 5000 requires DIRECTOR"
+    assert partial.conditions.endswith("reviewed somewhere")
+    db.commit.assert_called_once()
@@ -0,0 +1,115 @@
+"""Tests for end-user identity propagation and confused-deputy detection."""
+from unittest.mock import Mock
+
+from app.models.repository import Repository
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.identity_propagation_service import END_USER, Identity, trace_identities
+from app.services.service_call_extractor import extract_service_calls
+
+GATEWAY = '''import os
+
+import requests
+
+ORDERS_URL = os.environ["ORDERS_URL"]
+BILLING_URL = os.environ["BILLING_URL"]
+
+
+def get_order(request, order_id):
+    token = request.headers.get("Authorization")
+    return requests.get(f"{ORDERS_URL}/api/orders/{order_id}", headers={"Authorization": token})
+
+
+def refund(request, order_id):
+    headers = {"Authorization": f"Bearer {service_token()}"}
+    return requests.post(f"{BILLING_URL}/refunds/{order_id}", headers=headers)
+
+
+def cancel(request, order_id):
+    order = Order.objects.get(id=order_id)
+    if order.owner_id != request.user.id:
+        raise PermissionDenied
+    headers = {"x-api-key": os.environ["ORDERS_KEY"]}
+    return requests.delete(f"{ORDERS_URL}/api/orders/{order_id}", headers=headers)
+'''
+
+BILLING = """const axios = require('axios');
+
+async function refund(req, res) {
+  const account = req.body.account;
+  await axios.post(`http://ledger:8080/entries/${account}`, {}, {
+    headers: { authorization: req.headers.authorization },
+  });
+}
+"""
+
+
+def _repositories(*names):
+    """Mock repositories numbered from 1."""
+    repositories = []
+    for repository_id, name in enumerate(names, start=1):
+        repository = Mock(spec=Repository)
+        repository.id, repository.name = repository_id, name
+        repositories.append(repository)
+    return repositories
+
+
+def test_extractor_records_request_input_and_caller_checks():
+    """Test calls record the request input they carry, directly or through handler parameters, and prior checks."""
+    calls = extract_service_calls({"app.py": GATEWAY, "refunds.js": BILLING})
+
+    found = [(c.target, c.method, c.user_input, c.input_check) for c in calls]
+    assert found == [
+        ("orders", "GET", "parameter order_id", None),
+        ("billing", "POST", "parameter order_id", None),
+        ("orders", "DELETE", "parameter order_id", "owner_id !="),
+        ("ledger", "POST", "account = req.body.account", None),
+    ]
+
+
+def test_traces_replaced_and_relayed_identities_to_confused_deputies():
+    """Test relayed tokens carry the upstream service identity and unchecked input to checked endpoints is flagged."""
+    repositories = _repositories("gateway", "orders", "billing", "ledger")
+    calls = {1: extract_service_calls({"app.py": GATEWAY}), 3: extract_service_calls({"refunds.js": BILLING})}
+    rules = {
+        1: [
+            EndpointRule(method="GET", path="/orders/{order_id}", file_path="app.py", line_start=9),
+            EndpointRule(method="POST", path="/orders/{order_id}/refund", requires_authentication=False, file_path="app.py", line_start=14),
+        ],
+        2: [EndpointRule(method="*", path="/api/orders/{id}", roles=["customer"], policy_ids=[2])],
+        3: [EndpointRule(method="POST", path="/refunds/{order_id}", roles=["finance"], file_path="refunds.js", line_start=3)],
+        4: [EndpointRule(method="POST", path="/entries/{account}", conditions=["account is open"], policy_ids=[9])],
+    }
+
+    result = trace_identities(repositories, calls, rules)
+
+    services = {s["name"]: (s["entry"], s["receives"]) for s in result["services"]}
+    assert services == {
+        "gateway": (True, [END_USER]),
+        "orders": (False, [END_USER, "gateway"]),
+        "billing": (False, ["gateway"]),
+        "ledger": (False, ["gateway"]),
+    }
+    hops = {(h["source"], h["target"], h["method"]): h for h in result["hops"]}
+    assert hops[("gateway", "orders", "GET")]["identity"] == Identity.USER
+    refund = hops[("gateway", "billing", "POST")]
+    assert (refund["identity"], refund["replaces_user"]) == (Identity.SERVICE, True)
+    relay = hops[("billing", "ledger", "POST")]
+    assert (relay["identity"], relay["carried"], relay["replaces_user"]) == (Identity.RELAYED, ["gateway"], False)
+    assert result["summary"]["replaced"] == 2
+    assert result["summary"]["by_identity"] == {"service": 2, "user": 1, "relayed": 1}
+
+    # The checked cancel call is not a deputy; the public refund endpoint is the most severe
+    high, low = result["deputies"]
+    assert (high["source"], high["target_endpoint"], high["caller_endpoint"], high["severity"]) == (
+        "gateway",
+        "POST /refunds/{order_id}",
+        "POST /orders/{order_id}/refund",
+        "high",
+    )
+    assert high["description"] == (
+        "gateway calls POST /refunds/{order_id} on billing as its own service identity (service_token) with "
+        "parameter order_id; billing authorizes it for finance, but gateway does not check that the user may act on that input"
+    )
+    assert (low["source"], low["identity"], low["caller_roles"], low["severity"]) == ("billing", Identity.RELAYED, ["finance"], "low")
+    assert low["target_conditions"] == ["account is open"]
+    assert low["description"].startswith("billing calls POST /entries/{account} on ledger as a token relayed from gateway")
@@ -0,0 +1,152 @@
+"""Tests for IdP group ingestion and endpoint population analysis."""
+import json
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.idp_group import IdpGroup
+from app.models.role_assignment import RoleAssignment
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.idp_group_service import (
+    IdpGroupService,
+    RoleMatch,
+    fetch_okta_groups,
+    match_role_by_name,
+    parse_export,
+)
+
+LDIF = """dn: uid=alice,ou=people,dc=example,dc=com
+objectClass: inetOrgPerson
+uid: alice
+mail: alice@example.com
+
+dn: cn=expense-admins,ou=groups,dc=example,dc=com
+objectClass: groupOfNames
+cn: expense-admins
+member: uid=alice,ou=people,dc=example,dc=com
+member: cn=finance-leads,ou=groups,dc=exam
+ ple,dc=com
+
+# Nested into expense-admins
+dn: cn=finance-leads,ou=groups,dc=example,dc=com
+objectClass: groupOfNames
+cn: finance-leads
+member: uid=bob,ou=people,dc=example,dc=com
+"""
+
+
+def test_parse_exports_expand_nested_groups():
+    """Test Okta, Entra, and LDIF exports parse with nested members expanded."""
+    okta = parse_export(
+        "okta",
+        json.dumps([{"id": "00g1", "profile": {"name": "Approvers"}, "users": [{"profile": {"login": "a@x.io"}}]}]),
+    )
+    assert [(g.name, g.members) for g in okta] == [("Approvers", {"a@x.io"})]
+
+    entra = parse_export(
+        "entra",
+        json.dumps(
+            {
+                "value": [
+                    {"id": "g1", "displayName": "Admins", "members": [{"@odata.type": "#microsoft.graph.group", "id": "g2"}]},
+                    {"id": "g2", "displayName": "Ops", "members": [{"userPrincipalName": "ops@x.io"}]},
+                ]
+            }
+        ),
+    )
+    assert {g.name: g.members for g in entra} == {"Admins": {"ops@x.io"}, "Ops": {"ops@x.io"}}
+
+    ldap = {g.name: g.members for g in parse_export("ldap", LDIF)}
+    assert ldap == {"expense-admins": {"alice@example.com", "bob"}, "finance-leads": {"bob"}}
+
+
+def test_match_role_by_name():
+    """Test group names match the longest mined role suffix."""
+    roles = {"ADMIN", "APPROVER", "BILLING_APPROVER"}
+    assert match_role_by_name("expenses-admins", roles) == "ADMIN"
+    assert match_role_by_name("Billing Approvers", roles) == "BILLING_APPROVER"
+    assert match_role_by_name("GRP_ExpenseApprover", roles) == "APPROVER"
+    assert match_role_by_name("all-staff", roles) is None
+
+
+def test_fetch_okta_groups_follows_pagination():
+    """Test the Okta connector follows Link headers and reads member logins."""
+    def response(data, next_url=None):
+        return Mock(json=Mock(return_value=data), links={"next": {"url": next_url}} if next_url else {})
+
+    client = MagicMock()
+    client.get.side_effect = [
+        response([{"id": "g1", "profile": {"name": "Admins"}}], "https://acme.okta.com/api/v1/groups?after=g1"),
+        response([{"id": "g2", "profile": {"name": "Auditors"}}]),
+        response([{"profile": {"login": "a@acme.com"}}]),
+        response([]),
+    ]
+
+    groups = fetch_okta_groups("https://acme.okta.com/", "token", client)
+
+    assert [(g.name, g.members) for g in groups] == [("Admins", {"a@acme.com"}), ("Auditors", set())]
+    assert client.get.call_args_list[0].kwargs["headers"]["Authorization"] == "SSWS token"
+    assert client.get.call_args_list[1].kwargs["params"] is None
+
+
+def test_sync_assignments_expands_groups_to_roles():
+    """Test mapped and name-matched groups become idp-sourced role assignments."""
+    groups = [
+        Mock(spec=IdpGroup, id=1, source="okta", members=["a@x.io", "b@x.io"]),
+        Mock(spec=IdpGroup, id=2, source="okta", members=["b@x.io"]),
+        Mock(spec=IdpGroup, id=3, source="ldap", members=["c@x.io"]),
+    ]
+    for group, name in zip(groups, ["Finance", "expense-admins", "everyone"], strict=True):
+        group.name = name
+    mapping = Mock(group_name="finance", source=None, role="APPROVER", application_id=4)
+
+    db = MagicMock()
+    db.query.return_value.filter.return_value.all.side_effect = [groups, [mapping]]
+    service = IdpGroupService(db, tenant_id="acme")
+
+    with patch.object(IdpGroupService, "known_roles", return_value={"ADMIN", "APPROVER"}):
+        result = service.sync_assignments()
+
+    assert result == {"assignments": 3, "users": 2, "unmapped_groups": ["everyone"]}
+    created = {(a.user_identifier, a.role, a.application_id, a.source) for a in (c.args[0] for c in db.add.call_args_list)}
+    assert created == {
+        ("a@x.io", "APPROVER", 4, "idp:okta"),
+        ("b@x.io", "APPROVER", 4, "idp:okta"),
+        ("b@x.io", "ADMIN", None, "idp:okta"),
+    }
+    assert all(isinstance(c.args[0], RoleAssignment) for c in db.add.call_args_list)
+
+
+def test_endpoint_population_counts_role_holders():
+    """Test endpoint population joins mined roles with role holders and granting groups."""
+    rules = [
+        EndpointRule(method="DELETE", path="/api/expenses/{id}", roles=["ADMIN", "OWNER"], policy_ids=[7]),
+        EndpointRule(method="GET", path="/api/expenses/{id}", roles=[], policy_ids=[8]),
+    ]
+    group = Mock(spec=IdpGroup, id=1, source="entra", members=["dave@x.io"])
+    group.name = "Expense Admins"
+    service = IdpGroupService(MagicMock(), tenant_id="acme")
+    service._query = MagicMock()
+    service._query.return_value.all.return_value = [group]
+
+    with (
+        patch("app.services.idp_group_service.DecisionSimulationService.load_policies", return_value=[]),
+        patch("app.services.idp_group_service.EndpointMappingService.map_policies", return_value=rules),
+        patch(
+            "app.services.idp_group_service.RoleImpactService.load_assignments",
+            return_value={"dave@x.io": {"ADMIN"}, "erin@x.io": {"ADMIN"}, "frank@x.io": {"VIEWER"}},
+        ),
+        patch.object(
+            IdpGroupService, "resolve_group_roles", return_value={1: [("ADMIN", None, RoleMatch.NAME)]}
+        ),
+    ):
+        report = service.endpoint_population("delete", "/api/expenses/42")
+
+    assert report["user_count"] == 2
+    assert report["users"] == ["dave@x.io", "erin@x.io"]
+    (endpoint,) = report["endpoints"]
+    assert endpoint["endpoint"] == "DELETE /api/expenses/{id}"
+    assert endpoint["roles"] == [
+        {"role": "ADMIN", "users": 2, "groups": ["entra:Expense Admins"]},
+        {"role": "OWNER", "users": 0, "groups": []},
+    ]
+    assert report["roles_without_holders"] == ["OWNER"]
+    assert report["public"] is False
@@ -0,0 +1,133 @@
+"""Tests for IdP role sync and role drift detection."""
+from datetime import UTC, datetime, timedelta
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.idp_connector import (
+    IdpConnector,
+    IdpConnectorType,
+    IdpRoleDrift,
+    RoleDriftKind,
+    RoleDriftStatus,
+)
+from app.services.idp_role_sync_service import (
+    IdpRoleSyncService,
+    diff_roles,
+    fetch_auth0_roles,
+    fetch_keycloak_roles,
+    normalize_role,
+)
+
+
+def response(data):
+    """Mock HTTP response returning JSON data."""
+    return Mock(json=Mock(return_value=data))
+
+
+def test_diff_roles_in_both_directions():
+    """Test roles are normalized and compared, ignoring IdP built-in roles."""
+    assert normalize_role("ROLE_expense-approver") == normalize_role("Expense Approver") == "EXPENSE_APPROVER"
+
+    drifts = diff_roles(
+        {"admin": "Administrators", "legacy-auditor": "", "offline_access": "", "default-roles-acme": ""},
+        {"ADMIN": [1], "EXPENSE_APPROVER": [2, 3]},
+    )
+
+    assert drifts == [
+        ("EXPENSE_APPROVER", RoleDriftKind.MISSING_FROM_IDP, {"policy_ids": [2, 3]}),
+        ("LEGACY_AUDITOR", RoleDriftKind.UNUSED_IN_CODE, {"idp_name": "legacy-auditor", "description": ""}),
+    ]
+
+
+def test_fetch_keycloak_roles_includes_client_roles():
+    """Test the Keycloak connector pages realm roles and adds the configured client's roles."""
+    connector = Mock(
+        spec=IdpConnector, base_url="https://sso.acme.io/", realm="acme", client_id="miner",
+        client_secret="s3cret", role_client_id="expenses-api",
+    )
+    client = MagicMock()
+    client.post.return_value = response({"access_token": "tok"})
+    client.get.side_effect = [
+        response([{"name": "admin", "description": "Admins"}]),
+        response([{"id": "c-1"}]),
+        response([{"name": "approver"}]),
+    ]
+
+    roles = fetch_keycloak_roles(connector, client)
+
+    assert roles == {"admin": "Admins", "approver": ""}
+    assert client.post.call_args.args[0] == "https://sso.acme.io/realms/acme/protocol/openid-connect/token"
+    assert client.get.call_args_list[2].args[0] == "https://sso.acme.io/admin/realms/acme/clients/c-1/roles"
+    assert client.get.call_args_list[0].kwargs["headers"] == {"Authorization": "Bearer tok"}
+
+
+def test_fetch_auth0_roles_pages_until_total():
+    """Test the Auth0 connector requests a management token and pages roles."""
+    connector = Mock(spec=IdpConnector, base_url="https://acme.us.auth0.com", client_id="m2m", client_secret="x")
+    client = MagicMock()
+    client.post.return_value = response({"access_token": "tok"})
+    client.get.side_effect = [
+        response({"roles": [{"name": "admin"}], "total": 2}),
+        response({"roles": [{"name": "viewer", "description": "Read only"}], "total": 2}),
+    ]
+
+    assert fetch_auth0_roles(connector, client) == {"admin": "", "viewer": "Read only"}
+    assert client.post.call_args.kwargs["json"]["audience"] == "https://acme.us.auth0.com/api/v2/"
+    assert client.get.call_args_list[1].kwargs["params"]["page"] == 1
+
+
+def test_sync_reconciles_drift_findings():
+    """Test a sync opens new drift, reopens resolved drift, resolves vanished drift, and keeps dismissals."""
+    connector = Mock(
+        spec=IdpConnector, id=3, name="Keycloak", base_url="https://sso.acme.io",
+        connector_type=IdpConnectorType.KEYCLOAK, application_id=None,
+    )
+    stale = Mock(spec=IdpRoleDrift, role="OLD", kind=RoleDriftKind.UNUSED_IN_CODE, status=RoleDriftStatus.OPEN)
+    returning = Mock(
+        spec=IdpRoleDrift, role="AUDITOR", kind=RoleDriftKind.UNUSED_IN_CODE, status=RoleDriftStatus.RESOLVED
+    )
+    dismissed = Mock(
+        spec=IdpRoleDrift, role="SUPPORT", kind=RoleDriftKind.UNUSED_IN_CODE, status=RoleDriftStatus.DISMISSED
+    )
+    db = MagicMock()
+    service = IdpRoleSyncService(db, tenant_id="acme")
+    service.get_connector = MagicMock(return_value=connector)
+    service.code_roles = MagicMock(return_value={"ADMIN": [1], "APPROVER": [4]})
+    service._drifts = MagicMock(return_value=[stale, returning, dismissed])
+
+    fetch = MagicMock(return_value={"admin": "", "auditor": "", "support": ""})
+    with patch.dict("app.services.idp_role_sync_service.ROLE_FETCHERS", {IdpConnectorType.KEYCLOAK: fetch}):
+        result = service.sync_connector(3, client=MagicMock())
+
+    assert [(d["role"], d["kind"]) for d in result["opened"]] == [
+        ("APPROVER", "missing_from_idp"),
+        ("AUDITOR", "unused_in_code"),
+    ]
+    assert result["resolved"] == 1
+    assert stale.status == RoleDriftStatus.RESOLVED
+    assert returning.status == RoleDriftStatus.OPEN and returning.resolved_at is None
+    assert dismissed.status == RoleDriftStatus.DISMISSED
+    assert connector.idp_roles == ["admin", "auditor", "support"]
+    assert connector.last_error is None
+    (added,) = [c.args[0] for c in db.add.call_args_list]
+    assert (added.role, added.details) == ("APPROVER", {"policy_ids": [4]})
+
+
+def test_sync_due_connectors_skips_recent_and_survives_failures():
+    """Test scheduled sync honors intervals and isolates connector failures."""
+    now = datetime.now(UTC)
+    recent = Mock(spec=IdpConnector, id=1, tenant_id="a", sync_interval_minutes=60, last_synced_at=now)
+    due = Mock(spec=IdpConnector, id=2, tenant_id="b", sync_interval_minutes=None,
+               last_synced_at=now - timedelta(days=1))
+    failing = Mock(spec=IdpConnector, id=3, tenant_id="c", sync_interval_minutes=None, last_synced_at=None)
+    db = MagicMock()
+    db.query.return_value.all.return_value = [recent, due, failing]
+
+    def sync(self, connector_id, client=None):
+        if connector_id == 3:
+            raise ValueError("IdP unreachable")
+        return {"connector_id": connector_id, "tenant": self.tenant_id}
+
+    with patch.object(IdpRoleSyncService, "sync_connector", sync):
+        results = IdpRoleSyncService(db).sync_due_connectors()
+
+    assert results == [{"connector_id": 2, "tenant": "b"}]
@@ -0,0 +1,149 @@
+"""Tests for container image config scanning."""
+import io
+import json
+import tarfile
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.policy import ExtractionMethod, Policy, RiskLevel
+from app.models.repository import Repository
+from app.services.image_scan_service import ImageScanService, read_image_archive
+
+REGO = 'package authz\n\nallow if {\n    input.method == "GET"\n    "auditor" in input.user.roles\n}\n'
+
+
+def _tar_bytes(files: dict[str, bytes]) -> bytes:
+    """Build an uncompressed tarball from path -> content."""
+    buffer = io.BytesIO()
+    with tarfile.open(fileobj=buffer, mode="w") as tar:
+        for path, content in files.items():
+            info = tarfile.TarInfo(path)
+            info.size = len(content)
+            tar.addfile(info, io.BytesIO(content))
+    return buffer.getvalue()
+
+
+def _docker_save(tmp_path, layers: list[dict[str, bytes]], env: list[str]) -> str:
+    """Write a docker-save style archive and return its path."""
+    files = {
+        "config.json": json.dumps({"config": {"Env": env}}).encode(),
+        "manifest.json": json.dumps(
+            [{"Config": "config.json", "RepoTags": ["payments:1.4"], "Layers": [f"l{i}/layer.tar" for i in range(len(layers))]}]
+        ).encode(),
+    }
+    for i, layer in enumerate(layers):
+        files[f"l{i}/layer.tar"] = _tar_bytes(layer)
+    path = tmp_path / "image.tar"
+    path.write_bytes(_tar_bytes(files))
+    return str(path)
+
+
+def test_read_image_archive_applies_layers_and_whiteouts(tmp_path):
+    """Test layers are flattened in order with whiteouts removing lower files."""
+    archive = _docker_save(
+        tmp_path,
+        [
+            {
+                "opa/authz.rego": REGO.encode(),
+                "srv/app/.env": b"SKIP_AUTH=true\n",
+                "etc/ld.so.conf": b"/usr/lib\n",
+                "etc/nginx/conf.d/old.conf": b"server {}\n",
+            },
+            {"srv/app/.wh..env": b"", "etc/nginx/conf.d/.wh..wh..opq": b""},
+        ],
+        ["AUTH_DISABLED=true"],
+    )
+
+    contents = read_image_archive(archive)
+
+    assert contents.name == "payments:1.4"
+    assert contents.layers == 2
+    assert contents.env == ["AUTH_DISABLED=true"]
+    assert list(contents.files) == ["opa/authz.rego"]
+
+
+def test_read_image_archive_rejects_non_images(tmp_path):
+    """Test archives without an image manifest are rejected."""
+    path = tmp_path / "source.tar"
+    path.write_bytes(_tar_bytes({"README.md": b"hello"}))
+
+    with pytest.raises(ValueError, match="Not a container image archive"):
+        read_image_archive(str(path))
+
+
+@pytest.mark.parametrize(
+    "files",
+    [
+        {"manifest.json": b"[]"},
+        {"manifest.json": b'{"Config": "config.json"}'},
+        {"manifest.json": b'[{"Layers": []}]'},
+        {"manifest.json": b'[{"Config": "config.json", "Layers": []}]', "config.json": b"[]"},
+        {"index.json": b'{"manifests": [{"mediaType": "application/vnd.oci.image.manifest.v1+json"}]}'},
+        {"index.json": b'{"manifests": "sha256:abc"}'},
+        {"index.json": b'{"manifests": [{"digest": "sha256:abc"}]}', "blobs/sha256/abc": b'{"layers": []}'},
+    ],
+)
+def test_read_image_archive_rejects_malformed_manifests(tmp_path, files):
+    """Test manifests, indexes, and configs of the wrong shape are rejected as unreadable images."""
+    path = tmp_path / "image.tar"
+    path.write_bytes(_tar_bytes(files))
+
+    with pytest.raises(ValueError, match="Malformed|lists no manifests"):
+        read_image_archive(str(path))
+
+
+def test_scan_image_merges_with_source_policies(tmp_path):
+    """Test matching findings corroborate source policies and others become new policies."""
+    archive = _docker_save(tmp_path, [{"opa/authz.rego": REGO.encode()}], ["AUTH_DISABLED=true"])
+    repo = Mock(spec=Repository, id=7, tenant_id="acme")
+    source_policy = Mock(spec=Policy, id=11, subject="Auditor", resource="authz", action="get", application_id=3)
+    source_policy.evidence = []
+
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    db.query.return_value.join.return_value.filter.return_value.filter.return_value.all.return_value = []
+    db.query.return_value.filter.return_value.all.return_value = [source_policy]
+
+    result = ImageScanService(db, "acme").scan_image(7, archive)
+
+    assert result["image"] == "payments:1.4"
+    assert result["findings_by_kind"] == {"env_toggle": 1, "opa_rego": 1}
+    assert result["auth_disabling_findings"] == 1
+    assert (result["policies_created"], result["policies_merged"], result["policies_removed"]) == (1, 1, 0)
+    assert source_policy.evidence[0].file_path == "image://payments:1.4/opa/authz.rego"
+
+    created = db.add.call_args.args[0]
+    assert created.resource == "AUTH_DISABLED"
+    assert created.application_id == 3
+    assert created.risk_level == RiskLevel.HIGH
+    assert (created.extraction_method, created.confidence_score <= 95) == (ExtractionMethod.DECLARED_CONFIG, True)
+    assert created.evidence[0].file_path == "image://payments:1.4/config/Env"
+    db.commit.assert_called_once()
+
+
+def test_rescan_removes_previous_image_evidence():
+    """Test image-only policies are deleted and merged evidence is detached on rescan."""
+    origin = "image://payments:1.4/"
+    image_only = Mock(spec=Policy, id=1)
+    image_only.evidence = [Mock(file_path=f"{origin}config/Env")]
+    image_only.evidence[0].policy = image_only
+    merged = Mock(spec=Policy, id=2)
+    merged_evidence = Mock(file_path=f"{origin}opa/authz.rego")
+    merged.evidence = [Mock(file_path="src/routes.py"), merged_evidence]
+    merged_evidence.policy = merged
+
+    db = MagicMock()
+    db.query.return_value.join.return_value.filter.return_value.filter.return_value.all.return_value = [
+        image_only.evidence[0],
+        merged_evidence,
+    ]
+    db.query.return_value.filter.return_value.all.return_value = []
+
+    result = ImageScanService(db).merge_findings(
+        Mock(spec=Repository, id=7, tenant_id=None), [], "image", origin=origin
+    )
+
+    assert result["policies_removed"] == 1
+    db.delete.assert_any_call(image_only)
+    db.delete.assert_any_call(merged_evidence)
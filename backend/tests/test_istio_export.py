@@ -0,0 +1,85 @@
+"""Tests for exporting mined policies as Istio AuthorizationPolicies."""
+import yaml
+
+from app.services.istio_export_service import IstioExportService, istio_path, k8s_name
+
+
+def test_names_and_paths():
+    """Test resource names from service names and path templates from route parameters."""
+    assert k8s_name("Expense API", "repository-4") == "expense-api"
+    assert k8s_name("billing_v2.0", "repository-4") == "billing-v2-0"
+    assert k8s_name("!!", "repository-4") == "repository-4"
+    assert istio_path("/api/expenses/{}/approve") == "/api/expenses/{*}/approve"
+    assert istio_path("/files/{}.json") == "/files/{*}"
+
+
+def test_one_allow_policy_per_service_with_operations_roles_and_claims(make_policy, make_export_service):
+    """Test rules per policy, claim conditions, public routes, and clauses left to the application."""
+    policies = [
+        make_policy(
+            1, "MANAGER", "approve", "router.put('/api/expenses/:id/approve', requireRole('MANAGER'), approve)",
+            conditions="amount <= 5000 and user department is Finance",
+        ),
+        make_policy(
+            2, "AUDITOR or DIRECTOR", "approve", "router.put('/api/expenses/:id/approve', requireRole('AUDITOR', 'DIRECTOR'), approve)",
+            conditions='user.region != "EU"',
+        ),
+        make_policy(
+            3, "EMPLOYEE", "read", "router.get('/api/expenses/:id', requireRole('EMPLOYEE'), show)",
+            conditions="user is owner of expense.owner_id unless ADMIN",
+        ),
+        make_policy(4, "authenticated", "read", "app.get('/api/invoices', list)", resource="Invoice", application="Billing"),
+        make_policy(5, "anyone", "read", "app.get('/api/invoices/rates', rates)", resource="Rate", application="Billing"),
+        make_policy(6, "ADMIN", "read", "app.get('/api/audit', audit)", confidence=40.0),
+    ]
+    result = make_export_service(IstioExportService, policies).export(4, namespace="payments", role_claim="groups", dry_run=True, min_confidence=50)
+
+    documents = list(yaml.safe_load_all(result["manifests"]))
+    assert [d["metadata"]["name"] for d in documents] == ["billing-mined", "expense-api-mined"]
+    billing, expenses = documents
+    assert expenses["apiVersion"] == "security.istio.io/v1" and expenses["kind"] == "AuthorizationPolicy"
+    assert expenses["metadata"]["namespace"] == "payments"
+    assert expenses["metadata"]["annotations"] == {"policyminer.io/repository-id": "4", "istio.io/dry-run": "true"}
+    assert expenses["spec"]["selector"] == {"matchLabels": {"app": "expense-api"}}
+    assert expenses["spec"]["action"] == "ALLOW"
+
+    read, approve, approve_audit = expenses["spec"]["rules"]
+    assert read["to"] == [{"operation": {"methods": ["GET"], "paths": ["/api/expenses/{*}"]}}]
+    assert read["when"] == [{"key": "request.auth.claims[groups]", "values": ["EMPLOYEE"]}]
+    assert approve["from"] == [{"source": {"requestPrincipals": ["*"]}}]
+    assert approve["when"] == [
+        {"key": "request.auth.claims[groups]", "values": ["MANAGER"]},
+        {"key": "request.auth.claims[department]", "values": ["Finance"]},
+    ]
+    assert approve_audit["when"][1] == {"key": "request.auth.claims[region]", "notValues": ["EU"]}
+
+    invoices, rates = billing["spec"]["rules"]
+    assert invoices["from"] == [{"source": {"requestPrincipals": ["*"]}}] and "when" not in invoices
+    assert rates == {"to": [{"operation": {"methods": ["GET"], "paths": ["/api/invoices/rates"]}}]}
+
+    assert [(u["policy_id"], u["clause"]) for u in result["untranslated"]] == [
+        (3, "user is owner of expense.owner_id unless ADMIN"),
+        (1, "amount <= 5000"),
+    ]
+    assert result["authorization_policies"][1] == {
+        "name": "expense-api-mined",
+        "service": "expense-api",
+        "endpoints": ["GET /api/expenses/{}", "PUT /api/expenses/{}/approve"],
+        "mined_policy_ids": [1, 2, 3],
+        "rules": 3,
+    }
+    assert result["summary"] == {
+        "policies": 5,
+        "services": 2,
+        "rules": 5,
+        "endpoints": 4,
+        "below_min_confidence": 1,
+        "untranslated_clauses": 2,
+    }
+
+
+def test_empty_repository_exports_nothing(make_export_service):
+    """Test a repository without approved policies has no AuthorizationPolicies to apply."""
+    result = make_export_service(IstioExportService, []).export(4)
+    assert result["manifests"] == "" and result["authorization_policies"] == []
+    assert result["summary"]["services"] == 0
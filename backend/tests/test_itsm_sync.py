@@ -0,0 +1,203 @@
+"""Tests for syncing findings with ServiceNow and generic ITSM tickets."""
+from unittest.mock import MagicMock, Mock, patch
+
+import httpx
+import pytest
+
+from app.core.config import settings
+from app.models.itsm_connector import FindingTicket, FindingTicketStatus, ItsmConnector, ItsmConnectorType
+from app.services.itsm_sync_service import (
+    STILL_DETECTED_NOTE,
+    GenericItsmClient,
+    ItsmSyncService,
+    RemoteTicket,
+    ServiceNowClient,
+    TicketState,
+    ticket_description,
+    ticket_title,
+)
+
+
+def make_finding(key, severity="high"):
+    """A tagged finding as FindingExportService returns it."""
+    return {
+        "finding_key": key,
+        "rule_id": "missing_authorization",
+        "title": "Route with no authorization",
+        "severity": severity,
+        "description": "DELETE /api/expenses/{id} has no authorization check",
+        "repository_id": 3,
+        "repository_name": "expenses",
+        "file_path": "routes.js",
+        "line_start": 42,
+        "cwe": ["CWE-862"],
+        "owasp_api": ["API5:2023"],
+    }
+
+
+def response(data):
+    """Mock HTTP response returning JSON data."""
+    return Mock(json=Mock(return_value=data))
+
+
+def make_ticket(key, ticket_id, status=FindingTicketStatus.OPEN):
+    """A ticket link of connector 7."""
+    return Mock(
+        spec=FindingTicket, finding_key=key, ticket_id=ticket_id, status=status, ticket_number=None, resolution_note=None
+    )
+
+
+def test_servicenow_client_creates_records_and_reads_risk_acceptance():
+    """Test record fields, batched reads, close-code mapping, and resolve/reopen updates."""
+    connector = Mock(
+        spec=ItsmConnector, base_url="https://acme.service-now.com/", username="miner", secret="pw", table=None,
+        assignment_group="AppSec", risk_accepted_code=None,
+    )
+    client = MagicMock()
+    client.request.side_effect = [
+        response({"result": {"sys_id": "a1", "number": "INC001", "state": "1"}}),
+        response(
+            {
+                "result": [
+                    {"sys_id": "a1", "number": "INC001", "state": "2"},
+                    {"sys_id": "b2", "number": "INC002", "state": "7", "close_code": "Risk Accepted", "close_notes": "WAF"},
+                    {"sys_id": "c3", "number": "INC003", "state": "6", "close_code": "Solved (Permanently)"},
+                ]
+            }
+        ),
+        response({"result": {}}),
+    ]
+    itsm = ServiceNowClient(connector, client)
+
+    created = itsm.create_ticket(make_finding("fnd_1", "critical"))
+    tickets = itsm.fetch_tickets(["a1", "b2", "c3"])
+    itsm.update_ticket("c3", TicketState.OPEN, STILL_DETECTED_NOTE)
+
+    assert (created.ticket_id, created.number, created.state) == ("a1", "INC001", TicketState.OPEN)
+    assert created.url == "https://acme.service-now.com/nav_to.do?uri=incident.do?sys_id=a1"
+    method, url = client.request.call_args_list[0].args
+    body = client.request.call_args_list[0].kwargs["json"]
+    assert (method, url) == ("POST", "https://acme.service-now.com/api/now/table/incident")
+    assert (body["urgency"], body["impact"], body["correlation_id"]) == ("1", "1", "fnd_1")
+    assert body["assignment_group"] == "AppSec"
+    assert client.request.call_args_list[0].kwargs["auth"] == ("miner", "pw")
+    assert client.request.call_args_list[1].kwargs["params"]["sysparm_query"] == "sys_idINa1,b2,c3"
+    assert {k: t.state for k, t in tickets.items()} == {
+        "a1": TicketState.OPEN, "b2": TicketState.RISK_ACCEPTED, "c3": TicketState.RESOLVED,
+    }
+    assert tickets["b2"].resolution == "WAF"
+    assert client.request.call_args_list[2].kwargs["json"] == {"work_notes": STILL_DETECTED_NOTE, "state": "2"}
+
+
+def test_generic_client_follows_the_ticket_contract():
+    """Test bearer auth, the contract's requests, unknown states, and tickets deleted from the ITSM."""
+    connector = Mock(spec=ItsmConnector, base_url="https://itsm.acme.io/api", username=None, secret="tok")
+    missing = httpx.HTTPStatusError("not found", request=Mock(), response=Mock(status_code=404))
+    client = MagicMock()
+    client.request.side_effect = [
+        response({"id": 12, "number": "SEC-12", "state": "open"}),
+        response({"id": 12, "state": "triage"}),
+        Mock(raise_for_status=Mock(side_effect=missing)),
+    ]
+    itsm = GenericItsmClient(connector, client)
+
+    created = itsm.create_ticket(make_finding("fnd_1"))
+    tickets = itsm.fetch_tickets(["12", "13"])
+
+    assert (created.ticket_id, created.number) == ("12", "SEC-12")
+    assert client.request.call_args_list[0].args == ("POST", "https://itsm.acme.io/api/tickets")
+    assert client.request.call_args_list[0].kwargs["json"]["labels"] == ["missing_authorization", "CWE-862", "API5:2023"]
+    assert client.request.call_args_list[0].kwargs["headers"]["Authorization"] == "Bearer tok"
+    assert list(tickets) == ["12"] and (tickets["12"].state, tickets["12"].remote_state) == (TicketState.OPEN, "triage")
+
+
+def test_sync_moves_findings_and_tickets_both_ways():
+    """Test creation, risk acceptance and its revocation, resolving, and reopening tickets closed while detected."""
+    connector = Mock(
+        spec=ItsmConnector, id=7, base_url="https://acme.service-now.com",
+        connector_type=ItsmConnectorType.SERVICENOW, min_severity="high", repository_id=None,
+    )
+    connector.name = "ServiceNow"
+    accepted = make_ticket("fnd_accepted", "t1")
+    revoked = make_ticket("fnd_revoked", "t2", FindingTicketStatus.RISK_ACCEPTED)
+    fixed = make_ticket("fnd_fixed", "t3")
+    closed_early = make_ticket("fnd_closed_early", "t4")
+    returned = make_ticket("fnd_returned", "t5", FindingTicketStatus.RESOLVED)
+    db = MagicMock()
+    service = ItsmSyncService(db, tenant_id="acme")
+    service.get_connector = MagicMock(return_value=connector)
+    service._tickets = MagicMock(return_value=[accepted, revoked, fixed, closed_early, returned])
+    service.detected_findings = MagicMock(
+        return_value={k: make_finding(k) for k in ("fnd_accepted", "fnd_revoked", "fnd_closed_early", "fnd_returned", "fnd_new")}
+    )
+
+    itsm = MagicMock()
+    itsm.fetch_tickets.return_value = {
+        "t1": RemoteTicket("t1", TicketState.RISK_ACCEPTED, "7", resolution="Compensating WAF rule"),
+        "t2": RemoteTicket("t2", TicketState.OPEN, "2"),
+        "t3": RemoteTicket("t3", TicketState.OPEN, "2"),
+        "t4": RemoteTicket("t4", TicketState.RESOLVED, "6"),
+        "t5": RemoteTicket("t5", TicketState.RESOLVED, "6"),
+    }
+    itsm.create_ticket.return_value = RemoteTicket("t6", TicketState.OPEN, "1", number="INC006")
+    with patch.dict("app.services.itsm_sync_service.ITSM_CLIENTS", {ItsmConnectorType.SERVICENOW: Mock(return_value=itsm)}):
+        result = service.sync_connector(7, client=MagicMock())
+
+    assert (accepted.status, accepted.resolution_note) == (FindingTicketStatus.RISK_ACCEPTED, "Compensating WAF rule")
+    assert revoked.status == FindingTicketStatus.OPEN
+    assert fixed.status == FindingTicketStatus.RESOLVED
+    assert closed_early.status == returned.status == FindingTicketStatus.OPEN
+    assert [c.args[:2] for c in itsm.update_ticket.call_args_list] == [
+        ("t3", TicketState.RESOLVED),
+        ("t4", TicketState.OPEN),
+        ("t5", TicketState.OPEN),
+    ]
+    (added,) = [c.args[0] for c in db.add.call_args_list]
+    assert (added.finding_key, added.ticket_id, added.ticket_number) == ("fnd_new", "t6", "INC006")
+    assert {k: result[k] for k in ("created", "resolved", "reopened", "risk_accepted", "acceptance_revoked")} == {
+        "created": 1, "resolved": 1, "reopened": 2, "risk_accepted": 1, "acceptance_revoked": 1,
+    }
+    assert connector.last_error is None
+
+
+def test_sync_records_itsm_failures_and_filters_by_severity():
+    """Test a failing ITSM is recorded on the connector, and only findings at the minimum severity are ticketed."""
+    connector = Mock(
+        spec=ItsmConnector, id=7, base_url="https://itsm.acme.io",
+        connector_type=ItsmConnectorType.GENERIC, min_severity="high", repository_id=3,
+    )
+    connector.name = "Generic"
+    service = ItsmSyncService(MagicMock(), tenant_id="acme")
+    service.get_connector = MagicMock(return_value=connector)
+    service._tickets = MagicMock(return_value=[])
+    findings = [make_finding("fnd_high"), make_finding("fnd_medium", "medium"), make_finding("fnd_critical", "critical")]
+    with patch("app.services.itsm_sync_service.FindingExportService.findings", return_value=findings) as exported:
+        detected = service.detected_findings(connector)
+    exported.assert_called_once_with(repository_id=3, include_accepted=True)
+    assert sorted(detected) == ["fnd_critical", "fnd_high"]
+
+    itsm = MagicMock()
+    itsm.create_ticket.side_effect = httpx.ConnectError("refused")
+    service.detected_findings = MagicMock(return_value=detected)
+    with (
+        patch.dict("app.services.itsm_sync_service.ITSM_CLIENTS", {ItsmConnectorType.GENERIC: Mock(return_value=itsm)}),
+        pytest.raises(ValueError, match="Failed to sync tickets with Generic"),
+    ):
+        service.sync_connector(7, client=MagicMock())
+    assert connector.last_error == "refused"
+
+
+def test_ticket_titles_fit_servicenow():
+    """Test titles carry severity and repository and are cut to the short description limit."""
+    assert ticket_title(make_finding("fnd_1")) == "[HIGH] Route with no authorization in expenses"
+    long = make_finding("fnd_1") | {"title": "x" * 200}
+    assert len(ticket_title(long)) == 160 and ticket_title(long).endswith("...")
+
+
+def test_ticket_descriptions_are_redacted():
+    """Test finding text is redacted before it goes into a ticket."""
+    finding = make_finding("fnd_1") | {"description": "Owner ana@acme.io left the route open"}
+    with patch.object(settings, "REDACTION_ENABLED", True), patch.object(settings, "REDACTION_CONFIG_PATH", ""):
+        description = ticket_description(finding)
+    assert description.startswith("Owner [REDACTED_EMAIL] left the route open\n")
+    assert "Finding key: fnd_1" in description
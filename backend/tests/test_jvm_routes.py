@@ -0,0 +1,164 @@
+"""Tests for Micronaut and Quarkus route authorization mining."""
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.jvm_route_extractor import (
+    JvmRouteKind,
+    extract_jvm_routes,
+    extract_micronaut_intercept_urls,
+    extract_micronaut_routes,
+    extract_quarkus_permissions,
+)
+
+MICRONAUT_CONTROLLER = """package com.acme.orders;
+
+import io.micronaut.security.rules.SecurityRule;
+
+@Controller("/orders")
+@Secured(SecurityRule.IS_AUTHENTICATED)
+public class OrderController {
+
+    private final OrderService service = new OrderService();
+
+    @Get("/{id}")
+    public Order show(@PathVariable String id) {
+        return service.find(id);
+    }
+
+    // @Secured("ROLE_IGNORED") in a comment does not count
+    @Post("/{id}/refund")
+    @Secured({"ROLE_FINANCE", "ROLE_ADMIN"})
+    public HttpResponse<?> refund(String id) {
+        if (id == null) { throw new IllegalArgumentException("(unbalanced"); }
+        return HttpResponse.ok();
+    }
+
+    @Get(uri = "/health")
+    @Secured(SecurityRule.IS_ANONYMOUS)
+    public String health() { return "ok"; }
+}
+"""
+
+QUARKUS_RESOURCE = """package com.acme.billing;
+
+@Path("/invoices")
+@RolesAllowed("accountant")
+public class InvoiceResource {
+
+    @GET
+    public List<Invoice> list() { return List.of(); }
+
+    @DELETE
+    @Path("{id}")
+    @RolesAllowed({"admin"})
+    public void delete(@PathParam("id") long id) {}
+
+    @GET
+    @Path("/public/summary")
+    @PermitAll
+    public Summary summary() { return null; }
+}
+
+@Path("/reports")
+class ReportResource {
+    @GET
+    public String all() { return ""; }
+
+    @POST
+    @PermissionsAllowed("reports:write")
+    public void create() {}
+}
+"""
+
+APPLICATION_PROPERTIES = """quarkus.http.auth.permission.admin.paths=/admin/*,/ops/*
+quarkus.http.auth.permission.admin.policy=admin-policy
+quarkus.http.auth.permission.admin.methods=GET,POST
+quarkus.http.auth.policy.admin-policy.roles-allowed=admin
+# Health checks stay open
+quarkus.http.auth.permission.public.paths=/q/health/*
+quarkus.http.auth.permission.public.policy=permit
+%prod.quarkus.http.auth.permission.metrics.paths=/q/metrics
+%prod.quarkus.http.auth.permission.metrics.policy=deny
+quarkus.security.jaxrs.deny-unannotated-endpoints=true
+"""
+
+APPLICATION_YML = """micronaut:
+  security:
+    intercept-url-map:
+      - pattern: /swagger/**
+        http-method: GET
+        access:
+          - isAnonymous()
+      - pattern: /admin/**
+        access:
+          - ROLE_ADMIN
+"""
+
+
+def test_micronaut_secured_overrides_controller_default():
+    """Test method @Secured overrides the controller's, and SecurityRule constants resolve."""
+    findings = {(f.action, f.resource): f for f in extract_micronaut_routes("OrderController.java", MICRONAUT_CONTROLLER)}
+
+    assert set(findings) == {("GET", "/orders/{id}"), ("POST", "/orders/{id}/refund"), ("GET", "/orders/health")}
+    show = findings[("GET", "/orders/{id}")]
+    assert show.subject == "Authenticated users"
+    assert show.description.endswith("(inherited from OrderController)")
+    assert (show.line_start, show.line_end) == (11, 12)
+
+    refund = findings[("POST", "/orders/{id}/refund")]
+    assert refund.subject == "ROLE_FINANCE or ROLE_ADMIN"
+    assert EndpointMappingService.parse_roles(refund.subject) == (["FINANCE", "ADMIN"], True)
+
+    health = findings[("GET", "/orders/health")]
+    assert (health.subject, health.disables_auth) == ("Anonymous", True)
+
+
+def test_quarkus_annotations_and_jaxrs_defaults():
+    """Test @RolesAllowed/@PermitAll/@PermissionsAllowed and deny-unannotated-endpoints."""
+    findings = extract_jvm_routes(
+        {
+            "src/main/java/InvoiceResource.java": QUARKUS_RESOURCE,
+            "src/main/resources/application.properties": "quarkus.security.jaxrs.deny-unannotated-endpoints=true\n",
+        }
+    )
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    assert all(f.kind == JvmRouteKind.QUARKUS_ROUTE for f in findings)
+    assert by_route[("GET", "/invoices")].subject == "accountant"
+    assert by_route[("DELETE", "/invoices/{id}")].subject == "admin"
+    assert by_route[("GET", "/invoices/public/summary")].subject == "Anonymous"
+    assert by_route[("GET", "/reports")].subject == "Nobody"
+    create = by_route[("POST", "/reports")]
+    assert (create.subject, create.conditions) == ("Authenticated users", "caller holds permission reports:write")
+    assert EndpointMappingService.find_routes(QUARKUS_RESOURCE) == [("DELETE", "/{id}"), ("GET", "/public/summary")]
+
+
+def test_quarkus_http_permissions_resolve_policies():
+    """Test path permissions resolve named and built-in policies per path and method."""
+    findings = extract_quarkus_permissions("application.properties", APPLICATION_PROPERTIES)
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    assert set(by_route) == {
+        ("GET", "/admin/*"),
+        ("POST", "/admin/*"),
+        ("GET", "/ops/*"),
+        ("POST", "/ops/*"),
+        ("*", "/q/health/*"),
+        ("*", "/q/metrics"),
+    }
+    admin = by_route[("GET", "/admin/*")]
+    assert admin.subject == "admin"
+    assert (admin.line_start, admin.line_end) == (1, 4)
+    assert by_route[("*", "/q/health/*")].disables_auth is True
+    metrics = by_route[("*", "/q/metrics")]
+    assert (metrics.subject, metrics.conditions) == ("Nobody", "active in the prod profile")
+
+
+def test_micronaut_intercept_url_map():
+    """Test intercept-url-map rules in application.yml become findings."""
+    findings = extract_micronaut_intercept_urls("src/main/resources/application.yml", APPLICATION_YML)
+
+    assert [(f.action, f.resource, f.subject) for f in findings] == [
+        ("GET", "/swagger/**", "Anonymous"),
+        ("*", "/admin/**", "ROLE_ADMIN"),
+    ]
+    assert findings[1].line_start == 8
+    assert extract_micronaut_intercept_urls("application.yml", "intercept-url-map: [unclosed") == []
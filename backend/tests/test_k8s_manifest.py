@@ -0,0 +1,158 @@
+"""Tests for Helm chart and Kubernetes manifest auth mining."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.application import Application
+from app.models.repository import Repository
+from app.services.k8s_manifest_service import (
+    K8sKind,
+    K8sManifestService,
+    extract_ingresses,
+    extract_opa_sidecars,
+    extract_service_account_rbac,
+    parse_documents,
+    render_helm_template,
+)
+
+INGRESS = """apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: expenses
+  annotations:
+    nginx.ingress.kubernetes.io/auth-url: https://auth.example.com/verify
+    nginx.ingress.kubernetes.io/rewrite-target: /
+spec:
+  rules:
+    - host: api.example.com
+      http:
+        paths:
+          - path: /expenses
+            pathType: Prefix
+            backend:
+              service:
+                name: expenses-api
+                port:
+                  number: 80
+"""
+
+DEPLOYMENT_TEMPLATE = """apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "expenses.fullname" . }}
+  labels:
+    {{- include "expenses.labels" . | nindent 4 }}
+spec:
+  template:
+    spec:
+      serviceAccountName: {{ include "expenses.fullname" . }}
+      containers:
+        - name: app
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+        - name: opa
+          image: openpolicyagent/opa:0.68.0-envoy
+          args:
+            - run
+            - --server
+            - --set=plugins.envoy_ext_authz_grpc.path=envoy/authz/allow
+"""
+
+RBAC = """apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: secret-reader
+  namespace: payments
+rules:
+  - apiGroups: [""]
+    resources: ["secrets"]
+    verbs: ["get", "list"]
+    resourceNames: ["db-credentials"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: expenses-secret-reader
+  namespace: payments
+subjects:
+  - kind: ServiceAccount
+    name: expenses
+roleRef:
+  kind: Role
+  name: secret-reader
+  apiGroup: rbac.authorization.k8s.io
+"""
+
+
+def test_render_helm_template_keeps_line_numbers():
+    """Test Helm actions are blanked or substituted without shifting lines."""
+    rendered = render_helm_template(DEPLOYMENT_TEMPLATE, "expenses")
+
+    assert len(rendered.splitlines()) == len(DEPLOYMENT_TEMPLATE.splitlines())
+    assert "name: expenses" in rendered
+    assert 'image: "helm-value:helm-value"' in rendered
+    assert rendered.splitlines()[5] == ""
+
+
+def test_parse_documents_tracks_line_ranges():
+    """Test multi-document files split with per-document line ranges."""
+    role, binding = parse_documents("k8s/rbac.yaml", RBAC + "---\nnot: a resource\n")
+
+    assert (role.kind, role.line_start, role.line_end) == ("Role", 1, 10)
+    assert (binding.kind, binding.line_start) == ("RoleBinding", 12)
+
+
+def test_extract_ingress_auth_per_path():
+    """Test ingress auth annotations become per-path findings for the backend service."""
+    (finding,) = extract_ingresses(parse_documents("k8s/ingress.yaml", INGRESS))
+
+    assert finding.kind == K8sKind.INGRESS_AUTH
+    assert finding.subject == "Caller approved by external auth https://auth.example.com/verify"
+    assert finding.resource == "api.example.com/expenses"
+    assert finding.service == "expenses-api"
+    assert "rewrite-target" not in finding.conditions
+
+
+def test_extract_opa_sidecar_and_rbac():
+    """Test OPA sidecars and service-account RBAC are attributed to the workload."""
+    documents = parse_documents(
+        "charts/expenses/templates/deployment.yaml", render_helm_template(DEPLOYMENT_TEMPLATE, "expenses")
+    ) + parse_documents("k8s/rbac.yaml", RBAC)
+
+    (sidecar,) = extract_opa_sidecars(documents)
+    assert sidecar.subject == "Callers allowed by OPA (envoy/authz/allow)"
+    assert sidecar.service == "expenses"
+
+    (rbac,) = extract_service_account_rbac(documents)
+    assert rbac.subject == "service account payments/expenses"
+    assert (rbac.resource, rbac.action) == ("secrets", "get, list")
+    assert rbac.conditions == "resourceNames: db-credentials"
+    assert rbac.file_path == "k8s/rbac.yaml"
+    assert rbac.service == "expenses"
+
+
+def test_scan_repository_attributes_findings_to_applications(tmp_path):
+    """Test a repository scan reads charts and merges findings under the matching application."""
+    chart = tmp_path / "7" / "charts" / "expenses"
+    (chart / "templates").mkdir(parents=True)
+    (chart / "Chart.yaml").write_text("apiVersion: v2\nname: expenses\n")
+    (chart / "values.yaml").write_text("ingress:\n  enabled: true\n")
+    (chart / "templates" / "deployment.yaml").write_text(DEPLOYMENT_TEMPLATE)
+    (tmp_path / "7" / "k8s").mkdir()
+    (tmp_path / "7" / "k8s" / "rbac.yaml").write_text(RBAC)
+
+    repo = Mock(spec=Repository, id=7, tenant_id="acme")
+    app = Mock(spec=Application, id=5)
+    app.name = "Expenses"
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    db.query.return_value.join.return_value.filter.return_value.filter.return_value.all.return_value = []
+    db.query.return_value.filter.return_value.all.side_effect = [[], [app]]
+
+    result = K8sManifestService(db, "acme", str(tmp_path)).scan_repository(7)
+
+    assert result["documents"] == 3
+    assert result["files"] == 2
+    assert result["findings_by_kind"] == {K8sKind.OPA_SIDECAR: 1, K8sKind.SERVICE_ACCOUNT_RBAC: 1}
+    assert result["services"] == ["expenses"]
+    assert result["policies_created"] == 2
+    created = [call.args[0] for call in db.add.call_args_list]
+    assert {p.application_id for p in created} == {5}
+    assert created[0].evidence[0].file_path == "charts/expenses/templates/deployment.yaml"
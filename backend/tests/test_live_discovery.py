@@ -0,0 +1,100 @@
+"""Tests for live gRPC and OpenAPI endpoint discovery."""
+import json
+from unittest.mock import MagicMock, Mock, patch
+
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.live_discovery_service import (
+    LiveDiscoveryService,
+    LiveEndpoint,
+    LiveEndpointStatus,
+    LiveProtocol,
+    fetch_openapi,
+    parse_openapi,
+    reconcile,
+)
+
+OPENAPI = {
+    "openapi": "3.0.0",
+    "servers": [{"url": "https://api.example.com/v2"}],
+    "security": [{"bearer": []}],
+    "paths": {
+        "/orders/{id}": {"get": {"operationId": "getOrder"}, "parameters": []},
+        "/health": {"get": {"security": []}},
+        "/refunds": {"post": {"operationId": "createRefund"}},
+    },
+}
+
+
+def _rule(method: str, path: str) -> EndpointRule:
+    return EndpointRule(method=method, path=path, roles=["ADMIN"], requires_authentication=True, conditions=[], policy_ids=[1])
+
+
+def test_parse_openapi_applies_base_path_and_security():
+    """Test OpenAPI 3 and Swagger 2 documents enumerate routes with declared security."""
+    endpoints = {e.key: e for e in parse_openapi(OPENAPI, "https://api.example.com/openapi.json")}
+    assert set(endpoints) == {"GET /v2/orders/{}", "GET /v2/health", "POST /v2/refunds"}
+    assert endpoints["GET /v2/orders/{}"].requires_auth is True
+    assert endpoints["GET /v2/health"].requires_auth is False
+    assert endpoints["POST /v2/refunds"].operation == "createRefund"
+
+    swagger = parse_openapi({"swagger": "2.0", "basePath": "/api/", "paths": {"/users": {"delete": {}}}}, "t")
+    assert [(e.method, e.path, e.requires_auth) for e in swagger] == [("DELETE", "/api/users", None)]
+
+
+def test_fetch_openapi_probes_well_known_paths():
+    """Test a bare service URL is probed until a document is found."""
+    client = MagicMock()
+    client.get.side_effect = lambda url, **_: (
+        Mock(status_code=200, text=json.dumps(OPENAPI)) if url.endswith("/v3/api-docs") else Mock(status_code=404)
+    )
+    endpoints = fetch_openapi("https://svc.internal", client)
+    assert len(endpoints) == 3
+    assert endpoints[0].target == "https://svc.internal/v3/api-docs"
+
+
+def test_reconcile_flags_never_seen_endpoints():
+    """Test live endpoints are classified as mined, unmined, or never seen."""
+    live = parse_openapi(OPENAPI, "doc") + [
+        LiveEndpoint(protocol=LiveProtocol.GRPC, method="POST", path="/billing.Billing/Charge", target="billing:50051",
+                     operation="Charge"),
+        LiveEndpoint(protocol=LiveProtocol.GRPC, method="POST", path="/billing.Billing/Void", target="billing:50051",
+                     operation="Void"),
+    ]
+    policy = Mock(resource="billing", action="Charge", evidence=[])
+    rules = [_rule("GET", "/orders/{id}"), _rule("DELETE", "/users/{id}")]
+
+    with patch("app.services.live_discovery_service.EndpointMappingService.map_policies", return_value=rules):
+        report = reconcile(live, [policy], {"POST /refunds": "app/refunds.py"})
+
+    statuses = {r["key"]: r["status"] for r in report["endpoints"]}
+    assert statuses == {
+        "GET /v2/orders/{}": LiveEndpointStatus.MINED,
+        "POST /v2/refunds": LiveEndpointStatus.UNMINED,
+        "GET /v2/health": LiveEndpointStatus.NEVER_SEEN,
+        "POST /billing.Billing/Charge": LiveEndpointStatus.MINED,
+        "POST /billing.Billing/Void": LiveEndpointStatus.NEVER_SEEN,
+    }
+    assert report["endpoints"][0]["status"] == LiveEndpointStatus.NEVER_SEEN
+    assert report["counts"] == {"mined": 2, "unmined": 1, "never_seen": 2}
+    assert report["mined_not_live"] == ["DELETE /users/{}"]
+    assert report["unauthenticated_live"] == ["GET /v2/health"]
+
+
+def test_discover_reports_unreachable_targets():
+    """Test one failing target does not abort discovery of the others."""
+    service = LiveDiscoveryService(MagicMock(), tenant_id="tenant-1", clone_dir="/nonexistent")
+    grpc_live = [LiveEndpoint(protocol=LiveProtocol.GRPC, method="POST", path="/a.A/Get", target="a:1", operation="Get")]
+
+    with (
+        patch("app.services.live_discovery_service.fetch_openapi", side_effect=ValueError("No OpenAPI document")),
+        patch("app.services.live_discovery_service.fetch_grpc_methods", return_value=grpc_live),
+        patch("app.services.live_discovery_service.DecisionSimulationService") as simulation,
+        patch("app.services.live_discovery_service.httpx.Client"),
+    ):
+        simulation.return_value.load_policies.return_value = []
+        result = service.discover(openapi_urls=["https://down.internal"], grpc_targets=["a:1"], repository_id=3)
+
+    assert result["errors"] == {"https://down.internal": "No OpenAPI document"}
+    assert result["live_endpoints"] == 1
+    assert result["source_routes_available"] is False
+    assert result["mined_not_live"] == []
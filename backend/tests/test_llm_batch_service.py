@@ -0,0 +1,210 @@
+"""Tests for batched LLM processing with retry and rate-limit handling."""
+from unittest.mock import AsyncMock, MagicMock, patch
+
+import pytest
+
+from app.services.llm_batch_service import (
+    LLMBatchProcessor,
+    RateLimiter,
+    get_retry_after_seconds,
+    is_retryable_llm_error,
+)
+
+
+class ProviderError(Exception):
+    """Provider error carrying an HTTP status code."""
+
+    def __init__(self, message: str, status_code: int | None = None, headers: dict | None = None):
+        """Initialize error."""
+        super().__init__(message)
+        self.status_code = status_code
+        if headers is not None:
+            self.response = MagicMock(headers=headers, status_code=status_code)
+
+
+@pytest.fixture
+def mock_provider():
+    """Create a mock LLM provider."""
+    return MagicMock()
+
+
+def make_processor(provider, **kwargs):
+    """Create a processor with no pacing or backoff delay."""
+    defaults = {
+        "max_concurrency": 2,
+        "requests_per_minute": 0,
+        "max_retries": 3,
+        "base_delay_seconds": 0,
+        "max_delay_seconds": 0,
+    }
+    defaults.update(kwargs)
+    return LLMBatchProcessor(provider, **defaults)
+
+
+class TestRetryableErrors:
+    """Tests for transient error classification."""
+
+    def test_overloaded_status_is_retryable(self):
+        """Test that 529 overloaded errors are retried."""
+        assert is_retryable_llm_error(ProviderError("Overloaded", status_code=529))
+
+    def test_rate_limit_status_is_retryable(self):
+        """Test that 429 rate-limit errors are retried."""
+        assert is_retryable_llm_error(ProviderError("Too many requests", status_code=429))
+
+    def test_client_error_is_not_retryable(self):
+        """Test that 400 errors fail immediately."""
+        assert not is_retryable_llm_error(ProviderError("Bad request", status_code=400))
+
+    def test_conflict_status_is_not_retryable(self):
+        """Test that 409 conflicts fail immediately."""
+        assert not is_retryable_llm_error(ProviderError("Conflict", status_code=409))
+
+    def test_bedrock_throttling_is_classified_by_status(self):
+        """Test that Bedrock ThrottlingException is retried for its 429 status, not its message."""
+        error = Exception("ThrottlingException: Rate exceeded")
+        error.response = {"Error": {"Code": "ThrottlingException"}, "ResponseMetadata": {"HTTPStatusCode": 429}}
+        assert is_retryable_llm_error(error)
+        assert not is_retryable_llm_error(Exception("ThrottlingException: Rate exceeded"))
+
+    def test_connection_failures_are_retryable(self):
+        """Test that timeouts and dropped connections without a response are retried."""
+        assert is_retryable_llm_error(TimeoutError("read timed out"))
+        assert is_retryable_llm_error(ConnectionResetError("connection reset by peer"))
+        assert not is_retryable_llm_error(RuntimeError("request timed out"))
+
+    def test_botocore_style_response_is_parsed(self):
+        """Test status code extraction from a botocore-style response dict."""
+        error = Exception("An error occurred")
+        error.response = {"ResponseMetadata": {"HTTPStatusCode": 503}}
+        assert is_retryable_llm_error(error)
+
+    def test_unrelated_error_is_not_retryable(self):
+        """Test that arbitrary errors are not retried."""
+        assert not is_retryable_llm_error(ValueError("Unexpected response format"))
+
+    def test_retry_after_header(self):
+        """Test Retry-After header extraction."""
+        error = ProviderError("slow down", status_code=429, headers={"retry-after": "7"})
+        assert get_retry_after_seconds(error) == 7.0
+        assert get_retry_after_seconds(Exception("no response")) is None
+
+
+@pytest.mark.asyncio
+async def test_create_message_retries_then_succeeds(mock_provider):
+    """Test that overload errors are retried until the provider recovers."""
+    mock_provider.create_message.side_effect = [
+        ProviderError("Overloaded", status_code=529),
+        ProviderError("Overloaded", status_code=529),
+        "[]",
+    ]
+    processor = make_processor(mock_provider)
+
+    response, attempts = await processor.create_message("prompt")
+
+    assert response == "[]"
+    assert attempts == 3
+    assert mock_provider.create_message.call_count == 3
+
+
+@pytest.mark.asyncio
+async def test_create_message_gives_up_after_max_retries(mock_provider):
+    """Test that retries are bounded."""
+    mock_provider.create_message.side_effect = ProviderError("Overloaded", status_code=529)
+    processor = make_processor(mock_provider, max_retries=2)
+
+    with pytest.raises(ProviderError):
+        await processor.create_message("prompt")
+
+    assert mock_provider.create_message.call_count == 3
+
+
+@pytest.mark.asyncio
+async def test_create_message_does_not_retry_permanent_errors(mock_provider):
+    """Test that non-transient errors fail on the first attempt."""
+    mock_provider.create_message.side_effect = ProviderError("Bad request", status_code=400)
+    processor = make_processor(mock_provider)
+
+    with pytest.raises(ProviderError):
+        await processor.create_message("prompt")
+
+    assert mock_provider.create_message.call_count == 1
+
+
+@pytest.mark.asyncio
+async def test_process_batch_isolates_failures(mock_provider):
+    """Test that one failing prompt does not fail the whole batch."""
+
+    def respond(prompt: str, max_tokens: int, temperature: float) -> str:
+        if prompt == "bad":
+            raise ProviderError("Bad request", status_code=400)
+        return f"response:{prompt}"
+
+    mock_provider.create_message.side_effect = respond
+    processor = make_processor(mock_provider)
+
+    results = await processor.process_batch(["a", "bad", "c"])
+
+    assert [r.index for r in results] == [0, 1, 2]
+    assert results[0].response == "response:a"
+    assert not results[1].succeeded
+    assert "Bad request" in results[1].error
+    assert results[1].attempts == 1
+    assert results[2].response == "response:c"
+
+
+@pytest.mark.asyncio
+async def test_process_batch_records_attempts_of_failed_prompts(mock_provider):
+    """Test that a prompt failing after its retries reports every attempt."""
+    mock_provider.create_message.side_effect = ProviderError("Overloaded", status_code=529)
+    processor = make_processor(mock_provider, max_retries=2)
+
+    results = await processor.process_batch(["a"])
+
+    assert not results[0].succeeded
+    assert results[0].attempts == 3
+
+
+@pytest.mark.asyncio
+async def test_process_batch_respects_concurrency_limit(mock_provider):
+    """Test that no more than max_concurrency calls run at once."""
+    import threading
+    import time
+
+    lock = threading.Lock()
+    state = {"active": 0, "peak": 0}
+
+    def respond(prompt: str, max_tokens: int, temperature: float) -> str:
+        with lock:
+            state["active"] += 1
+            state["peak"] = max(state["peak"], state["active"])
+        time.sleep(0.02)
+        with lock:
+            state["active"] -= 1
+        return "[]"
+
+    mock_provider.create_message.side_effect = respond
+    processor = make_processor(mock_provider, max_concurrency=2)
+
+    results = await processor.process_batch([str(i) for i in range(6)])
+
+    assert all(r.succeeded for r in results)
+    assert state["peak"] <= 2
+
+
+@pytest.mark.asyncio
+async def test_rate_limiter_spaces_requests():
+    """Test that the limiter enforces the minimum interval between requests."""
+    limiter = RateLimiter(requests_per_minute=1200)  # 50ms interval
+
+    with patch("app.services.llm_batch_service.asyncio.sleep", new_callable=AsyncMock) as mock_sleep:
+        await limiter.acquire()
+        await limiter.acquire()
+
+        assert mock_sleep.call_count == 1
+        assert mock_sleep.call_args[0][0] == pytest.approx(0.05, abs=0.01)
+
+
+def test_rate_limiter_disabled():
+    """Test that a zero quota disables pacing."""
+    assert RateLimiter(requests_per_minute=0).min_interval == 0.0
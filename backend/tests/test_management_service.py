@@ -0,0 +1,50 @@
+"""Tests for the management API's workspaces and repository webhooks."""
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.repository import Repository
+from app.models.tenant import Tenant
+from app.services.management_service import ManagementService
+
+
+def test_deleted_workspaces_are_reactivated_and_active_ones_conflict():
+    """Test creating a workspace again after deleting it, and over an active one."""
+    deleted = Mock(spec=Tenant, tenant_id="acme", is_active=False)
+    db = MagicMock()
+    db.query.return_value.filter.return_value.first.return_value = deleted
+    service = ManagementService(db)
+
+    tenant = service.create_workspace({"workspace_id": "acme", "name": "Acme", "description": None})
+
+    assert tenant is deleted
+    assert (tenant.is_active, tenant.name) == (True, "Acme")
+    db.add.assert_not_called()
+    with pytest.raises(ValueError, match="already exists"):
+        service.create_workspace({"workspace_id": "acme", "name": "Acme", "description": None})
+
+
+def test_webhook_secrets_are_generated_kept_and_forgotten():
+    """Test a secret is generated once, returned only when set, and cleared on delete."""
+    repository = Mock(spec=Repository, id=4, webhook_enabled=False, webhook_secret=None)
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repository
+    service = ManagementService(db, "acme")
+
+    generated = service.configure_webhook(4, enabled=True)
+    assert generated["secret"] and len(generated["secret"]) >= 32
+    assert repository.webhook_secret == generated["secret"]
+    assert generated["has_secret"] and generated["enabled"]
+    assert generated["webhook_url"] == "/api/v1/webhooks/github"
+
+    kept = service.configure_webhook(4, enabled=False)
+    assert kept["secret"] is None
+    assert repository.webhook_secret == generated["secret"]
+    assert not kept["enabled"]
+
+    assert service.configure_webhook(4, enabled=True, secret="s" * 20)["secret"] == "s" * 20
+
+    service.delete_webhook(4)
+    assert service.get_webhook(4) == {
+        "repository_id": 4, "enabled": False, "has_secret": False, "webhook_url": "/api/v1/webhooks/github", "secret": None
+    }
@@ -0,0 +1,161 @@
+"""Tests for detecting request bodies bound to models with privileged fields."""
+import shutil
+from pathlib import Path
+from unittest.mock import MagicMock, Mock
+
+from app.models.repository import Repository
+from app.services.mass_assignment_service import MassAssignmentService, detect_mass_assignment, parse_models
+
+GO_APP = Path(__file__).parent / "test_data" / "sample_apps" / "go_app.go"
+
+GO_MODELS = """package models
+
+type Expense struct {
+	ID         int     `json:"id"`
+	Amount     float64 `json:"amount"`
+	Approved   bool    `json:"approved"`
+	ApprovedBy string  `json:"-"`
+}
+
+type User struct {
+	Name    string `json:"name"`
+	IsAdmin bool   `json:"is_admin"`
+}
+"""
+
+GO_PROFILE = """func UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user := &models.User{}
+	if err := json.NewDecoder(r.Body).Decode(user); err != nil {
+		return
+	}
+	user.IsAdmin = false
+	save(user)
+}
+"""
+
+MONGOOSE = """const UserSchema = new mongoose.Schema({
+  email: String,
+  isAdmin: { type: Boolean, default: false },
+  profile: { role: String },
+});
+module.exports = mongoose.model('User', UserSchema);
+"""
+
+EXPRESS = """router.put('/users/:id', async (req, res) => {
+  const user = await User.findById(req.params.id);
+  Object.assign(user, req.body);
+  await user.save();
+});
+
+router.put('/me', async (req, res) => {
+  delete req.body.isAdmin;
+  await User.findByIdAndUpdate(req.user.id, req.body);
+});
+"""
+
+DJANGO = """class Account(models.Model):
+    email = models.EmailField()
+    is_staff = models.BooleanField(default=False)
+    is_verified = models.BooleanField(default=False)
+
+
+class AccountSerializer(serializers.ModelSerializer):
+    class Meta:
+        model = Account
+        fields = "__all__"
+        read_only_fields = ["is_verified"]
+"""
+
+JAVA_MODEL = """public class Member {
+    private String name;
+    @JsonIgnore
+    private boolean admin;
+    private String role;
+}
+"""
+
+JAVA_CONTROLLER = """@RestController
+public class MemberController {
+    @PostMapping("/members")
+    public Member create(@RequestBody Member member) {
+        return repository.save(member);
+    }
+}
+"""
+
+CSHARP_MODEL = """public class Order
+{
+    public int Id { get; set; }
+    [BindNever]
+    public bool IsApproved { get; set; }
+    public string Role { get; private set; }
+}
+"""
+
+
+def _models(*files: tuple[str, str]) -> dict:
+    return {m.name: m for path, text in files for m in parse_models(path, text)}
+
+
+def test_go_binds_reach_fields_not_excluded_or_overwritten():
+    """Test the sample CreateExpense flow, json:"-" exclusion, and fields reassigned after the bind."""
+    models = _models(("models/models.go", GO_MODELS))
+    assert (models["Expense"].fields, models["Expense"].excluded) == (["approved"], ["ApprovedBy"])
+
+    [create] = detect_mass_assignment("handlers/expenses.go", GO_APP.read_text(), models)
+    assert (create.function, create.line_start, create.model, create.model_file) == ("CreateExpense", 79, "Expense", "models/models.go")
+    assert (create.fields, create.sink, create.endpoint, create.severity) == (["approved"], "Decode", "POST /api/expenses", "medium")
+    assert create.description == (
+        "CreateExpense binds the request body to Expense with Decode() and no field filtering, so a caller can set approved"
+    )
+    assert detect_mass_assignment("handlers/profile.go", GO_PROFILE, models) == []
+
+
+def test_binds_and_exclusions_across_languages():
+    """Test Mongoose, DRF, Spring, and ASP.NET models, and fields deleted from the body before binding."""
+    models = _models(("models/user.js", MONGOOSE))
+    assert models["User"].fields == ["isAdmin"]
+    [assign] = detect_mass_assignment("routes/users.js", EXPRESS, models)
+    assert (assign.line_start, assign.sink, assign.endpoint, assign.severity) == (3, "Object.assign", "PUT /users/:id", "high")
+
+    [serializer] = detect_mass_assignment("accounts.py", DJANGO, _models(("accounts.py", DJANGO)))
+    assert (serializer.function, serializer.fields, serializer.sink) == ("AccountSerializer", ["is_staff"], "ModelSerializer")
+
+    models = _models(("Member.java", JAVA_MODEL), ("Order.cs", CSHARP_MODEL))
+    assert (models["Member"].fields, models["Member"].excluded) == (["role"], ["admin"])
+    assert (models["Order"].fields, models["Order"].excluded) == ([], ["IsApproved", "Role"])
+    [save] = detect_mass_assignment("MemberController.java", JAVA_CONTROLLER, models)
+    assert (save.function, save.line_start, save.sink, save.fields) == ("create", 5, "save", ["role"])
+
+
+def test_service_reports_candidates_per_repository(tmp_path):
+    """Test models are indexed across the clone, vendored files are skipped, and the summary counts."""
+    root = tmp_path / "6"
+    (root / "models").mkdir(parents=True)
+    (root / "handlers").mkdir()
+    (root / "vendor").mkdir()
+    (root / "models" / "user.js").write_text(MONGOOSE)
+    (root / "models" / "models.go").write_text(GO_MODELS)
+    (root / "routes.js").write_text(EXPRESS)
+    shutil.copy(GO_APP, root / "handlers" / "expenses.go")
+    (root / "vendor" / "routes.js").write_text(EXPRESS)
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 6, "expenses"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    service = MassAssignmentService(db, "acme", clone_dir=str(tmp_path))
+
+    result = service.candidates()
+    assert result["summary"] == {
+        "total": 2,
+        "repositories_scanned": 1,
+        "repositories_with_findings": 1,
+        "by_severity": {"high": 1, "medium": 1},
+    }
+    assert [(f["file_path"], f["model"], f["repository_name"]) for f in result["findings"]] == [
+        ("routes.js", "User", "expenses"),
+        ("handlers/expenses.go", "Expense", "expenses"),
+    ]
+    assert [f["severity"] for f in service.candidates(severity="MEDIUM")["findings"]] == ["medium"]
@@ -0,0 +1,279 @@
+"""Tests for NestJS guard and metadata mining."""
+from app.services.nestjs_route_extractor import NestRouteKind, extract_nestjs_routes
+
+ROLES = """import { SetMetadata } from '@nestjs/common';
+import { Role } from './role.enum';
+
+export const ROLES_KEY = 'roles';
+export const Roles = (...roles: Role[]) => SetMetadata(ROLES_KEY, roles);
+"""
+
+ROLE_ENUM = """export enum Role {
+  Admin = 'admin',
+  Auditor = 'auditor',
+}
+"""
+
+ROLES_GUARD = """import { Injectable, CanActivate, ExecutionContext } from '@nestjs/common';
+import { Reflector } from '@nestjs/core';
+import { ROLES_KEY } from './roles.decorator';
+
+@Injectable()
+export class RolesGuard implements CanActivate {
+  constructor(private reflector: Reflector) {}
+
+  canActivate(context: ExecutionContext): boolean {
+    const requiredRoles = this.reflector.getAllAndOverride<Role[]>(ROLES_KEY, [
+      context.getHandler(),
+      context.getClass(),
+    ]);
+    if (!requiredRoles) {
+      return true;
+    }
+    const { user } = context.switchToHttp().getRequest();
+    return requiredRoles.some((role) => user.roles?.includes(role));
+  }
+}
+"""
+
+CATS = """import { Controller, Get, Post, Delete, UseGuards } from '@nestjs/common';
+import { AuthGuard } from '@nestjs/passport';
+import { Roles } from './roles.decorator';
+import { RolesGuard } from './roles.guard';
+import { Role } from './role.enum';
+
+@Controller('cats')
+@UseGuards(AuthGuard('jwt'), RolesGuard)
+@Roles(Role.Auditor)
+export class CatsController {
+  @Get()
+  findAll() {}
+
+  @Post()
+  @Roles(Role.Admin)
+  create() {}
+
+  @Delete(':id')
+  @Roles(Role.Admin, 'owner')
+  remove() {}
+}
+"""
+
+
+def _routes(findings):
+    """Findings keyed by (method, path)."""
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_roles_metadata_resolves_through_the_guard_reading_it():
+    """Test @Roles metadata reaches routes through RolesGuard's getAllAndOverride read, handler over class."""
+    findings = extract_nestjs_routes(
+        {
+            "src/roles.decorator.ts": ROLES,
+            "src/role.enum.ts": ROLE_ENUM,
+            "src/roles.guard.ts": ROLES_GUARD,
+            "src/cats.controller.ts": CATS,
+            "README.md": CATS,
+        }
+    )
+
+    assert {f.kind for f in findings} == {NestRouteKind.ROUTE}
+    routes = _routes(findings)
+    assert sorted(routes) == [("DELETE", "/cats/:id"), ("GET", "/cats"), ("POST", "/cats")]
+    assert routes[("GET", "/cats")].subject == "auditor"
+    create = routes[("POST", "/cats")]
+    assert (create.subject, create.file_path, create.line_start, create.line_end) == ("admin", "src/cats.controller.ts", 14, 16)
+    assert create.description == (
+        "NestJS route guarded by AuthGuard('jwt') (@UseGuards), RolesGuard (@UseGuards) reading @Roles(Role.Admin)"
+    )
+    assert routes[("DELETE", "/cats/:id")].subject == "admin or owner"
+
+
+PUBLIC = """import { SetMetadata } from '@nestjs/common';
+
+export const IS_PUBLIC_KEY = 'isPublic';
+export const Public = () => SetMetadata(IS_PUBLIC_KEY, true);
+"""
+
+JWT_GUARD = """import { ExecutionContext, Injectable } from '@nestjs/common';
+import { Reflector } from '@nestjs/core';
+import { AuthGuard } from '@nestjs/passport';
+import { IS_PUBLIC_KEY } from './public.decorator';
+
+@Injectable()
+export class JwtAuthGuard extends AuthGuard('jwt') {
+  constructor(private reflector: Reflector) {
+    super();
+  }
+
+  canActivate(context: ExecutionContext) {
+    const isPublic = this.reflector.getAllAndOverride<boolean>(IS_PUBLIC_KEY, [
+      context.getHandler(),
+      context.getClass(),
+    ]);
+    if (isPublic) {
+      return true;
+    }
+    return super.canActivate(context);
+  }
+}
+"""
+
+APP_MODULE = """import { Module } from '@nestjs/common';
+import { APP_GUARD } from '@nestjs/core';
+import { JwtAuthGuard } from './auth/jwt-auth.guard';
+
+@Module({
+  providers: [
+    {
+      provide: APP_GUARD,
+      useClass: JwtAuthGuard,
+    },
+  ],
+})
+export class AppModule {}
+"""
+
+MAIN = """import { NestFactory } from '@nestjs/core';
+import { AppModule } from './app.module';
+
+async function bootstrap() {
+  const app = await NestFactory.create(AppModule);
+  app.setGlobalPrefix('api');
+  await app.listen(3000);
+}
+bootstrap();
+"""
+
+HEALTH = """import { Controller, Get } from '@nestjs/common';
+import { Public } from './auth/public.decorator';
+
+@Controller({ path: 'health' })
+export class HealthController {
+  @Get()
+  @Public()
+  check() {}
+
+  @Get('details')
+  details() {}
+}
+"""
+
+
+def test_global_guard_applies_unless_a_public_flag_bypasses_it():
+    """Test an APP_GUARD authentication guard covers every route, and @Public() metadata it reads opens a route."""
+    findings = extract_nestjs_routes(
+        {
+            "src/main.ts": MAIN,
+            "src/app.module.ts": APP_MODULE,
+            "src/auth/public.decorator.ts": PUBLIC,
+            "src/auth/jwt-auth.guard.ts": JWT_GUARD,
+            "src/health.controller.ts": HEALTH,
+        }
+    )
+
+    routes = _routes(findings)
+    details = routes[("GET", "/api/health/details")]
+    assert (details.subject, details.disables_auth) == ("Authenticated users", False)
+    assert details.description == "NestJS route guarded by JwtAuthGuard (APP_GUARD)"
+    check = routes[("GET", "/api/health")]
+    assert (check.subject, check.disables_auth) == ("Anonymous", True)
+    assert check.description == "NestJS route open to anyone: @Public() lets it past JwtAuthGuard (APP_GUARD)"
+
+
+ADMIN_GUARD = """import { CanActivate, ExecutionContext, Injectable } from '@nestjs/common';
+
+@Injectable()
+export class AdminGuard implements CanActivate {
+  canActivate(context: ExecutionContext): boolean {
+    const request = context.switchToHttp().getRequest();
+    return request.user?.role === 'admin';
+  }
+}
+
+@Injectable()
+export class SignatureGuard implements CanActivate {
+  canActivate(context: ExecutionContext): boolean {
+    return verifySignature(context.switchToHttp().getRequest());
+  }
+}
+"""
+
+AUTH_DECORATOR = """import { applyDecorators, SetMetadata, UseGuards } from '@nestjs/common';
+import { Reflector } from '@nestjs/core';
+
+export const Permissions = Reflector.createDecorator<string[]>();
+
+export function Auth(...permissions: string[]) {
+  return applyDecorators(Permissions(permissions), UseGuards(JwtAuthGuard, PermissionsGuard));
+}
+"""
+
+PERMISSIONS_GUARD = """import { CanActivate, ExecutionContext, Injectable } from '@nestjs/common';
+import { Reflector } from '@nestjs/core';
+import { Permissions } from './auth.decorator';
+
+@Injectable()
+export class PermissionsGuard implements CanActivate {
+  constructor(private reflector: Reflector) {}
+
+  canActivate(context: ExecutionContext): boolean {
+    const permissions = this.reflector.get(Permissions, context.getHandler());
+    if (!permissions) {
+      return true;
+    }
+    return matchPermissions(permissions, context.switchToHttp().getRequest().user);
+  }
+}
+"""
+
+REPORTS = """import { Controller, Get, Post, UseGuards } from '@nestjs/common';
+import { AdminGuard, SignatureGuard } from './admin.guard';
+import { Auth } from './auth.decorator';
+import { ThrottlerGuard } from '@nestjs/throttler';
+
+@Controller('reports')
+@UseGuards(ThrottlerGuard)
+export class ReportsController {
+  @Get()
+  @Auth('reports:read')
+  list() {}
+
+  @Post('purge')
+  @UseGuards(AdminGuard)
+  purge() {}
+
+  @Post('webhook')
+  @UseGuards(SignatureGuard)
+  webhook() {}
+
+  @Get('open')
+  open() {}
+}
+"""
+
+
+def test_custom_can_activate_guards_and_composed_decorators():
+    """Test hardcoded CanActivate checks, applyDecorators compositions, and createDecorator metadata keys."""
+    findings = extract_nestjs_routes(
+        {
+            "src/admin.guard.ts": ADMIN_GUARD,
+            "src/auth.decorator.ts": AUTH_DECORATOR,
+            "src/permissions.guard.ts": PERMISSIONS_GUARD,
+            "src/reports.controller.ts": REPORTS,
+        }
+    )
+
+    routes = _routes(findings)
+    # ThrottlerGuard limits rates rather than deciding access
+    assert ("GET", "/reports/open") not in routes
+    listing = routes[("GET", "/reports")]
+    assert listing.subject == "Authenticated users"
+    assert listing.conditions == "requires permission reports:read"
+    assert listing.description == (
+        "NestJS route guarded by JwtAuthGuard (@Auth('reports:read')), "
+        "PermissionsGuard (@Auth('reports:read')) reading @Auth('reports:read')"
+    )
+    assert routes[("POST", "/reports/purge")].subject == "admin"
+    webhook = routes[("POST", "/reports/webhook")]
+    assert webhook.conditions == "passes SignatureGuard.canActivate"
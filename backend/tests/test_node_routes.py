@@ -0,0 +1,153 @@
+"""Tests for Koa and Hapi route authorization mining."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.framework_route_service import FrameworkRouteService
+from app.services.node_route_extractor import NodeRouteKind, extract_hapi_routes, extract_node_routes
+
+HAPI_SERVER = """const Hapi = require('@hapi/hapi');
+const server = Hapi.server({ port: 3000 });
+server.auth.strategy('jwt', 'jwt', { key: process.env.KEY });
+server.auth.default('jwt');
+"""
+
+HAPI_ROUTES = """// Gateway routes
+module.exports = [
+  {
+    method: 'GET',
+    path: '/health',
+    options: { auth: false },
+    handler: () => 'ok',
+  },
+  {
+    method: ['PUT', 'PATCH'],
+    path: '/orders/{id}',
+    options: {
+      auth: { access: { scope: ['admin', '+finance', 'user-{params.id}'] } },
+      handler(request, h) { return h.response(); },
+    },
+  },
+  { method: 'GET', path: '/me', handler: (request) => request.auth.credentials },
+  { method: 'POST', path: '/webhooks', options: { auth: { strategy: 'hmac', mode: 'try' } }, handler },
+];
+"""
+
+KOA_APP = """const Koa = require('koa');
+const Router = require('@koa/router');
+const compose = require('koa-compose');
+const jwt = require('koa-jwt');
+const users = require('./routes/users');
+
+const app = new Koa();
+const api = new Router({ prefix: '/api' });
+const adminOnly = compose([requireRole('admin'), audit()]);
+
+app.use(bodyParser());
+app.use(jwt({ secret: process.env.SECRET }).unless({ path: ['/api/login', /^\\/api\\/public/] }));
+api.post('/login', login);
+api.get('/public/docs', docs);
+api.delete('/tenants/:id', adminOnly, removeTenant);
+api.use('/users', users.routes(), users.allowedMethods());
+app.use(api.routes());
+"""
+
+KOA_USERS = """const Router = require('@koa/router');
+const router = new Router();
+router.get('/', list);
+router.post('/:id/roles', checkRole('owner', 'admin'), assign);
+module.exports = router;
+"""
+
+
+def test_hapi_routes_resolve_options_auth_and_server_default():
+    """Test Hapi route auth, scopes, and modes resolve against the server default."""
+    findings = extract_node_routes({"server.js": HAPI_SERVER, "routes/gateway.js": HAPI_ROUTES})
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    assert set(by_route) == {
+        ("GET", "/health"),
+        ("PUT", "/orders/{id}"),
+        ("PATCH", "/orders/{id}"),
+        ("GET", "/me"),
+        ("POST", "/webhooks"),
+    }
+    assert all(f.kind == NodeRouteKind.HAPI_ROUTE for f in findings)
+
+    health = by_route[("GET", "/health")]
+    assert (health.subject, health.disables_auth) == ("Anonymous", True)
+
+    orders = by_route[("PUT", "/orders/{id}")]
+    assert orders.subject == "admin"
+    assert orders.conditions == "caller has scope finance; scope user-{params.id} matches the request"
+    assert "strategy jwt" in orders.description
+    assert orders.line_start == 9 and "path: '/orders/{id}'" in orders.snippet
+
+    me = by_route[("GET", "/me")]
+    assert me.subject == "Authenticated users"
+    assert "inherited from server.auth.default" in me.description
+    assert by_route[("POST", "/webhooks")].subject == "Anonymous"
+
+
+def test_hapi_routes_without_auth_or_default_are_skipped():
+    """Test unguarded routes produce no findings when no server default exists."""
+    findings = extract_hapi_routes("routes/gateway.js", HAPI_ROUTES)
+
+    assert [(f.action, f.resource) for f in findings] == [
+        ("GET", "/health"),
+        ("PUT", "/orders/{id}"),
+        ("PATCH", "/orders/{id}"),
+        ("POST", "/webhooks"),
+    ]
+    # Single-method routes are also inventoried for coverage
+    assert EndpointMappingService.find_routes(HAPI_ROUTES) == [
+        ("GET", "/health"),
+        ("GET", "/me"),
+        ("POST", "/webhooks"),
+    ]
+
+
+def test_koa_routes_resolve_composed_middleware_across_mounts():
+    """Test Koa guards resolve through compose(), .unless(), and routers mounted from other files."""
+    findings = extract_node_routes({"app.js": KOA_APP, "routes/users.js": KOA_USERS})
+    by_route = {(f.action, f.resource): f for f in findings}
+
+    # /api/login and /api/public/* are excluded from koa-jwt, and carry no other guard
+    assert set(by_route) == {("DELETE", "/api/tenants/:id"), ("GET", "/api/users"), ("POST", "/api/users/:id/roles")}
+    assert all(f.kind == NodeRouteKind.KOA_ROUTE for f in findings)
+    assert by_route[("DELETE", "/api/tenants/:id")].subject == "admin"
+    assert by_route[("GET", "/api/users")].subject == "Authenticated users"
+    assert by_route[("GET", "/api/users")].file_path == "routes/users.js"
+    roles = by_route[("POST", "/api/users/:id/roles")]
+    assert roles.subject == "owner or admin"
+    assert roles.description == "Koa route guarded by jwt({ secret: process.env.SECRET }), checkRole('owner', 'admin')"
+
+
+def test_scan_repository_merges_routes_without_duplicating_evidence(tmp_path):
+    """Test a rescan corroborates a mined policy once and only replaces its own policies."""
+    (tmp_path / "4" / "routes").mkdir(parents=True)
+    (tmp_path / "4" / "server.js").write_text(HAPI_SERVER)
+    (tmp_path / "4" / "routes" / "gateway.js").write_text(HAPI_ROUTES)
+    (tmp_path / "4" / "node_modules").mkdir()
+    (tmp_path / "4" / "node_modules" / "dep.js").write_text(HAPI_ROUTES)
+
+    mined = Policy(id=11, repository_id=4, subject="Authenticated users", resource="/me", action="GET")
+    mined.evidence.append(Evidence(file_path="routes/gateway.js", line_start=17, line_end=17, code_snippet="x"))
+    repo = Mock(spec=Repository, id=4, tenant_id="acme")
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    previous = db.query.return_value.join.return_value.filter.return_value.filter.return_value
+    previous.filter.return_value.all.return_value = []
+    db.query.return_value.filter.return_value.all.side_effect = [[mined], []]
+
+    result = FrameworkRouteService(db, "acme", str(tmp_path)).scan_repository(4)
+
+    assert result["findings_by_kind"] == {NodeRouteKind.HAPI_ROUTE: 5}
+    assert result["files"] == 1
+    assert result["policies_created"] == 4
+    assert result["policies_merged"] == 1
+    assert len(mined.evidence) == 1
+    previous.filter.assert_called_once()
+    created = [call.args[0] for call in db.add.call_args_list]
+    assert all(p.description.endswith("(from framework route config)") for p in created)
@@ -0,0 +1,123 @@
+"""Tests for embedded OPA query detection and Rego policy import."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.repository import Repository
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.opa_query_extractor import extract_opa_queries, find_queries
+from app.services.opa_query_service import OPA_QUERY_LABEL, OpaQueryService
+
+REGO = """package httpapi.authz
+
+import rego.v1
+
+default allow := false
+
+allow if {
+    "admin" in input.user.roles
+}
+
+can_export if {
+    input.user.role == "auditor"
+    input.method == "GET"
+}
+"""
+
+GO_SERVER = """package main
+
+func main() {
+	r := gin.Default()
+	r.GET("/reports/:id", func(c *gin.Context) {
+		query, _ := rego.New(
+			rego.Query("data.httpapi.authz.allow"),
+			rego.Load([]string{"policies"}, nil),
+		).PrepareForEval(c)
+		c.JSON(200, nil)
+	})
+	r.DELETE("/reports/:id", func(c *gin.Context) {
+		decision, err := opa.Decision(c, sdk.DecisionOptions{
+			Path:  "/httpapi/authz/allow",
+			Input: input(c),
+		})
+		c.Status(204)
+	})
+}
+"""
+
+FLASK = """OPA_URL = os.environ["OPA_URL"]
+
+
+@app.get("/exports")
+def export():
+    decision = requests.post(f"{OPA_URL}/v1/data/httpapi/authz/can_export", json={"input": build_input()})
+    return jsonify(rows())
+
+
+def audit():
+    return requests.post(f"{OPA_URL}/v1/data/audit/allow", json={})
+"""
+
+
+def test_queries_are_found_in_each_style_and_attributed_to_routes():
+    """Test rego.Query, opa.Decision, and data API calls, and the handler route each is made from."""
+    rego, sdk = find_queries("cmd/main.go", GO_SERVER)
+    assert (rego.style, rego.path, rego.line, rego.route, rego.route_line) == ("rego", "httpapi/authz/allow", 7, ("GET", "/reports/:id"), 5)
+    assert (sdk.style, sdk.path, sdk.line, sdk.route) == ("sdk", "httpapi/authz/allow", 13, ("DELETE", "/reports/:id"))
+
+    export, audit = find_queries("app.py", FLASK)
+    assert (export.style, export.path, export.route, export.route_line) == ("http", "httpapi/authz/can_export", ("GET", "/exports"), 4)
+    assert (audit.path, audit.route) == ("audit/allow", None)
+    assert find_queries("util.py", "def query(db):\n    return db.query(User)\n") == []
+
+
+def test_queried_rules_map_the_querying_endpoints():
+    """Test resolution to the longest package, one finding per querying route, and unresolved paths."""
+    scan = extract_opa_queries({"cmd/main.go": GO_SERVER, "app.py": FLASK}, {"policies/authz.rego": REGO, "policies/authz_test.rego": REGO})
+
+    assert scan.unresolved == ["audit/allow"]
+    assert scan.rego_files == ["policies/authz.rego"]
+    [query] = [q for q in scan.queries if q.style == "http" and q.package]
+    assert (query.package, query.rule) == ("httpapi.authz", "can_export")
+
+    delete, get, export = sorted(scan.findings, key=lambda f: (f.line_start, f.action))
+    assert (get.subject, get.resource, get.action, get.line_start) == ("admin", "/reports/:id", "GET", 7)
+    assert get.description == "OPA allow rule in package httpapi.authz queried at cmd/main.go:7"
+    assert get.related_evidence == [("cmd/main.go", 5, 7, 'r.GET("/reports/:id", func(c *gin.Context) {\nrego.Query("data.httpapi.authz.allow"),')]
+    assert (delete.resource, delete.action) == ("/reports/:id", "DELETE")
+    # A rule that names its own method keeps it
+    assert (export.subject, export.resource, export.action, export.line_start) == ("auditor", "/exports", "GET", 11)
+
+    policies = []
+    for finding in scan.findings:
+        policy = Mock(subject=finding.subject, resource=finding.resource, action=finding.action, conditions=None, id=None)
+        policy.evidence = [Mock(file_path=p, line_start=s, code_snippet=c) for p, s, _, c in finding.related_evidence]
+        policies.append(policy)
+    assert sorted(r.key for r in EndpointMappingService.map_policies(policies)) == sorted(
+        ["GET /exports", "GET /reports/:id", "DELETE /reports/:id"]
+    )
+
+
+def test_scan_repository_imports_queried_rules(tmp_path):
+    """Test clone reading skips vendored files and new policies are labelled with the source."""
+    root = tmp_path / "9"
+    (root / "cmd").mkdir(parents=True)
+    (root / "policies").mkdir()
+    (root / "vendor").mkdir()
+    (root / "cmd" / "main.go").write_text(GO_SERVER)
+    (root / "policies" / "authz.rego").write_text(REGO)
+    (root / "vendor" / "client.go").write_text(GO_SERVER.replace("/reports", "/vendored"))
+
+    repo = Mock(spec=Repository, id=9, tenant_id="acme")
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    db.query.return_value.join.return_value.filter.return_value.filter.return_value.filter.return_value.all.return_value = []
+    db.query.return_value.filter.return_value.all.return_value = []
+
+    result = OpaQueryService(db, "acme", str(tmp_path)).scan_repository(9)
+
+    assert (result["queries"], result["routes"], result["rules"]) == (2, 2, 2)
+    assert result["packages"] == ["httpapi.authz"]
+    assert result["unresolved_paths"] == []
+    assert result["policies_created"] == 2
+    created = [call.args[0] for call in db.add.call_args_list]
+    assert all(p.description.endswith(f"(from {OPA_QUERY_LABEL})") for p in created)
+    assert [(e.file_path, e.line_start) for e in created[0].evidence] == [("policies/authz.rego", 7), ("cmd/main.go", 5)]
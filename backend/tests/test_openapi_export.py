@@ -0,0 +1,128 @@
+"""Tests for annotating OpenAPI documents with mined authorization."""
+import pytest
+
+from app.services.openapi_export_service import OpenApiExportService, openapi_path, parse_spec
+
+SPEC = """
+openapi: 3.0.3
+info: {title: Expenses, version: "2"}
+servers:
+  - url: https://expenses.example.com/api
+security:
+  - corpOAuth: [expenses]
+components:
+  securitySchemes:
+    corpOAuth:
+      type: oauth2
+      flows: {clientCredentials: {tokenUrl: https://auth.example.com/token, scopes: {expenses: Expenses}}}
+paths:
+  /expenses/{expenseId}/approve:
+    put:
+      operationId: approveExpense
+      x-required-roles: [STALE]
+  /expenses/{expenseId}:
+    get: {operationId: getExpense}
+  /reports:
+    get: {operationId: listReports}
+    parameters: []
+  /rates:
+    get: {operationId: getRates, x-required-roles: [STALE]}
+"""
+
+
+@pytest.fixture
+def policies(make_policy):
+    """Policies mined from the expense, rate, and audit routes."""
+    return [
+        make_policy(
+            1, "MANAGER", "approve",
+            "router.put('/api/expenses/:id/approve', passport.authenticate('jwt'), requireRole('MANAGER'), approve)",
+        ),
+        make_policy(
+            2, "DIRECTOR", "approve",
+            "router.put('/api/expenses/{id}/approve', passport.authenticate('jwt'), requireRole('DIRECTOR'), approve)",
+        ),
+        make_policy(3, "authenticated", "read", "router.get('/api/expenses/:id', requireApiKey, show)"),
+        make_policy(4, "anyone", "read", "app.get('/api/rates', rates)", resource="Rate"),
+        make_policy(5, "ADMIN", "read", "app.get('/api/audit', audit)", confidence=40.0),
+        make_policy(6, "AUDITOR", "delete", "app.delete('/api/expenses/:id', remove)"),
+    ]
+
+
+def test_parse_spec_and_paths():
+    """Test only OpenAPI 3 documents are accepted and route parameters become templates."""
+    assert parse_spec(SPEC)["openapi"] == "3.0.3"
+    assert parse_spec('{"openapi": "3.1.0", "paths": {}}')["paths"] == {}
+    with pytest.raises(ValueError):
+        parse_spec('{"swagger": "2.0", "paths": {}}')
+    with pytest.raises(ValueError):
+        parse_spec("- not a document")
+    assert openapi_path("/api/expenses/:id/approve") == "/api/expenses/{id}/approve"
+    assert openapi_path("/files/<int:file_id>/") == "/files/{file_id}"
+
+
+def test_annotates_a_supplied_spec_with_schemes_and_roles(policies, make_export_service):
+    """Test matching below the server base path, scheme reuse and addition, and public operations."""
+    spec = parse_spec(SPEC)
+    result = make_export_service(OpenApiExportService, policies).export(4, spec=spec, min_confidence=50)
+    document = result["spec"]
+    paths = document["paths"]
+
+    approve = paths["/expenses/{expenseId}/approve"]["put"]
+    assert approve["security"] == [{"corpOAuth": []}]  # Existing OAuth scheme; 3.0 lists no roles
+    assert approve["x-required-roles"] == ["MANAGER", "DIRECTOR"]
+
+    show = paths["/expenses/{expenseId}"]["get"]
+    assert show["security"] == [{"apiKeyAuth": []}] and "x-required-roles" not in show
+    assert document["components"]["securitySchemes"]["apiKeyAuth"] == {
+        "type": "apiKey", "in": "header", "name": "X-API-Key",
+    }
+
+    rates = paths["/rates"]["get"]
+    assert rates["security"] == [] and "x-required-roles" not in rates
+    assert paths["/reports"]["get"] == {"operationId": "listReports"}
+    assert spec["paths"]["/rates"]["get"]["x-required-roles"] == ["STALE"]  # Input left untouched
+
+    assert result["generated"] is False
+    assert result["unmatched_operations"] == ["GET /reports"]
+    assert result["unmatched_endpoints"] == ["DELETE /api/expenses/{}"]
+    assert [o["mechanisms"] for o in result["operations"]] == [["jwt_bearer"], ["api_key"], ["none"]]
+    assert result["operations"][0]["mined_policy_ids"] == [1, 2]
+    assert result["summary"] == {
+        "policies": 5,
+        "operations_annotated": 3,
+        "public_operations": 1,
+        "unmatched_operations": 1,
+        "unmatched_endpoints": 1,
+        "security_schemes": 2,
+        "below_min_confidence": 1,
+    }
+
+
+def test_generates_an_openapi_31_spec_with_roles_in_requirements(policies, make_export_service):
+    """Test a generated document has an operation per mined endpoint, with roles listed on its schemes."""
+    result = make_export_service(OpenApiExportService, policies).export(4)
+    document = result["spec"]
+    assert document["openapi"] == "3.1.0" and document["info"]["title"] == "Expense API"
+    assert sorted(document["paths"]) == [
+        "/api/audit", "/api/expenses/{id}", "/api/expenses/{id}/approve", "/api/rates",
+    ]
+
+    approve = document["paths"]["/api/expenses/{id}/approve"]["put"]
+    assert approve["operationId"] == "put_api_expenses_id_approve"
+    assert approve["parameters"] == [{"name": "id", "in": "path", "required": True, "schema": {"type": "string"}}]
+    assert approve["security"] == [{"bearerAuth": ["MANAGER", "DIRECTOR"]}]
+
+    remove = document["paths"]["/api/expenses/{id}"]["delete"]
+    assert remove["security"] == [{"bearerAuth": ["AUDITOR"]}]  # No mechanism in the evidence
+    assert remove["x-required-roles"] == ["AUDITOR"]
+    assert set(document["components"]["securitySchemes"]) == {"bearerAuth", "apiKeyAuth"}
+    assert result["generated"] is True and result["unmatched_operations"] == []
+    assert result["unmatched_endpoints"] == []
+
+
+def test_empty_repository_generates_an_empty_spec(make_export_service):
+    """Test a repository without approved policies generates a document without operations."""
+    result = make_export_service(OpenApiExportService, []).export(4)
+    assert result["spec"]["paths"] == {} and "components" not in result["spec"]
+    assert result["operations"] == []
@@ -0,0 +1,116 @@
+"""Tests for CODEOWNERS and blame ownership attribution."""
+from unittest.mock import Mock, patch
+
+from app.core.config import settings
+from app.models.ownership import OwnershipSource, PolicyOwnership
+from app.models.policy import Evidence, Policy, PolicyStatus
+from app.models.policy_change import PolicyChange, WorkItem, WorkItemStatus
+from app.models.repository import Repository
+from app.services.ownership_service import BlameSummary, OwnershipService, match_owners, parse_codeowners
+
+CODEOWNERS = """\
+# Default owners
+*                      @acme/platform
+/services/payments/    @acme/payments pay-lead@acme.com  # money paths
+*.sql                  @acme/dba
+docs/*                 @acme/writers
+/services/payments/legacy/
+
+[Security]
+**/auth/**             @acme/security
+"""
+
+
+def make_policy(policy_id, file_path, status=PolicyStatus.PENDING):
+    """Create a policy with one evidence location."""
+    policy = Mock(spec=Policy)
+    policy.id, policy.repository_id, policy.status = policy_id, 3, status
+    evidence = Mock(spec=Evidence)
+    evidence.file_path, evidence.line_start, evidence.line_end = file_path, 10, 14
+    policy.evidence = [evidence]
+    return policy
+
+
+def make_ownership(policy_id, owners, repository_id=3):
+    """Create a stored ownership attribution."""
+    ownership = Mock(spec=PolicyOwnership)
+    ownership.policy_id, ownership.repository_id, ownership.owners = policy_id, repository_id, owners
+    return ownership
+
+
+def test_codeowners_last_matching_rule_wins_with_gitignore_patterns():
+    """Test anchoring, directory, extension, and double-star patterns."""
+    rules = parse_codeowners(CODEOWNERS)
+
+    def owners(path):
+        rule = match_owners(rules, path)
+        return rule.owners if rule else None
+
+    assert owners("services/payments/api.py") == ["@acme/payments", "pay-lead@acme.com"]
+    assert owners("services/payments/legacy/old.py") == []
+    assert owners("services/payments/migrate.sql") == ["@acme/dba"]
+    assert owners("docs/guide.md") == ["@acme/writers"]
+    assert owners("api/docs/guide.md") == ["@acme/platform"]
+    assert owners("./src/auth/login.js") == ["@acme/security"]
+    assert owners(".github/workflows/ci.yml") == ["@acme/platform"]
+    assert match_owners(parse_codeowners("/services/ @acme/services"), "README.md") is None
+
+
+def test_attribute_uses_codeowners_then_blame(tmp_path, make_db):
+    """Test CODEOWNERS decides owners where it matches and blame elsewhere."""
+    clone = tmp_path / "3"
+    (clone / ".git").mkdir(parents=True)
+    (clone / ".github").mkdir()
+    (clone / ".github" / "CODEOWNERS").write_text("/services/payments/ @acme/payments\n")
+    owned, blamed, done = make_policy(1, "services/payments/api.py"), make_policy(2, "lib/util.py"), make_policy(4, "x.py")
+    db = make_db({Repository: [Mock(spec=Repository, id=3)], Policy: [owned, blamed, done], PolicyOwnership: [make_ownership(4, [])]})
+    blame = BlameSummary(authors=["dev@acme.com", "ops@acme.com"], last_commit="a" * 40)
+
+    # Blame is stubbed in this process, so the pass runs here rather than in the scan sandbox
+    with (
+        patch.object(settings, "SCAN_SANDBOX_ENABLED", False),
+        patch("app.services.ownership_service.blame_lines", return_value=blame) as blame_lines,
+    ):
+        assert OwnershipService(db, "acme", str(tmp_path)).attribute(3) == 2
+
+    added = {c.args[0].policy_id: c.args[0] for c in db.add.call_args_list}
+    assert set(added) == {1, 2}
+    assert (added[1].owners, added[1].source, added[1].codeowners_file) == (["@acme/payments"], OwnershipSource.CODEOWNERS, ".github/CODEOWNERS")
+    assert added[1].codeowners_pattern == "/services/payments/" and added[1].authors == blame.authors
+    assert (added[2].owners, added[2].source, added[2].codeowners_pattern) == (["dev@acme.com"], OwnershipSource.BLAME, None)
+    blame_lines.assert_any_call(clone, "lib/util.py", 10, 14)
+    db.commit.assert_called_once()
+
+
+def test_route_work_items_assigns_first_owner_of_the_changed_policy(make_db):
+    """Test unassigned work items go to the owner of the policy their change touched."""
+    items = [Mock(spec=WorkItem, policy_change_id=11, assigned_to=None), Mock(spec=WorkItem, policy_change_id=12, assigned_to=None)]
+    changes = [Mock(spec=PolicyChange, id=11, policy_id=1, previous_policy_id=None), Mock(spec=PolicyChange, id=12, policy_id=None, previous_policy_id=9)]
+    db = make_db({PolicyOwnership: [make_ownership(1, ["@acme/payments", "@acme/platform"])], WorkItem: items, PolicyChange: changes})
+
+    assert OwnershipService(db, "acme").route_work_items(3) == 1
+    assert (items[0].assigned_to, items[1].assigned_to) == ("@acme/payments", None)
+
+
+def test_teams_summarize_ownership_and_open_work(make_db):
+    """Test per-team counts, with unowned policies collected separately."""
+    db = make_db(
+        {
+            PolicyOwnership: [
+                make_ownership(1, ["@acme/payments"]),
+                make_ownership(2, ["@acme/payments", "@acme/dba"], repository_id=5),
+                make_ownership(3, []),
+            ],
+            Policy: [make_policy(1, "a"), make_policy(2, "b", PolicyStatus.APPROVED), make_policy(3, "c")],
+            WorkItem: [Mock(spec=WorkItem, assigned_to="@acme/payments", status=WorkItemStatus.OPEN)],
+        }
+    )
+
+    teams = OwnershipService(db, "acme").teams()
+
+    assert [(t["team"], t["policies"], t["pending_policies"], t["open_work_items"]) for t in teams] == [
+        ("@acme/payments", 2, 1, 1),
+        ("@acme/dba", 1, 0, 0),
+        (None, 1, 1, 0),
+    ]
+    assert teams[0]["repository_ids"] == [3, 5]
@@ -0,0 +1,146 @@
+"""Tests for migrating inline authorization checks to PDP calls."""
+from datetime import UTC, datetime
+from unittest.mock import MagicMock, Mock
+
+from app.models.pdp_migration import CallSiteStatus, MigrationCallSite, MigrationProgressSnapshot
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.pdp_migration_service import (
+    PdpMigrationService,
+    call_site_status,
+    cedar_query,
+    fingerprint,
+    opa_query,
+    replacement_snippet,
+)
+
+CHECK = """router.post("/expenses/:id/approve", async (req, res) => {
+  if (req.user.role !== "MANAGER") {
+    return res.status(403).json({ error: "forbidden" });
+  }
+  await approve(req.params.id);
+});"""
+
+MIGRATED = """const { isAllowed } = require("./authz/pdp");
+
+router.post("/expenses/:id/approve", async (req, res) => {
+  if (!(await isAllowed(req.user, "approve", "expense", req.path))) {
+    return res.status(403).json({ error: "forbidden" });
+  }
+  await approve(req.params.id);
+});"""
+
+
+def approve_policy() -> Mock:
+    """An approved policy with a conditional rule."""
+    return Mock(
+        spec=Policy,
+        id=7,
+        application_id=None,
+        subject="MANAGER",
+        action="approve",
+        resource="expense",
+        conditions="amount < 5000 and user.department == request.department",
+    )
+
+
+def call_site(policy: Mock) -> MigrationCallSite:
+    """A call site for the inline check in CHECK."""
+    return MigrationCallSite(
+        repository_id=3,
+        fingerprint=fingerprint("src/expenses.js", CHECK),
+        file_path="src/expenses.js",
+        line_start=10,
+        line_end=15,
+        language="javascript",
+        check_snippet=CHECK,
+        replacement=replacement_snippet("javascript", policy),
+        status=CallSiteStatus.PENDING,
+    )
+
+
+def test_queries_and_replacement_per_call_site():
+    """Test checks map to OPA and Cedar queries carrying their condition attributes, and to stub calls."""
+    policy = approve_policy()
+
+    assert opa_query(policy) == {
+        "input": {
+            "user": {"id": "<caller id>", "roles": ["<caller roles>"], "department": "<caller department>"},
+            "action": "approve",
+            "resource": {"type": "expense", "path": "<request path>", "amount": "<amount>", "department": "<department>"},
+        }
+    }
+    cedar = cedar_query(policy)
+    assert (cedar["action"], cedar["resource"]) == ('Action::"approve"', 'ResourceType::"expense"')
+    assert cedar["context"] == {
+        "path": "<request path>",
+        "amount": "<amount>",
+        "department": "<department>",
+        "principal_department": "<caller department>",
+    }
+
+    snippet = replacement_snippet("javascript", policy)
+    assert 'await isAllowed(req.user, "approve", "expense", req.path)' in snippet
+    assert snippet.startswith("// The condition amount < 5000 and user.department == request.department needs amount, department")
+    assert "authz.IsAllowed(r.Context(), user, \"approve\", \"expense\"" in replacement_snippet("go", policy)
+    assert replacement_snippet(None, policy) is None
+
+    # The fingerprint ignores where the check sits and how it is indented
+    assert fingerprint("src/expenses.js", CHECK) == fingerprint("src/expenses.js", "\n\n" + CHECK.replace("  ", "    "))
+
+
+def test_call_site_status_follows_the_code():
+    """Test a check is pending while present, migrated once replaced by a PDP call, and removed otherwise."""
+    site = call_site(approve_policy())
+
+    assert call_site_status(site, f"// header\n\n{CHECK}\n") == (CallSiteStatus.PENDING, 4)
+    # The replacement keeps the 403 response line, which alone does not mean the check is still there
+    assert call_site_status(site, MIGRATED) == (CallSiteStatus.MIGRATED, None)
+    assert call_site_status(site, CHECK.replace('  if (req.user.role !== "MANAGER") {\n', "  {\n")) == (
+        CallSiteStatus.REMOVED,
+        None,
+    )
+    assert call_site_status(site, None) == (CallSiteStatus.REMOVED, None)
+
+
+def test_refresh_records_progress_over_time(tmp_path):
+    """Test refreshes re-check call sites against the clone and snapshots chart the percentage per bucket."""
+    policy = approve_policy()
+    migrated, pending = call_site(policy), call_site(policy)
+    pending.file_path = "src/reports.js"
+    (tmp_path / "3" / "src").mkdir(parents=True)
+    (tmp_path / "3" / "src" / "expenses.js").write_text(MIGRATED)
+    (tmp_path / "3" / "src" / "reports.js").write_text(f"\n{CHECK}")
+
+    db = MagicMock()
+    service = PdpMigrationService(db, tenant_id="acme", clone_dir=str(tmp_path))
+    service.get_repository = MagicMock(return_value=Mock(spec=Repository, id=3))
+    service.sync_call_sites = MagicMock(return_value=[migrated, pending])
+
+    snapshot = service.refresh(3, scan_id=12)
+
+    assert (snapshot.total_call_sites, snapshot.migrated_call_sites, snapshot.migrated_percent) == (2, 1, 50.0)
+    assert migrated.status == CallSiteStatus.MIGRATED and migrated.migrated_at is not None
+    assert (pending.status, pending.line_start, pending.line_end) == (CallSiteStatus.PENDING, 3, 8)
+    db.commit.assert_called_once()
+
+    def taken(day, total, done, repository_id=3):
+        return MigrationProgressSnapshot(
+            repository_id=repository_id,
+            total_call_sites=total,
+            migrated_call_sites=done,
+            removed_call_sites=0,
+            captured_at=datetime(2026, 3, day, tzinfo=UTC),
+        )
+
+    service._query = MagicMock()
+    query = service._query.return_value
+    query.filter.return_value = query
+    # Monday 2 and Wednesday 4 share a week, so the later snapshot counts
+    query.order_by.return_value.all.return_value = [taken(2, 4, 0), taken(4, 4, 1), taken(4, 6, 6, repository_id=5), taken(9, 4, 3)]
+
+    series = service.progress_series()
+    assert [(p["bucket"], p["repositories"], p["migrated_percent"]) for p in series] == [
+        ("2026-03-02", 2, 70.0),
+        ("2026-03-09", 1, 75.0),
+    ]
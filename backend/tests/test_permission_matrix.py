@@ -0,0 +1,75 @@
+"""Tests for the role-permission matrix."""
+from unittest.mock import MagicMock, Mock, patch
+
+import pytest
+
+from app.models.policy import Policy
+from app.services.permission_matrix_service import CellAccess, PermissionMatrixService
+
+
+def make_policy(policy_id, subject, resource, action, conditions=None):
+    """Create a policy."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = resource
+    policy.action = action
+    policy.conditions = conditions
+    return policy
+
+
+@pytest.fixture
+def policies():
+    """Policies resembling the sample expense app."""
+    return [
+        make_policy(1, "MANAGER or DIRECTOR", "Expense", "approve", "amount > 5000 requires DIRECTOR"),
+        make_policy(2, "DIRECTOR", "Expense", "Approve"),
+        make_policy(3, "ADMIN", "Expense", "delete"),
+        make_policy(4, "Authenticated users", "Expense", "view"),
+        make_policy(5, "anonymous", "Health", "view"),
+    ]
+
+
+def build(policies, **kwargs):
+    """Build a matrix over the given policies."""
+    service = PermissionMatrixService(MagicMock(), tenant_id="tenant-1")
+    with patch(
+        "app.services.permission_matrix_service.DecisionSimulationService.load_policies",
+        return_value=policies,
+    ):
+        return service.build(**kwargs)
+
+
+def test_aggregate_cells(policies):
+    """Test that cells merge policies and track conditional access."""
+    cells = {(c.role, c.resource, c.action): c for c in PermissionMatrixService.aggregate(policies)}
+
+    assert cells[("MANAGER", "Expense", "approve")].access == CellAccess.CONDITIONAL
+    assert cells[("MANAGER", "Expense", "approve")].conditions == ["amount > 5000 requires DIRECTOR"]
+    # An unconditional grant for the same cell makes it a plain allow
+    assert cells[("DIRECTOR", "Expense", "approve")].access == CellAccess.ALLOW
+    assert cells[("DIRECTOR", "Expense", "approve")].policy_ids == [1, 2]
+    assert ("authenticated", "Expense", "view") in cells
+    assert ("anonymous", "Health", "view") in cells
+
+
+def test_matrix_pagination_keeps_columns(policies):
+    """Test that role pages share the full column set."""
+    first = build(policies, skip=0, limit=2)
+    second = build(policies, skip=2, limit=2)
+
+    assert first["total_roles"] == 5
+    assert first["roles"] == ["ADMIN", "DIRECTOR"]
+    assert second["roles"] == ["MANAGER", "anonymous"]
+    assert first["columns"] == second["columns"]
+    assert {c["role"] for c in first["cells"]} == {"ADMIN", "DIRECTOR"}
+
+
+def test_matrix_filters(policies):
+    """Test role, action, and conditional filters."""
+    conditional = build(policies, conditional_only=True)
+    assert [(c["role"], c["action"]) for c in conditional["cells"]] == [("MANAGER", "approve")]
+
+    deletes = build(policies, action="DEL")
+    assert deletes["roles"] == ["ADMIN"]
+    assert deletes["columns"] == [{"resource": "Expense", "action": "delete"}]
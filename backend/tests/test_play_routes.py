@@ -0,0 +1,159 @@
+"""Tests for Play Framework action composition mining."""
+from app.services.endpoint_mapping_service import EndpointMappingService
+from app.services.play_route_extractor import PlayRouteKind, extract_play_routes, resolve_routes
+
+ROUTES = """# Routes
+GET     /                   controllers.HomeController.index
+GET     /orders/:id         controllers.OrderController.show(id: Long)
++ nocsrf
+POST    /orders/:id/refund  controllers.OrderController.refund(id: Long)
+DELETE  /orders/$id<[0-9]+> controllers.OrderController.delete(id: Long)
+GET     /assets/*file       controllers.Assets.versioned(path="/public", file: Asset)
+
+->      /admin              admin.Routes
+"""
+
+ADMIN_ROUTES = """GET   /audit     controllers.AdminController.audit
+POST  /users     controllers.AdminController.createUser
+"""
+
+ACTIONS = """package actions
+
+import javax.inject.Inject
+import play.api.mvc._
+
+class UserRequest[A](val user: User, request: Request[A]) extends WrappedRequest[A](request)
+
+// Rejects callers without a session
+class AuthenticatedAction @Inject()(parser: BodyParsers.Default)(implicit ec: ExecutionContext)
+    extends ActionBuilderImpl(parser) {
+  override def invokeBlock[A](request: Request[A], block: Request[A] => Future[Result]) =
+    request.session.get("user") match {
+      case Some(_) => block(request)
+      case None => Future.successful(Unauthorized)
+    }
+}
+
+class AdminFilter @Inject()(implicit val executionContext: ExecutionContext) extends ActionFilter[UserRequest] {
+  def filter[A](request: UserRequest[A]) = Future.successful {
+    if (request.user.roles.contains("admin")) None else Some(Forbidden)
+  }
+}
+
+case class PermissionCheck(permission: String) extends ActionFilter[UserRequest]
+"""
+
+CONTROLLERS = """package controllers
+
+class HomeController @Inject()(cc: ControllerComponents) extends AbstractController(cc) {
+  def index = Action { implicit request =>
+    Ok(views.html.index())
+  }
+}
+
+class OrderController @Inject()(
+    cc: ControllerComponents,
+    authAction: AuthenticatedAction,
+    adminFilter: AdminFilter
+) extends AbstractController(cc) with Secured {
+
+  private val adminAction = authAction andThen adminFilter
+
+  def show(id: Long) = authAction.async { implicit request =>
+    Future.successful(Ok("{}"))
+  }
+
+  def refund(id: Long) = (authAction andThen PermissionCheck("orders:refund")) { implicit request =>
+    Ok
+  }
+
+  def delete(id: Long) = adminAction { implicit request => NoContent }
+
+  def export = withRole("finance") { user => implicit request =>
+    Ok(csv)
+  }
+}
+
+class AdminController @Inject()(silhouette: Silhouette[Env], cc: ControllerComponents) extends AbstractController(cc) {
+  def audit = silhouette.SecuredAction(WithRole("auditor")).async { implicit request =>
+    Future.successful(Ok)
+  }
+
+  def createUser = deadbolt.Restrict(List(Array("admin")))() { implicit request =>
+    Future.successful(Created)
+  }
+}
+"""
+
+FILES = {
+    "conf/routes": ROUTES,
+    "conf/admin.routes": ADMIN_ROUTES,
+    "app/actions/Actions.scala": ACTIONS,
+    "app/controllers/Controllers.scala": CONTROLLERS,
+}
+
+
+def test_routes_files_resolve_includes_and_path_parameters():
+    """Test "->" includes prefix sub-routers and Play path parameters normalize."""
+    routes = resolve_routes({p: t for p, t in FILES.items() if p.startswith("conf/")})
+
+    assert [(r.verb, r.path, r.action) for r in routes] == [
+        ("GET", "/", "index"),
+        ("GET", "/orders/{id}", "show"),
+        ("POST", "/orders/{id}/refund", "refund"),
+        ("DELETE", "/orders/{id}", "delete"),
+        ("GET", "/assets/{file}", "versioned"),
+        ("GET", "/admin/audit", "audit"),
+        ("POST", "/admin/users", "createUser"),
+    ]
+    assert routes[2].modifiers == ["nocsrf"]
+    assert EndpointMappingService.find_routes(ADMIN_ROUTES) == [("GET", "/audit"), ("POST", "/users")]
+
+
+def test_action_builders_and_compositions_resolve_to_subjects():
+    """Test custom builders, andThen compositions, and injected actions resolve per route."""
+    findings = {(f.action, f.resource): f for f in extract_play_routes(FILES) if f.kind == PlayRouteKind.PLAY_ROUTE}
+
+    index = findings[("GET", "/")]
+    assert (index.subject, index.disables_auth) == ("Anonymous", True)
+
+    show = findings[("GET", "/orders/{id}")]
+    assert show.subject == "Authenticated users"
+    assert show.file_path == "app/controllers/Controllers.scala"
+    assert (show.line_start, show.line_end) == (17, 19)
+    assert show.description.endswith("routed by conf/routes:3")
+
+    refund = findings[("POST", "/orders/{id}/refund")]
+    assert refund.subject == "Authenticated users"
+    assert refund.conditions == "caller holds permission orders:refund; CSRF check disabled (+nocsrf)"
+
+    # adminAction = authAction andThen adminFilter; the filter checks roles.contains("admin")
+    assert findings[("DELETE", "/orders/{id}")].subject == "admin"
+    assert ("GET", "/assets/{file}") not in findings
+
+
+def test_secured_wrappers_and_library_actions():
+    """Test withRole wrappers, Silhouette WithRole, and Deadbolt Restrict."""
+    findings = extract_play_routes(FILES)
+    by_resource = {f.resource: f for f in findings}
+
+    assert by_resource["/admin/audit"].subject == "auditor"
+    assert by_resource["/admin/users"].subject == "admin"
+    assert EndpointMappingService.parse_roles(by_resource["/admin/users"].subject) == (["ADMIN"], True)
+
+    export = by_resource["OrderController.export"]
+    assert (export.kind, export.action, export.subject) == (PlayRouteKind.PLAY_ACTION, "*", "finance")
+    assert export.description.endswith("not mapped by any routes file")
+
+
+def test_unbalanced_and_unknown_sources_are_skipped():
+    """Test malformed Scala and unknown controllers yield no findings instead of errors."""
+    files = {
+        "conf/routes": "GET /x controllers.Missing.run\nGET /y controllers.Broken.run\n",
+        "app/Broken.scala": 'class Broken extends Controller {\n  def run = authAction { "unterminated\n',
+    }
+
+    findings = extract_play_routes(files)
+
+    assert [(f.resource, f.subject) for f in findings] == [("/y", "Authenticated users")]
+    assert extract_play_routes({"app/Empty.scala": "def = = {{{ ((("}) == []
@@ -0,0 +1,83 @@
+"""Tests for inline policy annotations."""
+from app.services.policy_annotation_extractor import (
+    AnnotationKind,
+    AnnotationStatus,
+    extract_annotation_findings,
+    extract_annotations,
+)
+
+GO_HANDLERS = """package expenses
+
+// policy: role=MANAGER resource=expense action=create
+r.POST("/expenses", createExpense)
+
+// policy: role=MANAGER,FINANCE resource=expense action=approve when="amount < 5000"
+// policy: permission=expenses:audit resource=expense action=approve
+func approve(c *gin.Context) {
+	approver.Approve(c)
+}
+
+// Handlers below check their own policy: see docs/authz.md
+func list(c *gin.Context) {}
+"""
+
+STALE = """@RestController
+public class LedgerController {
+    /* policy: role=AUDITOR resource=ledger action=read */
+    @PreAuthorize("hasRole('ADMIN')")
+    @GetMapping("/ledger")
+    public Ledger read() { return ledger; }
+
+    // policy: role=ADMIN resource=ledger action=delete
+    @GetMapping("/reports")
+    public Report report() { return report; }
+}
+# policy: role=ops resource=cache action=flush
+"""
+
+
+def test_annotations_declare_rules_for_the_code_below_them():
+    """Test roles, permissions, and conditions are mined, stacked annotations share code, and prose is ignored."""
+    annotations, findings = extract_annotation_findings({"expenses/handlers.go": GO_HANDLERS})
+
+    assert [a.line for a in annotations] == [3, 6, 7]
+    assert all(a.status == AnnotationStatus.VALID for a in annotations)
+    assert [a.target_line for a in annotations] == [4, 8, 8]
+    assert all(f.kind == AnnotationKind.POLICY for f in findings)
+    create, approve, audit = findings
+    assert (create.subject, create.resource, create.action) == ("MANAGER", "expense", "create")
+    assert create.description == 'Inline policy annotation on r.POST("/expenses", createExpense)'
+    assert approve.subject == "MANAGER or FINANCE"
+    assert approve.conditions == "amount < 5000"
+    assert audit.subject == "Authenticated users"
+    assert audit.conditions == "requires permission expenses:audit"
+
+
+def test_annotations_contradicting_their_code_are_flagged_stale():
+    """Test a different role check, a route not fitting the action or resource, and a dangling annotation."""
+    annotations = extract_annotations("LedgerController.java", STALE)
+
+    assert [a.status for a in annotations] == [AnnotationStatus.STALE] * 3
+    assert annotations[0].problems == ["code checks role ADMIN, not AUDITOR"]
+    assert annotations[1].problems == [
+        "action delete does not fit the route GET /reports",
+        "resource ledger does not appear in the route GET /reports",
+    ]
+    assert annotations[2].problems == ["annotates no code"]
+    # Stale annotations are still mined, with the reason in the description
+    _, findings = extract_annotation_findings({"LedgerController.java": STALE})
+    assert findings[0].description.endswith("flagged stale: code checks role ADMIN, not AUDITOR")
+
+
+def test_malformed_annotations_are_reported_and_not_mined():
+    """Test unknown keys, missing fields, and stray text make an annotation invalid."""
+    source = "# policy: role=admin resourse=report action=export\n# policy: resource=report action=read extra\nrun()\n"
+    annotations, findings = extract_annotation_findings({"jobs/export.py": source})
+
+    assert findings == []
+    assert [a.status for a in annotations] == [AnnotationStatus.INVALID] * 2
+    assert annotations[0].problems == [
+        "unknown key 'resourse'; use role, permission, resource, action, or when",
+        "resource is required",
+    ]
+    assert annotations[1].problems == ["unparsed text 'extra'", "a role or permission is required"]
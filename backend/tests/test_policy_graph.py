@@ -0,0 +1,91 @@
+"""Tests for the policy graph."""
+import time
+from unittest.mock import MagicMock, Mock, patch
+
+import pytest
+
+from app.models.policy import Policy
+from app.services.policy_graph_service import GraphDetail, NodeType, PolicyGraphService, node_id
+
+
+def make_policy(policy_id, subject, resource, action, conditions=None):
+    """Create a policy."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = resource
+    policy.action = action
+    policy.conditions = conditions
+    return policy
+
+
+@pytest.fixture
+def policies():
+    """Policies resembling the sample expense app."""
+    return [
+        make_policy(1, "MANAGER or DIRECTOR", "Expense", "approve", "amount > 5000 requires DIRECTOR"),
+        make_policy(2, "ADMIN", "Expense", "delete"),
+        make_policy(3, "ADMIN", "User Account", "delete"),
+    ]
+
+
+def build(policies, **kwargs):
+    """Build a graph over the given policies."""
+    service = PolicyGraphService(MagicMock(), tenant_id="tenant-1")
+    with patch(
+        "app.services.policy_graph_service.DecisionSimulationService.load_policies",
+        return_value=policies,
+    ):
+        return service.build(**kwargs)
+
+
+def test_standard_graph(policies):
+    """Test role -> permission -> resource edges with stable IDs."""
+    graph = build(policies)
+    node_ids = {n["id"] for n in graph["nodes"]}
+    edges = {e["id"]: e for e in graph["edges"]}
+
+    assert "subject:manager" in node_ids
+    assert "permission:approve:expense" in node_ids
+    assert "resource:user-account" in node_ids
+    assert edges["subject:manager->permission:approve:expense"]["conditional"] is True
+    assert edges["permission:delete:expense->resource:expense"]["conditional"] is False
+    assert not any(n["type"] == NodeType.CONDITION for n in graph["nodes"])
+    # IDs are deterministic across builds
+    assert build(policies) == graph
+
+
+def test_levels_of_detail(policies):
+    """Test that summary collapses permissions and full adds conditions."""
+    summary = build(policies, detail=GraphDetail.SUMMARY)
+    assert {n["type"] for n in summary["nodes"]} == {NodeType.SUBJECT, NodeType.RESOURCE}
+    assert any(e["id"] == "subject:admin->resource:expense" for e in summary["edges"])
+
+    full = build(policies, detail=GraphDetail.FULL)
+    condition = node_id(NodeType.CONDITION, "amount > 5000 requires DIRECTOR")
+    assert condition in {n["id"] for n in full["nodes"]}
+    when = next(e for e in full["edges"] if e["target"] == condition)
+    assert when["policy_ids"] == [1]
+
+    with pytest.raises(ValueError):
+        build(policies, detail="everything")
+
+
+def test_large_graph_truncates_within_budget():
+    """Test that a 10k+ edge graph builds quickly and honors the edge budget."""
+    policies = [
+        make_policy(i, f"ROLE_{i % 100}", f"Resource {i}", f"action{i % 7}")
+        for i in range(6_000)
+    ]
+
+    start = time.perf_counter()
+    graph = build(policies)
+    assert time.perf_counter() - start < 10
+    assert graph["edge_count"] > 10_000
+    assert graph["truncated"] is False
+
+    limited = build(policies, max_edges=1_000)
+    assert limited["truncated"] is True
+    assert limited["edge_count"] <= 1_000
+    connected = {e["source"] for e in limited["edges"]} | {e["target"] for e in limited["edges"]}
+    assert {n["id"] for n in limited["nodes"]} == connected
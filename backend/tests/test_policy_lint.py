@@ -0,0 +1,123 @@
+"""Tests for the organizational policy lint engine."""
+from unittest.mock import Mock, patch
+
+import pytest
+
+from app.models.lint import LintCheck, LintRule, LintRun
+from app.models.policy import Evidence, Policy, SourceType
+from app.models.repository import Repository
+from app.services.policy_lint_service import PolicyLintService, gate, lint_endpoints
+
+
+def make_policy(policy_id, subject, action, snippet, source_type=SourceType.BACKEND):
+    """Create a policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id, policy.subject, policy.resource, policy.action = policy_id, subject, "Order", action
+    policy.conditions, policy.description, policy.source_type = None, None, source_type
+    evidence = Mock(spec=Evidence)
+    evidence.code_snippet, evidence.file_path, evidence.line_start = snippet, "routes.js", policy_id
+    policy.evidence = [evidence]
+    return policy
+
+
+def make_rule(name, check, params=None, severity="medium", enabled=True, blocking=True):
+    """Create a stored lint rule."""
+    rule = Mock(spec=LintRule)
+    rule.name, rule.description, rule.check, rule.params = name, None, check, params or {}
+    rule.severity, rule.enabled, rule.blocking = severity, enabled, blocking
+    return rule
+
+
+POLICIES = [
+    make_policy(1, "Authenticated users", "delete", "app.delete('/api/orders/:id', requireSession, remove)\n// req.session.user"),
+    make_policy(2, "ADMIN", "create", "app.post('/api/orders', passport.authenticate('jwt'), requireRole('ADMIN'))"),
+    make_policy(
+        3, "MANAGER", "update", "if (user.role === 'MANAGER') api.put('/api/orders/:id')", source_type=SourceType.FRONTEND
+    ),
+    make_policy(4, "Anonymous", "view", "app.get('/api/catalog', list)"),
+]
+
+
+def test_default_rules_flag_mutating_endpoints_without_roles_and_frontend_only_checks(make_db):
+    """Test the defaults apply when a workspace defines no rules."""
+    db = make_db({Repository: [Mock(spec=Repository, id=7)], LintRule: []})
+    with patch("app.services.policy_lint_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        report = PolicyLintService(db, "acme").lint(7)
+
+    found = {(v["rule"], v["endpoint"]) for v in report["violations"]}
+    assert found == {
+        ("mutating-endpoints-require-role", "DELETE /api/orders/:id"),
+        ("no-frontend-only-enforcement", "PUT /api/orders/:id"),
+    }
+    assert (report["rules_evaluated"], report["endpoints_evaluated"]) == (2, 4)
+    assert report["violation_counts"] == {"high": 2}
+    assert report["passed"] is False
+
+
+def test_custom_rules_respect_scope_mechanisms_and_forbidden_roles():
+    """Test path scopes, exclusions, and per-check params."""
+    rules = [
+        {"name": "auth", "check": LintCheck.REQUIRE_AUTHENTICATION, "params": {"paths": ["/api/**"]}, "severity": "critical"},
+        {
+            "name": "jwt-only",
+            "check": LintCheck.ALLOWED_MECHANISMS,
+            "params": {"methods": ["POST", "DELETE"], "mechanisms": ["jwt_bearer"]},
+            "severity": "medium",
+        },
+        {"name": "no-admin", "check": LintCheck.FORBIDDEN_ROLES, "params": {"roles": ["admin"]}, "severity": "low"},
+        {"name": "scoped-out", "check": LintCheck.REQUIRE_ROLE, "params": {"exclude_paths": ["/api/**"]}, "severity": "high"},
+    ]
+
+    violations, _ = lint_endpoints(rules, POLICIES)
+
+    assert [(v["rule"], v["endpoint"]) for v in violations] == [
+        ("auth", "GET /api/catalog"),
+        ("jwt-only", "DELETE /api/orders/:id"),
+        ("no-admin", "POST /api/orders"),
+    ]
+    assert violations[1]["message"] == "DELETE /api/orders/:id authenticates with cookie_session"
+    assert violations[0]["policy_ids"] == [4]
+
+
+def test_gate_fails_only_on_blocking_violations_at_or_above_threshold(make_db):
+    """Test fail_on and the blocking switch decide the CI outcome."""
+    violations = [
+        {"severity": "high", "blocking": False},
+        {"severity": "medium", "blocking": True},
+    ]
+    assert gate(violations, "high") == []
+    assert gate(violations, "medium") == [violations[1]]
+
+    db = make_db({Repository: [Mock(spec=Repository, id=7)], LintRule: [make_rule("advisory", LintCheck.REQUIRE_ROLE, blocking=False)]})
+    with patch("app.services.policy_lint_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        report = PolicyLintService(db, "acme").lint(7, fail_on="low")
+    assert report["violations"] and report["passed"] is True
+
+    with pytest.raises(ValueError):
+        PolicyLintService(db, "acme").lint(7, fail_on="severe")
+    with pytest.raises(ValueError):
+        PolicyLintService(make_db({}), "acme").lint(99)
+
+
+def test_create_rule_validates_params_and_record_run_persists_results(make_db):
+    """Test invalid rules are rejected and scans store their lint run."""
+    service = PolicyLintService(make_db({}), "acme")
+    with pytest.raises(ValueError, match="mechanisms"):
+        service.create_rule("jwt-only", LintCheck.ALLOWED_MECHANISMS)
+    with pytest.raises(ValueError, match="Unknown auth mechanisms"):
+        service.create_rule("jwt-only", LintCheck.ALLOWED_MECHANISMS, {"mechanisms": ["oauth-ish"]})
+    with pytest.raises(ValueError, match="Severity"):
+        service.create_rule("role", LintCheck.REQUIRE_ROLE, severity="urgent")
+
+    taken = make_db({LintRule: [make_rule("role", LintCheck.REQUIRE_ROLE)]})
+    with pytest.raises(ValueError, match="already exists"):
+        PolicyLintService(taken, "acme").create_rule("role", LintCheck.REQUIRE_ROLE)
+
+    db = make_db({Repository: [Mock(spec=Repository, id=7)], LintRule: []})
+    with patch("app.services.policy_lint_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        run = PolicyLintService(db, "acme").record_run(7, scan_id=31)
+
+    assert isinstance(run, LintRun)
+    assert (run.tenant_id, run.repository_id, run.scan_id, len(run.violations)) == ("acme", 7, 31, 2)
+    db.add.assert_called_once_with(run)
+    db.commit.assert_called_once()
@@ -0,0 +1,97 @@
+"""Tests for policy mutation testing."""
+from unittest.mock import MagicMock
+
+import pytest
+
+from app.services.authz_test_generation_service import AuthzTestGenerationService
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.policy_mutation_service import (
+    MutationHarness,
+    MutationOperator,
+    PolicyMutationService,
+)
+
+
+@pytest.fixture
+def rules():
+    """Endpoint rules resembling the sample expense app."""
+    return [
+        EndpointRule(method="GET", path="/api/expenses", roles=[]),
+        EndpointRule(method="DELETE", path="/api/expenses/{id}", roles=["ADMIN"]),
+        EndpointRule(
+            method="PUT",
+            path="/api/expenses/{id}/approve",
+            roles=["MANAGER", "DIRECTOR"],
+            conditions=["amount > 5000 requires DIRECTOR"],
+        ),
+    ]
+
+
+@pytest.fixture
+def service(rules):
+    """Create a service backed by the sample rules."""
+    service = PolicyMutationService(MagicMock(), tenant_id="tenant-1")
+    service.test_generation_service.load_endpoint_rules = MagicMock(return_value=(MagicMock(), rules))
+    return service
+
+
+def test_flip_threshold():
+    """Test boundary mutation of numeric conditions."""
+    assert PolicyMutationService.flip_threshold("amount > 5000") == "amount >= 5000"
+    assert PolicyMutationService.flip_threshold("amount <= $1,000") == "amount < $1,000"
+    assert PolicyMutationService.flip_threshold("department is Finance") is None
+
+
+def test_generate_mutants(rules):
+    """Test that each applicable operator produces a mutant."""
+    mutants = PolicyMutationService.generate_mutants(rules)
+    operators = {(m.endpoint, m.operator) for m in mutants}
+
+    assert ("DELETE /api/expenses/{id}", MutationOperator.DROP_ROLE) in operators
+    assert ("DELETE /api/expenses/{id}", MutationOperator.ADD_ROLE) in operators
+    assert ("GET /api/expenses", MutationOperator.MAKE_PUBLIC) in operators
+    assert ("PUT /api/expenses/{id}/approve", MutationOperator.FLIP_THRESHOLD) in operators
+    assert ("PUT /api/expenses/{id}/approve", MutationOperator.REMOVE_CONDITION) in operators
+    # Originals are never modified by mutation
+    assert rules[2].conditions == ["amount > 5000 requires DIRECTOR"]
+    assert rules[1].roles == ["ADMIN"]
+
+
+def test_role_mutants_killed_condition_mutants_survive(rules):
+    """Test that generated cases catch role changes but not untested conditions."""
+    mutants = PolicyMutationService.generate_mutants(rules)
+    PolicyMutationService.evaluate_mutants(mutants, AuthzTestGenerationService.build_cases(rules))
+
+    for mutant in mutants:
+        if mutant.endpoint == "PUT /api/expenses/{id}/approve" and mutant.operator in (
+            MutationOperator.DROP_ROLE,
+            MutationOperator.FLIP_THRESHOLD,
+            MutationOperator.REMOVE_CONDITION,
+        ):
+            # Allowed cases on conditional endpoints are skipped, so narrowing
+            # the endpoint or changing its condition goes unnoticed
+            assert not mutant.killed, mutant.description
+        else:
+            assert mutant.killed, mutant.description
+            assert mutant.killed_by
+
+
+def test_score_repository(service):
+    """Test the strength score for generated suites."""
+    report = service.score_repository(1)
+
+    assert report["harness"] == MutationHarness.GENERATED_TESTS
+    assert report["total_mutants"] == report["killed"] + report["survived"]
+    assert report["survived"] == 4
+    assert report["strength_score"] == pytest.approx(report["killed"] / report["total_mutants"] * 100, abs=0.1)
+    assert report["by_operator"][MutationOperator.FLIP_THRESHOLD] == {"total": 1, "killed": 0}
+
+
+def test_probe_harness_scores_lower(service):
+    """Test that the probe harness cannot kill mutants on unsafe methods."""
+    tests_report = service.score_repository(1, MutationHarness.GENERATED_TESTS)
+    probe_report = service.score_repository(1, MutationHarness.PROBE)
+
+    assert probe_report["strength_score"] < tests_report["strength_score"]
+    surviving = {m["endpoint"] for m in probe_report["mutants"] if not m["killed"]}
+    assert "DELETE /api/expenses/{id}" in surviving
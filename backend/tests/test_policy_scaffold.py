@@ -0,0 +1,143 @@
+"""Tests for policy-as-code scaffolding pull requests."""
+import base64
+from unittest.mock import AsyncMock, MagicMock, Mock, patch
+
+import pytest
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.models.scaffold_pull_request import ScaffoldFormat, ScaffoldPullRequestStatus
+from app.services.policy_scaffold_service import (
+    GitHubPullRequests,
+    PolicyScaffoldService,
+    integration_stub,
+    parse_remote,
+    policy_files,
+    scaffold_readme,
+    stub_language,
+)
+
+REGO = """package authz
+
+allow {
+    input.user.role == "MANAGER"
+    input.action == "approve"
+}"""
+
+
+def approve_policy() -> Mock:
+    """An approved policy mined from two inline checks."""
+    evidence = [
+        Mock(spec=Evidence, file_path="src/expenses.js", line_start=12, line_end=14),
+        Mock(spec=Evidence, file_path="src/reports.js", line_start=40, line_end=40),
+    ]
+    return Mock(
+        spec=Policy, id=7, subject="Manager", action="approve", resource="expense", conditions="amount < 5000", evidence=evidence
+    )
+
+
+def test_scaffold_files_per_format():
+    """Test Rego scaffolds are OPA bundles, Cedar ones a policy set, and stubs target the right PDP."""
+    rego = policy_files({7: REGO}, ScaffoldFormat.REGO, "scaffold-1")
+    assert set(rego) == {"policy/.manifest", "policy/policy_miner/main.rego", "policy/policy_miner/policies/policy_7.rego"}
+    assert rego["policy/policy_miner/policies/policy_7.rego"].startswith("package policy_miner.policies.policy_7\n")
+
+    translations = {9: 'permit(principal, action == Action::"view", resource);', 7: "forbid(principal, action, resource);"}
+    cedar = policy_files(translations, ScaffoldFormat.CEDAR, "scaffold-1")
+    assert list(cedar) == ["policy/policies.cedar"]
+    assert cedar["policy/policies.cedar"].index("// Policy 7") < cedar["policy/policies.cedar"].index("// Policy 9")
+
+    path, stub = integration_stub("javascript", ScaffoldFormat.REGO)
+    assert path == "authz/pdp.js"
+    assert "process.env.OPA_URL" in stub and "decision.result === true" in stub
+    path, stub = integration_stub("go", ScaffoldFormat.CEDAR)
+    assert path == "authz/pdp.go"
+    assert '"CEDAR_AGENT_URL"' in stub and 'decision.Decision == "Allow"' in stub
+    assert "@@" not in integration_stub("java", ScaffoldFormat.CEDAR)[1]
+    with pytest.raises(ValueError, match="No integration stub for 'ruby'"):
+        integration_stub("ruby", ScaffoldFormat.REGO)
+
+    repo = Mock(spec=Repository)
+    repo.name = "expenses"
+    readme = scaffold_readme(repo, [approve_policy()], ScaffoldFormat.CEDAR, "authz/pdp.js")
+    assert "| 7 | Manager may approve expense when amount < 5000 | src/expenses.js:12-14, src/reports.js:40-40 |" in readme
+    assert "`$CEDAR_AGENT_URL`" in readme
+
+
+def test_remote_parsing_and_stub_language():
+    """Test GitHub/GitLab remotes are recognized and the stub follows the service's main language."""
+    assert parse_remote("https://github.com/acme/expenses.git") == ("github", "https://github.com", "acme/expenses")
+    assert parse_remote("git@gitlab.acme.io:finance/apps/expenses.git") == (
+        "gitlab", "https://gitlab.acme.io", "finance/apps/expenses"
+    )
+    with pytest.raises(ValueError, match="GitHub or GitLab"):
+        parse_remote("https://bitbucket.org/acme/expenses")
+    with pytest.raises(ValueError):
+        parse_remote(None)
+
+    assert GitHubPullRequests(MagicMock(), "https://github.acme.io", "a/b", "t").api == "https://github.acme.io/api/v3"
+
+    express = {"src/app.js": "const express = require('express');\nconst app = express();\n", "tools/gen.py": "print(1)\n"}
+    assert stub_language(express) == "javascript"
+    assert stub_language({"main.go": "package main\n", "util.go": "package main\n", "x.py": "x = 1\n"}) == "go"
+    assert stub_language({"README.md": "# docs"}) is None
+
+
+@pytest.mark.asyncio
+async def test_open_pull_request_on_github():
+    """Test the pull request commits every file to a new branch and failures are recorded."""
+    repo = Mock(spec=Repository, id=3, source_url="https://github.com/acme/expenses", connection_config={"token": "ghp"})
+    repo.name = "expenses"
+    service = PolicyScaffoldService(MagicMock(), tenant_id="acme")
+    service.get_repository = MagicMock(return_value=repo)
+    service.scope_policies = MagicMock(return_value=[approve_policy()])
+
+    client = MagicMock()
+    responses = [
+        {"default_branch": "main"},
+        {"object": {"sha": "abc123"}},
+        {"ref": "refs/heads/x"},
+        *[{"content": {}}] * 5,
+        {"html_url": "https://github.com/acme/expenses/pull/42", "number": 42},
+    ]
+    client.request = AsyncMock(side_effect=[Mock(json=Mock(return_value=r), raise_for_status=Mock()) for r in responses])
+    client.__aenter__ = AsyncMock(return_value=client)
+    client.__aexit__ = AsyncMock(return_value=False)
+    translator = MagicMock(translate_to_rego=AsyncMock(return_value=REGO))
+
+    with (
+        patch("app.services.policy_scaffold_service.TranslationService", return_value=translator),
+        patch("app.services.policy_scaffold_service.httpx.AsyncClient", return_value=client),
+        patch("app.services.policy_scaffold_service.is_test_mode", return_value=False),
+        patch("app.services.policy_scaffold_service.ensure_host_allowed") as allowed,
+    ):
+        record = await service.open_pull_request(3, ScaffoldFormat.REGO, language="javascript", requested_by="sam@acme.io")
+
+    allowed.assert_called_once_with("github.com", "scaffold_pull_request")
+    assert record.status == ScaffoldPullRequestStatus.OPENED
+    assert (record.url, record.number, record.base_branch) == ("https://github.com/acme/expenses/pull/42", 42, "main")
+    assert record.branch.startswith("policy-miner/scaffold-rego-")
+    assert "authz/pdp.js" in record.files and "policy/README.md" in record.files
+    calls = client.request.call_args_list
+    assert calls[2].args == ("POST", "https://api.github.com/repos/acme/expenses/git/refs")
+    assert calls[2].kwargs["json"] == {"ref": f"refs/heads/{record.branch}", "sha": "abc123"}
+    puts = {c.args[1].rsplit("/contents/", 1)[1]: c.kwargs["json"] for c in calls[3:8]}
+    assert base64.b64decode(puts["authz/pdp.js"]["content"]).decode() == integration_stub("javascript", ScaffoldFormat.REGO)[1]
+    pull = calls[8].kwargs["json"]
+    assert (pull["head"], pull["base"]) == (record.branch, "main")
+    assert "src/expenses.js:12-14" in pull["body"]
+
+    client.request = AsyncMock(side_effect=RuntimeError("422 Reference already exists"))
+    with (
+        patch("app.services.policy_scaffold_service.TranslationService", return_value=translator),
+        patch("app.services.policy_scaffold_service.httpx.AsyncClient", return_value=client),
+        patch("app.services.policy_scaffold_service.is_test_mode", return_value=False),
+        patch("app.services.policy_scaffold_service.ensure_host_allowed"),
+    ):
+        failed = await service.open_pull_request(3, ScaffoldFormat.REGO, language="javascript")
+    assert failed.status == ScaffoldPullRequestStatus.FAILED
+    assert failed.error_message == "422 Reference already exists"
+
+    repo.connection_config = {}
+    with patch("app.services.policy_scaffold_service.is_test_mode", return_value=False), pytest.raises(ValueError, match="no access token"):
+        await service.open_pull_request(3)
@@ -0,0 +1,322 @@
+"""Tests for Rails controller authorization mining."""
+from app.services.rails_route_extractor import RailsRouteKind, extract_rails_routes
+
+ROUTES = """Rails.application.routes.draw do
+  devise_for :users
+  root "home#index"
+
+  resources :orders, only: [:index, :show, :update, :destroy] do
+    member do
+      post :refund
+    end
+    resources :comments, only: :create
+  end
+
+  namespace :admin do
+    get "reports/:year", to: "reports#show", as: :report
+  end
+
+  authenticate :user, ->(u) { u.admin? } do
+    mount Sidekiq::Web => "/sidekiq"
+    get "ops/health" => "ops#health"
+  end
+
+  get "about", to: "pages#about"
+  match "webhooks/:id" => "webhooks#receive", via: [:get, :post]
+end
+"""
+
+APPLICATION = """class ApplicationController < ActionController::Base
+  include Pundit::Authorization
+
+  before_action :authenticate_user!
+end
+"""
+
+ORDERS = """class OrdersController < ApplicationController
+  before_action :set_order, only: [:show, :update, :destroy, :refund]
+  before_action :require_manager, only: :refund
+
+  def index
+    @orders = policy_scope(Order)
+  end
+
+  def show
+    authorize @order
+  end
+
+  def update
+    authorize @order
+    @order.update!(order_params)
+  end
+
+  def destroy
+    authorize @order, :manage?
+    @order.destroy
+  end
+
+  def refund
+    @order.refund!
+  end
+
+  private
+
+  def set_order
+    @order = Order.find(params[:id])
+  end
+
+  def require_manager
+    redirect_to root_path, alert: "Not allowed" unless current_user.manager?
+  end
+end
+"""
+
+PAGES = """class PagesController < ApplicationController
+  skip_before_action :authenticate_user!, only: :about
+
+  def about
+  end
+end
+"""
+
+WEBHOOKS = """class WebhooksController < ActionController::API
+  before_action :verify_signature
+
+  def receive
+    head :ok
+  end
+
+  private
+
+  def verify_signature
+    head :unauthorized unless valid_signature?(request)
+  end
+end
+"""
+
+REPORTS = """module Admin
+  class ReportsController < ApplicationController
+    before_action -> { redirect_to root_path unless current_user.has_role?(:finance) }
+
+    def show
+    end
+
+    def archive
+    end
+  end
+end
+"""
+
+APPLICATION_POLICY = """class ApplicationPolicy
+  attr_reader :user, :record
+
+  def initialize(user, record)
+    @user = user
+    @record = record
+  end
+
+  def show?
+    false
+  end
+
+  def update?
+    false
+  end
+end
+"""
+
+ORDER_POLICY = """class OrderPolicy < ApplicationPolicy
+  def show?
+    user.admin? || record.user_id == user.id
+  end
+
+  def update?
+    return true if user.admin?
+    record.user_id == user.id && !record.shipped?
+  end
+
+  def manage?
+    user.has_role?(:admin)
+  end
+end
+"""
+
+COMMENTS = """class CommentsController < ApplicationController
+  def create
+    authorize Comment
+  end
+end
+"""
+
+
+def _routes(findings):
+    """Findings keyed by (method, path)."""
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_filters_map_to_routes_drawn_by_routes_rb():
+    """Test inherited Devise filters, skips, custom filters, and routes.rb guards reach the routes drawn for each action."""
+    findings = extract_rails_routes(
+        {
+            "config/routes.rb": ROUTES,
+            "app/controllers/application_controller.rb": APPLICATION,
+            "app/controllers/orders_controller.rb": ORDERS,
+            "app/controllers/pages_controller.rb": PAGES,
+            "app/controllers/webhooks_controller.rb": WEBHOOKS,
+            "app/controllers/admin/reports_controller.rb": REPORTS,
+            "README.md": ORDERS,
+        }
+    )
+
+    assert {f.kind for f in findings} == {RailsRouteKind.ACTION}
+    routes = _routes(findings)
+    refund = routes[("POST", "/orders/{id}/refund")]
+    assert (refund.subject, refund.file_path, refund.line_start, refund.line_end) == (
+        "manager",
+        "app/controllers/orders_controller.rb",
+        23,
+        25,
+    )
+    assert refund.description == (
+        "Rails action OrdersController#refund secured by before_action :authenticate_user! and "
+        'before_action :require_manager (redirect_to root_path, alert: "Not allowed" unless current_user.manager?)'
+    )
+    about = routes[("GET", "/about")]
+    assert (about.subject, about.disables_auth) == ("Anonymous", True)
+    assert about.description.endswith("secured by skip_before_action :authenticate_user!")
+    # The webhook controller skips Devise by not inheriting ApplicationController
+    assert routes[("POST", "/webhooks/{id}")].conditions == "passes valid_signature?(request)"
+    assert ("GET", "/webhooks/{id}") in routes
+    assert routes[("GET", "/admin/reports/{year}")].subject == "finance"
+    # Routes inside authenticate blocks are guarded even without a controller in the repository
+    health = routes[("GET", "/ops/health")]
+    assert (health.subject, health.file_path, health.line_start) == ("admin", "config/routes.rb", 18)
+    archive = routes[("*", "admin/reports#archive")]
+    assert archive.description.startswith("Rails action Admin::ReportsController#archive (not routed in routes.rb)")
+
+
+def test_pundit_authorize_resolves_policy_query_methods():
+    """Test authorize calls resolve to the policy class query for the action, inherited and explicit queries included."""
+    findings = extract_rails_routes(
+        {
+            "config/routes.rb": ROUTES,
+            "app/controllers/application_controller.rb": APPLICATION,
+            "app/controllers/orders_controller.rb": ORDERS,
+            "app/controllers/comments_controller.rb": COMMENTS,
+            "app/policies/application_policy.rb": APPLICATION_POLICY,
+            "app/policies/order_policy.rb": ORDER_POLICY,
+        }
+    )
+
+    routes = _routes(findings)
+    show = routes[("GET", "/orders/{id}")]
+    assert show.conditions == "any of: admin | Authenticated users (passes record.user_id == user.id)"
+    assert show.description == (
+        "Rails action OrdersController#show secured by before_action :authenticate_user! and Pundit OrderPolicy#show?"
+    )
+    update = routes[("PATCH", "/orders/{id}")]
+    assert update.conditions == (
+        "any of: admin | Authenticated users (passes record.user_id == user.id; passes not record.shipped?)"
+    )
+    assert routes[("PUT", "/orders/{id}")].conditions == update.conditions
+    assert routes[("DELETE", "/orders/{id}")].subject == "admin"
+    assert routes[("GET", "/orders")].conditions == "records limited to OrderPolicy::Scope"
+    comment = routes[("POST", "/orders/{order_id}/comments")]
+    assert comment.conditions == "passes CommentPolicy#create?, which no policy class in the repository defines"
+
+
+ABILITY_ROUTES = """Rails.application.routes.draw do
+  scope "/api/v1", module: :api do
+    resources :invoices do
+      post :approve, on: :member
+      get :export, on: :collection
+    end
+  end
+end
+"""
+
+ABILITY = """# frozen_string_literal: true
+
+class Ability
+  include CanCan::Ability
+
+  def initialize(user)
+    user ||= User.new
+    alias_action :approve, :export, to: :process
+
+    case user.role
+    when "accountant"
+      can [:read, :process], Invoice
+    when "clerk"
+      can :create, Invoice
+      can :update, Invoice do |invoice|
+        invoice.draft?
+      end
+    end
+    can :read, Invoice, tenant_id: user.tenant_id if user.persisted?
+    admin_rules if user.admin?
+  end
+
+  private
+
+  def admin_rules
+    can :manage, :all
+    cannot :destroy, Invoice, status: "paid"
+  end
+end
+"""
+
+INVOICES = """module Api
+  class InvoicesController < ApplicationController
+    before_action :authenticate_api_user!
+    load_and_authorize_resource
+
+    def index; end
+
+    def create
+    end
+
+    def update
+    end
+
+    def destroy
+    end
+
+    def approve
+    end
+  end
+end
+"""
+
+
+def test_cancancan_abilities_answer_authorized_resources():
+    """Test load_and_authorize_resource resolves to the can and cannot rules of the Ability class by role branch."""
+    findings = extract_rails_routes(
+        {
+            "config/routes.rb": ABILITY_ROUTES,
+            "app/models/ability.rb": ABILITY,
+            "app/controllers/application_controller.rb": "class ApplicationController < ActionController::Base\nend\n",
+            "app/controllers/api/invoices_controller.rb": INVOICES,
+        }
+    )
+
+    routes = _routes(findings)
+    assert sorted(routes) == [
+        ("DELETE", "/api/v1/invoices/{id}"),
+        ("GET", "/api/v1/invoices"),
+        ("PATCH", "/api/v1/invoices/{id}"),
+        ("POST", "/api/v1/invoices"),
+        ("POST", "/api/v1/invoices/{id}/approve"),
+        ("PUT", "/api/v1/invoices/{id}"),
+    ]
+    index = routes[("GET", "/api/v1/invoices")]
+    assert index.conditions == "any of: accountant | Authenticated users (Invoice matches tenant_id: user.tenant_id) | admin"
+    assert index.description == (
+        "Rails action Api::InvoicesController#index secured by before_action :authenticate_api_user! and "
+        "load_and_authorize_resource (CanCanCan Ability :index on Invoice)"
+    )
+    assert routes[("POST", "/api/v1/invoices")].subject == "clerk or admin"
+    assert routes[("PATCH", "/api/v1/invoices/{id}")].conditions == "any of: clerk (passes invoice.draft?) | admin"
+    assert routes[("POST", "/api/v1/invoices/{id}/approve")].subject == "accountant or admin"
+    destroy = routes[("DELETE", "/api/v1/invoices/{id}")]
+    assert (destroy.subject, destroy.conditions) == ("admin", 'refused by cannot :destroy, Invoice, status: "paid"')
@@ -0,0 +1,200 @@
+"""Tests for rate limit and quota mining."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.rate_limit_extractor import (
+    RateLimitKey,
+    describe_period,
+    extract_rate_limits,
+    parse_duration,
+    parse_rate,
+)
+from app.services.rate_limit_service import RateLimitService
+
+EXPRESS = """const rateLimit = require('express-rate-limit')
+const apiLimiter = rateLimit({
+  windowMs: 15 * 60 * 1000,
+  max: (req) => req.user.role === 'partner' ? 1000 : 100,
+  keyGenerator: (req) => req.headers['x-api-key'],
+})
+const loginLimiter = rateLimit({ windowMs: 60000, max: 5 })
+app.use('/api', apiLimiter)
+app.post('/login', loginLimiter, login)
+// app.use('/admin', apiLimiter)
+"""
+
+FLASK = """limiter = Limiter(key_func=get_remote_address, default_limits=["200 per day"])
+
+@limiter.limit(lambda: "1000/hour" if current_user.role == "partner" else "100/hour")
+@app.route('/reports', methods=['POST'])
+def reports():
+    pass
+
+@limiter.exempt
+@app.get('/health')
+def health():
+    pass
+"""
+
+ASPNET = """builder.Services.AddRateLimiter(options =>
+{
+    options.AddPolicy("partner", context => RateLimitPartition.GetFixedWindowLimiter(
+        partitionKey: context.User.Identity?.Name,
+        factory: _ => new FixedWindowRateLimiterOptions { PermitLimit = context.User.IsInRole("Partner") ? 500 : 50, Window = TimeSpan.FromMinutes(1) }));
+    options.AddPolicy("admins", policy => policy.RequireRole("Admin"));
+});
+app.MapGet("/orders", GetOrders).RequireRateLimiting("partner");
+"""
+
+LARAVEL = """RateLimiter::for('api', function (Request $request) {
+    return $request->user()->isPremium()
+        ? Limit::perMinute(1000)->by($request->user()->id)
+        : Limit::perMinute(60)->by($request->ip());
+});
+Route::middleware('throttle:api')->prefix('api')->group(function () {});
+Route::post('/upload', [UploadController::class, 'store'])->middleware('throttle:10,1');
+"""
+
+NGINX = """http {
+  limit_req_zone $binary_remote_addr zone=api:10m rate=10r/s;
+  server {
+    location /api/ {
+      limit_req zone=api burst=20;
+    }
+    location /static/ {
+      root /var/www;
+    }
+  }
+}
+"""
+
+KONG = """_format_version: "3.0"
+services:
+- name: orders
+  url: http://orders
+  routes:
+  - name: orders-route
+    paths: [/orders]
+  plugins:
+  - name: rate-limiting
+    config:
+      minute: 100
+      limit_by: ip
+consumers:
+- username: acme-partner
+  plugins:
+  - name: rate-limiting
+    config:
+      hour: 10000
+"""
+
+
+def make_policy(policy_id, subject, snippet):
+    """Create a policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = "Report"
+    policy.action = "access"
+    policy.conditions = None
+    policy.description = None
+    ev = Mock(spec=Evidence)
+    ev.code_snippet = snippet
+    ev.file_path = "app.js"
+    ev.line_start = 1
+    policy.evidence = [ev]
+    return policy
+
+
+def test_parse_rates_and_durations():
+    """Test rate strings, duration expressions, and period descriptions."""
+    assert parse_rate("100/hour") == (100, 3600)
+    assert parse_rate("10 per 5 minutes") == (10, 300)
+    assert parse_rate("1000/d") == (1000, 86400)
+    assert parse_rate("no limit here") is None
+    assert parse_duration("TimeSpan.FromMinutes(2)") == 120
+    assert parse_duration("period: 20.seconds") == 20
+    assert parse_duration("10*time.Second") == 10
+    assert parse_duration("limitRefreshPeriod: 500ms") == 1
+    assert [describe_period(s) for s in (60, 900, 86400, 90)] == ["minute", "15 minutes", "day", "90 seconds"]
+
+
+def test_extract_per_role_and_per_key_limits_from_middleware():
+    """Test Express, Flask-Limiter, and ASP.NET limits resolve roles, keys, and routes."""
+    express = extract_rate_limits({"server.js": EXPRESS})
+    assert [(l.scope, l.method, l.subject, l.limit, l.key) for l in express] == [
+        ("/api/*", None, "partner", 1000, RateLimitKey.API_KEY),
+        ("/api/*", None, None, 100, RateLimitKey.API_KEY),
+        ("/login", "POST", None, 5, RateLimitKey.IP),
+    ]
+    assert express[0].rate == "1000 requests per 15 minutes per API key"
+
+    flask = extract_rate_limits({"app.py": FLASK})
+    assert [(l.scope, l.subject, l.limit, l.period_seconds, l.exempt) for l in flask] == [
+        (None, None, 200, 86400, False),
+        ("/reports", "partner", 1000, 3600, False),
+        ("/reports", None, 100, 3600, False),
+        ("/health", None, None, None, True),
+    ]
+
+    aspnet = extract_rate_limits({"Program.cs": ASPNET})
+    assert [(l.name, l.scope, l.subject, l.limit, l.key) for l in aspnet] == [
+        ("partner", "/orders", "Partner", 500, RateLimitKey.USER),
+        ("partner", "/orders", None, 50, RateLimitKey.USER),
+    ]
+    assert extract_rate_limits({"view.py": "limit = 10  # pagination"}) == []
+
+
+def test_extract_throttles_and_gateway_limits():
+    """Test Laravel named and inline throttles, nginx zones, and Kong plugins."""
+    laravel = extract_rate_limits({"routes/api.php": LARAVEL})
+    assert [(l.scope, l.method, l.subject, l.limit, l.key) for l in laravel] == [
+        ("/api/*", None, "Premium", 1000, RateLimitKey.USER),
+        ("/api/*", None, None, 60, RateLimitKey.IP),
+        ("/upload", "POST", None, 10, RateLimitKey.USER),
+    ]
+
+    [nginx] = extract_rate_limits({"deploy/nginx.conf": NGINX})
+    assert (nginx.name, nginx.scope, nginx.limit, nginx.period_seconds, nginx.line_start) == ("api", "/api/*", 10, 1, 5)
+
+    service, consumer = extract_rate_limits({"kong.yml": KONG})
+    assert (service.scope, service.limit, service.period_seconds, service.key) == ("/orders/*", 100, 60, RateLimitKey.IP)
+    assert (consumer.subject, consumer.key, consumer.line_start) == ("acme-partner", RateLimitKey.CONSUMER, 16)
+
+
+def test_services_resolve_limits_onto_endpoints(tmp_path):
+    """Test limits from the clone attach per role and flag unlimited authenticated endpoints."""
+    (tmp_path / "5").mkdir()
+    (tmp_path / "5" / "server.js").write_text(EXPRESS)
+    (tmp_path / "5" / "node_modules").mkdir()
+    (tmp_path / "5" / "node_modules" / "limits.js").write_text("app.use('/admin', rateLimit({ max: 1 }))")
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 5, "partner-api"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    policies = [
+        make_policy(1, "PARTNER", "router.get('/api/reports', requireRole('PARTNER'), list)"),
+        make_policy(2, "ADMIN", "router.get('/api/audit', requireRole('ADMIN'), audit)"),
+        make_policy(3, "ADMIN", "app.delete('/admin/users/:id', requireRole('ADMIN'), remove)"),
+        make_policy(4, "Anonymous", "app.post('/login', loginLimiter, login)"),
+    ]
+
+    service = RateLimitService(db, "acme", clone_dir=str(tmp_path))
+    with patch("app.services.rate_limit_service.DecisionSimulationService.load_policies", return_value=policies):
+        [report] = service.services(repository_id=5)
+        assert service.services(unlimited_only=True)[0]["repository_id"] == 5
+
+    assert (report["cloned"], len(report["limits"]), report["unlimited_endpoints"]) == (True, 3, 1)
+    endpoints = {e["endpoint"]: e for e in report["endpoints"]}
+    assert report["endpoints"][0]["endpoint"] == "DELETE /admin/users/:id"
+    assert endpoints["DELETE /admin/users/:id"]["unlimited"] is True
+    assert endpoints["GET /api/reports"]["rates"] == {
+        "partner": ["1000 requests per 15 minutes per API key"],
+        "*": ["100 requests per 15 minutes per API key"],
+    }
+    assert list(endpoints["GET /api/audit"]["rates"]) == ["*"]
+    assert endpoints["POST /login"]["rates"] == {"*": ["5 requests per minute per client IP"]}
+    assert endpoints["POST /login"]["unlimited"] is False
@@ -0,0 +1,181 @@
+"""Tests for repository readiness assessments."""
+from unittest.mock import Mock
+
+import pytest
+
+from app.models.readiness import ReadinessAssessment
+from app.models.repository import Repository
+from app.services.readiness_service import (
+    DynamicPatternKind,
+    FrameworkSupport,
+    ReadinessService,
+    assess_clone,
+    classify_registration,
+)
+
+EXPRESS_APP = """const express = require('express');
+const app = express();
+const routes = require('./routes/table');
+
+app.get('/health', health);
+app.post('/orders', requirePermission('orders:write'), createOrder);
+app.get(API_PREFIX + '/users', listUsers);
+for (const r of routes) {
+  app[r.method](r.path, r.handler);
+}
+
+function requirePermission(permission) {
+  return (req, res, next) => (req.user.permissions.includes(permission) ? next() : res.sendStatus(403));
+}
+"""
+
+FLASK_ADMIN = """from flask import Blueprint
+import casbin
+
+bp = Blueprint("admin", __name__)
+enforcer = casbin.Enforcer("model.conf", "policy.csv")
+
+
+def ensure_admin(user):
+    if not enforcer.enforce(user, "admin", "access"):
+        abort(403)
+
+
+@bp.route("/admin/reports")
+def reports():
+    ensure_admin(current_user)
+
+
+@bp.route(f"/admin/{section}")
+def section():
+    return getattr(views, section_name)()
+"""
+
+KOA_APP = """const Router = require('@koa/router');
+const router = new Router();
+router.get('/', list);
+router.post('/:id/roles', checkRole('owner'), assign);
+module.exports = router;
+"""
+
+
+def write(root, files):
+    """Write files under a clone root."""
+    for name, text in files.items():
+        (root / name).parent.mkdir(parents=True, exist_ok=True)
+        (root / name).write_text(text)
+
+
+def make_repository(repository_id=3, name="payments"):
+    """Create a repository."""
+    repo = Mock(spec=Repository)
+    repo.id, repo.name = repository_id, name
+    return repo
+
+
+def test_assessment_reports_frameworks_routes_and_dynamic_patterns(tmp_path):
+    """Test the assessment finds what limits mining and recommends fixes, most impactful first."""
+    write(
+        tmp_path,
+        {
+            "src/app.js": EXPRESS_APP,
+            "src/admin.py": FLASK_ADMIN,
+            "web/page.tsx": "const user = await api.get(`/users/${id}`);\n",
+            "tests/test_app.js": "app.get(dynamicPath, handler);\n",
+            "native/lib.rs": "fn main() {}\n",
+            "node_modules/dep/index.js": EXPRESS_APP,
+        },
+    )
+
+    report = assess_clone(tmp_path)
+
+    assert report["source_files"] == 4
+    assert {(f["name"], f["support"], f["files"]) for f in report["frameworks"]} == {
+        ("Express", FrameworkSupport.ROUTES, 1),
+        ("Flask", FrameworkSupport.ROUTES, 1),
+    }
+    assert {"language": "Rust", "files": 1, "supported": False} in report["languages"]
+    # Frontend API calls and test files are not route registrations
+    assert report["routes"] == {"registrations": 6, "resolvable": 3, "dynamic": 3, "resolvable_percent": 50}
+
+    counts = report["dynamic_patterns"]["counts"]
+    assert counts == {
+        DynamicPatternKind.DYNAMIC_ROUTE: 3,
+        DynamicPatternKind.CUSTOM_AUTH_HELPER: 2,
+        DynamicPatternKind.EXTERNAL_POLICY_ENGINE: 1,
+        DynamicPatternKind.REFLECTION: 1,
+    }
+    helpers = {p["detail"]: p for p in report["dynamic_patterns"]["items"] if p["kind"] == "custom_auth_helper"}
+    assert set(helpers) == {"requirePermission", "ensure_admin"}
+    assert (helpers["ensure_admin"]["file_path"], helpers["ensure_admin"]["line"]) == ("src/admin.py", 8)
+    assert helpers["ensure_admin"]["call_sites"] == 1
+
+    priorities = [r["priority"] for r in report["recommendations"]]
+    assert priorities == sorted(priorities, key=["high", "medium", "low"].index)
+    areas = [r["area"] for r in report["recommendations"]]
+    assert areas[:2] == ["policy_engines", "routes"]
+    assert "first at src/admin.py:18" in report["recommendations"][1]["message"]
+    assert not report["ready"] and report["grade"] in "CDF"
+
+
+def test_clean_dedicated_framework_scores_ready(tmp_path):
+    """Test literal routes in a framework with a dedicated extractor score as ready."""
+    write(tmp_path, {"routes/users.js": KOA_APP})
+
+    report = assess_clone(tmp_path)
+
+    assert report["components"] == {
+        "language_coverage": 100,
+        "framework_support": 100,
+        "route_resolution": 100,
+        "dynamic_patterns": 100,
+    }
+    assert (report["score"], report["grade"], report["ready"]) == (100, "A", True)
+    assert report["recommendations"] == []
+
+    empty = assess_clone(tmp_path / "missing")
+    assert (empty["score"], empty["routes"]["resolvable_percent"]) == (0, None)
+    assert empty["recommendations"][0]["area"] == "languages"
+
+
+@pytest.mark.parametrize(
+    "arg,annotation,expected",
+    [
+        ("'/users/:id'", False, True),
+        ('"/users/{id}"', False, True),
+        ('""', False, True),
+        ('"user"', False, None),
+        ('"{id}"', True, True),
+        (None, True, True),
+        ("`/users/${id}`", False, False),
+        ('f"/admin/{section}"', False, False),
+        ("PREFIX + '/x'", False, False),
+        ('{"/a"', True, True),
+    ],
+)
+def test_classify_registration(arg, annotation, expected):
+    """Test literal, relative, interpolated, and computed route paths."""
+    assert classify_registration(arg, annotation) is expected
+
+
+def test_assess_records_result_and_workspace_lists_least_ready_first(tmp_path, make_db):
+    """Test assessments are stored per repository and the workspace view orders by score."""
+    write(tmp_path / "3", {"routes/users.js": KOA_APP})
+    payments, billing = make_repository(3, "payments"), make_repository(4, "billing")
+    db = make_db({Repository: [payments]})
+
+    assessment = ReadinessService(db, "acme", str(tmp_path)).assess(3, scan_id=12, assessed_by="dev@acme.com")
+
+    assert (assessment.score, assessment.grade, assessment.ready) == (100, "A", True)
+    assert (assessment.tenant_id, assessment.scan_id, assessment.assessed_by) == ("acme", 12, "dev@acme.com")
+    assert assessment.route_resolution_percent == 100
+    assert assessment.report["frameworks"][0]["name"] == "Koa"
+    db.add.assert_called_once_with(assessment)
+
+    with pytest.raises(ValueError, match="has not been cloned"):
+        ReadinessService(make_db({Repository: [billing]}), "acme", str(tmp_path)).assess(4)
+
+    stored = Mock(spec=ReadinessAssessment)
+    stored.id, stored.repository_id, stored.score, stored.grade, stored.ready = 7, 3, 82, "B", True
+    rows = ReadinessService(make_db({Repository: [payments, billing], ReadinessAssessment: [stored]}), "acme").workspace()
+    assert [(r["repository_name"], r["score"]) for r in rows] == [("billing", None), ("payments", 82)]
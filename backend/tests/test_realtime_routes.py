@@ -0,0 +1,198 @@
+"""Tests for WebSocket, socket.io, STOMP, and SSE authorization mining."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.repository import Repository
+from app.services.framework_route_service import FrameworkRouteService
+from app.services.realtime_route_extractor import RealtimeRouteKind, extract_realtime_routes
+
+GORILLA = """package main
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func main() {
+	http.HandleFunc("/ws", requireAuth(wsHandler))
+	http.HandleFunc("/feed", feedHandler)
+	http.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+	})
+}
+
+func wsHandler(w http.ResponseWriter, r *http.Request) {
+	conn, _ := upgrader.Upgrade(w, r, nil)
+	for {
+		var msg Message
+		conn.ReadJSON(&msg)
+		switch msg.Type {
+		case "chat":
+			broadcast(msg)
+		case "kick":
+			if user.Role != "admin" {
+				continue
+			}
+			kick(msg)
+		}
+	}
+}
+
+func feedHandler(w http.ResponseWriter, r *http.Request) {
+	conn, _ := upgrader.Upgrade(w, r, nil)
+	conn.WriteJSON(feed)
+}
+"""
+SOCKET_IO = """const { Server } = require('socket.io')
+const io = new Server(server)
+const admin = io.of('/admin')
+admin.use((socket, next) => {
+  const token = socket.handshake.auth.token
+  jwt.verify(token, secret) ? next() : next(new Error('unauthorized'))
+})
+admin.on('connection', (socket) => {
+  socket.on('ban-user', (id) => {
+    if (socket.data.user.role !== 'superadmin') return
+    ban(id)
+  })
+  socket.on('list-users', listUsers)
+  socket.on('disconnect', () => {})
+})
+io.on('connection', (socket) => {
+  socket.on('chat', (msg) => io.emit('chat', msg))
+})
+function listUsers(cb) { cb(users) }
+"""
+STOMP_CONFIG = """@Configuration
+@EnableWebSocketMessageBroker
+public class WsConfig implements WebSocketMessageBrokerConfigurer {
+    public void registerStompEndpoints(StompEndpointRegistry registry) {
+        registry.addEndpoint("/ws").withSockJS();
+    }
+    public void configureMessageBroker(MessageBrokerRegistry registry) {
+        registry.setApplicationDestinationPrefixes("/app");
+    }
+}
+"""
+STOMP_SECURITY = """@EnableWebSecurity
+public class Sec {
+    SecurityFilterChain chain(HttpSecurity http) {}
+    AuthorizationManager<Message<?>> messages(MessageMatcherDelegatingAuthorizationManager.Builder messages) {
+        messages.simpDestMatchers("/app/admin/**").hasRole("ADMIN")
+                .simpSubscribeDestMatchers("/topic/public").permitAll()
+                .anyMessage().authenticated();
+        return messages.build();
+    }
+}
+"""
+STOMP_CONTROLLER = """@Controller
+public class ChatController {
+    @MessageMapping("/chat.send")
+    public void send(ChatMessage m) {
+        template.convertAndSend("/topic/chat", m);
+    }
+
+    @PreAuthorize("hasRole('MODERATOR')")
+    @MessageMapping("/chat.delete")
+    public void delete(String id) {
+        repo.delete(id);
+    }
+
+    @MessageMapping("/admin/reset")
+    public void reset() {}
+
+    @PreAuthorize("isAuthenticated()")
+    @GetMapping(value = "/stream", produces = MediaType.TEXT_EVENT_STREAM_VALUE)
+    public SseEmitter stream() {
+        return new SseEmitter();
+    }
+}
+"""
+FASTAPI_SSE = """@app.get("/notifications/stream")
+async def stream(user: User = Depends(get_current_user)):
+    return EventSourceResponse(events(user))
+
+
+@app.get("/status")
+async def status():
+    return {"ok": True}
+"""
+EXPRESS_SSE = """app.get('/events', requireAuth, (req, res) => {
+  res.writeHead(200, { 'Content-Type': 'text/event-stream' })
+})
+"""
+
+
+def _by_resource(findings):
+    """Index findings by (action, resource)."""
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_gorilla_upgrade_and_message_types():
+    """Test handshake auth, per-message role checks, inherited subjects, and any-origin upgrades."""
+    findings = _by_resource(extract_realtime_routes({"main.go": GORILLA}))
+
+    ws = findings[("GET", "/ws")]
+    assert (ws.kind, ws.subject, ws.line_start) == (RealtimeRouteKind.WEBSOCKET_UPGRADE, "Authenticated users", 15)
+    assert "cross-site WebSocket hijacking" in ws.description
+    chat, kick = findings[("SEND", "/ws/chat")], findings[("SEND", "/ws/kick")]
+    assert chat.subject == "Authenticated users"
+    assert "relies on the connection's" in chat.description
+    assert (kick.kind, kick.subject) == (RealtimeRouteKind.WEBSOCKET_MESSAGE, "ADMIN")
+    feed = findings[("GET", "/feed")]
+    assert (feed.subject, feed.disables_auth) == ("Anonymous", True)
+    assert findings[("GET", "/events")].kind == RealtimeRouteKind.SSE_STREAM
+
+
+def test_socketio_namespaces_middleware_and_events():
+    """Test namespace middleware authenticates the connection and events inherit or narrow it."""
+    findings = _by_resource(extract_realtime_routes({"server.js": SOCKET_IO}))
+
+    assert findings[("GET", "/socket.io/admin")].subject == "Authenticated users"
+    assert findings[("SEND", "/socket.io/admin/ban-user")].subject == "SUPERADMIN"
+    assert findings[("SEND", "/socket.io/admin/list-users")].subject == "Authenticated users"
+    assert ("SEND", "/socket.io/admin/disconnect") not in findings
+    chat = findings[("SEND", "/socket.io/chat")]
+    assert (chat.kind, chat.subject, chat.disables_auth) == (RealtimeRouteKind.SOCKETIO_EVENT, "Anonymous", True)
+
+
+def test_stomp_destinations_resolve_annotations_then_message_security():
+    """Test method annotations win over message security rules, and SSE mappings are found."""
+    files = {
+        "WsConfig.java": STOMP_CONFIG,
+        "Sec.java": STOMP_SECURITY,
+        "ChatController.java": STOMP_CONTROLLER,
+    }
+    findings = _by_resource(extract_realtime_routes(files))
+
+    assert findings[("GET", "/ws")].subject == "Authenticated users"
+    assert findings[("SEND", "/app/chat.send")].subject == "Authenticated users"
+    delete = findings[("SEND", "/app/chat.delete")]
+    assert (delete.subject, delete.line_start) == ("MODERATOR", 9)
+    assert "method annotation" in delete.description
+    reset = findings[("SEND", "/app/admin/reset")]
+    assert reset.subject == "ADMIN"
+    assert "simpDestMatchers('/app/admin/**')" in reset.description
+    stream = findings[("GET", "/stream")]
+    assert (stream.kind, stream.subject, stream.line_start) == (RealtimeRouteKind.SSE_STREAM, "Authenticated users", 17)
+
+
+def test_scan_repository_includes_realtime_endpoints(tmp_path):
+    """Test SSE streams from Python and Express clones are merged as framework route policies."""
+    (tmp_path / "6").mkdir()
+    (tmp_path / "6" / "api.py").write_text(FASTAPI_SSE)
+    (tmp_path / "6" / "sse.js").write_text(EXPRESS_SSE)
+    (tmp_path / "6" / "vendor").mkdir()
+    (tmp_path / "6" / "vendor" / "ws.go").write_text(GORILLA)
+    repo = Mock(spec=Repository, id=6, tenant_id="acme")
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    previous = db.query.return_value.join.return_value.filter.return_value.filter.return_value
+    previous.filter.return_value.all.return_value = []
+    db.query.return_value.filter.return_value.all.side_effect = [[], []]
+
+    result = FrameworkRouteService(db, "acme", str(tmp_path)).scan_repository(6)
+
+    assert result["findings_by_kind"] == {RealtimeRouteKind.SSE_STREAM: 2}
+    assert result["files"] == 2
+    created = {(p.action, p.resource, p.subject) for p in (call.args[0] for call in db.add.call_args_list)}
+    assert ("GET", "/notifications/stream", "Authenticated users") in created
+    assert ("GET", "/events", "Authenticated users") in created
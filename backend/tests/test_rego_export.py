@@ -0,0 +1,103 @@
+"""Tests for exporting mined policies as Rego packages."""
+import gzip
+import io
+import json
+import tarfile
+
+from app.services.rego_export_service import RegoExportService, path_pattern, rego_name
+
+
+def clone(tmp_path):
+    """Clone with an expenses and a billing service."""
+    for service in ("expenses", "billing"):
+        (tmp_path / "4" / "services" / service).mkdir(parents=True)
+        (tmp_path / "4" / "services" / service / "package.json").write_text("{}")
+    return tmp_path
+
+
+def test_names_and_path_patterns_are_valid_rego():
+    """Test identifiers from service names and routes, and path parameters as wildcards."""
+    assert rego_name("billing-api", "service") == "billing_api"
+    assert rego_name("GET /api/expenses/{}", "endpoint") == "get_api_expenses_item"
+    assert rego_name("default", "service") == "service_default"
+    assert rego_name("3ds", "service") == "service_3ds"
+    assert path_pattern("/api/expenses/:id/approve") == '["api", "expenses", _, "approve"]'
+    assert path_pattern("/") == "[]"
+
+
+def test_one_package_per_service_with_a_rule_per_endpoint(tmp_path, make_policy, make_export_service):
+    """Test grouping by service, one body per policy and alternative, conditions, and the input schema."""
+    policies = [
+        make_policy(
+            1, "MANAGER", "read", "router.get('/api/expenses/:id', requireRole('MANAGER'), show)",
+            file_path="services/expenses/routes.js", conditions="user is owner of expense.owner_id unless ADMIN",
+        ),
+        make_policy(
+            2, "AUDITOR", "read", "router.get('/api/expenses/:id', requireRole('AUDITOR'), show)",
+            file_path="services/expenses/routes.js", conditions="user department is Finance",
+        ),
+        make_policy(
+            3, "MANAGER", "approve", "router.put('/api/expenses/:id/approve', requireRole('MANAGER'), approve)",
+            file_path="services/expenses/routes.js", conditions="amount > 5000 requires DIRECTOR; vibes are good",
+        ),
+        make_policy(4, "anonymous", "read", "app.get('/api/invoices', list)", file_path="services/billing/routes.js"),
+    ]
+    result = make_export_service(RegoExportService, policies, clone(tmp_path), repository_name="platform").export(4)
+
+    billing, expenses = result["packages"]
+    assert (billing["service"], expenses["service"]) == ("billing", "expense")
+    assert expenses["package"] == "policy_miner.services.expense"
+    assert expenses["path"] == "policy_miner/services/expense/policy.rego"
+    assert expenses["schema_path"] == "schemas/expense/input.json"
+    assert (expenses["endpoints"], expenses["policy_ids"]) == (2, [1, 2, 3])
+
+    rego = expenses["rego"]
+    assert "#   - input: schema.expense.input\npackage policy_miner.services.expense\n" in rego
+    assert "allow if get_api_expenses_item\nallow if put_api_expenses_item_approve\n" in rego
+    # Owner or ADMIN, and the AUDITOR's policy: three bodies of one endpoint rule
+    assert rego.count("get_api_expenses_item if {") == 3
+    assert "\tinput.resource.attributes.owner_id == input.subject.id\n" in rego
+    assert '\thas_role({"ADMIN"})\n' in rego
+    assert '\tlower(input.subject.attributes.department) == "finance"\n' in rego
+    assert '\tinput.request.path = ["api", "expenses", _, "approve"]\n' in rego
+    assert "\tinput.resource.attributes.amount <= 5000\n" in rego
+    assert '\thas_role({"DIRECTOR"})\n' in rego
+    assert "# Policy 3: MANAGER may approve Expense (confidence 90, explicit_middleware)" in rego
+    assert "#   policy_ids: [1, 2]" in rego
+    # The untranslated clause keeps both of policy 3's bodies from matching
+    assert rego.count("\t# Not translated, review: vibes are good\n\tfalse\n") == 2
+    assert expenses["untranslated"] == [
+        {"policy_id": 3, "endpoint": "PUT /api/expenses/{}/approve", "clause": "vibes are good"}
+    ]
+
+    assert "input.subject.authenticated" not in billing["rego"]
+    schema = expenses["input_schema"]
+    assert schema["properties"]["request"]["properties"]["method"]["enum"] == ["GET", "PUT"]
+    assert schema["properties"]["subject"]["properties"]["attributes"]["properties"] == {"department": {"type": "string"}}
+    assert schema["properties"]["resource"]["properties"]["attributes"]["properties"] == {
+        "amount": {"type": "number"},
+        "owner_id": {"type": "string"},
+    }
+    assert result["summary"] == {
+        "packages": 2, "endpoints": 3, "policies": 4, "below_min_confidence": 0, "untranslated_clauses": 1,
+    }
+
+
+def test_low_confidence_policies_are_left_out_and_archived(tmp_path, make_policy, make_export_service):
+    """Test min_confidence filtering and the archive layout."""
+    policies = [
+        make_policy(1, "ADMIN", "delete", "router.delete('/api/expenses/:id', remove)", file_path="services/expenses/a.js"),
+        make_policy(2, "ADMIN", "read", "router.get('/api/expenses', list)", file_path="services/expenses/a.js", confidence=40.0),
+        make_policy(3, "ADMIN", "read", "router.get('/api/invoices', list)", file_path="services/billing/a.js", confidence=None),
+    ]
+    service = make_export_service(RegoExportService, policies, clone(tmp_path), repository_name="platform")
+
+    result = service.export(4, min_confidence=50)
+    assert [p["policy_ids"] for p in result["packages"]] == [[1]]
+    assert result["summary"]["below_min_confidence"] == 2
+
+    with tarfile.open(fileobj=io.BytesIO(gzip.decompress(service.archive(4, min_confidence=50)))) as tar:
+        members = {member.name: member for member in tar.getmembers()}
+        schema = json.load(tar.extractfile(members["/schemas/expense/input.json"]))
+    assert sorted(members) == ["/policy_miner/services/expense/policy.rego", "/schemas/expense/input.json"]
+    assert schema["title"] == "Input of policy_miner.services.expense"
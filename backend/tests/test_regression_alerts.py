@@ -0,0 +1,170 @@
+"""Tests for paging on auth regressions through PagerDuty and Opsgenie."""
+from unittest.mock import MagicMock, Mock, patch
+
+import pytest
+
+from app.core.air_gap import AirGapViolation
+from app.core.config import settings
+from app.models.incident_alert import (
+    AuthRegression,
+    AuthRegressionStatus,
+    IncidentConnector,
+    IncidentProvider,
+    IncidentStatus,
+    RegressionIncident,
+    RouteProtectionObservation,
+)
+from app.models.repository import Repository
+from app.services.regression_alert_service import (
+    OpsgenieClient,
+    PagerDutyClient,
+    RegressionAlertService,
+    incident_details,
+)
+
+
+def observe(endpoint, protection, exposure="internet_facing", scan_id=2, line=10):
+    """A route as one scan saw it."""
+    return RouteProtectionObservation(
+        repository_id=3, scan_id=scan_id, endpoint=endpoint, protection=protection, exposure=exposure,
+        file_path="routes.js", line=line, code=f"router.x('{endpoint}')", policy_ids=[8] if protection else [],
+    )
+
+
+def make_regression(status=AuthRegressionStatus.OPEN):
+    """Regression 5: DELETE /api/expenses/{} lost its guard between scans 1 and 2."""
+    regression = Mock(spec=AuthRegression)
+    regression.id, regression.repository_id, regression.status = 5, 3, status
+    regression.endpoint, regression.exposure, regression.previous_protection = "DELETE /api/expenses/{}", "internet_facing", "registration"
+    regression.description = "DELETE /api/expenses/{} lost its guard"
+    regression.evidence = {
+        "before": {
+            "file_path": "routes.js", "line": 10, "code": "router.delete('/api/expenses/:id', requireAuth, remove)",
+            "protection": "registration", "policy_ids": [8], "scan_id": 1, "commit": "abc1234",
+        },
+        "after": {
+            "file_path": "routes.js", "line": 12, "code": "router.delete('/api/expenses/:id', remove) // ask dana@acme.io",
+            "scan_id": 2, "commit": None,
+        },
+    }
+    return regression
+
+
+@pytest.fixture
+def make_service(make_db):
+    """Build services whose tenant-scoped queries return records by model."""
+
+    def build(records):
+        service = RegressionAlertService(make_db(records), tenant_id="acme")
+        service._commit = MagicMock(side_effect=lambda scan_id: f"commit{scan_id}")
+        return service
+
+    return build
+
+
+def test_only_lost_authentication_on_internet_facing_routes_regresses(make_service):
+    """Test which protection changes open a regression, its evidence, and resolving fixed routes."""
+    fixed = Mock(spec=AuthRegression, endpoint="PUT /api/expenses/{}/approve", status=AuthRegressionStatus.OPEN)
+    service = make_service({AuthRegression: [fixed]})
+    repository = Mock(spec=Repository, id=3)
+    repository.name = "expenses"
+    previous = [
+        observe("DELETE /api/expenses/{}", "registration", scan_id=1),
+        observe("GET /api/expenses", "middleware", exposure="internal_only", scan_id=1),
+        observe("GET /healthz", "controller", scan_id=1),
+        observe("GET /api/status", "public", scan_id=1),
+        observe("POST /api/expenses", "policy", scan_id=1),
+        observe("PUT /api/expenses/{}/approve", None, scan_id=1),
+    ]
+    current = [
+        observe("DELETE /api/expenses/{}", None, line=12),
+        observe("GET /api/expenses", None, exposure="internal_only"),
+        observe("GET /healthz", None),
+        observe("GET /api/status", None),
+        observe("POST /api/expenses", "public"),
+        observe("PUT /api/expenses/{}/approve", "middleware"),
+    ]
+
+    opened, resolved = service.detect(repository, 2, current, previous)
+
+    (regression,) = opened
+    assert (regression.endpoint, regression.previous_protection, regression.previous_scan_id) == (
+        "DELETE /api/expenses/{}", "registration", 1,
+    )
+    assert regression.evidence["before"] == {
+        "file_path": "routes.js", "line": 10, "code": "router.x('DELETE /api/expenses/{}')", "protection": "registration",
+        "policy_ids": [8], "scan_id": 1, "commit": "commit1",
+    }
+    assert regression.evidence["after"]["line"] == 12 and regression.evidence["after"]["commit"] == "commit2"
+    assert "was protected by a guard on its registration at routes.js:10" in regression.description
+    assert resolved == [fixed] and fixed.status == AuthRegressionStatus.RESOLVED
+
+
+def test_clients_send_evidence_to_pagerduty_and_opsgenie():
+    """Test trigger and resolve requests of both providers."""
+    regression = make_regression()
+    with patch.object(settings, "REDACTION_ENABLED", True), patch.object(settings, "REDACTION_CONFIG_PATH", ""):
+        details = incident_details(regression, "expenses")
+    assert details["before"] == "routes.js:10" and details["previous_scan_commit"] == "abc1234"
+    assert details["previous_protection"] == "a guard on its registration"
+    assert "scan_commit" not in details and details["previous_policy_ids"] == "8"
+
+    client = MagicMock()
+    pagerduty = PagerDutyClient(Mock(spec=IncidentConnector, base_url=None, secret="R0UTING"), client)
+    pagerduty.trigger("key-5", "Authentication removed", details, "long description")
+    pagerduty.resolve("key-5")
+    (url,), kwargs = client.post.call_args_list[0]
+    assert url == "https://events.pagerduty.com/v2/enqueue"
+    event = kwargs["json"]
+    assert (event["routing_key"], event["event_action"], event["dedup_key"]) == ("R0UTING", "trigger", "key-5")
+    assert (event["payload"]["severity"], event["payload"]["component"]) == ("critical", "DELETE /api/expenses/{}")
+    assert event["payload"]["custom_details"]["after_code"] == "router.delete('/api/expenses/:id', remove) // ask [REDACTED_EMAIL]"
+    assert client.post.call_args_list[1].kwargs["json"] == {
+        "routing_key": "R0UTING", "event_action": "resolve", "dedup_key": "key-5",
+    }
+
+    client = MagicMock()
+    opsgenie = OpsgenieClient(Mock(spec=IncidentConnector, base_url="https://api.eu.opsgenie.com/", secret="g3nie"), client)
+    opsgenie.trigger("key-5", "x" * 200, details, "long description")
+    opsgenie.resolve("key-5")
+    (url,), kwargs = client.post.call_args_list[0]
+    assert url == "https://api.eu.opsgenie.com/v2/alerts"
+    assert kwargs["headers"]["Authorization"] == "GenieKey g3nie"
+    alert = kwargs["json"]
+    assert (alert["alias"], alert["priority"], len(alert["message"])) == ("key-5", "P1", 130)
+    assert alert["details"] is details
+    assert client.post.call_args_list[1].args == ("https://api.eu.opsgenie.com/v2/alerts/key-5/close?identifierType=alias",)
+
+
+def test_delivery_records_failures_and_resolves_triggered_incidents(make_service):
+    """Test triggering through each covering connector, recording a failed one, and resolving later."""
+    pagerduty = Mock(spec=IncidentConnector, id=1, provider=IncidentProvider.PAGERDUTY, base_url=None, secret="r", repository_id=None)
+    opsgenie = Mock(spec=IncidentConnector, id=2, provider=IncidentProvider.OPSGENIE, base_url=None, secret="g", repository_id=3)
+    other = Mock(spec=IncidentConnector, id=3, provider=IncidentProvider.PAGERDUTY, base_url=None, secret="o", repository_id=9)
+    service = make_service({IncidentConnector: [pagerduty, opsgenie, other], RegressionIncident: []})
+    regression = make_regression()
+
+    def ensure_host_allowed(host, component):
+        if host == "api.opsgenie.com":
+            raise AirGapViolation("incident_alerting may not reach api.opsgenie.com")
+
+    client = MagicMock()
+    with patch("app.services.regression_alert_service.ensure_host_allowed", side_effect=ensure_host_allowed):
+        counts = service.deliver(regression, "expenses", client)
+
+    assert counts == {"triggered": 1, "resolved": 0, "failed": 1}
+    incidents = {i.connector_id: i for i in (c.args[0] for c in service.db.add.call_args_list)}
+    assert sorted(incidents) == [1, 2]
+    assert incidents[1].status == IncidentStatus.TRIGGERED
+    assert incidents[1].dedup_key.startswith("policy-miner-auth-regression-5-")
+    assert incidents[2].status == IncidentStatus.FAILED
+    assert opsgenie.last_error == incidents[2].error == "incident_alerting may not reach api.opsgenie.com"
+    assert client.post.call_count == 1
+
+    regression.status = AuthRegressionStatus.RESOLVED
+    service = make_service({IncidentConnector: [pagerduty, opsgenie], RegressionIncident: list(incidents.values())})
+    client = MagicMock()
+    counts = service.deliver(regression, "expenses", client)
+    assert counts == {"triggered": 0, "resolved": 1, "failed": 0}
+    assert client.post.call_args.kwargs["json"]["event_action"] == "resolve"
+    assert incidents[1].status == IncidentStatus.RESOLVED and incidents[2].status == IncidentStatus.FAILED
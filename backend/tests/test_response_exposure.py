@@ -0,0 +1,182 @@
+"""Tests for sensitive response fields reaching roles the data visibility policy excludes."""
+import json
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.mass_assignment_service import parse_models
+from app.services.response_exposure_service import (
+    FieldClassifier,
+    ResponseExposureService,
+    load_visibility_policy,
+    response_fields,
+    serializer_models,
+)
+
+GO_MODELS = """package models
+
+type Employee struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Salary   float64 `json:"salary"`
+	SSN      string  `json:"ssn"`
+	Password string  `json:"-"`
+}
+"""
+
+GO_HANDLERS = """package handlers
+
+func ListEmployees(w http.ResponseWriter, r *http.Request) {
+	var employees []models.Employee
+	db.Find(&employees)
+	json.NewEncoder(w).Encode(employees)
+}
+
+func GetSalary(c *gin.Context) {
+	e := loadEmployee(c)
+	c.JSON(http.StatusOK, gin.H{"name": e.Name, "salary": e.Salary})
+}
+
+func GetPublicProfile(c *gin.Context) {
+	e := loadEmployee(c)
+	c.JSON(http.StatusOK, gin.H{"name": e.Name, "dob": e.DateOfBirth})
+}
+
+func RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/employees", RequireRole("EMPLOYEE")(ListEmployees)).Methods("GET")
+	r.HandleFunc("/api/employees/{id}/salary", RequireRole("HR")(GetSalary)).Methods("GET")
+	r.HandleFunc("/api/employees/{id}/profile", GetPublicProfile).Methods("GET")
+}
+"""
+
+MONGOOSE = """const UserSchema = new mongoose.Schema({
+  email: String,
+  password: String,
+  dateOfBirth: Date,
+});
+module.exports = mongoose.model('User', UserSchema);
+"""
+
+EXPRESS = """router.get('/me', async (req, res) => {
+  const user = await User.findById(req.user.id).select('-password');
+  res.json(user);
+});
+
+router.get('/users', async (req, res) => {
+  const users = await User.find();
+  res.json({ users, total: users.length });
+});
+"""
+
+DJANGO = """class Employee(models.Model):
+    name = models.CharField()
+    salary = models.DecimalField()
+    tax_id = models.CharField()
+
+
+class EmployeeSerializer(serializers.ModelSerializer):
+    class Meta:
+        model = Employee
+        fields = ["name", "salary"]
+
+
+def directory(request):
+    serializer = EmployeeSerializer(Employee.objects.all(), many=True)
+    return Response(serializer.data)
+"""
+
+
+def _models(classify, *files):
+    models = {}
+    for path, text in files:
+        for model in parse_models(path, text, keep=lambda f: True, output=True):
+            models.setdefault(model.name, model)
+    models.update(serializer_models({p: t for p, t in files if p.endswith(".py")}, models))
+    for model in models.values():
+        model.fields = [f for f in model.fields if classify(f)]
+    return models
+
+
+def _responses(text, models, classify):
+    lines = text.split("\n")
+    return [(r.line, r.model, r.fields) for r in response_fields(lines, 0, len(lines), models, classify)]
+
+
+def test_returned_sensitive_fields_across_languages():
+    """Test Go encoders and literals, Mongoose projections, shorthand properties, and DRF serializer field lists."""
+    classify = FieldClassifier(load_visibility_policy(""))
+    assert [classify(name) for name in ("SSN", "baseSalary", "classname", "name", "apiKey")] == [
+        "government_id", "compensation", None, None, "credential",
+    ]  # fmt: skip
+
+    models = _models(classify, ("models.go", GO_MODELS))
+    assert _responses(GO_HANDLERS, models, classify) == [
+        (6, "Employee", ["salary", "ssn"]),
+        (11, None, ["salary"]),
+        (16, None, ["dob"]),
+    ]
+
+    models = _models(classify, ("models/user.js", MONGOOSE))
+    lines = EXPRESS.split("\n")
+    # The projection removes the password from /me but not from /users
+    assert [(r.line, r.fields) for r in response_fields(lines, 0, 4, models, classify)] == [(3, ["dateOfBirth"])]
+    assert [(r.line, r.fields) for r in response_fields(lines, 5, len(lines), models, classify)] == [(8, ["password", "dateOfBirth"])]
+
+    models = _models(classify, ("employees.py", DJANGO))
+    assert models["EmployeeSerializer"].fields == ["salary"]
+    assert _responses(DJANGO, models, classify) == [(15, "EmployeeSerializer", ["salary"])]
+
+
+def test_service_flags_fields_callers_may_not_see(tmp_path):
+    """Test caller roles against allowed roles, anonymous endpoints, and visibility policy overrides."""
+    root = tmp_path / "3"
+    (root / "models").mkdir(parents=True)
+    (root / "models" / "models.go").write_text(GO_MODELS)
+    (root / "handlers.go").write_text(GO_HANDLERS)
+    lines = GO_HANDLERS.split("\n")
+    policies = []
+    for policy_id, subject, resource, line in [
+        (1, "EMPLOYEE", "/api/employees", 20),
+        (2, "HR", "/api/employees/{id}/salary", 21),
+        (3, "Anonymous", "/api/employees/{id}/profile", 22),
+    ]:
+        policy = Policy(id=policy_id, repository_id=3, subject=subject, resource=resource, action="GET")
+        policy.evidence = [Evidence(file_path="handlers.go", line_start=line, line_end=line, code_snippet=lines[line - 1])]
+        policies.append(policy)
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 3, "hr"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    query.all.return_value = policies
+    service = ResponseExposureService(db, "acme", clone_dir=str(tmp_path))
+
+    result = service.exposures()
+    assert result["summary"] == {
+        "total": 2,
+        "repositories_scanned": 1,
+        "repositories_with_findings": 1,
+        "by_severity": {"critical": 1, "high": 1},
+        "by_class": {"personal": 1, "compensation": 1, "government_id": 1},
+    }
+    anonymous, listing = result["findings"]
+    assert (anonymous["endpoint"], anonymous["function"], anonymous["fields"], anonymous["anonymous"]) == (
+        "GET /api/employees/{id}/profile", "GetPublicProfile", {"dob": "personal"}, True,
+    )  # fmt: skip
+    assert (listing["line_start"], listing["model"], listing["unauthorized_roles"], listing["policy_ids"]) == (6, "Employee", ["EMPLOYEE"], [1])
+    assert listing["description"] == (
+        "GET /api/employees returns salary (compensation), ssn (government id) to EMPLOYEE, "
+        "which the data visibility policy restricts to ADMIN, FINANCE, HR, PAYROLL"
+    )
+
+    overrides = tmp_path / "visibility.json"
+    overrides.write_text(json.dumps({"classes": {"compensation": {"roles": ["ADMIN", "EMPLOYEE"]}, "personal": {"patterns": []}}}))
+    service_policy = load_visibility_policy(str(overrides))
+    assert service_policy["classes"]["compensation"]["severity"] == "medium"
+    assert FieldClassifier(service_policy)("dob") is None
+    overrides.write_text("{")
+    with pytest.raises(ValueError, match="Invalid data visibility policy file"):
+        load_visibility_policy(str(overrides))
@@ -0,0 +1,111 @@
+"""Tests for what-if role change impact analysis."""
+from unittest.mock import MagicMock, patch
+
+import pytest
+
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.role_impact_service import (
+    EndpointImpact,
+    RoleChangeOperation,
+    RoleImpactService,
+)
+
+
+@pytest.fixture
+def service():
+    """Create a service whose policies map to the sample expense endpoints."""
+    rules = [
+        EndpointRule(method="GET", path="/api/expenses", roles=[], policy_ids=[1]),
+        EndpointRule(method="DELETE", path="/api/expenses/{id}", roles=["ADMIN"], policy_ids=[2]),
+        EndpointRule(
+            method="PUT",
+            path="/api/expenses/{id}/approve",
+            roles=["MANAGER", "DIRECTOR"],
+            conditions=["amount > 5000 requires DIRECTOR"],
+            policy_ids=[3],
+        ),
+        EndpointRule(method="GET", path="/api/reports", roles=["MANAGER"], policy_ids=[4]),
+        EndpointRule(method="GET", path="/api/team", roles=["LEAD"], policy_ids=[5]),
+    ]
+    service = RoleImpactService(MagicMock(), tenant_id="tenant-1")
+    service.load_assignments = MagicMock(
+        return_value={
+            "alice@example.com": {"MANAGER"},
+            "bob@example.com": {"MANAGER", "DIRECTOR"},
+            "carol@example.com": {"LEAD"},
+            "dave@example.com": {"ADMIN"},
+        }
+    )
+    with (
+        patch("app.services.role_impact_service.DecisionSimulationService.load_policies", return_value=[]),
+        patch("app.services.role_impact_service.EndpointMappingService.map_policies", return_value=rules),
+    ):
+        yield service
+
+
+def by_endpoint(report):
+    """Index report endpoints by key."""
+    return {e["endpoint"]: e for e in report["endpoints"]}
+
+
+def test_remove_role(service):
+    """Test removing a role orphans or narrows the endpoints it gated."""
+    report = service.analyze(RoleChangeOperation.REMOVE, "manager")
+    endpoints = by_endpoint(report)
+
+    assert set(endpoints) == {"PUT /api/expenses/{id}/approve", "GET /api/reports"}
+    assert endpoints["GET /api/reports"]["impact"] == EndpointImpact.ORPHANED
+    assert endpoints["GET /api/reports"]["users_losing_access"] == ["alice@example.com", "bob@example.com"]
+
+    approve = endpoints["PUT /api/expenses/{id}/approve"]
+    assert approve["impact"] == EndpointImpact.NARROWED
+    assert approve["roles_after"] == ["DIRECTOR"]
+    # Bob keeps access through DIRECTOR
+    assert approve["users_losing_access"] == ["alice@example.com"]
+
+    users = {u["user_identifier"]: u for u in report["users"]}
+    assert set(users) == {"alice@example.com", "bob@example.com"}
+    assert users["bob@example.com"]["endpoints_lost"] == ["GET /api/reports"]
+
+
+def test_merge_role(service):
+    """Test merging a role widens endpoints for holders of both roles."""
+    report = service.analyze(RoleChangeOperation.MERGE, "MANAGER", target_role="LEAD")
+    endpoints = by_endpoint(report)
+
+    assert endpoints["GET /api/team"]["impact"] == EndpointImpact.WIDENED
+    assert endpoints["GET /api/team"]["users_gaining_access"] == ["alice@example.com", "bob@example.com"]
+    assert endpoints["GET /api/reports"]["roles_after"] == ["LEAD"]
+    assert endpoints["GET /api/reports"]["users_gaining_access"] == ["carol@example.com"]
+    assert endpoints["GET /api/reports"]["users_losing_access"] == []
+    assert "DELETE /api/expenses/{id}" not in endpoints
+
+    users = {u["user_identifier"]: u for u in report["users"]}
+    assert users["alice@example.com"]["roles_after"] == ["LEAD"]
+    assert users["carol@example.com"]["endpoints_gained"] == [
+        "PUT /api/expenses/{id}/approve",
+        "GET /api/reports",
+    ]
+
+
+def test_condition_references_are_reported(service):
+    """Test that conditions naming the role surface even if no endpoint lists it."""
+    report = service.analyze(RoleChangeOperation.REMOVE, "DIRECTOR")
+    approve = by_endpoint(report)["PUT /api/expenses/{id}/approve"]
+
+    assert approve["conditions_referencing_role"] == ["amount > 5000 requires DIRECTOR"]
+    assert report["role_known"] is True
+
+
+@pytest.mark.parametrize(
+    "operation,role,target_role",
+    [
+        ("rename", "MANAGER", None),
+        (RoleChangeOperation.MERGE, "MANAGER", None),
+        (RoleChangeOperation.MERGE, "MANAGER", "manager"),
+    ],
+)
+def test_invalid_changes(service, operation, role, target_role):
+    """Test that malformed changes are rejected."""
+    with pytest.raises(ValueError):
+        service.analyze(operation, role, target_role=target_role)
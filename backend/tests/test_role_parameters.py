@@ -0,0 +1,172 @@
+"""Tests for role requirements read from configuration at runtime, and their bindings."""
+
+from app.models.policy import Policy
+from app.models.role_parameter import BindingSource, RoleParameterBinding, RoleParameterUsage
+from app.services.config_policy_extractor import role_parameter
+from app.services.go_route_extractor import extract_go_routes
+from app.services.role_parameter_service import RoleParameterService, snapshot_values
+from app.services.typescript_route_extractor import build_environment, resolve_guard
+
+GIN_MAIN = """package main
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/app/config"
+	"example.com/app/middleware"
+	"github.com/acme/authz"
+)
+
+func main() {
+	cfg := config.Load()
+	r := gin.Default()
+	r.POST("/refunds/:id/approve", middleware.RequireRole(cfg.ApproverRole), approveRefund)
+	r.DELETE("/tenants/:id", authz.RequireRole(os.Getenv("TENANT_ADMIN_ROLE")), removeTenant)
+}
+"""
+
+GIN_MIDDLEWARE = """package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+"""
+
+
+def test_configured_roles_become_parameters_and_request_values_do_not():
+    """Test config selectors, env lookups, and role lookups are parameters; request data and enums are not."""
+    assert role_parameter("cfg.ApproverRole") == "${cfg.ApproverRole}"
+    assert role_parameter('os.Getenv("ADMIN_ROLE")') == "${env.ADMIN_ROLE}"
+    assert role_parameter("process.env.ADMIN_ROLE") == "${env.ADMIN_ROLE}"
+    assert role_parameter('viper.GetString("roles.approver")') == "${config.roles.approver}"
+    assert role_parameter('roleStore.RoleFor("refund")') == "${roleStore.RoleFor.refund}"
+    assert role_parameter("req.body.role") is None
+    assert role_parameter('c.GetString("role")') is None
+    assert role_parameter("auth.RoleAdmin") is None
+
+
+def test_gin_and_typescript_guards_keep_configured_roles_as_parameters():
+    """Test RequireRole(cfg.ApproverRole) is mined with a bindable role instead of a garbage condition."""
+    routes = {
+        (f.action, f.resource): f
+        for f in extract_go_routes({"main.go": GIN_MAIN, "middleware/auth.go": GIN_MIDDLEWARE})
+    }
+
+    approve = routes[("POST", "/refunds/:id/approve")]
+    assert approve.subject == "${cfg.ApproverRole}"
+    assert approve.conditions is None
+    # Middleware defined outside the repository falls back to its name and still keeps the parameter
+    assert routes[("DELETE", "/tenants/:id")].subject == "${env.TENANT_ADMIN_ROLE}"
+
+    env = build_environment({"src/guards.ts": "export function requireRole(...roles: string[]) {}\n"})
+    assert resolve_guard("requireRole(config.roles.approver)", env).roles == ["${config.roles.approver}"]
+    assert resolve_guard("requireRole(req.body.role)", env).unresolved is not None
+
+
+def test_bindings_render_policies_and_repository_bindings_override_workspace_ones(make_db):
+    """Test recorded usages are rendered with bound values, preferring the repository's own binding."""
+    policy = Policy(id=7, repository_id=3, subject="${cfg.ApproverRole}", conditions="amount < ${cfg.Limit}")
+    other = Policy(id=8, repository_id=4, subject="${cfg.ApproverRole}")
+    bindings = [
+        RoleParameterBinding(name="cfg.ApproverRole", values=["approver"], repository_id=None),
+        RoleParameterBinding(name="cfg.ApproverRole", values=["finance-approver", "cfo"], repository_id=3),
+    ]
+    usages = [
+        RoleParameterUsage(
+            repository_id=3,
+            policy_id=7,
+            parameters=["cfg.ApproverRole", "cfg.Limit"],
+            subject_template=policy.subject,
+            conditions_template=policy.conditions,
+        ),
+        RoleParameterUsage(repository_id=4, policy_id=8, parameters=["cfg.ApproverRole"], subject_template=other.subject),
+    ]
+    db = make_db({Policy: [policy, other], RoleParameterBinding: bindings, RoleParameterUsage: usages})
+
+    assert RoleParameterService(db).apply() == 2
+    assert policy.subject == "finance-approver or cfo"
+    # An unbound parameter stays visible in the rendered text
+    assert policy.conditions == "amount < ${cfg.Limit}"
+    assert other.subject == "approver"
+    assert usages[0].rendered_subject == "finance-approver or cfo"
+
+    rows = {row["name"]: row for row in RoleParameterService(db).parameters()}
+    assert rows["cfg.ApproverRole"]["bound"] is True
+    assert rows["cfg.ApproverRole"]["overrides"] == {3: ["finance-approver", "cfo"]}
+    assert rows["cfg.Limit"]["bound"] is False
+
+
+SNAPSHOT = {
+    "env": {"MAX_REFUND": "500", "DATABASE_PASSWORD": "hunter2"},
+    "config": {"limits": {"max_self_approve_amount": 5000}, "approver_role": "finance-approver", "feature": {"strict": True}},
+}
+
+
+def test_snapshot_values_resolve_env_lookups_config_paths_and_selectors():
+    """Test env variables, config getters, and Go-style selectors matched against YAML keys."""
+    names = ["cfg.Limits.MaxSelfApproveAmount", "env.MAX_REFUND", "config.feature.strict", "cfg.ApproverRole", "cfg.Missing", "env.HOME"]
+    assert snapshot_values(names, SNAPSHOT) == {
+        "cfg.Limits.MaxSelfApproveAmount": ["5000"],
+        "env.MAX_REFUND": ["500"],
+        "config.feature.strict": ["true"],
+        "cfg.ApproverRole": ["finance-approver"],
+    }
+    # Sections are not values
+    assert snapshot_values(["cfg.Limits"], SNAPSHOT) == {}
+
+
+def test_scan_snapshot_binds_used_parameters_and_records_provenance(make_db):
+    """Test a snapshot renders conditions with concrete values and each policy records the binding used."""
+    policy = Policy(id=7, repository_id=3, subject="${cfg.ApproverRole}", conditions="expense.amount > ${cfg.Limits.MaxSelfApproveAmount} requires DIRECTOR")
+    workspace = RoleParameterBinding(id=1, name="cfg.ApproverRole", values=["approver"], repository_id=None, bound_by="ana@acme.io")
+    usage = RoleParameterUsage(
+        repository_id=3,
+        policy_id=7,
+        parameters=["cfg.ApproverRole", "cfg.Limits.MaxSelfApproveAmount", "env.REGION"],
+        subject_template=policy.subject,
+        conditions_template=policy.conditions,
+    )
+    rows = {Policy: [policy], RoleParameterBinding: [workspace], RoleParameterUsage: [usage]}
+    db = make_db(rows)
+    db.add.side_effect = lambda row: rows[type(row)].append(row)
+    service = RoleParameterService(db, "acme")
+
+    result = service.apply_snapshot(3, {"env": SNAPSHOT["env"], "config": {"limits": SNAPSHOT["config"]["limits"]}}, scan_id=42)
+    assert result == {
+        "repository_id": 3,
+        "scan_id": 42,
+        "resolved": {"cfg.Limits.MaxSelfApproveAmount": ["5000"]},
+        "unresolved": ["cfg.ApproverRole", "env.REGION"],
+        "policies_updated": 1,
+    }
+    assert (policy.subject, policy.conditions) == ("approver", "expense.amount > 5000 requires DIRECTOR")
+    # Only parameters in use are stored; the rest of the snapshot is not
+    [snapshot_binding] = [b for b in rows[RoleParameterBinding] if b.repository_id == 3]
+    assert (snapshot_binding.source, snapshot_binding.scan_id) == (BindingSource.SCAN_SNAPSHOT, 42)
+    assert usage.provenance["cfg.Limits.MaxSelfApproveAmount"]["source"] == BindingSource.SCAN_SNAPSHOT
+    assert usage.provenance["cfg.ApproverRole"] == {
+        "values": ["approver"],
+        "source": BindingSource.MANUAL,
+        "scope": "workspace",
+        "binding_id": 1,
+        "scan_id": None,
+        "bound_by": "ana@acme.io",
+    }
+
+    provenance = service.provenance(7)
+    assert [(b["name"], b["scan_id"]) for b in provenance["bindings"]] == [("cfg.ApproverRole", None), ("cfg.Limits.MaxSelfApproveAmount", 42)]
+    assert provenance["unbound"] == ["env.REGION"]
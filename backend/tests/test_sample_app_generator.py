@@ -0,0 +1,110 @@
+"""Tests for the synthetic sample-app corpus generator."""
+import pytest
+
+from app.services.endpoint_mapping_service import EndpointMappingService, EndpointRule
+from tests.fixtures.sample_app_generator import (
+    RENDERERS,
+    generate_corpus,
+    generate_sample_app,
+    normalize_path,
+    score_endpoint_rules,
+    write_corpus,
+)
+
+
+@pytest.mark.parametrize("framework", list(RENDERERS))
+def test_generation_is_deterministic(framework):
+    """Test that the same seed always produces the same app."""
+    first = generate_sample_app(framework, seed=42)
+    second = generate_sample_app(framework, seed=42)
+
+    assert first.content == second.content
+    assert first.expected_rules == second.expected_rules
+    assert generate_sample_app(framework, seed=43).content != first.content
+
+
+@pytest.mark.parametrize("framework", list(RENDERERS))
+def test_every_expected_route_is_present(framework):
+    """Benchmark: route registrations are discoverable in every framework."""
+    sample = generate_sample_app(framework, seed=3, rule_count=10)
+
+    found = {(m, normalize_path(p)) for m, p in EndpointMappingService.find_routes(sample.content)}
+    expected = {(r.method, r.path) for r in sample.expected_rules}
+
+    assert expected <= found
+
+
+@pytest.mark.parametrize("framework", list(RENDERERS))
+def test_expected_roles_appear_in_source(framework):
+    """Test that each rule's roles are rendered into the app."""
+    sample = generate_sample_app(framework, seed=5)
+
+    for rule in sample.expected_rules:
+        for role in rule.roles:
+            assert role in sample.content
+
+
+def test_ground_truth_has_unique_endpoints():
+    """Test that generated rules never repeat an endpoint."""
+    sample = generate_sample_app("flask", seed=1, rule_count=20)
+    keys = [r.key for r in sample.expected_rules]
+
+    assert len(keys) == len(set(keys)) == 20
+
+
+def test_unknown_framework_raises():
+    """Test that unsupported frameworks are rejected."""
+    with pytest.raises(ValueError):
+        generate_sample_app("cobol_cics")
+
+
+def test_normalize_path():
+    """Test route parameter normalization."""
+    assert normalize_path("/api/x/<int:id>") == "/api/x/{id}"
+    assert normalize_path("/api/x/:id/approve") == "/api/x/{id}/approve"
+    assert normalize_path("api/x/{id}") == "/api/x/{id}"
+
+
+def test_score_perfect_extraction():
+    """Test scoring when the analyzer recovers every rule."""
+    sample = generate_sample_app("express", seed=2)
+
+    score = score_endpoint_rules(sample.expected_rules, sample.expected_rules)
+
+    assert score.precision == 1.0
+    assert score.recall == 1.0
+    assert score.f1 == 1.0
+
+
+def test_score_penalizes_missing_and_wrong_roles():
+    """Test scoring with a missed endpoint, a wrong role, and a spurious endpoint."""
+    expected = [
+        EndpointRule(method="GET", path="/api/a", roles=["ADMIN"]),
+        EndpointRule(method="PUT", path="/api/a/{id}", roles=["MANAGER"]),
+        EndpointRule(method="DELETE", path="/api/a/{id}", roles=[]),
+    ]
+    extracted = [
+        EndpointRule(method="GET", path="/api/a", roles=["admin"]),
+        EndpointRule(method="PUT", path="/api/a/:id", roles=["DIRECTOR"]),
+        EndpointRule(method="POST", path="/api/b", roles=[]),
+    ]
+
+    score = score_endpoint_rules(expected, extracted)
+
+    assert score.true_positives == 1
+    assert score.missing == ["DELETE /api/a/{id}"]
+    assert score.unexpected == ["POST /api/b"]
+    assert score.role_mismatches == ["PUT /api/a/{id}"]
+    assert score.precision == pytest.approx(1 / 3)
+    assert score.recall == pytest.approx(1 / 3)
+
+
+def test_write_corpus(tmp_path):
+    """Test writing a corpus to disk."""
+    corpus = generate_corpus(frameworks=["go_mux", "spring"], seeds=[0, 1])
+
+    paths = write_corpus(corpus, tmp_path)
+
+    assert len(paths) == 4
+    assert {p.suffix for p in paths} == {".go", ".java"}
+    assert all(p.read_text() for p in paths)
@@ -0,0 +1,81 @@
+"""Tests for saved views."""
+from unittest.mock import MagicMock
+
+import pytest
+
+from app.models.saved_view import SavedView
+from app.services.saved_view_service import SavedViewService, SavedViewType, share_path, validate_filters
+
+
+@pytest.fixture
+def db():
+    """Mock session whose queries find no existing views."""
+    session = MagicMock()
+    query = session.query.return_value
+    query.filter.return_value = query
+    query.first.return_value = None
+    return session
+
+
+def test_create_view(db):
+    """Test saving a view scoped to the workspace with a share token."""
+    service = SavedViewService(db, tenant_id="tenant-1")
+
+    view = service.create_view(
+        name="  payments-team critical findings ",
+        view_type=SavedViewType.CONFLICTS,
+        filters={"application_id": [3, 4], "severity": "critical"},
+        created_by="lead@example.com",
+    )
+
+    assert view.name == "payments-team critical findings"
+    assert view.tenant_id == "tenant-1"
+    assert len(view.share_token) >= 32
+    assert share_path(view) == f"/api/v1/saved-views/shared/{view.share_token}"
+    db.add.assert_called_once_with(view)
+    db.commit.assert_called_once()
+
+
+def test_create_view_rejects_duplicate_name(db):
+    """Test names are unique per page within a workspace."""
+    db.query.return_value.first.return_value = SavedView(name="mine", view_type=SavedViewType.POLICIES)
+
+    with pytest.raises(ValueError, match="already exists"):
+        SavedViewService(db).create_view("mine", SavedViewType.POLICIES, {})
+
+
+@pytest.mark.parametrize(
+    "view_type,filters,message",
+    [
+        ("dashboards", {}, "Unknown view type"),
+        (SavedViewType.POLICIES, {"risk": {"nested": 1}}, "plain value"),
+        (SavedViewType.POLICIES, {"ids": [1, [2]]}, "list of plain values"),
+        (SavedViewType.POLICIES, {"": 1}, "non-empty"),
+    ],
+)
+def test_create_view_validation(db, view_type, filters, message):
+    """Test invalid pages and filters are rejected."""
+    with pytest.raises(ValueError, match=message):
+        SavedViewService(db).create_view("view", view_type, filters)
+
+
+def test_validate_filters_limit():
+    """Test the filter count cap."""
+    with pytest.raises(ValueError, match="at most"):
+        validate_filters({f"f{i}": i for i in range(51)})
+
+
+def test_shared_view_not_found(db):
+    """Test unknown share tokens do not resolve."""
+    with pytest.raises(ValueError, match="Shared view not found"):
+        SavedViewService(db, tenant_id="tenant-1").get_shared_view("nope")
+
+
+def test_regenerate_share_token(db):
+    """Test a new token invalidates the old link."""
+    view = SavedView(id=1, name="v", view_type=SavedViewType.POLICIES, filters={}, share_token="old")
+    db.query.return_value.first.return_value = view
+
+    SavedViewService(db).regenerate_share_token(1)
+
+    assert view.share_token != "old"
@@ -0,0 +1,119 @@
+"""Tests for the scan comparison view."""
+from datetime import datetime
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.policy_change import ChangeType, PolicyChange
+from app.models.scan_progress import ScanProgress
+from app.services.scan_comparison_service import (
+    ComparisonGroup,
+    DiffOp,
+    ScanComparisonService,
+    condition_diff,
+)
+
+
+def make_change(change_id, change_type, before=None, after=None, policy_id=None, previous_policy_id=None):
+    """Create a mock policy change from (subject, resource, action, conditions) tuples."""
+    change = Mock(spec=PolicyChange)
+    change.id = change_id
+    change.change_type = change_type
+    change.policy_id = policy_id
+    change.previous_policy_id = previous_policy_id
+    for prefix, values in (("before", before), ("after", after)):
+        subject, resource, action, conditions = values or (None, None, None, None)
+        setattr(change, f"{prefix}_subject", subject)
+        setattr(change, f"{prefix}_resource", resource)
+        setattr(change, f"{prefix}_action", action)
+        setattr(change, f"{prefix}_conditions", conditions)
+    return change
+
+
+def make_scan(scan_id, day):
+    """Create a mock completed scan."""
+    scan = Mock(spec=ScanProgress)
+    scan.id = scan_id
+    scan.started_at = datetime(2026, 10, day)
+    scan.completed_at = datetime(2026, 10, day, 1)
+    return scan
+
+
+def test_condition_diff():
+    """Test word-level inline rendering of a threshold change."""
+    segments = condition_diff("amount > 5000 requires DIRECTOR", "amount > 10000 requires DIRECTOR")
+
+    assert segments == [
+        {"op": DiffOp.EQUAL, "text": "amount > "},
+        {"op": DiffOp.DELETE, "text": "5000"},
+        {"op": DiffOp.INSERT, "text": "10000"},
+        {"op": DiffOp.EQUAL, "text": " requires DIRECTOR"},
+    ]
+    assert condition_diff(None, "x == 1") == [{"op": DiffOp.INSERT, "text": "x == 1"}]
+
+
+def test_pair_changes_groups_rules():
+    """Test that a delete and add on the same resource and action become one modification."""
+    changes = [
+        make_change(1, ChangeType.DELETED, before=("Manager", "Expense", "approve", "amount < 5000"), previous_policy_id=10),
+        make_change(2, ChangeType.ADDED, after=("Manager", "expense", "Approve", "amount < 10000"), policy_id=20),
+        make_change(3, ChangeType.ADDED, after=("Admin", "Report", "export", None), policy_id=21),
+        make_change(4, ChangeType.DELETED, before=("User", "Invoice", "delete", None), previous_policy_id=11),
+    ]
+
+    rules = {r.group: r for r in ScanComparisonService.pair_changes(changes)}
+
+    modified = rules[ComparisonGroup.MODIFIED]
+    assert modified.change_ids == [1, 2]
+    assert modified.before.policy_id == 10
+    assert modified.after.policy_id == 20
+    assert modified.changed_fields == ["resource", "action", "conditions"]
+    assert rules[ComparisonGroup.ADDED].after.resource == "Report"
+    assert rules[ComparisonGroup.ADDED].before is None
+    assert rules[ComparisonGroup.REMOVED].before.resource == "Invoice"
+    assert rules[ComparisonGroup.REMOVED].after is None
+
+
+def test_compare_attaches_evidence_for_both_sides():
+    """Test the full view with evidence linked to before and after policies."""
+    db = MagicMock()
+    service = ScanComparisonService(db, tenant_id="tenant-1")
+    service.resolve_scans = Mock(return_value=(make_scan(2, 8), make_scan(1, 1), None))
+    service.load_changes = Mock(
+        return_value=[
+            make_change(1, ChangeType.DELETED, before=("Manager", "Expense", "approve", "a"), previous_policy_id=10),
+            make_change(2, ChangeType.ADDED, after=("Manager", "Expense", "approve", "b"), policy_id=20),
+        ]
+    )
+    evidence = [
+        Mock(id=100, policy_id=10, file_path="old.py", line_start=1, line_end=2, code_snippet="old"),
+        Mock(id=200, policy_id=20, file_path="new.py", line_start=3, line_end=4, code_snippet="new"),
+    ]
+    db.query.return_value.filter.return_value.order_by.return_value.all.return_value = evidence
+
+    result = service.compare(repository_id=5)
+
+    assert result["scan_id"] == 2
+    assert result["previous_scan_id"] == 1
+    assert result["summary"] == {"added": 0, "removed": 0, "modified": 1}
+    rule = result["modified"][0]
+    assert rule["before"]["evidence"][0]["file_path"] == "old.py"
+    assert rule["after"]["evidence"][0]["file_path"] == "new.py"
+
+
+def test_resolve_scans():
+    """Test picking the requested scan and its neighbours."""
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [make_scan(1, 1), make_scan(2, 8), make_scan(3, 15)]
+    service = ScanComparisonService(db)
+
+    scan, previous, following = service.resolve_scans(5, scan_id=2)
+    assert (scan.id, previous.id, following.id) == (2, 1, 3)
+
+    scan, previous, following = service.resolve_scans(5)
+    assert (scan.id, previous.id, following) == (3, 2, None)
+
+    with pytest.raises(ValueError, match="not found"):
+        service.resolve_scans(5, scan_id=99)
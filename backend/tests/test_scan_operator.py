@@ -0,0 +1,152 @@
+"""Tests for the Kubernetes operator reconciling PolicyScan resources."""
+from datetime import UTC, datetime, timedelta
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.repository import Repository
+from app.models.scan_progress import ScanProgress, ScanStatus
+from app.services.scan_operator_service import KubernetesClient, ScanOperatorService
+
+# A Friday evening
+NOW = datetime(2026, 3, 6, 22, 17, tzinfo=UTC)
+
+
+def make_resource(spec, generation=1, status=None):
+    """PolicyScan in the payments namespace."""
+    return {
+        "metadata": {"name": "expense-api", "namespace": "payments", "generation": generation},
+        "spec": spec,
+        "status": status,
+    }
+
+
+def make_service(progress=None, repository=True, active=0):
+    """Service over repository 4 ("expense-api") with the scan progress found for it."""
+    kubernetes = Mock(spec=KubernetesClient)
+    enqueue = Mock(return_value="task-1")
+    service = ScanOperatorService(MagicMock(), kubernetes, enqueue)
+    found = Mock(spec=Repository, id=4, tenant_id="payments")
+    found.name = "expense-api"  # Mock() takes name as its own
+    service._repository = Mock(return_value=found if repository else None)
+    service._progress = Mock(return_value=progress)
+    service._active_scans = Mock(return_value=active)
+    return service, kubernetes, enqueue
+
+
+def conditions(status):
+    """Condition type -> (status, reason)."""
+    return {c["type"]: (c["status"], c["reason"]) for c in status["conditions"]}
+
+
+def test_unscheduled_scan_runs_once_per_generation_and_reports_progress():
+    """Test queueing, following the scan to completion, and a new scan when the spec changes."""
+    service, _, enqueue = make_service()
+    status = service.reconcile(make_resource({"repositoryId": 4, "incremental": False}), NOW)
+
+    enqueue.assert_called_once_with(4, "payments", False)
+    assert status["lastScan"] == {"taskId": "task-1", "queuedAt": "2026-03-06T22:17:00Z", "incremental": False}
+    assert status["scannedGeneration"] == 1 and status["repositoryName"] == "expense-api"
+    assert conditions(status) == {
+        "Ready": ("True", "RepositoryFound"),
+        "Scanning": ("True", "Queued"),
+        "Succeeded": ("Unknown", "NotScanned"),
+    }
+
+    running = Mock(spec=ScanProgress, id=31, status=ScanStatus.PROCESSING, processed_files=5, total_files=20)
+    service._progress.return_value = running
+    status = service.reconcile(make_resource({"repositoryId": 4}, status=status), NOW + timedelta(minutes=1))
+    assert enqueue.call_count == 1
+    assert conditions(status)["Scanning"] == ("True", "Processing")
+    assert next(c for c in status["conditions"] if c["type"] == "Scanning")["message"] == "5 of 20 files processed"
+    queued_since = next(c for c in status["conditions"] if c["type"] == "Scanning")["lastTransitionTime"]
+    assert queued_since == "2026-03-06T22:17:00Z"
+
+    service._progress.return_value = Mock(
+        spec=ScanProgress, id=31, status=ScanStatus.COMPLETED, processed_files=20, policies_extracted=7,
+        completed_at=datetime(2026, 3, 6, 22, 25),
+    )
+    status = service.reconcile(make_resource({"repositoryId": 4}, status=status), NOW + timedelta(minutes=9))
+    assert enqueue.call_count == 1
+    assert status["lastScan"]["completedAt"] == "2026-03-06T22:25:00Z"
+    assert (status["lastScan"]["scanId"], status["lastScan"]["result"], status["lastScan"]["policiesExtracted"]) == (31, "completed", 7)
+    assert conditions(status)["Succeeded"] == ("True", "ScanCompleted")
+    assert conditions(status)["Scanning"] == ("False", "Idle")
+
+    status = service.reconcile(make_resource({"repositoryId": 4}, generation=2, status=status), NOW + timedelta(minutes=10))
+    assert enqueue.call_count == 2
+    assert status["scannedGeneration"] == 2 and "scanId" not in status["lastScan"]
+
+
+def test_scheduled_scans_wait_for_the_cron_run_and_skip_runs_while_scanning():
+    """Test the next scan time, a due run, suspension, and queue failures."""
+    service, _, enqueue = make_service()
+    spec = {"repositoryId": 4, "schedule": "0 3 * * 1-5"}
+    status = service.reconcile(make_resource(spec), NOW)
+    enqueue.assert_not_called()
+    assert status["nextScanTime"] == "2026-03-09T03:00:00Z"
+
+    monday = datetime(2026, 3, 9, 3, 0, 20, tzinfo=UTC)
+    status = service.reconcile(make_resource(spec, status=status), monday)
+    enqueue.assert_called_once_with(4, "payments", True)
+    assert status["nextScanTime"] == "2026-03-10T03:00:00Z"
+
+    # Still scanning at Tuesday's run, which is skipped
+    service._progress.return_value = Mock(spec=ScanProgress, id=32, status=ScanStatus.PROCESSING, processed_files=1, total_files=9)
+    status = service.reconcile(make_resource(spec, status=status), monday + timedelta(days=1))
+    assert enqueue.call_count == 1
+    assert status["nextScanTime"] == "2026-03-11T03:00:00Z"
+
+    suspended = service.reconcile(make_resource({**spec, "suspend": True}, generation=2, status=status), monday)
+    assert "nextScanTime" not in suspended
+
+    failing, _, enqueue = make_service()
+    enqueue.side_effect = RuntimeError("broker down")
+    status = failing.reconcile(make_resource({"repositoryId": 4}), NOW)
+    assert conditions(status)["Scanning"] == ("False", "QueueFailed")
+    assert "scannedGeneration" not in status  # Retried next resync
+
+
+def test_invalid_specs_missing_repositories_and_lost_scans():
+    """Test Ready reasons and a queued scan that never starts."""
+    service, _, enqueue = make_service()
+    status = service.reconcile(make_resource({"repositoryId": 4, "schedule": "0 0 30 2 *"}), NOW)
+    assert conditions(status) == {"Ready": ("False", "InvalidSpec")}
+
+    missing, _, _ = make_service(repository=False)
+    status = missing.reconcile(make_resource({"repositoryId": 9}), NOW)
+    assert conditions(status) == {"Ready": ("False", "RepositoryNotFound")}
+
+    status = service.reconcile(make_resource({"repositoryId": 4}), NOW)
+    status = service.reconcile(make_resource({"repositoryId": 4}, status=status), NOW + timedelta(hours=2))
+    assert conditions(status)["Succeeded"] == ("False", "ScanNotStarted")
+    assert status["lastScan"]["result"] == "failed"
+    assert enqueue.call_count == 1
+
+
+def test_reconcile_all_patches_changed_status_and_scales_workers():
+    """Test removed status fields are patched to null and workers follow the scans in flight."""
+    service, kubernetes, _ = make_service(active=2)
+    unchanged = make_resource({"repositoryId": 4, "suspend": True})
+    unchanged["status"] = service.reconcile(unchanged, NOW)
+    suspended = make_resource(
+        {"repositoryId": 4, "schedule": "@daily", "suspend": True}, generation=2,
+        status={"observedGeneration": 1, "nextScanTime": "2026-03-07T00:00:00Z", "conditions": []},
+    )
+    queued = make_resource({"repositoryId": 4})
+    kubernetes.list_policy_scans.return_value = [unchanged, suspended, queued]
+    kubernetes.own_namespace.return_value = "policy-miner"
+    kubernetes.get_replicas.return_value = 1
+
+    result = service.reconcile_all(NOW)
+
+    assert result == {"reconciled": 3, "failed": 0, "workers": 3}
+    assert [c.args[1] for c in kubernetes.patch_status.call_args_list] == ["expense-api", "expense-api"]
+    assert kubernetes.patch_status.call_args_list[0].args[2]["nextScanTime"] is None
+    kubernetes.set_replicas.assert_called_once_with("policy-miner", "scan-worker", 3)
+
+    service._active_scans.return_value = 40
+    kubernetes.get_replicas.return_value = 10
+    kubernetes.list_policy_scans.return_value = []
+    kubernetes.set_replicas.reset_mock()
+    with patch("app.services.scan_operator_service.settings.OPERATOR_MAX_WORKERS", 10):
+        assert service.reconcile_all(NOW)["workers"] == 10
+    kubernetes.set_replicas.assert_not_called()
@@ -0,0 +1,80 @@
+"""Tests for scanning repositories on cron schedules."""
+from datetime import UTC, datetime
+from unittest.mock import MagicMock, Mock
+
+import pytest
+
+from app.models.repository import Repository
+from app.models.scan_schedule import ScanSchedule
+from app.services.scan_schedule_service import ScanScheduleService, next_run
+
+# A Friday evening
+NOW = datetime(2026, 3, 6, 22, 17, 30, tzinfo=UTC)
+
+
+def test_next_run_follows_cron_fields():
+    """Test steps, ranges, names, shorthands, and either-day matching when both day fields are set."""
+    assert next_run("0 3 * * 1-5", NOW) == datetime(2026, 3, 9, 3, 0, tzinfo=UTC)
+    assert next_run("*/15 * * * *", NOW) == datetime(2026, 3, 6, 22, 30, tzinfo=UTC)
+    assert next_run("17 22 * * *", NOW) == datetime(2026, 3, 7, 22, 17, tzinfo=UTC)
+    assert next_run("@monthly", NOW) == datetime(2026, 4, 1, tzinfo=UTC)
+    assert next_run("0 12 * jan,jul sun", NOW) == datetime(2026, 7, 5, 12, 0, tzinfo=UTC)
+    assert next_run("0 0 1 * 7", NOW) == datetime(2026, 3, 8, tzinfo=UTC)
+    assert next_run("30 2 29 2 *", NOW) == datetime(2028, 2, 29, 2, 30, tzinfo=UTC)
+
+
+@pytest.mark.parametrize(
+    ("expression", "message"),
+    [
+        ("* * *", "must have 5 fields"),
+        ("61 * * * *", "outside 0-59"),
+        ("*/0 * * * *", "Invalid minute step"),
+        ("0 0 * foo *", "Invalid month value"),
+        ("0 0 30 2 *", "never matches"),
+    ],
+)
+def test_invalid_expressions_are_rejected(expression, message):
+    """Test malformed and unsatisfiable expressions."""
+    with pytest.raises(ValueError, match=message):
+        next_run(expression, NOW)
+
+
+def test_due_schedules_are_queued_and_rescheduled():
+    """Test queued scans, failures recorded without stopping others, and the next run computed from now."""
+    nightly = Mock(spec=ScanSchedule, id=1, repository_id=3, tenant_id="acme", cron="0 3 * * *", enabled=True)
+    hourly = Mock(spec=ScanSchedule, id=2, repository_id=4, tenant_id="beta", cron="@hourly", enabled=True)
+    db = MagicMock()
+    service = ScanScheduleService(db)
+    service._due = Mock(return_value=[nightly, hourly])
+    enqueue = Mock(side_effect=["task-1", RuntimeError("broker down")])
+
+    queued = service.run_due_schedules(enqueue, now=NOW)
+
+    assert queued == [{"schedule_id": 1, "repository_id": 3, "task_id": "task-1"}]
+    assert (nightly.last_task_id, nightly.last_error, nightly.last_run_at) == ("task-1", None, NOW)
+    assert nightly.next_run_at == datetime(2026, 3, 7, 3, 0, tzinfo=UTC)
+    assert hourly.last_error == "broker down"
+    assert hourly.next_run_at == datetime(2026, 3, 6, 23, 0, tzinfo=UTC)
+    db.commit.assert_called_once()
+
+
+def test_create_schedule_validates_and_allows_one_per_repository():
+    """Test the first run is set on creation, disabled schedules have none, and duplicates are refused."""
+    db = MagicMock()
+    repository = Mock(spec=Repository, id=3)
+    db.query.return_value.filter.return_value.filter.return_value.first.side_effect = [repository, None, repository, None]
+    service = ScanScheduleService(db, "acme")
+
+    schedule = service.create_schedule({"repository_id": 3, "cron": "@daily", "incremental": True, "enabled": True})
+    assert schedule.tenant_id == "acme" and schedule.next_run_at.hour == 0
+
+    paused = service.create_schedule({"repository_id": 3, "cron": "0 3 * * *", "incremental": False, "enabled": False})
+    assert paused.next_run_at is None
+
+    db.query.return_value.filter.return_value.filter.return_value.first.side_effect = [repository, Mock(spec=ScanSchedule)]
+    with pytest.raises(ValueError, match="already has a scan schedule"):
+        service.create_schedule({"repository_id": 3, "cron": "@daily", "incremental": True, "enabled": True})
+
+    db.query.return_value.filter.return_value.filter.return_value.first.side_effect = [repository, None]
+    with pytest.raises(ValueError, match="must have 5 fields"):
+        service.create_schedule({"repository_id": 3, "cron": "daily", "incremental": True, "enabled": False})
@@ -0,0 +1,184 @@
+"""Tests for the cross-service call graph."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Policy
+from app.models.repository import Repository
+from app.services.endpoint_mapping_service import EndpointRule
+from app.services.service_call_extractor import CallCredential, CallProtocol, extract_service_calls
+from app.services.service_graph_service import ServiceGraphService, UserContext
+
+GATEWAY = '''import os
+
+import httpx
+import requests
+
+ORDERS_URL = os.getenv("ORDERS_SERVICE_URL", "http://orders-service:8080")
+BILLING_URL = os.environ["BILLING_API_URL"]
+
+
+def get_order(request, order_id):
+    token = request.headers.get("Authorization")
+    return requests.get(f"{ORDERS_URL}/api/orders/{order_id}", headers={"Authorization": token})
+
+
+def refund(order_id):
+    headers = {"Authorization": f"Bearer {service_token()}"}
+    return requests.post(BILLING_URL + "/refunds/" + order_id, headers=headers)
+
+
+def weather():
+    return requests.get("https://api.weather.com/v1/today")
+
+
+inventory = httpx.Client(base_url="http://inventory.default.svc.cluster.local/api")
+
+
+def reserve(sku):
+    return inventory.post(f"/reservations/{sku}", json={})
+'''
+
+BFF = """const axios = require('axios');
+
+const users = axios.create({ baseURL: `${process.env.USERS_URL}/v2`, headers: { 'x-api-key': process.env.USERS_KEY } });
+
+async function profile(req, res) {
+  const me = await users.get(`/users/${req.params.id}`);
+  const prefs = await fetch(`http://preferences:3000/prefs/${req.params.id}`, {
+    method: 'PUT',
+    headers: { authorization: req.headers.authorization },
+  });
+  res.json({ me, prefs });
+}
+"""
+
+WORKER = """package worker
+
+import (
+	"net/http"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	pb "example.com/ledger/proto"
+)
+
+func Sync(ctx context.Context) error {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodDelete, os.Getenv("CATALOG_URL")+"/items/stale", nil)
+	_, err := http.DefaultClient.Do(req)
+	conn, err := grpc.Dial("ledger:50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	client := pb.NewLedgerServiceClient(conn)
+	return err
+}
+"""
+
+FEIGN = """@FeignClient(name = "accounts-service", configuration = AccountsAuth.class)
+public interface AccountsClient {
+    @GetMapping("/accounts/{id}")
+    Account get(@PathVariable String id);
+
+    @PostMapping("/accounts/{id}/lock")
+    void lock(@PathVariable String id);
+}
+
+class AccountsAuth {
+    @Bean
+    RequestInterceptor serviceToken(OAuth2AuthorizedClientManager manager) {
+        return template -> template.header("Authorization", "Bearer " + clientCredentials(manager));
+    }
+}
+"""
+
+HTTP_CLIENT = """public class ShippingGateway
+{
+    public ShippingGateway(HttpClient client)
+    {
+        client.BaseAddress = new Uri(config["Services:Shipping"]);
+        _client = client;
+    }
+
+    public Task<HttpResponseMessage> Cancel(string id) => _client.DeleteAsync($"shipments/{id}");
+}
+"""
+
+
+def test_extracts_python_and_javascript_calls_with_their_credentials():
+    """Test callees come from hosts, env vars, and base URLs, and credentials from the enclosing function."""
+    calls = extract_service_calls({"gateway/app.py": GATEWAY, "bff/profile.js": BFF, "README.md": GATEWAY})
+
+    found = [(c.client, c.target, c.method, c.path, c.credential) for c in calls]
+    assert found == [
+        ("requests", "orders", "GET", "/api/orders/{order_id}", CallCredential.USER_TOKEN),
+        ("requests", "billing", "POST", "/refunds/{order_id}", CallCredential.SERVICE_ACCOUNT),
+        ("httpx", "inventory", "POST", "/api/reservations/{sku}", CallCredential.NONE),
+        ("fetch", "preferences", "PUT", "/prefs/{id}", CallCredential.USER_TOKEN),
+        ("axios", "users", "GET", "/v2/users/{id}", CallCredential.SERVICE_ACCOUNT),
+    ]
+    # The public weather API is not an internal service
+    assert all(c.target != "weather" for c in calls)
+    fetch = calls[3]
+    assert (fetch.line_start, fetch.line_end, fetch.credential_evidence) == (7, 10, "req.headers.authorization")
+
+
+def test_extracts_go_feign_http_client_and_grpc_calls():
+    """Test Go requests, gRPC stubs, Feign clients with interceptors, and HttpClient base addresses."""
+    calls = extract_service_calls({"worker/sync.go": WORKER, "src/AccountsClient.java": FEIGN, "Shipping.cs": HTTP_CLIENT})
+
+    found = [(c.protocol, c.client, c.target, c.method, c.path, c.credential) for c in calls]
+    assert found == [
+        (CallProtocol.HTTP, "net/http", "catalog", "DELETE", "/items/stale", CallCredential.NONE),
+        (CallProtocol.GRPC, "grpc-go", "ledger", "*", "LedgerService", CallCredential.NONE),
+        (CallProtocol.HTTP, "Feign", "accounts", "GET", "/accounts/{id}", CallCredential.SERVICE_ACCOUNT),
+        (CallProtocol.HTTP, "Feign", "accounts", "POST", "/accounts/{id}/lock", CallCredential.SERVICE_ACCOUNT),
+        (CallProtocol.HTTP, "HttpClient", "shipping", "DELETE", "/shipments/{id}", CallCredential.NONE),
+    ]
+
+
+def test_graph_flags_hops_that_drop_user_context(tmp_path):
+    """Test hops resolve to callee rules and role-checked ones called without the user's token are flagged."""
+    (tmp_path / "1").mkdir()
+    (tmp_path / "1" / "app.py").write_text(GATEWAY)
+    repositories = []
+    for repository_id, name in ((1, "gateway"), (2, "orders-service"), (3, "billing")):
+        repository = Mock(spec=Repository)
+        repository.id, repository.name = repository_id, name
+        repositories.append(repository)
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = repositories
+    rules = {
+        1: [],
+        2: [EndpointRule(method="GET", path="/api/orders/{id}", roles=["customer"], policy_ids=[4])],
+        3: [EndpointRule(method="POST", path="/refunds/{order_id}", roles=["finance"], policy_ids=[7])],
+    }
+    policies = {repository_id: [Mock(spec=Policy)] for repository_id in rules}
+
+    service = ServiceGraphService(db, "acme", clone_dir=str(tmp_path))
+    with (
+        patch(
+            "app.services.service_graph_service.DecisionSimulationService.load_policies",
+            side_effect=lambda repository_id: policies[repository_id],
+        ),
+        patch(
+            "app.services.service_graph_service.EndpointMappingService.map_policies",
+            side_effect=lambda ps: next(rules[i] for i, p in policies.items() if p is ps),
+        ),
+    ):
+        graph = service.graph()
+        dropped = service.graph(repository_id=3, dropped_only=True)
+
+    assert graph["summary"]["calls"] == 3
+    assert graph["summary"]["dropped"] == 1
+    refund, order, reserve = graph["hops"]
+    assert (refund["target"], refund["target_endpoint"], refund["user_context"]) == (
+        "billing",
+        "POST /refunds/{order_id}",
+        UserContext.DROPPED,
+    )
+    assert (refund["target_roles"], refund["target_policy_ids"]) == (["finance"], [7])
+    assert (order["target"], order["user_context"]) == ("orders-service", UserContext.PROPAGATED)
+    assert (reserve["target_repository_id"], reserve["user_context"]) == (None, UserContext.UNKNOWN)
+    assert {"name": "inventory", "repository_id": None, "mined": False} in graph["nodes"]
+    assert graph["edges"][0]["target"] == "billing" and graph["edges"][0]["dropped"] == 1
+    assert [h["target"] for h in dropped["hops"]] == ["billing"]
+    assert [(e["source"], e["target"]) for e in dropped["edges"]] == [("gateway", "billing")]
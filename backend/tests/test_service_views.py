@@ -0,0 +1,107 @@
+"""Tests for unifying the policies of a multi-language monorepo service into layered views."""
+import json
+from unittest.mock import MagicMock, Mock, patch
+
+import pytest
+
+from app.core.config import settings
+from app.models.policy import Evidence, Policy, SourceType
+from app.models.repository import Repository
+from app.services.service_view_service import Layer, ServiceViewService, component_of, find_components
+
+BFF = """export async function listExpenses(req, res) {
+  const expenses = await fetch(`http://expenses-api:8080/api/expenses`, {
+    headers: { authorization: req.headers.authorization },
+  });
+  res.json(await expenses.json());
+}
+"""
+
+
+def test_components_are_named_after_their_service_and_placed_in_a_layer():
+    """Test manifests and SQL directories, layer words in paths, and a root-level layout."""
+    paths = [
+        "services/expenses/api/go.mod",
+        "services/expenses/api/queries.sql",
+        "services/expenses/expense-bff/package.json",
+        "services/expenses/expense-bff/tsconfig.json",
+        "services/expenses/db/migrations/001_rls.sql",
+        "services/billing/billingService/pom.xml",
+        "package.json",
+    ]
+    components = find_components(paths, "platform")
+    assert [(c.path, c.language, c.service, c.layer) for c in components] == [
+        ("services/expenses/db/migrations", "sql", "expense", Layer.DATA),
+        ("services/billing/billingService", "java", "billing", Layer.APP),
+        ("services/expenses/api", "go", "expense", Layer.APP),
+        ("services/expenses/expense-bff", "typescript", "expense", Layer.EDGE),
+        ("", "javascript", "platform", Layer.APP),
+    ]
+    assert component_of("services/expenses/api/handlers/expenses.go", components).path == "services/expenses/api"
+    assert component_of("scripts/seed.go", components).path == ""
+
+    layout = [{"path": "bff", "service": "expenses"}, {"path": "gateway"}, {"path": "db", "layer": "app"}]
+    pinned = find_components(["backend/go.mod", "bff/package.json", "db/schema.sql"], "expenses-monorepo", layout)
+    assert [(c.path, c.service, c.layer, c.pinned) for c in pinned] == [
+        ("backend", "expenses-monorepo", Layer.APP, False),
+        ("bff", "expenses", Layer.EDGE, True),
+        ("db", "expenses-monorepo", Layer.APP, True),
+        ("gateway", "expenses-monorepo", Layer.EDGE, True),
+    ]
+
+def test_service_policies_are_unified_into_layers_with_edge_links(tmp_path):
+    """Test one service across Go, TypeScript, and SQL, database policies, and calls from the BFF to the backend."""
+    root = tmp_path / "4"
+    for directory in ("backend", "bff/src", "db"):
+        (root / directory).mkdir(parents=True)
+    (root / "backend" / "go.mod").write_text("module expenses\n")
+    (root / "bff" / "package.json").write_text("{}")
+    (root / "bff" / "tsconfig.json").write_text("{}")
+    (root / "bff" / "src" / "expenses.ts").write_text(BFF)
+    (root / "db" / "policies.sql").write_text("CREATE POLICY own_expenses ON expenses USING (owner_id = current_user_id());\n")
+
+    def policy(policy_id, subject, resource, action, file_path, source_type):
+        created = Policy(id=policy_id, repository_id=4, subject=subject, resource=resource, action=action, source_type=source_type)
+        created.evidence = [Evidence(file_path=file_path, line_start=1, line_end=1, code_snippet="")]
+        return created
+
+    policies = [
+        policy(1, "MANAGER", "/api/expenses", "GET", "backend/handlers/expenses.go", SourceType.BACKEND),
+        policy(2, "Authenticated", "/expenses", "GET", "bff/src/expenses.ts", SourceType.FRONTEND),
+        policy(3, "app_user", "expenses", "SELECT", "db/policies.sql", SourceType.DATABASE),
+        policy(4, "AUDITOR", "expenses", "SELECT", "backend/store/expenses.go", SourceType.DATABASE),
+    ]
+    repository = Mock(spec=Repository, id=4, tenant_id="acme")
+    repository.name = "expenses-monorepo"
+    db = MagicMock()
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repository
+    db.query.return_value.filter.return_value.all.return_value = policies
+    service = ServiceViewService(db, "acme", str(tmp_path))
+
+    result = service.services(4)
+    assert result["summary"] == {
+        "services": 1,
+        "multi_language_services": 1,
+        "policies": 4,
+        "by_layer": {"edge": 1, "app": 1, "data": 2},
+    }
+    [view] = result["services"]
+    assert (view["name"], view["languages"]) == ("expenses-monorepo", ["go", "typescript", "sql"])
+    assert [(c["path"], c["layer"], c["policies"]) for c in view["components"]] == [("backend", "app", 2), ("bff", "edge", 1), ("db", "data", 1)]
+    layers = view["layers"]
+    assert [p["policy_id"] for p in layers["data"]["policies"]] == [3, 4]
+    assert (layers["data"]["resources"], layers["app"]["resources"], layers["edge"]["resources"]) == (
+        ["expenses"], ["GET /api/expenses"], ["GET /expenses"],
+    )  # fmt: skip
+    assert layers["app"]["roles"] == ["MANAGER"]
+    [link] = view["links"]
+    assert (link["file_path"], link["call"], link["app_endpoint"], link["app_policy_ids"], link["user_context"]) == (
+        "bff/src/expenses.ts", "GET /api/expenses", "GET /api/expenses", [1], "propagated",
+    )  # fmt: skip
+
+    with pytest.raises(ValueError, match="Service billing not found"):
+        service.services(4, service="billing")
+    layout = tmp_path / "layout.json"
+    layout.write_text(json.dumps({"components": [{"path": "bff", "layer": "perimeter"}]}))
+    with patch.object(settings, "SERVICE_LAYOUT_PATH", str(layout)), pytest.raises(ValueError, match="Invalid service layout file"):
+        service.services(4)
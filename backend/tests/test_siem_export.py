@@ -0,0 +1,199 @@
+"""Tests for streaming findings, auth regressions, and audit events to SIEMs."""
+import base64
+import hashlib
+import hmac
+import json
+from datetime import UTC, datetime
+from unittest.mock import MagicMock, Mock, patch
+
+import httpx
+import pytest
+
+from app.core.config import settings
+from app.models.audit_log import AuditEventType
+from app.models.siem_connector import SiemConnector, SiemDestination, SiemFindingState, SiemFormat
+from app.services.siem_export_service import (
+    ElasticClient,
+    SentinelClient,
+    SiemEvent,
+    SiemExportService,
+    SplunkClient,
+    flatten,
+    to_cef,
+    to_ecs,
+)
+
+NOW = datetime(2026, 3, 1, 12, 0, tzinfo=UTC)
+
+
+def make_event(**overrides):
+    """A detected finding event."""
+    fields = {
+        "event_id": "fnd_1-detected",
+        "timestamp": NOW,
+        "dataset": "finding",
+        "action": "finding-detected",
+        "kind": "alert",
+        "category": "vulnerability",
+        "event_type": "info",
+        "severity": "high",
+        "signature": "missing_authorization",
+        "name": "Route with no authorization",
+        "message": "DELETE /api/expenses/{id} has no authorization check",
+        "tenant_id": "acme",
+        "repository_id": 3,
+        "repository_name": "expenses",
+        "file_path": "routes.js",
+        "line": 42,
+        "finding_key": "fnd_1",
+        "cwe": ["CWE-862"],
+        "owasp_api": ["API5:2023"],
+    }
+    return SiemEvent(**(fields | overrides))
+
+
+def make_connector(destination, event_format=SiemFormat.ECS, **fields):
+    """A connector of tenant acme."""
+    connector = Mock(
+        spec=SiemConnector, id=4, destination=destination, event_format=event_format, index=None,
+        repository_id=None, audit_log_cursor=0, regression_cursor=0, **fields,
+    )
+    connector.name = destination.value
+    return connector
+
+
+def test_events_render_as_ecs_and_cef():
+    """Test ECS fields and pruning, CEF header and extension escaping, and flattening for Sentinel."""
+    document = to_ecs(make_event())
+    assert document["@timestamp"] == "2026-03-01T12:00:00+00:00"
+    assert document["event"]["severity"] == 73 and document["event"]["dataset"] == "policy_miner.finding"
+    assert document["vulnerability"] == {
+        "id": ["CWE-862"], "classification": "CWE", "category": ["API5:2023"], "severity": "high",
+    }
+    assert "user" not in document and "http" not in document
+
+    audit = to_ecs(make_event(dataset="audit", severity="info", user_email="ana@acme.io", cwe=[], owasp_api=[]))
+    assert "rule" not in audit and "vulnerability" not in audit
+    assert audit["user"] == {"email": "ana@acme.io"}
+
+    line = to_cef(make_event(name="Policy|rule", message="a=b\nc\\d"))
+    assert line.startswith("CEF:0|Policy Miner|Policy Miner|0.1.0|missing_authorization|Policy\\|rule|8|")
+    assert "msg=a\\=b\\nc\\\\d" in line
+    assert "rt=1772366400000" in line and "externalId=fnd_1-detected" in line
+    assert "cs1Label=repository cs1=expenses" in line and "cn2Label=line cn2=42" in line
+    assert "suser=" not in line
+
+    flat = flatten(document)
+    assert flat["timestamp"] == "2026-03-01T12:00:00+00:00"
+    assert flat["event_severity"] == 73 and flat["policy_miner_repository_name"] == "expenses"
+
+
+def test_clients_send_events_the_way_each_siem_ingests_them():
+    """Test HEC envelopes, bulk NDJSON and its rejected items, and Sentinel's SharedKey signature."""
+    client = MagicMock()
+    splunk = SplunkClient(make_connector(SiemDestination.SPLUNK, SiemFormat.CEF, base_url="https://hec.acme.io:8088/", secret="tok"), client)
+    splunk.connector.index = "appsec"
+    splunk.send([make_event(), make_event(event_id="fnd_2-detected")])
+    url = client.post.call_args.args[0]
+    entries = [json.loads(line) for line in client.post.call_args.kwargs["content"].split("\n")]
+    assert url == "https://hec.acme.io:8088/services/collector/event"
+    assert client.post.call_args.kwargs["headers"]["Authorization"] == "Splunk tok"
+    assert entries[0]["sourcetype"] == "policy_miner:cef" and entries[0]["index"] == "appsec"
+    assert entries[1]["event"].startswith("CEF:0|") and entries[0]["time"] == NOW.timestamp()
+
+    client = MagicMock()
+    client.post.return_value = Mock(
+        json=Mock(return_value={"errors": True, "items": [{"create": {"status": 201}}, {"create": {"error": {"reason": "mapping"}}}]})
+    )
+    elastic = ElasticClient(make_connector(SiemDestination.ELASTIC, base_url="https://es.acme.io", secret="key"), client)
+    with pytest.raises(ValueError, match="rejected 1 events: mapping"):
+        elastic.send([make_event()])
+    lines = client.post.call_args.kwargs["content"].splitlines()
+    assert json.loads(lines[0]) == {"create": {"_index": "logs-policy_miner-default"}}
+    assert json.loads(lines[1])["event"]["id"] == "fnd_1-detected"
+    assert client.post.call_args.kwargs["headers"]["Authorization"] == "ApiKey key"
+
+    client = MagicMock()
+    key = base64.b64encode(b"shared-key").decode()
+    sentinel = SentinelClient(make_connector(SiemDestination.SENTINEL, base_url=None, workspace_id="ws-1", secret=key), client)
+    sentinel.send([make_event()])
+    headers = client.post.call_args.kwargs["headers"]
+    body = client.post.call_args.kwargs["content"]
+    signed = f"POST\n{len(body)}\napplication/json\nx-ms-date:{headers['x-ms-date']}\n/api/logs"
+    expected = base64.b64encode(hmac.new(b"shared-key", signed.encode(), hashlib.sha256).digest()).decode()
+    assert client.post.call_args.args[0] == "https://ws-1.ods.opinsights.azure.com/api/logs?api-version=2016-04-01"
+    assert headers["Authorization"] == f"SharedKey ws-1:{expected}"
+    assert headers["Log-Type"] == "PolicyMiner" and headers["time-generated-field"] == "timestamp"
+    assert json.loads(body)[0]["event_id"] == "fnd_1-detected"
+
+
+def test_clients_redact_event_text():
+    """Test event names and messages are redacted before any SIEM sees them."""
+    event = make_event(message="Approved policy 5 for ana@acme.io")
+    splunk = SplunkClient(make_connector(SiemDestination.SPLUNK, SiemFormat.CEF, base_url="https://hec.acme.io"), MagicMock())
+    elastic = ElasticClient(make_connector(SiemDestination.ELASTIC, base_url="https://es.acme.io"), MagicMock())
+    with patch.object(settings, "REDACTION_ENABLED", True), patch.object(settings, "REDACTION_CONFIG_PATH", ""):
+        assert "msg=Approved policy 5 for [REDACTED_EMAIL]" in splunk.render(event)
+        assert elastic.render(event)["message"] == "Approved policy 5 for [REDACTED_EMAIL]"
+    assert event.message == "Approved policy 5 for ana@acme.io"
+
+
+def test_stream_sends_new_events_and_advances_state_only_after_delivery():
+    """Test detected and resolved findings, regression and audit cursors, and failed streams resending."""
+    connector = make_connector(SiemDestination.SPLUNK, base_url="https://hec.acme.io", secret="tok")
+    service = SiemExportService(MagicMock(), tenant_id="acme")
+    service.get_connector = MagicMock(return_value=connector)
+    service._repository_names = MagicMock(return_value={3: "expenses"})
+    gone = Mock(spec=SiemFindingState, finding_key="fnd_gone", rule_id="bola", title="BOLA", severity="critical",
+                repository_id=3, file_path="api.py")
+    service._states = MagicMock(return_value=[Mock(spec=SiemFindingState, finding_key="fnd_kept"), gone])
+    finding = {
+        "finding_key": "fnd_new", "rule_id": "missing_authorization", "title": "Route with no authorization",
+        "severity": "high", "description": "", "repository_id": 3, "repository_name": "expenses",
+        "file_path": "routes.js", "line_start": 42, "cwe": ["CWE-862"], "owasp_api": ["API5:2023"],
+    }
+    regression = Mock(
+        id=9, repository_id=3, endpoint="DELETE /api/expenses/{}", detected_at=NOW, description="Auth removed",
+        previous_protection="middleware", exposure="public", evidence={"after": {"file_path": "routes.js", "line": 42}},
+    )
+    audit = Mock(
+        id=31, tenant_id="acme", event_type=AuditEventType.POLICY_APPROVAL, created_at=NOW, repository_id=3,
+        event_description="Approved policy 5", user_email="ana@acme.io", policy_id=5, ai_model=None,
+        ai_provider=None, ai_prompt="secret prompt",
+    )
+    service._regressions = MagicMock(return_value=[regression])
+    service._audit_logs = MagicMock(return_value=[audit])
+
+    siem = MagicMock()
+    siem.host = "hec.acme.io"
+    siem.send.side_effect = httpx.ConnectError("refused")
+    exported = patch(
+        "app.services.siem_export_service.FindingExportService.findings",
+        return_value=[finding, finding | {"finding_key": "fnd_kept"}],
+    )
+    clients = patch.dict("app.services.siem_export_service.SIEM_CLIENTS", {SiemDestination.SPLUNK: Mock(return_value=siem)})
+    with exported, clients, pytest.raises(ValueError, match="Failed to stream events to splunk"):
+        service.stream_connector(4, client=MagicMock())
+    assert connector.last_error == "refused" and connector.regression_cursor == connector.audit_log_cursor == 0
+    service.db.add.assert_not_called()
+
+    siem.send.side_effect = None
+    with exported, clients:
+        result = service.stream_connector(4, client=MagicMock())
+
+    (events,) = siem.send.call_args.args
+    assert [e.action for e in events] == [
+        "finding-detected", "finding-resolved", "auth-regression-detected", "policy_approval",
+    ]
+    assert events[0].message == "Route with no authorization" and events[1].severity == "critical"
+    assert (events[2].method, events[2].path, events[2].repository_name) == ("DELETE", "/api/expenses/{}", "expenses")
+    assert "secret prompt" not in json.dumps(to_ecs(events[3])) and events[3].user_email == "ana@acme.io"
+    (added,) = [c.args[0] for c in service.db.add.call_args_list]
+    assert (added.finding_key, added.connector_id) == ("fnd_new", 4)
+    service.db.delete.assert_called_once_with(gone)
+    assert (connector.regression_cursor, connector.audit_log_cursor) == (9, 31)
+    assert connector.last_error is None
+    assert result == {
+        "connector_id": 4, "findings_detected": 1, "findings_resolved": 1, "regressions": 1, "audit_events": 1,
+        "events_sent": 4,
+    }
@@ -6,6 +6,7 @@ from fastapi.testclient import TestClient
 from sqlalchemy import create_engine
 from sqlalchemy.orm import sessionmaker
 
+from app.core.config import settings
 from app.core.database import get_db
 from app.main import app
 from app.models.policy import Evidence, Policy, PolicyStatus, RiskLevel, SourceType
@@ -84,7 +85,7 @@ def test_policy(db_session, test_repository):
 def test_evidence_with_file(db_session, test_policy, test_repository):
     """Create test evidence with actual source file."""
     # Create temporary source file
-    repo_dir = Path("/tmp/policy_miner_repos") / str(test_repository.id)
+    repo_dir = Path(settings.REPO_CLONE_DIR) / str(test_repository.id)
     repo_dir.mkdir(parents=True, exist_ok=True)
 
     test_file = repo_dir / "test.py"
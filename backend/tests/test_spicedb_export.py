@@ -0,0 +1,114 @@
+"""Tests for exporting mined role and ownership policies as a SpiceDB schema and relationships."""
+import gzip
+import io
+import tarfile
+from unittest.mock import patch
+
+from app.services.spicedb_export_service import SpiceDbExportService, object_id, ownership_relation, spicedb_name
+
+ASSIGNMENTS = {"ana@acme.io": {"MANAGER"}, "bo": {"ADMIN", "INTERN"}}
+
+
+def test_names_and_object_ids_are_valid_spicedb_identifiers():
+    """Test prefixes, definitions, and relations from repository, resource, and attribute names."""
+    assert spicedb_name("expense-api", "repository") == "expense_api"
+    assert spicedb_name("ExpenseReport", "resource") == "expense_report"
+    assert spicedb_name("QA", "role") == "role_qa"
+    assert spicedb_name("3ds", "repository") == "repository_3ds"
+    assert spicedb_name("", "resource") == "resource"
+    assert ownership_relation("expense.owner_id") == "owner"
+    assert ownership_relation("submitted_by") == "submitted_by"
+    assert ownership_relation(None) == "owner"
+    assert object_id("alice@acme.io") == "alice=40acme=2Eio"
+    assert object_id("emp-42") == "emp-42"
+
+
+def test_roles_and_ownership_become_relations_and_permissions(make_policy, make_export_service):
+    """Test unions and intersections, fail-closed attribute checks, and role relationships."""
+    policies = [
+        make_policy(
+            1, "MANAGER", "read", "router.get('/api/expenses/:id', requireRole('MANAGER'), show)",
+            conditions="user is owner of expense.owner_id unless ADMIN",
+        ),
+        make_policy(2, "AUDITOR or MANAGER", "read", "router.get('/api/expenses', requireRole('AUDITOR', 'MANAGER'), list)"),
+        make_policy(
+            3, "DIRECTOR", "approve", "router.put('/api/expenses/:id/approve', requireRole('DIRECTOR'), approve)",
+            conditions="user department is Finance",
+        ),
+        make_policy(4, "authenticated", "read", "app.get('/api/invoices', list)", resource="Invoice"),
+        make_policy(5, "anonymous", "platform", "app.get('/api/status', status)", resource="User"),
+    ]
+    with patch("app.services.spicedb_export_service.RoleImpactService.load_assignments", return_value=ASSIGNMENTS):
+        result = make_export_service(SpiceDbExportService, policies, repository_name="expense-api").export(4)
+
+    schema = result["zed_schema"]
+    assert result["prefix"] == "expense_api"
+    assert schema.startswith(
+        "definition expense_api/user {}\n\n"
+        "definition expense_api/platform {\n"
+        "    relation everyone: expense_api/user:*\n"
+        "    relation admin: expense_api/user\n"
+        "    relation auditor: expense_api/user\n"
+        "    relation manager: expense_api/user\n"
+        "}\n"
+    )
+    assert (
+        "definition expense_api/expense {\n"
+        "    relation platform: expense_api/platform\n"
+        "    relation owner: expense_api/user\n\n"
+        "    // Policy 3 not translated, review: DIRECTOR may approve Expense (PUT /api/expenses/{}/approve)\n"
+        "    permission approve = nil\n\n"
+        "    // Policy 1: MANAGER may read Expense (GET /api/expenses/{})\n"
+        "    // Policy 2: AUDITOR or MANAGER may read Expense (GET /api/expenses)\n"
+        "    permission read = (platform->manager & (owner + platform->admin)) + (platform->auditor + platform->manager)\n"
+        "}\n"
+    ) in schema
+    assert "permission read = platform->everyone" in schema
+    assert "definition expense_api/user_object {" in schema
+    assert "permission platform_action = platform->everyone" in schema
+    assert "director" not in schema
+
+    assert result["relationships"] == [
+        "expense_api/platform:main#everyone@expense_api/user:*",
+        "expense_api/platform:main#manager@expense_api/user:ana=40acme=2Eio",
+        "expense_api/platform:main#admin@expense_api/user:bo",
+    ]
+    assert [(p["resource_type"], p["permission"], p["policy_ids"]) for p in result["permissions"]] == [
+        ("expense", "approve", []),
+        ("expense", "read", [1, 2]),
+        ("invoice", "read", [4]),
+        ("user_object", "platform_action", [5]),
+    ]
+    assert result["untranslated"] == [
+        {"policy_id": 3, "endpoint": "PUT /api/expenses/{}/approve", "clause": "user department is Finance"}
+    ]
+    assert result["summary"] == {
+        "policies": 5, "resource_types": 3, "permissions": 4, "roles": 3, "relationships": 3,
+        "roles_without_members": ["AUDITOR"], "below_min_confidence": 0, "untranslated_clauses": 1,
+    }
+
+
+def test_min_confidence_and_the_archive(make_policy, make_export_service):
+    """Test low-confidence policies are left out and the archive holds a zed import file."""
+    policies = [
+        make_policy(1, "ADMIN", "delete", "router.delete('/api/expenses/:id', requireRole('ADMIN'), remove)"),
+        make_policy(2, "MANAGER", "read", "router.get('/api/expenses/:id', requireRole('MANAGER'), show)", confidence=40.0),
+    ]
+    service = make_export_service(SpiceDbExportService, policies, repository_name="expense-api")
+    with patch("app.services.spicedb_export_service.RoleImpactService.load_assignments", return_value=ASSIGNMENTS):
+        result = service.export(4, min_confidence=50)
+        archive = service.archive(4, min_confidence=50)
+
+    assert [p["permission"] for p in result["permissions"]] == ["delete"]
+    assert result["summary"]["below_min_confidence"] == 1
+    assert result["relationships"] == ["expense_api/platform:main#admin@expense_api/user:bo"]
+
+    with tarfile.open(fileobj=io.BytesIO(gzip.decompress(archive))) as tar:
+        files = {m.name.lstrip("/"): tar.extractfile(m).read().decode() for m in tar.getmembers()}
+    assert sorted(files) == ["bootstrap.yaml", "relationships.txt", "schema.zed"]
+    assert files["schema.zed"] == result["zed_schema"]
+    assert files["relationships.txt"] == "expense_api/platform:main#admin@expense_api/user:bo\n"
+    assert files["bootstrap.yaml"].startswith("schema: |-\n  definition expense_api/user {}\n\n  definition")
+    assert files["bootstrap.yaml"].endswith(
+        "relationships: |-\n  expense_api/platform:main#admin@expense_api/user:bo\n"
+    )
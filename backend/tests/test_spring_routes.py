@@ -0,0 +1,166 @@
+"""Tests for Spring Security method security and filter chain mining."""
+from app.services.spring_route_extractor import SpringRouteKind, extract_spring_routes, spel_access
+
+IS_ADMIN = """package com.acme.security;
+
+import org.springframework.security.access.prepost.PreAuthorize;
+
+@Retention(RetentionPolicy.RUNTIME)
+@PreAuthorize("hasRole('ADMIN')")
+public @interface IsAdmin {}
+"""
+
+ROLES = """package com.acme.security;
+
+public final class Roles {
+    public static final String AUDITOR = "ROLE_AUDITOR";
+}
+"""
+
+ORDER_CONTROLLER = """package com.acme.orders;
+
+import org.springframework.web.bind.annotation.*;
+
+@RestController
+@RequestMapping("/api/orders")
+@PreAuthorize("isAuthenticated()")
+public class OrderController {
+
+    @GetMapping
+    public List<Order> list() { return orders; }
+
+    @PostMapping({"", "/bulk"})
+    @PreAuthorize("hasAnyRole('EDITOR', 'ADMIN')")
+    public Order create(@RequestBody Order order) { return order; }
+
+    @DeleteMapping("/{id}")
+    @IsAdmin
+    public void delete(@PathVariable long id) {}
+
+    @RequestMapping(value = "/{id}/audit", method = RequestMethod.GET)
+    @Secured(Roles.AUDITOR)
+    public Audit audit(@PathVariable long id) { return null; }
+
+    @GetMapping("/{id}")
+    @PreAuthorize("hasRole('ADMIN') or #id == authentication.principal.id")
+    public Order show(@PathVariable long id) { return null; }
+}
+"""
+
+INVOICE_SERVICE = """package com.acme.billing;
+
+import jakarta.annotation.security.RolesAllowed;
+
+@Service
+public class InvoiceService {
+    @RolesAllowed({"BILLING", "ADMIN"})
+    public void refund(long id) {}
+
+    @PreAuthorize("hasAuthority('invoices:write') and hasRole('BILLING')")
+    public void issue() {}
+}
+"""
+
+SECURITY_CONFIG = """package com.acme;
+
+import org.springframework.security.config.annotation.web.builders.HttpSecurity;
+
+@Configuration
+public class SecurityConfig {
+    @Bean
+    SecurityFilterChain chain(HttpSecurity http) throws Exception {
+        http.authorizeHttpRequests(auth -> auth
+            .requestMatchers("/actuator/health", "/login").permitAll()
+            .requestMatchers(HttpMethod.DELETE, "/api/**").hasRole("ADMIN")
+            .requestMatchers("/api/**").authenticated()
+            // Never applies: /api/** above matches first
+            .requestMatchers("/api/admin/**").hasRole("ADMIN")
+            .anyRequest().denyAll());
+        return http.build();
+    }
+}
+"""
+
+KOTLIN_CONFIG = """package com.acme
+
+import org.springframework.security.config.web.servlet.invoke
+
+@Configuration
+class KotlinSecurity {
+    @Bean
+    fun chain(http: HttpSecurity): SecurityFilterChain {
+        http {
+            authorizeHttpRequests {
+                authorize(HttpMethod.POST, "/v2/orders/**", hasAnyRole("EDITOR", "ADMIN"))
+                authorize(anyRequest, authenticated)
+            }
+        }
+        return http.build()
+    }
+}
+"""
+
+FILES = {
+    "src/main/java/com/acme/security/IsAdmin.java": IS_ADMIN,
+    "src/main/java/com/acme/security/Roles.java": ROLES,
+    "src/main/java/com/acme/orders/OrderController.java": ORDER_CONTROLLER,
+    "src/main/java/com/acme/billing/InvoiceService.java": INVOICE_SERVICE,
+    "src/main/java/com/acme/SecurityConfig.java": SECURITY_CONFIG,
+    "src/main/kotlin/com/acme/KotlinSecurity.kt": KOTLIN_CONFIG,
+}
+
+
+def by_kind(kind):
+    """Findings of a kind indexed by (action, resource)."""
+    return {(f.action, f.resource): f for f in extract_spring_routes(FILES) if f.kind == kind}
+
+
+def test_method_security_on_controllers_and_services():
+    """Test @PreAuthorize, @Secured with constants, @RolesAllowed, meta-annotations, and class defaults."""
+    routes = by_kind(SpringRouteKind.ROUTE)
+
+    assert set(routes) == {
+        ("GET", "/api/orders"),
+        ("POST", "/api/orders"),
+        ("POST", "/api/orders/bulk"),
+        ("DELETE", "/api/orders/{id}"),
+        ("GET", "/api/orders/{id}/audit"),
+        ("GET", "/api/orders/{id}"),
+    }
+    assert routes[("GET", "/api/orders")].subject == "Authenticated users"
+    assert routes[("GET", "/api/orders")].description.endswith("(inherited from OrderController)")
+    assert routes[("POST", "/api/orders/bulk")].subject == "EDITOR or ADMIN"
+    assert routes[("DELETE", "/api/orders/{id}")].subject == "ADMIN"
+    assert routes[("GET", "/api/orders/{id}/audit")].subject == "AUDITOR"
+    assert routes[("GET", "/api/orders/{id}")].conditions == (
+        "passes any of hasRole('ADMIN'), #id == authentication.principal.id"
+    )
+
+    methods = by_kind(SpringRouteKind.METHOD_SECURITY)
+    assert methods[("call", "InvoiceService.refund")].subject == "BILLING or ADMIN"
+    assert methods[("call", "InvoiceService.issue")].subject == "BILLING"
+    assert methods[("call", "InvoiceService.issue")].conditions == "requires authority invoices:write"
+
+
+def test_filter_chain_rules_apply_in_order():
+    """Test Java and Kotlin DSL rules, HTTP methods, and rules shadowed by a broader earlier matcher."""
+    rules = by_kind(SpringRouteKind.HTTP_SECURITY)
+
+    assert rules[("*", "/login")].subject == "Anonymous"
+    assert rules[("*", "/login")].disables_auth
+    assert rules[("DELETE", "/api/**")].subject == "ADMIN"
+    assert rules[("*", "/api/admin/**")].conditions == "shadowed by rule 3 (/api/**), which matches first"
+    assert rules[("*", "/api/admin/**")].line_start == 14
+    java_default = next(f for f in extract_spring_routes(FILES) if f.resource == "/**" and f.file_path.endswith(".java"))
+    assert java_default.description == "Spring Security filter chain rule 5 applying .denyAll()"
+    assert rules[("POST", "/v2/orders/**")].subject == "EDITOR or ADMIN"
+    assert rules[("POST", "/v2/orders/**")].file_path.endswith(".kt")
+
+
+def test_spel_expressions():
+    """Test SpEL alternatives, conjunctions, and ROLE_-prefixed authorities."""
+    assert spel_access("hasRole('A') or hasAuthority('ROLE_B')", "").subject == "A or B"
+    assert spel_access("permitAll() or hasRole('A')", "").subject == "Anonymous"
+    assert spel_access("hasAnyAuthority('read', 'write')", "").conditions == "requires authority read or write"
+    combined = spel_access("(hasRole('A') or hasRole('B')) and #order.owner == principal.username", "")
+    assert (combined.subject, combined.conditions) == ("A or B", "when #order.owner == principal.username")
@@ -0,0 +1,105 @@
+"""Tests for stable rule and endpoint identifiers across scans."""
+from datetime import UTC, datetime
+from unittest.mock import Mock
+
+import pytest
+
+from app.models.policy import Evidence, Policy, PolicyStatus
+from app.models.policy_change import ChangeType, PolicyChange
+from app.models.repository import Repository
+from app.services.stable_identity_service import (
+    StableIdentityService,
+    build_lineages,
+    code_fingerprint,
+    endpoint_id,
+    normalize_path,
+    rule_id,
+)
+
+REVIEWED_AT = datetime(2026, 9, 1, tzinfo=UTC)
+
+
+def make_policy(policy_id, subject, file_path, snippet, resource="Invoice", conditions=None):
+    """Create an unreviewed policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id, policy.subject, policy.resource, policy.action = policy_id, subject, resource, "delete"
+    policy.conditions = conditions
+    policy.status, policy.approval_comment, policy.reviewed_by, policy.reviewed_at = PolicyStatus.PENDING, None, None, None
+    policy.application_id = None
+    evidence = Mock(spec=Evidence)
+    evidence.file_path, evidence.line_start, evidence.code_snippet = file_path, 10, snippet
+    policy.evidence = [evidence]
+    return policy
+
+
+SNIPPET = "router.delete('/invoices/:id', requireRole('ADMIN'), remove)"
+
+
+def test_identifiers_ignore_location_parameter_names_and_role_order():
+    """Test IDs depend on what the rule enforces, not where its code lives."""
+    assert normalize_path("/users/<int:user_id>/orders/") == "/users/{}/orders"
+    assert endpoint_id("GET", "/users/:id") == endpoint_id("get", "/users/{userId}/")
+    assert endpoint_id("GET", "/users/:id") != endpoint_id("POST", "/users/:id")
+
+    original = make_policy(1, "Admin or Manager", "src/invoices.js", SNIPPET)
+    moved = make_policy(2, "MANAGER, ADMIN", "src/billing/invoice_routes.js", SNIPPET.replace(":id", ":invoiceId"))
+    narrowed = make_policy(3, "Admin or Manager", "src/invoices.js", SNIPPET, conditions="invoice.status == 'draft'")
+
+    assert rule_id(original) == rule_id(moved)
+    assert rule_id(original).startswith("rule_")
+    assert rule_id(original) != rule_id(narrowed)
+
+
+def test_lineage_follows_reworded_rules_by_code_fingerprint():
+    """Test a rule reworded between scans stays in its lineage when its code is unchanged."""
+    first = make_policy(1, "ADMIN", "src/invoices.js", SNIPPET)
+    reworded = make_policy(5, "ADMIN", "lib/invoices.js", "// moved\n" + SNIPPET.replace(", ", ",  "), resource="Invoices")
+    unrelated = make_policy(6, "ADMIN", "src/users.js", "router.delete('/users/:id', requireRole('ADMIN'))", "User")
+
+    assert code_fingerprint(first) == code_fingerprint(reworded)
+    lineages = build_lineages([unrelated, reworded, first])
+
+    assert [[p.id for p in lineage] for _, lineage in lineages] == [[1, 5], [6]]
+    assert lineages[0][0] == rule_id(first)
+
+
+def test_carry_over_copies_triage_and_owner_to_unreviewed_successors(make_db):
+    """Test the newest policy inherits its lineage's last review and owner."""
+    approved = make_policy(1, "ADMIN", "src/invoices.js", SNIPPET)
+    approved.status, approved.approval_comment = PolicyStatus.APPROVED, "matches the RBAC matrix"
+    approved.reviewed_by, approved.reviewed_at, approved.application_id = "lead@example.com", REVIEWED_AT, 4
+    rescanned = make_policy(7, "Admin", "src/billing/invoices.js", SNIPPET)
+    rejected = make_policy(2, "SUPPORT", "src/users.js", "router.get('/users', requireRole('SUPPORT'))", "User")
+    rejected.status, rejected.reviewed_by = PolicyStatus.REJECTED, "lead@example.com"
+    re_reviewed = make_policy(8, "SUPPORT", "src/users.js", "router.get('/users', requireRole('SUPPORT'))", "User")
+    re_reviewed.status, re_reviewed.reviewed_by = PolicyStatus.APPROVED, "owner@example.com"
+    db = make_db({Repository: [Mock(spec=Repository)], Policy: [approved, rejected, rescanned, re_reviewed]})
+
+    assert StableIdentityService(db, "acme").carry_over(3) == 1
+
+    assert (rescanned.status, rescanned.approval_comment) == (PolicyStatus.APPROVED, "matches the RBAC matrix")
+    assert (rescanned.reviewed_by, rescanned.reviewed_at, rescanned.application_id) == ("lead@example.com", REVIEWED_AT, 4)
+    assert (re_reviewed.status, re_reviewed.reviewed_by) == (PolicyStatus.APPROVED, "owner@example.com")
+    db.commit.assert_called_once()
+
+
+def test_lineages_report_locations_and_history(make_db):
+    """Test lineages list every file a rule lived in and the changes to all its policies."""
+    first = make_policy(1, "ADMIN", "src/invoices.js", SNIPPET)
+    moved = make_policy(7, "ADMIN", "src/billing/invoices.js", SNIPPET)
+    added = Mock(spec=PolicyChange, id=11, policy_id=1, previous_policy_id=None, change_type=ChangeType.ADDED)
+    added.detected_at = REVIEWED_AT
+    db = make_db({Repository: [Mock(spec=Repository)], Policy: [first, moved], PolicyChange: [added]})
+
+    [lineage] = StableIdentityService(db).lineages(3)
+
+    assert (lineage["current_policy_id"], lineage["policy_ids"], lineage["moved"]) == (7, [1, 7], True)
+    assert lineage["locations"] == ["src/invoices.js", "src/billing/invoices.js"]
+    assert lineage["endpoint"] == "DELETE /invoices/:id"
+    assert lineage["endpoint_id"] == endpoint_id("DELETE", "/invoices/{id}")
+    assert [c["change_id"] for c in lineage["history"]] == [11]
+
+    db.query.side_effect = None
+    db.query.return_value.filter.return_value.first.return_value = None
+    with pytest.raises(ValueError, match="Repository 3 not found"):
+        StableIdentityService(db).lineages(3)
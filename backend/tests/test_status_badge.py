@@ -0,0 +1,96 @@
+"""Tests for per-repository policy status and badges."""
+from datetime import UTC, datetime
+from unittest.mock import Mock
+
+import pytest
+
+from app.models.repository import Repository
+from app.models.scan_metrics import ScanMetricsSnapshot
+from app.services.status_badge_service import (
+    CRITICAL_COLOR,
+    UNKNOWN_COLOR,
+    BadgeStatus,
+    StatusBadgeService,
+    badge_content,
+    render_badge,
+)
+
+
+@pytest.fixture
+def make_service(make_db):
+    """Build services whose repository and latest snapshot queries return the given rows."""
+
+    def build(repository, snapshot):
+        rows = {Repository: [repository] if repository else [], ScanMetricsSnapshot: [snapshot] if snapshot else []}
+        return StatusBadgeService(make_db(rows), "acme")
+
+    return build
+
+
+def test_status_reads_the_latest_scan_snapshot(make_service):
+    """Test passing and failing states, never-scanned repositories, and unknown repositories."""
+    repository = Mock(spec=Repository, id=7, badge_token="b4dg3")
+    repository.name = "payments"
+    snapshot = ScanMetricsSnapshot(
+        repository_id=7,
+        scan_id=41,
+        total_endpoints=40,
+        authenticated_percent=87.5,
+        authorized_percent=60.0,
+        open_findings={"conflicts": 1},
+        critical_findings=2,
+        captured_at=datetime(2026, 10, 14, tzinfo=UTC),
+    )
+
+    status = make_service(repository, snapshot).status(7)
+    assert (status["status"], status["coverage_percent"], status["critical_findings"], status["scan_id"]) == (
+        BadgeStatus.FAILING, 87.5, 2, 41
+    )
+    assert status["badge_url"] == "/api/v1/status/badges/b4dg3.svg"
+    assert badge_content(status) == ("87.5% | 2 critical", CRITICAL_COLOR)
+
+    snapshot.critical_findings = 0
+    status = make_service(repository, snapshot).status(7)
+    assert status["status"] == BadgeStatus.PASSING
+    assert badge_content(status) == ("87.5%", "#97ca00")
+    assert badge_content({**status, "coverage_percent": 100.0}) == ("100%", "#4c1")
+
+    never_scanned = make_service(repository, None).status(7)
+    assert never_scanned["status"] == BadgeStatus.UNKNOWN
+    assert badge_content(never_scanned) == ("not scanned", UNKNOWN_COLOR)
+    with pytest.raises(ValueError):
+        make_service(None, None).status(8)
+
+
+def test_public_badges_resolve_only_by_token(make_service):
+    """Test public badges look repositories up by token, and tokens are issued and regenerated."""
+    repository = Mock(spec=Repository, id=7, badge_token=None)
+    repository.name = "payments"
+    service = make_service(repository, None)
+    service.tenant_id = None
+
+    svg = service.public_badge("b4dg3")
+    assert 'aria-label="auth coverage: not scanned"' in svg
+    first = repository.badge_token
+    assert first and len(first) >= 32
+
+    status = make_service(repository, None).regenerate_badge_token(7)
+    assert repository.badge_token != first and status["badge_url"] == f"/api/v1/status/badges/{repository.badge_token}.svg"
+    with pytest.raises(ValueError, match="Badge not found"):
+        make_service(None, None).public_badge("guess")
+    with pytest.raises(ValueError, match="Badge not found"):
+        make_service(repository, None).public_badge("")
+
+
+def test_badge_is_sized_to_its_text(make_service):
+    """Test the SVG segments, accessible label, and escaping."""
+    svg = make_service(Mock(spec=Repository, id=7, badge_token="b4dg3"), None).badge(7)
+    assert svg.startswith('<svg xmlns="http://www.w3.org/2000/svg" width="176" height="20"')
+    assert 'aria-label="auth coverage: not scanned"' in svg
+    assert f'fill="{UNKNOWN_COLOR}"' in svg
+
+    wide = render_badge("auth coverage", "100% | 12 critical", CRITICAL_COLOR)
+    narrow = render_badge("auth coverage", "9%", "#fe7d37")
+    assert wide.count("<text") == 2
+    assert int(wide.split('width="')[1].split('"')[0]) > int(narrow.split('width="')[1].split('"')[0])
+    assert "&lt;b&gt;" in render_badge("<b>", "x", "#555")
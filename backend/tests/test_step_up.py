@@ -0,0 +1,98 @@
+"""Tests for step-up authentication and MFA requirement detection."""
+from unittest.mock import MagicMock, Mock, patch
+
+from app.models.policy import Evidence, Policy, RiskLevel
+from app.services.step_up_service import StepUpKind, StepUpService, detect_step_up
+
+
+def make_policy(policy_id, subject, action, snippet, risk_level=RiskLevel.LOW):
+    """Create a policy with one evidence snippet."""
+    policy = Mock(spec=Policy)
+    policy.id = policy_id
+    policy.subject = subject
+    policy.resource = "Account"
+    policy.action = action
+    policy.conditions = None
+    policy.description = None
+    policy.risk_level = risk_level
+    policy.risk_score = 80.0 if risk_level == RiskLevel.HIGH else 20.0
+    policy.complexity_score = 10.0
+    policy.impact_score = 10.0
+    policy.confidence_score = 90.0
+    policy.historical_score = 0.0
+    policy.status = None
+    ev = Mock(spec=Evidence)
+    ev.code_snippet = snippet
+    ev.file_path = "app.js"
+    ev.line_start = 1
+    policy.evidence = [ev]
+    return policy
+
+
+POLICIES = [
+    make_policy(
+        1,
+        "ADMIN",
+        "delete",
+        "app.delete('/accounts/:id', requireRole('ADMIN'), (req, res) => {\n"
+        "  if (!req.auth.amr.includes('mfa')) return res.status(403).end()\n})",
+        RiskLevel.HIGH,
+    ),
+    make_policy(2, "ADMIN", "transfer", "app.post('/accounts/:id/transfer', requireRole('ADMIN'), transfer)", RiskLevel.HIGH),
+    make_policy(3, "Authenticated users", "view", "app.get('/accounts/:id', isLoggedIn, show)"),
+    make_policy(
+        4,
+        "Authenticated users",
+        "update",
+        "@app.put('/accounts/{id}')\n@fresh_login_required\ndef update(id):",
+        RiskLevel.HIGH,
+    ),
+]
+
+
+def test_detect_step_up_reads_claim_values_and_max_age():
+    """Test amr/acr claim checks record their accepted values and the strictest max_age."""
+    strength = detect_step_up(
+        "if token['acr'] not in ('urn:mace:incommon:iap:silver', 'gold'): deny()\n"
+        "assert 'otp' in claims.get('amr', [])\n"
+        "authorize(max_age=300, acr_values='gold')\nmaxAge: 600"
+    )
+
+    assert strength.kinds == [StepUpKind.AMR_CLAIM.value, StepUpKind.ACR_CLAIM.value, StepUpKind.REAUTHENTICATION.value]
+    assert strength.acr_values == ["urn:mace:incommon:iap:silver", "gold"]
+    assert strength.amr_values == ["otp"]
+    assert strength.max_age_seconds == 300
+
+
+def test_detect_step_up_mfa_guards_and_reauthentication_prompts():
+    """Test MFA decorators, password confirmation, and plain role checks."""
+    assert detect_step_up("@otp_required\ndef wire(request):").kinds == [StepUpKind.MFA.value]
+    assert detect_step_up("Route::post('/keys')->middleware('password.confirm')").kinds == [
+        StepUpKind.REAUTHENTICATION.value
+    ]
+    assert detect_step_up("[Authorize(Policy = \"RequireStepUp\")]").kinds == [StepUpKind.MFA.value]
+    assert not detect_step_up("if user.is_admin and user.reauthorize_scope:").step_up
+
+
+def test_endpoints_flag_high_risk_without_step_up():
+    """Test high-risk authenticated endpoints lacking step-up are flagged first."""
+    service = StepUpService(MagicMock(), "acme")
+    with patch("app.services.step_up_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        endpoints = {e["endpoint"]: e for e in service.endpoints()}
+        flagged = service.endpoints(missing_only=True)
+
+    assert [e["endpoint"] for e in flagged] == ["POST /accounts/:id/transfer"]
+    deleted = endpoints["DELETE /accounts/:id"]
+    assert (deleted["high_risk"], deleted["step_up"], deleted["amr_values"]) == (True, True, ["mfa"])
+    assert endpoints["PUT /accounts/{id}"]["kinds"] == [StepUpKind.REAUTHENTICATION.value]
+    assert endpoints["GET /accounts/:id"]["missing_step_up"] is False
+
+
+def test_policies_report_strength_attributes():
+    """Test per-policy attributes include the risk level and detected kinds."""
+    service = StepUpService(MagicMock())
+    with patch("app.services.step_up_service.DecisionSimulationService.load_policies", return_value=POLICIES):
+        by_id = {p["policy_id"]: p for p in service.policies()}
+
+    assert by_id[1]["kinds"] == [StepUpKind.AMR_CLAIM.value, StepUpKind.MFA.value]
+    assert (by_id[2]["risk_level"], by_id[2]["step_up"]) == ("high", False)
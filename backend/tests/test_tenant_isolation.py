@@ -0,0 +1,113 @@
+"""Tests for mining tenant isolation constraints and the endpoints missing them."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.policy import Evidence, Policy
+from app.models.repository import Repository
+from app.services.condition_evaluation_service import ConditionEvaluationService
+from app.services.tenant_isolation_service import TenantIsolationService, tenant_checks, tenant_resources
+
+MODELS = """package models
+
+type Expense struct {
+	ID       string  `json:"id"`
+	TenantID string  `json:"tenant_id"`
+	Amount   float64 `json:"amount"`
+}
+"""
+
+HANDLERS = """package handlers
+
+func GetExpense(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	expense, err := store.GetExpenseByID(mux.Vars(r)["id"])
+	if err != nil || expense.TenantID != user.TenantID {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(expense)
+}
+
+func UpdateExpense(w http.ResponseWriter, r *http.Request) {
+	expense, _ := store.GetExpenseByID(mux.Vars(r)["id"])
+	json.NewDecoder(r.Body).Decode(expense)
+	store.Save(expense)
+}
+
+func RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/api/expenses/{id}", RequireRole("MANAGER")(GetExpense)).Methods("GET")
+	r.HandleFunc("/api/expenses/{id}", RequireRole("MANAGER")(UpdateExpense)).Methods("PUT")
+}
+"""
+
+PYTHON = """@tenant_required
+def show(request, pk):
+    return Expense.objects.get(pk=pk)
+
+
+def approve(request, pk):
+    expense = Expense.objects.get(pk=pk, org_id=request.user.org_id)
+
+
+def create(request):
+    order_item = OrderItem(**request.data)
+    order_item.org_id = request.user.org_id
+    order_item.save()
+"""
+
+
+def test_tenant_checks_and_tenant_owned_resources_are_found_in_handlers():
+    """Test middleware decorators, query filters, tenant stamping, and model references."""
+    lines = PYTHON.split("\n")
+    models = {"Expense": "tenant_id", "OrderItem": "org_id"}
+    assert [(c.kind, c.resource_attribute) for c in tenant_checks(lines, 1, 4, "Expense")] == [("middleware", "expense.tenant_id")]
+    [query] = tenant_checks(lines, 5, 8, "Expense")
+    assert (query.line, query.kind, query.condition) == (7, "query_filter", "user is in the same tenant as expense.org_id")
+    [stamped] = tenant_checks(lines, 9, len(lines), "OrderItem")
+    assert (stamped.kind, stamped.resource_attribute) == ("assignment", "order_item.org_id")
+    assert tenant_resources(lines, 9, len(lines), models) == ["OrderItem"]
+
+    clause = ConditionEvaluationService.parse_clause(query.condition)
+    assert (clause.kind, clause.attribute) == ("same_tenant", "expense.org_id")
+    assert ConditionEvaluationService.evaluate_clause(clause, {"user.tenant_id": "t1", "expense.org_id": "t2"}, []) is False
+    assert ConditionEvaluationService.evaluate_clause(clause, {"expense.org_id": "t2"}, []) is None
+
+
+def test_endpoints_touching_tenant_owned_models_without_a_check_are_reported(tmp_path):
+    """Test constraints, gaps, and adding constraints to policy conditions."""
+    root = tmp_path / "5"
+    (root / "handlers").mkdir(parents=True)
+    (root / "models").mkdir()
+    (root / "models" / "expense.go").write_text(MODELS)
+    (root / "handlers" / "expenses.go").write_text(HANDLERS)
+    lines = HANDLERS.split("\n")
+
+    get = Policy(id=1, repository_id=5, subject="MANAGER", resource="/api/expenses/{id}", action="GET")
+    get.evidence = [Evidence(file_path="handlers/expenses.go", line_start=20, line_end=20, code_snippet=lines[19])]
+    update = Policy(id=2, repository_id=5, subject="MANAGER", resource="/api/expenses/{id}", action="PUT")
+    update.evidence = [Evidence(file_path="handlers/expenses.go", line_start=21, line_end=21, code_snippet=lines[20])]
+    repo = Mock(spec=Repository, id=5, tenant_id="acme")
+    repo.name = "expenses"
+    db = MagicMock()
+    db.query.return_value.filter.return_value.order_by.return_value.all.return_value = [repo]
+    db.query.return_value.filter.return_value.filter.return_value.first.return_value = repo
+    db.query.return_value.filter.return_value.all.return_value = [get, update]
+    service = TenantIsolationService(db, "acme", str(tmp_path))
+
+    report = service.isolation()
+    [constraint] = report["constraints"]
+    assert (constraint["endpoint"], constraint["function"], constraint["line"], constraint["kind"]) == ("GET /api/expenses/{id}", "GetExpense", 6, "comparison")
+    assert constraint["condition"] == "user is in the same tenant as expense.tenant_id"
+    [gap] = report["gaps"]
+    assert (gap["endpoint"], gap["function"], gap["line_start"], gap["resources"], gap["severity"]) == (
+        "PUT /api/expenses/{id}", "UpdateExpense", 13, ["Expense"], "high"
+    )
+    assert "Expense.tenant_id" in gap["description"]
+    assert report["summary"]["protected_endpoints"] == 1
+
+    result = service.apply_constraints(5)
+    assert result == {"repository_id": 5, "constraints": 1, "policies_updated": 1}
+    assert get.conditions == "user is in the same tenant as expense.tenant_id"
+    assert (get.evidence[-1].file_path, get.evidence[-1].line_start) == ("handlers/expenses.go", 6)
+    assert update.conditions is None
+    # Constraints already stated are not added twice
+    assert service.apply_constraints(5)["policies_updated"] == 0
@@ -0,0 +1,111 @@
+"""Tests for condition threshold tracking across scans."""
+from unittest.mock import Mock, patch
+
+import pytest
+
+from app.models.policy import Evidence, Policy, SourceType
+from app.models.repository import Repository
+from app.models.threshold import ThresholdChange, ThresholdChangeStatus, ThresholdObservation
+from app.services.threshold_tracking_service import ThresholdTrackingService, extract_thresholds, loosened
+
+
+def make_policy(policy_id, subject, conditions, action="approve"):
+    """Create an expense policy with the given conditions."""
+    policy = Mock(spec=Policy)
+    policy.id, policy.subject, policy.resource, policy.action = policy_id, subject, "Expense", action
+    policy.conditions, policy.description, policy.source_type = conditions, None, SourceType.BACKEND
+    evidence = Mock(spec=Evidence)
+    evidence.code_snippet, evidence.file_path, evidence.line_start = "app.post('/expenses/:id/approve', approve)", "x.js", 1
+    policy.evidence = [evidence]
+    return policy
+
+
+def observe(threshold, value=None, operator=None, scan_id=1, observation_id=1):
+    """Stored observation of a threshold, optionally with another boundary."""
+    observation = Mock(spec=ThresholdObservation)
+    observation.id, observation.scan_id, observation.policy_id = observation_id, scan_id, threshold.policy_id
+    observation.threshold_key, observation.attribute = threshold.key, threshold.attribute
+    observation.operator = operator or threshold.operator
+    observation.value = threshold.value if value is None else value
+    observation.required_role, observation.clause = threshold.required_role, threshold.clause
+    observation.resource, observation.action, observation.observed_at = threshold.resource, threshold.action, None
+    return observation
+
+
+def test_extract_thresholds_keys_boundaries_independent_of_value():
+    """Test only numeric boundaries are tracked and their key ignores the value."""
+    [approval] = extract_thresholds(make_policy(1, "MANAGER", "amount > $5,000 requires DIRECTOR"))
+    [limit] = extract_thresholds(make_policy(2, "MANAGER", "amount <= 5000 AND department == 'Finance'"))
+
+    assert (approval.attribute, approval.operator, approval.value, approval.required_role) == ("amount", ">", 5000.0, "DIRECTOR")
+    assert limit.required_role is None and limit.key != approval.key
+    assert extract_thresholds(make_policy(3, "MANAGER", "amount > 25000 requires DIRECTOR"))[0].key == approval.key
+    assert extract_thresholds(make_policy(4, "CLERK", "amount <= 9000"))[0].key != limit.key
+    assert extract_thresholds(make_policy(5, "MANAGER", "User department is Finance")) == []
+
+
+@pytest.mark.parametrize(
+    "before,after,expected",
+    [
+        ("amount <= 5000", "amount <= 10000", True),
+        ("amount <= 5000", "amount <= 1000", False),
+        ("amount < 5000", "amount <= 5000", True),
+        ("account_age_days >= 30", "account_age_days >= 7", True),
+        ("amount > 5000 requires DIRECTOR", "amount > 25000 requires DIRECTOR", True),
+        ("amount > 5000 requires DIRECTOR", "amount >= 5000 requires DIRECTOR", False),
+        ("amount <= 5000", "amount <= 5000", None),
+    ],
+)
+def test_loosened_depends_on_what_the_boundary_guards(before, after, expected):
+    """Test permits loosen when their region grows and role requirements when theirs shrinks."""
+    [old] = extract_thresholds(make_policy(1, "MANAGER", before))
+    [new] = extract_thresholds(make_policy(2, "MANAGER", after))
+    assert loosened(old.operator, old.value, new) is expected
+
+
+def test_record_raises_finding_only_for_loosened_thresholds(make_db):
+    """Test a scan records every threshold and flags the loosened approval limit."""
+    [approval] = extract_thresholds(make_policy(1, "MANAGER", "amount > 5000 requires DIRECTOR"))
+    [refund] = extract_thresholds(make_policy(2, "CLERK", "refund_total <= 500", action="refund"))
+    previous = [observe(approval, observation_id=1), observe(refund, value=800, observation_id=2)]
+    policies = [
+        make_policy(1, "MANAGER", "amount > 5000 requires DIRECTOR"),
+        make_policy(7, "MANAGER", "amount > 25000 requires DIRECTOR"),
+        make_policy(2, "CLERK", "refund_total <= 500", action="refund"),
+    ]
+    db = make_db({ThresholdObservation: previous})
+    with patch("app.services.threshold_tracking_service.DecisionSimulationService.load_policies", return_value=policies):
+        [change] = ThresholdTrackingService(db, "acme").record(4, scan_id=12)
+
+    assert isinstance(change, ThresholdChange)
+    assert (change.before_value, change.after_value, change.policy_id, change.severity) == (5000, 25000, 7, "high")
+    assert change.description == (
+        "Threshold loosened on approve Expense: amount > 5000 requires DIRECTOR became amount > 25000 requires DIRECTOR"
+    )
+    observations = [c.args[0] for c in db.add.call_args_list if isinstance(c.args[0], ThresholdObservation)]
+    assert sorted(o.value for o in observations) == [500, 25000]
+    db.commit.assert_called_once()
+
+
+def test_history_collapses_repeats_and_review_updates_status(make_db):
+    """Test the timeline keeps one point per distinct boundary and findings can be reviewed."""
+    [approval] = extract_thresholds(make_policy(1, "MANAGER", "amount > 5000 requires DIRECTOR"))
+    observations = [
+        observe(approval, scan_id=1, observation_id=1),
+        observe(approval, scan_id=2, observation_id=2),
+        observe(approval, value=25000, scan_id=3, observation_id=3),
+    ]
+    db = make_db({Repository: [Mock(spec=Repository, id=4)], ThresholdObservation: observations})
+
+    [history] = ThresholdTrackingService(db, "acme").history(4)
+
+    assert history["value"] == 25000
+    assert [(p["scan_id"], p["value"]) for p in history["history"]] == [(1, 5000), (3, 25000)]
+
+    finding = Mock(spec=ThresholdChange)
+    reviewed = ThresholdTrackingService(make_db({ThresholdChange: [finding]}), "acme").update_status(
+        9, ThresholdChangeStatus.ACKNOWLEDGED, "Limit raised by finance policy FIN-12", "cfo@example.com"
+    )
+    assert (reviewed.status, reviewed.resolved_by) == (ThresholdChangeStatus.ACKNOWLEDGED, "cfo@example.com")
+    with pytest.raises(ValueError):
+        ThresholdTrackingService(make_db({}), "acme").update_status(9, ThresholdChangeStatus.RESOLVED)
@@ -1,10 +1,11 @@
 """Tests for the translation service."""
 
+import json
 from unittest.mock import AsyncMock, MagicMock, patch
 
 import pytest
 
-from app.models.policy import Policy, PolicyStatus, RiskLevel, SourceType
+from app.models.policy import ExtractionMethod, Policy, PolicyStatus, RiskLevel, SourceType
 from app.services.translation_service import TranslationService
 
 
@@ -25,6 +26,7 @@ def sample_policy():
         complexity_score=20,
         impact_score=40,
         confidence_score=90,
+        extraction_method=ExtractionMethod.EXPLICIT_MIDDLEWARE,
         historical_score=0,
         risk_level=RiskLevel.LOW,
         source_type=SourceType.BACKEND,
@@ -139,6 +141,8 @@ async def test_translate_to_json(sample_policy):
     assert "approve" in json_policy
     assert "amount < 5000" in json_policy
     assert "backend" in json_policy.lower()
+    assert json.loads(json_policy)["extraction_method"] == "explicit_middleware"
+    assert json.loads(json_policy)["confidence_score"] == 90
 
 
 @pytest.mark.asyncio
@@ -5,7 +5,7 @@ from unittest.mock import Mock, patch
 
 import pytest
 
-from app.models.policy import Policy, PolicyStatus, RiskLevel, SourceType
+from app.models.policy import ExtractionMethod, Policy, PolicyStatus, RiskLevel, SourceType
 
 
 @pytest.fixture
@@ -21,6 +21,8 @@ def mock_policy():
     policy.source_type = SourceType.BACKEND
     policy.status = PolicyStatus.PENDING
     policy.risk_level = RiskLevel.LOW
+    policy.confidence_score = 81.0
+    policy.extraction_method = ExtractionMethod.EXPLICIT_MIDDLEWARE
     return policy
 
 
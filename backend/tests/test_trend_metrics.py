@@ -0,0 +1,79 @@
+"""Tests for historical trend metrics."""
+from datetime import UTC, date, datetime
+from unittest.mock import MagicMock, patch
+
+from app.models.scan_metrics import ScanMetricsSnapshot
+from app.services.trend_metrics_service import TrendInterval, TrendMetricsService, bucket_start
+
+
+def make_snapshot(repository_id, captured_at, total, authenticated):
+    """Create a snapshot with coverage counts."""
+    return ScanMetricsSnapshot(
+        repository_id=repository_id,
+        captured_at=captured_at,
+        total_policies=total,
+        total_endpoints=total,
+        authenticated_endpoints=authenticated,
+        authorized_endpoints=authenticated,
+        conditional_endpoints=0,
+        unknown_patterns=0,
+    )
+
+
+def test_bucket_start():
+    """Test that weeks start on Monday."""
+    wednesday = datetime(2026, 10, 14, 9, 30, tzinfo=UTC)
+    assert bucket_start(wednesday) == date(2026, 10, 12)
+    assert bucket_start(wednesday, TrendInterval.DAY) == date(2026, 10, 14)
+
+
+def test_coverage_series_uses_latest_snapshot_per_repository():
+    """Test that workspace points combine each repository's latest snapshot."""
+    snapshots = [
+        make_snapshot(1, datetime(2026, 10, 5, tzinfo=UTC), 10, 5),
+        make_snapshot(1, datetime(2026, 10, 7, tzinfo=UTC), 10, 8),
+        make_snapshot(2, datetime(2026, 10, 8, tzinfo=UTC), 10, 10),
+        # Naive timestamps from legacy rows are treated as UTC
+        make_snapshot(1, datetime(2026, 10, 13), 20, 20),
+    ]
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = snapshots
+
+    points = TrendMetricsService(db, tenant_id="tenant-1").coverage_series()
+
+    assert [p["bucket"] for p in points] == ["2026-10-05", "2026-10-12"]
+    assert points[0]["repositories"] == 2
+    assert points[0]["total_endpoints"] == 20
+    assert points[0]["authenticated_percent"] == 90.0
+    assert points[1]["authenticated_percent"] == 100.0
+
+
+def test_findings_per_week():
+    """Test opened/closed bucketing and the reporting window."""
+    now = datetime(2026, 10, 14, tzinfo=UTC)
+    # Opened/closed timestamps in query order: conflicts, inconsistent
+    # enforcement, security gaps, then secrets (opened only)
+    timestamps = [
+        [datetime(2026, 10, 13, tzinfo=UTC), datetime(2026, 10, 6, tzinfo=UTC)],
+        [datetime(2026, 10, 14, tzinfo=UTC)],
+        [],
+        [],
+        [datetime(2026, 9, 1, tzinfo=UTC)],
+        [],
+        [datetime(2026, 10, 7, tzinfo=UTC)],
+    ]
+
+    service = TrendMetricsService(MagicMock(), tenant_id="tenant-1")
+    with patch.object(service, "_timestamps", side_effect=timestamps):
+        weeks = service.findings_per_week(weeks=2, now=now)
+
+    assert [w["week"] for w in weeks] == ["2026-10-05", "2026-10-12"]
+    assert weeks[0]["opened"]["conflicts"] == 1
+    assert weeks[0]["opened"]["secrets"] == 1
+    assert weeks[1]["opened"]["conflicts"] == 1
+    assert weeks[1]["closed"]["conflicts"] == 1
+    assert weeks[1]["total_closed"] == 1
+    # Findings outside the window are ignored
+    assert sum(w["opened"]["security_gaps"] for w in weeks) == 0
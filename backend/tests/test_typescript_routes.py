@@ -0,0 +1,149 @@
+"""Tests for type-level route authorization mining in TypeScript backends."""
+from app.services.typescript_route_extractor import (
+    TsRouteKind,
+    build_environment,
+    extract_typescript_routes,
+    resolve_guard,
+)
+
+ROLES = """export enum Role {
+  Admin = 'admin',
+  Editor = 'editor',
+  Viewer = 'viewer',
+}
+
+export const Permissions = {
+  OrdersRead: 'orders:read',
+  OrdersWrite: 'orders:write',
+} as const;
+
+export type Permission = typeof Permissions[keyof typeof Permissions];
+export type Staff = Role.Admin | Role.Editor;
+"""
+
+GUARDS = """import { RequestHandler } from 'express';
+import { Role, Permission } from './roles';
+
+// Defaults to the least privileged role when called without arguments
+export function requireRole<R extends Role = Role.Viewer>(...roles: R[]): RequestHandler {
+  return (req, res, next) => (roles.includes(req.user.role) ? next() : res.sendStatus(403));
+}
+
+export const authorize = <P extends Permission>(permission: P): RequestHandler => (req, res, next) => next();
+
+export const adminOnly: RoleGuard<Role.Admin> = makeGuard(config);
+export const staffOnly = requireRole<Staff>();
+export const AdminOnly = () => Authorized(Role.Admin);
+"""
+
+EXPRESS_APP = """import express from 'express';
+import { Role as R, Permissions } from './auth/roles';
+import { requireRole, authorize, adminOnly, staffOnly } from './auth/guards';
+
+const app = express();
+const orders = express.Router();
+
+orders.use(authenticate);
+orders.get('/', authorize(Permissions.OrdersRead), listOrders);
+orders.post('/', requireRole(R.Admin, R.Editor), createOrder);
+orders.delete('/:id', adminOnly, deleteOrder);
+orders.get('/reports', staffOnly, reports);
+orders.put('/:id/owner', requireRole(req.body.role), reassign);
+
+app.get('/health', health);
+app.use('/api/orders', orders);
+"""
+
+CONTROLLER = """import { JsonController, Get, Post, Authorized } from 'routing-controllers';
+import { Security } from 'tsoa';
+import { Role, Permissions } from '../auth/roles';
+import { AdminOnly } from '../auth/guards';
+
+@JsonController('/v2/orders')
+@Authorized()
+export class OrderController {
+  private readonly limit = 50;
+
+  @Get('/')
+  list() {
+    return [];
+  }
+
+  @Post('/')
+  @Authorized([Role.Admin, Role.Editor])
+  create(@Body() body: Order) {
+    return body;
+  }
+
+  @Get('/:id/audit')
+  @AdminOnly()
+  audit() {}
+
+  @Get('/:id/lines')
+  @Security('jwt', [Permissions.OrdersRead])
+  lines() {}
+}
+"""
+
+FILES = {
+    "src/auth/roles.ts": ROLES,
+    "src/auth/guards.ts": GUARDS,
+    "src/app.ts": EXPRESS_APP,
+    "src/controllers/orders.ts": CONTROLLER,
+}
+
+
+def by_route(findings):
+    """Index findings by (method, path)."""
+    return {(f.action, f.resource): f for f in findings}
+
+
+def test_express_guards_resolve_enums_consts_generics_and_annotations():
+    """Test role and permission values come from enum members, const keys, type defaults, and declared types."""
+    routes = by_route(f for f in extract_typescript_routes(FILES) if f.kind == TsRouteKind.EXPRESS_ROUTE)
+
+    # /health has no guard; routes inherit the router's .use() and its mount prefix
+    assert set(routes) == {
+        ("GET", "/api/orders"),
+        ("POST", "/api/orders"),
+        ("DELETE", "/api/orders/:id"),
+        ("GET", "/api/orders/reports"),
+        ("PUT", "/api/orders/:id/owner"),
+    }
+    assert routes[("GET", "/api/orders")].subject == "Authenticated users"
+    assert routes[("GET", "/api/orders")].conditions == "requires permission orders:read"
+    assert routes[("POST", "/api/orders")].subject == "admin or editor"
+    assert routes[("DELETE", "/api/orders/:id")].subject == "admin"
+    assert routes[("GET", "/api/orders/reports")].subject == "admin or editor"
+    assert routes[("GET", "/api/orders/reports")].description == "Express route guarded by authenticate, staffOnly"
+
+
+def test_runtime_arguments_are_reported_instead_of_falling_back_to_defaults():
+    """Test a guard fed a runtime value is flagged rather than credited with its type parameter's default."""
+    routes = by_route(extract_typescript_routes(FILES))
+    env = build_environment(FILES)
+
+    owner = routes[("PUT", "/api/orders/:id/owner")]
+    assert owner.subject == "Authenticated users"
+    assert owner.conditions == "requireRole(req.body.role) takes a value that cannot be resolved statically"
+    # Without arguments the default applies; an explicit type argument narrows a runtime value
+    assert resolve_guard("requireRole()", env).roles == ["viewer"]
+    assert resolve_guard("authorize<'orders:write'>(lookup(req))", env).permissions == ["orders:write"]
+    assert resolve_guard("cors()", env) is None
+
+
+def test_controller_decorators_combine_class_and_action_metadata():
+    """Test routing-controllers and tsoa decorators, including custom decorators returning one."""
+    routes = by_route(f for f in extract_typescript_routes(FILES) if f.kind == TsRouteKind.CONTROLLER_ROUTE)
+
+    assert set(routes) == {
+        ("GET", "/v2/orders"),
+        ("POST", "/v2/orders"),
+        ("GET", "/v2/orders/:id/audit"),
+        ("GET", "/v2/orders/:id/lines"),
+    }
+    assert routes[("GET", "/v2/orders")].subject == "Authenticated users"
+    assert routes[("POST", "/v2/orders")].subject == "admin or editor"
+    assert routes[("GET", "/v2/orders/:id/audit")].subject == "admin"
+    assert routes[("GET", "/v2/orders/:id/lines")].conditions == "requires permission orders:read"
+    assert routes[("POST", "/v2/orders")].line_start == 16
@@ -0,0 +1,137 @@
+"""Tests for known-vulnerable authentication pattern detection."""
+from unittest.mock import MagicMock, Mock
+
+from app.models.repository import Repository
+from app.services.vulnerable_auth_pattern_service import PatternKind, VulnerableAuthPatternService, detect_patterns
+
+PYTHON = '''import jwt, hmac, requests
+
+def current_user(token):
+    claims = jwt.decode(token, KEY, algorithms=["HS256", "none"])
+    other = jwt.decode(token, KEY, algorithms=["RS256"], options={"verify_aud": False})
+    peek = jwt.decode(token, options={"verify_signature": False})
+    legacy = jwt.decode(token, verify=False)
+    return claims
+
+def check_webhook(request):
+    if request.headers["X-Signature"] == expected_signature:
+        return True
+    if hmac.compare_digest(sig, expected):
+        return True
+    if token is None or token_type == "Bearer" or api_key != settings.API_KEY:
+        return False
+    requests.get("https://billing.internal", verify=False)
+    # requests.get(url, verify=False)
+'''
+
+GO = '''package main
+import (
+  "crypto/tls"
+  "github.com/golang-jwt/jwt/v5"
+)
+func parse(s string) {
+  token, _ := jwt.Parse(s, keyFunc)
+  cfg := &tls.Config{InsecureSkipVerify: true}
+  if r.Header.Get("X-Token") == secretToken { }
+}
+'''
+
+
+def _kinds(findings):
+    """(line, kind) pairs of findings."""
+    return sorted((f.line_start, f.kind) for f in findings)
+
+
+def test_jwt_tls_and_secret_comparisons():
+    """Test JWT algorithm, signature, and audience checks, disabled TLS verification, and timing-unsafe comparisons."""
+    assert _kinds(detect_patterns("auth.py", PYTHON)) == [
+        (4, PatternKind.JWT_NONE_ALGORITHM),
+        (5, PatternKind.JWT_AUDIENCE_NOT_VALIDATED),
+        (6, PatternKind.JWT_SIGNATURE_NOT_VERIFIED),
+        (7, PatternKind.JWT_SIGNATURE_NOT_VERIFIED),
+        (11, PatternKind.TIMING_UNSAFE_COMPARISON),
+        (15, PatternKind.TIMING_UNSAFE_COMPARISON),
+        (17, PatternKind.TLS_VERIFICATION_DISABLED),
+    ]
+    assert _kinds(detect_patterns("main.go", GO)) == [
+        (7, PatternKind.JWT_AUDIENCE_NOT_VALIDATED),
+        (8, PatternKind.TLS_VERIFICATION_DISABLED),
+        (9, PatternKind.TIMING_UNSAFE_COMPARISON),
+    ]
+    # decode() alone only matters when the file never verifies
+    decoded = detect_patterns("decode.ts", "import jwt from 'jsonwebtoken';\nexport const user = (t: string) => jwt.decode(t);\n")
+    assert _kinds(decoded) == [(2, PatternKind.JWT_SIGNATURE_NOT_VERIFIED)]
+    verified = "import jwt from 'jsonwebtoken';\nconst p = jwt.verify(t, key, { audience: 'orders' });\nconst h = jwt.decode(t);\n"
+    assert detect_patterns("verify.ts", verified) == []
+
+
+EXPRESS = '''const express = require('express');
+const app = express();
+
+app.get('/admin/stats', stats);
+app.get('/health', health);
+app.use('/admin', requireAuth, requireRole('admin'));
+app.get('/admin/users', users);
+'''
+
+SPRING = '''import org.springframework.security.config.annotation.web.builders.HttpSecurity;
+public class SecurityConfig {
+  SecurityFilterChain chain(HttpSecurity http) throws Exception {
+    http.authorizeHttpRequests(auth -> auth
+        .requestMatchers("/api/**").permitAll()
+        .requestMatchers("/api/admin/**").hasRole("ADMIN")
+        .anyRequest().authenticated());
+    String jws = Jwts.parserBuilder().setSigningKey(key).build().parseClaimsJwt(token);
+    if (signature.equals(expectedSignature)) {}
+    return http.build();
+  }
+}
+'''
+
+
+def test_route_order_bypasses():
+    """Test routes registered ahead of the middleware guarding them and matcher rules shadowed by permitAll."""
+    [bypass] = detect_patterns("server.js", EXPRESS)
+    assert (bypass.kind, bypass.line_start) == (PatternKind.ROUTE_ORDER_BYPASS, 4)
+    assert bypass.description.startswith("GET /admin/stats is registered before app.use('/admin'")
+
+    findings = detect_patterns("SecurityConfig.java", SPRING)
+    assert _kinds(findings) == [
+        (6, PatternKind.ROUTE_ORDER_BYPASS),
+        (8, PatternKind.JWT_AUDIENCE_NOT_VALIDATED),
+        (8, PatternKind.JWT_NONE_ALGORITHM),
+        (9, PatternKind.TIMING_UNSAFE_COMPARISON),
+    ]
+    assert "rule 1 (/api/** .permitAll())" in findings[0].description
+
+
+def test_service_reports_findings_with_cwe_mappings(tmp_path):
+    """Test clone scanning, CWE and severity filters, and the summary counts."""
+    (tmp_path / "5").mkdir()
+    (tmp_path / "5" / "auth.py").write_text(PYTHON)
+    (tmp_path / "5" / "server.js").write_text(EXPRESS)
+    (tmp_path / "5" / "node_modules").mkdir()
+    (tmp_path / "5" / "node_modules" / "lib.js").write_text("new https.Agent({ rejectUnauthorized: false });")
+    repository = Mock(spec=Repository)
+    repository.id, repository.name = 5, "billing"
+    db = MagicMock()
+    query = db.query.return_value
+    query.filter.return_value = query
+    query.order_by.return_value.all.return_value = [repository]
+    service = VulnerableAuthPatternService(db, "acme", clone_dir=str(tmp_path))
+
+    result = service.findings()
+    summary = result["summary"]
+    assert (summary["total"], summary["repositories_scanned"], summary["repositories_with_findings"]) == (8, 1, 1)
+    assert summary["by_cwe"] == {"CWE-347": 3, "CWE-287": 1, "CWE-208": 2, "CWE-295": 1, "CWE-696": 1}
+    assert summary["by_severity"] == {"critical": 3, "high": 2, "medium": 3}
+    first = result["findings"][0]
+    assert (first["severity"], first["cwe"], first["repository_name"]) == ("critical", "CWE-347", "billing")
+    assert all(f["file_path"] != "node_modules/lib.js" for f in result["findings"])
+
+    [timing] = {f["cwe"] for f in service.findings(cwe="cwe-208")["findings"]}
+    assert timing == "CWE-208"
+    assert [f["kind"] for f in service.findings(severity="high")["findings"]] == [
+        PatternKind.TLS_VERIFICATION_DISABLED,
+        PatternKind.ROUTE_ORDER_BYPASS,
+    ]
@@ -1,13 +1,15 @@
 """Unit tests for webhook endpoints."""
 import hashlib
 import hmac
-from unittest.mock import AsyncMock, MagicMock, patch
+import json
+from unittest.mock import MagicMock, patch
 
 import pytest
 from fastapi.testclient import TestClient
 from sqlalchemy.orm import Session
 
 from app.api.v1.webhooks import verify_github_signature
+from app.core.database import get_db
 from app.main import app
 from app.models.repository import Repository
 
@@ -111,9 +113,32 @@ def test_generate_webhook_secret_repository_not_found(client):
         assert "not found" in response.json()["detail"].lower()
 
 
-@pytest.mark.asyncio
-async def test_github_webhook_push_event(client):
-    """Test GitHub webhook with push event."""
+def push(client, repository, signature=None):
+    """Post a signed push for repository's clone URL, with the session returning repository."""
+    mock_db = MagicMock(spec=Session)
+    mock_db.scalars.return_value.first.return_value = repository
+    body = json.dumps({
+        "ref": "refs/heads/main",
+        "repository": {
+            "clone_url": "https://github.com/test/repo.git",
+            "full_name": "test/repo"
+        }
+    }).encode()
+    headers = {"X-GitHub-Event": "push", "Content-Type": "application/json"}
+    if signature is None and repository.webhook_secret:
+        signature = "sha256=" + hmac.new(repository.webhook_secret.encode(), body, hashlib.sha256).hexdigest()
+    if signature:
+        headers["X-Hub-Signature-256"] = signature
+
+    app.dependency_overrides[get_db] = lambda: mock_db
+    try:
+        return client.post("/api/v1/webhooks/github", content=body, headers=headers)
+    finally:
+        app.dependency_overrides.pop(get_db)
+
+
+def test_github_webhook_push_event(client):
+    """Test a signed push queues a scan instead of running it in the request."""
     # Create mock repository
     mock_repo = MagicMock(spec=Repository)
     mock_repo.id = 1
@@ -122,38 +147,31 @@ async def test_github_webhook_push_event(client):
     mock_repo.webhook_secret = "test-secret"
     mock_repo.source_url = "https://github.com/test/repo.git"
 
-    # Mock database
-    mock_db = MagicMock(spec=Session)
-    mock_db.scalars.return_value.first.return_value = mock_repo
-
-    # Mock scanner
-    mock_scan_result = {"scan_id": 123, "status": "started"}
+    with patch("app.api.v1.webhooks.enqueue_scan", return_value="task-123") as enqueue:
+        response = push(client, mock_repo)
 
-    with patch("app.api.v1.webhooks.get_db", return_value=mock_db), \
-         patch("app.api.v1.webhooks.ScannerService") as mock_scanner_class:
+    assert response.status_code == 200
+    data = response.json()
+    assert data["status"] == "queued"
+    assert data["repository_id"] == 1
+    assert data["task_id"] == "task-123"
+    enqueue.assert_called_once_with(1, tenant_id="test-tenant")
 
-        mock_scanner = mock_scanner_class.return_value
-        mock_scanner.scan_repository = AsyncMock(return_value=mock_scan_result)
 
-        payload = {
-            "ref": "refs/heads/main",
-            "repository": {
-                "clone_url": "https://github.com/test/repo.git",
-                "full_name": "test/repo"
-            }
-        }
+@pytest.mark.parametrize("signature", ["", "sha256=invalid_signature"])
+def test_github_webhook_rejects_missing_or_bad_signature(client, signature):
+    """Test a repository with a secret rejects pushes without a valid signature over the body."""
+    mock_repo = MagicMock(spec=Repository)
+    mock_repo.id = 1
+    mock_repo.tenant_id = "test-tenant"
+    mock_repo.webhook_enabled = 1
+    mock_repo.webhook_secret = "test-secret"
 
-        response = client.post(
-            "/api/v1/webhooks/github",
-            json=payload,
-            headers={"X-GitHub-Event": "push"}
-        )
+    with patch("app.api.v1.webhooks.enqueue_scan") as enqueue:
+        response = push(client, mock_repo, signature)
 
-        assert response.status_code == 200
-        data = response.json()
-        assert data["status"] == "success"
-        assert data["repository_id"] == 1
-        assert data["scan_id"] == 123
+    assert response.status_code == 401
+    enqueue.assert_not_called()
 
 
 def test_github_webhook_non_push_event(client):
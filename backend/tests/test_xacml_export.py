@@ -0,0 +1,143 @@
+"""Tests for exporting mined policies as an XACML 3.0 PolicySet."""
+import xml.etree.ElementTree as ET
+
+from app.services.xacml_export_service import XacmlExportService, urn_segment
+
+NS = {"x": "urn:oasis:names:tc:xacml:3.0:core:schema:wd-17"}
+FUNCTION = "urn:oasis:names:tc:xacml:1.0:function:"
+
+
+def functions(element):
+    """FunctionIds of the Apply elements in an expression, outermost first, without the 1.0 prefix."""
+    return [a.get("FunctionId").replace(FUNCTION, "") for a in element.iter(f"{{{NS['x']}}}Apply")]
+
+
+def test_urn_segments():
+    """Test identifier segments from repository names."""
+    assert urn_segment("Expense API", "repository-4") == "expense-api"
+    assert urn_segment("billing_v2.0", "repository-4") == "billing_v2.0"
+    assert urn_segment("!!", "repository-4") == "repository-4"
+
+
+def test_endpoints_become_policies_with_role_targets_conditions_and_audit_obligations(make_policy, make_export_service):
+    """Test targets, amount thresholds, department checks, fail-closed clauses, and obligations."""
+    policies = [
+        make_policy(
+            1, "MANAGER", "approve", "router.put('/api/expenses/:id/approve', requireRole('MANAGER'), approve)",
+            conditions="amount <= 5000 and user department is Finance",
+        ),
+        make_policy(
+            2, "AUDITOR or DIRECTOR", "approve", "router.put('/api/expenses/:id/approve', requireRole('AUDITOR', 'DIRECTOR'), approve)",
+            conditions="amount > 5000 requires DIRECTOR; vibes are good",
+        ),
+        make_policy(
+            3, "EMPLOYEE", "read", "router.get('/api/expenses/:id', requireRole('EMPLOYEE'), show)",
+            conditions="user is owner of expense.owner_id unless ADMIN", confidence=None,
+        ),
+        make_policy(4, "authenticated", "read", "app.get('/api/invoices', list)", resource="Invoice"),
+    ]
+    result = make_export_service(XacmlExportService, policies).export(4)
+
+    root = ET.fromstring(result["xacml"])
+    assert result["xacml"].startswith('<?xml version="1.0" encoding="UTF-8"?>\n<PolicySet xmlns=')
+    assert root.get("PolicySetId") == result["policy_set_id"] == "urn:policy-miner:expense-api"
+    assert root.get("PolicyCombiningAlgId").endswith("policy-combining-algorithm:deny-unless-permit")
+    assert [(p["endpoint"], p["mined_policy_ids"]) for p in result["policies"]] == [
+        ("GET /api/expenses/{}", [3]),
+        ("GET /api/invoices", [4]),
+        ("PUT /api/expenses/{}/approve", [1, 2]),
+    ]
+
+    approve = root.find("x:Policy[x:Description='PUT /api/expenses/{}/approve']", NS)
+    assert approve.get("PolicyId") == "urn:policy-miner:expense-api:put_api_expenses_item_approve"
+    assert approve.get("RuleCombiningAlgId").endswith("rule-combining-algorithm:permit-overrides")
+    assert [
+        (m.find("x:AttributeValue", NS).text, m.find("x:AttributeDesignator", NS).get("AttributeId"))
+        for m in approve.findall("x:Target/x:AnyOf/x:AllOf/x:Match", NS)
+    ] == [
+        ("PUT", "urn:oasis:names:tc:xacml:1.0:action:action-id"),
+        ("/api/expenses/{}/approve", "urn:policy-miner:resource:endpoint"),
+    ]
+
+    threshold, either = approve.findall("x:Rule", NS)
+    assert threshold.get("RuleId") == "urn:policy-miner:expense-api:policy:1"
+    assert threshold.get("Effect") == "Permit"
+    assert [a.text for a in threshold.findall("x:Target/x:AnyOf/x:AllOf/x:Match/x:AttributeValue", NS)] == ["MANAGER"]
+    condition = threshold.find("x:Condition/x:Apply", NS)
+    assert functions(condition) == [
+        "and", "integer-less-than-or-equal", "integer-one-and-only", "string-equal", "string-one-and-only",
+    ]
+    assert [(d.get("AttributeId"), d.get("DataType").split("#")[1]) for d in condition.iter(f"{{{NS['x']}}}AttributeDesignator")] == [
+        ("urn:policy-miner:resource:amount", "integer"),
+        ("urn:policy-miner:subject:department", "string"),
+    ]
+    assert [v.text for v in condition.iter(f"{{{NS['x']}}}AttributeValue")] == ["5000", "Finance"]
+
+    # One AllOf per role, so either role matches
+    assert [
+        [m.find("x:AttributeValue", NS).text for m in all_of]
+        for all_of in either.findall("x:Target/x:AnyOf/x:AllOf", NS)
+    ] == [["AUDITOR"], ["DIRECTOR"]]
+    condition = either.find("x:Condition/x:Apply", NS)
+    assert functions(condition) == [
+        "and", "or", "integer-less-than-or-equal", "integer-one-and-only", "urn:oasis:names:tc:xacml:3.0:function:any-of",
+    ]
+    assert condition.findall("x:AttributeValue", NS)[-1].text == "false"
+    assert "Not translated, review: vibes are good" in result["xacml"]
+
+    obligation = threshold.find("x:ObligationExpressions/x:ObligationExpression", NS)
+    assert (obligation.get("ObligationId"), obligation.get("FulfillOn")) == ("urn:policy-miner:obligation:audit", "Permit")
+    assert {
+        a.get("AttributeId").rsplit(":", 1)[1]: a[0].text or a[0].get("AttributeId")
+        for a in obligation.findall("x:AttributeAssignmentExpression", NS)
+    } == {
+        "policy-id": "1",
+        "endpoint": "PUT /api/expenses/{}/approve",
+        "subject-id": "urn:oasis:names:tc:xacml:1.0:subject:subject-id",
+        "confidence": "90.0",
+    }
+
+    owner = root.find("x:Policy[x:Description='GET /api/expenses/{}']/x:Rule", NS)
+    assert functions(owner.find("x:Condition", NS)) == [
+        "or", "string-at-least-one-member-of", "urn:oasis:names:tc:xacml:3.0:function:any-of",
+    ]
+    assert len(owner.findall(".//x:AttributeAssignmentExpression", NS)) == 3  # No confidence
+    invoices = root.find("x:Policy[x:Description='GET /api/invoices']/x:Rule", NS)
+    assert invoices.find("x:Target", NS).findall("x:AnyOf", NS) == []
+    assert functions(invoices.find("x:Condition", NS)) == ["boolean-is-in"]
+
+    deny = root.find("x:ObligationExpressions/x:ObligationExpression", NS)
+    assert (deny.get("ObligationId"), deny.get("FulfillOn")) == ("urn:policy-miner:obligation:audit", "Deny")
+
+    assert {a["attribute_id"].rsplit(":", 1)[1] for a in result["attributes"]} == {
+        "subject-id", "role", "department", "authenticated", "action-id", "endpoint", "amount", "owner_id",
+    }
+    assert result["attributes"][0]["category"] == "urn:oasis:names:tc:xacml:1.0:subject-category:access-subject"
+    assert result["untranslated"] == [
+        {"policy_id": 2, "endpoint": "PUT /api/expenses/{}/approve", "clause": "vibes are good"}
+    ]
+    assert result["summary"] == {
+        "policies": 4, "endpoints": 3, "rules": 4, "attributes": 8, "below_min_confidence": 0, "untranslated_clauses": 1,
+    }
+
+
+def test_min_confidence_and_value_types(make_policy, make_export_service):
+    """Test low-confidence policies are left out, and decimals, inequality, and string ordering."""
+    policies = [
+        make_policy(
+            1, "ADMIN", "delete", "router.delete('/api/expenses/:id', requireRole('ADMIN'), remove)",
+            conditions="amount <= 99.5 and status != 'paid' and status > open",
+        ),
+        make_policy(2, "MANAGER", "read", "router.get('/api/expenses/:id', requireRole('MANAGER'), show)", confidence=40.0),
+    ]
+    result = make_export_service(XacmlExportService, policies).export(4, min_confidence=50)
+
+    assert [p["endpoint"] for p in result["policies"]] == ["DELETE /api/expenses/{}"]
+    assert result["summary"]["below_min_confidence"] == 1
+    rule = ET.fromstring(result["xacml"]).find("x:Policy/x:Rule", NS)
+    assert functions(rule.find("x:Condition", NS)) == [
+        "and", "double-less-than-or-equal", "double-one-and-only", "not", "string-equal", "string-one-and-only",
+    ]
+    assert result["untranslated"] == [
+        {"policy_id": 1, "endpoint": "DELETE /api/expenses/{}", "clause": "status > open"}
+    ]
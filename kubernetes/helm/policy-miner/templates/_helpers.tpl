@@ -0,0 +1,80 @@
+{{/*
+Release-qualified name, e.g. "prod-policy-miner".
+*/}}
+{{- define "policy-miner.fullname" -}}
+{{- if contains .Chart.Name .Release.Name -}}
+{{- .Release.Name | trunc 63 | trimSuffix "-" -}}
+{{- else -}}
+{{- printf "%s-%s" .Release.Name .Chart.Name | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
+{{- end -}}
+
+{{/*
+Labels of every resource.
+*/}}
+{{- define "policy-miner.labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version }}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end -}}
+
+{{/*
+Selector labels of a component; call with (dict "root" $ "component" "backend").
+*/}}
+{{- define "policy-miner.selectorLabels" -}}
+app.kubernetes.io/name: {{ .root.Chart.Name }}
+app.kubernetes.io/instance: {{ .root.Release.Name }}
+app.kubernetes.io/component: {{ .component }}
+{{- end -}}
+
+{{- define "policy-miner.secretName" -}}
+{{- .Values.secrets.existingSecret | default (printf "%s-secrets" (include "policy-miner.fullname" .)) -}}
+{{- end -}}
+
+{{- define "policy-miner.backendImage" -}}
+{{ .Values.image.backend.repository }}:{{ .Values.image.backend.tag | default .Chart.AppVersion }}
+{{- end -}}
+
+{{/*
+Settings and secrets of backend-image containers.
+*/}}
+{{- define "policy-miner.backendEnv" -}}
+envFrom:
+  - configMapRef:
+      name: {{ include "policy-miner.fullname" . }}-config
+  - secretRef:
+      name: {{ include "policy-miner.secretName" . }}
+{{- end -}}
+
+{{/*
+Pod annotations restarting pods when settings change.
+*/}}
+{{- define "policy-miner.checksums" -}}
+checksum/config: {{ include (print .Template.BasePath "/configmap.yaml") . | sha256sum }}
+{{- if not .Values.secrets.existingSecret }}
+checksum/secret: {{ include (print .Template.BasePath "/secret.yaml") . | sha256sum }}
+{{- end }}
+{{- end -}}
+
+{{/*
+Pod scheduling spreading a component across zones and nodes; call like selectorLabels.
+*/}}
+{{- define "policy-miner.scheduling" -}}
+{{- with .root.Values.imagePullSecrets }}
+imagePullSecrets:
+  {{- toYaml . | nindent 2 }}
+{{- end }}
+{{- if .root.Values.topologySpread.enabled }}
+topologySpreadConstraints:
+  {{- range list .root.Values.topologySpread.zoneKey .root.Values.topologySpread.nodeKey }}
+  - maxSkew: 1
+    topologyKey: {{ . }}
+    whenUnsatisfiable: ScheduleAnyway
+    labelSelector:
+      matchLabels:
+        {{- include "policy-miner.selectorLabels" $ | nindent 8 }}
+  {{- end }}
+{{- end }}
+{{- end -}}
@@ -0,0 +1,51 @@
+terraform {
+  required_providers {
+    policyminer = {
+      source = "doogie-bigmack/policyminer"
+    }
+  }
+}
+
+# Credentials come from POLICY_MINER_TOKEN, or POLICY_MINER_EMAIL and POLICY_MINER_PASSWORD
+provider "policyminer" {
+  endpoint = "https://policy-miner.example.com"
+}
+
+variable "github_token" {
+  type      = string
+  sensitive = true
+}
+
+# Requires a superuser
+resource "policyminer_workspace" "payments" {
+  workspace_id = "payments"
+  name         = "Payments"
+  description  = "Payment processing services"
+}
+
+resource "policyminer_repository" "expense_api" {
+  name            = "expense-api"
+  repository_type = "git"
+  source_url      = "https://github.com/acme/expense-api"
+  connection_config = jsonencode({
+    token = var.github_token
+  })
+}
+
+resource "policyminer_repository_webhook" "expense_api" {
+  repository_id = policyminer_repository.expense_api.id
+}
+
+resource "policyminer_scan_schedule" "expense_api_nightly" {
+  repository_id = policyminer_repository.expense_api.id
+  cron          = "0 3 * * 1-5"
+}
+
+# Register with GitHub: https://policy-miner.example.com<webhook_url>
+output "expense_api_webhook" {
+  value = {
+    url    = policyminer_repository_webhook.expense_api.webhook_url
+    secret = policyminer_repository_webhook.expense_api.secret
+  }
+  sensitive = true
+}
@@ -0,0 +1,247 @@
+// Package client talks to the Policy Miner management API (/api/v1/management).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when the API answers 404, e.g. for an object deleted outside Terraform.
+var ErrNotFound = errors.New("not found")
+
+// Client is an authenticated management API client.
+type Client struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+// New returns a client for the API at endpoint (e.g. https://miner.example.com)
+// using a bearer token, or signing in with email and password when token is empty.
+func New(ctx context.Context, endpoint, token, email, password string) (*Client, error) {
+	c := &Client{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		token:    token,
+		http:     &http.Client{Timeout: 60 * time.Second},
+	}
+	if c.token != "" {
+		return c, nil
+	}
+	var login struct {
+		AccessToken string `json:"access_token"`
+	}
+	err := c.do(ctx, http.MethodPost, "/api/v1/auth/login", map[string]string{"email": email, "password": password}, &login)
+	if err != nil {
+		return nil, fmt.Errorf("signing in as %s: %w", email, err)
+	}
+	c.token = login.AccessToken
+	return c, nil
+}
+
+// APIError is a non-2xx response other than 404.
+type APIError struct {
+	Status int
+	Detail string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("policy miner API returned %d: %s", e.Status, e.Detail)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return &APIError{Status: resp.StatusCode, Detail: detail(data)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// detail is the message of a FastAPI error body, or the raw body.
+func detail(data []byte) string {
+	var body struct {
+		Detail json.RawMessage `json:"detail"`
+	}
+	if json.Unmarshal(data, &body) != nil || body.Detail == nil {
+		return strings.TrimSpace(string(data))
+	}
+	var message string
+	if json.Unmarshal(body.Detail, &message) == nil {
+		return message
+	}
+	return string(body.Detail) // Validation errors are a list
+}
+
+const management = "/api/v1/management"
+
+// Workspace is a tenant.
+type Workspace struct {
+	WorkspaceID string  `json:"workspace_id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+// CreateWorkspace creates a workspace, or reactivates a deleted one with the same ID.
+func (c *Client) CreateWorkspace(ctx context.Context, w Workspace) (*Workspace, error) {
+	var out Workspace
+	return &out, c.do(ctx, http.MethodPost, management+"/workspaces", w, &out)
+}
+
+// GetWorkspace gets an active workspace.
+func (c *Client) GetWorkspace(ctx context.Context, id string) (*Workspace, error) {
+	var out Workspace
+	return &out, c.do(ctx, http.MethodGet, management+"/workspaces/"+id, nil, &out)
+}
+
+// UpdateWorkspace renames or redescribes a workspace.
+func (c *Client) UpdateWorkspace(ctx context.Context, w Workspace) (*Workspace, error) {
+	var out Workspace
+	body := map[string]any{"name": w.Name, "description": w.Description}
+	return &out, c.do(ctx, http.MethodPut, management+"/workspaces/"+w.WorkspaceID, body, &out)
+}
+
+// DeleteWorkspace deactivates a workspace.
+func (c *Client) DeleteWorkspace(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, management+"/workspaces/"+id, nil, nil)
+}
+
+// Repository is a repository registration.
+type Repository struct {
+	ID               int64          `json:"id,omitempty"`
+	Name             string         `json:"name"`
+	Description      *string        `json:"description"`
+	RepositoryType   string         `json:"repository_type,omitempty"`
+	SourceURL        *string        `json:"source_url"`
+	ConnectionConfig map[string]any `json:"connection_config,omitempty"`
+	Status           string         `json:"status,omitempty"`
+}
+
+// CreateRepository registers a repository in the caller's workspace.
+func (c *Client) CreateRepository(ctx context.Context, r Repository) (*Repository, error) {
+	var out Repository
+	return &out, c.do(ctx, http.MethodPost, management+"/repositories", r, &out)
+}
+
+// GetRepository gets a repository; its connection config is never returned.
+func (c *Client) GetRepository(ctx context.Context, id int64) (*Repository, error) {
+	var out Repository
+	return &out, c.do(ctx, http.MethodGet, fmt.Sprintf("%s/repositories/%d", management, id), nil, &out)
+}
+
+// UpdateRepository changes a registration; a nil connection config keeps the stored one.
+func (c *Client) UpdateRepository(ctx context.Context, r Repository) (*Repository, error) {
+	var out Repository
+	body := map[string]any{"name": r.Name, "description": r.Description, "source_url": r.SourceURL}
+	if r.ConnectionConfig != nil {
+		body["connection_config"] = r.ConnectionConfig
+	}
+	return &out, c.do(ctx, http.MethodPut, fmt.Sprintf("%s/repositories/%d", management, r.ID), body, &out)
+}
+
+// DeleteRepository deregisters a repository.
+func (c *Client) DeleteRepository(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("%s/repositories/%d", management, id), nil, nil)
+}
+
+// Webhook is a repository's push webhook settings.
+type Webhook struct {
+	RepositoryID int64   `json:"repository_id"`
+	Enabled      bool    `json:"enabled"`
+	HasSecret    bool    `json:"has_secret"`
+	WebhookURL   string  `json:"webhook_url"`
+	Secret       *string `json:"secret"`
+}
+
+// GetWebhook gets a repository's webhook settings, without the secret.
+func (c *Client) GetWebhook(ctx context.Context, repositoryID int64) (*Webhook, error) {
+	var out Webhook
+	return &out, c.do(ctx, http.MethodGet, fmt.Sprintf("%s/repositories/%d/webhook", management, repositoryID), nil, &out)
+}
+
+// ConfigureWebhook turns a webhook on or off; a nil secret keeps the stored one or generates one.
+func (c *Client) ConfigureWebhook(ctx context.Context, repositoryID int64, enabled bool, secret *string) (*Webhook, error) {
+	var out Webhook
+	body := map[string]any{"enabled": enabled}
+	if secret != nil {
+		body["secret"] = *secret
+	}
+	return &out, c.do(ctx, http.MethodPut, fmt.Sprintf("%s/repositories/%d/webhook", management, repositoryID), body, &out)
+}
+
+// DeleteWebhook turns a webhook off and forgets its secret.
+func (c *Client) DeleteWebhook(ctx context.Context, repositoryID int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("%s/repositories/%d/webhook", management, repositoryID), nil, nil)
+}
+
+// ScanSchedule is a repository's cron scan schedule.
+type ScanSchedule struct {
+	ID           int64   `json:"id,omitempty"`
+	RepositoryID int64   `json:"repository_id"`
+	Cron         string  `json:"cron"`
+	Incremental  bool    `json:"incremental"`
+	Enabled      bool    `json:"enabled"`
+	NextRunAt    *string `json:"next_run_at,omitempty"`
+}
+
+// CreateScanSchedule schedules a repository's scans.
+func (c *Client) CreateScanSchedule(ctx context.Context, s ScanSchedule) (*ScanSchedule, error) {
+	var out ScanSchedule
+	return &out, c.do(ctx, http.MethodPost, management+"/scan-schedules", s, &out)
+}
+
+// GetScanSchedule gets a schedule.
+func (c *Client) GetScanSchedule(ctx context.Context, id int64) (*ScanSchedule, error) {
+	var out ScanSchedule
+	return &out, c.do(ctx, http.MethodGet, fmt.Sprintf("%s/scan-schedules/%d", management, id), nil, &out)
+}
+
+// UpdateScanSchedule changes a schedule's cron expression, scan kind, or whether it is enabled.
+func (c *Client) UpdateScanSchedule(ctx context.Context, s ScanSchedule) (*ScanSchedule, error) {
+	var out ScanSchedule
+	body := map[string]any{"cron": s.Cron, "incremental": s.Incremental, "enabled": s.Enabled}
+	return &out, c.do(ctx, http.MethodPut, fmt.Sprintf("%s/scan-schedules/%d", management, s.ID), body, &out)
+}
+
+// DeleteScanSchedule deletes a schedule.
+func (c *Client) DeleteScanSchedule(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("%s/scan-schedules/%d", management, id), nil, nil)
+}
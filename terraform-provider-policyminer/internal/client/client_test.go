@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignsInAndSendsBearerToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/auth/login":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["email"] != "ops@acme.io" || body["password"] != "hunter22" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token": "jwt", "token_type": "bearer", "tenant_id": "acme"}`))
+		case "/api/v1/management/scan-schedules/7":
+			authorization = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte(`{"id": 7, "repository_id": 3, "cron": "@daily", "incremental": true, "enabled": true, "next_run_at": null}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(context.Background(), server.URL+"/", "", "ops@acme.io", "hunter22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := c.GetScanSchedule(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer jwt" {
+		t.Errorf("Authorization = %q, want Bearer jwt", authorization)
+	}
+	if schedule.Cron != "@daily" || schedule.RepositoryID != 3 || schedule.NextRunAt != nil {
+		t.Errorf("schedule = %+v", schedule)
+	}
+	if _, err := c.GetRepository(context.Background(), 9); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing repository error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestErrorsCarryTheAPIDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer static" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["secret"]; ok {
+			t.Errorf("secret sent when not configured: %v", body)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"detail": "Repository 3 already has a scan schedule"}`))
+	}))
+	defer server.Close()
+
+	c, err := New(context.Background(), server.URL, "static", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.ConfigureWebhook(context.Background(), 3, true, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != 400 || apiErr.Detail != "Repository 3 already has a scan schedule" {
+		t.Errorf("error = %v", err)
+	}
+}
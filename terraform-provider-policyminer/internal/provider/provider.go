@@ -0,0 +1,144 @@
+// Package provider implements the policyminer Terraform provider: workspaces,
+// repository registrations, repository webhooks, and scan schedules managed
+// through the Policy Miner management API.
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/doogie-bigmack/application-security-policy-miner/terraform-provider-policyminer/internal/client"
+)
+
+var _ provider.Provider = (*policyMinerProvider)(nil)
+
+type policyMinerProvider struct {
+	version string
+}
+
+type providerModel struct {
+	Endpoint types.String `tfsdk:"endpoint"`
+	Token    types.String `tfsdk:"token"`
+	Email    types.String `tfsdk:"email"`
+	Password types.String `tfsdk:"password"`
+}
+
+// New returns the provider factory for a release version.
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &policyMinerProvider{version: version}
+	}
+}
+
+func (p *policyMinerProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "policyminer"
+	resp.Version = p.version
+}
+
+func (p *policyMinerProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages Policy Miner workspaces, repositories, webhooks, and scan schedules.",
+		Attributes: map[string]schema.Attribute{
+			"endpoint": schema.StringAttribute{
+				Description: "Base URL of the Policy Miner API, e.g. https://miner.example.com. Defaults to POLICY_MINER_ENDPOINT.",
+				Optional:    true,
+			},
+			"token": schema.StringAttribute{
+				Description: "Bearer token. Defaults to POLICY_MINER_TOKEN; when unset the provider signs in with email and password.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"email": schema.StringAttribute{
+				Description: "Email to sign in with. Defaults to POLICY_MINER_EMAIL.",
+				Optional:    true,
+			},
+			"password": schema.StringAttribute{
+				Description: "Password to sign in with. Defaults to POLICY_MINER_PASSWORD.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+// setting is a configured value, or the environment variable's when unset.
+func setting(value types.String, env string) string {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueString()
+	}
+	return os.Getenv(env)
+}
+
+func (p *policyMinerProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config providerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	endpoint := setting(config.Endpoint, "POLICY_MINER_ENDPOINT")
+	token := setting(config.Token, "POLICY_MINER_TOKEN")
+	email := setting(config.Email, "POLICY_MINER_EMAIL")
+	password := setting(config.Password, "POLICY_MINER_PASSWORD")
+	if endpoint == "" {
+		resp.Diagnostics.AddError("Missing endpoint", "Set endpoint or POLICY_MINER_ENDPOINT to the Policy Miner API URL.")
+	}
+	if token == "" && (email == "" || password == "") {
+		resp.Diagnostics.AddError("Missing credentials", "Set token, or email and password (or their POLICY_MINER_* variables).")
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	c, err := client.New(ctx, endpoint, token, email, password)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to sign in to Policy Miner", err.Error())
+		return
+	}
+	resp.ResourceData = c
+}
+
+func (p *policyMinerProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newWorkspaceResource,
+		newRepositoryResource,
+		newRepositoryWebhookResource,
+		newScanScheduleResource,
+	}
+}
+
+func (p *policyMinerProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+// configureClient takes the provider's client from resource configuration.
+func configureClient(req resource.ConfigureRequest, diags *diag.Diagnostics) *client.Client {
+	if req.ProviderData == nil {
+		return nil // The provider is not configured yet
+	}
+	c, ok := req.ProviderData.(*client.Client)
+	if !ok {
+		diags.AddError("Unexpected provider data", "Expected a Policy Miner client.")
+	}
+	return c
+}
+
+// notFound reports whether an object is gone, so Read can drop it from state.
+func notFound(err error) bool {
+	return errors.Is(err, client.ErrNotFound)
+}
+
+// optionalString is a nullable string attribute's value.
+func optionalString(value types.String) *string {
+	if value.IsNull() || value.IsUnknown() {
+		return nil
+	}
+	s := value.ValueString()
+	return &s
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// resourceSchema is a resource's schema, failing the test on diagnostics.
+func resourceSchema(t *testing.T, r resource.Resource) schema.Schema {
+	t.Helper()
+	var resp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("schema diagnostics: %v", resp.Diagnostics)
+	}
+	return resp.Schema
+}
+
+// newPlan is a plan holding model, which may leave computed attributes unknown.
+func newPlan(t *testing.T, s schema.Schema, model any) tfsdk.Plan {
+	t.Helper()
+	plan := tfsdk.Plan{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+	if diags := plan.Set(context.Background(), model); diags.HasError() {
+		t.Fatalf("setting plan: %v", diags)
+	}
+	return plan
+}
+
+// newState is a state holding model, or an empty state when model is nil.
+func newState(t *testing.T, s schema.Schema, model any) tfsdk.State {
+	t.Helper()
+	state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+	if model == nil {
+		return state
+	}
+	if diags := state.Set(context.Background(), model); diags.HasError() {
+		t.Fatalf("setting state: %v", diags)
+	}
+	return state
+}
+
+func TestSchemasAreValid(t *testing.T) {
+	ctx := context.Background()
+	p := New("test")()
+
+	var providerSchema provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &providerSchema)
+	if diags := providerSchema.Schema.ValidateImplementation(ctx); diags.HasError() {
+		t.Fatalf("provider schema: %v", diags)
+	}
+	for _, name := range []string{"token", "password"} {
+		if !providerSchema.Schema.Attributes[name].IsSensitive() {
+			t.Errorf("provider attribute %s is not sensitive", name)
+		}
+	}
+
+	var metadata provider.MetadataResponse
+	p.Metadata(ctx, provider.MetadataRequest{}, &metadata)
+	sensitive := map[string]string{
+		"policyminer_workspace":          "",
+		"policyminer_repository":         "connection_config",
+		"policyminer_repository_webhook": "secret",
+		"policyminer_scan_schedule":      "",
+	}
+	for _, factory := range p.Resources(ctx) {
+		r := factory()
+		var resp resource.MetadataResponse
+		r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: metadata.TypeName}, &resp)
+		attribute, ok := sensitive[resp.TypeName]
+		if !ok {
+			t.Errorf("unexpected resource %s", resp.TypeName)
+			continue
+		}
+		delete(sensitive, resp.TypeName)
+
+		s := resourceSchema(t, r)
+		if diags := s.ValidateImplementation(ctx); diags.HasError() {
+			t.Errorf("%s schema: %v", resp.TypeName, diags)
+		}
+		if attribute != "" && !s.Attributes[attribute].IsSensitive() {
+			t.Errorf("%s attribute %s is not sensitive", resp.TypeName, attribute)
+		}
+		if _, ok := r.(resource.ResourceWithImportState); !ok {
+			t.Errorf("%s cannot be imported", resp.TypeName)
+		}
+	}
+	for name := range sensitive {
+		t.Errorf("resource %s is not registered", name)
+	}
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/doogie-bigmack/application-security-policy-miner/terraform-provider-policyminer/internal/client"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*repositoryResource)(nil)
+	_ resource.ResourceWithImportState = (*repositoryResource)(nil)
+)
+
+type repositoryResource struct {
+	client *client.Client
+}
+
+type repositoryModel struct {
+	ID               types.Int64  `tfsdk:"id"`
+	Name             types.String `tfsdk:"name"`
+	Description      types.String `tfsdk:"description"`
+	RepositoryType   types.String `tfsdk:"repository_type"`
+	SourceURL        types.String `tfsdk:"source_url"`
+	ConnectionConfig types.String `tfsdk:"connection_config"`
+	Status           types.String `tfsdk:"status"`
+}
+
+func newRepositoryResource() resource.Resource {
+	return &repositoryResource{}
+}
+
+func (r *repositoryResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository"
+}
+
+func (r *repositoryResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A repository registered in the provider user's workspace.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.Int64Attribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.Int64{int64planmodifier.UseStateForUnknown()},
+			},
+			"name": schema.StringAttribute{
+				Required: true,
+			},
+			"description": schema.StringAttribute{
+				Optional: true,
+			},
+			"repository_type": schema.StringAttribute{
+				Description:   "git, database, or mainframe.",
+				Required:      true,
+				Validators:    []validator.String{stringvalidator.OneOf("git", "database", "mainframe")},
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"source_url": schema.StringAttribute{
+				Optional: true,
+			},
+			"connection_config": schema.StringAttribute{
+				Description: "JSON connection settings and credentials, e.g. jsonencode({ token = var.github_token }). " +
+					"Write-only: the API never returns it, so changes made outside Terraform are not detected.",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"status": schema.StringAttribute{
+				Description: "Connection and scan status.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *repositoryResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, &resp.Diagnostics)
+}
+
+// repository is the API registration a plan describes.
+func (m *repositoryModel) repository() (client.Repository, error) {
+	repository := client.Repository{
+		ID:             m.ID.ValueInt64(),
+		Name:           m.Name.ValueString(),
+		Description:    optionalString(m.Description),
+		RepositoryType: m.RepositoryType.ValueString(),
+		SourceURL:      optionalString(m.SourceURL),
+	}
+	if config := optionalString(m.ConnectionConfig); config != nil {
+		if err := json.Unmarshal([]byte(*config), &repository.ConnectionConfig); err != nil {
+			return repository, err
+		}
+	}
+	return repository, nil
+}
+
+// set takes the API's fields; the connection config stays as configured.
+func (m *repositoryModel) set(r *client.Repository) {
+	m.ID = types.Int64Value(r.ID)
+	m.Name = types.StringValue(r.Name)
+	m.Description = types.StringPointerValue(r.Description)
+	m.RepositoryType = types.StringValue(r.RepositoryType)
+	m.SourceURL = types.StringPointerValue(r.SourceURL)
+	m.Status = types.StringValue(r.Status)
+}
+
+func (r *repositoryResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan repositoryModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	repository, err := plan.repository()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("connection_config"), "Invalid connection config", err.Error())
+		return
+	}
+	created, err := r.client.CreateRepository(ctx, repository)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to register repository", err.Error())
+		return
+	}
+	plan.set(created)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *repositoryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state repositoryModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	repository, err := r.client.GetRepository(ctx, state.ID.ValueInt64())
+	if notFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to read repository", err.Error())
+		return
+	}
+	state.set(repository)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *repositoryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state repositoryModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.ID = state.ID
+	repository, err := plan.repository()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("connection_config"), "Invalid connection config", err.Error())
+		return
+	}
+	if plan.ConnectionConfig.Equal(state.ConnectionConfig) {
+		repository.ConnectionConfig = nil // Unchanged, so leave the stored one alone
+	}
+	updated, err := r.client.UpdateRepository(ctx, repository)
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to update repository", err.Error())
+		return
+	}
+	plan.set(updated)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *repositoryResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state repositoryModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.client.DeleteRepository(ctx, state.ID.ValueInt64()); err != nil && !notFound(err) {
+		resp.Diagnostics.AddError("Unable to deregister repository", err.Error())
+	}
+}
+
+func (r *repositoryResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importID(ctx, req, resp, "id")
+}
+
+// importID imports a resource by a numeric ID attribute.
+func importID(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse, attribute string) {
+	id, err := strconv.ParseInt(req.ID, 10, 64)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid import ID", "Expected a numeric ID, got "+strconv.Quote(req.ID))
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(attribute), id)...)
+}
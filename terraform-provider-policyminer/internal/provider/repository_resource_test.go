@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/doogie-bigmack/application-security-policy-miner/terraform-provider-policyminer/internal/client"
+)
+
+// repositoryAPI serves /repositories/7, recording the bodies it receives.
+type repositoryAPI struct {
+	repository map[string]any
+	requests   []string
+	bodies     []map[string]any
+}
+
+func (a *repositoryAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.requests = append(a.requests, r.Method+" "+r.URL.Path)
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	a.bodies = append(a.bodies, body)
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/management/repositories":
+		a.repository = map[string]any{"id": 7, "status": "pending"}
+		for _, key := range []string{"name", "description", "repository_type", "source_url"} {
+			a.repository[key] = body[key]
+		}
+	case r.URL.Path != "/api/v1/management/repositories/7" || a.repository == nil:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case r.Method == http.MethodPut:
+		for _, key := range []string{"name", "description", "source_url"} {
+			a.repository[key] = body[key]
+		}
+	case r.Method == http.MethodDelete:
+		a.repository = nil
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(a.repository)
+}
+
+func TestRepositoryCRUD(t *testing.T) {
+	ctx := context.Background()
+	api := &repositoryAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	c, err := client.New(ctx, server.URL, "static", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &repositoryResource{client: c}
+	s := resourceSchema(t, r)
+
+	planned := repositoryModel{
+		ID:               types.Int64Unknown(),
+		Name:             types.StringValue("payments"),
+		Description:      types.StringNull(),
+		RepositoryType:   types.StringValue("git"),
+		SourceURL:        types.StringValue("https://github.com/acme/payments"),
+		ConnectionConfig: types.StringValue(`{"token": "ghp_x"}`),
+		Status:           types.StringUnknown(),
+	}
+	created := resource.CreateResponse{State: newState(t, s, nil)}
+	r.Create(ctx, resource.CreateRequest{Plan: newPlan(t, s, &planned)}, &created)
+	if created.Diagnostics.HasError() {
+		t.Fatalf("create: %v", created.Diagnostics)
+	}
+	var state repositoryModel
+	created.State.Get(ctx, &state)
+	if state.ID.ValueInt64() != 7 || state.Status.ValueString() != "pending" || !state.Description.IsNull() {
+		t.Errorf("state after create = %+v", state)
+	}
+	// The connection config is sent as JSON and kept as configured, since the API never returns it
+	if config, _ := api.bodies[0]["connection_config"].(map[string]any); config["token"] != "ghp_x" {
+		t.Errorf("create body = %v", api.bodies[0])
+	}
+	if state.ConnectionConfig.ValueString() != `{"token": "ghp_x"}` {
+		t.Errorf("connection_config = %s", state.ConnectionConfig)
+	}
+
+	// An unchanged connection config is left out of the update so the stored one is kept
+	changed := state
+	changed.Description = types.StringValue("Card payments")
+	updated := resource.UpdateResponse{State: newState(t, s, &state)}
+	r.Update(ctx, resource.UpdateRequest{Plan: newPlan(t, s, &changed), State: newState(t, s, &state)}, &updated)
+	if updated.Diagnostics.HasError() {
+		t.Fatalf("update: %v", updated.Diagnostics)
+	}
+	if _, ok := api.bodies[1]["connection_config"]; ok || api.bodies[1]["description"] != "Card payments" {
+		t.Errorf("update body = %v", api.bodies[1])
+	}
+	updated.State.Get(ctx, &state)
+	if state.Description.ValueString() != "Card payments" {
+		t.Errorf("description after update = %s", state.Description)
+	}
+
+	deleted := resource.DeleteResponse{State: newState(t, s, &state)}
+	r.Delete(ctx, resource.DeleteRequest{State: newState(t, s, &state)}, &deleted)
+	if deleted.Diagnostics.HasError() {
+		t.Fatalf("delete: %v", deleted.Diagnostics)
+	}
+
+	// Once deleted outside Terraform, Read drops the resource instead of failing
+	read := resource.ReadResponse{State: newState(t, s, &state)}
+	r.Read(ctx, resource.ReadRequest{State: newState(t, s, &state)}, &read)
+	if read.Diagnostics.HasError() || !read.State.Raw.IsNull() {
+		t.Errorf("read after delete: diagnostics %v, state %v", read.Diagnostics, read.State.Raw)
+	}
+	want := []string{
+		"POST /api/v1/management/repositories",
+		"PUT /api/v1/management/repositories/7",
+		"DELETE /api/v1/management/repositories/7",
+		"GET /api/v1/management/repositories/7",
+	}
+	if len(api.requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", api.requests, want)
+	}
+	for i := range want {
+		if api.requests[i] != want[i] {
+			t.Errorf("request %d = %s, want %s", i, api.requests[i], want[i])
+		}
+	}
+}
+
+func TestRepositoryRejectsInvalidConnectionConfig(t *testing.T) {
+	r := &repositoryResource{}
+	s := resourceSchema(t, r)
+	planned := repositoryModel{
+		ID:               types.Int64Unknown(),
+		Name:             types.StringValue("payments"),
+		Description:      types.StringNull(),
+		RepositoryType:   types.StringValue("git"),
+		SourceURL:        types.StringNull(),
+		ConnectionConfig: types.StringValue("token = ghp_x"),
+		Status:           types.StringUnknown(),
+	}
+	resp := resource.CreateResponse{State: newState(t, s, nil)}
+	r.Create(context.Background(), resource.CreateRequest{Plan: newPlan(t, s, &planned)}, &resp)
+	if !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Invalid connection config" {
+		t.Errorf("diagnostics = %v", resp.Diagnostics)
+	}
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/doogie-bigmack/application-security-policy-miner/terraform-provider-policyminer/internal/client"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*repositoryWebhookResource)(nil)
+	_ resource.ResourceWithImportState = (*repositoryWebhookResource)(nil)
+)
+
+type repositoryWebhookResource struct {
+	client *client.Client
+}
+
+type repositoryWebhookModel struct {
+	RepositoryID types.Int64  `tfsdk:"repository_id"`
+	Enabled      types.Bool   `tfsdk:"enabled"`
+	Secret       types.String `tfsdk:"secret"`
+	WebhookURL   types.String `tfsdk:"webhook_url"`
+}
+
+func newRepositoryWebhookResource() resource.Resource {
+	return &repositoryWebhookResource{}
+}
+
+func (r *repositoryWebhookResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository_webhook"
+}
+
+func (r *repositoryWebhookResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Whether pushes to a repository trigger scans, and the secret the Git provider signs payloads with. " +
+			"Destroying it turns the webhook off and forgets the secret.",
+		Attributes: map[string]schema.Attribute{
+			"repository_id": schema.Int64Attribute{
+				Required:      true,
+				PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			},
+			"enabled": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+			},
+			"secret": schema.StringAttribute{
+				Description: "Payload signing secret of at least 16 characters; generated when omitted. " +
+					"Only known to Terraform when set or generated by it.",
+				Optional:      true,
+				Computed:      true,
+				Sensitive:     true,
+				Validators:    []validator.String{stringvalidator.LengthAtLeast(16)},
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"webhook_url": schema.StringAttribute{
+				Description:   "Path to register with the Git provider, relative to the API endpoint.",
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+		},
+	}
+}
+
+func (r *repositoryWebhookResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, &resp.Diagnostics)
+}
+
+// set takes the API's settings; the secret is kept unless the API returned one or has none.
+func (m *repositoryWebhookModel) set(w *client.Webhook) {
+	m.RepositoryID = types.Int64Value(w.RepositoryID)
+	m.Enabled = types.BoolValue(w.Enabled)
+	m.WebhookURL = types.StringValue(w.WebhookURL)
+	switch {
+	case w.Secret != nil:
+		m.Secret = types.StringPointerValue(w.Secret)
+	case !w.HasSecret, m.Secret.IsUnknown():
+		m.Secret = types.StringNull() // Cleared outside Terraform, or kept from before an import
+	}
+}
+
+// configure turns the webhook on or off, sending the secret only when configured.
+func (r *repositoryWebhookResource) configure(ctx context.Context, plan *repositoryWebhookModel) error {
+	w, err := r.client.ConfigureWebhook(ctx, plan.RepositoryID.ValueInt64(), plan.Enabled.ValueBool(), optionalString(plan.Secret))
+	if err != nil {
+		return err
+	}
+	plan.set(w)
+	return nil
+}
+
+func (r *repositoryWebhookResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan repositoryWebhookModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.configure(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError("Unable to configure repository webhook", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *repositoryWebhookResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state repositoryWebhookModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	w, err := r.client.GetWebhook(ctx, state.RepositoryID.ValueInt64())
+	if notFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to read repository webhook", err.Error())
+		return
+	}
+	state.set(w)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *repositoryWebhookResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan repositoryWebhookModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.configure(ctx, &plan); err != nil {
+		resp.Diagnostics.AddError("Unable to configure repository webhook", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *repositoryWebhookResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state repositoryWebhookModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.client.DeleteWebhook(ctx, state.RepositoryID.ValueInt64()); err != nil && !notFound(err) {
+		resp.Diagnostics.AddError("Unable to delete repository webhook", err.Error())
+	}
+}
+
+func (r *repositoryWebhookResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importID(ctx, req, resp, "repository_id")
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/doogie-bigmack/application-security-policy-miner/terraform-provider-policyminer/internal/client"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*scanScheduleResource)(nil)
+	_ resource.ResourceWithImportState = (*scanScheduleResource)(nil)
+)
+
+type scanScheduleResource struct {
+	client *client.Client
+}
+
+type scanScheduleModel struct {
+	ID           types.Int64  `tfsdk:"id"`
+	RepositoryID types.Int64  `tfsdk:"repository_id"`
+	Cron         types.String `tfsdk:"cron"`
+	Incremental  types.Bool   `tfsdk:"incremental"`
+	Enabled      types.Bool   `tfsdk:"enabled"`
+	NextRunAt    types.String `tfsdk:"next_run_at"`
+}
+
+func newScanScheduleResource() resource.Resource {
+	return &scanScheduleResource{}
+}
+
+func (r *scanScheduleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_scan_schedule"
+}
+
+func (r *scanScheduleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Scans a repository on a cron schedule. A repository has at most one schedule.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.Int64Attribute{
+				Computed:      true,
+				PlanModifiers: []planmodifier.Int64{int64planmodifier.UseStateForUnknown()},
+			},
+			"repository_id": schema.Int64Attribute{
+				Required:      true,
+				PlanModifiers: []planmodifier.Int64{int64planmodifier.RequiresReplace()},
+			},
+			"cron": schema.StringAttribute{
+				Description: "Five-field cron expression in UTC, e.g. \"0 3 * * 1-5\", or @hourly, @daily, @weekly, @monthly.",
+				Required:    true,
+			},
+			"incremental": schema.BoolAttribute{
+				Description: "Only scan files changed since the last scan.",
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+			},
+			"enabled": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(true),
+			},
+			"next_run_at": schema.StringAttribute{
+				Description: "When the next scan is queued; null while disabled.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *scanScheduleResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.client = configureClient(req, &resp.Diagnostics)
+}
+
+func (m *scanScheduleModel) schedule() client.ScanSchedule {
+	return client.ScanSchedule{
+		ID:           m.ID.ValueInt64(),
+		RepositoryID: m.RepositoryID.ValueInt64(),
+		Cron:         m.Cron.ValueString(),
+		Incremental:  m.Incremental.ValueBool(),
+		Enabled:      m.Enabled.ValueBool(),
+	}
+}
+
+func (m *scanScheduleModel) set(s *client.ScanSchedule) {
+	m.ID = types.Int64Value(s.ID)
+	m.RepositoryID = types.Int64Value(s.RepositoryID)
+	m.Cron = types.StringValue(s.Cron)
+	m.Incremental = types.BoolValue(s.Incremental)
+	m.Enabled = types.BoolValue(s.Enabled)
+	m.NextRunAt = types.StringPointerValue(s.NextRunAt)
+}
+
+func (r *scanScheduleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan scanScheduleModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	s, err := r.client.CreateScanSchedule(ctx, plan.schedule())
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to create scan schedule", err.Error())
+		return
+	}
+	plan.set(s)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *scanScheduleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state scanScheduleModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	s, err := r.client.GetScanSchedule(ctx, state.ID.ValueInt64())
+	if notFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to read scan schedule", err.Error())
+		return
+	}
+	state.set(s)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *scanScheduleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state scanScheduleModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.ID = state.ID
+	s, err := r.client.UpdateScanSchedule(ctx, plan.schedule())
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to update scan schedule", err.Error())
+		return
+	}
+	plan.set(s)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *scanScheduleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state scanScheduleModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err := r.client.DeleteScanSchedule(ctx, state.ID.ValueInt64()); err != nil && !notFound(err) {
+		resp.Diagnostics.AddError("Unable to delete scan schedule", err.Error())
+	}
+}
+
+func (r *scanScheduleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importID(ctx, req, resp, "id")
+}